@@ -0,0 +1,138 @@
+/*
+Package analysistest provides assertions on the tokens of TokenStreams
+and Analyzers, for the tests of analysis components.
+*/
+package analysistest
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"strings"
+	"testing"
+)
+
+// analysis/BaseTokenStreamTestCase.java
+
+/*
+Consumes ts, checking each token against the expected values. The
+term texts in output are always checked; startOffsets, endOffsets,
+types, posIncrements and posLengths are only checked if they are not
+nil, and the final offset is only checked if finalOffset is not
+negative. The stream is closed afterwards.
+
+The tokens are also checked to form a valid token graph: positions
+never go backwards, position lengths are positive, and all tokens
+leaving from a position have the same start offset, while all tokens
+arriving at a position have the same end offset.
+*/
+func AssertTokenStreamContents(t testing.TB, ts analysis.TokenStream, output []string,
+	startOffsets, endOffsets []int, types []string, posIncrements, posLengths []int,
+	finalOffset int) {
+	t.Helper()
+	atts := ts.Attributes()
+	termAtt := atts.Add("CharTermAttribute").(analysis.CharTermAttribute)
+	offsetAtt := atts.Add("OffsetAttribute").(analysis.OffsetAttribute)
+	typeAtt := atts.Add("TypeAttribute").(analysis.TypeAttribute)
+	posIncrAtt := atts.Add("PositionIncrementAttribute").(analysis.PositionIncrementAttribute)
+	posLengthAtt := atts.Add("PositionLengthAttribute").(analysis.PositionLengthAttribute)
+	defer func() {
+		if err := ts.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	if err := ts.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	// the start offsets of the tokens leaving from, and the end offsets
+	// of the tokens arriving at, each position of the graph
+	pos := -1
+	posToStartOffset, posToEndOffset := make(map[int]int), make(map[int]int)
+	for i, term := range output {
+		// extra safety to enforce, that the state is not preserved and
+		// also assign bogus values
+		atts.Clear()
+		termAtt.Append("bogusTerm")
+		offsetAtt.SetOffset(14584724, 24683243)
+		typeAtt.SetType("bogusType")
+		posIncrAtt.SetPositionIncrement(45987657)
+		posLengthAtt.SetPositionLength(45987653)
+
+		ok, err := ts.IncrementToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("token %v does not exist, expected %v", i, term)
+		}
+		if v := termAtt.String(); v != term {
+			t.Errorf("term %v: expected %v, but was %v", i, term, v)
+		}
+		if startOffsets != nil && offsetAtt.StartOffset() != startOffsets[i] {
+			t.Errorf("startOffset %v (%v): expected %v, but was %v", i, term, startOffsets[i], offsetAtt.StartOffset())
+		}
+		if endOffsets != nil && offsetAtt.EndOffset() != endOffsets[i] {
+			t.Errorf("endOffset %v (%v): expected %v, but was %v", i, term, endOffsets[i], offsetAtt.EndOffset())
+		}
+		if types != nil && typeAtt.Type() != types[i] {
+			t.Errorf("type %v (%v): expected %v, but was %v", i, term, types[i], typeAtt.Type())
+		}
+		if posIncrements != nil && posIncrAtt.PositionIncrement() != posIncrements[i] {
+			t.Errorf("posIncrement %v (%v): expected %v, but was %v", i, term, posIncrements[i], posIncrAtt.PositionIncrement())
+		}
+		if posLengths != nil && posLengthAtt.PositionLength() != posLengths[i] {
+			t.Errorf("posLength %v (%v): expected %v, but was %v", i, term, posLengths[i], posLengthAtt.PositionLength())
+		}
+
+		startOffset, endOffset := offsetAtt.StartOffset(), offsetAtt.EndOffset()
+		if startOffset < 0 || endOffset < startOffset {
+			t.Errorf("token %v (%v): invalid offsets %v-%v", i, term, startOffset, endOffset)
+		}
+		posInc, posLength := posIncrAtt.PositionIncrement(), posLengthAtt.PositionLength()
+		if pos < 0 && posInc < 1 {
+			t.Errorf("token %v (%v): first posIncrement must be >= 1, but was %v", i, term, posInc)
+		}
+		if posLength < 1 {
+			t.Errorf("token %v (%v): posLength must be >= 1, but was %v", i, term, posLength)
+		}
+		pos += posInc
+		if offset, ok := posToStartOffset[pos]; !ok {
+			// first time we've seen a token leaving from this position
+			posToStartOffset[pos] = startOffset
+		} else if offset != startOffset {
+			t.Errorf("token %v (%v) leaving pos=%v: expected startOffset %v, but was %v", i, term, pos, offset, startOffset)
+		}
+		endPos := pos + posLength
+		if offset, ok := posToEndOffset[endPos]; !ok {
+			// first time we've seen a token arriving to this position
+			posToEndOffset[endPos] = endOffset
+		} else if offset != endOffset {
+			t.Errorf("token %v (%v) arriving pos=%v: expected endOffset %v, but was %v", i, term, endPos, offset, endOffset)
+		}
+	}
+	if ok, err := ts.IncrementToken(); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Errorf("TokenStream has more tokens than expected (expected count=%v): %v", len(output), termAtt)
+	}
+	if err := ts.End(); err != nil {
+		t.Fatal(err)
+	}
+	if finalOffset >= 0 && offsetAtt.EndOffset() != finalOffset {
+		t.Errorf("finalOffset: expected %v, but was %v", finalOffset, offsetAtt.EndOffset())
+	}
+}
+
+/*
+Analyzes input with the Analyzer a, as the field "dummy", and checks
+the tokens as AssertTokenStreamContents() does, with the length of
+the input as the final offset.
+*/
+func AssertAnalyzesTo(t testing.TB, a analysis.Analyzer, input string, output []string,
+	startOffsets, endOffsets []int, types []string, posIncrements []int) {
+	t.Helper()
+	ts, err := a.TokenStream("dummy", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertTokenStreamContents(t, ts, output, startOffsets, endOffsets, types, posIncrements, nil, len(input))
+}
@@ -0,0 +1,76 @@
+package analysistest
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+)
+
+// analysis/CannedTokenStream.java
+
+// A token of a CannedTokenStream.
+type Token struct {
+	Term              string
+	PositionIncrement int
+	PositionLength    int
+	StartOffset       int
+	EndOffset         int
+	// Optional payload of the token
+	Payload []byte
+}
+
+// Creates a token with the given position increment and length, and
+// offsets.
+func NewToken(term string, posInc, posLength, startOffset, endOffset int) Token {
+	return Token{term, posInc, posLength, startOffset, endOffset, nil}
+}
+
+// TokenStream from a canned list of Tokens, e.g. to test the filters
+// on token graphs which no tokenizer produces.
+type CannedTokenStream struct {
+	*analysis.TokenStreamImpl
+	tokens       []Token
+	upto         int
+	finalOffset  int
+	termAtt      analysis.CharTermAttribute
+	posIncrAtt   analysis.PositionIncrementAttribute
+	posLengthAtt analysis.PositionLengthAttribute
+	offsetAtt    analysis.OffsetAttribute
+	payloadAtt   analysis.PayloadAttribute
+}
+
+// Creates a stream of the given tokens, whose final offset is
+// finalOffset.
+func NewCannedTokenStream(finalOffset int, tokens ...Token) *CannedTokenStream {
+	ans := &CannedTokenStream{TokenStreamImpl: analysis.NewTokenStream(), tokens: tokens, finalOffset: finalOffset}
+	atts := ans.Attributes()
+	ans.termAtt = atts.Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.posIncrAtt = atts.Add("PositionIncrementAttribute").(analysis.PositionIncrementAttribute)
+	ans.posLengthAtt = atts.Add("PositionLengthAttribute").(analysis.PositionLengthAttribute)
+	ans.offsetAtt = atts.Add("OffsetAttribute").(analysis.OffsetAttribute)
+	ans.payloadAtt = atts.Add("PayloadAttribute").(analysis.PayloadAttribute)
+	return ans
+}
+
+func (ts *CannedTokenStream) IncrementToken() (bool, error) {
+	if ts.upto >= len(ts.tokens) {
+		return false, nil
+	}
+	token := ts.tokens[ts.upto]
+	ts.upto++
+	ts.Attributes().Clear()
+	ts.termAtt.Append(token.Term)
+	ts.posIncrAtt.SetPositionIncrement(token.PositionIncrement)
+	ts.posLengthAtt.SetPositionLength(token.PositionLength)
+	ts.offsetAtt.SetOffset(token.StartOffset, token.EndOffset)
+	ts.payloadAtt.SetPayload(token.Payload)
+	return true, nil
+}
+
+func (ts *CannedTokenStream) End() error {
+	ts.offsetAtt.SetOffset(ts.finalOffset, ts.finalOffset)
+	return nil
+}
+
+func (ts *CannedTokenStream) Reset() error {
+	ts.upto = 0
+	return nil
+}
@@ -0,0 +1,334 @@
+package analysis
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// analysis/Analyzer.java
+
+/*
+An Analyzer builds TokenStreams, which analyze text. It thus
+represents a policy for extracting index terms from text.
+
+In order to define what analysis is done, implementations embed
+*AnalyzerImpl, and implement ComponentsCreator:
+
+	type MyAnalyzer struct {
+		*analysis.AnalyzerImpl
+	}
+
+	func NewMyAnalyzer() *MyAnalyzer {
+		ans := new(MyAnalyzer)
+		ans.AnalyzerImpl = analysis.NewAnalyzer(ans)
+		return ans
+	}
+
+	func (a *MyAnalyzer) CreateComponents(fieldName string,
+		reader io.Reader) *analysis.TokenStreamComponents {
+		source := NewMyTokenizer(reader)
+		return analysis.NewTokenStreamComponents(source, NewMyFilter(source))
+	}
+*/
+type Analyzer interface {
+	/*
+		Returns a TokenStream suitable for fieldName, tokenizing the
+		contents of reader.
+
+		This method uses the ReuseStrategy of the analyzer to reuse the
+		components created for a previous call, once its TokenStream is
+		closed; the TokenStream must therefore be closed by the
+		consumer, and not used anymore after that. Streams which are
+		not closed are not reused, so concurrent calls always get
+		distinct components.
+	*/
+	TokenStream(fieldName string, reader io.Reader) (TokenStream, error)
+	/*
+		Invoked before indexing an instance of a field, other than the
+		first one of the document. This allows custom analyzers to place
+		an automatic position increment gap between instances, so that
+		e.g. phrase queries don't match across them. The default is 0.
+	*/
+	PositionIncrementGap(fieldName string) int
+	/*
+		Just like PositionIncrementGap(), except for token offsets
+		instead. By default this returns 1, so the offsets of the
+		instances of a field don't overlap.
+	*/
+	OffsetGap(fieldName string) int
+	// Frees persistent resources used by this Analyzer.
+	Close() error
+}
+
+// Creates the components of an Analyzer.
+type ComponentsCreator interface {
+	// Creates a new TokenStreamComponents instance for this analyzer.
+	CreateComponents(fieldName string, reader io.Reader) *TokenStreamComponents
+}
+
+// Embeddable implementation of Analyzer, which creates and reuses the
+// TokenStreamComponents of a ComponentsCreator.
+type AnalyzerImpl struct {
+	creator       ComponentsCreator
+	reuseStrategy ReuseStrategy
+	// the reuse slots which are not in use
+	lock   sync.Mutex
+	free   []*reuseSlot
+	closed bool
+}
+
+/*
+The state kept for a consumer of an Analyzer, as Lucene keeps it per
+thread: the stored value of the ReuseStrategy, and the TokenStream
+handed out to the consumer, which are both reused by the next
+consumer once the stream is closed. Since the slots are reused, a
+goroutine analyzing one document after another allocates nothing but
+what its Tokenizer reads.
+*/
+type reuseSlot struct {
+	analyzer    *AnalyzerImpl
+	storedValue interface{}
+	stream      reusableTokenStream
+	inUse       bool
+}
+
+// Create a new Analyzer, reusing the same set of components for every
+// field across calls to TokenStream().
+func NewAnalyzer(creator ComponentsCreator) *AnalyzerImpl {
+	return NewAnalyzerWithStrategy(creator, GLOBAL_REUSE_STRATEGY)
+}
+
+// Expert: create a new Analyzer with a custom ReuseStrategy.
+func NewAnalyzerWithStrategy(creator ComponentsCreator, reuseStrategy ReuseStrategy) *AnalyzerImpl {
+	return &AnalyzerImpl{creator: creator, reuseStrategy: reuseStrategy}
+}
+
+func (a *AnalyzerImpl) TokenStream(fieldName string, reader io.Reader) (TokenStream, error) {
+	slot, components, err := a.takeSlot(fieldName)
+	if err != nil {
+		return nil, err
+	}
+	if components == nil {
+		components = a.creator.CreateComponents(fieldName, reader)
+		slot.storedValue = a.reuseStrategy.SetReusableComponents(slot.storedValue, fieldName, components)
+	} else if err = components.SetReader(reader); err != nil {
+		a.releaseSlot(slot)
+		return nil, err
+	}
+	slot.stream.TokenStream = components.TokenStream()
+	return &slot.stream, nil
+}
+
+/*
+Takes a reuse slot not in use, preferably one with reusable components
+for the field, which are returned as well, or a new slot if there is
+none. Thus the fields of documents analyzed one after another keep
+reusing their components, even with PER_FIELD_REUSE_STRATEGY.
+*/
+func (a *AnalyzerImpl) takeSlot(fieldName string) (*reuseSlot, *TokenStreamComponents, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.closed {
+		return nil, nil, errors.New("this Analyzer is closed")
+	}
+	n := len(a.free)
+	if n == 0 {
+		ans := &reuseSlot{analyzer: a, inUse: true}
+		ans.stream.slot = ans
+		return ans, nil, nil
+	}
+	i := n - 1
+	components := a.reuseStrategy.ReusableComponents(a.free[i].storedValue, fieldName)
+	for j := n - 2; j >= 0 && components == nil; j-- {
+		if components = a.reuseStrategy.ReusableComponents(a.free[j].storedValue, fieldName); components != nil {
+			i = j
+		}
+	}
+	ans := a.free[i]
+	copy(a.free[i:], a.free[i+1:])
+	a.free[n-1] = nil
+	a.free = a.free[:n-1]
+	ans.inUse = true
+	return ans, components, nil
+}
+
+// Makes a reuse slot available to the next calls of TokenStream().
+func (a *AnalyzerImpl) releaseSlot(slot *reuseSlot) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if !slot.inUse {
+		return
+	}
+	slot.inUse = false
+	if !a.closed && slot.storedValue != nil {
+		a.free = append(a.free, slot)
+	}
+}
+
+func (a *AnalyzerImpl) PositionIncrementGap(fieldName string) int {
+	return 0
+}
+
+func (a *AnalyzerImpl) OffsetGap(fieldName string) int {
+	return 1
+}
+
+// Returns the used ReuseStrategy.
+func (a *AnalyzerImpl) ReuseStrategy() ReuseStrategy {
+	return a.reuseStrategy
+}
+
+func (a *AnalyzerImpl) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.closed = true
+	a.free = nil
+	return nil
+}
+
+// The TokenStream returned by Analyzer.TokenStream(), which makes its
+// components reusable once closed.
+type reusableTokenStream struct {
+	TokenStream
+	slot *reuseSlot
+}
+
+func (ts *reusableTokenStream) Close() error {
+	err := ts.TokenStream.Close()
+	ts.slot.analyzer.releaseSlot(ts.slot)
+	return err
+}
+
+// analysis/Analyzer.java/TokenStreamComponents
+
+/*
+This class encapsulates the outer components of a token stream. It
+provides access to the source (Tokenizer) and the outer end (sink),
+an instance of TokenFilter which also serves as the TokenStream
+returned by Analyzer.TokenStream().
+*/
+type TokenStreamComponents struct {
+	// Original source of the tokens.
+	source Tokenizer
+	// Sink tokenstream, such as the outer tokenfilter decorating the
+	// chain. This can be the source if there are no filters.
+	sink TokenStream
+	// Resets the components with a new reader, if not the default.
+	setReader func(reader io.Reader) error
+}
+
+// Creates a new TokenStreamComponents instance. If result is nil,
+// the source is used as the sink.
+func NewTokenStreamComponents(source Tokenizer, result TokenStream) *TokenStreamComponents {
+	if result == nil {
+		result = source
+	}
+	return &TokenStreamComponents{source: source, sink: result}
+}
+
+/*
+Creates a new TokenStreamComponents instance, which calls setReader
+to reset the components with a new reader, instead of only setting
+the reader of the source. This allows reused components to pick up
+the current settings of their analyzer.
+*/
+func NewTokenStreamComponentsWith(source Tokenizer, result TokenStream,
+	setReader func(reader io.Reader) error) *TokenStreamComponents {
+	ans := NewTokenStreamComponents(source, result)
+	ans.setReader = setReader
+	return ans
+}
+
+/*
+Resets the encapsulated components with the given reader. If the
+components cannot be reset, an error should be returned.
+*/
+func (c *TokenStreamComponents) SetReader(reader io.Reader) error {
+	if c.setReader != nil {
+		return c.setReader(reader)
+	}
+	return c.source.SetReader(reader)
+}
+
+// Returns the sink TokenStream.
+func (c *TokenStreamComponents) TokenStream() TokenStream {
+	return c.sink
+}
+
+// Returns the component's Tokenizer.
+func (c *TokenStreamComponents) Tokenizer() Tokenizer {
+	return c.source
+}
+
+// analysis/Analyzer.java/ReuseStrategy
+
+/*
+Strategy defining how TokenStreamComponents are reused per call to
+Analyzer.TokenStream().
+
+The analyzer keeps one stored value per concurrent consumer, and
+hands it to the strategy, which records the components it reuses in
+it. A nil stored value is the initial state.
+*/
+type ReuseStrategy interface {
+	/*
+		Gets the reusable TokenStreamComponents for the field with the
+		given name, from the stored value. Returns nil if there are no
+		reusable components for the field.
+	*/
+	ReusableComponents(storedValue interface{}, fieldName string) *TokenStreamComponents
+	/*
+		Records the TokenStreamComponents of the field with the given
+		name in the stored value, returning the new stored value.
+	*/
+	SetReusableComponents(storedValue interface{}, fieldName string,
+		components *TokenStreamComponents) interface{}
+}
+
+// analysis/Analyzer.java/GlobalReuseStrategy
+
+// A predefined ReuseStrategy that reuses the same components for
+// every field.
+var GLOBAL_REUSE_STRATEGY ReuseStrategy = globalReuseStrategy{}
+
+type globalReuseStrategy struct{}
+
+func (s globalReuseStrategy) ReusableComponents(storedValue interface{},
+	fieldName string) *TokenStreamComponents {
+	if storedValue == nil {
+		return nil
+	}
+	return storedValue.(*TokenStreamComponents)
+}
+
+func (s globalReuseStrategy) SetReusableComponents(storedValue interface{},
+	fieldName string, components *TokenStreamComponents) interface{} {
+	return components
+}
+
+// analysis/Analyzer.java/PerFieldReuseStrategy
+
+// A predefined ReuseStrategy that reuses components per field by
+// maintaining a map of TokenStreamComponents per field name.
+var PER_FIELD_REUSE_STRATEGY ReuseStrategy = perFieldReuseStrategy{}
+
+type perFieldReuseStrategy struct{}
+
+func (s perFieldReuseStrategy) ReusableComponents(storedValue interface{},
+	fieldName string) *TokenStreamComponents {
+	if storedValue == nil {
+		return nil
+	}
+	return storedValue.(map[string]*TokenStreamComponents)[fieldName]
+}
+
+func (s perFieldReuseStrategy) SetReusableComponents(storedValue interface{},
+	fieldName string, components *TokenStreamComponents) interface{} {
+	componentsPerField, _ := storedValue.(map[string]*TokenStreamComponents)
+	if componentsPerField == nil {
+		componentsPerField = make(map[string]*TokenStreamComponents)
+	}
+	componentsPerField[fieldName] = components
+	return componentsPerField
+}
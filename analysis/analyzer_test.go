@@ -0,0 +1,301 @@
+package analysis
+
+import (
+	"github.com/balzaczyy/golucene/util"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// Splits its input at spaces.
+type testWhitespaceTokenizer struct {
+	*TokenizerImpl
+	termAtt   CharTermAttribute
+	offsetAtt OffsetAttribute
+	text      []byte
+	offset    int
+}
+
+func newTestWhitespaceTokenizer(input io.Reader) *testWhitespaceTokenizer {
+	ans := &testWhitespaceTokenizer{TokenizerImpl: NewTokenizer(input)}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(OffsetAttribute)
+	return ans
+}
+
+func (t *testWhitespaceTokenizer) Reset() (err error) {
+	t.text, err = ioutil.ReadAll(t.Input)
+	t.offset = 0
+	return err
+}
+
+func (t *testWhitespaceTokenizer) IncrementToken() (bool, error) {
+	for t.offset < len(t.text) && t.text[t.offset] == ' ' {
+		t.offset++
+	}
+	if t.offset == len(t.text) {
+		return false, nil
+	}
+	start := t.offset
+	for t.offset < len(t.text) && t.text[t.offset] != ' ' {
+		t.offset++
+	}
+	t.Attributes().Clear()
+	t.termAtt.AppendBytes(t.text[start:t.offset])
+	t.offsetAtt.SetOffset(start, t.offset)
+	return true, nil
+}
+
+func (t *testWhitespaceTokenizer) End() error {
+	t.offsetAtt.SetOffset(len(t.text), len(t.text))
+	return nil
+}
+
+// Removes the tokens shorter than min, leaving holes in their place.
+type testMinLengthFilter struct {
+	*TokenFilter
+	termAtt    CharTermAttribute
+	posIncrAtt PositionIncrementAttribute
+	min        int
+}
+
+func newTestMinLengthFilter(input TokenStream, min int) *testMinLengthFilter {
+	ans := &testMinLengthFilter{TokenFilter: NewTokenFilter(input), min: min}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	ans.posIncrAtt = ans.Attributes().Add("PositionIncrementAttribute").(PositionIncrementAttribute)
+	return ans
+}
+
+func (f *testMinLengthFilter) IncrementToken() (bool, error) {
+	skipped := 0
+	for {
+		ok, err := f.Input.IncrementToken()
+		if err != nil || !ok {
+			return ok, err
+		}
+		if f.termAtt.Length() >= f.min {
+			f.posIncrAtt.SetPositionIncrement(f.posIncrAtt.PositionIncrement() + skipped)
+			return true, nil
+		}
+		skipped += f.posIncrAtt.PositionIncrement()
+	}
+}
+
+// Emits each token twice, the copy at the same position, typed "copy".
+type testDuplicateFilter struct {
+	*TokenFilter
+	posIncrAtt PositionIncrementAttribute
+	typeAtt    TypeAttribute
+	state      *util.AttributeState
+}
+
+func newTestDuplicateFilter(input TokenStream) *testDuplicateFilter {
+	ans := &testDuplicateFilter{TokenFilter: NewTokenFilter(input)}
+	ans.posIncrAtt = ans.Attributes().Add("PositionIncrementAttribute").(PositionIncrementAttribute)
+	ans.typeAtt = ans.Attributes().Add("TypeAttribute").(TypeAttribute)
+	return ans
+}
+
+func (f *testDuplicateFilter) IncrementToken() (bool, error) {
+	if f.state != nil {
+		f.Attributes().RestoreState(f.state)
+		f.state = nil
+		f.posIncrAtt.SetPositionIncrement(0)
+		f.typeAtt.SetType("copy")
+		return true, nil
+	}
+	ok, err := f.Input.IncrementToken()
+	if ok {
+		f.state = f.Attributes().CaptureState()
+	}
+	return ok, err
+}
+
+func (f *testDuplicateFilter) Reset() error {
+	f.state = nil
+	return f.TokenFilter.Reset()
+}
+
+type testAnalyzer struct {
+	*AnalyzerImpl
+	numCreated int
+}
+
+func newTestAnalyzer() *testAnalyzer {
+	return newTestAnalyzerWithStrategy(GLOBAL_REUSE_STRATEGY)
+}
+
+func newTestAnalyzerWithStrategy(reuseStrategy ReuseStrategy) *testAnalyzer {
+	ans := new(testAnalyzer)
+	ans.AnalyzerImpl = NewAnalyzerWithStrategy(ans, reuseStrategy)
+	return ans
+}
+
+func (a *testAnalyzer) CreateComponents(fieldName string, reader io.Reader) *TokenStreamComponents {
+	a.numCreated++
+	source := newTestWhitespaceTokenizer(reader)
+	return NewTokenStreamComponents(source, newTestMinLengthFilter(source, 2))
+}
+
+type testToken struct {
+	term                   string
+	posIncr                int
+	startOffset, endOffset int
+	typ                    string
+}
+
+func assertTokenStream(t *testing.T, ts TokenStream, expected []testToken, finalOffset int) {
+	termAtt := ts.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	posIncrAtt := ts.Attributes().Add("PositionIncrementAttribute").(PositionIncrementAttribute)
+	offsetAtt := ts.Attributes().Add("OffsetAttribute").(OffsetAttribute)
+	typeAtt := ts.Attributes().Add("TypeAttribute").(TypeAttribute)
+	if err := ts.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range expected {
+		ok, err := ts.IncrementToken()
+		if err != nil || !ok {
+			t.Fatalf("Expected token %v (%v)", e.term, err)
+		}
+		actual := testToken{termAtt.String(), posIncrAtt.PositionIncrement(),
+			offsetAtt.StartOffset(), offsetAtt.EndOffset(), typeAtt.Type()}
+		if e.typ == "" {
+			e.typ = DEFAULT_TOKEN_TYPE
+		}
+		if actual != e {
+			t.Errorf("Expected %v, but was %v", e, actual)
+		}
+	}
+	if ok, err := ts.IncrementToken(); ok || err != nil {
+		t.Errorf("Stream should be exhausted, but was %v (%v)", termAtt, err)
+	}
+	if err := ts.End(); err != nil {
+		t.Fatal(err)
+	}
+	if offset := offsetAtt.EndOffset(); offset != finalOffset {
+		t.Errorf("Expected final offset %v, but was %v", finalOffset, offset)
+	}
+	if err := ts.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	a := newTestAnalyzer()
+	ts, err := a.TokenStream("body", strings.NewReader("the a quick  b c fox "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTokenStream(t, ts, []testToken{
+		{"the", 1, 0, 3, ""},
+		{"quick", 2, 6, 11, ""},
+		{"fox", 3, 17, 20, ""},
+	}, 21)
+
+	// the components of a closed stream are reused
+	ts, err = a.TokenStream("title", strings.NewReader("go lucene"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.numCreated != 1 {
+		t.Errorf("Components should be reused, but %v were created", a.numCreated)
+	}
+	// while the stream is in use, new components are created
+	ts2, err := a.TokenStream("title", strings.NewReader("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.numCreated != 2 {
+		t.Errorf("Components in use should not be reused, but %v were created", a.numCreated)
+	}
+	assertTokenStream(t, ts2, nil, 1)
+	assertTokenStream(t, ts, []testToken{{"go", 1, 0, 2, ""}, {"lucene", 1, 3, 9, ""}}, 9)
+	assertEquals(t, 0, a.PositionIncrementGap("body"))
+	assertEquals(t, 1, a.OffsetGap("body"))
+
+	if err = a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = a.TokenStream("body", strings.NewReader("x")); err == nil {
+		t.Error("Closed analyzer should not create streams")
+	}
+}
+
+func TestPerFieldReuseStrategy(t *testing.T) {
+	a := newTestAnalyzerWithStrategy(PER_FIELD_REUSE_STRATEGY)
+	if a.ReuseStrategy() != PER_FIELD_REUSE_STRATEGY {
+		t.Error("Wrong reuse strategy")
+	}
+	analyze := func(fieldName, text string) TokenStream {
+		ts, err := a.TokenStream(fieldName, strings.NewReader(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	assertTokenStream(t, analyze("body", "quick fox"), []testToken{{"quick", 1, 0, 5, ""}, {"fox", 1, 6, 9, ""}}, 9)
+	// each field has its own components
+	assertTokenStream(t, analyze("title", "go lucene"), []testToken{{"go", 1, 0, 2, ""}, {"lucene", 1, 3, 9, ""}}, 9)
+	assertEquals(t, 2, a.numCreated)
+	for i := 0; i < 3; i++ {
+		assertTokenStream(t, analyze("body", "x brown"), []testToken{{"brown", 2, 2, 7, ""}}, 7)
+		assertTokenStream(t, analyze("title", "lazy dog"), []testToken{{"lazy", 1, 0, 4, ""}, {"dog", 1, 5, 8, ""}}, 8)
+	}
+	assertEquals(t, 2, a.numCreated)
+
+	// the streams of a document in use at the same time are analyzed
+	// with distinct components, which are all reused afterwards
+	for i := 0; i < 3; i++ {
+		body, title := analyze("body", "jumps over"), analyze("title", "dog")
+		assertTokenStream(t, body, []testToken{{"jumps", 1, 0, 5, ""}, {"over", 1, 6, 10, ""}}, 10)
+		assertTokenStream(t, title, []testToken{{"dog", 1, 0, 3, ""}}, 3)
+	}
+	assertEquals(t, 3, a.numCreated)
+}
+
+func TestReuseStreamClosedTwice(t *testing.T) {
+	a := newTestAnalyzer()
+	ts, err := a.TokenStream("body", strings.NewReader("quick"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTokenStream(t, ts, []testToken{{"quick", 1, 0, 5, ""}}, 5)
+	if err = ts.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// the components are released only once
+	ts1, err := a.TokenStream("body", strings.NewReader("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts2, err := a.TokenStream("body", strings.NewReader("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts1 == ts2 {
+		t.Error("Streams in use should not be shared")
+	}
+	assertEquals(t, 2, a.numCreated)
+}
+
+func TestTokenFilterState(t *testing.T) {
+	ts := newTestDuplicateFilter(newTestMinLengthFilter(
+		newTestWhitespaceTokenizer(strings.NewReader("a quick fox")), 2))
+	// the filters share the attributes of the tokenizer
+	if ts.Attributes() != ts.Input.Attributes() || !ts.Attributes().Has("TypeAttribute") {
+		t.Error("Filters should share the attributes of their input")
+	}
+	assertTokenStream(t, ts, []testToken{
+		{"quick", 2, 2, 7, ""},
+		{"quick", 0, 2, 7, "copy"},
+		{"fox", 1, 8, 11, ""},
+		{"fox", 0, 8, 11, "copy"},
+	}, 11)
+}
+
+func assertEquals(t *testing.T, expected, actual interface{}) {
+	if expected != actual {
+		t.Errorf("Expected %v, but was %v", expected, actual)
+	}
+}
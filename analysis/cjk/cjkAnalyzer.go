@@ -0,0 +1,54 @@
+package cjk
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/core"
+	"github.com/balzaczyy/golucene/analysis/standard"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io"
+)
+
+// analysis/cjk/CJKAnalyzer.java
+
+// The default set of stopwords of CJKAnalyzer, English words, as the
+// CJK stop words are short and would be part of many bigrams. It is
+// shared, and must not be modified.
+var DEFAULT_STOPWORD_SET = util.NewCharArraySetFrom([]string{
+	"a", "and", "are", "as", "at", "be", "but", "by",
+	"for", "if", "in", "into", "is", "it",
+	"no", "not", "of", "on", "or", "s", "such",
+	"t", "that", "the", "their", "then", "there", "these",
+	"they", "this", "to", "was", "will", "with", "www",
+}, false)
+
+/*
+An Analyzer that tokenizes text with StandardTokenizer, normalizes
+content with CJKWidthFilter, folds case with LowerCaseFilter, forms
+bigrams of CJK with CJKBigramFilter, and filters stopwords with
+StopFilter.
+*/
+type CJKAnalyzer struct {
+	*util.StopwordAnalyzerBase
+}
+
+// Builds an analyzer which removes words in DEFAULT_STOPWORD_SET.
+func NewCJKAnalyzer() *CJKAnalyzer {
+	return NewCJKAnalyzerWithStopWords(DEFAULT_STOPWORD_SET)
+}
+
+// Builds an analyzer with the given stop words.
+func NewCJKAnalyzerWithStopWords(stopwords *util.CharArraySet) *CJKAnalyzer {
+	ans := new(CJKAnalyzer)
+	ans.StopwordAnalyzerBase = util.NewStopwordAnalyzerBase(ans, stopwords)
+	return ans
+}
+
+func (a *CJKAnalyzer) CreateComponents(fieldName string, reader io.Reader) *analysis.TokenStreamComponents {
+	source := standard.NewStandardTokenizer(reader)
+	// run the widthfilter first before bigramming, it sometimes
+	// combines characters.
+	var result analysis.TokenStream = NewCJKWidthFilter(source)
+	result = core.NewLowerCaseFilter(result)
+	result = NewCJKBigramFilter(result)
+	return analysis.NewTokenStreamComponents(source, core.NewStopFilter(result, a.StopwordSet()))
+}
@@ -0,0 +1,305 @@
+package cjk
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/standard"
+	"github.com/balzaczyy/golucene/util"
+	"unicode/utf8"
+)
+
+// analysis/cjk/CJKBigramFilter.java
+
+// Flags of the scripts to form bigrams of.
+const (
+	// bigram flag for Han Ideographs
+	HAN = 1 << iota
+	// bigram flag for Hiragana
+	HIRAGANA
+	// bigram flag for Katakana
+	KATAKANA
+	// bigram flag for Hangul
+	HANGUL
+)
+
+// The token types of the tokens of CJKBigramFilter.
+const (
+	// when we emit a bigram, its then marked as this type
+	DOUBLE_TYPE = "<DOUBLE>"
+	// when we emit a unigram, its then marked as this type
+	SINGLE_TYPE = "<SINGLE>"
+)
+
+/*
+Forms bigrams of CJK terms that are generated from StandardTokenizer.
+
+CJK types are set by these tokenizers, but you can also use
+NewCJKBigramFilterWith() to explicitly control which of the CJK
+scripts are turned into bigrams.
+
+By default, when a CJK character has no adjacent characters to form a
+bigram, it is output in unigram form. If you want to always output
+both unigrams and bigrams, set the outputUnigrams flag. This can be
+used for a combined unigram+bigram approach.
+
+In all cases, all non-CJK input is passed thru unmodified.
+*/
+type CJKBigramFilter struct {
+	*analysis.TokenFilter
+
+	doHan, doHiragana, doKatakana, doHangul bool
+
+	// true if we should output unigram tokens always
+	outputUnigrams bool
+	// false if we should output a unigram next, true for a bigram
+	ngramState bool
+
+	termAtt      analysis.CharTermAttribute
+	typeAtt      analysis.TypeAttribute
+	offsetAtt    analysis.OffsetAttribute
+	posIncAtt    analysis.PositionIncrementAttribute
+	posLengthAtt analysis.PositionLengthAttribute
+
+	// buffers containing code points and offsets of the buffered
+	// characters
+	buffer      []rune
+	startOffset []int
+	endOffset   []int
+	// position into the buffers of the next character to output
+	index int
+	// the end offset of the last buffered token
+	lastEndOffset int
+
+	// true if we have consumed the input
+	exhausted bool
+	// the state of a non-CJK or non-adjacent token, to emit after the
+	// buffered unigram
+	loneState *util.AttributeState
+
+	bytes []byte
+}
+
+// Calls NewCJKBigramFilterWith(input, HAN|HIRAGANA|KATAKANA|HANGUL, false)
+func NewCJKBigramFilter(input analysis.TokenStream) *CJKBigramFilter {
+	return NewCJKBigramFilterWith(input, HAN|HIRAGANA|KATAKANA|HANGUL, false)
+}
+
+/*
+Create a new CJKBigramFilter, specifying which writing systems should
+be bigrammed, and whether or not unigrams should also be output.
+flags is the OR'ed set from HAN, HIRAGANA, KATAKANA, HANGUL.
+*/
+func NewCJKBigramFilterWith(input analysis.TokenStream, flags int, outputUnigrams bool) *CJKBigramFilter {
+	ans := &CJKBigramFilter{
+		TokenFilter:    analysis.NewTokenFilter(input),
+		doHan:          flags&HAN != 0,
+		doHiragana:     flags&HIRAGANA != 0,
+		doKatakana:     flags&KATAKANA != 0,
+		doHangul:       flags&HANGUL != 0,
+		outputUnigrams: outputUnigrams,
+	}
+	atts := ans.Attributes()
+	ans.termAtt = atts.Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.typeAtt = atts.Add("TypeAttribute").(analysis.TypeAttribute)
+	ans.offsetAtt = atts.Add("OffsetAttribute").(analysis.OffsetAttribute)
+	ans.posIncAtt = atts.Add("PositionIncrementAttribute").(analysis.PositionIncrementAttribute)
+	ans.posLengthAtt = atts.Add("PositionLengthAttribute").(analysis.PositionLengthAttribute)
+	return ans
+}
+
+/*
+much of this complexity revolves around handling the special case of
+a "lone cjk character" where StandardTokenizer outputs a unigram. We
+have to defer this unigram, to output it only if there is no adjacent
+CJK character to form a bigram with.
+*/
+func (f *CJKBigramFilter) IncrementToken() (bool, error) {
+	for {
+		if f.hasBufferedBigram() {
+			// case 1: we have multiple remaining codepoints buffered,
+			// so we can emit a bigram here.
+			if f.outputUnigrams {
+				// when also outputting unigrams, we output the unigram
+				// first, then rewind back to revisit the bigram.
+				if f.ngramState {
+					f.flushBigram()
+				} else {
+					f.flushUnigram()
+					f.index--
+				}
+				f.ngramState = !f.ngramState
+			} else {
+				f.flushBigram()
+			}
+			return true, nil
+		}
+
+		ok, err := f.doNext()
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			// case 3: input is exhausted; flush a lone unigram, if any
+			if f.hasBufferedUnigram() {
+				f.flushUnigram()
+				return true, nil
+			}
+			return false, nil
+		}
+
+		if !f.isCJKType(f.typeAtt.Type()) {
+			// case 2: not a CJK type, we just return it as-is, after
+			// emitting a lone buffered unigram.
+			if f.hasBufferedUnigram() {
+				f.loneState = f.Attributes().CaptureState()
+				f.flushUnigram()
+				return true, nil
+			}
+			f.clearBuffer()
+			return true, nil
+		}
+
+		// case 4: a CJK token. If it is not adjacent to the buffered
+		// characters, they cannot form a bigram with it.
+		if f.offsetAtt.StartOffset() != f.lastEndOffset {
+			if f.hasBufferedUnigram() {
+				f.loneState = f.Attributes().CaptureState()
+				f.flushUnigram()
+				return true, nil
+			}
+			f.clearBuffer()
+		}
+		f.refill()
+	}
+}
+
+// Restores the lone state if any, or else reads the next token from
+// the input.
+func (f *CJKBigramFilter) doNext() (bool, error) {
+	if f.loneState != nil {
+		f.Attributes().RestoreState(f.loneState)
+		f.loneState = nil
+		return true, nil
+	}
+	if f.exhausted {
+		return false, nil
+	}
+	ok, err := f.Input.IncrementToken()
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		f.exhausted = true
+	}
+	return ok, nil
+}
+
+func (f *CJKBigramFilter) isCJKType(typ string) bool {
+	switch typ {
+	case standard.TOKEN_TYPES[standard.IDEOGRAPHIC]:
+		return f.doHan
+	case standard.TOKEN_TYPES[standard.HIRAGANA]:
+		return f.doHiragana
+	case standard.TOKEN_TYPES[standard.KATAKANA]:
+		return f.doKatakana
+	case standard.TOKEN_TYPES[standard.HANGUL]:
+		return f.doHangul
+	}
+	return false
+}
+
+// Refills buffers with new data from the current token.
+func (f *CJKBigramFilter) refill() {
+	// compact buffers to keep them smallish if they become large
+	// just a safety check, but technically we only need the last
+	// codepoint
+	if f.index > 0 {
+		n := copy(f.buffer, f.buffer[f.index:])
+		copy(f.startOffset, f.startOffset[f.index:])
+		copy(f.endOffset, f.endOffset[f.index:])
+		f.buffer, f.startOffset, f.endOffset = f.buffer[:n], f.startOffset[:n], f.endOffset[:n]
+		f.index = 0
+	}
+
+	term := f.termAtt.Bytes()
+	start, end := f.offsetAtt.StartOffset(), f.offsetAtt.EndOffset()
+	// only use the offsets of the characters if they match the term,
+	// e.g. it was not changed by a previous filter.
+	trust := end-start == len(term)
+	for i := 0; i < len(term); {
+		c, size := utf8.DecodeRune(term[i:])
+		f.buffer = append(f.buffer, c)
+		if trust {
+			f.startOffset = append(f.startOffset, start+i)
+			f.endOffset = append(f.endOffset, start+i+size)
+		} else {
+			f.startOffset = append(f.startOffset, start)
+			f.endOffset = append(f.endOffset, end)
+		}
+		i += size
+	}
+	f.lastEndOffset = end
+}
+
+// Flushes a bigram token to output from our buffer. This is the
+// normal case, e.g. ABC -> AB BC
+func (f *CJKBigramFilter) flushBigram() {
+	f.Attributes().Clear()
+	f.bytes = utf8.AppendRune(utf8.AppendRune(f.bytes[:0], f.buffer[f.index]), f.buffer[f.index+1])
+	f.termAtt.CopyBytes(f.bytes)
+	f.offsetAtt.SetOffset(f.startOffset[f.index], f.endOffset[f.index+1])
+	f.typeAtt.SetType(DOUBLE_TYPE)
+	// when outputting unigrams, all bigrams are synonyms that span
+	// two unigrams
+	if f.outputUnigrams {
+		f.posIncAtt.SetPositionIncrement(0)
+		f.posLengthAtt.SetPositionLength(2)
+	}
+	f.index++
+}
+
+/*
+Flushes a unigram token to output from our buffer. This happens when
+we encounter isolated CJK characters, either the whole CJK string is
+a single character, or we encounter a CJK character surrounded by
+space, punctuation, english, etc, but not beside any other CJK.
+*/
+func (f *CJKBigramFilter) flushUnigram() {
+	f.Attributes().Clear()
+	f.bytes = utf8.AppendRune(f.bytes[:0], f.buffer[f.index])
+	f.termAtt.CopyBytes(f.bytes)
+	f.offsetAtt.SetOffset(f.startOffset[f.index], f.endOffset[f.index])
+	f.typeAtt.SetType(SINGLE_TYPE)
+	f.index++
+}
+
+// True if we have multiple codepoints sitting in our buffer
+func (f *CJKBigramFilter) hasBufferedBigram() bool {
+	return len(f.buffer)-f.index > 1
+}
+
+/*
+True if we have a single codepoint sitting in our buffer, where its
+future (whether it is emitted as unigram or forms a bigram) depends
+upon not-yet-seen inputs.
+*/
+func (f *CJKBigramFilter) hasBufferedUnigram() bool {
+	if f.outputUnigrams {
+		// when outputting unigrams always
+		return len(f.buffer)-f.index == 1
+	}
+	// otherwise its only when we have a lone CJK character
+	return len(f.buffer) == 1 && f.index == 0
+}
+
+// Discards the buffered characters.
+func (f *CJKBigramFilter) clearBuffer() {
+	f.buffer, f.startOffset, f.endOffset = f.buffer[:0], f.startOffset[:0], f.endOffset[:0]
+	f.index = 0
+}
+
+func (f *CJKBigramFilter) Reset() error {
+	f.clearBuffer()
+	f.lastEndOffset = 0
+	f.loneState, f.exhausted, f.ngramState = nil, false, false
+	return f.TokenFilter.Reset()
+}
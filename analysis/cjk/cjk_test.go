@@ -0,0 +1,68 @@
+package cjk
+
+import (
+	"github.com/balzaczyy/golucene/analysis/analysistest"
+	"github.com/balzaczyy/golucene/analysis/core"
+	"github.com/balzaczyy/golucene/analysis/standard"
+	"strings"
+	"testing"
+)
+
+func TestCJKWidthFilter(t *testing.T) {
+	input := "ＴｅｓｔＩｎｇ １２３４ ｶﾀｶﾅ ｳﾞｨｯﾂ ﾊﾟﾅｿﾆｯｸ ﾞ"
+	ts := NewCJKWidthFilter(core.NewWhitespaceTokenizer(strings.NewReader(input)))
+	analysistest.AssertTokenStreamContents(t, ts,
+		[]string{"TestIng", "1234", "カタカナ", "ヴィッツ", "パナソニック", "゙"},
+		[]int{0, 22, 35, 48, 64, 86}, []int{21, 34, 47, 63, 85, 89}, nil, nil, nil, len(input))
+}
+
+func TestCJKBigramFilter(t *testing.T) {
+	input := "多くの学生が試験に落ちた。"
+	ts := NewCJKBigramFilter(standard.NewStandardTokenizer(strings.NewReader(input)))
+	analysistest.AssertTokenStreamContents(t, ts,
+		[]string{"多く", "くの", "の学", "学生", "生が", "が試", "試験", "験に", "に落", "落ち", "ちた"},
+		[]int{0, 3, 6, 9, 12, 15, 18, 21, 24, 27, 30}, []int{6, 9, 12, 15, 18, 21, 24, 27, 30, 33, 36},
+		[]string{"<DOUBLE>", "<DOUBLE>", "<DOUBLE>", "<DOUBLE>", "<DOUBLE>", "<DOUBLE>",
+			"<DOUBLE>", "<DOUBLE>", "<DOUBLE>", "<DOUBLE>", "<DOUBLE>"},
+		[]int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, nil, len(input))
+}
+
+func TestCJKBigramFilterUnigrams(t *testing.T) {
+	input := "多くの学生"
+	ts := NewCJKBigramFilterWith(standard.NewStandardTokenizer(strings.NewReader(input)),
+		HAN|HIRAGANA|KATAKANA|HANGUL, true)
+	analysistest.AssertTokenStreamContents(t, ts,
+		[]string{"多", "多く", "く", "くの", "の", "の学", "学", "学生", "生"},
+		[]int{0, 0, 3, 3, 6, 6, 9, 9, 12}, []int{3, 6, 6, 9, 9, 12, 12, 15, 15},
+		[]string{"<SINGLE>", "<DOUBLE>", "<SINGLE>", "<DOUBLE>", "<SINGLE>", "<DOUBLE>",
+			"<SINGLE>", "<DOUBLE>", "<SINGLE>"},
+		[]int{1, 0, 1, 0, 1, 0, 1, 0, 1}, []int{1, 2, 1, 2, 1, 2, 1, 2, 1}, len(input))
+}
+
+func TestCJKBigramFilterHanOnly(t *testing.T) {
+	input := "多くの学生が試験に落ちた。"
+	ts := NewCJKBigramFilterWith(standard.NewStandardTokenizer(strings.NewReader(input)), HAN, false)
+	analysistest.AssertTokenStreamContents(t, ts,
+		[]string{"多", "く", "の", "学生", "が", "試験", "に", "落", "ち", "た"},
+		[]int{0, 3, 6, 9, 15, 18, 24, 27, 30, 33}, []int{3, 6, 9, 15, 18, 24, 27, 30, 33, 36},
+		[]string{"<SINGLE>", "<HIRAGANA>", "<HIRAGANA>", "<DOUBLE>", "<HIRAGANA>", "<DOUBLE>",
+			"<HIRAGANA>", "<SINGLE>", "<HIRAGANA>", "<HIRAGANA>"},
+		nil, nil, len(input))
+}
+
+func TestCJKAnalyzer(t *testing.T) {
+	a := NewCJKAnalyzer()
+	analysistest.AssertAnalyzesTo(t, a, "一二三四五六七八九十",
+		[]string{"一二", "二三", "三四", "四五", "五六", "六七", "七八", "八九", "九十"},
+		[]int{0, 3, 6, 9, 12, 15, 18, 21, 24}, []int{6, 9, 12, 15, 18, 21, 24, 27, 30}, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "一 二三四 五六七八九 十",
+		[]string{"一", "二三", "三四", "五六", "六七", "七八", "八九", "十"},
+		[]int{0, 4, 7, 14, 17, 20, 23, 30}, []int{3, 10, 13, 20, 23, 26, 29, 33},
+		[]string{"<SINGLE>", "<DOUBLE>", "<DOUBLE>", "<DOUBLE>", "<DOUBLE>", "<DOUBLE>", "<DOUBLE>", "<SINGLE>"}, nil)
+	analysistest.AssertAnalyzesTo(t, a, "The 一 Quick二 brown 三四",
+		[]string{"一", "quick", "二", "brown", "三四"},
+		nil, nil, []string{"<SINGLE>", "<ALPHANUM>", "<SINGLE>", "<ALPHANUM>", "<DOUBLE>"}, []int{2, 1, 1, 1, 1})
+	analysistest.AssertAnalyzesTo(t, a, "안녕하세요 한글입니다",
+		[]string{"안녕", "녕하", "하세", "세요", "한글", "글입", "입니", "니다"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "ＴＥＳＴ ｶﾀｶﾅ", []string{"test", "カタ", "タカ", "カナ"}, nil, nil, nil, nil)
+}
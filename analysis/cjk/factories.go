@@ -0,0 +1,72 @@
+package cjk
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+)
+
+func init() {
+	util.RegisterTokenFilterFactory("cjkwidth", func(args map[string]string) (util.TokenFilterFactory, error) {
+		return NewCJKWidthFilterFactory(args)
+	})
+	util.RegisterTokenFilterFactory("cjkbigram", func(args map[string]string) (util.TokenFilterFactory, error) {
+		return NewCJKBigramFilterFactory(args)
+	})
+}
+
+// analysis/cjk/CJKWidthFilterFactory.java
+
+// Factory for CJKWidthFilter.
+type CJKWidthFilterFactory struct {
+	*util.AbstractAnalysisFactory
+}
+
+// Creates a new CJKWidthFilterFactory, which takes no arguments.
+func NewCJKWidthFilterFactory(args map[string]string) (*CJKWidthFilterFactory, error) {
+	ans := &CJKWidthFilterFactory{util.NewAbstractAnalysisFactory(args)}
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *CJKWidthFilterFactory) Create(input analysis.TokenStream) analysis.TokenStream {
+	return NewCJKWidthFilter(input)
+}
+
+// analysis/cjk/CJKBigramFilterFactory.java
+
+/*
+Factory for CJKBigramFilter.
+
+It takes the boolean arguments han, hiragana, katakana and hangul,
+which are all true by default, to select the scripts to form bigrams
+of, and outputUnigrams, false by default.
+*/
+type CJKBigramFilterFactory struct {
+	*util.AbstractAnalysisFactory
+	flags          int
+	outputUnigrams bool
+}
+
+// Creates a new CJKBigramFilterFactory.
+func NewCJKBigramFilterFactory(args map[string]string) (*CJKBigramFilterFactory, error) {
+	ans := &CJKBigramFilterFactory{AbstractAnalysisFactory: util.NewAbstractAnalysisFactory(args)}
+	for _, v := range []struct {
+		name string
+		flag int
+	}{{"han", HAN}, {"hiragana", HIRAGANA}, {"katakana", KATAKANA}, {"hangul", HANGUL}} {
+		if ans.GetBoolean(v.name, true) {
+			ans.flags |= v.flag
+		}
+	}
+	ans.outputUnigrams = ans.GetBoolean("outputUnigrams", false)
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *CJKBigramFilterFactory) Create(input analysis.TokenStream) analysis.TokenStream {
+	return NewCJKBigramFilterWith(input, f.flags, f.outputUnigrams)
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"github.com/balzaczyy/golucene/analysis/analysistest"
+	"strings"
+	"testing"
+)
+
+func TestWhitespaceAnalyzer(t *testing.T) {
+	a := NewWhitespaceAnalyzer()
+	analysistest.AssertAnalyzesTo(t, a, "foo bar FOO BAR", []string{"foo", "bar", "FOO", "BAR"},
+		[]int{0, 4, 8, 12}, []int{3, 7, 11, 15}, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, " \tfoo bar .　FOO <> BAR \n",
+		[]string{"foo bar", ".", "FOO", "<>", "BAR"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "", nil, nil, nil, nil, nil)
+}
+
+func TestSimpleAnalyzer(t *testing.T) {
+	a := NewSimpleAnalyzer()
+	analysistest.AssertAnalyzesTo(t, a, "foo bar FOO BAR", []string{"foo", "bar", "foo", "bar"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "foo      bar .  FOO <> BAR", []string{"foo", "bar", "foo", "bar"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "foo.bar.FOO.BAR", []string{"foo", "bar", "foo", "bar"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "U.S.A.", []string{"u", "s", "a"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "C++", []string{"c"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "B2B", []string{"b", "b"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "2B", []string{"b"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "\"QUOTED\" wÖrd", []string{"quoted", "wörd"},
+		[]int{1, 9}, []int{7, 14}, nil, nil)
+}
+
+func TestKeywordAnalyzer(t *testing.T) {
+	a := NewKeywordAnalyzer()
+	analysistest.AssertAnalyzesTo(t, a, "Q36 B-2 ", []string{"Q36 B-2 "}, []int{0}, []int{8}, nil, nil)
+	// reused components emit the new input
+	analysistest.AssertAnalyzesTo(t, a, "abc", []string{"abc"}, []int{0}, []int{3}, nil, nil)
+}
+
+func TestCharTokenizerMaxWordLength(t *testing.T) {
+	input := strings.Repeat("a", 300) + " b"
+	analysistest.AssertTokenStreamContents(t, NewLowerCaseTokenizer(strings.NewReader(input)),
+		[]string{strings.Repeat("a", 255), strings.Repeat("a", 45), "b"},
+		[]int{0, 255, 301}, []int{255, 300, 302}, nil, nil, nil, 302)
+}
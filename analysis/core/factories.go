@@ -0,0 +1,196 @@
+package core
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io"
+)
+
+func init() {
+	util.RegisterTokenizerFactory("whitespace", func(args map[string]string) (util.TokenizerFactory, error) {
+		return NewWhitespaceTokenizerFactory(args)
+	})
+	util.RegisterTokenizerFactory("letter", func(args map[string]string) (util.TokenizerFactory, error) {
+		return NewLetterTokenizerFactory(args)
+	})
+	util.RegisterTokenizerFactory("lowercase", func(args map[string]string) (util.TokenizerFactory, error) {
+		return NewLowerCaseTokenizerFactory(args)
+	})
+	util.RegisterTokenizerFactory("keyword", func(args map[string]string) (util.TokenizerFactory, error) {
+		return NewKeywordTokenizerFactory(args)
+	})
+	util.RegisterTokenFilterFactory("lowercase", func(args map[string]string) (util.TokenFilterFactory, error) {
+		return NewLowerCaseFilterFactory(args)
+	})
+	util.RegisterTokenFilterFactory("stop", func(args map[string]string) (util.TokenFilterFactory, error) {
+		return NewStopFilterFactory(args)
+	})
+}
+
+// analysis/core/WhitespaceTokenizerFactory.java
+
+// Factory for WhitespaceTokenizer.
+type WhitespaceTokenizerFactory struct {
+	*util.AbstractAnalysisFactory
+}
+
+// Creates a new WhitespaceTokenizerFactory, which takes no arguments.
+func NewWhitespaceTokenizerFactory(args map[string]string) (*WhitespaceTokenizerFactory, error) {
+	ans := &WhitespaceTokenizerFactory{util.NewAbstractAnalysisFactory(args)}
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *WhitespaceTokenizerFactory) Create(input io.Reader) analysis.Tokenizer {
+	return NewWhitespaceTokenizer(input)
+}
+
+// analysis/core/LetterTokenizerFactory.java
+
+// Factory for LetterTokenizer.
+type LetterTokenizerFactory struct {
+	*util.AbstractAnalysisFactory
+}
+
+// Creates a new LetterTokenizerFactory, which takes no arguments.
+func NewLetterTokenizerFactory(args map[string]string) (*LetterTokenizerFactory, error) {
+	ans := &LetterTokenizerFactory{util.NewAbstractAnalysisFactory(args)}
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *LetterTokenizerFactory) Create(input io.Reader) analysis.Tokenizer {
+	return NewLetterTokenizer(input)
+}
+
+// analysis/core/LowerCaseTokenizerFactory.java
+
+// Factory for LowerCaseTokenizer.
+type LowerCaseTokenizerFactory struct {
+	*util.AbstractAnalysisFactory
+}
+
+// Creates a new LowerCaseTokenizerFactory, which takes no arguments.
+func NewLowerCaseTokenizerFactory(args map[string]string) (*LowerCaseTokenizerFactory, error) {
+	ans := &LowerCaseTokenizerFactory{util.NewAbstractAnalysisFactory(args)}
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *LowerCaseTokenizerFactory) Create(input io.Reader) analysis.Tokenizer {
+	return NewLowerCaseTokenizer(input)
+}
+
+// analysis/core/KeywordTokenizerFactory.java
+
+// Factory for KeywordTokenizer.
+type KeywordTokenizerFactory struct {
+	*util.AbstractAnalysisFactory
+}
+
+// Creates a new KeywordTokenizerFactory, which takes no arguments.
+func NewKeywordTokenizerFactory(args map[string]string) (*KeywordTokenizerFactory, error) {
+	ans := &KeywordTokenizerFactory{util.NewAbstractAnalysisFactory(args)}
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *KeywordTokenizerFactory) Create(input io.Reader) analysis.Tokenizer {
+	return NewKeywordTokenizer(input)
+}
+
+// analysis/core/LowerCaseFilterFactory.java
+
+// Factory for LowerCaseFilter.
+type LowerCaseFilterFactory struct {
+	*util.AbstractAnalysisFactory
+}
+
+// Creates a new LowerCaseFilterFactory, which takes no arguments.
+func NewLowerCaseFilterFactory(args map[string]string) (*LowerCaseFilterFactory, error) {
+	ans := &LowerCaseFilterFactory{util.NewAbstractAnalysisFactory(args)}
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *LowerCaseFilterFactory) Create(input analysis.TokenStream) analysis.TokenStream {
+	return NewLowerCaseFilter(input)
+}
+
+// analysis/core/StopFilterFactory.java
+
+/*
+Factory for StopFilter.
+
+It takes the arguments:
+
+  - words: the comma separated paths of the files of stop words, or
+    else ENGLISH_STOP_WORDS_SET is used
+  - format: the format of the files, "wordset" (the default, one word
+    per line) or "snowball"
+  - ignoreCase: whether the stop words are matched regardless of
+    their case, false by default
+  - enablePositionIncrements: whether holes are left at the positions
+    of the removed words, true by default
+*/
+type StopFilterFactory struct {
+	*util.AbstractAnalysisFactory
+	stopWords                *util.CharArraySet
+	ignoreCase               bool
+	enablePositionIncrements bool
+}
+
+// Creates a new StopFilterFactory, loading the files of stop words.
+func NewStopFilterFactory(args map[string]string) (*StopFilterFactory, error) {
+	ans := &StopFilterFactory{AbstractAnalysisFactory: util.NewAbstractAnalysisFactory(args)}
+	files := ans.GetSet("words")
+	format := ans.Get("format", "")
+	ans.ignoreCase = ans.GetBoolean("ignoreCase", false)
+	ans.enablePositionIncrements = ans.GetBoolean("enablePositionIncrements", true)
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	if files != nil {
+		var err error
+		if ans.stopWords, err = ans.LoadWordSet(files, format, ans.ignoreCase); err != nil {
+			return nil, err
+		}
+	} else if ans.ignoreCase {
+		ans.stopWords = util.NewCharArraySetFrom(ENGLISH_STOP_WORDS_SET.Words(), true)
+	} else {
+		ans.stopWords = ENGLISH_STOP_WORDS_SET
+	}
+	return ans, nil
+}
+
+// Returns the set of stop words.
+func (f *StopFilterFactory) StopWords() *util.CharArraySet {
+	return f.stopWords
+}
+
+// Returns true if the stop words are matched regardless of their
+// case.
+func (f *StopFilterFactory) IsIgnoreCase() bool {
+	return f.ignoreCase
+}
+
+// Returns true if holes are left at the positions of removed words.
+func (f *StopFilterFactory) EnablePositionIncrements() bool {
+	return f.enablePositionIncrements
+}
+
+func (f *StopFilterFactory) Create(input analysis.TokenStream) analysis.TokenStream {
+	ans := NewStopFilter(input, f.stopWords)
+	ans.SetEnablePositionIncrements(f.enablePositionIncrements)
+	return ans
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"io"
+	"io/ioutil"
+)
+
+// analysis/core/KeywordTokenizer.java
+
+// Emits the entire input as a single token.
+type KeywordTokenizer struct {
+	*analysis.TokenizerImpl
+	done        bool
+	finalOffset int
+	termAtt     analysis.CharTermAttribute
+	offsetAtt   analysis.OffsetAttribute
+}
+
+// Construct a new KeywordTokenizer.
+func NewKeywordTokenizer(input io.Reader) *KeywordTokenizer {
+	ans := &KeywordTokenizer{TokenizerImpl: analysis.NewTokenizer(input)}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(analysis.OffsetAttribute)
+	return ans
+}
+
+func (t *KeywordTokenizer) IncrementToken() (bool, error) {
+	if t.done {
+		return false, nil
+	}
+	t.Attributes().Clear()
+	t.done = true
+	text, err := ioutil.ReadAll(t.Input)
+	if err != nil {
+		return false, err
+	}
+	t.termAtt.AppendBytes(text)
+	t.finalOffset = len(text)
+	t.offsetAtt.SetOffset(0, t.finalOffset)
+	return true, nil
+}
+
+func (t *KeywordTokenizer) End() error {
+	// set final offset
+	t.offsetAtt.SetOffset(t.finalOffset, t.finalOffset)
+	return nil
+}
+
+func (t *KeywordTokenizer) Reset() error {
+	t.done = false
+	t.finalOffset = 0
+	return nil
+}
+
+// analysis/core/KeywordAnalyzer.java
+
+// "Tokenizes" the entire stream as a single token. This is useful for
+// data like zip codes, ids, and some product names.
+type KeywordAnalyzer struct {
+	*analysis.AnalyzerImpl
+}
+
+// Creates a new KeywordAnalyzer.
+func NewKeywordAnalyzer() *KeywordAnalyzer {
+	ans := new(KeywordAnalyzer)
+	ans.AnalyzerImpl = analysis.NewAnalyzer(ans)
+	return ans
+}
+
+func (a *KeywordAnalyzer) CreateComponents(fieldName string, reader io.Reader) *analysis.TokenStreamComponents {
+	return analysis.NewTokenStreamComponents(NewKeywordTokenizer(reader), nil)
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/analysis"
+	"unicode/utf8"
+)
+
+// analysis/core/LowerCaseFilter.java
+
+// Normalizes token text to lower case, by the Unicode lower case
+// mapping of each character.
+type LowerCaseFilter struct {
+	*analysis.TokenFilter
+	termAtt analysis.CharTermAttribute
+}
+
+// Create a new LowerCaseFilter, that normalizes token text to lower
+// case.
+func NewLowerCaseFilter(input analysis.TokenStream) *LowerCaseFilter {
+	ans := &LowerCaseFilter{TokenFilter: analysis.NewTokenFilter(input)}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	return ans
+}
+
+func (f *LowerCaseFilter) IncrementToken() (bool, error) {
+	ok, err := f.Input.IncrementToken()
+	if err != nil || !ok {
+		return false, err
+	}
+	toLower(f.termAtt)
+	return true, nil
+}
+
+// Lower cases the term in place, unless it has non-ASCII characters,
+// whose lower case may have a different length.
+func toLower(termAtt analysis.CharTermAttribute) {
+	term := termAtt.Bytes()
+	for i, b := range term {
+		if b >= utf8.RuneSelf {
+			termAtt.CopyBytes(bytes.ToLower(term))
+			return
+		}
+		if 'A' <= b && b <= 'Z' {
+			term[i] = b + 'a' - 'A'
+		}
+	}
+}
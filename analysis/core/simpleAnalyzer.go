@@ -0,0 +1,80 @@
+package core
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io"
+	"unicode"
+)
+
+// analysis/core/LetterTokenizer.java
+
+/*
+A LetterTokenizer is a tokenizer that divides text at non-letters.
+That's to say, it defines tokens as maximal strings of adjacent
+letters, as defined by unicode.IsLetter().
+
+Note: this does a decent job for most European languages, but does a
+terrible job for some Asian languages, where words are not separated
+by spaces.
+*/
+type LetterTokenizer struct {
+	*util.CharTokenizerImpl
+}
+
+// Construct a new LetterTokenizer.
+func NewLetterTokenizer(input io.Reader) *LetterTokenizer {
+	ans := new(LetterTokenizer)
+	ans.CharTokenizerImpl = util.NewCharTokenizer(ans, input)
+	return ans
+}
+
+// Collects only characters which satisfy unicode.IsLetter().
+func (t *LetterTokenizer) IsTokenChar(c rune) bool {
+	return unicode.IsLetter(c)
+}
+
+// analysis/core/LowerCaseTokenizer.java
+
+/*
+LowerCaseTokenizer performs the function of LetterTokenizer and
+LowerCaseFilter together. It divides text at non-letters and converts
+them to lower case. While it is functionally equivalent to the
+combination of LetterTokenizer and LowerCaseFilter, there is a
+performance advantage to doing the two tasks at once, hence this
+(redundant) implementation.
+*/
+type LowerCaseTokenizer struct {
+	*LetterTokenizer
+}
+
+// Construct a new LowerCaseTokenizer.
+func NewLowerCaseTokenizer(input io.Reader) *LowerCaseTokenizer {
+	ans := &LowerCaseTokenizer{new(LetterTokenizer)}
+	ans.CharTokenizerImpl = util.NewCharTokenizer(ans, input)
+	return ans
+}
+
+// Converts char to lower case.
+func (t *LowerCaseTokenizer) Normalize(c rune) rune {
+	return unicode.ToLower(c)
+}
+
+// analysis/core/SimpleAnalyzer.java
+
+// An Analyzer that filters LetterTokenizer with LowerCaseFilter, by
+// using LowerCaseTokenizer.
+type SimpleAnalyzer struct {
+	*analysis.AnalyzerImpl
+}
+
+// Creates a new SimpleAnalyzer.
+func NewSimpleAnalyzer() *SimpleAnalyzer {
+	ans := new(SimpleAnalyzer)
+	ans.AnalyzerImpl = analysis.NewAnalyzer(ans)
+	return ans
+}
+
+func (a *SimpleAnalyzer) CreateComponents(fieldName string, reader io.Reader) *analysis.TokenStreamComponents {
+	return analysis.NewTokenStreamComponents(NewLowerCaseTokenizer(reader), nil)
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io"
+)
+
+// analysis/core/StopAnalyzer.java
+
+// A set containing some common English words that are not usually
+// useful for searching. It is shared, and must not be modified.
+var ENGLISH_STOP_WORDS_SET = util.NewCharArraySetFrom([]string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by",
+	"for", "if", "in", "into", "is", "it",
+	"no", "not", "of", "on", "or", "such",
+	"that", "the", "their", "then", "there", "these",
+	"they", "this", "to", "was", "will", "with",
+}, false)
+
+// Filters LetterTokenizer with LowerCaseFilter and StopFilter.
+type StopAnalyzer struct {
+	*util.StopwordAnalyzerBase
+}
+
+// Builds an analyzer which removes words in ENGLISH_STOP_WORDS_SET.
+func NewStopAnalyzer() *StopAnalyzer {
+	return NewStopAnalyzerWithStopWords(ENGLISH_STOP_WORDS_SET)
+}
+
+// Builds an analyzer with the stop words from the given set.
+func NewStopAnalyzerWithStopWords(stopWords *util.CharArraySet) *StopAnalyzer {
+	ans := new(StopAnalyzer)
+	ans.StopwordAnalyzerBase = util.NewStopwordAnalyzerBase(ans, stopWords)
+	return ans
+}
+
+/*
+Builds an analyzer with the stop words from the given file, one word
+per line.
+*/
+func NewStopAnalyzerFromFile(path string) (*StopAnalyzer, error) {
+	stopWords, err := util.LoadStopwordSetFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewStopAnalyzerWithStopWords(stopWords), nil
+}
+
+func (a *StopAnalyzer) CreateComponents(fieldName string, reader io.Reader) *analysis.TokenStreamComponents {
+	source := NewLowerCaseTokenizer(reader)
+	return analysis.NewTokenStreamComponents(source, NewStopFilter(source, a.StopwordSet()))
+}
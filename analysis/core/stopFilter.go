@@ -0,0 +1,48 @@
+package core
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+)
+
+// analysis/core/StopFilter.java
+
+/*
+Removes stop words from a token stream.
+
+Whether the stop words are matched regardless of their case is
+decided by the CharArraySet: either build it with ignoreCase, or put a
+LowerCaseFilter before the StopFilter and use lower case stop words.
+*/
+type StopFilter struct {
+	*util.FilteringTokenFilterImpl
+	stopWords *util.CharArraySet
+	termAtt   analysis.CharTermAttribute
+}
+
+/*
+Builds a set from a list of stop words, suitable for passing to
+NewStopFilter(). If ignoreCase is true, the stop words are matched
+regardless of their case.
+*/
+func MakeStopSet(stopWords []string, ignoreCase bool) *util.CharArraySet {
+	return util.NewCharArraySetFrom(stopWords, ignoreCase)
+}
+
+/*
+Constructs a filter which removes words from the input TokenStream
+that are named in the set. The position increments of the removed
+words are added to the next token, see
+FilteringTokenFilterImpl.SetEnablePositionIncrements().
+*/
+func NewStopFilter(input analysis.TokenStream, stopWords *util.CharArraySet) *StopFilter {
+	ans := &StopFilter{stopWords: stopWords}
+	ans.FilteringTokenFilterImpl = util.NewFilteringTokenFilter(ans, true, input)
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	return ans
+}
+
+// Returns the next input Token whose term is not a stop word.
+func (f *StopFilter) Accept() (bool, error) {
+	return !f.stopWords.Contains(f.termAtt.Bytes()), nil
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"github.com/balzaczyy/golucene/analysis/analysistest"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLowerCaseFilter(t *testing.T) {
+	ts := NewLowerCaseFilter(NewWhitespaceTokenizer(strings.NewReader("HeLLo ÉCOLE İstanbul ΣΟΦΙΑ")))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"hello", "école", "istanbul", "σοφια"},
+		[]int{0, 6, 13, 23}, []int{5, 12, 22, 33}, nil, nil, nil, 33)
+}
+
+func TestStopFilter(t *testing.T) {
+	stopWords := util.NewCharArraySetFrom([]string{"is", "the", "Time"}, false)
+	ts := NewStopFilter(NewWhitespaceTokenizer(strings.NewReader("Now is The Time")), stopWords)
+	analysistest.AssertTokenStreamContents(t, ts, []string{"Now", "The"},
+		nil, nil, nil, []int{1, 2}, nil, 15)
+
+	// without position increments, the holes are not preserved
+	ts = NewStopFilter(NewWhitespaceTokenizer(strings.NewReader("the is a test is")), stopWords)
+	ts.SetEnablePositionIncrements(false)
+	analysistest.AssertTokenStreamContents(t, ts, []string{"a", "test"},
+		nil, nil, nil, []int{1, 1}, nil, 16)
+}
+
+func TestStopFilterIgnoreCase(t *testing.T) {
+	stopWords := MakeStopSet([]string{"is", "the", "Time"}, true)
+	ts := NewStopFilter(NewWhitespaceTokenizer(strings.NewReader("Now IS The time")), stopWords)
+	analysistest.AssertTokenStreamContents(t, ts, []string{"Now"},
+		[]int{0}, []int{3}, nil, []int{1}, nil, 15)
+}
+
+func TestStopAnalyzer(t *testing.T) {
+	a := NewStopAnalyzer()
+	analysistest.AssertAnalyzesTo(t, a, "This is a Test, of the StopAnalyzer2",
+		[]string{"test", "stopanalyzer"}, []int{10, 23}, []int{14, 35}, nil, []int{4, 3})
+
+	f, err := ioutil.TempFile("", "stopwords")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString("good\ntest\nanalyzer\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if a, err = NewStopAnalyzerFromFile(f.Name()); err != nil {
+		t.Fatal(err)
+	}
+	analysistest.AssertAnalyzesTo(t, a, "This is a good test of the english stop analyzer",
+		[]string{"this", "is", "a", "of", "the", "english", "stop"},
+		nil, nil, nil, []int{1, 1, 1, 3, 1, 1, 1})
+}
+
+func TestStopFilterTokenGraph(t *testing.T) {
+	// "the wi fi network", with "wifi" stacked on "wi fi"
+	stopWords := MakeStopSet([]string{"the", "wi"}, false)
+	ts := NewStopFilter(NewLowerCaseFilter(analysistest.NewCannedTokenStream(17,
+		analysistest.NewToken("The", 1, 1, 0, 3),
+		analysistest.NewToken("wi", 1, 1, 4, 6),
+		analysistest.NewToken("WiFi", 0, 2, 4, 9),
+		analysistest.NewToken("fi", 1, 1, 7, 9),
+		analysistest.NewToken("network", 1, 1, 10, 17),
+	)), stopWords)
+	// the increments of the removed tokens go to the stacked token,
+	// which keeps spanning its positions
+	analysistest.AssertTokenStreamContents(t, ts, []string{"wifi", "fi", "network"},
+		[]int{4, 7, 10}, []int{9, 9, 17}, nil, []int{2, 1, 1}, []int{2, 1, 1}, 17)
+}
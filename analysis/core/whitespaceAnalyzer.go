@@ -0,0 +1,64 @@
+package core
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io"
+	"unicode"
+)
+
+// analysis/core/WhitespaceTokenizer.java
+
+/*
+A WhitespaceTokenizer is a tokenizer that divides text at whitespace.
+Adjacent sequences of non-Whitespace characters form tokens.
+
+Whitespace is defined as Java's Character.isWhitespace() does, so
+non-breaking spaces are part of the tokens.
+*/
+type WhitespaceTokenizer struct {
+	*util.CharTokenizerImpl
+}
+
+// Construct a new WhitespaceTokenizer.
+func NewWhitespaceTokenizer(input io.Reader) *WhitespaceTokenizer {
+	ans := new(WhitespaceTokenizer)
+	ans.CharTokenizerImpl = util.NewCharTokenizer(ans, input)
+	return ans
+}
+
+// Collects only characters which do not satisfy isWhitespace().
+func (t *WhitespaceTokenizer) IsTokenChar(c rune) bool {
+	return !isWhitespace(c)
+}
+
+// Returns true if c is a Unicode space character but not a
+// non-breaking space, or one of the ASCII separators, as Java's
+// Character.isWhitespace().
+func isWhitespace(c rune) bool {
+	switch c {
+	case '\t', '\n', '\v', '\f', '\r', 0x1C, 0x1D, 0x1E, 0x1F:
+		return true
+	case 0xA0, 0x2007, 0x202F:
+		return false
+	}
+	return unicode.In(c, unicode.Zs, unicode.Zl, unicode.Zp)
+}
+
+// analysis/core/WhitespaceAnalyzer.java
+
+// An Analyzer that uses WhitespaceTokenizer.
+type WhitespaceAnalyzer struct {
+	*analysis.AnalyzerImpl
+}
+
+// Creates a new WhitespaceAnalyzer.
+func NewWhitespaceAnalyzer() *WhitespaceAnalyzer {
+	ans := new(WhitespaceAnalyzer)
+	ans.AnalyzerImpl = analysis.NewAnalyzer(ans)
+	return ans
+}
+
+func (a *WhitespaceAnalyzer) CreateComponents(fieldName string, reader io.Reader) *analysis.TokenStreamComponents {
+	return analysis.NewTokenStreamComponents(NewWhitespaceTokenizer(reader), nil)
+}
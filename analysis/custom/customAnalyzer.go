@@ -0,0 +1,229 @@
+package custom
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io"
+
+	// the bundled analysis components, registered by name
+	_ "github.com/balzaczyy/golucene/analysis/cjk"
+	_ "github.com/balzaczyy/golucene/analysis/core"
+	_ "github.com/balzaczyy/golucene/analysis/en"
+	_ "github.com/balzaczyy/golucene/analysis/miscellaneous"
+	_ "github.com/balzaczyy/golucene/analysis/standard"
+)
+
+// analysis/custom/CustomAnalyzer.java
+
+/*
+A general-purpose Analyzer that can be created with a builder-style
+API. Under the hood it uses the factory classes TokenizerFactory and
+TokenFilterFactory, looked up by their registered names, e.g.
+"standard" or "lowercase" (see util.AvailableTokenizers() and
+util.AvailableTokenFilters()):
+
+	analyzer, err := custom.Builder().
+		WithTokenizer("standard").
+		AddFilter("lowercase").
+		AddFilter("stop", "ignoreCase", "false", "words", "stopwords.txt").
+		Build()
+
+The parameters of the components are given as key-value pairs. The
+components of other packages can be used once the packages which
+register them are imported.
+*/
+type CustomAnalyzer struct {
+	*analysis.AnalyzerImpl
+	tokenizer            util.TokenizerFactory
+	tokenFilters         []util.TokenFilterFactory
+	positionIncrementGap *int
+	offsetGap            *int
+}
+
+// Returns a builder for custom analyzers.
+func Builder() *CustomAnalyzerBuilder {
+	return new(CustomAnalyzerBuilder)
+}
+
+func newCustomAnalyzer(tokenizer util.TokenizerFactory, tokenFilters []util.TokenFilterFactory,
+	positionIncrementGap, offsetGap *int) *CustomAnalyzer {
+	ans := &CustomAnalyzer{
+		tokenizer:            tokenizer,
+		tokenFilters:         tokenFilters,
+		positionIncrementGap: positionIncrementGap,
+		offsetGap:            offsetGap,
+	}
+	ans.AnalyzerImpl = analysis.NewAnalyzer(ans)
+	return ans
+}
+
+func (a *CustomAnalyzer) CreateComponents(fieldName string, reader io.Reader) *analysis.TokenStreamComponents {
+	tk := a.tokenizer.Create(reader)
+	var ts analysis.TokenStream = tk
+	for _, filter := range a.tokenFilters {
+		ts = filter.Create(ts)
+	}
+	return analysis.NewTokenStreamComponents(tk, ts)
+}
+
+func (a *CustomAnalyzer) PositionIncrementGap(fieldName string) int {
+	// use default from Analyzer base class if nil
+	if a.positionIncrementGap == nil {
+		return a.AnalyzerImpl.PositionIncrementGap(fieldName)
+	}
+	return *a.positionIncrementGap
+}
+
+func (a *CustomAnalyzer) OffsetGap(fieldName string) int {
+	// use default from Analyzer base class if nil
+	if a.offsetGap == nil {
+		return a.AnalyzerImpl.OffsetGap(fieldName)
+	}
+	return *a.offsetGap
+}
+
+// Returns the factory of the tokenizer used by this analyzer.
+func (a *CustomAnalyzer) TokenizerFactory() util.TokenizerFactory {
+	return a.tokenizer
+}
+
+// Returns the list of factories of the token filters used by this
+// analyzer, in order.
+func (a *CustomAnalyzer) TokenFilterFactories() []util.TokenFilterFactory {
+	return a.tokenFilters
+}
+
+func (a *CustomAnalyzer) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("CustomAnalyzer(")
+	fmt.Fprintf(&buf, "%T", a.tokenizer)
+	for _, filter := range a.tokenFilters {
+		fmt.Fprintf(&buf, ",%T", filter)
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+/*
+Builder for CustomAnalyzer.
+
+The errors of the components, e.g. an unknown name or an invalid
+parameter, are kept until Build(), which returns the first of them,
+so the calls can be chained.
+*/
+type CustomAnalyzerBuilder struct {
+	tokenizer            util.TokenizerFactory
+	tokenFilters         []util.TokenFilterFactory
+	positionIncrementGap *int
+	offsetGap            *int
+	err                  error
+}
+
+// Records the first error of the builder.
+func (b *CustomAnalyzerBuilder) fail(err error) *CustomAnalyzerBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+/*
+Uses the given tokenizer, looked up by name, with the given
+parameters, as key-value pairs.
+*/
+func (b *CustomAnalyzerBuilder) WithTokenizer(name string, params ...string) *CustomAnalyzerBuilder {
+	args, err := paramsToMap(params)
+	if err != nil {
+		return b.fail(err)
+	}
+	return b.WithTokenizerArgs(name, args)
+}
+
+// Uses the given tokenizer, looked up by name, with the given
+// arguments.
+func (b *CustomAnalyzerBuilder) WithTokenizerArgs(name string, args map[string]string) *CustomAnalyzerBuilder {
+	if b.tokenizer != nil {
+		return b.fail(errors.New("Tokenizer can only be set once"))
+	}
+	factory, err := util.TokenizerFactoryForName(name, args)
+	if err != nil {
+		return b.fail(err)
+	}
+	b.tokenizer = factory
+	return b
+}
+
+/*
+Adds the given token filter, looked up by name, with the given
+parameters, as key-value pairs. The filters are applied in the order
+they are added.
+*/
+func (b *CustomAnalyzerBuilder) AddFilter(name string, params ...string) *CustomAnalyzerBuilder {
+	args, err := paramsToMap(params)
+	if err != nil {
+		return b.fail(err)
+	}
+	return b.AddFilterArgs(name, args)
+}
+
+// Adds the given token filter, looked up by name, with the given
+// arguments.
+func (b *CustomAnalyzerBuilder) AddFilterArgs(name string, args map[string]string) *CustomAnalyzerBuilder {
+	factory, err := util.TokenFilterFactoryForName(name, args)
+	if err != nil {
+		return b.fail(err)
+	}
+	b.tokenFilters = append(b.tokenFilters, factory)
+	return b
+}
+
+// Sets the position increment gap of the analyzer. The default is
+// defined in the Analyzer base class.
+func (b *CustomAnalyzerBuilder) WithPositionIncrementGap(gap int) *CustomAnalyzerBuilder {
+	if gap < 0 {
+		return b.fail(errors.New("Position increment gap must be >= 0"))
+	}
+	b.positionIncrementGap = &gap
+	return b
+}
+
+// Sets the offset gap of the analyzer. The default is defined in the
+// Analyzer base class.
+func (b *CustomAnalyzerBuilder) WithOffsetGap(gap int) *CustomAnalyzerBuilder {
+	if gap < 0 {
+		return b.fail(errors.New("Offset gap must be >= 0"))
+	}
+	b.offsetGap = &gap
+	return b
+}
+
+// Builds the analyzer, or returns the first error of the builder.
+func (b *CustomAnalyzerBuilder) Build() (*CustomAnalyzer, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.tokenizer == nil {
+		return nil, errors.New("You have to set at least a tokenizer.")
+	}
+	filters := make([]util.TokenFilterFactory, len(b.tokenFilters))
+	copy(filters, b.tokenFilters)
+	return newCustomAnalyzer(b.tokenizer, filters, b.positionIncrementGap, b.offsetGap), nil
+}
+
+func paramsToMap(params []string) (map[string]string, error) {
+	if len(params)%2 != 0 {
+		return nil, errors.New(fmt.Sprintf(
+			"Key-value pairs expected, so the number of params must be even: %v", params))
+	}
+	ans := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		if _, ok := ans[params[i]]; ok {
+			return nil, errors.New(fmt.Sprintf("Duplicate parameter: %v", params[i]))
+		}
+		ans[params[i]] = params[i+1]
+	}
+	return ans, nil
+}
@@ -0,0 +1,100 @@
+package custom
+
+import (
+	"github.com/balzaczyy/golucene/analysis/analysistest"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCustomAnalyzer(t *testing.T) {
+	a, err := Builder().
+		WithTokenizer("standard").
+		AddFilter("lowercase").
+		AddFilter("stop").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	analysistest.AssertAnalyzesTo(t, a, "The Quick-Brown FOX",
+		[]string{"quick", "brown", "fox"}, []int{4, 10, 16}, []int{9, 15, 19}, nil, []int{2, 1, 1})
+	if a.PositionIncrementGap("dummy") != 0 || a.OffsetGap("dummy") != 1 {
+		t.Error("should use the default gaps")
+	}
+	if s := a.String(); s != "CustomAnalyzer(*standard.StandardTokenizerFactory,"+
+		"*core.LowerCaseFilterFactory,*core.StopFilterFactory)" {
+		t.Errorf("unexpected string: %v", s)
+	}
+
+	a, err = Builder().
+		WithTokenizer("Whitespace").
+		AddFilter("asciiFolding", "preserveOriginal", "true").
+		WithPositionIncrementGap(100).
+		WithOffsetGap(10).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	analysistest.AssertAnalyzesTo(t, a, "Des clés", []string{"Des", "cles", "clés"},
+		nil, nil, nil, []int{1, 1, 0})
+	if a.PositionIncrementGap("dummy") != 100 || a.OffsetGap("dummy") != 10 {
+		t.Error("should use the given gaps")
+	}
+	if len(a.TokenFilterFactories()) != 1 || a.TokenizerFactory() == nil {
+		t.Error("unexpected factories")
+	}
+}
+
+func TestCustomAnalyzerStopWordsFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "stopwords")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString("# comment\nquick\nFOX\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	a, err := Builder().
+		WithTokenizer("whitespace").
+		AddFilterArgs("stop", map[string]string{"words": f.Name(), "ignoreCase": "true"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	analysistest.AssertAnalyzesTo(t, a, "The Quick fox jumps", []string{"The", "jumps"},
+		nil, nil, nil, []int{1, 3})
+}
+
+func TestCustomAnalyzerErrors(t *testing.T) {
+	for _, v := range []struct {
+		builder *CustomAnalyzerBuilder
+		message string
+	}{
+		{Builder(), "You have to set at least a tokenizer."},
+		{Builder().WithTokenizer("nonexistent"), "A TokenizerFactory with name 'nonexistent' does not exist"},
+		{Builder().WithTokenizer("standard").AddFilter("nonexistent"), "A TokenFilterFactory with name 'nonexistent' does not exist"},
+		{Builder().WithTokenizer("standard").WithTokenizer("whitespace"), "Tokenizer can only be set once"},
+		{Builder().WithTokenizer("standard", "maxTokenLength"), "Key-value pairs expected"},
+		{Builder().WithTokenizer("standard", "maxTokenLength", "x"), "Configuration Error: 'maxTokenLength' is not an integer"},
+		{Builder().WithTokenizer("whitespace", "foo", "bar"), "Unknown parameters: foo"},
+		{Builder().WithTokenizer("whitespace").AddFilter("stop", "words", "stopwords.txt", "format", "xml"), "Unknown 'format'"},
+		{Builder().WithTokenizer("whitespace").WithPositionIncrementGap(-1), "Position increment gap must be >= 0"},
+	} {
+		if _, err := v.builder.Build(); err == nil || !strings.HasPrefix(err.Error(), v.message) {
+			t.Errorf("expected error %v, but was %v", v.message, err)
+		}
+	}
+}
+
+func TestAvailableFactories(t *testing.T) {
+	if s := strings.Join(util.AvailableTokenizers(), ","); s != "keyword,letter,lowercase,standard,whitespace" {
+		t.Errorf("unexpected tokenizers: %v", s)
+	}
+	if s := strings.Join(util.AvailableTokenFilters(), ","); s != "asciifolding,cjkbigram,cjkwidth,englishpossessive,keywordmarker,lowercase,porterstem,stop" {
+		t.Errorf("unexpected token filters: %v", s)
+	}
+}
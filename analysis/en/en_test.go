@@ -0,0 +1,101 @@
+package en
+
+import (
+	"github.com/balzaczyy/golucene/analysis/analysistest"
+	"github.com/balzaczyy/golucene/analysis/core"
+	"github.com/balzaczyy/golucene/analysis/miscellaneous"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"strings"
+	"testing"
+)
+
+func TestPorterStemmer(t *testing.T) {
+	var stemmer porterStemmer
+	for _, v := range []struct{ input, output string }{
+		{"caresses", "caress"},
+		{"ponies", "poni"},
+		{"ties", "ti"},
+		{"caress", "caress"},
+		{"cats", "cat"},
+		{"feed", "feed"},
+		{"agreed", "agre"},
+		{"disabled", "disabl"},
+		{"matting", "mat"},
+		{"mating", "mate"},
+		{"meeting", "meet"},
+		{"milling", "mill"},
+		{"messing", "mess"},
+		{"meetings", "meet"},
+		{"motoring", "motor"},
+		{"hopping", "hop"},
+		{"happy", "happi"},
+		{"sky", "sky"},
+		{"relational", "relat"},
+		{"conditional", "condit"},
+		{"generalizations", "gener"},
+		{"oscillators", "oscil"},
+		{"hopeful", "hope"},
+		{"goodness", "good"},
+		{"archaeology", "archaeolog"},
+		{"revival", "reviv"},
+		{"adjustment", "adjust"},
+		{"controlling", "control"},
+		{"rolling", "roll"},
+		{"as", "as"},
+		{"is", "is"},
+	} {
+		if stem, _ := stemmer.stem([]byte(v.input)); string(stem) != v.output {
+			t.Errorf("%v: expected %v, but was %v", v.input, v.output, string(stem))
+		}
+	}
+	if _, changed := stemmer.stem([]byte("caress")); changed {
+		t.Error("caress should not be changed")
+	}
+	if _, changed := stemmer.stem([]byte("cats")); !changed {
+		t.Error("cats should be changed")
+	}
+}
+
+func TestPorterStemFilter(t *testing.T) {
+	ts := NewPorterStemFilter(core.NewWhitespaceTokenizer(strings.NewReader("ponies hopping yourselves")))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"poni", "hop", "yourselv"},
+		[]int{0, 7, 15}, []int{6, 14, 25}, nil, nil, nil, 25)
+}
+
+func TestPorterStemFilterWithKeywords(t *testing.T) {
+	set := util.NewCharArraySetFrom([]string{"yourselves"}, false)
+	ts := NewPorterStemFilter(miscellaneous.NewSetKeywordMarkerFilter(
+		core.NewWhitespaceTokenizer(strings.NewReader("ponies yourselves")), set))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"poni", "yourselves"}, nil, nil, nil, nil, nil, 17)
+}
+
+func TestEnglishPossessiveFilter(t *testing.T) {
+	ts := NewEnglishPossessiveFilter(core.NewWhitespaceTokenizer(strings.NewReader("John's Mary’S Bill＇s cats s 's")))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"John", "Mary", "Bill", "cats", "s", ""},
+		nil, nil, nil, nil, nil, -1)
+}
+
+func TestEnglishAnalyzer(t *testing.T) {
+	a := NewEnglishAnalyzer()
+	// stemming
+	analysistest.AssertAnalyzesTo(t, a, "books", []string{"book"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "book", []string{"book"}, nil, nil, nil, nil)
+	// stopword
+	analysistest.AssertAnalyzesTo(t, a, "the", []string{}, nil, nil, nil, nil)
+	// possessive removal
+	analysistest.AssertAnalyzesTo(t, a, "steven's", []string{"steven"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "steven’s", []string{"steven"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "The Ponies of John's Farms",
+		[]string{"poni", "john", "farm"}, []int{4, 14, 21}, []int{10, 20, 26}, nil, []int{2, 2, 1})
+}
+
+func TestEnglishAnalyzerStemExclusion(t *testing.T) {
+	exclusionSet := util.NewCharArraySetFrom([]string{"books"}, false)
+	a := NewEnglishAnalyzerWithStemExclusion(core.ENGLISH_STOP_WORDS_SET, exclusionSet)
+	if a.StemExclusionSet() != exclusionSet {
+		t.Error("wrong stem exclusion set")
+	}
+	analysistest.AssertAnalyzesTo(t, a, "books", []string{"books"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "book", []string{"book"}, nil, nil, nil, nil)
+	analysistest.AssertAnalyzesTo(t, a, "Books farms", []string{"books", "farm"}, nil, nil, nil, nil)
+}
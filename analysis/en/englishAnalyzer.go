@@ -0,0 +1,68 @@
+package en
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/core"
+	"github.com/balzaczyy/golucene/analysis/miscellaneous"
+	"github.com/balzaczyy/golucene/analysis/standard"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io"
+)
+
+// analysis/en/EnglishAnalyzer.java
+
+/*
+Analyzer for English.
+
+It tokenizes text with StandardTokenizer, removes possessives with
+EnglishPossessiveFilter, folds case with LowerCaseFilter, filters
+stopwords with StopFilter, and stems with PorterStemFilter. The words
+of the stem exclusion set, if any, are marked as keywords with
+SetKeywordMarkerFilter beforehand, so they are not stemmed.
+*/
+type EnglishAnalyzer struct {
+	*util.StopwordAnalyzerBase
+	stemExclusionSet *util.CharArraySet
+}
+
+// Builds an analyzer with the default stop words:
+// core.ENGLISH_STOP_WORDS_SET.
+func NewEnglishAnalyzer() *EnglishAnalyzer {
+	return NewEnglishAnalyzerWithStopWords(core.ENGLISH_STOP_WORDS_SET)
+}
+
+// Builds an analyzer with the given stop words.
+func NewEnglishAnalyzerWithStopWords(stopwords *util.CharArraySet) *EnglishAnalyzer {
+	return NewEnglishAnalyzerWithStemExclusion(stopwords, util.EMPTY_SET)
+}
+
+/*
+Builds an analyzer with the given stop words. If a non-empty stem
+exclusion set is provided this analyzer will add a
+SetKeywordMarkerFilter before stemming.
+*/
+func NewEnglishAnalyzerWithStemExclusion(stopwords, stemExclusionSet *util.CharArraySet) *EnglishAnalyzer {
+	if stemExclusionSet == nil {
+		stemExclusionSet = util.EMPTY_SET
+	}
+	ans := &EnglishAnalyzer{stemExclusionSet: stemExclusionSet}
+	ans.StopwordAnalyzerBase = util.NewStopwordAnalyzerBase(ans, stopwords)
+	return ans
+}
+
+// Returns the set of words which are not stemmed.
+func (a *EnglishAnalyzer) StemExclusionSet() *util.CharArraySet {
+	return a.stemExclusionSet
+}
+
+func (a *EnglishAnalyzer) CreateComponents(fieldName string, reader io.Reader) *analysis.TokenStreamComponents {
+	source := standard.NewStandardTokenizer(reader)
+	var result analysis.TokenStream = NewEnglishPossessiveFilter(source)
+	result = core.NewLowerCaseFilter(result)
+	result = core.NewStopFilter(result, a.StopwordSet())
+	if a.stemExclusionSet.Len() > 0 {
+		result = miscellaneous.NewSetKeywordMarkerFilter(result, a.stemExclusionSet)
+	}
+	result = NewPorterStemFilter(result)
+	return analysis.NewTokenStreamComponents(source, result)
+}
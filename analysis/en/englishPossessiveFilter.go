@@ -0,0 +1,44 @@
+package en
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/analysis"
+)
+
+// analysis/en/EnglishPossessiveFilter.java
+
+// The apostrophes which may precede the trailing s of a possessive:
+// the ASCII apostrophe, the right single quotation mark and the
+// fullwidth apostrophe.
+var possessiveApostrophes = [][]byte{[]byte("'"), []byte("’"), []byte("＇")}
+
+// TokenFilter that removes possessives (trailing 's) from words.
+type EnglishPossessiveFilter struct {
+	*analysis.TokenFilter
+	termAtt analysis.CharTermAttribute
+}
+
+// Create a new EnglishPossessiveFilter, which removes the possessives
+// of the tokens of input.
+func NewEnglishPossessiveFilter(input analysis.TokenStream) *EnglishPossessiveFilter {
+	ans := &EnglishPossessiveFilter{TokenFilter: analysis.NewTokenFilter(input)}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	return ans
+}
+
+func (f *EnglishPossessiveFilter) IncrementToken() (bool, error) {
+	ok, err := f.Input.IncrementToken()
+	if err != nil || !ok {
+		return false, err
+	}
+	term := f.termAtt.Bytes()
+	if n := len(term); n >= 2 && (term[n-1] == 's' || term[n-1] == 'S') {
+		for _, apostrophe := range possessiveApostrophes {
+			if bytes.HasSuffix(term[:n-1], apostrophe) {
+				f.termAtt.SetLength(n - 1 - len(apostrophe))
+				break
+			}
+		}
+	}
+	return true, nil
+}
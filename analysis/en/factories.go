@@ -0,0 +1,56 @@
+package en
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+)
+
+func init() {
+	util.RegisterTokenFilterFactory("porterstem", func(args map[string]string) (util.TokenFilterFactory, error) {
+		return NewPorterStemFilterFactory(args)
+	})
+	util.RegisterTokenFilterFactory("englishpossessive", func(args map[string]string) (util.TokenFilterFactory, error) {
+		return NewEnglishPossessiveFilterFactory(args)
+	})
+}
+
+// analysis/en/PorterStemFilterFactory.java
+
+// Factory for PorterStemFilter.
+type PorterStemFilterFactory struct {
+	*util.AbstractAnalysisFactory
+}
+
+// Creates a new PorterStemFilterFactory, which takes no arguments.
+func NewPorterStemFilterFactory(args map[string]string) (*PorterStemFilterFactory, error) {
+	ans := &PorterStemFilterFactory{util.NewAbstractAnalysisFactory(args)}
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *PorterStemFilterFactory) Create(input analysis.TokenStream) analysis.TokenStream {
+	return NewPorterStemFilter(input)
+}
+
+// analysis/en/EnglishPossessiveFilterFactory.java
+
+// Factory for EnglishPossessiveFilter.
+type EnglishPossessiveFilterFactory struct {
+	*util.AbstractAnalysisFactory
+}
+
+// Creates a new EnglishPossessiveFilterFactory, which takes no
+// arguments.
+func NewEnglishPossessiveFilterFactory(args map[string]string) (*EnglishPossessiveFilterFactory, error) {
+	ans := &EnglishPossessiveFilterFactory{util.NewAbstractAnalysisFactory(args)}
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *EnglishPossessiveFilterFactory) Create(input analysis.TokenStream) analysis.TokenStream {
+	return NewEnglishPossessiveFilter(input)
+}
@@ -0,0 +1,56 @@
+package en
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+)
+
+// analysis/en/PorterStemFilter.java
+
+/*
+Transforms the token stream as per the Porter stemming algorithm.
+Note: the input to the stemming filter must already be in lower case,
+so you will need to use LowerCaseFilter or LowerCaseTokenizer farther
+down the Tokenizer chain in order for this to work properly!
+
+To use this filter with other analyzers, you'll want to write an
+Analyzer class that sets up the TokenStream chain as you want it. To
+use this with LowerCaseTokenizer, for example, you'd write an analyzer
+like this:
+
+	func (a *MyAnalyzer) CreateComponents(fieldName string, reader io.Reader) *analysis.TokenStreamComponents {
+		source := core.NewLowerCaseTokenizer(reader)
+		return analysis.NewTokenStreamComponents(source, en.NewPorterStemFilter(source))
+	}
+
+Note: This filter is aware of the KeywordAttribute. To prevent certain
+terms from being passed to the stemmer KeywordAttribute.IsKeyword()
+should be set to true in a previous TokenStream, e.g. by
+miscellaneous.SetKeywordMarkerFilter.
+*/
+type PorterStemFilter struct {
+	*analysis.TokenFilter
+	stemmer     porterStemmer
+	termAtt     analysis.CharTermAttribute
+	keywordAttr analysis.KeywordAttribute
+}
+
+// Create a new PorterStemFilter, which stems the tokens of input.
+func NewPorterStemFilter(input analysis.TokenStream) *PorterStemFilter {
+	ans := &PorterStemFilter{TokenFilter: analysis.NewTokenFilter(input)}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.keywordAttr = ans.Attributes().Add("KeywordAttribute").(analysis.KeywordAttribute)
+	return ans
+}
+
+func (f *PorterStemFilter) IncrementToken() (bool, error) {
+	ok, err := f.Input.IncrementToken()
+	if err != nil || !ok {
+		return false, err
+	}
+	if !f.keywordAttr.IsKeyword() {
+		if stem, changed := f.stemmer.stem(f.termAtt.Bytes()); changed {
+			f.termAtt.CopyBytes(stem)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,400 @@
+package en
+
+// analysis/en/PorterStemmer.java
+
+/*
+Stemmer, implementing the Porter Stemming Algorithm
+
+The Stemmer class transforms a word into its root form. The input
+word can be provided a byte at a time, or all at once by calling
+stem(); it is expected to be in lower case ASCII.
+*/
+type porterStemmer struct {
+	b []byte
+	// offset into b
+	i int
+	// j, k and k0 are offsets of b, as in the original algorithm
+	j, k, k0 int
+	// true if a stemming step changed the word
+	dirty bool
+}
+
+// cons(i) is true <=> b[i] is a consonant.
+func (s *porterStemmer) cons(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == s.k0 || !s.cons(i-1)
+	default:
+		return true
+	}
+}
+
+/*
+m() measures the number of consonant sequences between k0 and j. if
+c is a consonant sequence and v a vowel sequence, and <..> indicates
+arbitrary presence,
+
+	<c><v>       gives 0
+	<c>vc<v>     gives 1
+	<c>vcvc<v>   gives 2
+	<c>vcvcvc<v> gives 3
+	....
+*/
+func (s *porterStemmer) m() int {
+	n := 0
+	i := s.k0
+	for {
+		if i > s.j {
+			return n
+		}
+		if !s.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+	for {
+		for {
+			if i > s.j {
+				return n
+			}
+			if s.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+		for {
+			if i > s.j {
+				return n
+			}
+			if !s.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+// vowelinstem() is true <=> k0,...j contains a vowel
+func (s *porterStemmer) vowelinstem() bool {
+	for i := s.k0; i <= s.j; i++ {
+		if !s.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doublec(j) is true <=> j,(j-1) contain a double consonant.
+func (s *porterStemmer) doublec(j int) bool {
+	if j < s.k0+1 {
+		return false
+	}
+	if s.b[j] != s.b[j-1] {
+		return false
+	}
+	return s.cons(j)
+}
+
+/*
+cvc(i) is true <=> i-2,i-1,i has the form consonant - vowel -
+consonant and also if the second c is not w,x or y. this is used when
+trying to restore an e at the end of a short word. e.g.
+
+	cav(e), lov(e), hop(e), crim(e), but
+	snow, box, tray.
+*/
+func (s *porterStemmer) cvc(i int) bool {
+	if i < s.k0+2 || !s.cons(i) || s.cons(i-1) || !s.cons(i-2) {
+		return false
+	}
+	switch s.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends(s) is true <=> k0,...k ends with the string suffix, and sets j
+// before the suffix if so.
+func (s *porterStemmer) ends(suffix string) bool {
+	l := len(suffix)
+	o := s.k - l + 1
+	if o < s.k0 {
+		return false
+	}
+	if string(s.b[o:s.k+1]) != suffix {
+		return false
+	}
+	s.j = s.k - l
+	return true
+}
+
+// setto(s) sets (j+1),...k to the characters in the string suffix,
+// readjusting k.
+func (s *porterStemmer) setto(suffix string) {
+	s.b = append(s.b[:s.j+1], suffix...)
+	s.k = s.j + len(suffix)
+	s.dirty = true
+}
+
+// r(s) is used further down.
+func (s *porterStemmer) r(suffix string) {
+	if s.m() > 0 {
+		s.setto(suffix)
+	}
+}
+
+/*
+step1() gets rid of plurals and -ed or -ing. e.g.
+
+	caresses  ->  caress
+	ponies    ->  poni
+	ties      ->  ti
+	caress    ->  caress
+	cats      ->  cat
+
+	feed      ->  feed
+	agreed    ->  agree
+	disabled  ->  disable
+
+	matting   ->  mat
+	mating    ->  mate
+	meeting   ->  meet
+	milling   ->  mill
+	messing   ->  mess
+
+	meetings  ->  meet
+*/
+func (s *porterStemmer) step1() {
+	if s.b[s.k] == 's' {
+		if s.ends("sses") {
+			s.k -= 2
+		} else if s.ends("ies") {
+			s.setto("i")
+		} else if s.b[s.k-1] != 's' {
+			s.k--
+		}
+	}
+	if s.ends("eed") {
+		if s.m() > 0 {
+			s.k--
+		}
+	} else if (s.ends("ed") || s.ends("ing")) && s.vowelinstem() {
+		s.k = s.j
+		if s.ends("at") {
+			s.setto("ate")
+		} else if s.ends("bl") {
+			s.setto("ble")
+		} else if s.ends("iz") {
+			s.setto("ize")
+		} else if s.doublec(s.k) {
+			ch := s.b[s.k]
+			s.k--
+			if ch == 'l' || ch == 's' || ch == 'z' {
+				s.k++
+			}
+		} else if s.m() == 1 && s.cvc(s.k) {
+			s.setto("e")
+		}
+	}
+}
+
+// step2() turns terminal y to i when there is another vowel in the
+// stem.
+func (s *porterStemmer) step2() {
+	if s.ends("y") && s.vowelinstem() {
+		s.b[s.k] = 'i'
+		s.dirty = true
+	}
+}
+
+/*
+step3() maps double suffices to single ones. so -ization ( = -ize
+plus -ation) maps to -ize etc. note that the string before the suffix
+must give m() > 0.
+*/
+func (s *porterStemmer) step3() {
+	if s.k == s.k0 {
+		return // For Bug 1
+	}
+	switch s.b[s.k-1] {
+	case 'a':
+		if s.ends("ational") {
+			s.r("ate")
+		} else if s.ends("tional") {
+			s.r("tion")
+		}
+	case 'c':
+		if s.ends("enci") {
+			s.r("ence")
+		} else if s.ends("anci") {
+			s.r("ance")
+		}
+	case 'e':
+		if s.ends("izer") {
+			s.r("ize")
+		}
+	case 'l':
+		if s.ends("bli") {
+			s.r("ble")
+		} else if s.ends("alli") {
+			s.r("al")
+		} else if s.ends("entli") {
+			s.r("ent")
+		} else if s.ends("eli") {
+			s.r("e")
+		} else if s.ends("ousli") {
+			s.r("ous")
+		}
+	case 'o':
+		if s.ends("ization") {
+			s.r("ize")
+		} else if s.ends("ation") {
+			s.r("ate")
+		} else if s.ends("ator") {
+			s.r("ate")
+		}
+	case 's':
+		if s.ends("alism") {
+			s.r("al")
+		} else if s.ends("iveness") {
+			s.r("ive")
+		} else if s.ends("fulness") {
+			s.r("ful")
+		} else if s.ends("ousness") {
+			s.r("ous")
+		}
+	case 't':
+		if s.ends("aliti") {
+			s.r("al")
+		} else if s.ends("iviti") {
+			s.r("ive")
+		} else if s.ends("biliti") {
+			s.r("ble")
+		}
+	case 'g':
+		if s.ends("logi") {
+			s.r("log")
+		}
+	}
+}
+
+// step4() deals with -ic-, -full, -ness etc. similar strategy to
+// step3.
+func (s *porterStemmer) step4() {
+	switch s.b[s.k] {
+	case 'e':
+		if s.ends("icate") {
+			s.r("ic")
+		} else if s.ends("ative") {
+			s.r("")
+		} else if s.ends("alize") {
+			s.r("al")
+		}
+	case 'i':
+		if s.ends("iciti") {
+			s.r("ic")
+		}
+	case 'l':
+		if s.ends("ical") {
+			s.r("ic")
+		} else if s.ends("ful") {
+			s.r("")
+		}
+	case 's':
+		if s.ends("ness") {
+			s.r("")
+		}
+	}
+}
+
+// step5() takes off -ant, -ence etc., in context <c>vcvc<v>.
+func (s *porterStemmer) step5() {
+	if s.k == s.k0 {
+		return // for Bug 1
+	}
+	var found bool
+	switch s.b[s.k-1] {
+	case 'a':
+		found = s.ends("al")
+	case 'c':
+		found = s.ends("ance") || s.ends("ence")
+	case 'e':
+		found = s.ends("er")
+	case 'i':
+		found = s.ends("ic")
+	case 'l':
+		found = s.ends("able") || s.ends("ible")
+	case 'n':
+		// element etc. not stripped before the m
+		found = s.ends("ant") || s.ends("ement") || s.ends("ment") || s.ends("ent")
+	case 'o':
+		// j >= 0 fixes Bug 2
+		found = s.ends("ion") && s.j >= 0 && (s.b[s.j] == 's' || s.b[s.j] == 't')
+		if !found {
+			// takes care of -ous
+			found = s.ends("ou")
+		}
+	case 's':
+		found = s.ends("ism")
+	case 't':
+		found = s.ends("ate") || s.ends("iti")
+	case 'u':
+		found = s.ends("ous")
+	case 'v':
+		found = s.ends("ive")
+	case 'z':
+		found = s.ends("ize")
+	}
+	if found && s.m() > 1 {
+		s.k = s.j
+	}
+}
+
+// step6() removes a final -e if m() > 1.
+func (s *porterStemmer) step6() {
+	s.j = s.k
+	if s.b[s.k] == 'e' {
+		if a := s.m(); a > 1 || a == 1 && !s.cvc(s.k-1) {
+			s.k--
+		}
+	}
+	if s.b[s.k] == 'l' && s.doublec(s.k) && s.m() > 1 {
+		s.k--
+	}
+}
+
+/*
+Stem the word, and return the stemmed form, which is only valid until
+the next call, and true if the stemming process resulted in a word
+different from the input. Words of less than three characters are not
+stemmed.
+*/
+func (s *porterStemmer) stem(word []byte) ([]byte, bool) {
+	s.b = append(s.b[:0], word...)
+	s.i = len(word)
+	s.k = s.i - 1
+	s.k0 = 0
+	s.dirty = false
+	if s.k > s.k0+1 {
+		s.step1()
+		s.step2()
+		s.step3()
+		s.step4()
+		s.step5()
+		s.step6()
+	}
+	// Also, a word is considered dirty if we lopped off letters
+	// Thanks to Ifigenia Vairelles for pointing this out.
+	if s.i != s.k+1 {
+		s.dirty = true
+	}
+	s.i = s.k + 1
+	return s.b[:s.i], s.dirty
+}
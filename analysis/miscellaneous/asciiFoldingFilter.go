@@ -0,0 +1,128 @@
+package miscellaneous
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/util"
+	"unicode/utf8"
+)
+
+// analysis/miscellaneous/ASCIIFoldingFilter.java
+
+/*
+This class converts alphabetic, numeric, and symbolic Unicode
+characters which are not in the first 127 ASCII characters (the
+"Basic Latin" Unicode block) into their ASCII equivalents, if one
+exists.
+
+Characters from the following Unicode blocks are converted; however,
+only those characters with reasonable ASCII alternatives are
+converted:
+
+  - C1 Controls and Latin-1 Supplement
+  - Latin Extended-A, -B, -C, -D and Additional
+  - IPA Extensions and Phonetic Extensions
+  - General Punctuation, Superscripts and Subscripts
+  - Enclosed Alphanumerics and Dingbats
+  - Supplemental Punctuation
+  - Alphabetic Presentation Forms
+  - Halfwidth and Fullwidth Forms
+
+For example, 'à' will be replaced by 'a', 'Æ' by "AE" and '①' by '1'.
+
+If preserveOriginal is true, the original token is emitted too, after
+the folded one and at the same position, whenever folding changed it.
+*/
+type ASCIIFoldingFilter struct {
+	*analysis.TokenFilter
+	preserveOriginal bool
+	termAtt          analysis.CharTermAttribute
+	posIncAtt        analysis.PositionIncrementAttribute
+	output           []byte
+	state            *util.AttributeState
+}
+
+// Create a new ASCIIFoldingFilter, which replaces the original tokens
+// by the folded ones.
+func NewASCIIFoldingFilter(input analysis.TokenStream) *ASCIIFoldingFilter {
+	return NewASCIIFoldingFilterWith(input, false)
+}
+
+// Create a new ASCIIFoldingFilter, which also emits the original
+// tokens if preserveOriginal is true.
+func NewASCIIFoldingFilterWith(input analysis.TokenStream, preserveOriginal bool) *ASCIIFoldingFilter {
+	ans := &ASCIIFoldingFilter{TokenFilter: analysis.NewTokenFilter(input), preserveOriginal: preserveOriginal}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.posIncAtt = ans.Attributes().Add("PositionIncrementAttribute").(analysis.PositionIncrementAttribute)
+	return ans
+}
+
+// Does the filter preserve the original tokens?
+func (f *ASCIIFoldingFilter) PreserveOriginal() bool {
+	return f.preserveOriginal
+}
+
+func (f *ASCIIFoldingFilter) IncrementToken() (bool, error) {
+	if f.state != nil {
+		f.Attributes().RestoreState(f.state)
+		f.posIncAtt.SetPositionIncrement(0)
+		f.state = nil
+		return true, nil
+	}
+	ok, err := f.Input.IncrementToken()
+	if err != nil || !ok {
+		return false, err
+	}
+	term := f.termAtt.Bytes()
+	// If no characters actually require rewriting then we
+	// just return token as-is:
+	for _, b := range term {
+		if b >= utf8.RuneSelf {
+			f.foldToASCII(term)
+			break
+		}
+	}
+	return true, nil
+}
+
+func (f *ASCIIFoldingFilter) Reset() error {
+	f.state = nil
+	return f.TokenFilter.Reset()
+}
+
+// Folds the term, which has non-ASCII characters, into the term
+// attribute, capturing the original first if it is to be preserved.
+func (f *ASCIIFoldingFilter) foldToASCII(term []byte) {
+	f.output = FoldToASCII(term, f.output[:0])
+	if string(f.output) == string(term) {
+		return
+	}
+	if f.preserveOriginal {
+		f.state = f.Attributes().CaptureState()
+	}
+	f.termAtt.CopyBytes(f.output)
+}
+
+/*
+Converts characters above ASCII to their ASCII equivalents. For
+example, accents are removed from accented characters. Appends the
+folded input to output, and returns the extended slice. Characters,
+or invalid UTF-8 bytes, without an ASCII equivalent are copied as is.
+*/
+func FoldToASCII(input, output []byte) []byte {
+	for len(input) > 0 {
+		// Quick test: if it's not in range then just keep current character
+		if b := input[0]; b < utf8.RuneSelf {
+			output = append(output, b)
+			input = input[1:]
+			continue
+		}
+		c, size := utf8.DecodeRune(input)
+		if folded, ok := asciiFoldings[c]; ok {
+			output = append(output, folded...)
+		} else {
+			output = append(output, input[:size]...)
+		}
+		input = input[size:]
+	}
+	return output
+}
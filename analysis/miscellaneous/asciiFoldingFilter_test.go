@@ -0,0 +1,43 @@
+package miscellaneous
+
+import (
+	"github.com/balzaczyy/golucene/analysis/analysistest"
+	"github.com/balzaczyy/golucene/analysis/core"
+	"strings"
+	"testing"
+)
+
+func TestFoldToASCII(t *testing.T) {
+	for _, v := range []struct{ input, output string }{
+		{"", ""},
+		{"plain ascii", "plain ascii"},
+		{"Des mot clés À LA CHAÎNE", "Des mot cles A LA CHAINE"},
+		{"Æ æ Œ œ ß Þ þ Ð ð Ø ø ĳ", "AE ae OE oe ss TH th D d O o ij"},
+		{"Łódź Dvořák Ærøskøbing", "Lodz Dvorak AEroskobing"},
+		{"Vĩnh Long ǅ ŉ", "Vinh Long Dz 'n"},
+		{"“quoted” ‘single’ — «guillemets»", "\"quoted\" 'single' - \"guillemets\""},
+		{"①⑴⒈ ²₃ ﬁ ＡＢＣ１２３！", "1(1)1. 23 fi ABC123!"},
+		{"中文 ελληνικά", "中文 ελληνικά"},
+		{"a\xffb", "a\xffb"},
+	} {
+		if s := string(FoldToASCII([]byte(v.input), nil)); s != v.output {
+			t.Errorf("%q: expected %q, but was %q", v.input, v.output, s)
+		}
+	}
+}
+
+func TestASCIIFoldingFilter(t *testing.T) {
+	ts := NewASCIIFoldingFilter(core.NewWhitespaceTokenizer(strings.NewReader("Des mot clés À LA CHAÎNE ǅ ①")))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"Des", "mot", "cles", "A", "LA", "CHAINE", "Dz", "1"},
+		[]int{0, 4, 8, 14, 17, 20, 28, 31}, []int{3, 7, 13, 16, 19, 27, 30, 34}, nil, []int{1, 1, 1, 1, 1, 1, 1, 1}, nil, 34)
+}
+
+func TestASCIIFoldingFilterPreserveOriginal(t *testing.T) {
+	ts := NewASCIIFoldingFilterWith(core.NewWhitespaceTokenizer(strings.NewReader("Des clés 中文")), true)
+	if !ts.PreserveOriginal() {
+		t.Error("should preserve original")
+	}
+	// unchanged tokens are not duplicated
+	analysistest.AssertTokenStreamContents(t, ts, []string{"Des", "cles", "clés", "中文"},
+		[]int{0, 4, 4, 10}, []int{3, 9, 9, 16}, nil, []int{1, 1, 0, 1}, nil, 16)
+}
@@ -0,0 +1,1221 @@
+package miscellaneous
+
+// The foldings of ASCIIFoldingFilter, from the Latin-1 Supplement,
+// Latin Extended, IPA and phonetic, punctuation, super- and subscript,
+// enclosed alphanumeric, alphabetic presentation and halfwidth and
+// fullwidth blocks to the ASCII characters they look like.
+var asciiFoldings = map[rune]string{
+	0x00AA: "a",    // ª [FEMININE ORDINAL INDICATOR]
+	0x00AB: "\"",   // « [LEFT-POINTING DOUBLE ANGLE QUOTATION MARK]
+	0x00B2: "2",    // ² [SUPERSCRIPT TWO]
+	0x00B3: "3",    // ³ [SUPERSCRIPT THREE]
+	0x00B9: "1",    // ¹ [SUPERSCRIPT ONE]
+	0x00BA: "o",    // º [MASCULINE ORDINAL INDICATOR]
+	0x00BB: "\"",   // » [RIGHT-POINTING DOUBLE ANGLE QUOTATION MARK]
+	0x00C0: "A",    // À [LATIN CAPITAL LETTER A WITH GRAVE]
+	0x00C1: "A",    // Á [LATIN CAPITAL LETTER A WITH ACUTE]
+	0x00C2: "A",    // Â [LATIN CAPITAL LETTER A WITH CIRCUMFLEX]
+	0x00C3: "A",    // Ã [LATIN CAPITAL LETTER A WITH TILDE]
+	0x00C4: "A",    // Ä [LATIN CAPITAL LETTER A WITH DIAERESIS]
+	0x00C5: "A",    // Å [LATIN CAPITAL LETTER A WITH RING ABOVE]
+	0x00C6: "AE",   // Æ [LATIN CAPITAL LETTER AE]
+	0x00C7: "C",    // Ç [LATIN CAPITAL LETTER C WITH CEDILLA]
+	0x00C8: "E",    // È [LATIN CAPITAL LETTER E WITH GRAVE]
+	0x00C9: "E",    // É [LATIN CAPITAL LETTER E WITH ACUTE]
+	0x00CA: "E",    // Ê [LATIN CAPITAL LETTER E WITH CIRCUMFLEX]
+	0x00CB: "E",    // Ë [LATIN CAPITAL LETTER E WITH DIAERESIS]
+	0x00CC: "I",    // Ì [LATIN CAPITAL LETTER I WITH GRAVE]
+	0x00CD: "I",    // Í [LATIN CAPITAL LETTER I WITH ACUTE]
+	0x00CE: "I",    // Î [LATIN CAPITAL LETTER I WITH CIRCUMFLEX]
+	0x00CF: "I",    // Ï [LATIN CAPITAL LETTER I WITH DIAERESIS]
+	0x00D0: "D",    // Ð [LATIN CAPITAL LETTER ETH]
+	0x00D1: "N",    // Ñ [LATIN CAPITAL LETTER N WITH TILDE]
+	0x00D2: "O",    // Ò [LATIN CAPITAL LETTER O WITH GRAVE]
+	0x00D3: "O",    // Ó [LATIN CAPITAL LETTER O WITH ACUTE]
+	0x00D4: "O",    // Ô [LATIN CAPITAL LETTER O WITH CIRCUMFLEX]
+	0x00D5: "O",    // Õ [LATIN CAPITAL LETTER O WITH TILDE]
+	0x00D6: "O",    // Ö [LATIN CAPITAL LETTER O WITH DIAERESIS]
+	0x00D8: "O",    // Ø [LATIN CAPITAL LETTER O WITH STROKE]
+	0x00D9: "U",    // Ù [LATIN CAPITAL LETTER U WITH GRAVE]
+	0x00DA: "U",    // Ú [LATIN CAPITAL LETTER U WITH ACUTE]
+	0x00DB: "U",    // Û [LATIN CAPITAL LETTER U WITH CIRCUMFLEX]
+	0x00DC: "U",    // Ü [LATIN CAPITAL LETTER U WITH DIAERESIS]
+	0x00DD: "Y",    // Ý [LATIN CAPITAL LETTER Y WITH ACUTE]
+	0x00DE: "TH",   // Þ [LATIN CAPITAL LETTER THORN]
+	0x00DF: "ss",   // ß [LATIN SMALL LETTER SHARP S]
+	0x00E0: "a",    // à [LATIN SMALL LETTER A WITH GRAVE]
+	0x00E1: "a",    // á [LATIN SMALL LETTER A WITH ACUTE]
+	0x00E2: "a",    // â [LATIN SMALL LETTER A WITH CIRCUMFLEX]
+	0x00E3: "a",    // ã [LATIN SMALL LETTER A WITH TILDE]
+	0x00E4: "a",    // ä [LATIN SMALL LETTER A WITH DIAERESIS]
+	0x00E5: "a",    // å [LATIN SMALL LETTER A WITH RING ABOVE]
+	0x00E6: "ae",   // æ [LATIN SMALL LETTER AE]
+	0x00E7: "c",    // ç [LATIN SMALL LETTER C WITH CEDILLA]
+	0x00E8: "e",    // è [LATIN SMALL LETTER E WITH GRAVE]
+	0x00E9: "e",    // é [LATIN SMALL LETTER E WITH ACUTE]
+	0x00EA: "e",    // ê [LATIN SMALL LETTER E WITH CIRCUMFLEX]
+	0x00EB: "e",    // ë [LATIN SMALL LETTER E WITH DIAERESIS]
+	0x00EC: "i",    // ì [LATIN SMALL LETTER I WITH GRAVE]
+	0x00ED: "i",    // í [LATIN SMALL LETTER I WITH ACUTE]
+	0x00EE: "i",    // î [LATIN SMALL LETTER I WITH CIRCUMFLEX]
+	0x00EF: "i",    // ï [LATIN SMALL LETTER I WITH DIAERESIS]
+	0x00F0: "d",    // ð [LATIN SMALL LETTER ETH]
+	0x00F1: "n",    // ñ [LATIN SMALL LETTER N WITH TILDE]
+	0x00F2: "o",    // ò [LATIN SMALL LETTER O WITH GRAVE]
+	0x00F3: "o",    // ó [LATIN SMALL LETTER O WITH ACUTE]
+	0x00F4: "o",    // ô [LATIN SMALL LETTER O WITH CIRCUMFLEX]
+	0x00F5: "o",    // õ [LATIN SMALL LETTER O WITH TILDE]
+	0x00F6: "o",    // ö [LATIN SMALL LETTER O WITH DIAERESIS]
+	0x00F8: "o",    // ø [LATIN SMALL LETTER O WITH STROKE]
+	0x00F9: "u",    // ù [LATIN SMALL LETTER U WITH GRAVE]
+	0x00FA: "u",    // ú [LATIN SMALL LETTER U WITH ACUTE]
+	0x00FB: "u",    // û [LATIN SMALL LETTER U WITH CIRCUMFLEX]
+	0x00FC: "u",    // ü [LATIN SMALL LETTER U WITH DIAERESIS]
+	0x00FD: "y",    // ý [LATIN SMALL LETTER Y WITH ACUTE]
+	0x00FE: "th",   // þ [LATIN SMALL LETTER THORN]
+	0x00FF: "y",    // ÿ [LATIN SMALL LETTER Y WITH DIAERESIS]
+	0x0100: "A",    // Ā [LATIN CAPITAL LETTER A WITH MACRON]
+	0x0101: "a",    // ā [LATIN SMALL LETTER A WITH MACRON]
+	0x0102: "A",    // Ă [LATIN CAPITAL LETTER A WITH BREVE]
+	0x0103: "a",    // ă [LATIN SMALL LETTER A WITH BREVE]
+	0x0104: "A",    // Ą [LATIN CAPITAL LETTER A WITH OGONEK]
+	0x0105: "a",    // ą [LATIN SMALL LETTER A WITH OGONEK]
+	0x0106: "C",    // Ć [LATIN CAPITAL LETTER C WITH ACUTE]
+	0x0107: "c",    // ć [LATIN SMALL LETTER C WITH ACUTE]
+	0x0108: "C",    // Ĉ [LATIN CAPITAL LETTER C WITH CIRCUMFLEX]
+	0x0109: "c",    // ĉ [LATIN SMALL LETTER C WITH CIRCUMFLEX]
+	0x010A: "C",    // Ċ [LATIN CAPITAL LETTER C WITH DOT ABOVE]
+	0x010B: "c",    // ċ [LATIN SMALL LETTER C WITH DOT ABOVE]
+	0x010C: "C",    // Č [LATIN CAPITAL LETTER C WITH CARON]
+	0x010D: "c",    // č [LATIN SMALL LETTER C WITH CARON]
+	0x010E: "D",    // Ď [LATIN CAPITAL LETTER D WITH CARON]
+	0x010F: "d",    // ď [LATIN SMALL LETTER D WITH CARON]
+	0x0110: "D",    // Đ [LATIN CAPITAL LETTER D WITH STROKE]
+	0x0111: "d",    // đ [LATIN SMALL LETTER D WITH STROKE]
+	0x0112: "E",    // Ē [LATIN CAPITAL LETTER E WITH MACRON]
+	0x0113: "e",    // ē [LATIN SMALL LETTER E WITH MACRON]
+	0x0114: "E",    // Ĕ [LATIN CAPITAL LETTER E WITH BREVE]
+	0x0115: "e",    // ĕ [LATIN SMALL LETTER E WITH BREVE]
+	0x0116: "E",    // Ė [LATIN CAPITAL LETTER E WITH DOT ABOVE]
+	0x0117: "e",    // ė [LATIN SMALL LETTER E WITH DOT ABOVE]
+	0x0118: "E",    // Ę [LATIN CAPITAL LETTER E WITH OGONEK]
+	0x0119: "e",    // ę [LATIN SMALL LETTER E WITH OGONEK]
+	0x011A: "E",    // Ě [LATIN CAPITAL LETTER E WITH CARON]
+	0x011B: "e",    // ě [LATIN SMALL LETTER E WITH CARON]
+	0x011C: "G",    // Ĝ [LATIN CAPITAL LETTER G WITH CIRCUMFLEX]
+	0x011D: "g",    // ĝ [LATIN SMALL LETTER G WITH CIRCUMFLEX]
+	0x011E: "G",    // Ğ [LATIN CAPITAL LETTER G WITH BREVE]
+	0x011F: "g",    // ğ [LATIN SMALL LETTER G WITH BREVE]
+	0x0120: "G",    // Ġ [LATIN CAPITAL LETTER G WITH DOT ABOVE]
+	0x0121: "g",    // ġ [LATIN SMALL LETTER G WITH DOT ABOVE]
+	0x0122: "G",    // Ģ [LATIN CAPITAL LETTER G WITH CEDILLA]
+	0x0123: "g",    // ģ [LATIN SMALL LETTER G WITH CEDILLA]
+	0x0124: "H",    // Ĥ [LATIN CAPITAL LETTER H WITH CIRCUMFLEX]
+	0x0125: "h",    // ĥ [LATIN SMALL LETTER H WITH CIRCUMFLEX]
+	0x0126: "H",    // Ħ [LATIN CAPITAL LETTER H WITH STROKE]
+	0x0127: "h",    // ħ [LATIN SMALL LETTER H WITH STROKE]
+	0x0128: "I",    // Ĩ [LATIN CAPITAL LETTER I WITH TILDE]
+	0x0129: "i",    // ĩ [LATIN SMALL LETTER I WITH TILDE]
+	0x012A: "I",    // Ī [LATIN CAPITAL LETTER I WITH MACRON]
+	0x012B: "i",    // ī [LATIN SMALL LETTER I WITH MACRON]
+	0x012C: "I",    // Ĭ [LATIN CAPITAL LETTER I WITH BREVE]
+	0x012D: "i",    // ĭ [LATIN SMALL LETTER I WITH BREVE]
+	0x012E: "I",    // Į [LATIN CAPITAL LETTER I WITH OGONEK]
+	0x012F: "i",    // į [LATIN SMALL LETTER I WITH OGONEK]
+	0x0130: "I",    // İ [LATIN CAPITAL LETTER I WITH DOT ABOVE]
+	0x0131: "i",    // ı [LATIN SMALL LETTER DOTLESS I]
+	0x0132: "IJ",   // Ĳ [LATIN CAPITAL LIGATURE IJ]
+	0x0133: "ij",   // ĳ [LATIN SMALL LIGATURE IJ]
+	0x0134: "J",    // Ĵ [LATIN CAPITAL LETTER J WITH CIRCUMFLEX]
+	0x0135: "j",    // ĵ [LATIN SMALL LETTER J WITH CIRCUMFLEX]
+	0x0136: "K",    // Ķ [LATIN CAPITAL LETTER K WITH CEDILLA]
+	0x0137: "k",    // ķ [LATIN SMALL LETTER K WITH CEDILLA]
+	0x0138: "q",    // ĸ [LATIN SMALL LETTER KRA]
+	0x0139: "L",    // Ĺ [LATIN CAPITAL LETTER L WITH ACUTE]
+	0x013A: "l",    // ĺ [LATIN SMALL LETTER L WITH ACUTE]
+	0x013B: "L",    // Ļ [LATIN CAPITAL LETTER L WITH CEDILLA]
+	0x013C: "l",    // ļ [LATIN SMALL LETTER L WITH CEDILLA]
+	0x013D: "L",    // Ľ [LATIN CAPITAL LETTER L WITH CARON]
+	0x013E: "l",    // ľ [LATIN SMALL LETTER L WITH CARON]
+	0x013F: "L",    // Ŀ [LATIN CAPITAL LETTER L WITH MIDDLE DOT]
+	0x0140: "l",    // ŀ [LATIN SMALL LETTER L WITH MIDDLE DOT]
+	0x0141: "L",    // Ł [LATIN CAPITAL LETTER L WITH STROKE]
+	0x0142: "l",    // ł [LATIN SMALL LETTER L WITH STROKE]
+	0x0143: "N",    // Ń [LATIN CAPITAL LETTER N WITH ACUTE]
+	0x0144: "n",    // ń [LATIN SMALL LETTER N WITH ACUTE]
+	0x0145: "N",    // Ņ [LATIN CAPITAL LETTER N WITH CEDILLA]
+	0x0146: "n",    // ņ [LATIN SMALL LETTER N WITH CEDILLA]
+	0x0147: "N",    // Ň [LATIN CAPITAL LETTER N WITH CARON]
+	0x0148: "n",    // ň [LATIN SMALL LETTER N WITH CARON]
+	0x0149: "'n",   // ŉ [LATIN SMALL LETTER N PRECEDED BY APOSTROPHE]
+	0x014A: "N",    // Ŋ [LATIN CAPITAL LETTER ENG]
+	0x014B: "n",    // ŋ [LATIN SMALL LETTER ENG]
+	0x014C: "O",    // Ō [LATIN CAPITAL LETTER O WITH MACRON]
+	0x014D: "o",    // ō [LATIN SMALL LETTER O WITH MACRON]
+	0x014E: "O",    // Ŏ [LATIN CAPITAL LETTER O WITH BREVE]
+	0x014F: "o",    // ŏ [LATIN SMALL LETTER O WITH BREVE]
+	0x0150: "O",    // Ő [LATIN CAPITAL LETTER O WITH DOUBLE ACUTE]
+	0x0151: "o",    // ő [LATIN SMALL LETTER O WITH DOUBLE ACUTE]
+	0x0152: "OE",   // Œ [LATIN CAPITAL LIGATURE OE]
+	0x0153: "oe",   // œ [LATIN SMALL LIGATURE OE]
+	0x0154: "R",    // Ŕ [LATIN CAPITAL LETTER R WITH ACUTE]
+	0x0155: "r",    // ŕ [LATIN SMALL LETTER R WITH ACUTE]
+	0x0156: "R",    // Ŗ [LATIN CAPITAL LETTER R WITH CEDILLA]
+	0x0157: "r",    // ŗ [LATIN SMALL LETTER R WITH CEDILLA]
+	0x0158: "R",    // Ř [LATIN CAPITAL LETTER R WITH CARON]
+	0x0159: "r",    // ř [LATIN SMALL LETTER R WITH CARON]
+	0x015A: "S",    // Ś [LATIN CAPITAL LETTER S WITH ACUTE]
+	0x015B: "s",    // ś [LATIN SMALL LETTER S WITH ACUTE]
+	0x015C: "S",    // Ŝ [LATIN CAPITAL LETTER S WITH CIRCUMFLEX]
+	0x015D: "s",    // ŝ [LATIN SMALL LETTER S WITH CIRCUMFLEX]
+	0x015E: "S",    // Ş [LATIN CAPITAL LETTER S WITH CEDILLA]
+	0x015F: "s",    // ş [LATIN SMALL LETTER S WITH CEDILLA]
+	0x0160: "S",    // Š [LATIN CAPITAL LETTER S WITH CARON]
+	0x0161: "s",    // š [LATIN SMALL LETTER S WITH CARON]
+	0x0162: "T",    // Ţ [LATIN CAPITAL LETTER T WITH CEDILLA]
+	0x0163: "t",    // ţ [LATIN SMALL LETTER T WITH CEDILLA]
+	0x0164: "T",    // Ť [LATIN CAPITAL LETTER T WITH CARON]
+	0x0165: "t",    // ť [LATIN SMALL LETTER T WITH CARON]
+	0x0166: "T",    // Ŧ [LATIN CAPITAL LETTER T WITH STROKE]
+	0x0167: "t",    // ŧ [LATIN SMALL LETTER T WITH STROKE]
+	0x0168: "U",    // Ũ [LATIN CAPITAL LETTER U WITH TILDE]
+	0x0169: "u",    // ũ [LATIN SMALL LETTER U WITH TILDE]
+	0x016A: "U",    // Ū [LATIN CAPITAL LETTER U WITH MACRON]
+	0x016B: "u",    // ū [LATIN SMALL LETTER U WITH MACRON]
+	0x016C: "U",    // Ŭ [LATIN CAPITAL LETTER U WITH BREVE]
+	0x016D: "u",    // ŭ [LATIN SMALL LETTER U WITH BREVE]
+	0x016E: "U",    // Ů [LATIN CAPITAL LETTER U WITH RING ABOVE]
+	0x016F: "u",    // ů [LATIN SMALL LETTER U WITH RING ABOVE]
+	0x0170: "U",    // Ű [LATIN CAPITAL LETTER U WITH DOUBLE ACUTE]
+	0x0171: "u",    // ű [LATIN SMALL LETTER U WITH DOUBLE ACUTE]
+	0x0172: "U",    // Ų [LATIN CAPITAL LETTER U WITH OGONEK]
+	0x0173: "u",    // ų [LATIN SMALL LETTER U WITH OGONEK]
+	0x0174: "W",    // Ŵ [LATIN CAPITAL LETTER W WITH CIRCUMFLEX]
+	0x0175: "w",    // ŵ [LATIN SMALL LETTER W WITH CIRCUMFLEX]
+	0x0176: "Y",    // Ŷ [LATIN CAPITAL LETTER Y WITH CIRCUMFLEX]
+	0x0177: "y",    // ŷ [LATIN SMALL LETTER Y WITH CIRCUMFLEX]
+	0x0178: "Y",    // Ÿ [LATIN CAPITAL LETTER Y WITH DIAERESIS]
+	0x0179: "Z",    // Ź [LATIN CAPITAL LETTER Z WITH ACUTE]
+	0x017A: "z",    // ź [LATIN SMALL LETTER Z WITH ACUTE]
+	0x017B: "Z",    // Ż [LATIN CAPITAL LETTER Z WITH DOT ABOVE]
+	0x017C: "z",    // ż [LATIN SMALL LETTER Z WITH DOT ABOVE]
+	0x017D: "Z",    // Ž [LATIN CAPITAL LETTER Z WITH CARON]
+	0x017E: "z",    // ž [LATIN SMALL LETTER Z WITH CARON]
+	0x017F: "s",    // ſ [LATIN SMALL LETTER LONG S]
+	0x0180: "b",    // ƀ [LATIN SMALL LETTER B WITH STROKE]
+	0x0181: "B",    // Ɓ [LATIN CAPITAL LETTER B WITH HOOK]
+	0x0182: "B",    // Ƃ [LATIN CAPITAL LETTER B WITH TOPBAR]
+	0x0183: "b",    // ƃ [LATIN SMALL LETTER B WITH TOPBAR]
+	0x0186: "O",    // Ɔ [LATIN CAPITAL LETTER OPEN O]
+	0x0187: "C",    // Ƈ [LATIN CAPITAL LETTER C WITH HOOK]
+	0x0188: "c",    // ƈ [LATIN SMALL LETTER C WITH HOOK]
+	0x018A: "D",    // Ɗ [LATIN CAPITAL LETTER D WITH HOOK]
+	0x018B: "D",    // Ƌ [LATIN CAPITAL LETTER D WITH TOPBAR]
+	0x018C: "d",    // ƌ [LATIN SMALL LETTER D WITH TOPBAR]
+	0x018E: "E",    // Ǝ [LATIN CAPITAL LETTER REVERSED E]
+	0x018F: "E",    // Ə [LATIN CAPITAL LETTER SCHWA]
+	0x0190: "E",    // Ɛ [LATIN CAPITAL LETTER OPEN E]
+	0x0191: "F",    // Ƒ [LATIN CAPITAL LETTER F WITH HOOK]
+	0x0192: "f",    // ƒ [LATIN SMALL LETTER F WITH HOOK]
+	0x0193: "G",    // Ɠ [LATIN CAPITAL LETTER G WITH HOOK]
+	0x0195: "hv",   // ƕ [LATIN SMALL LETTER HV]
+	0x0197: "I",    // Ɨ [LATIN CAPITAL LETTER I WITH STROKE]
+	0x0198: "K",    // Ƙ [LATIN CAPITAL LETTER K WITH HOOK]
+	0x0199: "k",    // ƙ [LATIN SMALL LETTER K WITH HOOK]
+	0x019A: "l",    // ƚ [LATIN SMALL LETTER L WITH BAR]
+	0x019C: "M",    // Ɯ [LATIN CAPITAL LETTER TURNED M]
+	0x019D: "N",    // Ɲ [LATIN CAPITAL LETTER N WITH LEFT HOOK]
+	0x019E: "n",    // ƞ [LATIN SMALL LETTER N WITH LONG RIGHT LEG]
+	0x019F: "O",    // Ɵ [LATIN CAPITAL LETTER O WITH MIDDLE TILDE]
+	0x01A0: "O",    // Ơ [LATIN CAPITAL LETTER O WITH HORN]
+	0x01A1: "o",    // ơ [LATIN SMALL LETTER O WITH HORN]
+	0x01A4: "P",    // Ƥ [LATIN CAPITAL LETTER P WITH HOOK]
+	0x01A5: "p",    // ƥ [LATIN SMALL LETTER P WITH HOOK]
+	0x01AB: "t",    // ƫ [LATIN SMALL LETTER T WITH PALATAL HOOK]
+	0x01AC: "T",    // Ƭ [LATIN CAPITAL LETTER T WITH HOOK]
+	0x01AD: "t",    // ƭ [LATIN SMALL LETTER T WITH HOOK]
+	0x01AE: "T",    // Ʈ [LATIN CAPITAL LETTER T WITH RETROFLEX HOOK]
+	0x01AF: "U",    // Ư [LATIN CAPITAL LETTER U WITH HORN]
+	0x01B0: "u",    // ư [LATIN SMALL LETTER U WITH HORN]
+	0x01B2: "V",    // Ʋ [LATIN CAPITAL LETTER V WITH HOOK]
+	0x01B3: "Y",    // Ƴ [LATIN CAPITAL LETTER Y WITH HOOK]
+	0x01B4: "y",    // ƴ [LATIN SMALL LETTER Y WITH HOOK]
+	0x01B5: "Z",    // Ƶ [LATIN CAPITAL LETTER Z WITH STROKE]
+	0x01B6: "z",    // ƶ [LATIN SMALL LETTER Z WITH STROKE]
+	0x01C4: "DZ",   // Ǆ [LATIN CAPITAL LETTER DZ WITH CARON]
+	0x01C5: "Dz",   // ǅ [LATIN CAPITAL LETTER D WITH SMALL LETTER Z WITH CARON]
+	0x01C6: "dz",   // ǆ [LATIN SMALL LETTER DZ WITH CARON]
+	0x01C7: "LJ",   // Ǉ [LATIN CAPITAL LETTER LJ]
+	0x01C8: "Lj",   // ǈ [LATIN CAPITAL LETTER L WITH SMALL LETTER J]
+	0x01C9: "lj",   // ǉ [LATIN SMALL LETTER LJ]
+	0x01CA: "NJ",   // Ǌ [LATIN CAPITAL LETTER NJ]
+	0x01CB: "Nj",   // ǋ [LATIN CAPITAL LETTER N WITH SMALL LETTER J]
+	0x01CC: "nj",   // ǌ [LATIN SMALL LETTER NJ]
+	0x01CD: "A",    // Ǎ [LATIN CAPITAL LETTER A WITH CARON]
+	0x01CE: "a",    // ǎ [LATIN SMALL LETTER A WITH CARON]
+	0x01CF: "I",    // Ǐ [LATIN CAPITAL LETTER I WITH CARON]
+	0x01D0: "i",    // ǐ [LATIN SMALL LETTER I WITH CARON]
+	0x01D1: "O",    // Ǒ [LATIN CAPITAL LETTER O WITH CARON]
+	0x01D2: "o",    // ǒ [LATIN SMALL LETTER O WITH CARON]
+	0x01D3: "U",    // Ǔ [LATIN CAPITAL LETTER U WITH CARON]
+	0x01D4: "u",    // ǔ [LATIN SMALL LETTER U WITH CARON]
+	0x01D5: "U",    // Ǖ [LATIN CAPITAL LETTER U WITH DIAERESIS AND MACRON]
+	0x01D6: "u",    // ǖ [LATIN SMALL LETTER U WITH DIAERESIS AND MACRON]
+	0x01D7: "U",    // Ǘ [LATIN CAPITAL LETTER U WITH DIAERESIS AND ACUTE]
+	0x01D8: "u",    // ǘ [LATIN SMALL LETTER U WITH DIAERESIS AND ACUTE]
+	0x01D9: "U",    // Ǚ [LATIN CAPITAL LETTER U WITH DIAERESIS AND CARON]
+	0x01DA: "u",    // ǚ [LATIN SMALL LETTER U WITH DIAERESIS AND CARON]
+	0x01DB: "U",    // Ǜ [LATIN CAPITAL LETTER U WITH DIAERESIS AND GRAVE]
+	0x01DC: "u",    // ǜ [LATIN SMALL LETTER U WITH DIAERESIS AND GRAVE]
+	0x01DD: "e",    // ǝ [LATIN SMALL LETTER TURNED E]
+	0x01DE: "A",    // Ǟ [LATIN CAPITAL LETTER A WITH DIAERESIS AND MACRON]
+	0x01DF: "a",    // ǟ [LATIN SMALL LETTER A WITH DIAERESIS AND MACRON]
+	0x01E0: "A",    // Ǡ [LATIN CAPITAL LETTER A WITH DOT ABOVE AND MACRON]
+	0x01E1: "a",    // ǡ [LATIN SMALL LETTER A WITH DOT ABOVE AND MACRON]
+	0x01E2: "AE",   // Ǣ [LATIN CAPITAL LETTER AE WITH MACRON]
+	0x01E3: "ae",   // ǣ [LATIN SMALL LETTER AE WITH MACRON]
+	0x01E4: "G",    // Ǥ [LATIN CAPITAL LETTER G WITH STROKE]
+	0x01E5: "g",    // ǥ [LATIN SMALL LETTER G WITH STROKE]
+	0x01E6: "G",    // Ǧ [LATIN CAPITAL LETTER G WITH CARON]
+	0x01E7: "g",    // ǧ [LATIN SMALL LETTER G WITH CARON]
+	0x01E8: "K",    // Ǩ [LATIN CAPITAL LETTER K WITH CARON]
+	0x01E9: "k",    // ǩ [LATIN SMALL LETTER K WITH CARON]
+	0x01EA: "O",    // Ǫ [LATIN CAPITAL LETTER O WITH OGONEK]
+	0x01EB: "o",    // ǫ [LATIN SMALL LETTER O WITH OGONEK]
+	0x01EC: "O",    // Ǭ [LATIN CAPITAL LETTER O WITH OGONEK AND MACRON]
+	0x01ED: "o",    // ǭ [LATIN SMALL LETTER O WITH OGONEK AND MACRON]
+	0x01F0: "j",    // ǰ [LATIN SMALL LETTER J WITH CARON]
+	0x01F1: "DZ",   // Ǳ [LATIN CAPITAL LETTER DZ]
+	0x01F2: "Dz",   // ǲ [LATIN CAPITAL LETTER D WITH SMALL LETTER Z]
+	0x01F3: "dz",   // ǳ [LATIN SMALL LETTER DZ]
+	0x01F4: "G",    // Ǵ [LATIN CAPITAL LETTER G WITH ACUTE]
+	0x01F5: "g",    // ǵ [LATIN SMALL LETTER G WITH ACUTE]
+	0x01F8: "N",    // Ǹ [LATIN CAPITAL LETTER N WITH GRAVE]
+	0x01F9: "n",    // ǹ [LATIN SMALL LETTER N WITH GRAVE]
+	0x01FA: "A",    // Ǻ [LATIN CAPITAL LETTER A WITH RING ABOVE AND ACUTE]
+	0x01FB: "a",    // ǻ [LATIN SMALL LETTER A WITH RING ABOVE AND ACUTE]
+	0x01FC: "AE",   // Ǽ [LATIN CAPITAL LETTER AE WITH ACUTE]
+	0x01FD: "ae",   // ǽ [LATIN SMALL LETTER AE WITH ACUTE]
+	0x01FE: "O",    // Ǿ [LATIN CAPITAL LETTER O WITH STROKE AND ACUTE]
+	0x01FF: "o",    // ǿ [LATIN SMALL LETTER O WITH STROKE AND ACUTE]
+	0x0200: "A",    // Ȁ [LATIN CAPITAL LETTER A WITH DOUBLE GRAVE]
+	0x0201: "a",    // ȁ [LATIN SMALL LETTER A WITH DOUBLE GRAVE]
+	0x0202: "A",    // Ȃ [LATIN CAPITAL LETTER A WITH INVERTED BREVE]
+	0x0203: "a",    // ȃ [LATIN SMALL LETTER A WITH INVERTED BREVE]
+	0x0204: "E",    // Ȅ [LATIN CAPITAL LETTER E WITH DOUBLE GRAVE]
+	0x0205: "e",    // ȅ [LATIN SMALL LETTER E WITH DOUBLE GRAVE]
+	0x0206: "E",    // Ȇ [LATIN CAPITAL LETTER E WITH INVERTED BREVE]
+	0x0207: "e",    // ȇ [LATIN SMALL LETTER E WITH INVERTED BREVE]
+	0x0208: "I",    // Ȉ [LATIN CAPITAL LETTER I WITH DOUBLE GRAVE]
+	0x0209: "i",    // ȉ [LATIN SMALL LETTER I WITH DOUBLE GRAVE]
+	0x020A: "I",    // Ȋ [LATIN CAPITAL LETTER I WITH INVERTED BREVE]
+	0x020B: "i",    // ȋ [LATIN SMALL LETTER I WITH INVERTED BREVE]
+	0x020C: "O",    // Ȍ [LATIN CAPITAL LETTER O WITH DOUBLE GRAVE]
+	0x020D: "o",    // ȍ [LATIN SMALL LETTER O WITH DOUBLE GRAVE]
+	0x020E: "O",    // Ȏ [LATIN CAPITAL LETTER O WITH INVERTED BREVE]
+	0x020F: "o",    // ȏ [LATIN SMALL LETTER O WITH INVERTED BREVE]
+	0x0210: "R",    // Ȑ [LATIN CAPITAL LETTER R WITH DOUBLE GRAVE]
+	0x0211: "r",    // ȑ [LATIN SMALL LETTER R WITH DOUBLE GRAVE]
+	0x0212: "R",    // Ȓ [LATIN CAPITAL LETTER R WITH INVERTED BREVE]
+	0x0213: "r",    // ȓ [LATIN SMALL LETTER R WITH INVERTED BREVE]
+	0x0214: "U",    // Ȕ [LATIN CAPITAL LETTER U WITH DOUBLE GRAVE]
+	0x0215: "u",    // ȕ [LATIN SMALL LETTER U WITH DOUBLE GRAVE]
+	0x0216: "U",    // Ȗ [LATIN CAPITAL LETTER U WITH INVERTED BREVE]
+	0x0217: "u",    // ȗ [LATIN SMALL LETTER U WITH INVERTED BREVE]
+	0x0218: "S",    // Ș [LATIN CAPITAL LETTER S WITH COMMA BELOW]
+	0x0219: "s",    // ș [LATIN SMALL LETTER S WITH COMMA BELOW]
+	0x021A: "T",    // Ț [LATIN CAPITAL LETTER T WITH COMMA BELOW]
+	0x021B: "t",    // ț [LATIN SMALL LETTER T WITH COMMA BELOW]
+	0x021E: "H",    // Ȟ [LATIN CAPITAL LETTER H WITH CARON]
+	0x021F: "h",    // ȟ [LATIN SMALL LETTER H WITH CARON]
+	0x0220: "N",    // Ƞ [LATIN CAPITAL LETTER N WITH LONG RIGHT LEG]
+	0x0221: "d",    // ȡ [LATIN SMALL LETTER D WITH CURL]
+	0x0222: "OU",   // Ȣ [LATIN CAPITAL LETTER OU]
+	0x0223: "ou",   // ȣ [LATIN SMALL LETTER OU]
+	0x0224: "Z",    // Ȥ [LATIN CAPITAL LETTER Z WITH HOOK]
+	0x0225: "z",    // ȥ [LATIN SMALL LETTER Z WITH HOOK]
+	0x0226: "A",    // Ȧ [LATIN CAPITAL LETTER A WITH DOT ABOVE]
+	0x0227: "a",    // ȧ [LATIN SMALL LETTER A WITH DOT ABOVE]
+	0x0228: "E",    // Ȩ [LATIN CAPITAL LETTER E WITH CEDILLA]
+	0x0229: "e",    // ȩ [LATIN SMALL LETTER E WITH CEDILLA]
+	0x022A: "O",    // Ȫ [LATIN CAPITAL LETTER O WITH DIAERESIS AND MACRON]
+	0x022B: "o",    // ȫ [LATIN SMALL LETTER O WITH DIAERESIS AND MACRON]
+	0x022C: "O",    // Ȭ [LATIN CAPITAL LETTER O WITH TILDE AND MACRON]
+	0x022D: "o",    // ȭ [LATIN SMALL LETTER O WITH TILDE AND MACRON]
+	0x022E: "O",    // Ȯ [LATIN CAPITAL LETTER O WITH DOT ABOVE]
+	0x022F: "o",    // ȯ [LATIN SMALL LETTER O WITH DOT ABOVE]
+	0x0230: "O",    // Ȱ [LATIN CAPITAL LETTER O WITH DOT ABOVE AND MACRON]
+	0x0231: "o",    // ȱ [LATIN SMALL LETTER O WITH DOT ABOVE AND MACRON]
+	0x0232: "Y",    // Ȳ [LATIN CAPITAL LETTER Y WITH MACRON]
+	0x0233: "y",    // ȳ [LATIN SMALL LETTER Y WITH MACRON]
+	0x0234: "l",    // ȴ [LATIN SMALL LETTER L WITH CURL]
+	0x0235: "n",    // ȵ [LATIN SMALL LETTER N WITH CURL]
+	0x0236: "t",    // ȶ [LATIN SMALL LETTER T WITH CURL]
+	0x0237: "j",    // ȷ [LATIN SMALL LETTER DOTLESS J]
+	0x023A: "A",    // Ⱥ [LATIN CAPITAL LETTER A WITH STROKE]
+	0x023B: "C",    // Ȼ [LATIN CAPITAL LETTER C WITH STROKE]
+	0x023C: "c",    // ȼ [LATIN SMALL LETTER C WITH STROKE]
+	0x023D: "L",    // Ƚ [LATIN CAPITAL LETTER L WITH BAR]
+	0x023E: "T",    // Ⱦ [LATIN CAPITAL LETTER T WITH DIAGONAL STROKE]
+	0x023F: "s",    // ȿ [LATIN SMALL LETTER S WITH SWASH TAIL]
+	0x0240: "z",    // ɀ [LATIN SMALL LETTER Z WITH SWASH TAIL]
+	0x0243: "B",    // Ƀ [LATIN CAPITAL LETTER B WITH STROKE]
+	0x0245: "V",    // Ʌ [LATIN CAPITAL LETTER TURNED V]
+	0x0246: "E",    // Ɇ [LATIN CAPITAL LETTER E WITH STROKE]
+	0x0247: "e",    // ɇ [LATIN SMALL LETTER E WITH STROKE]
+	0x0248: "J",    // Ɉ [LATIN CAPITAL LETTER J WITH STROKE]
+	0x0249: "j",    // ɉ [LATIN SMALL LETTER J WITH STROKE]
+	0x024B: "q",    // ɋ [LATIN SMALL LETTER Q WITH HOOK TAIL]
+	0x024C: "R",    // Ɍ [LATIN CAPITAL LETTER R WITH STROKE]
+	0x024D: "r",    // ɍ [LATIN SMALL LETTER R WITH STROKE]
+	0x024E: "Y",    // Ɏ [LATIN CAPITAL LETTER Y WITH STROKE]
+	0x024F: "y",    // ɏ [LATIN SMALL LETTER Y WITH STROKE]
+	0x0250: "a",    // ɐ [LATIN SMALL LETTER TURNED A]
+	0x0253: "b",    // ɓ [LATIN SMALL LETTER B WITH HOOK]
+	0x0254: "o",    // ɔ [LATIN SMALL LETTER OPEN O]
+	0x0255: "c",    // ɕ [LATIN SMALL LETTER C WITH CURL]
+	0x0256: "d",    // ɖ [LATIN SMALL LETTER D WITH TAIL]
+	0x0257: "d",    // ɗ [LATIN SMALL LETTER D WITH HOOK]
+	0x0258: "e",    // ɘ [LATIN SMALL LETTER REVERSED E]
+	0x0259: "e",    // ə [LATIN SMALL LETTER SCHWA]
+	0x025A: "e",    // ɚ [LATIN SMALL LETTER SCHWA WITH HOOK]
+	0x025B: "e",    // ɛ [LATIN SMALL LETTER OPEN E]
+	0x025F: "j",    // ɟ [LATIN SMALL LETTER DOTLESS J WITH STROKE]
+	0x0260: "g",    // ɠ [LATIN SMALL LETTER G WITH HOOK]
+	0x0261: "g",    // ɡ [LATIN SMALL LETTER SCRIPT G]
+	0x0262: "G",    // ɢ [LATIN LETTER SMALL CAPITAL G]
+	0x0265: "h",    // ɥ [LATIN SMALL LETTER TURNED H]
+	0x0266: "h",    // ɦ [LATIN SMALL LETTER H WITH HOOK]
+	0x0268: "i",    // ɨ [LATIN SMALL LETTER I WITH STROKE]
+	0x026A: "I",    // ɪ [LATIN LETTER SMALL CAPITAL I]
+	0x026B: "l",    // ɫ [LATIN SMALL LETTER L WITH MIDDLE TILDE]
+	0x026C: "l",    // ɬ [LATIN SMALL LETTER L WITH BELT]
+	0x026D: "l",    // ɭ [LATIN SMALL LETTER L WITH RETROFLEX HOOK]
+	0x026F: "m",    // ɯ [LATIN SMALL LETTER TURNED M]
+	0x0270: "m",    // ɰ [LATIN SMALL LETTER TURNED M WITH LONG LEG]
+	0x0271: "m",    // ɱ [LATIN SMALL LETTER M WITH HOOK]
+	0x0272: "n",    // ɲ [LATIN SMALL LETTER N WITH LEFT HOOK]
+	0x0273: "n",    // ɳ [LATIN SMALL LETTER N WITH RETROFLEX HOOK]
+	0x0274: "N",    // ɴ [LATIN LETTER SMALL CAPITAL N]
+	0x0276: "OE",   // ɶ [LATIN LETTER SMALL CAPITAL OE]
+	0x0279: "r",    // ɹ [LATIN SMALL LETTER TURNED R]
+	0x027A: "r",    // ɺ [LATIN SMALL LETTER TURNED R WITH LONG LEG]
+	0x027B: "r",    // ɻ [LATIN SMALL LETTER TURNED R WITH HOOK]
+	0x027C: "r",    // ɼ [LATIN SMALL LETTER R WITH LONG LEG]
+	0x027D: "r",    // ɽ [LATIN SMALL LETTER R WITH TAIL]
+	0x027E: "r",    // ɾ [LATIN SMALL LETTER R WITH FISHHOOK]
+	0x027F: "r",    // ɿ [LATIN SMALL LETTER REVERSED R WITH FISHHOOK]
+	0x0280: "R",    // ʀ [LATIN LETTER SMALL CAPITAL R]
+	0x0282: "s",    // ʂ [LATIN SMALL LETTER S WITH HOOK]
+	0x0284: "j",    // ʄ [LATIN SMALL LETTER DOTLESS J WITH STROKE AND HOOK]
+	0x0287: "t",    // ʇ [LATIN SMALL LETTER TURNED T]
+	0x0288: "t",    // ʈ [LATIN SMALL LETTER T WITH RETROFLEX HOOK]
+	0x028B: "v",    // ʋ [LATIN SMALL LETTER V WITH HOOK]
+	0x028C: "v",    // ʌ [LATIN SMALL LETTER TURNED V]
+	0x028D: "w",    // ʍ [LATIN SMALL LETTER TURNED W]
+	0x028E: "y",    // ʎ [LATIN SMALL LETTER TURNED Y]
+	0x028F: "Y",    // ʏ [LATIN LETTER SMALL CAPITAL Y]
+	0x0290: "z",    // ʐ [LATIN SMALL LETTER Z WITH RETROFLEX HOOK]
+	0x0291: "z",    // ʑ [LATIN SMALL LETTER Z WITH CURL]
+	0x0299: "B",    // ʙ [LATIN LETTER SMALL CAPITAL B]
+	0x029C: "H",    // ʜ [LATIN LETTER SMALL CAPITAL H]
+	0x029D: "j",    // ʝ [LATIN SMALL LETTER J WITH CROSSED-TAIL]
+	0x029E: "k",    // ʞ [LATIN SMALL LETTER TURNED K]
+	0x029F: "L",    // ʟ [LATIN LETTER SMALL CAPITAL L]
+	0x02A0: "q",    // ʠ [LATIN SMALL LETTER Q WITH HOOK]
+	0x02AE: "h",    // ʮ [LATIN SMALL LETTER TURNED H WITH FISHHOOK]
+	0x02AF: "h",    // ʯ [LATIN SMALL LETTER TURNED H WITH FISHHOOK AND TAIL]
+	0x1D00: "A",    // ᴀ [LATIN LETTER SMALL CAPITAL A]
+	0x1D01: "AE",   // ᴁ [LATIN LETTER SMALL CAPITAL AE]
+	0x1D02: "ae",   // ᴂ [LATIN SMALL LETTER TURNED AE]
+	0x1D04: "C",    // ᴄ [LATIN LETTER SMALL CAPITAL C]
+	0x1D05: "D",    // ᴅ [LATIN LETTER SMALL CAPITAL D]
+	0x1D07: "E",    // ᴇ [LATIN LETTER SMALL CAPITAL E]
+	0x1D09: "i",    // ᴉ [LATIN SMALL LETTER TURNED I]
+	0x1D0A: "J",    // ᴊ [LATIN LETTER SMALL CAPITAL J]
+	0x1D0B: "K",    // ᴋ [LATIN LETTER SMALL CAPITAL K]
+	0x1D0D: "M",    // ᴍ [LATIN LETTER SMALL CAPITAL M]
+	0x1D0F: "O",    // ᴏ [LATIN LETTER SMALL CAPITAL O]
+	0x1D11: "o",    // ᴑ [LATIN SMALL LETTER SIDEWAYS O]
+	0x1D13: "o",    // ᴓ [LATIN SMALL LETTER SIDEWAYS O WITH STROKE]
+	0x1D14: "oe",   // ᴔ [LATIN SMALL LETTER TURNED OE]
+	0x1D15: "OU",   // ᴕ [LATIN LETTER SMALL CAPITAL OU]
+	0x1D18: "P",    // ᴘ [LATIN LETTER SMALL CAPITAL P]
+	0x1D1B: "T",    // ᴛ [LATIN LETTER SMALL CAPITAL T]
+	0x1D1C: "U",    // ᴜ [LATIN LETTER SMALL CAPITAL U]
+	0x1D1D: "u",    // ᴝ [LATIN SMALL LETTER SIDEWAYS U]
+	0x1D20: "V",    // ᴠ [LATIN LETTER SMALL CAPITAL V]
+	0x1D21: "W",    // ᴡ [LATIN LETTER SMALL CAPITAL W]
+	0x1D22: "Z",    // ᴢ [LATIN LETTER SMALL CAPITAL Z]
+	0x1D2C: "A",    // ᴬ [MODIFIER LETTER CAPITAL A]
+	0x1D2E: "B",    // ᴮ [MODIFIER LETTER CAPITAL B]
+	0x1D30: "D",    // ᴰ [MODIFIER LETTER CAPITAL D]
+	0x1D31: "E",    // ᴱ [MODIFIER LETTER CAPITAL E]
+	0x1D33: "G",    // ᴳ [MODIFIER LETTER CAPITAL G]
+	0x1D34: "H",    // ᴴ [MODIFIER LETTER CAPITAL H]
+	0x1D35: "I",    // ᴵ [MODIFIER LETTER CAPITAL I]
+	0x1D36: "J",    // ᴶ [MODIFIER LETTER CAPITAL J]
+	0x1D37: "K",    // ᴷ [MODIFIER LETTER CAPITAL K]
+	0x1D38: "L",    // ᴸ [MODIFIER LETTER CAPITAL L]
+	0x1D39: "M",    // ᴹ [MODIFIER LETTER CAPITAL M]
+	0x1D3A: "N",    // ᴺ [MODIFIER LETTER CAPITAL N]
+	0x1D3C: "O",    // ᴼ [MODIFIER LETTER CAPITAL O]
+	0x1D3E: "P",    // ᴾ [MODIFIER LETTER CAPITAL P]
+	0x1D3F: "R",    // ᴿ [MODIFIER LETTER CAPITAL R]
+	0x1D40: "T",    // ᵀ [MODIFIER LETTER CAPITAL T]
+	0x1D41: "U",    // ᵁ [MODIFIER LETTER CAPITAL U]
+	0x1D42: "W",    // ᵂ [MODIFIER LETTER CAPITAL W]
+	0x1D43: "a",    // ᵃ [MODIFIER LETTER SMALL A]
+	0x1D47: "b",    // ᵇ [MODIFIER LETTER SMALL B]
+	0x1D48: "d",    // ᵈ [MODIFIER LETTER SMALL D]
+	0x1D49: "e",    // ᵉ [MODIFIER LETTER SMALL E]
+	0x1D4D: "g",    // ᵍ [MODIFIER LETTER SMALL G]
+	0x1D4F: "k",    // ᵏ [MODIFIER LETTER SMALL K]
+	0x1D50: "m",    // ᵐ [MODIFIER LETTER SMALL M]
+	0x1D52: "o",    // ᵒ [MODIFIER LETTER SMALL O]
+	0x1D56: "p",    // ᵖ [MODIFIER LETTER SMALL P]
+	0x1D57: "t",    // ᵗ [MODIFIER LETTER SMALL T]
+	0x1D58: "u",    // ᵘ [MODIFIER LETTER SMALL U]
+	0x1D5B: "v",    // ᵛ [MODIFIER LETTER SMALL V]
+	0x1D62: "i",    // ᵢ [LATIN SUBSCRIPT SMALL LETTER I]
+	0x1D63: "r",    // ᵣ [LATIN SUBSCRIPT SMALL LETTER R]
+	0x1D64: "u",    // ᵤ [LATIN SUBSCRIPT SMALL LETTER U]
+	0x1D65: "v",    // ᵥ [LATIN SUBSCRIPT SMALL LETTER V]
+	0x1D6B: "ue",   // ᵫ [LATIN SMALL LETTER UE]
+	0x1D6C: "b",    // ᵬ [LATIN SMALL LETTER B WITH MIDDLE TILDE]
+	0x1D6D: "d",    // ᵭ [LATIN SMALL LETTER D WITH MIDDLE TILDE]
+	0x1D6E: "f",    // ᵮ [LATIN SMALL LETTER F WITH MIDDLE TILDE]
+	0x1D6F: "m",    // ᵯ [LATIN SMALL LETTER M WITH MIDDLE TILDE]
+	0x1D70: "n",    // ᵰ [LATIN SMALL LETTER N WITH MIDDLE TILDE]
+	0x1D71: "p",    // ᵱ [LATIN SMALL LETTER P WITH MIDDLE TILDE]
+	0x1D72: "r",    // ᵲ [LATIN SMALL LETTER R WITH MIDDLE TILDE]
+	0x1D73: "r",    // ᵳ [LATIN SMALL LETTER R WITH FISHHOOK AND MIDDLE TILDE]
+	0x1D74: "s",    // ᵴ [LATIN SMALL LETTER S WITH MIDDLE TILDE]
+	0x1D75: "t",    // ᵵ [LATIN SMALL LETTER T WITH MIDDLE TILDE]
+	0x1D76: "z",    // ᵶ [LATIN SMALL LETTER Z WITH MIDDLE TILDE]
+	0x1D77: "g",    // ᵷ [LATIN SMALL LETTER TURNED G]
+	0x1D7D: "p",    // ᵽ [LATIN SMALL LETTER P WITH STROKE]
+	0x1D80: "b",    // ᶀ [LATIN SMALL LETTER B WITH PALATAL HOOK]
+	0x1D81: "d",    // ᶁ [LATIN SMALL LETTER D WITH PALATAL HOOK]
+	0x1D82: "f",    // ᶂ [LATIN SMALL LETTER F WITH PALATAL HOOK]
+	0x1D83: "g",    // ᶃ [LATIN SMALL LETTER G WITH PALATAL HOOK]
+	0x1D84: "k",    // ᶄ [LATIN SMALL LETTER K WITH PALATAL HOOK]
+	0x1D85: "l",    // ᶅ [LATIN SMALL LETTER L WITH PALATAL HOOK]
+	0x1D86: "m",    // ᶆ [LATIN SMALL LETTER M WITH PALATAL HOOK]
+	0x1D87: "n",    // ᶇ [LATIN SMALL LETTER N WITH PALATAL HOOK]
+	0x1D88: "p",    // ᶈ [LATIN SMALL LETTER P WITH PALATAL HOOK]
+	0x1D89: "r",    // ᶉ [LATIN SMALL LETTER R WITH PALATAL HOOK]
+	0x1D8A: "s",    // ᶊ [LATIN SMALL LETTER S WITH PALATAL HOOK]
+	0x1D8C: "v",    // ᶌ [LATIN SMALL LETTER V WITH PALATAL HOOK]
+	0x1D8D: "x",    // ᶍ [LATIN SMALL LETTER X WITH PALATAL HOOK]
+	0x1D8E: "z",    // ᶎ [LATIN SMALL LETTER Z WITH PALATAL HOOK]
+	0x1D8F: "a",    // ᶏ [LATIN SMALL LETTER A WITH RETROFLEX HOOK]
+	0x1D91: "d",    // ᶑ [LATIN SMALL LETTER D WITH HOOK AND TAIL]
+	0x1D92: "e",    // ᶒ [LATIN SMALL LETTER E WITH RETROFLEX HOOK]
+	0x1D93: "e",    // ᶓ [LATIN SMALL LETTER OPEN E WITH RETROFLEX HOOK]
+	0x1D95: "e",    // ᶕ [LATIN SMALL LETTER SCHWA WITH RETROFLEX HOOK]
+	0x1D96: "i",    // ᶖ [LATIN SMALL LETTER I WITH RETROFLEX HOOK]
+	0x1D97: "o",    // ᶗ [LATIN SMALL LETTER OPEN O WITH RETROFLEX HOOK]
+	0x1D99: "u",    // ᶙ [LATIN SMALL LETTER U WITH RETROFLEX HOOK]
+	0x1D9C: "c",    // ᶜ [MODIFIER LETTER SMALL C]
+	0x1DA0: "f",    // ᶠ [MODIFIER LETTER SMALL F]
+	0x1DBB: "z",    // ᶻ [MODIFIER LETTER SMALL Z]
+	0x1E00: "A",    // Ḁ [LATIN CAPITAL LETTER A WITH RING BELOW]
+	0x1E01: "a",    // ḁ [LATIN SMALL LETTER A WITH RING BELOW]
+	0x1E02: "B",    // Ḃ [LATIN CAPITAL LETTER B WITH DOT ABOVE]
+	0x1E03: "b",    // ḃ [LATIN SMALL LETTER B WITH DOT ABOVE]
+	0x1E04: "B",    // Ḅ [LATIN CAPITAL LETTER B WITH DOT BELOW]
+	0x1E05: "b",    // ḅ [LATIN SMALL LETTER B WITH DOT BELOW]
+	0x1E06: "B",    // Ḇ [LATIN CAPITAL LETTER B WITH LINE BELOW]
+	0x1E07: "b",    // ḇ [LATIN SMALL LETTER B WITH LINE BELOW]
+	0x1E08: "C",    // Ḉ [LATIN CAPITAL LETTER C WITH CEDILLA AND ACUTE]
+	0x1E09: "c",    // ḉ [LATIN SMALL LETTER C WITH CEDILLA AND ACUTE]
+	0x1E0A: "D",    // Ḋ [LATIN CAPITAL LETTER D WITH DOT ABOVE]
+	0x1E0B: "d",    // ḋ [LATIN SMALL LETTER D WITH DOT ABOVE]
+	0x1E0C: "D",    // Ḍ [LATIN CAPITAL LETTER D WITH DOT BELOW]
+	0x1E0D: "d",    // ḍ [LATIN SMALL LETTER D WITH DOT BELOW]
+	0x1E0E: "D",    // Ḏ [LATIN CAPITAL LETTER D WITH LINE BELOW]
+	0x1E0F: "d",    // ḏ [LATIN SMALL LETTER D WITH LINE BELOW]
+	0x1E10: "D",    // Ḑ [LATIN CAPITAL LETTER D WITH CEDILLA]
+	0x1E11: "d",    // ḑ [LATIN SMALL LETTER D WITH CEDILLA]
+	0x1E12: "D",    // Ḓ [LATIN CAPITAL LETTER D WITH CIRCUMFLEX BELOW]
+	0x1E13: "d",    // ḓ [LATIN SMALL LETTER D WITH CIRCUMFLEX BELOW]
+	0x1E14: "E",    // Ḕ [LATIN CAPITAL LETTER E WITH MACRON AND GRAVE]
+	0x1E15: "e",    // ḕ [LATIN SMALL LETTER E WITH MACRON AND GRAVE]
+	0x1E16: "E",    // Ḗ [LATIN CAPITAL LETTER E WITH MACRON AND ACUTE]
+	0x1E17: "e",    // ḗ [LATIN SMALL LETTER E WITH MACRON AND ACUTE]
+	0x1E18: "E",    // Ḙ [LATIN CAPITAL LETTER E WITH CIRCUMFLEX BELOW]
+	0x1E19: "e",    // ḙ [LATIN SMALL LETTER E WITH CIRCUMFLEX BELOW]
+	0x1E1A: "E",    // Ḛ [LATIN CAPITAL LETTER E WITH TILDE BELOW]
+	0x1E1B: "e",    // ḛ [LATIN SMALL LETTER E WITH TILDE BELOW]
+	0x1E1C: "E",    // Ḝ [LATIN CAPITAL LETTER E WITH CEDILLA AND BREVE]
+	0x1E1D: "e",    // ḝ [LATIN SMALL LETTER E WITH CEDILLA AND BREVE]
+	0x1E1E: "F",    // Ḟ [LATIN CAPITAL LETTER F WITH DOT ABOVE]
+	0x1E1F: "f",    // ḟ [LATIN SMALL LETTER F WITH DOT ABOVE]
+	0x1E20: "G",    // Ḡ [LATIN CAPITAL LETTER G WITH MACRON]
+	0x1E21: "g",    // ḡ [LATIN SMALL LETTER G WITH MACRON]
+	0x1E22: "H",    // Ḣ [LATIN CAPITAL LETTER H WITH DOT ABOVE]
+	0x1E23: "h",    // ḣ [LATIN SMALL LETTER H WITH DOT ABOVE]
+	0x1E24: "H",    // Ḥ [LATIN CAPITAL LETTER H WITH DOT BELOW]
+	0x1E25: "h",    // ḥ [LATIN SMALL LETTER H WITH DOT BELOW]
+	0x1E26: "H",    // Ḧ [LATIN CAPITAL LETTER H WITH DIAERESIS]
+	0x1E27: "h",    // ḧ [LATIN SMALL LETTER H WITH DIAERESIS]
+	0x1E28: "H",    // Ḩ [LATIN CAPITAL LETTER H WITH CEDILLA]
+	0x1E29: "h",    // ḩ [LATIN SMALL LETTER H WITH CEDILLA]
+	0x1E2A: "H",    // Ḫ [LATIN CAPITAL LETTER H WITH BREVE BELOW]
+	0x1E2B: "h",    // ḫ [LATIN SMALL LETTER H WITH BREVE BELOW]
+	0x1E2C: "I",    // Ḭ [LATIN CAPITAL LETTER I WITH TILDE BELOW]
+	0x1E2D: "i",    // ḭ [LATIN SMALL LETTER I WITH TILDE BELOW]
+	0x1E2E: "I",    // Ḯ [LATIN CAPITAL LETTER I WITH DIAERESIS AND ACUTE]
+	0x1E2F: "i",    // ḯ [LATIN SMALL LETTER I WITH DIAERESIS AND ACUTE]
+	0x1E30: "K",    // Ḱ [LATIN CAPITAL LETTER K WITH ACUTE]
+	0x1E31: "k",    // ḱ [LATIN SMALL LETTER K WITH ACUTE]
+	0x1E32: "K",    // Ḳ [LATIN CAPITAL LETTER K WITH DOT BELOW]
+	0x1E33: "k",    // ḳ [LATIN SMALL LETTER K WITH DOT BELOW]
+	0x1E34: "K",    // Ḵ [LATIN CAPITAL LETTER K WITH LINE BELOW]
+	0x1E35: "k",    // ḵ [LATIN SMALL LETTER K WITH LINE BELOW]
+	0x1E36: "L",    // Ḷ [LATIN CAPITAL LETTER L WITH DOT BELOW]
+	0x1E37: "l",    // ḷ [LATIN SMALL LETTER L WITH DOT BELOW]
+	0x1E38: "L",    // Ḹ [LATIN CAPITAL LETTER L WITH DOT BELOW AND MACRON]
+	0x1E39: "l",    // ḹ [LATIN SMALL LETTER L WITH DOT BELOW AND MACRON]
+	0x1E3A: "L",    // Ḻ [LATIN CAPITAL LETTER L WITH LINE BELOW]
+	0x1E3B: "l",    // ḻ [LATIN SMALL LETTER L WITH LINE BELOW]
+	0x1E3C: "L",    // Ḽ [LATIN CAPITAL LETTER L WITH CIRCUMFLEX BELOW]
+	0x1E3D: "l",    // ḽ [LATIN SMALL LETTER L WITH CIRCUMFLEX BELOW]
+	0x1E3E: "M",    // Ḿ [LATIN CAPITAL LETTER M WITH ACUTE]
+	0x1E3F: "m",    // ḿ [LATIN SMALL LETTER M WITH ACUTE]
+	0x1E40: "M",    // Ṁ [LATIN CAPITAL LETTER M WITH DOT ABOVE]
+	0x1E41: "m",    // ṁ [LATIN SMALL LETTER M WITH DOT ABOVE]
+	0x1E42: "M",    // Ṃ [LATIN CAPITAL LETTER M WITH DOT BELOW]
+	0x1E43: "m",    // ṃ [LATIN SMALL LETTER M WITH DOT BELOW]
+	0x1E44: "N",    // Ṅ [LATIN CAPITAL LETTER N WITH DOT ABOVE]
+	0x1E45: "n",    // ṅ [LATIN SMALL LETTER N WITH DOT ABOVE]
+	0x1E46: "N",    // Ṇ [LATIN CAPITAL LETTER N WITH DOT BELOW]
+	0x1E47: "n",    // ṇ [LATIN SMALL LETTER N WITH DOT BELOW]
+	0x1E48: "N",    // Ṉ [LATIN CAPITAL LETTER N WITH LINE BELOW]
+	0x1E49: "n",    // ṉ [LATIN SMALL LETTER N WITH LINE BELOW]
+	0x1E4A: "N",    // Ṋ [LATIN CAPITAL LETTER N WITH CIRCUMFLEX BELOW]
+	0x1E4B: "n",    // ṋ [LATIN SMALL LETTER N WITH CIRCUMFLEX BELOW]
+	0x1E4C: "O",    // Ṍ [LATIN CAPITAL LETTER O WITH TILDE AND ACUTE]
+	0x1E4D: "o",    // ṍ [LATIN SMALL LETTER O WITH TILDE AND ACUTE]
+	0x1E4E: "O",    // Ṏ [LATIN CAPITAL LETTER O WITH TILDE AND DIAERESIS]
+	0x1E4F: "o",    // ṏ [LATIN SMALL LETTER O WITH TILDE AND DIAERESIS]
+	0x1E50: "O",    // Ṑ [LATIN CAPITAL LETTER O WITH MACRON AND GRAVE]
+	0x1E51: "o",    // ṑ [LATIN SMALL LETTER O WITH MACRON AND GRAVE]
+	0x1E52: "O",    // Ṓ [LATIN CAPITAL LETTER O WITH MACRON AND ACUTE]
+	0x1E53: "o",    // ṓ [LATIN SMALL LETTER O WITH MACRON AND ACUTE]
+	0x1E54: "P",    // Ṕ [LATIN CAPITAL LETTER P WITH ACUTE]
+	0x1E55: "p",    // ṕ [LATIN SMALL LETTER P WITH ACUTE]
+	0x1E56: "P",    // Ṗ [LATIN CAPITAL LETTER P WITH DOT ABOVE]
+	0x1E57: "p",    // ṗ [LATIN SMALL LETTER P WITH DOT ABOVE]
+	0x1E58: "R",    // Ṙ [LATIN CAPITAL LETTER R WITH DOT ABOVE]
+	0x1E59: "r",    // ṙ [LATIN SMALL LETTER R WITH DOT ABOVE]
+	0x1E5A: "R",    // Ṛ [LATIN CAPITAL LETTER R WITH DOT BELOW]
+	0x1E5B: "r",    // ṛ [LATIN SMALL LETTER R WITH DOT BELOW]
+	0x1E5C: "R",    // Ṝ [LATIN CAPITAL LETTER R WITH DOT BELOW AND MACRON]
+	0x1E5D: "r",    // ṝ [LATIN SMALL LETTER R WITH DOT BELOW AND MACRON]
+	0x1E5E: "R",    // Ṟ [LATIN CAPITAL LETTER R WITH LINE BELOW]
+	0x1E5F: "r",    // ṟ [LATIN SMALL LETTER R WITH LINE BELOW]
+	0x1E60: "S",    // Ṡ [LATIN CAPITAL LETTER S WITH DOT ABOVE]
+	0x1E61: "s",    // ṡ [LATIN SMALL LETTER S WITH DOT ABOVE]
+	0x1E62: "S",    // Ṣ [LATIN CAPITAL LETTER S WITH DOT BELOW]
+	0x1E63: "s",    // ṣ [LATIN SMALL LETTER S WITH DOT BELOW]
+	0x1E64: "S",    // Ṥ [LATIN CAPITAL LETTER S WITH ACUTE AND DOT ABOVE]
+	0x1E65: "s",    // ṥ [LATIN SMALL LETTER S WITH ACUTE AND DOT ABOVE]
+	0x1E66: "S",    // Ṧ [LATIN CAPITAL LETTER S WITH CARON AND DOT ABOVE]
+	0x1E67: "s",    // ṧ [LATIN SMALL LETTER S WITH CARON AND DOT ABOVE]
+	0x1E68: "S",    // Ṩ [LATIN CAPITAL LETTER S WITH DOT BELOW AND DOT ABOVE]
+	0x1E69: "s",    // ṩ [LATIN SMALL LETTER S WITH DOT BELOW AND DOT ABOVE]
+	0x1E6A: "T",    // Ṫ [LATIN CAPITAL LETTER T WITH DOT ABOVE]
+	0x1E6B: "t",    // ṫ [LATIN SMALL LETTER T WITH DOT ABOVE]
+	0x1E6C: "T",    // Ṭ [LATIN CAPITAL LETTER T WITH DOT BELOW]
+	0x1E6D: "t",    // ṭ [LATIN SMALL LETTER T WITH DOT BELOW]
+	0x1E6E: "T",    // Ṯ [LATIN CAPITAL LETTER T WITH LINE BELOW]
+	0x1E6F: "t",    // ṯ [LATIN SMALL LETTER T WITH LINE BELOW]
+	0x1E70: "T",    // Ṱ [LATIN CAPITAL LETTER T WITH CIRCUMFLEX BELOW]
+	0x1E71: "t",    // ṱ [LATIN SMALL LETTER T WITH CIRCUMFLEX BELOW]
+	0x1E72: "U",    // Ṳ [LATIN CAPITAL LETTER U WITH DIAERESIS BELOW]
+	0x1E73: "u",    // ṳ [LATIN SMALL LETTER U WITH DIAERESIS BELOW]
+	0x1E74: "U",    // Ṵ [LATIN CAPITAL LETTER U WITH TILDE BELOW]
+	0x1E75: "u",    // ṵ [LATIN SMALL LETTER U WITH TILDE BELOW]
+	0x1E76: "U",    // Ṷ [LATIN CAPITAL LETTER U WITH CIRCUMFLEX BELOW]
+	0x1E77: "u",    // ṷ [LATIN SMALL LETTER U WITH CIRCUMFLEX BELOW]
+	0x1E78: "U",    // Ṹ [LATIN CAPITAL LETTER U WITH TILDE AND ACUTE]
+	0x1E79: "u",    // ṹ [LATIN SMALL LETTER U WITH TILDE AND ACUTE]
+	0x1E7A: "U",    // Ṻ [LATIN CAPITAL LETTER U WITH MACRON AND DIAERESIS]
+	0x1E7B: "u",    // ṻ [LATIN SMALL LETTER U WITH MACRON AND DIAERESIS]
+	0x1E7C: "V",    // Ṽ [LATIN CAPITAL LETTER V WITH TILDE]
+	0x1E7D: "v",    // ṽ [LATIN SMALL LETTER V WITH TILDE]
+	0x1E7E: "V",    // Ṿ [LATIN CAPITAL LETTER V WITH DOT BELOW]
+	0x1E7F: "v",    // ṿ [LATIN SMALL LETTER V WITH DOT BELOW]
+	0x1E80: "W",    // Ẁ [LATIN CAPITAL LETTER W WITH GRAVE]
+	0x1E81: "w",    // ẁ [LATIN SMALL LETTER W WITH GRAVE]
+	0x1E82: "W",    // Ẃ [LATIN CAPITAL LETTER W WITH ACUTE]
+	0x1E83: "w",    // ẃ [LATIN SMALL LETTER W WITH ACUTE]
+	0x1E84: "W",    // Ẅ [LATIN CAPITAL LETTER W WITH DIAERESIS]
+	0x1E85: "w",    // ẅ [LATIN SMALL LETTER W WITH DIAERESIS]
+	0x1E86: "W",    // Ẇ [LATIN CAPITAL LETTER W WITH DOT ABOVE]
+	0x1E87: "w",    // ẇ [LATIN SMALL LETTER W WITH DOT ABOVE]
+	0x1E88: "W",    // Ẉ [LATIN CAPITAL LETTER W WITH DOT BELOW]
+	0x1E89: "w",    // ẉ [LATIN SMALL LETTER W WITH DOT BELOW]
+	0x1E8A: "X",    // Ẋ [LATIN CAPITAL LETTER X WITH DOT ABOVE]
+	0x1E8B: "x",    // ẋ [LATIN SMALL LETTER X WITH DOT ABOVE]
+	0x1E8C: "X",    // Ẍ [LATIN CAPITAL LETTER X WITH DIAERESIS]
+	0x1E8D: "x",    // ẍ [LATIN SMALL LETTER X WITH DIAERESIS]
+	0x1E8E: "Y",    // Ẏ [LATIN CAPITAL LETTER Y WITH DOT ABOVE]
+	0x1E8F: "y",    // ẏ [LATIN SMALL LETTER Y WITH DOT ABOVE]
+	0x1E90: "Z",    // Ẑ [LATIN CAPITAL LETTER Z WITH CIRCUMFLEX]
+	0x1E91: "z",    // ẑ [LATIN SMALL LETTER Z WITH CIRCUMFLEX]
+	0x1E92: "Z",    // Ẓ [LATIN CAPITAL LETTER Z WITH DOT BELOW]
+	0x1E93: "z",    // ẓ [LATIN SMALL LETTER Z WITH DOT BELOW]
+	0x1E94: "Z",    // Ẕ [LATIN CAPITAL LETTER Z WITH LINE BELOW]
+	0x1E95: "z",    // ẕ [LATIN SMALL LETTER Z WITH LINE BELOW]
+	0x1E96: "h",    // ẖ [LATIN SMALL LETTER H WITH LINE BELOW]
+	0x1E97: "t",    // ẗ [LATIN SMALL LETTER T WITH DIAERESIS]
+	0x1E98: "w",    // ẘ [LATIN SMALL LETTER W WITH RING ABOVE]
+	0x1E99: "y",    // ẙ [LATIN SMALL LETTER Y WITH RING ABOVE]
+	0x1E9A: "a",    // ẚ [LATIN SMALL LETTER A WITH RIGHT HALF RING]
+	0x1E9B: "s",    // ẛ [LATIN SMALL LETTER LONG S WITH DOT ABOVE]
+	0x1E9C: "s",    // ẜ [LATIN SMALL LETTER LONG S WITH DIAGONAL STROKE]
+	0x1E9D: "s",    // ẝ [LATIN SMALL LETTER LONG S WITH HIGH STROKE]
+	0x1E9E: "SS",   // ẞ [LATIN CAPITAL LETTER SHARP S]
+	0x1EA0: "A",    // Ạ [LATIN CAPITAL LETTER A WITH DOT BELOW]
+	0x1EA1: "a",    // ạ [LATIN SMALL LETTER A WITH DOT BELOW]
+	0x1EA2: "A",    // Ả [LATIN CAPITAL LETTER A WITH HOOK ABOVE]
+	0x1EA3: "a",    // ả [LATIN SMALL LETTER A WITH HOOK ABOVE]
+	0x1EA4: "A",    // Ấ [LATIN CAPITAL LETTER A WITH CIRCUMFLEX AND ACUTE]
+	0x1EA5: "a",    // ấ [LATIN SMALL LETTER A WITH CIRCUMFLEX AND ACUTE]
+	0x1EA6: "A",    // Ầ [LATIN CAPITAL LETTER A WITH CIRCUMFLEX AND GRAVE]
+	0x1EA7: "a",    // ầ [LATIN SMALL LETTER A WITH CIRCUMFLEX AND GRAVE]
+	0x1EA8: "A",    // Ẩ [LATIN CAPITAL LETTER A WITH CIRCUMFLEX AND HOOK ABOVE]
+	0x1EA9: "a",    // ẩ [LATIN SMALL LETTER A WITH CIRCUMFLEX AND HOOK ABOVE]
+	0x1EAA: "A",    // Ẫ [LATIN CAPITAL LETTER A WITH CIRCUMFLEX AND TILDE]
+	0x1EAB: "a",    // ẫ [LATIN SMALL LETTER A WITH CIRCUMFLEX AND TILDE]
+	0x1EAC: "A",    // Ậ [LATIN CAPITAL LETTER A WITH CIRCUMFLEX AND DOT BELOW]
+	0x1EAD: "a",    // ậ [LATIN SMALL LETTER A WITH CIRCUMFLEX AND DOT BELOW]
+	0x1EAE: "A",    // Ắ [LATIN CAPITAL LETTER A WITH BREVE AND ACUTE]
+	0x1EAF: "a",    // ắ [LATIN SMALL LETTER A WITH BREVE AND ACUTE]
+	0x1EB0: "A",    // Ằ [LATIN CAPITAL LETTER A WITH BREVE AND GRAVE]
+	0x1EB1: "a",    // ằ [LATIN SMALL LETTER A WITH BREVE AND GRAVE]
+	0x1EB2: "A",    // Ẳ [LATIN CAPITAL LETTER A WITH BREVE AND HOOK ABOVE]
+	0x1EB3: "a",    // ẳ [LATIN SMALL LETTER A WITH BREVE AND HOOK ABOVE]
+	0x1EB4: "A",    // Ẵ [LATIN CAPITAL LETTER A WITH BREVE AND TILDE]
+	0x1EB5: "a",    // ẵ [LATIN SMALL LETTER A WITH BREVE AND TILDE]
+	0x1EB6: "A",    // Ặ [LATIN CAPITAL LETTER A WITH BREVE AND DOT BELOW]
+	0x1EB7: "a",    // ặ [LATIN SMALL LETTER A WITH BREVE AND DOT BELOW]
+	0x1EB8: "E",    // Ẹ [LATIN CAPITAL LETTER E WITH DOT BELOW]
+	0x1EB9: "e",    // ẹ [LATIN SMALL LETTER E WITH DOT BELOW]
+	0x1EBA: "E",    // Ẻ [LATIN CAPITAL LETTER E WITH HOOK ABOVE]
+	0x1EBB: "e",    // ẻ [LATIN SMALL LETTER E WITH HOOK ABOVE]
+	0x1EBC: "E",    // Ẽ [LATIN CAPITAL LETTER E WITH TILDE]
+	0x1EBD: "e",    // ẽ [LATIN SMALL LETTER E WITH TILDE]
+	0x1EBE: "E",    // Ế [LATIN CAPITAL LETTER E WITH CIRCUMFLEX AND ACUTE]
+	0x1EBF: "e",    // ế [LATIN SMALL LETTER E WITH CIRCUMFLEX AND ACUTE]
+	0x1EC0: "E",    // Ề [LATIN CAPITAL LETTER E WITH CIRCUMFLEX AND GRAVE]
+	0x1EC1: "e",    // ề [LATIN SMALL LETTER E WITH CIRCUMFLEX AND GRAVE]
+	0x1EC2: "E",    // Ể [LATIN CAPITAL LETTER E WITH CIRCUMFLEX AND HOOK ABOVE]
+	0x1EC3: "e",    // ể [LATIN SMALL LETTER E WITH CIRCUMFLEX AND HOOK ABOVE]
+	0x1EC4: "E",    // Ễ [LATIN CAPITAL LETTER E WITH CIRCUMFLEX AND TILDE]
+	0x1EC5: "e",    // ễ [LATIN SMALL LETTER E WITH CIRCUMFLEX AND TILDE]
+	0x1EC6: "E",    // Ệ [LATIN CAPITAL LETTER E WITH CIRCUMFLEX AND DOT BELOW]
+	0x1EC7: "e",    // ệ [LATIN SMALL LETTER E WITH CIRCUMFLEX AND DOT BELOW]
+	0x1EC8: "I",    // Ỉ [LATIN CAPITAL LETTER I WITH HOOK ABOVE]
+	0x1EC9: "i",    // ỉ [LATIN SMALL LETTER I WITH HOOK ABOVE]
+	0x1ECA: "I",    // Ị [LATIN CAPITAL LETTER I WITH DOT BELOW]
+	0x1ECB: "i",    // ị [LATIN SMALL LETTER I WITH DOT BELOW]
+	0x1ECC: "O",    // Ọ [LATIN CAPITAL LETTER O WITH DOT BELOW]
+	0x1ECD: "o",    // ọ [LATIN SMALL LETTER O WITH DOT BELOW]
+	0x1ECE: "O",    // Ỏ [LATIN CAPITAL LETTER O WITH HOOK ABOVE]
+	0x1ECF: "o",    // ỏ [LATIN SMALL LETTER O WITH HOOK ABOVE]
+	0x1ED0: "O",    // Ố [LATIN CAPITAL LETTER O WITH CIRCUMFLEX AND ACUTE]
+	0x1ED1: "o",    // ố [LATIN SMALL LETTER O WITH CIRCUMFLEX AND ACUTE]
+	0x1ED2: "O",    // Ồ [LATIN CAPITAL LETTER O WITH CIRCUMFLEX AND GRAVE]
+	0x1ED3: "o",    // ồ [LATIN SMALL LETTER O WITH CIRCUMFLEX AND GRAVE]
+	0x1ED4: "O",    // Ổ [LATIN CAPITAL LETTER O WITH CIRCUMFLEX AND HOOK ABOVE]
+	0x1ED5: "o",    // ổ [LATIN SMALL LETTER O WITH CIRCUMFLEX AND HOOK ABOVE]
+	0x1ED6: "O",    // Ỗ [LATIN CAPITAL LETTER O WITH CIRCUMFLEX AND TILDE]
+	0x1ED7: "o",    // ỗ [LATIN SMALL LETTER O WITH CIRCUMFLEX AND TILDE]
+	0x1ED8: "O",    // Ộ [LATIN CAPITAL LETTER O WITH CIRCUMFLEX AND DOT BELOW]
+	0x1ED9: "o",    // ộ [LATIN SMALL LETTER O WITH CIRCUMFLEX AND DOT BELOW]
+	0x1EDA: "O",    // Ớ [LATIN CAPITAL LETTER O WITH HORN AND ACUTE]
+	0x1EDB: "o",    // ớ [LATIN SMALL LETTER O WITH HORN AND ACUTE]
+	0x1EDC: "O",    // Ờ [LATIN CAPITAL LETTER O WITH HORN AND GRAVE]
+	0x1EDD: "o",    // ờ [LATIN SMALL LETTER O WITH HORN AND GRAVE]
+	0x1EDE: "O",    // Ở [LATIN CAPITAL LETTER O WITH HORN AND HOOK ABOVE]
+	0x1EDF: "o",    // ở [LATIN SMALL LETTER O WITH HORN AND HOOK ABOVE]
+	0x1EE0: "O",    // Ỡ [LATIN CAPITAL LETTER O WITH HORN AND TILDE]
+	0x1EE1: "o",    // ỡ [LATIN SMALL LETTER O WITH HORN AND TILDE]
+	0x1EE2: "O",    // Ợ [LATIN CAPITAL LETTER O WITH HORN AND DOT BELOW]
+	0x1EE3: "o",    // ợ [LATIN SMALL LETTER O WITH HORN AND DOT BELOW]
+	0x1EE4: "U",    // Ụ [LATIN CAPITAL LETTER U WITH DOT BELOW]
+	0x1EE5: "u",    // ụ [LATIN SMALL LETTER U WITH DOT BELOW]
+	0x1EE6: "U",    // Ủ [LATIN CAPITAL LETTER U WITH HOOK ABOVE]
+	0x1EE7: "u",    // ủ [LATIN SMALL LETTER U WITH HOOK ABOVE]
+	0x1EE8: "U",    // Ứ [LATIN CAPITAL LETTER U WITH HORN AND ACUTE]
+	0x1EE9: "u",    // ứ [LATIN SMALL LETTER U WITH HORN AND ACUTE]
+	0x1EEA: "U",    // Ừ [LATIN CAPITAL LETTER U WITH HORN AND GRAVE]
+	0x1EEB: "u",    // ừ [LATIN SMALL LETTER U WITH HORN AND GRAVE]
+	0x1EEC: "U",    // Ử [LATIN CAPITAL LETTER U WITH HORN AND HOOK ABOVE]
+	0x1EED: "u",    // ử [LATIN SMALL LETTER U WITH HORN AND HOOK ABOVE]
+	0x1EEE: "U",    // Ữ [LATIN CAPITAL LETTER U WITH HORN AND TILDE]
+	0x1EEF: "u",    // ữ [LATIN SMALL LETTER U WITH HORN AND TILDE]
+	0x1EF0: "U",    // Ự [LATIN CAPITAL LETTER U WITH HORN AND DOT BELOW]
+	0x1EF1: "u",    // ự [LATIN SMALL LETTER U WITH HORN AND DOT BELOW]
+	0x1EF2: "Y",    // Ỳ [LATIN CAPITAL LETTER Y WITH GRAVE]
+	0x1EF3: "y",    // ỳ [LATIN SMALL LETTER Y WITH GRAVE]
+	0x1EF4: "Y",    // Ỵ [LATIN CAPITAL LETTER Y WITH DOT BELOW]
+	0x1EF5: "y",    // ỵ [LATIN SMALL LETTER Y WITH DOT BELOW]
+	0x1EF6: "Y",    // Ỷ [LATIN CAPITAL LETTER Y WITH HOOK ABOVE]
+	0x1EF7: "y",    // ỷ [LATIN SMALL LETTER Y WITH HOOK ABOVE]
+	0x1EF8: "Y",    // Ỹ [LATIN CAPITAL LETTER Y WITH TILDE]
+	0x1EF9: "y",    // ỹ [LATIN SMALL LETTER Y WITH TILDE]
+	0x1EFE: "Y",    // Ỿ [LATIN CAPITAL LETTER Y WITH LOOP]
+	0x1EFF: "y",    // ỿ [LATIN SMALL LETTER Y WITH LOOP]
+	0x2010: "-",    // ‐ [HYPHEN]
+	0x2011: "-",    // ‑ [NON-BREAKING HYPHEN]
+	0x2012: "-",    // ‒ [FIGURE DASH]
+	0x2013: "-",    // – [EN DASH]
+	0x2014: "-",    // — [EM DASH]
+	0x2018: "'",    // ‘ [LEFT SINGLE QUOTATION MARK]
+	0x2019: "'",    // ’ [RIGHT SINGLE QUOTATION MARK]
+	0x201A: "'",    // ‚ [SINGLE LOW-9 QUOTATION MARK]
+	0x201B: "'",    // ‛ [SINGLE HIGH-REVERSED-9 QUOTATION MARK]
+	0x201C: "\"",   // “ [LEFT DOUBLE QUOTATION MARK]
+	0x201D: "\"",   // ” [RIGHT DOUBLE QUOTATION MARK]
+	0x201E: "\"",   // „ [DOUBLE LOW-9 QUOTATION MARK]
+	0x2024: ".",    // ․ [ONE DOT LEADER]
+	0x2025: "..",   // ‥ [TWO DOT LEADER]
+	0x2026: "...",  // … [HORIZONTAL ELLIPSIS]
+	0x2032: "'",    // ′ [PRIME]
+	0x2033: "\"",   // ″ [DOUBLE PRIME]
+	0x2035: "'",    // ‵ [REVERSED PRIME]
+	0x2036: "\"",   // ‶ [REVERSED DOUBLE PRIME]
+	0x2038: "^",    // ‸ [CARET]
+	0x2039: "'",    // ‹ [SINGLE LEFT-POINTING ANGLE QUOTATION MARK]
+	0x203A: "'",    // › [SINGLE RIGHT-POINTING ANGLE QUOTATION MARK]
+	0x203C: "!!",   // ‼ [DOUBLE EXCLAMATION MARK]
+	0x2044: "/",    // ⁄ [FRACTION SLASH]
+	0x2045: "[",    // ⁅ [LEFT SQUARE BRACKET WITH QUILL]
+	0x2046: "]",    // ⁆ [RIGHT SQUARE BRACKET WITH QUILL]
+	0x2047: "??",   // ⁇ [DOUBLE QUESTION MARK]
+	0x2048: "?!",   // ⁈ [QUESTION EXCLAMATION MARK]
+	0x2049: "!?",   // ⁉ [EXCLAMATION QUESTION MARK]
+	0x204E: "*",    // ⁎ [LOW ASTERISK]
+	0x204F: ";",    // ⁏ [REVERSED SEMICOLON]
+	0x2052: "%",    // ⁒ [COMMERCIAL MINUS SIGN]
+	0x2053: "~",    // ⁓ [SWUNG DASH]
+	0x2070: "0",    // ⁰ [SUPERSCRIPT ZERO]
+	0x2071: "i",    // ⁱ [SUPERSCRIPT LATIN SMALL LETTER I]
+	0x2074: "4",    // ⁴ [SUPERSCRIPT FOUR]
+	0x2075: "5",    // ⁵ [SUPERSCRIPT FIVE]
+	0x2076: "6",    // ⁶ [SUPERSCRIPT SIX]
+	0x2077: "7",    // ⁷ [SUPERSCRIPT SEVEN]
+	0x2078: "8",    // ⁸ [SUPERSCRIPT EIGHT]
+	0x2079: "9",    // ⁹ [SUPERSCRIPT NINE]
+	0x207A: "+",    // ⁺ [SUPERSCRIPT PLUS SIGN]
+	0x207B: "-",    // ⁻ [SUPERSCRIPT MINUS]
+	0x207C: "=",    // ⁼ [SUPERSCRIPT EQUALS SIGN]
+	0x207D: "(",    // ⁽ [SUPERSCRIPT LEFT PARENTHESIS]
+	0x207E: ")",    // ⁾ [SUPERSCRIPT RIGHT PARENTHESIS]
+	0x207F: "n",    // ⁿ [SUPERSCRIPT LATIN SMALL LETTER N]
+	0x2080: "0",    // ₀ [SUBSCRIPT ZERO]
+	0x2081: "1",    // ₁ [SUBSCRIPT ONE]
+	0x2082: "2",    // ₂ [SUBSCRIPT TWO]
+	0x2083: "3",    // ₃ [SUBSCRIPT THREE]
+	0x2084: "4",    // ₄ [SUBSCRIPT FOUR]
+	0x2085: "5",    // ₅ [SUBSCRIPT FIVE]
+	0x2086: "6",    // ₆ [SUBSCRIPT SIX]
+	0x2087: "7",    // ₇ [SUBSCRIPT SEVEN]
+	0x2088: "8",    // ₈ [SUBSCRIPT EIGHT]
+	0x2089: "9",    // ₉ [SUBSCRIPT NINE]
+	0x208A: "+",    // ₊ [SUBSCRIPT PLUS SIGN]
+	0x208B: "-",    // ₋ [SUBSCRIPT MINUS]
+	0x208C: "=",    // ₌ [SUBSCRIPT EQUALS SIGN]
+	0x208D: "(",    // ₍ [SUBSCRIPT LEFT PARENTHESIS]
+	0x208E: ")",    // ₎ [SUBSCRIPT RIGHT PARENTHESIS]
+	0x2090: "a",    // ₐ [LATIN SUBSCRIPT SMALL LETTER A]
+	0x2091: "e",    // ₑ [LATIN SUBSCRIPT SMALL LETTER E]
+	0x2092: "o",    // ₒ [LATIN SUBSCRIPT SMALL LETTER O]
+	0x2093: "x",    // ₓ [LATIN SUBSCRIPT SMALL LETTER X]
+	0x2095: "h",    // ₕ [LATIN SUBSCRIPT SMALL LETTER H]
+	0x2096: "k",    // ₖ [LATIN SUBSCRIPT SMALL LETTER K]
+	0x2097: "l",    // ₗ [LATIN SUBSCRIPT SMALL LETTER L]
+	0x2098: "m",    // ₘ [LATIN SUBSCRIPT SMALL LETTER M]
+	0x2099: "n",    // ₙ [LATIN SUBSCRIPT SMALL LETTER N]
+	0x209A: "p",    // ₚ [LATIN SUBSCRIPT SMALL LETTER P]
+	0x209B: "s",    // ₛ [LATIN SUBSCRIPT SMALL LETTER S]
+	0x209C: "t",    // ₜ [LATIN SUBSCRIPT SMALL LETTER T]
+	0x2460: "1",    // ① [CIRCLED DIGIT ONE]
+	0x2461: "2",    // ② [CIRCLED DIGIT TWO]
+	0x2462: "3",    // ③ [CIRCLED DIGIT THREE]
+	0x2463: "4",    // ④ [CIRCLED DIGIT FOUR]
+	0x2464: "5",    // ⑤ [CIRCLED DIGIT FIVE]
+	0x2465: "6",    // ⑥ [CIRCLED DIGIT SIX]
+	0x2466: "7",    // ⑦ [CIRCLED DIGIT SEVEN]
+	0x2467: "8",    // ⑧ [CIRCLED DIGIT EIGHT]
+	0x2468: "9",    // ⑨ [CIRCLED DIGIT NINE]
+	0x2469: "10",   // ⑩ [CIRCLED NUMBER TEN]
+	0x246A: "11",   // ⑪ [CIRCLED NUMBER ELEVEN]
+	0x246B: "12",   // ⑫ [CIRCLED NUMBER TWELVE]
+	0x246C: "13",   // ⑬ [CIRCLED NUMBER THIRTEEN]
+	0x246D: "14",   // ⑭ [CIRCLED NUMBER FOURTEEN]
+	0x246E: "15",   // ⑮ [CIRCLED NUMBER FIFTEEN]
+	0x246F: "16",   // ⑯ [CIRCLED NUMBER SIXTEEN]
+	0x2470: "17",   // ⑰ [CIRCLED NUMBER SEVENTEEN]
+	0x2471: "18",   // ⑱ [CIRCLED NUMBER EIGHTEEN]
+	0x2472: "19",   // ⑲ [CIRCLED NUMBER NINETEEN]
+	0x2473: "20",   // ⑳ [CIRCLED NUMBER TWENTY]
+	0x2474: "(1)",  // ⑴ [PARENTHESIZED DIGIT ONE]
+	0x2475: "(2)",  // ⑵ [PARENTHESIZED DIGIT TWO]
+	0x2476: "(3)",  // ⑶ [PARENTHESIZED DIGIT THREE]
+	0x2477: "(4)",  // ⑷ [PARENTHESIZED DIGIT FOUR]
+	0x2478: "(5)",  // ⑸ [PARENTHESIZED DIGIT FIVE]
+	0x2479: "(6)",  // ⑹ [PARENTHESIZED DIGIT SIX]
+	0x247A: "(7)",  // ⑺ [PARENTHESIZED DIGIT SEVEN]
+	0x247B: "(8)",  // ⑻ [PARENTHESIZED DIGIT EIGHT]
+	0x247C: "(9)",  // ⑼ [PARENTHESIZED DIGIT NINE]
+	0x247D: "(10)", // ⑽ [PARENTHESIZED NUMBER TEN]
+	0x247E: "(11)", // ⑾ [PARENTHESIZED NUMBER ELEVEN]
+	0x247F: "(12)", // ⑿ [PARENTHESIZED NUMBER TWELVE]
+	0x2480: "(13)", // ⒀ [PARENTHESIZED NUMBER THIRTEEN]
+	0x2481: "(14)", // ⒁ [PARENTHESIZED NUMBER FOURTEEN]
+	0x2482: "(15)", // ⒂ [PARENTHESIZED NUMBER FIFTEEN]
+	0x2483: "(16)", // ⒃ [PARENTHESIZED NUMBER SIXTEEN]
+	0x2484: "(17)", // ⒄ [PARENTHESIZED NUMBER SEVENTEEN]
+	0x2485: "(18)", // ⒅ [PARENTHESIZED NUMBER EIGHTEEN]
+	0x2486: "(19)", // ⒆ [PARENTHESIZED NUMBER NINETEEN]
+	0x2487: "(20)", // ⒇ [PARENTHESIZED NUMBER TWENTY]
+	0x2488: "1.",   // ⒈ [DIGIT ONE FULL STOP]
+	0x2489: "2.",   // ⒉ [DIGIT TWO FULL STOP]
+	0x248A: "3.",   // ⒊ [DIGIT THREE FULL STOP]
+	0x248B: "4.",   // ⒋ [DIGIT FOUR FULL STOP]
+	0x248C: "5.",   // ⒌ [DIGIT FIVE FULL STOP]
+	0x248D: "6.",   // ⒍ [DIGIT SIX FULL STOP]
+	0x248E: "7.",   // ⒎ [DIGIT SEVEN FULL STOP]
+	0x248F: "8.",   // ⒏ [DIGIT EIGHT FULL STOP]
+	0x2490: "9.",   // ⒐ [DIGIT NINE FULL STOP]
+	0x2491: "10.",  // ⒑ [NUMBER TEN FULL STOP]
+	0x2492: "11.",  // ⒒ [NUMBER ELEVEN FULL STOP]
+	0x2493: "12.",  // ⒓ [NUMBER TWELVE FULL STOP]
+	0x2494: "13.",  // ⒔ [NUMBER THIRTEEN FULL STOP]
+	0x2495: "14.",  // ⒕ [NUMBER FOURTEEN FULL STOP]
+	0x2496: "15.",  // ⒖ [NUMBER FIFTEEN FULL STOP]
+	0x2497: "16.",  // ⒗ [NUMBER SIXTEEN FULL STOP]
+	0x2498: "17.",  // ⒘ [NUMBER SEVENTEEN FULL STOP]
+	0x2499: "18.",  // ⒙ [NUMBER EIGHTEEN FULL STOP]
+	0x249A: "19.",  // ⒚ [NUMBER NINETEEN FULL STOP]
+	0x249B: "20.",  // ⒛ [NUMBER TWENTY FULL STOP]
+	0x249C: "(a)",  // ⒜ [PARENTHESIZED LATIN SMALL LETTER A]
+	0x249D: "(b)",  // ⒝ [PARENTHESIZED LATIN SMALL LETTER B]
+	0x249E: "(c)",  // ⒞ [PARENTHESIZED LATIN SMALL LETTER C]
+	0x249F: "(d)",  // ⒟ [PARENTHESIZED LATIN SMALL LETTER D]
+	0x24A0: "(e)",  // ⒠ [PARENTHESIZED LATIN SMALL LETTER E]
+	0x24A1: "(f)",  // ⒡ [PARENTHESIZED LATIN SMALL LETTER F]
+	0x24A2: "(g)",  // ⒢ [PARENTHESIZED LATIN SMALL LETTER G]
+	0x24A3: "(h)",  // ⒣ [PARENTHESIZED LATIN SMALL LETTER H]
+	0x24A4: "(i)",  // ⒤ [PARENTHESIZED LATIN SMALL LETTER I]
+	0x24A5: "(j)",  // ⒥ [PARENTHESIZED LATIN SMALL LETTER J]
+	0x24A6: "(k)",  // ⒦ [PARENTHESIZED LATIN SMALL LETTER K]
+	0x24A7: "(l)",  // ⒧ [PARENTHESIZED LATIN SMALL LETTER L]
+	0x24A8: "(m)",  // ⒨ [PARENTHESIZED LATIN SMALL LETTER M]
+	0x24A9: "(n)",  // ⒩ [PARENTHESIZED LATIN SMALL LETTER N]
+	0x24AA: "(o)",  // ⒪ [PARENTHESIZED LATIN SMALL LETTER O]
+	0x24AB: "(p)",  // ⒫ [PARENTHESIZED LATIN SMALL LETTER P]
+	0x24AC: "(q)",  // ⒬ [PARENTHESIZED LATIN SMALL LETTER Q]
+	0x24AD: "(r)",  // ⒭ [PARENTHESIZED LATIN SMALL LETTER R]
+	0x24AE: "(s)",  // ⒮ [PARENTHESIZED LATIN SMALL LETTER S]
+	0x24AF: "(t)",  // ⒯ [PARENTHESIZED LATIN SMALL LETTER T]
+	0x24B0: "(u)",  // ⒰ [PARENTHESIZED LATIN SMALL LETTER U]
+	0x24B1: "(v)",  // ⒱ [PARENTHESIZED LATIN SMALL LETTER V]
+	0x24B2: "(w)",  // ⒲ [PARENTHESIZED LATIN SMALL LETTER W]
+	0x24B3: "(x)",  // ⒳ [PARENTHESIZED LATIN SMALL LETTER X]
+	0x24B4: "(y)",  // ⒴ [PARENTHESIZED LATIN SMALL LETTER Y]
+	0x24B5: "(z)",  // ⒵ [PARENTHESIZED LATIN SMALL LETTER Z]
+	0x24B6: "A",    // Ⓐ [CIRCLED LATIN CAPITAL LETTER A]
+	0x24B7: "B",    // Ⓑ [CIRCLED LATIN CAPITAL LETTER B]
+	0x24B8: "C",    // Ⓒ [CIRCLED LATIN CAPITAL LETTER C]
+	0x24B9: "D",    // Ⓓ [CIRCLED LATIN CAPITAL LETTER D]
+	0x24BA: "E",    // Ⓔ [CIRCLED LATIN CAPITAL LETTER E]
+	0x24BB: "F",    // Ⓕ [CIRCLED LATIN CAPITAL LETTER F]
+	0x24BC: "G",    // Ⓖ [CIRCLED LATIN CAPITAL LETTER G]
+	0x24BD: "H",    // Ⓗ [CIRCLED LATIN CAPITAL LETTER H]
+	0x24BE: "I",    // Ⓘ [CIRCLED LATIN CAPITAL LETTER I]
+	0x24BF: "J",    // Ⓙ [CIRCLED LATIN CAPITAL LETTER J]
+	0x24C0: "K",    // Ⓚ [CIRCLED LATIN CAPITAL LETTER K]
+	0x24C1: "L",    // Ⓛ [CIRCLED LATIN CAPITAL LETTER L]
+	0x24C2: "M",    // Ⓜ [CIRCLED LATIN CAPITAL LETTER M]
+	0x24C3: "N",    // Ⓝ [CIRCLED LATIN CAPITAL LETTER N]
+	0x24C4: "O",    // Ⓞ [CIRCLED LATIN CAPITAL LETTER O]
+	0x24C5: "P",    // Ⓟ [CIRCLED LATIN CAPITAL LETTER P]
+	0x24C6: "Q",    // Ⓠ [CIRCLED LATIN CAPITAL LETTER Q]
+	0x24C7: "R",    // Ⓡ [CIRCLED LATIN CAPITAL LETTER R]
+	0x24C8: "S",    // Ⓢ [CIRCLED LATIN CAPITAL LETTER S]
+	0x24C9: "T",    // Ⓣ [CIRCLED LATIN CAPITAL LETTER T]
+	0x24CA: "U",    // Ⓤ [CIRCLED LATIN CAPITAL LETTER U]
+	0x24CB: "V",    // Ⓥ [CIRCLED LATIN CAPITAL LETTER V]
+	0x24CC: "W",    // Ⓦ [CIRCLED LATIN CAPITAL LETTER W]
+	0x24CD: "X",    // Ⓧ [CIRCLED LATIN CAPITAL LETTER X]
+	0x24CE: "Y",    // Ⓨ [CIRCLED LATIN CAPITAL LETTER Y]
+	0x24CF: "Z",    // Ⓩ [CIRCLED LATIN CAPITAL LETTER Z]
+	0x24D0: "a",    // ⓐ [CIRCLED LATIN SMALL LETTER A]
+	0x24D1: "b",    // ⓑ [CIRCLED LATIN SMALL LETTER B]
+	0x24D2: "c",    // ⓒ [CIRCLED LATIN SMALL LETTER C]
+	0x24D3: "d",    // ⓓ [CIRCLED LATIN SMALL LETTER D]
+	0x24D4: "e",    // ⓔ [CIRCLED LATIN SMALL LETTER E]
+	0x24D5: "f",    // ⓕ [CIRCLED LATIN SMALL LETTER F]
+	0x24D6: "g",    // ⓖ [CIRCLED LATIN SMALL LETTER G]
+	0x24D7: "h",    // ⓗ [CIRCLED LATIN SMALL LETTER H]
+	0x24D8: "i",    // ⓘ [CIRCLED LATIN SMALL LETTER I]
+	0x24D9: "j",    // ⓙ [CIRCLED LATIN SMALL LETTER J]
+	0x24DA: "k",    // ⓚ [CIRCLED LATIN SMALL LETTER K]
+	0x24DB: "l",    // ⓛ [CIRCLED LATIN SMALL LETTER L]
+	0x24DC: "m",    // ⓜ [CIRCLED LATIN SMALL LETTER M]
+	0x24DD: "n",    // ⓝ [CIRCLED LATIN SMALL LETTER N]
+	0x24DE: "o",    // ⓞ [CIRCLED LATIN SMALL LETTER O]
+	0x24DF: "p",    // ⓟ [CIRCLED LATIN SMALL LETTER P]
+	0x24E0: "q",    // ⓠ [CIRCLED LATIN SMALL LETTER Q]
+	0x24E1: "r",    // ⓡ [CIRCLED LATIN SMALL LETTER R]
+	0x24E2: "s",    // ⓢ [CIRCLED LATIN SMALL LETTER S]
+	0x24E3: "t",    // ⓣ [CIRCLED LATIN SMALL LETTER T]
+	0x24E4: "u",    // ⓤ [CIRCLED LATIN SMALL LETTER U]
+	0x24E5: "v",    // ⓥ [CIRCLED LATIN SMALL LETTER V]
+	0x24E6: "w",    // ⓦ [CIRCLED LATIN SMALL LETTER W]
+	0x24E7: "x",    // ⓧ [CIRCLED LATIN SMALL LETTER X]
+	0x24E8: "y",    // ⓨ [CIRCLED LATIN SMALL LETTER Y]
+	0x24E9: "z",    // ⓩ [CIRCLED LATIN SMALL LETTER Z]
+	0x24EA: "0",    // ⓪ [CIRCLED DIGIT ZERO]
+	0x2C60: "L",    // Ⱡ [LATIN CAPITAL LETTER L WITH DOUBLE BAR]
+	0x2C61: "l",    // ⱡ [LATIN SMALL LETTER L WITH DOUBLE BAR]
+	0x2C62: "L",    // Ɫ [LATIN CAPITAL LETTER L WITH MIDDLE TILDE]
+	0x2C63: "P",    // Ᵽ [LATIN CAPITAL LETTER P WITH STROKE]
+	0x2C64: "R",    // Ɽ [LATIN CAPITAL LETTER R WITH TAIL]
+	0x2C65: "a",    // ⱥ [LATIN SMALL LETTER A WITH STROKE]
+	0x2C66: "t",    // ⱦ [LATIN SMALL LETTER T WITH DIAGONAL STROKE]
+	0x2C67: "H",    // Ⱨ [LATIN CAPITAL LETTER H WITH DESCENDER]
+	0x2C68: "h",    // ⱨ [LATIN SMALL LETTER H WITH DESCENDER]
+	0x2C69: "K",    // Ⱪ [LATIN CAPITAL LETTER K WITH DESCENDER]
+	0x2C6A: "k",    // ⱪ [LATIN SMALL LETTER K WITH DESCENDER]
+	0x2C6B: "Z",    // Ⱬ [LATIN CAPITAL LETTER Z WITH DESCENDER]
+	0x2C6C: "z",    // ⱬ [LATIN SMALL LETTER Z WITH DESCENDER]
+	0x2C6E: "M",    // Ɱ [LATIN CAPITAL LETTER M WITH HOOK]
+	0x2C6F: "A",    // Ɐ [LATIN CAPITAL LETTER TURNED A]
+	0x2C71: "v",    // ⱱ [LATIN SMALL LETTER V WITH RIGHT HOOK]
+	0x2C72: "W",    // Ⱳ [LATIN CAPITAL LETTER W WITH HOOK]
+	0x2C73: "w",    // ⱳ [LATIN SMALL LETTER W WITH HOOK]
+	0x2C74: "v",    // ⱴ [LATIN SMALL LETTER V WITH CURL]
+	0x2C78: "e",    // ⱸ [LATIN SMALL LETTER E WITH NOTCH]
+	0x2C79: "r",    // ⱹ [LATIN SMALL LETTER TURNED R WITH TAIL]
+	0x2C7A: "o",    // ⱺ [LATIN SMALL LETTER O WITH LOW RING INSIDE]
+	0x2C7C: "j",    // ⱼ [LATIN SUBSCRIPT SMALL LETTER J]
+	0x2C7D: "V",    // ⱽ [MODIFIER LETTER CAPITAL V]
+	0x2C7E: "S",    // Ȿ [LATIN CAPITAL LETTER S WITH SWASH TAIL]
+	0x2C7F: "Z",    // Ɀ [LATIN CAPITAL LETTER Z WITH SWASH TAIL]
+	0x2E28: "(",    // ⸨ [LEFT DOUBLE PARENTHESIS]
+	0x2E29: ")",    // ⸩ [RIGHT DOUBLE PARENTHESIS]
+	0x2768: "(",    // ❨ [MEDIUM LEFT PARENTHESIS ORNAMENT]
+	0x2769: ")",    // ❩ [MEDIUM RIGHT PARENTHESIS ORNAMENT]
+	0x276A: "(",    // ❪ [MEDIUM FLATTENED LEFT PARENTHESIS ORNAMENT]
+	0x276B: ")",    // ❫ [MEDIUM FLATTENED RIGHT PARENTHESIS ORNAMENT]
+	0x276C: "<",    // ❬ [MEDIUM LEFT-POINTING ANGLE BRACKET ORNAMENT]
+	0x276D: ">",    // ❭ [MEDIUM RIGHT-POINTING ANGLE BRACKET ORNAMENT]
+	0x276E: "\"",   // ❮ [HEAVY LEFT-POINTING ANGLE QUOTATION MARK ORNAMENT]
+	0x276F: "\"",   // ❯ [HEAVY RIGHT-POINTING ANGLE QUOTATION MARK ORNAMENT]
+	0x2770: "<",    // ❰ [HEAVY LEFT-POINTING ANGLE BRACKET ORNAMENT]
+	0x2771: ">",    // ❱ [HEAVY RIGHT-POINTING ANGLE BRACKET ORNAMENT]
+	0x2772: "[",    // ❲ [LIGHT LEFT TORTOISE SHELL BRACKET ORNAMENT]
+	0x2773: "]",    // ❳ [LIGHT RIGHT TORTOISE SHELL BRACKET ORNAMENT]
+	0x2774: "{",    // ❴ [MEDIUM LEFT CURLY BRACKET ORNAMENT]
+	0x2775: "}",    // ❵ [MEDIUM RIGHT CURLY BRACKET ORNAMENT]
+	0xA728: "TZ",   // Ꜩ [LATIN CAPITAL LETTER TZ]
+	0xA729: "tz",   // ꜩ [LATIN SMALL LETTER TZ]
+	0xA730: "F",    // ꜰ [LATIN LETTER SMALL CAPITAL F]
+	0xA731: "S",    // ꜱ [LATIN LETTER SMALL CAPITAL S]
+	0xA732: "AA",   // Ꜳ [LATIN CAPITAL LETTER AA]
+	0xA733: "aa",   // ꜳ [LATIN SMALL LETTER AA]
+	0xA734: "AO",   // Ꜵ [LATIN CAPITAL LETTER AO]
+	0xA735: "ao",   // ꜵ [LATIN SMALL LETTER AO]
+	0xA736: "AU",   // Ꜷ [LATIN CAPITAL LETTER AU]
+	0xA737: "au",   // ꜷ [LATIN SMALL LETTER AU]
+	0xA738: "AV",   // Ꜹ [LATIN CAPITAL LETTER AV]
+	0xA739: "av",   // ꜹ [LATIN SMALL LETTER AV]
+	0xA73A: "AV",   // Ꜻ [LATIN CAPITAL LETTER AV WITH HORIZONTAL BAR]
+	0xA73B: "av",   // ꜻ [LATIN SMALL LETTER AV WITH HORIZONTAL BAR]
+	0xA73E: "C",    // Ꜿ [LATIN CAPITAL LETTER REVERSED C WITH DOT]
+	0xA73F: "c",    // ꜿ [LATIN SMALL LETTER REVERSED C WITH DOT]
+	0xA740: "K",    // Ꝁ [LATIN CAPITAL LETTER K WITH STROKE]
+	0xA741: "k",    // ꝁ [LATIN SMALL LETTER K WITH STROKE]
+	0xA742: "K",    // Ꝃ [LATIN CAPITAL LETTER K WITH DIAGONAL STROKE]
+	0xA743: "k",    // ꝃ [LATIN SMALL LETTER K WITH DIAGONAL STROKE]
+	0xA744: "K",    // Ꝅ [LATIN CAPITAL LETTER K WITH STROKE AND DIAGONAL STROKE]
+	0xA745: "k",    // ꝅ [LATIN SMALL LETTER K WITH STROKE AND DIAGONAL STROKE]
+	0xA748: "L",    // Ꝉ [LATIN CAPITAL LETTER L WITH HIGH STROKE]
+	0xA749: "l",    // ꝉ [LATIN SMALL LETTER L WITH HIGH STROKE]
+	0xA74A: "O",    // Ꝋ [LATIN CAPITAL LETTER O WITH LONG STROKE OVERLAY]
+	0xA74B: "o",    // ꝋ [LATIN SMALL LETTER O WITH LONG STROKE OVERLAY]
+	0xA74C: "O",    // Ꝍ [LATIN CAPITAL LETTER O WITH LOOP]
+	0xA74D: "o",    // ꝍ [LATIN SMALL LETTER O WITH LOOP]
+	0xA74E: "OO",   // Ꝏ [LATIN CAPITAL LETTER OO]
+	0xA74F: "oo",   // ꝏ [LATIN SMALL LETTER OO]
+	0xA750: "P",    // Ꝑ [LATIN CAPITAL LETTER P WITH STROKE THROUGH DESCENDER]
+	0xA751: "p",    // ꝑ [LATIN SMALL LETTER P WITH STROKE THROUGH DESCENDER]
+	0xA752: "P",    // Ꝓ [LATIN CAPITAL LETTER P WITH FLOURISH]
+	0xA753: "p",    // ꝓ [LATIN SMALL LETTER P WITH FLOURISH]
+	0xA754: "P",    // Ꝕ [LATIN CAPITAL LETTER P WITH SQUIRREL TAIL]
+	0xA755: "p",    // ꝕ [LATIN SMALL LETTER P WITH SQUIRREL TAIL]
+	0xA756: "Q",    // Ꝗ [LATIN CAPITAL LETTER Q WITH STROKE THROUGH DESCENDER]
+	0xA757: "q",    // ꝗ [LATIN SMALL LETTER Q WITH STROKE THROUGH DESCENDER]
+	0xA758: "Q",    // Ꝙ [LATIN CAPITAL LETTER Q WITH DIAGONAL STROKE]
+	0xA759: "q",    // ꝙ [LATIN SMALL LETTER Q WITH DIAGONAL STROKE]
+	0xA75E: "V",    // Ꝟ [LATIN CAPITAL LETTER V WITH DIAGONAL STROKE]
+	0xA75F: "v",    // ꝟ [LATIN SMALL LETTER V WITH DIAGONAL STROKE]
+	0xA760: "VY",   // Ꝡ [LATIN CAPITAL LETTER VY]
+	0xA761: "vy",   // ꝡ [LATIN SMALL LETTER VY]
+	0xA764: "TH",   // Ꝥ [LATIN CAPITAL LETTER THORN WITH STROKE]
+	0xA765: "th",   // ꝥ [LATIN SMALL LETTER THORN WITH STROKE]
+	0xA766: "TH",   // Ꝧ [LATIN CAPITAL LETTER THORN WITH STROKE THROUGH DESCENDER]
+	0xA767: "th",   // ꝧ [LATIN SMALL LETTER THORN WITH STROKE THROUGH DESCENDER]
+	0xA780: "L",    // Ꞁ [LATIN CAPITAL LETTER TURNED L]
+	0xA781: "l",    // ꞁ [LATIN SMALL LETTER TURNED L]
+	0xA78D: "H",    // Ɥ [LATIN CAPITAL LETTER TURNED H]
+	0xA78E: "l",    // ꞎ [LATIN SMALL LETTER L WITH RETROFLEX HOOK AND BELT]
+	0xA790: "N",    // Ꞑ [LATIN CAPITAL LETTER N WITH DESCENDER]
+	0xA791: "n",    // ꞑ [LATIN SMALL LETTER N WITH DESCENDER]
+	0xA792: "C",    // Ꞓ [LATIN CAPITAL LETTER C WITH BAR]
+	0xA793: "c",    // ꞓ [LATIN SMALL LETTER C WITH BAR]
+	0xA794: "c",    // ꞔ [LATIN SMALL LETTER C WITH PALATAL HOOK]
+	0xA795: "h",    // ꞕ [LATIN SMALL LETTER H WITH PALATAL HOOK]
+	0xA796: "B",    // Ꞗ [LATIN CAPITAL LETTER B WITH FLOURISH]
+	0xA797: "b",    // ꞗ [LATIN SMALL LETTER B WITH FLOURISH]
+	0xA798: "F",    // Ꞙ [LATIN CAPITAL LETTER F WITH STROKE]
+	0xA799: "f",    // ꞙ [LATIN SMALL LETTER F WITH STROKE]
+	0xA7A0: "G",    // Ꞡ [LATIN CAPITAL LETTER G WITH OBLIQUE STROKE]
+	0xA7A1: "g",    // ꞡ [LATIN SMALL LETTER G WITH OBLIQUE STROKE]
+	0xA7A2: "K",    // Ꞣ [LATIN CAPITAL LETTER K WITH OBLIQUE STROKE]
+	0xA7A3: "k",    // ꞣ [LATIN SMALL LETTER K WITH OBLIQUE STROKE]
+	0xA7A4: "N",    // Ꞥ [LATIN CAPITAL LETTER N WITH OBLIQUE STROKE]
+	0xA7A5: "n",    // ꞥ [LATIN SMALL LETTER N WITH OBLIQUE STROKE]
+	0xA7A6: "R",    // Ꞧ [LATIN CAPITAL LETTER R WITH OBLIQUE STROKE]
+	0xA7A7: "r",    // ꞧ [LATIN SMALL LETTER R WITH OBLIQUE STROKE]
+	0xA7A8: "S",    // Ꞩ [LATIN CAPITAL LETTER S WITH OBLIQUE STROKE]
+	0xA7A9: "s",    // ꞩ [LATIN SMALL LETTER S WITH OBLIQUE STROKE]
+	0xA7AA: "H",    // Ɦ [LATIN CAPITAL LETTER H WITH HOOK]
+	0xA7AC: "G",    // Ɡ [LATIN CAPITAL LETTER SCRIPT G]
+	0xA7AD: "L",    // Ɬ [LATIN CAPITAL LETTER L WITH BELT]
+	0xA7AF: "Q",    // ꞯ [LATIN LETTER SMALL CAPITAL Q]
+	0xA7B0: "K",    // Ʞ [LATIN CAPITAL LETTER TURNED K]
+	0xA7B1: "T",    // Ʇ [LATIN CAPITAL LETTER TURNED T]
+	0xA7B2: "J",    // Ʝ [LATIN CAPITAL LETTER J WITH CROSSED-TAIL]
+	0xA7B8: "U",    // Ꞹ [LATIN CAPITAL LETTER U WITH STROKE]
+	0xA7B9: "u",    // ꞹ [LATIN SMALL LETTER U WITH STROKE]
+	0xA7C4: "C",    // Ꞔ [LATIN CAPITAL LETTER C WITH PALATAL HOOK]
+	0xA7C5: "S",    // Ʂ [LATIN CAPITAL LETTER S WITH HOOK]
+	0xA7C6: "Z",    // Ᶎ [LATIN CAPITAL LETTER Z WITH PALATAL HOOK]
+	0xA7C7: "D",    // Ꟈ [LATIN CAPITAL LETTER D WITH SHORT STROKE OVERLAY]
+	0xA7C8: "d",    // ꟈ [LATIN SMALL LETTER D WITH SHORT STROKE OVERLAY]
+	0xA7C9: "S",    // Ꟊ [LATIN CAPITAL LETTER S WITH SHORT STROKE OVERLAY]
+	0xA7CA: "s",    // ꟊ [LATIN SMALL LETTER S WITH SHORT STROKE OVERLAY]
+	0xA7F2: "C",    // ꟲ [MODIFIER LETTER CAPITAL C]
+	0xA7F3: "F",    // ꟳ [MODIFIER LETTER CAPITAL F]
+	0xA7F4: "Q",    // ꟴ [MODIFIER LETTER CAPITAL Q]
+	0xFB00: "ff",   // ﬀ [LATIN SMALL LIGATURE FF]
+	0xFB01: "fi",   // ﬁ [LATIN SMALL LIGATURE FI]
+	0xFB02: "fl",   // ﬂ [LATIN SMALL LIGATURE FL]
+	0xFB03: "ffi",  // ﬃ [LATIN SMALL LIGATURE FFI]
+	0xFB04: "ffl",  // ﬄ [LATIN SMALL LIGATURE FFL]
+	0xFB05: "st",   // ﬅ [LATIN SMALL LIGATURE LONG S T]
+	0xFB06: "st",   // ﬆ [LATIN SMALL LIGATURE ST]
+	0xFF01: "!",    // ！ [FULLWIDTH EXCLAMATION MARK]
+	0xFF02: "\"",   // ＂ [FULLWIDTH QUOTATION MARK]
+	0xFF03: "#",    // ＃ [FULLWIDTH NUMBER SIGN]
+	0xFF04: "$",    // ＄ [FULLWIDTH DOLLAR SIGN]
+	0xFF05: "%",    // ％ [FULLWIDTH PERCENT SIGN]
+	0xFF06: "&",    // ＆ [FULLWIDTH AMPERSAND]
+	0xFF07: "'",    // ＇ [FULLWIDTH APOSTROPHE]
+	0xFF08: "(",    // （ [FULLWIDTH LEFT PARENTHESIS]
+	0xFF09: ")",    // ） [FULLWIDTH RIGHT PARENTHESIS]
+	0xFF0A: "*",    // ＊ [FULLWIDTH ASTERISK]
+	0xFF0B: "+",    // ＋ [FULLWIDTH PLUS SIGN]
+	0xFF0C: ",",    // ， [FULLWIDTH COMMA]
+	0xFF0D: "-",    // － [FULLWIDTH HYPHEN-MINUS]
+	0xFF0E: ".",    // ． [FULLWIDTH FULL STOP]
+	0xFF0F: "/",    // ／ [FULLWIDTH SOLIDUS]
+	0xFF10: "0",    // ０ [FULLWIDTH DIGIT ZERO]
+	0xFF11: "1",    // １ [FULLWIDTH DIGIT ONE]
+	0xFF12: "2",    // ２ [FULLWIDTH DIGIT TWO]
+	0xFF13: "3",    // ３ [FULLWIDTH DIGIT THREE]
+	0xFF14: "4",    // ４ [FULLWIDTH DIGIT FOUR]
+	0xFF15: "5",    // ５ [FULLWIDTH DIGIT FIVE]
+	0xFF16: "6",    // ６ [FULLWIDTH DIGIT SIX]
+	0xFF17: "7",    // ７ [FULLWIDTH DIGIT SEVEN]
+	0xFF18: "8",    // ８ [FULLWIDTH DIGIT EIGHT]
+	0xFF19: "9",    // ９ [FULLWIDTH DIGIT NINE]
+	0xFF1A: ":",    // ： [FULLWIDTH COLON]
+	0xFF1B: ";",    // ； [FULLWIDTH SEMICOLON]
+	0xFF1C: "<",    // ＜ [FULLWIDTH LESS-THAN SIGN]
+	0xFF1D: "=",    // ＝ [FULLWIDTH EQUALS SIGN]
+	0xFF1E: ">",    // ＞ [FULLWIDTH GREATER-THAN SIGN]
+	0xFF1F: "?",    // ？ [FULLWIDTH QUESTION MARK]
+	0xFF20: "@",    // ＠ [FULLWIDTH COMMERCIAL AT]
+	0xFF21: "A",    // Ａ [FULLWIDTH LATIN CAPITAL LETTER A]
+	0xFF22: "B",    // Ｂ [FULLWIDTH LATIN CAPITAL LETTER B]
+	0xFF23: "C",    // Ｃ [FULLWIDTH LATIN CAPITAL LETTER C]
+	0xFF24: "D",    // Ｄ [FULLWIDTH LATIN CAPITAL LETTER D]
+	0xFF25: "E",    // Ｅ [FULLWIDTH LATIN CAPITAL LETTER E]
+	0xFF26: "F",    // Ｆ [FULLWIDTH LATIN CAPITAL LETTER F]
+	0xFF27: "G",    // Ｇ [FULLWIDTH LATIN CAPITAL LETTER G]
+	0xFF28: "H",    // Ｈ [FULLWIDTH LATIN CAPITAL LETTER H]
+	0xFF29: "I",    // Ｉ [FULLWIDTH LATIN CAPITAL LETTER I]
+	0xFF2A: "J",    // Ｊ [FULLWIDTH LATIN CAPITAL LETTER J]
+	0xFF2B: "K",    // Ｋ [FULLWIDTH LATIN CAPITAL LETTER K]
+	0xFF2C: "L",    // Ｌ [FULLWIDTH LATIN CAPITAL LETTER L]
+	0xFF2D: "M",    // Ｍ [FULLWIDTH LATIN CAPITAL LETTER M]
+	0xFF2E: "N",    // Ｎ [FULLWIDTH LATIN CAPITAL LETTER N]
+	0xFF2F: "O",    // Ｏ [FULLWIDTH LATIN CAPITAL LETTER O]
+	0xFF30: "P",    // Ｐ [FULLWIDTH LATIN CAPITAL LETTER P]
+	0xFF31: "Q",    // Ｑ [FULLWIDTH LATIN CAPITAL LETTER Q]
+	0xFF32: "R",    // Ｒ [FULLWIDTH LATIN CAPITAL LETTER R]
+	0xFF33: "S",    // Ｓ [FULLWIDTH LATIN CAPITAL LETTER S]
+	0xFF34: "T",    // Ｔ [FULLWIDTH LATIN CAPITAL LETTER T]
+	0xFF35: "U",    // Ｕ [FULLWIDTH LATIN CAPITAL LETTER U]
+	0xFF36: "V",    // Ｖ [FULLWIDTH LATIN CAPITAL LETTER V]
+	0xFF37: "W",    // Ｗ [FULLWIDTH LATIN CAPITAL LETTER W]
+	0xFF38: "X",    // Ｘ [FULLWIDTH LATIN CAPITAL LETTER X]
+	0xFF39: "Y",    // Ｙ [FULLWIDTH LATIN CAPITAL LETTER Y]
+	0xFF3A: "Z",    // Ｚ [FULLWIDTH LATIN CAPITAL LETTER Z]
+	0xFF3B: "[",    // ［ [FULLWIDTH LEFT SQUARE BRACKET]
+	0xFF3C: "\\",   // ＼ [FULLWIDTH REVERSE SOLIDUS]
+	0xFF3D: "]",    // ］ [FULLWIDTH RIGHT SQUARE BRACKET]
+	0xFF3E: "^",    // ＾ [FULLWIDTH CIRCUMFLEX ACCENT]
+	0xFF3F: "_",    // ＿ [FULLWIDTH LOW LINE]
+	0xFF40: "`",    // ｀ [FULLWIDTH GRAVE ACCENT]
+	0xFF41: "a",    // ａ [FULLWIDTH LATIN SMALL LETTER A]
+	0xFF42: "b",    // ｂ [FULLWIDTH LATIN SMALL LETTER B]
+	0xFF43: "c",    // ｃ [FULLWIDTH LATIN SMALL LETTER C]
+	0xFF44: "d",    // ｄ [FULLWIDTH LATIN SMALL LETTER D]
+	0xFF45: "e",    // ｅ [FULLWIDTH LATIN SMALL LETTER E]
+	0xFF46: "f",    // ｆ [FULLWIDTH LATIN SMALL LETTER F]
+	0xFF47: "g",    // ｇ [FULLWIDTH LATIN SMALL LETTER G]
+	0xFF48: "h",    // ｈ [FULLWIDTH LATIN SMALL LETTER H]
+	0xFF49: "i",    // ｉ [FULLWIDTH LATIN SMALL LETTER I]
+	0xFF4A: "j",    // ｊ [FULLWIDTH LATIN SMALL LETTER J]
+	0xFF4B: "k",    // ｋ [FULLWIDTH LATIN SMALL LETTER K]
+	0xFF4C: "l",    // ｌ [FULLWIDTH LATIN SMALL LETTER L]
+	0xFF4D: "m",    // ｍ [FULLWIDTH LATIN SMALL LETTER M]
+	0xFF4E: "n",    // ｎ [FULLWIDTH LATIN SMALL LETTER N]
+	0xFF4F: "o",    // ｏ [FULLWIDTH LATIN SMALL LETTER O]
+	0xFF50: "p",    // ｐ [FULLWIDTH LATIN SMALL LETTER P]
+	0xFF51: "q",    // ｑ [FULLWIDTH LATIN SMALL LETTER Q]
+	0xFF52: "r",    // ｒ [FULLWIDTH LATIN SMALL LETTER R]
+	0xFF53: "s",    // ｓ [FULLWIDTH LATIN SMALL LETTER S]
+	0xFF54: "t",    // ｔ [FULLWIDTH LATIN SMALL LETTER T]
+	0xFF55: "u",    // ｕ [FULLWIDTH LATIN SMALL LETTER U]
+	0xFF56: "v",    // ｖ [FULLWIDTH LATIN SMALL LETTER V]
+	0xFF57: "w",    // ｗ [FULLWIDTH LATIN SMALL LETTER W]
+	0xFF58: "x",    // ｘ [FULLWIDTH LATIN SMALL LETTER X]
+	0xFF59: "y",    // ｙ [FULLWIDTH LATIN SMALL LETTER Y]
+	0xFF5A: "z",    // ｚ [FULLWIDTH LATIN SMALL LETTER Z]
+	0xFF5B: "{",    // ｛ [FULLWIDTH LEFT CURLY BRACKET]
+	0xFF5C: "|",    // ｜ [FULLWIDTH VERTICAL LINE]
+	0xFF5D: "}",    // ｝ [FULLWIDTH RIGHT CURLY BRACKET]
+	0xFF5E: "~",    // ～ [FULLWIDTH TILDE]
+}
@@ -0,0 +1,95 @@
+package miscellaneous
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"regexp"
+)
+
+func init() {
+	util.RegisterTokenFilterFactory("asciifolding", func(args map[string]string) (util.TokenFilterFactory, error) {
+		return NewASCIIFoldingFilterFactory(args)
+	})
+	util.RegisterTokenFilterFactory("keywordmarker", func(args map[string]string) (util.TokenFilterFactory, error) {
+		return NewKeywordMarkerFilterFactory(args)
+	})
+}
+
+// analysis/miscellaneous/ASCIIFoldingFilterFactory.java
+
+/*
+Factory for ASCIIFoldingFilter.
+
+It takes the argument preserveOriginal, false by default.
+*/
+type ASCIIFoldingFilterFactory struct {
+	*util.AbstractAnalysisFactory
+	preserveOriginal bool
+}
+
+// Creates a new ASCIIFoldingFilterFactory.
+func NewASCIIFoldingFilterFactory(args map[string]string) (*ASCIIFoldingFilterFactory, error) {
+	ans := &ASCIIFoldingFilterFactory{AbstractAnalysisFactory: util.NewAbstractAnalysisFactory(args)}
+	ans.preserveOriginal = ans.GetBoolean("preserveOriginal", false)
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *ASCIIFoldingFilterFactory) Create(input analysis.TokenStream) analysis.TokenStream {
+	return NewASCIIFoldingFilterWith(input, f.preserveOriginal)
+}
+
+// analysis/miscellaneous/KeywordMarkerFilterFactory.java
+
+/*
+Factory for KeywordMarkerFilter.
+
+It takes the arguments:
+
+  - protected: the comma separated paths of the files of protected
+    words, one word per line
+  - pattern: a regular expression matching the protected words
+  - ignoreCase: whether the protected words of the files are matched
+    regardless of their case, false by default
+*/
+type KeywordMarkerFilterFactory struct {
+	*util.AbstractAnalysisFactory
+	protectedWords *util.CharArraySet
+	pattern        *regexp.Regexp
+}
+
+// Creates a new KeywordMarkerFilterFactory, loading the files of
+// protected words.
+func NewKeywordMarkerFilterFactory(args map[string]string) (*KeywordMarkerFilterFactory, error) {
+	ans := &KeywordMarkerFilterFactory{AbstractAnalysisFactory: util.NewAbstractAnalysisFactory(args)}
+	files := ans.GetSet("protected")
+	pattern := ans.Get("pattern", "")
+	ignoreCase := ans.GetBoolean("ignoreCase", false)
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	var err error
+	if files != nil {
+		if ans.protectedWords, err = ans.LoadWordSet(files, "", ignoreCase); err != nil {
+			return nil, err
+		}
+	}
+	if pattern != "" {
+		if ans.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return ans, nil
+}
+
+func (f *KeywordMarkerFilterFactory) Create(input analysis.TokenStream) analysis.TokenStream {
+	if f.pattern != nil {
+		input = NewPatternKeywordMarkerFilter(input, f.pattern)
+	}
+	if f.protectedWords != nil {
+		input = NewSetKeywordMarkerFilter(input, f.protectedWords)
+	}
+	return input
+}
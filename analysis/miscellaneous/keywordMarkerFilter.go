@@ -0,0 +1,101 @@
+package miscellaneous
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"regexp"
+)
+
+// analysis/miscellaneous/KeywordMarkerFilter.java
+
+// Hooks of a KeywordMarkerFilter, implemented by the concrete type
+// which embeds KeywordMarkerFilterImpl.
+type KeywordMarkerFilter interface {
+	analysis.TokenStream
+	// Returns true if the current token should be marked as a keyword.
+	IsKeyword() bool
+}
+
+/*
+Marks terms as keywords via the KeywordAttribute, so that keyword
+aware filters, e.g. stemmers, leave them as they are. Whether a term
+is a keyword is decided by IsKeyword(); terms which are not keywords
+keep their KeywordAttribute, as set by a previous filter.
+*/
+type KeywordMarkerFilterImpl struct {
+	*analysis.TokenFilter
+	self        KeywordMarkerFilter
+	keywordAttr analysis.KeywordAttribute
+}
+
+// Creates a new KeywordMarkerFilter, whose IsKeyword() is implemented
+// by self.
+func NewKeywordMarkerFilter(self KeywordMarkerFilter, input analysis.TokenStream) *KeywordMarkerFilterImpl {
+	ans := &KeywordMarkerFilterImpl{TokenFilter: analysis.NewTokenFilter(input), self: self}
+	ans.keywordAttr = ans.Attributes().Add("KeywordAttribute").(analysis.KeywordAttribute)
+	return ans
+}
+
+func (f *KeywordMarkerFilterImpl) IncrementToken() (bool, error) {
+	ok, err := f.Input.IncrementToken()
+	if err != nil || !ok {
+		return false, err
+	}
+	if f.self.IsKeyword() {
+		f.keywordAttr.SetKeyword(true)
+	}
+	return true, nil
+}
+
+// analysis/miscellaneous/SetKeywordMarkerFilter.java
+
+// Marks terms as keywords via the KeywordAttribute. Each token
+// contained in the provided set is marked as a keyword by setting
+// KeywordAttribute.SetKeyword(true).
+type SetKeywordMarkerFilter struct {
+	*KeywordMarkerFilterImpl
+	keywordSet *util.CharArraySet
+	termAtt    analysis.CharTermAttribute
+}
+
+// Create a new SetKeywordMarkerFilter, that marks the current token
+// as a keyword if the token's term buffer is contained in the given
+// set via the KeywordAttribute.
+func NewSetKeywordMarkerFilter(input analysis.TokenStream, keywordSet *util.CharArraySet) *SetKeywordMarkerFilter {
+	ans := &SetKeywordMarkerFilter{keywordSet: keywordSet}
+	ans.KeywordMarkerFilterImpl = NewKeywordMarkerFilter(ans, input)
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	return ans
+}
+
+func (f *SetKeywordMarkerFilter) IsKeyword() bool {
+	return f.keywordSet.Contains(f.termAtt.Bytes())
+}
+
+// analysis/miscellaneous/PatternKeywordMarkerFilter.java
+
+// Marks terms as keywords via the KeywordAttribute. Each token that
+// matches the provided pattern is marked as a keyword by setting
+// KeywordAttribute.SetKeyword(true).
+type PatternKeywordMarkerFilter struct {
+	*KeywordMarkerFilterImpl
+	pattern *regexp.Regexp
+	termAtt analysis.CharTermAttribute
+}
+
+/*
+Create a new PatternKeywordMarkerFilter, that marks the current token
+as a keyword if the token's term buffer matches the provided pattern
+via the KeywordAttribute. The pattern must match the entire term, as
+Java's Matcher.matches() does.
+*/
+func NewPatternKeywordMarkerFilter(input analysis.TokenStream, pattern *regexp.Regexp) *PatternKeywordMarkerFilter {
+	ans := &PatternKeywordMarkerFilter{pattern: regexp.MustCompile(`^(?:` + pattern.String() + `)$`)}
+	ans.KeywordMarkerFilterImpl = NewKeywordMarkerFilter(ans, input)
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	return ans
+}
+
+func (f *PatternKeywordMarkerFilter) IsKeyword() bool {
+	return f.pattern.Match(f.termAtt.Bytes())
+}
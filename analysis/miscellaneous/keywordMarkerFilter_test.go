@@ -0,0 +1,75 @@
+package miscellaneous
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/analysistest"
+	"github.com/balzaczyy/golucene/analysis/core"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Lower cases the terms which are not keywords.
+type lowerCaseFilterMock struct {
+	*analysis.TokenFilter
+	termAtt     analysis.CharTermAttribute
+	keywordAttr analysis.KeywordAttribute
+}
+
+func newLowerCaseFilterMock(input analysis.TokenStream) *lowerCaseFilterMock {
+	ans := &lowerCaseFilterMock{TokenFilter: analysis.NewTokenFilter(input)}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.keywordAttr = ans.Attributes().Add("KeywordAttribute").(analysis.KeywordAttribute)
+	return ans
+}
+
+func (f *lowerCaseFilterMock) IncrementToken() (bool, error) {
+	ok, err := f.Input.IncrementToken()
+	if err != nil || !ok {
+		return false, err
+	}
+	if !f.keywordAttr.IsKeyword() {
+		f.termAtt.CopyBytes(bytes.ToLower(f.termAtt.Bytes()))
+	}
+	return true, nil
+}
+
+func TestSetKeywordMarkerFilter(t *testing.T) {
+	set := util.NewCharArraySetFrom([]string{"LuceneFox"}, false)
+	ts := newLowerCaseFilterMock(NewSetKeywordMarkerFilter(
+		core.NewWhitespaceTokenizer(strings.NewReader("The quIck browN LuceneFox Jumps")), set))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"the", "quick", "brown", "LuceneFox", "jumps"},
+		nil, nil, nil, nil, nil, 31)
+
+	set = util.NewCharArraySetFrom([]string{"lucenefox"}, true)
+	ts = newLowerCaseFilterMock(NewSetKeywordMarkerFilter(
+		core.NewWhitespaceTokenizer(strings.NewReader("The quIck browN LuceneFox Jumps")), set))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"the", "quick", "brown", "LuceneFox", "jumps"},
+		nil, nil, nil, nil, nil, 31)
+}
+
+func TestPatternKeywordMarkerFilter(t *testing.T) {
+	ts := newLowerCaseFilterMock(NewPatternKeywordMarkerFilter(
+		core.NewWhitespaceTokenizer(strings.NewReader("The quIck browN LuceneFox Jumps")),
+		regexp.MustCompile("[a-zA-Z]+[fF]ox")))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"the", "quick", "brown", "LuceneFox", "jumps"},
+		nil, nil, nil, nil, nil, 31)
+
+	// the pattern must match the whole term
+	ts = newLowerCaseFilterMock(NewPatternKeywordMarkerFilter(
+		core.NewWhitespaceTokenizer(strings.NewReader("The quIck browN LuceneFox Jumps")),
+		regexp.MustCompile("[fF]ox")))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"the", "quick", "brown", "lucenefox", "jumps"},
+		nil, nil, nil, nil, nil, 31)
+}
+
+func TestComposeKeywordMarkerFilter(t *testing.T) {
+	set := util.NewCharArraySetFrom([]string{"Birds", "Houses"}, false)
+	ts := newLowerCaseFilterMock(NewSetKeywordMarkerFilter(NewPatternKeywordMarkerFilter(
+		core.NewWhitespaceTokenizer(strings.NewReader("Dogs Trees Birds Houses")),
+		regexp.MustCompile("Dogs")), set))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"Dogs", "trees", "Birds", "Houses"},
+		nil, nil, nil, nil, nil, 23)
+}
@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"errors"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// analysis/NumericTokenStream.java
+
+/*
+Expert: This class provides a TokenStream for indexing numeric values
+that can be used by NumericRangeQuery.
+
+Note that for simple usage, IntField, LongField, FloatField or
+DoubleField is recommended. These fields disable norms and term
+freqs, as they are not usually needed during searching. If you need
+to change these settings, you should use this class.
+
+Here's an example usage, for an int field:
+
+	ft := document.NewFieldType()
+	ft.SetIndexed(true)
+	ft.SetOmitNorms(true)
+	ft.SetIndexOptions(document.INDEX_OPT_DOCS_ONLY)
+	ft.Freeze()
+	field := document.NewFieldFromTokenStream(name,
+		analysis.NewNumericTokenStream(precisionStep).SetIntValue(value), ft)
+
+The stream emits the value at full precision first, then at each
+lower precision, with a position increment of zero, so all the terms
+of a value are at the same position. The prefix coded bytes of each
+value are set as the term of the CharTermAttribute, and its precision
+is given by the TypeAttribute, TOKEN_TYPE_FULL_PREC or
+TOKEN_TYPE_LOWER_PREC.
+
+precisionStep is the number of bits of precision dropped at each
+lower precision; smaller values index more terms but make range
+queries faster. A precision step larger than the bit size of the
+value indexes the full precision value only, which disables range
+queries optimization, but not sorting.
+*/
+type NumericTokenStream struct {
+	*TokenStreamImpl
+	termAtt       CharTermAttribute
+	typeAtt       TypeAttribute
+	posIncrAtt    PositionIncrementAttribute
+	precisionStep int
+	valSize       int // valSize==0 means not initialized
+	value         int64
+	shift         int
+}
+
+const (
+	// The full precision token gets this token type assigned.
+	TOKEN_TYPE_FULL_PREC = "fullPrecNumeric"
+	// The lower precision tokens gets this token type assigned.
+	TOKEN_TYPE_LOWER_PREC = "lowerPrecNumeric"
+)
+
+// Creates a token stream for numeric values with the specified
+// precisionStep. It panics if precisionStep is less than 1.
+func NewNumericTokenStream(precisionStep int) *NumericTokenStream {
+	if precisionStep < 1 {
+		panic("precisionStep must be >=1")
+	}
+	ans := &NumericTokenStream{TokenStreamImpl: NewTokenStream(), precisionStep: precisionStep}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	ans.typeAtt = ans.Attributes().Add("TypeAttribute").(TypeAttribute)
+	ans.posIncrAtt = ans.Attributes().Add("PositionIncrementAttribute").(PositionIncrementAttribute)
+	return ans
+}
+
+// Initializes the token stream with the supplied int64 value.
+func (ts *NumericTokenStream) SetLongValue(value int64) *NumericTokenStream {
+	ts.value, ts.valSize, ts.shift = value, 64, 0
+	return ts
+}
+
+// Initializes the token stream with the supplied int32 value.
+func (ts *NumericTokenStream) SetIntValue(value int32) *NumericTokenStream {
+	ts.value, ts.valSize, ts.shift = int64(value), 32, 0
+	return ts
+}
+
+// Initializes the token stream with the supplied float64 value.
+func (ts *NumericTokenStream) SetDoubleValue(value float64) *NumericTokenStream {
+	ts.value, ts.valSize, ts.shift = util.DoubleToSortableLong(value), 64, 0
+	return ts
+}
+
+// Initializes the token stream with the supplied float32 value.
+func (ts *NumericTokenStream) SetFloatValue(value float32) *NumericTokenStream {
+	ts.value, ts.valSize, ts.shift = int64(util.FloatToSortableInt(value)), 32, 0
+	return ts
+}
+
+// Returns the precision step.
+func (ts *NumericTokenStream) PrecisionStep() int {
+	return ts.precisionStep
+}
+
+func (ts *NumericTokenStream) Reset() error {
+	if ts.valSize == 0 {
+		return errors.New("call Set???Value() before usage")
+	}
+	ts.shift = 0
+	return nil
+}
+
+func (ts *NumericTokenStream) IncrementToken() (bool, error) {
+	if ts.valSize == 0 {
+		return false, errors.New("call Set???Value() before usage")
+	}
+	if ts.shift >= ts.valSize {
+		return false, nil
+	}
+	ts.Attributes().Clear()
+	if ts.valSize == 64 {
+		ts.termAtt.CopyBytes(util.LongToPrefixCoded(ts.value, ts.shift))
+	} else {
+		ts.termAtt.CopyBytes(util.IntToPrefixCoded(int32(ts.value), ts.shift))
+	}
+	if ts.shift == 0 {
+		ts.typeAtt.SetType(TOKEN_TYPE_FULL_PREC)
+	} else {
+		ts.typeAtt.SetType(TOKEN_TYPE_LOWER_PREC)
+		ts.posIncrAtt.SetPositionIncrement(0)
+	}
+	ts.shift += ts.precisionStep
+	return true, nil
+}
@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"github.com/balzaczyy/golucene/util"
+	"testing"
+)
+
+func TestNumericTokenStream(t *testing.T) {
+	const value = int64(4573245871874382)
+	ts := NewNumericTokenStream(8).SetLongValue(value)
+	termAtt := ts.Attributes().Get("CharTermAttribute").(CharTermAttribute)
+	typeAtt := ts.Attributes().Get("TypeAttribute").(TypeAttribute)
+	posIncrAtt := ts.Attributes().Get("PositionIncrementAttribute").(PositionIncrementAttribute)
+	if err := ts.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	for shift := 0; shift < 64; shift += 8 {
+		ok, err := ts.IncrementToken()
+		if err != nil || !ok {
+			t.Fatalf("Expected a token at shift %v (%v)", shift, err)
+		}
+		if s, err := util.PrefixCodedLongShift(termAtt.Bytes()); err != nil || s != shift {
+			t.Errorf("Expected shift %v, but was %v (%v)", shift, s, err)
+		}
+		decoded, err := util.PrefixCodedToLong(termAtt.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := value >> uint(shift) << uint(shift); decoded != expected {
+			t.Errorf("Expected %v, but was %v", expected, decoded)
+		}
+		expectedIncr, expectedType := 0, TOKEN_TYPE_LOWER_PREC
+		if shift == 0 {
+			expectedIncr, expectedType = 1, TOKEN_TYPE_FULL_PREC
+		}
+		if posIncrAtt.PositionIncrement() != expectedIncr {
+			t.Errorf("Unexpected position increment %v at shift %v", posIncrAtt.PositionIncrement(), shift)
+		}
+		if typeAtt.Type() != expectedType {
+			t.Errorf("Unexpected type %v at shift %v", typeAtt.Type(), shift)
+		}
+	}
+	if ok, err := ts.IncrementToken(); ok || err != nil {
+		t.Errorf("Stream should be exhausted (%v)", err)
+	}
+
+	// a reset stream starts over with the new value
+	ts.SetIntValue(-1)
+	if err := ts.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	for {
+		ok, err := ts.IncrementToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		if v, err := util.PrefixCodedToInt(termAtt.Bytes()); err != nil || v != int32(-1)>>uint(count*8)<<uint(count*8) {
+			t.Errorf("Unexpected value %v at shift %v (%v)", v, count*8, err)
+		}
+		count++
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 tokens, but was %v", count)
+	}
+
+	if err := NewNumericTokenStream(4).Reset(); err == nil {
+		t.Error("Should not reset a stream without a value")
+	}
+}
@@ -0,0 +1,42 @@
+package standard
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io"
+)
+
+func init() {
+	util.RegisterTokenizerFactory("standard", func(args map[string]string) (util.TokenizerFactory, error) {
+		return NewStandardTokenizerFactory(args)
+	})
+}
+
+// analysis/standard/StandardTokenizerFactory.java
+
+/*
+Factory for StandardTokenizer.
+
+It takes the argument maxTokenLength, DEFAULT_MAX_TOKEN_LENGTH by
+default.
+*/
+type StandardTokenizerFactory struct {
+	*util.AbstractAnalysisFactory
+	maxTokenLength int
+}
+
+// Creates a new StandardTokenizerFactory.
+func NewStandardTokenizerFactory(args map[string]string) (*StandardTokenizerFactory, error) {
+	ans := &StandardTokenizerFactory{AbstractAnalysisFactory: util.NewAbstractAnalysisFactory(args)}
+	ans.maxTokenLength = ans.GetInt("maxTokenLength", DEFAULT_MAX_TOKEN_LENGTH)
+	if err := ans.Err(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *StandardTokenizerFactory) Create(input io.Reader) analysis.Tokenizer {
+	ans := NewStandardTokenizer(input)
+	ans.SetMaxTokenLength(f.maxTokenLength)
+	return ans
+}
@@ -0,0 +1,78 @@
+package standard
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/core"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"io"
+)
+
+// analysis/standard/StandardAnalyzer.java
+
+// Default maximum allowed token length
+const DEFAULT_MAX_TOKEN_LENGTH = 255
+
+// A set containing some common English words that are usually not
+// useful for searching. It is shared, and must not be modified.
+var STOP_WORDS_SET = core.ENGLISH_STOP_WORDS_SET
+
+/*
+Filters StandardTokenizer with LowerCaseFilter and StopFilter, using
+a list of English stop words.
+
+It produces the same terms as Java Lucene's StandardAnalyzer, so the
+terms of an index built with either match the queries analyzed by the
+other. Offsets are in bytes of the UTF-8 text, rather than in UTF-16
+code units.
+*/
+type StandardAnalyzer struct {
+	*util.StopwordAnalyzerBase
+	maxTokenLength int
+}
+
+// Builds an analyzer with the default stop words (STOP_WORDS_SET).
+func NewStandardAnalyzer() *StandardAnalyzer {
+	return NewStandardAnalyzerWithStopWords(STOP_WORDS_SET)
+}
+
+// Builds an analyzer with the given stop words.
+func NewStandardAnalyzerWithStopWords(stopWords *util.CharArraySet) *StandardAnalyzer {
+	ans := &StandardAnalyzer{maxTokenLength: DEFAULT_MAX_TOKEN_LENGTH}
+	ans.StopwordAnalyzerBase = util.NewStopwordAnalyzerBase(ans, stopWords)
+	return ans
+}
+
+// Builds an analyzer with the stop words from the given file, one
+// word per line.
+func NewStandardAnalyzerFromFile(path string) (*StandardAnalyzer, error) {
+	stopWords, err := util.LoadStopwordSetFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewStandardAnalyzerWithStopWords(stopWords), nil
+}
+
+/*
+Set maximum allowed token length. If a token is seen that exceeds
+this length then it is discarded. This setting only takes effect the
+next time TokenStream() is called.
+*/
+func (a *StandardAnalyzer) SetMaxTokenLength(length int) {
+	a.maxTokenLength = length
+}
+
+// Returns the maximum allowed token length.
+func (a *StandardAnalyzer) MaxTokenLength() int {
+	return a.maxTokenLength
+}
+
+func (a *StandardAnalyzer) CreateComponents(fieldName string, reader io.Reader) *analysis.TokenStreamComponents {
+	src := NewStandardTokenizer(reader)
+	src.SetMaxTokenLength(a.maxTokenLength)
+	var tok analysis.TokenStream = core.NewLowerCaseFilter(src)
+	tok = core.NewStopFilter(tok, a.StopwordSet())
+	return analysis.NewTokenStreamComponentsWith(src, tok, func(reader io.Reader) error {
+		src.SetMaxTokenLength(a.maxTokenLength)
+		return src.SetReader(reader)
+	})
+}
@@ -0,0 +1,79 @@
+package standard
+
+import (
+	"github.com/balzaczyy/golucene/analysis/analysistest"
+	"github.com/balzaczyy/golucene/analysis/util"
+	"strings"
+	"testing"
+)
+
+func assertTokenizesTo(t *testing.T, input string, output []string, types []string) {
+	ts := NewStandardTokenizer(strings.NewReader(input))
+	analysistest.AssertTokenStreamContents(t, ts, output, nil, nil, types, nil, nil, len(input))
+}
+
+func TestStandardTokenizer(t *testing.T) {
+	for _, v := range []struct {
+		input  string
+		output []string
+	}{
+		{"", nil},
+		{"  .,;  ", nil},
+		{"Whát's this thing do?", []string{"Whát's", "this", "thing", "do"}},
+		{"a-b", []string{"a", "b"}},
+		{"U.S.A. O'Reilly don't", []string{"U.S.A", "O'Reilly", "don't"}},
+		{"user@example.com http://lucene.apache.org",
+			[]string{"user", "example.com", "http", "lucene.apache.org"}},
+		{"snake_case __init__ ___", []string{"snake_case", "__init__"}},
+		{"1,000.50 3.14 v2 1-2", []string{"1,000.50", "3.14", "v2", "1", "2"}},
+		{"ab:cd 12:30", []string{"ab:cd", "12", "30"}},
+		{"Größe straße", []string{"Größe", "straße"}},
+		{"Ελληνικά русский עברית", []string{"Ελληνικά", "русский", "עברית"}},
+		{"a\u00adb", []string{"a\u00adb"}}, // soft hyphen
+	} {
+		assertTokenizesTo(t, v.input, v.output, nil)
+	}
+}
+
+func TestStandardTokenizerTypes(t *testing.T) {
+	assertTokenizesTo(t, "我是中国人", []string{"我", "是", "中", "国", "人"},
+		[]string{"<IDEOGRAPHIC>", "<IDEOGRAPHIC>", "<IDEOGRAPHIC>", "<IDEOGRAPHIC>", "<IDEOGRAPHIC>"})
+	assertTokenizesTo(t, "カタカナ ひらがな", []string{"カタカナ", "ひ", "ら", "が", "な"},
+		[]string{"<KATAKANA>", "<HIRAGANA>", "<HIRAGANA>", "<HIRAGANA>", "<HIRAGANA>"})
+	assertTokenizesTo(t, "안녕하세요 한글입니다", []string{"안녕하세요", "한글입니다"},
+		[]string{"<HANGUL>", "<HANGUL>"})
+	assertTokenizesTo(t, "การที่ได้ต้องแสดงว่างานดี. แล้วเธอจะไปไหน? ๑๒๓๔",
+		[]string{"การที่ได้ต้องแสดงว่างานดี", "แล้วเธอจะไปไหน", "๑๒๓๔"},
+		[]string{"<SOUTHEAST_ASIAN>", "<SOUTHEAST_ASIAN>", "<NUM>"})
+	assertTokenizesTo(t, "abc 123 a1 1a 12_34 カ_ナ", []string{"abc", "123", "a1", "1a", "12_34", "カ_ナ"},
+		[]string{"<ALPHANUM>", "<NUM>", "<ALPHANUM>", "<ALPHANUM>", "<NUM>", "<ALPHANUM>"})
+}
+
+func TestStandardTokenizerOffsets(t *testing.T) {
+	input := "  Größe, 中文 end  "
+	ts := NewStandardTokenizer(strings.NewReader(input))
+	analysistest.AssertTokenStreamContents(t, ts, []string{"Größe", "中", "文", "end"},
+		[]int{2, 11, 14, 18}, []int{9, 14, 17, 21}, nil, []int{1, 1, 1, 1}, nil, len(input))
+}
+
+func TestStandardTokenizerMaxTokenLength(t *testing.T) {
+	ts := NewStandardTokenizer(strings.NewReader("one twelve threeee four"))
+	ts.SetMaxTokenLength(5)
+	analysistest.AssertTokenStreamContents(t, ts, []string{"one", "four"}, nil, nil, nil, []int{1, 3}, nil, 23)
+}
+
+func TestStandardAnalyzer(t *testing.T) {
+	a := NewStandardAnalyzer()
+	analysistest.AssertAnalyzesTo(t, a, "The Quick-Brown FOX jumped over the lazy DOG's back",
+		[]string{"quick", "brown", "fox", "jumped", "over", "lazy", "dog's", "back"},
+		nil, nil, nil, []int{2, 1, 1, 1, 1, 2, 1, 1})
+	analysistest.AssertAnalyzesTo(t, a, "ÉCOLE Été", []string{"école", "été"},
+		[]int{0, 7}, []int{6, 12}, []string{"<ALPHANUM>", "<ALPHANUM>"}, nil)
+
+	// reused components pick up the new max token length
+	a.SetMaxTokenLength(3)
+	analysistest.AssertAnalyzesTo(t, a, "abcd abc", []string{"abc"}, nil, nil, nil, []int{2})
+
+	a = NewStandardAnalyzerWithStopWords(util.NewCharArraySetFrom([]string{"quick"}, false))
+	analysistest.AssertAnalyzesTo(t, a, "The quick fox", []string{"the", "fox"}, nil, nil, nil, []int{1, 2})
+}
@@ -0,0 +1,124 @@
+package standard
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"io"
+	"io/ioutil"
+	"unicode/utf8"
+)
+
+// analysis/standard/StandardTokenizer.java
+
+// The token types of StandardTokenizer.
+const (
+	ALPHANUM = iota
+	NUM
+	SOUTHEAST_ASIAN
+	IDEOGRAPHIC
+	HIRAGANA
+	KATAKANA
+	HANGUL
+)
+
+// String token types that correspond to token type int constants, as
+// set in the TypeAttribute.
+var TOKEN_TYPES = []string{
+	"<ALPHANUM>",
+	"<NUM>",
+	"<SOUTHEAST_ASIAN>",
+	"<IDEOGRAPHIC>",
+	"<HIRAGANA>",
+	"<KATAKANA>",
+	"<HANGUL>",
+}
+
+/*
+A grammar-based tokenizer, which implements the Word Break rules from
+the Unicode Text Segmentation algorithm, as specified in Unicode
+Standard Annex #29, as Java Lucene's StandardTokenizer does.
+
+Words of letters and digits are tokens, including their inner
+apostrophes, periods and connector punctuations, e.g. "O'Neil",
+"3.14" or "snake_case"; each ideographic (Han) and Hiragana character
+is a token of its own. Punctuations, symbols and spaces are dropped.
+
+Tokens longer than the maximum token length are skipped, leaving a
+hole in the positions.
+*/
+type StandardTokenizer struct {
+	*analysis.TokenizerImpl
+	scanner        *standardTokenizerImpl
+	maxTokenLength int
+	termAtt        analysis.CharTermAttribute
+	offsetAtt      analysis.OffsetAttribute
+	posIncrAtt     analysis.PositionIncrementAttribute
+	typeAtt        analysis.TypeAttribute
+}
+
+/*
+Creates a new instance of the StandardTokenizer. Attaches the input
+to the newly created scanner.
+
+See http://issues.apache.org/jira/browse/LUCENE-1068
+*/
+func NewStandardTokenizer(input io.Reader) *StandardTokenizer {
+	ans := &StandardTokenizer{
+		TokenizerImpl:  analysis.NewTokenizer(input),
+		scanner:        new(standardTokenizerImpl),
+		maxTokenLength: DEFAULT_MAX_TOKEN_LENGTH,
+	}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(analysis.OffsetAttribute)
+	ans.posIncrAtt = ans.Attributes().Add("PositionIncrementAttribute").(analysis.PositionIncrementAttribute)
+	ans.typeAtt = ans.Attributes().Add("TypeAttribute").(analysis.TypeAttribute)
+	return ans
+}
+
+// Set the max allowed token length, in characters. Any token longer
+// than this is skipped.
+func (t *StandardTokenizer) SetMaxTokenLength(length int) {
+	t.maxTokenLength = length
+}
+
+// Returns the max allowed token length.
+func (t *StandardTokenizer) MaxTokenLength() int {
+	return t.maxTokenLength
+}
+
+func (t *StandardTokenizer) IncrementToken() (bool, error) {
+	t.Attributes().Clear()
+	skippedPositions := 0
+	for {
+		tokenType := t.scanner.nextToken()
+		if tokenType < 0 {
+			return false, nil
+		}
+		token := t.scanner.text[t.scanner.start:t.scanner.end]
+		if utf8.RuneCount(token) <= t.maxTokenLength {
+			t.posIncrAtt.SetPositionIncrement(skippedPositions + 1)
+			t.termAtt.AppendBytes(token)
+			t.offsetAtt.SetOffset(t.scanner.start, t.scanner.end)
+			t.typeAtt.SetType(TOKEN_TYPES[tokenType])
+			return true, nil
+		}
+		// When we skip a too-long term, we still increment the
+		// position increment
+		skippedPositions++
+	}
+}
+
+func (t *StandardTokenizer) End() error {
+	// set final offset
+	finalOffset := len(t.scanner.text)
+	t.offsetAtt.SetOffset(finalOffset, finalOffset)
+	return nil
+}
+
+func (t *StandardTokenizer) Reset() error {
+	text, err := ioutil.ReadAll(t.Input)
+	if err != nil {
+		return err
+	}
+	t.scanner.reset(text)
+	return nil
+}
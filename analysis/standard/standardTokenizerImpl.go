@@ -0,0 +1,261 @@
+package standard
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// analysis/standard/StandardTokenizerImpl.java
+
+/*
+The word break property values of the Unicode Text Segmentation
+algorithm (UAX#29) used by StandardTokenizer, with the classes of
+characters it emits as tokens on their own. As in Lucene, which
+implements Unicode 6.1, the apostrophe is a MidNumLet, and Hebrew
+letters are ALetters.
+*/
+const (
+	wbOther = iota
+	wbExtend
+	wbFormat
+	wbALetter
+	wbHangul // ALetter in the Hangul script
+	wbNumeric
+	wbKatakana
+	wbMidLetter
+	wbMidNumLet
+	wbMidNum
+	wbExtendNumLet
+	wbHan
+	wbHiragana
+	wbComplexContext
+)
+
+// Scripts with Line_Break=Complex_Context characters, which are kept
+// together as a single token.
+var complexContextScripts = []*unicode.RangeTable{
+	unicode.Thai, unicode.Lao, unicode.Myanmar, unicode.Khmer,
+	unicode.Tai_Le, unicode.New_Tai_Lue, unicode.Tai_Tham, unicode.Tai_Viet,
+}
+
+// Returns the word break class of a character.
+func wordBreakClass(r rune) int {
+	switch r {
+	case 0x2E, 0x27, 0x2018, 0x2019, 0x2024, 0xFE52, 0xFF07, 0xFF0E:
+		return wbMidNumLet
+	case 0x3A, 0xB7, 0x387, 0x5F4, 0x2027, 0xFE13, 0xFE55, 0xFF1A:
+		return wbMidLetter
+	case 0x2C, 0x3B, 0x37E, 0x589, 0x60C, 0x60D, 0x66C, 0x7F8, 0x2044,
+		0xFE10, 0xFE14, 0xFE50, 0xFE54, 0xFF0C, 0xFF1B:
+		return wbMidNum
+	case 0x200C, 0x200D:
+		return wbExtend
+	case 0x200B:
+		return wbOther
+	case 0x3031, 0x3032, 0x3033, 0x3034, 0x3035, 0x309B, 0x309C, 0x30A0, 0x30FC, 0xFF70:
+		return wbKatakana
+	case 0x5F3:
+		return wbALetter
+	}
+	if r < utf8.RuneSelf {
+		// fast path for ASCII
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+			return wbALetter
+		case '0' <= r && r <= '9':
+			return wbNumeric
+		case r == '_':
+			return wbExtendNumLet
+		}
+		return wbOther
+	}
+	if unicode.IsLetter(r) || unicode.IsMark(r) {
+		if unicode.IsOneOf(complexContextScripts, r) {
+			return wbComplexContext
+		}
+	}
+	switch {
+	case unicode.IsMark(r) || unicode.Is(unicode.Other_Grapheme_Extend, r):
+		return wbExtend
+	case unicode.Is(unicode.Cf, r):
+		return wbFormat
+	case unicode.Is(unicode.Han, r):
+		return wbHan
+	case unicode.Is(unicode.Hiragana, r):
+		return wbHiragana
+	case unicode.Is(unicode.Katakana, r):
+		return wbKatakana
+	case unicode.Is(unicode.Nd, r):
+		return wbNumeric
+	case unicode.Is(unicode.Pc, r):
+		return wbExtendNumLet
+	case unicode.Is(unicode.Ideographic, r):
+		return wbOther
+	case unicode.IsLetter(r) || unicode.Is(unicode.Nl, r) || unicode.Is(unicode.Other_Alphabetic, r):
+		if unicode.Is(unicode.Hangul, r) {
+			return wbHangul
+		}
+		return wbALetter
+	}
+	return wbOther
+}
+
+func isLetter(class int) bool {
+	return class == wbALetter || class == wbHangul
+}
+
+/*
+Scans the text for the tokens of StandardTokenizer, following the
+word boundary rules of UAX#29: the words made of letters, digits and
+Katakana are returned as a whole, while each ideographic and Hiragana
+character is a token of its own, and runs of South East Asian
+characters are kept together. Everything else is skipped.
+*/
+type standardTokenizerImpl struct {
+	text []byte
+	// the position of the next character to scan
+	pos int
+	// start and end of the last token
+	start, end int
+}
+
+func (s *standardTokenizerImpl) reset(text []byte) {
+	s.text, s.pos, s.start, s.end = text, 0, 0, 0
+}
+
+// Returns the class of the character at pos, and the position after
+// it and the Format and Extend characters which follow it (UAX#29 WB4).
+func (s *standardTokenizerImpl) charAt(pos int) (class, next int) {
+	r, size := utf8.DecodeRune(s.text[pos:])
+	class, next = wordBreakClass(r), pos+size
+	for next < len(s.text) {
+		r, size = utf8.DecodeRune(s.text[next:])
+		if c := wordBreakClass(r); c != wbExtend && c != wbFormat {
+			break
+		}
+		next += size
+	}
+	return
+}
+
+// Returns the class of the character at pos, or wbOther at the end of
+// the text.
+func (s *standardTokenizerImpl) classAt(pos int) int {
+	if pos >= len(s.text) {
+		return wbOther
+	}
+	class, _ := s.charAt(pos)
+	return class
+}
+
+// Returns the type of the next token, whose bounds are then given by
+// start and end, or -1 at the end of the text.
+func (s *standardTokenizerImpl) nextToken() int {
+	for s.pos < len(s.text) {
+		s.start = s.pos
+		r, size := utf8.DecodeRune(s.text[s.pos:])
+		switch class := wordBreakClass(r); class {
+		case wbHan, wbHiragana:
+			// UAX#29 WB14. Any ÷ Any
+			s.pos += size
+			s.end = s.pos
+			if class == wbHan {
+				return IDEOGRAPHIC
+			}
+			return HIRAGANA
+		case wbComplexContext:
+			for s.pos < len(s.text) {
+				r, size = utf8.DecodeRune(s.text[s.pos:])
+				if wordBreakClass(r) != wbComplexContext {
+					break
+				}
+				s.pos += size
+			}
+			s.end = s.pos
+			return SOUTHEAST_ASIAN
+		case wbALetter, wbHangul, wbNumeric, wbKatakana, wbExtendNumLet:
+			if typ := s.scanWord(); typ >= 0 {
+				return typ
+			}
+		default:
+			// Not numeric, word, ideographic, hiragana, or SE Asian --
+			// ignore it.
+			s.pos += size
+		}
+	}
+	return -1
+}
+
+// Scans the word starting at pos, and returns its type, or -1 if it
+// is made of connector punctuations only.
+func (s *standardTokenizerImpl) scanWord() int {
+	var numLetters, numHangul, numNumerics, numKatakana, numOthers int
+	last := -1
+	for s.pos < len(s.text) {
+		class, next := s.charAt(s.pos)
+		if last >= 0 && !s.joins(last, class, next) {
+			break
+		}
+		switch class {
+		case wbALetter:
+			numLetters++
+		case wbHangul:
+			numHangul++
+		case wbNumeric:
+			numNumerics++
+		case wbKatakana:
+			numKatakana++
+		default:
+			numOthers++
+		}
+		if class != wbMidLetter && class != wbMidNumLet && class != wbMidNum {
+			last = class
+		}
+		s.pos = next
+	}
+	s.end = s.pos
+	switch numWords := numLetters + numHangul + numNumerics + numKatakana; {
+	case numWords == 0:
+		return -1
+	case numNumerics == numWords:
+		return NUM
+	case numOthers > 0:
+		return ALPHANUM
+	case numKatakana == numWords:
+		return KATAKANA
+	case numHangul == numWords:
+		return HANGUL
+	}
+	return ALPHANUM
+}
+
+// Returns true if there is no word boundary between a character of
+// the class last, and the next character of class class.
+func (s *standardTokenizerImpl) joins(last, class, next int) bool {
+	switch {
+	// UAX#29 WB5.   ALetter × ALetter
+	//        WB8.   Numeric × Numeric
+	//        WB9.   ALetter × Numeric
+	//        WB10.  Numeric × ALetter
+	case (isLetter(last) || last == wbNumeric) && (isLetter(class) || class == wbNumeric):
+		return true
+	// UAX#29 WB6.   ALetter × (MidLetter | MidNumLet) ALetter
+	//        WB7.   ALetter (MidLetter | MidNumLet) × ALetter
+	case isLetter(last) && (class == wbMidLetter || class == wbMidNumLet):
+		return isLetter(s.classAt(next))
+	// UAX#29 WB11.  Numeric (MidNum | MidNumLet) × Numeric
+	//        WB12.  Numeric × (MidNum | MidNumLet) Numeric
+	case last == wbNumeric && (class == wbMidNum || class == wbMidNumLet):
+		return s.classAt(next) == wbNumeric
+	// UAX#29 WB13.  Katakana × Katakana
+	case last == wbKatakana && class == wbKatakana:
+		return true
+	// UAX#29 WB13a. (ALetter | Numeric | Katakana | ExtendNumLet) × ExtendNumLet
+	case class == wbExtendNumLet:
+		return true
+	// UAX#29 WB13b. ExtendNumLet × (ALetter | Numeric | Katakana)
+	case last == wbExtendNumLet:
+		return isLetter(class) || class == wbNumeric || class == wbKatakana
+	}
+	return false
+}
@@ -0,0 +1,422 @@
+package analysis
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// Registers the default implementations of the token attributes.
+func init() {
+	util.RegisterAttributeImpl("CharTermAttribute", func() util.AttributeImpl {
+		return new(charTermAttribute)
+	})
+	util.RegisterAttributeImpl("OffsetAttribute", func() util.AttributeImpl {
+		return new(offsetAttribute)
+	})
+	util.RegisterAttributeImpl("PositionIncrementAttribute", func() util.AttributeImpl {
+		return &positionIncrementAttribute{1}
+	})
+	util.RegisterAttributeImpl("PositionLengthAttribute", func() util.AttributeImpl {
+		return &positionLengthAttribute{1}
+	})
+	util.RegisterAttributeImpl("PayloadAttribute", func() util.AttributeImpl {
+		return new(payloadAttribute)
+	})
+	util.RegisterAttributeImpl("TypeAttribute", func() util.AttributeImpl {
+		return &typeAttribute{DEFAULT_TOKEN_TYPE}
+	})
+	util.RegisterAttributeImpl("KeywordAttribute", func() util.AttributeImpl {
+		return new(keywordAttribute)
+	})
+}
+
+// analysis/tokenattributes/CharTermAttribute.java
+
+/*
+The term text of a Token, as its UTF-8 bytes.
+
+The bytes returned by Bytes() may be modified in place, as long as
+the length of the term doesn't change.
+*/
+type CharTermAttribute interface {
+	// Returns the bytes of the term, which are valid until the term is
+	// changed.
+	Bytes() []byte
+	// Copies the given bytes into the term buffer.
+	CopyBytes(b []byte)
+	// Appends the given string to the term.
+	Append(s string)
+	// Appends the given bytes to the term.
+	AppendBytes(b []byte)
+	// Set number of valid bytes (length of the term). It panics if
+	// length is larger than the current length.
+	SetLength(length int)
+	// Sets the length of the term to zero.
+	SetEmpty()
+	// Returns the number of bytes of the term.
+	Length() int
+	// Returns the term as a string.
+	String() string
+}
+
+type charTermAttribute struct {
+	term []byte
+}
+
+func (a *charTermAttribute) Bytes() []byte {
+	return a.term
+}
+
+func (a *charTermAttribute) CopyBytes(b []byte) {
+	a.term = append(a.term[:0], b...)
+}
+
+func (a *charTermAttribute) Append(s string) {
+	a.term = append(a.term, s...)
+}
+
+func (a *charTermAttribute) AppendBytes(b []byte) {
+	a.term = append(a.term, b...)
+}
+
+func (a *charTermAttribute) SetLength(length int) {
+	if length < 0 || length > len(a.term) {
+		panic(fmt.Sprintf("length %v exceeds the size of the termBuffer (%v)", length, len(a.term)))
+	}
+	a.term = a.term[:length]
+}
+
+func (a *charTermAttribute) SetEmpty() {
+	a.term = a.term[:0]
+}
+
+func (a *charTermAttribute) Length() int {
+	return len(a.term)
+}
+
+func (a *charTermAttribute) String() string {
+	return string(a.term)
+}
+
+func (a *charTermAttribute) Interfaces() []string {
+	return []string{"CharTermAttribute"}
+}
+
+func (a *charTermAttribute) Clear() {
+	a.term = a.term[:0]
+}
+
+func (a *charTermAttribute) CopyTo(target util.AttributeImpl) {
+	target.(CharTermAttribute).CopyBytes(a.term)
+}
+
+func (a *charTermAttribute) Clone() util.AttributeImpl {
+	return &charTermAttribute{append([]byte(nil), a.term...)}
+}
+
+// analysis/tokenattributes/OffsetAttribute.java
+
+// The start and end byte offsets of a Token, in the UTF-8 encoding of
+// the text it was extracted from.
+type OffsetAttribute interface {
+	/*
+		Returns this Token's starting offset, the position of the first
+		byte corresponding to this token in the source text.
+
+		Note that the difference between EndOffset() and StartOffset()
+		may not be equal to the length of the term, as the term text may
+		have been altered by a stemmer or some other filter.
+	*/
+	StartOffset() int
+	// Set the starting and ending offset. It panics if startOffset or
+	// endOffset are negative, or if startOffset is greater than
+	// endOffset.
+	SetOffset(startOffset, endOffset int)
+	// Returns this Token's ending offset, one greater than the
+	// position of the last byte corresponding to this token in the
+	// source text.
+	EndOffset() int
+}
+
+type offsetAttribute struct {
+	startOffset, endOffset int
+}
+
+func (a *offsetAttribute) StartOffset() int {
+	return a.startOffset
+}
+
+func (a *offsetAttribute) SetOffset(startOffset, endOffset int) {
+	if startOffset < 0 || endOffset < startOffset {
+		panic(fmt.Sprintf("startOffset must be non-negative, and endOffset must be >= startOffset, startOffset=%v,endOffset=%v",
+			startOffset, endOffset))
+	}
+	a.startOffset, a.endOffset = startOffset, endOffset
+}
+
+func (a *offsetAttribute) EndOffset() int {
+	return a.endOffset
+}
+
+func (a *offsetAttribute) Interfaces() []string {
+	return []string{"OffsetAttribute"}
+}
+
+func (a *offsetAttribute) Clear() {
+	a.startOffset, a.endOffset = 0, 0
+}
+
+func (a *offsetAttribute) CopyTo(target util.AttributeImpl) {
+	target.(OffsetAttribute).SetOffset(a.startOffset, a.endOffset)
+}
+
+func (a *offsetAttribute) Clone() util.AttributeImpl {
+	clone := *a
+	return &clone
+}
+
+// analysis/tokenattributes/PositionIncrementAttribute.java
+
+/*
+Determines the position of this token relative to the previous Token
+in a TokenStream, used in phrase searching.
+
+The default value is one.
+
+Some common uses for this are:
+
+- Set it to zero to put multiple terms in the same position. This is
+useful if, e.g., a word has multiple stems. Searches for phrases
+including either stem will match. In this case, all but the first
+stem's increment should be set to zero: the increment of the first
+instance should be one. Repeating a token with an increment of zero
+can also be used to boost the scores of matches on that token.
+
+- Set it to values greater than one to inhibit exact phrase matches.
+If, for example, one does not want phrases to match across removed
+stop words, then one could build a stop word filter that removes stop
+words and also sets the increment to the number of stop words removed
+before each non-stop word. Then exact phrase queries will only match
+when the terms occur with no intervening stop words.
+*/
+type PositionIncrementAttribute interface {
+	// Set the position increment. The default value is one. It panics
+	// if positionIncrement is negative.
+	SetPositionIncrement(positionIncrement int)
+	// Returns the position increment of this Token.
+	PositionIncrement() int
+}
+
+type positionIncrementAttribute struct {
+	positionIncrement int
+}
+
+func (a *positionIncrementAttribute) SetPositionIncrement(positionIncrement int) {
+	if positionIncrement < 0 {
+		panic(fmt.Sprintf("Increment must be zero or greater: got %v", positionIncrement))
+	}
+	a.positionIncrement = positionIncrement
+}
+
+func (a *positionIncrementAttribute) PositionIncrement() int {
+	return a.positionIncrement
+}
+
+func (a *positionIncrementAttribute) Interfaces() []string {
+	return []string{"PositionIncrementAttribute"}
+}
+
+func (a *positionIncrementAttribute) Clear() {
+	a.positionIncrement = 1
+}
+
+func (a *positionIncrementAttribute) CopyTo(target util.AttributeImpl) {
+	target.(PositionIncrementAttribute).SetPositionIncrement(a.positionIncrement)
+}
+
+func (a *positionIncrementAttribute) Clone() util.AttributeImpl {
+	return &positionIncrementAttribute{a.positionIncrement}
+}
+
+// analysis/tokenattributes/PositionLengthAttribute.java
+
+/*
+Determines how many positions this token spans. Very few analyzer
+components actually produce this attribute, and indexing ignores it,
+but it's useful to express the graph structure naturally produced by
+decompounding, word splitting/joining, synonym filtering, etc.
+
+The default value is one.
+*/
+type PositionLengthAttribute interface {
+	// Set the position length of this Token. The default value is one.
+	// It panics if positionLength is less than one.
+	SetPositionLength(positionLength int)
+	// Returns the position length of this Token.
+	PositionLength() int
+}
+
+type positionLengthAttribute struct {
+	positionLength int
+}
+
+func (a *positionLengthAttribute) SetPositionLength(positionLength int) {
+	if positionLength < 1 {
+		panic(fmt.Sprintf("Position length must be 1 or greater: got %v", positionLength))
+	}
+	a.positionLength = positionLength
+}
+
+func (a *positionLengthAttribute) PositionLength() int {
+	return a.positionLength
+}
+
+func (a *positionLengthAttribute) Interfaces() []string {
+	return []string{"PositionLengthAttribute"}
+}
+
+func (a *positionLengthAttribute) Clear() {
+	a.positionLength = 1
+}
+
+func (a *positionLengthAttribute) CopyTo(target util.AttributeImpl) {
+	target.(PositionLengthAttribute).SetPositionLength(a.positionLength)
+}
+
+func (a *positionLengthAttribute) Clone() util.AttributeImpl {
+	return &positionLengthAttribute{a.positionLength}
+}
+
+// analysis/tokenattributes/PayloadAttribute.java
+
+/*
+The payload of a Token.
+
+The payload is stored in the index at each position, and can be used
+to influence scoring when using payload-based queries.
+*/
+type PayloadAttribute interface {
+	// Returns this Token's payload, or nil if it has none.
+	Payload() []byte
+	// Sets this Token's payload.
+	SetPayload(payload []byte)
+}
+
+type payloadAttribute struct {
+	payload []byte
+}
+
+func (a *payloadAttribute) Payload() []byte {
+	return a.payload
+}
+
+func (a *payloadAttribute) SetPayload(payload []byte) {
+	a.payload = payload
+}
+
+func (a *payloadAttribute) Interfaces() []string {
+	return []string{"PayloadAttribute"}
+}
+
+func (a *payloadAttribute) Clear() {
+	a.payload = nil
+}
+
+func (a *payloadAttribute) CopyTo(target util.AttributeImpl) {
+	var payload []byte
+	if a.payload != nil {
+		payload = append([]byte(nil), a.payload...)
+	}
+	target.(PayloadAttribute).SetPayload(payload)
+}
+
+func (a *payloadAttribute) Clone() util.AttributeImpl {
+	clone := new(payloadAttribute)
+	a.CopyTo(clone)
+	return clone
+}
+
+// analysis/tokenattributes/TypeAttribute.java
+
+// The default type.
+const DEFAULT_TOKEN_TYPE = "word"
+
+// A Token's lexical type. The default value is "word".
+type TypeAttribute interface {
+	// Returns this Token's lexical type. Defaults to "word".
+	Type() string
+	// Set the lexical type.
+	SetType(typ string)
+}
+
+type typeAttribute struct {
+	typ string
+}
+
+func (a *typeAttribute) Type() string {
+	return a.typ
+}
+
+func (a *typeAttribute) SetType(typ string) {
+	a.typ = typ
+}
+
+func (a *typeAttribute) Interfaces() []string {
+	return []string{"TypeAttribute"}
+}
+
+func (a *typeAttribute) Clear() {
+	a.typ = DEFAULT_TOKEN_TYPE
+}
+
+func (a *typeAttribute) CopyTo(target util.AttributeImpl) {
+	target.(TypeAttribute).SetType(a.typ)
+}
+
+func (a *typeAttribute) Clone() util.AttributeImpl {
+	return &typeAttribute{a.typ}
+}
+
+// analysis/tokenattributes/KeywordAttribute.java
+
+/*
+This attribute can be used to mark a token as a keyword. Keyword
+aware TokenStreams can decide to modify a token based on the return
+value of IsKeyword() if the token is modified. Stemming filters for
+instance can use this attribute to conditionally skip a term if
+IsKeyword() returns true.
+*/
+type KeywordAttribute interface {
+	// Returns true if the current token is a keyword, otherwise false.
+	IsKeyword() bool
+	// Marks the current token as keyword if set to true.
+	SetKeyword(isKeyword bool)
+}
+
+type keywordAttribute struct {
+	keyword bool
+}
+
+func (a *keywordAttribute) IsKeyword() bool {
+	return a.keyword
+}
+
+func (a *keywordAttribute) SetKeyword(isKeyword bool) {
+	a.keyword = isKeyword
+}
+
+func (a *keywordAttribute) Interfaces() []string {
+	return []string{"KeywordAttribute"}
+}
+
+func (a *keywordAttribute) Clear() {
+	a.keyword = false
+}
+
+func (a *keywordAttribute) CopyTo(target util.AttributeImpl) {
+	target.(KeywordAttribute).SetKeyword(a.keyword)
+}
+
+func (a *keywordAttribute) Clone() util.AttributeImpl {
+	return &keywordAttribute{a.keyword}
+}
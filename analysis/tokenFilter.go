@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"github.com/balzaczyy/golucene/util"
+)
+
+// analysis/TokenFilter.java
+
+/*
+A TokenFilter is a TokenStream whose input is another TokenStream.
+
+This is an abstract class; implementations embed *TokenFilter and
+implement IncrementToken(), which pulls the next token from Input and
+modifies its attributes in place: a filter shares the AttributeSource
+of its input, so the changes are seen by the consumer of the filter. Implementations that
+keep state between tokens must also override Reset(), and call the
+Reset() of the embedded TokenFilter.
+*/
+type TokenFilter struct {
+	// The source of tokens for this filter.
+	Input TokenStream
+}
+
+// Construct a token stream filtering the given input.
+func NewTokenFilter(input TokenStream) *TokenFilter {
+	return &TokenFilter{input}
+}
+
+// Returns the attributes of the input, which the filter shares.
+func (f *TokenFilter) Attributes() *util.AttributeSource {
+	return f.Input.Attributes()
+}
+
+/*
+This method is called by the consumer after the last token has been
+consumed, after IncrementToken() returned false. It calls End() on
+the input. If you override this method, always call the End() of the
+embedded TokenFilter.
+*/
+func (f *TokenFilter) End() error {
+	return f.Input.End()
+}
+
+// Resets the input. If you override this method, always call the
+// Reset() of the embedded TokenFilter.
+func (f *TokenFilter) Reset() error {
+	return f.Input.Reset()
+}
+
+// Closes the input. If you override this method, always call the
+// Close() of the embedded TokenFilter.
+func (f *TokenFilter) Close() error {
+	return f.Input.Close()
+}
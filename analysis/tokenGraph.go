@@ -0,0 +1,201 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+)
+
+// util/graph/GraphTokenStreamFiniteStrings.java
+
+// A token of a TokenGraph, with the positions it spans.
+type GraphToken struct {
+	Term []byte
+	Type string
+	// The position the token leaves from.
+	Position int
+	// The number of positions the token spans.
+	PositionLength int
+	StartOffset    int
+	EndOffset      int
+}
+
+/*
+TokenGraph holds the tokens of a TokenStream as a graph, whose nodes
+are positions and whose edges are tokens, each going from its
+position to its position plus its PositionLengthAttribute.
+
+Filters such as synonym or word-delimiter filters produce such
+graphs: tokens stacked on the same position (position increment 0)
+are alternatives, and a token spanning several positions is an
+alternative to the tokens along those positions. E.g. "wi fi
+network" with the synonym "wifi" yields the tokens wi (0-1), wifi
+(0-2), fi (1-2) and network (2-3), whose paths are "wi fi network"
+and "wifi network".
+
+The index only records the position of each token, not its position
+length, so an indexed graph is flattened: there, wifi is followed by
+fi, and "wifi network" only matches as a phrase with a slop. Consumers
+building phrases from a graph, e.g. of a query, should rather take all
+its paths, as FiniteStrings() returns them.
+
+Positions skipped by a position increment greater than one, e.g.
+where StopFilter removed a token, are holes: no token leaves from
+them, and paths go over them, to the next position.
+*/
+type TokenGraph struct {
+	tokens []*GraphToken
+	// the tokens leaving from each position
+	leaving [][]*GraphToken
+	// the last position of the graph
+	end int
+}
+
+/*
+Builds the graph of the tokens of ts. The stream is reset, consumed
+and ended, but not closed, which the caller should do.
+*/
+func NewTokenGraph(ts TokenStream) (*TokenGraph, error) {
+	atts := ts.Attributes()
+	termAtt := atts.Add("CharTermAttribute").(CharTermAttribute)
+	typeAtt := atts.Add("TypeAttribute").(TypeAttribute)
+	posIncAtt := atts.Add("PositionIncrementAttribute").(PositionIncrementAttribute)
+	posLengthAtt := atts.Add("PositionLengthAttribute").(PositionLengthAttribute)
+	offsetAtt := atts.Add("OffsetAttribute").(OffsetAttribute)
+
+	if err := ts.Reset(); err != nil {
+		return nil, err
+	}
+	g := new(TokenGraph)
+	pos := -1
+	for {
+		ok, err := ts.IncrementToken()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		posInc := posIncAtt.PositionIncrement()
+		if pos < 0 && posInc == 0 {
+			return nil, errors.New(fmt.Sprintf(
+				"first position increment must be > 0 (got 0) for token %v", termAtt))
+		}
+		pos += posInc
+		token := &GraphToken{
+			Term:           append([]byte(nil), termAtt.Bytes()...),
+			Type:           typeAtt.Type(),
+			Position:       pos,
+			PositionLength: posLengthAtt.PositionLength(),
+			StartOffset:    offsetAtt.StartOffset(),
+			EndOffset:      offsetAtt.EndOffset(),
+		}
+		g.tokens = append(g.tokens, token)
+		for len(g.leaving) <= pos {
+			g.leaving = append(g.leaving, nil)
+		}
+		g.leaving[pos] = append(g.leaving[pos], token)
+		if endPos := pos + token.PositionLength; endPos > g.end {
+			g.end = endPos
+		}
+	}
+	if err := ts.End(); err != nil {
+		return nil, err
+	}
+	for len(g.leaving) <= g.end {
+		g.leaving = append(g.leaving, nil)
+	}
+	return g, nil
+}
+
+// Returns all the tokens of the graph, in the order of the stream.
+func (g *TokenGraph) Tokens() []*GraphToken {
+	return g.tokens
+}
+
+// Returns the tokens leaving from the given position.
+func (g *TokenGraph) TokensAt(pos int) []*GraphToken {
+	if pos < 0 || pos >= len(g.leaving) {
+		return nil
+	}
+	return g.leaving[pos]
+}
+
+// Returns the last position of the graph, where all paths end. It is
+// 0 for an empty graph.
+func (g *TokenGraph) EndPosition() int {
+	return g.end
+}
+
+// Returns true if a token leaving from the given position spans more
+// than one position, i.e. the graph has a side path there.
+func (g *TokenGraph) HasSidePath(pos int) bool {
+	for _, token := range g.TokensAt(pos) {
+		if token.PositionLength > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Returns the articulation points of the graph, in increasing order:
+the positions, other than the first and the last, which no token
+spans over, so every path goes through them. The graph between two
+consecutive articulation points can be handled independently, e.g. as
+a phrase of the alternatives at each position if it has no side path.
+*/
+func (g *TokenGraph) ArticulationPoints() []int {
+	var ans []int
+	// the furthest position reached by the tokens leaving before pos
+	reach := 0
+	for pos := 0; pos < g.end; pos++ {
+		if pos > 0 && reach <= pos {
+			ans = append(ans, pos)
+		}
+		for _, token := range g.leaving[pos] {
+			if endPos := pos + token.PositionLength; endPos > reach {
+				reach = endPos
+			}
+		}
+	}
+	return ans
+}
+
+// Returns all the paths through the graph, from its first to its last
+// position, each as its sequence of tokens.
+func (g *TokenGraph) FiniteStrings() [][]*GraphToken {
+	return g.FiniteStringsBetween(0, g.end)
+}
+
+/*
+Returns all the paths through the graph, from the start position to
+the end position, each as its sequence of tokens. Tokens spanning
+over the end position are left out, and holes are skipped over.
+*/
+func (g *TokenGraph) FiniteStringsBetween(start, end int) [][]*GraphToken {
+	var ans [][]*GraphToken
+	var path []*GraphToken
+	var walk func(pos int)
+	walk = func(pos int) {
+		if pos >= end {
+			ans = append(ans, append([]*GraphToken(nil), path...))
+			return
+		}
+		if len(g.leaving[pos]) == 0 { // a hole
+			walk(pos + 1)
+			return
+		}
+		for _, token := range g.leaving[pos] {
+			if pos+token.PositionLength > end {
+				continue
+			}
+			path = append(path, token)
+			walk(pos + token.PositionLength)
+			path = path[:len(path)-1]
+		}
+	}
+	if start >= 0 && start < end && end <= g.end {
+		walk(start)
+	}
+	return ans
+}
@@ -0,0 +1,119 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// A token stream of canned tokens: term, posInc, posLength.
+type testGraphStream struct {
+	*TokenStreamImpl
+	tokens       [][3]interface{}
+	upto, offset int
+	termAtt      CharTermAttribute
+	posIncAtt    PositionIncrementAttribute
+	posLengthAtt PositionLengthAttribute
+	offsetAtt    OffsetAttribute
+}
+
+func newTestGraphStream(tokens ...[3]interface{}) *testGraphStream {
+	ans := &testGraphStream{TokenStreamImpl: NewTokenStream(), tokens: tokens}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	ans.posIncAtt = ans.Attributes().Add("PositionIncrementAttribute").(PositionIncrementAttribute)
+	ans.posLengthAtt = ans.Attributes().Add("PositionLengthAttribute").(PositionLengthAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(OffsetAttribute)
+	return ans
+}
+
+func (ts *testGraphStream) IncrementToken() (bool, error) {
+	if ts.upto >= len(ts.tokens) {
+		return false, nil
+	}
+	token := ts.tokens[ts.upto]
+	ts.upto++
+	ts.Attributes().Clear()
+	term := token[0].(string)
+	ts.termAtt.Append(term)
+	ts.posIncAtt.SetPositionIncrement(token[1].(int))
+	ts.posLengthAtt.SetPositionLength(token[2].(int))
+	ts.offsetAtt.SetOffset(ts.offset, ts.offset+len(term))
+	ts.offset += len(term) + 1
+	return true, nil
+}
+
+func pathsOf(paths [][]*GraphToken) string {
+	var ans []string
+	for _, path := range paths {
+		var terms []string
+		for _, token := range path {
+			terms = append(terms, string(token.Term))
+		}
+		ans = append(ans, strings.Join(terms, " "))
+	}
+	return strings.Join(ans, "|")
+}
+
+func TestTokenGraph(t *testing.T) {
+	// "fast wi fi network", with "wifi" and "hotspot" for "wi fi"
+	g, err := NewTokenGraph(newTestGraphStream(
+		[3]interface{}{"fast", 1, 1},
+		[3]interface{}{"wi", 1, 1},
+		[3]interface{}{"wifi", 0, 2},
+		[3]interface{}{"hotspot", 0, 2},
+		[3]interface{}{"fi", 1, 1},
+		[3]interface{}{"network", 1, 1},
+		[3]interface{}{"net", 0, 1},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 7, len(g.Tokens()))
+	assertEquals(t, 4, g.EndPosition())
+	assertEquals(t, 3, len(g.TokensAt(1)))
+	assertEquals(t, false, g.HasSidePath(0))
+	assertEquals(t, true, g.HasSidePath(1))
+	assertEquals(t, "[1 3]", fmt.Sprint(g.ArticulationPoints()))
+	assertEquals(t, "fast wi fi network|fast wi fi net|fast wifi network|fast wifi net|"+
+		"fast hotspot network|fast hotspot net", pathsOf(g.FiniteStrings()))
+	assertEquals(t, "wi fi|wifi|hotspot", pathsOf(g.FiniteStringsBetween(1, 3)))
+	// tokens spanning over the end are left out
+	assertEquals(t, "wi", pathsOf(g.FiniteStringsBetween(1, 2)))
+
+	token := g.TokensAt(1)[1]
+	assertEquals(t, "wifi", string(token.Term))
+	assertEquals(t, 1, token.Position)
+	assertEquals(t, 2, token.PositionLength)
+	assertEquals(t, 8, token.StartOffset)
+	assertEquals(t, 12, token.EndOffset)
+	assertEquals(t, DEFAULT_TOKEN_TYPE, token.Type)
+}
+
+func TestTokenGraphHoles(t *testing.T) {
+	// "the quick fox", with the stop words removed
+	g, err := NewTokenGraph(newTestGraphStream(
+		[3]interface{}{"quick", 2, 1},
+		[3]interface{}{"fox", 2, 1},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 4, g.EndPosition())
+	assertEquals(t, "[1 2 3]", fmt.Sprint(g.ArticulationPoints()))
+	assertEquals(t, "quick fox", pathsOf(g.FiniteStrings()))
+	paths := g.FiniteStrings()
+	assertEquals(t, 1, paths[0][0].Position)
+	assertEquals(t, 3, paths[0][1].Position)
+
+	if _, err = NewTokenGraph(newTestGraphStream([3]interface{}{"a", 0, 1})); err == nil {
+		t.Error("first position increment of 0 should fail")
+	}
+
+	g, err = NewTokenGraph(newTestGraphStream())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, g.EndPosition())
+	assertEquals(t, 0, len(g.FiniteStrings()))
+	assertEquals(t, 0, len(g.ArticulationPoints()))
+}
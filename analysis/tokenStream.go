@@ -0,0 +1,101 @@
+package analysis
+
+import (
+	"github.com/balzaczyy/golucene/util"
+)
+
+// analysis/TokenStream.java
+
+/*
+A TokenStream enumerates the sequence of tokens, either from fields
+of a document or from query text.
+
+The workflow of a consumer is:
+
+1. Get the attributes it needs from the stream's AttributeSource,
+with Attributes().Add(), e.g. Add("CharTermAttribute");
+2. Reset() the stream, which must be called before the first token;
+3. call IncrementToken() until it returns false, reading the
+attributes after each call;
+4. End() the stream, so it can record its end-of-stream state, such
+as the final offset, in its attributes;
+5. Close() the stream to release its resources.
+
+A Tokenizer is a TokenStream whose input is text, and a TokenFilter
+is a TokenStream whose input is another TokenStream. An Analyzer
+builds the chain of a Tokenizer and its TokenFilters for a field. All
+the streams of a chain share the same AttributeSource, so a filter
+sees, and may modify, the attributes of the current token set by its
+input, and filters can cooperate by adding attributes of their own.
+
+The attributes must be added when the stream is created, not in
+IncrementToken(). A stream which buffers tokens can use
+CaptureState() and RestoreState() of the AttributeSource.
+*/
+type TokenStream interface {
+	// Returns the AttributeSource holding the attributes of the current
+	// token, shared with the other streams of the chain.
+	Attributes() *util.AttributeSource
+	// Resets this stream to a clean state. Stateful implementations
+	// must implement this method so that they can be reused, just as
+	// if they had been created fresh.
+	Reset() error
+	/*
+		Advances the stream to the next token. Returns false for end of
+		stream.
+
+		Implementing streams must call Clear() on the AttributeSource
+		before setting the attributes of a new token, if they don't set
+		all of them.
+	*/
+	IncrementToken() (bool, error)
+	/*
+		This method is called by the consumer after the last token has
+		been consumed, after IncrementToken() returned false. It can be
+		used to perform any end-of-stream operations, such as setting
+		the final offset of the stream in its OffsetAttribute. The final
+		offset may be different from the end offset of the last token,
+		e.g. if the text ends with whitespace.
+	*/
+	End() error
+	// Releases resources associated with this stream.
+	Close() error
+}
+
+// Embeddable implementation of TokenStream, which holds its
+// AttributeSource.
+type TokenStreamImpl struct {
+	attributes *util.AttributeSource
+}
+
+// A TokenStream using the default attribute factory.
+func NewTokenStream() *TokenStreamImpl {
+	return NewTokenStreamWith(util.DEFAULT_ATTRIBUTE_FACTORY)
+}
+
+// A TokenStream using the supplied AttributeFactory for creating new
+// Attribute instances.
+func NewTokenStreamWith(factory util.AttributeFactory) *TokenStreamImpl {
+	return NewTokenStreamFrom(util.NewAttributeSourceWith(factory))
+}
+
+// A TokenStream that uses the same attributes as the supplied one.
+func NewTokenStreamFrom(input *util.AttributeSource) *TokenStreamImpl {
+	return &TokenStreamImpl{input}
+}
+
+func (ts *TokenStreamImpl) Attributes() *util.AttributeSource {
+	return ts.attributes
+}
+
+func (ts *TokenStreamImpl) Reset() error {
+	return nil
+}
+
+func (ts *TokenStreamImpl) End() error {
+	return nil
+}
+
+func (ts *TokenStreamImpl) Close() error {
+	return nil
+}
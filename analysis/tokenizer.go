@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"errors"
+	"github.com/balzaczyy/golucene/util"
+	"io"
+)
+
+// analysis/Tokenizer.java
+
+/*
+A Tokenizer is a TokenStream whose input is a Reader.
+
+This is an abstract class; implementations embed *TokenizerImpl, add
+the attributes they set when created, and implement IncrementToken(),
+and End() to set the final offset. Implementations that keep state
+between tokens must also override Reset(), which is called before the
+first token of each new input.
+*/
+type Tokenizer interface {
+	TokenStream
+	/*
+		Expert: Set a new reader on the Tokenizer. Typically, an
+		analyzer (in its TokenStream method) will use this to re-use a
+		previously created tokenizer.
+	*/
+	SetReader(input io.Reader) error
+}
+
+// Embeddable implementation of Tokenizer, which keeps its input.
+type TokenizerImpl struct {
+	*TokenStreamImpl
+	// The text source for this Tokenizer.
+	Input io.Reader
+}
+
+// Construct a token stream processing the given input. It panics if
+// input is nil.
+func NewTokenizer(input io.Reader) *TokenizerImpl {
+	return NewTokenizerWith(util.DEFAULT_ATTRIBUTE_FACTORY, input)
+}
+
+// Construct a token stream processing the given input using the given
+// AttributeFactory. It panics if input is nil.
+func NewTokenizerWith(factory util.AttributeFactory, input io.Reader) *TokenizerImpl {
+	if input == nil {
+		panic("input must not be null")
+	}
+	return &TokenizerImpl{NewTokenStreamWith(factory), input}
+}
+
+func (t *TokenizerImpl) SetReader(input io.Reader) error {
+	if input == nil {
+		return errors.New("input must not be null")
+	}
+	t.Input = input
+	return nil
+}
+
+// Closes the input if it is an io.Closer. Implementations must call
+// this if they override Close().
+func (t *TokenizerImpl) Close() error {
+	if t.Input == nil {
+		return nil
+	}
+	defer func() { t.Input = nil }()
+	if closer, ok := t.Input.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
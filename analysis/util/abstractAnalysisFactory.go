@@ -0,0 +1,185 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// analysis/util/AbstractAnalysisFactory.java
+
+/*
+Abstract parent class for analysis factories TokenizerFactory and
+TokenFilterFactory.
+
+The typical lifecycle for a factory consumer is:
+
+ 1. Create factory via its constructor (or via XXXFactoryForName)
+ 2. (Optional) If the factory uses resources such as files, they are
+    loaded by the constructor.
+ 3. Consumer calls Create() to obtain instances.
+
+A factory consumes its arguments with the getters below, which record
+the first invalid argument; Err() then also reports the arguments
+which were not consumed, as the factory does not know them.
+*/
+type AbstractAnalysisFactory struct {
+	originalArgs map[string]string
+	args         map[string]string
+	err          error
+}
+
+// Initialize this factory via a set of key-value pairs.
+func NewAbstractAnalysisFactory(args map[string]string) *AbstractAnalysisFactory {
+	ans := &AbstractAnalysisFactory{
+		originalArgs: make(map[string]string, len(args)),
+		args:         make(map[string]string, len(args)),
+	}
+	for k, v := range args {
+		ans.originalArgs[k] = v
+		ans.args[k] = v
+	}
+	return ans
+}
+
+// Returns the arguments the factory was created with.
+func (f *AbstractAnalysisFactory) OriginalArgs() map[string]string {
+	return f.originalArgs
+}
+
+// Consumes the named argument, and reports whether it was given.
+func (f *AbstractAnalysisFactory) consume(name string) (string, bool) {
+	s, ok := f.args[name]
+	delete(f.args, name)
+	return s, ok
+}
+
+// Records the first error of the arguments.
+func (f *AbstractAnalysisFactory) fail(err error) {
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+// Returns the named argument, which must be given.
+func (f *AbstractAnalysisFactory) Require(name string) string {
+	s, ok := f.consume(name)
+	if !ok {
+		f.fail(errors.New(fmt.Sprintf("Configuration Error: missing parameter '%v'", name)))
+	}
+	return s
+}
+
+// Returns the named argument, or defaultVal if it is not given.
+func (f *AbstractAnalysisFactory) Get(name, defaultVal string) string {
+	if s, ok := f.consume(name); ok {
+		return s
+	}
+	return defaultVal
+}
+
+// Returns the named integer argument, or defaultVal if it is not
+// given.
+func (f *AbstractAnalysisFactory) GetInt(name string, defaultVal int) int {
+	s, ok := f.consume(name)
+	if !ok {
+		return defaultVal
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		f.fail(errors.New(fmt.Sprintf("Configuration Error: '%v' is not an integer: %v", name, s)))
+	}
+	return n
+}
+
+// Returns the named boolean argument, or defaultVal if it is not
+// given.
+func (f *AbstractAnalysisFactory) GetBoolean(name string, defaultVal bool) bool {
+	s, ok := f.consume(name)
+	if !ok {
+		return defaultVal
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		f.fail(errors.New(fmt.Sprintf("Configuration Error: '%v' is not a boolean: %v", name, s)))
+	}
+	return b
+}
+
+var itemPattern = regexp.MustCompile(`[,\s]+`)
+
+// Returns the items of the named argument, separated by commas or
+// whitespace, or nil if it is not given.
+func (f *AbstractAnalysisFactory) GetSet(name string) []string {
+	s, ok := f.consume(name)
+	if !ok {
+		return nil
+	}
+	var ans []string
+	for _, item := range itemPattern.Split(s, -1) {
+		if item != "" {
+			ans = append(ans, item)
+		}
+	}
+	return ans
+}
+
+/*
+Returns the first error of the consumed arguments, or else an error
+listing the unknown arguments which were not consumed, or nil. It is
+called by the constructor of the factory once all its arguments are
+consumed.
+*/
+func (f *AbstractAnalysisFactory) Err() error {
+	if f.err != nil {
+		return f.err
+	}
+	if len(f.args) > 0 {
+		names := make([]string, 0, len(f.args))
+		for name := range f.args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return errors.New(fmt.Sprintf("Unknown parameters: %v", strings.Join(names, ", ")))
+	}
+	return nil
+}
+
+/*
+Returns a CharArraySet of the words of the given files, one word per
+line, as GetWordSet() reads them. If format is "snowball", the files
+are read as GetSnowballWordSet() does.
+*/
+func (f *AbstractAnalysisFactory) LoadWordSet(files []string, format string, ignoreCase bool) (*CharArraySet, error) {
+	read := GetWordSet
+	switch format {
+	case "", "wordset":
+	case "snowball":
+		read = GetSnowballWordSet
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown 'format' specified for word set: %v", format))
+	}
+	words := NewCharArraySet(16, ignoreCase)
+	for _, file := range files {
+		if err := loadWords(file, read, words); err != nil {
+			return nil, err
+		}
+	}
+	return words, nil
+}
+
+// Adds the words of the file at path to words, read by read.
+func loadWords(path string, read func(io.Reader, *CharArraySet) (*CharArraySet, error), words *CharArraySet) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = read(f, words)
+	return err
+}
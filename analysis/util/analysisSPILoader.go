@@ -0,0 +1,118 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis"
+	"io"
+	"sort"
+	"strings"
+)
+
+// analysis/util/TokenizerFactory.java
+
+// Abstract parent class for analysis factories that create Tokenizer
+// instances.
+type TokenizerFactory interface {
+	// Creates a Tokenizer of the specified input.
+	Create(input io.Reader) analysis.Tokenizer
+}
+
+// analysis/util/TokenFilterFactory.java
+
+// Abstract parent class for analysis factories that create
+// TokenFilter instances.
+type TokenFilterFactory interface {
+	// Transform the specified input TokenStream.
+	Create(input analysis.TokenStream) analysis.TokenStream
+}
+
+// analysis/util/AnalysisSPILoader.java
+
+// Creators of the factories, registered by lower case names.
+var (
+	tokenizerFactories   = make(map[string]func(args map[string]string) (TokenizerFactory, error))
+	tokenFilterFactories = make(map[string]func(args map[string]string) (TokenFilterFactory, error))
+)
+
+/*
+Registers the function creating the named TokenizerFactory from its
+arguments. Names are case insensitive. It is intended to be called
+from the init() function of the package defining the Tokenizer, and
+panics if the name is already registered.
+*/
+func RegisterTokenizerFactory(name string, creator func(args map[string]string) (TokenizerFactory, error)) {
+	name = strings.ToLower(name)
+	if _, ok := tokenizerFactories[name]; ok {
+		panic(fmt.Sprintf("TokenizerFactory %v is already registered", name))
+	}
+	tokenizerFactories[name] = creator
+}
+
+/*
+Registers the function creating the named TokenFilterFactory from its
+arguments. Names are case insensitive. It is intended to be called
+from the init() function of the package defining the TokenFilter, and
+panics if the name is already registered.
+*/
+func RegisterTokenFilterFactory(name string, creator func(args map[string]string) (TokenFilterFactory, error)) {
+	name = strings.ToLower(name)
+	if _, ok := tokenFilterFactories[name]; ok {
+		panic(fmt.Sprintf("TokenFilterFactory %v is already registered", name))
+	}
+	tokenFilterFactories[name] = creator
+}
+
+// Looks up a TokenizerFactory by name, and creates it with the given
+// arguments.
+func TokenizerFactoryForName(name string, args map[string]string) (TokenizerFactory, error) {
+	creator, ok := tokenizerFactories[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(
+			"A TokenizerFactory with name '%v' does not exist. You need to import the package "+
+				"registering it. The current registry supports the following names: %v",
+			name, AvailableTokenizers()))
+	}
+	factory, err := creator(args)
+	if err != nil {
+		return nil, err
+	}
+	return factory, nil
+}
+
+// Looks up a TokenFilterFactory by name, and creates it with the given
+// arguments.
+func TokenFilterFactoryForName(name string, args map[string]string) (TokenFilterFactory, error) {
+	creator, ok := tokenFilterFactories[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(
+			"A TokenFilterFactory with name '%v' does not exist. You need to import the package "+
+				"registering it. The current registry supports the following names: %v",
+			name, AvailableTokenFilters()))
+	}
+	factory, err := creator(args)
+	if err != nil {
+		return nil, err
+	}
+	return factory, nil
+}
+
+// Returns the sorted names of the registered TokenizerFactories.
+func AvailableTokenizers() []string {
+	ans := make([]string, 0, len(tokenizerFactories))
+	for name := range tokenizerFactories {
+		ans = append(ans, name)
+	}
+	sort.Strings(ans)
+	return ans
+}
+
+// Returns the sorted names of the registered TokenFilterFactories.
+func AvailableTokenFilters() []string {
+	ans := make([]string, 0, len(tokenFilterFactories))
+	for name := range tokenFilterFactories {
+		ans = append(ans, name)
+	}
+	sort.Strings(ans)
+	return ans
+}
@@ -0,0 +1,111 @@
+package util
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// analysis/util/CharArraySet.java
+
+/*
+A simple set of words, which supports looking up the term bytes of a
+token, as returned by CharTermAttribute.Bytes(), without converting
+them to a string first. It is designed to be quick to test if a term
+is in the set, e.g. a stop word.
+
+If ignoreCase is true for this set, the words are lower cased on
+insertion, and the looked up terms are compared in lower case, by
+the Unicode lower case mapping of each character, without allocating
+for short terms.
+*/
+type CharArraySet struct {
+	words      map[string]bool
+	ignoreCase bool
+}
+
+// An empty set. It is shared, and must not be modified.
+var EMPTY_SET = NewCharArraySet(0, false)
+
+// Create a set with enough capacity to hold startSize terms.
+func NewCharArraySet(startSize int, ignoreCase bool) *CharArraySet {
+	return &CharArraySet{make(map[string]bool, startSize), ignoreCase}
+}
+
+// Creates a set from a list of words.
+func NewCharArraySetFrom(words []string, ignoreCase bool) *CharArraySet {
+	ans := NewCharArraySet(len(words), ignoreCase)
+	for _, word := range words {
+		ans.Add(word)
+	}
+	return ans
+}
+
+// Returns true if the set compares its words case insensitively.
+func (set *CharArraySet) IgnoreCase() bool {
+	return set.ignoreCase
+}
+
+// Adds a word into the set.
+func (set *CharArraySet) Add(word string) {
+	set.AddBytes([]byte(word))
+}
+
+// Adds the bytes of a term into the set.
+func (set *CharArraySet) AddBytes(term []byte) {
+	if set.ignoreCase {
+		term = appendLower(nil, term)
+	}
+	set.words[string(term)] = true
+}
+
+// True if the term bytes are in the set.
+func (set *CharArraySet) Contains(term []byte) bool {
+	if set.ignoreCase {
+		var buf [64]byte
+		return set.words[string(appendLower(buf[:0], term))]
+	}
+	return set.words[string(term)]
+}
+
+// True if the word is in the set.
+func (set *CharArraySet) ContainsString(word string) bool {
+	if set.ignoreCase {
+		return set.Contains([]byte(word))
+	}
+	return set.words[word]
+}
+
+// Returns the number of words in the set.
+func (set *CharArraySet) Len() int {
+	return len(set.words)
+}
+
+// Returns the words of the set, lower cased if the set ignores case,
+// in no particular order.
+func (set *CharArraySet) Words() []string {
+	ans := make([]string, 0, len(set.words))
+	for word := range set.words {
+		ans = append(ans, word)
+	}
+	return ans
+}
+
+// Appends the lower case of term to buf.
+func appendLower(buf, term []byte) []byte {
+	for len(term) > 0 {
+		if b := term[0]; b < utf8.RuneSelf {
+			if 'A' <= b && b <= 'Z' {
+				b += 'a' - 'A'
+			}
+			buf = append(buf, b)
+			term = term[1:]
+			continue
+		}
+		c, size := utf8.DecodeRune(term)
+		var enc [utf8.UTFMax]byte
+		n := utf8.EncodeRune(enc[:], unicode.ToLower(c))
+		buf = append(buf, enc[:n]...)
+		term = term[size:]
+	}
+	return buf
+}
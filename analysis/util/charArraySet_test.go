@@ -0,0 +1,43 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestCharArraySet(t *testing.T) {
+	set := NewCharArraySetFrom([]string{"Time", "école"}, false)
+	if !set.ContainsString("Time") || set.ContainsString("time") || set.Contains([]byte("TIME")) {
+		t.Error("case sensitive set should only contain the exact word")
+	}
+	if !set.Contains([]byte("école")) || set.Contains([]byte("ÉCOLE")) {
+		t.Error("case sensitive set should only contain the exact word")
+	}
+	if set.Len() != 2 || set.IgnoreCase() {
+		t.Errorf("unexpected set: %v", set.Words())
+	}
+}
+
+func TestCharArraySetIgnoreCase(t *testing.T) {
+	set := NewCharArraySet(4, true)
+	set.Add("Time")
+	set.AddBytes([]byte("ÉCOLE"))
+	set.Add("time")
+	if set.Len() != 2 {
+		t.Errorf("words should be lower cased on insertion: %v", set.Words())
+	}
+	for _, word := range []string{"time", "TIME", "tIMe", "école", "ÉCOLE", "ÉcOlE"} {
+		if !set.ContainsString(word) || !set.Contains([]byte(word)) {
+			t.Errorf("%v should be in the set", word)
+		}
+	}
+	if set.ContainsString("times") || set.Contains([]byte("ecole")) {
+		t.Error("only the added words should be in the set")
+	}
+
+	// long terms are lower cased beyond the stack buffer
+	long := "ABCDEFGHIJKLMNOPQRSTUVWXYZABCDEFGHIJKLMNOPQRSTUVWXYZABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	set.Add(long)
+	if !set.Contains([]byte(long)) || !set.ContainsString("abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+		t.Error("long word should be in the set")
+	}
+}
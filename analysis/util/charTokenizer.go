@@ -0,0 +1,114 @@
+package util
+
+import (
+	"bufio"
+	"github.com/balzaczyy/golucene/analysis"
+	"io"
+	"unicode/utf8"
+)
+
+// analysis/util/CharTokenizer.java
+
+// Hooks of a CharTokenizer, implemented by the concrete type which
+// embeds CharTokenizerImpl.
+type CharTokenizer interface {
+	analysis.Tokenizer
+	/*
+		Returns true iff a character should be included in a token. This
+		tokenizer generates as tokens adjacent sequences of characters
+		which satisfy this predicate. Characters for which this is false
+		are used to define token boundaries and are not included in
+		tokens.
+	*/
+	IsTokenChar(c rune) bool
+	/*
+		Called on each token character to normalize it before it is
+		added to the token. The default implementation does nothing.
+		Subclasses may use this to, e.g., lowercase tokens.
+	*/
+	Normalize(c rune) rune
+}
+
+// Maximum length of a token, in characters. Longer tokens are split.
+const MAX_WORD_LEN = 255
+
+/*
+An abstract base class for simple, character-oriented tokenizers.
+
+A CharTokenizer emits the maximal runs of characters for which
+IsTokenChar() returns true, normalized by Normalize(). Tokens longer
+than MAX_WORD_LEN characters are split.
+*/
+type CharTokenizerImpl struct {
+	*analysis.TokenizerImpl
+	self        CharTokenizer
+	reader      *bufio.Reader
+	offset      int
+	finalOffset int
+	termAtt     analysis.CharTermAttribute
+	offsetAtt   analysis.OffsetAttribute
+}
+
+// Creates a new CharTokenizer, whose IsTokenChar() and Normalize()
+// are implemented by self.
+func NewCharTokenizer(self CharTokenizer, input io.Reader) *CharTokenizerImpl {
+	ans := &CharTokenizerImpl{TokenizerImpl: analysis.NewTokenizer(input), self: self}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(analysis.OffsetAttribute)
+	return ans
+}
+
+func (t *CharTokenizerImpl) Normalize(c rune) rune {
+	return c
+}
+
+func (t *CharTokenizerImpl) IncrementToken() (bool, error) {
+	t.Attributes().Clear()
+	length, start, end := 0, -1, -1
+	var buf [utf8.UTFMax]byte
+	for {
+		c, size, err := t.reader.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return false, err
+		}
+		t.offset += size
+		if t.self.IsTokenChar(c) {
+			if length == 0 { // start of token
+				start = t.offset - size
+			}
+			n := utf8.EncodeRune(buf[:], t.self.Normalize(c))
+			t.termAtt.AppendBytes(buf[:n])
+			length++
+			end = t.offset
+			if length >= MAX_WORD_LEN { // buffer overflow!
+				break
+			}
+		} else if length > 0 { // at non-Letter w/ chars
+			break // return 'em
+		}
+	}
+	t.finalOffset = t.offset
+	if length == 0 {
+		return false, nil
+	}
+	t.offsetAtt.SetOffset(start, end)
+	return true, nil
+}
+
+func (t *CharTokenizerImpl) End() error {
+	// set final offset
+	t.offsetAtt.SetOffset(t.finalOffset, t.finalOffset)
+	return nil
+}
+
+func (t *CharTokenizerImpl) Reset() error {
+	if t.reader == nil {
+		t.reader = bufio.NewReader(t.Input)
+	} else {
+		t.reader.Reset(t.Input)
+	}
+	t.offset, t.finalOffset = 0, 0
+	return nil
+}
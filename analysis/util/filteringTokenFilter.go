@@ -0,0 +1,111 @@
+package util
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+)
+
+// analysis/util/FilteringTokenFilter.java
+
+// Hooks of a FilteringTokenFilter, implemented by the concrete type
+// which embeds FilteringTokenFilterImpl.
+type FilteringTokenFilter interface {
+	analysis.TokenStream
+	// Override this method and return if the current input token
+	// should be returned by IncrementToken().
+	Accept() (bool, error)
+}
+
+/*
+Abstract base class for TokenFilters that may remove tokens. You have
+to implement Accept() and return a boolean if the current token
+should be preserved. IncrementToken() uses this method to decide if a
+token should be passed to the caller.
+
+As of Lucene 2.9, position increments are enabled by default: the
+position increments of the removed tokens are added to the next
+accepted token, so the holes left by them are preserved.
+*/
+type FilteringTokenFilterImpl struct {
+	*analysis.TokenFilter
+	self                     FilteringTokenFilter
+	posIncrAtt               analysis.PositionIncrementAttribute
+	enablePositionIncrements bool
+	first                    bool // only used when not preserving gaps
+}
+
+// Create a new FilteringTokenFilter, whose Accept() is implemented by
+// self.
+func NewFilteringTokenFilter(self FilteringTokenFilter, enablePositionIncrements bool,
+	input analysis.TokenStream) *FilteringTokenFilterImpl {
+	ans := &FilteringTokenFilterImpl{
+		TokenFilter:              analysis.NewTokenFilter(input),
+		self:                     self,
+		enablePositionIncrements: enablePositionIncrements,
+		first:                    true,
+	}
+	ans.posIncrAtt = ans.Attributes().Add("PositionIncrementAttribute").(analysis.PositionIncrementAttribute)
+	return ans
+}
+
+func (f *FilteringTokenFilterImpl) IncrementToken() (bool, error) {
+	if f.enablePositionIncrements {
+		skippedPositions := 0
+		for {
+			ok, err := f.Input.IncrementToken()
+			if err != nil || !ok {
+				return false, err
+			}
+			if ok, err = f.self.Accept(); err != nil {
+				return false, err
+			} else if ok {
+				if skippedPositions != 0 {
+					f.posIncrAtt.SetPositionIncrement(f.posIncrAtt.PositionIncrement() + skippedPositions)
+				}
+				return true, nil
+			}
+			skippedPositions += f.posIncrAtt.PositionIncrement()
+		}
+	}
+	for {
+		ok, err := f.Input.IncrementToken()
+		if err != nil || !ok {
+			return false, err
+		}
+		if ok, err = f.self.Accept(); err != nil {
+			return false, err
+		} else if ok {
+			if f.first {
+				// first token having posinc=0 is illegal.
+				if f.posIncrAtt.PositionIncrement() == 0 {
+					f.posIncrAtt.SetPositionIncrement(1)
+				}
+				f.first = false
+			}
+			return true, nil
+		}
+	}
+}
+
+func (f *FilteringTokenFilterImpl) Reset() error {
+	f.first = true
+	return f.TokenFilter.Reset()
+}
+
+// Returns true if the filter preserves the holes left by the removed
+// tokens.
+func (f *FilteringTokenFilterImpl) EnablePositionIncrements() bool {
+	return f.enablePositionIncrements
+}
+
+/*
+If true, this TokenFilter will preserve positions of the incoming
+tokens (ie, accumulate and set position increments of the removed
+tokens). Generally, true is best as it does not lose information
+(positions of the original tokens) during indexing.
+
+When set, when a token is stopped (omitted), the position increment
+of the following token is incremented.
+*/
+func (f *FilteringTokenFilterImpl) SetEnablePositionIncrements(enable bool) {
+	f.enablePositionIncrements = enable
+}
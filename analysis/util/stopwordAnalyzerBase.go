@@ -0,0 +1,59 @@
+package util
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"io"
+	"os"
+)
+
+// analysis/util/StopwordAnalyzerBase.java
+
+/*
+Base class for Analyzers that need to make use of stopword sets.
+
+The concrete analyzer embeds StopwordAnalyzerBase, and implements
+CreateComponents() to apply StopwordSet() in its chain.
+*/
+type StopwordAnalyzerBase struct {
+	*analysis.AnalyzerImpl
+	// An immutable stopword set
+	stopwords *CharArraySet
+}
+
+/*
+Creates a new instance initialized with the given stopword set, whose
+components are created by creator. A nil set is treated as empty.
+*/
+func NewStopwordAnalyzerBase(creator analysis.ComponentsCreator, stopwords *CharArraySet) *StopwordAnalyzerBase {
+	if stopwords == nil {
+		stopwords = EMPTY_SET
+	}
+	return &StopwordAnalyzerBase{analysis.NewAnalyzer(creator), stopwords}
+}
+
+// Returns the analyzer's stopword set or an empty set if the analyzer
+// has no stopwords.
+func (a *StopwordAnalyzerBase) StopwordSet() *CharArraySet {
+	return a.stopwords
+}
+
+/*
+Creates a CharArraySet from a reader, with one stopword per line,
+ignoring lines starting with comment. See GetWordSetWithComment().
+*/
+func LoadStopwordSet(reader io.Reader, ignoreCase bool, comment string) (*CharArraySet, error) {
+	return GetWordSetWithComment(reader, comment, NewCharArraySet(16, ignoreCase))
+}
+
+/*
+Creates a case sensitive CharArraySet from a UTF-8 encoded file, with
+one stopword per line, such as the stopword files shipped with Lucene.
+*/
+func LoadStopwordSetFromFile(path string) (*CharArraySet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return GetWordSet(f, NewCharArraySet(16, false))
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// analysis/util/WordlistLoader.java
+
+/*
+Reads lines from a reader and adds every line as an entry to a
+CharArraySet (omitting leading and trailing whitespace and empty
+lines). Every line of the reader should contain only one word. The
+words need to be in lowercase if you make use of an Analyzer which
+uses LowerCaseFilter (like StandardAnalyzer). Returns result, with the
+words added.
+*/
+func GetWordSet(reader io.Reader, result *CharArraySet) (*CharArraySet, error) {
+	return GetWordSetWithComment(reader, "", result)
+}
+
+/*
+Reads lines from a reader and adds every non-comment line as an entry
+to a CharArraySet (omitting leading and trailing whitespace and empty
+lines). A line is a comment if it starts with comment; an empty
+comment disables comments. Every line of the reader should contain
+only one word. Returns result, with the words added.
+*/
+func GetWordSetWithComment(reader io.Reader, comment string, result *CharArraySet) (*CharArraySet, error) {
+	err := readLines(reader, func(line string) {
+		if comment != "" && strings.HasPrefix(line, comment) {
+			return
+		}
+		if word := strings.TrimSpace(line); word != "" {
+			result.Add(word)
+		}
+	})
+	return result, err
+}
+
+/*
+Reads stopwords from a stopword list in Snowball format.
+
+The snowball format is the following:
+
+  - Lines may contain multiple words separated by whitespace.
+  - The comment character is the vertical line (|).
+  - Lines may contain trailing comments.
+
+Returns result, with the words added.
+*/
+func GetSnowballWordSet(reader io.Reader, result *CharArraySet) (*CharArraySet, error) {
+	err := readLines(reader, func(line string) {
+		if comment := strings.IndexByte(line, '|'); comment >= 0 {
+			line = line[:comment]
+		}
+		for _, word := range strings.FieldsFunc(line, unicode.IsSpace) {
+			result.Add(word)
+		}
+	})
+	return result, err
+}
+
+// Calls f on each line of reader, without the line terminator. A
+// leading UTF-8 byte order mark is skipped.
+func readLines(reader io.Reader, f func(line string)) error {
+	scanner := bufio.NewScanner(reader)
+	for first := true; scanner.Scan(); first = false {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		f(line)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func assertWords(t *testing.T, set *CharArraySet, expected ...string) {
+	t.Helper()
+	words := set.Words()
+	sort.Strings(words)
+	sort.Strings(expected)
+	if strings.Join(words, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected words %v, but was %v", expected, words)
+	}
+}
+
+func TestWordlistLoader(t *testing.T) {
+	s := "\ufeffONE\n  two \r\n\nthree\n#four"
+	set, err := GetWordSet(strings.NewReader(s), NewCharArraySet(0, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertWords(t, set, "ONE", "two", "three", "#four")
+
+	set, err = GetWordSetWithComment(strings.NewReader(s), "#", NewCharArraySet(0, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertWords(t, set, "one", "two", "three")
+}
+
+func TestSnowballWordSet(t *testing.T) {
+	s := "|comment\n" + // commented line
+		" |comment\n" + // commented line with leading whitespace
+		"\n" + // blank line
+		"  \t\n" + // line with only whitespace
+		" |comment | comment\n" + // commented line with comment
+		"ONE\n" + // stopword, in uppercase
+		"   two   \n" + // stopword with leading/trailing space
+		" three   four five \n" + // multiple stopwords
+		"six seven | comment\n" //multiple stopwords + comment
+	set, err := GetSnowballWordSet(strings.NewReader(s), NewCharArraySet(0, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertWords(t, set, "ONE", "two", "three", "four", "five", "six", "seven")
+}
+
+func TestLoadStopwordSet(t *testing.T) {
+	f, err := ioutil.TempFile("", "stopwords")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString("the\nAnd\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	set, err := LoadStopwordSetFromFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertWords(t, set, "the", "And")
+
+	if _, err = LoadStopwordSetFromFile(f.Name() + ".missing"); err == nil {
+		t.Error("loading a missing file should fail")
+	}
+
+	set, err = LoadStopwordSet(strings.NewReader("// comment\nThe\n"), true, "//")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertWords(t, set, "the")
+}
@@ -0,0 +1,132 @@
+/*
+Command golucene-checkindex checks the health of an index and
+optionally exorcises broken segments from it, as Lucene's CheckIndex
+tool does.
+
+Usage:
+
+	golucene-checkindex [-fix] [-verbose] [-segment X] [-segment Y] pathToIndex
+
+	-fix: actually write a new segments_N file, removing any problematic segments
+	-verbose: print additional details
+	-segment X: only check the specified segments. This can be specified
+	            multiple times, to check more than one segment, eg '-segment _2
+	            -segment _a'. You can't use this with the -fix option
+
+WARNING: -fix should only be used on an emergency basis as it will
+cause documents (perhaps many) to be permanently removed from the
+index. Always make a backup copy of your index before running this!
+Do not run this tool on an index that is actively being written to.
+You have been warned!
+
+Run without -fix, this tool will open the index, report version
+information and report any exceptions it hits and what action it
+would take if -fix were specified. With -fix, this tool will remove
+any segments that have issues and write a new segments_N file. This
+means all documents contained in the affected segments will be
+removed.
+
+This tool exits with exit code 1 if the index cannot be opened or has
+any corruption, else 0.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/store"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+type segmentList []string
+
+func (l *segmentList) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *segmentList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var onlySegments segmentList
+	doFix := flag.Bool("fix", false, "write a new segments_N file, removing any problematic segments")
+	verbose := flag.Bool("verbose", false, "print additional details")
+	flag.Var(&onlySegments, "segment", "only check the specified segment; can be repeated")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %v [-fix] [-verbose] [-segment X] [-segment Y] pathToIndex\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	indexPath := flag.Arg(0)
+	if *doFix && len(onlySegments) > 0 {
+		fmt.Println("ERROR: cannot specify both -fix and -segment")
+		os.Exit(1)
+	}
+	if !*verbose {
+		// the readers log every file they open
+		log.SetOutput(ioutil.Discard)
+	}
+
+	fmt.Printf("\nOpening index @ %v\n\n", indexPath)
+	dir, err := store.OpenFSDirectory(indexPath)
+	if err != nil {
+		fmt.Printf("ERROR: could not open directory \"%v\"; exiting\n", indexPath)
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer dir.Close()
+
+	checker := index.NewCheckIndex(dir)
+	checker.SetInfoStream(os.Stdout, *verbose)
+
+	var result *index.CheckIndexStatus
+	if len(onlySegments) == 0 {
+		result, err = checker.CheckIndex()
+	} else {
+		result, err = checker.CheckIndexSegments(onlySegments)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if result.MissingSegments {
+		os.Exit(1)
+	}
+
+	if !result.Clean {
+		if !*doFix {
+			fmt.Printf("WARNING: would write new segments file, and %v documents would be lost, if -fix were specified\n\n",
+				result.TotLoseDocCount)
+		} else {
+			fmt.Println("WARNING: will write new segments file in 5 seconds; this will remove", result.TotLoseDocCount,
+				"docs from the index. THIS IS YOUR LAST CHANCE TO CTRL+C!")
+			for s := 0; s < 5; s++ {
+				time.Sleep(time.Second)
+				fmt.Printf("  %v...\n", 5-s)
+			}
+			fmt.Println("Writing...")
+			if err = checker.FixIndex(result); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("OK\nWrote new segments file \"%v\"\n", result.NewSegmentsFileName())
+		}
+	}
+	fmt.Println()
+
+	if !result.Clean {
+		os.Exit(1)
+	}
+}
@@ -1,39 +1,112 @@
 package codec
 
 import (
+	"bytes"
+	"compress/flate"
 	"errors"
 	"fmt"
+	"io/ioutil"
 )
 
+// CompressionMode.java
+
+/*
+A compression mode. Tells how much effort should be spent on
+compression and decompression of stored fields.
+*/
 type CompressionMode interface {
 	NewCompressor() Compressor
 	NewDecompressor() Decompressor
 }
 
 const (
+	/*
+		A compression mode that trades compression ratio for speed.
+		Although the compression ratio might remain high, compression
+		and decompression are very fast. Use this mode with indices that
+		have a high update rate but should be able to load documents
+		from disk quickly.
+	*/
 	COMPRESSION_MODE_FAST = CompressionModeDefaults(1)
+	/*
+		A compression mode that trades speed for compression ratio.
+		Although compression and decompression might be slow, this
+		compression mode should provide a good compression ratio. This
+		mode might be interesting if/when your index size is much bigger
+		than your OS cache.
+	*/
+	COMPRESSION_MODE_HIGH_COMPRESSION = CompressionModeDefaults(2)
 )
 
 type CompressionModeDefaults int
 
 func (m CompressionModeDefaults) NewCompressor() Compressor {
-	panic("not supported yet")
-	return nil
+	switch int(m) {
+	case 1:
+		return newLZ4FastCompressor()
+	case 2:
+		// 6 is the default level; higher levels are mostly a waste of
+		// cpu for stored fields
+		return newDeflateCompressor(6)
+	default:
+		panic("not implemented yet")
+	}
 }
 
 func (m CompressionModeDefaults) NewDecompressor() Decompressor {
 	switch int(m) {
 	case 1:
 		return LZ4_DECOMPRESSOR
+	case 2:
+		return DEFLATE_DECOMPRESSOR
 	default:
 		panic("not implemented yet")
 	}
 }
 
-type Compressor interface{}
+func (m CompressionModeDefaults) String() string {
+	switch int(m) {
+	case 1:
+		return "FAST"
+	case 2:
+		return "HIGH_COMPRESSION"
+	}
+	panic("assert fail")
+}
+
+// A data compressor.
+type Compressor interface {
+	/*
+		Compress bytes into out. It is the responsibility of the
+		compressor to add all necessary information so that a
+		Decompressor will know when to stop decompressing bytes from the
+		stream.
+	*/
+	Compress(bytes []byte, out DataOutput) error
+}
+
+type LZ4FastCompressor struct {
+	ht *LZ4HashTable
+}
+
+func newLZ4FastCompressor() *LZ4FastCompressor {
+	return &LZ4FastCompressor{new(LZ4HashTable)}
+}
 
+func (c *LZ4FastCompressor) Compress(bytes []byte, out DataOutput) error {
+	return LZ4Compress(bytes, out, c.ht)
+}
+
+// A decompressor.
 type Decompressor interface {
-	decompress(in DataInput, originalLength int, buf []byte) (res []byte, err error)
+	/*
+		Decompress bytes that were stored between offsets offset and
+		offset+length in the original stream from the compressed stream
+		in to buf. After returning, res holds exactly the decompressed
+		bytes. It may share the underlying array of buf, which is reused
+		when it is large enough.
+	*/
+	Decompress(in DataInput, originalLength, offset, length int, buf []byte) (res []byte, err error)
 }
 
 var (
@@ -42,16 +115,121 @@ var (
 
 type LZ4Decompressor int
 
-func (d LZ4Decompressor) decompress(in DataInput, originalLength int, buf []byte) (res []byte, err error) {
-	// assert offset + length <= originalLength
+func (d LZ4Decompressor) Decompress(in DataInput, originalLength, offset, length int, buf []byte) (res []byte, err error) {
+	if offset+length > originalLength {
+		panic("assert fail")
+	}
 	// add 7 padding bytes, this is not necessary but can help decompression run faster
-	res = buf
-	if len(buf) < originalLength+7 {
+	res = buf[:cap(buf)]
+	if len(res) < originalLength+7 {
 		res = make([]byte, originalLength+7)
 	}
-	decompressedLength, err := LZ4Decompress(in, len(res), res)
+	decompressedLength, err := LZ4Decompress(in, offset+length, res)
+	if err != nil {
+		return nil, err
+	}
 	if decompressedLength > originalLength {
 		return nil, errors.New(fmt.Sprintf("Corrupted: lengths mismatch: %v > %v (resource=%v)", decompressedLength, originalLength, in))
 	}
-	return res, nil
+	return res[offset : offset+length], nil
+}
+
+// CompressionMode.java/DeflateCompressor
+
+/*
+Compresses with raw deflate, and writes the compressed length as a
+vInt before the compressed bytes.
+*/
+type DeflateCompressor struct {
+	compressed *bytes.Buffer
+	compressor *flate.Writer
+}
+
+func newDeflateCompressor(level int) *DeflateCompressor {
+	compressed := new(bytes.Buffer)
+	compressor, err := flate.NewWriter(compressed, level)
+	if err != nil {
+		panic(err) // invalid level
+	}
+	return &DeflateCompressor{compressed, compressor}
+}
+
+func (c *DeflateCompressor) Compress(bytes []byte, out DataOutput) error {
+	c.compressed.Reset()
+	c.compressor.Reset(c.compressed)
+	if len(bytes) > 0 {
+		if _, err := c.compressor.Write(bytes); err != nil {
+			return err
+		}
+		if err := c.compressor.Close(); err != nil {
+			return err
+		}
+	}
+	if err := writeVInt(out, int32(c.compressed.Len())); err != nil {
+		return err
+	}
+	return out.WriteBytes(c.compressed.Bytes())
+}
+
+// CompressionMode.java/DeflateDecompressor
+
+var (
+	DEFLATE_DECOMPRESSOR = DeflateDecompressor(1)
+)
+
+type DeflateDecompressor int
+
+func (d DeflateDecompressor) Decompress(in DataInput, originalLength, offset, length int, buf []byte) (res []byte, err error) {
+	if offset+length > originalLength {
+		panic("assert fail")
+	}
+	if length == 0 {
+		return buf[:0], nil
+	}
+	compressedLength, err := readVInt(in)
+	if err != nil {
+		return nil, err
+	}
+	compressed := make([]byte, compressedLength)
+	if err = in.ReadBytes(compressed); err != nil {
+		return nil, err
+	}
+
+	decompressed, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Corrupted: %v (resource=%v)", err, in))
+	}
+	if len(decompressed) != originalLength {
+		return nil, errors.New(fmt.Sprintf("Corrupted: lengths mismatch: %v != %v (resource=%v)",
+			len(decompressed), originalLength, in))
+	}
+	res = buf[:cap(buf)]
+	if len(res) < length {
+		res = make([]byte, length)
+	}
+	copy(res, decompressed[offset:offset+length])
+	return res[:length], nil
+}
+
+func readVInt(in DataInput) (n int32, err error) {
+	for shift := uint(0); ; shift += 7 {
+		b, err := in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n |= int32(b&0x7F) << shift
+		if b < 0x80 {
+			return n, nil
+		}
+	}
+}
+
+func writeVInt(out DataOutput, i int32) error {
+	for (i & ^0x7F) != 0 {
+		if err := out.WriteByte(byte((i & 0x7F) | 0x80)); err != nil {
+			return err
+		}
+		i = int32(uint32(i) >> 7)
+	}
+	return out.WriteByte(byte(i))
 }
@@ -0,0 +1,50 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeflateCompress(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		[]byte("abc"),
+		bytes.Repeat([]byte("0123456789"), 3000),
+	} {
+		compressor := COMPRESSION_MODE_HIGH_COMPRESSION.NewCompressor()
+		out := new(bytesDataOutput)
+		if err := compressor.Compress(data, out); err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 1000 && out.Len() >= len(data)/10 {
+			t.Errorf("repetitive data of %v bytes was not compressed: %v bytes", len(data), out.Len())
+		}
+
+		decompressor := COMPRESSION_MODE_HIGH_COMPRESSION.NewDecompressor()
+		res, err := decompressor.Decompress(bytesDataInput{bytes.NewReader(out.Bytes())}, len(data), 0, len(data), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, res) {
+			t.Errorf("round trip of %v bytes failed: got %v bytes", len(data), len(res))
+		}
+		if len(data) > 20 {
+			res, err = decompressor.Decompress(bytesDataInput{bytes.NewReader(out.Bytes())}, len(data), 5, 15, res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data[5:20], res) {
+				t.Errorf("Expected %v, but was %v", string(data[5:20]), string(res))
+			}
+		}
+	}
+
+	// corrupted length
+	out := new(bytesDataOutput)
+	if err := COMPRESSION_MODE_HIGH_COMPRESSION.NewCompressor().Compress([]byte("abcdef"), out); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DEFLATE_DECOMPRESSOR.Decompress(bytesDataInput{bytes.NewReader(out.Bytes())}, 7, 0, 7, nil); err == nil {
+		t.Error("Should detect length mismatch")
+	}
+}
@@ -0,0 +1,27 @@
+package codec
+
+import (
+	"fmt"
+)
+
+//go:generate go run gen_forDecoders.go
+
+/*
+Decodes iterations blocks of packed values into values, the same way
+as PackedIntsDecoder.DecodeByteToInt() does for the PACKED format: the
+values are stored as a big-endian bit stream, and each block is the
+smallest number of bytes which holds a whole number of values.
+
+Unlike the generic decoder, each ForDecoder is an unrolled loop
+specialized for a single number of bits per value, which is what the
+postings readers spend most of their time in.
+*/
+type ForDecoder func(blocks []byte, values []int32, iterations int)
+
+// Returns the ForDecoder of values of bitsPerValue bits, in [1, 32].
+func GetForDecoder(bitsPerValue uint32) ForDecoder {
+	if bitsPerValue < 1 || bitsPerValue >= uint32(len(forDecoders)) {
+		panic(fmt.Sprintf("Unsupported bitsPerValue: %v", bitsPerValue))
+	}
+	return forDecoders[bitsPerValue]
+}
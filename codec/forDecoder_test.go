@@ -0,0 +1,85 @@
+package codec
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// Reads values of bitsPerValue bits from the big-endian bit stream,
+// bit by bit.
+func decodeBitByBit(blocks []byte, values []int32, bitsPerValue uint32) {
+	bit := uint32(0)
+	for i := range values {
+		var v uint32
+		for b := uint32(0); b < bitsPerValue; b, bit = b+1, bit+1 {
+			v = v<<1 | uint32(blocks[bit/8]>>(7-bit%8))&1
+		}
+		values[i] = int32(v)
+	}
+}
+
+func TestForDecoder(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for bpv := uint32(1); bpv <= 32; bpv++ {
+		const iterations = 16
+		// the smallest number of bytes holding whole values
+		byteBlockCount := int(bpv)
+		for byteBlockCount%2 == 0 && byteBlockCount*8/int(bpv)%2 == 0 {
+			byteBlockCount /= 2
+		}
+		byteValueCount := byteBlockCount * 8 / int(bpv)
+
+		blocks := make([]byte, iterations*byteBlockCount)
+		r.Read(blocks)
+		expected := make([]int32, iterations*byteValueCount)
+		decodeBitByBit(blocks, expected, bpv)
+
+		actual := make([]int32, len(expected))
+		GetForDecoder(bpv)(blocks, actual, iterations)
+		for i, v := range expected {
+			if actual[i] != v {
+				t.Fatalf("bpv=%v: expected %v at %v, but was %v", bpv, v, i, actual[i])
+			}
+		}
+	}
+}
+
+func TestGetForDecoderUnsupported(t *testing.T) {
+	for _, bpv := range []uint32{0, 33} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Should fail for bitsPerValue %v", bpv)
+				}
+			}()
+			GetForDecoder(bpv)
+		}()
+	}
+}
+
+// Decodes a 128-value block, as ForUtil.ReadBlock() does.
+func BenchmarkForDecoder(b *testing.B) {
+	for _, bpv := range []uint32{1, 3, 7, 8, 12, 17, 24, 32} {
+		b.Run(fmt.Sprintf("bpv=%v", bpv), func(b *testing.B) {
+			encoded := make([]byte, 128*4)
+			rand.New(rand.NewSource(1)).Read(encoded)
+			decoded := make([]int32, 128)
+			decoder := GetForDecoder(bpv)
+			// 128 values fit in whole blocks for any bpv
+			iterations := 128 / 8
+			if bpv%8 == 0 {
+				iterations = 128
+			} else if bpv%4 == 0 {
+				iterations = 128 / 2
+			} else if bpv%2 == 0 {
+				iterations = 128 / 4
+			}
+			b.SetBytes(int64(128 * bpv / 8))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				decoder(encoded, decoded, iterations)
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+// Code generated by gen_forDecoders.go. DO NOT EDIT.
+
+package codec
+
+var forDecoders = []ForDecoder{
+	nil,
+	decodePacked1,
+	decodePacked2,
+	decodePacked3,
+	decodePacked4,
+	decodePacked5,
+	decodePacked6,
+	decodePacked7,
+	decodePacked8,
+	decodePacked9,
+	decodePacked10,
+	decodePacked11,
+	decodePacked12,
+	decodePacked13,
+	decodePacked14,
+	decodePacked15,
+	decodePacked16,
+	decodePacked17,
+	decodePacked18,
+	decodePacked19,
+	decodePacked20,
+	decodePacked21,
+	decodePacked22,
+	decodePacked23,
+	decodePacked24,
+	decodePacked25,
+	decodePacked26,
+	decodePacked27,
+	decodePacked28,
+	decodePacked29,
+	decodePacked30,
+	decodePacked31,
+	decodePacked32,
+}
+
+func decodePacked1(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*1 : i*1+1]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0]) >> 7)
+		v[1] = int32((uint32(b[0]) >> 6) & 1)
+		v[2] = int32((uint32(b[0]) >> 5) & 1)
+		v[3] = int32((uint32(b[0]) >> 4) & 1)
+		v[4] = int32((uint32(b[0]) >> 3) & 1)
+		v[5] = int32((uint32(b[0]) >> 2) & 1)
+		v[6] = int32((uint32(b[0]) >> 1) & 1)
+		v[7] = int32(uint32(b[0]) & 1)
+	}
+}
+
+func decodePacked2(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*1 : i*1+1]
+		v := values[i*4 : i*4+4]
+		v[0] = int32(uint32(b[0]) >> 6)
+		v[1] = int32((uint32(b[0]) >> 4) & 3)
+		v[2] = int32((uint32(b[0]) >> 2) & 3)
+		v[3] = int32(uint32(b[0]) & 3)
+	}
+}
+
+func decodePacked3(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*3 : i*3+3]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0]) >> 5)
+		v[1] = int32((uint32(b[0]) >> 2) & 7)
+		v[2] = int32((uint32(b[0])&3)<<1 | uint32(b[1])>>7)
+		v[3] = int32((uint32(b[1]) >> 4) & 7)
+		v[4] = int32((uint32(b[1]) >> 1) & 7)
+		v[5] = int32((uint32(b[1])&1)<<2 | uint32(b[2])>>6)
+		v[6] = int32((uint32(b[2]) >> 3) & 7)
+		v[7] = int32(uint32(b[2]) & 7)
+	}
+}
+
+func decodePacked4(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*1 : i*1+1]
+		v := values[i*2 : i*2+2]
+		v[0] = int32(uint32(b[0]) >> 4)
+		v[1] = int32(uint32(b[0]) & 15)
+	}
+}
+
+func decodePacked5(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*5 : i*5+5]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0]) >> 3)
+		v[1] = int32((uint32(b[0])&7)<<2 | uint32(b[1])>>6)
+		v[2] = int32((uint32(b[1]) >> 1) & 31)
+		v[3] = int32((uint32(b[1])&1)<<4 | uint32(b[2])>>4)
+		v[4] = int32((uint32(b[2])&15)<<1 | uint32(b[3])>>7)
+		v[5] = int32((uint32(b[3]) >> 2) & 31)
+		v[6] = int32((uint32(b[3])&3)<<3 | uint32(b[4])>>5)
+		v[7] = int32(uint32(b[4]) & 31)
+	}
+}
+
+func decodePacked6(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*3 : i*3+3]
+		v := values[i*4 : i*4+4]
+		v[0] = int32(uint32(b[0]) >> 2)
+		v[1] = int32((uint32(b[0])&3)<<4 | uint32(b[1])>>4)
+		v[2] = int32((uint32(b[1])&15)<<2 | uint32(b[2])>>6)
+		v[3] = int32(uint32(b[2]) & 63)
+	}
+}
+
+func decodePacked7(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*7 : i*7+7]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0]) >> 1)
+		v[1] = int32((uint32(b[0])&1)<<6 | uint32(b[1])>>2)
+		v[2] = int32((uint32(b[1])&3)<<5 | uint32(b[2])>>3)
+		v[3] = int32((uint32(b[2])&7)<<4 | uint32(b[3])>>4)
+		v[4] = int32((uint32(b[3])&15)<<3 | uint32(b[4])>>5)
+		v[5] = int32((uint32(b[4])&31)<<2 | uint32(b[5])>>6)
+		v[6] = int32((uint32(b[5])&63)<<1 | uint32(b[6])>>7)
+		v[7] = int32(uint32(b[6]) & 127)
+	}
+}
+
+func decodePacked8(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*1 : i*1+1]
+		v := values[i*1 : i*1+1]
+		v[0] = int32(uint32(b[0]))
+	}
+}
+
+func decodePacked9(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*9 : i*9+9]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<1 | uint32(b[1])>>7)
+		v[1] = int32((uint32(b[1])&127)<<2 | uint32(b[2])>>6)
+		v[2] = int32((uint32(b[2])&63)<<3 | uint32(b[3])>>5)
+		v[3] = int32((uint32(b[3])&31)<<4 | uint32(b[4])>>4)
+		v[4] = int32((uint32(b[4])&15)<<5 | uint32(b[5])>>3)
+		v[5] = int32((uint32(b[5])&7)<<6 | uint32(b[6])>>2)
+		v[6] = int32((uint32(b[6])&3)<<7 | uint32(b[7])>>1)
+		v[7] = int32((uint32(b[7])&1)<<8 | uint32(b[8]))
+	}
+}
+
+func decodePacked10(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*5 : i*5+5]
+		v := values[i*4 : i*4+4]
+		v[0] = int32(uint32(b[0])<<2 | uint32(b[1])>>6)
+		v[1] = int32((uint32(b[1])&63)<<4 | uint32(b[2])>>4)
+		v[2] = int32((uint32(b[2])&15)<<6 | uint32(b[3])>>2)
+		v[3] = int32((uint32(b[3])&3)<<8 | uint32(b[4]))
+	}
+}
+
+func decodePacked11(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*11 : i*11+11]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<3 | uint32(b[1])>>5)
+		v[1] = int32((uint32(b[1])&31)<<6 | uint32(b[2])>>2)
+		v[2] = int32((uint32(b[2])&3)<<9 | uint32(b[3])<<1 | uint32(b[4])>>7)
+		v[3] = int32((uint32(b[4])&127)<<4 | uint32(b[5])>>4)
+		v[4] = int32((uint32(b[5])&15)<<7 | uint32(b[6])>>1)
+		v[5] = int32((uint32(b[6])&1)<<10 | uint32(b[7])<<2 | uint32(b[8])>>6)
+		v[6] = int32((uint32(b[8])&63)<<5 | uint32(b[9])>>3)
+		v[7] = int32((uint32(b[9])&7)<<8 | uint32(b[10]))
+	}
+}
+
+func decodePacked12(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*3 : i*3+3]
+		v := values[i*2 : i*2+2]
+		v[0] = int32(uint32(b[0])<<4 | uint32(b[1])>>4)
+		v[1] = int32((uint32(b[1])&15)<<8 | uint32(b[2]))
+	}
+}
+
+func decodePacked13(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*13 : i*13+13]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<5 | uint32(b[1])>>3)
+		v[1] = int32((uint32(b[1])&7)<<10 | uint32(b[2])<<2 | uint32(b[3])>>6)
+		v[2] = int32((uint32(b[3])&63)<<7 | uint32(b[4])>>1)
+		v[3] = int32((uint32(b[4])&1)<<12 | uint32(b[5])<<4 | uint32(b[6])>>4)
+		v[4] = int32((uint32(b[6])&15)<<9 | uint32(b[7])<<1 | uint32(b[8])>>7)
+		v[5] = int32((uint32(b[8])&127)<<6 | uint32(b[9])>>2)
+		v[6] = int32((uint32(b[9])&3)<<11 | uint32(b[10])<<3 | uint32(b[11])>>5)
+		v[7] = int32((uint32(b[11])&31)<<8 | uint32(b[12]))
+	}
+}
+
+func decodePacked14(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*7 : i*7+7]
+		v := values[i*4 : i*4+4]
+		v[0] = int32(uint32(b[0])<<6 | uint32(b[1])>>2)
+		v[1] = int32((uint32(b[1])&3)<<12 | uint32(b[2])<<4 | uint32(b[3])>>4)
+		v[2] = int32((uint32(b[3])&15)<<10 | uint32(b[4])<<2 | uint32(b[5])>>6)
+		v[3] = int32((uint32(b[5])&63)<<8 | uint32(b[6]))
+	}
+}
+
+func decodePacked15(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*15 : i*15+15]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<7 | uint32(b[1])>>1)
+		v[1] = int32((uint32(b[1])&1)<<14 | uint32(b[2])<<6 | uint32(b[3])>>2)
+		v[2] = int32((uint32(b[3])&3)<<13 | uint32(b[4])<<5 | uint32(b[5])>>3)
+		v[3] = int32((uint32(b[5])&7)<<12 | uint32(b[6])<<4 | uint32(b[7])>>4)
+		v[4] = int32((uint32(b[7])&15)<<11 | uint32(b[8])<<3 | uint32(b[9])>>5)
+		v[5] = int32((uint32(b[9])&31)<<10 | uint32(b[10])<<2 | uint32(b[11])>>6)
+		v[6] = int32((uint32(b[11])&63)<<9 | uint32(b[12])<<1 | uint32(b[13])>>7)
+		v[7] = int32((uint32(b[13])&127)<<8 | uint32(b[14]))
+	}
+}
+
+func decodePacked16(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*2 : i*2+2]
+		v := values[i*1 : i*1+1]
+		v[0] = int32(uint32(b[0])<<8 | uint32(b[1]))
+	}
+}
+
+func decodePacked17(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*17 : i*17+17]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<9 | uint32(b[1])<<1 | uint32(b[2])>>7)
+		v[1] = int32((uint32(b[2])&127)<<10 | uint32(b[3])<<2 | uint32(b[4])>>6)
+		v[2] = int32((uint32(b[4])&63)<<11 | uint32(b[5])<<3 | uint32(b[6])>>5)
+		v[3] = int32((uint32(b[6])&31)<<12 | uint32(b[7])<<4 | uint32(b[8])>>4)
+		v[4] = int32((uint32(b[8])&15)<<13 | uint32(b[9])<<5 | uint32(b[10])>>3)
+		v[5] = int32((uint32(b[10])&7)<<14 | uint32(b[11])<<6 | uint32(b[12])>>2)
+		v[6] = int32((uint32(b[12])&3)<<15 | uint32(b[13])<<7 | uint32(b[14])>>1)
+		v[7] = int32((uint32(b[14])&1)<<16 | uint32(b[15])<<8 | uint32(b[16]))
+	}
+}
+
+func decodePacked18(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*9 : i*9+9]
+		v := values[i*4 : i*4+4]
+		v[0] = int32(uint32(b[0])<<10 | uint32(b[1])<<2 | uint32(b[2])>>6)
+		v[1] = int32((uint32(b[2])&63)<<12 | uint32(b[3])<<4 | uint32(b[4])>>4)
+		v[2] = int32((uint32(b[4])&15)<<14 | uint32(b[5])<<6 | uint32(b[6])>>2)
+		v[3] = int32((uint32(b[6])&3)<<16 | uint32(b[7])<<8 | uint32(b[8]))
+	}
+}
+
+func decodePacked19(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*19 : i*19+19]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<11 | uint32(b[1])<<3 | uint32(b[2])>>5)
+		v[1] = int32((uint32(b[2])&31)<<14 | uint32(b[3])<<6 | uint32(b[4])>>2)
+		v[2] = int32((uint32(b[4])&3)<<17 | uint32(b[5])<<9 | uint32(b[6])<<1 | uint32(b[7])>>7)
+		v[3] = int32((uint32(b[7])&127)<<12 | uint32(b[8])<<4 | uint32(b[9])>>4)
+		v[4] = int32((uint32(b[9])&15)<<15 | uint32(b[10])<<7 | uint32(b[11])>>1)
+		v[5] = int32((uint32(b[11])&1)<<18 | uint32(b[12])<<10 | uint32(b[13])<<2 | uint32(b[14])>>6)
+		v[6] = int32((uint32(b[14])&63)<<13 | uint32(b[15])<<5 | uint32(b[16])>>3)
+		v[7] = int32((uint32(b[16])&7)<<16 | uint32(b[17])<<8 | uint32(b[18]))
+	}
+}
+
+func decodePacked20(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*5 : i*5+5]
+		v := values[i*2 : i*2+2]
+		v[0] = int32(uint32(b[0])<<12 | uint32(b[1])<<4 | uint32(b[2])>>4)
+		v[1] = int32((uint32(b[2])&15)<<16 | uint32(b[3])<<8 | uint32(b[4]))
+	}
+}
+
+func decodePacked21(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*21 : i*21+21]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<13 | uint32(b[1])<<5 | uint32(b[2])>>3)
+		v[1] = int32((uint32(b[2])&7)<<18 | uint32(b[3])<<10 | uint32(b[4])<<2 | uint32(b[5])>>6)
+		v[2] = int32((uint32(b[5])&63)<<15 | uint32(b[6])<<7 | uint32(b[7])>>1)
+		v[3] = int32((uint32(b[7])&1)<<20 | uint32(b[8])<<12 | uint32(b[9])<<4 | uint32(b[10])>>4)
+		v[4] = int32((uint32(b[10])&15)<<17 | uint32(b[11])<<9 | uint32(b[12])<<1 | uint32(b[13])>>7)
+		v[5] = int32((uint32(b[13])&127)<<14 | uint32(b[14])<<6 | uint32(b[15])>>2)
+		v[6] = int32((uint32(b[15])&3)<<19 | uint32(b[16])<<11 | uint32(b[17])<<3 | uint32(b[18])>>5)
+		v[7] = int32((uint32(b[18])&31)<<16 | uint32(b[19])<<8 | uint32(b[20]))
+	}
+}
+
+func decodePacked22(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*11 : i*11+11]
+		v := values[i*4 : i*4+4]
+		v[0] = int32(uint32(b[0])<<14 | uint32(b[1])<<6 | uint32(b[2])>>2)
+		v[1] = int32((uint32(b[2])&3)<<20 | uint32(b[3])<<12 | uint32(b[4])<<4 | uint32(b[5])>>4)
+		v[2] = int32((uint32(b[5])&15)<<18 | uint32(b[6])<<10 | uint32(b[7])<<2 | uint32(b[8])>>6)
+		v[3] = int32((uint32(b[8])&63)<<16 | uint32(b[9])<<8 | uint32(b[10]))
+	}
+}
+
+func decodePacked23(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*23 : i*23+23]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<15 | uint32(b[1])<<7 | uint32(b[2])>>1)
+		v[1] = int32((uint32(b[2])&1)<<22 | uint32(b[3])<<14 | uint32(b[4])<<6 | uint32(b[5])>>2)
+		v[2] = int32((uint32(b[5])&3)<<21 | uint32(b[6])<<13 | uint32(b[7])<<5 | uint32(b[8])>>3)
+		v[3] = int32((uint32(b[8])&7)<<20 | uint32(b[9])<<12 | uint32(b[10])<<4 | uint32(b[11])>>4)
+		v[4] = int32((uint32(b[11])&15)<<19 | uint32(b[12])<<11 | uint32(b[13])<<3 | uint32(b[14])>>5)
+		v[5] = int32((uint32(b[14])&31)<<18 | uint32(b[15])<<10 | uint32(b[16])<<2 | uint32(b[17])>>6)
+		v[6] = int32((uint32(b[17])&63)<<17 | uint32(b[18])<<9 | uint32(b[19])<<1 | uint32(b[20])>>7)
+		v[7] = int32((uint32(b[20])&127)<<16 | uint32(b[21])<<8 | uint32(b[22]))
+	}
+}
+
+func decodePacked24(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*3 : i*3+3]
+		v := values[i*1 : i*1+1]
+		v[0] = int32(uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2]))
+	}
+}
+
+func decodePacked25(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*25 : i*25+25]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<17 | uint32(b[1])<<9 | uint32(b[2])<<1 | uint32(b[3])>>7)
+		v[1] = int32((uint32(b[3])&127)<<18 | uint32(b[4])<<10 | uint32(b[5])<<2 | uint32(b[6])>>6)
+		v[2] = int32((uint32(b[6])&63)<<19 | uint32(b[7])<<11 | uint32(b[8])<<3 | uint32(b[9])>>5)
+		v[3] = int32((uint32(b[9])&31)<<20 | uint32(b[10])<<12 | uint32(b[11])<<4 | uint32(b[12])>>4)
+		v[4] = int32((uint32(b[12])&15)<<21 | uint32(b[13])<<13 | uint32(b[14])<<5 | uint32(b[15])>>3)
+		v[5] = int32((uint32(b[15])&7)<<22 | uint32(b[16])<<14 | uint32(b[17])<<6 | uint32(b[18])>>2)
+		v[6] = int32((uint32(b[18])&3)<<23 | uint32(b[19])<<15 | uint32(b[20])<<7 | uint32(b[21])>>1)
+		v[7] = int32((uint32(b[21])&1)<<24 | uint32(b[22])<<16 | uint32(b[23])<<8 | uint32(b[24]))
+	}
+}
+
+func decodePacked26(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*13 : i*13+13]
+		v := values[i*4 : i*4+4]
+		v[0] = int32(uint32(b[0])<<18 | uint32(b[1])<<10 | uint32(b[2])<<2 | uint32(b[3])>>6)
+		v[1] = int32((uint32(b[3])&63)<<20 | uint32(b[4])<<12 | uint32(b[5])<<4 | uint32(b[6])>>4)
+		v[2] = int32((uint32(b[6])&15)<<22 | uint32(b[7])<<14 | uint32(b[8])<<6 | uint32(b[9])>>2)
+		v[3] = int32((uint32(b[9])&3)<<24 | uint32(b[10])<<16 | uint32(b[11])<<8 | uint32(b[12]))
+	}
+}
+
+func decodePacked27(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*27 : i*27+27]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<19 | uint32(b[1])<<11 | uint32(b[2])<<3 | uint32(b[3])>>5)
+		v[1] = int32((uint32(b[3])&31)<<22 | uint32(b[4])<<14 | uint32(b[5])<<6 | uint32(b[6])>>2)
+		v[2] = int32((uint32(b[6])&3)<<25 | uint32(b[7])<<17 | uint32(b[8])<<9 | uint32(b[9])<<1 | uint32(b[10])>>7)
+		v[3] = int32((uint32(b[10])&127)<<20 | uint32(b[11])<<12 | uint32(b[12])<<4 | uint32(b[13])>>4)
+		v[4] = int32((uint32(b[13])&15)<<23 | uint32(b[14])<<15 | uint32(b[15])<<7 | uint32(b[16])>>1)
+		v[5] = int32((uint32(b[16])&1)<<26 | uint32(b[17])<<18 | uint32(b[18])<<10 | uint32(b[19])<<2 | uint32(b[20])>>6)
+		v[6] = int32((uint32(b[20])&63)<<21 | uint32(b[21])<<13 | uint32(b[22])<<5 | uint32(b[23])>>3)
+		v[7] = int32((uint32(b[23])&7)<<24 | uint32(b[24])<<16 | uint32(b[25])<<8 | uint32(b[26]))
+	}
+}
+
+func decodePacked28(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*7 : i*7+7]
+		v := values[i*2 : i*2+2]
+		v[0] = int32(uint32(b[0])<<20 | uint32(b[1])<<12 | uint32(b[2])<<4 | uint32(b[3])>>4)
+		v[1] = int32((uint32(b[3])&15)<<24 | uint32(b[4])<<16 | uint32(b[5])<<8 | uint32(b[6]))
+	}
+}
+
+func decodePacked29(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*29 : i*29+29]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<21 | uint32(b[1])<<13 | uint32(b[2])<<5 | uint32(b[3])>>3)
+		v[1] = int32((uint32(b[3])&7)<<26 | uint32(b[4])<<18 | uint32(b[5])<<10 | uint32(b[6])<<2 | uint32(b[7])>>6)
+		v[2] = int32((uint32(b[7])&63)<<23 | uint32(b[8])<<15 | uint32(b[9])<<7 | uint32(b[10])>>1)
+		v[3] = int32((uint32(b[10])&1)<<28 | uint32(b[11])<<20 | uint32(b[12])<<12 | uint32(b[13])<<4 | uint32(b[14])>>4)
+		v[4] = int32((uint32(b[14])&15)<<25 | uint32(b[15])<<17 | uint32(b[16])<<9 | uint32(b[17])<<1 | uint32(b[18])>>7)
+		v[5] = int32((uint32(b[18])&127)<<22 | uint32(b[19])<<14 | uint32(b[20])<<6 | uint32(b[21])>>2)
+		v[6] = int32((uint32(b[21])&3)<<27 | uint32(b[22])<<19 | uint32(b[23])<<11 | uint32(b[24])<<3 | uint32(b[25])>>5)
+		v[7] = int32((uint32(b[25])&31)<<24 | uint32(b[26])<<16 | uint32(b[27])<<8 | uint32(b[28]))
+	}
+}
+
+func decodePacked30(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*15 : i*15+15]
+		v := values[i*4 : i*4+4]
+		v[0] = int32(uint32(b[0])<<22 | uint32(b[1])<<14 | uint32(b[2])<<6 | uint32(b[3])>>2)
+		v[1] = int32((uint32(b[3])&3)<<28 | uint32(b[4])<<20 | uint32(b[5])<<12 | uint32(b[6])<<4 | uint32(b[7])>>4)
+		v[2] = int32((uint32(b[7])&15)<<26 | uint32(b[8])<<18 | uint32(b[9])<<10 | uint32(b[10])<<2 | uint32(b[11])>>6)
+		v[3] = int32((uint32(b[11])&63)<<24 | uint32(b[12])<<16 | uint32(b[13])<<8 | uint32(b[14]))
+	}
+}
+
+func decodePacked31(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*31 : i*31+31]
+		v := values[i*8 : i*8+8]
+		v[0] = int32(uint32(b[0])<<23 | uint32(b[1])<<15 | uint32(b[2])<<7 | uint32(b[3])>>1)
+		v[1] = int32((uint32(b[3])&1)<<30 | uint32(b[4])<<22 | uint32(b[5])<<14 | uint32(b[6])<<6 | uint32(b[7])>>2)
+		v[2] = int32((uint32(b[7])&3)<<29 | uint32(b[8])<<21 | uint32(b[9])<<13 | uint32(b[10])<<5 | uint32(b[11])>>3)
+		v[3] = int32((uint32(b[11])&7)<<28 | uint32(b[12])<<20 | uint32(b[13])<<12 | uint32(b[14])<<4 | uint32(b[15])>>4)
+		v[4] = int32((uint32(b[15])&15)<<27 | uint32(b[16])<<19 | uint32(b[17])<<11 | uint32(b[18])<<3 | uint32(b[19])>>5)
+		v[5] = int32((uint32(b[19])&31)<<26 | uint32(b[20])<<18 | uint32(b[21])<<10 | uint32(b[22])<<2 | uint32(b[23])>>6)
+		v[6] = int32((uint32(b[23])&63)<<25 | uint32(b[24])<<17 | uint32(b[25])<<9 | uint32(b[26])<<1 | uint32(b[27])>>7)
+		v[7] = int32((uint32(b[27])&127)<<24 | uint32(b[28])<<16 | uint32(b[29])<<8 | uint32(b[30]))
+	}
+}
+
+func decodePacked32(blocks []byte, values []int32, iterations int) {
+	for i := 0; i < iterations; i++ {
+		b := blocks[i*4 : i*4+4]
+		v := values[i*1 : i*1+1]
+		v[0] = int32(uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3]))
+	}
+}
@@ -0,0 +1,101 @@
+//go:build ignore
+
+// Generates forDecoders.go, the unrolled decode loops of ForDecoder,
+// one per bits per value. Run with "go generate" in the codec package.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+)
+
+const maxBitsPerValue = 32
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func writeDecoder(buf *bytes.Buffer, bpv int) {
+	// smallest number of bytes holding a whole number of values
+	byteBlockCount := bpv / gcd(bpv, 8)
+	byteValueCount := byteBlockCount * 8 / bpv
+
+	fmt.Fprintf(buf, "func decodePacked%v(blocks []byte, values []int32, iterations int) {\n", bpv)
+	fmt.Fprintf(buf, "for i := 0; i < iterations; i++ {\n")
+	fmt.Fprintf(buf, "b := blocks[i*%v : i*%v+%v]\n", byteBlockCount, byteBlockCount, byteBlockCount)
+	fmt.Fprintf(buf, "v := values[i*%v : i*%v+%v]\n", byteValueCount, byteValueCount, byteValueCount)
+	for i := 0; i < byteValueCount; i++ {
+		start, end := i*bpv, (i+1)*bpv // [start, end) in bits
+		var parts []string
+		for bit := start; bit < end; {
+			byteIndex := bit / 8
+			// bits of this value available in the current byte
+			offset := bit % 8
+			n := 8 - offset
+			if n > end-bit {
+				n = end - bit
+			}
+			shiftRight := 8 - offset - n
+			shiftLeft := end - bit - n
+			// Go's shifts and & share the same precedence, parenthesize
+			// compound operands for readability
+			expr, compound := fmt.Sprintf("uint32(b[%v])", byteIndex), false
+			if shiftRight > 0 {
+				expr, compound = fmt.Sprintf("%v>>%v", expr, shiftRight), true
+			}
+			if n < 8 && offset > 0 {
+				if compound {
+					expr = "(" + expr + ")"
+				}
+				expr, compound = fmt.Sprintf("%v&%v", expr, (1<<uint(n))-1), true
+			}
+			if shiftLeft > 0 {
+				if compound {
+					expr = "(" + expr + ")"
+				}
+				expr = fmt.Sprintf("%v<<%v", expr, shiftLeft)
+			}
+			parts = append(parts, expr)
+			bit += n
+		}
+		fmt.Fprintf(buf, "v[%v] = int32(", i)
+		for j, part := range parts {
+			if j > 0 {
+				fmt.Fprint(buf, " | ")
+			}
+			fmt.Fprint(buf, part)
+		}
+		fmt.Fprint(buf, ")\n")
+	}
+	fmt.Fprint(buf, "}\n}\n\n")
+}
+
+func main() {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by gen_forDecoders.go. DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package codec")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "var forDecoders = []ForDecoder{")
+	fmt.Fprintln(&buf, "nil,")
+	for bpv := 1; bpv <= maxBitsPerValue; bpv++ {
+		fmt.Fprintf(&buf, "decodePacked%v,\n", bpv)
+	}
+	fmt.Fprint(&buf, "}\n\n")
+	for bpv := 1; bpv <= maxBitsPerValue; bpv++ {
+		writeDecoder(&buf, bpv)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile("forDecoders.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
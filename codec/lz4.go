@@ -1,5 +1,254 @@
 package codec
 
+// LZ4.java
+
+/*
+LZ4 compression and decompression routines.
+
+http://code.google.com/p/lz4/
+http://fastcompression.blogspot.fr/p/lz4.html
+*/
+
+const LZ4_MIN_MATCH = 4 // minimum length of a match
+
+/*
+Decompress at least decompressedLen bytes into dest. Please note that
+dest must be large enough to be able to hold all decompressed data
+(meaning that you need to know the total decompressed length).
+*/
 func LZ4Decompress(compressed DataInput, decompressedLen int, dest []byte) (n int, err error) {
-	panic("not implemented yet")
+	dOff, destEnd := 0, len(dest)
+
+	for {
+		// literals
+		token, err := compressed.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		literalLen := int(token >> 4)
+		if literalLen != 0 {
+			if literalLen == 0x0F {
+				var length byte
+				for length, err = compressed.ReadByte(); err == nil && length == 0xFF; length, err = compressed.ReadByte() {
+					literalLen += 0xFF
+				}
+				if err != nil {
+					return 0, err
+				}
+				literalLen += int(length)
+			}
+			if err = compressed.ReadBytes(dest[dOff : dOff+literalLen]); err != nil {
+				return 0, err
+			}
+			dOff += literalLen
+		}
+
+		if dOff >= decompressedLen {
+			break
+		}
+
+		// matchs
+		b1, err := compressed.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		b2, err := compressed.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		matchDec := int(b1) | int(b2)<<8
+		if matchDec <= 0 {
+			panic("assert fail")
+		}
+
+		matchLen := int(token & 0x0F)
+		if matchLen == 0x0F {
+			var length byte
+			for length, err = compressed.ReadByte(); err == nil && length == 0xFF; length, err = compressed.ReadByte() {
+				matchLen += 0xFF
+			}
+			if err != nil {
+				return 0, err
+			}
+			matchLen += int(length)
+		}
+		matchLen += LZ4_MIN_MATCH
+
+		// copying a multiple of 8 bytes can make decompression from 5% to 10% faster
+		fastLen := (matchLen + 7) & 0xFFFFFFF8
+		if matchDec < matchLen || dOff+fastLen > destEnd {
+			// overlap -> naive incremental copy
+			for ref, end := dOff-matchDec, dOff+matchLen; dOff < end; ref, dOff = ref+1, dOff+1 {
+				dest[dOff] = dest[ref]
+			}
+		} else {
+			// no overlap -> arraycopy
+			copy(dest[dOff:dOff+fastLen], dest[dOff-matchDec:])
+			dOff += matchLen
+		}
+
+		if dOff >= decompressedLen {
+			break
+		}
+	}
+
+	return dOff, nil
+}
+
+const (
+	LZ4_MEMORY_USAGE  = 14
+	LZ4_MAX_DISTANCE  = 1 << 16 // maximum distance of a reference
+	LZ4_LAST_LITERALS = 5       // the last 5 bytes must be encoded as literals
+)
+
+func lz4Hash(i uint32, hashBits uint) int {
+	return int((i * 2654435761) >> (32 - hashBits))
+}
+
+func lz4ReadInt(buf []byte, i int) uint32 {
+	return uint32(buf[i])<<24 | uint32(buf[i+1])<<16 | uint32(buf[i+2])<<8 | uint32(buf[i+3])
+}
+
+func lz4CommonBytes(b []byte, o1, o2, limit int) int {
+	count := 0
+	for o2 < limit && b[o1] == b[o2] {
+		o1, o2, count = o1+1, o2+1, count+1
+	}
+	return count
+}
+
+func lz4EncodeLen(l int, out DataOutput) error {
+	for l >= 0xFF {
+		if err := out.WriteByte(0xFF); err != nil {
+			return err
+		}
+		l -= 0xFF
+	}
+	return out.WriteByte(byte(l))
+}
+
+func lz4EncodeLiterals(bytes []byte, token byte, anchor, literalLen int, out DataOutput) error {
+	if err := out.WriteByte(token); err != nil {
+		return err
+	}
+	// encode literal length
+	if literalLen >= 0x0F {
+		if err := lz4EncodeLen(literalLen-0x0F, out); err != nil {
+			return err
+		}
+	}
+	// encode literals
+	return out.WriteBytes(bytes[anchor : anchor+literalLen])
+}
+
+func lz4EncodeLastLiterals(bytes []byte, anchor, literalLen int, out DataOutput) error {
+	token := literalLen
+	if token > 0x0F {
+		token = 0x0F
+	}
+	return lz4EncodeLiterals(bytes, byte(token<<4), anchor, literalLen, out)
+}
+
+func lz4EncodeSequence(bytes []byte, anchor, matchRef, matchOff, matchLen int, out DataOutput) error {
+	literalLen := matchOff - anchor
+	// assert matchLen >= 4
+	// encode token
+	token := literalLen
+	if token > 0x0F {
+		token = 0x0F
+	}
+	token <<= 4
+	if matchLen-LZ4_MIN_MATCH < 0x0F {
+		token |= matchLen - LZ4_MIN_MATCH
+	} else {
+		token |= 0x0F
+	}
+	if err := lz4EncodeLiterals(bytes, byte(token), anchor, literalLen, out); err != nil {
+		return err
+	}
+
+	// encode match dec
+	matchDec := matchOff - matchRef
+	if matchDec <= 0 || matchDec >= 1<<16 {
+		panic("assert fail")
+	}
+	if err := out.WriteByte(byte(matchDec)); err != nil {
+		return err
+	}
+	if err := out.WriteByte(byte(matchDec >> 8)); err != nil {
+		return err
+	}
+
+	// encode match len
+	if matchLen >= LZ4_MIN_MATCH+0x0F {
+		return lz4EncodeLen(matchLen-0x0F-LZ4_MIN_MATCH, out)
+	}
+	return nil
+}
+
+// Hash table of the positions of the last seen 4-byte sequences.
+type LZ4HashTable struct {
+	hashLog   uint
+	hashTable []int
+}
+
+func (ht *LZ4HashTable) reset() {
+	ht.hashLog = LZ4_MEMORY_USAGE - 2
+	if ht.hashTable == nil {
+		ht.hashTable = make([]int, 1<<ht.hashLog)
+	} else {
+		for i := range ht.hashTable {
+			ht.hashTable[i] = 0
+		}
+	}
+}
+
+/*
+Compress bytes into out using at most 16KB of memory. ht shouldn't
+be shared across threads but can safely be reused.
+*/
+func LZ4Compress(bytes []byte, out DataOutput, ht *LZ4HashTable) error {
+	base, off, end := 0, 0, len(bytes)
+	anchor := off
+	off++
+
+	if len(bytes) > LZ4_LAST_LITERALS+LZ4_MIN_MATCH {
+		limit := end - LZ4_LAST_LITERALS
+		matchLimit := limit - LZ4_MIN_MATCH
+		ht.reset()
+		hashLog, hashTable := ht.hashLog, ht.hashTable
+
+	main:
+		for off < limit {
+			// find a match
+			var ref int
+			for {
+				if off >= matchLimit {
+					break main
+				}
+				v := lz4ReadInt(bytes, off)
+				h := lz4Hash(v, hashLog)
+				ref = base + hashTable[h]
+				hashTable[h] = off - base
+				if off-ref < LZ4_MAX_DISTANCE && off > ref && lz4ReadInt(bytes, ref) == v {
+					break
+				}
+				off++
+			}
+
+			// compute match length
+			matchLen := LZ4_MIN_MATCH + lz4CommonBytes(bytes, ref+LZ4_MIN_MATCH, off+LZ4_MIN_MATCH, limit)
+
+			if err := lz4EncodeSequence(bytes, anchor, ref, off, matchLen, out); err != nil {
+				return err
+			}
+			off += matchLen
+			anchor = off
+		}
+	}
+
+	// last literals
+	literalLen := end - anchor
+	// assert literalLen >= LZ4_LAST_LITERALS || literalLen == len(bytes)
+	return lz4EncodeLastLiterals(bytes, anchor, literalLen, out)
 }
@@ -0,0 +1,106 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+type bytesDataInput struct {
+	*bytes.Reader
+}
+
+func (in bytesDataInput) ReadBytes(buf []byte) error {
+	if n, _ := in.Read(buf); n != len(buf) {
+		return errors.New("EOF")
+	}
+	return nil
+}
+
+func (in bytesDataInput) ReadInt() (int32, error) {
+	panic("not implemented")
+}
+
+func (in bytesDataInput) ReadString() (string, error) {
+	panic("not implemented")
+}
+
+func TestLZ4Decompress(t *testing.T) {
+	long := bytes.Repeat([]byte("0123456789"), 30)
+	compressed := []byte{
+		// 3 literals "abc", then a match of 4+9 bytes at distance 3,
+		// which overlaps the bytes it produces
+		0x39, 'a', 'b', 'c', 3, 0,
+		// 15+255+30 literals, without match
+		0xF0, 255, 30,
+	}
+	compressed = append(compressed, long...)
+
+	expected := append([]byte("abcabcabcabcabca"), long...)
+	dest := make([]byte, len(expected)+7)
+	n, err := LZ4Decompress(bytesDataInput{bytes.NewReader(compressed)}, len(expected), dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(expected) || !bytes.Equal(expected, dest[:n]) {
+		t.Errorf("Expected %v, but was %v", string(expected), string(dest[:n]))
+	}
+
+	res, err := LZ4_DECOMPRESSOR.Decompress(bytesDataInput{bytes.NewReader(compressed)}, len(expected), 5, 20, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected[5:25], res) {
+		t.Errorf("Expected %v, but was %v", string(expected[5:25]), string(res))
+	}
+}
+
+type bytesDataOutput struct {
+	bytes.Buffer
+}
+
+func (out *bytesDataOutput) WriteBytes(buf []byte) error {
+	_, err := out.Write(buf)
+	return err
+}
+
+func (out *bytesDataOutput) WriteInt(i int32) error {
+	panic("not implemented")
+}
+
+func (out *bytesDataOutput) WriteString(s string) error {
+	panic("not implemented")
+}
+
+func TestLZ4Compress(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	random := make([]byte, 1000)
+	r.Read(random)
+	for _, data := range [][]byte{
+		nil,
+		[]byte("abc"),
+		bytes.Repeat([]byte("0123456789"), 300),
+		bytes.Repeat([]byte{'a'}, 70000),
+		random,
+		append(append([]byte("prefix "), random[:100]...), random[:100]...),
+	} {
+		compressor := COMPRESSION_MODE_FAST.NewCompressor()
+		out := new(bytesDataOutput)
+		if err := compressor.Compress(data, out); err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 1000 && out.Len() >= len(data) {
+			t.Errorf("repetitive data of %v bytes was not compressed: %v bytes", len(data), out.Len())
+		}
+
+		res, err := LZ4_DECOMPRESSOR.Decompress(bytesDataInput{bytes.NewReader(out.Bytes())}, len(data), 0, len(data), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, res) {
+			t.Errorf("round trip of %v bytes failed: got %v bytes", len(data), len(res))
+		}
+	}
+}
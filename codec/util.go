@@ -14,10 +14,37 @@ func HeaderLength(codec string) int {
 }
 
 type DataInput interface {
+	ReadByte() (b byte, err error)
+	ReadBytes(buf []byte) error
 	ReadInt() (n int32, err error)
 	ReadString() (s string, err error)
 }
 
+type DataOutput interface {
+	WriteByte(b byte) error
+	WriteBytes(buf []byte) error
+	WriteInt(i int32) error
+	WriteString(s string) error
+}
+
+/*
+Writes a codec header, which records both a string to identify the
+file and a version number. This header can be parsed and validated
+with CheckHeader().
+*/
+func WriteHeader(out DataOutput, codec string, version int32) error {
+	if len(codec) >= 128 {
+		panic(fmt.Sprintf("codec must be simple ASCII, less than 128 characters in length [got %v]", codec))
+	}
+	if err := out.WriteInt(CODEC_MAGIC); err != nil {
+		return err
+	}
+	if err := out.WriteString(codec); err != nil {
+		return err
+	}
+	return out.WriteInt(version)
+}
+
 func CheckHeader(in DataInput, codec string, minVersion, maxVersion int32) (v int32, err error) {
 	// Safety to guard against reading a bogus string:
 	actualHeader, err := in.ReadInt()
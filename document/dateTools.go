@@ -0,0 +1,215 @@
+package document
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// document/DateTools.java
+
+/*
+Provides support for converting dates to strings and vice-versa. The
+strings are structured so that lexicographic sorting orders them by
+date, which makes them suitable for use as field values and search
+terms.
+
+This class also helps you to limit the resolution of your dates. Do
+not save dates with a finer resolution than you really need, as then
+range and prefix queries will require more memory and become slower.
+
+Another approach is NumericUtils, which provides a sortable binary
+representation (prefix encoded) of numeric values, which date/time
+are. For indexing a time.Time, just get its UnixNano(), or its
+milliseconds as returned by TimeOf(), and index it as a numeric value
+with LongField.
+
+Dates are always converted in the GMT/UTC time zone, and the strings
+use the same format as Java Lucene's DateTools, e.g.
+"20040921135011123" for 2004-09-21 13:50:11.123 at millisecond
+resolution, so the fields of both remain interoperable.
+*/
+
+// document/DateTools.java/Resolution
+
+// Specifies the time granularity.
+type Resolution int
+
+const (
+	// Limit a date's resolution to year granularity.
+	RESOLUTION_YEAR = Resolution(1)
+	// Limit a date's resolution to month granularity.
+	RESOLUTION_MONTH = Resolution(2)
+	// Limit a date's resolution to day granularity.
+	RESOLUTION_DAY = Resolution(3)
+	// Limit a date's resolution to hour granularity.
+	RESOLUTION_HOUR = Resolution(4)
+	// Limit a date's resolution to minute granularity.
+	RESOLUTION_MINUTE = Resolution(5)
+	// Limit a date's resolution to second granularity.
+	RESOLUTION_SECOND = Resolution(6)
+	// Limit a date's resolution to millisecond granularity.
+	RESOLUTION_MILLISECOND = Resolution(7)
+)
+
+// Length of the formatted date of each resolution, as in the Java
+// format "yyyyMMddHHmmssSSS".
+var resolutionFormatLens = []int{0, 4, 6, 8, 10, 12, 14, 17}
+
+func (r Resolution) String() string {
+	switch r {
+	case RESOLUTION_YEAR:
+		return "year"
+	case RESOLUTION_MONTH:
+		return "month"
+	case RESOLUTION_DAY:
+		return "day"
+	case RESOLUTION_HOUR:
+		return "hour"
+	case RESOLUTION_MINUTE:
+		return "minute"
+	case RESOLUTION_SECOND:
+		return "second"
+	case RESOLUTION_MILLISECOND:
+		return "millisecond"
+	}
+	return fmt.Sprintf("Resolution(%d)", int(r))
+}
+
+/*
+Converts a time.Time to a string suitable for indexing, in the form
+yyyyMMddHHmmssSSS or shorter, depending on resolution; using GMT as
+timezone.
+*/
+func DateToString(date time.Time, resolution Resolution) string {
+	date = date.UTC()
+	ans := fmt.Sprintf("%04d", date.Year())
+	for _, v := range []struct {
+		resolution Resolution
+		format     string
+		value      int
+	}{
+		{RESOLUTION_MONTH, "%02d", int(date.Month())},
+		{RESOLUTION_DAY, "%02d", date.Day()},
+		{RESOLUTION_HOUR, "%02d", date.Hour()},
+		{RESOLUTION_MINUTE, "%02d", date.Minute()},
+		{RESOLUTION_SECOND, "%02d", date.Second()},
+		{RESOLUTION_MILLISECOND, "%03d", date.Nanosecond() / int(time.Millisecond)},
+	} {
+		if resolution < v.resolution {
+			break
+		}
+		ans += fmt.Sprintf(v.format, v.value)
+	}
+	return ans
+}
+
+/*
+Converts a millisecond time to a string suitable for indexing, in the
+form yyyyMMddHHmmssSSS or shorter, depending on resolution; using
+GMT as timezone.
+*/
+func TimeToString(millis int64, resolution Resolution) string {
+	return DateToString(DateOf(millis), resolution)
+}
+
+/*
+Converts a string produced by TimeToString() or DateToString() back
+to a time, represented as the number of milliseconds since January
+1, 1970, 00:00:00 GMT. It returns an error if dateString is not in
+the expected format.
+*/
+func StringToTime(dateString string) (int64, error) {
+	date, err := StringToDate(dateString)
+	if err != nil {
+		return 0, err
+	}
+	return TimeOf(date), nil
+}
+
+/*
+Converts a string produced by TimeToString() or DateToString() back
+to a time.Time, in UTC. It returns an error if dateString is not in
+the expected format.
+*/
+func StringToDate(dateString string) (time.Time, error) {
+	layouts := []string{"", "2006", "200601", "20060102", "2006010215",
+		"200601021504", "20060102150405", "20060102150405"}
+	for r, n := range resolutionFormatLens {
+		if r == 0 || len(dateString) != n {
+			continue
+		}
+		value, millis := dateString, uint64(0)
+		if Resolution(r) == RESOLUTION_MILLISECOND {
+			var err error
+			value = dateString[:n-3]
+			if millis, err = strconv.ParseUint(dateString[n-3:], 10, 16); err != nil {
+				break
+			}
+		}
+		date, err := time.Parse(layouts[r], value)
+		if err != nil {
+			break
+		}
+		return date.Add(time.Duration(millis) * time.Millisecond), nil
+	}
+	return time.Time{}, errors.New(fmt.Sprintf("Input is not a valid date string: %v", dateString))
+}
+
+/*
+Limit a date's resolution. For example, the date 2004-09-21 13:50:11
+will be changed to 2004-09-01 00:00:00 when using RESOLUTION_MONTH.
+The date is returned in UTC.
+*/
+func Round(date time.Time, resolution Resolution) time.Time {
+	date = date.UTC()
+	year, month, day := date.Date()
+	hour, min, sec := date.Clock()
+	nsec := date.Nanosecond() / int(time.Millisecond) * int(time.Millisecond)
+	switch resolution {
+	case RESOLUTION_YEAR:
+		month = time.January
+		fallthrough
+	case RESOLUTION_MONTH:
+		day = 1
+		fallthrough
+	case RESOLUTION_DAY:
+		hour = 0
+		fallthrough
+	case RESOLUTION_HOUR:
+		min = 0
+		fallthrough
+	case RESOLUTION_MINUTE:
+		sec = 0
+		fallthrough
+	case RESOLUTION_SECOND:
+		nsec = 0
+	}
+	return time.Date(year, month, day, hour, min, sec, nsec, time.UTC)
+}
+
+/*
+Limit a millisecond time's resolution. For example, the time of
+2004-09-21 13:50:11 will be changed to the time of 2004-09-01
+00:00:00 when using RESOLUTION_MONTH.
+*/
+func RoundTime(millis int64, resolution Resolution) int64 {
+	return TimeOf(Round(DateOf(millis), resolution))
+}
+
+// Returns the number of milliseconds since January 1, 1970, 00:00:00
+// GMT of date, as Java's Date.getTime().
+func TimeOf(date time.Time) int64 {
+	return date.Unix()*1000 + int64(date.Nanosecond())/int64(time.Millisecond)
+}
+
+// Returns the time.Time, in UTC, of the number of milliseconds since
+// January 1, 1970, 00:00:00 GMT, as Java's new Date(millis).
+func DateOf(millis int64) time.Time {
+	sec, msec := millis/1000, millis%1000
+	if msec < 0 {
+		sec, msec = sec-1, msec+1000
+	}
+	return time.Unix(sec, msec*int64(time.Millisecond)).UTC()
+}
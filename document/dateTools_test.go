@@ -0,0 +1,58 @@
+package document
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateToString(t *testing.T) {
+	date := time.Date(2004, time.September, 21, 13, 50, 11, 123456789, time.UTC)
+	for _, v := range []struct {
+		resolution Resolution
+		s          string
+		rounded    time.Time
+	}{
+		{RESOLUTION_YEAR, "2004", time.Date(2004, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{RESOLUTION_MONTH, "200409", time.Date(2004, time.September, 1, 0, 0, 0, 0, time.UTC)},
+		{RESOLUTION_DAY, "20040921", time.Date(2004, time.September, 21, 0, 0, 0, 0, time.UTC)},
+		{RESOLUTION_HOUR, "2004092113", time.Date(2004, time.September, 21, 13, 0, 0, 0, time.UTC)},
+		{RESOLUTION_MINUTE, "200409211350", time.Date(2004, time.September, 21, 13, 50, 0, 0, time.UTC)},
+		{RESOLUTION_SECOND, "20040921135011", time.Date(2004, time.September, 21, 13, 50, 11, 0, time.UTC)},
+		{RESOLUTION_MILLISECOND, "20040921135011123", time.Date(2004, time.September, 21, 13, 50, 11, 123000000, time.UTC)},
+	} {
+		if s := DateToString(date, v.resolution); s != v.s {
+			t.Errorf("Expected %v at %v, but was %v", v.s, v.resolution, s)
+		}
+		if s := DateToString(date.In(time.FixedZone("CET", 3600)), v.resolution); s != v.s {
+			t.Errorf("Date should be converted in UTC, but was %v", s)
+		}
+		if rounded := Round(date, v.resolution); !rounded.Equal(v.rounded) {
+			t.Errorf("Expected %v at %v, but was %v", v.rounded, v.resolution, rounded)
+		}
+		parsed, err := StringToDate(v.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Equal(v.rounded) {
+			t.Errorf("Expected %v, but was %v", v.rounded, parsed)
+		}
+	}
+
+	if s := TimeToString(0, RESOLUTION_MILLISECOND); s != "19700101000000000" {
+		t.Errorf("Unexpected epoch %v", s)
+	}
+	if s := TimeToString(-1, RESOLUTION_MILLISECOND); s != "19691231235959999" {
+		t.Errorf("Unexpected time before epoch %v", s)
+	}
+	if millis, err := StringToTime("19700101000000001"); millis != 1 || err != nil {
+		t.Errorf("Expected 1, but was %v (%v)", millis, err)
+	}
+	if millis := RoundTime(1095774611123, RESOLUTION_DAY); millis != 1095724800000 {
+		t.Errorf("Expected 1095724800000, but was %v", millis)
+	}
+	for _, s := range []string{"", "97", "200401011", "20041301", "2004010100000+12", "2004x"} {
+		if _, err := StringToDate(s); err == nil {
+			t.Errorf("Should not parse %v", s)
+		}
+	}
+}
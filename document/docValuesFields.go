@@ -0,0 +1,92 @@
+package document
+
+import (
+	"math"
+)
+
+func newDocValuesFieldType(docValueType DocValuesType) *FieldType {
+	ft := NewFieldType()
+	ft.SetDocValueType(docValueType)
+	ft.Freeze()
+	return ft
+}
+
+// document/NumericDocValuesField.java
+
+// Type for numeric DocValues.
+var NUMERIC_DOC_VALUES_FIELD_TYPE = newDocValuesFieldType(DOC_VALUES_TYPE_NUMERIC)
+
+/*
+Creates a field that stores a per-document int64 value for scoring,
+sorting or value retrieval. If you also need to store the value, you
+should add a separate StoredField instance.
+*/
+func NewNumericDocValuesField(name string, value int64) *Field {
+	return newField(NUMERIC_DOC_VALUES_FIELD_TYPE, name, value)
+}
+
+// document/FloatDocValuesField.java
+
+/*
+Creates a field that stores a per-document float32 value for scoring,
+sorting or value retrieval. The value is stored as the raw bits of
+the float in a numeric DocValues field.
+*/
+func NewFloatDocValuesField(name string, value float32) *Field {
+	return NewNumericDocValuesField(name, int64(math.Float32bits(value)))
+}
+
+// document/DoubleDocValuesField.java
+
+/*
+Creates a field that stores a per-document float64 value for scoring,
+sorting or value retrieval. The value is stored as the raw bits of
+the double in a numeric DocValues field.
+*/
+func NewDoubleDocValuesField(name string, value float64) *Field {
+	return NewNumericDocValuesField(name, int64(math.Float64bits(value)))
+}
+
+// document/BinaryDocValuesField.java
+
+// Type for straight bytes DocValues.
+var BINARY_DOC_VALUES_FIELD_TYPE = newDocValuesFieldType(DOC_VALUES_TYPE_BINARY)
+
+/*
+Creates a field that stores a per-document []byte value. The values
+are stored directly with no sharing, which is a good fit when the
+fields don't share (many) values, such as a title field. If values
+may be shared and sorted it's better to use SortedDocValuesField.
+*/
+func NewBinaryDocValuesField(name string, value []byte) *Field {
+	return newField(BINARY_DOC_VALUES_FIELD_TYPE, name, value)
+}
+
+// document/SortedDocValuesField.java
+
+// Type for sorted bytes DocValues.
+var SORTED_DOC_VALUES_FIELD_TYPE = newDocValuesFieldType(DOC_VALUES_TYPE_SORTED)
+
+/*
+Creates a field that stores a per-document []byte value, indexed for
+sorting. If you also need to store the value, you should add a
+separate StoredField instance.
+*/
+func NewSortedDocValuesField(name string, bytes []byte) *Field {
+	return newField(SORTED_DOC_VALUES_FIELD_TYPE, name, bytes)
+}
+
+// document/SortedSetDocValuesField.java
+
+// Type for sorted bytes DocValues.
+var SORTED_SET_DOC_VALUES_FIELD_TYPE = newDocValuesFieldType(DOC_VALUES_TYPE_SORTED_SET)
+
+/*
+Creates a field that stores a set of per-document []byte values,
+indexed for faceting, grouping and joining. Add the field once per
+value of the document. If you also need to store the values, you
+should add a separate StoredField instance.
+*/
+func NewSortedSetDocValuesField(name string, bytes []byte) *Field {
+	return newField(SORTED_SET_DOC_VALUES_FIELD_TYPE, name, bytes)
+}
@@ -0,0 +1,186 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// document/Document.java
+
+/*
+Documents are the unit of indexing and search.
+
+A Document is a set of fields. Each field has a name and a textual
+value. A field may be stored with the document, in which case it is
+returned with search hits on the document. Thus each document should
+typically contain one or more stored fields which uniquely identify
+it.
+
+Note that fields which are not stored are not available in documents
+retrieved from the index, e.g. with IndexSearcher.Doc() or
+IndexReader.Document().
+*/
+type Document struct {
+	fields []IndexableField
+}
+
+// Constructs a new document with no fields.
+func NewDocument() *Document {
+	return &Document{}
+}
+
+/*
+Adds a field to a document. Several fields may be added with the
+same name. In this case, if the fields are indexed, their text is
+treated as though appended for the purposes of search.
+*/
+func (doc *Document) Add(field IndexableField) {
+	doc.fields = append(doc.fields, field)
+}
+
+/*
+Removes field with the specified name from the document. If multiple
+fields exist with this name, this method removes the first field that
+has been added. If there is no field with the specified name, the
+document remains unchanged.
+*/
+func (doc *Document) RemoveField(name string) {
+	for i, field := range doc.fields {
+		if field.Name() == name {
+			doc.fields = append(doc.fields[:i], doc.fields[i+1:]...)
+			return
+		}
+	}
+}
+
+/*
+Removes all fields with the given name from the document. If there
+is no field with the specified name, the document remains unchanged.
+*/
+func (doc *Document) RemoveFields(name string) {
+	fields := doc.fields[:0]
+	for _, field := range doc.fields {
+		if field.Name() != name {
+			fields = append(fields, field)
+		}
+	}
+	doc.fields = fields
+}
+
+/*
+Returns an array of byte arrays for of the fields that have the name
+specified as the method parameter. This method returns an empty
+array when there are no matching fields. It never returns nil.
+*/
+func (doc *Document) BinaryValues(name string) [][]byte {
+	ans := make([][]byte, 0)
+	for _, field := range doc.fields {
+		if field.Name() == name {
+			if v := field.BinaryValue(); v != nil {
+				ans = append(ans, v)
+			}
+		}
+	}
+	return ans
+}
+
+/*
+Returns an array of bytes for the first (or only) field that has the
+name specified as the method parameter. This method will return nil
+if no binary fields with the specified name are available. There may
+be non-binary fields with the same name.
+*/
+func (doc *Document) BinaryValue(name string) []byte {
+	for _, field := range doc.fields {
+		if field.Name() == name {
+			if v := field.BinaryValue(); v != nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Returns a field with the given name if any exist in this document, or
+nil. If multiple fields exists with this name, this method returns the
+first value added.
+*/
+func (doc *Document) Field(name string) IndexableField {
+	for _, field := range doc.fields {
+		if field.Name() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+/*
+Returns an array of IndexableFields with the given name. This method
+returns an empty array when there are no matching fields. It never
+returns nil.
+*/
+func (doc *Document) FieldsByName(name string) []IndexableField {
+	ans := make([]IndexableField, 0)
+	for _, field := range doc.fields {
+		if field.Name() == name {
+			ans = append(ans, field)
+		}
+	}
+	return ans
+}
+
+/*
+Returns a List of all the fields in a document.
+
+Note that fields which are not stored are not available in documents
+retrieved from the index.
+*/
+func (doc *Document) Fields() []IndexableField {
+	return doc.fields
+}
+
+/*
+Returns an array of values of the field specified as the method
+parameter. This method returns an empty array when there are no
+matching fields. It never returns nil. For IndexableField#NumericValue()
+fields it returns the string value of the number. Binary fields are
+skipped.
+*/
+func (doc *Document) Values(name string) []string {
+	ans := make([]string, 0)
+	for _, field := range doc.fields {
+		if field.Name() == name && field.BinaryValue() == nil {
+			ans = append(ans, field.StringValue())
+		}
+	}
+	return ans
+}
+
+/*
+Returns the string value of the field with the given name if any
+exist in this document, or "". If multiple fields exist with this
+name, this method returns the first value added. Binary fields are
+skipped.
+*/
+func (doc *Document) Get(name string) string {
+	for _, field := range doc.fields {
+		if field.Name() == name && field.BinaryValue() == nil {
+			return field.StringValue()
+		}
+	}
+	return ""
+}
+
+func (doc *Document) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("Document<")
+	for i, field := range doc.fields {
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		fmt.Fprint(&buf, field)
+	}
+	buf.WriteString(">")
+	return buf.String()
+}
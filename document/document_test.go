@@ -0,0 +1,214 @@
+package document
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	doc := NewDocument()
+	doc.Add(NewStoredFieldFromString("keyword", "test1"))
+	doc.Add(NewStoredFieldFromString("keyword", "test2"))
+	doc.Add(NewStoredFieldFromBytes("binary", []byte("bytes1")))
+	doc.Add(NewStoredFieldFromBytes("binary", []byte("bytes2")))
+	doc.Add(NewStoredFieldFromLong("number", 7))
+
+	if n := len(doc.Fields()); n != 5 {
+		t.Errorf("Expected 5 fields, but was %v", n)
+	}
+	if v := doc.Values("keyword"); len(v) != 2 || v[0] != "test1" || v[1] != "test2" {
+		t.Errorf("Unexpected values %v", v)
+	}
+	if v := doc.BinaryValues("binary"); len(v) != 2 || string(v[1]) != "bytes2" {
+		t.Errorf("Unexpected binary values %v", v)
+	}
+	// binary fields have no string value
+	if v := doc.Values("binary"); len(v) != 0 {
+		t.Errorf("Unexpected values %v", v)
+	}
+	if v := doc.BinaryValue("keyword"); v != nil {
+		t.Errorf("Unexpected binary value %v", v)
+	}
+	if v := doc.Get("number"); v != "7" {
+		t.Errorf("Expected '7', but was '%v'", v)
+	}
+	if v := doc.Field("number").NumericValue(); v != int64(7) {
+		t.Errorf("Expected 7, but was %v", v)
+	}
+
+	doc.RemoveField("keyword")
+	if v := doc.Values("keyword"); len(v) != 1 || v[0] != "test2" {
+		t.Errorf("Unexpected values %v", v)
+	}
+	doc.RemoveFields("binary")
+	if n := len(doc.FieldsByName("binary")); n != 0 {
+		t.Errorf("Expected no binary fields, but was %v", n)
+	}
+	doc.RemoveFields("unknown")
+	if n := len(doc.Fields()); n != 2 {
+		t.Errorf("Expected 2 fields, but was %v", n)
+	}
+	if doc.Field("unknown") != nil || doc.Get("unknown") != "" {
+		t.Error("Unknown field should not exist")
+	}
+}
+
+func TestFieldType(t *testing.T) {
+	ft := NewFieldType()
+	if !ft.Tokenized() || ft.IndexOptions() != INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS {
+		t.Errorf("Unexpected defaults: %v, %v", ft.Tokenized(), ft.IndexOptions())
+	}
+	ft.SetIndexed(true)
+	ft.SetStored(true)
+	ft.SetStoreTermVectors(true)
+	if v := ft.String(); v != "stored,indexed,tokenized,termVector" {
+		t.Errorf("Unexpected type %v", v)
+	}
+	ft.Freeze()
+	copied := NewFieldTypeFrom(ft)
+	copied.SetIndexOptions(INDEX_OPT_DOCS_AND_FREQS)
+	if v := copied.String(); v != "stored,indexed,tokenized,termVector,indexOptions=DOCS_AND_FREQS" {
+		t.Errorf("Unexpected type %v", v)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Frozen type should not be changed")
+		}
+	}()
+	ft.SetTokenized(false)
+}
+
+func TestStringField(t *testing.T) {
+	f := NewStringField("id", "0001", STORE_YES)
+	if v := fmt.Sprint(f.FieldType()); v != "stored,indexed,omitNorms,indexOptions=DOCS_ONLY" {
+		t.Errorf("Unexpected type %v", v)
+	}
+	if f.StringValue() != "0001" {
+		t.Errorf("Unexpected value %v", f.StringValue())
+	}
+	if f = NewStringField("id", "0001", STORE_NO); f.FieldType().Stored() {
+		t.Error("Field should not be stored")
+	}
+}
+
+func TestFieldBoost(t *testing.T) {
+	ft := NewFieldType()
+	ft.SetIndexed(true)
+	ft.Freeze()
+	f := NewField("title", "golucene", ft)
+	if f.Boost() != 1 {
+		t.Errorf("Default boost should be 1, but was %v", f.Boost())
+	}
+	f.SetBoost(2)
+	if f.Boost() != 2 {
+		t.Errorf("Boost should be 2, but was %v", f.Boost())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Should not set a boost on a field omitting norms")
+		}
+	}()
+	NewStringField("id", "0001", STORE_NO).SetBoost(2)
+}
+
+func TestFieldTokenStream(t *testing.T) {
+	f := NewStringField("id", "0001", STORE_YES)
+	ts, err := f.TokenStream(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	termAtt := ts.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	posIncrAtt := ts.Attributes().Add("PositionIncrementAttribute").(analysis.PositionIncrementAttribute)
+	offsetAtt := ts.Attributes().Add("OffsetAttribute").(analysis.OffsetAttribute)
+	if err = ts.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	var terms []string
+	for {
+		ok, err := ts.IncrementToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		terms = append(terms, termAtt.String())
+		if posIncrAtt.PositionIncrement() != 1 || offsetAtt.StartOffset() != 0 || offsetAtt.EndOffset() != 4 {
+			t.Errorf("Unexpected token %v", ts.Attributes())
+		}
+	}
+	if len(terms) != 1 || terms[0] != "0001" {
+		t.Errorf("Value should be a single token, but was %v", terms)
+	}
+	if ts, err = NewStoredFieldFromString("body", "text").TokenStream(nil); ts != nil || err != nil {
+		t.Errorf("Stored-only field should have no TokenStream, but was %v (%v)", ts, err)
+	}
+}
+
+func TestNumericField(t *testing.T) {
+	f := NewIntField("price", 42, STORE_YES)
+	if v := fmt.Sprint(f.FieldType()); v != "stored,indexed,tokenized,omitNorms,indexOptions=DOCS_ONLY,numericType=INT,numericPrecisionStep=4" {
+		t.Errorf("Unexpected type %v", v)
+	}
+	if f.NumericValue() != 42 {
+		t.Errorf("Unexpected value %v", f.NumericValue())
+	}
+	for _, v := range []struct {
+		field     *Field
+		numTokens int
+	}{
+		{f, 8},
+		{NewLongField("time", 1<<40, STORE_NO), 16},
+		{NewFloatField("weight", 0.5, STORE_NO), 8},
+		{NewDoubleField("ratio", 0.25, STORE_NO), 16},
+	} {
+		ts, err := v.field.TokenStream(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ts.Reset(); err != nil {
+			t.Fatal(err)
+		}
+		var count int
+		for {
+			ok, err := ts.IncrementToken()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				break
+			}
+			count++
+		}
+		if count != v.numTokens {
+			t.Errorf("Expected %v tokens for %v, but was %v", v.numTokens, v.field, count)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Should not create an int field of a long type")
+		}
+	}()
+	NewIntFieldWithType("price", 42, LONG_FIELD_TYPE_STORED)
+}
+
+func TestTextField(t *testing.T) {
+	f := NewTextField("body", "some text", STORE_YES)
+	if v := fmt.Sprint(f.FieldType()); v != "stored,indexed,tokenized" {
+		t.Errorf("Unexpected type %v", v)
+	}
+	if f = NewTextField("body", "some text", STORE_NO); f.FieldType().Stored() {
+		t.Error("Field should not be stored")
+	}
+	ts := newStringTokenStream("some text")
+	f = NewTextFieldFromTokenStream("body", ts)
+	if v, err := f.TokenStream(nil); v != ts || err != nil {
+		t.Errorf("Field should be indexed with its TokenStream, but was %v (%v)", v, err)
+	}
+	if fmt.Sprint(NewStoredFieldFromString("title", "text").FieldType()) != "stored" {
+		t.Error("Stored field should only be stored")
+	}
+}
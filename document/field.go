@@ -0,0 +1,418 @@
+package document
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis"
+	"strings"
+)
+
+// index/IndexableField.java
+
+/*
+Represents a single field for indexing. IndexWriter consumes
+[]IndexableField as a document.
+*/
+type IndexableField interface {
+	// Field name
+	Name() string
+	// IndexableFieldType describing the properties of this field.
+	FieldType() IndexableFieldType
+	// Non-nil if this field has a binary value
+	BinaryValue() []byte
+	// Non-empty if this field has a string value, or a numeric value
+	// in its string form
+	StringValue() string
+	// Non-nil if this field has a numeric value, which is one of int,
+	// int64, float32 or float64
+	NumericValue() interface{}
+	/*
+		Returns the field's index-time boost. Only fields can have an
+		index-time boost, if you want to simulate a "document boost",
+		then you must pre-multiply it across all the relevant fields
+		yourself.
+
+		The boost is used to compute the norm factor for the field. By
+		default, in the Similarity.ComputeNorm() method, the boost value
+		is multiplied by the length normalization factor and then
+		rounded by DefaultSimilarity.EncodeNormValue() before it is
+		stored in the index. One should attempt to ensure that this
+		product does not overflow the range of that encoding.
+
+		It is illegal to return a boost other than 1 if the field is not
+		indexed, or if it omits norms.
+	*/
+	Boost() float32
+	/*
+		Creates the TokenStream used for indexing this field, or nil if
+		the field is not indexed. If appropriate, implementations should
+		use the given Analyzer, which may be nil, to create the
+		TokenStream. IndexWriter resets the stream before consuming it,
+		and closes it once the document is inverted.
+	*/
+	TokenStream(analyzer analysis.Analyzer) (analysis.TokenStream, error)
+}
+
+// document/Field.java
+
+/*
+Expert: directly create a field for a document. Most users should
+use one of the sugar constructors: NewStringField(), NewTextField(),
+the numeric fields, e.g. NewIntField(), or the stored fields, e.g.
+NewStoredFieldFromString().
+
+A field is a section of a Document. Each field has two parts, a name
+and a value. Values may be a string, a []byte or a number.
+*/
+type Field struct {
+	// Field's type
+	_type IndexableFieldType
+	// Field's name
+	name string
+	// Field's value
+	fieldsData interface{}
+	// Pre-analyzed tokenStream for indexed fields; this is separate
+	// from fieldsData because you are allowed to have both; e.g. maybe
+	// field has a string value but you customize how it's tokenized
+	tokenStream analysis.TokenStream
+	// Field's boost
+	boost float32
+	// Token stream reused to index the value of a numeric field
+	numericTokenStream *analysis.NumericTokenStream
+}
+
+func newField(ft IndexableFieldType, name string, value interface{}) *Field {
+	return &Field{_type: ft, name: name, fieldsData: value, boost: 1}
+}
+
+/*
+Create field with string value. It panics if the field type is
+neither indexed nor stored, if it stores term vectors without being
+indexed, or if value is empty.
+*/
+func NewField(name, value string, ft IndexableFieldType) *Field {
+	if name == "" {
+		panic("name cannot be empty")
+	}
+	if value == "" {
+		panic("value cannot be empty")
+	}
+	if !ft.Stored() && !ft.Indexed() {
+		panic("it doesn't make sense to have a field that is neither indexed nor stored")
+	}
+	if !ft.Indexed() && ft.StoreTermVectors() {
+		panic("cannot store term vector information for a field that is not indexed")
+	}
+	return newField(ft, name, value)
+}
+
+/*
+Create field with TokenStream value. It panics if the field type is
+stored, or is not indexed and tokenized.
+*/
+func NewFieldFromTokenStream(name string, tokenStream analysis.TokenStream,
+	ft IndexableFieldType) *Field {
+	if name == "" {
+		panic("name cannot be empty")
+	}
+	if tokenStream == nil {
+		panic("tokenStream cannot be nil")
+	}
+	if !ft.Indexed() || !ft.Tokenized() {
+		panic("TokenStream fields must be indexed and tokenized")
+	}
+	if ft.Stored() {
+		panic("TokenStream fields cannot be stored")
+	}
+	f := newField(ft, name, nil)
+	f.tokenStream = tokenStream
+	return f
+}
+
+func (f *Field) Name() string {
+	return f.name
+}
+
+func (f *Field) FieldType() IndexableFieldType {
+	return f._type
+}
+
+func (f *Field) BinaryValue() []byte {
+	if v, ok := f.fieldsData.([]byte); ok {
+		return v
+	}
+	return nil
+}
+
+/*
+The value of the field as a string, or "" if the field has a binary
+value. If the field is numeric, its string form is returned.
+*/
+func (f *Field) StringValue() string {
+	switch v := f.fieldsData.(type) {
+	case string:
+		return v
+	case int, int64, float32, float64:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+func (f *Field) NumericValue() interface{} {
+	switch v := f.fieldsData.(type) {
+	case int, int64, float32, float64:
+		return v
+	}
+	return nil
+}
+
+func (f *Field) Boost() float32 {
+	return f.boost
+}
+
+/*
+Sets the boost factor on this field. It panics if the field is not
+indexed, or omits norms.
+*/
+func (f *Field) SetBoost(boost float32) {
+	if boost != 1 && (!f._type.Indexed() || f._type.OmitNorms()) {
+		panic("You cannot set an index-time boost on an unindexed field, or one that omits norms")
+	}
+	f.boost = boost
+}
+
+/*
+Returns the TokenStream of the field if it was created with one, or
+indexes its numeric value as trie terms if it is numeric. Otherwise
+the string value of the field, or the string form of its numeric
+value, is analyzed with analyzer if the field is tokenized, or
+indexed as a single token if it isn't, or if analyzer is nil.
+*/
+func (f *Field) TokenStream(analyzer analysis.Analyzer) (analysis.TokenStream, error) {
+	if !f._type.Indexed() {
+		return nil, nil
+	}
+	if ft, ok := f._type.(*FieldType); ok && ft.NumericType() != 0 {
+		return f.numericValueTokenStream(ft)
+	}
+	if f.tokenStream != nil {
+		return f.tokenStream, nil
+	}
+	value := f.StringValue()
+	if !f._type.Tokenized() {
+		if value == "" {
+			return nil, errors.New("Non-Tokenized Fields must have a String value")
+		}
+		return newStringTokenStream(value), nil
+	}
+	if value != "" {
+		if analyzer == nil {
+			return newStringTokenStream(value), nil
+		}
+		return analyzer.TokenStream(f.name, strings.NewReader(value))
+	}
+	return nil, errors.New("Field must have either TokenStream, String or Number value")
+}
+
+// Returns the reused NumericTokenStream of the field, initialized with
+// its numeric value.
+func (f *Field) numericValueTokenStream(ft *FieldType) (analysis.TokenStream, error) {
+	ts := f.numericTokenStream
+	if ts == nil || ts.PrecisionStep() != ft.NumericPrecisionStep() {
+		ts = analysis.NewNumericTokenStream(ft.NumericPrecisionStep())
+		f.numericTokenStream = ts
+	}
+	switch v := f.fieldsData.(type) {
+	case int:
+		ts.SetIntValue(int32(v))
+	case int64:
+		ts.SetLongValue(v)
+	case float32:
+		ts.SetFloatValue(v)
+	case float64:
+		ts.SetDoubleValue(v)
+	default:
+		return nil, errors.New(fmt.Sprintf(
+			"Numeric fields of type %v must have a numeric value", ft.NumericType()))
+	}
+	return ts, nil
+}
+
+func (f *Field) String() string {
+	if v, ok := f.fieldsData.([]byte); ok {
+		return fmt.Sprintf("%v<%v:%v bytes>", f._type, f.name, len(v))
+	}
+	return fmt.Sprintf("%v<%v:%v>", f._type, f.name, f.fieldsData)
+}
+
+// document/Field.java/StringTokenStream
+
+// A TokenStream of a single token, the whole value of a field.
+type stringTokenStream struct {
+	*analysis.TokenStreamImpl
+	termAttribute   analysis.CharTermAttribute
+	offsetAttribute analysis.OffsetAttribute
+	value           string
+	used            bool
+}
+
+func newStringTokenStream(value string) *stringTokenStream {
+	ans := &stringTokenStream{TokenStreamImpl: analysis.NewTokenStream(), value: value}
+	ans.termAttribute = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.offsetAttribute = ans.Attributes().Add("OffsetAttribute").(analysis.OffsetAttribute)
+	return ans
+}
+
+func (ts *stringTokenStream) Reset() error {
+	ts.used = false
+	return nil
+}
+
+func (ts *stringTokenStream) IncrementToken() (bool, error) {
+	if ts.used {
+		return false, nil
+	}
+	ts.Attributes().Clear()
+	ts.termAttribute.Append(ts.value)
+	ts.offsetAttribute.SetOffset(0, len(ts.value))
+	ts.used = true
+	return true, nil
+}
+
+func (ts *stringTokenStream) End() error {
+	// set final offset
+	finalOffset := len(ts.value)
+	ts.offsetAttribute.SetOffset(finalOffset, finalOffset)
+	return nil
+}
+
+func (ts *stringTokenStream) Close() error {
+	ts.value = ""
+	return nil
+}
+
+// document/StoredField.java
+
+// Type for a stored-only field.
+var STORED_FIELD_TYPE = func() *FieldType {
+	ft := NewFieldType()
+	ft.SetStored(true)
+	ft.Freeze()
+	return ft
+}()
+
+// Create a stored-only field with the given binary value.
+func NewStoredFieldFromBytes(name string, value []byte) *Field {
+	return newField(STORED_FIELD_TYPE, name, value)
+}
+
+// Create a stored-only field with the given string value.
+func NewStoredFieldFromString(name, value string) *Field {
+	return newField(STORED_FIELD_TYPE, name, value)
+}
+
+// Create a stored-only field with the given integer value.
+func NewStoredFieldFromInt(name string, value int) *Field {
+	return newField(STORED_FIELD_TYPE, name, value)
+}
+
+// Create a stored-only field with the given long value.
+func NewStoredFieldFromLong(name string, value int64) *Field {
+	return newField(STORED_FIELD_TYPE, name, value)
+}
+
+// Create a stored-only field with the given float value.
+func NewStoredFieldFromFloat(name string, value float32) *Field {
+	return newField(STORED_FIELD_TYPE, name, value)
+}
+
+// Create a stored-only field with the given double value.
+func NewStoredFieldFromDouble(name string, value float64) *Field {
+	return newField(STORED_FIELD_TYPE, name, value)
+}
+
+// document/Field.java/Store
+
+// Specifies whether and how a field should be stored.
+type Store int
+
+const (
+	// Store the original field value in the index. This is useful for
+	// short texts like a document's title which should be displayed
+	// with the results. The value is stored in its original form, i.e.
+	// no analyzer is used before it is stored.
+	STORE_YES = Store(1)
+	// Do not store the field value in the index.
+	STORE_NO = Store(2)
+)
+
+// document/StringField.java
+
+func newStringFieldType(stored bool) *FieldType {
+	ft := NewFieldType()
+	ft.SetIndexed(true)
+	ft.SetOmitNorms(true)
+	ft.SetIndexOptions(INDEX_OPT_DOCS_ONLY)
+	ft.SetStored(stored)
+	ft.SetTokenized(false)
+	ft.Freeze()
+	return ft
+}
+
+var (
+	// Indexed, not tokenized, omits norms, indexes DOCS_ONLY, not
+	// stored.
+	STRING_FIELD_TYPE_NOT_STORED = newStringFieldType(false)
+	// Indexed, not tokenized, omits norms, indexes DOCS_ONLY, stored
+	STRING_FIELD_TYPE_STORED = newStringFieldType(true)
+)
+
+/*
+Creates a field that is indexed but not tokenized: the entire string
+value is indexed as a single token. For example this might be used
+for a 'country' field or an 'id' field, or any field that you intend
+to use for sorting or access through the field cache.
+*/
+func NewStringField(name, value string, stored Store) *Field {
+	if stored == STORE_YES {
+		return NewField(name, value, STRING_FIELD_TYPE_STORED)
+	}
+	return NewField(name, value, STRING_FIELD_TYPE_NOT_STORED)
+}
+
+// document/TextField.java
+
+func newTextFieldType(stored bool) *FieldType {
+	ft := NewFieldType()
+	ft.SetIndexed(true)
+	ft.SetTokenized(true)
+	ft.SetStored(stored)
+	ft.Freeze()
+	return ft
+}
+
+var (
+	// Indexed, tokenized, not stored.
+	TEXT_FIELD_TYPE_NOT_STORED = newTextFieldType(false)
+	// Indexed, tokenized, stored.
+	TEXT_FIELD_TYPE_STORED = newTextFieldType(true)
+)
+
+/*
+Creates a field that is indexed and tokenized, without term vectors.
+For example this would be used on a 'body' field, that contains the
+bulk of a document's text. The value is analyzed by the Analyzer of
+IndexWriter; use NewTextFieldFromTokenStream() to index pre-analyzed
+tokens.
+*/
+func NewTextField(name, value string, stored Store) *Field {
+	if stored == STORE_YES {
+		return NewField(name, value, TEXT_FIELD_TYPE_STORED)
+	}
+	return NewField(name, value, TEXT_FIELD_TYPE_NOT_STORED)
+}
+
+// Creates a new un-stored TextField with TokenStream value.
+func NewTextFieldFromTokenStream(name string, stream analysis.TokenStream) *Field {
+	return NewFieldFromTokenStream(name, stream, TEXT_FIELD_TYPE_NOT_STORED)
+}
@@ -0,0 +1,418 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// index/IndexableFieldType.java
+
+// Describes the properties of a field.
+type IndexableFieldType interface {
+	// True if this field should be indexed (inverted)
+	Indexed() bool
+	// True if the field's value should be stored
+	Stored() bool
+	// True if this field's value should be analyzed by the Analyzer.
+	Tokenized() bool
+	// True if this field's indexed form should be also stored into
+	// term vectors.
+	StoreTermVectors() bool
+	// True if this field's token character offsets should also be
+	// stored into term vectors.
+	StoreTermVectorOffsets() bool
+	// True if this field's token positions should also be stored
+	// into the term vectors.
+	StoreTermVectorPositions() bool
+	// True if this field's token payloads should also be stored into
+	// the term vectors.
+	StoreTermVectorPayloads() bool
+	// True if normalization values should be omitted for the field.
+	OmitNorms() bool
+	// IndexOptions, describing what should be recorded into the
+	// inverted index.
+	IndexOptions() IndexOptions
+	// DocValues DocValuesType: if non-zero then the field's value will
+	// be indexed into docValues.
+	DocValueType() DocValuesType
+}
+
+// index/FieldInfo.java/IndexOptions
+
+/*
+Controls how much information is stored in the postings lists. The
+options are ordered, each including the information of the previous
+ones.
+*/
+type IndexOptions int
+
+const (
+	/*
+		Only documents are indexed: term frequencies and positions are
+		omitted. Phrase and other positional queries on the field will
+		return an error, and scoring will behave as if any term in the
+		document appears only once.
+	*/
+	INDEX_OPT_DOCS_ONLY = IndexOptions(1)
+	/*
+		Only documents and term frequencies are indexed: positions are
+		omitted. This enables normal scoring, except phrase and other
+		positional queries will return an error.
+	*/
+	INDEX_OPT_DOCS_AND_FREQS = IndexOptions(2)
+	/*
+		Indexes documents, frequencies and positions. This is a typical
+		default for full-text search: full scoring is enabled and
+		positional queries are supported.
+	*/
+	INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS = IndexOptions(3)
+	/*
+		Indexes documents, frequencies, positions and offsets. Character
+		offsets are encoded alongside the positions.
+	*/
+	INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS = IndexOptions(4)
+)
+
+func (opt IndexOptions) String() string {
+	switch opt {
+	case INDEX_OPT_DOCS_ONLY:
+		return "DOCS_ONLY"
+	case INDEX_OPT_DOCS_AND_FREQS:
+		return "DOCS_AND_FREQS"
+	case INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS:
+		return "DOCS_AND_FREQS_AND_POSITIONS"
+	case INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS:
+		return "DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS"
+	}
+	return fmt.Sprintf("IndexOptions(%d)", int(opt))
+}
+
+// index/FieldInfo.java/DocValuesType
+
+/*
+DocValues types. Note that DocValues is strongly typed, so a field
+cannot have different types across different documents.
+*/
+type DocValuesType int
+
+const (
+	// A per-document numeric value.
+	DOC_VALUES_TYPE_NUMERIC = DocValuesType(1)
+	// A per-document []byte.
+	DOC_VALUES_TYPE_BINARY = DocValuesType(2)
+	/*
+		A pre-sorted []byte. Fields with this type only store distinct
+		byte values and store an additional offset pointer per document
+		to dereference the shared []byte. The stored []byte is presorted
+		and allows access via document id, ordinal and by-value.
+	*/
+	DOC_VALUES_TYPE_SORTED = DocValuesType(3)
+	/*
+		A pre-sorted set of []byte. Fields with this type only store
+		distinct byte values and store additional offset pointers per
+		document to dereference the shared []byte. The stored []byte is
+		presorted and allows access via document id, ordinal and
+		by-value.
+	*/
+	DOC_VALUES_TYPE_SORTED_SET = DocValuesType(4)
+)
+
+func (t DocValuesType) String() string {
+	switch t {
+	case DOC_VALUES_TYPE_NUMERIC:
+		return "NUMERIC"
+	case DOC_VALUES_TYPE_BINARY:
+		return "BINARY"
+	case DOC_VALUES_TYPE_SORTED:
+		return "SORTED"
+	case DOC_VALUES_TYPE_SORTED_SET:
+		return "SORTED_SET"
+	}
+	return fmt.Sprintf("DocValuesType(%d)", int(t))
+}
+
+// document/FieldType.java/NumericType
+
+// Data type of the numeric value
+type NumericType int
+
+const (
+	// 32-bit integer numeric type
+	NUMERIC_TYPE_INT = NumericType(1)
+	// 64-bit long numeric type
+	NUMERIC_TYPE_LONG = NumericType(2)
+	// 32-bit float numeric type
+	NUMERIC_TYPE_FLOAT = NumericType(3)
+	// 64-bit double numeric type
+	NUMERIC_TYPE_DOUBLE = NumericType(4)
+)
+
+func (t NumericType) String() string {
+	switch t {
+	case NUMERIC_TYPE_INT:
+		return "INT"
+	case NUMERIC_TYPE_LONG:
+		return "LONG"
+	case NUMERIC_TYPE_FLOAT:
+		return "FLOAT"
+	case NUMERIC_TYPE_DOUBLE:
+		return "DOUBLE"
+	}
+	return fmt.Sprintf("NumericType(%d)", int(t))
+}
+
+// document/FieldType.java
+
+// Describes the properties of a field.
+type FieldType struct {
+	indexed                  bool
+	stored                   bool
+	tokenized                bool
+	storeTermVectors         bool
+	storeTermVectorOffsets   bool
+	storeTermVectorPositions bool
+	storeTermVectorPayloads  bool
+	omitNorms                bool
+	indexOptions             IndexOptions
+	docValueType             DocValuesType
+	numericType              NumericType
+	numericPrecisionStep     int
+	frozen                   bool
+}
+
+/*
+Create a new FieldType with default properties: neither indexed nor
+stored, tokenized, and indexing documents, frequencies and positions
+once indexed.
+*/
+func NewFieldType() *FieldType {
+	return &FieldType{
+		tokenized:            true,
+		indexOptions:         INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS,
+		numericPrecisionStep: util.NUMERIC_PRECISION_STEP_DEFAULT,
+	}
+}
+
+// Create a new mutable FieldType with all of the properties from ref
+func NewFieldTypeFrom(ref IndexableFieldType) *FieldType {
+	ans := &FieldType{
+		indexed:                  ref.Indexed(),
+		stored:                   ref.Stored(),
+		tokenized:                ref.Tokenized(),
+		storeTermVectors:         ref.StoreTermVectors(),
+		storeTermVectorOffsets:   ref.StoreTermVectorOffsets(),
+		storeTermVectorPositions: ref.StoreTermVectorPositions(),
+		storeTermVectorPayloads:  ref.StoreTermVectorPayloads(),
+		omitNorms:                ref.OmitNorms(),
+		indexOptions:             ref.IndexOptions(),
+		docValueType:             ref.DocValueType(),
+		numericPrecisionStep:     util.NUMERIC_PRECISION_STEP_DEFAULT,
+	}
+	if ft, ok := ref.(*FieldType); ok {
+		ans.numericType = ft.numericType
+		ans.numericPrecisionStep = ft.numericPrecisionStep
+	}
+	return ans
+}
+
+func (ft *FieldType) checkIfFrozen() {
+	if ft.frozen {
+		panic("this FieldType is already frozen and cannot be changed")
+	}
+}
+
+/*
+Prevents future changes. Note, it is recommended that this is called
+once the FieldType's properties have been set, to prevent unintentional
+state changes.
+*/
+func (ft *FieldType) Freeze() {
+	ft.frozen = true
+}
+
+func (ft *FieldType) Indexed() bool {
+	return ft.indexed
+}
+
+// Set to true to index (invert) this field.
+func (ft *FieldType) SetIndexed(value bool) {
+	ft.checkIfFrozen()
+	ft.indexed = value
+}
+
+func (ft *FieldType) Stored() bool {
+	return ft.stored
+}
+
+// Set to true to store this field.
+func (ft *FieldType) SetStored(value bool) {
+	ft.checkIfFrozen()
+	ft.stored = value
+}
+
+func (ft *FieldType) Tokenized() bool {
+	return ft.tokenized
+}
+
+// Set to true to tokenize this field's contents. Default is true.
+func (ft *FieldType) SetTokenized(value bool) {
+	ft.checkIfFrozen()
+	ft.tokenized = value
+}
+
+func (ft *FieldType) StoreTermVectors() bool {
+	return ft.storeTermVectors
+}
+
+// Set to true if this field's indexed form should be also stored
+// into term vectors.
+func (ft *FieldType) SetStoreTermVectors(value bool) {
+	ft.checkIfFrozen()
+	ft.storeTermVectors = value
+}
+
+func (ft *FieldType) StoreTermVectorOffsets() bool {
+	return ft.storeTermVectorOffsets
+}
+
+// Set to true to also store token character offsets into the term
+// vector for this field.
+func (ft *FieldType) SetStoreTermVectorOffsets(value bool) {
+	ft.checkIfFrozen()
+	ft.storeTermVectorOffsets = value
+}
+
+func (ft *FieldType) StoreTermVectorPositions() bool {
+	return ft.storeTermVectorPositions
+}
+
+// Set to true to also store token positions into the term vector for
+// this field.
+func (ft *FieldType) SetStoreTermVectorPositions(value bool) {
+	ft.checkIfFrozen()
+	ft.storeTermVectorPositions = value
+}
+
+func (ft *FieldType) StoreTermVectorPayloads() bool {
+	return ft.storeTermVectorPayloads
+}
+
+// Set to true to also store token payloads into the term vector for
+// this field.
+func (ft *FieldType) SetStoreTermVectorPayloads(value bool) {
+	ft.checkIfFrozen()
+	ft.storeTermVectorPayloads = value
+}
+
+func (ft *FieldType) OmitNorms() bool {
+	return ft.omitNorms
+}
+
+// Set to true to omit normalization values for the field.
+func (ft *FieldType) SetOmitNorms(value bool) {
+	ft.checkIfFrozen()
+	ft.omitNorms = value
+}
+
+func (ft *FieldType) IndexOptions() IndexOptions {
+	return ft.indexOptions
+}
+
+// Sets the indexing options for the field. Default is
+// INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS.
+func (ft *FieldType) SetIndexOptions(value IndexOptions) {
+	ft.checkIfFrozen()
+	ft.indexOptions = value
+}
+
+func (ft *FieldType) DocValueType() DocValuesType {
+	return ft.docValueType
+}
+
+// Set's the field's DocValuesType, or 0 if no DocValues should be
+// stored.
+func (ft *FieldType) SetDocValueType(value DocValuesType) {
+	ft.checkIfFrozen()
+	ft.docValueType = value
+}
+
+// NumericType: if non-zero then the field's value will be indexed
+// numerically so that NumericRangeQuery can be used at search time.
+func (ft *FieldType) NumericType() NumericType {
+	return ft.numericType
+}
+
+// Specifies the field's numeric type, or 0 if the field's value is
+// not numeric. Default is 0.
+func (ft *FieldType) SetNumericType(value NumericType) {
+	ft.checkIfFrozen()
+	ft.numericType = value
+}
+
+/*
+Precision step for numeric field. This has no effect if NumericType()
+returns 0. Default is util.NUMERIC_PRECISION_STEP_DEFAULT.
+*/
+func (ft *FieldType) NumericPrecisionStep() int {
+	return ft.numericPrecisionStep
+}
+
+// Sets the numeric precision step for the field. It panics if
+// precisionStep is less than 1.
+func (ft *FieldType) SetNumericPrecisionStep(precisionStep int) {
+	ft.checkIfFrozen()
+	if precisionStep < 1 {
+		panic(fmt.Sprintf("precisionStep must be >= 1 (got %v)", precisionStep))
+	}
+	ft.numericPrecisionStep = precisionStep
+}
+
+// Prints a Field for human consumption.
+func (ft *FieldType) String() string {
+	var buf bytes.Buffer
+	if ft.stored {
+		buf.WriteString("stored")
+	}
+	if ft.indexed {
+		if buf.Len() > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("indexed")
+		if ft.tokenized {
+			buf.WriteString(",tokenized")
+		}
+		if ft.storeTermVectors {
+			buf.WriteString(",termVector")
+		}
+		if ft.storeTermVectorOffsets {
+			buf.WriteString(",termVectorOffsets")
+		}
+		if ft.storeTermVectorPositions {
+			buf.WriteString(",termVectorPosition")
+			if ft.storeTermVectorPayloads {
+				buf.WriteString(",termVectorPayloads")
+			}
+		}
+		if ft.omitNorms {
+			buf.WriteString(",omitNorms")
+		}
+		if ft.indexOptions != INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS {
+			buf.WriteString(",indexOptions=")
+			buf.WriteString(ft.indexOptions.String())
+		}
+		if ft.numericType != 0 {
+			buf.WriteString(",numericType=")
+			buf.WriteString(ft.numericType.String())
+			buf.WriteString(fmt.Sprintf(",numericPrecisionStep=%v", ft.numericPrecisionStep))
+		}
+	}
+	if ft.docValueType != 0 {
+		if buf.Len() > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("docValueType=")
+		buf.WriteString(ft.docValueType.String())
+	}
+	return buf.String()
+}
@@ -0,0 +1,154 @@
+package document
+
+import (
+	"fmt"
+)
+
+/*
+Numeric fields are indexed as trie terms by a NumericTokenStream, at
+the precision step of their type, so range queries over them only
+need to visit a few terms of each precision, see
+util.SplitLongRange(). Their values may also be stored.
+
+By default, each value is indexed with a precision step of
+util.NUMERIC_PRECISION_STEP_DEFAULT (4). Use a custom FieldType with
+SetNumericPrecisionStep() to change it: smaller steps index more
+terms, and make range queries faster; a step larger than the value's
+bit size indexes only the full precision value, which makes the index
+smaller, and range queries slower. Range queries must use the same
+precision step as the field.
+
+These fields omit norms and index documents only, as neither is
+useful for numeric values.
+*/
+func newNumericFieldType(numericType NumericType, stored bool) *FieldType {
+	ft := NewFieldType()
+	ft.SetIndexed(true)
+	ft.SetTokenized(true)
+	ft.SetOmitNorms(true)
+	ft.SetIndexOptions(INDEX_OPT_DOCS_ONLY)
+	ft.SetNumericType(numericType)
+	ft.SetStored(stored)
+	ft.Freeze()
+	return ft
+}
+
+func newNumericField(name string, value interface{}, ft *FieldType, numericType NumericType) *Field {
+	if name == "" {
+		panic("name cannot be empty")
+	}
+	if ft.NumericType() != numericType {
+		panic(fmt.Sprintf("type.numericType() must be %v but got %v", numericType, ft.NumericType()))
+	}
+	return newField(ft, name, value)
+}
+
+// document/IntField.java
+
+var (
+	// Type for an IntField that is not stored: normalization factors,
+	// frequencies, and positions are omitted.
+	INT_FIELD_TYPE_NOT_STORED = newNumericFieldType(NUMERIC_TYPE_INT, false)
+	// Type for a stored IntField: normalization factors, frequencies,
+	// and positions are omitted.
+	INT_FIELD_TYPE_STORED = newNumericFieldType(NUMERIC_TYPE_INT, true)
+)
+
+// Creates an IntField, a field that indexes an int value for
+// efficient range filtering.
+func NewIntField(name string, value int, stored Store) *Field {
+	if stored == STORE_YES {
+		return NewIntFieldWithType(name, value, INT_FIELD_TYPE_STORED)
+	}
+	return NewIntFieldWithType(name, value, INT_FIELD_TYPE_NOT_STORED)
+}
+
+// Expert: allows you to customize the FieldType. It panics if the
+// numeric type of ft is not NUMERIC_TYPE_INT.
+func NewIntFieldWithType(name string, value int, ft *FieldType) *Field {
+	return newNumericField(name, value, ft, NUMERIC_TYPE_INT)
+}
+
+// document/LongField.java
+
+var (
+	// Type for a LongField that is not stored: normalization factors,
+	// frequencies, and positions are omitted.
+	LONG_FIELD_TYPE_NOT_STORED = newNumericFieldType(NUMERIC_TYPE_LONG, false)
+	// Type for a stored LongField: normalization factors, frequencies,
+	// and positions are omitted.
+	LONG_FIELD_TYPE_STORED = newNumericFieldType(NUMERIC_TYPE_LONG, true)
+)
+
+/*
+Creates a LongField, a field that indexes an int64 value for
+efficient range filtering.
+
+Any type that can be converted to int64 can also be indexed. For
+example, date/time values represented by a time.Time can be
+translated into an int64 value using its UnixNano() method.
+*/
+func NewLongField(name string, value int64, stored Store) *Field {
+	if stored == STORE_YES {
+		return NewLongFieldWithType(name, value, LONG_FIELD_TYPE_STORED)
+	}
+	return NewLongFieldWithType(name, value, LONG_FIELD_TYPE_NOT_STORED)
+}
+
+// Expert: allows you to customize the FieldType. It panics if the
+// numeric type of ft is not NUMERIC_TYPE_LONG.
+func NewLongFieldWithType(name string, value int64, ft *FieldType) *Field {
+	return newNumericField(name, value, ft, NUMERIC_TYPE_LONG)
+}
+
+// document/FloatField.java
+
+var (
+	// Type for a FloatField that is not stored: normalization factors,
+	// frequencies, and positions are omitted.
+	FLOAT_FIELD_TYPE_NOT_STORED = newNumericFieldType(NUMERIC_TYPE_FLOAT, false)
+	// Type for a stored FloatField: normalization factors,
+	// frequencies, and positions are omitted.
+	FLOAT_FIELD_TYPE_STORED = newNumericFieldType(NUMERIC_TYPE_FLOAT, true)
+)
+
+// Creates a FloatField, a field that indexes a float32 value for
+// efficient range filtering.
+func NewFloatField(name string, value float32, stored Store) *Field {
+	if stored == STORE_YES {
+		return NewFloatFieldWithType(name, value, FLOAT_FIELD_TYPE_STORED)
+	}
+	return NewFloatFieldWithType(name, value, FLOAT_FIELD_TYPE_NOT_STORED)
+}
+
+// Expert: allows you to customize the FieldType. It panics if the
+// numeric type of ft is not NUMERIC_TYPE_FLOAT.
+func NewFloatFieldWithType(name string, value float32, ft *FieldType) *Field {
+	return newNumericField(name, value, ft, NUMERIC_TYPE_FLOAT)
+}
+
+// document/DoubleField.java
+
+var (
+	// Type for a DoubleField that is not stored: normalization
+	// factors, frequencies, and positions are omitted.
+	DOUBLE_FIELD_TYPE_NOT_STORED = newNumericFieldType(NUMERIC_TYPE_DOUBLE, false)
+	// Type for a stored DoubleField: normalization factors,
+	// frequencies, and positions are omitted.
+	DOUBLE_FIELD_TYPE_STORED = newNumericFieldType(NUMERIC_TYPE_DOUBLE, true)
+)
+
+// Creates a DoubleField, a field that indexes a float64 value for
+// efficient range filtering.
+func NewDoubleField(name string, value float64, stored Store) *Field {
+	if stored == STORE_YES {
+		return NewDoubleFieldWithType(name, value, DOUBLE_FIELD_TYPE_STORED)
+	}
+	return NewDoubleFieldWithType(name, value, DOUBLE_FIELD_TYPE_NOT_STORED)
+}
+
+// Expert: allows you to customize the FieldType. It panics if the
+// numeric type of ft is not NUMERIC_TYPE_DOUBLE.
+func NewDoubleFieldWithType(name string, value float64, ft *FieldType) *Field {
+	return newNumericField(name, value, ft, NUMERIC_TYPE_DOUBLE)
+}
@@ -0,0 +1,153 @@
+/*
+Package golucene is a Go port of Apache Lucene.
+
+The Index type bundles a Directory, an IndexWriter and a
+SearcherManager with sane defaults, for applications which just need
+to index and search documents. The packages index, search, store and
+analysis provide the complete, low-level APIs.
+*/
+package golucene
+
+import (
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/analysis/standard"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/search"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"strings"
+)
+
+const (
+	// Name of the field identifying a document, which Delete() matches.
+	ID_FIELD = "id"
+	// Name of the field Search() matches the query string against.
+	CONTENT_FIELD = "content"
+)
+
+/*
+An index in a directory of the file system, which documents are
+added to and deleted from, and which can be searched at the same
+time.
+
+Documents are identified by the value of their ID_FIELD, which should
+be indexed as a single term, e.g. with document.NewStringField(), and
+are searched in their CONTENT_FIELD, analyzed with a StandardAnalyzer,
+e.g. with document.NewTextField().
+
+Changes are visible to Search() once Refresh() is called, and are
+committed to the directory by Close(). An Index can be used by
+multiple goroutines.
+*/
+type Index struct {
+	directory store.Directory
+	analyzer  analysis.Analyzer
+	writer    *index.IndexWriter
+	manager   *search.SearcherManager
+}
+
+// A document matching the query of Search().
+type Hit struct {
+	Score float32
+	// The stored fields of the document.
+	Document *document.Document
+}
+
+// Opens the index at path, creating it if it doesn't exist.
+func Open(path string) (idx *Index, err error) {
+	idx = &Index{analyzer: standard.NewStandardAnalyzer()}
+	if idx.directory, err = store.OpenFSDirectory(path); err != nil {
+		return nil, err
+	}
+	conf := index.NewIndexWriterConfig().SetAnalyzer(idx.analyzer)
+	if idx.writer, err = index.NewIndexWriter(idx.directory, conf); err != nil {
+		util.CloseWhileSuppressingError(idx.analyzer, idx.directory)
+		return nil, err
+	}
+	if idx.manager, err = search.NewSearcherManagerFromWriter(idx.writer, true, nil); err != nil {
+		util.CloseWhileSuppressingError(idx.writer, idx.analyzer, idx.directory)
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Adds a document to the index.
+func (idx *Index) Index(doc []document.IndexableField) error {
+	return idx.writer.AddDocument(doc)
+}
+
+// Deletes the documents with the given id from the index.
+func (idx *Index) Delete(id string) error {
+	return idx.writer.DeleteDocuments(index.NewTerm(ID_FIELD, id))
+}
+
+/*
+Returns the top n documents matching any term of the query string,
+analyzed like the CONTENT_FIELD of the documents, best first.
+*/
+func (idx *Index) Search(query string, n int) ([]Hit, error) {
+	q, err := idx.parse(query)
+	if err != nil {
+		return nil, err
+	}
+	searcher, err := idx.manager.Acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer idx.manager.Release(searcher)
+	topDocs, err := searcher.SearchTop(q, n)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]Hit, len(topDocs.ScoreDocs))
+	for i, scoreDoc := range topDocs.ScoreDocs {
+		hits[i].Score = scoreDoc.Score
+		if hits[i].Document, err = searcher.Doc(scoreDoc.Doc); err != nil {
+			return nil, err
+		}
+	}
+	return hits, nil
+}
+
+// Returns a BooleanQuery of the terms of the analyzed query string,
+// any of which a document must contain.
+func (idx *Index) parse(query string) (search.Query, error) {
+	stream, err := idx.analyzer.TokenStream(CONTENT_FIELD, strings.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	termAtt := stream.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	if err = stream.Reset(); err != nil {
+		return nil, err
+	}
+	q := search.NewBooleanQuery()
+	for {
+		ok, err := stream.IncrementToken()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		term := index.NewTerm(CONTENT_FIELD, string(termAtt.Bytes()))
+		if err = q.Add(search.NewTermQuery(term), search.OCCUR_SHOULD); err != nil {
+			return nil, err
+		}
+	}
+	return q, stream.End()
+}
+
+/*
+Makes the documents indexed and deleted so far visible to Search().
+Blocks if another goroutine is refreshing the index, until it's done.
+*/
+func (idx *Index) Refresh() error {
+	return idx.manager.MaybeRefreshBlocking()
+}
+
+// Commits all changes to the directory, and closes the index.
+func (idx *Index) Close() error {
+	return util.Close(idx.manager, idx.writer, idx.analyzer, idx.directory)
+}
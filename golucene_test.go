@@ -0,0 +1,93 @@
+package golucene
+
+import (
+	"github.com/balzaczyy/golucene/document"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func newTestDoc(id, content string) []document.IndexableField {
+	return []document.IndexableField{
+		document.NewStringField(ID_FIELD, id, document.STORE_YES),
+		document.NewTextField(CONTENT_FIELD, content, document.STORE_YES),
+	}
+}
+
+// Returns the ids of the hits of the query.
+func searchIDs(t *testing.T, idx *Index, query string) []string {
+	hits, err := idx.Search(query, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.Document.Get(ID_FIELD)
+	}
+	return ids
+}
+
+func assertIDs(t *testing.T, query string, expected, actual []string) {
+	if len(expected) != len(actual) {
+		t.Errorf("Expected %v for '%v', but was %v", expected, query, actual)
+		return
+	}
+	for i, id := range expected {
+		if actual[i] != id {
+			t.Errorf("Expected %v for '%v', but was %v", expected, query, actual)
+			return
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	idx, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range [][]document.IndexableField{
+		newTestDoc("1", "The quick brown fox"),
+		newTestDoc("2", "jumps over the lazy dog"),
+		newTestDoc("3", "The Quick Dog"),
+	} {
+		if err = idx.Index(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// not visible before a refresh
+	assertIDs(t, "quick", nil, searchIDs(t, idx, "quick"))
+	if err = idx.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	// the query is analyzed like the documents, and documents matching
+	// more terms come first
+	assertIDs(t, "QUICK dog", []string{"3", "1", "2"}, searchIDs(t, idx, "QUICK dog"))
+	assertIDs(t, "fox", []string{"1"}, searchIDs(t, idx, "fox"))
+	assertIDs(t, "cat", nil, searchIDs(t, idx, "cat"))
+	assertIDs(t, "", nil, searchIDs(t, idx, ""))
+
+	if err = idx.Delete("3"); err != nil {
+		t.Fatal(err)
+	}
+	if err = idx.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, "quick", []string{"1"}, searchIDs(t, idx, "quick"))
+	if err = idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the changes were committed
+	idx, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	assertIDs(t, "dog", []string{"2"}, searchIDs(t, idx, "dog"))
+}
@@ -0,0 +1,145 @@
+package index
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/util/automaton"
+	"unicode/utf8"
+)
+
+// AutomatonTermsEnum.java
+
+/*
+A FilteredTermsEnum that enumerates terms based upon what is accepted
+by a DFA.
+
+The algorithm is such:
+1. As long as matches are successful, keep reading sequentially.
+2. When a match fails, skip to the next string in lexicographic order
+that does not enter a reject state.
+
+The algorithm does not attempt to actually skip to the next string
+that is completely accepted. This is not possible when the language
+accepted by the FSM is not finite (i.e. * operator).
+
+The automaton must be deterministic, without dead transitions (see
+Automaton.RemoveDeadTransitions()).
+*/
+type AutomatonTermsEnum struct {
+	*FilteredTermsEnumImpl
+	a *automaton.Automaton
+	// if not nil, only the terms after it are enumerated
+	startTerm []byte
+}
+
+func NewAutomatonTermsEnum(tenum TermsEnum, a *automaton.Automaton) *AutomatonTermsEnum {
+	ans := &AutomatonTermsEnum{a: a}
+	ans.FilteredTermsEnumImpl = NewFilteredTermsEnum(ans, tenum, true)
+	return ans
+}
+
+// Returns true if the term matches the automaton. Also stashes away
+// the term to assist with smart enumeration.
+func (e *AutomatonTermsEnum) Accept(term []byte) AcceptStatus {
+	if e.a.RunBytes(term) {
+		return ACCEPT_STATUS_YES_AND_SEEK
+	}
+	return ACCEPT_STATUS_NO_AND_SEEK
+}
+
+func (e *AutomatonTermsEnum) NextSeekTerm(term []byte) ([]byte, error) {
+	// on the first call, the empty string is a candidate itself
+	inclusive := term == nil
+	if term == nil && e.startTerm != nil {
+		term = e.startTerm
+	}
+	var s []rune
+	if term != nil {
+		s = []rune(string(term))
+	}
+	suffix, ok := nextString(e.a.InitialState(), s, inclusive)
+	if !ok {
+		// no more possible strings can match
+		return nil, nil
+	}
+	ans := []byte(string(suffix))
+	if term != nil && bytes.Compare(ans, term) <= 0 {
+		// term is not valid UTF-8, so fall back to the next string
+		// in binary order
+		ans = append(append(ans[:0], term...), 0)
+	}
+	return ans, nil
+}
+
+/*
+Returns the smallest string, starting from state p, that is greater
+than s (or equal to it if inclusive) and does not put the machine
+into a reject state. When the automaton accepts a finite language,
+this is the next accepted string.
+*/
+func nextString(p *automaton.State, s []rune, inclusive bool) ([]rune, bool) {
+	if len(s) == 0 {
+		if inclusive && p.IsAccept() {
+			return []rune{}, true
+		}
+		// smallest non-empty string
+		for _, t := range p.Transitions() {
+			if c, ok := validCodePoint(t, t.Min); ok {
+				return append([]rune{c}, smallestString(t.To)...), true
+			}
+		}
+		return nil, false
+	}
+
+	c := s[0]
+	if next := p.Step(c); next != nil {
+		if suffix, ok := nextString(next, s[1:], inclusive); ok {
+			return append([]rune{c}, suffix...), true
+		}
+	}
+	// backtrack: take the smallest transition above c
+	for _, t := range p.Transitions() {
+		if t.Max <= c {
+			continue
+		}
+		from := t.Min
+		if from <= c {
+			from = c + 1
+		}
+		if c, ok := validCodePoint(t, from); ok {
+			return append([]rune{c}, smallestString(t.To)...), true
+		}
+	}
+	return nil, false
+}
+
+// Returns the smallest string leading from p to an accept state, or
+// the path up to the first loop if there is none.
+func smallestString(p *automaton.State) []rune {
+	var ans []rune
+	seen := make(map[*automaton.State]bool)
+	for !p.IsAccept() && !seen[p] {
+		seen[p] = true
+		var next *automaton.State
+		for _, t := range p.Transitions() {
+			if c, ok := validCodePoint(t, t.Min); ok {
+				ans = append(ans, c)
+				next = t.To
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		p = next
+	}
+	return ans
+}
+
+// Returns the smallest code point of t not below from which can be
+// encoded as UTF-8, skipping the surrogates.
+func validCodePoint(t *automaton.Transition, from rune) (rune, bool) {
+	if from >= 0xD800 && from <= 0xDFFF {
+		from = 0xE000
+	}
+	return from, from <= t.Max && utf8.ValidRune(from)
+}
@@ -0,0 +1,385 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// codecs/lucene40/BitVector.java
+
+const (
+	BIT_VECTOR_CODEC = "BitVector"
+
+	// Version before version tracking was added:
+	BIT_VECTOR_VERSION_PRE = -1
+	// First version:
+	BIT_VECTOR_VERSION_START = 0
+	// Changed DGaps to encode gaps between cleared bits, not set:
+	BIT_VECTOR_VERSION_DGAPS_CLEARED = 1
+	// Increment version to change it:
+	BIT_VECTOR_VERSION_CURRENT = BIT_VECTOR_VERSION_DGAPS_CLEARED
+)
+
+// number of set bits of each byte value
+var bitVectorByteCounts = func() (counts [256]int) {
+	for i := range counts {
+		for b := i; b != 0; b &= b - 1 {
+			counts[i]++
+		}
+	}
+	return counts
+}()
+
+/*
+Optimized implementation of a vector of bits. This is more-or-less
+like java.util.BitSet, but also includes the following:
+
+- a count() method, which efficiently computes the number of one bits;
+- optimized read from a disk file;
+- inlinable get() method;
+- store and load, as bit set or d-gaps, depending on sparseness;
+*/
+type BitVector struct {
+	bits    []byte
+	size    int
+	count   int
+	version int32
+}
+
+// Constructs a vector capable of holding n bits, all cleared.
+func newBitVector(n int) *BitVector {
+	return &BitVector{
+		bits:    make([]byte, bitVectorNumBytes(n)),
+		size:    n,
+		version: BIT_VECTOR_VERSION_CURRENT,
+	}
+}
+
+// Returns a copy of the vector, which can be changed independently.
+func (bv *BitVector) clone() *BitVector {
+	ans := *bv
+	ans.bits = append([]byte(nil), bv.bits...)
+	return &ans
+}
+
+// Constructs a bit vector from the file name in Directory d.
+func newBitVectorFrom(d store.Directory, name string, context store.IOContext) (bv *BitVector, err error) {
+	input, err := d.OpenInput(name, context)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	bv = &BitVector{}
+	firstInt, err := input.ReadInt()
+	if err != nil {
+		return nil, err
+	}
+	if firstInt == -2 {
+		// New format, with full header & version:
+		if bv.version, err = codec.CheckHeader(input, BIT_VECTOR_CODEC,
+			BIT_VECTOR_VERSION_START, BIT_VECTOR_VERSION_CURRENT); err != nil {
+			return nil, err
+		}
+		if bv.size, err = asInt(input.ReadInt()); err != nil {
+			return nil, err
+		}
+	} else {
+		bv.version = BIT_VECTOR_VERSION_PRE
+		bv.size = int(firstInt)
+	}
+	if bv.size == -1 {
+		if bv.version >= BIT_VECTOR_VERSION_DGAPS_CLEARED {
+			err = bv.readClearedDgaps(input)
+		} else {
+			err = bv.readSetDgaps(input)
+		}
+	} else {
+		err = bv.readBits(input)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if bv.version < BIT_VECTOR_VERSION_DGAPS_CLEARED {
+		bv.invertAll()
+	}
+	if !bv.verifyCount() {
+		return nil, errors.New(fmt.Sprintf(
+			"Corrupted: count mismatch, expected %v (resource=%v)", bv.count, input))
+	}
+	return bv, nil
+}
+
+func bitVectorNumBytes(size int) int {
+	bytesLength := int(uint(size) >> 3)
+	if size&7 != 0 {
+		bytesLength++
+	}
+	return bytesLength
+}
+
+// Read as a bit set
+func (bv *BitVector) readBits(input store.IndexInput) (err error) {
+	if bv.count, err = asInt(input.ReadInt()); err != nil { // read count
+		return err
+	}
+	bv.bits = make([]byte, bitVectorNumBytes(bv.size)) // allocate bits
+	return input.ReadBytes(bv.bits)
+}
+
+// read as a d-gaps list
+func (bv *BitVector) readSetDgaps(input store.IndexInput) (err error) {
+	if bv.size, err = asInt(input.ReadInt()); err != nil { // (re)read size
+		return err
+	}
+	if bv.count, err = asInt(input.ReadInt()); err != nil { // read count
+		return err
+	}
+	bv.bits = make([]byte, bitVectorNumBytes(bv.size)) // allocate bits
+	last := 0
+	for n := bv.count; n > 0; {
+		gap, err := input.ReadVInt()
+		if err != nil {
+			return err
+		}
+		last += int(gap)
+		if last >= len(bv.bits) {
+			return errors.New(fmt.Sprintf("Corrupted: d-gap out of bounds (resource=%v)", input))
+		}
+		if bv.bits[last], err = input.ReadByte(); err != nil {
+			return err
+		}
+		n -= bitVectorByteCounts[bv.bits[last]]
+		// assert n >= 0
+	}
+	return nil
+}
+
+// read as a d-gaps cleared bits list
+func (bv *BitVector) readClearedDgaps(input store.IndexInput) (err error) {
+	if bv.size, err = asInt(input.ReadInt()); err != nil { // (re)read size
+		return err
+	}
+	if bv.count, err = asInt(input.ReadInt()); err != nil { // read count
+		return err
+	}
+	bv.bits = make([]byte, bitVectorNumBytes(bv.size)) // allocate bits
+	for i := range bv.bits {
+		bv.bits[i] = 0xff
+	}
+	bv.clearUnusedBits()
+	last := 0
+	for numCleared := bv.size - bv.count; numCleared > 0; {
+		gap, err := input.ReadVInt()
+		if err != nil {
+			return err
+		}
+		last += int(gap)
+		if last >= len(bv.bits) {
+			return errors.New(fmt.Sprintf("Corrupted: d-gap out of bounds (resource=%v)", input))
+		}
+		if bv.bits[last], err = input.ReadByte(); err != nil {
+			return err
+		}
+		numCleared -= 8 - bitVectorByteCounts[bv.bits[last]]
+		// assert numCleared >= 0 || (last == len(bits)-1 && numCleared == -(8-(size&7)))
+	}
+	return nil
+}
+
+// Invert all bits
+func (bv *BitVector) invertAll() {
+	if bv.count != -1 {
+		bv.count = bv.size - bv.count
+	}
+	for i, b := range bv.bits {
+		bv.bits[i] = ^b
+	}
+	bv.clearUnusedBits()
+}
+
+func (bv *BitVector) clearUnusedBits() {
+	// Take care not to invert the "unused" bits in the last byte:
+	if len(bv.bits) > 0 {
+		if lastNBits := bv.size & 7; lastNBits != 0 {
+			mask := byte(1<<uint(lastNBits)) - 1
+			bv.bits[len(bv.bits)-1] &= mask
+		}
+	}
+}
+
+// Verifies that the recorded count matches the bits actually set.
+func (bv *BitVector) verifyCount() bool {
+	// assert count != -1
+	count := 0
+	for _, b := range bv.bits {
+		count += bitVectorByteCounts[b]
+	}
+	return count == bv.count
+}
+
+// Sets the value of bit to zero.
+func (bv *BitVector) Clear(bit int) {
+	bv.getAndClear(bit)
+}
+
+/*
+Sets the value of bit to zero, and returns true if it was one.
+*/
+func (bv *BitVector) getAndClear(bit int) bool {
+	// assert bit >= 0 && bit < size
+	pos, mask := bit>>3, byte(1<<uint(bit&7))
+	if bv.bits[pos]&mask == 0 {
+		return false
+	}
+	bv.bits[pos] &^= mask
+	bv.count--
+	return true
+}
+
+/*
+Returns true if bit is one and false if it is zero.
+*/
+func (bv *BitVector) Get(bit int) bool {
+	// assert bit >= 0 && bit < size
+	return bv.bits[bit>>3]&(1<<uint(bit&7)) != 0
+}
+
+// Returns the number of bits in this vector.
+func (bv *BitVector) Size() int {
+	return bv.size
+}
+
+func (bv *BitVector) Length() int {
+	return bv.size
+}
+
+/*
+Returns the total number of one bits in this vector. This is
+efficiently computed and cached, so that, if the vector is not
+changed, no recomputation is done for repeated calls.
+*/
+func (bv *BitVector) Count() int {
+	return bv.count
+}
+
+/*
+Writes this vector to the file name in Directory d, as a bit set or
+as d-gaps of the cleared bits, whichever is smaller.
+*/
+func (bv *BitVector) write(d store.Directory, name string, context store.IOContext) (err error) {
+	output, err := d.CreateOutput(name, context)
+	if err != nil {
+		return err
+	}
+	success := false
+	defer func() {
+		if !success {
+			util.CloseWhileSuppressingError(output)
+			d.DeleteFile(name)
+		} else {
+			err = output.Close()
+		}
+	}()
+
+	if err = output.WriteInt(-2); err != nil {
+		return err
+	}
+	if err = codec.WriteHeader(output, BIT_VECTOR_CODEC, BIT_VECTOR_VERSION_CURRENT); err != nil {
+		return err
+	}
+	if bv.isSparse() {
+		// sparse bit-set more efficiently saved as d-gaps.
+		err = bv.writeClearedDgaps(output)
+	} else {
+		err = bv.writeBits(output)
+	}
+	if err != nil {
+		return err
+	}
+	// assert verifyCount()
+	success = true
+	return nil
+}
+
+// Write as a bit set
+func (bv *BitVector) writeBits(output store.IndexOutput) error {
+	if err := output.WriteInt(int32(bv.size)); err != nil { // write size
+		return err
+	}
+	if err := output.WriteInt(int32(bv.count)); err != nil { // write count
+		return err
+	}
+	return output.WriteBytes(bv.bits)
+}
+
+// Write as a d-gaps list
+func (bv *BitVector) writeClearedDgaps(output store.IndexOutput) error {
+	if err := output.WriteInt(-1); err != nil { // mark using d-gaps
+		return err
+	}
+	if err := output.WriteInt(int32(bv.size)); err != nil { // write size
+		return err
+	}
+	if err := output.WriteInt(int32(bv.count)); err != nil { // write count
+		return err
+	}
+	last := 0
+	numCleared := bv.size - bv.count
+	for i := 0; i < len(bv.bits) && numCleared > 0; i++ {
+		if bv.bits[i] != 0xff {
+			if err := output.WriteVInt(int32(i - last)); err != nil {
+				return err
+			}
+			if err := output.WriteByte(bv.bits[i]); err != nil {
+				return err
+			}
+			last = i
+			numCleared -= 8 - bitVectorByteCounts[bv.bits[i]]
+			// assert numCleared >= 0 || (i == len(bits)-1 && numCleared == -(8-(size&7)))
+		}
+	}
+	return nil
+}
+
+/*
+Indicates if the bit vector is sparse and should be saved as a d-gaps
+list, or dense, and should be saved as a bit set.
+*/
+func (bv *BitVector) isSparse() bool {
+	clearedCount := bv.size - bv.count
+	if clearedCount == 0 {
+		return true
+	}
+
+	avgGapLength := len(bv.bits) / clearedCount
+
+	// expected number of bytes for vInt encoding of each gap
+	var expectedDGapBytes int
+	switch {
+	case avgGapLength <= 1<<7:
+		expectedDGapBytes = 1
+	case avgGapLength <= 1<<14:
+		expectedDGapBytes = 2
+	case avgGapLength <= 1<<21:
+		expectedDGapBytes = 3
+	case avgGapLength <= 1<<28:
+		expectedDGapBytes = 4
+	default:
+		expectedDGapBytes = 5
+	}
+
+	// +1 because we write the byte itself that contains the set bit
+	bytesPerSetBit := expectedDGapBytes + 1
+
+	// note: adding 32 because we start with ((int) -1) to indicate d-gaps format.
+	expectedBits := int64(32 + 8*bytesPerSetBit*clearedCount)
+
+	// note: factor is for read/write of byte-arrays being faster than vints.
+	const factor = 10
+	return factor*expectedBits < int64(bv.size)
+}
@@ -0,0 +1,155 @@
+package index
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/store"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Writes a .del file, as BitVector does, either as a bit set or as
+// d-gaps of cleared bits.
+func writeTestBitVector(t *testing.T, path string, size int, deleted map[int]bool, dgaps bool) {
+	w := &testDVWriter{}
+	buf := w.writeInt(nil, uint32(0xFFFFFFFE)) // -2
+	buf = w.writeHeader(buf, BIT_VECTOR_CODEC, BIT_VECTOR_VERSION_CURRENT)
+	bits := make([]byte, bitVectorNumBytes(size))
+	for i := 0; i < size; i++ {
+		if !deleted[i] {
+			bits[i>>3] |= 1 << uint(i&7)
+		}
+	}
+	if dgaps {
+		buf = w.writeInt(buf, uint32(0xFFFFFFFF)) // -1
+		buf = w.writeInt(buf, uint32(size))
+		buf = w.writeInt(buf, uint32(size-len(deleted)))
+		last := 0
+		for i, b := range bits {
+			if b != 0xff {
+				buf = w.writeVInt(buf, i-last)
+				buf = append(buf, b)
+				last = i
+			}
+		}
+	} else {
+		buf = w.writeInt(buf, uint32(size))
+		buf = w.writeInt(buf, uint32(size-len(deleted)))
+		buf = append(buf, bits...)
+	}
+	if err := ioutil.WriteFile(path, buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLiveDocs(t *testing.T) {
+	dir := copyTestIndex(t, "../search/testdata/win8/belfrysample")
+	defer os.RemoveAll(dir)
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sis := &SegmentInfos{}
+	if err = sis.ReadAll(d); err != nil {
+		t.Fatal(err)
+	}
+	info := sis.Segments[0].info
+	maxDoc := int(info.docCount)
+
+	deleted := map[int]bool{2: true, 5: true}
+	writeTestBitVector(t, filepath.Join(dir, "_0_1.del"), maxDoc, deleted, false)
+	writeTestBitVector(t, filepath.Join(dir, "_0_2.del"), maxDoc, deleted, true)
+	for _, delGen := range []int64{1, 2} {
+		r, err := NewSegmentReader(NewSegmentInfoPerCommit(info, len(deleted), delGen, -1),
+			DEFAULT_TERMS_INDEX_DIVISOR, store.IO_CONTEXT_READ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.NumDocs() != maxDoc-len(deleted) {
+			t.Errorf("Expected %v docs, but was %v", maxDoc-len(deleted), r.NumDocs())
+		}
+		liveDocs := r.LiveDocs()
+		if liveDocs.Length() != maxDoc {
+			t.Errorf("Expected %v live docs bits, but was %v", maxDoc, liveDocs.Length())
+		}
+		for docID := 0; docID < maxDoc; docID++ {
+			if liveDocs.Get(docID) == deleted[docID] {
+				t.Errorf("Unexpected liveness of doc %v in gen %v", docID, delGen)
+			}
+		}
+
+		// deleted docs are skipped by enums
+		termsEnum := r.Terms("scope").Iterator(nil)
+		if ok, err := termsEnum.SeekExact([]byte("belfrysample")); !ok || err != nil {
+			t.Fatalf("Term not found: %v", err)
+		}
+		docsEnum := termsEnum.Docs(liveDocs, DOCS_ENUM_EMPTY)
+		count := 0
+		for docID, ok := docsEnum.NextDoc(); ok && docID != NO_MORE_DOCS; docID, ok = docsEnum.NextDoc() {
+			if deleted[docID] {
+				t.Errorf("Deleted doc %v should be skipped", docID)
+			}
+			count++
+		}
+		if count != maxDoc-len(deleted) {
+			t.Errorf("Expected %v docs, but was %v", maxDoc-len(deleted), count)
+		}
+	}
+
+	// inconsistent delete count
+	if _, err = NewSegmentReader(NewSegmentInfoPerCommit(info, 1, 1, -1),
+		DEFAULT_TERMS_INDEX_DIVISOR, store.IO_CONTEXT_READ); err == nil {
+		t.Error("Should fail on mismatched delete count")
+	}
+	// corrupted header
+	w := &testDVWriter{}
+	buf := w.writeInt(nil, uint32(0xFFFFFFFE))
+	buf = w.writeHeader(buf, "Bogus", BIT_VECTOR_VERSION_CURRENT)
+	if err = ioutil.WriteFile(filepath.Join(dir, "_0_3.del"), buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = newBitVectorFrom(d, "_0_3.del", store.IO_CONTEXT_READONCE); err == nil {
+		t.Error("Should fail on codec mismatch")
+	}
+}
+
+func TestBitVectorWrite(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	// few deletes are written as d-gaps, many as a bit set
+	for _, numDeleted := range []int{0, 3, 500} {
+		const size = 1003
+		bv := Lucene40NewLiveDocs(size, nil).(*BitVector)
+		assertEquals(t, size, bv.Count())
+		for i := 0; i < numDeleted; i++ {
+			bv.Clear(i * 2)
+			bv.Clear(i * 2) // already cleared
+		}
+		assertEquals(t, size-numDeleted, bv.Count())
+		assertEquals(t, numDeleted < 500, bv.isSparse())
+
+		name := fmt.Sprintf("_0_%v.del", numDeleted)
+		if err := bv.write(d, name, store.IO_CONTEXT_DEFAULT); err != nil {
+			t.Fatal(err)
+		}
+		read, err := newBitVectorFrom(d, name, store.IO_CONTEXT_READONCE)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, size, read.Size())
+		assertEquals(t, size-numDeleted, read.Count())
+		for i := 0; i < size; i++ {
+			if read.Get(i) != bv.Get(i) {
+				t.Fatalf("Bit %v differs after writing %v deletes", i, numDeleted)
+			}
+		}
+
+		// changing a copy leaves the original untouched
+		clone := Lucene40NewLiveDocs(size, read).(*BitVector)
+		clone.Clear(size - 1)
+		assertEquals(t, true, read.Get(size-1))
+		assertEquals(t, size-numDeleted-1, clone.Count())
+	}
+}
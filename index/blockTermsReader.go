@@ -26,15 +26,16 @@ type BlockTermState struct {
 }
 
 func NewBlockTermState() *BlockTermState {
-	return &BlockTermState{OrdTermState: &OrdTermState{}}
+	ans := &BlockTermState{OrdTermState: &OrdTermState{}}
+	ans.Self = ans
+	return ans
 }
 
+// Copies the common fields only; use Self.CopyFrom() to copy
+// codec-specific state as well.
 func (ts *BlockTermState) CopyFrom(other TermState) {
 	if ots, ok := other.(*BlockTermState); ok {
 		ts.OrdTermState.CopyFrom(ots.OrdTermState)
-		if ts.Self != nil {
-			ts.Self.CopyFrom(ots.Self)
-		}
 		ts.docFreq = ots.docFreq
 		ts.totalTermFreq = ots.totalTermFreq
 		ts.termBlockOrd = ots.termBlockOrd
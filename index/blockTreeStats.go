@@ -0,0 +1,182 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// BlockTreeTermsReader.java/Stats
+
+/*
+BlockTree statistics for a single field returned by
+FieldReader.ComputeStats().
+*/
+type BlockTreeStats struct {
+	// How many nodes in the index FST.
+	IndexNodeCount int64
+	// How many arcs in the index FST.
+	IndexArcCount int64
+	// Byte size of the index.
+	IndexNumBytes int64
+
+	// Total number of terms in the field.
+	TotalTermCount int64
+	// Total number of bytes (sum of term lengths) across all terms in
+	// the field.
+	TotalTermBytes int64
+
+	// The number of normal (non-floor) blocks in the terms file.
+	NonFloorBlockCount int
+	// The number of floor blocks (meta-blocks larger than the allowed
+	// maxItemsPerBlock) in the terms file.
+	FloorBlockCount int
+	// The number of sub-blocks within the floor blocks.
+	FloorSubBlockCount int
+	// The number of "internal" blocks (that have both terms and
+	// sub-blocks).
+	MixedBlockCount int
+	// The number of "leaf" blocks (blocks that have only terms).
+	TermsOnlyBlockCount int
+	// The number of "internal" blocks that do not contain terms (have
+	// only sub-blocks).
+	SubBlocksOnlyBlockCount int
+	// Total number of blocks.
+	TotalBlockCount int
+
+	// Number of blocks at each prefix depth.
+	BlockCountByPrefixLen []int
+
+	startBlockCount, endBlockCount int
+
+	// Total number of bytes used to store term suffixes.
+	TotalBlockSuffixBytes int64
+	// Total number of bytes used to store term stats (not including
+	// what the PostingsBase stores).
+	TotalBlockStatsBytes int64
+	// Total bytes stored by the PostingsBase, plus the other few vInts
+	// stored in the frame.
+	TotalBlockOtherBytes int64
+
+	// Segment name.
+	Segment string
+	// Field name.
+	Field string
+}
+
+func newBlockTreeStats(segment, field string) *BlockTreeStats {
+	return &BlockTreeStats{
+		Segment:               segment,
+		Field:                 field,
+		BlockCountByPrefixLen: make([]int, 10),
+	}
+}
+
+func (s *BlockTreeStats) startBlock(frame *segmentTermsEnumFrame, isFloor bool) {
+	s.TotalBlockCount++
+	if isFloor {
+		if frame.fp == frame.fpOrig {
+			s.FloorBlockCount++
+		}
+		s.FloorSubBlockCount++
+	} else {
+		s.NonFloorBlockCount++
+	}
+
+	if len(s.BlockCountByPrefixLen) <= frame.prefix {
+		next := make([]int, 1+frame.prefix)
+		copy(next, s.BlockCountByPrefixLen)
+		s.BlockCountByPrefixLen = next
+	}
+	s.BlockCountByPrefixLen[frame.prefix]++
+	s.startBlockCount++
+	s.TotalBlockSuffixBytes += int64(frame.suffixesReader.Length())
+	s.TotalBlockStatsBytes += int64(frame.statsReader.Length())
+}
+
+func (s *BlockTreeStats) endBlock(frame *segmentTermsEnumFrame) {
+	termCount := frame.state.termBlockOrd
+	if frame.isLeafBlock {
+		termCount = frame.entCount
+	}
+	subBlockCount := frame.entCount - termCount
+	s.TotalTermCount += int64(termCount)
+	if termCount != 0 && subBlockCount != 0 {
+		s.MixedBlockCount++
+	} else if termCount != 0 {
+		s.TermsOnlyBlockCount++
+	} else if subBlockCount != 0 {
+		s.SubBlocksOnlyBlockCount++
+	} else {
+		panic("illegal state")
+	}
+	s.endBlockCount++
+	otherBytes := frame.fpEnd - frame.fp - int64(frame.suffixesReader.Length()) - int64(frame.statsReader.Length())
+	if otherBytes <= 0 {
+		panic(fmt.Sprintf("otherBytes=%v frame.fp=%v frame.fpEnd=%v", otherBytes, frame.fp, frame.fpEnd))
+	}
+	s.TotalBlockOtherBytes += otherBytes
+}
+
+func (s *BlockTreeStats) term(term []byte) {
+	s.TotalTermBytes += int64(len(term))
+}
+
+func (s *BlockTreeStats) finish() {
+	if s.startBlockCount != s.endBlockCount {
+		panic(fmt.Sprintf("startBlockCount=%v endBlockCount=%v", s.startBlockCount, s.endBlockCount))
+	}
+	if s.TotalBlockCount != s.FloorSubBlockCount+s.NonFloorBlockCount {
+		panic(fmt.Sprintf("floorSubBlockCount=%v nonFloorBlockCount=%v totalBlockCount=%v",
+			s.FloorSubBlockCount, s.NonFloorBlockCount, s.TotalBlockCount))
+	}
+	if s.TotalBlockCount != s.MixedBlockCount+s.TermsOnlyBlockCount+s.SubBlocksOnlyBlockCount {
+		panic(fmt.Sprintf("totalBlockCount=%v mixedBlockCount=%v subBlocksOnlyBlockCount=%v termsOnlyBlockCount=%v",
+			s.TotalBlockCount, s.MixedBlockCount, s.SubBlocksOnlyBlockCount, s.TermsOnlyBlockCount))
+	}
+}
+
+func (s *BlockTreeStats) String() string {
+	var buf bytes.Buffer
+	perBlock := func(n int64, unit string) string {
+		if s.TotalBlockCount == 0 {
+			return ""
+		}
+		return fmt.Sprintf(" (%.1f %v/block)", float64(n)/float64(s.TotalBlockCount), unit)
+	}
+	fmt.Fprintln(&buf, "  index FST:")
+	fmt.Fprintf(&buf, "    %v nodes\n", s.IndexNodeCount)
+	fmt.Fprintf(&buf, "    %v arcs\n", s.IndexArcCount)
+	fmt.Fprintf(&buf, "    %v bytes\n", s.IndexNumBytes)
+	fmt.Fprintln(&buf, "  terms:")
+	fmt.Fprintf(&buf, "    %v terms\n", s.TotalTermCount)
+	fmt.Fprintf(&buf, "    %v bytes", s.TotalTermBytes)
+	if s.TotalTermCount != 0 {
+		fmt.Fprintf(&buf, " (%.1f bytes/term)", float64(s.TotalTermBytes)/float64(s.TotalTermCount))
+	}
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "  blocks:")
+	fmt.Fprintf(&buf, "    %v blocks\n", s.TotalBlockCount)
+	fmt.Fprintf(&buf, "    %v terms-only blocks\n", s.TermsOnlyBlockCount)
+	fmt.Fprintf(&buf, "    %v sub-block-only blocks\n", s.SubBlocksOnlyBlockCount)
+	fmt.Fprintf(&buf, "    %v mixed blocks\n", s.MixedBlockCount)
+	fmt.Fprintf(&buf, "    %v floor blocks\n", s.FloorBlockCount)
+	fmt.Fprintf(&buf, "    %v non-floor blocks\n", s.TotalBlockCount-s.FloorSubBlockCount)
+	fmt.Fprintf(&buf, "    %v floor sub-blocks\n", s.FloorSubBlockCount)
+	fmt.Fprintf(&buf, "    %v term suffix bytes%v\n", s.TotalBlockSuffixBytes, perBlock(s.TotalBlockSuffixBytes, "suffix-bytes"))
+	fmt.Fprintf(&buf, "    %v term stats bytes%v\n", s.TotalBlockStatsBytes, perBlock(s.TotalBlockStatsBytes, "stats-bytes"))
+	fmt.Fprintf(&buf, "    %v other bytes%v\n", s.TotalBlockOtherBytes, perBlock(s.TotalBlockOtherBytes, "other-bytes"))
+	if s.TotalBlockCount != 0 {
+		fmt.Fprintln(&buf, "    by prefix length:")
+		total := 0
+		for prefix, blockCount := range s.BlockCountByPrefixLen {
+			total += blockCount
+			if blockCount != 0 {
+				fmt.Fprintf(&buf, "      %2d: %v\n", prefix, blockCount)
+			}
+		}
+		if s.TotalBlockCount != total {
+			panic("assert fail")
+		}
+	}
+	return buf.String()
+}
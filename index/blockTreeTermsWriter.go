@@ -0,0 +1,246 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// BlockTreeTermsWriter.java
+
+/*
+Block-based terms index and dictionary writer, producing the .tim and
+.tip files read by BlockTreeTermsReader.
+
+NOTE: the terms of each field are currently written as one single
+leaf block, so the terms index of every field is an FST which only
+accepts the empty string. This is a valid (if not a fast to seek)
+block tree.
+*/
+type BlockTreeTermsWriter struct {
+	out            store.IndexOutput
+	indexOut       store.IndexOutput
+	postingsWriter PostingsWriterBase
+	fields         []blockTreeFieldMetaData
+}
+
+type blockTreeFieldMetaData struct {
+	fieldInfo        FieldInfo
+	rootCode         []byte
+	numTerms         int64
+	indexStartFP     int64
+	sumTotalTermFreq int64
+	sumDocFreq       int64
+	docCount         int
+}
+
+// Create a new writer, writing the postings with postingsWriter.
+func newBlockTreeTermsWriter(state SegmentWriteState,
+	postingsWriter PostingsWriterBase) (w *BlockTreeTermsWriter, err error) {
+	w = &BlockTreeTermsWriter{postingsWriter: postingsWriter}
+	success := false
+	defer func() {
+		if !success {
+			util.CloseWhileSuppressingError(w.out, w.indexOut)
+		}
+	}()
+
+	termsFileName := util.SegmentFileName(state.segmentInfo.name, state.segmentSuffix, BTT_EXTENSION)
+	if w.out, err = state.directory.CreateOutput(termsFileName, state.context); err != nil {
+		return nil, err
+	}
+	if err = codec.WriteHeader(w.out, BTT_CODEC_NAME, BTT_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+
+	termsIndexFileName := util.SegmentFileName(state.segmentInfo.name, state.segmentSuffix, BTT_INDEX_EXTENSION)
+	if w.indexOut, err = state.directory.CreateOutput(termsIndexFileName, state.context); err != nil {
+		return nil, err
+	}
+	if err = codec.WriteHeader(w.indexOut, BTT_INDEX_CODEC_NAME, BTT_INDEX_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+
+	// have consumer write its format/header
+	if err = postingsWriter.Start(w.out); err != nil {
+		return nil, err
+	}
+	success = true
+	return w, nil
+}
+
+func (w *BlockTreeTermsWriter) AddField(field FieldInfo) (TermsConsumer, error) {
+	return newBlockTreeTermsWriterPerField(w, field), nil
+}
+
+func (w *BlockTreeTermsWriter) Close() (err error) {
+	defer func() {
+		if e := util.Close(w.out, w.indexOut, w.postingsWriter); err == nil {
+			err = e
+		}
+	}()
+
+	dirStart := w.out.FilePointer()
+	indexDirStart := w.indexOut.FilePointer()
+
+	if err = w.out.WriteVInt(int32(len(w.fields))); err != nil {
+		return err
+	}
+	for _, field := range w.fields {
+		if err = w.out.WriteVInt(field.fieldInfo.number); err == nil {
+			err = w.out.WriteVLong(field.numTerms)
+		}
+		if err == nil {
+			err = w.out.WriteVInt(int32(len(field.rootCode)))
+		}
+		if err == nil {
+			err = w.out.WriteBytes(field.rootCode)
+		}
+		if err == nil && field.fieldInfo.indexOptions != INDEX_OPT_DOCS_ONLY {
+			err = w.out.WriteVLong(field.sumTotalTermFreq)
+		}
+		if err == nil {
+			err = w.out.WriteVLong(field.sumDocFreq)
+		}
+		if err == nil {
+			err = w.out.WriteVInt(int32(field.docCount))
+		}
+		if err == nil {
+			err = w.indexOut.WriteVLong(field.indexStartFP)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err = w.out.WriteLong(dirStart); err != nil {
+		return err
+	}
+	return w.indexOut.WriteLong(indexDirStart)
+}
+
+// BlockTreeTermsWriter.java/TermsWriter
+
+type blockTreePendingTerm struct {
+	term  []byte
+	stats TermStats
+}
+
+type blockTreeTermsWriterPerField struct {
+	owner     *BlockTreeTermsWriter
+	fieldInfo FieldInfo
+	numTerms  int64
+	pending   []blockTreePendingTerm
+}
+
+func newBlockTreeTermsWriterPerField(owner *BlockTreeTermsWriter, fieldInfo FieldInfo) *blockTreeTermsWriterPerField {
+	owner.postingsWriter.SetField(fieldInfo)
+	return &blockTreeTermsWriterPerField{owner: owner, fieldInfo: fieldInfo}
+}
+
+func (w *blockTreeTermsWriterPerField) StartTerm(text []byte) (PostingsConsumer, error) {
+	if err := w.owner.postingsWriter.StartTerm(); err != nil {
+		return nil, err
+	}
+	return w.owner.postingsWriter, nil
+}
+
+func (w *blockTreeTermsWriterPerField) FinishTerm(text []byte, stats TermStats) error {
+	if stats.DocFreq <= 0 {
+		panic("assert fail")
+	}
+	term := make([]byte, len(text))
+	copy(term, text)
+	w.pending = append(w.pending, blockTreePendingTerm{term, stats})
+	if err := w.owner.postingsWriter.FinishTerm(stats); err != nil {
+		return err
+	}
+	w.numTerms++
+	return nil
+}
+
+// Finishes all terms in this field
+func (w *blockTreeTermsWriterPerField) Finish(sumTotalTermFreq, sumDocFreq int64, docCount int) error {
+	if w.numTerms == 0 {
+		return nil
+	}
+	out := w.owner.out
+
+	// TODO: build the floor/sub-block tree and its FST index as
+	// Lucene does; for now all terms go into the root block.
+	fp := out.FilePointer()
+	if err := w.writeLeafBlock(); err != nil {
+		return err
+	}
+
+	rootCode := store.NewRAMOutputStream()
+	if err := rootCode.WriteVLong((fp << BTT_OUTPUT_FLAGS_NUM_BITS) | BTT_OUTPUT_FLAG_HAS_TERMS); err != nil {
+		return err
+	}
+	code := make([]byte, rootCode.FilePointer())
+	rootCode.WriteToBytes(code)
+
+	indexStartFP := w.owner.indexOut.FilePointer()
+	index := util.NewEmptyStringFST(util.INPUT_TYPE_BYTE1, util.ByteSequenceOutputsSingleton(), code)
+	if err := index.Save(w.owner.indexOut); err != nil {
+		return err
+	}
+
+	w.owner.fields = append(w.owner.fields, blockTreeFieldMetaData{
+		w.fieldInfo, code, w.numTerms, indexStartFP, sumTotalTermFreq, sumDocFreq, docCount})
+	return nil
+}
+
+func (w *blockTreeTermsWriterPerField) writeLeafBlock() (err error) {
+	out := w.owner.out
+	count := len(w.pending)
+
+	// Write block header; this is the only, hence last, block:
+	if err = out.WriteVInt(int32(count<<1) | 1); err != nil {
+		return err
+	}
+
+	suffixWriter := store.NewRAMOutputStream()
+	statsWriter := store.NewRAMOutputStream()
+	for _, term := range w.pending {
+		if err = suffixWriter.WriteVInt(int32(len(term.term))); err == nil {
+			err = suffixWriter.WriteBytes(term.term)
+		}
+		// Write term stats, to separate []byte blob:
+		if err == nil {
+			err = statsWriter.WriteVInt(int32(term.stats.DocFreq))
+		}
+		if err == nil && w.fieldInfo.indexOptions != INDEX_OPT_DOCS_ONLY {
+			if term.stats.TotalTermFreq < int64(term.stats.DocFreq) {
+				panic("assert fail")
+			}
+			err = statsWriter.WriteVLong(term.stats.TotalTermFreq - int64(term.stats.DocFreq))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Write suffixes []byte blob to terms dict output; the low bit
+	// marks a leaf block:
+	if err = out.WriteVInt(int32(suffixWriter.FilePointer()<<1) | 1); err != nil {
+		return err
+	}
+	if err = suffixWriter.WriteTo(out); err != nil {
+		return err
+	}
+
+	// Write term stats []byte blob
+	if err = out.WriteVInt(int32(statsWriter.FilePointer())); err != nil {
+		return err
+	}
+	if err = statsWriter.WriteTo(out); err != nil {
+		return err
+	}
+
+	// Have postings writer write block
+	if err = w.owner.postingsWriter.FlushTermsBlock(count, count); err != nil {
+		return err
+	}
+	w.pending = nil
+	return nil
+}
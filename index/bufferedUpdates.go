@@ -0,0 +1,49 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/util"
+	"math"
+)
+
+// BufferedUpdates.java
+
+// docIDUpto of the updates applying to all documents of a segment
+const BUFFERED_UPDATES_MAX_INT = math.MaxInt32
+
+/*
+Holds the deletes and doc values updates buffered by a
+DocumentsWriterPerThread for the documents of its segment. Each
+delete or update only applies to the documents added before it, i.e.
+below its docIDUpto, and is applied once the segment is flushed,
+instead of flushing the segment right away.
+*/
+type bufferedUpdates struct {
+	deletes []*termDelete
+	updates []*docValuesUpdate
+	// the bytes used by the DocumentsWriterPerThread, which the
+	// buffered updates count towards
+	bytesUsed util.Counter
+}
+
+func newBufferedUpdates(bytesUsed util.Counter) *bufferedUpdates {
+	return &bufferedUpdates{bytesUsed: bytesUsed}
+}
+
+// Buffers a delete of the documents containing term below docIDUpto.
+func (b *bufferedUpdates) addDelete(term Term, docIDUpto int) {
+	b.deletes = append(b.deletes, &termDelete{term: term, docIDUpto: docIDUpto})
+	b.bytesUsed.AddAndGet(bufferedDeletesBytes(b.deletes[len(b.deletes)-1:]))
+}
+
+// Buffers a copy of update, applying to the documents below
+// docIDUpto.
+func (b *bufferedUpdates) addUpdate(update *docValuesUpdate, docIDUpto int) {
+	clone := *update
+	clone.docIDUpto = docIDUpto
+	b.updates = append(b.updates, &clone)
+	b.bytesUsed.AddAndGet(bufferedUpdatesBytes(b.updates[len(b.updates)-1:]))
+}
+
+func (b *bufferedUpdates) clear() {
+	b.deletes, b.updates = nil, nil
+}
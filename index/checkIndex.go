@@ -0,0 +1,1052 @@
+package index
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// index/CheckIndex.java
+
+/*
+Basic tool and API to check the health of an index and write a new
+segments file that removes reference to problematic segments.
+
+As this tool checks every byte in the index, on a large index it can
+take quite a long time to run.
+
+WARNING: FixIndex() will permanently remove documents in the broken
+segments from the index. Always make a backup copy of the index
+before running it!
+*/
+type CheckIndex struct {
+	infoStream io.Writer
+	dir        store.Directory
+	verbose    bool
+}
+
+// Create a new CheckIndex on the directory.
+func NewCheckIndex(dir store.Directory) *CheckIndex {
+	return &CheckIndex{dir: dir}
+}
+
+/*
+Set infoStream where messages should go. If nil, no messages are
+printed. If verbose is true then more details are printed.
+*/
+func (ch *CheckIndex) SetInfoStream(out io.Writer, verbose bool) {
+	ch.infoStream = out
+	ch.verbose = verbose
+}
+
+func (ch *CheckIndex) msg(format string, args ...interface{}) {
+	ch.print(format+"\n", args...)
+}
+
+func (ch *CheckIndex) print(format string, args ...interface{}) {
+	if ch.infoStream != nil {
+		fmt.Fprintf(ch.infoStream, format, args...)
+	}
+}
+
+/*
+Returned from CheckIndex() detailing the health and status of the
+index.
+*/
+type CheckIndexStatus struct {
+	// True if no problems were found with the index.
+	Clean bool
+	// True if we were unable to locate and load the segments_N file.
+	MissingSegments bool
+	// True if we were unable to open the segments_N file.
+	CantOpenSegments bool
+	// Name of latest segments_N file in the index.
+	SegmentsFileName string
+	// Number of segments in the index.
+	NumSegments int
+	/*
+		Empty unless you passed specific segments list to check as
+		optional 2nd argument.
+	*/
+	SegmentsChecked []string
+	// List of SegmentInfoStatus instances, detailing status of each
+	// segment.
+	SegmentInfos []*SegmentInfoStatus
+	// Directory index is in.
+	Dir store.Directory
+	/*
+		SegmentInfos instance containing only segments that had no
+		problems (this is used with the FixIndex() method to repair the
+		index.
+	*/
+	newSegments *SegmentInfos
+	// How many documents will be lost to bad segments.
+	TotLoseDocCount int
+	// How many bad segments were found.
+	NumBadSegments int
+	/*
+		True if we checked only specific segments (CheckIndexSegments()
+		was called with non-nil argument).
+	*/
+	Partial bool
+	// The greatest segment name.
+	MaxSegmentName int
+	// Whether the SegmentInfos.counter is greater than any of the
+	// segments' names.
+	ValidCounter bool
+	// Holds the userData of the last commit in the index
+	UserData map[string]string
+}
+
+// Returns the name of the segments_N file written by FixIndex().
+func (s *CheckIndexStatus) NewSegmentsFileName() string {
+	return s.newSegments.SegmentsFileName()
+}
+
+// Holds the status of each segment in the index.
+type SegmentInfoStatus struct {
+	// Name of the segment.
+	Name string
+	// Codec used to read this segment.
+	Codec string
+	// Document count (does not take deletions into account).
+	DocCount int
+	// True if segment is compound file format.
+	Compound bool
+	// Number of files referenced by this segment.
+	NumFiles int
+	// Net size (MB) of the files referenced by this segment.
+	SizeMB float64
+	// True if this segment has pending deletions.
+	HasDeletions bool
+	// Current deletions generation.
+	DeletionsGen int64
+	// Number of deleted documents.
+	NumDeleted int
+	// True if we were able to open a SegmentReader on this segment.
+	OpenReaderPassed bool
+	// Number of fields in this segment.
+	NumFields int
+	/*
+		Map that includes certain debugging details that IndexWriter
+		records into each segment it creates.
+	*/
+	Diagnostics map[string]string
+	// Status for testing of field norms (nil if field norms could not
+	// be tested).
+	FieldNormStatus *FieldNormStatus
+	// Status for testing of indexed terms (nil if indexed terms could
+	// not be tested).
+	TermIndexStatus *TermIndexStatus
+	// Status for testing of stored fields (nil if stored fields could
+	// not be tested).
+	StoredFieldStatus *StoredFieldStatus
+	// Status for testing of term vectors (nil if term vectors could
+	// not be tested).
+	TermVectorStatus *TermVectorStatus
+	// Status for testing of DocValues (nil if DocValues could not be
+	// tested).
+	DocValuesStatus *DocValuesStatus
+}
+
+// Status from testing field norms.
+type FieldNormStatus struct {
+	// Number of fields successfully tested
+	TotFields int64
+	// Error thrown during term index test (nil on success)
+	Error error
+}
+
+// Status from testing term index.
+type TermIndexStatus struct {
+	// Number of terms with at least one live doc.
+	TermCount int64
+	// Number of terms with zero live docs.
+	DelTermCount int64
+	// Total frequency across all terms.
+	TotFreq int64
+	/*
+		Holds details of block allocations in the block tree terms
+		dictionary (this is only set if the PostingsFormat for this
+		segment uses block tree.
+	*/
+	BlockTreeStats map[string]*BlockTreeStats
+	// Error thrown during term index test (nil on success)
+	Error error
+}
+
+// Status from testing stored fields.
+type StoredFieldStatus struct {
+	// Number of documents tested.
+	DocCount int
+	// Total number of stored fields tested.
+	TotFields int64
+	// Error thrown during stored fields test (nil on success)
+	Error error
+}
+
+// Status from testing stored fields.
+type TermVectorStatus struct {
+	// Number of documents tested.
+	DocCount int
+	// Total number of term vectors tested.
+	TotVectors int64
+	// Error thrown during term vector test (nil on success)
+	Error error
+}
+
+// Status from testing DocValues
+type DocValuesStatus struct {
+	// Total number of docValues tested.
+	TotalValueFields int64
+	// Error thrown during doc values test (nil on success)
+	Error error
+}
+
+/*
+Returns a CheckIndexStatus instance detailing the state of the index.
+
+As this method checks every byte in the index, on a large index it
+can take quite a long time to run.
+
+WARNING: make sure you only call this when the index is not opened by
+any writer.
+*/
+func (ch *CheckIndex) CheckIndex() (*CheckIndexStatus, error) {
+	return ch.CheckIndexSegments(nil)
+}
+
+/*
+Returns a CheckIndexStatus instance detailing the state of the index.
+
+onlySegments is list of segment names to check. If nil, all segments
+are checked. The returned status is then partial and cannot be used
+to fix the index.
+*/
+func (ch *CheckIndex) CheckIndexSegments(onlySegments []string) (*CheckIndexStatus, error) {
+	result := &CheckIndexStatus{Dir: ch.dir}
+	sis := &SegmentInfos{}
+	if err := readAllRecovered(sis, ch.dir); err != nil {
+		ch.msg("ERROR: could not read any segments file in directory")
+		result.MissingSegments = true
+		ch.msg("%v", err)
+		return result, nil
+	}
+
+	// find the oldest and newest segment versions
+	var oldest, newest string
+	var oldSegs string
+	for _, si := range sis.Segments {
+		version := si.info.version
+		if version == "" {
+			// pre-3.1 segment
+			oldSegs = "pre-3.1"
+		} else {
+			if oldest == "" || compareVersions(version, oldest) < 0 {
+				oldest = version
+			}
+			if newest == "" || compareVersions(version, newest) > 0 {
+				newest = version
+			}
+		}
+	}
+
+	numSegments := len(sis.Segments)
+	segmentsFileName := sis.SegmentsFileName()
+	// note: we only read the format byte (required preamble) here!
+	input, err := ch.dir.OpenInput(segmentsFileName, store.IO_CONTEXT_DEFAULT)
+	if err != nil {
+		ch.msg("ERROR: could not open segments file in directory")
+		ch.msg("%v", err)
+		result.CantOpenSegments = true
+		return result, nil
+	}
+	_, err = input.ReadInt()
+	input.Close()
+	if err != nil {
+		ch.msg("ERROR: could not read segment file version in directory")
+		ch.msg("%v", err)
+		result.CantOpenSegments = true
+		return result, nil
+	}
+
+	result.SegmentsFileName = segmentsFileName
+	result.NumSegments = numSegments
+	result.UserData = sis.userData
+	var userDataString string
+	if len(sis.userData) > 0 {
+		userDataString = fmt.Sprintf(" userData=%v", sis.userData)
+	}
+
+	var versionString string
+	if oldSegs != "" {
+		if newest != "" {
+			versionString = fmt.Sprintf("versions=[%v .. %v]", oldSegs, newest)
+		} else {
+			versionString = fmt.Sprintf("version=%v", oldSegs)
+		}
+	} else if oldest == newest {
+		versionString = fmt.Sprintf("version=%v", oldest)
+	} else {
+		versionString = fmt.Sprintf("versions=[%v .. %v]", oldest, newest)
+	}
+
+	ch.msg("Segments file=%v numSegments=%v %v%v",
+		segmentsFileName, numSegments, versionString, userDataString)
+
+	if onlySegments != nil {
+		result.Partial = true
+		ch.msg("\nChecking only these segments: %v:", strings.Join(onlySegments, " "))
+		result.SegmentsChecked = append(result.SegmentsChecked, onlySegments...)
+	}
+
+	result.newSegments = sis.cloneWithoutSegments()
+	result.MaxSegmentName = -1
+
+	for i, info := range sis.Segments {
+		segmentName, _ := strconv.ParseInt(info.info.name[1:], 36, 64)
+		if int(segmentName) > result.MaxSegmentName {
+			result.MaxSegmentName = int(segmentName)
+		}
+		if onlySegments != nil && !containsString(onlySegments, info.info.name) {
+			continue
+		}
+		segInfoStat := &SegmentInfoStatus{}
+		result.SegmentInfos = append(result.SegmentInfos, segInfoStat)
+		ch.msg("  %v of %v: name=%v docCount=%v", 1+i, numSegments, info.info.name, info.info.docCount)
+		segInfoStat.Name = info.info.name
+		segInfoStat.DocCount = int(info.info.docCount)
+
+		toLoseDocCount := int(info.info.docCount)
+		if err := ch.checkSegment(info, segInfoStat, &toLoseDocCount); err != nil {
+			ch.msg("FAILED")
+			comment := "fixIndex() would remove reference to this segment"
+			ch.msg("    WARNING: %v; full exception:", comment)
+			ch.msg("%v", err)
+			ch.msg("")
+			result.TotLoseDocCount += toLoseDocCount
+			result.NumBadSegments++
+			continue
+		}
+
+		// Keeper
+		result.newSegments.Segments = append(result.newSegments.Segments, info)
+	}
+
+	if result.NumBadSegments == 0 {
+		result.Clean = true
+	} else {
+		ch.msg("WARNING: %v broken segments (containing %v documents) detected",
+			result.NumBadSegments, result.TotLoseDocCount)
+	}
+
+	if result.ValidCounter = result.MaxSegmentName < sis.counter; !result.ValidCounter {
+		result.Clean = false
+		result.newSegments.counter = result.MaxSegmentName + 1
+		ch.msg("ERROR: Next segment name counter %v is not greater than max segment name %v",
+			sis.counter, result.MaxSegmentName)
+	}
+
+	if result.Clean {
+		ch.msg("No problems were detected with this index.\n")
+	}
+
+	return result, nil
+}
+
+// Reads the latest commit, reporting a panic of the reading code as
+// an error, as a corrupted segments file may well trigger one.
+func readAllRecovered(sis *SegmentInfos, dir store.Directory) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+	return sis.ReadAll(dir)
+}
+
+// Compares dotted version strings like "4.2" numerically.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks a single segment, filling in segInfoStat on the way.
+func (ch *CheckIndex) checkSegment(info SegmentInfoPerCommit,
+	segInfoStat *SegmentInfoStatus, toLoseDocCount *int) (err error) {
+	var reader *SegmentReader
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+		if reader != nil {
+			reader.Close()
+		}
+	}()
+
+	codec := info.info.codec
+	ch.msg("    codec=%v", codec.Name)
+	segInfoStat.Codec = codec.Name
+	ch.msg("    compound=%v", info.info.isCompoundFile)
+	segInfoStat.Compound = info.info.isCompoundFile
+	files := info.Files()
+	ch.msg("    numFiles=%v", len(files))
+	segInfoStat.NumFiles = len(files)
+	var size int64
+	for _, file := range files {
+		length, err := ch.dir.FileLength(file)
+		if err != nil {
+			return err
+		}
+		size += length
+	}
+	segInfoStat.SizeMB = float64(size) / (1024 * 1024)
+	ch.msg("    size (MB)=%.3f", segInfoStat.SizeMB)
+	if diagnostics := info.info.diagnostics; len(diagnostics) > 0 {
+		segInfoStat.Diagnostics = diagnostics
+		ch.msg("    diagnostics = %v", diagnostics)
+	}
+
+	if !info.HasDeletions() {
+		ch.msg("    no deletions")
+		segInfoStat.HasDeletions = false
+	} else {
+		ch.msg("    has deletions [delGen=%v]", info.delGen)
+		segInfoStat.HasDeletions = true
+		segInfoStat.DeletionsGen = info.delGen
+	}
+
+	ch.print("    test: open reader.........")
+	if reader, err = NewSegmentReader(info, DEFAULT_TERMS_INDEX_DIVISOR, store.IO_CONTEXT_DEFAULT); err != nil {
+		reader = nil // already released
+		return err
+	}
+	segInfoStat.OpenReaderPassed = true
+
+	numDocs := reader.NumDocs()
+	*toLoseDocCount = numDocs
+	if reader.MaxDoc() != int(info.info.docCount) {
+		return errors.New(fmt.Sprintf("SegmentReader.MaxDoc() %v != SegmentInfos.docCount %v",
+			reader.MaxDoc(), info.info.docCount))
+	}
+	if info.HasDeletions() {
+		if numDocs != int(info.info.docCount)-info.delCount {
+			return errors.New(fmt.Sprintf("delete count mismatch: info=%v vs reader=%v",
+				int(info.info.docCount)-info.delCount, numDocs))
+		}
+		if int(info.info.docCount)-numDocs > reader.MaxDoc() {
+			return errors.New(fmt.Sprintf("too many deleted docs: maxDoc()=%v vs del count=%v",
+				reader.MaxDoc(), int(info.info.docCount)-numDocs))
+		}
+		if int(info.info.docCount)-numDocs != info.delCount {
+			return errors.New(fmt.Sprintf("delete count mismatch: info=%v vs reader=%v",
+				info.delCount, int(info.info.docCount)-numDocs))
+		}
+		liveDocs := reader.LiveDocs()
+		if liveDocs == nil {
+			return errors.New("segment should have deletions, but liveDocs is nil")
+		}
+		numLive := 0
+		for j := 0; j < liveDocs.Length(); j++ {
+			if liveDocs.Get(j) {
+				numLive++
+			}
+		}
+		if numLive != numDocs {
+			return errors.New(fmt.Sprintf("liveDocs count mismatch: info=%v, vs bits=%v", numDocs, numLive))
+		}
+		segInfoStat.NumDeleted = int(info.info.docCount) - numDocs
+		ch.msg("OK [%v deleted docs]", segInfoStat.NumDeleted)
+	} else {
+		if info.delCount != 0 {
+			return errors.New(fmt.Sprintf("delete count mismatch: info=%v vs reader=%v",
+				info.delCount, int(info.info.docCount)-numDocs))
+		}
+		if liveDocs := reader.LiveDocs(); liveDocs != nil {
+			// it's ok for it to be non-nil here, as long as none are set right?
+			for j := 0; j < liveDocs.Length(); j++ {
+				if !liveDocs.Get(j) {
+					return errors.New(fmt.Sprintf("liveDocs mismatch: info says no deletions but doc %v is deleted.", j))
+				}
+			}
+		}
+		ch.msg("OK")
+	}
+	if reader.MaxDoc() != int(info.info.docCount) {
+		return errors.New(fmt.Sprintf("SegmentReader.MaxDoc() %v != SegmentInfos.docCount %v",
+			reader.MaxDoc(), info.info.docCount))
+	}
+
+	// Test getFieldInfos()
+	ch.print("    test: fields..............")
+	fieldInfos := reader.FieldInfos()
+	ch.msg("OK [%v fields]", len(fieldInfos.values))
+	segInfoStat.NumFields = len(fieldInfos.values)
+
+	// Test Field Norms
+	segInfoStat.FieldNormStatus = ch.testFieldNorms(reader)
+	// Test the Term Index
+	segInfoStat.TermIndexStatus = ch.testPostings(reader)
+	// Test Stored Fields
+	segInfoStat.StoredFieldStatus = ch.testStoredFields(reader)
+	// Test Term Vectors
+	segInfoStat.TermVectorStatus = ch.testTermVectors(reader)
+	// Test Doc Values
+	segInfoStat.DocValuesStatus = ch.testDocValues(reader)
+
+	// Rethrow the first error we encountered
+	// This will cause stats for failed segments to be incremented properly
+	switch {
+	case segInfoStat.FieldNormStatus.Error != nil:
+		return errors.New("Field Norm test failed")
+	case segInfoStat.TermIndexStatus.Error != nil:
+		return errors.New("Term Index test failed")
+	case segInfoStat.StoredFieldStatus.Error != nil:
+		return errors.New("Stored Field test failed")
+	case segInfoStat.TermVectorStatus.Error != nil:
+		return errors.New("Term Vector test failed")
+	case segInfoStat.DocValuesStatus.Error != nil:
+		return errors.New("DocValues test failed")
+	}
+
+	ch.msg("")
+	return nil
+}
+
+// Runs test, reporting a panic as an error as well.
+func (ch *CheckIndex) runTest(test func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+		if err != nil {
+			ch.msg("ERROR [%v]", err)
+		}
+	}()
+	return test()
+}
+
+// Test field norms.
+func (ch *CheckIndex) testFieldNorms(reader *SegmentReader) *FieldNormStatus {
+	status := &FieldNormStatus{}
+	ch.print("    test: field norms.........")
+	status.Error = ch.runTest(func() error {
+		for _, info := range reader.FieldInfos().values {
+			norms, err := reader.NormValues(info.name)
+			if err != nil {
+				return err
+			}
+			if info.normType != 0 {
+				if norms == nil {
+					return errors.New(fmt.Sprintf("field: %v should have norms but omits them!", info.name))
+				}
+				if err = checkNorms(info, reader.MaxDoc(), norms); err != nil {
+					return err
+				}
+				status.TotFields++
+			} else if norms != nil {
+				return errors.New(fmt.Sprintf("field: %v should omit norms but has them!", info.name))
+			}
+		}
+		ch.msg("OK [%v fields]", status.TotFields)
+		return nil
+	})
+	return status
+}
+
+func checkNorms(fi FieldInfo, maxDoc int, norms NumericDocValues) error {
+	switch fi.normType {
+	case DOC_VALUES_TYPE_NUMERIC:
+		for i := 0; i < maxDoc; i++ {
+			norms.Get(i)
+		}
+		return nil
+	default:
+		return errors.New(fmt.Sprintf("wtf: %v", fi.normType))
+	}
+}
+
+/*
+Test the term index, checking that terms are in order, that each
+term's postings agree with its statistics, and that each field's
+statistics agree with its terms.
+*/
+func (ch *CheckIndex) testPostings(reader *SegmentReader) *TermIndexStatus {
+	status := &TermIndexStatus{}
+	ch.print("    test: terms, freq, prox...")
+	status.Error = ch.runTest(func() error {
+		maxDoc := reader.MaxDoc()
+		liveDocs := reader.LiveDocs()
+		fields := reader.Fields()
+		for _, info := range reader.FieldInfos().values {
+			if !info.indexed {
+				if fields != nil && fields.Terms(info.name) != nil {
+					return errors.New(fmt.Sprintf("field %v is not indexed, but has terms", info.name))
+				}
+				continue
+			}
+			if fields == nil {
+				continue
+			}
+			terms := fields.Terms(info.name)
+			if terms == nil {
+				continue
+			}
+			if err := ch.checkTerms(info, terms, maxDoc, liveDocs, status); err != nil {
+				return err
+			}
+			if fr, ok := terms.(*FieldReader); ok && ch.verbose {
+				stats, err := fr.ComputeStats()
+				if err != nil {
+					return err
+				}
+				if status.BlockTreeStats == nil {
+					status.BlockTreeStats = make(map[string]*BlockTreeStats)
+				}
+				status.BlockTreeStats[info.name] = stats
+			}
+		}
+		ch.msg("OK [%v terms; %v terms/docs pairs]", status.TermCount, status.TotFreq)
+		if ch.verbose {
+			for field, stats := range status.BlockTreeStats {
+				ch.msg("      field \"%v\":\n%v", field, stats)
+			}
+		}
+		return nil
+	})
+	return status
+}
+
+func (ch *CheckIndex) checkTerms(info FieldInfo, terms Terms, maxDoc int,
+	liveDocs util.Bits, status *TermIndexStatus) error {
+	hasFreqs := info.indexOptions >= INDEX_OPT_DOCS_AND_FREQS
+
+	var lastTerm []byte
+	var sumTotalTermFreq, sumDocFreq int64
+	visitedDocs := util.NewFixedBitSet(maxDoc)
+	var termCount int64
+	var sampled [][]byte
+	var sampledDocFreqs []int
+
+	termsEnum := terms.Iterator(nil)
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			return err
+		}
+		if term == nil {
+			break
+		}
+		// make sure terms arrive in order according to the comp
+		if lastTerm != nil && bytes.Compare(lastTerm, term) >= 0 {
+			return errors.New(fmt.Sprintf("terms out of order: lastTerm=%v term=%v",
+				brToString(lastTerm), brToString(term)))
+		}
+		lastTerm = append(lastTerm[:0], term...)
+
+		docFreq := termsEnum.DocFreq()
+		if docFreq <= 0 {
+			return errors.New(fmt.Sprintf("docfreq: %v is out of bounds", docFreq))
+		}
+		sumDocFreq += int64(docFreq)
+
+		flags := 0
+		if hasFreqs {
+			flags = DOCS_ENUM_FLAG_FREQS
+		}
+		postings := termsEnum.DocsByFlags(nil, DOCS_ENUM_EMPTY, flags)
+		lastDoc := -1
+		docCount := 0
+		liveDocCount := 0
+		var totalTermFreq int64
+		for {
+			doc, more := postings.NextDoc()
+			if !more || doc == NO_MORE_DOCS {
+				break
+			}
+			visitedDocs.Set(doc)
+			freq := 1
+			if hasFreqs {
+				if freq = postings.Freq(); freq <= 0 {
+					return errors.New(fmt.Sprintf("term %v: doc %v: freq %v is out of bounds",
+						brToString(term), doc, freq))
+				}
+			}
+			totalTermFreq += int64(freq)
+			docCount++
+			if liveDocs == nil || liveDocs.Get(doc) {
+				liveDocCount++
+			}
+			if doc <= lastDoc {
+				return errors.New(fmt.Sprintf("term %v: doc %v <= lastDoc %v",
+					brToString(term), doc, lastDoc))
+			}
+			if doc >= maxDoc {
+				return errors.New(fmt.Sprintf("term %v: doc %v >= maxDoc %v",
+					brToString(term), doc, maxDoc))
+			}
+			lastDoc = doc
+		}
+		status.TotFreq += int64(docCount)
+		if liveDocCount > 0 {
+			status.TermCount++
+		} else {
+			status.DelTermCount++
+		}
+
+		if docCount != docFreq {
+			return errors.New(fmt.Sprintf("term %v docFreq=%v != tot docs w/o deletions %v",
+				brToString(term), docFreq, docCount))
+		}
+		if totalTermFreq2 := termsEnum.TotalTermFreq(); totalTermFreq2 != -1 {
+			if hasFreqs && totalTermFreq != totalTermFreq2 {
+				return errors.New(fmt.Sprintf("term %v totalTermFreq=%v != recomputed totalTermFreq=%v",
+					brToString(term), totalTermFreq2, totalTermFreq))
+			}
+			if totalTermFreq2 < int64(docFreq) {
+				return errors.New(fmt.Sprintf("term %v totalTermFreq=%v < docFreq=%v",
+					brToString(term), totalTermFreq2, docFreq))
+			}
+			sumTotalTermFreq += totalTermFreq2
+		}
+
+		// remember every 7th term to test seeking afterwards
+		if termCount%7 == 0 {
+			sampled = append(sampled, append([]byte(nil), term...))
+			sampledDocFreqs = append(sampledDocFreqs, docFreq)
+		}
+		termCount++
+	}
+
+	if v := terms.SumTotalTermFreq(); v != -1 && sumTotalTermFreq != v {
+		return errors.New(fmt.Sprintf("sumTotalTermFreq for field %v=%v != recomputed sumTotalTermFreq=%v",
+			info.name, v, sumTotalTermFreq))
+	}
+	if v := terms.SumDocFreq(); v != -1 && sumDocFreq != v {
+		return errors.New(fmt.Sprintf("sumDocFreq for field %v=%v != recomputed sumDocFreq=%v",
+			info.name, v, sumDocFreq))
+	}
+	if v := terms.DocCount(); v != -1 {
+		if docCount := visitedDocs.Cardinality(); docCount != v {
+			return errors.New(fmt.Sprintf("docCount for field %v=%v != recomputed docCount=%v",
+				info.name, v, docCount))
+		}
+	}
+
+	// Test seek to last term:
+	if lastTerm != nil {
+		if termsEnum.SeekCeil(lastTerm) != SEEK_STATUS_FOUND {
+			return errors.New(fmt.Sprintf("seek to last term %v failed", brToString(lastTerm)))
+		}
+	}
+
+	// Test seeking to sampled terms:
+	for i, term := range sampled {
+		ok, err := termsEnum.SeekExact(term)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New(fmt.Sprintf("seek to existing term %v failed", brToString(term)))
+		}
+		if docFreq := termsEnum.DocFreq(); docFreq != sampledDocFreqs[i] {
+			return errors.New(fmt.Sprintf("docFreq for term %v=%v != %v after seek",
+				brToString(term), docFreq, sampledDocFreqs[i]))
+		}
+	}
+	return nil
+}
+
+// Test stored fields for a segment.
+func (ch *CheckIndex) testStoredFields(reader *SegmentReader) *StoredFieldStatus {
+	status := &StoredFieldStatus{}
+	ch.print("    test: stored fields.......")
+	status.Error = ch.runTest(func() error {
+		// Scan stored fields for all documents
+		liveDocs := reader.LiveDocs()
+		for j, maxDoc := 0, reader.MaxDoc(); j < maxDoc; j++ {
+			// Intentionally pull even deleted documents to
+			// make sure they too are not corrupt:
+			visitor := NewDocumentStoredFieldVisitor()
+			if err := reader.Document(j, visitor); err != nil {
+				return err
+			}
+			if liveDocs == nil || liveDocs.Get(j) {
+				status.DocCount++
+				status.TotFields += int64(len(visitor.Document().Fields()))
+			}
+		}
+
+		// Validate docCount
+		if status.DocCount != reader.NumDocs() {
+			return errors.New(fmt.Sprintf("docCount=%v but saw %v undeleted docs",
+				status.DocCount, reader.NumDocs()))
+		}
+		ch.msg("OK [%v total field count; avg %.1f fields per doc]",
+			status.TotFields, perDoc(status.TotFields, status.DocCount))
+		return nil
+	})
+	return status
+}
+
+func perDoc(total int64, docCount int) float64 {
+	if docCount == 0 {
+		return 0
+	}
+	return float64(total) / float64(docCount)
+}
+
+// Test term vectors.
+func (ch *CheckIndex) testTermVectors(reader *SegmentReader) *TermVectorStatus {
+	status := &TermVectorStatus{}
+	ch.print("    test: term vectors........")
+	status.Error = ch.runTest(func() error {
+		fieldInfos := reader.FieldInfos()
+		liveDocs := reader.LiveDocs()
+		for j, maxDoc := 0, reader.MaxDoc(); j < maxDoc; j++ {
+			// Intentionally pull/visit (but don't count in
+			// stats) deleted documents to make sure they too
+			// are not corrupt:
+			tfv, err := reader.TermVectors(j)
+			if err != nil {
+				return err
+			}
+			if tfv == nil {
+				continue
+			}
+			vectorCount := 0
+			for _, info := range fieldInfos.values {
+				terms := tfv.Terms(info.name)
+				if terms == nil {
+					continue
+				}
+				if !info.storeTermVector {
+					return errors.New(fmt.Sprintf(
+						"docID=%v has term vectors for field=%v but FieldInfo has storeTermVector=false",
+						j, info.name))
+				}
+				if err = ch.checkTerms(info, terms, 1, nil, &TermIndexStatus{}); err != nil {
+					return err
+				}
+				vectorCount++
+			}
+			if liveDocs == nil || liveDocs.Get(j) {
+				status.DocCount++
+				status.TotVectors += int64(vectorCount)
+			}
+		}
+		ch.msg("OK [%v total vector count; avg %.1f term/freq vector fields per doc]",
+			status.TotVectors, perDoc(status.TotVectors, status.DocCount))
+		return nil
+	})
+	return status
+}
+
+// Test docvalues.
+func (ch *CheckIndex) testDocValues(reader *SegmentReader) *DocValuesStatus {
+	status := &DocValuesStatus{}
+	ch.print("    test: docvalues...........")
+	status.Error = ch.runTest(func() error {
+		for _, info := range reader.FieldInfos().values {
+			if info.docValueType != 0 {
+				status.TotalValueFields++
+				if err := checkDocValues(info, reader); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, dvType := range []DocValuesType{DOC_VALUES_TYPE_NUMERIC, DOC_VALUES_TYPE_BINARY,
+				DOC_VALUES_TYPE_SORTED, DOC_VALUES_TYPE_SORTED_SET} {
+				if ok, err := hasDocValues(reader, info.name, dvType); err != nil {
+					return err
+				} else if ok {
+					return errors.New(fmt.Sprintf("field: %v has docvalues but should omit them!", info.name))
+				}
+			}
+		}
+		ch.msg("OK [%v total doc count; %v docvalues fields]", reader.NumDocs(), status.TotalValueFields)
+		return nil
+	})
+	return status
+}
+
+func hasDocValues(reader AtomicReader, field string, dvType DocValuesType) (bool, error) {
+	switch dvType {
+	case DOC_VALUES_TYPE_NUMERIC:
+		v, err := reader.NumericDocValues(field)
+		return v != nil, err
+	case DOC_VALUES_TYPE_BINARY:
+		v, err := reader.BinaryDocValues(field)
+		return v != nil, err
+	case DOC_VALUES_TYPE_SORTED:
+		v, err := reader.SortedDocValues(field)
+		return v != nil, err
+	case DOC_VALUES_TYPE_SORTED_SET:
+		v, err := reader.SortedSetDocValues(field)
+		return v != nil, err
+	}
+	panic("assert fail")
+}
+
+func checkDocValues(fi FieldInfo, reader AtomicReader) error {
+	// check that only the declared type is exposed
+	for _, dvType := range []DocValuesType{DOC_VALUES_TYPE_NUMERIC, DOC_VALUES_TYPE_BINARY,
+		DOC_VALUES_TYPE_SORTED, DOC_VALUES_TYPE_SORTED_SET} {
+		ok, err := hasDocValues(reader, fi.name, dvType)
+		if err != nil {
+			return err
+		}
+		if ok != (dvType == fi.docValueType) {
+			return errors.New(fmt.Sprintf("field: %v has docvalues type %v but type %v is exposed: %v",
+				fi.name, fi.docValueType, dvType, ok))
+		}
+	}
+
+	maxDoc := reader.MaxDoc()
+	switch fi.docValueType {
+	case DOC_VALUES_TYPE_NUMERIC:
+		dv, _ := reader.NumericDocValues(fi.name)
+		for i := 0; i < maxDoc; i++ {
+			dv.Get(i)
+		}
+	case DOC_VALUES_TYPE_BINARY:
+		dv, _ := reader.BinaryDocValues(fi.name)
+		for i := 0; i < maxDoc; i++ {
+			dv.Get(i)
+		}
+	case DOC_VALUES_TYPE_SORTED:
+		dv, _ := reader.SortedDocValues(fi.name)
+		return checkSortedDocValues(fi.name, maxDoc, dv)
+	case DOC_VALUES_TYPE_SORTED_SET:
+		dv, _ := reader.SortedSetDocValues(fi.name)
+		return checkSortedSetDocValues(fi.name, maxDoc, dv)
+	default:
+		panic("assert fail")
+	}
+	return nil
+}
+
+func checkSortedDocValues(fieldName string, maxDoc int, dv SortedDocValues) error {
+	maxOrd := dv.ValueCount() - 1
+	seenOrds := make([]bool, dv.ValueCount())
+	maxOrd2 := -1
+	for i := 0; i < maxDoc; i++ {
+		ord := dv.Ord(i)
+		if ord < 0 || ord > maxOrd {
+			return errors.New(fmt.Sprintf("ord out of bounds: %v", ord))
+		}
+		if ord > maxOrd2 {
+			maxOrd2 = ord
+		}
+		seenOrds[ord] = true
+	}
+	if maxOrd != maxOrd2 {
+		return errors.New(fmt.Sprintf("dv for field: %v reports wrong maxOrd=%v but this is not the case: %v",
+			fieldName, maxOrd, maxOrd2))
+	}
+	for ord, seen := range seenOrds {
+		if !seen {
+			return errors.New(fmt.Sprintf("dv for field: %v has holes in its ords, ord %v is never used",
+				fieldName, ord))
+		}
+	}
+	var lastValue []byte
+	for i := 0; i <= maxOrd; i++ {
+		term := dv.LookupOrd(i)
+		if lastValue != nil && bytes.Compare(term, lastValue) <= 0 {
+			return errors.New(fmt.Sprintf("dv for field: %v has ords out of order: %v >=%v",
+				fieldName, brToString(lastValue), brToString(term)))
+		}
+		lastValue = append(lastValue[:0], term...)
+	}
+	return nil
+}
+
+func checkSortedSetDocValues(fieldName string, maxDoc int, dv SortedSetDocValues) error {
+	maxOrd := dv.ValueCount() - 1
+	seenOrds := make([]bool, dv.ValueCount())
+	maxOrd2 := int64(-1)
+	for i := 0; i < maxDoc; i++ {
+		dv.SetDocument(i)
+		lastOrd := int64(-1)
+		for ord := dv.NextOrd(); ord != NO_MORE_ORDS; ord = dv.NextOrd() {
+			if ord <= lastOrd {
+				return errors.New(fmt.Sprintf("ords out of order: %v <= %v for doc: %v", ord, lastOrd, i))
+			}
+			if ord < 0 || ord > maxOrd {
+				return errors.New(fmt.Sprintf("ord out of bounds: %v", ord))
+			}
+			lastOrd = ord
+			if ord > maxOrd2 {
+				maxOrd2 = ord
+			}
+			seenOrds[ord] = true
+		}
+	}
+	if maxOrd != maxOrd2 {
+		return errors.New(fmt.Sprintf("dv for field: %v reports wrong maxOrd=%v but this is not the case: %v",
+			fieldName, maxOrd, maxOrd2))
+	}
+	for ord, seen := range seenOrds {
+		if !seen {
+			return errors.New(fmt.Sprintf("dv for field: %v has holes in its ords, ord %v is never used",
+				fieldName, ord))
+		}
+	}
+	var lastValue []byte
+	for i := int64(0); i <= maxOrd; i++ {
+		term := dv.LookupOrd(i)
+		if lastValue != nil && bytes.Compare(term, lastValue) <= 0 {
+			return errors.New(fmt.Sprintf("dv for field: %v has ords out of order: %v >=%v",
+				fieldName, brToString(lastValue), brToString(term)))
+		}
+		lastValue = append(lastValue[:0], term...)
+	}
+	return nil
+}
+
+/*
+Repairs the index using previously returned result from CheckIndex().
+Note that this does not remove any of the unreferenced files after
+it's done; you must separately open an IndexWriter, which deletes
+unreferenced files when it's created.
+
+WARNING: this writes a new segments file into the index, effectively
+removing all documents in broken segments from the index. BE CAREFUL.
+
+WARNING: Make sure you only call this when the index is not opened by
+any writer.
+*/
+func (ch *CheckIndex) FixIndex(result *CheckIndexStatus) error {
+	if result.Partial {
+		return errors.New("can only fix an index that was fully checked (this status checked a subset of segments)")
+	}
+	result.newSegments.changed()
+	return result.newSegments.Commit(result.Dir)
+}
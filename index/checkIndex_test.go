@@ -0,0 +1,102 @@
+package index
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/store"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckIndex(t *testing.T) {
+	dir := copyTestIndex(t, "../search/testdata/win8/belfrysample")
+	defer os.RemoveAll(dir)
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, true)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+	if status.NumSegments != 1 || len(status.SegmentInfos) != 1 || status.SegmentsFileName != "segments_1" {
+		t.Fatalf("Unexpected status: %+v", status)
+	}
+	seg := status.SegmentInfos[0]
+	if !seg.OpenReaderPassed || seg.Codec != "Lucene42" || !seg.Compound {
+		t.Errorf("Unexpected segment status: %+v", seg)
+	}
+	if seg.TermIndexStatus.TermCount == 0 || seg.TermIndexStatus.BlockTreeStats == nil {
+		t.Errorf("Should have checked terms: %+v", seg.TermIndexStatus)
+	}
+	if seg.StoredFieldStatus.DocCount != seg.DocCount {
+		t.Errorf("Expected %v docs with stored fields, but was %v", seg.DocCount, seg.StoredFieldStatus.DocCount)
+	}
+	if !strings.Contains(out.String(), "No problems were detected with this index.") {
+		t.Errorf("Unexpected report:\n%v", out.String())
+	}
+
+	// a delete count which doesn't match the deletions file
+	writeTestBitVector(t, filepath.Join(dir, "_0_1.del"), seg.DocCount, map[int]bool{1: true, 2: true}, false)
+	writeTestSegmentsFile(t, dir, 2, 4, 1, 1)
+	out.Reset()
+	status, err = checker.CheckIndexSegments([]string{"_0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Clean || !status.Partial || status.NumBadSegments != 1 {
+		t.Fatalf("Segment _0 should be broken: %+v", status)
+	}
+	if err = checker.FixIndex(status); err == nil {
+		t.Error("Should not fix from a partial check")
+	}
+	status, err = checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Clean || status.NumBadSegments != 1 || status.TotLoseDocCount != seg.DocCount {
+		t.Fatalf("Segment _0 should be broken: %+v", status)
+	}
+	if !strings.Contains(out.String(), "FAILED") {
+		t.Errorf("Unexpected report:\n%v", out.String())
+	}
+
+	// exorcise the broken segment
+	if err = checker.FixIndex(status); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	status, err = checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean || status.NumSegments != 0 || status.SegmentsFileName != "segments_3" {
+		t.Errorf("Fixed index should be clean and empty: %+v\n%v", status, out.String())
+	}
+}
+
+func TestCheckIndexMissingSegments(t *testing.T) {
+	dir := copyTestIndex(t, "../search/testdata/win8/belfrysample")
+	defer os.RemoveAll(dir)
+	os.Remove(filepath.Join(dir, "segments_1"))
+	os.Remove(filepath.Join(dir, INDEX_FILENAME_SEGMENTS_GEN))
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := NewCheckIndex(d).CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Clean || !status.MissingSegments {
+		t.Errorf("Should report missing segments: %+v", status)
+	}
+}
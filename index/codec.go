@@ -1,6 +1,8 @@
 package index
 
 import (
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/util"
 	"io"
 )
 
@@ -12,6 +14,476 @@ type StoredFieldsReader interface {
 
 type TermVectorsReader interface {
 	io.Closer
-	get(doc int) Fields
+	get(doc int) (Fields, error)
 	clone() TermVectorsReader
 }
+
+// codecs/StoredFieldsWriter.java
+
+/*
+Codec API for writing stored fields:
+
+1. For every document, StartDocument() is called, informing the Codec
+how many fields will be written.
+2. WriteField() is called for each field in the document.
+3. After all documents have been written, Finish() is called for
+verification/sanity-checks.
+4. Finally the writer is closed.
+*/
+type StoredFieldsWriter interface {
+	io.Closer
+	// Called before writing the stored fields of the document.
+	StartDocument(numStoredFields int) error
+	// Called when a document and all its fields have been added.
+	FinishDocument() error
+	// Writes a single stored field.
+	WriteField(info FieldInfo, field document.IndexableField) error
+	// Aborts writing entirely, implementation should remove any
+	// partially-written files, etc.
+	Abort()
+	/*
+		Called before Close(), passing in the number of documents that
+		were written. Note that this is intentionally redundant
+		(equivalent to the number of calls to StartDocument()), but a
+		Codec should check that this is the case to detect the bug
+		described in LUCENE-1282.
+	*/
+	Finish(fis FieldInfos, numDocs int) error
+}
+
+/*
+Merges the stored fields of the live documents of the segments being
+merged, in the order of the readers, and returns the number of
+documents merged.
+*/
+func mergeStoredFields(w StoredFieldsWriter, mergeState *MergeState) (docCount int, err error) {
+	for _, reader := range mergeState.readers {
+		maxDoc, liveDocs := reader.MaxDoc(), reader.LiveDocs()
+		for i := 0; i < maxDoc; i++ {
+			if liveDocs != nil && !liveDocs.Get(i) {
+				// skip deleted docs
+				continue
+			}
+			visitor := NewDocumentStoredFieldVisitor()
+			if err = reader.Document(i, visitor); err != nil {
+				return 0, err
+			}
+			fields := visitor.Document().Fields()
+			if err = w.StartDocument(len(fields)); err != nil {
+				return 0, err
+			}
+			for _, field := range fields {
+				if err = w.WriteField(mergeState.fieldInfos.byName[field.Name()], field); err != nil {
+					return 0, err
+				}
+			}
+			if err = w.FinishDocument(); err != nil {
+				return 0, err
+			}
+			docCount++
+		}
+	}
+	return docCount, w.Finish(mergeState.fieldInfos, docCount)
+}
+
+// codecs/TermVectorsWriter.java
+
+/*
+Codec API for writing term vectors:
+
+1. For every document, StartDocument() is called, informing the Codec
+how many fields will be written.
+2. StartField() is called for each field in the document, informing
+the codec how many terms will be written for that field, and whether
+or not positions, offsets, or payloads are enabled.
+3. Within each field, StartTerm() is called for each term.
+4. If offsets and/or positions are enabled, then AddPosition() will be
+called for each term occurrence.
+5. After all documents have been written, Finish() is called for
+verification/sanity-checks.
+6. Finally the writer is closed.
+*/
+type TermVectorsWriter interface {
+	io.Closer
+	/*
+		Called before writing the term vectors of the document.
+		StartField() will be called numVectorFields times. Note that if
+		term vectors are enabled, this is called even if the document
+		has no vector fields, in this case numVectorFields will be zero.
+	*/
+	StartDocument(numVectorFields int) error
+	// Called after a doc and all its fields have been added.
+	FinishDocument() error
+	// Called before writing the terms of the field. StartTerm() will
+	// be called numTerms times.
+	StartField(info FieldInfo, numTerms int, positions, offsets, payloads bool) error
+	// Called after a field and all its terms have been added.
+	FinishField() error
+	/*
+		Adds a term and its term frequency freq. If this field has
+		positions and/or offsets enabled, then AddPosition() will be
+		called freq times respectively.
+	*/
+	StartTerm(term []byte, freq int) error
+	// Adds a term position and offsets. The offsets are -1 if they
+	// are not indexed, and the payload is nil if there is none.
+	AddPosition(position, startOffset, endOffset int, payload []byte) error
+	// Aborts writing entirely, implementation should remove any
+	// partially-written files, etc.
+	Abort()
+	/*
+		Called before Close(), passing in the number of documents that
+		were written. Note that this is intentionally redundant
+		(equivalent to the number of calls to StartDocument()), but a
+		Codec should check that this is the case to detect the bug
+		described in LUCENE-1282.
+	*/
+	Finish(fis FieldInfos, numDocs int) error
+}
+
+/*
+Merges the term vectors of the live documents of the segments being
+merged, in the order of the readers, and returns the number of
+documents merged.
+*/
+func mergeTermVectors(w TermVectorsWriter, mergeState *MergeState) (docCount int, err error) {
+	for _, reader := range mergeState.readers {
+		maxDoc, liveDocs := reader.MaxDoc(), reader.LiveDocs()
+		for i := 0; i < maxDoc; i++ {
+			if liveDocs != nil && !liveDocs.Get(i) {
+				// skip deleted docs
+				continue
+			}
+			// NOTE: it's very important to first assign to vectors then
+			// pass it to addAllDocVectors; see LUCENE-1282
+			vectors, err := reader.TermVectors(i)
+			if err != nil {
+				return 0, err
+			}
+			if err = addAllDocVectors(w, vectors, mergeState.fieldInfos); err != nil {
+				return 0, err
+			}
+			docCount++
+		}
+	}
+	return docCount, w.Finish(mergeState.fieldInfos, docCount)
+}
+
+// The per-field term vectors of a document, as the term vectors
+// readers return them.
+type termVectorsTerms interface {
+	Terms
+	Size() int64
+	HasPositions() bool
+	HasOffsets() bool
+	HasPayloads() bool
+}
+
+/*
+Safe (but, slowish) default method to write every vector field in the
+document, in field number order.
+*/
+func addAllDocVectors(w TermVectorsWriter, vectors Fields, fieldInfos FieldInfos) error {
+	if vectors == nil {
+		if err := w.StartDocument(0); err != nil {
+			return err
+		}
+		return w.FinishDocument()
+	}
+
+	var fields []FieldInfo
+	for _, fi := range fieldInfos.values {
+		if fi.storeTermVector && vectors.Terms(fi.name) != nil {
+			fields = append(fields, fi)
+		}
+	}
+	if err := w.StartDocument(len(fields)); err != nil {
+		return err
+	}
+
+	var termsEnum TermsEnum
+	var docsAndPositionsEnum DocsAndPositionsEnum
+	for _, fi := range fields {
+		terms := vectors.Terms(fi.name).(termVectorsTerms)
+		hasPositions, hasOffsets := terms.HasPositions(), terms.HasOffsets()
+		if err := w.StartField(fi, int(terms.Size()), hasPositions, hasOffsets,
+			terms.HasPayloads()); err != nil {
+			return err
+		}
+
+		termsEnum = terms.Iterator(termsEnum)
+		for {
+			term, err := termsEnum.Next()
+			if err != nil {
+				return err
+			}
+			if term == nil {
+				break
+			}
+			freq := int(termsEnum.TotalTermFreq())
+			if err = w.StartTerm(term, freq); err != nil {
+				return err
+			}
+			if !hasPositions && !hasOffsets {
+				continue
+			}
+
+			docsAndPositionsEnum = termsEnum.DocsAndPositionsByFlags(nil, docsAndPositionsEnum,
+				DOCS_POSITIONS_ENUM_FLAG_OFF_SETS|DOCS_POSITIONS_ENUM_FLAG_PAYLOADS)
+			if docsAndPositionsEnum.DocsAndPositionsIterator == nil {
+				panic("assert fail")
+			}
+			if doc, more := docsAndPositionsEnum.NextDoc(); !more || doc == NO_MORE_DOCS {
+				panic("assert fail")
+			}
+			for i := 0; i < freq; i++ {
+				position := docsAndPositionsEnum.NextPosition()
+				if err = w.AddPosition(position, docsAndPositionsEnum.StartOffset(),
+					docsAndPositionsEnum.EndOffset(), docsAndPositionsEnum.Payload()); err != nil {
+					return err
+				}
+			}
+		}
+		if err := w.FinishField(); err != nil {
+			return err
+		}
+	}
+	return w.FinishDocument()
+}
+
+// codecs/DocValuesConsumer.java
+
+/*
+Abstract API that consumes numeric, binary and sorted docvalues.
+Concrete implementations of this actually do "something" with the
+docvalues (write it into the index in a specific format).
+
+The lifecycle is:
+
+1. DocValuesConsumer is created by the codec's GetDocValuesConsumer()
+or GetNormsConsumer().
+2. AddNumericField(), AddBinaryField(), AddSortedField() or
+AddSortedSetField() are called for each Numeric, Binary, Sorted or
+SortedSet docvalues field. The API is a "pull" rather than "push",
+and the implementation is free to iterate over the values multiple
+times.
+3. After all fields are added, the consumer is closed.
+*/
+type DocValuesConsumer interface {
+	io.Closer
+	// Writes numeric docvalues for a field, one value per document.
+	AddNumericField(field FieldInfo, values []int64) error
+	// Writes binary docvalues for a field, one value per document.
+	AddBinaryField(field FieldInfo, values [][]byte) error
+	// Writes pre-sorted binary docvalues for a field: the sorted
+	// unique values, and the ordinal of the value of each document.
+	AddSortedField(field FieldInfo, values [][]byte, docToOrd []int64) error
+	/*
+		Writes pre-sorted set docvalues for a field: the sorted unique
+		values, the number of ordinals of each document, and the
+		ordinals of all documents, in increasing order within each
+		document.
+	*/
+	AddSortedSetField(field FieldInfo, values [][]byte, docToOrdCount, ords []int64) error
+}
+
+/*
+Called by the indexing chain when a term's positions and/or offsets
+are available, in the encoding of TermVectorsConsumerPerField: each
+position is a vInt delta shifted left by one, whose low bit tells if
+a payload (length and bytes) follows; each offset is the vInt delta
+of its start offset from the previous end offset, followed by its
+length. Either input may be nil, in which case the position or the
+offsets are passed as -1.
+*/
+func addProx(w TermVectorsWriter, numProx int, positions, offsets util.DataInput) error {
+	position, lastOffset := 0, 0
+	for i := 0; i < numProx; i++ {
+		startOffset, endOffset := -1, -1
+		var payload []byte
+
+		if positions == nil {
+			position = -1
+		} else {
+			code, err := positions.ReadVInt()
+			if err != nil {
+				return err
+			}
+			position += int(uint32(code) >> 1)
+			if (code & 1) != 0 {
+				// This position has a payload
+				n, err := positions.ReadVInt()
+				if err != nil {
+					return err
+				}
+				payload = make([]byte, n)
+				if err = positions.ReadBytes(payload); err != nil {
+					return err
+				}
+			}
+		}
+
+		if offsets != nil {
+			n, err := offsets.ReadVInt()
+			if err != nil {
+				return err
+			}
+			startOffset = lastOffset + int(n)
+			if n, err = offsets.ReadVInt(); err != nil {
+				return err
+			}
+			endOffset = startOffset + int(n)
+			lastOffset = endOffset
+		}
+
+		if err := w.AddPosition(position, startOffset, endOffset, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Merges the numeric docvalues of a field from the segments being
+merged, which values returns for each reader. Documents of a segment
+without values for the field get 0.
+*/
+func mergeNumericField(w DocValuesConsumer, fieldInfo FieldInfo, mergeState *MergeState,
+	values func(r AtomicReader) (NumericDocValues, error)) error {
+	merged := make([]int64, 0, mergeState.segmentInfo.docCount)
+	for _, reader := range mergeState.readers {
+		dv, err := values(reader)
+		if err != nil {
+			return err
+		}
+		maxDoc, liveDocs := reader.MaxDoc(), reader.LiveDocs()
+		for i := 0; i < maxDoc; i++ {
+			if liveDocs != nil && !liveDocs.Get(i) {
+				continue
+			}
+			var v int64
+			if dv != nil {
+				v = dv.Get(i)
+			}
+			merged = append(merged, v)
+		}
+	}
+	return w.AddNumericField(fieldInfo, merged)
+}
+
+/*
+Merges the binary docvalues of a field from the segments being
+merged. Documents of a segment without values for the field get the
+empty value.
+*/
+func mergeBinaryField(w DocValuesConsumer, fieldInfo FieldInfo, mergeState *MergeState) error {
+	merged := make([][]byte, 0, mergeState.segmentInfo.docCount)
+	for _, reader := range mergeState.readers {
+		dv, err := reader.BinaryDocValues(fieldInfo.name)
+		if err != nil {
+			return err
+		}
+		maxDoc, liveDocs := reader.MaxDoc(), reader.LiveDocs()
+		for i := 0; i < maxDoc; i++ {
+			if liveDocs != nil && !liveDocs.Get(i) {
+				continue
+			}
+			var v []byte
+			if dv != nil {
+				v = append(v, dv.Get(i)...)
+			}
+			merged = append(merged, v)
+		}
+	}
+	return w.AddBinaryField(fieldInfo, merged)
+}
+
+/*
+Merges the sorted docvalues of a field from the segments being
+merged. Only the values of live documents are kept, and documents of
+a segment without values for the field get the empty value.
+*/
+func mergeSortedField(w DocValuesConsumer, fieldInfo FieldInfo, mergeState *MergeState) error {
+	hash := newDocValuesBytesRefHash(util.NewCounter(false))
+	termIDs := make([]int, 0, mergeState.segmentInfo.docCount)
+	for _, reader := range mergeState.readers {
+		dv, err := reader.SortedDocValues(fieldInfo.name)
+		if err != nil {
+			return err
+		}
+		maxDoc, liveDocs := reader.MaxDoc(), reader.LiveDocs()
+		for i := 0; i < maxDoc; i++ {
+			if liveDocs != nil && !liveDocs.Get(i) {
+				continue
+			}
+			var v []byte
+			if dv != nil {
+				if ord := dv.Ord(i); ord != -1 {
+					v = dv.LookupOrd(ord)
+				}
+			}
+			termID, err := hash.Add(v)
+			if err != nil {
+				return err
+			}
+			if termID < 0 {
+				termID = -termID - 1
+			}
+			termIDs = append(termIDs, termID)
+		}
+	}
+
+	values, ordMap := sortDocValuesHash(hash)
+	docToOrd := make([]int64, len(termIDs))
+	for docID, termID := range termIDs {
+		docToOrd[docID] = int64(ordMap[termID])
+	}
+	return w.AddSortedField(fieldInfo, values, docToOrd)
+}
+
+/*
+Merges the sorted set docvalues of a field from the segments being
+merged. Only the values of live documents are kept.
+*/
+func mergeSortedSetField(w DocValuesConsumer, fieldInfo FieldInfo, mergeState *MergeState) error {
+	hash := newDocValuesBytesRefHash(util.NewCounter(false))
+	var termIDs []int
+	docToOrdCount := make([]int64, 0, mergeState.segmentInfo.docCount)
+	for _, reader := range mergeState.readers {
+		dv, err := reader.SortedSetDocValues(fieldInfo.name)
+		if err != nil {
+			return err
+		}
+		maxDoc, liveDocs := reader.MaxDoc(), reader.LiveDocs()
+		for i := 0; i < maxDoc; i++ {
+			if liveDocs != nil && !liveDocs.Get(i) {
+				continue
+			}
+			var count int64
+			if dv != nil {
+				dv.SetDocument(i)
+				for ord := dv.NextOrd(); ord != NO_MORE_ORDS; ord = dv.NextOrd() {
+					termID, err := hash.Add(dv.LookupOrd(ord))
+					if err != nil {
+						return err
+					}
+					if termID < 0 {
+						termID = -termID - 1
+					}
+					termIDs = append(termIDs, termID)
+					count++
+				}
+			}
+			docToOrdCount = append(docToOrdCount, count)
+		}
+	}
+
+	// ords of each document stay sorted, since the ords of a segment
+	// follow the order of their values
+	values, ordMap := sortDocValuesHash(hash)
+	ords := make([]int64, len(termIDs))
+	for i, termID := range termIDs {
+		ords[i] = int64(ordMap[termID])
+	}
+	return w.AddSortedSetField(fieldInfo, values, docToOrdCount, ords)
+}
@@ -110,6 +110,25 @@ func (ctx *CompositeReaderContext) Leaves() []AtomicReaderContext {
 	return ans
 }
 
+/*
+Returns index of the leaf containing document docID, which is
+relative to this top-level context. Leaves are searched by their
+docBase, i.e. the same way a composite reader maps docIDs to its
+sub readers.
+*/
+func (ctx *CompositeReaderContext) SubIndex(docID int) int {
+	return SubIndex(docID, ctx.Leaves())
+}
+
+/*
+Returns the leaf containing document docID, which is relative to
+this top-level context, and the docID translated to the leaf.
+Panics if docID is out of range.
+*/
+func (ctx *CompositeReaderContext) LeafForDoc(docID int) (leaf AtomicReaderContext, localDocID int) {
+	return LeafForDoc(docID, ctx.Leaves())
+}
+
 func (ctx *CompositeReaderContext) Children() []IndexReaderContext {
 	return ctx.children
 }
@@ -128,15 +147,15 @@ type CompositeReaderContextBuilder struct {
 	leafDocBase int
 }
 
-func newCompositeReaderContextBuilder(r CompositeReader) CompositeReaderContextBuilder {
-	return CompositeReaderContextBuilder{reader: r, leaves: list.New()}
+func newCompositeReaderContextBuilder(r CompositeReader) *CompositeReaderContextBuilder {
+	return &CompositeReaderContextBuilder{reader: r, leaves: list.New()}
 }
 
-func (b CompositeReaderContextBuilder) build() *CompositeReaderContext {
+func (b *CompositeReaderContextBuilder) build() *CompositeReaderContext {
 	return b.build4(nil, b.reader, 0, 0).(*CompositeReaderContext)
 }
 
-func (b CompositeReaderContextBuilder) build4(parent *CompositeReaderContext,
+func (b *CompositeReaderContextBuilder) build4(parent *CompositeReaderContext,
 	reader IndexReader, ord, docBase int) IndexReaderContext {
 	log.Printf("Building context from %v(parent: %v, %v-%v)", reader, parent, ord, docBase)
 	if ar, ok := reader.(AtomicReader); ok {
@@ -159,8 +178,8 @@ func (b CompositeReaderContextBuilder) build4(parent *CompositeReaderContext,
 	}
 	newDocBase := 0
 	for i, r := range sequentialSubReaders {
-		children[i] = b.build4(parent, r, i, newDocBase)
-		newDocBase = r.MaxDoc()
+		children[i] = b.build4(newParent, r, i, newDocBase)
+		newDocBase += r.MaxDoc()
 	}
 	// assert newDocBase == cr.maxDoc()
 	return newParent
@@ -205,7 +224,7 @@ func newBaseCompositeReader(self IndexReader, readers []IndexReader) *BaseCompos
 		}
 		numDocs += r.NumDocs() // compute numDocs
 		log.Printf("Obtained %v docs (max %v)", numDocs, maxDoc)
-		r.registerParentReader(ans)
+		r.registerParentReader(self)
 	}
 	ans.starts[len(readers)] = maxDoc
 	ans.maxDoc = maxDoc
@@ -214,11 +233,10 @@ func newBaseCompositeReader(self IndexReader, readers []IndexReader) *BaseCompos
 	return ans
 }
 
-func (r *BaseCompositeReader) TermVectors(docID int) error {
+func (r *BaseCompositeReader) TermVectors(docID int) (fs Fields, err error) {
 	r.ensureOpen()
-	panic("not implemented yet")
-	// i := readerIndex(docID)
-	// return r.subReaders[i].TermVectors(docID - starts[i])
+	i := r.readerIndex(docID)                               // find subreader num
+	return r.subReaders[i].TermVectors(docID - r.starts[i]) // dispatch to subreader
 }
 
 func (r *BaseCompositeReader) NumDocs() int {
@@ -230,27 +248,86 @@ func (r *BaseCompositeReader) MaxDoc() int {
 }
 
 func (r *BaseCompositeReader) Document(docID int, visitor StoredFieldVisitor) error {
-	panic("not implemented yet")
+	r.ensureOpen()
+	i := r.readerIndex(docID)                                   // find subreader num
+	return r.subReaders[i].Document(docID-r.starts[i], visitor) // dispatch to subreader
 }
 
-func (r *BaseCompositeReader) DocFreq(term Term) int {
-	panic("not implemented yet")
+func (r *BaseCompositeReader) DocFreq(term Term) (n int, err error) {
+	r.ensureOpen()
+	total := 0 // sum freqs in subreaders
+	for _, sub := range r.subReaders {
+		v, err := sub.DocFreq(term)
+		if err != nil {
+			return 0, err
+		}
+		total += v
+	}
+	return total, nil
 }
 
-func (r *BaseCompositeReader) TotalTermFreq(term Term) int64 {
-	panic("not implemented yet")
+func (r *BaseCompositeReader) TotalTermFreq(term Term) (n int64, err error) {
+	r.ensureOpen()
+	total := int64(0) // sum freqs in subreaders
+	for _, sub := range r.subReaders {
+		v, err := sub.TotalTermFreq(term)
+		if err != nil {
+			return 0, err
+		}
+		if v == -1 {
+			return -1, nil
+		}
+		total += v
+	}
+	return total, nil
 }
 
-func (r *BaseCompositeReader) SumDocFreq(field string) int64 {
-	panic("not implemented yet")
+func (r *BaseCompositeReader) SumDocFreq(field string) (n int64, err error) {
+	r.ensureOpen()
+	total := int64(0) // sum doc freqs in subreaders
+	for _, sub := range r.subReaders {
+		v, err := sub.SumDocFreq(field)
+		if err != nil {
+			return 0, err
+		}
+		if v == -1 {
+			return -1, nil // if any of the subs doesn't support it, return -1
+		}
+		total += v
+	}
+	return total, nil
 }
 
-func (r *BaseCompositeReader) DocCount(field string) int {
-	panic("not implemented yet")
+func (r *BaseCompositeReader) DocCount(field string) (n int, err error) {
+	r.ensureOpen()
+	total := 0 // sum doc counts in subreaders
+	for _, sub := range r.subReaders {
+		v, err := sub.DocCount(field)
+		if err != nil {
+			return 0, err
+		}
+		if v == -1 {
+			return -1, nil // if any of the subs doesn't support it, return -1
+		}
+		total += v
+	}
+	return total, nil
 }
 
-func (r *BaseCompositeReader) SumTotalTermFreq(field string) int64 {
-	panic("not implemented yet")
+func (r *BaseCompositeReader) SumTotalTermFreq(field string) (n int64, err error) {
+	r.ensureOpen()
+	total := int64(0) // sum doc total term freqs in subreaders
+	for _, sub := range r.subReaders {
+		v, err := sub.SumTotalTermFreq(field)
+		if err != nil {
+			return 0, err
+		}
+		if v == -1 {
+			return -1, nil // if any of the subs doesn't support it, return -1
+		}
+		total += v
+	}
+	return total, nil
 }
 
 func (r *BaseCompositeReader) readerIndex(docID int) int {
@@ -261,7 +338,10 @@ func (r *BaseCompositeReader) readerIndex(docID int) int {
 }
 
 func (r *BaseCompositeReader) readerBase(readerIndex int) int {
-	panic("not implemented yet")
+	if readerIndex < 0 || readerIndex >= len(r.subReaders) {
+		panic("readerIndex must be >= 0 and < getSequentialSubReaders().size()")
+	}
+	return r.starts[readerIndex]
 }
 
 func (r *BaseCompositeReader) getSequentialSubReaders() []IndexReader {
@@ -0,0 +1,144 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
+)
+
+// CompressingStoredFieldsFormat.java
+
+/*
+A stored fields format that compresses documents in chunks in order
+to improve the compression ratio.
+
+For a chunk size of chunkSize bytes, this format does not support
+documents larger than (2^31 - chunkSize) bytes. In case this is a
+problem, you should use another format, such as Lucene40's.
+
+For optimal performance, you should use a MergePolicy that returns
+segments that have the biggest byte size first.
+*/
+type CompressingStoredFieldsFormat struct {
+	formatName      string
+	segmentSuffix   string
+	compressionMode codec.CompressionMode
+	chunkSize       int
+}
+
+/*
+Creates a new CompressingStoredFieldsFormat.
+
+formatName is the name of the format. This name will be used in the
+file formats to perform codec header checks.
+
+segmentSuffix is the segment suffix. This suffix is added to the
+result file name only if it's not the empty string.
+
+The compressionMode parameter allows you to choose between
+compression algorithms that have various compression and
+decompression speeds so that you can pick the one that best fits
+your indexing and searching throughput. You should never instantiate
+two CompressingStoredFieldsFormats that have the same name but
+different CompressionModes.
+
+chunkSize is the minimum byte size of a chunk of documents. A value
+of 1 can make sense if there is redundancy across fields. In that
+case, both performance and compression ratio should be better than
+with Lucene40's format with compressed fields.
+
+Higher values of chunkSize should improve the compression ratio but
+will require more memory at indexing time and might make document
+loading a little slower (depending on the size of your OS cache
+compared to the size of your index).
+*/
+func NewCompressingStoredFieldsFormat(formatName, segmentSuffix string,
+	compressionMode codec.CompressionMode, chunkSize int) *CompressingStoredFieldsFormat {
+	if chunkSize < 1 {
+		panic("chunkSize must be >= 1")
+	}
+	return &CompressingStoredFieldsFormat{formatName, segmentSuffix, compressionMode, chunkSize}
+}
+
+func (f *CompressingStoredFieldsFormat) FieldsReader(d store.Directory, si SegmentInfo,
+	fn FieldInfos, ctx store.IOContext) (r StoredFieldsReader, err error) {
+	p, err := newCompressingStoredFieldsReader(d, si, f.segmentSuffix, fn, ctx,
+		f.formatName, f.compressionMode)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (f *CompressingStoredFieldsFormat) FieldsWriter(d store.Directory, si *SegmentInfo,
+	ctx store.IOContext) (w StoredFieldsWriter, err error) {
+	p, err := newCompressingStoredFieldsWriter(d, si, f.segmentSuffix, ctx,
+		f.formatName, f.compressionMode, f.chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (f *CompressingStoredFieldsFormat) String() string {
+	return fmt.Sprintf("CompressingStoredFieldsFormat(compressionMode=%v, chunkSize=%v)",
+		f.compressionMode, f.chunkSize)
+}
+
+// Lucene41StoredFieldsFormat.java
+
+// Configuration option for the stored fields of the Lucene42 codec.
+type StoredFieldsMode int
+
+const (
+	// Trade compression ratio for retrieval speed: LZ4 compressed
+	// chunks of 16KB, which is the original Lucene41 stored fields
+	// format.
+	STORED_FIELDS_BEST_SPEED = StoredFieldsMode(1)
+	// Trade retrieval speed for compression ratio: deflate compressed
+	// chunks of 60KB.
+	STORED_FIELDS_BEST_COMPRESSION = StoredFieldsMode(2)
+)
+
+// Attribute key for the stored fields mode of a segment.
+const LUCENE41_SF_MODE_KEY = "Lucene41StoredFieldsFormat.mode"
+
+func (m StoredFieldsMode) String() string {
+	switch m {
+	case STORED_FIELDS_BEST_SPEED:
+		return "BEST_SPEED"
+	case STORED_FIELDS_BEST_COMPRESSION:
+		return "BEST_COMPRESSION"
+	}
+	panic("assert fail")
+}
+
+func (m StoredFieldsMode) format() *CompressingStoredFieldsFormat {
+	switch m {
+	case STORED_FIELDS_BEST_SPEED:
+		return NewCompressingStoredFieldsFormat("Lucene41StoredFields", "",
+			codec.COMPRESSION_MODE_FAST, 1<<14)
+	case STORED_FIELDS_BEST_COMPRESSION:
+		return NewCompressingStoredFieldsFormat("Lucene41StoredFields", "",
+			codec.COMPRESSION_MODE_HIGH_COMPRESSION, 61440)
+	}
+	panic("assert fail")
+}
+
+/*
+Returns the stored fields mode recorded in the segment's attributes.
+Segments without the attribute were written with
+STORED_FIELDS_BEST_SPEED.
+*/
+func storedFieldsModeOf(si SegmentInfo) (StoredFieldsMode, error) {
+	switch value := si.attributes[LUCENE41_SF_MODE_KEY]; value {
+	case "", STORED_FIELDS_BEST_SPEED.String():
+		return STORED_FIELDS_BEST_SPEED, nil
+	case STORED_FIELDS_BEST_COMPRESSION.String():
+		return STORED_FIELDS_BEST_COMPRESSION, nil
+	default:
+		return 0, errors.New(fmt.Sprintf(
+			"invalid stored fields mode '%v' for segment %v", value, si.name))
+	}
+}
@@ -0,0 +1,504 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+)
+
+// CompressingStoredFieldsWriter.java
+
+const (
+	// hard limit on the maximum number of documents per chunk
+	CODEC_SFX_MAX_DOCUMENTS_PER_CHUNK = 128
+)
+
+/*
+StoredFieldsWriter impl for CompressingStoredFieldsFormat.
+
+Documents are buffered in memory until they reach chunkSize bytes or
+CODEC_SFX_MAX_DOCUMENTS_PER_CHUNK documents, at which point the chunk
+is compressed and flushed, and its start pointer recorded in the
+fields index.
+*/
+type CompressingStoredFieldsWriter struct {
+	directory    store.Directory
+	segment      string
+	indexWriter  *CompressingStoredFieldsIndexWriter
+	fieldsStream store.IndexOutput
+
+	compressor codec.Compressor
+	chunkSize  int
+
+	bufferedDocs    *store.RAMOutputStream
+	numStoredFields []int // number of stored fields
+	endOffsets      []int // end offsets in bufferedDocs
+	docBase         int   // doc ID at the beginning of the chunk
+	numBufferedDocs int   // docBase + numBufferedDocs == current doc ID
+}
+
+func newCompressingStoredFieldsWriter(dir store.Directory, si *SegmentInfo, segmentSuffix string,
+	ctx store.IOContext, formatName string, compressionMode codec.CompressionMode,
+	chunkSize int) (w *CompressingStoredFieldsWriter, err error) {
+	if dir == nil {
+		panic("assert fail")
+	}
+	w = &CompressingStoredFieldsWriter{
+		directory:       dir,
+		segment:         si.name,
+		compressor:      compressionMode.NewCompressor(),
+		chunkSize:       chunkSize,
+		bufferedDocs:    store.NewRAMOutputStream(),
+		numStoredFields: make([]int, 16),
+		endOffsets:      make([]int, 16),
+	}
+
+	var indexStream store.IndexOutput
+	success := false
+	defer func() {
+		if !success {
+			util.CloseWhileSuppressingError(indexStream)
+			w.Abort()
+		}
+	}()
+
+	indexStream, err = dir.CreateOutput(util.SegmentFileName(w.segment, segmentSuffix, LUCENE40_SF_FIELDS_INDEX_EXTENSION), ctx)
+	if err != nil {
+		return nil, err
+	}
+	w.fieldsStream, err = dir.CreateOutput(util.SegmentFileName(w.segment, segmentSuffix, LUCENE40_SF_FIELDS_EXTENSION), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codecNameIdx := formatName + CODEC_SFX_IDX
+	codecNameDat := formatName + CODEC_SFX_DAT
+	if err = codec.WriteHeader(indexStream, codecNameIdx, CODEC_SFX_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	if err = codec.WriteHeader(w.fieldsStream, codecNameDat, CODEC_SFX_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	if int64(codec.HeaderLength(codecNameDat)) != w.fieldsStream.FilePointer() {
+		panic("assert fail")
+	}
+	if int64(codec.HeaderLength(codecNameIdx)) != indexStream.FilePointer() {
+		panic("assert fail")
+	}
+
+	if w.indexWriter, err = newCompressingStoredFieldsIndexWriter(indexStream); err != nil {
+		return nil, err
+	}
+	indexStream = nil
+
+	if err = w.fieldsStream.WriteVInt(util.PACKED_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+
+	success = true
+	return w, nil
+}
+
+func (w *CompressingStoredFieldsWriter) Close() error {
+	defer func() {
+		w.fieldsStream = nil
+		w.indexWriter = nil
+	}()
+	return util.Close(w.fieldsStream, w.indexWriter)
+}
+
+func (w *CompressingStoredFieldsWriter) StartDocument(numStoredFields int) error {
+	if w.numBufferedDocs == len(w.numStoredFields) {
+		w.numStoredFields = append(w.numStoredFields, 0)
+		w.endOffsets = append(w.endOffsets, 0)
+	}
+	w.numStoredFields[w.numBufferedDocs] = numStoredFields
+	w.numBufferedDocs++
+	return nil
+}
+
+func (w *CompressingStoredFieldsWriter) FinishDocument() error {
+	w.endOffsets[w.numBufferedDocs-1] = int(w.bufferedDocs.FilePointer())
+	if w.triggerFlush() {
+		return w.flush()
+	}
+	return nil
+}
+
+func saveInts(values []int, out store.IndexOutput) error {
+	if len(values) == 0 {
+		panic("assert fail")
+	}
+	if len(values) == 1 {
+		return out.WriteVInt(int32(values[0]))
+	}
+
+	allEqual := true
+	for _, v := range values[1:] {
+		if v != values[0] {
+			allEqual = false
+			break
+		}
+	}
+	if allEqual {
+		if err := out.WriteVInt(0); err != nil {
+			return err
+		}
+		return out.WriteVInt(int32(values[0]))
+	}
+
+	max := int64(0)
+	for _, v := range values {
+		max |= int64(v)
+	}
+	bitsRequired := util.BitsRequired(max)
+	if err := out.WriteVInt(int32(bitsRequired)); err != nil {
+		return err
+	}
+	pw := util.NewPackedWriterNoHeader(out, util.PACKED, int32(len(values)), bitsRequired)
+	for _, v := range values {
+		if err := pw.Add(int64(v)); err != nil {
+			return err
+		}
+	}
+	return pw.Finish()
+}
+
+func (w *CompressingStoredFieldsWriter) writeHeader(docBase, numBufferedDocs int, numStoredFields, lengths []int) error {
+	// save docBase and numBufferedDocs
+	if err := w.fieldsStream.WriteVInt(int32(docBase)); err != nil {
+		return err
+	}
+	if err := w.fieldsStream.WriteVInt(int32(numBufferedDocs)); err != nil {
+		return err
+	}
+	// save numStoredFields
+	if err := saveInts(numStoredFields[:numBufferedDocs], w.fieldsStream); err != nil {
+		return err
+	}
+	// save lengths
+	return saveInts(lengths[:numBufferedDocs], w.fieldsStream)
+}
+
+func (w *CompressingStoredFieldsWriter) triggerFlush() bool {
+	return int(w.bufferedDocs.FilePointer()) >= w.chunkSize || // chunks of at least chunkSize bytes
+		w.numBufferedDocs >= CODEC_SFX_MAX_DOCUMENTS_PER_CHUNK
+}
+
+func (w *CompressingStoredFieldsWriter) flush() error {
+	if err := w.indexWriter.writeIndex(w.numBufferedDocs, w.fieldsStream.FilePointer()); err != nil {
+		return err
+	}
+
+	// transform end offsets into lengths
+	lengths := w.endOffsets
+	for i := w.numBufferedDocs - 1; i > 0; i-- {
+		lengths[i] = w.endOffsets[i] - w.endOffsets[i-1]
+		if lengths[i] < 0 {
+			panic("assert fail")
+		}
+	}
+	if err := w.writeHeader(w.docBase, w.numBufferedDocs, w.numStoredFields, lengths); err != nil {
+		return err
+	}
+
+	// compress stored fields to fieldsStream
+	bytes := make([]byte, w.bufferedDocs.FilePointer())
+	w.bufferedDocs.WriteToBytes(bytes)
+	if err := w.compressor.Compress(bytes, w.fieldsStream); err != nil {
+		return err
+	}
+
+	// reset
+	w.docBase += w.numBufferedDocs
+	w.numBufferedDocs = 0
+	w.bufferedDocs.Reset()
+	return nil
+}
+
+func (w *CompressingStoredFieldsWriter) WriteField(info FieldInfo, field document.IndexableField) (err error) {
+	var bits int
+	var bytes []byte
+	var str string
+
+	number := field.NumericValue()
+	if number != nil {
+		switch number.(type) {
+		case int:
+			bits = CODEC_SFX_NUMERIC_INT
+		case int64:
+			bits = CODEC_SFX_NUMERIC_LONG
+		case float32:
+			bits = CODEC_SFX_NUMERIC_FLOAT
+		case float64:
+			bits = CODEC_SFX_NUMERIC_DOUBLE
+		default:
+			panic("cannot store numeric type")
+		}
+	} else if bytes = field.BinaryValue(); bytes != nil {
+		bits = CODEC_SFX_BYTE_ARR
+	} else {
+		bits = CODEC_SFX_STRING
+		str = field.StringValue()
+	}
+
+	infoAndBits := (int64(info.number) << CODEC_SFX_TYPE_BITS) | int64(bits)
+	if err = w.bufferedDocs.WriteVLong(infoAndBits); err != nil {
+		return err
+	}
+
+	switch {
+	case bytes != nil:
+		if err = w.bufferedDocs.WriteVInt(int32(len(bytes))); err == nil {
+			err = w.bufferedDocs.WriteBytes(bytes)
+		}
+	case number == nil:
+		err = w.bufferedDocs.WriteString(str)
+	default:
+		switch v := number.(type) {
+		case int:
+			err = w.bufferedDocs.WriteInt(int32(v))
+		case int64:
+			err = w.bufferedDocs.WriteLong(v)
+		case float32:
+			err = w.bufferedDocs.WriteInt(int32(math.Float32bits(v)))
+		case float64:
+			err = w.bufferedDocs.WriteLong(int64(math.Float64bits(v)))
+		}
+	}
+	return err
+}
+
+// Aborts writing entirely, deleting any partially written files.
+func (w *CompressingStoredFieldsWriter) Abort() {
+	util.CloseWhileSuppressingError(w)
+	for _, ext := range []string{LUCENE40_SF_FIELDS_EXTENSION, LUCENE40_SF_FIELDS_INDEX_EXTENSION} {
+		w.directory.DeleteFile(util.SegmentFileName(w.segment, "", ext))
+	}
+}
+
+func (w *CompressingStoredFieldsWriter) Finish(fis FieldInfos, numDocs int) error {
+	if w.numBufferedDocs > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	} else if w.bufferedDocs.FilePointer() != 0 {
+		panic("assert fail")
+	}
+	if w.docBase != numDocs {
+		return errors.New(fmt.Sprintf("Wrote %v docs, finish called with numDocs=%v", w.docBase, numDocs))
+	}
+	return w.indexWriter.finish(numDocs)
+}
+
+// CompressingStoredFieldsIndexWriter.java
+
+// number of chunks to serialize at once
+const CODEC_SFX_INDEX_BLOCK_SIZE = 1024
+
+func moveSignToLowOrderBit(n int64) int64 {
+	return (n >> 63) ^ (n << 1)
+}
+
+/*
+Efficient index format for block-based Codecs.
+
+This writer generates a file which can be loaded into memory using
+memory-efficient data structures to quickly locate the block that
+contains any document.
+
+In order to have a compact in-memory representation, for every block
+of 1024 chunks, this index computes the average number of bytes per
+chunk and for every chunk, only stores the difference between
+
+- ${chunk number} * ${average length of a chunk}
+- and the actual start offset of the chunk
+
+Data is written as follows:
+
+	FieldsIndex (.fdx) --> <Header>, FieldsIndex, PackedIntsVersion, <Block>^BlockCount, BlocksEndMarker
+	Header --> CodecHeader
+	PackedIntsVersion --> PACKED_VERSION_CURRENT as a VInt
+	BlocksEndMarker --> 0 as a VInt, this marks the end of blocks since blocks are not allowed to start with 0
+	Block --> BlockChunks, <DocBases>, <StartPointers>
+	BlockChunks --> a VInt which is the number of chunks encoded in the block
+	DocBases --> DocBase, AvgChunkDocs, BitsPerDocBaseDelta, DocBaseDeltas
+	DocBase --> first document ID of the block of chunks, as a VInt
+	AvgChunkDocs --> average number of documents in a single chunk, as a VInt
+	BitsPerDocBaseDelta --> number of bits required to represent a delta from the average using ZigZag encoding
+	DocBaseDeltas --> packed array of BlockChunks elements of BitsPerDocBaseDelta bits each, representing the deltas from the average doc base using ZigZag encoding.
+	StartPointers --> StartPointerBase, AvgChunkSize, BitsPerStartPointerDelta, StartPointerDeltas
+	StartPointerBase --> the first start pointer of the block, as a VLong
+	AvgChunkSize --> the average size of a chunk of compressed documents, as a VLong
+	BitsPerStartPointerDelta --> number of bits required to represent a delta from the average using ZigZag encoding
+	StartPointerDeltas --> packed array of BlockChunks elements of BitsPerStartPointerDelta bits each, representing the deltas from the average start pointer using ZigZag encoding
+*/
+type CompressingStoredFieldsIndexWriter struct {
+	fieldsIndexOut     store.IndexOutput
+	totalDocs          int
+	blockDocs          int
+	blockChunks        int
+	firstStartPointer  int64
+	maxStartPointer    int64
+	docBaseDeltas      []int
+	startPointerDeltas []int64
+}
+
+func newCompressingStoredFieldsIndexWriter(indexOutput store.IndexOutput) (*CompressingStoredFieldsIndexWriter, error) {
+	w := &CompressingStoredFieldsIndexWriter{
+		fieldsIndexOut:     indexOutput,
+		docBaseDeltas:      make([]int, CODEC_SFX_INDEX_BLOCK_SIZE),
+		startPointerDeltas: make([]int64, CODEC_SFX_INDEX_BLOCK_SIZE),
+	}
+	w.reset()
+	if err := indexOutput.WriteVInt(util.PACKED_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *CompressingStoredFieldsIndexWriter) reset() {
+	w.blockChunks = 0
+	w.blockDocs = 0
+	w.firstStartPointer = -1 // means unset
+}
+
+func (w *CompressingStoredFieldsIndexWriter) writeBlock() error {
+	if w.blockChunks <= 0 {
+		panic("assert fail")
+	}
+	out := w.fieldsIndexOut
+	if err := out.WriteVInt(int32(w.blockChunks)); err != nil {
+		return err
+	}
+
+	// The trick here is that we only store the difference from the
+	// average start pointer or doc base, this helps save bits per
+	// value. And in order to prevent a few chunks that would be far
+	// from the average to raise the number of bits per value for all
+	// of them, we only encode blocks of 1024 chunks at once.
+	// See LUCENE-4512
+
+	// doc bases
+	var avgChunkDocs int
+	if w.blockChunks > 1 {
+		avgChunkDocs = int(math.Floor(float64(w.blockDocs-w.docBaseDeltas[w.blockChunks-1])/
+			float64(w.blockChunks-1) + 0.5))
+	}
+	if err := out.WriteVInt(int32(w.totalDocs - w.blockDocs)); err != nil { // docBase
+		return err
+	}
+	if err := out.WriteVInt(int32(avgChunkDocs)); err != nil {
+		return err
+	}
+	docBase := 0
+	maxDelta := int64(0)
+	for i := 0; i < w.blockChunks; i++ {
+		delta := docBase - avgChunkDocs*i
+		maxDelta |= moveSignToLowOrderBit(int64(delta))
+		docBase += w.docBaseDeltas[i]
+	}
+
+	bitsPerDocBase := util.BitsRequired(maxDelta)
+	if err := out.WriteVInt(int32(bitsPerDocBase)); err != nil {
+		return err
+	}
+	writer := util.NewPackedWriterNoHeader(out, util.PACKED, int32(w.blockChunks), bitsPerDocBase)
+	docBase = 0
+	for i := 0; i < w.blockChunks; i++ {
+		delta := docBase - avgChunkDocs*i
+		if util.BitsRequired(moveSignToLowOrderBit(int64(delta))) > bitsPerDocBase {
+			panic("assert fail")
+		}
+		if err := writer.Add(moveSignToLowOrderBit(int64(delta))); err != nil {
+			return err
+		}
+		docBase += w.docBaseDeltas[i]
+	}
+	if err := writer.Finish(); err != nil {
+		return err
+	}
+
+	// start pointers
+	if err := out.WriteVLong(w.firstStartPointer); err != nil {
+		return err
+	}
+	var avgChunkSize int64
+	if w.blockChunks > 1 {
+		avgChunkSize = (w.maxStartPointer - w.firstStartPointer) / int64(w.blockChunks-1)
+	}
+	if err := out.WriteVLong(avgChunkSize); err != nil {
+		return err
+	}
+	startPointer := int64(0)
+	maxDelta = 0
+	for i := 0; i < w.blockChunks; i++ {
+		startPointer += w.startPointerDeltas[i]
+		delta := startPointer - avgChunkSize*int64(i)
+		maxDelta |= moveSignToLowOrderBit(delta)
+	}
+
+	bitsPerStartPointer := util.BitsRequired(maxDelta)
+	if err := out.WriteVInt(int32(bitsPerStartPointer)); err != nil {
+		return err
+	}
+	writer = util.NewPackedWriterNoHeader(out, util.PACKED, int32(w.blockChunks), bitsPerStartPointer)
+	startPointer = 0
+	for i := 0; i < w.blockChunks; i++ {
+		startPointer += w.startPointerDeltas[i]
+		delta := startPointer - avgChunkSize*int64(i)
+		if util.BitsRequired(moveSignToLowOrderBit(delta)) > bitsPerStartPointer {
+			panic("assert fail")
+		}
+		if err := writer.Add(moveSignToLowOrderBit(delta)); err != nil {
+			return err
+		}
+	}
+	return writer.Finish()
+}
+
+func (w *CompressingStoredFieldsIndexWriter) writeIndex(numDocs int, startPointer int64) error {
+	if w.blockChunks == CODEC_SFX_INDEX_BLOCK_SIZE {
+		if err := w.writeBlock(); err != nil {
+			return err
+		}
+		w.reset()
+	}
+
+	if w.firstStartPointer == -1 {
+		w.firstStartPointer = startPointer
+		w.maxStartPointer = startPointer
+	}
+	if startPointer < w.maxStartPointer {
+		panic("assert fail")
+	}
+
+	w.docBaseDeltas[w.blockChunks] = numDocs
+	w.startPointerDeltas[w.blockChunks] = startPointer - w.maxStartPointer
+
+	w.blockChunks++
+	w.blockDocs += numDocs
+	w.totalDocs += numDocs
+	w.maxStartPointer = startPointer
+	return nil
+}
+
+func (w *CompressingStoredFieldsIndexWriter) finish(numDocs int) error {
+	if numDocs != w.totalDocs {
+		return errors.New(fmt.Sprintf("Expected %v docs, but got %v", numDocs, w.totalDocs))
+	}
+	if w.blockChunks > 0 {
+		if err := w.writeBlock(); err != nil {
+			return err
+		}
+	}
+	return w.fieldsIndexOut.WriteVInt(0) // end marker
+}
+
+func (w *CompressingStoredFieldsIndexWriter) Close() error {
+	return w.fieldsIndexOut.Close()
+}
@@ -0,0 +1,105 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/store"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCompressingStoredFieldsWriter(t *testing.T) {
+	// a custom chunk size is readable with the default format, as long
+	// as the compression mode matches
+	smallChunks := NewLucene42Codec()
+	smallChunks.GetStoredFieldsWriter = NewCompressingStoredFieldsFormat(
+		"Lucene41StoredFields", "", codec.COMPRESSION_MODE_FAST, 1).FieldsWriter
+
+	for _, test := range []struct {
+		codec Codec
+		mode  string
+	}{
+		{NewLucene42Codec(), "BEST_SPEED"},
+		{NewLucene42CodecWithMode(STORED_FIELDS_BEST_COMPRESSION), "BEST_COMPRESSION"},
+		{smallChunks, ""},
+	} {
+		path, err := ioutil.TempDir("", "golucene")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(path)
+		d, err := store.OpenFSDirectory(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// more docs than fit in one chunk, and a doc larger than a chunk
+		const numDocs = 500
+		large := strings.Repeat("large document ", 10000)
+		w, err := NewIndexWriter(d, NewIndexWriterConfig().SetCodec(test.codec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < numDocs; i++ {
+			doc := []document.IndexableField{
+				document.NewStoredFieldFromString("body", fmt.Sprintf("document number %v", i)),
+				document.NewStoredFieldFromBytes("bytes", bytes.Repeat([]byte{byte(i)}, i%50)),
+				document.NewStoredFieldFromLong("long", int64(i)<<40),
+			}
+			if i == 42 {
+				doc = append(doc, document.NewStoredFieldFromString("large", large))
+			}
+			if err = w.AddDocument(doc); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := OpenDirectoryReader(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf := r.Leaves()[0].Reader().(*SegmentReader)
+		assertEquals(t, test.mode, leaf.si.info.attributes[LUCENE41_SF_MODE_KEY])
+		for i := 0; i < numDocs; i++ {
+			visitor := NewDocumentStoredFieldVisitor()
+			if err = r.Document(i, visitor); err != nil {
+				t.Fatal(err)
+			}
+			doc := visitor.Document()
+			assertEquals(t, fmt.Sprintf("document number %v", i), doc.Get("body"))
+			assertEquals(t, fmt.Sprintf("%v", int64(i)<<40), doc.Get("long"))
+			if b := doc.BinaryValue("bytes"); !bytes.Equal(bytes.Repeat([]byte{byte(i)}, i%50), b) {
+				t.Errorf("Unexpected bytes of doc %v: %v", i, b)
+			}
+			if i == 42 {
+				assertEquals(t, large, doc.Get("large"))
+			}
+		}
+		if err = r.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStoredFieldsModeOf(t *testing.T) {
+	for value, expected := range map[string]StoredFieldsMode{
+		"":                 STORED_FIELDS_BEST_SPEED,
+		"BEST_SPEED":       STORED_FIELDS_BEST_SPEED,
+		"BEST_COMPRESSION": STORED_FIELDS_BEST_COMPRESSION,
+	} {
+		mode, err := storedFieldsModeOf(SegmentInfo{attributes: map[string]string{LUCENE41_SF_MODE_KEY: value}})
+		if err != nil || mode != expected {
+			t.Errorf("Expected %v for '%v', but was %v (%v)", expected, value, mode, err)
+		}
+	}
+	if _, err := storedFieldsModeOf(SegmentInfo{attributes: map[string]string{LUCENE41_SF_MODE_KEY: "FOO"}}); err == nil {
+		t.Error("Should reject unknown mode")
+	}
+}
@@ -0,0 +1,992 @@
+package index
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"github.com/balzaczyy/golucene/util/automaton"
+	"log"
+	"math"
+	"sort"
+)
+
+// CompressingTermVectorsWriter.java
+
+const (
+	COMPRESSING_TV_VECTORS_EXTENSION       = "tvd"
+	COMPRESSING_TV_VECTORS_INDEX_EXTENSION = "tvx"
+
+	COMPRESSING_TV_VERSION_START   = 0
+	COMPRESSING_TV_VERSION_CURRENT = COMPRESSING_TV_VERSION_START
+
+	COMPRESSING_TV_BLOCK_SIZE = 64
+
+	COMPRESSING_TV_POSITIONS = 0x01
+	COMPRESSING_TV_OFFSETS   = 0x02
+	COMPRESSING_TV_PAYLOADS  = 0x04
+	// bitsRequired(POSITIONS | OFFSETS | PAYLOADS)
+	COMPRESSING_TV_FLAGS_BITS = 3
+)
+
+// CompressingTermVectorsReader.java
+
+/*
+TermVectorsReader for CompressingTermVectorsFormat.
+*/
+type CompressingTermVectorsReader struct {
+	fieldInfos        FieldInfos
+	indexReader       *CompressingStoredFieldsIndexReader
+	vectorsStream     store.IndexInput
+	closed            bool
+	packedIntsVersion int32
+	compressionMode   codec.CompressionMode
+	decompressor      codec.Decompressor
+	chunkSize         int
+	numDocs           int
+	reader            *util.BlockPackedReaderIterator
+}
+
+// used by clone
+func newCompressingTermVectorsReaderFrom(r *CompressingTermVectorsReader) *CompressingTermVectorsReader {
+	vectorsStream := r.vectorsStream.Clone()
+	return &CompressingTermVectorsReader{
+		fieldInfos:        r.fieldInfos,
+		vectorsStream:     vectorsStream,
+		indexReader:       r.indexReader,
+		packedIntsVersion: r.packedIntsVersion,
+		compressionMode:   r.compressionMode,
+		decompressor:      r.compressionMode.NewDecompressor(),
+		chunkSize:         r.chunkSize,
+		numDocs:           r.numDocs,
+		reader: util.NewBlockPackedReaderIterator(vectorsStream, r.packedIntsVersion,
+			COMPRESSING_TV_BLOCK_SIZE, 0),
+	}
+}
+
+func newCompressingTermVectorsReader(d store.Directory, si SegmentInfo, segmentSuffix string, fn FieldInfos,
+	ctx store.IOContext, formatName string, compressionMode codec.CompressionMode) (r *CompressingTermVectorsReader, err error) {
+	r = &CompressingTermVectorsReader{}
+	r.compressionMode = compressionMode
+	segment := si.name
+	r.fieldInfos = fn
+	r.numDocs = int(si.docCount)
+
+	var indexStream store.IndexInput
+	success := false
+	defer func() {
+		if !success {
+			log.Println("Failed to initialize CompressingTermVectorsReader.")
+			if err != nil {
+				log.Print(err)
+			}
+			util.Close(r, indexStream)
+		}
+	}()
+
+	// Load the index into memory
+	indexStreamFN := util.SegmentFileName(segment, segmentSuffix, COMPRESSING_TV_VECTORS_INDEX_EXTENSION)
+	indexStream, err = d.OpenInput(indexStreamFN, ctx)
+	if err != nil {
+		return nil, err
+	}
+	codecNameIdx := formatName + CODEC_SFX_IDX
+	if _, err = codec.CheckHeader(indexStream, codecNameIdx, COMPRESSING_TV_VERSION_START, COMPRESSING_TV_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	if int64(codec.HeaderLength(codecNameIdx)) != indexStream.FilePointer() {
+		panic("assert fail")
+	}
+	r.indexReader, err = newCompressingStoredFieldsIndexReader(indexStream, si)
+	if err != nil {
+		return nil, err
+	}
+	err = indexStream.Close()
+	if err != nil {
+		return nil, err
+	}
+	indexStream = nil
+
+	// Open the data file and read metadata
+	vectorsStreamFN := util.SegmentFileName(segment, segmentSuffix, COMPRESSING_TV_VECTORS_EXTENSION)
+	r.vectorsStream, err = d.OpenInput(vectorsStreamFN, ctx)
+	if err != nil {
+		return nil, err
+	}
+	codecNameDat := formatName + CODEC_SFX_DAT
+	if _, err = codec.CheckHeader(r.vectorsStream, codecNameDat, COMPRESSING_TV_VERSION_START, COMPRESSING_TV_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	if int64(codec.HeaderLength(codecNameDat)) != r.vectorsStream.FilePointer() {
+		panic("assert fail")
+	}
+
+	if r.packedIntsVersion, err = r.vectorsStream.ReadVInt(); err != nil {
+		return nil, err
+	}
+	n, err := r.vectorsStream.ReadVInt()
+	if err != nil {
+		return nil, err
+	}
+	r.chunkSize = int(n)
+	r.decompressor = compressionMode.NewDecompressor()
+	r.reader = util.NewBlockPackedReaderIterator(r.vectorsStream, r.packedIntsVersion, COMPRESSING_TV_BLOCK_SIZE, 0)
+
+	success = true
+	return r, nil
+}
+
+func (r *CompressingTermVectorsReader) ensureOpen() {
+	if r.closed {
+		panic("this FieldsReader is closed")
+	}
+}
+
+func (r *CompressingTermVectorsReader) Close() (err error) {
+	if !r.closed {
+		if err = util.Close(r.vectorsStream); err == nil {
+			r.closed = true
+		}
+	}
+	return err
+}
+
+func (r *CompressingTermVectorsReader) clone() TermVectorsReader {
+	return newCompressingTermVectorsReaderFrom(r)
+}
+
+func (r *CompressingTermVectorsReader) get(doc int) (fs Fields, err error) {
+	r.ensureOpen()
+
+	// seek to the right place
+	r.vectorsStream.Seek(r.indexReader.startPointer(doc))
+
+	// decode
+	// - docBase: first doc ID of the chunk
+	// - chunkDocs: number of docs of the chunk
+	n, err := r.vectorsStream.ReadVInt()
+	if err != nil {
+		return nil, err
+	}
+	docBase := int(n)
+	if n, err = r.vectorsStream.ReadVInt(); err != nil {
+		return nil, err
+	}
+	chunkDocs := int(n)
+	if doc < docBase || doc >= docBase+chunkDocs || docBase+chunkDocs > r.numDocs {
+		return nil, errors.New(fmt.Sprintf("Corrupted: docBase=%v,chunkDocs=%v,doc=%v (resource=%v)",
+			docBase, chunkDocs, doc, r.vectorsStream))
+	}
+
+	var skip int        // number of fields to skip
+	var numFields int   // number of fields of the document we're looking for
+	var totalFields int // total number of fields of the chunk (sum for all docs)
+	if chunkDocs == 1 {
+		if n, err = r.vectorsStream.ReadVInt(); err != nil {
+			return nil, err
+		}
+		numFields, totalFields = int(n), int(n)
+	} else {
+		r.reader.Reset(r.vectorsStream, int64(chunkDocs))
+		sum := 0
+		for i := docBase; i < doc; i++ {
+			v, err := r.reader.Next()
+			if err != nil {
+				return nil, err
+			}
+			sum += int(v)
+		}
+		skip = sum
+		v, err := r.reader.Next()
+		if err != nil {
+			return nil, err
+		}
+		numFields = int(v)
+		sum += numFields
+		for i := doc + 1; i < docBase+chunkDocs; i++ {
+			if v, err = r.reader.Next(); err != nil {
+				return nil, err
+			}
+			sum += int(v)
+		}
+		totalFields = sum
+	}
+
+	if numFields == 0 {
+		// no vectors
+		return nil, nil
+	}
+
+	// read field numbers that have term vectors
+	var fieldNums []int
+	{
+		token, err := r.vectorsStream.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		// token != 0 since we checked numFields above
+		if token == 0 {
+			panic("assert fail")
+		}
+		bitsPerFieldNum := uint32(token & 0x1F)
+		totalDistinctFields := int(token >> 5)
+		if totalDistinctFields == 0x07 {
+			if n, err = r.vectorsStream.ReadVInt(); err != nil {
+				return nil, err
+			}
+			totalDistinctFields += int(n)
+		}
+		totalDistinctFields++
+		if fieldNums, err = r.readPacked(totalDistinctFields, bitsPerFieldNum); err != nil {
+			return nil, err
+		}
+	}
+
+	// read field numbers and flags
+	fieldNumOffs := make([]int, numFields)
+	var flags []int
+	{
+		bitsPerOff := util.BitsRequired(int64(len(fieldNums) - 1))
+		allFieldNumOffs, err := r.readPacked(totalFields, bitsPerOff)
+		if err != nil {
+			return nil, err
+		}
+		if n, err = r.vectorsStream.ReadVInt(); err != nil {
+			return nil, err
+		}
+		switch n {
+		case 0:
+			fieldFlags, err := r.readPacked(len(fieldNums), COMPRESSING_TV_FLAGS_BITS)
+			if err != nil {
+				return nil, err
+			}
+			flags = make([]int, totalFields)
+			for i := range flags {
+				fieldNumOff := allFieldNumOffs[i]
+				if fieldNumOff < 0 || fieldNumOff >= len(fieldNums) {
+					panic("assert fail")
+				}
+				flags[i] = fieldFlags[fieldNumOff]
+			}
+		case 1:
+			if flags, err = r.readPacked(totalFields, COMPRESSING_TV_FLAGS_BITS); err != nil {
+				return nil, err
+			}
+		default:
+			panic("assert fail")
+		}
+		copy(fieldNumOffs, allFieldNumOffs[skip:skip+numFields])
+	}
+
+	// number of terms per field for all fields
+	var numTerms []int
+	totalTerms := 0
+	{
+		if n, err = r.vectorsStream.ReadVInt(); err != nil {
+			return nil, err
+		}
+		if numTerms, err = r.readPacked(totalFields, uint32(n)); err != nil {
+			return nil, err
+		}
+		for _, v := range numTerms {
+			totalTerms += v
+		}
+	}
+
+	// term lengths
+	docOff, docLen, totalLen := 0, 0, 0
+	fieldLengths := make([]int, numFields)
+	prefixLengths := make([][]int, numFields)
+	suffixLengths := make([][]int, numFields)
+	{
+		r.reader.Reset(r.vectorsStream, int64(totalTerms))
+		// skip
+		toSkip := 0
+		for _, v := range numTerms[:skip] {
+			toSkip += v
+		}
+		if err = r.reader.Skip(int64(toSkip)); err != nil {
+			return nil, err
+		}
+		// read prefix lengths
+		for i := range prefixLengths {
+			if prefixLengths[i], err = r.readBlockPacked(numTerms[skip+i]); err != nil {
+				return nil, err
+			}
+		}
+		if err = r.reader.Skip(int64(totalTerms) - r.reader.Ord()); err != nil {
+			return nil, err
+		}
+
+		r.reader.Reset(r.vectorsStream, int64(totalTerms))
+		// skip
+		for i := 0; i < toSkip; i++ {
+			v, err := r.reader.Next()
+			if err != nil {
+				return nil, err
+			}
+			docOff += int(v)
+		}
+		for i := range suffixLengths {
+			if suffixLengths[i], err = r.readBlockPacked(numTerms[skip+i]); err != nil {
+				return nil, err
+			}
+			for _, v := range suffixLengths[i] {
+				fieldLengths[i] += v
+			}
+			docLen += fieldLengths[i]
+		}
+		totalLen = docOff + docLen
+		for i := int(r.reader.Ord()); i < totalTerms; i++ {
+			v, err := r.reader.Next()
+			if err != nil {
+				return nil, err
+			}
+			totalLen += int(v)
+		}
+	}
+
+	// term freqs
+	termFreqs, err := r.readBlockPackedFrom(totalTerms)
+	if err != nil {
+		return nil, err
+	}
+	for i := range termFreqs {
+		termFreqs[i]++
+	}
+
+	// total number of positions, offsets and payloads
+	totalPositions, totalOffsets, totalPayloads := 0, 0, 0
+	for i, termIndex := 0, 0; i < totalFields; i++ {
+		f := flags[i]
+		for j := 0; j < numTerms[i]; j++ {
+			freq := termFreqs[termIndex]
+			termIndex++
+			if (f & COMPRESSING_TV_POSITIONS) != 0 {
+				totalPositions += freq
+			}
+			if (f & COMPRESSING_TV_OFFSETS) != 0 {
+				totalOffsets += freq
+			}
+			if (f & COMPRESSING_TV_PAYLOADS) != 0 {
+				totalPayloads += freq
+			}
+		}
+		if i == totalFields-1 && termIndex != totalTerms {
+			panic(fmt.Sprintf("assert fail: %v %v", termIndex, totalTerms))
+		}
+	}
+
+	positionIndex := r.positionIndex(skip, numFields, numTerms, termFreqs)
+	var positions, startOffsets, lengths [][]int
+	if totalPositions > 0 {
+		positions, err = r.readPositions(skip, numFields, flags, numTerms, termFreqs,
+			COMPRESSING_TV_POSITIONS, totalPositions, positionIndex)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		positions = make([][]int, numFields)
+	}
+
+	if totalOffsets > 0 {
+		// average number of chars per term
+		charsPerTerm := make([]float32, len(fieldNums))
+		for i := range charsPerTerm {
+			bits, err := r.vectorsStream.ReadInt()
+			if err != nil {
+				return nil, err
+			}
+			charsPerTerm[i] = math.Float32frombits(uint32(bits))
+		}
+		startOffsets, err = r.readPositions(skip, numFields, flags, numTerms, termFreqs,
+			COMPRESSING_TV_OFFSETS, totalOffsets, positionIndex)
+		if err != nil {
+			return nil, err
+		}
+		lengths, err = r.readPositions(skip, numFields, flags, numTerms, termFreqs,
+			COMPRESSING_TV_OFFSETS, totalOffsets, positionIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < numFields; i++ {
+			fStartOffsets, fPositions := startOffsets[i], positions[i]
+			// patch offsets from positions
+			if fStartOffsets != nil && fPositions != nil {
+				fieldCharsPerTerm := charsPerTerm[fieldNumOffs[i]]
+				for j := range fStartOffsets {
+					fStartOffsets[j] += int(fieldCharsPerTerm * float32(fPositions[j]))
+				}
+			}
+			if fStartOffsets != nil {
+				fPrefixLengths, fSuffixLengths, fLengths := prefixLengths[i], suffixLengths[i], lengths[i]
+				fPositionIndex := positionIndex[i]
+				for j, end := 0, numTerms[skip+i]; j < end; j++ {
+					// delta-decode start offsets and patch lengths using term lengths
+					termLength := fPrefixLengths[j] + fSuffixLengths[j]
+					fLengths[fPositionIndex[j]] += termLength
+					for k := fPositionIndex[j] + 1; k < fPositionIndex[j+1]; k++ {
+						fStartOffsets[k] += fStartOffsets[k-1]
+						fLengths[k] += termLength
+					}
+				}
+			}
+		}
+	} else {
+		startOffsets = make([][]int, numFields)
+		lengths = startOffsets
+	}
+	if totalPositions > 0 {
+		// delta-decode positions
+		for i, fPositions := range positions {
+			if fPositions == nil {
+				continue
+			}
+			fPositionIndex := positionIndex[i]
+			for j, end := 0, numTerms[skip+i]; j < end; j++ {
+				for k := fPositionIndex[j] + 1; k < fPositionIndex[j+1]; k++ {
+					fPositions[k] += fPositions[k-1]
+				}
+			}
+		}
+	}
+
+	// payload lengths
+	payloadIndex := make([][]int, numFields)
+	totalPayloadLength, payloadOff, payloadLen := 0, 0, 0
+	if totalPayloads > 0 {
+		r.reader.Reset(r.vectorsStream, int64(totalPayloads))
+		// skip
+		termIndex := 0
+		for i := 0; i < skip; i++ {
+			if (flags[i] & COMPRESSING_TV_PAYLOADS) != 0 {
+				for j := 0; j < numTerms[i]; j++ {
+					for k := 0; k < termFreqs[termIndex+j]; k++ {
+						v, err := r.reader.Next()
+						if err != nil {
+							return nil, err
+						}
+						payloadOff += int(v)
+					}
+				}
+			}
+			termIndex += numTerms[i]
+		}
+		totalPayloadLength = payloadOff
+		// read doc payload lengths
+		for i := 0; i < numFields; i++ {
+			termCount := numTerms[skip+i]
+			if (flags[skip+i] & COMPRESSING_TV_PAYLOADS) != 0 {
+				totalFreq := positionIndex[i][termCount]
+				payloadIndex[i] = make([]int, totalFreq+1)
+				posIdx := 0
+				payloadIndex[i][posIdx] = payloadLen
+				for j := 0; j < termCount; j++ {
+					for k := 0; k < termFreqs[termIndex+j]; k++ {
+						v, err := r.reader.Next()
+						if err != nil {
+							return nil, err
+						}
+						payloadLen += int(v)
+						payloadIndex[i][posIdx+1] = payloadLen
+						posIdx++
+					}
+				}
+				if posIdx != totalFreq {
+					panic("assert fail")
+				}
+			}
+			termIndex += termCount
+		}
+		totalPayloadLength += payloadLen
+		for i := skip + numFields; i < totalFields; i++ {
+			if (flags[i] & COMPRESSING_TV_PAYLOADS) != 0 {
+				for j := 0; j < numTerms[i]; j++ {
+					for k := 0; k < termFreqs[termIndex+j]; k++ {
+						v, err := r.reader.Next()
+						if err != nil {
+							return nil, err
+						}
+						totalPayloadLength += int(v)
+					}
+				}
+			}
+			termIndex += numTerms[i]
+		}
+		if termIndex != totalTerms {
+			panic(fmt.Sprintf("assert fail: %v %v", termIndex, totalTerms))
+		}
+	}
+
+	// decompress data
+	// the returned fields outlive this call, so they don't share a buffer
+	data, err := r.decompressor.Decompress(r.vectorsStream, totalLen+totalPayloadLength,
+		docOff+payloadOff, docLen+payloadLen, nil)
+	if err != nil {
+		return nil, err
+	}
+	suffixBytes, payloadBytes := data[:docLen], data[docLen:]
+
+	fieldFlags := make([]int, numFields)
+	copy(fieldFlags, flags[skip:skip+numFields])
+
+	fieldNumTerms := make([]int, numFields)
+	copy(fieldNumTerms, numTerms[skip:skip+numFields])
+
+	fieldTermFreqs := make([][]int, numFields)
+	{
+		termIdx := 0
+		for _, v := range numTerms[:skip] {
+			termIdx += v
+		}
+		for i := range fieldTermFreqs {
+			termCount := numTerms[skip+i]
+			fieldTermFreqs[i] = termFreqs[termIdx : termIdx+termCount]
+			termIdx += termCount
+		}
+	}
+
+	return &tvFields{
+		owner:         r,
+		fieldNums:     fieldNums,
+		fieldFlags:    fieldFlags,
+		fieldNumOffs:  fieldNumOffs,
+		numTerms:      fieldNumTerms,
+		fieldLengths:  fieldLengths,
+		prefixLengths: prefixLengths,
+		suffixLengths: suffixLengths,
+		termFreqs:     fieldTermFreqs,
+		positionIndex: positionIndex,
+		positions:     positions,
+		startOffsets:  startOffsets,
+		lengths:       lengths,
+		payloadBytes:  payloadBytes,
+		payloadIndex:  payloadIndex,
+		suffixBytes:   suffixBytes,
+	}, nil
+}
+
+// Reads count values packed with bitsPerValue bits from the vectors stream.
+func (r *CompressingTermVectorsReader) readPacked(count int, bitsPerValue uint32) (values []int, err error) {
+	pr, err := util.NewPackedReaderNoHeader(r.vectorsStream, util.PACKED, r.packedIntsVersion, int32(count), bitsPerValue)
+	if err != nil {
+		return nil, err
+	}
+	values = make([]int, count)
+	for i := range values {
+		values[i] = int(pr.Get(int32(i)))
+	}
+	return values, nil
+}
+
+// Reads the next count values of the block packed reader.
+func (r *CompressingTermVectorsReader) readBlockPacked(count int) (values []int, err error) {
+	values = make([]int, count)
+	for j := 0; j < count; {
+		next, err := r.reader.NextN(count - j)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range next {
+			values[j] = int(v)
+			j++
+		}
+	}
+	return values, nil
+}
+
+// Resets the block packed reader and reads all of its count values.
+func (r *CompressingTermVectorsReader) readBlockPackedFrom(count int) (values []int, err error) {
+	r.reader.Reset(r.vectorsStream, int64(count))
+	return r.readBlockPacked(count)
+}
+
+// field -> term index -> position index
+func (r *CompressingTermVectorsReader) positionIndex(skip, numFields int, numTerms, termFreqs []int) [][]int {
+	positionIndex := make([][]int, numFields)
+	termIndex := 0
+	for _, v := range numTerms[:skip] {
+		termIndex += v
+	}
+	for i := range positionIndex {
+		termCount := numTerms[skip+i]
+		positionIndex[i] = make([]int, termCount+1)
+		for j := 0; j < termCount; j++ {
+			freq := termFreqs[termIndex+j]
+			positionIndex[i][j+1] = positionIndex[i][j] + freq
+		}
+		termIndex += termCount
+	}
+	return positionIndex
+}
+
+func (r *CompressingTermVectorsReader) readPositions(skip, numFields int, flags, numTerms, termFreqs []int,
+	flag, totalPositions int, positionIndex [][]int) (positions [][]int, err error) {
+	positions = make([][]int, numFields)
+	r.reader.Reset(r.vectorsStream, int64(totalPositions))
+	// skip
+	toSkip, termIndex := 0, 0
+	for i := 0; i < skip; i++ {
+		if (flags[i] & flag) != 0 {
+			for j := 0; j < numTerms[i]; j++ {
+				toSkip += termFreqs[termIndex+j]
+			}
+		}
+		termIndex += numTerms[i]
+	}
+	if err = r.reader.Skip(int64(toSkip)); err != nil {
+		return nil, err
+	}
+	// read doc positions
+	for i := range positions {
+		termCount := numTerms[skip+i]
+		if (flags[skip+i] & flag) != 0 {
+			if positions[i], err = r.readBlockPacked(positionIndex[i][termCount]); err != nil {
+				return nil, err
+			}
+		}
+		termIndex += termCount
+	}
+	if err = r.reader.Skip(int64(totalPositions) - r.reader.Ord()); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// CompressingTermVectorsReader.java/TVFields
+type tvFields struct {
+	owner                                   *CompressingTermVectorsReader
+	fieldNums, fieldFlags, fieldNumOffs     []int
+	numTerms, fieldLengths                  []int
+	prefixLengths, suffixLengths, termFreqs [][]int
+	positionIndex                           [][]int
+	positions, startOffsets, lengths        [][]int
+	payloadBytes                            []byte
+	payloadIndex                            [][]int
+	suffixBytes                             []byte
+}
+
+func (fs *tvFields) Terms(field string) Terms {
+	fieldInfo, ok := fs.owner.fieldInfos.byName[field]
+	if !ok {
+		return nil
+	}
+	idx := -1
+	for i, off := range fs.fieldNumOffs {
+		if fs.fieldNums[off] == int(fieldInfo.number) {
+			idx = i
+			break
+		}
+	}
+
+	if idx == -1 || fs.numTerms[idx] == 0 {
+		// no term
+		return nil
+	}
+	fieldOff := 0
+	for _, v := range fs.fieldLengths[:idx] {
+		fieldOff += v
+	}
+	return &tvTerms{
+		numTerms:      fs.numTerms[idx],
+		flags:         fs.fieldFlags[idx],
+		prefixLengths: fs.prefixLengths[idx],
+		suffixLengths: fs.suffixLengths[idx],
+		termFreqs:     fs.termFreqs[idx],
+		positionIndex: fs.positionIndex[idx],
+		positions:     fs.positions[idx],
+		startOffsets:  fs.startOffsets[idx],
+		lengths:       fs.lengths[idx],
+		payloadIndex:  fs.payloadIndex[idx],
+		payloadBytes:  fs.payloadBytes,
+		termBytes:     fs.suffixBytes[fieldOff : fieldOff+fs.fieldLengths[idx]],
+	}
+}
+
+// Returns the number of fields of the document which have term vectors.
+func (fs *tvFields) Size() int {
+	return len(fs.fieldNumOffs)
+}
+
+// CompressingTermVectorsReader.java/TVTerms
+type tvTerms struct {
+	numTerms, flags                                        int
+	prefixLengths, suffixLengths, termFreqs, positionIndex []int
+	positions, startOffsets, lengths, payloadIndex         []int
+	payloadBytes, termBytes                                []byte
+}
+
+func (t *tvTerms) Iterator(reuse TermsEnum) TermsEnum {
+	termsEnum, ok := reuse.(*tvTermsEnum)
+	if !ok {
+		termsEnum = newTVTermsEnum()
+	}
+	termsEnum.reset(t)
+	return termsEnum
+}
+
+func (t *tvTerms) Intersect(compiled *automaton.CompiledAutomaton, startTerm []byte) (TermsEnum, error) {
+	return intersect(t, compiled, startTerm)
+}
+
+func (t *tvTerms) Size() int64 {
+	return int64(t.numTerms)
+}
+
+func (t *tvTerms) SumTotalTermFreq() int64 {
+	return -1
+}
+
+func (t *tvTerms) SumDocFreq() int64 {
+	return int64(t.numTerms)
+}
+
+func (t *tvTerms) DocCount() int {
+	return 1
+}
+
+func (t *tvTerms) HasOffsets() bool {
+	return (t.flags & COMPRESSING_TV_OFFSETS) != 0
+}
+
+func (t *tvTerms) HasPositions() bool {
+	return (t.flags & COMPRESSING_TV_POSITIONS) != 0
+}
+
+func (t *tvTerms) HasPayloads() bool {
+	return (t.flags & COMPRESSING_TV_PAYLOADS) != 0
+}
+
+// CompressingTermVectorsReader.java/TVTermsEnum
+type tvTermsEnum struct {
+	*TermsEnumImpl
+	terms *tvTerms
+	ord   int
+	off   int // offset of the next suffix in terms.termBytes
+	term  []byte
+}
+
+func newTVTermsEnum() *tvTermsEnum {
+	ans := &tvTermsEnum{}
+	ans.TermsEnumImpl = newTermsEnumImpl(ans)
+	return ans
+}
+
+func (e *tvTermsEnum) reset(terms *tvTerms) {
+	e.terms = terms
+	e.rewind()
+}
+
+func (e *tvTermsEnum) rewind() {
+	e.term = e.term[:0]
+	e.off = 0
+	e.ord = -1
+}
+
+func (e *tvTermsEnum) Next() (term []byte, err error) {
+	if e.ord == e.terms.numTerms-1 {
+		return nil, nil
+	}
+	e.ord++
+
+	// read term
+	start := e.off
+	e.off += e.terms.suffixLengths[e.ord]
+	e.term = append(e.term[:e.terms.prefixLengths[e.ord]], e.terms.termBytes[start:e.off]...)
+	return e.term, nil
+}
+
+func (e *tvTermsEnum) Comparator() sort.Interface {
+	// terms are sorted by their UTF-8 bytes
+	return nil
+}
+
+func (e *tvTermsEnum) SeekCeil(text []byte) SeekStatus {
+	if e.ord < e.terms.numTerms && e.ord >= 0 {
+		cmp := bytes.Compare(e.term, text)
+		if cmp == 0 {
+			return SEEK_STATUS_FOUND
+		} else if cmp > 0 {
+			e.rewind()
+		}
+	}
+	// linear scan
+	for {
+		term, err := e.Next()
+		if err != nil {
+			panic(err)
+		}
+		if term == nil {
+			return SEEK_STATUS_END
+		}
+		if cmp := bytes.Compare(term, text); cmp > 0 {
+			return SEEK_STATUS_NOT_FOUND
+		} else if cmp == 0 {
+			return SEEK_STATUS_FOUND
+		}
+	}
+}
+
+func (e *tvTermsEnum) SeekExactByPosition(ord int64) error {
+	return ErrUnsupported
+}
+
+func (e *tvTermsEnum) Term() []byte {
+	return e.term
+}
+
+func (e *tvTermsEnum) Ord() (int64, error) {
+	return 0, ErrUnsupported
+}
+
+func (e *tvTermsEnum) DocFreq() int {
+	return 1
+}
+
+func (e *tvTermsEnum) TotalTermFreq() int64 {
+	return int64(e.terms.termFreqs[e.ord])
+}
+
+func (e *tvTermsEnum) DocsByFlags(liveDocs util.Bits, reuse DocsEnum, flags int) DocsEnum {
+	docsEnum, ok := reuse.DocIdSetIterator.(*tvDocsEnum)
+	if !ok {
+		docsEnum = new(tvDocsEnum)
+	}
+	docsEnum.reset(liveDocs, e.terms, e.ord)
+	return DocsEnum{docsEnum}
+}
+
+func (e *tvTermsEnum) DocsAndPositionsByFlags(liveDocs util.Bits, reuse DocsAndPositionsEnum, flags int) DocsAndPositionsEnum {
+	if e.terms.positions == nil && e.terms.startOffsets == nil {
+		return DocsAndPositionsEnum{}
+	}
+	docsEnum, ok := reuse.DocsAndPositionsIterator.(*tvDocsEnum)
+	if !ok {
+		docsEnum = new(tvDocsEnum)
+	}
+	docsEnum.reset(liveDocs, e.terms, e.ord)
+	return DocsAndPositionsEnum{docsEnum}
+}
+
+// CompressingTermVectorsReader.java/TVDocsEnum
+type tvDocsEnum struct {
+	liveDocs      util.Bits
+	doc           int
+	termFreq      int
+	positionIndex int
+	positions     []int
+	startOffsets  []int
+	lengths       []int
+	payloadBytes  []byte
+	payloadIndex  []int
+	payload       []byte
+	i             int
+}
+
+func (de *tvDocsEnum) reset(liveDocs util.Bits, terms *tvTerms, ord int) {
+	de.liveDocs = liveDocs
+	de.termFreq = terms.termFreqs[ord]
+	de.positionIndex = terms.positionIndex[ord]
+	de.positions = terms.positions
+	de.startOffsets = terms.startOffsets
+	de.lengths = terms.lengths
+	de.payloadBytes = terms.payloadBytes
+	de.payloadIndex = terms.payloadIndex
+	de.payload = nil
+	de.doc, de.i = -1, -1
+}
+
+func (de *tvDocsEnum) checkDoc() {
+	if de.doc == NO_MORE_DOCS {
+		panic("DocsEnum exhausted")
+	} else if de.doc == -1 {
+		panic("DocsEnum not started")
+	}
+}
+
+func (de *tvDocsEnum) checkPosition() {
+	de.checkDoc()
+	if de.i < 0 {
+		panic("Position enum not started")
+	} else if de.i >= de.termFreq {
+		panic("Read past last position")
+	}
+}
+
+func (de *tvDocsEnum) NextPosition() int {
+	if de.doc != 0 {
+		panic("illegal state")
+	} else if de.i >= de.termFreq-1 {
+		panic("Read past last position")
+	}
+
+	de.i++
+
+	if de.payloadIndex != nil {
+		idx := de.positionIndex + de.i
+		de.payload = de.payloadBytes[de.payloadIndex[idx]:de.payloadIndex[idx+1]]
+	}
+
+	if de.positions == nil {
+		return -1
+	}
+	return de.positions[de.positionIndex+de.i]
+}
+
+func (de *tvDocsEnum) StartOffset() int {
+	de.checkPosition()
+	if de.startOffsets == nil {
+		return -1
+	}
+	return de.startOffsets[de.positionIndex+de.i]
+}
+
+func (de *tvDocsEnum) EndOffset() int {
+	de.checkPosition()
+	if de.startOffsets == nil {
+		return -1
+	}
+	return de.startOffsets[de.positionIndex+de.i] + de.lengths[de.positionIndex+de.i]
+}
+
+func (de *tvDocsEnum) Payload() []byte {
+	de.checkPosition()
+	if de.payloadIndex == nil || len(de.payload) == 0 {
+		return nil
+	}
+	return de.payload
+}
+
+func (de *tvDocsEnum) Freq() int {
+	de.checkDoc()
+	return de.termFreq
+}
+
+func (de *tvDocsEnum) DocId() int {
+	return de.doc
+}
+
+func (de *tvDocsEnum) NextDoc() (doc int, more bool) {
+	if de.doc == -1 && (de.liveDocs == nil || de.liveDocs.Get(0)) {
+		de.doc = 0
+		return de.doc, true
+	}
+	de.doc = NO_MORE_DOCS
+	return de.doc, false
+}
+
+func (de *tvDocsEnum) Cost() int64 {
+	return 1
+}
+
+func (de *tvDocsEnum) Advance(target int) (doc int, more bool) {
+	for de.doc < target {
+		if _, more = de.NextDoc(); !more {
+			break
+		}
+	}
+	return de.doc, de.doc != NO_MORE_DOCS
+}
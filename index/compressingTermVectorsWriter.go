@@ -0,0 +1,827 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+	"sort"
+)
+
+// CompressingTermVectorsFormat.java
+
+/*
+A TermVectorsFormat that compresses chunks of documents together in
+order to improve the compression ratio.
+*/
+type CompressingTermVectorsFormat struct {
+	formatName      string
+	segmentSuffix   string
+	compressionMode codec.CompressionMode
+	chunkSize       int
+}
+
+/*
+Creates a new CompressingTermVectorsFormat.
+
+formatName is the name of the format. This name will be used in the
+file formats to perform codec header checks.
+
+The compressionMode parameter allows you to choose between
+compression algorithms that have various compression and
+decompression speeds so that you can pick the one that best fits
+your indexing and searching throughput. You should never instantiate
+two CompressingTermVectorsFormats that have the same name but
+different CompressionModes.
+
+chunkSize is the minimum byte size of a chunk of documents. Higher
+values of chunkSize should improve the compression ratio but will
+require more memory at indexing time and might make document loading
+a little slower (depending on the size of your OS cache compared to
+the size of your index).
+*/
+func NewCompressingTermVectorsFormat(formatName, segmentSuffix string,
+	compressionMode codec.CompressionMode, chunkSize int) *CompressingTermVectorsFormat {
+	if chunkSize < 1 {
+		panic("chunkSize must be >= 1")
+	}
+	return &CompressingTermVectorsFormat{formatName, segmentSuffix, compressionMode, chunkSize}
+}
+
+func (f *CompressingTermVectorsFormat) VectorsReader(d store.Directory, si SegmentInfo,
+	fn FieldInfos, ctx store.IOContext) (r TermVectorsReader, err error) {
+	p, err := newCompressingTermVectorsReader(d, si, f.segmentSuffix, fn, ctx,
+		f.formatName, f.compressionMode)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (f *CompressingTermVectorsFormat) VectorsWriter(d store.Directory, si *SegmentInfo,
+	ctx store.IOContext) (w TermVectorsWriter, err error) {
+	p, err := newCompressingTermVectorsWriter(d, si, f.segmentSuffix, ctx,
+		f.formatName, f.compressionMode, f.chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (f *CompressingTermVectorsFormat) String() string {
+	return fmt.Sprintf("CompressingTermVectorsFormat(compressionMode=%v, chunkSize=%v)",
+		f.compressionMode, f.chunkSize)
+}
+
+// CompressingTermVectorsWriter.java
+
+// hard limit on the maximum number of documents per chunk
+const COMPRESSING_TV_MAX_DOCUMENTS_PER_CHUNK = 128
+
+/*
+TermVectorsWriter for CompressingTermVectorsFormat.
+
+The term vectors of documents are buffered until their terms and
+payloads reach chunkSize bytes, or
+COMPRESSING_TV_MAX_DOCUMENTS_PER_CHUNK documents are buffered. The
+chunk is then written as packed metadata (fields, flags, number of
+terms, term lengths, frequencies, positions, offsets and payload
+lengths) followed by the compressed term suffixes and payloads.
+*/
+type CompressingTermVectorsWriter struct {
+	directory     store.Directory
+	segment       string
+	segmentSuffix string
+	indexWriter   *CompressingStoredFieldsIndexWriter
+	vectorsStream store.IndexOutput
+
+	compressor codec.Compressor
+	chunkSize  int
+
+	numDocs     int          // cumulative number of docs seen
+	pendingDocs []*tvDocData // pending docs
+	curDoc      *tvDocData   // current document
+	curField    *tvFieldData // current field
+	lastTerm    []byte
+
+	// buffers for positions, offsets and payload lengths, shared by
+	// the pending docs
+	positionsBuf      []int
+	startOffsetsBuf   []int
+	lengthsBuf        []int
+	payloadLengthsBuf []int
+
+	termSuffixes []byte // buffered term suffixes
+	payloadBytes []byte // buffered term payloads of the current doc
+	writer       *util.BlockPackedWriter
+}
+
+// a pending doc
+type tvDocData struct {
+	numFields                    int
+	fields                       []*tvFieldData
+	posStart, offStart, payStart int
+}
+
+func (w *CompressingTermVectorsWriter) newDocData(numFields, posStart, offStart, payStart int) *tvDocData {
+	return &tvDocData{
+		numFields: numFields,
+		fields:    make([]*tvFieldData, 0, numFields),
+		posStart:  posStart,
+		offStart:  offStart,
+		payStart:  payStart,
+	}
+}
+
+func (d *tvDocData) addField(w *CompressingTermVectorsWriter, fieldNum, numTerms int,
+	positions, offsets, payloads bool) *tvFieldData {
+	var field *tvFieldData
+	if len(d.fields) == 0 {
+		field = w.newFieldData(fieldNum, numTerms, positions, offsets, payloads,
+			d.posStart, d.offStart, d.payStart)
+	} else {
+		last := d.fields[len(d.fields)-1]
+		posStart, offStart, payStart := last.nextStarts()
+		field = w.newFieldData(fieldNum, numTerms, positions, offsets, payloads,
+			posStart, offStart, payStart)
+	}
+	d.fields = append(d.fields, field)
+	return field
+}
+
+func (w *CompressingTermVectorsWriter) addDocData(numVectorFields int) *tvDocData {
+	var last *tvFieldData
+	for i := len(w.pendingDocs) - 1; i >= 0; i-- {
+		if doc := w.pendingDocs[i]; len(doc.fields) > 0 {
+			last = doc.fields[len(doc.fields)-1]
+			break
+		}
+	}
+	var doc *tvDocData
+	if last == nil {
+		doc = w.newDocData(numVectorFields, 0, 0, 0)
+	} else {
+		posStart, offStart, payStart := last.nextStarts()
+		doc = w.newDocData(numVectorFields, posStart, offStart, payStart)
+	}
+	w.pendingDocs = append(w.pendingDocs, doc)
+	return doc
+}
+
+// a pending field
+type tvFieldData struct {
+	w                                     *CompressingTermVectorsWriter
+	hasPositions, hasOffsets, hasPayloads bool
+	fieldNum, flags, numTerms             int
+	freqs, prefixLengths, suffixLengths   []int
+	posStart, offStart, payStart          int
+	totalPositions                        int
+	ord                                   int
+}
+
+func (w *CompressingTermVectorsWriter) newFieldData(fieldNum, numTerms int,
+	positions, offsets, payloads bool, posStart, offStart, payStart int) *tvFieldData {
+	ans := &tvFieldData{
+		w:             w,
+		fieldNum:      fieldNum,
+		numTerms:      numTerms,
+		hasPositions:  positions,
+		hasOffsets:    offsets,
+		hasPayloads:   payloads,
+		freqs:         make([]int, numTerms),
+		prefixLengths: make([]int, numTerms),
+		suffixLengths: make([]int, numTerms),
+		posStart:      posStart,
+		offStart:      offStart,
+		payStart:      payStart,
+	}
+	if positions {
+		ans.flags |= COMPRESSING_TV_POSITIONS
+	}
+	if offsets {
+		ans.flags |= COMPRESSING_TV_OFFSETS
+	}
+	if payloads {
+		ans.flags |= COMPRESSING_TV_PAYLOADS
+	}
+	return ans
+}
+
+// Returns where the positions, offsets and payload lengths of the
+// next field start in the shared buffers.
+func (f *tvFieldData) nextStarts() (posStart, offStart, payStart int) {
+	posStart, offStart, payStart = f.posStart, f.offStart, f.payStart
+	if f.hasPositions {
+		posStart += f.totalPositions
+	}
+	if f.hasOffsets {
+		offStart += f.totalPositions
+	}
+	if f.hasPayloads {
+		payStart += f.totalPositions
+	}
+	return
+}
+
+func (f *tvFieldData) addTerm(freq, prefixLength, suffixLength int) {
+	f.freqs[f.ord] = freq
+	f.prefixLengths[f.ord] = prefixLength
+	f.suffixLengths[f.ord] = suffixLength
+	f.ord++
+}
+
+func growInts(array []int, minSize int) []int {
+	if len(array) >= minSize {
+		return array
+	}
+	return append(array, make([]int, minSize-len(array)+len(array)>>3)...)
+}
+
+func (f *tvFieldData) addPosition(position, startOffset, length, payloadLength int) {
+	w := f.w
+	if f.hasPositions {
+		w.positionsBuf = growInts(w.positionsBuf, f.posStart+f.totalPositions+1)
+		w.positionsBuf[f.posStart+f.totalPositions] = position
+	}
+	if f.hasOffsets {
+		w.startOffsetsBuf = growInts(w.startOffsetsBuf, f.offStart+f.totalPositions+1)
+		w.lengthsBuf = growInts(w.lengthsBuf, f.offStart+f.totalPositions+1)
+		w.startOffsetsBuf[f.offStart+f.totalPositions] = startOffset
+		w.lengthsBuf[f.offStart+f.totalPositions] = length
+	}
+	if f.hasPayloads {
+		w.payloadLengthsBuf = growInts(w.payloadLengthsBuf, f.payStart+f.totalPositions+1)
+		w.payloadLengthsBuf[f.payStart+f.totalPositions] = payloadLength
+	}
+	f.totalPositions++
+}
+
+func newCompressingTermVectorsWriter(d store.Directory, si *SegmentInfo, segmentSuffix string,
+	ctx store.IOContext, formatName string, compressionMode codec.CompressionMode,
+	chunkSize int) (w *CompressingTermVectorsWriter, err error) {
+	if d == nil {
+		panic("assert fail")
+	}
+	w = &CompressingTermVectorsWriter{
+		directory:         d,
+		segment:           si.name,
+		segmentSuffix:     segmentSuffix,
+		compressor:        compressionMode.NewCompressor(),
+		chunkSize:         chunkSize,
+		positionsBuf:      make([]int, 1024),
+		startOffsetsBuf:   make([]int, 1024),
+		lengthsBuf:        make([]int, 1024),
+		payloadLengthsBuf: make([]int, 1024),
+	}
+
+	var indexStream store.IndexOutput
+	success := false
+	defer func() {
+		if !success {
+			util.CloseWhileSuppressingError(indexStream)
+			w.Abort()
+		}
+	}()
+
+	indexStream, err = d.CreateOutput(util.SegmentFileName(w.segment, segmentSuffix, COMPRESSING_TV_VECTORS_INDEX_EXTENSION), ctx)
+	if err != nil {
+		return nil, err
+	}
+	w.vectorsStream, err = d.CreateOutput(util.SegmentFileName(w.segment, segmentSuffix, COMPRESSING_TV_VECTORS_EXTENSION), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codecNameIdx := formatName + CODEC_SFX_IDX
+	codecNameDat := formatName + CODEC_SFX_DAT
+	if err = codec.WriteHeader(indexStream, codecNameIdx, COMPRESSING_TV_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	if err = codec.WriteHeader(w.vectorsStream, codecNameDat, COMPRESSING_TV_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	if int64(codec.HeaderLength(codecNameDat)) != w.vectorsStream.FilePointer() {
+		panic("assert fail")
+	}
+	if int64(codec.HeaderLength(codecNameIdx)) != indexStream.FilePointer() {
+		panic("assert fail")
+	}
+
+	if w.indexWriter, err = newCompressingStoredFieldsIndexWriter(indexStream); err != nil {
+		return nil, err
+	}
+	indexStream = nil
+
+	if err = w.vectorsStream.WriteVInt(util.PACKED_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	if err = w.vectorsStream.WriteVInt(int32(chunkSize)); err != nil {
+		return nil, err
+	}
+	w.writer = util.NewBlockPackedWriter(w.vectorsStream, COMPRESSING_TV_BLOCK_SIZE)
+
+	success = true
+	return w, nil
+}
+
+func (w *CompressingTermVectorsWriter) Close() error {
+	defer func() {
+		w.vectorsStream = nil
+		w.indexWriter = nil
+	}()
+	return util.Close(w.vectorsStream, w.indexWriter)
+}
+
+// Aborts writing entirely, deleting any partially written files.
+func (w *CompressingTermVectorsWriter) Abort() {
+	util.CloseWhileSuppressingError(w)
+	for _, ext := range []string{COMPRESSING_TV_VECTORS_EXTENSION, COMPRESSING_TV_VECTORS_INDEX_EXTENSION} {
+		w.directory.DeleteFile(util.SegmentFileName(w.segment, w.segmentSuffix, ext))
+	}
+}
+
+func (w *CompressingTermVectorsWriter) StartDocument(numVectorFields int) error {
+	w.curDoc = w.addDocData(numVectorFields)
+	return nil
+}
+
+func (w *CompressingTermVectorsWriter) FinishDocument() error {
+	// append the payload bytes of the doc after its terms
+	w.termSuffixes = append(w.termSuffixes, w.payloadBytes...)
+	w.payloadBytes = w.payloadBytes[:0]
+	w.numDocs++
+	if w.triggerFlush() {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	w.curDoc = nil
+	return nil
+}
+
+func (w *CompressingTermVectorsWriter) StartField(info FieldInfo, numTerms int,
+	positions, offsets, payloads bool) error {
+	w.curField = w.curDoc.addField(w, int(info.number), numTerms, positions, offsets, payloads)
+	w.lastTerm = w.lastTerm[:0]
+	return nil
+}
+
+func (w *CompressingTermVectorsWriter) FinishField() error {
+	w.curField = nil
+	return nil
+}
+
+func (w *CompressingTermVectorsWriter) StartTerm(term []byte, freq int) error {
+	if freq < 1 {
+		panic("assert fail")
+	}
+	prefix := bytesDifference(w.lastTerm, term)
+	w.curField.addTerm(freq, prefix, len(term)-prefix)
+	w.termSuffixes = append(w.termSuffixes, term[prefix:]...)
+	// copy last term
+	w.lastTerm = append(w.lastTerm[:0], term...)
+	return nil
+}
+
+// Returns the length of the common prefix of the two terms.
+func bytesDifference(left, right []byte) int {
+	n := len(left)
+	if len(right) < n {
+		n = len(right)
+	}
+	for i := 0; i < n; i++ {
+		if left[i] != right[i] {
+			return i
+		}
+	}
+	return n
+}
+
+func (w *CompressingTermVectorsWriter) AddPosition(position, startOffset, endOffset int, payload []byte) error {
+	if w.curField.flags == 0 {
+		panic("assert fail")
+	}
+	w.curField.addPosition(position, startOffset, endOffset-startOffset, len(payload))
+	if w.curField.hasPayloads && payload != nil {
+		w.payloadBytes = append(w.payloadBytes, payload...)
+	}
+	return nil
+}
+
+func (w *CompressingTermVectorsWriter) triggerFlush() bool {
+	return len(w.termSuffixes) >= w.chunkSize ||
+		len(w.pendingDocs) >= COMPRESSING_TV_MAX_DOCUMENTS_PER_CHUNK
+}
+
+func (w *CompressingTermVectorsWriter) flush() error {
+	chunkDocs := len(w.pendingDocs)
+	if chunkDocs <= 0 {
+		panic("assert fail")
+	}
+
+	// write the index file
+	if err := w.indexWriter.writeIndex(chunkDocs, w.vectorsStream.FilePointer()); err != nil {
+		return err
+	}
+
+	docBase := w.numDocs - chunkDocs
+	if err := w.vectorsStream.WriteVInt(int32(docBase)); err != nil {
+		return err
+	}
+	if err := w.vectorsStream.WriteVInt(int32(chunkDocs)); err != nil {
+		return err
+	}
+
+	// total number of fields of the chunk
+	totalFields, err := w.flushNumFields(chunkDocs)
+	if err != nil {
+		return err
+	}
+
+	if totalFields > 0 {
+		// unique field numbers (sorted)
+		fieldNums, err := w.flushFieldNums()
+		if err != nil {
+			return err
+		}
+		// offsets in the array of unique field numbers
+		if err = w.flushFields(totalFields, fieldNums); err != nil {
+			return err
+		}
+		// flags (does the field have positions, offsets, payloads?)
+		if err = w.flushFlags(totalFields, fieldNums); err != nil {
+			return err
+		}
+		// number of terms of each field
+		if err = w.flushNumTerms(totalFields); err != nil {
+			return err
+		}
+		// prefix and suffix lengths for each field
+		if err = w.flushTermLengths(); err != nil {
+			return err
+		}
+		// term freqs - 1 (because termFreq is always >=1) for each term
+		if err = w.flushTermFreqs(); err != nil {
+			return err
+		}
+		// positions for all terms, when enabled
+		if err = w.flushPositions(); err != nil {
+			return err
+		}
+		// offsets for all terms, when enabled
+		if err = w.flushOffsets(fieldNums); err != nil {
+			return err
+		}
+		// payload lengths for all terms, when enabled
+		if err = w.flushPayloadLengths(); err != nil {
+			return err
+		}
+
+		// compress terms and payloads and write them to the output
+		if err = w.compressor.Compress(w.termSuffixes, w.vectorsStream); err != nil {
+			return err
+		}
+	}
+
+	// reset
+	w.pendingDocs = w.pendingDocs[:0]
+	w.curDoc = nil
+	w.curField = nil
+	w.termSuffixes = w.termSuffixes[:0]
+	return nil
+}
+
+func (w *CompressingTermVectorsWriter) flushNumFields(chunkDocs int) (int, error) {
+	if chunkDocs == 1 {
+		numFields := w.pendingDocs[0].numFields
+		return numFields, w.vectorsStream.WriteVInt(int32(numFields))
+	}
+	w.writer.Reset(w.vectorsStream)
+	totalFields := 0
+	for _, dd := range w.pendingDocs {
+		if err := w.writer.Add(int64(dd.numFields)); err != nil {
+			return 0, err
+		}
+		totalFields += dd.numFields
+	}
+	return totalFields, w.writer.Finish()
+}
+
+// Returns a sorted array containing unique field numbers
+func (w *CompressingTermVectorsWriter) flushFieldNums() ([]int, error) {
+	seen := make(map[int]bool)
+	var fieldNums []int
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			if !seen[fd.fieldNum] {
+				seen[fd.fieldNum] = true
+				fieldNums = append(fieldNums, fd.fieldNum)
+			}
+		}
+	}
+	sort.Ints(fieldNums)
+
+	numDistinctFields := len(fieldNums)
+	if numDistinctFields <= 0 {
+		panic("assert fail")
+	}
+	bitsRequired := util.BitsRequired(int64(fieldNums[numDistinctFields-1]))
+	token := numDistinctFields - 1
+	if token > 0x07 {
+		token = 0x07
+	}
+	token = (token << 5) | int(bitsRequired)
+	if err := w.vectorsStream.WriteByte(byte(token)); err != nil {
+		return nil, err
+	}
+	if numDistinctFields-1 >= 0x07 {
+		if err := w.vectorsStream.WriteVInt(int32(numDistinctFields - 1 - 0x07)); err != nil {
+			return nil, err
+		}
+	}
+	writer := util.NewPackedWriterNoHeader(w.vectorsStream, util.PACKED, int32(numDistinctFields), bitsRequired)
+	for _, fieldNum := range fieldNums {
+		if err := writer.Add(int64(fieldNum)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Finish(); err != nil {
+		return nil, err
+	}
+	return fieldNums, nil
+}
+
+func (w *CompressingTermVectorsWriter) flushFields(totalFields int, fieldNums []int) error {
+	writer := util.NewPackedWriterNoHeader(w.vectorsStream, util.PACKED, int32(totalFields),
+		util.BitsRequired(int64(len(fieldNums)-1)))
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			fieldNumIndex := sort.SearchInts(fieldNums, fd.fieldNum)
+			if err := writer.Add(int64(fieldNumIndex)); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.Finish()
+}
+
+func (w *CompressingTermVectorsWriter) flushFlags(totalFields int, fieldNums []int) error {
+	// check if fields always have the same flags
+	nonChangingFlags := true
+	fieldFlags := make([]int, len(fieldNums))
+	for i, _ := range fieldFlags {
+		fieldFlags[i] = -1
+	}
+outer:
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			fieldNumOff := sort.SearchInts(fieldNums, fd.fieldNum)
+			if fieldFlags[fieldNumOff] == -1 {
+				fieldFlags[fieldNumOff] = fd.flags
+			} else if fieldFlags[fieldNumOff] != fd.flags {
+				nonChangingFlags = false
+				break outer
+			}
+		}
+	}
+
+	if nonChangingFlags {
+		// write one flag per field num
+		if err := w.vectorsStream.WriteVInt(0); err != nil {
+			return err
+		}
+		writer := util.NewPackedWriterNoHeader(w.vectorsStream, util.PACKED, int32(len(fieldFlags)), COMPRESSING_TV_FLAGS_BITS)
+		for _, flags := range fieldFlags {
+			if flags < 0 {
+				panic("assert fail")
+			}
+			if err := writer.Add(int64(flags)); err != nil {
+				return err
+			}
+		}
+		return writer.Finish()
+	}
+
+	// write one flag for every field instance
+	if err := w.vectorsStream.WriteVInt(1); err != nil {
+		return err
+	}
+	writer := util.NewPackedWriterNoHeader(w.vectorsStream, util.PACKED, int32(totalFields), COMPRESSING_TV_FLAGS_BITS)
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			if err := writer.Add(int64(fd.flags)); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.Finish()
+}
+
+func (w *CompressingTermVectorsWriter) flushNumTerms(totalFields int) error {
+	maxNumTerms := 0
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			maxNumTerms |= fd.numTerms
+		}
+	}
+	bitsRequired := util.BitsRequired(int64(maxNumTerms))
+	if err := w.vectorsStream.WriteVInt(int32(bitsRequired)); err != nil {
+		return err
+	}
+	writer := util.NewPackedWriterNoHeader(w.vectorsStream, util.PACKED, int32(totalFields), bitsRequired)
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			if err := writer.Add(int64(fd.numTerms)); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.Finish()
+}
+
+// Writes, with the block packed writer, the values returned by f for
+// each term of the pending fields.
+func (w *CompressingTermVectorsWriter) flushPerTerm(f func(fd *tvFieldData, i int) int) error {
+	w.writer.Reset(w.vectorsStream)
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			for i := 0; i < fd.numTerms; i++ {
+				if err := w.writer.Add(int64(f(fd, i))); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return w.writer.Finish()
+}
+
+func (w *CompressingTermVectorsWriter) flushTermLengths() error {
+	if err := w.flushPerTerm(func(fd *tvFieldData, i int) int { return fd.prefixLengths[i] }); err != nil {
+		return err
+	}
+	return w.flushPerTerm(func(fd *tvFieldData, i int) int { return fd.suffixLengths[i] })
+}
+
+func (w *CompressingTermVectorsWriter) flushTermFreqs() error {
+	return w.flushPerTerm(func(fd *tvFieldData, i int) int { return fd.freqs[i] - 1 })
+}
+
+func (w *CompressingTermVectorsWriter) flushPositions() error {
+	w.writer.Reset(w.vectorsStream)
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			if !fd.hasPositions {
+				continue
+			}
+			pos := 0
+			for i := 0; i < fd.numTerms; i++ {
+				previousPosition := 0
+				for j := 0; j < fd.freqs[i]; j++ {
+					position := w.positionsBuf[fd.posStart+pos]
+					pos++
+					if err := w.writer.Add(int64(position - previousPosition)); err != nil {
+						return err
+					}
+					previousPosition = position
+				}
+			}
+			if pos != fd.totalPositions {
+				panic("assert fail")
+			}
+		}
+	}
+	return w.writer.Finish()
+}
+
+func (w *CompressingTermVectorsWriter) flushOffsets(fieldNums []int) error {
+	hasOffsets := false
+	sumPos := make([]int64, len(fieldNums))
+	sumOffsets := make([]int64, len(fieldNums))
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			hasOffsets = hasOffsets || fd.hasOffsets
+			if fd.hasOffsets && fd.hasPositions {
+				fieldNumOff := sort.SearchInts(fieldNums, fd.fieldNum)
+				pos := 0
+				for i := 0; i < fd.numTerms; i++ {
+					previousPos, previousOff := 0, 0
+					for j := 0; j < fd.freqs[i]; j++ {
+						position := w.positionsBuf[fd.posStart+pos]
+						startOffset := w.startOffsetsBuf[fd.offStart+pos]
+						sumPos[fieldNumOff] += int64(position - previousPos)
+						sumOffsets[fieldNumOff] += int64(startOffset - previousOff)
+						previousPos, previousOff = position, startOffset
+						pos++
+					}
+				}
+				if pos != fd.totalPositions {
+					panic("assert fail")
+				}
+			}
+		}
+	}
+
+	if !hasOffsets {
+		// nothing to do
+		return nil
+	}
+
+	charsPerTerm := make([]float32, len(fieldNums))
+	for i, _ := range fieldNums {
+		if sumPos[i] > 0 && sumOffsets[i] > 0 {
+			charsPerTerm[i] = float32(float64(sumOffsets[i]) / float64(sumPos[i]))
+		}
+	}
+
+	// start offsets
+	for _, cpt := range charsPerTerm {
+		if err := w.vectorsStream.WriteInt(int32(math.Float32bits(cpt))); err != nil {
+			return err
+		}
+	}
+
+	w.writer.Reset(w.vectorsStream)
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			if (fd.flags & COMPRESSING_TV_OFFSETS) == 0 {
+				continue
+			}
+			cpt := charsPerTerm[sort.SearchInts(fieldNums, fd.fieldNum)]
+			pos := 0
+			for i := 0; i < fd.numTerms; i++ {
+				previousPos, previousOff := 0, 0
+				for j := 0; j < fd.freqs[i]; j++ {
+					position := 0
+					if fd.hasPositions {
+						position = w.positionsBuf[fd.posStart+pos]
+					}
+					startOffset := w.startOffsetsBuf[fd.offStart+pos]
+					delta := startOffset - previousOff - int(cpt*float32(position-previousPos))
+					if err := w.writer.Add(int64(delta)); err != nil {
+						return err
+					}
+					previousPos, previousOff = position, startOffset
+					pos++
+				}
+			}
+		}
+	}
+	if err := w.writer.Finish(); err != nil {
+		return err
+	}
+
+	// lengths
+	w.writer.Reset(w.vectorsStream)
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			if (fd.flags & COMPRESSING_TV_OFFSETS) == 0 {
+				continue
+			}
+			pos := 0
+			for i := 0; i < fd.numTerms; i++ {
+				for j := 0; j < fd.freqs[i]; j++ {
+					length := w.lengthsBuf[fd.offStart+pos] - fd.prefixLengths[i] - fd.suffixLengths[i]
+					if err := w.writer.Add(int64(length)); err != nil {
+						return err
+					}
+					pos++
+				}
+			}
+			if pos != fd.totalPositions {
+				panic("assert fail")
+			}
+		}
+	}
+	return w.writer.Finish()
+}
+
+func (w *CompressingTermVectorsWriter) flushPayloadLengths() error {
+	w.writer.Reset(w.vectorsStream)
+	for _, dd := range w.pendingDocs {
+		for _, fd := range dd.fields {
+			if !fd.hasPayloads {
+				continue
+			}
+			for i := 0; i < fd.totalPositions; i++ {
+				if err := w.writer.Add(int64(w.payloadLengthsBuf[fd.payStart+i])); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return w.writer.Finish()
+}
+
+func (w *CompressingTermVectorsWriter) Finish(fis FieldInfos, numDocs int) error {
+	if len(w.pendingDocs) > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	if numDocs != w.numDocs {
+		return errors.New(fmt.Sprintf("Wrote %v docs, finish called with numDocs=%v", w.numDocs, numDocs))
+	}
+	return w.indexWriter.finish(numDocs)
+}
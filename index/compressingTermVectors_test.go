@@ -0,0 +1,622 @@
+package index
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+type testTVTerm struct {
+	text      string
+	freq      int
+	positions []int    // nil if the field has no positions
+	starts    []int    // nil if the field has no offsets
+	ends      []int    // nil if the field has no offsets
+	payloads  []string // nil if the field has no payloads
+}
+
+type testTVField struct {
+	number int
+	flags  int
+	terms  []testTVTerm
+}
+
+// Writes term vectors in the compressing format, to mimic
+// CompressingTermVectorsWriter. The data of each chunk is compressed
+// as LZ4 literals only.
+type testTVWriter struct {
+	vectors []byte
+	// per chunk
+	docBases      []int
+	startPointers []int64
+}
+
+func (w *testTVWriter) writeVInt(buf []byte, n int) []byte {
+	for n >= 0x80 {
+		buf = append(buf, byte(n&0x7f|0x80))
+		n >>= 7
+	}
+	return append(buf, byte(n))
+}
+
+func (w *testTVWriter) writeHeader(buf []byte, codecName string) []byte {
+	magic := uint32(codec.CODEC_MAGIC)
+	buf = append(buf, byte(magic>>24), byte(magic>>16), byte(magic>>8), byte(magic))
+	buf = w.writeVInt(buf, len(codecName))
+	buf = append(buf, codecName...)
+	return append(buf, 0, 0, 0, COMPRESSING_TV_VERSION_CURRENT)
+}
+
+// Appends values as a big-endian bit stream, as the PACKED format does.
+func (w *testTVWriter) writePacked(buf []byte, values []int64, bpv uint32) []byte {
+	packed := make([]byte, util.PackedFormat(util.PACKED).ByteCount(
+		util.PACKED_VERSION_CURRENT, int32(len(values)), bpv))
+	for i, v := range values {
+		for b := uint32(0); b < bpv; b++ {
+			if v&(int64(1)<<(bpv-1-b)) != 0 {
+				bit := uint32(i)*bpv + b
+				packed[bit/8] |= 1 << (7 - bit%8)
+			}
+		}
+	}
+	return append(buf, packed...)
+}
+
+// Appends values as BlockPackedWriter does.
+func (w *testTVWriter) writeBlockPacked(buf []byte, values []int64) []byte {
+	for len(values) > 0 {
+		block := values
+		if len(block) > COMPRESSING_TV_BLOCK_SIZE {
+			block = block[:COMPRESSING_TV_BLOCK_SIZE]
+		}
+		values = values[len(block):]
+
+		min, max := block[0], block[0]
+		for _, v := range block {
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		bpv := uint32(0)
+		if max != min {
+			bpv = util.BitsRequired(max - min)
+		}
+		token := byte(bpv << util.BLOCK_PACKED_BPV_SHIFT)
+		if min == 0 {
+			token |= util.BLOCK_PACKED_MIN_VALUE_EQUALS_0
+		}
+		buf = append(buf, token)
+		if min != 0 {
+			// zig-zag encoded, minus one since it can't be 0
+			n := uint64((min<<1)^(min>>63)) - 1
+			for n >= 0x80 {
+				buf = append(buf, byte(n&0x7F|0x80))
+				n >>= 7
+			}
+			buf = append(buf, byte(n))
+		}
+		if bpv > 0 {
+			deltas := make([]int64, len(block))
+			for i, v := range block {
+				deltas[i] = v - min
+			}
+			buf = w.writePacked(buf, deltas, bpv)
+		}
+	}
+	return buf
+}
+
+func (w *testTVWriter) writeLZ4Literals(buf []byte, data []byte) []byte {
+	n := len(data)
+	if n < 0x0F {
+		buf = append(buf, byte(n<<4))
+	} else {
+		buf = append(buf, 0xF0)
+		for n -= 0x0F; n >= 0xFF; n -= 0xFF {
+			buf = append(buf, 0xFF)
+		}
+		buf = append(buf, byte(n))
+	}
+	return append(buf, data...)
+}
+
+func (w *testTVWriter) charsPerTerm(number int) float32 {
+	return 1.5 + float32(number)
+}
+
+func (w *testTVWriter) writeChunk(docBase int, docs [][]testTVField, flagsPerField bool) {
+	w.docBases = append(w.docBases, docBase)
+	w.startPointers = append(w.startPointers, int64(len(w.vectors)))
+	buf := w.writeVInt(w.vectors, docBase)
+	buf = w.writeVInt(buf, len(docs))
+
+	var fields []testTVField
+	var numFields []int64
+	for _, doc := range docs {
+		fields = append(fields, doc...)
+		numFields = append(numFields, int64(len(doc)))
+	}
+	if len(docs) == 1 {
+		buf = w.writeVInt(buf, len(fields))
+	} else {
+		buf = w.writeBlockPacked(buf, numFields)
+	}
+	if len(fields) == 0 {
+		w.vectors = buf
+		return
+	}
+
+	// field numbers
+	distinct := map[int]int{}
+	var fieldNums []int
+	for _, f := range fields {
+		if _, ok := distinct[f.number]; !ok {
+			distinct[f.number] = f.flags
+			fieldNums = append(fieldNums, f.number)
+		}
+	}
+	sort.Ints(fieldNums)
+	values := make([]int64, len(fieldNums))
+	for i, v := range fieldNums {
+		values[i] = int64(v)
+	}
+	bitsPerFieldNum := util.BitsRequired(int64(fieldNums[len(fieldNums)-1]))
+	numDistinctFields := len(fieldNums) - 1
+	if numDistinctFields < 0x07 {
+		buf = append(buf, byte(numDistinctFields<<5|int(bitsPerFieldNum)))
+	} else {
+		buf = append(buf, byte(0x07<<5|int(bitsPerFieldNum)))
+		buf = w.writeVInt(buf, numDistinctFields-0x07)
+	}
+	buf = w.writePacked(buf, values, bitsPerFieldNum)
+
+	// field number offsets and flags
+	fieldNumOffs := make([]int64, len(fields))
+	fieldFlags := make([]int64, len(fields))
+	for i, f := range fields {
+		fieldNumOffs[i] = int64(sort.SearchInts(fieldNums, f.number))
+		fieldFlags[i] = int64(f.flags)
+	}
+	buf = w.writePacked(buf, fieldNumOffs, util.BitsRequired(int64(len(fieldNums)-1)))
+	if flagsPerField {
+		buf = w.writeVInt(buf, 1)
+		buf = w.writePacked(buf, fieldFlags, COMPRESSING_TV_FLAGS_BITS)
+	} else {
+		buf = w.writeVInt(buf, 0)
+		for i, num := range fieldNums {
+			values[i] = int64(distinct[num])
+		}
+		buf = w.writePacked(buf, values, COMPRESSING_TV_FLAGS_BITS)
+	}
+
+	// number of terms
+	numTerms := make([]int64, len(fields))
+	maxNumTerms := int64(0)
+	for i, f := range fields {
+		numTerms[i] = int64(len(f.terms))
+		if numTerms[i] > maxNumTerms {
+			maxNumTerms = numTerms[i]
+		}
+	}
+	bitsPerNumTerms := util.BitsRequired(maxNumTerms)
+	buf = w.writeVInt(buf, int(bitsPerNumTerms))
+	buf = w.writePacked(buf, numTerms, bitsPerNumTerms)
+
+	// term lengths, freqs, positions, offsets and payloads
+	var prefixLengths, suffixLengths, freqs, positions, startOffsets, lengths, payloadLengths []int64
+	var data []byte
+	for _, doc := range docs {
+		var payloads []byte
+		for _, f := range doc {
+			var previous string
+			for _, term := range f.terms {
+				prefix := 0
+				for prefix < len(previous) && prefix < len(term.text) && previous[prefix] == term.text[prefix] {
+					prefix++
+				}
+				prefixLengths = append(prefixLengths, int64(prefix))
+				suffixLengths = append(suffixLengths, int64(len(term.text)-prefix))
+				data = append(data, term.text[prefix:]...)
+				previous = term.text
+				freqs = append(freqs, int64(term.freq-1))
+
+				previousPosition, previousOffset := 0, 0
+				for k := 0; k < term.freq; k++ {
+					position := 0
+					if (f.flags & COMPRESSING_TV_POSITIONS) != 0 {
+						position = term.positions[k]
+						positions = append(positions, int64(position-previousPosition))
+					}
+					if (f.flags & COMPRESSING_TV_OFFSETS) != 0 {
+						delta := int(w.charsPerTerm(f.number) * float32(position-previousPosition))
+						startOffsets = append(startOffsets, int64(term.starts[k]-previousOffset-delta))
+						lengths = append(lengths, int64(term.ends[k]-term.starts[k]-len(term.text)))
+						previousOffset = term.starts[k]
+					}
+					if (f.flags & COMPRESSING_TV_PAYLOADS) != 0 {
+						payloadLengths = append(payloadLengths, int64(len(term.payloads[k])))
+						payloads = append(payloads, term.payloads[k]...)
+					}
+					previousPosition = position
+				}
+			}
+		}
+		data = append(data, payloads...)
+	}
+	buf = w.writeBlockPacked(buf, prefixLengths)
+	buf = w.writeBlockPacked(buf, suffixLengths)
+	buf = w.writeBlockPacked(buf, freqs)
+	buf = w.writeBlockPacked(buf, positions)
+	if len(startOffsets) > 0 {
+		for _, num := range fieldNums {
+			n := math.Float32bits(w.charsPerTerm(num))
+			buf = append(buf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+		}
+		buf = w.writeBlockPacked(buf, startOffsets)
+		buf = w.writeBlockPacked(buf, lengths)
+	}
+	buf = w.writeBlockPacked(buf, payloadLengths)
+
+	w.vectors = w.writeLZ4Literals(buf, data)
+}
+
+// Writes the .tvx and .tvd files of segment _0 to dir.
+func (w *testTVWriter) finish(dir, formatName string) error {
+	index := w.writeHeader(nil, formatName+CODEC_SFX_IDX)
+	index = w.writeVInt(index, util.PACKED_VERSION_CURRENT)
+	// a single block with average 0, so that deltas are the values
+	index = w.writeVInt(index, len(w.docBases))
+	index = w.writeVInt(index, w.docBases[0])
+	index = w.writeVInt(index, 0)
+	docBaseDeltas := make([]int64, len(w.docBases))
+	for i, v := range w.docBases {
+		docBaseDeltas[i] = int64(v-w.docBases[0]) << 1
+	}
+	bits := util.BitsRequired(docBaseDeltas[len(docBaseDeltas)-1])
+	index = w.writeVInt(index, int(bits))
+	index = w.writePacked(index, docBaseDeltas, bits)
+	index = w.writeVInt(index, int(w.startPointers[0]))
+	index = w.writeVInt(index, 0)
+	startPointerDeltas := make([]int64, len(w.startPointers))
+	for i, v := range w.startPointers {
+		startPointerDeltas[i] = (v - w.startPointers[0]) << 1
+	}
+	bits = util.BitsRequired(startPointerDeltas[len(startPointerDeltas)-1])
+	index = w.writeVInt(index, int(bits))
+	index = w.writePacked(index, startPointerDeltas, bits)
+	index = w.writeVInt(index, 0)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "_0.tvx"), index, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "_0.tvd"), w.vectors, 0644)
+}
+
+const (
+	testTVBody = iota
+	testTVTitle
+	testTVTags
+)
+
+var testTVFieldNames = []string{"body", "title", "tags"}
+
+func newTestTVFieldInfos() FieldInfos {
+	return NewFieldInfos([]FieldInfo{
+		NewFieldInfo("body", true, testTVBody, true, false, true, INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS, 0, 0, nil),
+		NewFieldInfo("title", true, testTVTitle, true, false, false, INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS, 0, 0, nil),
+		NewFieldInfo("tags", true, testTVTags, true, false, false, INDEX_OPT_DOCS_AND_FREQS, 0, 0, nil),
+		NewFieldInfo("other", true, testTVTags+1, false, false, false, INDEX_OPT_DOCS_AND_FREQS, 0, 0, nil),
+	})
+}
+
+// Returns docs of term vectors spanning several chunks.
+func newTestTVDocs() [][]testTVField {
+	const (
+		body  = testTVBody
+		title = testTVTitle
+		tags  = testTVTags
+	)
+	const (
+		all       = COMPRESSING_TV_POSITIONS | COMPRESSING_TV_OFFSETS | COMPRESSING_TV_PAYLOADS
+		positions = COMPRESSING_TV_POSITIONS
+		offsets   = COMPRESSING_TV_OFFSETS
+	)
+	docs := [][]testTVField{
+		{ // chunk with a single doc
+			{body, all, []testTVTerm{
+				{"apple", 2, []int{0, 3}, []int{0, 20}, []int{5, 25}, []string{"p1", ""}},
+				{"apricot", 1, []int{1}, []int{6}, []int{13}, []string{"xyz"}},
+				{"banana", 1, []int{2}, []int{14}, []int{20}, []string{""}},
+			}},
+			{title, positions, []testTVTerm{
+				{"hello", 3, []int{0, 4, 9}, nil, nil, nil},
+			}},
+		},
+		// chunk of several docs, with different flags per field
+		{
+			{tags, 0, []testTVTerm{
+				{"a", 1, nil, nil, nil, nil},
+				{"ab", 2, nil, nil, nil, nil},
+			}},
+			{title, offsets, []testTVTerm{
+				{"world", 2, nil, []int{0, 100}, []int{5, 105}, nil},
+			}},
+		},
+		{}, // no term vectors
+		{
+			{body, all, []testTVTerm{
+				{"cherry", 1, []int{0}, []int{0}, []int{6}, []string{"q"}},
+				{"cherrypie", 2, []int{1, 7}, []int{7, 50}, []int{16, 59}, []string{"", "long payload"}},
+			}},
+			{title, positions, []testTVTerm{
+				{"zed", 1, []int{5}, nil, nil, nil},
+			}},
+		},
+		{
+			{tags, 0, []testTVTerm{
+				{"c", 1, nil, nil, nil, nil},
+			}},
+		},
+	}
+	// plenty of terms to span several packed blocks
+	var many []testTVTerm
+	for i := 0; i < 150; i++ {
+		text := string([]byte{'t', byte('a' + i/26), byte('a' + i%26)})
+		many = append(many, testTVTerm{text, 1 + i%3, []int{i, i + 200, i + 400}[:1+i%3],
+			[]int{i * 4, i*4 + 1000, i*4 + 2000}[:1+i%3], []int{i*4 + 3, i*4 + 1003, i*4 + 2003}[:1+i%3],
+			[]string{"", "x", "yy"}[:1+i%3]})
+	}
+	docs = append(docs, []testTVField{{body, all, many}})
+	return docs
+}
+
+func TestCompressingTermVectorsReader(t *testing.T) {
+	docs := newTestTVDocs()
+
+	w := &testTVWriter{}
+	header := w.writeHeader(nil, "Lucene42TermVectors"+CODEC_SFX_DAT)
+	header = w.writeVInt(header, util.PACKED_VERSION_CURRENT)
+	w.vectors = w.writeVInt(header, 1<<12)
+	w.writeChunk(0, docs[:1], false)
+	w.writeChunk(1, docs[1:5], true)
+	w.writeChunk(5, docs[5:], false)
+
+	dir, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = w.finish(dir, "Lucene42TermVectors"); err != nil {
+		t.Fatal(err)
+	}
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fieldInfos := newTestTVFieldInfos()
+	si := SegmentInfo{name: "_0", docCount: int32(len(docs))}
+	r, err := newLucene42TermVectorsReader(d, si, fieldInfos, store.IO_CONTEXT_READ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	reader := r.clone()
+	defer reader.Close()
+	// visit docs out of order, so that chunks are switched
+	checkTestTVDocs(t, reader, docs, []int{3, 0, 5, 4, 1, 2, 0, 3})
+}
+
+func TestCompressingTermVectorsWriter(t *testing.T) {
+	docs := newTestTVDocs()
+	// more docs than a chunk can hold
+	for i := 0; i < 2*COMPRESSING_TV_MAX_DOCUMENTS_PER_CHUNK; i++ {
+		docs = append(docs, docs[i%5])
+	}
+
+	dir, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fieldInfos := newTestTVFieldInfos()
+	si := &SegmentInfo{name: "_0", docCount: int32(len(docs))}
+	// small chunks, so that big docs get a chunk of their own
+	format := NewCompressingTermVectorsFormat("Lucene42TermVectors", "", codec.COMPRESSION_MODE_FAST, 64)
+	w, err := format.VectorsWriter(d, si, store.IO_CONTEXT_DEFAULT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range docs {
+		if err = w.StartDocument(len(doc)); err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range doc {
+			err = w.StartField(fieldInfos.values[field.number], len(field.terms),
+				field.flags&COMPRESSING_TV_POSITIONS != 0, field.flags&COMPRESSING_TV_OFFSETS != 0,
+				field.flags&COMPRESSING_TV_PAYLOADS != 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, term := range field.terms {
+				if err = w.StartTerm([]byte(term.text), term.freq); err != nil {
+					t.Fatal(err)
+				}
+				if field.flags&(COMPRESSING_TV_POSITIONS|COMPRESSING_TV_OFFSETS) == 0 {
+					continue
+				}
+				for k := 0; k < term.freq; k++ {
+					position, start, end := -1, -1, -1
+					var payload []byte
+					if term.positions != nil {
+						position = term.positions[k]
+					}
+					if term.starts != nil {
+						start, end = term.starts[k], term.ends[k]
+					}
+					if term.payloads != nil {
+						payload = []byte(term.payloads[k])
+					}
+					if err = w.AddPosition(position, start, end, payload); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if err = w.FinishField(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = w.FinishDocument(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Finish(fieldInfos, len(docs)-1); err == nil {
+		t.Error("Should fail on a wrong number of docs")
+	}
+	if err = w.Finish(fieldInfos, len(docs)); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := format.VectorsReader(d, *si, fieldInfos, store.IO_CONTEXT_READ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	order := make([]int, len(docs))
+	for i, _ := range order {
+		order[i] = len(docs) - 1 - i
+	}
+	checkTestTVDocs(t, r, docs, order)
+}
+
+// Checks the term vectors of the given docs, in the given order.
+func checkTestTVDocs(t *testing.T, reader TermVectorsReader, docs [][]testTVField, order []int) {
+	names := testTVFieldNames
+	for _, doc := range order {
+		fields, err := reader.get(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(docs[doc]) == 0 {
+			if fields != nil {
+				t.Errorf("Doc %v should have no term vectors", doc)
+			}
+			continue
+		}
+		if fields.Terms("other") != nil || fields.Terms("unknown") != nil {
+			t.Errorf("Doc %v should have no term vectors of other fields", doc)
+		}
+		seen := map[string]bool{}
+		for _, field := range docs[doc] {
+			name := names[field.number]
+			seen[name] = true
+			terms := fields.Terms(name)
+			if terms == nil {
+				t.Fatalf("Doc %v should have term vectors of %v", doc, name)
+			}
+			if terms.DocCount() != 1 || terms.SumDocFreq() != int64(len(field.terms)) {
+				t.Errorf("Unexpected stats of %v in doc %v: docCount=%v sumDocFreq=%v",
+					name, doc, terms.DocCount(), terms.SumDocFreq())
+			}
+			termsEnum := terms.Iterator(nil)
+			for _, expected := range field.terms {
+				term, err := termsEnum.Next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(term) != expected.text {
+					t.Fatalf("Expected term '%v' of %v in doc %v, but was '%v'", expected.text, name, doc, string(term))
+				}
+				if termsEnum.TotalTermFreq() != int64(expected.freq) {
+					t.Errorf("Expected freq %v of '%v', but was %v", expected.freq, expected.text, termsEnum.TotalTermFreq())
+				}
+				docsEnum := termsEnum.DocsByFlags(nil, DOCS_ENUM_EMPTY, DOCS_ENUM_FLAG_FREQS)
+				if d, more := docsEnum.NextDoc(); !more || d != 0 || docsEnum.Freq() != expected.freq {
+					t.Errorf("Expected doc 0 with freq %v, but was %v (freq=%v)", expected.freq, d, docsEnum.Freq())
+				}
+				if _, more := docsEnum.NextDoc(); more {
+					t.Error("Should be exhausted")
+				}
+
+				dpEnum := termsEnum.DocsAndPositionsByFlags(nil, DocsAndPositionsEnum{},
+					DOCS_POSITIONS_ENUM_FLAG_OFF_SETS|DOCS_POSITIONS_ENUM_FLAG_PAYLOADS)
+				if field.flags&(COMPRESSING_TV_POSITIONS|COMPRESSING_TV_OFFSETS) == 0 {
+					if dpEnum.DocsAndPositionsIterator != nil {
+						t.Errorf("Expected no positions of %v", name)
+					}
+					continue
+				}
+				if d, more := dpEnum.NextDoc(); !more || d != 0 {
+					t.Fatalf("Expected doc 0, but was %v", d)
+				}
+				for k := 0; k < expected.freq; k++ {
+					position := dpEnum.NextPosition()
+					if expected.positions == nil && position != -1 || expected.positions != nil && position != expected.positions[k] {
+						t.Errorf("Unexpected position %v of '%v' at %v in doc %v", position, expected.text, k, doc)
+					}
+					start, end := dpEnum.StartOffset(), dpEnum.EndOffset()
+					if expected.starts == nil && (start != -1 || end != -1) ||
+						expected.starts != nil && (start != expected.starts[k] || end != expected.ends[k]) {
+						t.Errorf("Unexpected offsets [%v, %v) of '%v' at %v in doc %v", start, end, expected.text, k, doc)
+					}
+					payload := dpEnum.Payload()
+					if expected.payloads == nil && payload != nil ||
+						expected.payloads != nil && string(payload) != expected.payloads[k] {
+						t.Errorf("Unexpected payload '%v' of '%v' at %v in doc %v", string(payload), expected.text, k, doc)
+					}
+				}
+			}
+			if term, err := termsEnum.Next(); err != nil || term != nil {
+				t.Errorf("Terms of %v in doc %v should be exhausted, but was '%v' (%v)", name, doc, string(term), err)
+			}
+
+			// seeking
+			last := field.terms[len(field.terms)-1].text
+			if status := termsEnum.SeekCeil([]byte(last)); status != SEEK_STATUS_FOUND {
+				t.Errorf("Should find '%v', but was %v", last, status)
+			}
+			if status := termsEnum.SeekCeil([]byte(field.terms[0].text)); status != SEEK_STATUS_FOUND ||
+				!bytes.Equal(termsEnum.Term(), []byte(field.terms[0].text)) {
+				t.Errorf("Should seek back to '%v', but was %v", field.terms[0].text, status)
+			}
+			if status := termsEnum.SeekCeil([]byte(last + "z")); status != SEEK_STATUS_END {
+				t.Errorf("Should reach the end after '%v', but was %v", last, status)
+			}
+		}
+		for _, name := range names {
+			if !seen[name] && fields.Terms(name) != nil {
+				t.Errorf("Doc %v should have no term vectors of %v", doc, name)
+			}
+		}
+	}
+}
+
+func TestCompressingTermVectorsReaderLiveDocs(t *testing.T) {
+	terms := &tvTerms{numTerms: 1, termFreqs: []int{1}, positionIndex: []int{0, 1}}
+	e := newTVTermsEnum()
+	e.reset(terms)
+	e.ord = 0
+	for _, live := range []bool{true, false} {
+		docsEnum := e.DocsByFlags(testBits{live}, DOCS_ENUM_EMPTY, 0)
+		if _, more := docsEnum.NextDoc(); more != live {
+			t.Errorf("Doc 0 should be visited iff it is live (%v)", live)
+		}
+	}
+}
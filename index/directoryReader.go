@@ -2,6 +2,7 @@ package index
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/balzaczyy/golucene/store"
 	"github.com/balzaczyy/golucene/util"
@@ -13,11 +14,19 @@ const DEFAULT_TERMS_INDEX_DIVISOR = 1
 
 type DirectoryReader interface {
 	IndexReader
-	// doOpenIfChanged() error
-	// doOpenIfChanged(c IndexCommit) error
+	/*
+		Implement this method to support OpenIfChanged(). If this reader
+		does not support reopen, return nil, so client code is happy.
+		This should be consistent with IsCurrent() (should always return
+		true) if reopen is not supported. If commit is nil, the latest
+		commit in the directory is used.
+	*/
+	doOpenIfChanged(commit IndexCommit) (r DirectoryReader, err error)
 	// doOpenIfChanged(w IndexWriter, c IndexCommit) error
 	Version() int64
 	IsCurrent() bool
+	// Expert: returns the IndexCommit that this reader has opened.
+	IndexCommit() IndexCommit
 }
 
 type DirectoryReaderImpl struct {
@@ -36,27 +45,121 @@ func newDirectoryReader(self IndexReader, directory store.Directory, segmentRead
 	return ans
 }
 
+// Returns an IndexReader reading the index in the given Directory.
 func OpenDirectoryReader(directory store.Directory) (r DirectoryReader, err error) {
-	return openStandardDirectoryReader(directory, DEFAULT_TERMS_INDEX_DIVISOR)
+	return openStandardDirectoryReader(directory, nil, DEFAULT_TERMS_INDEX_DIVISOR)
+}
+
+/*
+Expert: returns an IndexReader reading the index in the given
+Directory with the given termInfosIndexDivisor.
+
+termInfosIndexDivisor subsamples which indexed terms are loaded into
+RAM. This has the same effect as IndexWriterConfig.setTermIndexInterval
+except that setting must be done at indexing time while this setting
+can be set per reader. When set to N, then one in every
+N*termIndexInterval terms in the index is loaded into memory. By
+setting this to a value > 1 you can reduce memory usage, at the
+expense of higher latency when loading a TermInfo. The default value
+is 1. Set this to -1 to skip loading the terms index entirely. NOTE:
+divisor settings > 1 do not apply to all PostingsFormat
+implementations, including the default one in this release. It only
+makes sense for terms indexes that can efficiently re-sample terms at
+load time.
+*/
+func OpenDirectoryReaderWithDivisor(directory store.Directory, termInfosIndexDivisor int) (r DirectoryReader, err error) {
+	return openStandardDirectoryReader(directory, nil, termInfosIndexDivisor)
+}
+
+/*
+Open a near real time IndexReader from the IndexWriter. The buffered
+documents of the writer are flushed to new segments, without a
+commit, so the reader sees them within milliseconds of indexing.
+
+If applyAllDeletes is true, the pending deletes and doc values
+updates of the writer are applied as well, so the reader sees them;
+if false, they may or may not be visible, depending on whether they
+were applied already.
+
+OpenIfChanged() on the returned reader re-asks the writer for a new
+near real time reader.
+*/
+func OpenDirectoryReaderFromWriter(writer *IndexWriter, applyAllDeletes bool) (r DirectoryReader, err error) {
+	return writer.GetReader(applyAllDeletes)
+}
+
+/*
+Expert: returns an IndexReader reading the index in the given
+IndexCommit. The segments file of the commit is read directly, so no
+segments.gen lookup or directory listing is involved, which makes it
+suitable to serve a file set restored from a backup or replicated
+from elsewhere (see NewIndexCommitFromFiles). The checksum of the
+segments file is verified, as well as that every file referenced by
+the commit is listed by it and exists in the directory.
+*/
+func OpenDirectoryReaderFromCommit(commit IndexCommit) (r DirectoryReader, err error) {
+	return openStandardDirectoryReader(commit.Directory(), commit, DEFAULT_TERMS_INDEX_DIVISOR)
+}
+
+/*
+Expert: returns an IndexReader reading the index in the given
+IndexCommit and termInfosIndexDivisor. See
+OpenDirectoryReaderWithDivisor() for details on the divisor.
+*/
+func OpenDirectoryReaderFromCommitWithDivisor(commit IndexCommit, termInfosIndexDivisor int) (r DirectoryReader, err error) {
+	return openStandardDirectoryReader(commit.Directory(), commit, termInfosIndexDivisor)
+}
+
+/*
+If the index has changed since the provided reader was opened, open
+and return a new reader; else, return nil. The new reader, if not
+nil, will be the same type of reader as the previous one, ie an NRT
+reader will open a new NRT reader, a MultiReader will open a new
+MultiReader, etc.
+
+This method is typically far less costly than opening a fully new
+DirectoryReader as it shares resources (for example sub-readers) with
+the provided DirectoryReader, when possible.
+
+The provided reader is not closed (you are responsible for doing so);
+if a new reader is returned you also must eventually close it. Be sure
+to never close a reader while other goroutines are still using it.
+*/
+func OpenIfChanged(oldReader DirectoryReader) (r DirectoryReader, err error) {
+	return oldReader.doOpenIfChanged(nil)
+}
+
+/*
+If the IndexCommit differs from what the provided reader is
+searching, open and return a new reader; else, return nil.
+*/
+func OpenIfChangedFromCommit(oldReader DirectoryReader, commit IndexCommit) (r DirectoryReader, err error) {
+	return oldReader.doOpenIfChanged(commit)
 }
 
 type StandardDirectoryReader struct {
 	*DirectoryReaderImpl
-	segmentInfos SegmentInfos
+	// the writer this near real time reader was obtained from, if any
+	writer                *IndexWriter
+	segmentInfos          SegmentInfos
+	termInfosIndexDivisor int
+	applyAllDeletes       bool
 }
 
-// TODO support IndexWriter
 func newStandardDirectoryReader(directory store.Directory, readers []AtomicReader,
 	sis SegmentInfos, termInfosIndexDivisor int, applyAllDeletes bool) *StandardDirectoryReader {
 	log.Printf("Initializing StandardDirectoryReader with %v sub readers...", len(readers))
-	ans := &StandardDirectoryReader{segmentInfos: sis}
+	ans := &StandardDirectoryReader{segmentInfos: sis, termInfosIndexDivisor: termInfosIndexDivisor,
+		applyAllDeletes: applyAllDeletes}
 	ans.DirectoryReaderImpl = newDirectoryReader(ans, directory, readers)
 	return ans
 }
 
-// TODO support IndexCommit
-func openStandardDirectoryReader(directory store.Directory,
+func openStandardDirectoryReader(directory store.Directory, commit IndexCommit,
 	termInfosIndexDivisor int) (r DirectoryReader, err error) {
+	if termInfosIndexDivisor == 0 {
+		return nil, errors.New("indexDivisor must be < 0 (don't load terms index) or greater than 0 (got 0)")
+	}
 	log.Print("Initializing SegmentsFile...")
 	obj, err := NewFindSegmentsFile(directory, func(segmentFileName string) (obj interface{}, err error) {
 		sis := &SegmentInfos{}
@@ -65,27 +168,110 @@ func openStandardDirectoryReader(directory store.Directory,
 			return nil, err
 		}
 		log.Printf("Found %v segments...", len(sis.Segments))
+		if commit != nil {
+			if err = checkCommitFiles(directory, commit, sis); err != nil {
+				return nil, err
+			}
+		}
 		readers := make([]AtomicReader, len(sis.Segments))
 		for i := len(sis.Segments) - 1; i >= 0; i-- {
 			sr, err := NewSegmentReader(sis.Segments[i], termInfosIndexDivisor, store.IO_CONTEXT_READ)
-			readers[i] = sr
 			if err != nil {
-				rs := make([]io.Closer, len(readers))
-				for i, v := range readers {
-					rs[i] = v
+				// close the readers opened so far
+				rs := make([]io.Closer, 0, len(readers)-i-1)
+				for _, v := range readers[i+1:] {
+					rs = append(rs, v)
 				}
 				return nil, util.CloseWhileHandlingError(err, rs...)
 			}
+			readers[i] = sr
 		}
 		log.Printf("Obtained %v SegmentReaders.", len(readers))
 		return newStandardDirectoryReader(directory, readers, *sis, termInfosIndexDivisor, false), nil
-	}).run()
+	}).run(commit)
 	if err != nil {
 		return nil, err
 	}
 	return obj.(*StandardDirectoryReader), err
 }
 
+/*
+Used by doOpenIfChanged() to open a reader on the given SegmentInfos,
+reusing the SegmentReaders of the old reader for segments which did
+not change.
+*/
+func openStandardDirectoryReaderFrom(directory store.Directory, infos SegmentInfos,
+	oldReaders []IndexReader, termInfosIndexDivisor int) (r *StandardDirectoryReader, err error) {
+	// we put the old SegmentReaders in a map, that allows us to lookup
+	// a reader using its segment name
+	segmentReaders := make(map[string]*SegmentReader)
+	for _, v := range oldReaders {
+		sr := v.(*SegmentReader)
+		segmentReaders[sr.si.info.name] = sr
+	}
+
+	newReaders := make([]AtomicReader, len(infos.Segments))
+	// remember which readers are shared between the old and the
+	// re-opened DirectoryReader - we have to incRef those readers
+	readerShared := make([]bool, len(infos.Segments))
+	for i := len(infos.Segments) - 1; i >= 0; i-- {
+		info := infos.Segments[i]
+		// find SegmentReader for this segment
+		var newReader *SegmentReader
+		oldReader, ok := segmentReaders[info.info.name]
+		if !ok || info.info.isCompoundFile != oldReader.si.info.isCompoundFile {
+			// this is a new reader; in case we hit an error we can close
+			// it safely
+			newReader, err = NewSegmentReader(info, termInfosIndexDivisor, store.IO_CONTEXT_READ)
+		} else if oldReader.si.delGen == info.delGen && oldReader.si.fieldInfosGen == info.fieldInfosGen {
+			// No change; this reader will be shared between the old and
+			// the new one, so we must incRef it:
+			readerShared[i] = true
+			oldReader.incRef()
+			newReader = oldReader
+		} else {
+			// assert info.info.dir == oldReader.si.info.dir
+			newReader, err = openSegmentReaderFromCore(info, oldReader.core)
+		}
+		if err != nil {
+			for j := i + 1; j < len(newReaders); j++ {
+				if readerShared[j] {
+					// this subReader is also used by the old reader, so
+					// instead closing we must decRef it
+					newReaders[j].decRef()
+				} else {
+					// this is a new subReader that is not used by the old
+					// one, we can close it
+					newReaders[j].Close()
+				}
+			}
+			return nil, err
+		}
+		newReaders[i] = newReader
+	}
+	return newStandardDirectoryReader(directory, newReaders, infos, termInfosIndexDivisor, false), nil
+}
+
+// Verifies that the files referenced by the segments of the commit
+// are all part of the commit and present in the directory.
+func checkCommitFiles(directory store.Directory, commit IndexCommit, sis *SegmentInfos) error {
+	listed := make(map[string]bool)
+	for _, file := range commit.FileNames() {
+		listed[file] = true
+	}
+	for _, file := range sis.Files(directory, true) {
+		if !listed[file] {
+			return errors.New(fmt.Sprintf("file '%v' is referenced by %v but not listed in commit %v",
+				file, commit.SegmentsFileName(), commit))
+		}
+		if !directory.FileExists(file) {
+			return errors.New(fmt.Sprintf("file '%v' referenced by %v does not exist in %v",
+				file, commit.SegmentsFileName(), directory))
+		}
+	}
+	return nil
+}
+
 func (r *StandardDirectoryReader) String() string {
 	var buf bytes.Buffer
 	buf.WriteString("StandardDirectoryReader(")
@@ -93,9 +279,9 @@ func (r *StandardDirectoryReader) String() string {
 	if segmentsFile != "" {
 		fmt.Fprintf(&buf, "%v:%v", segmentsFile, r.segmentInfos.version)
 	}
-	// if r.writer != nil {
-	// fmt.Fprintf(w, "%v", r.writer)
-	// }
+	if r.writer != nil {
+		buf.WriteString(":nrt")
+	}
 	for _, v := range r.getSequentialSubReaders() {
 		fmt.Fprintf(&buf, " %v", v)
 	}
@@ -103,6 +289,70 @@ func (r *StandardDirectoryReader) String() string {
 	return buf.String()
 }
 
+func (r *StandardDirectoryReader) doOpenIfChanged(commit IndexCommit) (DirectoryReader, error) {
+	r.ensureOpen()
+	// If we were obtained by writer.getReader(), re-ask the writer to
+	// get a new reader.
+	if r.writer != nil {
+		return r.doOpenFromWriter(commit)
+	}
+	return r.doOpenNoWriter(commit)
+}
+
+func (r *StandardDirectoryReader) doOpenFromWriter(commit IndexCommit) (DirectoryReader, error) {
+	if commit != nil {
+		return r.doOpenFromCommit(commit)
+	}
+	if r.writer.nrtIsCurrent(&r.segmentInfos) {
+		return nil, nil
+	}
+	reader, err := r.writer.getReader(r.getSequentialSubReaders(), r.applyAllDeletes)
+	if err != nil {
+		return nil, err
+	}
+	// If in fact no changes took place, return nil:
+	if reader.Version() == r.segmentInfos.version {
+		return nil, reader.Close()
+	}
+	return reader, nil
+}
+
+func (r *StandardDirectoryReader) doOpenNoWriter(commit IndexCommit) (DirectoryReader, error) {
+	if commit == nil {
+		if r.IsCurrent() {
+			return nil, nil
+		}
+	} else {
+		if r.directory != commit.Directory() {
+			return nil, errors.New("the specified commit does not match the specified Directory")
+		}
+		if commit.SegmentsFileName() == r.segmentInfos.SegmentsFileName() {
+			return nil, nil
+		}
+	}
+	return r.doOpenFromCommit(commit)
+}
+
+func (r *StandardDirectoryReader) doOpenFromCommit(commit IndexCommit) (DirectoryReader, error) {
+	obj, err := NewFindSegmentsFile(r.directory, func(segmentFileName string) (obj interface{}, err error) {
+		infos := &SegmentInfos{}
+		if err = infos.Read(r.directory, segmentFileName); err != nil {
+			return nil, err
+		}
+		if commit != nil {
+			if err = checkCommitFiles(r.directory, commit, infos); err != nil {
+				return nil, err
+			}
+		}
+		return openStandardDirectoryReaderFrom(r.directory, *infos,
+			r.getSequentialSubReaders(), r.termInfosIndexDivisor)
+	}).run(commit)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*StandardDirectoryReader), nil
+}
+
 func (r *StandardDirectoryReader) Version() int64 {
 	r.ensureOpen()
 	return r.segmentInfos.version
@@ -110,22 +360,37 @@ func (r *StandardDirectoryReader) Version() int64 {
 
 func (r *StandardDirectoryReader) IsCurrent() bool {
 	r.ensureOpen()
-	// if writer == nill || writer.IsClosed() {
-	// Fully read the segments file: this ensures that it's
-	// completely written so that if
-	// IndexWriter.prepareCommit has been called (but not
-	// yet commit), then the reader will still see itself as
-	// current:
-	sis := SegmentInfos{}
-	sis.ReadAll(r.directory)
-
-	// we loaded SegmentInfos from the directory
-	return sis.version == r.segmentInfos.version
-	// } else {
-	// return writer.nrtIsCurrent(r.segmentInfos)
-	// }
-}
-
-func (r *StandardDirectoryReader) doClose() error {
-	panic("not implemented yet")
+	if r.writer == nil || r.writer.isClosed() {
+		// Fully read the segments file: this ensures that it's
+		// completely written so that if
+		// IndexWriter.prepareCommit has been called (but not
+		// yet commit), then the reader will still see itself as
+		// current:
+		sis := SegmentInfos{}
+		sis.ReadAll(r.directory)
+
+		// we loaded SegmentInfos from the directory
+		return sis.version == r.segmentInfos.version
+	}
+	return r.writer.nrtIsCurrent(&r.segmentInfos)
+}
+
+func (r *StandardDirectoryReader) IndexCommit() IndexCommit {
+	r.ensureOpen()
+	return newReaderCommit(&r.segmentInfos, r.directory)
+}
+
+func (r *StandardDirectoryReader) doClose() (err error) {
+	for _, sub := range r.getSequentialSubReaders() {
+		// try to close each reader, even if an error is returned
+		if err2 := sub.decRef(); err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	if r.writer != nil {
+		// Since we just closed, writer may now be able to
+		// delete unused files:
+		r.writer.decRefDeleter(&r.segmentInfos)
+	}
+	return err
 }
@@ -3,6 +3,11 @@ package index
 import (
 	"fmt"
 	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -26,3 +31,428 @@ func TestLeaves(t *testing.T) {
 		t.Error("Should have one sub reader.")
 	}
 }
+
+func TestOpenFromCommit(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := d.ListAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := NewIndexCommitFromFiles(d, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "segments_1", commit.SegmentsFileName())
+	r, err := OpenDirectoryReaderFromCommit(commit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Leaves()) != 1 {
+		t.Error("Should have one sub reader.")
+	}
+
+	// a commit missing a referenced file must be rejected
+	var partial []string
+	for _, file := range files {
+		if file != "_0.si" {
+			partial = append(partial, file)
+		}
+	}
+	commit, err = NewIndexCommitFromFiles(d, partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = OpenDirectoryReaderFromCommit(commit); err == nil {
+		t.Error("Should fail on missing file _0.si.")
+	}
+}
+
+func TestFileBreakdown(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := r.Leaves()[0].Reader().(*SegmentReader).FileBreakdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SegmentFile{
+		{"_0.cfe", "cfe", "", "CompoundFileWriterEntries", 0, 268},
+		{"_0.cfs", "cfs", "", "CompoundFileWriterData", 0, 10072},
+		{"_0.si", "si", "", LUCENE40_CODEC_NAME, 0, 261},
+		{"_0.fdt", "fdt", "_0.cfs", "Lucene41StoredFieldsData", 0, 570},
+		{"_0.fdx", "fdx", "_0.cfs", "Lucene41StoredFieldsIndex", 0, 45},
+		{"_0.fnm", "fnm", "_0.cfs", "Lucene42FieldInfos", 0, 541},
+		{"_0.nvd", "nvd", "_0.cfs", "Lucene41NormsData", 1, 74},
+		{"_0.nvm", "nvm", "_0.cfs", "Lucene41NormsMetadata", 1, 101},
+		{"_0_Lucene41_0.doc", "doc", "_0.cfs", LUCENE41_DOC_CODEC, 0, 889},
+		{"_0_Lucene41_0.pos", "pos", "_0.cfs", LUCENE41_POS_CODEC, 0, 1965},
+		{"_0_Lucene41_0.tim", "tim", "_0.cfs", BTT_CODEC_NAME, 1, 5604},
+		{"_0_Lucene41_0.tip", "tip", "_0.cfs", BTT_INDEX_CODEC_NAME, 1, 252},
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("Expected %v files, but %v", len(expected), files)
+	}
+	for i, f := range files {
+		if f != expected[i] {
+			t.Errorf("Expected %#v, but %#v", expected[i], f)
+		}
+	}
+}
+
+func TestLeafForDoc(t *testing.T) {
+	leaves := []AtomicReaderContext{{DocBase: 0}, {DocBase: 10}, {DocBase: 10}, {DocBase: 25}}
+	for docID, expected := range map[int]int{0: 0, 9: 0, 10: 2, 24: 2, 25: 3, 100: 3} {
+		assertEquals(t, expected, SubIndex(docID, leaves))
+	}
+
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := r.Context().(*CompositeReaderContext)
+	leaf, localDocID := ctx.LeafForDoc(r.MaxDoc() - 1)
+	assertEquals(t, 0, ctx.SubIndex(r.MaxDoc()-1))
+	assertEquals(t, r.MaxDoc()-1, localDocID)
+	assertEquals(t, r.Leaves()[0].Reader(), leaf.Reader())
+	assertEquals(t, IndexReaderContext(ctx), TopLevelContext(&leaf))
+
+	// nested composite readers
+	mr := NewMultiReader([]IndexReader{r, r}, false)
+	defer mr.Close()
+	leaves = mr.Leaves()
+	leaf, localDocID = LeafForDoc(r.MaxDoc()+2, leaves)
+	assertEquals(t, 1, leaf.Ord)
+	assertEquals(t, 2, localDocID)
+	assertEquals(t, mr.Context(), TopLevelContext(&leaf))
+	child := mr.Context().Children()[1]
+	assertEquals(t, mr.Context(), TopLevelContext(child))
+	assertEquals(t, mr.Context(), TopLevelContext(mr.Context()))
+	defer func() {
+		if recover() == nil {
+			t.Error("Should panic on docID out of range")
+		}
+	}()
+	LeafForDoc(mr.MaxDoc(), leaves)
+}
+
+func TestOpenWithDivisor(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, divisor := range []int{-1, 1, 4} {
+		r, err := OpenDirectoryReaderWithDivisor(d, divisor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.NumDocs() != 8 {
+			t.Errorf("Expected 8 docs, but was %v", r.NumDocs())
+		}
+		sr := r.Leaves()[0].Reader().(*SegmentReader)
+		if n := sr.TermInfosIndexDivisor(); n != divisor {
+			t.Errorf("Expected divisor %v, but was %v", divisor, n)
+		}
+	}
+	if _, err = OpenDirectoryReaderWithDivisor(d, 0); err == nil {
+		t.Error("Should reject divisor 0")
+	}
+}
+
+type testReaderClosedListener struct {
+	closed []IndexReader
+}
+
+func (l *testReaderClosedListener) OnClose(r IndexReader) {
+	l.closed = append(l.closed, r)
+}
+
+type testCoreClosedListener struct {
+	closed chan *SegmentReader
+}
+
+func (l *testCoreClosedListener) OnClose(r *SegmentReader) {
+	l.closed <- r
+}
+
+func assertClosed(t *testing.T, desc string, r IndexReader) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%v should be closed", desc)
+		}
+	}()
+	r.ensureOpen()
+}
+
+func TestCloseDirectoryReader(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr := r.Leaves()[0].Reader().(*SegmentReader)
+
+	// a second reader sharing the same core
+	shared := newSegmentReaderFromCore(sr.si, sr.core, sr.liveDocs, sr.numDocs)
+	coreListener := &testCoreClosedListener{make(chan *SegmentReader, 1)}
+	sr.AddCoreClosedListener(coreListener)
+	listener := &testReaderClosedListener{}
+	r.(*StandardDirectoryReader).AddReaderClosedListener(listener)
+
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Close(); err != nil {
+		t.Errorf("Closing twice should be a no-op, but was %v", err)
+	}
+	if len(listener.closed) != 1 || listener.closed[0] != r {
+		t.Errorf("Listener should be notified once, but was %v", listener.closed)
+	}
+	assertClosed(t, "DirectoryReader", r)
+	assertClosed(t, "SegmentReader", sr)
+
+	// core is still referenced by the shared reader
+	if n := shared.NumDocs(); n != 8 {
+		t.Errorf("Expected 8 docs, but was %v", n)
+	}
+	if terms := shared.Terms("content"); terms == nil || terms.Iterator(nil) == nil {
+		t.Error("Shared reader should still be usable")
+	}
+	select {
+	case <-coreListener.closed:
+		t.Error("Core should not be closed yet")
+	default:
+	}
+	if err = shared.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if owner := <-coreListener.closed; owner != sr {
+		t.Errorf("Core listener should be notified with owner %v, but was %v", sr, owner)
+	}
+}
+
+func TestCloseSubReader(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr := r.Leaves()[0].Reader()
+	sr.incRef()
+	if err = sr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sr.ensureOpen() // still referenced by parent
+	if err = sr.decRef(); err != nil {
+		t.Fatal(err)
+	}
+	assertClosed(t, "DirectoryReader whose child was closed", r)
+}
+
+func TestCompositeReaderStats(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sis := SegmentInfos{}
+	if err = sis.ReadAll(d); err != nil {
+		t.Fatal(err)
+	}
+	// two sub readers on the same segment
+	subs := make([]AtomicReader, 2)
+	for i := range subs {
+		if subs[i], err = NewSegmentReader(sis.Segments[0], DEFAULT_TERMS_INDEX_DIVISOR, store.IO_CONTEXT_READ); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := newStandardDirectoryReader(d, subs, sis, DEFAULT_TERMS_INDEX_DIVISOR, false)
+	defer r.Close()
+
+	leaf := subs[0]
+	for _, term := range []Term{NewTerm("scope", "belfrysample"), NewTerm("scope", "missing"), NewTerm("missing", "x")} {
+		expected, err := leaf.DocFreq(term)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := r.DocFreq(term); err != nil || n != 2*expected {
+			t.Errorf("Expected docFreq %v of %v, but was %v (%v)", 2*expected, term, n, err)
+		}
+		expected2, err := leaf.TotalTermFreq(term)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected2 != -1 {
+			expected2 *= 2
+		}
+		if n, err := r.TotalTermFreq(term); err != nil || n != expected2 {
+			t.Errorf("Expected totalTermFreq %v of %v, but was %v (%v)", expected2, term, n, err)
+		}
+	}
+	if n, _ := leaf.DocFreq(NewTerm("scope", "belfrysample")); n != leaf.MaxDoc() {
+		t.Errorf("Expected docFreq %v, but was %v", leaf.MaxDoc(), n)
+	}
+
+	for _, field := range []string{"scope", "key", "content", "missing"} {
+		docCount, _ := leaf.DocCount(field)
+		if n, err := r.DocCount(field); err != nil || n != 2*docCount {
+			t.Errorf("Expected docCount %v of %v, but was %v (%v)", 2*docCount, field, n, err)
+		}
+		sumDocFreq, _ := leaf.SumDocFreq(field)
+		if n, err := r.SumDocFreq(field); err != nil || n != 2*sumDocFreq {
+			t.Errorf("Expected sumDocFreq %v of %v, but was %v (%v)", 2*sumDocFreq, field, n, err)
+		}
+		// -1 is propagated if the field omits freqs
+		sumTotalTermFreq, _ := leaf.SumTotalTermFreq(field)
+		if sumTotalTermFreq != -1 {
+			sumTotalTermFreq *= 2
+		}
+		if n, err := r.SumTotalTermFreq(field); err != nil || n != sumTotalTermFreq {
+			t.Errorf("Expected sumTotalTermFreq %v of %v, but was %v (%v)", sumTotalTermFreq, field, n, err)
+		}
+	}
+
+	// a sub reader lacking the stats makes them unavailable
+	r2 := newStandardDirectoryReader(d, []AtomicReader{subs[0], noStatsReader{subs[1]}}, sis, DEFAULT_TERMS_INDEX_DIVISOR, false)
+	if n, err := r2.TotalTermFreq(NewTerm("scope", "belfrysample")); err != nil || n != -1 {
+		t.Errorf("Expected totalTermFreq -1, but was %v (%v)", n, err)
+	}
+	if n, err := r2.DocCount("scope"); err != nil || n != -1 {
+		t.Errorf("Expected docCount -1, but was %v (%v)", n, err)
+	}
+	if n, err := r2.SumDocFreq("scope"); err != nil || n != -1 {
+		t.Errorf("Expected sumDocFreq -1, but was %v (%v)", n, err)
+	}
+	if n, err := r2.SumTotalTermFreq("scope"); err != nil || n != -1 {
+		t.Errorf("Expected sumTotalTermFreq -1, but was %v (%v)", n, err)
+	}
+	if n, err := r2.DocFreq(NewTerm("scope", "belfrysample")); err != nil || n != 2*leaf.MaxDoc() {
+		t.Errorf("Expected docFreq %v, but was %v (%v)", 2*leaf.MaxDoc(), n, err)
+	}
+}
+
+// An AtomicReader whose codec doesn't store the optional statistics.
+type noStatsReader struct {
+	AtomicReader
+}
+
+func (r noStatsReader) TotalTermFreq(term Term) (int64, error) { return -1, nil }
+func (r noStatsReader) SumDocFreq(field string) (int64, error) { return -1, nil }
+func (r noStatsReader) DocCount(field string) (int, error)     { return -1, nil }
+func (r noStatsReader) SumTotalTermFreq(field string) (int64, error) {
+	return -1, nil
+}
+
+// Writes a segments_N file referencing the single segment _0 of the
+// belfrysample index, with the given deletions generation.
+func writeTestSegmentsFile(t *testing.T, dir string, gen, version, delGen int64, delCount int) {
+	w := &testDVWriter{}
+	buf := w.writeHeader(nil, "segments", VERSION_40)
+	buf = w.writeLong(buf, version)
+	buf = w.writeInt(buf, 1) // counter
+	buf = w.writeInt(buf, 1) // numSegments
+	for _, s := range []string{"_0", "Lucene42"} {
+		buf = append(w.writeVInt32(buf, int32(len(s))), s...)
+	}
+	buf = w.writeLong(buf, delGen)
+	buf = w.writeInt(buf, uint32(delCount))
+	buf = w.writeInt(buf, 0) // userData
+	buf = w.writeLong(buf, int64(crc32.ChecksumIEEE(buf)))
+	name := util.FileNameFromGeneration(INDEX_FILENAME_SEGMENTS, "", gen)
+	if err := ioutil.WriteFile(filepath.Join(dir, name), buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenIfChanged(t *testing.T) {
+	dir := copyTestIndex(t, "../search/testdata/win8/belfrysample")
+	defer os.RemoveAll(dir)
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r2, err := OpenIfChanged(r); r2 != nil || err != nil {
+		t.Fatalf("Unchanged index should not be reopened: %v, %v", r2, err)
+	}
+	sub := r.Leaves()[0].Reader().(*SegmentReader)
+	maxDoc := sub.MaxDoc()
+
+	// new deletions: the segment core is shared
+	deleted := map[int]bool{2: true, 5: true}
+	writeTestBitVector(t, filepath.Join(dir, "_0_1.del"), maxDoc, deleted, false)
+	writeTestSegmentsFile(t, dir, 2, 4, 1, len(deleted))
+	r2, err := OpenIfChanged(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r2 == nil {
+		t.Fatal("Changed index should be reopened")
+	}
+	if r2.NumDocs() != maxDoc-len(deleted) || r.NumDocs() != maxDoc {
+		t.Errorf("Unexpected num docs %v (old %v)", r2.NumDocs(), r.NumDocs())
+	}
+	sub2 := r2.Leaves()[0].Reader().(*SegmentReader)
+	if sub2 == sub || sub2.core != sub.core {
+		t.Error("Reopened segment reader should share the core only")
+	}
+	if r3, err := OpenIfChanged(r2); r3 != nil || err != nil {
+		t.Fatalf("Unchanged index should not be reopened: %v, %v", r3, err)
+	}
+
+	// a new commit of the same segment: the segment reader is shared
+	writeTestSegmentsFile(t, dir, 3, 5, 1, len(deleted))
+	r3, err := OpenIfChanged(r2)
+	if err != nil || r3 == nil {
+		t.Fatalf("Changed index should be reopened: %v", err)
+	}
+	if sub3 := r3.Leaves()[0].Reader(); sub3 != sub2 {
+		t.Error("Unchanged segment reader should be shared")
+	}
+	r3.Close()
+	assertEquals(t, false, sub2.closed)
+	r2.Close()
+	assertClosed(t, "shared segment reader", sub2)
+	assertEquals(t, false, sub.closed)
+
+	// reopen from an older commit
+	commit, err := NewIndexCommitFromFiles(d, []string{"segments_1", "_0.cfe", "_0.cfs", "_0.si"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r4, err := OpenIfChangedFromCommit(r, commit); r4 != nil || err != nil {
+		t.Errorf("Reader on the same commit should not be reopened: %v, %v", r4, err)
+	}
+	if r3, err = OpenIfChanged(r); err != nil || r3 == nil {
+		t.Fatalf("Changed index should be reopened: %v", err)
+	}
+	defer r3.Close()
+	r4, err := OpenIfChangedFromCommit(r3, commit)
+	if err != nil || r4 == nil {
+		t.Fatalf("Reader should be reopened on an older commit: %v", err)
+	}
+	defer r4.Close()
+	if r4.NumDocs() != maxDoc {
+		t.Errorf("Expected %v docs, but was %v", maxDoc, r4.NumDocs())
+	}
+}
@@ -0,0 +1,97 @@
+package index
+
+// NumericDocValues.java
+
+// A per-document numeric value.
+type NumericDocValues interface {
+	// Returns the numeric value for the specified document ID.
+	Get(docID int) int64
+}
+
+// Adapts an ordinary function to NumericDocValues.
+type NumericDocValuesFunc func(docID int) int64
+
+func (f NumericDocValuesFunc) Get(docID int) int64 {
+	return f(docID)
+}
+
+// BinaryDocValues.java
+
+// A per-document []byte.
+type BinaryDocValues interface {
+	/*
+		Lookup the value for document. The returned slice may be shared
+		and must not be modified.
+	*/
+	Get(docID int) []byte
+}
+
+// Adapts an ordinary function to BinaryDocValues.
+type BinaryDocValuesFunc func(docID int) []byte
+
+func (f BinaryDocValuesFunc) Get(docID int) []byte {
+	return f(docID)
+}
+
+// SortedDocValues.java
+
+/*
+A per-document []byte, deduplicated and sorted. Instead of returning
+the value for each document, this returns an ord for the document,
+which can be used to look up the value with LookupOrd().
+*/
+type SortedDocValues interface {
+	BinaryDocValues
+	/*
+		Returns the ordinal for the specified docID, in the range
+		[0, ValueCount()-1], or -1 if the document has no value.
+	*/
+	Ord(docID int) int
+	// Retrieves the value for the specified ordinal.
+	LookupOrd(ord int) []byte
+	// Returns the number of unique values.
+	ValueCount() int
+}
+
+// SortedSetDocValues.java
+
+// When returned by NextOrd() it means there are no more ordinals for the document.
+const NO_MORE_ORDS = -1
+
+/*
+A per-document set of presorted []byte values. Per document, an
+ordered set of ordinals is returned, each of which can be used to look
+up the value with LookupOrd().
+*/
+type SortedSetDocValues interface {
+	/*
+		Returns the next ordinal for the current document (previously
+		set by SetDocument()), or NO_MORE_ORDS.
+	*/
+	NextOrd() int64
+	// Sets iteration to the specified docID.
+	SetDocument(docID int)
+	// Retrieves the value for the specified ordinal.
+	LookupOrd(ord int64) []byte
+	// Returns the number of unique values.
+	ValueCount() int64
+}
+
+// An empty SortedSetDocValues which returns NO_MORE_ORDS for every document.
+var EMPTY_SORTED_SET_DOC_VALUES = emptySortedSetDocValues{}
+
+type emptySortedSetDocValues struct{}
+
+func (v emptySortedSetDocValues) NextOrd() int64 {
+	return NO_MORE_ORDS
+}
+
+func (v emptySortedSetDocValues) SetDocument(docID int) {}
+
+func (v emptySortedSetDocValues) LookupOrd(ord int64) []byte {
+	panic("index out of bounds")
+}
+
+func (v emptySortedSetDocValues) ValueCount() int64 {
+	return 0
+}
@@ -0,0 +1,280 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+	"strconv"
+)
+
+// DocValuesUpdate.java
+
+/*
+An in-place update to a doc values field, which sets the value of
+the field in all documents matching the term.
+*/
+type docValuesUpdate struct {
+	term         Term
+	field        string
+	typ          DocValuesType
+	numericValue int64
+	binaryValue  []byte
+	// the update only applies to the documents below docIDUpto
+	docIDUpto int
+	// names of the segments the update still has to be applied to
+	segments map[string]bool
+}
+
+// Rough number of bytes used by a buffered update besides its term,
+// field and value.
+const BYTES_PER_DOC_VALUES_UPDATE = 96
+
+// Returns the approximate RAM used by the buffered updates.
+func bufferedUpdatesBytes(updates []*docValuesUpdate) int64 {
+	var ans int64
+	for _, u := range updates {
+		ans += int64(BYTES_PER_DOC_VALUES_UPDATE + len(u.term.Field) + len(u.term.Bytes) +
+			len(u.field) + len(u.binaryValue))
+	}
+	return ans
+}
+
+func (u *docValuesUpdate) String() string {
+	if u.typ == DOC_VALUES_TYPE_BINARY {
+		return fmt.Sprintf("term=%v:%v,field=%v,value=%v", u.term.Field, string(u.term.Bytes), u.field, u.binaryValue)
+	}
+	return fmt.Sprintf("term=%v:%v,field=%v,value=%v", u.term.Field, string(u.term.Bytes), u.field, u.numericValue)
+}
+
+// ReadersAndLiveDocs.java/writeFieldUpdates
+
+/*
+Applies the updates, in order, to the segment, and writes the new
+values of each updated field as a new generation of doc values,
+along with a new generation of the segment's FieldInfos. Returns the
+segment info referencing the new generation, or info itself if no
+document of the segment matches any update.
+*/
+func writeFieldUpdates(dir store.Directory, fieldNumbers *fieldNumbers, info SegmentInfoPerCommit,
+	updates []*docValuesUpdate) (ans SegmentInfoPerCommit, err error) {
+	reader, err := NewSegmentReader(info, DEFAULT_TERMS_INDEX_DIVISOR, store.IO_CONTEXT_READONCE)
+	if err != nil {
+		return info, err
+	}
+	defer reader.decRef()
+
+	gen := info.nextWriteFieldInfosGen
+	fieldInfos := reader.FieldInfos()
+	updated := make(map[string]*FieldInfo)
+	numericValues := make(map[string][]int64)
+	binaryValues := make(map[string][][]byte)
+	for _, update := range updates {
+		docs, err := matchingDocs(reader, update.term)
+		if err != nil {
+			return info, err
+		}
+		// the documents added after the update keep their values
+		docs = docs[:sort.SearchInts(docs, update.docIDUpto)]
+		if len(docs) == 0 {
+			continue
+		}
+		if _, ok := updated[update.field]; !ok {
+			fi, err := updatedFieldInfo(fieldInfos, fieldNumbers, update)
+			if err != nil {
+				return info, err
+			}
+			fi.dvGen = gen
+			updated[update.field] = &fi
+		}
+		switch update.typ {
+		case DOC_VALUES_TYPE_NUMERIC:
+			values, ok := numericValues[update.field]
+			if !ok {
+				if values, err = currentNumericValues(reader, update.field); err != nil {
+					return info, err
+				}
+				numericValues[update.field] = values
+			}
+			for _, doc := range docs {
+				values[doc] = update.numericValue
+			}
+		case DOC_VALUES_TYPE_BINARY:
+			values, ok := binaryValues[update.field]
+			if !ok {
+				if values, err = currentBinaryValues(reader, update.field); err != nil {
+					return info, err
+				}
+				binaryValues[update.field] = values
+			}
+			for _, doc := range docs {
+				values[doc] = update.binaryValue
+			}
+		default:
+			panic("assert fail")
+		}
+	}
+	if len(updated) == 0 {
+		return info, nil
+	}
+
+	suffix := strconv.FormatInt(gen, 36)
+	tracker := store.NewTrackingDirectoryWrapper(dir)
+	success := false
+	defer func() {
+		if !success {
+			for file, _ := range tracker.CreatedFiles() {
+				dir.DeleteFile(file)
+			}
+		}
+	}()
+
+	updatedInfos := make([]FieldInfo, 0, len(updated))
+	for _, fi := range updated {
+		updatedInfos = append(updatedInfos, *fi)
+	}
+	if err = writeUpdatedDocValues(tracker, info.info, NewFieldInfos(updatedInfos), suffix,
+		numericValues, binaryValues); err != nil {
+		return info, err
+	}
+	// the doc values format recorded its suffix in the attributes of
+	// the updated fields, which are shared with the copies below
+	infos := make([]FieldInfo, 0, len(fieldInfos.values)+len(updated))
+	for _, fi := range fieldInfos.values {
+		if _, ok := updated[fi.name]; !ok {
+			infos = append(infos, fi)
+		}
+	}
+	infos = append(infos, updatedInfos...)
+	if err = info.info.codec.WriteFieldInfos(tracker, info.info.name, suffix,
+		NewFieldInfos(infos), store.IO_CONTEXT_DEFAULT); err != nil {
+		return info, err
+	}
+
+	ans = info
+	ans.fieldInfosGen, ans.nextWriteFieldInfosGen = gen, gen+1
+	ans.genUpdatesFiles = make(map[int64]map[string]bool)
+	for g, files := range info.genUpdatesFiles {
+		ans.genUpdatesFiles[g] = files
+	}
+	ans.genUpdatesFiles[gen] = tracker.CreatedFiles()
+	success = true
+	return ans, nil
+}
+
+/*
+Returns the FieldInfo of the updated field, with its own copy of the
+attributes. The field is added to the segment if none of its
+documents had it, or gets doc values if it only was indexed or
+stored.
+*/
+func updatedFieldInfo(fieldInfos FieldInfos, fieldNumbers *fieldNumbers,
+	update *docValuesUpdate) (FieldInfo, error) {
+	fi, ok := fieldInfos.byName[update.field]
+	if !ok {
+		number := fieldNumbers.addOrGet(update.field, -1)
+		return NewFieldInfo(update.field, false, number, false, false, false, 0,
+			update.typ, 0, make(map[string]string)), nil
+	}
+	if fi.docValueType != 0 && fi.docValueType != update.typ {
+		return fi, errors.New(fmt.Sprintf(
+			"cannot change DocValues type from %v to %v for field \"%v\"",
+			document.DocValuesType(fi.docValueType), document.DocValuesType(update.typ), fi.name))
+	}
+	fi.docValueType = update.typ
+	attributes := fi.attributes
+	fi.attributes = make(map[string]string)
+	for k, v := range attributes {
+		fi.attributes[k] = v
+	}
+	return fi, nil
+}
+
+// Writes the values of the updated fields with the doc values format
+// of the codec, in files named after the generation suffix.
+func writeUpdatedDocValues(dir store.Directory, si SegmentInfo, fieldInfos FieldInfos, suffix string,
+	numericValues map[string][]int64, binaryValues map[string][][]byte) (err error) {
+	state := newSegmentWriteState(dir, &si, fieldInfos, store.IO_CONTEXT_DEFAULT)
+	state.segmentSuffix = suffix
+	consumer, err := si.codec.GetDocValuesConsumer(state)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = consumer.Close()
+		} else {
+			util.CloseWhileSuppressingError(consumer)
+		}
+	}()
+	for _, fi := range fieldInfos.values {
+		if values, ok := numericValues[fi.name]; ok {
+			err = consumer.AddNumericField(fi, values)
+		} else {
+			err = consumer.AddBinaryField(fi, binaryValues[fi.name])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the live documents of the segment containing the term.
+func matchingDocs(reader *SegmentReader, term Term) ([]int, error) {
+	fields := reader.Fields()
+	if fields == nil {
+		return nil, nil
+	}
+	terms := fields.Terms(term.Field)
+	if terms == nil {
+		return nil, nil
+	}
+	termsEnum := terms.Iterator(nil)
+	found, err := termsEnum.SeekExact(term.Bytes)
+	if err != nil || !found {
+		return nil, err
+	}
+	var docs []int
+	docsEnum := termsEnum.DocsByFlags(reader.LiveDocs(), DOCS_ENUM_EMPTY, 0)
+	for doc, more := docsEnum.NextDoc(); more; doc, more = docsEnum.NextDoc() {
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// Returns a copy of the numeric doc values of the field, 0 for
+// documents without a value.
+func currentNumericValues(reader *SegmentReader, field string) ([]int64, error) {
+	dv, err := reader.NumericDocValues(field)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]int64, reader.MaxDoc())
+	if dv != nil {
+		for doc := range values {
+			values[doc] = dv.Get(doc)
+		}
+	}
+	return values, nil
+}
+
+// Returns a copy of the binary doc values of the field, empty for
+// documents without a value.
+func currentBinaryValues(reader *SegmentReader, field string) ([][]byte, error) {
+	dv, err := reader.BinaryDocValues(field)
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]byte, reader.MaxDoc())
+	for doc := range values {
+		if dv != nil {
+			values[doc] = append([]byte(nil), dv.Get(doc)...)
+		} else {
+			values[doc] = []byte{}
+		}
+	}
+	return values, nil
+}
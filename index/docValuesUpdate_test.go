@@ -0,0 +1,212 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"os"
+	"testing"
+)
+
+// Returns the "count" and "tag" doc values of all live documents,
+// keyed by id.
+func testDocValuesByID(t *testing.T, r IndexReader) (counts map[string]int64, tags map[string]string) {
+	counts, tags = make(map[string]int64), make(map[string]string)
+	for _, ctx := range r.Leaves() {
+		reader := ctx.Reader().(AtomicReader)
+		count, err := reader.NumericDocValues("count")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag, err := reader.BinaryDocValues("tag")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for doc := 0; doc < reader.MaxDoc(); doc++ {
+			visitor := NewDocumentStoredFieldVisitor()
+			if err = reader.Document(doc, visitor); err != nil {
+				t.Fatal(err)
+			}
+			id := visitor.Document().Get("id")
+			counts[id], tags[id] = count.Get(doc), string(tag.Get(doc))
+		}
+	}
+	return counts, tags
+}
+
+func TestUpdateDocValues(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	newDoc := func(i int) []document.IndexableField {
+		return append(newTestDoc(i),
+			document.NewNumericDocValuesField("count", int64(i)),
+			document.NewBinaryDocValuesField("tag", []byte(fmt.Sprintf("t%v", i))))
+	}
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetMaxBufferedDocs(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 flushed segments, and buffered documents
+	const numDocs = 25
+	for i := 0; i < numDocs; i++ {
+		if err = w.AddDocument(newDoc(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.UpdateNumericDocValue(NewTerm("parity", "even"), "count", 100); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.UpdateBinaryDocValue(NewTerm("id", "0003"), "tag", []byte("updated")); err != nil {
+		t.Fatal(err)
+	}
+	// not affected by the previous updates
+	if err = w.AddDocument(newDoc(numDocs)); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.UpdateNumericDocValue(NewTerm("id", "0003"), "tag", 1); err == nil {
+		t.Error("Should not update binary doc values with a numeric value")
+	}
+	if err = w.UpdateNumericDocValue(NewTerm("id", "0003"), "missing", 1); err == nil {
+		t.Error("Should not update a missing field")
+	}
+	if err = w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCount := func(i int) int64 {
+		if i%2 == 0 && i < numDocs {
+			return 100
+		}
+		return int64(i)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	counts, tags := testDocValuesByID(t, r)
+	assertEquals(t, numDocs+1, len(counts))
+	for i := 0; i <= numDocs; i++ {
+		id := fmt.Sprintf("%04d", i)
+		assertEquals(t, expectedCount(i), counts[id])
+		if i == 3 {
+			assertEquals(t, "updated", tags[id])
+		} else {
+			assertEquals(t, fmt.Sprintf("t%v", i), tags[id])
+		}
+	}
+
+	// a second generation of the same field
+	if err = w.UpdateNumericDocValue(NewTerm("id", "0001"), "count", 7); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	r2, err := OpenIfChanged(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r2 == nil {
+		t.Fatal("Reader should see the updates")
+	}
+	defer r2.Close()
+	counts, _ = testDocValuesByID(t, r2)
+	assertEquals(t, int64(7), counts["0001"])
+	assertEquals(t, int64(100), counts["0002"])
+	// the old reader still sees the previous generation
+	counts, _ = testDocValuesByID(t, r)
+	assertEquals(t, int64(1), counts["0001"])
+
+	// the merged segment keeps the updated values
+	if err = w.ForceMerge(1); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r3, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r3.Close()
+	assertEquals(t, 1, len(r3.Leaves()))
+	counts, tags = testDocValuesByID(t, r3)
+	for i := 0; i <= numDocs; i++ {
+		id := fmt.Sprintf("%04d", i)
+		if i == 1 {
+			assertEquals(t, int64(7), counts[id])
+		} else {
+			assertEquals(t, expectedCount(i), counts[id])
+		}
+	}
+	assertEquals(t, "updated", tags["0003"])
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, false)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+}
+
+func TestUpdateDocValuesBuffered(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	newDoc := func(i int) []document.IndexableField {
+		return append(newTestDoc(i),
+			document.NewNumericDocValuesField("count", int64(i)),
+			document.NewBinaryDocValuesField("tag", []byte(fmt.Sprintf("t%v", i))))
+	}
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 1000)
+	// the updates apply to the documents added before them only
+	const numDocs = 20
+	for i := 0; i < numDocs; i++ {
+		if err := w.AddDocument(newDoc(i)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.UpdateNumericDocValue(NewTerm("parity", "even"), "count", int64(100+i)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.UpdateBinaryDocValue(NewTerm("id", fmt.Sprintf("%04d", i)), "tag", []byte("updated")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.AddDocument(newDoc(numDocs)); err != nil {
+		t.Fatal(err)
+	}
+	// nothing was flushed by the updates
+	assertEquals(t, 0, len(w.segmentInfos.Segments))
+	assertEquals(t, numDocs+1, w.docWriter.numDocs())
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, len(r.Leaves()))
+	counts, tags := testDocValuesByID(t, r)
+	for i := 0; i <= numDocs; i++ {
+		id := fmt.Sprintf("%04d", i)
+		switch {
+		case i == numDocs:
+			assertEquals(t, int64(i), counts[id])
+			assertEquals(t, fmt.Sprintf("t%v", i), tags[id])
+		case i%2 == 0:
+			assertEquals(t, int64(100+numDocs-1), counts[id])
+			assertEquals(t, "updated", tags[id])
+		default:
+			assertEquals(t, int64(i), counts[id])
+			assertEquals(t, "updated", tags[id])
+		}
+	}
+}
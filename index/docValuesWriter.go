@@ -0,0 +1,397 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+)
+
+// DocValuesProcessor.java
+
+/*
+Buffers the doc values of the added documents, with one writer per
+field, and writes them through the codec's DocValuesConsumer when the
+segment is flushed. The memory of the buffered values is tracked by
+bytesUsed.
+*/
+type DocValuesProcessor struct {
+	codec     Codec
+	writers   map[string]DocValuesWriter
+	bytesUsed util.Counter
+}
+
+func newDocValuesProcessor(codec Codec, bytesUsed util.Counter) *DocValuesProcessor {
+	return &DocValuesProcessor{
+		codec:     codec,
+		writers:   make(map[string]DocValuesWriter),
+		bytesUsed: bytesUsed,
+	}
+}
+
+/*
+Adds the doc values of a field of the given document. The field must
+have been validated, i.e. its value matches its doc values type, and
+it is the only value of the field in the document unless the type is
+SORTED_SET.
+*/
+func (p *DocValuesProcessor) addField(docID int, field document.IndexableField,
+	fieldInfo *FieldInfo) error {
+	writer, ok := p.writers[fieldInfo.name]
+	switch fieldInfo.docValueType {
+	case DOC_VALUES_TYPE_NUMERIC:
+		if !ok {
+			writer = newNumericDocValuesWriter(fieldInfo, p.bytesUsed)
+			p.writers[fieldInfo.name] = writer
+		}
+		writer.(*NumericDocValuesWriter).addValue(docID, field.NumericValue().(int64))
+	case DOC_VALUES_TYPE_BINARY:
+		if !ok {
+			writer = newBinaryDocValuesWriter(fieldInfo, p.bytesUsed)
+			p.writers[fieldInfo.name] = writer
+		}
+		writer.(*BinaryDocValuesWriter).addValue(docID, field.BinaryValue())
+	case DOC_VALUES_TYPE_SORTED:
+		if !ok {
+			writer = newSortedDocValuesWriter(fieldInfo, p.bytesUsed)
+			p.writers[fieldInfo.name] = writer
+		}
+		return writer.(*SortedDocValuesWriter).addValue(docID, field.BinaryValue())
+	case DOC_VALUES_TYPE_SORTED_SET:
+		if !ok {
+			writer = newSortedSetDocValuesWriter(fieldInfo, p.bytesUsed)
+			p.writers[fieldInfo.name] = writer
+		}
+		return writer.(*SortedSetDocValuesWriter).addValue(docID, field.BinaryValue())
+	default:
+		panic("assert fail")
+	}
+	return nil
+}
+
+// Writes the buffered doc values of all fields, if any.
+func (p *DocValuesProcessor) flush(state SegmentWriteState) (err error) {
+	if len(p.writers) == 0 {
+		return nil
+	}
+	consumer, err := p.codec.GetDocValuesConsumer(state)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = consumer.Close()
+		} else {
+			util.CloseWhileSuppressingError(consumer)
+		}
+	}()
+
+	names := make([]string, 0, len(p.writers))
+	for name, _ := range p.writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writer := p.writers[name]
+		writer.finish(int(state.segmentInfo.docCount))
+		if err = writer.flush(state, consumer); err != nil {
+			return err
+		}
+	}
+	p.writers = make(map[string]DocValuesWriter)
+	return nil
+}
+
+// Discards the buffered doc values.
+func (p *DocValuesProcessor) abort() {
+	for _, writer := range p.writers {
+		writer.abort()
+	}
+	p.writers = make(map[string]DocValuesWriter)
+}
+
+// DocValuesWriter.java
+
+type DocValuesWriter interface {
+	abort()
+	// Fills the values of the documents without a value up to maxDoc.
+	finish(maxDoc int)
+	flush(state SegmentWriteState, consumer DocValuesConsumer) error
+}
+
+// NumericDocValuesWriter.java
+
+// Buffers up pending int64 per doc, then flushes when segment
+// flushes.
+type NumericDocValuesWriter struct {
+	fieldInfo   *FieldInfo
+	pending     []int64
+	iwBytesUsed util.Counter
+}
+
+func newNumericDocValuesWriter(fieldInfo *FieldInfo, iwBytesUsed util.Counter) *NumericDocValuesWriter {
+	return &NumericDocValuesWriter{fieldInfo: fieldInfo, iwBytesUsed: iwBytesUsed}
+}
+
+func (w *NumericDocValuesWriter) addValue(docID int, value int64) {
+	if docID < len(w.pending) {
+		panic("assert fail") // only one value is allowed per field
+	}
+	numValues := len(w.pending)
+	// Fill in any holes:
+	for len(w.pending) < docID {
+		w.pending = append(w.pending, 0)
+	}
+	w.pending = append(w.pending, value)
+	w.iwBytesUsed.AddAndGet(int64(len(w.pending)-numValues) * util.NUM_BYTES_LONG)
+}
+
+func (w *NumericDocValuesWriter) finish(maxDoc int) {
+	for len(w.pending) < maxDoc {
+		w.pending = append(w.pending, 0)
+	}
+}
+
+func (w *NumericDocValuesWriter) flush(state SegmentWriteState, consumer DocValuesConsumer) error {
+	return consumer.AddNumericField(*w.fieldInfo, w.pending)
+}
+
+func (w *NumericDocValuesWriter) abort() {
+	w.pending = nil
+}
+
+// BinaryDocValuesWriter.java
+
+// Buffers up pending []byte per doc, then flushes when segment
+// flushes.
+type BinaryDocValuesWriter struct {
+	fieldInfo   *FieldInfo
+	pending     [][]byte
+	iwBytesUsed util.Counter
+}
+
+func newBinaryDocValuesWriter(fieldInfo *FieldInfo, iwBytesUsed util.Counter) *BinaryDocValuesWriter {
+	return &BinaryDocValuesWriter{fieldInfo: fieldInfo, iwBytesUsed: iwBytesUsed}
+}
+
+func (w *BinaryDocValuesWriter) addValue(docID int, value []byte) {
+	if docID < len(w.pending) {
+		panic("assert fail") // only one value is allowed per field
+	}
+	numValues := len(w.pending)
+	// Fill in any holes:
+	for len(w.pending) < docID {
+		w.pending = append(w.pending, nil)
+	}
+	w.pending = append(w.pending, append([]byte(nil), value...))
+	w.iwBytesUsed.AddAndGet(int64((len(w.pending)-numValues)*util.NUM_BYTES_SLICE_HEADER + len(value)))
+}
+
+func (w *BinaryDocValuesWriter) finish(maxDoc int) {
+	for len(w.pending) < maxDoc {
+		w.pending = append(w.pending, nil)
+	}
+}
+
+func (w *BinaryDocValuesWriter) flush(state SegmentWriteState, consumer DocValuesConsumer) error {
+	return consumer.AddBinaryField(*w.fieldInfo, w.pending)
+}
+
+func (w *BinaryDocValuesWriter) abort() {
+	w.pending = nil
+}
+
+// SortedDocValuesWriter.java
+
+/*
+Buffers up pending []byte per doc, deref and sorting via int ord,
+then flushes when segment flushes. Documents without a value get the
+empty value.
+*/
+type SortedDocValuesWriter struct {
+	fieldInfo   *FieldInfo
+	hash        *util.BytesRefHash
+	pending     []int64 // term id of each document
+	iwBytesUsed util.Counter
+}
+
+func newSortedDocValuesWriter(fieldInfo *FieldInfo, iwBytesUsed util.Counter) *SortedDocValuesWriter {
+	return &SortedDocValuesWriter{
+		fieldInfo:   fieldInfo,
+		hash:        newDocValuesBytesRefHash(iwBytesUsed),
+		iwBytesUsed: iwBytesUsed,
+	}
+}
+
+// Returns a hash for the values of a field, whose byte blocks are
+// tracked by bytesUsed.
+func newDocValuesBytesRefHash(bytesUsed util.Counter) *util.BytesRefHash {
+	pool := util.NewByteBlockPool(util.NewDirectTrackingByteAllocator(bytesUsed))
+	pool.NextBuffer()
+	return util.NewBytesRefHash(pool, util.BYTES_REF_HASH_DEFAULT_CAPACITY,
+		util.NewDirectBytesStartArray(util.BYTES_REF_HASH_DEFAULT_CAPACITY))
+}
+
+func (w *SortedDocValuesWriter) addValue(docID int, value []byte) error {
+	if docID < len(w.pending) {
+		panic("assert fail") // only one value is allowed per field
+	}
+	// Fill in any holes:
+	for len(w.pending) < docID {
+		if err := w.addOneValue(nil); err != nil {
+			return err
+		}
+	}
+	return w.addOneValue(value)
+}
+
+func (w *SortedDocValuesWriter) addOneValue(value []byte) error {
+	termID, err := w.hash.Add(value)
+	if err != nil {
+		return err
+	}
+	if termID < 0 {
+		termID = -termID - 1
+	}
+	w.pending = append(w.pending, int64(termID))
+	w.iwBytesUsed.AddAndGet(util.NUM_BYTES_LONG)
+	return nil
+}
+
+func (w *SortedDocValuesWriter) finish(maxDoc int) {
+	for len(w.pending) < maxDoc {
+		if err := w.addOneValue(nil); err != nil {
+			panic(err) // the empty value always fits
+		}
+	}
+}
+
+func (w *SortedDocValuesWriter) flush(state SegmentWriteState, consumer DocValuesConsumer) error {
+	values, ordMap := sortDocValuesHash(w.hash)
+	docToOrd := make([]int64, len(w.pending))
+	for docID, termID := range w.pending {
+		docToOrd[docID] = int64(ordMap[termID])
+	}
+	return consumer.AddSortedField(*w.fieldInfo, values, docToOrd)
+}
+
+/*
+Returns the values of the hash in sorted order, and the map from the
+id of each value to its ord.
+*/
+func sortDocValuesHash(hash *util.BytesRefHash) (values [][]byte, ordMap []int) {
+	valueCount := hash.Size()
+	sortedValues := hash.Sort()
+	values = make([][]byte, valueCount)
+	ordMap = make([]int, valueCount)
+	for ord, id := range sortedValues[:valueCount] {
+		values[ord] = hash.Get(int(id))
+		ordMap[id] = ord
+	}
+	return values, ordMap
+}
+
+func (w *SortedDocValuesWriter) abort() {
+	w.hash.Clear(true)
+	w.pending = nil
+}
+
+// SortedSetDocValuesWriter.java
+
+/*
+Buffers up pending []byte's per doc, deref and sorting via int ord,
+then flushes when segment flushes.
+*/
+type SortedSetDocValuesWriter struct {
+	fieldInfo     *FieldInfo
+	hash          *util.BytesRefHash
+	pending       []int // stream of all termIDs
+	pendingCounts []int64
+	iwBytesUsed   util.Counter
+
+	currentDoc    int
+	currentValues []int
+}
+
+func newSortedSetDocValuesWriter(fieldInfo *FieldInfo, iwBytesUsed util.Counter) *SortedSetDocValuesWriter {
+	return &SortedSetDocValuesWriter{
+		fieldInfo:   fieldInfo,
+		hash:        newDocValuesBytesRefHash(iwBytesUsed),
+		iwBytesUsed: iwBytesUsed,
+	}
+}
+
+func (w *SortedSetDocValuesWriter) addValue(docID int, value []byte) error {
+	if docID != w.currentDoc {
+		w.finishCurrentDoc()
+	}
+
+	// Fill in any holes:
+	for w.currentDoc < docID {
+		w.pendingCounts = append(w.pendingCounts, 0) // no values
+		w.iwBytesUsed.AddAndGet(util.NUM_BYTES_LONG)
+		w.currentDoc++
+	}
+
+	termID, err := w.hash.Add(value)
+	if err != nil {
+		return err
+	}
+	if termID < 0 {
+		termID = -termID - 1
+	}
+	w.currentValues = append(w.currentValues, termID)
+	return nil
+}
+
+// finalize currentDoc: this deduplicates the current term ids
+func (w *SortedSetDocValuesWriter) finishCurrentDoc() {
+	sort.Ints(w.currentValues)
+	lastValue, count := -1, 0
+	for _, termID := range w.currentValues {
+		// if its not a duplicate
+		if termID != lastValue {
+			w.pending = append(w.pending, termID)
+			count++
+		}
+		lastValue = termID
+	}
+	// record the number of unique term ids for this doc
+	w.pendingCounts = append(w.pendingCounts, int64(count))
+	w.iwBytesUsed.AddAndGet(int64(count+1) * util.NUM_BYTES_LONG)
+	w.currentValues = w.currentValues[:0]
+	w.currentDoc++
+}
+
+func (w *SortedSetDocValuesWriter) finish(maxDoc int) {
+	w.finishCurrentDoc()
+
+	// fill in any holes
+	for w.currentDoc < maxDoc {
+		w.pendingCounts = append(w.pendingCounts, 0) // no values
+		w.currentDoc++
+	}
+}
+
+func (w *SortedSetDocValuesWriter) flush(state SegmentWriteState, consumer DocValuesConsumer) error {
+	values, ordMap := sortDocValuesHash(w.hash)
+	ords := make([]int64, 0, len(w.pending))
+	pending := w.pending
+	for _, count := range w.pendingCounts {
+		docOrds := make([]int, count)
+		for i, termID := range pending[:count] {
+			docOrds[i] = ordMap[termID]
+		}
+		pending = pending[count:]
+		sort.Ints(docOrds)
+		for _, ord := range docOrds {
+			ords = append(ords, int64(ord))
+		}
+	}
+	return consumer.AddSortedSetField(*w.fieldInfo, values, w.pendingCounts, ords)
+}
+
+func (w *SortedSetDocValuesWriter) abort() {
+	w.hash.Clear(true)
+	w.pending = nil
+	w.pendingCounts = nil
+}
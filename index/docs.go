@@ -1,9 +1,31 @@
 package index
 
+import (
+	"math"
+)
+
+// When returned by NextDoc() or Advance(), it means there are no more
+// docs in the iterator.
+const NO_MORE_DOCS = math.MaxInt32
+
 type DocIdSetIterator interface {
 	DocId() int
 	Freq() int
 	NextDoc() (doc int, more bool)
+	/*
+		Advances to the first beyond the current whose document number is
+		greater than or equal to target, and returns the document number
+		itself. Returns NO_MORE_DOCS and false if target is greater than
+		the highest document number in the set.
+	*/
+	Advance(target int) (doc int, more bool)
+	/*
+		Returns the estimated cost of this iterator, usually an upper
+		bound of the number of documents it matches. This is generally
+		used to order the iterators of conjunctions, so the cheapest
+		leads the matching.
+	*/
+	Cost() int64
 }
 
 const (
@@ -23,5 +45,36 @@ const (
 	DOCS_POSITIONS_ENUM_FLAG_PAYLOADS = 2
 )
 
+/*
+Also iterates through positions.
+*/
+type DocsAndPositionsIterator interface {
+	DocIdSetIterator
+	/*
+		Returns the next position. You should only call this up to Freq()
+		times else the behavior is not defined. If positions were not
+		indexed this will return -1; this only happens if offsets were
+		indexed and you passed needsOffset=true when pulling the enum.
+	*/
+	NextPosition() int
+	/*
+		Returns start offset for the current position, or -1 if offsets
+		were not indexed.
+	*/
+	StartOffset() int
+	/*
+		Returns end offset for the current position, or -1 if offsets
+		were not indexed.
+	*/
+	EndOffset() int
+	/*
+		Returns the payload at this position, or nil if no payload was
+		indexed. You should not modify anything (neither members of the
+		returned slice nor its bytes).
+	*/
+	Payload() []byte
+}
+
 type DocsAndPositionsEnum struct {
+	DocsAndPositionsIterator
 }
@@ -0,0 +1,84 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/document"
+)
+
+// document/DocumentStoredFieldVisitor.java
+
+/*
+A StoredFieldVisitor that creates a Document containing all stored
+fields, or only specific requested fields provided to
+NewDocumentStoredFieldVisitorOf().
+
+This is used by IndexSearcher.Doc() to load a document.
+*/
+type DocumentStoredFieldVisitor struct {
+	doc         *document.Document
+	fieldsToAdd map[string]bool
+}
+
+// Load all stored fields.
+func NewDocumentStoredFieldVisitor() *DocumentStoredFieldVisitor {
+	return &DocumentStoredFieldVisitor{doc: document.NewDocument()}
+}
+
+// Load only fields named in the provided list.
+func NewDocumentStoredFieldVisitorOf(fieldsToAdd ...string) *DocumentStoredFieldVisitor {
+	ans := &DocumentStoredFieldVisitor{
+		doc:         document.NewDocument(),
+		fieldsToAdd: make(map[string]bool),
+	}
+	for _, name := range fieldsToAdd {
+		ans.fieldsToAdd[name] = true
+	}
+	return ans
+}
+
+func (v *DocumentStoredFieldVisitor) binaryField(fi FieldInfo, value []byte) error {
+	v.doc.Add(document.NewStoredFieldFromBytes(fi.name, value))
+	return nil
+}
+
+func (v *DocumentStoredFieldVisitor) stringField(fi FieldInfo, value string) error {
+	v.doc.Add(document.NewStoredFieldFromString(fi.name, value))
+	return nil
+}
+
+func (v *DocumentStoredFieldVisitor) intField(fi FieldInfo, value int) error {
+	v.doc.Add(document.NewStoredFieldFromInt(fi.name, value))
+	return nil
+}
+
+func (v *DocumentStoredFieldVisitor) longField(fi FieldInfo, value int64) error {
+	v.doc.Add(document.NewStoredFieldFromLong(fi.name, value))
+	return nil
+}
+
+func (v *DocumentStoredFieldVisitor) floatField(fi FieldInfo, value float32) error {
+	v.doc.Add(document.NewStoredFieldFromFloat(fi.name, value))
+	return nil
+}
+
+func (v *DocumentStoredFieldVisitor) doubleField(fi FieldInfo, value float64) error {
+	v.doc.Add(document.NewStoredFieldFromDouble(fi.name, value))
+	return nil
+}
+
+func (v *DocumentStoredFieldVisitor) needsField(fi FieldInfo) StoredFieldVisitorStatus {
+	if v.fieldsToAdd == nil || v.fieldsToAdd[fi.name] {
+		return SOTRED_FIELD_VISITOR_STATUS_YES
+	}
+	return SOTRED_FIELD_VISITOR_STATUS_NO
+}
+
+/*
+Retrieve the visited document.
+
+Returns Document populated with stored fields. Note that only the
+stored information in the field instances is valid, data such as
+indexing options, term vector options, etc is not set.
+*/
+func (v *DocumentStoredFieldVisitor) Document() *document.Document {
+	return v.doc
+}
@@ -0,0 +1,81 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/store"
+	"testing"
+)
+
+func TestDocumentStoredFieldVisitor(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sis := SegmentInfos{}
+	if err = sis.ReadAll(d); err != nil {
+		t.Fatal(err)
+	}
+	// two sub readers on the same segment
+	subs := make([]AtomicReader, 2)
+	for i := range subs {
+		if subs[i], err = NewSegmentReader(sis.Segments[0], DEFAULT_TERMS_INDEX_DIVISOR, store.IO_CONTEXT_READ); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := newStandardDirectoryReader(d, subs, sis, DEFAULT_TERMS_INDEX_DIVISOR, false)
+	defer r.Close()
+	maxDoc := subs[0].MaxDoc()
+	if base := r.readerBase(1); base != maxDoc {
+		t.Errorf("Expected base %v of second sub reader, but was %v", maxDoc, base)
+	}
+
+	// docs of the second sub reader are routed with its doc base
+	for _, docID := range []int{2, maxDoc + 2} {
+		visitor := NewDocumentStoredFieldVisitor()
+		if err = r.Document(docID, visitor); err != nil {
+			t.Fatal(err)
+		}
+		doc := visitor.Document()
+		if n := len(doc.Fields()); n != 5 {
+			t.Errorf("Expected 5 stored fields, but was %v: %v", n, doc)
+		}
+		assertEquals(t, "belfrysample/batfeeding.dita", doc.Get("key"))
+		assertEquals(t, "Feeding your bat", doc.Get("title"))
+		assertEquals(t, "belfrysample", doc.Get("scope"))
+	}
+
+	// only requested fields are loaded
+	visitor := NewDocumentStoredFieldVisitorOf("title", "missing")
+	if err = r.Document(maxDoc+7, visitor); err != nil {
+		t.Fatal(err)
+	}
+	doc := visitor.Document()
+	if n := len(doc.Fields()); n != 1 {
+		t.Errorf("Expected 1 stored field, but was %v: %v", n, doc)
+	}
+	assertEquals(t, "Bats", doc.Get("title"))
+	assertEquals(t, "", doc.Get("key"))
+}
+
+func TestDocumentStoredFieldVisitorTypes(t *testing.T) {
+	visitor := NewDocumentStoredFieldVisitor()
+	fi := func(name string) FieldInfo {
+		return NewFieldInfo(name, false, 0, false, false, false, 0, 0, 0, nil)
+	}
+	visitor.binaryField(fi("bin"), []byte{1, 2})
+	visitor.stringField(fi("str"), "text")
+	visitor.intField(fi("int"), 42)
+	visitor.longField(fi("long"), int64(1)<<40)
+	visitor.floatField(fi("float"), 0.5)
+	visitor.doubleField(fi("double"), 0.25)
+	doc := visitor.Document()
+
+	if v := doc.BinaryValue("bin"); len(v) != 2 || v[1] != 2 {
+		t.Errorf("Unexpected binary value %v", v)
+	}
+	assertEquals(t, "text", doc.Get("str"))
+	assertEquals(t, 42, doc.Field("int").NumericValue())
+	assertEquals(t, int64(1)<<40, doc.Field("long").NumericValue())
+	assertEquals(t, float32(0.5), doc.Field("float").NumericValue())
+	assertEquals(t, 0.25, doc.Field("double").NumericValue())
+	assertEquals(t, "42", doc.Get("int"))
+}
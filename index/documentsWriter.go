@@ -0,0 +1,327 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/store"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// FieldInfos.java/FieldNumbers
+
+/*
+Keeps the field name to number mapping consistent across all
+segments written by one IndexWriter, so a field always gets the same
+number, which is what makes bulk merging possible. It is shared by
+all DocumentsWriterPerThreads, hence synchronized.
+*/
+type fieldNumbers struct {
+	sync.Locker
+
+	numberToName  map[int32]string
+	nameToNumber  map[string]int32
+	docValuesType map[string]DocValuesType
+
+	lowestUnassignedFieldNumber int32
+}
+
+func newFieldNumbers() *fieldNumbers {
+	return &fieldNumbers{
+		Locker:                      &sync.Mutex{},
+		numberToName:                make(map[int32]string),
+		nameToNumber:                make(map[string]int32),
+		docValuesType:               make(map[string]DocValuesType),
+		lowestUnassignedFieldNumber: -1,
+	}
+}
+
+/*
+Returns the global field number for the given field name. If the name
+does not exist yet it tries to add it with the given preferred field
+number assigned if possible otherwise the first unassigned field
+number is used as the field number.
+*/
+func (fn *fieldNumbers) addOrGet(fieldName string, preferredFieldNumber int32) int32 {
+	fn.Lock()
+	defer fn.Unlock()
+	if number, ok := fn.nameToNumber[fieldName]; ok {
+		return number
+	}
+	// first time we see this field in this index
+	var fieldNumber int32
+	if _, ok := fn.numberToName[preferredFieldNumber]; preferredFieldNumber != -1 && !ok {
+		// cool - we can use this number globally
+		fieldNumber = preferredFieldNumber
+	} else {
+		// find a new FieldNumber
+		for {
+			fn.lowestUnassignedFieldNumber++
+			if _, ok := fn.numberToName[fn.lowestUnassignedFieldNumber]; !ok {
+				break
+			}
+		}
+		fieldNumber = fn.lowestUnassignedFieldNumber
+	}
+	fn.numberToName[fieldNumber] = fieldName
+	fn.nameToNumber[fieldName] = fieldNumber
+	return fieldNumber
+}
+
+/*
+Records the doc values type of the given field, or returns an error
+if the field already has doc values of a different type in this index.
+*/
+func (fn *fieldNumbers) setDocValuesType(fieldName string, dvType DocValuesType) error {
+	fn.Lock()
+	defer fn.Unlock()
+	if current, ok := fn.docValuesType[fieldName]; ok && current != dvType {
+		return errors.New(fmt.Sprintf(
+			"cannot change DocValues type from %v to %v for field \"%v\"",
+			document.DocValuesType(current), document.DocValuesType(dvType), fieldName))
+	}
+	fn.docValuesType[fieldName] = dvType
+	return nil
+}
+
+// Returns true if the field exists in this index with doc values of
+// the given type.
+func (fn *fieldNumbers) contains(fieldName string, dvType DocValuesType) bool {
+	fn.Lock()
+	defer fn.Unlock()
+	if _, ok := fn.nameToNumber[fieldName]; !ok {
+		return false
+	}
+	return fn.docValuesType[fieldName] == dvType
+}
+
+// DocumentsWriter.java
+
+/*
+This class accepts multiple added documents and directly writes
+segment files.
+
+Each goroutine adding documents obtains a ThreadState from the pool,
+and adds the document to the DocumentsWriterPerThread of that state,
+which buffers its own segment. Goroutines don't share any state while
+a document is inverted, so concurrent AddDocument calls scale across
+cores.
+
+After each document, the DocumentsWriterFlushControl consults the
+FlushPolicy, which marks the segments to flush once maxBufferedDocs
+documents are buffered by a DocumentsWriterPerThread, or the buffered
+documents of all of them use ramBufferSizeMB of RAM. A marked segment
+is flushed by the goroutine that added the last document, or by the
+next goroutine adding a document if the ThreadState is free, and the
+new segment is published to the IndexWriter. On commit all
+ThreadStates are flushed.
+*/
+type DocumentsWriter struct {
+	indexWriter   *IndexWriter
+	directory     store.Directory
+	codec         Codec
+	perThreadPool *DocumentsWriterPerThreadPool
+	flushControl  *DocumentsWriterFlushControl
+
+	// total number of buffered documents of all
+	// DocumentsWriterPerThreads, accessed atomically
+	numDocsInRAM int32
+
+	// set while all ThreadStates are held; read while holding one
+	closed bool
+}
+
+func newDocumentsWriter(indexWriter *IndexWriter, directory store.Directory, codec Codec) *DocumentsWriter {
+	perThreadPool := newDocumentsWriterPerThreadPool(indexWriter.config.maxThreadStates)
+	return &DocumentsWriter{
+		indexWriter:   indexWriter,
+		directory:     directory,
+		codec:         codec,
+		perThreadPool: perThreadPool,
+		flushControl:  newDocumentsWriterFlushControl(indexWriter.config, perThreadPool),
+	}
+}
+
+// Returns the number of documents buffered by all
+// DocumentsWriterPerThreads.
+func (dw *DocumentsWriter) numDocs() int {
+	return int(atomic.LoadInt32(&dw.numDocsInRAM))
+}
+
+/*
+Adds a document to the segment of a free ThreadState, and flushes the
+segments the FlushPolicy marked as pending, in which case flushed is
+true.
+*/
+func (dw *DocumentsWriter) addDocument(doc []document.IndexableField) (flushed bool, err error) {
+	state := dw.perThreadPool.obtain()
+	flushed, err = dw.addDocumentToThreadState(state, doc)
+	dw.perThreadPool.release(state)
+	if err != nil {
+		return flushed, err
+	}
+	// help flushing the pending segments of free ThreadStates
+	for {
+		state = dw.perThreadPool.obtainFlushPending(dw.flushControl)
+		if state == nil {
+			return flushed, nil
+		}
+		err = dw.flushThreadState(state)
+		dw.perThreadPool.release(state)
+		if err != nil {
+			return true, err
+		}
+		flushed = true
+	}
+}
+
+func (dw *DocumentsWriter) addDocumentToThreadState(state *ThreadState,
+	doc []document.IndexableField) (flushed bool, err error) {
+	if dw.closed {
+		return false, errors.New("this IndexWriter is closed")
+	}
+	if dw.flushControl.isFlushPending(state) {
+		// marked while free; flush it before buffering more documents
+		if err = dw.flushThreadState(state); err != nil {
+			return true, err
+		}
+		flushed = true
+	}
+
+	if state.dwpt == nil {
+		segment := dw.indexWriter.newSegmentName()
+		if state.dwpt, err = newDocumentsWriterPerThread(segment, dw.directory,
+			dw.codec, dw.indexWriter.config.similarity, dw.indexWriter.fieldNumbers); err != nil {
+			return false, err
+		}
+	}
+	dwpt := state.dwpt
+	if err = dwpt.addDocument(doc, dw.indexWriter.config.analyzer); err != nil {
+		if dwpt.aborting {
+			dw.abortThreadState(state)
+		}
+		return flushed, err
+	}
+	atomic.AddInt32(&dw.numDocsInRAM, 1)
+
+	if dw.flushControl.doAfterDocument(state) {
+		return true, dw.flushThreadState(state)
+	}
+	return flushed, nil
+}
+
+/*
+Flushes the segment of the given ThreadState, which must be held by
+the calling goroutine, and publishes it to the IndexWriter.
+*/
+func (dw *DocumentsWriter) flushThreadState(state *ThreadState) error {
+	dwpt := state.dwpt
+	if dwpt == nil {
+		return nil
+	}
+	state.dwpt = nil
+	bytes := dw.flushControl.doOnFlush(state)
+	defer dw.flushControl.doAfterFlush(bytes)
+	numDocs, pending := dwpt.numDocsInRAM, dwpt.pendingUpdates
+	info, err := dwpt.flush()
+	if err != nil || info == nil {
+		dw.subtractFlushedNumDocs(numDocs)
+		return err
+	}
+	return dw.indexWriter.publishFlushedSegment(info, pending, numDocs)
+}
+
+// Called by the IndexWriter once flushed documents are visible as a
+// segment.
+func (dw *DocumentsWriter) subtractFlushedNumDocs(numFlushed int) {
+	atomic.AddInt32(&dw.numDocsInRAM, -int32(numFlushed))
+}
+
+/*
+Buffers the doc values update in the segments of all ThreadStates,
+for the documents they hold so far, and registers it with the
+IndexWriter for the flushed segments. The segments are not flushed:
+the update applies to each of them once flushed. Returns true if all
+buffered updates must be applied.
+*/
+func (dw *DocumentsWriter) updateDocValue(update *docValuesUpdate) (applyAllDeletes bool, err error) {
+	states := dw.perThreadPool.obtainAll()
+	defer dw.perThreadPool.releaseAll()
+	if dw.closed {
+		return false, errors.New("this IndexWriter is closed")
+	}
+	for _, state := range states {
+		if dwpt := state.dwpt; dwpt != nil && dwpt.numDocsInRAM > 0 {
+			dwpt.pendingUpdates.addUpdate(update, dwpt.numDocsInRAM)
+		}
+	}
+	return dw.indexWriter.bufferUpdate(update), nil
+}
+
+/*
+Buffers deletes of the terms in the segments of all ThreadStates, for
+the documents they hold so far, and registers them with the
+IndexWriter for the flushed segments, like doc values updates.
+Returns true if all buffered deletes must be applied.
+*/
+func (dw *DocumentsWriter) deleteTerms(terms []Term) (applyAllDeletes bool, err error) {
+	states := dw.perThreadPool.obtainAll()
+	defer dw.perThreadPool.releaseAll()
+	if dw.closed {
+		return false, errors.New("this IndexWriter is closed")
+	}
+	for _, state := range states {
+		if dwpt := state.dwpt; dwpt != nil && dwpt.numDocsInRAM > 0 {
+			for _, term := range terms {
+				dwpt.pendingUpdates.addDelete(term, dwpt.numDocsInRAM)
+			}
+		}
+	}
+	return dw.indexWriter.bufferDeletes(terms), nil
+}
+
+// Discards the segment of the given ThreadState.
+func (dw *DocumentsWriter) abortThreadState(state *ThreadState) {
+	if dwpt := state.dwpt; dwpt != nil {
+		log.Printf("DW: abort segment %v", dwpt.segmentInfo.name)
+		dw.subtractFlushedNumDocs(dwpt.numDocsInRAM)
+		dw.flushControl.doAfterFlush(dw.flushControl.doOnFlush(state))
+		dwpt.abort()
+		state.dwpt = nil
+	}
+}
+
+/*
+Flushes the segments of all ThreadStates, blocking concurrent
+AddDocument calls until done. If closing is true, no documents are
+accepted afterwards.
+*/
+func (dw *DocumentsWriter) flushAllThreads(closing bool) (err error) {
+	states := dw.perThreadPool.obtainAll()
+	defer dw.perThreadPool.releaseAll()
+	if dw.closed {
+		return errors.New("this IndexWriter is closed")
+	}
+	for _, state := range states {
+		if e := dw.flushThreadState(state); e != nil && err == nil {
+			err = e
+		}
+	}
+	if closing {
+		dw.closed = true
+	}
+	return err
+}
+
+// Discards the segments of all ThreadStates, and stops accepting
+// documents.
+func (dw *DocumentsWriter) abort() {
+	states := dw.perThreadPool.obtainAll()
+	defer dw.perThreadPool.releaseAll()
+	for _, state := range states {
+		dw.abortThreadState(state)
+	}
+	dw.closed = true
+}
@@ -0,0 +1,172 @@
+package index
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DocumentsWriterFlushControl.java
+
+/*
+This class controls DocumentsWriterPerThread flushing during
+indexing. It tracks the memory consumption per ThreadState and uses
+the configured FlushPolicy to decide if a DocumentsWriterPerThread
+must flush.
+
+In addition to the FlushPolicy the flush control might set certain
+DocumentsWriterPerThread as flush pending. Unlike Lucene, which
+stalls indexing threads when flushing falls behind, a pending segment
+is flushed right away by the goroutine which added the last document,
+or the next goroutine obtaining its ThreadState, so flushing never
+falls behind indexing.
+
+The bytes used by the segment of a ThreadState are counted as active
+bytes while the segment is indexed, and as flush bytes once it is
+pending or being flushed. The bytes used by the buffered doc values
+updates of the IndexWriter are tracked as delete bytes, while those
+buffered for the documents of a segment count towards the segment.
+*/
+type DocumentsWriterFlushControl struct {
+	sync.Locker
+
+	config        *IndexWriterConfig
+	flushPolicy   FlushPolicy
+	perThreadPool *DocumentsWriterPerThreadPool
+
+	activeBytes int64
+	flushBytes  int64
+	numPending  int
+
+	numDeletes      int
+	deleteBytes     int64
+	applyAllDeletes bool
+}
+
+func newDocumentsWriterFlushControl(config *IndexWriterConfig,
+	perThreadPool *DocumentsWriterPerThreadPool) *DocumentsWriterFlushControl {
+	return &DocumentsWriterFlushControl{
+		Locker:        &sync.Mutex{},
+		config:        config,
+		flushPolicy:   config.flushPolicy,
+		perThreadPool: perThreadPool,
+	}
+}
+
+/*
+Updates the bytes used by the segment of the given ThreadState after
+a document was added, and consults the FlushPolicy. Returns true if
+the segment of the ThreadState must be flushed.
+*/
+func (c *DocumentsWriterFlushControl) doAfterDocument(state *ThreadState) bool {
+	c.Lock()
+	defer c.Unlock()
+	delta := state.dwpt.bytesUsed.Get() - state.bytesUsed
+	state.bytesUsed += delta
+	if state.flushPending {
+		c.flushBytes += delta
+	} else {
+		c.activeBytes += delta
+		c.flushPolicy.onInsert(c, state)
+	}
+	return state.flushPending
+}
+
+/*
+Sets flush pending state on the given ThreadState. The segment of the
+ThreadState is flushed by the goroutine holding it once the current
+document is added, or by the next goroutine which obtains it.
+*/
+func (c *DocumentsWriterFlushControl) setFlushPending(state *ThreadState) {
+	if !state.flushPending && state.bytesUsed > 0 {
+		state.flushPending = true
+		c.flushBytes += state.bytesUsed
+		c.activeBytes -= state.bytesUsed
+		c.numPending++
+	}
+}
+
+/*
+Returns the ThreadState whose segment uses the most bytes among those
+not pending yet. The given ThreadState, which is held by the calling
+goroutine, is returned if none uses more bytes.
+*/
+func (c *DocumentsWriterFlushControl) findLargestNonPendingWriter(state *ThreadState) *ThreadState {
+	maxRamSoFar, maxRamUsingState := state.bytesUsed, state
+	for _, next := range c.perThreadPool.threadStates {
+		if !next.flushPending && next.bytesUsed > maxRamSoFar {
+			maxRamSoFar, maxRamUsingState = next.bytesUsed, next
+		}
+	}
+	return maxRamUsingState
+}
+
+// Returns true if the segment of the given ThreadState is pending.
+func (c *DocumentsWriterFlushControl) isFlushPending(state *ThreadState) bool {
+	c.Lock()
+	defer c.Unlock()
+	return state.flushPending
+}
+
+/*
+Takes the segment of the given ThreadState, which must be held by the
+calling goroutine, out of the accounting of the indexed segments
+before it is flushed or aborted. Returns the bytes it uses, which
+must be passed to doAfterFlush() once it is done.
+*/
+func (c *DocumentsWriterFlushControl) doOnFlush(state *ThreadState) int64 {
+	c.Lock()
+	defer c.Unlock()
+	bytes := state.bytesUsed
+	if state.flushPending {
+		c.numPending--
+	} else {
+		c.activeBytes -= bytes
+		c.flushBytes += bytes
+	}
+	state.bytesUsed, state.flushPending = 0, false
+	return bytes
+}
+
+// Releases the bytes of a flushed or aborted segment.
+func (c *DocumentsWriterFlushControl) doAfterFlush(bytes int64) {
+	c.Lock()
+	defer c.Unlock()
+	c.flushBytes -= bytes
+}
+
+/*
+Records the number and bytes of the buffered deletes, and consults
+the FlushPolicy. Returns true if all buffered deletes must be applied.
+*/
+func (c *DocumentsWriterFlushControl) doOnDelete(numDeletes int, deleteBytes int64) bool {
+	c.Lock()
+	defer c.Unlock()
+	c.numDeletes, c.deleteBytes = numDeletes, deleteBytes
+	c.flushPolicy.onDelete(c)
+	ans := c.applyAllDeletes
+	c.applyAllDeletes = false
+	return ans
+}
+
+// Records the number and bytes of the buffered deletes, after some
+// were applied.
+func (c *DocumentsWriterFlushControl) setBufferedDeletes(numDeletes int, deleteBytes int64) {
+	c.Lock()
+	defer c.Unlock()
+	c.numDeletes, c.deleteBytes = numDeletes, deleteBytes
+}
+
+// Returns the bytes used by the segments being indexed and flushed,
+// and by the buffered deletes.
+func (c *DocumentsWriterFlushControl) netBytes() int64 {
+	c.Lock()
+	defer c.Unlock()
+	return c.activeBytes + c.flushBytes + c.deleteBytes
+}
+
+func (c *DocumentsWriterFlushControl) String() string {
+	c.Lock()
+	defer c.Unlock()
+	return fmt.Sprintf("DocumentsWriterFlushControl [activeBytes=%v, flushBytes=%v, numPending=%v, deleteBytes=%v]",
+		c.activeBytes, c.flushBytes, c.numPending, c.deleteBytes)
+}
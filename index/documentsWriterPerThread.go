@@ -0,0 +1,534 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+)
+
+// DocumentsWriterPerThread.java
+
+/*
+Buffers the documents added by one goroutine at a time as a new
+segment, and writes the segment files when flushed.
+
+Each added document is passed to the indexing chain, which in turn
+processes the document: stored fields are written to the stored
+fields writer right away, while the terms of indexed fields are
+inverted into the TermsHash, and the term vectors of the document are
+written once all its fields are inverted. The norm of each inverted
+field is computed by the Similarity, and buffered along with the doc
+values of the document. When the segment is flushed, the buffered
+postings, norms and doc values are written through the codec.
+
+A DocumentsWriterPerThread is owned by a single ThreadState, and
+never accessed concurrently; only the global field numbers are
+shared with the other instances. The memory buffered by the indexing
+chain is tracked by bytesUsed, which the flush policy consults after
+each document.
+*/
+type DocumentsWriterPerThread struct {
+	directory    store.Directory
+	codec        Codec
+	fieldNumbers *fieldNumbers
+
+	directoryTracker   *store.TrackingDirectoryWrapper
+	segmentInfo        *SegmentInfo
+	fieldInfos         map[string]*FieldInfo
+	freqProxWriter     *FreqProxTermsWriter
+	termVectors        *TermVectorsConsumer
+	norms              *NormsConsumer
+	docValues          *DocValuesProcessor
+	storedFieldsWriter StoredFieldsWriter
+	numDocsInRAM       int
+	fieldState         *FieldInvertState
+	bytesUsed          util.Counter
+	// the deletes and doc values updates of the buffered documents
+	pendingUpdates *bufferedUpdates
+
+	// true if an error was hit while adding a document, after which
+	// the segment must be aborted
+	aborting bool
+}
+
+func newDocumentsWriterPerThread(segment string, directory store.Directory,
+	codec Codec, similarity Similarity, fieldNumbers *fieldNumbers) (dwpt *DocumentsWriterPerThread, err error) {
+	tracker := store.NewTrackingDirectoryWrapper(directory)
+	bytesUsed := util.NewCounter(false)
+	dwpt = &DocumentsWriterPerThread{
+		directory:        directory,
+		codec:            codec,
+		fieldNumbers:     fieldNumbers,
+		directoryTracker: tracker,
+		segmentInfo: &SegmentInfo{
+			dir:         directory,
+			version:     util.LUCENE_MAIN_VERSION,
+			name:        segment,
+			docCount:    -1,
+			codec:       codec,
+			diagnostics: make(map[string]string),
+			attributes:  make(map[string]string),
+			Files:       make(map[string]bool),
+		},
+		fieldInfos:     make(map[string]*FieldInfo),
+		freqProxWriter: newFreqProxTermsWriter(bytesUsed),
+		norms:          newNormsConsumer(codec, similarity, bytesUsed),
+		docValues:      newDocValuesProcessor(codec, bytesUsed),
+		fieldState:     newFieldInvertState(""),
+		bytesUsed:      bytesUsed,
+		pendingUpdates: newBufferedUpdates(bytesUsed),
+	}
+	dwpt.termVectors = newTermVectorsConsumer(tracker, codec, dwpt.segmentInfo, bytesUsed)
+	dwpt.storedFieldsWriter, err = codec.GetStoredFieldsWriter(tracker,
+		dwpt.segmentInfo, store.IO_CONTEXT_DEFAULT)
+	if err != nil {
+		return nil, err
+	}
+	return dwpt, nil
+}
+
+func (dwpt *DocumentsWriterPerThread) fieldInfo(field document.IndexableField) *FieldInfo {
+	ft := field.FieldType()
+	indexOptions := IndexOptions(ft.IndexOptions())
+	fi, ok := dwpt.fieldInfos[field.Name()]
+	if !ok {
+		number := dwpt.fieldNumbers.addOrGet(field.Name(), -1)
+		info := NewFieldInfo(field.Name(), ft.Indexed(), number, ft.StoreTermVectors(),
+			ft.OmitNorms(), false, indexOptions, 0, 0, make(map[string]string))
+		fi = &info
+		dwpt.fieldInfos[field.Name()] = fi
+	} else if ft.Indexed() {
+		if !fi.indexed {
+			fi.indexed = true
+			fi.indexOptions = indexOptions
+			fi.omitNorms = ft.OmitNorms()
+		} else if indexOptions < fi.indexOptions {
+			// downgrade
+			fi.indexOptions = indexOptions
+			if indexOptions < INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS {
+				// cannot store payloads if we don't store positions:
+				fi.storePayloads = false
+			}
+		}
+		if fi.omitNorms != ft.OmitNorms() {
+			// if one require omitNorms at least once, it remains off
+			// for life
+			fi.omitNorms = true
+			fi.normType = 0
+		}
+		if ft.StoreTermVectors() {
+			fi.storeTermVector = true
+		}
+	}
+	return fi
+}
+
+/*
+Adds a document to the in-RAM segment, analyzing its tokenized fields
+with analyzer, which may be nil. A document that fails validation is
+rejected without touching the segment; any other error leaves the
+segment in an unknown state, and sets aborting.
+*/
+func (dwpt *DocumentsWriterPerThread) addDocument(doc []document.IndexableField,
+	analyzer analysis.Analyzer) (err error) {
+	// validate the document before touching any state, so a bad
+	// document doesn't leave the segment inconsistent
+	numStoredFields := 0
+	dvFields := make(map[string]DocValuesType)
+	tokenStreams := make([]analysis.TokenStream, len(doc))
+	defer func() {
+		for _, ts := range tokenStreams {
+			if ts == nil {
+				continue
+			}
+			if err2 := ts.Close(); err == nil {
+				err = err2
+			}
+		}
+	}()
+	for i, field := range doc {
+		ft := field.FieldType()
+		if field.Boost() != 1 && (!ft.Indexed() || ft.OmitNorms()) {
+			return errors.New(fmt.Sprintf(
+				"You cannot set an index-time boost: norms are omitted for field '%v'", field.Name()))
+		}
+		if ft.Indexed() {
+			if tokenStreams[i], err = field.TokenStream(analyzer); err != nil {
+				return errors.New(fmt.Sprintf("field '%v': %v", field.Name(), err))
+			}
+			if tokenStreams[i] == nil {
+				return errors.New(fmt.Sprintf(
+					"field '%v': indexed fields must have a TokenStream", field.Name()))
+			}
+		}
+		if err = checkTermVectorOptions(field.Name(), ft); err != nil {
+			return err
+		}
+		if ft.Stored() {
+			numStoredFields++
+		}
+		if dvType := DocValuesType(ft.DocValueType()); dvType != 0 {
+			if err = checkDocValuesField(field, dvType, dvFields); err != nil {
+				return err
+			}
+		}
+	}
+	for name, dvType := range dvFields {
+		if err = dwpt.fieldNumbers.setDocValuesType(name, dvType); err != nil {
+			return err
+		}
+	}
+
+	success := false
+	defer func() {
+		if !success {
+			dwpt.aborting = true
+		}
+	}()
+
+	docID := dwpt.numDocsInRAM
+	if err = dwpt.storedFieldsWriter.StartDocument(numStoredFields); err != nil {
+		return err
+	}
+	// group the indexed instances of each field
+	indexed := make(map[string][]int)
+	var names []string
+	for i, field := range doc {
+		fi := dwpt.fieldInfo(field)
+		if field.FieldType().Stored() {
+			if err = dwpt.storedFieldsWriter.WriteField(*fi, field); err != nil {
+				return err
+			}
+		}
+		if dvType := DocValuesType(field.FieldType().DocValueType()); dvType != 0 {
+			fi.docValueType = dvType
+			if err = dwpt.docValues.addField(docID, field, fi); err != nil {
+				return err
+			}
+		}
+		if field.FieldType().Indexed() {
+			if _, ok := indexed[field.Name()]; !ok {
+				names = append(names, field.Name())
+			}
+			indexed[field.Name()] = append(indexed[field.Name()], i)
+		}
+	}
+	if err = dwpt.storedFieldsWriter.FinishDocument(); err != nil {
+		return err
+	}
+
+	// Sort by field name
+	sort.Strings(names)
+	for _, name := range names {
+		fields := make([]document.IndexableField, len(indexed[name]))
+		streams := make([]analysis.TokenStream, len(indexed[name]))
+		for j, i := range indexed[name] {
+			fields[j], streams[j] = doc[i], tokenStreams[i]
+		}
+		if err = dwpt.invertField(docID, dwpt.fieldInfos[name], fields, streams, analyzer); err != nil {
+			return err
+		}
+	}
+	if err = dwpt.termVectors.finishDocument(docID); err != nil {
+		return err
+	}
+	dwpt.numDocsInRAM++
+	success = true
+	return nil
+}
+
+/*
+Inverts all instances of a field in the document, consuming the token
+stream of each instance, and adds their terms to the postings, and to
+the term vectors if any instance stores them, then computes the norm
+of the field. The instances of a tokenized field are separated by the
+position and offset gaps of analyzer, if any.
+*/
+func (dwpt *DocumentsWriterPerThread) invertField(docID int, fi *FieldInfo,
+	fields []document.IndexableField, streams []analysis.TokenStream,
+	analyzer analysis.Analyzer) error {
+	var doVectors, doVectorPositions, doVectorOffsets, doVectorPayloads bool
+	for _, field := range fields {
+		if ft := field.FieldType(); ft.StoreTermVectors() {
+			doVectors = true
+			doVectorPositions = doVectorPositions || ft.StoreTermVectorPositions()
+			doVectorOffsets = doVectorOffsets || ft.StoreTermVectorOffsets()
+			doVectorPayloads = doVectorPayloads || ft.StoreTermVectorPayloads()
+		}
+	}
+
+	perField := dwpt.freqProxWriter.addField(fi)
+	perField.start(docID)
+	var vectors *TermVectorsConsumerPerField
+	if doVectors {
+		vectors = dwpt.termVectors.addField(fi, doVectorPositions, doVectorOffsets, doVectorPayloads)
+	}
+	// only bother checking offsets if something will consume them
+	checkOffsets := doVectorOffsets ||
+		fi.indexOptions == INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+
+	state := dwpt.fieldState
+	state.name = fi.name
+	state.reset()
+	for i, field := range fields {
+		analyzed := field.FieldType().Tokenized() && analyzer != nil
+		if i > 0 && analyzed {
+			state.position += analyzer.PositionIncrementGap(fi.name)
+		}
+
+		stream := streams[i]
+		if err := stream.Reset(); err != nil {
+			return err
+		}
+		atts := stream.Attributes()
+		termAtt := atts.Add("CharTermAttribute").(analysis.CharTermAttribute)
+		posIncrAtt := atts.Add("PositionIncrementAttribute").(analysis.PositionIncrementAttribute)
+		offsetAtt := atts.Add("OffsetAttribute").(analysis.OffsetAttribute)
+		var payloadAtt analysis.PayloadAttribute
+		if atts.Has("PayloadAttribute") {
+			payloadAtt = atts.Get("PayloadAttribute").(analysis.PayloadAttribute)
+		}
+		lastStartOffset := 0
+		for {
+			ok, err := stream.IncrementToken()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+
+			posIncr := posIncrAtt.PositionIncrement()
+			if posIncr < 0 {
+				return errors.New(fmt.Sprintf(
+					"position increment must be >=0 (got %v) for field '%v'", posIncr, fi.name))
+			}
+			if state.position == 0 && posIncr == 0 {
+				return errors.New(fmt.Sprintf(
+					"first position increment must be > 0 (got 0) for field '%v'", fi.name))
+			}
+			position := state.position + posIncr
+			if position > 0 {
+				position--
+			} else if position < 0 {
+				return errors.New(fmt.Sprintf("position overflow for field '%v'", fi.name))
+			}
+			state.position = position
+			if posIncr == 0 {
+				state.numOverlap++
+			}
+
+			startOffset := state.offset + offsetAtt.StartOffset()
+			endOffset := state.offset + offsetAtt.EndOffset()
+			if checkOffsets {
+				if startOffset < 0 || endOffset < startOffset {
+					return errors.New(fmt.Sprintf(
+						"startOffset must be non-negative, and endOffset must be >= startOffset, startOffset=%v,endOffset=%v for field '%v'",
+						startOffset, endOffset, fi.name))
+				}
+				if startOffset < lastStartOffset {
+					return errors.New(fmt.Sprintf(
+						"offsets must not go backwards startOffset=%v is < lastStartOffset=%v for field '%v'",
+						startOffset, lastStartOffset, fi.name))
+				}
+				lastStartOffset = startOffset
+			}
+
+			var payload []byte
+			if payloadAtt != nil {
+				payload = payloadAtt.Payload()
+			}
+			perField.addTerm(termAtt.Bytes(), state.position, startOffset, endOffset, payload)
+			if vectors != nil {
+				vectors.addTerm(termAtt.Bytes(), state.position, startOffset, endOffset, payload)
+			}
+
+			state.length++
+			state.position++
+		}
+		// trigger streams to perform end-of-stream operations, which
+		// sets the final offset of the stream
+		if err := stream.End(); err != nil {
+			return err
+		}
+		state.offset += offsetAtt.EndOffset()
+		if analyzed {
+			state.offset += analyzer.OffsetGap(fi.name)
+		}
+		state.boost *= field.Boost()
+	}
+	dwpt.norms.finish(docID, fi, state)
+	return nil
+}
+
+// Returns an error if the term vector options of a field are
+// inconsistent.
+func checkTermVectorOptions(name string, ft document.IndexableFieldType) error {
+	switch {
+	case !ft.Indexed() && ft.StoreTermVectors():
+		return errors.New(fmt.Sprintf(
+			"cannot index term vectors when field is not indexed (field=\"%v\")", name))
+	case !ft.StoreTermVectors() && ft.StoreTermVectorOffsets():
+		return errors.New(fmt.Sprintf(
+			"cannot index term vector offsets when term vectors are not indexed (field=\"%v\")", name))
+	case !ft.StoreTermVectors() && ft.StoreTermVectorPositions():
+		return errors.New(fmt.Sprintf(
+			"cannot index term vector positions when term vectors are not indexed (field=\"%v\")", name))
+	case !ft.StoreTermVectors() && ft.StoreTermVectorPayloads():
+		return errors.New(fmt.Sprintf(
+			"cannot index term vector payloads when term vectors are not indexed (field=\"%v\")", name))
+	case ft.StoreTermVectorPayloads() && !ft.StoreTermVectorPositions():
+		return errors.New(fmt.Sprintf(
+			"cannot index term vector payloads without term vector positions (field=\"%v\")", name))
+	}
+	return nil
+}
+
+/*
+Returns an error if the value of a doc values field doesn't match its
+type, if a single-valued doc values field appears more than once in
+the document, or if the field has doc values of different types.
+*/
+func checkDocValuesField(field document.IndexableField, dvType DocValuesType,
+	seen map[string]DocValuesType) error {
+	name := field.Name()
+	if current, ok := seen[name]; ok {
+		if current != dvType {
+			return errors.New(fmt.Sprintf(
+				"cannot change DocValues type from %v to %v for field \"%v\"",
+				document.DocValuesType(current), document.DocValuesType(dvType), name))
+		}
+		if dvType != DOC_VALUES_TYPE_SORTED_SET {
+			return errors.New(fmt.Sprintf(
+				"DocValuesField \"%v\" appears more than once in this document (only one value is allowed per field)", name))
+		}
+	}
+	seen[name] = dvType
+
+	if dvType == DOC_VALUES_TYPE_NUMERIC {
+		if _, ok := field.NumericValue().(int64); !ok {
+			return errors.New(fmt.Sprintf(
+				"illegal type %T: DocValues types must be int64", field.NumericValue()))
+		}
+		return nil
+	}
+	value := field.BinaryValue()
+	if value == nil {
+		return errors.New(fmt.Sprintf("field \"%v\": null value not allowed", name))
+	}
+	if len(value) > util.BYTE_BLOCK_SIZE-2 {
+		return errors.New(fmt.Sprintf(
+			"DocValuesField \"%v\" is too large, must be <= %v", name, util.BYTE_BLOCK_SIZE-2))
+	}
+	return nil
+}
+
+// Discards the buffered segment, deleting any files written so far.
+func (dwpt *DocumentsWriterPerThread) abort() {
+	if dwpt.storedFieldsWriter != nil {
+		dwpt.storedFieldsWriter.Abort()
+		dwpt.storedFieldsWriter = nil
+	}
+	dwpt.termVectors.abort()
+	dwpt.norms.abort()
+	dwpt.docValues.abort()
+	for file, _ := range dwpt.directoryTracker.CreatedFiles() {
+		dwpt.directory.DeleteFile(file)
+	}
+	dwpt.freqProxWriter.reset()
+	dwpt.pendingUpdates.clear()
+	dwpt.numDocsInRAM = 0
+}
+
+/*
+Flushes all buffered documents as a new segment, and returns the new
+segment, or nil if nothing was buffered. The segment is aborted if an
+error is hit.
+*/
+func (dwpt *DocumentsWriterPerThread) flush() (info *SegmentInfoPerCommit, err error) {
+	if dwpt.numDocsInRAM == 0 {
+		dwpt.abort()
+		return nil, nil
+	}
+	success := false
+	defer func() {
+		if !success {
+			dwpt.abort()
+		}
+	}()
+
+	numDocs := dwpt.numDocsInRAM
+	dwpt.segmentInfo.docCount = int32(numDocs)
+
+	infos := make([]FieldInfo, 0, len(dwpt.fieldInfos))
+	for _, fi := range dwpt.fieldInfos {
+		infos = append(infos, *fi)
+	}
+	fieldInfos := NewFieldInfos(infos)
+	flushState := newSegmentWriteState(dwpt.directoryTracker, dwpt.segmentInfo, fieldInfos, store.IO_CONTEXT_DEFAULT)
+
+	err = dwpt.storedFieldsWriter.Finish(fieldInfos, numDocs)
+	if err == nil {
+		err = dwpt.storedFieldsWriter.Close()
+	}
+	dwpt.storedFieldsWriter = nil
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dwpt.termVectors.flush(flushState); err != nil {
+		return nil, err
+	}
+
+	if err = dwpt.flushPostings(flushState); err != nil {
+		return nil, err
+	}
+
+	if err = dwpt.norms.flush(flushState); err != nil {
+		return nil, err
+	}
+
+	if err = dwpt.docValues.flush(flushState); err != nil {
+		return nil, err
+	}
+
+	// Write FieldInfos after the postings and doc values, since
+	// their formats record their per-field attributes
+	if err = dwpt.codec.WriteFieldInfos(dwpt.directoryTracker, dwpt.segmentInfo.name, "",
+		fieldInfos, store.IO_CONTEXT_DEFAULT); err != nil {
+		return nil, err
+	}
+
+	setDiagnostics(dwpt.segmentInfo, "flush", nil)
+	dwpt.segmentInfo.Files = dwpt.directoryTracker.CreatedFiles()
+
+	// Have codec write SegmentInfo. Must do this last, so that the
+	// .si lists all the files of the segment:
+	if err = dwpt.codec.WriteSegmentInfo(dwpt.directoryTracker, dwpt.segmentInfo,
+		fieldInfos, store.IO_CONTEXT_DEFAULT); err != nil {
+		return nil, err
+	}
+
+	ans := NewSegmentInfoPerCommit(*dwpt.segmentInfo, 0, -1, -1)
+	success = true
+	return &ans, nil
+}
+
+func (dwpt *DocumentsWriterPerThread) flushPostings(state SegmentWriteState) (err error) {
+	consumer, err := dwpt.codec.GetFieldsConsumer(state)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = consumer.Close()
+		} else {
+			util.CloseWhileSuppressingError(consumer)
+		}
+	}()
+	return dwpt.freqProxWriter.flush(state, consumer)
+}
@@ -0,0 +1,145 @@
+package index
+
+import (
+	"sync"
+)
+
+// DocumentsWriterPerThreadPool.java
+
+/*
+ThreadState references and guards a DocumentsWriterPerThread
+instance that is used during indexing to build an in-memory index
+segment. A ThreadState is owned by at most one goroutine at a time,
+so the DocumentsWriterPerThread it holds needs no locking.
+
+The bytes used by the segment, and whether it is pending to be
+flushed, are guarded by the DocumentsWriterFlushControl instead, as
+the FlushPolicy inspects all ThreadStates.
+*/
+type ThreadState struct {
+	dwpt *DocumentsWriterPerThread
+
+	bytesUsed    int64
+	flushPending bool
+}
+
+/*
+DocumentsWriterPerThreadPool controls ThreadState instances and their
+goroutine assignments during indexing. Each ThreadState holds a
+reference to a DocumentsWriterPerThread that is once a ThreadState is
+obtained from the pool exclusively used for indexing a single document
+by the obtaining goroutine.
+
+Go offers no goroutine-local storage, so instead of binding states to
+threads, free states are kept on a stack: a goroutine obtains the
+most recently released state, which makes a single indexing goroutine
+keep filling the same segment, while concurrent goroutines each get
+their own.
+
+A full flush obtains all states, which blocks new obtains until the
+states are released again.
+*/
+type DocumentsWriterPerThreadPool struct {
+	sync.Locker
+	cond *sync.Cond
+
+	threadStates []*ThreadState
+	freeStates   []*ThreadState
+	fullFlush    bool
+}
+
+func newDocumentsWriterPerThreadPool(maxNumThreadStates int) *DocumentsWriterPerThreadPool {
+	if maxNumThreadStates < 1 {
+		panic("maxNumThreadStates must be >= 1")
+	}
+	lock := &sync.Mutex{}
+	ans := &DocumentsWriterPerThreadPool{
+		Locker:       lock,
+		cond:         sync.NewCond(lock),
+		threadStates: make([]*ThreadState, maxNumThreadStates),
+		freeStates:   make([]*ThreadState, 0, maxNumThreadStates),
+	}
+	for i, _ := range ans.threadStates {
+		ans.threadStates[i] = &ThreadState{}
+		ans.freeStates = append(ans.freeStates, ans.threadStates[i])
+	}
+	return ans
+}
+
+// Returns the max number of ThreadState instances available in this
+// pool.
+func (p *DocumentsWriterPerThreadPool) maxThreadStates() int {
+	return len(p.threadStates)
+}
+
+/*
+Obtains a ThreadState for exclusive use, blocking until one is free.
+It must be returned with release().
+*/
+func (p *DocumentsWriterPerThreadPool) obtain() *ThreadState {
+	p.Lock()
+	defer p.Unlock()
+	for p.fullFlush || len(p.freeStates) == 0 {
+		p.cond.Wait()
+	}
+	n := len(p.freeStates) - 1
+	state := p.freeStates[n]
+	p.freeStates = p.freeStates[:n]
+	return state
+}
+
+/*
+Obtains a free ThreadState whose segment is pending to be flushed,
+without blocking. Returns nil if there is none, or a full flush is
+running. It must be returned with release().
+*/
+func (p *DocumentsWriterPerThreadPool) obtainFlushPending(control *DocumentsWriterFlushControl) *ThreadState {
+	p.Lock()
+	defer p.Unlock()
+	if p.fullFlush {
+		return nil
+	}
+	for i, state := range p.freeStates {
+		if control.isFlushPending(state) {
+			p.freeStates = append(p.freeStates[:i], p.freeStates[i+1:]...)
+			return state
+		}
+	}
+	return nil
+}
+
+// Returns a ThreadState obtained with obtain() to the pool.
+func (p *DocumentsWriterPerThreadPool) release(state *ThreadState) {
+	p.Lock()
+	defer p.Unlock()
+	p.freeStates = append(p.freeStates, state)
+	p.cond.Broadcast()
+}
+
+/*
+Obtains all ThreadStates, waiting for the goroutines currently
+indexing to release theirs. No ThreadState is handed out until they
+are returned with releaseAll().
+*/
+func (p *DocumentsWriterPerThreadPool) obtainAll() []*ThreadState {
+	p.Lock()
+	defer p.Unlock()
+	for p.fullFlush {
+		p.cond.Wait()
+	}
+	p.fullFlush = true
+	for len(p.freeStates) < len(p.threadStates) {
+		p.cond.Wait()
+	}
+	p.freeStates = p.freeStates[:0]
+	return p.threadStates
+}
+
+// Returns all ThreadStates obtained with obtainAll() to the pool.
+func (p *DocumentsWriterPerThreadPool) releaseAll() {
+	p.Lock()
+	defer p.Unlock()
+	p.freeStates = append(p.freeStates[:0], p.threadStates...)
+	p.fullFlush = false
+	p.cond.Broadcast()
+}
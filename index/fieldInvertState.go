@@ -0,0 +1,84 @@
+package index
+
+// index/FieldInvertState.java
+
+/*
+This class tracks the number and position / offset parameters of
+terms being added to the index. The information collected in this
+class is also used to calculate the normalization factor for a field.
+*/
+type FieldInvertState struct {
+	name             string
+	position         int
+	length           int
+	numOverlap       int
+	offset           int
+	maxTermFrequency int
+	uniqueTermCount  int
+	boost            float32
+}
+
+// Creates FieldInvertState for the specified field name.
+func newFieldInvertState(name string) *FieldInvertState {
+	return &FieldInvertState{name: name}
+}
+
+// Re-initialize the state
+func (s *FieldInvertState) reset() {
+	s.position = 0
+	s.length = 0
+	s.numOverlap = 0
+	s.offset = 0
+	s.maxTermFrequency = 0
+	s.uniqueTermCount = 0
+	s.boost = 1
+}
+
+// Get the last processed term position.
+func (s *FieldInvertState) Position() int {
+	return s.position
+}
+
+/*
+Get total number of terms in this field.
+*/
+func (s *FieldInvertState) Length() int {
+	return s.length
+}
+
+// Get the number of terms with positionIncrement == 0.
+func (s *FieldInvertState) NumOverlap() int {
+	return s.numOverlap
+}
+
+// Get end offset of the last processed term.
+func (s *FieldInvertState) Offset() int {
+	return s.offset
+}
+
+/*
+Get boost value. This is the cumulative product of document boost and
+field boost for all field instances sharing the same field name.
+*/
+func (s *FieldInvertState) Boost() float32 {
+	return s.boost
+}
+
+/*
+Get the maximum term-frequency encountered for any term in the field.
+A field containing "the quick brown fox jumps over the lazy dog" would
+have a value of 2, because "the" appears twice.
+*/
+func (s *FieldInvertState) MaxTermFrequency() int {
+	return s.maxTermFrequency
+}
+
+// Return the number of unique terms encountered in this field.
+func (s *FieldInvertState) UniqueTermCount() int {
+	return s.uniqueTermCount
+}
+
+// Return the field's name
+func (s *FieldInvertState) Name() string {
+	return s.name
+}
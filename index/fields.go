@@ -2,6 +2,7 @@ package index
 
 import (
 	"fmt"
+	"github.com/balzaczyy/golucene/util"
 	"log"
 	"sort"
 )
@@ -34,7 +35,7 @@ func (mf MultiFields) Terms(field string) Terms {
 	// Gather all sub-readers that share this field
 	for i, v := range mf.subs {
 		terms := v.Terms(field)
-		if terms.Iterator != nil {
+		if terms != nil {
 			subs2 = append(subs2, terms)
 			slices2 = append(slices2, mf.subSlices[i])
 		}
@@ -69,7 +70,7 @@ func GetMultiFields(r IndexReader) Fields {
 				continue
 			}
 			fields = append(fields, f)
-			slices = append(slices, ReaderSlice{ctx.DocBase, r.MaxDoc(), len(fields)})
+			slices = append(slices, ReaderSlice{ctx.DocBase, ctx.Reader().MaxDoc(), len(fields) - 1})
 		}
 		log.Printf("Found %v fields in %v slices.", len(fields), len(slices))
 		switch len(fields) {
@@ -86,12 +87,91 @@ func GetMultiFields(r IndexReader) Fields {
 func GetMultiTerms(r IndexReader, field string) Terms {
 	log.Printf("Loading field '%v' from %v", field, r)
 	fields := GetMultiFields(r)
-	if fields.Terms == nil {
+	if fields == nil {
 		return nil
 	}
 	return fields.Terms(field)
 }
 
+/*
+Returns a single Bits instance for this reader, merging live
+Documents on the fly. This method will return nil if the reader has
+no deletions.
+
+NOTE: this is a very slow way to access live docs. For example, each
+Bits access will require a binary search. It's better to get the
+sub-readers and iterate through them yourself.
+*/
+func GetMultiLiveDocs(r IndexReader) util.Bits {
+	if r.NumDocs() == r.MaxDoc() { // no deletions
+		return nil
+	}
+	leaves := r.Leaves()
+	if len(leaves) == 1 {
+		return leaves[0].Reader().(AtomicReader).LiveDocs()
+	}
+	liveDocs := make([]util.Bits, len(leaves))
+	starts := make([]int, len(leaves)+1)
+	for i, ctx := range leaves {
+		// record all liveDocs, even if they are nil
+		liveDocs[i] = ctx.Reader().(AtomicReader).LiveDocs()
+		starts[i] = ctx.DocBase
+	}
+	starts[len(leaves)] = r.MaxDoc()
+	return newMultiBits(liveDocs, starts, true)
+}
+
+/*
+Call this to get the (merged) FieldInfos for a composite reader. The
+settings of a field are unified across the leaves.
+
+NOTE: a field keeps its number in the first leaf having it, unless
+another field already took that number, so the returned field numbers
+may not correspond to the actual field numbers in the underlying
+readers if they come from different indexes.
+*/
+func GetMergedFieldInfos(r IndexReader) FieldInfos {
+	byName := make(map[string]*FieldInfo)
+	var names []string
+	used := make(map[int32]bool)
+	var maxNumber int32 = -1
+	for _, ctx := range r.Leaves() {
+		for _, fi := range ctx.Reader().(AtomicReader).FieldInfos().values {
+			if fi.number > maxNumber {
+				maxNumber = fi.number
+			}
+		}
+	}
+	for _, ctx := range r.Leaves() {
+		for _, fi := range ctx.Reader().(AtomicReader).FieldInfos().values {
+			merged, ok := byName[fi.name]
+			if !ok {
+				info := fi
+				if used[info.number] {
+					maxNumber++
+					info.number = maxNumber
+				}
+				used[info.number] = true
+				byName[fi.name] = &info
+				names = append(names, fi.name)
+				continue
+			}
+			merged.update(fi)
+			if merged.docValueType == 0 {
+				merged.docValueType = fi.docValueType
+			}
+			if !merged.omitNorms && merged.normType == 0 {
+				merged.normType = fi.normType
+			}
+		}
+	}
+	infos := make([]FieldInfo, len(names))
+	for i, name := range names {
+		infos[i] = *byName[name]
+	}
+	return NewFieldInfos(infos)
+}
+
 type FieldInfo struct {
 	// Field's name
 	name string
@@ -110,11 +190,16 @@ type FieldInfo struct {
 	storePayloads bool
 
 	attributes map[string]string
+
+	// Generation of the doc values of the field, or -1 if they were
+	// never updated
+	dvGen int64
 }
 
 func NewFieldInfo(name string, indexed bool, number int32, storeTermVector, omitNorms, storePayloads bool,
 	indexOptions IndexOptions, docValues, normsType DocValuesType, attributes map[string]string) FieldInfo {
-	fi := FieldInfo{name: name, indexed: indexed, number: number, docValueType: docValues, attributes: attributes}
+	fi := FieldInfo{name: name, indexed: indexed, number: number, docValueType: docValues,
+		attributes: attributes, dvGen: -1}
 	if indexed {
 		fi.storeTermVector = storeTermVector
 		fi.storePayloads = storePayloads
@@ -133,6 +218,48 @@ func (fi FieldInfo) String() string {
 		fi.number, fi.name, fi.indexed, fi.docValueType, fi.storeTermVector, fi.normType, fi.omitNorms, fi.indexOptions, fi.storePayloads, fi.attributes)
 }
 
+/*
+Unifies the settings of a field with those of another instance of
+the field, e.g. from another segment.
+*/
+func (fi *FieldInfo) update(other FieldInfo) {
+	if fi.indexed != other.indexed {
+		fi.indexed = true
+	}
+	if !other.indexed {
+		return
+	}
+	if fi.storeTermVector != other.storeTermVector {
+		fi.storeTermVector = true // once vector, always vector
+	}
+	if fi.storePayloads != other.storePayloads {
+		fi.storePayloads = true
+	}
+	if fi.omitNorms != other.omitNorms {
+		fi.omitNorms = true // if one require omitNorms at least once, it remains off for life
+		fi.normType = 0
+	}
+	if fi.indexOptions != other.indexOptions {
+		if fi.indexOptions == 0 {
+			fi.indexOptions = other.indexOptions
+		} else if other.indexOptions < fi.indexOptions {
+			// downgrade
+			fi.indexOptions = other.indexOptions
+		}
+		if fi.indexOptions < INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS {
+			// cannot store payloads if we don't store positions:
+			fi.storePayloads = false
+		}
+	}
+}
+
+// Records that the field has payloads, if it indexes positions.
+func (fi *FieldInfo) setStorePayloads() {
+	if fi.indexed && fi.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS {
+		fi.storePayloads = true
+	}
+}
+
 type Int32Slice []int32
 
 func (p Int32Slice) Len() int           { return len(p) }
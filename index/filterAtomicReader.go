@@ -0,0 +1,115 @@
+package index
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// FilterAtomicReader.java
+
+/*
+A FilterAtomicReader contains another AtomicReader, which it uses as
+its basic source of data, possibly transforming the data along the
+way or providing additional functionality. FilterAtomicReader itself
+simply implements all methods of AtomicReader by passing all
+requests to the contained reader. Sub-types of FilterAtomicReader
+embed it, pass themselves as self, and may further override some of
+these methods, e.g. LiveDocs() and NumDocs() to hide documents, or
+Fields() to restrict the visible fields.
+
+NOTE: If you override LiveDocs(), you will likely need to override
+NumDocs() as well and vice-versa.
+*/
+type FilterAtomicReader struct {
+	*AtomicReaderImpl
+	// The underlying AtomicReader.
+	in AtomicReader
+}
+
+/*
+Construct a FilterAtomicReader based on the specified base reader.
+self is the outermost reader, whose overriding methods are used by
+the inherited ones, e.g. Terms() calls self.Fields().
+
+Note that base reader is closed if this FilterAtomicReader is closed.
+*/
+func NewFilterAtomicReader(self AtomicReader, in AtomicReader) *FilterAtomicReader {
+	ans := &FilterAtomicReader{in: in}
+	ans.AtomicReaderImpl = newAtomicReader(self)
+	ans.ARFieldsReader = self
+	in.registerParentReader(self)
+	return ans
+}
+
+// Returns the wrapped AtomicReader.
+func (r *FilterAtomicReader) Delegate() AtomicReader {
+	return r.in
+}
+
+func (r *FilterAtomicReader) LiveDocs() util.Bits {
+	r.ensureOpen()
+	return r.in.LiveDocs()
+}
+
+func (r *FilterAtomicReader) TermVectors(docID int) (fs Fields, err error) {
+	r.ensureOpen()
+	return r.in.TermVectors(docID)
+}
+
+func (r *FilterAtomicReader) NumDocs() int {
+	// Don't call ensureOpen() here (it could affect performance)
+	return r.in.NumDocs()
+}
+
+func (r *FilterAtomicReader) MaxDoc() int {
+	// Don't call ensureOpen() here (it could affect performance)
+	return r.in.MaxDoc()
+}
+
+func (r *FilterAtomicReader) Document(docID int, visitor StoredFieldVisitor) error {
+	r.ensureOpen()
+	return r.in.Document(docID, visitor)
+}
+
+func (r *FilterAtomicReader) doClose() error {
+	return r.in.Close()
+}
+
+func (r *FilterAtomicReader) FieldInfos() FieldInfos {
+	r.ensureOpen()
+	return r.in.FieldInfos()
+}
+
+func (r *FilterAtomicReader) Fields() Fields {
+	r.ensureOpen()
+	return r.in.Fields()
+}
+
+func (r *FilterAtomicReader) NumericDocValues(field string) (v NumericDocValues, err error) {
+	r.ensureOpen()
+	return r.in.NumericDocValues(field)
+}
+
+func (r *FilterAtomicReader) BinaryDocValues(field string) (v BinaryDocValues, err error) {
+	r.ensureOpen()
+	return r.in.BinaryDocValues(field)
+}
+
+func (r *FilterAtomicReader) SortedDocValues(field string) (v SortedDocValues, err error) {
+	r.ensureOpen()
+	return r.in.SortedDocValues(field)
+}
+
+func (r *FilterAtomicReader) SortedSetDocValues(field string) (v SortedSetDocValues, err error) {
+	r.ensureOpen()
+	return r.in.SortedSetDocValues(field)
+}
+
+func (r *FilterAtomicReader) NormValues(field string) (v NumericDocValues, err error) {
+	r.ensureOpen()
+	return r.in.NormValues(field)
+}
+
+func (r *FilterAtomicReader) String() string {
+	return fmt.Sprintf("FilterAtomicReader(%v)", r.in)
+}
@@ -0,0 +1,107 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/util"
+	"testing"
+)
+
+// Hides odd documents and the fields not in the allowed set.
+type testFilterReader struct {
+	*FilterAtomicReader
+	allowed map[string]bool
+}
+
+func newTestFilterReader(in AtomicReader, allowed ...string) *testFilterReader {
+	ans := &testFilterReader{allowed: make(map[string]bool)}
+	ans.FilterAtomicReader = NewFilterAtomicReader(ans, in)
+	for _, field := range allowed {
+		ans.allowed[field] = true
+	}
+	return ans
+}
+
+func (r *testFilterReader) LiveDocs() util.Bits {
+	liveDocs := make(testBits, r.MaxDoc())
+	for i := range liveDocs {
+		liveDocs[i] = i%2 == 0
+	}
+	return liveDocs
+}
+
+func (r *testFilterReader) NumDocs() int {
+	return (r.MaxDoc() + 1) / 2
+}
+
+func (r *testFilterReader) Fields() Fields {
+	return testFilterFields{r.FilterAtomicReader.Fields(), r.allowed}
+}
+
+type testFilterFields struct {
+	Fields
+	allowed map[string]bool
+}
+
+func (f testFilterFields) Terms(field string) Terms {
+	if !f.allowed[field] {
+		return nil
+	}
+	return f.Fields.Terms(field)
+}
+
+func TestFilterAtomicReader(t *testing.T) {
+	sub := openTestReaders(t, 1)[0]
+	defer sub.Close()
+	in := sub.Leaves()[0].Reader().(AtomicReader)
+	r := newTestFilterReader(in, "scope")
+	maxDoc := in.MaxDoc()
+	assertEquals(t, maxDoc, r.MaxDoc())
+	assertEquals(t, maxDoc/2, r.NumDocs())
+
+	// restricted fields
+	if r.Terms("key") != nil {
+		t.Error("Field 'key' should be hidden")
+	}
+	if n, err := r.DocFreq(NewTerm("key", "belfrysample/bats.dita")); err != nil || n != 0 {
+		t.Errorf("Expected docFreq 0 of hidden field, but was %v (%v)", n, err)
+	}
+	if n, err := r.DocFreq(NewTerm("scope", "belfrysample")); err != nil || n != maxDoc {
+		t.Errorf("Expected docFreq %v, but was %v (%v)", maxDoc, n, err)
+	}
+
+	// hidden docs, also when wrapped in a composite reader
+	mr := NewMultiReader([]IndexReader{r}, false)
+	if n := mr.NumDocs(); n != maxDoc/2 {
+		t.Errorf("Expected %v docs, but was %v", maxDoc/2, n)
+	}
+	leaf := mr.Leaves()[0].Reader().(AtomicReader)
+	termsEnum := leaf.Terms("scope").Iterator(nil)
+	if ok, err := termsEnum.SeekExact([]byte("belfrysample")); !ok || err != nil {
+		t.Fatalf("Term not found: %v", err)
+	}
+	docs := collectDocs(termsEnum.Docs(leaf.LiveDocs(), DOCS_ENUM_EMPTY))
+	if len(docs) != maxDoc/2 {
+		t.Errorf("Expected %v docs, but was %v", maxDoc/2, docs)
+	}
+	for _, docID := range docs {
+		if docID%2 != 0 {
+			t.Errorf("Doc %v should be hidden", docID)
+		}
+	}
+
+	// stored fields are passed through
+	visitor := NewDocumentStoredFieldVisitor()
+	if err := mr.Document(7, visitor); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Bats", visitor.Document().Get("title"))
+
+	// closing the filter reader closes the wrapped reader
+	if err := mr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertClosed(t, "filter reader", r)
+	assertClosed(t, "wrapped reader", in)
+}
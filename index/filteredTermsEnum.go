@@ -0,0 +1,223 @@
+package index
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+)
+
+// FilteredTermsEnum.java
+
+// Return value, if term should be accepted or the iteration should
+// END. The *_SEEK values denote, that after handling the current term
+// the enum should call NextSeekTerm() and step forward.
+type AcceptStatus int
+
+const (
+	// Accept the term and position the enum at the next term.
+	ACCEPT_STATUS_YES = AcceptStatus(1)
+	// Accept the term and advance (NextSeekTerm()) to the next term.
+	ACCEPT_STATUS_YES_AND_SEEK = AcceptStatus(2)
+	// Reject the term and position the enum at the next term.
+	ACCEPT_STATUS_NO = AcceptStatus(3)
+	// Reject the term and advance (NextSeekTerm()) to the next term.
+	ACCEPT_STATUS_NO_AND_SEEK = AcceptStatus(4)
+	// Reject the term and stop enumerating.
+	ACCEPT_STATUS_END = AcceptStatus(5)
+)
+
+/*
+Hooks of a filtered TermsEnum, implemented by the concrete type which
+embeds FilteredTermsEnumImpl.
+*/
+type FilteredTermsEnum interface {
+	TermsEnum
+	/*
+		Return if term is accepted, not accepted or the iteration
+		should ended (and possibly seek).
+	*/
+	Accept(term []byte) AcceptStatus
+	/*
+		On the first call to Next() or if Accept() returns
+		ACCEPT_STATUS_YES_AND_SEEK or ACCEPT_STATUS_NO_AND_SEEK, this
+		method will be called to eventually seek the underlying
+		TermsEnum to a new position. On the first call, currentTerm will
+		be nil, later calls will provide the term the underlying enum is
+		positioned at. This method returns per default only one time the
+		initial seek term and then nil, so no repositioning is ever done.
+
+		Override this method, if you want a more sophisticated TermsEnum,
+		that repositions the iterator during enumeration. If this method
+		always returns nil the enum is empty.
+
+		Please note: This method should always provide a greater term
+		than the last enumerated term, else the behaviour of this enum
+		violates the contract for TermsEnums.
+	*/
+	NextSeekTerm(currentTerm []byte) ([]byte, error)
+}
+
+/*
+Abstract class for enumerating a subset of all terms.
+
+Term enumerations are always ordered by Comparator(). Each term in
+the enumeration is greater than all that precede it.
+
+Please note: Consumers of this enum cannot call seek(), it is
+forward only; it panics or returns ErrUnsupported when a seeking
+method is called.
+*/
+type FilteredTermsEnumImpl struct {
+	self FilteredTermsEnum
+
+	initialSeekTerm []byte
+	doSeek          bool
+	actualTerm      []byte
+
+	tenum TermsEnum
+}
+
+/*
+Creates a filtered TermsEnum on a terms enum. If startWithSeek is
+true, the first call to Next() would seek the underlying enum to the
+term returned by NextSeekTerm(nil) (see SetInitialSeekTerm()),
+otherwise it is positioned at the current term of tenum.
+*/
+func NewFilteredTermsEnum(self FilteredTermsEnum, tenum TermsEnum, startWithSeek bool) *FilteredTermsEnumImpl {
+	// assert tenum != nil
+	return &FilteredTermsEnumImpl{self: self, tenum: tenum, doSeek: startWithSeek}
+}
+
+/*
+Use this method to set the initial []byte to seek before iterating.
+This is a convenience method for subclasses that do not override
+NextSeekTerm(). If the initial seek term is nil (default), the enum
+is empty.
+
+You can only use this method, if you keep the default
+implementation of NextSeekTerm().
+*/
+func (e *FilteredTermsEnumImpl) SetInitialSeekTerm(term []byte) {
+	e.initialSeekTerm = term
+}
+
+func (e *FilteredTermsEnumImpl) NextSeekTerm(currentTerm []byte) ([]byte, error) {
+	t := e.initialSeekTerm
+	e.initialSeekTerm = nil
+	return t, nil
+}
+
+// Returns the related attributes, the returned AttributeSource is
+// shared with the delegate TermsEnum.
+func (e *FilteredTermsEnumImpl) Attributes() *util.AttributeSource {
+	return e.tenum.Attributes()
+}
+
+func (e *FilteredTermsEnumImpl) Term() []byte {
+	return e.tenum.Term()
+}
+
+func (e *FilteredTermsEnumImpl) Comparator() sort.Interface {
+	return e.tenum.Comparator()
+}
+
+func (e *FilteredTermsEnumImpl) DocFreq() int {
+	return e.tenum.DocFreq()
+}
+
+func (e *FilteredTermsEnumImpl) TotalTermFreq() int64 {
+	return e.tenum.TotalTermFreq()
+}
+
+// This enum does not support seeking!
+func (e *FilteredTermsEnumImpl) SeekExact(text []byte) (ok bool, err error) {
+	return false, ErrUnsupported
+}
+
+// This enum does not support seeking!
+func (e *FilteredTermsEnumImpl) SeekCeil(text []byte) SeekStatus {
+	panic("FilteredTermsEnum does not support seeking")
+}
+
+// This enum does not support seeking!
+func (e *FilteredTermsEnumImpl) SeekExactByPosition(ord int64) error {
+	return ErrUnsupported
+}
+
+// This enum does not support seeking!
+func (e *FilteredTermsEnumImpl) SeekExactFromLast(text []byte, state TermState) error {
+	return ErrUnsupported
+}
+
+func (e *FilteredTermsEnumImpl) Ord() (int64, error) {
+	return e.tenum.Ord()
+}
+
+func (e *FilteredTermsEnumImpl) Docs(liveDocs util.Bits, reuse DocsEnum) DocsEnum {
+	return e.tenum.Docs(liveDocs, reuse)
+}
+
+func (e *FilteredTermsEnumImpl) DocsByFlags(liveDocs util.Bits, reuse DocsEnum, flags int) DocsEnum {
+	return e.tenum.DocsByFlags(liveDocs, reuse, flags)
+}
+
+func (e *FilteredTermsEnumImpl) DocsAndPositions(liveDocs util.Bits, reuse DocsAndPositionsEnum) DocsAndPositionsEnum {
+	return e.tenum.DocsAndPositions(liveDocs, reuse)
+}
+
+func (e *FilteredTermsEnumImpl) DocsAndPositionsByFlags(liveDocs util.Bits, reuse DocsAndPositionsEnum, flags int) DocsAndPositionsEnum {
+	return e.tenum.DocsAndPositionsByFlags(liveDocs, reuse, flags)
+}
+
+// Returns the filtered enums term state
+func (e *FilteredTermsEnumImpl) TermState() TermState {
+	// assert e.tenum != nil
+	return e.tenum.TermState()
+}
+
+func (e *FilteredTermsEnumImpl) Next() (term []byte, err error) {
+	for {
+		// Seek or forward the iterator
+		if e.doSeek {
+			e.doSeek = false
+			t, err := e.self.NextSeekTerm(e.actualTerm)
+			if err != nil {
+				return nil, err
+			}
+			// Make sure we always seek forward:
+			if e.actualTerm != nil && t != nil && bytes.Compare(t, e.actualTerm) <= 0 {
+				panic("assert fail")
+			}
+			if t == nil || e.tenum.SeekCeil(t) == SEEK_STATUS_END {
+				// no more terms to seek to or enum exhausted
+				return nil, nil
+			}
+			e.actualTerm = e.tenum.Term()
+		} else {
+			if e.actualTerm, err = e.tenum.Next(); err != nil {
+				return nil, err
+			}
+			if e.actualTerm == nil {
+				// enum exhausted
+				return nil, nil
+			}
+		}
+
+		// check if term is accepted
+		switch e.self.Accept(e.actualTerm) {
+		case ACCEPT_STATUS_YES_AND_SEEK:
+			e.doSeek = true
+			// term accepted, but we need to seek so fall-through
+			fallthrough
+		case ACCEPT_STATUS_YES:
+			// term accepted
+			return e.actualTerm, nil
+		case ACCEPT_STATUS_NO_AND_SEEK:
+			// invalid term, seek next time
+			e.doSeek = true
+		case ACCEPT_STATUS_END:
+			// we are supposed to end the enum
+			return nil, nil
+		}
+	}
+}
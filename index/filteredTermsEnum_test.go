@@ -0,0 +1,147 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util/automaton"
+	"testing"
+)
+
+type prefixTermsEnum struct {
+	*FilteredTermsEnumImpl
+	prefix []byte
+}
+
+func newPrefixTermsEnum(tenum TermsEnum, prefix []byte) *prefixTermsEnum {
+	ans := &prefixTermsEnum{prefix: prefix}
+	ans.FilteredTermsEnumImpl = NewFilteredTermsEnum(ans, tenum, true)
+	ans.SetInitialSeekTerm(prefix)
+	return ans
+}
+
+func (e *prefixTermsEnum) Accept(term []byte) AcceptStatus {
+	if bytes.HasPrefix(term, e.prefix) {
+		return ACCEPT_STATUS_YES
+	}
+	return ACCEPT_STATUS_END
+}
+
+func TestFilteredTermsEnum(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := r.Context().Leaves()[0].reader.Fields().Terms("content")
+
+	// walk all terms, which must come in order
+	var all [][]byte
+	termsEnum := terms.Iterator(nil)
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if term == nil {
+			break
+		}
+		if len(all) > 0 && bytes.Compare(all[len(all)-1], term) >= 0 {
+			t.Errorf("Terms out of order: %v then %v", string(all[len(all)-1]), string(term))
+		}
+		all = append(all, append([]byte(nil), term...))
+	}
+	assertEquals(t, terms.(*FieldReader).numTerms, int64(len(all)))
+
+	var expected, actual []string
+	for _, term := range all {
+		if bytes.HasPrefix(term, []byte("b")) {
+			expected = append(expected, string(term))
+		}
+	}
+	fte := newPrefixTermsEnum(terms.Iterator(nil), []byte("b"))
+	for {
+		term, err := fte.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if term == nil {
+			break
+		}
+		actual = append(actual, string(term))
+	}
+	if len(expected) == 0 || len(expected) != len(actual) {
+		t.Fatalf("Expected %v, but was %v", expected, actual)
+	}
+	for i, v := range expected {
+		assertEquals(t, v, actual[i])
+	}
+}
+
+// Returns the remaining terms of the enum as strings.
+func remainingTerms(t *testing.T, termsEnum TermsEnum) []string {
+	var ans []string
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if term == nil {
+			return ans
+		}
+		ans = append(ans, string(term))
+	}
+}
+
+func TestTermsIntersect(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := r.Context().Leaves()[0].reader.Fields().Terms("content")
+
+	re, err := automaton.NewRegExp("b.*t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiled := automaton.NewCompiledAutomaton(re.ToAutomaton())
+	assertEquals(t, automaton.AUTOMATON_TYPE_NORMAL, compiled.Type)
+
+	var expected []string
+	for _, term := range remainingTerms(t, newPrefixTermsEnum(terms.Iterator(nil), []byte("b"))) {
+		if term[len(term)-1] == 't' {
+			expected = append(expected, term)
+		}
+	}
+	termsEnum, err := terms.Intersect(compiled, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := remainingTerms(t, termsEnum)
+	if len(expected) < 2 || fmt.Sprint(expected) != fmt.Sprint(actual) {
+		t.Fatalf("Expected %v, but was %v", expected, actual)
+	}
+
+	// the terms after the start term only
+	if termsEnum, err = terms.Intersect(compiled, []byte(expected[0])); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, fmt.Sprint(expected[1:]), fmt.Sprint(remainingTerms(t, termsEnum)))
+
+	// only NORMAL automata are intersected
+	if _, err = terms.Intersect(automaton.NewCompiledAutomaton(automaton.MakeString("bat")), nil); err == nil {
+		t.Error("Expected error of intersecting a SINGLE automaton")
+	}
+
+	assertEquals(t, fmt.Sprint([]string{expected[0]}), fmt.Sprint(remainingTerms(t,
+		NewSingleTermsEnum(terms.Iterator(nil), []byte(expected[0])))))
+	assertEquals(t, "[]", fmt.Sprint(remainingTerms(t,
+		NewSingleTermsEnum(terms.Iterator(nil), []byte("nosuchterm")))))
+}
@@ -0,0 +1,107 @@
+package index
+
+import (
+	"log"
+)
+
+// FlushPolicy.java
+
+/*
+FlushPolicy controls when segments are flushed from a RAM resident
+internal data-structure to the IndexWriter's Directory.
+
+Segments are traditionally flushed by:
+
+  - RAM consumption - configured via IndexWriterConfig.SetRAMBufferSizeMB()
+  - Number of RAM resident documents - configured via
+    IndexWriterConfig.SetMaxBufferedDocs()
+  - Number of buffered delete terms/queries - configured via
+    IndexWriterConfig.SetMaxBufferedDeleteTerms()
+
+The IndexWriter consults a provided FlushPolicy to control the
+flushing process. The policy is informed for each added document and
+buffered update, and may mark the ThreadStates of the
+DocumentsWriterFlushControl as flush pending, or request all buffered
+deletes to be applied. The IndexWriter flushes the marked segments as
+soon as their ThreadState is not used for indexing.
+
+The methods of the policy are called with the lock of the
+DocumentsWriterFlushControl held.
+*/
+type FlushPolicy interface {
+	/*
+		Called for each buffered delete term or doc values update. If this
+		method requests all buffered deletes to be applied, the deletes
+		are applied by the calling goroutine.
+	*/
+	onDelete(control *DocumentsWriterFlushControl)
+	/*
+		Called for each document addition on the given ThreadState's
+		DocumentsWriterPerThread. If the ThreadState, or any other one, is
+		marked as flush pending, the segment is flushed by the calling
+		goroutine or the next one obtaining the ThreadState.
+	*/
+	onInsert(control *DocumentsWriterFlushControl, state *ThreadState)
+}
+
+// FlushByRamOrCountsPolicy.java
+
+/*
+Default FlushPolicy implementation that flushes new segments based
+on RAM used and document count depending on the IndexWriter's
+IndexWriterConfig. It also applies pending deletes based on the
+number of buffered delete terms.
+
+  - onDelete() - applies pending delete operations based on the global
+    number of buffered delete terms iff MaxBufferedDeleteTerms() is
+    enabled
+  - onInsert() - flushes either on the number of documents per
+    DocumentsWriterPerThread (numDocsInRAM) or on the global active
+    memory consumption in the current indexing session iff
+    MaxBufferedDocs() or RAMBufferSizeMB() is enabled respectively
+
+All IndexWriterConfig settings are used to mark
+DocumentsWriterPerThread as flush pending during indexing with
+respect to their live updates.
+
+If SetRAMBufferSizeMB() is enabled, the largest ram consuming
+DocumentsWriterPerThread will be marked as pending iff the global
+active RAM consumption is >= the configured max RAM buffer.
+*/
+type FlushByRamOrCountsPolicy struct{}
+
+func newFlushByRamOrCountsPolicy() *FlushByRamOrCountsPolicy {
+	return &FlushByRamOrCountsPolicy{}
+}
+
+func (p *FlushByRamOrCountsPolicy) onDelete(control *DocumentsWriterFlushControl) {
+	config := control.config
+	if maxBufferedDeleteTerms := config.maxBufferedDeleteTerms; maxBufferedDeleteTerms != IWC_DISABLE_AUTO_FLUSH &&
+		control.numDeletes >= maxBufferedDeleteTerms {
+		control.applyAllDeletes = true
+	}
+	if config.ramBufferSizeMB != IWC_DISABLE_AUTO_FLUSH && control.deleteBytes > ramBufferBytes(config) {
+		control.applyAllDeletes = true
+	}
+}
+
+func (p *FlushByRamOrCountsPolicy) onInsert(control *DocumentsWriterFlushControl, state *ThreadState) {
+	config := control.config
+	if config.maxBufferedDocs != IWC_DISABLE_AUTO_FLUSH &&
+		state.dwpt.numDocsInRAM >= config.maxBufferedDocs {
+		// Flush this state by num docs
+		control.setFlushPending(state)
+	} else if config.ramBufferSizeMB != IWC_DISABLE_AUTO_FLUSH {
+		// Flush state by RAM
+		if totalRAM := control.activeBytes + control.deleteBytes; totalRAM >= ramBufferBytes(config) {
+			largest := control.findLargestNonPendingWriter(state)
+			log.Printf("FP: flush total ram: %v, largest writer: %v bytes", totalRAM, largest.bytesUsed)
+			control.setFlushPending(largest)
+		}
+	}
+}
+
+// Returns the RAM buffer size of the config in bytes.
+func ramBufferBytes(config *IndexWriterConfig) int64 {
+	return int64(config.ramBufferSizeMB * 1024 * 1024)
+}
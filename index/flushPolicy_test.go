@@ -0,0 +1,154 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/document"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestFlushByRAM(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	const ramBufferSizeMB = 0.5
+	limit := int64(ramBufferSizeMB * 1024 * 1024)
+	conf := NewIndexWriterConfig().SetRAMBufferSizeMB(ramBufferSizeMB)
+	w, err := NewIndexWriter(d, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	control := w.docWriter.flushControl
+	var flushes int
+	for i := 0; i < 20000; i++ {
+		if err = w.AddDocument(newTestDoc(i)); err != nil {
+			t.Fatal(err)
+		}
+		if w.docWriter.numDocs() == 0 {
+			flushes++
+		}
+		if net := control.netBytes(); net >= limit {
+			t.Fatalf("Buffered documents should be flushed at %v bytes, but use %v", limit, net)
+		}
+	}
+	if flushes == 0 {
+		t.Error("Documents should be flushed by RAM usage")
+	}
+	if control.netBytes() == 0 {
+		t.Error("Buffered documents should use RAM")
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, int64(0), control.netBytes())
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 20000, r.NumDocs())
+	if len(r.Leaves()) < 2 {
+		t.Errorf("Expected several flushed segments, but was %v", len(r.Leaves()))
+	}
+}
+
+func TestFlushByRAMConcurrent(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetRAMBufferSizeMB(0.5).SetMaxThreadStates(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const numGoroutines, numDocs = 4, 2500
+	var wg sync.WaitGroup
+	errs := make(chan error, numGoroutines)
+	for g := 0; g < numGoroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < numDocs; i++ {
+				if err := w.AddDocument(newTestDoc(g*numDocs + i)); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, int64(0), w.docWriter.flushControl.netBytes())
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, numGoroutines*numDocs, r.NumDocs())
+}
+
+func TestFlushByDeleteTerms(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetMaxBufferedDeleteTerms(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	for i := 0; i < 4; i++ {
+		if err = w.AddDocument(append(newTestDoc(i),
+			document.NewNumericDocValuesField("count", int64(i)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.UpdateNumericDocValue(NewTerm("id", "0000"), "count", 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, len(w.pendingUpdates))
+	assertEquals(t, int64(-1), w.segmentInfos.Segments[0].fieldInfosGen)
+	// hitting the limit applies all buffered updates
+	if err = w.UpdateNumericDocValue(NewTerm("id", "0001"), "count", 11); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, len(w.pendingUpdates))
+	assertEquals(t, int64(1), w.segmentInfos.Segments[0].fieldInfosGen)
+	// without flushing the buffered documents
+	if err = w.AddDocument(newTestDoc(4)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err = w.UpdateNumericDocValue(NewTerm("id", "0002"), "count", 12); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertEquals(t, 1, len(w.segmentInfos.Segments))
+	assertEquals(t, 1, w.docWriter.numDocs())
+	assertEquals(t, int64(2), w.segmentInfos.Segments[0].fieldInfosGen)
+}
+
+func TestIndexWriterConfigFlushTriggers(t *testing.T) {
+	conf := NewIndexWriterConfig()
+	assertEquals(t, IWC_DISABLE_AUTO_FLUSH, conf.MaxBufferedDocs())
+	assertEquals(t, IWC_DEFAULT_RAM_BUFFER_SIZE_MB, conf.RAMBufferSizeMB())
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Should not disable both flush triggers")
+			}
+		}()
+		conf.SetRAMBufferSizeMB(IWC_DISABLE_AUTO_FLUSH)
+	}()
+	conf.SetMaxBufferedDocs(10).SetRAMBufferSizeMB(IWC_DISABLE_AUTO_FLUSH)
+	assertEquals(t, float64(IWC_DISABLE_AUTO_FLUSH), conf.RAMBufferSizeMB())
+}
@@ -0,0 +1,442 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+)
+
+// FreqProxTermsWriter.java
+
+/*
+Inverts the indexed fields of the documents of one
+DocumentsWriterPerThread into a TermsHash, and writes the buffered
+postings through the codec's FieldsConsumer when the segment is
+flushed.
+*/
+type FreqProxTermsWriter struct {
+	termsHash *TermsHash
+	fields    map[string]*FreqProxTermsWriterPerField
+}
+
+func newFreqProxTermsWriter(bytesUsed util.Counter) *FreqProxTermsWriter {
+	return &FreqProxTermsWriter{
+		termsHash: newTermsHash(bytesUsed),
+		fields:    make(map[string]*FreqProxTermsWriterPerField),
+	}
+}
+
+// Returns the per-field writer of the given field, creating it if
+// this is the first time the field is inverted.
+func (w *FreqProxTermsWriter) addField(fieldInfo *FieldInfo) *FreqProxTermsWriterPerField {
+	if perField, ok := w.fields[fieldInfo.name]; ok {
+		return perField
+	}
+	perField := newFreqProxTermsWriterPerField(w.termsHash, fieldInfo)
+	w.fields[fieldInfo.name] = perField
+	return perField
+}
+
+// Clears all buffered postings and releases the pools.
+func (w *FreqProxTermsWriter) reset() {
+	w.termsHash.reset()
+	w.fields = make(map[string]*FreqProxTermsWriterPerField)
+}
+
+/*
+Writes the buffered postings of all fields, sorted by field name,
+through the codec's FieldsConsumer.
+*/
+func (w *FreqProxTermsWriter) flush(state SegmentWriteState, consumer FieldsConsumer) error {
+	names := make([]string, 0, len(w.fields))
+	for name, perField := range w.fields {
+		if perField.bytesHash.Size() > 0 {
+			names = append(names, name)
+		}
+	}
+	// Sort by field name
+	sort.Strings(names)
+
+	for _, name := range names {
+		perField := w.fields[name]
+		// Fields may have been indexed for only some documents, so
+		// the flushed FieldInfos decide the index options.
+		fieldInfo := state.fieldInfos.byName[name]
+		if err := perField.flush(fieldInfo, consumer); err != nil {
+			return err
+		}
+	}
+	w.reset()
+	return nil
+}
+
+// FreqProxTermsWriterPerField.java
+
+/*
+Buffers the postings of a single field as vInt streams in the
+TermsHash. Stream 0 holds, for every document but the last one of a
+term, the doc delta shifted left by one, with the low bit set if the
+freq is 1, and the freq otherwise. The last document of each term is
+kept in the postings array until the term is seen in a new document,
+or the segment is flushed.
+
+If positions are indexed, stream 1 holds for every occurrence the
+position delta shifted left by one, with the low bit set if a payload
+follows as its length and bytes, then the start offset delta and the
+length of the occurrence if offsets are indexed.
+*/
+type FreqProxTermsWriterPerField struct {
+	*TermsHashPerField
+
+	postings   *FreqProxPostingsArray
+	hasFreq    bool
+	hasProx    bool
+	hasOffsets bool
+
+	// the document being inverted, and the number of distinct
+	// documents this field was indexed for
+	docID    int
+	lastDoc  int
+	docCount int
+}
+
+func newFreqProxTermsWriterPerField(termsHash *TermsHash, fieldInfo *FieldInfo) *FreqProxTermsWriterPerField {
+	// the buffered postings keep the format the field has when it is
+	// first inverted, a later downgrade of the field only drops data
+	// when flushing
+	ans := &FreqProxTermsWriterPerField{
+		hasFreq:    fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS,
+		hasProx:    fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS,
+		hasOffsets: fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS,
+		lastDoc:    -1,
+	}
+	streamCount := 1
+	if ans.hasProx {
+		streamCount = 2
+	}
+	ans.TermsHashPerField = newTermsHashPerField(termsHash, fieldInfo, streamCount, ans)
+	return ans
+}
+
+// Called before the terms of the field in a new document are added.
+func (w *FreqProxTermsWriterPerField) start(docID int) {
+	w.docID = docID
+	if docID != w.lastDoc {
+		w.lastDoc = docID
+		w.docCount++
+	}
+}
+
+/*
+Adds a term occurrence of the current document, at the given position
+and offsets, with payload if it is not empty.
+*/
+func (w *FreqProxTermsWriterPerField) addTerm(term []byte, position, startOffset, endOffset int, payload []byte) {
+	termID, isNew := w.add(term)
+	switch {
+	case termID == -1:
+		// skipped immense term
+	case isNew:
+		w.newTerm(termID, position, startOffset, endOffset, payload)
+	default:
+		w.addExistingTerm(termID, position, startOffset, endOffset, payload)
+	}
+}
+
+func (w *FreqProxTermsWriterPerField) writeProx(termID, proxCode, position int, payload []byte) {
+	if len(payload) > 0 {
+		w.writeVInt(1, int32(proxCode<<1)|1)
+		w.writeVInt(1, int32(len(payload)))
+		w.writeBytes(1, payload)
+		w.fieldInfo.setStorePayloads()
+	} else {
+		w.writeVInt(1, int32(proxCode<<1))
+	}
+	w.postings.lastPositions[termID] = int32(position)
+}
+
+func (w *FreqProxTermsWriterPerField) writeOffsets(termID, startOffset, endOffset int) {
+	w.writeVInt(1, int32(startOffset)-w.postings.lastOffsets[termID])
+	w.writeVInt(1, int32(endOffset-startOffset))
+	w.postings.lastOffsets[termID] = int32(startOffset)
+}
+
+func (w *FreqProxTermsWriterPerField) newTerm(termID, position, startOffset, endOffset int, payload []byte) {
+	// First time we're seeing this term since the last flush
+	postings := w.postings
+	postings.lastDocIDs[termID] = int32(w.docID)
+	if !w.hasFreq {
+		postings.lastDocCodes[termID] = int32(w.docID)
+	} else {
+		postings.lastDocCodes[termID] = int32(w.docID << 1)
+		postings.termFreqs[termID] = 1
+		if w.hasProx {
+			w.writeProx(termID, position, position, payload)
+			if w.hasOffsets {
+				w.writeOffsets(termID, startOffset, endOffset)
+			}
+		}
+	}
+}
+
+func (w *FreqProxTermsWriterPerField) addExistingTerm(termID, position, startOffset, endOffset int, payload []byte) {
+	postings := w.postings
+	docID := int32(w.docID)
+	if !w.hasFreq {
+		if docID != postings.lastDocIDs[termID] {
+			w.writeVInt(0, postings.lastDocCodes[termID])
+			postings.lastDocCodes[termID] = docID - postings.lastDocIDs[termID]
+			postings.lastDocIDs[termID] = docID
+		}
+	} else if docID != postings.lastDocIDs[termID] {
+		// Term not yet seen in the current doc but previously seen in
+		// other doc(s) since the last flush
+
+		// Now that we know doc freq for previous doc, write it &
+		// lastDocCode
+		if postings.termFreqs[termID] == 1 {
+			w.writeVInt(0, postings.lastDocCodes[termID]|1)
+		} else {
+			w.writeVInt(0, postings.lastDocCodes[termID])
+			w.writeVInt(0, postings.termFreqs[termID])
+		}
+		postings.termFreqs[termID] = 1
+		postings.lastDocCodes[termID] = (docID - postings.lastDocIDs[termID]) << 1
+		postings.lastDocIDs[termID] = docID
+		if w.hasProx {
+			w.writeProx(termID, position, position, payload)
+			if w.hasOffsets {
+				postings.lastOffsets[termID] = 0
+				w.writeOffsets(termID, startOffset, endOffset)
+			}
+		}
+	} else {
+		postings.termFreqs[termID]++
+		if w.hasProx {
+			w.writeProx(termID, position-int(postings.lastPositions[termID]), position, payload)
+			if w.hasOffsets {
+				w.writeOffsets(termID, startOffset, endOffset)
+			}
+		}
+	}
+}
+
+/*
+Walk through all unique text tokens (Posting instances) found in this
+field and serialize them into a single RAM segment.
+*/
+func (w *FreqProxTermsWriterPerField) flush(fieldInfo FieldInfo, consumer FieldsConsumer) (err error) {
+	termsConsumer, err := consumer.AddField(fieldInfo)
+	if err != nil {
+		return err
+	}
+
+	// The flushed FieldInfo may only write freqs, positions or offsets
+	// if all documents indexed them; we may have buffered them
+	// regardless
+	readTermFreq := w.hasFreq
+	readPositions := w.hasProx
+	readOffsets := w.hasOffsets
+	writeTermFreq := fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS
+	writePositions := fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS
+	writeOffsets := fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+
+	termIDs := w.sortPostings()
+	numTerms := w.bytesHash.Size()
+	freq := newByteSliceReader()
+	prox := newByteSliceReader()
+	postings := w.postings
+	var payload []byte
+
+	var sumTotalTermFreq, sumDocFreq int64
+	for i := 0; i < numTerms; i++ {
+		termID := int(termIDs[i])
+		// Get BytesRef
+		text := w.bytesHash.Get(termID)
+
+		postingsConsumer, err := termsConsumer.StartTerm(text)
+		if err != nil {
+			return err
+		}
+
+		w.initReader(freq, termID, 0)
+		if readPositions {
+			w.initReader(prox, termID, 1)
+		}
+
+		docFreq := 0
+		totalTermFreq := int64(0)
+		docID := 0
+
+		for {
+			termFreq := 1
+			if freq.eof() {
+				if postings.lastDocCodes[termID] == -1 {
+					break
+				}
+				// Return last doc
+				docID = int(postings.lastDocIDs[termID])
+				if readTermFreq {
+					termFreq = int(postings.termFreqs[termID])
+				}
+				postings.lastDocCodes[termID] = -1
+			} else {
+				code, err := freq.ReadVInt()
+				if err != nil {
+					return err
+				}
+				if !readTermFreq {
+					docID += int(code)
+				} else {
+					docID += int(uint32(code) >> 1)
+					if (code & 1) == 0 {
+						n, err := freq.ReadVInt()
+						if err != nil {
+							return err
+						}
+						termFreq = int(n)
+					}
+				}
+			}
+
+			docFreq++
+			if !writeTermFreq {
+				err = postingsConsumer.StartDoc(docID, -1)
+			} else {
+				err = postingsConsumer.StartDoc(docID, termFreq)
+			}
+			if err != nil {
+				return err
+			}
+			totalTermFreq += int64(termFreq)
+
+			if readPositions {
+				position, offset := 0, 0
+				for j := 0; j < termFreq; j++ {
+					code, err := prox.ReadVInt()
+					if err != nil {
+						return err
+					}
+					position += int(uint32(code) >> 1)
+					var thisPayload []byte
+					if (code & 1) != 0 {
+						// This position has a payload
+						payloadLength, err := prox.ReadVInt()
+						if err != nil {
+							return err
+						}
+						if cap(payload) < int(payloadLength) {
+							payload = make([]byte, payloadLength)
+						}
+						thisPayload = payload[:payloadLength]
+						if err = prox.ReadBytes(thisPayload); err != nil {
+							return err
+						}
+					}
+
+					startOffset, endOffset := -1, -1
+					if readOffsets {
+						delta, err := prox.ReadVInt()
+						if err != nil {
+							return err
+						}
+						length, err := prox.ReadVInt()
+						if err != nil {
+							return err
+						}
+						offset += int(delta)
+						if writeOffsets {
+							startOffset, endOffset = offset, offset+int(length)
+						}
+					}
+					if writePositions {
+						if err = postingsConsumer.AddPosition(position, thisPayload, startOffset, endOffset); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			if err = postingsConsumer.FinishDoc(); err != nil {
+				return err
+			}
+		}
+
+		if !writeTermFreq {
+			totalTermFreq = -1
+		}
+		if err = termsConsumer.FinishTerm(text, TermStats{docFreq, totalTermFreq}); err != nil {
+			return err
+		}
+		sumTotalTermFreq += totalTermFreq
+		sumDocFreq += int64(docFreq)
+	}
+
+	if !writeTermFreq {
+		sumTotalTermFreq = -1
+	}
+	return termsConsumer.Finish(sumTotalTermFreq, sumDocFreq, w.docCount)
+}
+
+func (w *FreqProxTermsWriterPerField) createPostingsArray(size int) *ParallelPostingsArray {
+	w.postings = newFreqProxPostingsArray(size, w.hasProx, w.hasOffsets)
+	return w.postings.ParallelPostingsArray
+}
+
+func (w *FreqProxTermsWriterPerField) growPostingsArray() *ParallelPostingsArray {
+	w.postings.grow()
+	return w.postings.ParallelPostingsArray
+}
+
+func (w *FreqProxTermsWriterPerField) clearPostingsArray() {
+	w.postings = nil
+}
+
+func (w *FreqProxTermsWriterPerField) bytesPerPosting() int {
+	bytes := PARALLEL_POSTINGS_ARRAY_BYTES_PER_POSTING + 3*util.NUM_BYTES_INT
+	if w.hasProx {
+		bytes += util.NUM_BYTES_INT
+	}
+	if w.hasOffsets {
+		bytes += util.NUM_BYTES_INT
+	}
+	return bytes
+}
+
+// FreqProxTermsWriterPerField.java/FreqProxPostingsArray
+
+type FreqProxPostingsArray struct {
+	*ParallelPostingsArray
+	termFreqs     []int32 // # times this term occurs in the current doc
+	lastDocIDs    []int32 // Last docID where this term occurred
+	lastDocCodes  []int32 // Code for prior doc
+	lastPositions []int32 // Last position where this term occurred
+	lastOffsets   []int32 // Last startOffset where this term occurred
+}
+
+func newFreqProxPostingsArray(size int, writeProx, writeOffsets bool) *FreqProxPostingsArray {
+	ans := &FreqProxPostingsArray{
+		ParallelPostingsArray: newParallelPostingsArray(size),
+		termFreqs:             make([]int32, size),
+		lastDocIDs:            make([]int32, size),
+		lastDocCodes:          make([]int32, size),
+	}
+	if writeProx {
+		ans.lastPositions = make([]int32, size)
+	}
+	if writeOffsets {
+		ans.lastOffsets = make([]int32, size)
+	}
+	return ans
+}
+
+func (arr *FreqProxPostingsArray) grow() {
+	arr.ParallelPostingsArray.grow()
+	arr.termFreqs = growInt32s(arr.termFreqs, arr.size)
+	arr.lastDocIDs = growInt32s(arr.lastDocIDs, arr.size)
+	arr.lastDocCodes = growInt32s(arr.lastDocCodes, arr.size)
+	if arr.lastPositions != nil {
+		arr.lastPositions = growInt32s(arr.lastPositions, arr.size)
+	}
+	if arr.lastOffsets != nil {
+		arr.lastOffsets = growInt32s(arr.lastOffsets, arr.size)
+	}
+}
@@ -0,0 +1,189 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/store"
+	"sort"
+	"strings"
+)
+
+// IndexCommit.java
+
+/*
+Expert: represents a single commit into an index as seen by the
+IndexDeletionPolicy or IndexReader.
+
+Changes to the content of an index are made visible only after the
+writer who made that change commits by writing a new segments file
+(segments_N). This point in time, when the action of writing of a
+new segments file to the directory is completed, is an index commit.
+
+Each index commit point has a unique segments file associated with
+it. The segments file associated with a later index commit point
+would have a larger N.
+*/
+type IndexCommit interface {
+	// Get the segments file (segments_N) associated with this commit point.
+	SegmentsFileName() string
+	// Returns all index files referenced by this commit point.
+	FileNames() []string
+	// Returns the Directory for the index.
+	Directory() store.Directory
+	// Returns the generation (the _N in segments_N) for this IndexCommit.
+	Generation() int64
+	// Returns userData, previously passed to IndexWriter.SetCommitData()
+	// for this commit.
+	UserData() (map[string]string, error)
+	/*
+		Delete this commit point. This only applies when using the
+		commit point in the context of IndexWriter's
+		IndexDeletionPolicy.
+
+		Upon calling this, the writer is notified that this commit point
+		should be deleted.
+
+		Decision that a commit-point should be deleted is taken by the
+		IndexDeletionPolicy in effect and therefore this should only be
+		called by its OnInit() or OnCommit() methods.
+	*/
+	Delete()
+	// Returns true if this commit should be deleted; this is only used
+	// by IndexWriter after invoking the IndexDeletionPolicy.
+	IsDeleted() bool
+}
+
+/*
+An IndexCommit over an explicit set of files, e.g. a file set restored
+from a backup or replicated from another node. No segments.gen lookup
+or directory listing is involved in resolving the commit: the segments
+file is the segments_N of highest generation among the given files.
+*/
+type explicitCommit struct {
+	directory        store.Directory
+	segmentsFileName string
+	files            []string
+	generation       int64
+}
+
+/*
+Creates an IndexCommit over the given files of the directory. Exactly
+the files referenced by the commit must be listed; extra files are
+allowed and ignored. Returns error if no segments_N file is listed.
+*/
+func NewIndexCommitFromFiles(directory store.Directory, files []string) (IndexCommit, error) {
+	gen := LastCommitGeneration(files)
+	if gen == -1 {
+		return nil, errors.New(fmt.Sprintf("no segments* file found in %v: files: %v", directory, files))
+	}
+	names := make([]string, len(files))
+	copy(names, files)
+	sort.Strings(names)
+	var segmentsFileName string
+	for _, name := range names {
+		if strings.HasPrefix(name, INDEX_FILENAME_SEGMENTS) &&
+			name != INDEX_FILENAME_SEGMENTS_GEN &&
+			GenerationFromSegmentsFileName(name) == gen {
+			segmentsFileName = name
+			break
+		}
+	}
+	return &explicitCommit{directory, segmentsFileName, names, gen}, nil
+}
+
+func (c *explicitCommit) SegmentsFileName() string {
+	return c.segmentsFileName
+}
+
+func (c *explicitCommit) FileNames() []string {
+	return c.files
+}
+
+func (c *explicitCommit) Directory() store.Directory {
+	return c.directory
+}
+
+func (c *explicitCommit) Generation() int64 {
+	return c.generation
+}
+
+// Reads the user data from the segments file of the commit.
+func (c *explicitCommit) UserData() (map[string]string, error) {
+	sis := &SegmentInfos{}
+	if err := sis.Read(c.directory, c.segmentsFileName); err != nil {
+		return nil, err
+	}
+	return sis.userData, nil
+}
+
+func (c *explicitCommit) Delete() {
+	panic("This IndexCommit does not support deletions")
+}
+
+func (c *explicitCommit) IsDeleted() bool {
+	return false
+}
+
+func (c *explicitCommit) String() string {
+	return fmt.Sprintf("IndexCommit(%v:%v)", c.directory, c.segmentsFileName)
+}
+
+// StandardDirectoryReader.java/ReaderCommit
+
+// The IndexCommit a StandardDirectoryReader was opened on.
+type readerCommit struct {
+	directory        store.Directory
+	segmentsFileName string
+	files            []string
+	generation       int64
+	userData         map[string]string
+	segmentCount     int
+}
+
+func newReaderCommit(infos *SegmentInfos, dir store.Directory) *readerCommit {
+	return &readerCommit{
+		directory:        dir,
+		segmentsFileName: infos.SegmentsFileName(),
+		files:            infos.Files(dir, true),
+		generation:       infos.generation,
+		userData:         infos.userData,
+		segmentCount:     len(infos.Segments),
+	}
+}
+
+func (c *readerCommit) SegmentsFileName() string {
+	return c.segmentsFileName
+}
+
+func (c *readerCommit) FileNames() []string {
+	return c.files
+}
+
+func (c *readerCommit) Directory() store.Directory {
+	return c.directory
+}
+
+func (c *readerCommit) Generation() int64 {
+	return c.generation
+}
+
+func (c *readerCommit) UserData() (map[string]string, error) {
+	return c.userData, nil
+}
+
+// Returns the number of segments in the index of this commit.
+func (c *readerCommit) SegmentCount() int {
+	return c.segmentCount
+}
+
+func (c *readerCommit) Delete() {
+	panic("This IndexCommit does not support deletions")
+}
+
+func (c *readerCommit) IsDeleted() bool {
+	return false
+}
+
+func (c *readerCommit) String() string {
+	return fmt.Sprintf("DirectoryReader.ReaderCommit(%v)", c.segmentsFileName)
+}
@@ -0,0 +1,93 @@
+package index
+
+// IndexDeletionPolicy.java
+
+/*
+Expert: policy for deletion of stale index commits.
+
+Implement this interface, and set it with
+IndexWriterConfig.SetIndexDeletionPolicy(), to customize when older
+point-in-time commits are deleted from the index directory. The
+default deletion policy is KeepOnlyLastCommitDeletionPolicy, which
+always removes old commits as soon as a new commit is done.
+
+One expected use case for this (and the reason why it was first
+created) is to work around problems with an index directory accessed
+via filesystems like NFS because NFS does not provide the "delete on
+last close" semantics that Lucene's "point in time" search normally
+relies on. By implementing a custom deletion policy, such as "a
+commit is only removed once it has been stale for more than X
+minutes", you can give your readers time to refresh to the new
+commit before IndexWriter removes the old commits. Note that doing so
+will increase the storage requirements of the index.
+*/
+type IndexDeletionPolicy interface {
+	/*
+		This is called once when a writer is first instantiated to give
+		the policy a chance to remove old commit points.
+
+		The writer locates all index commits present in the index
+		directory and calls this method. The policy may choose to delete
+		some of the commit points, doing so by calling method Delete()
+		of IndexCommit.
+
+		Note: the last commit point is the most recent one, i.e. the
+		"front index state". Be careful not to delete it, unless you know
+		for sure what you are doing, and unless you can afford to lose
+		the index content while doing that.
+
+		commits is a list of IndexCommits, sorted by age (the 0th one is
+		the oldest commit).
+	*/
+	OnInit(commits []IndexCommit) error
+	/*
+		This is called each time the writer completed a commit. This
+		gives the policy a chance to remove old commit points with each
+		commit.
+
+		The policy may now choose to delete old commit points by calling
+		method Delete() of IndexCommit.
+
+		This method is only called when Commit() or Close() is called,
+		or possibly not at all if Rollback() is called.
+
+		Note: the last commit point is the most recent one, i.e. the
+		"front index state". Be careful not to delete it, unless you know
+		for sure what you are doing, and unless you can afford to lose
+		the index content while doing that.
+
+		commits is a list of IndexCommits, sorted by age (the 0th one is
+		the oldest commit).
+	*/
+	OnCommit(commits []IndexCommit) error
+}
+
+// KeepOnlyLastCommitDeletionPolicy.java
+
+/*
+This IndexDeletionPolicy implementation that keeps only the most
+recent commit and immediately removes all prior commits after a new
+commit is done. This is the default deletion policy.
+*/
+type KeepOnlyLastCommitDeletionPolicy struct{}
+
+// Sole constructor.
+func NewKeepOnlyLastCommitDeletionPolicy() *KeepOnlyLastCommitDeletionPolicy {
+	return &KeepOnlyLastCommitDeletionPolicy{}
+}
+
+// Deletes all commits except the most recent one.
+func (p *KeepOnlyLastCommitDeletionPolicy) OnInit(commits []IndexCommit) error {
+	// Note that len(commits) should normally be 1:
+	return p.OnCommit(commits)
+}
+
+// Deletes all commits except the most recent one.
+func (p *KeepOnlyLastCommitDeletionPolicy) OnCommit(commits []IndexCommit) error {
+	// Note that len(commits) should normally be 2 (if not called by
+	// OnInit above):
+	for i := 0; i < len(commits)-1; i++ {
+		commits[i].Delete()
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package index
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/store"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Keeps all commits, and records the generations it was given.
+type keepAllTestDeletionPolicy struct {
+	onInit, onCommit []int64
+}
+
+func generations(commits []IndexCommit) []int64 {
+	ans := make([]int64, len(commits))
+	for i, commit := range commits {
+		ans[i] = commit.Generation()
+	}
+	return ans
+}
+
+func (p *keepAllTestDeletionPolicy) OnInit(commits []IndexCommit) error {
+	p.onInit = generations(commits)
+	return nil
+}
+
+func (p *keepAllTestDeletionPolicy) OnCommit(commits []IndexCommit) error {
+	p.onCommit = generations(commits)
+	return nil
+}
+
+// Returns the number of segments_N files in the directory.
+func countCommits(t *testing.T, d store.Directory) int {
+	files, err := d.ListAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for _, file := range files {
+		if strings.HasPrefix(file, INDEX_FILENAME_SEGMENTS+"_") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestKeepOnlyLastCommitDeletionPolicy(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 2)
+	for i := 0; i < 3; i++ {
+		addTestDocs(t, w, 3)
+		if err := w.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, 1, countCommits(t, d))
+	}
+	if err := w.ForceMerge(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// the merged away segments are removed with their commit
+	infos := &SegmentInfos{}
+	if err := infos.ReadAll(d); err != nil {
+		t.Fatal(err)
+	}
+	files, err := d.ListAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, len(infos.Files(d, true))+1, len(files)) // segments.gen
+}
+
+func TestIndexDeletionPolicy(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	policy := &keepAllTestDeletionPolicy{}
+	conf := NewIndexWriterConfig().SetMaxBufferedDocs(2).SetIndexDeletionPolicy(policy)
+	w, err := NewIndexWriter(d, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, len(policy.onInit))
+	for i := 0; i < 3; i++ {
+		addTestDocs(t, w, 3)
+		if err = w.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertEquals(t, "[1 2 3]", fmt.Sprint(policy.onCommit))
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// all commits can still be opened
+	assertEquals(t, 3, countCommits(t, d))
+	files, err := d.ListAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := NewIndexCommitFromFiles(d, []string{"segments_1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sis := &SegmentInfos{}
+	if err = sis.Read(d, first.SegmentsFileName()); err != nil {
+		t.Fatal(err)
+	}
+	if first, err = NewIndexCommitFromFiles(d, append(sis.Files(d, false), first.SegmentsFileName())); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReaderFromCommit(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 3, r.MaxDoc())
+
+	// the default policy deletes all but the last commit on init
+	w, err = NewIndexWriter(d, NewIndexWriterConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, countCommits(t, d))
+	policy = &keepAllTestDeletionPolicy{}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	w, err = NewIndexWriter(d, NewIndexWriterConfig().SetIndexDeletionPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[3]", fmt.Sprint(policy.onInit))
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	remaining, err := d.ListAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) >= len(files) {
+		t.Errorf("Files of deleted commits should be removed: %v", remaining)
+	}
+}
@@ -0,0 +1,386 @@
+package index
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/store"
+	"log"
+	"sort"
+	"strings"
+)
+
+// IndexFileDeleter.java
+
+/*
+This class keeps track of each SegmentInfos instance that is still
+"live", either because it corresponds to a segments_N file in the
+Directory (a "commit", i.e. a committed SegmentInfos) or because it's
+an in-memory SegmentInfos that a writer is actively updating but has
+not yet committed. This class uses simple reference counting to map
+the live SegmentInfos instances to individual files in the Directory.
+
+The same directory file may be referenced by more than one
+IndexCommit, i.e. more than one SegmentInfos. Therefore we count how
+many commits reference each file. When all the commits referencing a
+certain file have been deleted, the refcount for that file becomes
+zero, and the file is deleted.
+
+A separate deletion policy interface (IndexDeletionPolicy) is
+consulted on creation (OnInit) and once per commit (OnCommit), to
+decide when a commit should be removed.
+
+It is the business of the IndexDeletionPolicy to choose when to
+delete commit points. The actual mechanics of file deletion,
+retrying, etc, derived from the deletion of commit points is the
+business of the IndexFileDeleter.
+
+The current default deletion policy is
+KeepOnlyLastCommitDeletionPolicy, which removes all prior commits
+when a new commit has completed.
+
+Note that you must hold the IndexWriter lock when invoking any of
+the methods, except the constructor.
+*/
+type IndexFileDeleter struct {
+	directory store.Directory
+	policy    IndexDeletionPolicy
+
+	// Files that we tried to delete but failed (likely because they
+	// are open and we are running on Windows), so we will retry them
+	// again later:
+	deletable []string
+	// Reference count for all files in the index. Counts how many
+	// existing commits reference a file.
+	refCounts map[string]int
+	// Holds all commits (segments_N) currently in the index. This will
+	// have just 1 commit if you are using the default delete policy
+	// (KeepOnlyLastCommitDeletionPolicy). Other policies may leave
+	// commit points live for longer in which case this list would be
+	// longer than 1.
+	commits []*commitPoint
+	// Holds files we had incref'd from the previous non-commit
+	// checkpoint:
+	lastFiles []string
+	// Commits that the IndexDeletionPolicy have decided to delete:
+	commitsToDelete []*commitPoint
+}
+
+/*
+Initialize the deleter: find all previous commits in the Directory,
+incref the files they reference, call the policy to let it delete
+commits. This will remove any files not referenced by any of the
+commits.
+*/
+func newIndexFileDeleter(directory store.Directory, policy IndexDeletionPolicy,
+	segmentInfos *SegmentInfos) (*IndexFileDeleter, error) {
+	log.Printf("IFD: init: current segments file is \"%v\"; deletionPolicy=%T",
+		segmentInfos.SegmentsFileName(), policy)
+	fd := &IndexFileDeleter{
+		directory: directory,
+		policy:    policy,
+		refCounts: make(map[string]int),
+	}
+
+	// First pass: walk the files and initialize our ref counts:
+	files, err := directory.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, fileName := range files {
+		if !isIndexFile(fileName) || fileName == INDEX_FILENAME_SEGMENTS_GEN {
+			continue
+		}
+		// Add this file to refCounts with initial count 0:
+		fd.refCounts[fileName] += 0
+		if !strings.HasPrefix(fileName, INDEX_FILENAME_SEGMENTS) {
+			continue
+		}
+		// This is a commit (segments or segments_N), and it's valid
+		// (<= the max gen). Load it, then incref all files it refers
+		// to:
+		log.Printf("IFD: init: load commit \"%v\"", fileName)
+		sis := &SegmentInfos{}
+		if err = sis.Read(directory, fileName); err != nil {
+			if GenerationFromSegmentsFileName(fileName) <= segmentInfos.lastGeneration {
+				return nil, err
+			}
+			// Most likely we are opening an index that has an aborted
+			// "future" commit, so suppress the error in this case
+			log.Printf("IFD: init: hit error when loading commit \"%v\"; skipping this commit point: %v",
+				fileName, err)
+			continue
+		}
+		commit := newCommitPoint(&fd.commitsToDelete, directory, sis)
+		fd.commits = append(fd.commits, commit)
+		fd.incRefInfos(sis, true)
+	}
+
+	// Now delete anything with ref count at 0. These are presumably
+	// abandoned files eg due to crash of IndexWriter.
+	for fileName, count := range fd.refCounts {
+		if count == 0 {
+			log.Printf("IFD: init: removing unreferenced file \"%v\"", fileName)
+			delete(fd.refCounts, fileName)
+			fd.deleteFile(fileName)
+		}
+	}
+
+	// Finally, give policy a chance to remove things on startup:
+	sort.Sort(commitPointsByGeneration(fd.commits))
+	if err = policy.OnInit(fd.indexCommits()); err != nil {
+		return nil, err
+	}
+
+	// Always protect the incoming segmentInfos since sometime it may
+	// not be the most recent commit
+	fd.checkpoint(segmentInfos, false)
+	fd.deleteCommits()
+	return fd, nil
+}
+
+// Returns true if the file is written by an IndexWriter.
+func isIndexFile(fileName string) bool {
+	return CODEC_FILE_PATTERN.MatchString(fileName) ||
+		strings.HasPrefix(fileName, INDEX_FILENAME_SEGMENTS)
+}
+
+// Returns the commits as IndexCommits, for the IndexDeletionPolicy.
+func (fd *IndexFileDeleter) indexCommits() []IndexCommit {
+	ans := make([]IndexCommit, len(fd.commits))
+	for i, commit := range fd.commits {
+		ans[i] = commit
+	}
+	return ans
+}
+
+/*
+Remove the CommitPoints in the commitsToDelete list by DecRef'ing all
+files from each SegmentInfos.
+*/
+func (fd *IndexFileDeleter) deleteCommits() {
+	if len(fd.commitsToDelete) == 0 {
+		return
+	}
+	// First decref all files that had been referred to by the
+	// now-deleted commits:
+	for _, commit := range fd.commitsToDelete {
+		log.Printf("IFD: deleteCommits: now decRef commit \"%v\"", commit.segmentsFileName)
+		fd.decRefFiles(commit.files)
+	}
+	fd.commitsToDelete = nil
+
+	// Now compact commits to remove deleted ones (preserving the
+	// sort):
+	commits := fd.commits[:0]
+	for _, commit := range fd.commits {
+		if !commit.deleted {
+			commits = append(commits, commit)
+		}
+	}
+	fd.commits = commits
+}
+
+/*
+Writer calls this when it has hit an error and had to roll back, to
+tell us that there may now be unreferenced files in the filesystem.
+So we re-list the filesystem and delete such files.
+*/
+func (fd *IndexFileDeleter) refresh() error {
+	files, err := fd.directory.ListAll()
+	if err != nil {
+		return err
+	}
+	for _, fileName := range files {
+		if !isIndexFile(fileName) || fileName == INDEX_FILENAME_SEGMENTS_GEN {
+			continue
+		}
+		if _, ok := fd.refCounts[fileName]; !ok {
+			// Unreferenced file, so remove it
+			log.Printf("IFD: refresh: removing unreferenced file \"%v\"", fileName)
+			fd.deleteFile(fileName)
+		}
+	}
+	return nil
+}
+
+// Releases the files of the last non-commit checkpoint.
+func (fd *IndexFileDeleter) close() {
+	// DecRef old files from the last checkpoint, if any:
+	fd.decRefFiles(fd.lastFiles)
+	fd.lastFiles = nil
+	fd.deletePendingFiles()
+}
+
+// Retries the deletion of the files which could not be deleted before.
+func (fd *IndexFileDeleter) deletePendingFiles() {
+	deletable := fd.deletable
+	fd.deletable = nil
+	for _, fileName := range deletable {
+		log.Printf("IFD: delete pending file %v", fileName)
+		fd.deleteFile(fileName)
+	}
+}
+
+/*
+Writer calls this when it has made a "consistent change" to the index,
+meaning new files are written to the index and the in-memory
+SegmentInfos have been modified to point to those files.
+
+This may or may not be a commit (segments_N may or may not have been
+written).
+
+We simply incref the files referenced by the new SegmentInfos and
+decref the files we had previously seen (if any).
+
+If this is a commit, we also call the policy to give it a chance to
+remove other commits. If any commits are removed, we decref their
+files as well.
+*/
+func (fd *IndexFileDeleter) checkpoint(segmentInfos *SegmentInfos, isCommit bool) error {
+	log.Printf("IFD: now checkpoint \"%v\" [%v segments; isCommit = %v]",
+		segmentInfos.SegmentsFileName(), len(segmentInfos.Segments), isCommit)
+
+	// Try again now to delete any previously un-deletable files (because
+	// they were in use, on Windows):
+	fd.deletePendingFiles()
+
+	// Incref the files:
+	fd.incRefInfos(segmentInfos, isCommit)
+
+	if isCommit {
+		// Append to our commits list:
+		fd.commits = append(fd.commits, newCommitPoint(&fd.commitsToDelete, fd.directory, segmentInfos))
+		// Tell policy so it can remove commits:
+		if err := fd.policy.OnCommit(fd.indexCommits()); err != nil {
+			return err
+		}
+		// Decref files for commits that were deleted by the policy:
+		fd.deleteCommits()
+	} else {
+		// DecRef old files from the last checkpoint, if any:
+		fd.decRefFiles(fd.lastFiles)
+		// Save files so we can decr on next checkpoint/commit:
+		fd.lastFiles = segmentInfos.Files(fd.directory, false)
+	}
+	return nil
+}
+
+func (fd *IndexFileDeleter) incRefInfos(segmentInfos *SegmentInfos, isCommit bool) {
+	// If this is a commit point, also incRef the segments_N file:
+	fd.incRefFiles(segmentInfos.Files(fd.directory, isCommit))
+}
+
+func (fd *IndexFileDeleter) incRefFiles(files []string) {
+	for _, file := range files {
+		fd.refCounts[file]++
+	}
+}
+
+func (fd *IndexFileDeleter) decRefFiles(files []string) {
+	for _, file := range files {
+		fd.decRef(file)
+	}
+}
+
+func (fd *IndexFileDeleter) decRef(fileName string) {
+	count, ok := fd.refCounts[fileName]
+	if !ok || count == 0 {
+		panic(fmt.Sprintf("fileName=%v, refCount=0", fileName))
+	}
+	if count == 1 {
+		// This file is no longer referenced by any past commit points
+		// nor by the in-memory SegmentInfos:
+		delete(fd.refCounts, fileName)
+		fd.deleteFile(fileName)
+	} else {
+		fd.refCounts[fileName] = count - 1
+	}
+}
+
+func (fd *IndexFileDeleter) deleteFile(fileName string) {
+	log.Printf("IFD: delete \"%v\"", fileName)
+	if err := fd.directory.DeleteFile(fileName); err != nil && fd.directory.FileExists(fileName) {
+		// Some operating systems (e.g. Windows) don't permit a file to
+		// be deleted while it is opened for read (e.g. by another
+		// process or thread). So we assume that when a delete fails it
+		// is because the file is open in another process, and queue the
+		// file for subsequent deletion.
+		log.Printf("IFD: unable to remove file \"%v\": %v; Will re-try later.", fileName, err)
+		fd.deletable = append(fd.deletable, fileName)
+	}
+}
+
+// IndexFileDeleter.java/CommitPoint
+
+// Holds details for each commit point. This class is also passed to
+// the deletion policy.
+type commitPoint struct {
+	files            []string
+	segmentsFileName string
+	deleted          bool
+	directory        store.Directory
+	commitsToDelete  *[]*commitPoint
+	generation       int64
+	userData         map[string]string
+	segmentCount     int
+}
+
+func newCommitPoint(commitsToDelete *[]*commitPoint, directory store.Directory,
+	segmentInfos *SegmentInfos) *commitPoint {
+	return &commitPoint{
+		files:            segmentInfos.Files(directory, true),
+		segmentsFileName: segmentInfos.SegmentsFileName(),
+		directory:        directory,
+		commitsToDelete:  commitsToDelete,
+		generation:       segmentInfos.lastGeneration,
+		userData:         segmentInfos.userData,
+		segmentCount:     len(segmentInfos.Segments),
+	}
+}
+
+func (c *commitPoint) SegmentsFileName() string {
+	return c.segmentsFileName
+}
+
+func (c *commitPoint) FileNames() []string {
+	return c.files
+}
+
+func (c *commitPoint) Directory() store.Directory {
+	return c.directory
+}
+
+func (c *commitPoint) Generation() int64 {
+	return c.generation
+}
+
+func (c *commitPoint) UserData() (map[string]string, error) {
+	return c.userData, nil
+}
+
+// Returns the number of segments in the index of this commit.
+func (c *commitPoint) SegmentCount() int {
+	return c.segmentCount
+}
+
+// Called only by the deletion policy, to remove this commit point
+// from the index.
+func (c *commitPoint) Delete() {
+	if !c.deleted {
+		c.deleted = true
+		*c.commitsToDelete = append(*c.commitsToDelete, c)
+	}
+}
+
+func (c *commitPoint) IsDeleted() bool {
+	return c.deleted
+}
+
+func (c *commitPoint) String() string {
+	return fmt.Sprintf("IndexFileDeleter.CommitPoint(%v)", c.segmentsFileName)
+}
+
+type commitPointsByGeneration []*commitPoint
+
+func (a commitPointsByGeneration) Len() int           { return len(a) }
+func (a commitPointsByGeneration) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a commitPointsByGeneration) Less(i, j int) bool { return a[i].generation < a[j].generation }
@@ -0,0 +1,1636 @@
+package index
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"log"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IndexWriter.java
+
+/*
+An IndexWriter creates and maintains an index.
+
+The OpenMode option of IndexWriterConfig determines whether a new
+index is created, or whether an existing index is opened. Note that
+you can open an index with OPEN_MODE_CREATE even while readers are
+using the index. The old readers will continue to search the "point
+in time" snapshot they had opened, and won't see the newly created
+index until they re-open. If OPEN_MODE_CREATE_OR_APPEND is used
+IndexWriter will create a new index if there is not already an index
+at the provided path and otherwise open the existing index.
+
+In either case, documents are added with AddDocument(). Added
+documents are buffered in memory and periodically flushed to the
+Directory as new segments. Changes are not visible to readers until
+Commit() or Close() is called, which writes a new segments_N file.
+
+IndexWriter is safe for concurrent use: AddDocument() may be called
+from multiple goroutines, each of which buffers documents in its own
+segment, without holding a global lock.
+
+NOTE: the write lock is not obtained yet, so it's up to the
+application to ensure only one IndexWriter is open on a directory at
+any time.
+*/
+type IndexWriter struct {
+	sync.Locker
+	// serializes commits and close
+	commitLock sync.Locker
+
+	directory store.Directory
+	config    *IndexWriterConfig
+	codec     Codec
+
+	segmentInfos *SegmentInfos
+	fieldNumbers *fieldNumbers
+	docWriter    *DocumentsWriter
+	deleter      *IndexFileDeleter
+
+	// increments every time a change is completed
+	changeCount int64
+	// last changeCount that was committed
+	lastCommitChangeCount int64
+	// the segments of the last commit, restored by Rollback()
+	rollbackSegments []SegmentInfoPerCommit
+
+	// the commit started by PrepareCommit(), the changeCount it
+	// contains, and the files it references
+	pendingCommit            *SegmentInfos
+	pendingCommitChangeCount int64
+	filesToCommit            []string
+
+	mergePolicy    MergePolicy
+	mergeScheduler MergeScheduler
+	// merges registered but not started yet
+	pendingMerges []*OneMerge
+	// merges currently running
+	runningMerges map[*OneMerge]bool
+	// names of the segments being merged
+	mergingSegments map[string]bool
+	// names of the segments to merge by ForceMerge(), true if the
+	// segment existed when ForceMerge() was called
+	segmentsToMerge map[string]bool
+	// signaled, with the IndexWriter lock, when a merge finishes
+	mergeFinished *sync.Cond
+
+	// deletes and doc values updates not written to the segments yet,
+	// in order
+	pendingDeletes []*termDelete
+	pendingUpdates []*docValuesUpdate
+
+	// true once a near real time reader was opened, after which merged
+	// segments are warmed
+	poolReaders bool
+	// readers of warmed merged segments, by segment name, handed over
+	// to the next near real time reader
+	warmedReaders map[string]*SegmentReader
+
+	closed bool
+}
+
+// IndexWriter.java/IndexReaderWarmer
+
+/*
+If OpenDirectoryReaderFromWriter() has been called (ie, this writer
+is in near real-time mode), then after a merge completes, this
+interface is invoked to warm the reader on the newly merged segment,
+before the merge commits. This is not required for near real-time
+search, but will reduce search latency on opening a new near
+real-time reader after a merge completes.
+
+NOTE: Warm() is called before any deletes have been carried over to
+the merged segment.
+*/
+type IndexReaderWarmer interface {
+	Warm(reader AtomicReader) error
+}
+
+/*
+Constructs a new IndexWriter per the settings given in conf. Note
+that the passed in IndexWriterConfig is privately cloned; if you need
+to make subsequent "live" changes to the configuration, there is no
+way to do so yet.
+*/
+func NewIndexWriter(d store.Directory, conf *IndexWriterConfig) (w *IndexWriter, err error) {
+	clone := *conf
+	w = &IndexWriter{
+		Locker:          &sync.Mutex{},
+		commitLock:      &sync.Mutex{},
+		directory:       d,
+		config:          &clone,
+		codec:           clone.codec,
+		segmentInfos:    &SegmentInfos{},
+		fieldNumbers:    newFieldNumbers(),
+		mergePolicy:     clone.mergePolicy,
+		mergeScheduler:  clone.mergeScheduler,
+		runningMerges:   make(map[*OneMerge]bool),
+		mergingSegments: make(map[string]bool),
+		warmedReaders:   make(map[string]*SegmentReader),
+		segmentsToMerge: make(map[string]bool),
+	}
+	w.mergeFinished = sync.NewCond(w.Locker)
+	w.docWriter = newDocumentsWriter(w, d, w.codec)
+	w.mergePolicy.SetIndexWriter(w)
+
+	mode := conf.openMode
+	var create bool
+	switch mode {
+	case OPEN_MODE_CREATE:
+		create = true
+	case OPEN_MODE_APPEND:
+		create = false
+	default:
+		// CREATE_OR_APPEND - create only if an index does not exist
+		create = !indexExists(d)
+	}
+
+	if create {
+		// Try to read first. This is to allow create against an index
+		// that's currently open for searching. In this case we write
+		// the next segments_N file with no segments:
+		if err = w.segmentInfos.ReadAll(d); err != nil {
+			// Likely this means it's a fresh directory
+			log.Printf("IW: no existing index to overwrite: %v", err)
+			w.segmentInfos.generation, w.segmentInfos.lastGeneration = -1, -1
+		}
+		w.segmentInfos.Clear()
+		// Record that we have a change (zero out all segments) pending:
+		w.changed()
+	} else {
+		if err = w.segmentInfos.ReadAll(d); err != nil {
+			return nil, err
+		}
+		// Record that we have a clean, committed state
+		w.lastCommitChangeCount = w.changeCount
+	}
+	w.rollbackSegments = append([]SegmentInfoPerCommit(nil), w.segmentInfos.Segments...)
+
+	// Default deleter is KeepOnlyLastCommitDeletionPolicy:
+	if w.deleter, err = newIndexFileDeleter(d, conf.delPolicy, w.segmentInfos); err != nil {
+		return nil, err
+	}
+
+	if err = w.loadFieldNumbers(); err != nil {
+		return nil, err
+	}
+	log.Printf("IW: init: create=%v\n%v", create, w.config)
+	return w, nil
+}
+
+// Returns true if an index likely exists at the specified directory.
+func indexExists(d store.Directory) bool {
+	files, err := d.ListAll()
+	if err != nil {
+		return false
+	}
+	return LastCommitGeneration(files) != -1
+}
+
+/*
+Loads the global field number map from the field infos of all
+segments, so fields keep their numbers in newly flushed segments.
+*/
+func (w *IndexWriter) loadFieldNumbers() error {
+	for _, info := range w.segmentInfos.Segments {
+		fis, err := w.readFieldInfos(info.info)
+		if err != nil {
+			return err
+		}
+		if err = w.addFieldNumbers(fis); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Registers the field numbers and doc values types of the given
+// FieldInfos with the global field numbers.
+func (w *IndexWriter) addFieldNumbers(fis FieldInfos) error {
+	for _, fi := range fis.values {
+		w.fieldNumbers.addOrGet(fi.name, fi.number)
+		if fi.docValueType != 0 {
+			if err := w.fieldNumbers.setDocValuesType(fi.name, fi.docValueType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reads the FieldInfos of the given segment, from its compound file
+// if necessary.
+func (w *IndexWriter) readFieldInfos(info SegmentInfo) (fis FieldInfos, err error) {
+	var cfsDir store.Directory
+	if info.isCompoundFile {
+		cfsDir, err = store.NewCompoundFileDirectory(info.dir,
+			util.SegmentFileName(info.name, "", store.COMPOUND_FILE_EXTENSION),
+			store.IO_CONTEXT_READONCE, false)
+		if err != nil {
+			return fis, err
+		}
+		defer func() {
+			if e := cfsDir.Close(); err == nil {
+				err = e
+			}
+		}()
+	} else {
+		cfsDir = info.dir
+	}
+	return info.codec.ReadFieldInfos(cfsDir, info.name, "", store.IO_CONTEXT_READONCE)
+}
+
+// Called whenever the SegmentInfos has been updated.
+func (w *IndexWriter) changed() {
+	w.changeCount++
+	w.segmentInfos.changed()
+}
+
+// Returns true if the writer is closed.
+func (w *IndexWriter) isClosed() bool {
+	w.Lock()
+	defer w.Unlock()
+	return w.closed
+}
+
+/*
+Called whenever the SegmentInfos has been updated and the index files
+referenced exist (correctly) in the index directory. Called with the
+IndexWriter lock held.
+*/
+func (w *IndexWriter) checkpoint() {
+	w.changed()
+	// only commits consult the IndexDeletionPolicy, which may fail
+	w.deleter.checkpoint(w.segmentInfos, false)
+}
+
+func (w *IndexWriter) newSegmentName() string {
+	w.Lock()
+	defer w.Unlock()
+	w.segmentInfos.counter++
+	w.changeCount++
+	return "_" + strconv.FormatInt(int64(w.segmentInfos.counter-1), 36)
+}
+
+func (w *IndexWriter) ensureOpen() error {
+	if w.closed {
+		return errors.New("this IndexWriter is closed")
+	}
+	return nil
+}
+
+// Returns the Directory used by this index.
+func (w *IndexWriter) Directory() store.Directory {
+	return w.directory
+}
+
+/*
+Returns a near real time reader on the in-memory state of the writer,
+without a commit. The buffered documents are flushed to new segments
+first, and the pending deletes and doc values updates are applied if
+applyAllDeletes is true; if false, they may or may not be visible,
+depending on whether they were applied already.
+
+This is the same as OpenDirectoryReaderFromWriter(w, applyAllDeletes).
+*/
+func (w *IndexWriter) GetReader(applyAllDeletes bool) (DirectoryReader, error) {
+	r, err := w.getReader(nil, applyAllDeletes)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+/*
+Returns a near real time reader on the current segments, reusing the
+SegmentReaders of oldReaders for the segments which did not change.
+See GetReader().
+*/
+func (w *IndexWriter) getReader(oldReaders []IndexReader, applyAllDeletes bool) (*StandardDirectoryReader, error) {
+	w.commitLock.Lock()
+	defer w.commitLock.Unlock()
+	if err := w.ensureOpenLocked(); err != nil {
+		return nil, err
+	}
+	log.Print("IW: flush at getReader")
+	if err := w.docWriter.flushAllThreads(false); err != nil {
+		return nil, err
+	}
+	infos, warmed, err := w.nrtSegmentInfos(applyAllDeletes)
+	if err != nil {
+		return nil, err
+	}
+	// the warmed readers of merged segments are shared like old ones
+	readers := append(append([]IndexReader(nil), oldReaders...), warmed...)
+	r, err := openStandardDirectoryReaderFrom(w.directory, *infos, readers, DEFAULT_TERMS_INDEX_DIVISOR)
+	for _, reader := range warmed {
+		reader.decRef()
+	}
+	if err != nil {
+		w.decRefDeleter(infos)
+		return nil, err
+	}
+	r.writer, r.applyAllDeletes = w, applyAllDeletes
+	log.Printf("IW: return reader version=%v reader=%v", r.Version(), r)
+
+	// the newly flushed segments may need merging
+	if err = w.maybeMerge(MERGE_TRIGGER_FULL_FLUSH, -1); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+/*
+Returns a copy of the current segments for a near real time reader,
+whose files are protected from deletion until the reader is closed,
+and the readers of the warmed merged segments among them, which the
+caller must release.
+*/
+func (w *IndexWriter) nrtSegmentInfos(applyAllDeletes bool) (*SegmentInfos, []IndexReader, error) {
+	w.Lock()
+	defer w.Unlock()
+	w.poolReaders = true
+	if applyAllDeletes {
+		names := make([]string, len(w.segmentInfos.Segments))
+		for i, info := range w.segmentInfos.Segments {
+			names[i] = info.info.name
+		}
+		if err := w.applyDeletesAndUpdates(names, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+	infos := w.segmentInfos.clone()
+	w.deleter.incRefInfos(infos, false)
+	var warmed []IndexReader
+	for _, info := range infos.Segments {
+		if reader, ok := w.warmedReaders[info.info.name]; ok {
+			warmed = append(warmed, reader)
+			delete(w.warmedReaders, info.info.name)
+		}
+	}
+	return infos, warmed, nil
+}
+
+// Returns true if no changes happened since the near real time reader
+// of the given segments was opened.
+func (w *IndexWriter) nrtIsCurrent(infos *SegmentInfos) bool {
+	w.Lock()
+	defer w.Unlock()
+	log.Printf("IW: nrtIsCurrent: infoVersion matches: %v; DW changes: %v; pending deletes: %v; pending updates: %v",
+		infos.version == w.segmentInfos.version, w.docWriter.numDocs() > 0,
+		len(w.pendingDeletes), len(w.pendingUpdates))
+	return infos.version == w.segmentInfos.version && w.docWriter.numDocs() == 0 &&
+		len(w.pendingDeletes) == 0 && len(w.pendingUpdates) == 0
+}
+
+// Releases the files of a closed near real time reader, unless the
+// writer was closed already.
+func (w *IndexWriter) decRefDeleter(infos *SegmentInfos) {
+	w.Lock()
+	defer w.Unlock()
+	if !w.closed {
+		w.deleter.decRefFiles(infos.Files(w.directory, false))
+	}
+}
+
+/*
+Returns total number of docs in this index, including docs not yet
+flushed (still in the RAM buffer), not counting deletions.
+*/
+func (w *IndexWriter) MaxDoc() int {
+	w.Lock()
+	defer w.Unlock()
+	count := w.docWriter.numDocs()
+	for _, info := range w.segmentInfos.Segments {
+		count += int(info.info.docCount)
+	}
+	return count
+}
+
+/*
+Returns total number of docs in this index, including docs not yet
+flushed (still in the RAM buffer), and taking deletions into account.
+*/
+func (w *IndexWriter) NumDocs() int {
+	w.Lock()
+	defer w.Unlock()
+	count := w.docWriter.numDocs()
+	for _, info := range w.segmentInfos.Segments {
+		count += int(info.info.docCount) - info.delCount
+	}
+	return count
+}
+
+/*
+Adds a document to this index.
+
+Note that each field is processed according to its FieldType: stored
+fields are written to the segment's stored fields, and the string
+value of indexed fields is indexed as a single term.
+
+Documents are buffered in memory and flushed as new segments as the
+FlushPolicy decides, by default once the buffered documents use
+IndexWriterConfig.RAMBufferSizeMB() of RAM, or
+IndexWriterConfig.MaxBufferedDocs() documents have been added by the
+same goroutine; they become visible to readers after the next
+Commit().
+*/
+func (w *IndexWriter) AddDocument(doc []document.IndexableField) error {
+	if err := w.ensureOpenLocked(); err != nil {
+		return err
+	}
+	flushed, err := w.docWriter.addDocument(doc)
+	if err != nil || !flushed {
+		return err
+	}
+	return w.maybeMerge(MERGE_TRIGGER_SEGMENT_FLUSH, -1)
+}
+
+/*
+Updates the NumericDocValues of field to the given value, in all
+documents containing term which were added before this call. The
+field must already exist in the index as a numeric doc values field.
+
+The updates are buffered, and written on the next commit as a new
+generation of doc values of each affected segment, so the documents
+don't need to be reindexed. The update applies to the buffered
+documents too, once their segment is flushed.
+*/
+func (w *IndexWriter) UpdateNumericDocValue(term Term, field string, value int64) error {
+	return w.updateDocValue(&docValuesUpdate{term: term, field: field,
+		typ: DOC_VALUES_TYPE_NUMERIC, numericValue: value})
+}
+
+/*
+Updates the BinaryDocValues of field to the given value, in all
+documents containing term which were added before this call. The
+field must already exist in the index as a binary doc values field.
+
+See UpdateNumericDocValue() for how the updates are written.
+*/
+func (w *IndexWriter) UpdateBinaryDocValue(term Term, field string, value []byte) error {
+	return w.updateDocValue(&docValuesUpdate{term: term, field: field,
+		typ: DOC_VALUES_TYPE_BINARY, binaryValue: append([]byte(nil), value...)})
+}
+
+func (w *IndexWriter) updateDocValue(update *docValuesUpdate) error {
+	if err := w.ensureOpenLocked(); err != nil {
+		return err
+	}
+	if !w.fieldNumbers.contains(update.field, update.typ) {
+		return errors.New(fmt.Sprintf("can only update existing %v doc values fields; got \"%v\"",
+			document.DocValuesType(update.typ), update.field))
+	}
+	applyAllDeletes, err := w.docWriter.updateDocValue(update)
+	if err != nil || !applyAllDeletes {
+		return err
+	}
+	w.Lock()
+	defer w.Unlock()
+	return w.applyAllDeletesAndUpdates()
+}
+
+/*
+Deletes the documents containing any of the terms, which were added
+before this call.
+
+Like doc values updates, the deletes are buffered, and written on the
+next commit, or when a near real time reader applying all deletes is
+opened, as a new generation of live docs of each affected segment.
+The deletes apply to the buffered documents too, once their segment
+is flushed.
+*/
+func (w *IndexWriter) DeleteDocuments(terms ...Term) error {
+	if err := w.ensureOpenLocked(); err != nil {
+		return err
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+	applyAllDeletes, err := w.docWriter.deleteTerms(terms)
+	if err != nil || !applyAllDeletes {
+		return err
+	}
+	w.Lock()
+	defer w.Unlock()
+	return w.applyAllDeletesAndUpdates()
+}
+
+/*
+Registers deletes of the terms for all the flushed segments, all of
+whose documents they apply to. Returns true if all buffered deletes
+must be applied.
+*/
+func (w *IndexWriter) bufferDeletes(terms []Term) bool {
+	w.Lock()
+	defer w.Unlock()
+	if len(w.segmentInfos.Segments) > 0 {
+		for _, term := range terms {
+			del := &termDelete{term: term, docIDUpto: BUFFERED_UPDATES_MAX_INT,
+				segments: make(map[string]bool)}
+			for _, info := range w.segmentInfos.Segments {
+				del.segments[info.info.name] = true
+			}
+			w.pendingDeletes = append(w.pendingDeletes, del)
+		}
+	}
+	return w.docWriter.flushControl.doOnDelete(w.bufferedDeletesStats())
+}
+
+/*
+Registers the update for all the flushed segments, all of whose
+documents it applies to. Returns true if all buffered updates must be
+applied.
+*/
+func (w *IndexWriter) bufferUpdate(update *docValuesUpdate) bool {
+	w.Lock()
+	defer w.Unlock()
+	update.docIDUpto = BUFFERED_UPDATES_MAX_INT
+	update.segments = make(map[string]bool)
+	for _, info := range w.segmentInfos.Segments {
+		update.segments[info.info.name] = true
+	}
+	if len(update.segments) > 0 {
+		w.pendingUpdates = append(w.pendingUpdates, update)
+	}
+	return w.docWriter.flushControl.doOnDelete(w.bufferedDeletesStats())
+}
+
+// Returns the number and approximate RAM of the pending deletes and
+// updates. Called with the IndexWriter lock held.
+func (w *IndexWriter) bufferedDeletesStats() (int, int64) {
+	return len(w.pendingDeletes) + len(w.pendingUpdates),
+		bufferedDeletesBytes(w.pendingDeletes) + bufferedUpdatesBytes(w.pendingUpdates)
+}
+
+// Applies the pending deletes and doc values updates to all segments.
+// Called with the IndexWriter lock held.
+func (w *IndexWriter) applyAllDeletesAndUpdates() error {
+	log.Printf("IW: apply all %v buffered deletes and %v buffered doc values updates",
+		len(w.pendingDeletes), len(w.pendingUpdates))
+	names := make([]string, len(w.segmentInfos.Segments))
+	for i, info := range w.segmentInfos.Segments {
+		names[i] = info.info.name
+	}
+	return w.applyDeletesAndUpdates(names, nil)
+}
+
+/*
+Applies the pending deletes, then the pending doc values updates, to
+the named segments, writing new generations of their live docs and
+doc values. Deletes and updates applied to a segment being merged stay
+pending for it, so they are applied again to the merged segment,
+unless the given merge of the segments applies them before reading
+them. Called with the IndexWriter lock held.
+*/
+func (w *IndexWriter) applyDeletesAndUpdates(names []string, merge *OneMerge) error {
+	if len(w.pendingDeletes) == 0 && len(w.pendingUpdates) == 0 {
+		return nil
+	}
+	for _, name := range names {
+		var deletes []*termDelete
+		for _, del := range w.pendingDeletes {
+			if del.segments[name] {
+				deletes = append(deletes, del)
+			}
+		}
+		var updates []*docValuesUpdate
+		for _, update := range w.pendingUpdates {
+			if update.segments[name] {
+				updates = append(updates, update)
+			}
+		}
+		if len(deletes) == 0 && len(updates) == 0 {
+			continue
+		}
+		i := w.indexOfSegment(name)
+		if len(deletes) > 0 {
+			info, err := writeLiveDocs(w.directory, w.segmentInfos.Segments[i], deletes)
+			if err != nil {
+				return err
+			}
+			if info.delGen != w.segmentInfos.Segments[i].delGen {
+				log.Printf("IW: applied %v deletes to %v", len(deletes), info)
+				w.segmentInfos.Segments[i] = info
+				w.checkpoint()
+			}
+		}
+		if len(updates) > 0 {
+			info, err := writeFieldUpdates(w.directory, w.fieldNumbers, w.segmentInfos.Segments[i], updates)
+			if err != nil {
+				return err
+			}
+			if info.fieldInfosGen != w.segmentInfos.Segments[i].fieldInfosGen {
+				log.Printf("IW: applied %v doc values updates to %v", len(updates), info)
+				w.segmentInfos.Segments[i] = info
+				w.checkpoint()
+			}
+		}
+		if merge == nil && w.mergingSegments[name] {
+			continue
+		}
+		for _, del := range deletes {
+			delete(del.segments, name)
+		}
+		for _, update := range updates {
+			delete(update.segments, name)
+		}
+	}
+
+	var remainingDeletes []*termDelete
+	for _, del := range w.pendingDeletes {
+		if len(del.segments) > 0 {
+			remainingDeletes = append(remainingDeletes, del)
+		}
+	}
+	var remainingUpdates []*docValuesUpdate
+	for _, update := range w.pendingUpdates {
+		if len(update.segments) > 0 {
+			remainingUpdates = append(remainingUpdates, update)
+		}
+	}
+	w.pendingDeletes, w.pendingUpdates = remainingDeletes, remainingUpdates
+	w.docWriter.flushControl.setBufferedDeletes(w.bufferedDeletesStats())
+	return nil
+}
+
+func (w *IndexWriter) ensureOpenLocked() error {
+	w.Lock()
+	defer w.Unlock()
+	return w.ensureOpen()
+}
+
+/*
+Adds a newly flushed segment to the segment infos, and stops counting
+its documents as buffered. The deletes and doc values updates
+buffered along with its documents are pending for the segment from
+now on; they come after all pending ones, none of which applies to
+the new segment.
+*/
+func (w *IndexWriter) publishFlushedSegment(info *SegmentInfoPerCommit,
+	pending *bufferedUpdates, numDocs int) error {
+	w.Lock()
+	defer w.Unlock()
+	log.Printf("IW: publish flushed segment %v", info)
+	w.segmentInfos.Segments = append(w.segmentInfos.Segments, *info)
+	w.checkpoint()
+	w.docWriter.subtractFlushedNumDocs(numDocs)
+	if len(pending.deletes) == 0 && len(pending.updates) == 0 {
+		return nil
+	}
+	for _, del := range pending.deletes {
+		del.segments = map[string]bool{info.info.name: true}
+	}
+	for _, update := range pending.updates {
+		update.segments = map[string]bool{info.info.name: true}
+	}
+	w.pendingDeletes = append(w.pendingDeletes, pending.deletes...)
+	w.pendingUpdates = append(w.pendingUpdates, pending.updates...)
+	if w.docWriter.flushControl.doOnDelete(w.bufferedDeletesStats()) {
+		return w.applyAllDeletesAndUpdates()
+	}
+	return nil
+}
+
+/*
+Expert: prepare for commit. This does the first phase of 2-phase
+commit: it flushes all buffered documents, runs the merges the
+MergePolicy selects, syncs all referenced index files and writes,
+but does not publish, a new segments_N file. After calling this you
+must call either Commit() to finish the commit, or Rollback() to
+revert the commit and undo all changes done since the writer was
+opened or last committed.
+
+You can also just call Commit() directly without PrepareCommit()
+first, in which case that method will internally call
+PrepareCommit().
+*/
+func (w *IndexWriter) PrepareCommit() error {
+	w.commitLock.Lock()
+	defer w.commitLock.Unlock()
+	if err := w.ensureOpenLocked(); err != nil {
+		return err
+	}
+	return w.prepareCommitInternal(false)
+}
+
+/*
+Flushes the segments buffered by all goroutines, runs the merges the
+MergePolicy selects, and writes a new pending segments_N file if
+anything changed. Called with the commit lock held, but not the
+IndexWriter lock, so concurrent AddDocument calls can publish their
+segments while all ThreadStates are obtained.
+*/
+func (w *IndexWriter) prepareCommitInternal(closing bool) error {
+	if w.pendingCommit != nil {
+		return errors.New("prepareCommit was already called with no corresponding call to commit")
+	}
+	if err := w.docWriter.flushAllThreads(closing); err != nil {
+		return err
+	}
+	if err := w.maybeMerge(MERGE_TRIGGER_FULL_FLUSH, -1); err != nil {
+		return err
+	}
+
+	w.Lock()
+	defer w.Unlock()
+	if closing {
+		// Give merges started by other goroutines a chance to finish
+		for len(w.runningMerges) > 0 {
+			w.mergeFinished.Wait()
+		}
+	}
+	names := make([]string, len(w.segmentInfos.Segments))
+	for i, info := range w.segmentInfos.Segments {
+		names[i] = info.info.name
+	}
+	if err := w.applyDeletesAndUpdates(names, nil); err != nil {
+		return err
+	}
+	if w.changeCount == w.lastCommitChangeCount {
+		log.Print("IW: prepareCommit: skip: no changes pending")
+		return nil
+	}
+	log.Printf("IW: prepareCommit: %v segments", len(w.segmentInfos.Segments))
+	toCommit := w.segmentInfos.clone()
+	// protect the files to commit from checkpoints of concurrent
+	// flushes and merges
+	files := toCommit.Files(w.directory, false)
+	w.deleter.incRefFiles(files)
+	// all files must be on stable storage before segments_N
+	// references them
+	err := w.directory.Sync(files)
+	if err == nil {
+		err = toCommit.prepareCommit(w.directory)
+	}
+	if err != nil {
+		w.deleter.decRefFiles(files)
+		return errors.New(fmt.Sprintf("commit failed: %v", err))
+	}
+	w.pendingCommit, w.pendingCommitChangeCount = toCommit, w.changeCount
+	w.filesToCommit = files
+	return nil
+}
+
+/*
+Commits all pending changes (added documents) to the index, and syncs
+all referenced index files, such that a reader will see the changes
+and the index updates will survive an OS or machine crash or power
+loss.
+
+If PrepareCommit() was called before, this finishes that commit, and
+changes made since then are not part of it.
+*/
+func (w *IndexWriter) Commit() error {
+	w.commitLock.Lock()
+	defer w.commitLock.Unlock()
+	if err := w.ensureOpenLocked(); err != nil {
+		return err
+	}
+	return w.commitInternal(false)
+}
+
+// Prepares a commit unless one is pending already, and publishes it.
+// Called with the commit lock held.
+func (w *IndexWriter) commitInternal(closing bool) error {
+	if w.pendingCommit == nil {
+		if err := w.prepareCommitInternal(closing); err != nil {
+			return err
+		}
+	} else {
+		log.Print("IW: commit: already prepared")
+	}
+	return w.finishCommit()
+}
+
+// Publishes the pending segments_N file, if any. Called with the
+// commit lock held.
+func (w *IndexWriter) finishCommit() error {
+	w.Lock()
+	defer w.Unlock()
+	if w.pendingCommit == nil {
+		log.Print("IW: commit: pendingCommit == nil; skip")
+		return nil
+	}
+	defer func() {
+		w.pendingCommit = nil
+		w.deleter.decRefFiles(w.filesToCommit)
+		w.filesToCommit = nil
+	}()
+	log.Printf("IW: commit: %v", w.pendingCommit.SegmentsFileName())
+	if err := w.pendingCommit.finishCommit(w.directory); err != nil {
+		return errors.New(fmt.Sprintf("commit failed: %v", err))
+	}
+	w.segmentInfos.updateGeneration(w.pendingCommit)
+	w.lastCommitChangeCount = w.pendingCommitChangeCount
+	w.rollbackSegments = w.pendingCommit.Segments
+	// the commit is done even if the IndexDeletionPolicy fails
+	return w.deleter.checkpoint(w.pendingCommit, true)
+}
+
+/*
+Commits all changes to an index and closes all associated files.
+
+If an error is hit during close, the writer is still closed, and
+changes which were not committed yet are lost.
+*/
+func (w *IndexWriter) Close() (err error) {
+	w.commitLock.Lock()
+	defer w.commitLock.Unlock()
+	if err := w.ensureOpenLocked(); err != nil {
+		return nil
+	}
+	defer func() {
+		w.docWriter.abort()
+		w.Lock()
+		w.closed = true
+		w.releaseWarmedReaders()
+		w.deleter.close()
+		w.Unlock()
+		if err == nil {
+			err = w.mergeScheduler.Close()
+		} else {
+			util.CloseWhileSuppressingError(w.mergeScheduler)
+		}
+	}()
+	return w.commitInternal(true)
+}
+
+/*
+Sets the commit user data map. That method is considered a
+transaction by IndexWriter and will be committed by the next Commit()
+even if no other changes were made to the writer instance. Note that you must
+call this method before PrepareCommit(), or otherwise it won't be
+included in the follow-on Commit().
+
+NOTE: the map is cloned internally, therefore altering the map's
+contents after calling this method has no effect.
+*/
+func (w *IndexWriter) SetCommitData(commitUserData map[string]string) {
+	w.Lock()
+	defer w.Unlock()
+	userData := make(map[string]string)
+	for k, v := range commitUserData {
+		userData[k] = v
+	}
+	w.segmentInfos.userData = userData
+	w.changed()
+}
+
+/*
+Returns the commit user data map that was last committed, or the one
+that was set on SetCommitData().
+*/
+func (w *IndexWriter) CommitData() map[string]string {
+	w.Lock()
+	defer w.Unlock()
+	return w.segmentInfos.userData
+}
+
+/*
+Closes the IndexWriter without committing any changes that have
+occurred since the last commit (or since it was opened, if commit
+hasn't been called). This removes any temporary files that had been
+created, after which the state of the index will be the same as it
+was when Commit() was last called or when this writer was first
+opened. This also clears a previous call to PrepareCommit().
+
+Running merges are waited for, and their result is discarded.
+*/
+func (w *IndexWriter) Rollback() error {
+	w.commitLock.Lock()
+	defer w.commitLock.Unlock()
+	if err := w.ensureOpenLocked(); err != nil {
+		return nil
+	}
+	log.Print("IW: rollback")
+	w.docWriter.abort()
+
+	w.Lock()
+	defer w.Unlock()
+	// no merges are started or committed from now on
+	w.closed = true
+	for _, merge := range w.pendingMerges {
+		for _, info := range merge.segments {
+			delete(w.mergingSegments, info.info.name)
+		}
+	}
+	w.pendingMerges = nil
+	for len(w.runningMerges) > 0 {
+		w.mergeFinished.Wait()
+	}
+
+	if w.pendingCommit != nil {
+		w.pendingCommit.rollbackCommit(w.directory)
+		w.deleter.decRefFiles(w.filesToCommit)
+		w.pendingCommit, w.filesToCommit = nil, nil
+	}
+	w.segmentInfos.Segments = w.rollbackSegments
+	w.pendingDeletes, w.pendingUpdates = nil, nil
+	log.Printf("IW: rollback: infos=%v", w.segStringOf(w.segmentInfos.Segments))
+
+	w.releaseWarmedReaders()
+
+	// Ask deleter to locate unreferenced files & remove them:
+	w.deleter.checkpoint(w.segmentInfos, false)
+	err := w.deleter.refresh()
+	w.deleter.close()
+	if err != nil {
+		util.CloseWhileSuppressingError(w.mergeScheduler)
+		return err
+	}
+	return w.mergeScheduler.Close()
+}
+
+/*
+Forces merge policy to merge segments until there are <=
+maxNumSegments. The actual merges to be executed are determined by
+the MergePolicy.
+
+This is a horribly costly operation, especially when you pass a small
+maxNumSegments; usually you should only call this if the index is
+static (will no longer be changed).
+
+Note that this requires up to 2X the index size free space in your
+Directory, since the segments being merged are not deleted yet.
+
+Buffered documents are flushed first, and the call blocks until the
+merges complete.
+*/
+func (w *IndexWriter) ForceMerge(maxNumSegments int) error {
+	if err := w.ensureOpenLocked(); err != nil {
+		return err
+	}
+	if maxNumSegments < 1 {
+		return errors.New(fmt.Sprintf("maxNumSegments must be >= 1; got %v", maxNumSegments))
+	}
+	log.Printf("IW: forceMerge: index now %v", w.SegString())
+	if err := w.docWriter.flushAllThreads(false); err != nil {
+		return err
+	}
+
+	w.Lock()
+	w.segmentsToMerge = make(map[string]bool)
+	for _, info := range w.segmentInfos.Segments {
+		w.segmentsToMerge[info.info.name] = true
+	}
+	// Now mark all pending & running merges for forced merge:
+	for _, merge := range w.pendingMerges {
+		merge.maxNumSegments = maxNumSegments
+	}
+	for merge, _ := range w.runningMerges {
+		merge.maxNumSegments = maxNumSegments
+		if merge.info != nil {
+			w.segmentsToMerge[merge.info.info.name] = true
+		}
+	}
+	w.Unlock()
+
+	if err := w.maybeMerge(MERGE_TRIGGER_EXPLICIT, maxNumSegments); err != nil {
+		return err
+	}
+
+	w.Lock()
+	defer w.Unlock()
+	for w.maxNumSegmentsMergesPending() {
+		w.mergeFinished.Wait()
+	}
+	return w.ensureOpen()
+}
+
+/*
+Adds all segments from an array of indexes into this index.
+
+This may be used to parallelize batch indexing. A large document
+collection can be broken into sub-collections. Each sub-collection
+can be indexed in parallel, on a different goroutine, process or
+machine. The complete index can then be created by merging
+sub-collection indexes with this method.
+
+NOTE: the index in each Directory must not be changed (opened by a
+writer) while this method is running. This method does not acquire
+a write lock in each input Directory, so it is up to the caller to
+enforce this.
+
+This method is transactional in how errors are handled: it does not
+commit a new segments_N file until all indexes are added. If an
+error occurs, the segments copied so far are removed again.
+
+Note that each segment is copied as is, with its deletions and doc
+values updates; no merge is done. If you wish to do that, you should
+call ForceMerge() afterwards.
+*/
+func (w *IndexWriter) AddIndexes(dirs ...store.Directory) error {
+	if err := w.ensureOpenLocked(); err != nil {
+		return err
+	}
+	log.Printf("IW: flush at addIndexes(Directory...)")
+	if err := w.docWriter.flushAllThreads(false); err != nil {
+		return err
+	}
+
+	var infos []SegmentInfoPerCommit
+	success := false
+	defer func() {
+		if !success {
+			for _, info := range infos {
+				for _, file := range info.Files() {
+					w.directory.DeleteFile(file)
+				}
+			}
+		}
+	}()
+	for _, dir := range dirs {
+		log.Printf("IW: addIndexes: process directory %v", dir)
+		sis := &SegmentInfos{}
+		if err := sis.ReadAll(dir); err != nil {
+			return err
+		}
+		for _, info := range sis.Segments {
+			newInfo, err := w.copySegmentAsIs(info, w.newSegmentName())
+			if err != nil {
+				return err
+			}
+			infos = append(infos, newInfo)
+		}
+	}
+
+	w.Lock()
+	defer w.Unlock()
+	if err := w.ensureOpen(); err != nil {
+		return err
+	}
+	w.segmentInfos.Segments = append(w.segmentInfos.Segments, infos...)
+	w.checkpoint()
+	success = true
+	return nil
+}
+
+/*
+Copies the files of a segment from another index into this one,
+renamed after segName, and returns the info of the copy. The
+SegmentInfo is rewritten, as it records the segment's name.
+*/
+func (w *IndexWriter) copySegmentAsIs(info SegmentInfoPerCommit, segName string) (ans SegmentInfoPerCommit, err error) {
+	fis, err := w.readFieldInfos(info.info)
+	if err != nil {
+		return ans, err
+	}
+	w.Lock()
+	err = w.addFieldNumbers(fis)
+	w.Unlock()
+	if err != nil {
+		return ans, err
+	}
+
+	rename := func(file string) string {
+		return segName + util.StripSegmentName(file)
+	}
+	context := store.NewIOContextFromType(store.IO_CONTEXT_TYPE_MERGE)
+	tracker := store.NewTrackingDirectoryWrapper(w.directory)
+	success := false
+	defer func() {
+		if !success {
+			for file, _ := range tracker.CreatedFiles() {
+				w.directory.DeleteFile(file)
+			}
+		}
+	}()
+
+	si := &SegmentInfo{
+		dir:            w.directory,
+		version:        info.info.version,
+		name:           segName,
+		docCount:       info.info.docCount,
+		isCompoundFile: info.info.isCompoundFile,
+		codec:          info.info.codec,
+		diagnostics:    info.info.diagnostics,
+		attributes:     info.info.attributes,
+		Files:          make(map[string]bool),
+	}
+	for file, _ := range info.info.Files {
+		si.Files[rename(file)] = true
+	}
+	// the SegmentInfo file is not copied, but written for the new name
+	siFileName := util.SegmentFileName(info.info.name, "", LUCENE40_SI_EXTENSION)
+	if err = w.codec.WriteSegmentInfo(tracker, si, fis, context); err != nil {
+		return ans, err
+	}
+	for _, file := range info.Files() {
+		if file == siFileName {
+			continue
+		}
+		if err = store.CopyFile(info.info.dir, tracker, file, rename(file), context); err != nil {
+			return ans, err
+		}
+	}
+
+	ans = NewSegmentInfoPerCommit(*si, info.delCount, info.delGen, info.fieldInfosGen)
+	for gen, files := range info.genUpdatesFiles {
+		renamed := make(map[string]bool)
+		for file, _ := range files {
+			renamed[rename(file)] = true
+		}
+		ans.genUpdatesFiles[gen] = renamed
+	}
+	success = true
+	return ans, nil
+}
+
+/*
+Merges the provided indexes into this index.
+
+The provided IndexReaders are not closed.
+
+See AddIndexes() for details on transactional semantics. Unlike
+AddIndexes(), the documents of all readers are merged into a single
+new segment, leaving out deleted documents.
+
+NOTE: empty segments are dropped by this method and not added to
+this index.
+*/
+func (w *IndexWriter) AddIndexesFromReaders(readers ...IndexReader) error {
+	if err := w.ensureOpenLocked(); err != nil {
+		return err
+	}
+	log.Printf("IW: flush at addIndexes(IndexReader...)")
+	if err := w.docWriter.flushAllThreads(false); err != nil {
+		return err
+	}
+
+	var mergeReaders []AtomicReader
+	for _, reader := range readers {
+		for _, ctx := range reader.Leaves() {
+			mergeReaders = append(mergeReaders, ctx.Reader().(AtomicReader))
+		}
+	}
+
+	context := store.NewIOContextFromType(store.IO_CONTEXT_TYPE_MERGE)
+	tracker := store.NewTrackingDirectoryWrapper(w.directory)
+	si := &SegmentInfo{
+		dir:         w.directory,
+		version:     util.LUCENE_MAIN_VERSION,
+		name:        w.newSegmentName(),
+		docCount:    -1,
+		codec:       w.codec,
+		diagnostics: make(map[string]string),
+		attributes:  make(map[string]string),
+		Files:       make(map[string]bool),
+	}
+	success := false
+	defer func() {
+		if !success || si.docCount == 0 {
+			for file, _ := range tracker.CreatedFiles() {
+				w.directory.DeleteFile(file)
+			}
+		}
+	}()
+
+	mergeState, err := newSegmentMerger(mergeReaders, si, tracker, w.fieldNumbers, context).merge()
+	if err != nil {
+		return err
+	}
+	if si.docCount == 0 {
+		success = true
+		return nil
+	}
+	setDiagnostics(si, "addIndexes(IndexReader...)", nil)
+	si.Files = tracker.CreatedFiles()
+	// Have codec write SegmentInfo. Must do this after creating
+	// all the other files of the segment:
+	if err = w.codec.WriteSegmentInfo(tracker, si, mergeState.fieldInfos, context); err != nil {
+		return err
+	}
+
+	w.Lock()
+	defer w.Unlock()
+	if err = w.ensureOpen(); err != nil {
+		return err
+	}
+	w.segmentInfos.Segments = append(w.segmentInfos.Segments, NewSegmentInfoPerCommit(*si, 0, -1, -1))
+	w.checkpoint()
+	success = true
+	return nil
+}
+
+// Returns true if any merges in pendingMerges or runningMerges are
+// maxNumSegments merges.
+func (w *IndexWriter) maxNumSegmentsMergesPending() bool {
+	for _, merge := range w.pendingMerges {
+		if merge.maxNumSegments != -1 {
+			return true
+		}
+	}
+	for merge, _ := range w.runningMerges {
+		if merge.maxNumSegments != -1 {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Asks the MergePolicy whether any merges are necessary now, and hands
+them over to the MergeScheduler.
+*/
+func (w *IndexWriter) maybeMerge(trigger MergeTrigger, maxNumSegments int) error {
+	w.Lock()
+	err := w.updatePendingMerges(trigger, maxNumSegments)
+	w.Unlock()
+	if err != nil {
+		return err
+	}
+	return w.mergeScheduler.Merge(w)
+}
+
+// Registers the merges selected by the MergePolicy. Called with the
+// IndexWriter lock held.
+func (w *IndexWriter) updatePendingMerges(trigger MergeTrigger, maxNumSegments int) error {
+	if w.closed {
+		return nil
+	}
+	var spec *MergeSpecification
+	var err error
+	if maxNumSegments != -1 {
+		// assert trigger == EXPLICIT || trigger == MERGE_FINISHED
+		spec, err = w.mergePolicy.FindForcedMerges(w.segmentInfos, maxNumSegments, w.segmentsToMerge)
+		if err != nil {
+			return err
+		}
+		if spec != nil {
+			for _, merge := range spec.merges {
+				merge.maxNumSegments = maxNumSegments
+			}
+		}
+	} else {
+		if spec, err = w.mergePolicy.FindMerges(trigger, w.segmentInfos); err != nil {
+			return err
+		}
+	}
+	if spec != nil {
+		for _, merge := range spec.merges {
+			w.registerMerge(merge)
+		}
+	}
+	return nil
+}
+
+/*
+Checks whether this merge involves any segments already participating
+in a merge. If not, this merge is "registered", meaning we record
+that its segments are now participating in a merge, and true is
+returned. Else (the merge conflicts) false is returned. Called with
+the IndexWriter lock held.
+*/
+func (w *IndexWriter) registerMerge(merge *OneMerge) bool {
+	for _, info := range merge.segments {
+		if w.mergingSegments[info.info.name] {
+			log.Printf("IW: reject merge %v: segment %v is already marked for merge",
+				merge.SegString(), info.info.name)
+			return false
+		}
+		if w.indexOfSegment(info.info.name) == -1 {
+			log.Printf("IW: reject merge %v: segment %v does not exist in live infos",
+				merge.SegString(), info.info.name)
+			return false
+		}
+	}
+	w.pendingMerges = append(w.pendingMerges, merge)
+	log.Printf("IW: add merge to pendingMerges: %v [total %v pending]",
+		merge.SegString(), len(w.pendingMerges))
+	for _, info := range merge.segments {
+		w.mergingSegments[info.info.name] = true
+	}
+	return true
+}
+
+// Returns the position of the named segment in the segment infos, or
+// -1 if it is not there.
+func (w *IndexWriter) indexOfSegment(name string) int {
+	for i, info := range w.segmentInfos.Segments {
+		if info.info.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+/*
+Expert: the MergeScheduler calls this method to retrieve the next
+merge requested by the MergePolicy, which is marked as running. Nil
+is returned if there is no pending merge.
+*/
+func (w *IndexWriter) NextMerge() *OneMerge {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed || len(w.pendingMerges) == 0 {
+		return nil
+	}
+	merge := w.pendingMerges[0]
+	w.pendingMerges = w.pendingMerges[1:]
+	w.runningMerges[merge] = true
+	return merge
+}
+
+// Returns true if the given segment is being merged.
+func (w *IndexWriter) isMerging(info SegmentInfoPerCommit) bool {
+	return w.mergingSegments[info.info.name]
+}
+
+// Returns the number of deleted documents of the given segment.
+func (w *IndexWriter) numDeletedDocs(info SegmentInfoPerCommit) int {
+	return info.delCount
+}
+
+/*
+Merges the indicated segments, replacing them in the stack with a
+single segment. If the merge is forced, the MergePolicy is asked for
+the next merges once this one finished.
+
+Expert: called by the MergeScheduler with merges returned by
+NextMerge().
+*/
+func (w *IndexWriter) Merge(merge *OneMerge) (err error) {
+	success := false
+	defer func() {
+		w.Lock()
+		defer w.Unlock()
+		w.mergeFinish(merge)
+		if success && merge.maxNumSegments != -1 {
+			err = w.updatePendingMerges(MERGE_TRIGGER_MERGE_FINISHED, merge.maxNumSegments)
+		}
+	}()
+
+	log.Printf("IW: now merge\n  merge=%v\n  index=%v", merge.SegString(), w.SegString())
+	if err = w.mergeMiddle(merge); err != nil {
+		log.Printf("IW: hit error during merge: %v", err)
+		return err
+	}
+	success = true
+	return nil
+}
+
+// Unregisters the merge, and wakes up anyone waiting for it. Called
+// with the IndexWriter lock held.
+func (w *IndexWriter) mergeFinish(merge *OneMerge) {
+	for _, info := range merge.segments {
+		delete(w.mergingSegments, info.info.name)
+	}
+	delete(w.runningMerges, merge)
+	w.mergeFinished.Broadcast()
+}
+
+/*
+Does the actual (time-consuming) work of the merge, without holding
+the IndexWriter lock. The merged segment is not written as a compound
+file, since writing compound files is not supported yet.
+*/
+func (w *IndexWriter) mergeMiddle(merge *OneMerge) (err error) {
+	context := store.NewIOContextFromType(store.IO_CONTEXT_TYPE_MERGE)
+	tracker := store.NewTrackingDirectoryWrapper(w.directory)
+	si := &SegmentInfo{
+		dir:         w.directory,
+		version:     util.LUCENE_MAIN_VERSION,
+		name:        w.newSegmentName(),
+		docCount:    -1,
+		codec:       w.codec,
+		diagnostics: make(map[string]string),
+		attributes:  make(map[string]string),
+		Files:       make(map[string]bool),
+	}
+	info := NewSegmentInfoPerCommit(*si, 0, -1, -1)
+	w.Lock()
+	merge.info = &info
+	// the merged segment must include the pending deletes and doc
+	// values updates
+	names := make([]string, len(merge.segments))
+	for i, info := range merge.segments {
+		names[i] = info.info.name
+	}
+	if err = w.applyDeletesAndUpdates(names, merge); err == nil {
+		for i, name := range names {
+			merge.segments[i] = w.segmentInfos.Segments[w.indexOfSegment(name)]
+		}
+	}
+	w.Unlock()
+	if err != nil {
+		return err
+	}
+
+	success := false
+	defer func() {
+		if !success || si.docCount == 0 {
+			for file, _ := range tracker.CreatedFiles() {
+				w.directory.DeleteFile(file)
+			}
+		}
+	}()
+
+	readers := make([]AtomicReader, 0, len(merge.segments))
+	defer func() {
+		for _, reader := range readers {
+			reader.decRef()
+		}
+	}()
+	for _, info := range merge.segments {
+		reader, err := NewSegmentReader(info, DEFAULT_TERMS_INDEX_DIVISOR, context)
+		if err != nil {
+			return err
+		}
+		readers = append(readers, reader)
+	}
+
+	mergeReaders := readers
+	if merge.wrapReaders != nil {
+		if mergeReaders, err = merge.wrapReaders(readers); err != nil {
+			return err
+		}
+	}
+	mergeState, err := newSegmentMerger(mergeReaders, si, tracker, w.fieldNumbers, context).merge()
+	if err != nil {
+		return err
+	}
+	if si.docCount > 0 {
+		setDiagnostics(si, "merge", map[string]string{
+			"mergeFactor":         strconv.Itoa(len(merge.segments)),
+			"mergeMaxNumSegments": strconv.Itoa(merge.maxNumSegments),
+		})
+		si.Files = tracker.CreatedFiles()
+		// Have codec write SegmentInfo. Must do this after creating
+		// all the other files of the segment:
+		if err = w.codec.WriteSegmentInfo(tracker, si, mergeState.fieldInfos, context); err != nil {
+			return err
+		}
+	}
+	w.Lock()
+	info.info = *si
+	poolReaders := w.poolReaders
+	w.Unlock()
+
+	var warmed *SegmentReader
+	if warmer := w.config.mergedSegmentWarmer; warmer != nil && poolReaders && si.docCount > 0 {
+		if warmed, err = w.warmMergedSegment(warmer, info); err != nil {
+			return err
+		}
+	}
+	if err = w.commitMerge(merge); err != nil {
+		if warmed != nil {
+			warmed.decRef()
+		}
+		return err
+	}
+	if warmed != nil {
+		w.Lock()
+		if w.closed {
+			warmed.decRef()
+		} else {
+			w.warmedReaders[si.name] = warmed
+		}
+		w.Unlock()
+	}
+	success = true
+	return nil
+}
+
+/*
+Opens a reader on the newly merged segment, and warms it with the
+IndexReaderWarmer before the merge is committed, so near real time
+readers opened afterwards use the warmed reader.
+*/
+func (w *IndexWriter) warmMergedSegment(warmer IndexReaderWarmer, info SegmentInfoPerCommit) (*SegmentReader, error) {
+	reader, err := NewSegmentReader(info, DEFAULT_TERMS_INDEX_DIVISOR, store.IO_CONTEXT_READ)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("IW: warm merged segment %v", info.info.name)
+	if err = warmer.Warm(reader); err != nil {
+		reader.decRef()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// Releases the readers of warmed merged segments not handed over to
+// a near real time reader. Called with the IndexWriter lock held.
+func (w *IndexWriter) releaseWarmedReaders() {
+	for name, reader := range w.warmedReaders {
+		reader.decRef()
+		delete(w.warmedReaders, name)
+	}
+}
+
+/*
+Replaces the merged segments with the new segment, or just drops them
+if all their documents were deleted.
+*/
+func (w *IndexWriter) commitMerge(merge *OneMerge) error {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return errors.New("this IndexWriter is closed")
+	}
+	dropSegment := merge.info.info.docCount == 0
+	if dropSegment {
+		log.Printf("IW: merge away fully deleted segments")
+	}
+
+	merged := make(map[string]bool)
+	for _, info := range merge.segments {
+		merged[info.info.name] = true
+		// merged again before any near real time reader used it
+		if reader, ok := w.warmedReaders[info.info.name]; ok {
+			reader.decRef()
+			delete(w.warmedReaders, info.info.name)
+		}
+	}
+	segments := make([]SegmentInfoPerCommit, 0, len(w.segmentInfos.Segments))
+	inserted := false
+	for _, info := range w.segmentInfos.Segments {
+		if !merged[info.info.name] {
+			segments = append(segments, info)
+		} else if !inserted && !dropSegment {
+			// the new segment takes the place of the first merged one
+			segments = append(segments, *merge.info)
+			inserted = true
+		}
+	}
+	w.segmentInfos.Segments = segments
+	w.checkpoint()
+	log.Printf("IW: after commitMerge: %v", w.segStringOf(segments))
+
+	// deletes and updates which arrived during the merge apply to all
+	// documents of the merged segment
+	for name, _ := range merged {
+		for _, del := range w.pendingDeletes {
+			if del.segments[name] {
+				delete(del.segments, name)
+				if !dropSegment {
+					del.segments[merge.info.info.name] = true
+				}
+			}
+		}
+		for _, update := range w.pendingUpdates {
+			if update.segments[name] {
+				delete(update.segments, name)
+				if !dropSegment {
+					update.segments[merge.info.info.name] = true
+				}
+			}
+		}
+	}
+
+	if merge.maxNumSegments != -1 && !dropSegment {
+		// cascade the forced merge
+		if _, ok := w.segmentsToMerge[merge.info.info.name]; !ok {
+			w.segmentsToMerge[merge.info.info.name] = false
+		}
+	}
+	return nil
+}
+
+// Returns a string description of all segments, for debugging.
+func (w *IndexWriter) SegString() string {
+	w.Lock()
+	defer w.Unlock()
+	return w.segStringOf(w.segmentInfos.Segments)
+}
+
+// Returns a string description of the specified segments, for
+// debugging. Called with the IndexWriter lock held.
+func (w *IndexWriter) segStringOf(infos []SegmentInfoPerCommit) string {
+	var b bytes.Buffer
+	for i, info := range infos {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(info.StringOf(w.directory, 0))
+		if w.mergingSegments[info.info.name] {
+			b.WriteString(" [merging]")
+		}
+	}
+	return b.String()
+}
+
+// Sets the diagnostics of a newly written segment.
+func setDiagnostics(info *SegmentInfo, source string, details map[string]string) {
+	info.diagnostics = map[string]string{
+		"source":         source,
+		"os":             runtime.GOOS,
+		"os.arch":        runtime.GOARCH,
+		"go.version":     runtime.Version(),
+		"lucene.version": util.LUCENE_VERSION,
+		"timestamp":      strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10),
+	}
+	for k, v := range details {
+		info.diagnostics[k] = v
+	}
+}
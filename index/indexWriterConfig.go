@@ -0,0 +1,377 @@
+package index
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis"
+)
+
+// IndexWriterConfig.java
+
+// Specifies the open mode for IndexWriter.
+type OpenMode int
+
+const (
+	// Creates a new index or overwrites an existing one.
+	OPEN_MODE_CREATE = OpenMode(1)
+	// Opens an existing index.
+	OPEN_MODE_APPEND = OpenMode(2)
+	// Creates a new index if one does not exist, otherwise it opens
+	// the index and documents will be appended.
+	OPEN_MODE_CREATE_OR_APPEND = OpenMode(3)
+)
+
+func (m OpenMode) String() string {
+	switch m {
+	case OPEN_MODE_CREATE:
+		return "CREATE"
+	case OPEN_MODE_APPEND:
+		return "APPEND"
+	case OPEN_MODE_CREATE_OR_APPEND:
+		return "CREATE_OR_APPEND"
+	}
+	panic("assert fail")
+}
+
+const (
+	// Denotes a flush trigger is disabled.
+	IWC_DISABLE_AUTO_FLUSH = -1
+	// Disabled by default (because IndexWriter flushes by RAM usage by
+	// default).
+	IWC_DEFAULT_MAX_BUFFERED_DELETE_TERMS = IWC_DISABLE_AUTO_FLUSH
+	// Disabled by default (because IndexWriter flushes by RAM usage by
+	// default).
+	IWC_DEFAULT_MAX_BUFFERED_DOCS = IWC_DISABLE_AUTO_FLUSH
+	// Default value is 16 MB (which means flush when buffered docs
+	// consume approximately 16 MB RAM).
+	IWC_DEFAULT_RAM_BUFFER_SIZE_MB = 16.0
+	// The maximum number of simultaneous goroutines that may be
+	// indexing documents at once in IndexWriter; if more than this
+	// many goroutines arrive they will wait for others to finish.
+	IWC_DEFAULT_MAX_THREAD_STATES = 8
+)
+
+/*
+Holds all the configuration that is used to create an IndexWriter.
+Once IndexWriter has been created with this object, changes to this
+object will not affect the IndexWriter instance.
+
+All setters return the config itself, so they can be chained:
+
+	conf := index.NewIndexWriterConfig().SetOpenMode(index.OPEN_MODE_CREATE)
+*/
+type IndexWriterConfig struct {
+	openMode               OpenMode
+	maxBufferedDocs        int
+	maxBufferedDeleteTerms int
+	ramBufferSizeMB        float64
+	maxThreadStates        int
+	codec                  Codec
+	analyzer               analysis.Analyzer
+	similarity             Similarity
+	mergePolicy            MergePolicy
+	mergeScheduler         MergeScheduler
+	delPolicy              IndexDeletionPolicy
+	flushPolicy            FlushPolicy
+	mergedSegmentWarmer    IndexReaderWarmer
+}
+
+// Creates a new config with defaults.
+func NewIndexWriterConfig() *IndexWriterConfig {
+	return &IndexWriterConfig{
+		openMode:               OPEN_MODE_CREATE_OR_APPEND,
+		maxBufferedDocs:        IWC_DEFAULT_MAX_BUFFERED_DOCS,
+		maxBufferedDeleteTerms: IWC_DEFAULT_MAX_BUFFERED_DELETE_TERMS,
+		ramBufferSizeMB:        IWC_DEFAULT_RAM_BUFFER_SIZE_MB,
+		maxThreadStates:        IWC_DEFAULT_MAX_THREAD_STATES,
+		codec:                  NewLucene42Codec(),
+		similarity:             defaultSimilarity{},
+		mergePolicy:            NewLogByteSizeMergePolicy(),
+		mergeScheduler:         NewSerialMergeScheduler(),
+		delPolicy:              NewKeepOnlyLastCommitDeletionPolicy(),
+		flushPolicy:            newFlushByRamOrCountsPolicy(),
+	}
+}
+
+// Specifies OpenMode of the index. Only takes effect when
+// IndexWriter is first created.
+func (conf *IndexWriterConfig) SetOpenMode(openMode OpenMode) *IndexWriterConfig {
+	conf.openMode = openMode
+	return conf
+}
+
+// Returns the OpenMode set by SetOpenMode().
+func (conf *IndexWriterConfig) OpenMode() OpenMode {
+	return conf.openMode
+}
+
+/*
+Determines the minimal number of documents required before the
+buffered in-memory documents are flushed as a new segment. Large
+values generally give faster indexing.
+
+When this is set, the writer will flush every maxBufferedDocs added
+documents. Pass in IWC_DISABLE_AUTO_FLUSH to prevent triggering a
+flush due to number of buffered documents. Note that if flushing by
+RAM usage is also enabled, then the flush will be triggered by
+whichever comes first.
+
+Disabled by default (writer flushes by RAM usage).
+
+Panics if maxBufferedDocs is enabled but smaller than 2, or it
+disables maxBufferedDocs when ramBufferSizeMB is already disabled.
+*/
+func (conf *IndexWriterConfig) SetMaxBufferedDocs(maxBufferedDocs int) *IndexWriterConfig {
+	if maxBufferedDocs != IWC_DISABLE_AUTO_FLUSH && maxBufferedDocs < 2 {
+		panic("maxBufferedDocs must at least be 2 when enabled")
+	}
+	if maxBufferedDocs == IWC_DISABLE_AUTO_FLUSH && conf.ramBufferSizeMB == IWC_DISABLE_AUTO_FLUSH {
+		panic("at least one of ramBufferSize and maxBufferedDocs must be enabled")
+	}
+	conf.maxBufferedDocs = maxBufferedDocs
+	return conf
+}
+
+// Returns the number of buffered added documents that will trigger a
+// flush if enabled.
+func (conf *IndexWriterConfig) MaxBufferedDocs() int {
+	return conf.maxBufferedDocs
+}
+
+/*
+Determines the amount of RAM that may be used for buffering added
+documents and doc values updates before they are flushed to the
+Directory. Generally for faster indexing performance it's best to
+flush by RAM usage instead of document count and use as large a RAM
+buffer as you can.
+
+When this is set, the writer will flush whenever buffered documents
+and updates use this much RAM. Pass in IWC_DISABLE_AUTO_FLUSH to
+prevent triggering a flush due to RAM usage. Note that if flushing by
+document count is also enabled, then the flush will be triggered by
+whichever comes first.
+
+NOTE: the accounting of RAM usage for buffered documents is
+approximate; the buffered postings, norms, doc values and term
+vectors are counted, while the stored fields and term vectors
+written to the segment's files as documents are added are not.
+
+When the RAM buffer is full, the writer flushes the segment of the
+goroutine whose buffered documents use the most RAM, while the other
+goroutines keep indexing.
+
+The default value is IWC_DEFAULT_RAM_BUFFER_SIZE_MB.
+
+Panics if ramBufferSizeMB is enabled but non-positive, or it disables
+ramBufferSizeMB when maxBufferedDocs is already disabled.
+*/
+func (conf *IndexWriterConfig) SetRAMBufferSizeMB(ramBufferSizeMB float64) *IndexWriterConfig {
+	if ramBufferSizeMB != IWC_DISABLE_AUTO_FLUSH && ramBufferSizeMB <= 0 {
+		panic("ramBufferSize should be > 0.0 MB when enabled")
+	}
+	if ramBufferSizeMB == IWC_DISABLE_AUTO_FLUSH && conf.maxBufferedDocs == IWC_DISABLE_AUTO_FLUSH {
+		panic("at least one of ramBufferSize and maxBufferedDocs must be enabled")
+	}
+	conf.ramBufferSizeMB = ramBufferSizeMB
+	return conf
+}
+
+// Returns the value set by SetRAMBufferSizeMB() if enabled.
+func (conf *IndexWriterConfig) RAMBufferSizeMB() float64 {
+	return conf.ramBufferSizeMB
+}
+
+/*
+Determines the maximum number of delete-by-term operations that will
+be buffered before both the buffered in-memory delete terms and
+queries are applied and flushed. Buffered doc values updates count
+as delete terms, since they are applied the same way: once the limit
+is hit, all buffered updates are written as new generations of doc
+values of the affected segments.
+
+Disabled by default (writer flushes by RAM usage).
+
+Panics if maxBufferedDeleteTerms is enabled but smaller than 1.
+*/
+func (conf *IndexWriterConfig) SetMaxBufferedDeleteTerms(maxBufferedDeleteTerms int) *IndexWriterConfig {
+	if maxBufferedDeleteTerms != IWC_DISABLE_AUTO_FLUSH && maxBufferedDeleteTerms < 1 {
+		panic("maxBufferedDeleteTerms must at least be 1 when enabled")
+	}
+	conf.maxBufferedDeleteTerms = maxBufferedDeleteTerms
+	return conf
+}
+
+// Returns the number of buffered deleted terms that will trigger a
+// flush of all buffered deletes if enabled.
+func (conf *IndexWriterConfig) MaxBufferedDeleteTerms() int {
+	return conf.maxBufferedDeleteTerms
+}
+
+/*
+Sets the max number of simultaneous goroutines that may be indexing
+documents at once in IndexWriter. Values < 1 are invalid and if
+passed maxThreadStates will be set to IWC_DEFAULT_MAX_THREAD_STATES.
+
+Only takes effect when IndexWriter is first created.
+*/
+func (conf *IndexWriterConfig) SetMaxThreadStates(maxThreadStates int) *IndexWriterConfig {
+	if maxThreadStates < 1 {
+		maxThreadStates = IWC_DEFAULT_MAX_THREAD_STATES
+	}
+	conf.maxThreadStates = maxThreadStates
+	return conf
+}
+
+// Returns the max number of simultaneous goroutines that may be
+// indexing documents at once in IndexWriter.
+func (conf *IndexWriterConfig) MaxThreadStates() int {
+	return conf.maxThreadStates
+}
+
+/*
+Sets the Codec used to write new segments. Existing segments are
+read with the codec they were written with.
+
+Only takes effect when IndexWriter is first created.
+*/
+func (conf *IndexWriterConfig) SetCodec(codec Codec) *IndexWriterConfig {
+	conf.codec = codec
+	return conf
+}
+
+// Returns the current Codec.
+func (conf *IndexWriterConfig) Codec() Codec {
+	return conf.codec
+}
+
+/*
+Sets the Analyzer which analyzes the values of tokenized fields. If
+no analyzer is set, which is the default, the value of each field is
+indexed as a single token.
+*/
+func (conf *IndexWriterConfig) SetAnalyzer(analyzer analysis.Analyzer) *IndexWriterConfig {
+	conf.analyzer = analyzer
+	return conf
+}
+
+// Returns the default analyzer to use for indexing documents.
+func (conf *IndexWriterConfig) Analyzer() analysis.Analyzer {
+	return conf.analyzer
+}
+
+/*
+Sets the Similarity which computes the norms of indexed fields. It
+should match the Similarity used at search time.
+
+Only takes effect when IndexWriter is first created.
+*/
+func (conf *IndexWriterConfig) SetSimilarity(similarity Similarity) *IndexWriterConfig {
+	conf.similarity = similarity
+	return conf
+}
+
+// Returns the Similarity used to compute norms.
+func (conf *IndexWriterConfig) Similarity() Similarity {
+	return conf.similarity
+}
+
+/*
+Expert: MergePolicy is invoked whenever there are changes to the
+segments in the index. Its role is to select which merges to do, if
+any, and return a MergeSpecification describing the merges. It also
+selects merges to do for ForceMerge(). The default is
+LogByteSizeMergePolicy.
+
+The MergePolicy is bound to the IndexWriter created with this
+config, so a new instance should be set before the config is reused
+for another IndexWriter.
+
+Only takes effect when IndexWriter is first created.
+*/
+func (conf *IndexWriterConfig) SetMergePolicy(mergePolicy MergePolicy) *IndexWriterConfig {
+	conf.mergePolicy = mergePolicy
+	return conf
+}
+
+// Returns the current MergePolicy in use by this writer.
+func (conf *IndexWriterConfig) MergePolicy() MergePolicy {
+	return conf.mergePolicy
+}
+
+/*
+Expert: sets the merge scheduler used by this writer. The default is
+SerialMergeScheduler, which runs the merges in the goroutine that
+triggered them.
+
+Only takes effect when IndexWriter is first created.
+*/
+func (conf *IndexWriterConfig) SetMergeScheduler(mergeScheduler MergeScheduler) *IndexWriterConfig {
+	conf.mergeScheduler = mergeScheduler
+	return conf
+}
+
+// Returns the MergeScheduler that was set by SetMergeScheduler().
+func (conf *IndexWriterConfig) MergeScheduler() MergeScheduler {
+	return conf.mergeScheduler
+}
+
+/*
+Expert: allows an optional IndexDeletionPolicy implementation to be
+specified. You can use this to control when prior commits are deleted
+from the index. The default policy is
+KeepOnlyLastCommitDeletionPolicy which removes all prior commits as
+soon as a new commit is done.
+Creating your own policy can allow you to explicitly keep previous
+"point in time" commits alive in the index for some time, to allow
+readers to refresh to the new commit without having the old commit
+deleted out from under them. This is necessary on filesystems like
+NFS that do not support "delete on last close" semantics, which
+Lucene's "point in time" search normally relies on.
+
+Only takes effect when IndexWriter is first created.
+*/
+func (conf *IndexWriterConfig) SetIndexDeletionPolicy(delPolicy IndexDeletionPolicy) *IndexWriterConfig {
+	conf.delPolicy = delPolicy
+	return conf
+}
+
+// Returns the IndexDeletionPolicy specified in
+// SetIndexDeletionPolicy() or the default
+// KeepOnlyLastCommitDeletionPolicy.
+func (conf *IndexWriterConfig) IndexDeletionPolicy() IndexDeletionPolicy {
+	return conf.delPolicy
+}
+
+/*
+Set the merged segment warmer. See IndexReaderWarmer. It is only
+used once a near real time reader was opened from the IndexWriter.
+The default is nil, which doesn't warm merged segments.
+
+Only takes effect when IndexWriter is first created.
+*/
+func (conf *IndexWriterConfig) SetMergedSegmentWarmer(mergedSegmentWarmer IndexReaderWarmer) *IndexWriterConfig {
+	conf.mergedSegmentWarmer = mergedSegmentWarmer
+	return conf
+}
+
+// Returns the current merged segment warmer. See IndexReaderWarmer.
+func (conf *IndexWriterConfig) MergedSegmentWarmer() IndexReaderWarmer {
+	return conf.mergedSegmentWarmer
+}
+
+/*
+Expert: controls when segments are flushed to disk during indexing.
+The FlushPolicy is consulted after each added document and buffered
+doc values update, to decide which segments to flush. The default is
+FlushByRamOrCountsPolicy.
+*/
+func (conf *IndexWriterConfig) setFlushPolicy(flushPolicy FlushPolicy) *IndexWriterConfig {
+	conf.flushPolicy = flushPolicy
+	return conf
+}
+
+func (conf *IndexWriterConfig) String() string {
+	return fmt.Sprintf("openMode=%v\nmaxBufferedDocs=%v\nmaxBufferedDeleteTerms=%v\nramBufferSizeMB=%v\nmaxThreadStates=%v\ncodec=%v\nanalyzer=%T\nsimilarity=%T\nmergePolicy=%v\nmergeScheduler=%T\ndelPolicy=%T\nflushPolicy=%T\nmergedSegmentWarmer=%T\n",
+		conf.openMode, conf.maxBufferedDocs, conf.maxBufferedDeleteTerms, conf.ramBufferSizeMB,
+		conf.maxThreadStates, conf.codec.Name, conf.analyzer, conf.similarity, conf.mergePolicy,
+		conf.mergeScheduler, conf.delPolicy, conf.flushPolicy, conf.mergedSegmentWarmer)
+}
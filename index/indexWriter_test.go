@@ -0,0 +1,1542 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+var testIndexedStoredType = func() *document.FieldType {
+	ft := document.NewFieldType()
+	ft.SetIndexed(true)
+	ft.SetStored(true)
+	ft.Freeze()
+	return ft
+}()
+
+var testIndexedType = func() *document.FieldType {
+	ft := document.NewFieldType()
+	ft.SetIndexed(true)
+	ft.Freeze()
+	return ft
+}()
+
+func newTestDoc(i int) []document.IndexableField {
+	parity := "even"
+	if i%2 == 1 {
+		parity = "odd"
+	}
+	return []document.IndexableField{
+		document.NewField("id", fmt.Sprintf("%04d", i), testIndexedStoredType),
+		document.NewField("parity", parity, testIndexedType),
+		document.NewField("parity", parity, testIndexedType), // freq 2
+		document.NewStoredFieldFromString("body", fmt.Sprintf("document number %v", i)),
+		document.NewStoredFieldFromInt("num", i),
+	}
+}
+
+func openTestIndexWriter(t *testing.T, d store.Directory, mode OpenMode, maxBufferedDocs int) *IndexWriter {
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetOpenMode(mode).SetMaxBufferedDocs(maxBufferedDocs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestIndexWriter(t *testing.T) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// more than one block of postings for the parity terms
+	const numDocs = 300
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, IWC_DISABLE_AUTO_FLUSH)
+	for i := 0; i < numDocs; i++ {
+		if err = w.AddDocument(newTestDoc(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertEquals(t, numDocs, w.MaxDoc())
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.AddDocument(newTestDoc(0)); err == nil {
+		t.Error("Should not add to a closed writer")
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, len(r.Leaves()))
+	assertEquals(t, numDocs, r.MaxDoc())
+
+	// terms are sorted, with their stats
+	termsEnum := GetMultiTerms(r, "id").Iterator(nil)
+	count := 0
+	for term, err := termsEnum.Next(); term != nil; term, err = termsEnum.Next() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, fmt.Sprintf("%04d", count), string(term))
+		assertEquals(t, 1, termsEnum.DocFreq())
+		docs := collectDocs(termsEnum.Docs(nil, DOCS_ENUM_EMPTY))
+		if len(docs) != 1 || docs[0] != count {
+			t.Errorf("Unexpected docs %v for term %v", docs, string(term))
+		}
+		count++
+	}
+	assertEquals(t, numDocs, count)
+
+	termsEnum = GetMultiTerms(r, "parity").Iterator(nil)
+	if ok, err := termsEnum.SeekExact([]byte("odd")); !ok || err != nil {
+		t.Fatalf("Term not found: %v", err)
+	}
+	assertEquals(t, numDocs/2, termsEnum.DocFreq())
+	assertEquals(t, int64(numDocs), termsEnum.TotalTermFreq())
+	docsEnum := termsEnum.Docs(nil, DOCS_ENUM_EMPTY)
+	for i := 1; i < numDocs; i += 2 {
+		if docID, ok := docsEnum.NextDoc(); !ok || docID != i {
+			t.Fatalf("Expected doc %v, but was %v", i, docID)
+		}
+		assertEquals(t, 2, docsEnum.Freq())
+	}
+	if docID, ok := docsEnum.NextDoc(); ok || docID != NO_MORE_DOCS {
+		t.Errorf("Expected no more docs, but was %v", docID)
+	}
+	// skip data
+	docsEnum = termsEnum.Docs(nil, DOCS_ENUM_EMPTY)
+	if docID, ok := docsEnum.Advance(280); !ok || docID != 281 {
+		t.Errorf("Expected to advance to 281, but was %v", docID)
+	}
+	if ok, _ := termsEnum.SeekExact([]byte("none")); ok {
+		t.Error("Term 'none' should not exist")
+	}
+
+	// stored fields
+	visitor := NewDocumentStoredFieldVisitor()
+	if err = r.Document(123, visitor); err != nil {
+		t.Fatal(err)
+	}
+	doc := visitor.Document()
+	assertEquals(t, "0123", doc.Get("id"))
+	assertEquals(t, "document number 123", doc.Get("body"))
+	assertEquals(t, "123", doc.Get("num"))
+	assertEquals(t, "", doc.Get("parity"))
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, true)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+
+	// append more segments, keeping the field numbers
+	w = openTestIndexWriter(t, d, OPEN_MODE_APPEND, 40)
+	assertEquals(t, numDocs, w.NumDocs())
+	for i := numDocs; i < numDocs+100; i++ {
+		if err = w.AddDocument(newTestDoc(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	r, err = OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 4, len(r.Leaves()))
+	assertEquals(t, numDocs+100, r.NumDocs())
+	if n, err := r.DocFreq(NewTerm("parity", "even")); err != nil || n != (numDocs+100)/2 {
+		t.Errorf("Expected docFreq %v, but was %v (%v)", (numDocs+100)/2, n, err)
+	}
+	visitor = NewDocumentStoredFieldVisitor()
+	if err = r.Document(numDocs+42, visitor); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, fmt.Sprintf("%04d", numDocs+42), visitor.Document().Get("id"))
+	for _, leaf := range r.Leaves() {
+		fis := leaf.Reader().(*SegmentReader).FieldInfos()
+		assertEquals(t, int32(0), fis.byName["id"].number)
+		assertEquals(t, int32(3), fis.byName["num"].number)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// create discards the existing segments
+	w = openTestIndexWriter(t, d, OPEN_MODE_CREATE, IWC_DISABLE_AUTO_FLUSH)
+	if err = w.AddDocument(newTestDoc(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err = OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, r.MaxDoc())
+	assertEquals(t, "segments_3", r.(*StandardDirectoryReader).segmentInfos.SegmentsFileName())
+}
+
+func TestIndexWriterEmptyIndex(t *testing.T) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = NewIndexWriter(d, NewIndexWriterConfig().SetOpenMode(OPEN_MODE_APPEND)); err == nil {
+		t.Error("Should not append to a missing index")
+	}
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE_OR_APPEND, IWC_DISABLE_AUTO_FLUSH)
+	if err = w.AddDocument([]document.IndexableField{document.NewStoredFieldFromBytes("id", []byte{1})}); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, r.MaxDoc())
+	if GetMultiTerms(r, "id") != nil {
+		t.Error("Stored only field should have no terms")
+	}
+}
+
+func TestIndexWriterConcurrent(t *testing.T) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const numGoroutines, numDocsPerGoroutine = 4, 250
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetMaxBufferedDocs(100).SetMaxThreadStates(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := make(chan error, numGoroutines)
+	for g := 0; g < numGoroutines; g++ {
+		go func(g int) {
+			for i := 0; i < numDocsPerGoroutine; i++ {
+				if err := w.AddDocument(newTestDoc(g*numDocsPerGoroutine + i)); err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}(g)
+	}
+	for g := 0; g < numGoroutines; g++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	const numDocs = numGoroutines * numDocsPerGoroutine
+	assertEquals(t, numDocs, w.MaxDoc())
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, numDocs, r.MaxDoc())
+	if n, err := r.DocFreq(NewTerm("parity", "odd")); err != nil || n != numDocs/2 {
+		t.Errorf("Expected docFreq %v, but was %v (%v)", numDocs/2, n, err)
+	}
+
+	// every document is indexed once, with its stored fields
+	seen := make([]bool, numDocs)
+	termsEnum := GetMultiTerms(r, "id").Iterator(nil)
+	for term, err := termsEnum.Next(); term != nil; term, err = termsEnum.Next() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs := collectDocs(termsEnum.Docs(nil, DOCS_ENUM_EMPTY))
+		if len(docs) != 1 {
+			t.Fatalf("Expected one doc for term %v, but was %v", string(term), docs)
+		}
+		visitor := NewDocumentStoredFieldVisitor()
+		if err = r.Document(docs[0], visitor); err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, string(term), visitor.Document().Get("id"))
+		var id int
+		fmt.Sscanf(string(term), "%d", &id)
+		seen[id] = true
+	}
+	for id, ok := range seen {
+		if !ok {
+			t.Errorf("Document %v is missing", id)
+		}
+	}
+}
+
+func TestIndexWriterTermVectors(t *testing.T) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vectorsType := document.NewFieldTypeFrom(testIndexedType)
+	vectorsType.SetStoreTermVectors(true)
+	vectorsType.SetStoreTermVectorPositions(true)
+	vectorsType.SetStoreTermVectorOffsets(true)
+	vectorsType.Freeze()
+
+	badType := document.NewFieldTypeFrom(testIndexedType)
+	badType.SetStoreTermVectorOffsets(true)
+	badType.Freeze()
+
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 30)
+	if err = w.AddDocument([]document.IndexableField{
+		document.NewField("tags", "x", badType),
+	}); err == nil {
+		t.Error("Should reject offsets without term vectors")
+	}
+	const numDocs = 50
+	for i := 0; i < numDocs; i++ {
+		doc := newTestDoc(i)
+		if i%3 != 0 {
+			doc = append(doc,
+				document.NewField("tags", "red", vectorsType),
+				document.NewField("tags", fmt.Sprintf("size%v", i%4), vectorsType),
+				document.NewField("tags", "red", vectorsType))
+		}
+		if err = w.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 2, len(r.Leaves()))
+	for i := 0; i < numDocs; i++ {
+		fields, err := r.TermVectors(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i%3 == 0 {
+			if fields != nil && fields.Terms("tags") != nil {
+				t.Errorf("Doc %v should have no term vectors", i)
+			}
+			continue
+		}
+		if fields == nil || fields.Terms("tags") == nil {
+			t.Fatalf("Doc %v should have term vectors", i)
+		}
+		if fields.Terms("id") != nil {
+			t.Errorf("Doc %v should have no term vectors of id", i)
+		}
+		size := fmt.Sprintf("size%v", i%4)
+		expected := []struct {
+			term      string
+			positions []int
+			starts    []int
+		}{
+			{"red", []int{0, 2}, []int{0, 3 + len(size)}},
+			{size, []int{1}, []int{3}},
+		}
+		termsEnum := fields.Terms("tags").Iterator(nil)
+		for _, e := range expected {
+			term, err := termsEnum.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEquals(t, e.term, string(term))
+			assertEquals(t, int64(len(e.positions)), termsEnum.TotalTermFreq())
+			dpEnum := termsEnum.DocsAndPositionsByFlags(nil, DocsAndPositionsEnum{},
+				DOCS_POSITIONS_ENUM_FLAG_OFF_SETS)
+			if d, more := dpEnum.NextDoc(); !more || d != 0 {
+				t.Fatalf("Expected doc 0, but was %v", d)
+			}
+			for k, position := range e.positions {
+				assertEquals(t, position, dpEnum.NextPosition())
+				assertEquals(t, e.starts[k], dpEnum.StartOffset())
+				assertEquals(t, e.starts[k]+len(e.term), dpEnum.EndOffset())
+			}
+		}
+		if term, _ := termsEnum.Next(); term != nil {
+			t.Errorf("Unexpected term %v", string(term))
+		}
+	}
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, true)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+}
+
+func TestIndexWriterDocValues(t *testing.T) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 100)
+	if err = w.AddDocument([]document.IndexableField{
+		document.NewNumericDocValuesField("num", 1),
+		document.NewNumericDocValuesField("num", 2),
+	}); err == nil {
+		t.Error("Should reject a numeric doc values field appearing twice")
+	}
+	const numDocs = 50
+	for i := 0; i < numDocs; i++ {
+		doc := newTestDoc(i)
+		if i%5 != 4 {
+			doc = append(doc, document.NewNumericDocValuesField("num", int64(i*3-30)))
+		}
+		doc = append(doc,
+			document.NewBinaryDocValuesField("bin", []byte(fmt.Sprintf("value%v", i))),
+			document.NewSortedDocValuesField("sorted", []byte(fmt.Sprintf("s%v", i%7))))
+		if i%2 == 0 {
+			doc = append(doc,
+				document.NewSortedSetDocValuesField("set", []byte(fmt.Sprintf("c%v", i%3))),
+				document.NewSortedSetDocValuesField("set", []byte("a")),
+				document.NewSortedSetDocValuesField("set", []byte("a")))
+		}
+		if err = w.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.AddDocument([]document.IndexableField{
+		document.NewBinaryDocValuesField("num", []byte("x")),
+	}); err == nil {
+		t.Error("Should reject changing the doc values type of a field")
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, len(r.Leaves()))
+	sr := r.Leaves()[0].Reader().(*SegmentReader)
+	num, err := sr.NumericDocValues("num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin, err := sr.BinaryDocValues("bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted, err := sr.SortedDocValues("sorted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := sr.SortedSetDocValues("set")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 7, sorted.ValueCount())
+	assertEquals(t, int64(4), set.ValueCount())
+	for i := 0; i < numDocs; i++ {
+		expected := int64(i*3 - 30)
+		if i%5 == 4 {
+			expected = 0
+		}
+		assertEquals(t, expected, num.Get(i))
+		assertEquals(t, fmt.Sprintf("value%v", i), string(bin.Get(i)))
+		assertEquals(t, i%7, sorted.Ord(i))
+		assertEquals(t, fmt.Sprintf("s%v", i%7), string(sorted.Get(i)))
+
+		var values []string
+		set.SetDocument(i)
+		for ord := set.NextOrd(); ord != NO_MORE_ORDS; ord = set.NextOrd() {
+			values = append(values, string(set.LookupOrd(ord)))
+		}
+		if i%2 == 0 {
+			assertEquals(t, fmt.Sprintf("[a c%v]", i%3), fmt.Sprintf("%v", values))
+		} else if len(values) != 0 {
+			t.Errorf("Doc %v should have no values, but was %v", i, values)
+		}
+	}
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, true)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+}
+
+// Uses the number of terms of a field as its norm.
+type lengthSimilarity struct{}
+
+func (s lengthSimilarity) ComputeNorm(state *FieldInvertState) int64 {
+	return int64(state.Length())
+}
+
+func TestIndexWriterNorms(t *testing.T) {
+	for _, v := range []struct{ length, norm int }{{1, 124}, {4, 120}, {100, 110}} {
+		state := newFieldInvertState("body")
+		state.reset()
+		state.length = v.length
+		assertEquals(t, int64(v.norm), defaultSimilarity{}.ComputeNorm(state))
+	}
+
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	omitNormsType := document.NewFieldTypeFrom(testIndexedType)
+	omitNormsType.SetOmitNorms(true)
+	omitNormsType.Freeze()
+
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetSimilarity(lengthSimilarity{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const numDocs = 20
+	for i := 0; i < numDocs; i++ {
+		doc := newTestDoc(i)
+		if i%5 != 0 {
+			for j := 0; j <= i%4; j++ {
+				doc = append(doc, document.NewField("body", fmt.Sprintf("term%v", j), testIndexedType))
+			}
+		}
+		doc = append(doc, document.NewField("omit", "x", omitNormsType))
+		if err = w.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ar := r.Leaves()[0].Reader().(AtomicReader)
+	body, err := ar.NormValues("body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parity, err := ar.NormValues("parity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body == nil || parity == nil {
+		t.Fatal("Indexed fields should have norms")
+	}
+	for i := 0; i < numDocs; i++ {
+		expected := int64(i%4 + 1)
+		if i%5 == 0 {
+			expected = 0
+		}
+		assertEquals(t, expected, body.Get(i))
+		assertEquals(t, int64(2), parity.Get(i))
+	}
+	if v, err := ar.NormValues("omit"); v != nil || err != nil {
+		t.Errorf("Field omitting norms should have no norms, but was %v (%v)", v, err)
+	}
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, true)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+}
+
+func TestIndexWriterIndexOptions(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 10)
+	// "tag" starts with freqs, and is downgraded by the second document
+	if err := w.AddDocument([]document.IndexableField{
+		document.NewStringField("id", "0", document.STORE_YES),
+		document.NewField("tag", "a", testIndexedType),
+		document.NewField("tag", "a", testIndexedType),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddDocument([]document.IndexableField{
+		document.NewStringField("id", "1", document.STORE_NO),
+		document.NewStringField("tag", "a", document.STORE_NO),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ar := r.Leaves()[0].Reader().(AtomicReader)
+	infos := ar.FieldInfos()
+	assertEquals(t, INDEX_OPT_DOCS_ONLY, infos.byName["id"].indexOptions)
+	assertEquals(t, INDEX_OPT_DOCS_ONLY, infos.byName["tag"].indexOptions)
+	if v, err := ar.NormValues("id"); v != nil || err != nil {
+		t.Errorf("String field should have no norms, but was %v (%v)", v, err)
+	}
+
+	termsEnum := ar.Terms("tag").Iterator(nil)
+	if found, err := termsEnum.SeekExact([]byte("a")); err != nil || !found {
+		t.Fatalf("Term should be found (%v)", err)
+	}
+	assertEquals(t, 2, termsEnum.DocFreq())
+	assertEquals(t, int64(-1), termsEnum.TotalTermFreq())
+
+	visitor := NewDocumentStoredFieldVisitor()
+	if err = ar.Document(1, visitor); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, len(visitor.Document().Fields()))
+}
+
+type testToken struct {
+	term                   string
+	posIncr                int
+	startOffset, endOffset int
+}
+
+// An IndexableField whose value is a fixed list of tokens, which is
+// its own TokenStream.
+type testTokensField struct {
+	*analysis.TokenStreamImpl
+	termAtt    analysis.CharTermAttribute
+	posIncrAtt analysis.PositionIncrementAttribute
+	offsetAtt  analysis.OffsetAttribute
+	name       string
+	ft         document.IndexableFieldType
+	boost      float32
+	tokens     []testToken
+	next       int
+	closed     bool
+}
+
+func newTestTokensField(name string, ft document.IndexableFieldType, boost float32,
+	tokens ...testToken) *testTokensField {
+	ans := &testTokensField{TokenStreamImpl: analysis.NewTokenStream(),
+		name: name, ft: ft, boost: boost, tokens: tokens}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.posIncrAtt = ans.Attributes().Add("PositionIncrementAttribute").(analysis.PositionIncrementAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(analysis.OffsetAttribute)
+	return ans
+}
+
+func (f *testTokensField) Name() string                           { return f.name }
+func (f *testTokensField) FieldType() document.IndexableFieldType { return f.ft }
+func (f *testTokensField) BinaryValue() []byte                    { return nil }
+func (f *testTokensField) StringValue() string                    { return "" }
+func (f *testTokensField) NumericValue() interface{}              { return nil }
+func (f *testTokensField) Boost() float32                         { return f.boost }
+
+func (f *testTokensField) TokenStream(analyzer analysis.Analyzer) (analysis.TokenStream, error) {
+	return f, nil
+}
+
+func (f *testTokensField) Reset() error {
+	f.next = 0
+	return nil
+}
+
+func (f *testTokensField) IncrementToken() (bool, error) {
+	if f.next == len(f.tokens) {
+		return false, nil
+	}
+	token := f.tokens[f.next]
+	f.Attributes().Clear()
+	f.termAtt.Append(token.term)
+	f.posIncrAtt.SetPositionIncrement(token.posIncr)
+	f.offsetAtt.SetOffset(token.startOffset, token.endOffset)
+	f.next++
+	return true, nil
+}
+
+func (f *testTokensField) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestIndexWriterIndexableField(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	vectorsType := document.NewFieldTypeFrom(testIndexedType)
+	vectorsType.SetStoreTermVectors(true)
+	vectorsType.SetStoreTermVectorPositions(true)
+	vectorsType.SetStoreTermVectorOffsets(true)
+	vectorsType.Freeze()
+
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 10)
+	omitted := newTestTokensField("id", document.STRING_FIELD_TYPE_NOT_STORED, 2, testToken{"x", 1, 0, 1})
+	valid := newTestTokensField("text", testIndexedType, 1, testToken{"x", 1, 0, 1})
+	if err := w.AddDocument([]document.IndexableField{valid, omitted}); err == nil {
+		t.Error("Should reject a boost on a field omitting norms")
+	}
+	if !valid.closed {
+		t.Error("TokenStream of a rejected document should be closed")
+	}
+
+	field := newTestTokensField("text", vectorsType, 2,
+		testToken{"quick", 1, 0, 5},
+		testToken{"fast", 0, 0, 5}, // synonym
+		testToken{"fox", 2, 10, 13})
+	if err := w.AddDocument([]document.IndexableField{
+		field,
+		document.NewField("text", "dog", vectorsType),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !field.closed {
+		t.Error("TokenStream should be closed")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, r.NumDocs())
+	ar := r.Leaves()[0].Reader().(AtomicReader)
+	norms, err := ar.NormValues("text")
+	if err != nil || norms == nil {
+		t.Fatalf("Field should have norms (%v)", err)
+	}
+	state := newFieldInvertState("text")
+	state.reset()
+	state.length, state.numOverlap, state.boost = 4, 1, 2
+	assertEquals(t, defaultSimilarity{}.ComputeNorm(state), norms.Get(0))
+
+	fields, err := r.TermVectors(0)
+	if err != nil || fields == nil || fields.Terms("text") == nil {
+		t.Fatalf("Doc should have term vectors (%v)", err)
+	}
+	// the second instance starts where the first one ends
+	expected := []struct {
+		term          string
+		position      int
+		start, finish int
+	}{
+		{"dog", 3, 13, 16},
+		{"fast", 0, 0, 5},
+		{"fox", 2, 10, 13},
+		{"quick", 0, 0, 5},
+	}
+	termsEnum := fields.Terms("text").Iterator(nil)
+	for _, e := range expected {
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, e.term, string(term))
+		dpEnum := termsEnum.DocsAndPositionsByFlags(nil, DocsAndPositionsEnum{},
+			DOCS_POSITIONS_ENUM_FLAG_OFF_SETS)
+		if d, more := dpEnum.NextDoc(); !more || d != 0 {
+			t.Fatalf("Expected doc 0, but was %v", d)
+		}
+		assertEquals(t, e.position, dpEnum.NextPosition())
+		assertEquals(t, e.start, dpEnum.StartOffset())
+		assertEquals(t, e.finish, dpEnum.EndOffset())
+	}
+	if term, _ := termsEnum.Next(); term != nil {
+		t.Errorf("Unexpected term %v", string(term))
+	}
+}
+
+// Tokenizes its input at spaces, and leaves a gap of 10 positions
+// between the instances of a field.
+type testSpaceAnalyzer struct {
+	*analysis.AnalyzerImpl
+}
+
+func newTestSpaceAnalyzer() *testSpaceAnalyzer {
+	ans := new(testSpaceAnalyzer)
+	ans.AnalyzerImpl = analysis.NewAnalyzer(ans)
+	return ans
+}
+
+func (a *testSpaceAnalyzer) CreateComponents(fieldName string, reader io.Reader) *analysis.TokenStreamComponents {
+	return analysis.NewTokenStreamComponents(newTestSpaceTokenizer(reader), nil)
+}
+
+func (a *testSpaceAnalyzer) PositionIncrementGap(fieldName string) int {
+	return 10
+}
+
+type testSpaceTokenizer struct {
+	*analysis.TokenizerImpl
+	termAtt   analysis.CharTermAttribute
+	offsetAtt analysis.OffsetAttribute
+	text      []byte
+	offset    int
+}
+
+func newTestSpaceTokenizer(input io.Reader) *testSpaceTokenizer {
+	ans := &testSpaceTokenizer{TokenizerImpl: analysis.NewTokenizer(input)}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(analysis.CharTermAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(analysis.OffsetAttribute)
+	return ans
+}
+
+func (t *testSpaceTokenizer) Reset() (err error) {
+	t.text, err = ioutil.ReadAll(t.Input)
+	t.offset = 0
+	return err
+}
+
+func (t *testSpaceTokenizer) IncrementToken() (bool, error) {
+	for t.offset < len(t.text) && t.text[t.offset] == ' ' {
+		t.offset++
+	}
+	if t.offset == len(t.text) {
+		return false, nil
+	}
+	start := t.offset
+	for t.offset < len(t.text) && t.text[t.offset] != ' ' {
+		t.offset++
+	}
+	t.Attributes().Clear()
+	t.termAtt.AppendBytes(t.text[start:t.offset])
+	t.offsetAtt.SetOffset(start, t.offset)
+	return true, nil
+}
+
+func (t *testSpaceTokenizer) End() error {
+	t.offsetAtt.SetOffset(len(t.text), len(t.text))
+	return nil
+}
+
+func TestIndexWriterAnalyzer(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	vectorsType := document.NewFieldTypeFrom(document.TEXT_FIELD_TYPE_NOT_STORED)
+	vectorsType.SetStoreTermVectors(true)
+	vectorsType.SetStoreTermVectorPositions(true)
+	vectorsType.SetStoreTermVectorOffsets(true)
+	vectorsType.Freeze()
+
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetAnalyzer(newTestSpaceAnalyzer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.AddDocument([]document.IndexableField{
+		document.NewField("text", "quick fox ", vectorsType),
+		document.NewField("text", "lazy dog", vectorsType),
+		document.NewStringField("id", "a b", document.STORE_NO),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ar := r.Leaves()[0].Reader().(AtomicReader)
+	// untokenized fields are not analyzed
+	found, err := ar.Terms("id").Iterator(nil).SeekExact([]byte("a b"))
+	if err != nil || !found {
+		t.Errorf("Term should be found (%v)", err)
+	}
+
+	fields, err := r.TermVectors(0)
+	if err != nil || fields == nil || fields.Terms("text") == nil {
+		t.Fatalf("Doc should have term vectors (%v)", err)
+	}
+	// the instances are separated by the gaps of the analyzer
+	expected := []struct {
+		term          string
+		position      int
+		start, finish int
+	}{
+		{"dog", 13, 16, 19},
+		{"fox", 1, 6, 9},
+		{"lazy", 12, 11, 15},
+		{"quick", 0, 0, 5},
+	}
+	termsEnum := fields.Terms("text").Iterator(nil)
+	for _, e := range expected {
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, e.term, string(term))
+		dpEnum := termsEnum.DocsAndPositionsByFlags(nil, DocsAndPositionsEnum{},
+			DOCS_POSITIONS_ENUM_FLAG_OFF_SETS)
+		if d, more := dpEnum.NextDoc(); !more || d != 0 {
+			t.Fatalf("Expected doc 0, but was %v", d)
+		}
+		assertEquals(t, e.position, dpEnum.NextPosition())
+		assertEquals(t, e.start, dpEnum.StartOffset())
+		assertEquals(t, e.finish, dpEnum.EndOffset())
+	}
+}
+
+func TestIndexWriterNumericFields(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	const numDocs = 20
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, numDocs)
+	for i := 0; i < numDocs; i++ {
+		if err := w.AddDocument([]document.IndexableField{
+			document.NewIntField("price", i*10, document.STORE_YES),
+			document.NewLongField("time", int64(i)<<40, document.STORE_NO),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ar := r.Leaves()[0].Reader().(AtomicReader)
+	assertEquals(t, INDEX_OPT_DOCS_ONLY, ar.FieldInfos().byName["price"].indexOptions)
+	if v, err := ar.NormValues("price"); v != nil || err != nil {
+		t.Errorf("Numeric field should have no norms, but was %v (%v)", v, err)
+	}
+
+	// full precision terms sort after the lower precision ones
+	termsEnum := ar.Terms("price").Iterator(nil)
+	var full []int32
+	for term, err := termsEnum.Next(); term != nil; term, err = termsEnum.Next() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if shift, err := util.PrefixCodedIntShift(term); err != nil {
+			t.Fatal(err)
+		} else if shift == 0 {
+			v, err := util.PrefixCodedToInt(term)
+			if err != nil {
+				t.Fatal(err)
+			}
+			full = append(full, v)
+			assertEquals(t, 1, termsEnum.DocFreq())
+		}
+	}
+	assertEquals(t, numDocs, len(full))
+	for i, v := range full {
+		assertEquals(t, int32(i*10), v)
+	}
+
+	termsEnum = ar.Terms("time").Iterator(nil)
+	found, err := termsEnum.SeekExact(util.LongToPrefixCoded(int64(3)<<40, 0))
+	if err != nil || !found {
+		t.Fatalf("Term should be found (%v)", err)
+	}
+	// the same lower precision term is shared by docs 0 to 15
+	found, err = termsEnum.SeekExact(util.LongToPrefixCoded(0, 44))
+	if err != nil || !found {
+		t.Fatalf("Term should be found (%v)", err)
+	}
+	assertEquals(t, 16, termsEnum.DocFreq())
+
+	visitor := NewDocumentStoredFieldVisitor()
+	if err = ar.Document(3, visitor); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 30, visitor.Document().Field("price").NumericValue())
+}
+
+func TestIndexWriterTwoPhaseCommit(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 2)
+	addTestDocs(t, w, 4)
+	if err := w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 4, r.MaxDoc())
+
+	addTestDocs(t, w, 3)
+	if err = w.PrepareCommit(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.PrepareCommit(); err == nil {
+		t.Error("Should not prepare a commit twice")
+	}
+	// the prepared commit is not visible yet
+	if !r.IsCurrent() {
+		t.Error("Reader should be current before the commit is finished")
+	}
+	r2, err := OpenIfChanged(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r2 != nil {
+		t.Error("Reader should not see a prepared commit")
+	}
+
+	// not part of the prepared commit
+	addTestDocs(t, w, 1)
+	if err = w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if r2, err = OpenIfChanged(r); err != nil {
+		t.Fatal(err)
+	}
+	if r2 == nil {
+		t.Fatal("Reader should see the finished commit")
+	}
+	defer r2.Close()
+	assertEquals(t, 7, r2.MaxDoc())
+
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r3, err := OpenIfChanged(r2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r3 == nil {
+		t.Fatal("Reader should see the last commit")
+	}
+	defer r3.Close()
+	assertEquals(t, 8, r3.MaxDoc())
+}
+
+func TestIndexWriterRollback(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 2)
+	addTestDocs(t, w, 4)
+	if err := w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	committed, err := d.ListAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addTestDocs(t, w, 5)
+	if err = w.PrepareCommit(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.AddDocument(newTestDoc(0)); err == nil {
+		t.Error("Should not add documents after rollback")
+	}
+	if err = w.Rollback(); err != nil {
+		t.Error("Rollback should be a no-op once closed")
+	}
+	// the flushed segments and the pending segments_N are removed
+	files, err := d.ListAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, fmt.Sprint(committed), fmt.Sprint(files))
+
+	w = openTestIndexWriter(t, d, OPEN_MODE_APPEND, 2)
+	addTestDocs(t, w, 1)
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 5, r.MaxDoc())
+}
+
+func TestIndexWriterCommitData(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 2)
+	addTestDocs(t, w, 3)
+	data := map[string]string{"checkpoint": "1"}
+	w.SetCommitData(data)
+	// the writer keeps its own copy
+	data["checkpoint"] = "2"
+	assertEquals(t, "1", w.CommitData()["checkpoint"])
+	if err := w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	userData, err := r.IndexCommit().UserData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "1", userData["checkpoint"])
+
+	// new commit data alone is a change to commit
+	w.SetCommitData(map[string]string{"checkpoint": "3"})
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files, err := d.ListAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := NewIndexCommitFromFiles(d, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, r.IndexCommit().Generation()+1, commit.Generation())
+	if userData, err = commit.UserData(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "3", userData["checkpoint"])
+
+	w = openTestIndexWriter(t, d, OPEN_MODE_APPEND, 2)
+	assertEquals(t, "3", w.CommitData()["checkpoint"])
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexWriterNRTReader(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, IWC_DISABLE_AUTO_FLUSH)
+	addTestDocs(t, w, 5)
+	r, err := OpenDirectoryReaderFromWriter(w, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	// the buffered documents are visible without a commit
+	assertEquals(t, 5, r.MaxDoc())
+	if _, err = OpenDirectoryReader(d); err == nil {
+		t.Error("Nothing should be committed yet")
+	}
+	if !r.IsCurrent() {
+		t.Error("Reader should be current")
+	}
+	r2, err := OpenIfChanged(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r2 != nil {
+		t.Error("Reader should not be reopened without changes")
+	}
+
+	if err = w.AddDocument(newTestDoc(5)); err != nil {
+		t.Fatal(err)
+	}
+	if r.IsCurrent() {
+		t.Error("Reader should not be current after adding a document")
+	}
+	if r2, err = OpenIfChanged(r); err != nil {
+		t.Fatal(err)
+	}
+	if r2 == nil {
+		t.Fatal("Reader should see the new document")
+	}
+	defer r2.Close()
+	assertEquals(t, 6, r2.MaxDoc())
+	assertEquals(t, 2, len(r2.Leaves()))
+	// the unchanged segment is shared
+	if r.Leaves()[0].Reader() != r2.Leaves()[0].Reader() {
+		t.Error("Reopened reader should share the unchanged segment")
+	}
+	if !strings.Contains(fmt.Sprint(r2), ":nrt") {
+		t.Errorf("Expected a near real time reader, but was %v", r2)
+	}
+
+	// the reader outlives the writer
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		visitor := NewDocumentStoredFieldVisitor()
+		if err = r2.Document(i, visitor); err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, fmt.Sprintf("%04d", i), visitor.Document().Get("id"))
+	}
+	if _, err = OpenDirectoryReaderFromWriter(w, true); err == nil {
+		t.Error("Should not open a reader from a closed writer")
+	}
+}
+
+func TestIndexWriterGetReader(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 3)
+	defer w.Close()
+	addTestDocs(t, w, 5)
+	// a flushed and a buffered document
+	if err := w.DeleteDocuments(NewTerm("id", "0001"), NewTerm("id", "0004")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := w.GetReader(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	// the documents are flushed, but the deletes stay pending
+	assertEquals(t, 5, r.MaxDoc())
+	assertEquals(t, 5, r.NumDocs())
+	if r.IsCurrent() {
+		t.Error("Reader should not be current with pending deletes")
+	}
+
+	r2, err := w.GetReader(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+	assertEquals(t, 5, r2.MaxDoc())
+	assertEquals(t, 3, r2.NumDocs())
+	if !r2.IsCurrent() {
+		t.Error("Reader should be current")
+	}
+	if _, err = OpenDirectoryReader(d); err == nil {
+		t.Error("Nothing should be committed yet")
+	}
+}
+
+func TestIndexWriterAddIndexes(t *testing.T) {
+	src, srcPath := openTestDirectory(t)
+	defer os.RemoveAll(srcPath)
+	w := openTestIndexWriter(t, src, OPEN_MODE_CREATE, 3)
+	for i := 0; i < 5; i++ {
+		doc := append(newTestDoc(i), document.NewNumericDocValuesField("count", int64(i)))
+		if err := w.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the copied segment keeps its doc values updates
+	if err := w.UpdateNumericDocValue(NewTerm("id", "0001"), "count", 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+	w = openTestIndexWriter(t, d, OPEN_MODE_CREATE, IWC_DISABLE_AUTO_FLUSH)
+	addTestDocs(t, w, 2)
+	if err := w.AddIndexes(src); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err = w.AddIndexesFromReaders(r); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// the buffered segment, the 2 copied ones and the merged one
+	assertSegmentCount(t, d, 4)
+
+	r2, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+	assertEquals(t, 12, r2.NumDocs())
+	var counts []int64
+	for _, ctx := range r2.Leaves() {
+		reader := ctx.Reader().(AtomicReader)
+		count, err := reader.NumericDocValues("count")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for doc := 0; doc < reader.MaxDoc(); doc++ {
+			if count != nil {
+				counts = append(counts, count.Get(doc))
+			}
+		}
+	}
+	assertEquals(t, "[0 100 2 3 4 0 100 2 3 4]", fmt.Sprint(counts))
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, false)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+}
+
+// Records the readers it warms, after looking up a term.
+type testReaderWarmer struct {
+	readers []AtomicReader
+}
+
+func (w *testReaderWarmer) Warm(reader AtomicReader) error {
+	if _, err := reader.DocFreq(NewTerm("id", "0000")); err != nil {
+		return err
+	}
+	w.readers = append(w.readers, reader)
+	return nil
+}
+
+func TestIndexWriterMergedSegmentWarmer(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	warmer := &testReaderWarmer{}
+	conf := NewIndexWriterConfig().SetMaxBufferedDocs(2).SetMergedSegmentWarmer(warmer)
+	w, err := NewIndexWriter(d, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	addTestDocs(t, w, 4)
+	if err = w.ForceMerge(1); err != nil {
+		t.Fatal(err)
+	}
+	// not in near real time mode yet
+	assertEquals(t, 0, len(warmer.readers))
+
+	r, err := OpenDirectoryReaderFromWriter(w, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	addTestDocs(t, w, 4)
+	if err = w.ForceMerge(1); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, len(warmer.readers))
+	assertEquals(t, 8, warmer.readers[0].MaxDoc())
+
+	r2, err := OpenIfChanged(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r2 == nil {
+		t.Fatal("Reader should see the merged segment")
+	}
+	defer r2.Close()
+	assertEquals(t, 1, len(r2.Leaves()))
+	// the near real time reader uses the warmed reader
+	if r2.Leaves()[0].Reader() != warmer.readers[0] {
+		t.Error("Reader should share the warmed segment reader")
+	}
+}
+
+func TestIndexWriterPositions(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	offsetsType := document.NewFieldTypeFrom(document.TEXT_FIELD_TYPE_NOT_STORED)
+	offsetsType.SetIndexOptions(document.INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS)
+	offsetsType.Freeze()
+
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().
+		SetAnalyzer(newTestSpaceAnalyzer()).SetMaxBufferedDocs(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// enough docs for "a" to fill blocks of docs and positions, and
+	// to write skip data
+	const numDocs = 300
+	for i := 0; i < numDocs; i++ {
+		text := strings.Repeat("b ", i%3) + "a b a"
+		if err = w.AddDocument([]document.IndexableField{
+			document.NewTextField("text", text, document.STORE_NO),
+			document.NewField("offsets", text, offsetsType),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, len(r.Leaves()))
+	ar := r.Leaves()[0].Reader().(AtomicReader)
+	infos := ar.FieldInfos()
+	assertEquals(t, INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS, infos.byName["text"].indexOptions)
+	assertEquals(t, INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS, infos.byName["offsets"].indexOptions)
+
+	for _, field := range []string{"text", "offsets"} {
+		termsEnum := ar.Terms(field).Iterator(nil)
+		if found, err := termsEnum.SeekExact([]byte("a")); err != nil || !found {
+			t.Fatalf("Term should be found (%v)", err)
+		}
+		assertEquals(t, int64(2*numDocs), termsEnum.TotalTermFreq())
+
+		dpEnum := termsEnum.DocsAndPositionsByFlags(nil, DocsAndPositionsEnum{}, 0)
+		for i := 0; i < numDocs; i++ {
+			if doc, more := dpEnum.NextDoc(); !more || doc != i {
+				t.Fatalf("%v: expected doc %v, but was %v", field, i, doc)
+			}
+			assertEquals(t, 2, dpEnum.Freq())
+			assertEquals(t, i%3, dpEnum.NextPosition())
+			assertEquals(t, i%3+2, dpEnum.NextPosition())
+		}
+		if doc, more := dpEnum.NextDoc(); more {
+			t.Errorf("%v: unexpected doc %v", field, doc)
+		}
+
+		// skips to the block of the target, and its positions
+		dpEnum = termsEnum.DocsAndPositionsByFlags(nil, dpEnum, 0)
+		if doc, more := dpEnum.Advance(250); !more || doc != 250 {
+			t.Fatalf("%v: expected doc 250, but was %v", field, doc)
+		}
+		assertEquals(t, 1, dpEnum.NextPosition())
+		assertEquals(t, 3, dpEnum.NextPosition())
+	}
+}
+
+func TestIndexWriterAddIndexesFromPositionalReader(t *testing.T) {
+	src, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 10)
+	if err = w.AddIndexesFromReaders(r); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r2, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+	assertEquals(t, 1, len(r2.Leaves()))
+	assertEquals(t, r.NumDocs(), r2.NumDocs())
+	expected := r.Leaves()[0].Reader().(AtomicReader)
+	actual := r2.Leaves()[0].Reader().(AtomicReader)
+	assertEquals(t, INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS, actual.FieldInfos().byName["content"].indexOptions)
+	assertEquals(t, fmt.Sprint(allPositions(t, expected, "content")), fmt.Sprint(allPositions(t, actual, "content")))
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, false)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+}
@@ -0,0 +1,78 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+)
+
+/*
+A buffered delete of all documents containing the term, applied to
+the segments like a doc values update.
+*/
+type termDelete struct {
+	term Term
+	// the delete only applies to the documents below docIDUpto
+	docIDUpto int
+	// names of the segments the delete still has to be applied to
+	segments map[string]bool
+}
+
+// Rough number of bytes used by a buffered delete besides its term.
+const BYTES_PER_DEL_TERM = 64
+
+// Returns the approximate RAM used by the buffered deletes.
+func bufferedDeletesBytes(deletes []*termDelete) int64 {
+	var ans int64
+	for _, del := range deletes {
+		ans += int64(BYTES_PER_DEL_TERM + len(del.term.Field) + len(del.term.Bytes))
+	}
+	return ans
+}
+
+// ReadersAndLiveDocs.java/writeLiveDocs
+
+/*
+Deletes the documents of the segment matching the deletes, and writes
+its live docs as a new deletion generation. Returns the segment info
+referencing the new generation, or info itself if no live document of
+the segment matches any delete.
+*/
+func writeLiveDocs(dir store.Directory, info SegmentInfoPerCommit,
+	deletes []*termDelete) (SegmentInfoPerCommit, error) {
+	reader, err := NewSegmentReader(info, DEFAULT_TERMS_INDEX_DIVISOR, store.IO_CONTEXT_READONCE)
+	if err != nil {
+		return info, err
+	}
+	defer reader.decRef()
+
+	var liveDocs util.MutableBits
+	newDelCount := 0
+	for _, del := range deletes {
+		docs, err := matchingDocs(reader, del.term)
+		if err != nil {
+			return info, err
+		}
+		// the documents added after the delete are kept
+		for _, doc := range docs[:sort.SearchInts(docs, del.docIDUpto)] {
+			if liveDocs == nil {
+				liveDocs = info.info.codec.NewLiveDocs(int(info.info.docCount), reader.LiveDocs())
+			}
+			if liveDocs.Get(doc) {
+				liveDocs.Clear(doc)
+				newDelCount++
+			}
+		}
+	}
+	if newDelCount == 0 {
+		return info, nil
+	}
+
+	if err = info.info.codec.WriteLiveDocs(dir, info, liveDocs, newDelCount, store.IO_CONTEXT_DEFAULT); err != nil {
+		return info, err
+	}
+	ans := info
+	ans.delCount += newDelCount
+	ans.delGen, ans.nextWriteDelGen = info.nextWriteDelGen, info.nextWriteDelGen+1
+	return ans, nil
+}
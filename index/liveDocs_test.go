@@ -0,0 +1,170 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"os"
+	"testing"
+)
+
+// Returns how many live documents of the reader have each id.
+func testLiveIDs(t *testing.T, r IndexReader) map[string]int {
+	ids := make(map[string]int)
+	for _, ctx := range r.Leaves() {
+		reader := ctx.Reader().(AtomicReader)
+		liveDocs := reader.LiveDocs()
+		for doc := 0; doc < reader.MaxDoc(); doc++ {
+			if liveDocs != nil && !liveDocs.Get(doc) {
+				continue
+			}
+			visitor := NewDocumentStoredFieldVisitor()
+			if err := reader.Document(doc, visitor); err != nil {
+				t.Fatal(err)
+			}
+			ids[visitor.Document().Get("id")]++
+		}
+	}
+	return ids
+}
+
+func TestDeleteDocuments(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetMaxBufferedDocs(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 flushed segments, and buffered documents
+	const numDocs = 25
+	for i := 0; i < numDocs; i++ {
+		if err = w.AddDocument(newTestDoc(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.DeleteDocuments(NewTerm("id", "0003"), NewTerm("id", "0022"), NewTerm("id", "missing")); err != nil {
+		t.Fatal(err)
+	}
+	// not affected by the previous delete
+	if err = w.AddDocument(newTestDoc(3)); err != nil {
+		t.Fatal(err)
+	}
+	// the deletes are only counted once applied
+	assertEquals(t, numDocs+1, w.NumDocs())
+	if err = w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, numDocs+1, w.MaxDoc())
+	assertEquals(t, numDocs-1, w.NumDocs())
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, numDocs-1, r.NumDocs())
+	ids := testLiveIDs(t, r)
+	assertEquals(t, numDocs-1, len(ids))
+	assertEquals(t, 1, ids["0003"])
+	assertEquals(t, 0, ids["0022"])
+
+	// deleted again in the merged segment, which drops the deleted
+	// documents
+	if err = w.DeleteDocuments(NewTerm("parity", "odd")); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.ForceMerge(1); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r2, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+	assertEquals(t, 1, len(r2.Leaves()))
+	assertEquals(t, r2.MaxDoc(), r2.NumDocs())
+	ids = testLiveIDs(t, r2)
+	for i := 0; i < numDocs; i++ {
+		expected := 0
+		if i%2 == 0 && i != 22 {
+			expected = 1
+		}
+		assertEquals(t, expected, ids[fmt.Sprintf("%04d", i)])
+	}
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, false)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+}
+
+func TestDeleteDocumentsDuringMerge(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	mp := NewLogDocMergePolicy()
+	mp.SetMergeFactor(2)
+	mp.SetMinMergeDocs(2)
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetMaxBufferedDocs(2).
+		SetMergePolicy(mp).SetMergeScheduler(NoMergeScheduler{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	for i := 0; i < 4; i++ {
+		doc := append(newTestDoc(i), document.NewNumericDocValuesField("count", int64(i)))
+		if err = w.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	merge := w.NextMerge()
+	if merge == nil {
+		t.Fatal("The 2 flushed segments should be merged")
+	}
+	// applied to the segments being merged, after the merge read them
+	merge.wrapReaders = func(readers []AtomicReader) ([]AtomicReader, error) {
+		if err := w.DeleteDocuments(NewTerm("id", "0001")); err != nil {
+			return nil, err
+		}
+		if err := w.UpdateNumericDocValue(NewTerm("id", "0002"), "count", 100); err != nil {
+			return nil, err
+		}
+		r, err := OpenDirectoryReaderFromWriter(w, true)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		assertEquals(t, 3, r.NumDocs())
+		return readers, nil
+	}
+	if err = w.Merge(merge); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, len(r.Leaves()))
+	assertEquals(t, 3, r.NumDocs())
+	assertEquals(t, 0, testLiveIDs(t, r)["0001"])
+	count, err := r.Leaves()[0].Reader().(AtomicReader).NumericDocValues("count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, int64(100), count.Get(2))
+}
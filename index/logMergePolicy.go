@@ -0,0 +1,839 @@
+package index
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// LogMergePolicy.java
+
+const (
+	/*
+		Defines the allowed range of log(size) for each level. A level
+		is computed by taking the max segment log size, minus
+		LEVEL_LOG_SPAN, and finding all segments falling within that
+		range.
+	*/
+	LEVEL_LOG_SPAN = 0.75
+	// Default merge factor, which is how many segments are merged at a
+	// time.
+	LMP_DEFAULT_MERGE_FACTOR = 10
+	// Default maximum segment size. A segment of this size or larger
+	// will never be merged.
+	LMP_DEFAULT_MAX_MERGE_DOCS = math.MaxInt32
+	/*
+		Default noCFSRatio. If a merge's size is >= 10% of the index,
+		then we disable compound file for it.
+	*/
+	LMP_DEFAULT_NO_CFS_RATIO = 0.1
+	// Default maxCFSSegmentSize value allows compound file for a
+	// segment of any size.
+	LMP_DEFAULT_MAX_CFS_SEGMENT_SIZE = math.MaxInt64
+)
+
+/*
+This class implements a MergePolicy that tries to merge segments into
+levels of exponentially increasing size, where each level has fewer
+segments than the value of the merge factor. Whenever extra segments
+(beyond the merge factor upper bound) are encountered, all segments
+within the level are merged. You can get or set the merge factor
+using MergeFactor() and SetMergeFactor() respectively.
+
+This class is abstract and requires a function to measure the size
+of each segment, which LogByteSizeMergePolicy and LogDocMergePolicy
+provide: the former measures size as the total byte size of the
+file(s) for the segment, the latter by the number of documents (not
+taking deletions into account).
+*/
+type LogMergePolicy struct {
+	writer *IndexWriter
+
+	// Measures the size of a segment.
+	size func(info SegmentInfoPerCommit) (int64, error)
+
+	// How many segments to merge at a time.
+	mergeFactor int
+	// Any segments whose size is smaller than this value will be
+	// rounded up to this value. This ensures that tiny segments are
+	// aggressively merged.
+	minMergeSize int64
+	// If the size of a segment exceeds this value then it will never
+	// be merged.
+	maxMergeSize int64
+	// If the size of a segment exceeds this value then it will never
+	// be merged during ForceMerge().
+	maxMergeSizeForForcedMerge int64
+	// If a segment has more than this many documents then it will
+	// never be merged.
+	maxMergeDocs int
+	// If the size of the merge segment exceeds this ratio of the total
+	// index size then it will remain in non-compound format even if
+	// useCompoundFile is true.
+	noCFSRatio float64
+	// If the size of the merged segment exceeds this value then it
+	// will not use compound file format.
+	maxCFSSegmentSize int64
+	// If true, we pro-rate a segment's size by the percentage of
+	// non-deleted documents.
+	calibrateSizeByDeletes bool
+	// True if new segments (flushed or merged) should use the compound
+	// file format.
+	useCompoundFile bool
+}
+
+func newLogMergePolicy(size func(info SegmentInfoPerCommit) (int64, error)) *LogMergePolicy {
+	return &LogMergePolicy{
+		size:                       size,
+		mergeFactor:                LMP_DEFAULT_MERGE_FACTOR,
+		maxMergeSizeForForcedMerge: math.MaxInt64,
+		maxMergeDocs:               LMP_DEFAULT_MAX_MERGE_DOCS,
+		noCFSRatio:                 LMP_DEFAULT_NO_CFS_RATIO,
+		maxCFSSegmentSize:          LMP_DEFAULT_MAX_CFS_SEGMENT_SIZE,
+		calibrateSizeByDeletes:     true,
+		useCompoundFile:            true,
+	}
+}
+
+func (mp *LogMergePolicy) SetIndexWriter(writer *IndexWriter) {
+	mp.writer = writer
+}
+
+func (mp *LogMergePolicy) message(format string, args ...interface{}) {
+	log.Printf("LMP: %v", fmt.Sprintf(format, args...))
+}
+
+// Returns the current noCFSRatio.
+func (mp *LogMergePolicy) NoCFSRatio() float64 {
+	return mp.noCFSRatio
+}
+
+/*
+If a merged segment will be more than this percentage of the total
+size of the index, leave the segment as non-compound file even if
+compound file is enabled. Set to 1.0 to always use CFS regardless of
+merge size.
+*/
+func (mp *LogMergePolicy) SetNoCFSRatio(noCFSRatio float64) {
+	if noCFSRatio < 0 || noCFSRatio > 1 {
+		panic(fmt.Sprintf("noCFSRatio must be 0.0 to 1.0 inclusive; got %v", noCFSRatio))
+	}
+	mp.noCFSRatio = noCFSRatio
+}
+
+/*
+Returns the number of segments that are merged at once and also
+controls the total number of segments allowed to accumulate in the
+index.
+*/
+func (mp *LogMergePolicy) MergeFactor() int {
+	return mp.mergeFactor
+}
+
+/*
+Determines how often segment indices are merged by AddDocument().
+With smaller values, less RAM is used while indexing, and searches
+are faster, but indexing speed is slower. With larger values, more
+RAM is used during indexing, and while searches is slower, indexing
+is faster. Thus larger values (> 10) are best for batch index
+creation, and smaller values (< 10) for indices that are
+interactively maintained.
+*/
+func (mp *LogMergePolicy) SetMergeFactor(mergeFactor int) {
+	if mergeFactor < 2 {
+		panic("mergeFactor cannot be less than 2")
+	}
+	mp.mergeFactor = mergeFactor
+}
+
+// Sets whether compound file format should be used for newly flushed
+// and newly merged segments.
+func (mp *LogMergePolicy) SetUseCompoundFile(useCompoundFile bool) {
+	mp.useCompoundFile = useCompoundFile
+}
+
+// Returns true if newly flushed and newly merge segments are written
+// in compound file format.
+func (mp *LogMergePolicy) UseCompoundFileFormat() bool {
+	return mp.useCompoundFile
+}
+
+// Sets whether the segment size should be calibrated by the number
+// of deletes when choosing segments for merge.
+func (mp *LogMergePolicy) SetCalibrateSizeByDeletes(calibrateSizeByDeletes bool) {
+	mp.calibrateSizeByDeletes = calibrateSizeByDeletes
+}
+
+// Returns true if the segment size should be calibrated by the number
+// of deletes when choosing segments for merge.
+func (mp *LogMergePolicy) CalibrateSizeByDeletes() bool {
+	return mp.calibrateSizeByDeletes
+}
+
+/*
+Determines the largest segment (measured by document count) that may
+be merged with other segments. Small values (e.g., less than 10,000)
+are best for interactive indexing, as this limits the length of
+pauses while indexing to a few seconds. Larger values are best for
+batched indexing and speedier searches.
+
+The default value is math.MaxInt32.
+
+The default merge policy (LogByteSizeMergePolicy) also allows you to
+set this limit by net size (in MB) of the segment, using
+SetMaxMergeMB().
+*/
+func (mp *LogMergePolicy) SetMaxMergeDocs(maxMergeDocs int) {
+	mp.maxMergeDocs = maxMergeDocs
+}
+
+// Returns the largest segment (measured by document count) that may
+// be merged with other segments.
+func (mp *LogMergePolicy) MaxMergeDocs() int {
+	return mp.maxMergeDocs
+}
+
+/*
+If a merged segment will be more than this value, leave the segment
+as non-compound file even if compound file is enabled. Set this to
+math.Inf(1) (default) and noCFSRatio to 1.0 to always use CFS
+regardless of merge size.
+*/
+func (mp *LogMergePolicy) SetMaxCFSSegmentSizeMB(v float64) {
+	if v < 0 {
+		panic(fmt.Sprintf("maxCFSSegmentSizeMB must be >=0 (got %v)", v))
+	}
+	mp.maxCFSSegmentSize = mbToBytes(v)
+}
+
+// Returns the largest size allowed for a compound file segment.
+func (mp *LogMergePolicy) MaxCFSSegmentSizeMB() float64 {
+	return float64(mp.maxCFSSegmentSize) / 1024 / 1024
+}
+
+// Converts MB to bytes, rounding values too large for an int64 to
+// math.MaxInt64.
+func mbToBytes(mb float64) int64 {
+	v := mb * 1024 * 1024
+	if v >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(v)
+}
+
+/*
+Returns the number of documents in the provided SegmentInfoPerCommit,
+pro-rated by percentage of non-deleted documents if
+CalibrateSizeByDeletes() is set.
+*/
+func (mp *LogMergePolicy) sizeDocs(info SegmentInfoPerCommit) int64 {
+	if mp.calibrateSizeByDeletes {
+		delCount := mp.writer.numDeletedDocs(info)
+		// assert delCount <= info.info.docCount
+		return int64(int(info.info.docCount) - delCount)
+	}
+	return int64(info.info.docCount)
+}
+
+/*
+Return the byte size of the provided SegmentInfoPerCommit, pro-rated
+by percentage of non-deleted documents if CalibrateSizeByDeletes() is
+set.
+*/
+func (mp *LogMergePolicy) sizeBytes(info SegmentInfoPerCommit) (int64, error) {
+	byteSize, err := info.SizeInBytes()
+	if err != nil {
+		return 0, err
+	}
+	if mp.calibrateSizeByDeletes {
+		delCount := mp.writer.numDeletedDocs(info)
+		var delRatio float64
+		if info.info.docCount > 0 {
+			delRatio = float64(delCount) / float64(info.info.docCount)
+		}
+		// assert delRatio <= 1.0
+		return int64(float64(byteSize) * (1.0 - delRatio)), nil
+	}
+	return byteSize, nil
+}
+
+func (mp *LogMergePolicy) UseCompoundFile(infos *SegmentInfos, mergedInfo *SegmentInfoPerCommit) (bool, error) {
+	if !mp.useCompoundFile {
+		return false, nil
+	}
+	mergedInfoSize, err := mp.size(*mergedInfo)
+	if err != nil {
+		return false, err
+	}
+	if mergedInfoSize > mp.maxCFSSegmentSize {
+		return false, nil
+	}
+	if mp.noCFSRatio >= 1.0 {
+		return true, nil
+	}
+	var totalSize int64
+	for _, info := range infos.Segments {
+		size, err := mp.size(info)
+		if err != nil {
+			return false, err
+		}
+		totalSize += size
+	}
+	return float64(mergedInfoSize) <= mp.noCFSRatio*float64(totalSize), nil
+}
+
+/*
+Returns true if the number of segments eligible for merging is less
+than or equal to the specified maxNumSegments.
+*/
+func (mp *LogMergePolicy) isMergedTo(infos *SegmentInfos, maxNumSegments int,
+	segmentsToMerge map[string]bool) bool {
+	numToMerge := 0
+	var mergeInfo SegmentInfoPerCommit
+	segmentIsOriginal := false
+	for _, info := range infos.Segments {
+		if numToMerge > maxNumSegments {
+			break
+		}
+		if isOriginal, ok := segmentsToMerge[info.info.name]; ok {
+			segmentIsOriginal = isOriginal
+			numToMerge++
+			mergeInfo = info
+		}
+	}
+	return numToMerge <= maxNumSegments &&
+		(numToMerge != 1 || !segmentIsOriginal || mp.isMerged(mergeInfo))
+}
+
+/*
+Returns true if this single info is already fully merged (has no
+pending deletes, is in the same dir as the writer, and matches the
+current compound file setting.
+*/
+func (mp *LogMergePolicy) isMerged(info SegmentInfoPerCommit) bool {
+	w := mp.writer
+	// assert w != nil
+	hasDeletions := w.numDeletedDocs(info) > 0
+	return !hasDeletions && info.info.dir == w.directory &&
+		(info.info.isCompoundFile == mp.useCompoundFile || mp.noCFSRatio < 1.0)
+}
+
+/*
+Returns the merges necessary to merge the index, taking the max merge
+size or max merge docs into consideration. This method attempts to
+respect the maxNumSegments parameter, however it might be, due to
+size constraints, that more than that number of segments will remain
+in the index. Also, this method does not guarantee that exactly
+maxNumSegments will remain, but <= that number.
+*/
+func (mp *LogMergePolicy) findForcedMergesSizeLimit(infos *SegmentInfos,
+	maxNumSegments, last int) (*MergeSpecification, error) {
+	spec := &MergeSpecification{}
+	segments := infos.Segments
+
+	start := last - 1
+	for start >= 0 {
+		info := segments[start]
+		tooLarge, err := mp.isTooLargeForForcedMerge(info)
+		if err != nil {
+			return nil, err
+		}
+		if tooLarge {
+			mp.message("findForcedMergesSizeLimit: skip segment=%v: size is > maxMergeSize (%v) or sizeDocs is > maxMergeDocs (%v)",
+				info, mp.maxMergeSizeForForcedMerge, mp.maxMergeDocs)
+			// need to skip that segment + add a merge for the 'right'
+			// segments, unless there is only 1 which is merged.
+			if last-start-1 > 1 || (start != last-1 && !mp.isMerged(segments[start+1])) {
+				// there is more than 1 segment to the right of this one,
+				// or a mergeable single segment.
+				spec.add(NewOneMerge(segments[start+1 : last]))
+			}
+			last = start
+		} else if last-start == mp.mergeFactor {
+			// mergeFactor eligible segments were found, add them as a
+			// merge.
+			spec.add(NewOneMerge(segments[start:last]))
+			last = start
+		}
+		start--
+	}
+
+	// Add any left-over segments, unless there is just 1 already fully
+	// merged
+	if start++; last > 0 && (start+1 < last || !mp.isMerged(segments[start])) {
+		spec.add(NewOneMerge(segments[start:last]))
+	}
+
+	if len(spec.merges) == 0 {
+		return nil, nil
+	}
+	return spec, nil
+}
+
+func (mp *LogMergePolicy) isTooLargeForForcedMerge(info SegmentInfoPerCommit) (bool, error) {
+	size, err := mp.size(info)
+	if err != nil {
+		return false, err
+	}
+	return size > mp.maxMergeSizeForForcedMerge || mp.sizeDocs(info) > int64(mp.maxMergeDocs), nil
+}
+
+/*
+Returns the merges necessary to ForceMerge() the index. This method
+constraints the returned merges only by the maxNumSegments parameter,
+and guaranteed that exactly that number of segments will remain in
+the index.
+*/
+func (mp *LogMergePolicy) findForcedMergesMaxNumSegments(infos *SegmentInfos,
+	maxNumSegments, last int) (*MergeSpecification, error) {
+	spec := &MergeSpecification{}
+	segments := infos.Segments
+
+	// First, enroll all "full" merges (size mergeFactor) to
+	// potentially be run concurrently:
+	for last-maxNumSegments+1 >= mp.mergeFactor {
+		spec.add(NewOneMerge(segments[last-mp.mergeFactor : last]))
+		last -= mp.mergeFactor
+	}
+
+	// Only if there are no full merges pending do we add a final
+	// partial (< mergeFactor segments) merge:
+	if len(spec.merges) == 0 {
+		if maxNumSegments == 1 {
+			// Since we must merge down to 1 segment, the choice is
+			// simple:
+			if last > 1 || !mp.isMerged(segments[0]) {
+				spec.add(NewOneMerge(segments[:last]))
+			}
+		} else if last > maxNumSegments {
+			// Take care to pick a partial merge that is least cost, but
+			// does not make the index too lopsided. If we always just
+			// picked the partial tail then we could produce a highly
+			// lopsided index over time:
+
+			// We must merge this many segments to leave maxNumSegments in
+			// the index (from when ForceMerge() was first kicked off):
+			finalMergeSize := last - maxNumSegments + 1
+
+			// Consider all possible starting points:
+			var bestSize int64
+			bestStart := 0
+			for i := 0; i < last-finalMergeSize+1; i++ {
+				var sumSize int64
+				for j := 0; j < finalMergeSize; j++ {
+					size, err := mp.size(segments[j+i])
+					if err != nil {
+						return nil, err
+					}
+					sumSize += size
+				}
+				if i == 0 {
+					bestStart, bestSize = i, sumSize
+				} else {
+					prevSize, err := mp.size(segments[i-1])
+					if err != nil {
+						return nil, err
+					}
+					if sumSize < 2*prevSize && sumSize < bestSize {
+						bestStart, bestSize = i, sumSize
+					}
+				}
+			}
+
+			spec.add(NewOneMerge(segments[bestStart : bestStart+finalMergeSize]))
+		}
+	}
+	if len(spec.merges) == 0 {
+		return nil, nil
+	}
+	return spec, nil
+}
+
+/*
+Returns the merges necessary to merge the index down to a specified
+number of segments. This respects the maxMergeSizeForForcedMerge
+setting. By default, and assuming maxNumSegments=1, only one segment
+will be left in the index, where that segment has no deletions
+pending nor separate norms, and it is in compound file format if the
+current useCompoundFile setting is true. This method returns multiple
+merges (mergeFactor at a time) so the MergeScheduler in use may make
+use of concurrency.
+*/
+func (mp *LogMergePolicy) FindForcedMerges(infos *SegmentInfos, maxNumSegments int,
+	segmentsToMerge map[string]bool) (*MergeSpecification, error) {
+	// assert maxNumSegments > 0
+	mp.message("findForcedMerges: maxNumSegs=%v segsToMerge=%v", maxNumSegments, segmentsToMerge)
+
+	// If the segments are already merged (e.g. there's only 1
+	// segment), or there are <maxNumSegments:.
+	if mp.isMergedTo(infos, maxNumSegments, segmentsToMerge) {
+		mp.message("already merged; skip")
+		return nil, nil
+	}
+
+	// Find the newest (rightmost) segment that needs to be merged
+	// (other segments may have been flushed since merging started):
+	last := len(infos.Segments)
+	for last > 0 {
+		last--
+		if _, ok := segmentsToMerge[infos.Segments[last].info.name]; ok {
+			last++
+			break
+		}
+	}
+
+	if last == 0 {
+		mp.message("last == 0; skip")
+		return nil, nil
+	}
+
+	// There is only one segment already, and it is merged
+	if maxNumSegments == 1 && last == 1 && mp.isMerged(infos.Segments[0]) {
+		mp.message("already 1 seg; skip")
+		return nil, nil
+	}
+
+	// Check if there are any segments above the threshold
+	for _, info := range infos.Segments[:last] {
+		tooLarge, err := mp.isTooLargeForForcedMerge(info)
+		if err != nil {
+			return nil, err
+		}
+		if tooLarge {
+			return mp.findForcedMergesSizeLimit(infos, maxNumSegments, last)
+		}
+	}
+	return mp.findForcedMergesMaxNumSegments(infos, maxNumSegments, last)
+}
+
+/*
+Finds merges necessary to force-merge all deletes from the index. We
+simply merge adjacent segments that have deletes, up to mergeFactor
+at a time.
+*/
+func (mp *LogMergePolicy) FindForcedDeletesMerges(infos *SegmentInfos) (*MergeSpecification, error) {
+	segments := infos.Segments
+	numSegments := len(segments)
+	mp.message("findForcedDeleteMerges: %v segments", numSegments)
+
+	spec := &MergeSpecification{}
+	firstSegmentWithDeletions := -1
+	for i, info := range segments {
+		if delCount := mp.writer.numDeletedDocs(info); delCount > 0 {
+			mp.message("  segment %v has deletions", info.info.name)
+			if firstSegmentWithDeletions == -1 {
+				firstSegmentWithDeletions = i
+			} else if i-firstSegmentWithDeletions == mp.mergeFactor {
+				// We've seen mergeFactor segments in a row with deletions,
+				// so force a merge now:
+				mp.message("  add merge %v to %v inclusive", firstSegmentWithDeletions, i-1)
+				spec.add(NewOneMerge(segments[firstSegmentWithDeletions:i]))
+				firstSegmentWithDeletions = i
+			}
+		} else if firstSegmentWithDeletions != -1 {
+			// End of a sequence of segments with deletions, so, merge
+			// those past segments even if it's fewer than mergeFactor
+			// segments
+			mp.message("  add merge %v to %v inclusive", firstSegmentWithDeletions, i-1)
+			spec.add(NewOneMerge(segments[firstSegmentWithDeletions:i]))
+			firstSegmentWithDeletions = -1
+		}
+	}
+
+	if firstSegmentWithDeletions != -1 {
+		mp.message("  add merge %v to %v inclusive", firstSegmentWithDeletions, numSegments-1)
+		spec.add(NewOneMerge(segments[firstSegmentWithDeletions:numSegments]))
+	}
+	return spec, nil
+}
+
+// LogMergePolicy.java/SegmentInfoAndLevel
+
+type segmentInfoAndLevel struct {
+	info  SegmentInfoPerCommit
+	level float64
+	index int
+}
+
+/*
+Checks if any merges are now necessary and returns a
+MergeSpecification if so. A merge is necessary when there are more
+than SetMergeFactor() segments at a given level. When multiple levels
+have too many segments, this method will return multiple merges,
+allowing the MergeScheduler to use concurrency.
+*/
+func (mp *LogMergePolicy) FindMerges(trigger MergeTrigger, infos *SegmentInfos) (*MergeSpecification, error) {
+	numSegments := len(infos.Segments)
+	mp.message("findMerges: %v segments", numSegments)
+
+	// Compute levels, which is just log (base mergeFactor) of the size
+	// of each segment
+	levels := make([]segmentInfoAndLevel, 0, numSegments)
+	norm := math.Log(float64(mp.mergeFactor))
+
+	for i, info := range infos.Segments {
+		size, err := mp.size(info)
+		if err != nil {
+			return nil, err
+		}
+
+		// Floor tiny segments
+		if size < 1 {
+			size = 1
+		}
+
+		levels = append(levels, segmentInfoAndLevel{info, math.Log(float64(size)) / norm, i})
+		if mp.writer.isMerging(info) {
+			mp.message("seg=%v level=%v size=%.3f MB [merging]",
+				info, levels[i].level, float64(size)/1024/1024)
+		} else {
+			mp.message("seg=%v level=%v size=%.3f MB", info, levels[i].level, float64(size)/1024/1024)
+		}
+	}
+
+	var levelFloor float64
+	if mp.minMergeSize > 0 {
+		levelFloor = math.Log(float64(mp.minMergeSize)) / norm
+	}
+
+	// Now, we quantize the log values into levels. The first level is
+	// any segment whose log size is within LEVEL_LOG_SPAN of the max
+	// size, or, who has such as segment "to the right". Then, we find
+	// the max of all other segments and use that to define the next
+	// level segment, etc.
+
+	var spec *MergeSpecification
+
+	numMergeableSegments := len(levels)
+
+	start := 0
+	for start < numMergeableSegments {
+		// Find max level of all segments not already quantized.
+		maxLevel := levels[start].level
+		for _, v := range levels[start+1:] {
+			if v.level > maxLevel {
+				maxLevel = v.level
+			}
+		}
+
+		// Now search backwards for the rightmost segment that falls
+		// into this level:
+		var levelBottom float64
+		if maxLevel <= levelFloor {
+			// All remaining segments fall into the min level
+			levelBottom = -1.0
+		} else {
+			levelBottom = maxLevel - LEVEL_LOG_SPAN
+
+			// Force a boundary at the level floor
+			if levelBottom < levelFloor && maxLevel >= levelFloor {
+				levelBottom = levelFloor
+			}
+		}
+
+		upto := numMergeableSegments - 1
+		for upto >= start {
+			if levels[upto].level >= levelBottom {
+				break
+			}
+			upto--
+		}
+		mp.message("  level %v to %v: %v segments", levelBottom, maxLevel, 1+upto-start)
+
+		// Finally, record all merges that are viable at this level:
+		end := start + mp.mergeFactor
+		for end <= 1+upto {
+			anyTooLarge, anyMerging := false, false
+			for _, v := range levels[start:end] {
+				size, err := mp.size(v.info)
+				if err != nil {
+					return nil, err
+				}
+				anyTooLarge = anyTooLarge || size >= mp.maxMergeSize ||
+					mp.sizeDocs(v.info) >= int64(mp.maxMergeDocs)
+				if mp.writer.isMerging(v.info) {
+					anyMerging = true
+					break
+				}
+			}
+
+			if anyMerging {
+				// skip
+			} else if !anyTooLarge {
+				if spec == nil {
+					spec = &MergeSpecification{}
+				}
+				mergeInfos := make([]SegmentInfoPerCommit, 0, end-start)
+				for _, v := range levels[start:end] {
+					mergeInfos = append(mergeInfos, v.info)
+					// assert infos.contains(v.info)
+				}
+				mp.message("  add merge=%v start=%v end=%v",
+					mp.writer.segStringOf(mergeInfos), start, end)
+				spec.add(NewOneMerge(mergeInfos))
+			} else {
+				mp.message("    %v to %v: contains segment over maxMergeSize or maxMergeDocs; skipping",
+					start, end)
+			}
+
+			start = end
+			end = start + mp.mergeFactor
+		}
+
+		start = 1 + upto
+	}
+
+	return spec, nil
+}
+
+func (mp *LogMergePolicy) stringOf(name string) string {
+	return fmt.Sprintf("[%v: minMergeSize=%v, mergeFactor=%v, maxMergeSize=%v, "+
+		"maxMergeSizeForForcedMerge=%v, calibrateSizeByDeletes=%v, maxMergeDocs=%v, "+
+		"useCompoundFile=%v, maxCFSSegmentSizeMB=%v, noCFSRatio=%v]",
+		name, mp.minMergeSize, mp.mergeFactor, mp.maxMergeSize,
+		mp.maxMergeSizeForForcedMerge, mp.calibrateSizeByDeletes, mp.maxMergeDocs,
+		mp.useCompoundFile, mp.MaxCFSSegmentSizeMB(), mp.noCFSRatio)
+}
+
+// LogByteSizeMergePolicy.java
+
+const (
+	// Default minimum segment size.
+	LBSMP_DEFAULT_MIN_MERGE_MB = 1.6
+	/*
+		Default maximum segment size. A segment of this size or larger
+		will never be merged.
+	*/
+	LBSMP_DEFAULT_MAX_MERGE_MB = 2048
+	/*
+		Default maximum segment size. A segment of this size or larger
+		will never be merged during ForceMerge().
+	*/
+	LBSMP_DEFAULT_MAX_MERGE_MB_FOR_FORCED_MERGE = math.MaxInt64
+)
+
+/*
+This is a LogMergePolicy that measures size of a segment as the
+total byte size of the segment's files.
+*/
+type LogByteSizeMergePolicy struct {
+	*LogMergePolicy
+}
+
+func NewLogByteSizeMergePolicy() *LogByteSizeMergePolicy {
+	ans := &LogByteSizeMergePolicy{}
+	ans.LogMergePolicy = newLogMergePolicy(func(info SegmentInfoPerCommit) (int64, error) {
+		return ans.sizeBytes(info)
+	})
+	ans.minMergeSize = mbToBytes(LBSMP_DEFAULT_MIN_MERGE_MB)
+	ans.maxMergeSize = mbToBytes(LBSMP_DEFAULT_MAX_MERGE_MB)
+	ans.maxMergeSizeForForcedMerge = mbToBytes(LBSMP_DEFAULT_MAX_MERGE_MB_FOR_FORCED_MERGE)
+	return ans
+}
+
+/*
+Determines the largest segment (measured by total byte size of the
+segment's files, in MB) that may be merged with other segments. Small
+values (e.g., less than 50 MB) are best for interactive indexing, as
+this limits the length of pauses while indexing to a few seconds.
+Larger values are best for batched indexing and speedier searches.
+
+Note that SetMaxMergeDocs() is also used to check whether a segment
+is too large for merging (it's either or).
+*/
+func (mp *LogByteSizeMergePolicy) SetMaxMergeMB(mb float64) {
+	mp.maxMergeSize = mbToBytes(mb)
+}
+
+func (mp *LogByteSizeMergePolicy) String() string {
+	return mp.stringOf("LogByteSizeMergePolicy")
+}
+
+// Returns the largest segment (measured by total byte size of the
+// segment's files, in MB) that may be merged with other segments.
+func (mp *LogByteSizeMergePolicy) MaxMergeMB() float64 {
+	return float64(mp.maxMergeSize) / 1024 / 1024
+}
+
+/*
+Determines the largest segment (measured by total byte size of the
+segment's files, in MB) that may be merged with other segments during
+ForceMerge(). Setting it low will leave the index with more than 1
+segment, even if ForceMerge(1) is called.
+*/
+func (mp *LogByteSizeMergePolicy) SetMaxMergeMBForForcedMerge(mb float64) {
+	mp.maxMergeSizeForForcedMerge = mbToBytes(mb)
+}
+
+// Returns the largest segment (measured by total byte size of the
+// segment's files, in MB) that may be merged with other segments
+// during ForceMerge().
+func (mp *LogByteSizeMergePolicy) MaxMergeMBForForcedMerge() float64 {
+	return float64(mp.maxMergeSizeForForcedMerge) / 1024 / 1024
+}
+
+/*
+Sets the minimum size for the lowest level segments. Any segments
+below this size are considered to be on the same level (even if they
+vary drastically in size) and will be merged whenever there are
+mergeFactor of them. This effectively truncates the "long tail" of
+small segments that would otherwise be created into a single level.
+If you set this too large, it could greatly increase the merging cost
+during indexing (if you flush many small segments).
+*/
+func (mp *LogByteSizeMergePolicy) SetMinMergeMB(mb float64) {
+	mp.minMergeSize = mbToBytes(mb)
+}
+
+// Get the minimum size for a segment to remain un-merged.
+func (mp *LogByteSizeMergePolicy) MinMergeMB() float64 {
+	return float64(mp.minMergeSize) / 1024 / 1024
+}
+
+// LogDocMergePolicy.java
+
+// Default minimum segment size.
+const LDMP_DEFAULT_MIN_MERGE_DOCS = 1000
+
+/*
+This is a LogMergePolicy that measures size of a segment as the
+number of documents (not taking deletions into account).
+*/
+type LogDocMergePolicy struct {
+	*LogMergePolicy
+}
+
+func NewLogDocMergePolicy() *LogDocMergePolicy {
+	ans := &LogDocMergePolicy{}
+	ans.LogMergePolicy = newLogMergePolicy(func(info SegmentInfoPerCommit) (int64, error) {
+		return ans.sizeDocs(info), nil
+	})
+	ans.minMergeSize = LDMP_DEFAULT_MIN_MERGE_DOCS
+	// maxMergeSize is never used by LogDocMergePolicy; set it to
+	// math.MaxInt64 to disable it
+	ans.maxMergeSize = math.MaxInt64
+	ans.maxMergeSizeForForcedMerge = math.MaxInt64
+	return ans
+}
+
+func (mp *LogDocMergePolicy) String() string {
+	return mp.stringOf("LogDocMergePolicy")
+}
+
+/*
+Sets the minimum size for the lowest level segments. Any segments
+below this size are considered to be on the same level (even if they
+vary drastically in size) and will be merged whenever there are
+mergeFactor of them. This effectively truncates the "long tail" of
+small segments that would otherwise be created into a single level.
+If you set this too large, it could greatly increase the merging cost
+during indexing (if you flush many small segments).
+*/
+func (mp *LogDocMergePolicy) SetMinMergeDocs(minMergeDocs int) {
+	mp.minMergeSize = int64(minMergeDocs)
+}
+
+// Get the minimum size for a segment to remain un-merged.
+func (mp *LogDocMergePolicy) MinMergeDocs() int {
+	return int(mp.minMergeSize)
+}
@@ -0,0 +1,164 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/store"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func openTestDirectory(t *testing.T) (store.Directory, string) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		os.RemoveAll(path)
+		t.Fatal(err)
+	}
+	return d, path
+}
+
+func addTestDocs(t *testing.T, w *IndexWriter, numDocs int) {
+	for i := 0; i < numDocs; i++ {
+		if err := w.AddDocument(newTestDoc(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func assertSegmentCount(t *testing.T, d store.Directory, expected int) {
+	infos := &SegmentInfos{}
+	if err := infos.ReadAll(d); err != nil {
+		t.Fatal(err)
+	}
+	if len(infos.Segments) != expected {
+		t.Errorf("Expected %v segments, but was %v: %v", expected, len(infos.Segments), infos.Segments)
+	}
+}
+
+func TestLogDocMergePolicy(t *testing.T) {
+	for _, v := range []struct {
+		maxMergeDocs, numSegments int
+	}{{LMP_DEFAULT_MAX_MERGE_DOCS, 1}, {6, 3}} {
+		d, path := openTestDirectory(t)
+		defer os.RemoveAll(path)
+
+		mp := NewLogDocMergePolicy()
+		mp.SetMergeFactor(3)
+		mp.SetMinMergeDocs(2)
+		mp.SetMaxMergeDocs(v.maxMergeDocs)
+		w, err := NewIndexWriter(d, NewIndexWriterConfig().SetMaxBufferedDocs(2).SetMergePolicy(mp))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 9 segments of 2 docs are merged to 3 segments of 6 docs, and
+		// then to a single segment unless that is too large
+		addTestDocs(t, w, 18)
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		assertSegmentCount(t, d, v.numSegments)
+	}
+}
+
+func TestForceMerge(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	const numDocs = 10
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetMaxBufferedDocs(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestDocs(t, w, numDocs)
+	if err = w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// below the merge factor, so nothing is merged yet
+	assertSegmentCount(t, d, 5)
+
+	if err = w.ForceMerge(0); err == nil {
+		t.Error("Should reject maxNumSegments < 1")
+	}
+	if err = w.ForceMerge(1); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, numDocs, w.MaxDoc())
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.ForceMerge(1); err == nil {
+		t.Error("Should not merge with a closed writer")
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, len(r.Leaves()))
+	for i := 0; i < numDocs; i++ {
+		visitor := NewDocumentStoredFieldVisitor()
+		if err = r.Document(i, visitor); err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, fmt.Sprintf("%04d", i), visitor.Document().Get("id"))
+	}
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, false)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+}
+
+func TestLogMergePolicyUseCompoundFile(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetMaxBufferedDocs(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestDocs(t, w, 10)
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	infos := &SegmentInfos{}
+	if err = infos.ReadAll(d); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 5, len(infos.Segments))
+
+	mp := NewLogByteSizeMergePolicy()
+	assertUseCompoundFile := func(expected bool) {
+		ans, err := mp.UseCompoundFile(infos, &infos.Segments[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ans != expected {
+			t.Errorf("UseCompoundFile should be %v with %v", expected, mp)
+		}
+	}
+	// one of five similar segments is above the default noCFSRatio
+	assertUseCompoundFile(false)
+	mp.SetNoCFSRatio(0.5)
+	assertUseCompoundFile(true)
+	mp.SetNoCFSRatio(1)
+	assertUseCompoundFile(true)
+	mp.SetMaxCFSSegmentSizeMB(0)
+	assertUseCompoundFile(false)
+	mp.SetMaxCFSSegmentSizeMB(1)
+	assertUseCompoundFile(true)
+	mp.SetUseCompoundFile(false)
+	assertUseCompoundFile(false)
+}
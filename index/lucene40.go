@@ -15,6 +15,10 @@ const (
 	LUCENE40_VERSION_CURRENT = LUCENE40_VERSION_START
 
 	SEGMENT_INFO_YES = 1
+	SEGMENT_INFO_NO  = -1
+
+	// Extension of deletes
+	LUCENE40_DELETES_EXTENSION = "del"
 )
 
 var (
@@ -83,6 +87,108 @@ var (
 	}
 )
 
+// Lucene40SegmentInfoWriter.java
+
+// Save a single segment's info.
+var Lucene40SegmentInfoWriter = func(dir store.Directory, si *SegmentInfo, fis FieldInfos, ctx store.IOContext) (err error) {
+	fileName := util.SegmentFileName(si.name, "", LUCENE40_SI_EXTENSION)
+	si.Files[fileName] = true
+
+	output, err := dir.CreateOutput(fileName, ctx)
+	if err != nil {
+		return err
+	}
+
+	success := false
+	defer func() {
+		if !success {
+			util.CloseWhileSuppressingError(output)
+			dir.DeleteFile(fileName)
+		} else {
+			err = output.Close()
+		}
+	}()
+
+	if err = codec.WriteHeader(output, LUCENE40_CODEC_NAME, LUCENE40_VERSION_CURRENT); err != nil {
+		return err
+	}
+	// Write the Lucene version that created this segment, since 3.1
+	if err = output.WriteString(si.version); err != nil {
+		return err
+	}
+	if err = output.WriteInt(si.docCount); err != nil {
+		return err
+	}
+	isCompoundFile := byte(SEGMENT_INFO_NO & 0xff)
+	if si.isCompoundFile {
+		isCompoundFile = SEGMENT_INFO_YES
+	}
+	if err = output.WriteByte(isCompoundFile); err != nil {
+		return err
+	}
+	if err = output.WriteStringStringMap(si.diagnostics); err != nil {
+		return err
+	}
+	if err = output.WriteStringStringMap(si.attributes); err != nil {
+		return err
+	}
+	if err = output.WriteStringSet(si.Files); err != nil {
+		return err
+	}
+
+	success = true
+	return nil
+}
+
+// Lucene40LiveDocsFormat.java
+
+// Reads the live docs of the segment from the .del file of its current deletion generation.
+var Lucene40LiveDocsReader = func(dir store.Directory, info SegmentInfoPerCommit,
+	context store.IOContext) (liveDocs util.Bits, err error) {
+	filename := util.FileNameFromGeneration(info.info.name, LUCENE40_DELETES_EXTENSION, info.delGen)
+	bv, err := newBitVectorFrom(dir, filename, context)
+	if err != nil {
+		return nil, err
+	}
+	if bv.Count() != int(info.info.docCount)-info.delCount {
+		return nil, errors.New(fmt.Sprintf("liveDocs.count()=%v info.docCount=%v info.getDelCount()=%v",
+			bv.Count(), info.info.docCount, info.delCount))
+	}
+	if bv.Length() != int(info.info.docCount) {
+		return nil, errors.New(fmt.Sprintf("liveDocs.length()=%v info.docCount=%v",
+			bv.Length(), info.info.docCount))
+	}
+	return bv, nil
+}
+
+/*
+Returns a copy of the existing live docs, which can be changed, or
+live docs of the given size with all documents live if there are none.
+*/
+var Lucene40NewLiveDocs = func(size int, existing util.Bits) util.MutableBits {
+	if existing != nil {
+		return existing.(*BitVector).clone()
+	}
+	bv := newBitVector(size)
+	bv.invertAll()
+	return bv
+}
+
+// Writes the live docs, with newDelCount more deletions than the
+// segment has, as the .del file of its next deletion generation.
+var Lucene40LiveDocsWriter = func(dir store.Directory, info SegmentInfoPerCommit, bits util.MutableBits,
+	newDelCount int, context store.IOContext) error {
+	filename := util.FileNameFromGeneration(info.info.name, LUCENE40_DELETES_EXTENSION, info.nextWriteDelGen)
+	liveDocs := bits.(*BitVector)
+	if liveDocs.Count() != int(info.info.docCount)-info.delCount-newDelCount {
+		panic("assert fail")
+	}
+	if liveDocs.Length() != int(info.info.docCount) {
+		panic("assert fail")
+	}
+	return liveDocs.write(dir, filename, context)
+}
+
 // Lucene40StoredFieldsWriter.java
 const (
 	LUCENE40_SF_FIELDS_EXTENSION       = "fdt"
@@ -7,6 +7,7 @@ import (
 	"github.com/balzaczyy/golucene/store"
 	"github.com/balzaczyy/golucene/util"
 	"log"
+	"math"
 	"reflect"
 )
 
@@ -137,11 +138,72 @@ func (r *Lucene41PostingsReader) ReadTermsBlock(termsIn store.IndexInput, fieldI
 		termState.bytes = make([]byte, numBytes)
 	}
 
-	err = termsIn.ReadBytes(termState.bytes)
+	err = termsIn.ReadBytes(termState.bytes[:numBytes])
 	if err != nil {
 		return err
 	}
-	termState.bytesReader.Reset(termState.bytes)
+	termState.bytesReader.Reset(termState.bytes[:numBytes])
+	return nil
+}
+
+func (r *Lucene41PostingsReader) NextTerm(fieldInfo FieldInfo, _termState *BlockTermState) (err error) {
+	termState := _termState.Self.(*intBlockTermState)
+	isFirstTerm := termState.termBlockOrd == 0
+	fieldHasPositions := fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS
+	fieldHasOffsets := fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+	fieldHasPayloads := fieldInfo.storePayloads
+
+	in := termState.bytesReader
+	if isFirstTerm {
+		termState.docStartFP = 0
+		termState.posStartFP = 0
+		termState.payStartFP = -1
+	}
+	if termState.docFreq == 1 {
+		if termState.singletonDocID, err = asInt(in.ReadVInt()); err != nil {
+			return err
+		}
+	} else {
+		termState.singletonDocID = -1
+		delta, err := in.ReadVLong()
+		if err != nil {
+			return err
+		}
+		termState.docStartFP += delta
+	}
+	if fieldHasPositions {
+		delta, err := in.ReadVLong()
+		if err != nil {
+			return err
+		}
+		termState.posStartFP += delta
+		if termState.totalTermFreq > LUCENE41_BLOCK_SIZE {
+			if termState.lastPosBlockOffset, err = in.ReadVLong(); err != nil {
+				return err
+			}
+		} else {
+			termState.lastPosBlockOffset = -1
+		}
+		if (fieldHasPayloads || fieldHasOffsets) && termState.totalTermFreq >= LUCENE41_BLOCK_SIZE {
+			delta, err := in.ReadVLong()
+			if err != nil {
+				return err
+			}
+			if termState.payStartFP == -1 {
+				termState.payStartFP = delta
+			} else {
+				termState.payStartFP += delta
+			}
+		}
+	}
+
+	if termState.docFreq > LUCENE41_BLOCK_SIZE {
+		if termState.skipOffset, err = in.ReadVLong(); err != nil {
+			return err
+		}
+	} else {
+		termState.skipOffset = -1
+	}
 	return nil
 }
 
@@ -156,47 +218,1264 @@ type intBlockTermState struct {
 	// freq is always implicitly totalTermFreq in this case.
 	singletonDocID int
 
-	// Only used by the "primary" TermState -- clones don't
-	// copy this (basically they are "transient"):
-	bytesReader *store.ByteArrayDataInput
-	bytes       []byte
+	// Only used by the "primary" TermState -- clones don't
+	// copy this (basically they are "transient"):
+	bytesReader *store.ByteArrayDataInput
+	bytes       []byte
+}
+
+func newIntBlockTermState() *intBlockTermState {
+	ts := &intBlockTermState{}
+	parent := NewBlockTermState()
+	ts.BlockTermState, parent.Self = parent, ts
+	return ts
+}
+
+func (ts *intBlockTermState) Clone() TermState {
+	clone := newIntBlockTermState()
+	clone.CopyFrom(ts)
+	return clone
+}
+
+func (ts *intBlockTermState) CopyFrom(other TermState) {
+	if ots, ok := other.(*intBlockTermState); ok {
+		ts.BlockTermState.CopyFrom(ots.BlockTermState)
+		ts.docStartFP = ots.docStartFP
+		ts.posStartFP = ots.posStartFP
+		ts.payStartFP = ots.payStartFP
+		ts.lastPosBlockOffset = ots.lastPosBlockOffset
+		ts.skipOffset = ots.skipOffset
+		ts.singletonDocID = ots.singletonDocID
+
+		// Do not copy bytes, bytesReader (else TermState is
+		// very heavy, ie drags around the entire block's
+		// byte[]).  On seek back, if next() is in fact used
+		// (rare!), they will be re-read from disk.
+	} else {
+		panic(fmt.Sprintf("Can not copy from %v", reflect.TypeOf(other).Name()))
+	}
+}
+
+func (ts *intBlockTermState) String() string {
+	return fmt.Sprintf("%v docStartFP=%v posStartFP=%v payStartFP=%v lastPosBlockOffset=%v skipOffset=%v singletonDocID=%v",
+		ts.BlockTermState, ts.docStartFP, ts.posStartFP, ts.payStartFP, ts.lastPosBlockOffset, ts.skipOffset, ts.singletonDocID)
+}
+
+func (r *Lucene41PostingsReader) Docs(fieldInfo FieldInfo, termState *BlockTermState,
+	liveDocs util.Bits, reuse DocsEnum, flags int) (de DocsEnum, err error) {
+	docsEnum, ok := reuse.DocIdSetIterator.(*blockDocsEnum)
+	if !ok || !docsEnum.canReuse(r.docIn, fieldInfo) {
+		docsEnum = newBlockDocsEnum(r, fieldInfo)
+	}
+	if err = docsEnum.reset(liveDocs, termState.Self.(*intBlockTermState), flags); err != nil {
+		return DOCS_ENUM_EMPTY, err
+	}
+	return DocsEnum{docsEnum}, nil
+}
+
+func readVIntBlock(docIn store.IndexInput, docBuffer, freqBuffer []int32, num int, indexHasFreq bool) (err error) {
+	if indexHasFreq {
+		for i := 0; i < num; i++ {
+			code, err := docIn.ReadVInt()
+			if err != nil {
+				return err
+			}
+			docBuffer[i] = int32(uint32(code) >> 1)
+			if (code & 1) != 0 {
+				freqBuffer[i] = 1
+			} else if freqBuffer[i], err = docIn.ReadVInt(); err != nil {
+				return err
+			}
+		}
+	} else {
+		for i := 0; i < num; i++ {
+			if docBuffer[i], err = docIn.ReadVInt(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Lucene41PostingsReader.java/BlockDocsEnum
+type blockDocsEnum struct {
+	owner *Lucene41PostingsReader
+
+	encoded []byte
+
+	docDeltaBuffer []int32
+	freqBuffer     []int32
+
+	docBufferUpto int
+
+	skipper *lucene41SkipReader
+	skipped bool
+
+	startDocIn store.IndexInput
+
+	docIn            store.IndexInput
+	indexHasFreq     bool
+	indexHasPos      bool
+	indexHasOffsets  bool
+	indexHasPayloads bool
+
+	docFreq       int
+	totalTermFreq int64
+	docUpto       int
+	doc           int
+	accum         int
+	freq          int
+
+	// Where this term's postings start in the .doc file:
+	docTermStartFP int64
+
+	// Where this term's skip data starts (after
+	// docTermStartFP) in the .doc file (or -1 if there is
+	// no skip data for this term):
+	skipOffset int64
+
+	// docID for next skip point, we won't use skipper if
+	// target docID is not larger than this
+	nextSkipDoc int
+
+	liveDocs util.Bits
+
+	needsFreq      bool // true if the caller actually needs frequencies
+	singletonDocID int  // docid when there is a single pulsed posting, otherwise -1
+}
+
+func newBlockDocsEnum(owner *Lucene41PostingsReader, fieldInfo FieldInfo) *blockDocsEnum {
+	return &blockDocsEnum{
+		owner:            owner,
+		encoded:          make([]byte, LUCENE41_MAX_ENCODED_SIZE),
+		docDeltaBuffer:   make([]int32, LUCENE41_MAX_DATA_SIZE),
+		freqBuffer:       make([]int32, LUCENE41_MAX_DATA_SIZE),
+		startDocIn:       owner.docIn,
+		indexHasFreq:     fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS,
+		indexHasPos:      fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS,
+		indexHasOffsets:  fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS,
+		indexHasPayloads: fieldInfo.storePayloads,
+	}
+}
+
+func (de *blockDocsEnum) canReuse(docIn store.IndexInput, fieldInfo FieldInfo) bool {
+	return docIn == de.startDocIn &&
+		de.indexHasFreq == (fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS) &&
+		de.indexHasPos == (fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS) &&
+		de.indexHasPayloads == fieldInfo.storePayloads
+}
+
+func (de *blockDocsEnum) reset(liveDocs util.Bits, termState *intBlockTermState, flags int) error {
+	de.liveDocs = liveDocs
+	de.docFreq = termState.docFreq
+	if de.indexHasFreq {
+		de.totalTermFreq = termState.totalTermFreq
+	} else {
+		de.totalTermFreq = int64(de.docFreq)
+	}
+	de.docTermStartFP = termState.docStartFP
+	de.skipOffset = termState.skipOffset
+	de.singletonDocID = termState.singletonDocID
+	if de.docFreq > 1 {
+		if de.docIn == nil {
+			// lazy init
+			de.docIn = de.startDocIn.Clone()
+		}
+		de.docIn.Seek(de.docTermStartFP)
+	}
+
+	de.doc = -1
+	de.needsFreq = (flags & DOCS_ENUM_FLAG_FREQS) != 0
+	if !de.indexHasFreq {
+		for i := range de.freqBuffer {
+			de.freqBuffer[i] = 1
+		}
+	}
+	de.accum = 0
+	de.docUpto = 0
+	de.nextSkipDoc = LUCENE41_BLOCK_SIZE - 1 // we won't skip if target is found in first block
+	de.docBufferUpto = LUCENE41_BLOCK_SIZE
+	de.skipped = false
+	return nil
+}
+
+func (de *blockDocsEnum) Freq() int {
+	return de.freq
+}
+
+func (de *blockDocsEnum) Cost() int64 {
+	return int64(de.docFreq)
+}
+
+func (de *blockDocsEnum) DocId() int {
+	return de.doc
+}
+
+func (de *blockDocsEnum) refillDocs() (err error) {
+	left := de.docFreq - de.docUpto
+	if left <= 0 {
+		panic("assert fail")
+	}
+
+	if left >= LUCENE41_BLOCK_SIZE {
+		log.Printf("    fill doc block from fp=%v", de.docIn.FilePointer())
+		if err = de.owner.forUtil.ReadBlock(de.docIn, de.encoded, de.docDeltaBuffer); err != nil {
+			return err
+		}
+
+		if de.indexHasFreq {
+			log.Printf("    fill freq block from fp=%v", de.docIn.FilePointer())
+			if de.needsFreq {
+				err = de.owner.forUtil.ReadBlock(de.docIn, de.encoded, de.freqBuffer)
+			} else {
+				err = de.owner.forUtil.SkipBlock(de.docIn) // skip over freqs
+			}
+		}
+	} else if de.docFreq == 1 {
+		de.docDeltaBuffer[0] = int32(de.singletonDocID)
+		de.freqBuffer[0] = int32(de.totalTermFreq)
+	} else {
+		// Read vInts:
+		log.Printf("    fill last vInt block from fp=%v", de.docIn.FilePointer())
+		err = readVIntBlock(de.docIn, de.docDeltaBuffer, de.freqBuffer, left, de.indexHasFreq)
+	}
+	de.docBufferUpto = 0
+	return err
+}
+
+func (de *blockDocsEnum) NextDoc() (doc int, more bool) {
+	for {
+		if de.docUpto == de.docFreq {
+			de.doc = NO_MORE_DOCS
+			return de.doc, false
+		}
+		if de.docBufferUpto == LUCENE41_BLOCK_SIZE {
+			if err := de.refillDocs(); err != nil {
+				panic(err)
+			}
+		}
+
+		de.accum += int(de.docDeltaBuffer[de.docBufferUpto])
+		de.docUpto++
+
+		if de.liveDocs == nil || de.liveDocs.Get(de.accum) {
+			de.doc = de.accum
+			de.freq = int(de.freqBuffer[de.docBufferUpto])
+			de.docBufferUpto++
+			return de.doc, true
+		}
+		de.docBufferUpto++
+	}
+}
+
+func (de *blockDocsEnum) Advance(target int) (doc int, more bool) {
+	// TODO: make frq block load lazy/skippable
+
+	// current skip docID < docIDs generated from current buffer <= next
+	// skip docID, we don't need to skip if target is buffered already
+	if de.docFreq > LUCENE41_BLOCK_SIZE && target > de.nextSkipDoc {
+		log.Println("load skipper")
+
+		if de.skipper == nil {
+			// Lazy init: first time this enum has ever been used for skipping
+			de.skipper = newLucene41SkipReader(de.docIn.Clone(), LUCENE41_MAX_SKIP_LEVELS,
+				LUCENE41_BLOCK_SIZE, de.indexHasPos, de.indexHasOffsets, de.indexHasPayloads)
+		}
+
+		if !de.skipped {
+			if de.skipOffset == -1 {
+				panic("assert fail")
+			}
+			// This is the first time this enum has skipped since reset() was
+			// called; load the skip data:
+			de.skipper.init(de.docTermStartFP+de.skipOffset, de.docTermStartFP, 0, 0, de.docFreq)
+			de.skipped = true
+		}
+
+		// always plus one to fix the result, since skip position in
+		// lucene41SkipReader is a little different from
+		// MultiLevelSkipListReader
+		n, err := de.skipper.SkipTo(target)
+		if err != nil {
+			panic(err)
+		}
+		newDocUpto := n + 1
+
+		if newDocUpto > de.docUpto {
+			// Skipper moved
+			log.Printf("skipper moved to docUpto=%v vs current=%v; docID=%v fp=%v",
+				newDocUpto, de.docUpto, de.skipper.Doc(), de.skipper.DocPointer())
+			if newDocUpto%LUCENE41_BLOCK_SIZE != 0 {
+				panic(fmt.Sprintf("got %v", newDocUpto))
+			}
+			de.docUpto = newDocUpto
+
+			// Force to read next block
+			de.docBufferUpto = LUCENE41_BLOCK_SIZE
+			de.accum = de.skipper.Doc()            // actually, this is just lastSkipEntry
+			de.docIn.Seek(de.skipper.DocPointer()) // now point to the block we want to search
+		}
+		// next time we call advance, this is used to foresee whether
+		// skipper is necessary.
+		de.nextSkipDoc = de.skipper.NextSkipDoc()
+	}
+	if de.docUpto == de.docFreq {
+		de.doc = NO_MORE_DOCS
+		return de.doc, false
+	}
+	if de.docBufferUpto == LUCENE41_BLOCK_SIZE {
+		if err := de.refillDocs(); err != nil {
+			panic(err)
+		}
+	}
+
+	// Now scan... this is an inlined/pared down version of NextDoc():
+	for {
+		de.accum += int(de.docDeltaBuffer[de.docBufferUpto])
+		de.docUpto++
+
+		if de.accum >= target {
+			break
+		}
+		de.docBufferUpto++
+		if de.docUpto == de.docFreq {
+			de.doc = NO_MORE_DOCS
+			return de.doc, false
+		}
+	}
+
+	if de.liveDocs == nil || de.liveDocs.Get(de.accum) {
+		de.freq = int(de.freqBuffer[de.docBufferUpto])
+		de.docBufferUpto++
+		de.doc = de.accum
+		return de.doc, true
+	}
+	de.docBufferUpto++
+	return de.NextDoc()
+}
+
+func (r *Lucene41PostingsReader) DocsAndPositions(fieldInfo FieldInfo, termState *BlockTermState,
+	liveDocs util.Bits, reuse DocsAndPositionsEnum, flags int) (DocsAndPositionsEnum, error) {
+	indexHasOffsets := fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+	indexHasPayloads := fieldInfo.storePayloads
+
+	if indexHasOffsets && (flags&DOCS_POSITIONS_ENUM_FLAG_OFF_SETS) != 0 ||
+		indexHasPayloads && (flags&DOCS_POSITIONS_ENUM_FLAG_PAYLOADS) != 0 {
+		everythingEnum, ok := reuse.DocsAndPositionsIterator.(*everythingEnum)
+		if !ok || !everythingEnum.canReuse(r.docIn, fieldInfo) {
+			everythingEnum = newEverythingEnum(r, fieldInfo)
+		}
+		everythingEnum.reset(liveDocs, termState.Self.(*intBlockTermState), flags)
+		return DocsAndPositionsEnum{everythingEnum}, nil
+	}
+	docsAndPositionsEnum, ok := reuse.DocsAndPositionsIterator.(*blockDocsAndPositionsEnum)
+	if !ok || !docsAndPositionsEnum.canReuse(r.docIn, fieldInfo) {
+		docsAndPositionsEnum = newBlockDocsAndPositionsEnum(r, fieldInfo)
+	}
+	docsAndPositionsEnum.reset(liveDocs, termState.Self.(*intBlockTermState))
+	return DocsAndPositionsEnum{docsAndPositionsEnum}, nil
+}
+
+// Lucene41PostingsReader.java/BlockDocsAndPositionsEnum
+
+/*
+Iterates the positions of a term, besides its docs and freqs. The
+payloads and offsets, if indexed, are skipped over.
+*/
+type blockDocsAndPositionsEnum struct {
+	owner *Lucene41PostingsReader
+
+	encoded []byte
+
+	docDeltaBuffer []int32
+	freqBuffer     []int32
+	posDeltaBuffer []int32
+
+	docBufferUpto int
+	posBufferUpto int
+
+	skipper *lucene41SkipReader
+	skipped bool
+
+	startDocIn store.IndexInput
+
+	docIn            store.IndexInput
+	posIn            store.IndexInput
+	indexHasOffsets  bool
+	indexHasPayloads bool
+
+	docFreq       int
+	totalTermFreq int64
+	docUpto       int
+	doc           int
+	accum         int
+	freq          int
+	position      int
+
+	// how many positions "behind" we are; nextPosition must
+	// skip these to "catch up":
+	posPendingCount int
+
+	// Lazy pos seek: if != -1 then we must seek to this FP
+	// before reading positions:
+	posPendingFP int64
+
+	// Where this term's postings start in the .doc file:
+	docTermStartFP int64
+
+	// Where this term's postings start in the .pos file:
+	posTermStartFP int64
+
+	// Where this term's payloads/offsets start in the .pay
+	// file:
+	payTermStartFP int64
+
+	// File pointer where the last (vInt encoded) pos delta
+	// block is.  We need this to know whether to bulk
+	// decode vs vInt decode the block:
+	lastPosBlockFP int64
+
+	// Where this term's skip data starts (after
+	// docTermStartFP) in the .doc file (or -1 if there is
+	// no skip data for this term):
+	skipOffset int64
+
+	nextSkipDoc int
+
+	liveDocs       util.Bits
+	singletonDocID int // docid when there is a single pulsed posting, otherwise -1
+}
+
+func newBlockDocsAndPositionsEnum(owner *Lucene41PostingsReader, fieldInfo FieldInfo) *blockDocsAndPositionsEnum {
+	return &blockDocsAndPositionsEnum{
+		owner:            owner,
+		encoded:          make([]byte, LUCENE41_MAX_ENCODED_SIZE),
+		docDeltaBuffer:   make([]int32, LUCENE41_MAX_DATA_SIZE),
+		freqBuffer:       make([]int32, LUCENE41_MAX_DATA_SIZE),
+		posDeltaBuffer:   make([]int32, LUCENE41_MAX_DATA_SIZE),
+		startDocIn:       owner.docIn,
+		posIn:            owner.posIn.Clone(),
+		indexHasOffsets:  fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS,
+		indexHasPayloads: fieldInfo.storePayloads,
+	}
+}
+
+func (de *blockDocsAndPositionsEnum) canReuse(docIn store.IndexInput, fieldInfo FieldInfo) bool {
+	return docIn == de.startDocIn &&
+		de.indexHasOffsets == (fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS) &&
+		de.indexHasPayloads == fieldInfo.storePayloads
+}
+
+func (de *blockDocsAndPositionsEnum) reset(liveDocs util.Bits, termState *intBlockTermState) {
+	de.liveDocs = liveDocs
+	de.docFreq = termState.docFreq
+	de.docTermStartFP = termState.docStartFP
+	de.posTermStartFP = termState.posStartFP
+	de.payTermStartFP = termState.payStartFP
+	de.skipOffset = termState.skipOffset
+	de.totalTermFreq = termState.totalTermFreq
+	de.singletonDocID = termState.singletonDocID
+	if de.docFreq > 1 {
+		if de.docIn == nil {
+			// lazy init
+			de.docIn = de.startDocIn.Clone()
+		}
+		de.docIn.Seek(de.docTermStartFP)
+	}
+	de.posPendingFP = de.posTermStartFP
+	de.posPendingCount = 0
+	if de.totalTermFreq < LUCENE41_BLOCK_SIZE {
+		de.lastPosBlockFP = de.posTermStartFP
+	} else if de.totalTermFreq == LUCENE41_BLOCK_SIZE {
+		de.lastPosBlockFP = -1
+	} else {
+		de.lastPosBlockFP = de.posTermStartFP + termState.lastPosBlockOffset
+	}
+
+	de.doc = -1
+	de.accum = 0
+	de.docUpto = 0
+	de.nextSkipDoc = LUCENE41_BLOCK_SIZE - 1
+	de.docBufferUpto = LUCENE41_BLOCK_SIZE
+	de.skipped = false
+}
+
+func (de *blockDocsAndPositionsEnum) Freq() int {
+	return de.freq
+}
+
+func (de *blockDocsAndPositionsEnum) Cost() int64 {
+	return int64(de.docFreq)
+}
+
+func (de *blockDocsAndPositionsEnum) DocId() int {
+	return de.doc
+}
+
+func (de *blockDocsAndPositionsEnum) refillDocs() (err error) {
+	left := de.docFreq - de.docUpto
+	if left <= 0 {
+		panic("assert fail")
+	}
+
+	if left >= LUCENE41_BLOCK_SIZE {
+		if err = de.owner.forUtil.ReadBlock(de.docIn, de.encoded, de.docDeltaBuffer); err != nil {
+			return err
+		}
+		err = de.owner.forUtil.ReadBlock(de.docIn, de.encoded, de.freqBuffer)
+	} else if de.docFreq == 1 {
+		de.docDeltaBuffer[0] = int32(de.singletonDocID)
+		de.freqBuffer[0] = int32(de.totalTermFreq)
+	} else {
+		// Read vInts:
+		err = readVIntBlock(de.docIn, de.docDeltaBuffer, de.freqBuffer, left, true)
+	}
+	de.docBufferUpto = 0
+	return err
+}
+
+func (de *blockDocsAndPositionsEnum) refillPositions() error {
+	if de.posIn.FilePointer() != de.lastPosBlockFP {
+		return de.owner.forUtil.ReadBlock(de.posIn, de.encoded, de.posDeltaBuffer)
+	}
+	count := int(de.totalTermFreq % LUCENE41_BLOCK_SIZE)
+	payloadLength := 0
+	for i := 0; i < count; i++ {
+		code, err := de.posIn.ReadVInt()
+		if err != nil {
+			return err
+		}
+		if de.indexHasPayloads {
+			if (code & 1) != 0 {
+				if payloadLength, err = asInt(de.posIn.ReadVInt()); err != nil {
+					return err
+				}
+			}
+			de.posDeltaBuffer[i] = int32(uint32(code) >> 1)
+			if payloadLength != 0 {
+				de.posIn.Seek(de.posIn.FilePointer() + int64(payloadLength))
+			}
+		} else {
+			de.posDeltaBuffer[i] = code
+		}
+		if de.indexHasOffsets {
+			n, err := de.posIn.ReadVInt()
+			if err != nil {
+				return err
+			}
+			if (n & 1) != 0 {
+				// offset length changed
+				if _, err = de.posIn.ReadVInt(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (de *blockDocsAndPositionsEnum) NextDoc() (doc int, more bool) {
+	for {
+		if de.docUpto == de.docFreq {
+			de.doc = NO_MORE_DOCS
+			return de.doc, false
+		}
+		if de.docBufferUpto == LUCENE41_BLOCK_SIZE {
+			if err := de.refillDocs(); err != nil {
+				panic(err)
+			}
+		}
+
+		de.accum += int(de.docDeltaBuffer[de.docBufferUpto])
+		de.freq = int(de.freqBuffer[de.docBufferUpto])
+		de.posPendingCount += de.freq
+		de.docBufferUpto++
+		de.docUpto++
+
+		if de.liveDocs == nil || de.liveDocs.Get(de.accum) {
+			de.doc = de.accum
+			de.position = 0
+			return de.doc, true
+		}
+	}
+}
+
+func (de *blockDocsAndPositionsEnum) Advance(target int) (doc int, more bool) {
+	// TODO: make frq block load lazy/skippable
+
+	if de.docFreq > LUCENE41_BLOCK_SIZE && target > de.nextSkipDoc {
+		if de.skipper == nil {
+			// Lazy init: first time this enum has ever been used for skipping
+			de.skipper = newLucene41SkipReader(de.docIn.Clone(), LUCENE41_MAX_SKIP_LEVELS,
+				LUCENE41_BLOCK_SIZE, true, de.indexHasOffsets, de.indexHasPayloads)
+		}
+
+		if !de.skipped {
+			if de.skipOffset == -1 {
+				panic("assert fail")
+			}
+			// This is the first time this enum has skipped since reset() was
+			// called; load the skip data:
+			de.skipper.init(de.docTermStartFP+de.skipOffset, de.docTermStartFP,
+				de.posTermStartFP, de.payTermStartFP, de.docFreq)
+			de.skipped = true
+		}
+
+		n, err := de.skipper.SkipTo(target)
+		if err != nil {
+			panic(err)
+		}
+		newDocUpto := n + 1
+
+		if newDocUpto > de.docUpto {
+			// Skipper moved
+			if newDocUpto%LUCENE41_BLOCK_SIZE != 0 {
+				panic(fmt.Sprintf("got %v", newDocUpto))
+			}
+			de.docUpto = newDocUpto
+
+			// Force to read next block
+			de.docBufferUpto = LUCENE41_BLOCK_SIZE
+			de.accum = de.skipper.Doc()
+			de.docIn.Seek(de.skipper.DocPointer())
+			de.posPendingFP = de.skipper.PosPointer()
+			de.posPendingCount = de.skipper.PosBufferUpto()
+		}
+		de.nextSkipDoc = de.skipper.NextSkipDoc()
+	}
+	if de.docUpto == de.docFreq {
+		de.doc = NO_MORE_DOCS
+		return de.doc, false
+	}
+	if de.docBufferUpto == LUCENE41_BLOCK_SIZE {
+		if err := de.refillDocs(); err != nil {
+			panic(err)
+		}
+	}
+
+	// Now scan... this is an inlined/pared down version
+	// of NextDoc():
+	for {
+		de.accum += int(de.docDeltaBuffer[de.docBufferUpto])
+		de.freq = int(de.freqBuffer[de.docBufferUpto])
+		de.posPendingCount += de.freq
+		de.docBufferUpto++
+		de.docUpto++
+
+		if de.accum >= target {
+			break
+		}
+		if de.docUpto == de.docFreq {
+			de.doc = NO_MORE_DOCS
+			return de.doc, false
+		}
+	}
+
+	if de.liveDocs == nil || de.liveDocs.Get(de.accum) {
+		de.position = 0
+		de.doc = de.accum
+		return de.doc, true
+	}
+	return de.NextDoc()
+}
+
+// TODO: in theory we could avoid loading frq block
+// when not needed, ie, use skip data to load how far to
+// seek the pos pointer ... instead of having to load frq
+// blocks only to sum up how many positions to skip
+func (de *blockDocsAndPositionsEnum) skipPositions() error {
+	// Skip positions now:
+	toSkip := de.posPendingCount - de.freq
+	leftInBlock := LUCENE41_BLOCK_SIZE - de.posBufferUpto
+	if toSkip < leftInBlock {
+		de.posBufferUpto += toSkip
+	} else {
+		toSkip -= leftInBlock
+		for toSkip >= LUCENE41_BLOCK_SIZE {
+			if de.posIn.FilePointer() == de.lastPosBlockFP {
+				panic("assert fail")
+			}
+			if err := de.owner.forUtil.SkipBlock(de.posIn); err != nil {
+				return err
+			}
+			toSkip -= LUCENE41_BLOCK_SIZE
+		}
+		if err := de.refillPositions(); err != nil {
+			return err
+		}
+		de.posBufferUpto = toSkip
+	}
+	de.position = 0
+	return nil
+}
+
+func (de *blockDocsAndPositionsEnum) NextPosition() int {
+	if de.posPendingFP != -1 {
+		de.posIn.Seek(de.posPendingFP)
+		de.posPendingFP = -1
+
+		// Force buffer refill:
+		de.posBufferUpto = LUCENE41_BLOCK_SIZE
+	}
+
+	if de.posPendingCount > de.freq {
+		if err := de.skipPositions(); err != nil {
+			panic(err)
+		}
+		de.posPendingCount = de.freq
+	}
+
+	if de.posBufferUpto == LUCENE41_BLOCK_SIZE {
+		if err := de.refillPositions(); err != nil {
+			panic(err)
+		}
+		de.posBufferUpto = 0
+	}
+	de.position += int(de.posDeltaBuffer[de.posBufferUpto])
+	de.posBufferUpto++
+	de.posPendingCount--
+	return de.position
+}
+
+func (de *blockDocsAndPositionsEnum) StartOffset() int {
+	return -1
+}
+
+func (de *blockDocsAndPositionsEnum) EndOffset() int {
+	return -1
+}
+
+func (de *blockDocsAndPositionsEnum) Payload() []byte {
+	return nil
+}
+
+// Lucene41PostingsReader.java/EverythingEnum
+
+// Also handles payloads + offsets
+type everythingEnum struct {
+	owner *Lucene41PostingsReader
+
+	encoded []byte
+
+	docDeltaBuffer         []int32
+	freqBuffer             []int32
+	posDeltaBuffer         []int32
+	payloadLengthBuffer    []int32
+	offsetStartDeltaBuffer []int32
+	offsetLengthBuffer     []int32
+
+	payloadBytes    []byte
+	payloadByteUpto int
+	payloadLength   int
+
+	lastStartOffset int
+	startOffset     int
+	endOffset       int
+
+	docBufferUpto int
+	posBufferUpto int
+
+	skipper *lucene41SkipReader
+	skipped bool
+
+	startDocIn store.IndexInput
+
+	docIn   store.IndexInput
+	posIn   store.IndexInput
+	payIn   store.IndexInput
+	payload []byte
+
+	indexHasOffsets  bool
+	indexHasPayloads bool
+
+	docFreq       int
+	totalTermFreq int64
+	docUpto       int
+	doc           int
+	accum         int
+	freq          int
+	position      int
+
+	// how many positions "behind" we are; nextPosition must
+	// skip these to "catch up":
+	posPendingCount int
+
+	// Lazy pos seek: if != -1 then we must seek to this FP
+	// before reading positions:
+	posPendingFP int64
+
+	// Lazy pay seek: if != -1 then we must seek to this FP
+	// before reading payloads/offsets:
+	payPendingFP int64
+
+	// Where this term's postings start in the .doc file:
+	docTermStartFP int64
+
+	// Where this term's postings start in the .pos file:
+	posTermStartFP int64
+
+	// Where this term's payloads/offsets start in the .pay
+	// file:
+	payTermStartFP int64
+
+	// File pointer where the last (vInt encoded) pos delta
+	// block is.  We need this to know whether to bulk
+	// decode vs vInt decode the block:
+	lastPosBlockFP int64
+
+	// Where this term's skip data starts (after
+	// docTermStartFP) in the .doc file (or -1 if there is
+	// no skip data for this term):
+	skipOffset int64
+
+	nextSkipDoc int
+
+	liveDocs util.Bits
+
+	needsOffsets   bool // true if we actually need offsets
+	needsPayloads  bool // true if we actually need payloads
+	singletonDocID int  // docid when there is a single pulsed posting, otherwise -1
 }
 
-func newIntBlockTermState() *intBlockTermState {
-	ts := &intBlockTermState{}
-	parent := NewBlockTermState()
-	ts.BlockTermState, parent.Self = parent, ts
-	return ts
+func newEverythingEnum(owner *Lucene41PostingsReader, fieldInfo FieldInfo) *everythingEnum {
+	ans := &everythingEnum{
+		owner:            owner,
+		encoded:          make([]byte, LUCENE41_MAX_ENCODED_SIZE),
+		docDeltaBuffer:   make([]int32, LUCENE41_MAX_DATA_SIZE),
+		freqBuffer:       make([]int32, LUCENE41_MAX_DATA_SIZE),
+		posDeltaBuffer:   make([]int32, LUCENE41_MAX_DATA_SIZE),
+		startDocIn:       owner.docIn,
+		posIn:            owner.posIn.Clone(),
+		payIn:            owner.payIn.Clone(),
+		indexHasOffsets:  fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS,
+		indexHasPayloads: fieldInfo.storePayloads,
+	}
+	if ans.indexHasOffsets {
+		ans.offsetStartDeltaBuffer = make([]int32, LUCENE41_MAX_DATA_SIZE)
+		ans.offsetLengthBuffer = make([]int32, LUCENE41_MAX_DATA_SIZE)
+	} else {
+		ans.startOffset = -1
+		ans.endOffset = -1
+	}
+	if ans.indexHasPayloads {
+		ans.payloadLengthBuffer = make([]int32, LUCENE41_MAX_DATA_SIZE)
+		ans.payloadBytes = make([]byte, 128)
+	}
+	return ans
 }
 
-func (ts *intBlockTermState) Clone() TermState {
-	clone := newIntBlockTermState()
-	clone.CopyFrom(ts)
-	return clone
+func (de *everythingEnum) canReuse(docIn store.IndexInput, fieldInfo FieldInfo) bool {
+	return docIn == de.startDocIn &&
+		de.indexHasOffsets == (fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS) &&
+		de.indexHasPayloads == fieldInfo.storePayloads
 }
 
-func (ts *intBlockTermState) CopyFrom(other TermState) {
-	if ots, ok := other.(*intBlockTermState); ok {
-		ts.BlockTermState.CopyFrom(ots.BlockTermState)
-		ts.docStartFP = ots.docStartFP
-		ts.posStartFP = ots.posStartFP
-		ts.payStartFP = ots.payStartFP
-		ts.lastPosBlockOffset = ots.lastPosBlockOffset
-		ts.skipOffset = ots.skipOffset
-		ts.singletonDocID = ots.singletonDocID
+func (de *everythingEnum) reset(liveDocs util.Bits, termState *intBlockTermState, flags int) {
+	de.liveDocs = liveDocs
+	de.docFreq = termState.docFreq
+	de.docTermStartFP = termState.docStartFP
+	de.posTermStartFP = termState.posStartFP
+	de.payTermStartFP = termState.payStartFP
+	de.skipOffset = termState.skipOffset
+	de.totalTermFreq = termState.totalTermFreq
+	de.singletonDocID = termState.singletonDocID
+	if de.docFreq > 1 {
+		if de.docIn == nil {
+			// lazy init
+			de.docIn = de.startDocIn.Clone()
+		}
+		de.docIn.Seek(de.docTermStartFP)
+	}
+	de.posPendingFP = de.posTermStartFP
+	de.payPendingFP = de.payTermStartFP
+	de.posPendingCount = 0
+	if de.totalTermFreq < LUCENE41_BLOCK_SIZE {
+		de.lastPosBlockFP = de.posTermStartFP
+	} else if de.totalTermFreq == LUCENE41_BLOCK_SIZE {
+		de.lastPosBlockFP = -1
+	} else {
+		de.lastPosBlockFP = de.posTermStartFP + termState.lastPosBlockOffset
+	}
 
-		// Do not copy bytes, bytesReader (else TermState is
-		// very heavy, ie drags around the entire block's
-		// byte[]).  On seek back, if next() is in fact used
-		// (rare!), they will be re-read from disk.
+	de.needsOffsets = (flags & DOCS_POSITIONS_ENUM_FLAG_OFF_SETS) != 0
+	de.needsPayloads = (flags & DOCS_POSITIONS_ENUM_FLAG_PAYLOADS) != 0
+
+	de.doc = -1
+	de.accum = 0
+	de.docUpto = 0
+	de.nextSkipDoc = LUCENE41_BLOCK_SIZE - 1
+	de.docBufferUpto = LUCENE41_BLOCK_SIZE
+	de.skipped = false
+}
+
+func (de *everythingEnum) Freq() int {
+	return de.freq
+}
+
+func (de *everythingEnum) Cost() int64 {
+	return int64(de.docFreq)
+}
+
+func (de *everythingEnum) DocId() int {
+	return de.doc
+}
+
+func (de *everythingEnum) refillDocs() (err error) {
+	left := de.docFreq - de.docUpto
+	if left <= 0 {
+		panic("assert fail")
+	}
+
+	if left >= LUCENE41_BLOCK_SIZE {
+		if err = de.owner.forUtil.ReadBlock(de.docIn, de.encoded, de.docDeltaBuffer); err != nil {
+			return err
+		}
+		err = de.owner.forUtil.ReadBlock(de.docIn, de.encoded, de.freqBuffer)
+	} else if de.docFreq == 1 {
+		de.docDeltaBuffer[0] = int32(de.singletonDocID)
+		de.freqBuffer[0] = int32(de.totalTermFreq)
 	} else {
-		panic(fmt.Sprintf("Can not copy from %v", reflect.TypeOf(other).Name()))
+		// Read vInts:
+		err = readVIntBlock(de.docIn, de.docDeltaBuffer, de.freqBuffer, left, true)
 	}
+	de.docBufferUpto = 0
+	return err
 }
 
-func (ts *intBlockTermState) String() string {
-	return fmt.Sprintf("%v docStartFP=%v posStartFP=%v payStartFP=%v lastPosBlockOffset=%v skipOffset=%v singletonDocID=%v",
-		ts.BlockTermState, ts.docStartFP, ts.posStartFP, ts.payStartFP, ts.lastPosBlockOffset, ts.skipOffset, ts.singletonDocID)
+// Reads the payload bytes of the current vInt encoded position
+// into payloadBytes.
+func (de *everythingEnum) readPayloadBytes(in store.IndexInput, upto, length int) error {
+	if upto+length > len(de.payloadBytes) {
+		next := make([]byte, 2*(upto+length))
+		copy(next, de.payloadBytes[:upto])
+		de.payloadBytes = next
+	}
+	return in.ReadBytes(de.payloadBytes[upto : upto+length])
+}
+
+func (de *everythingEnum) refillPositions() (err error) {
+	if de.posIn.FilePointer() == de.lastPosBlockFP {
+		count := int(de.totalTermFreq % LUCENE41_BLOCK_SIZE)
+		payloadLength, offsetLength := 0, 0
+		de.payloadByteUpto = 0
+		for i := 0; i < count; i++ {
+			code, err := de.posIn.ReadVInt()
+			if err != nil {
+				return err
+			}
+			if de.indexHasPayloads {
+				if (code & 1) != 0 {
+					if payloadLength, err = asInt(de.posIn.ReadVInt()); err != nil {
+						return err
+					}
+				}
+				de.payloadLengthBuffer[i] = int32(payloadLength)
+				de.posDeltaBuffer[i] = int32(uint32(code) >> 1)
+				if payloadLength != 0 {
+					if err = de.readPayloadBytes(de.posIn, de.payloadByteUpto, payloadLength); err != nil {
+						return err
+					}
+					de.payloadByteUpto += payloadLength
+				}
+			} else {
+				de.posDeltaBuffer[i] = code
+			}
+
+			if de.indexHasOffsets {
+				deltaCode, err := de.posIn.ReadVInt()
+				if err != nil {
+					return err
+				}
+				if (deltaCode & 1) != 0 {
+					if offsetLength, err = asInt(de.posIn.ReadVInt()); err != nil {
+						return err
+					}
+				}
+				de.offsetStartDeltaBuffer[i] = int32(uint32(deltaCode) >> 1)
+				de.offsetLengthBuffer[i] = int32(offsetLength)
+			}
+		}
+		de.payloadByteUpto = 0
+		return nil
+	}
+
+	if err = de.owner.forUtil.ReadBlock(de.posIn, de.encoded, de.posDeltaBuffer); err != nil {
+		return err
+	}
+
+	if de.indexHasPayloads {
+		if de.needsPayloads {
+			if err = de.owner.forUtil.ReadBlock(de.payIn, de.encoded, de.payloadLengthBuffer); err != nil {
+				return err
+			}
+			numBytes, err := asInt(de.payIn.ReadVInt())
+			if err != nil {
+				return err
+			}
+			if err = de.readPayloadBytes(de.payIn, 0, numBytes); err != nil {
+				return err
+			}
+		} else {
+			// this works, because when writing a vint block we always
+			// force the first length to be written
+			if err = de.owner.forUtil.SkipBlock(de.payIn); err != nil { // skip over lengths
+				return err
+			}
+			numBytes, err := de.payIn.ReadVInt() // read length of payloadBytes
+			if err != nil {
+				return err
+			}
+			de.payIn.Seek(de.payIn.FilePointer() + int64(numBytes)) // skip over payloadBytes
+		}
+		de.payloadByteUpto = 0
+	}
+
+	if de.indexHasOffsets {
+		if de.needsOffsets {
+			if err = de.owner.forUtil.ReadBlock(de.payIn, de.encoded, de.offsetStartDeltaBuffer); err != nil {
+				return err
+			}
+			err = de.owner.forUtil.ReadBlock(de.payIn, de.encoded, de.offsetLengthBuffer)
+		} else {
+			if err = de.owner.forUtil.SkipBlock(de.payIn); err != nil { // skip over starts
+				return err
+			}
+			err = de.owner.forUtil.SkipBlock(de.payIn) // skip over lengths
+		}
+	}
+	return err
+}
+
+func (de *everythingEnum) NextDoc() (doc int, more bool) {
+	for {
+		if de.docUpto == de.docFreq {
+			de.doc = NO_MORE_DOCS
+			return de.doc, false
+		}
+		if de.docBufferUpto == LUCENE41_BLOCK_SIZE {
+			if err := de.refillDocs(); err != nil {
+				panic(err)
+			}
+		}
+
+		de.accum += int(de.docDeltaBuffer[de.docBufferUpto])
+		de.freq = int(de.freqBuffer[de.docBufferUpto])
+		de.posPendingCount += de.freq
+		de.docBufferUpto++
+		de.docUpto++
+
+		if de.liveDocs == nil || de.liveDocs.Get(de.accum) {
+			de.doc = de.accum
+			de.position = 0
+			de.lastStartOffset = 0
+			return de.doc, true
+		}
+	}
+}
+
+func (de *everythingEnum) Advance(target int) (doc int, more bool) {
+	// TODO: make frq block load lazy/skippable
+
+	if de.docFreq > LUCENE41_BLOCK_SIZE && target > de.nextSkipDoc {
+		if de.skipper == nil {
+			// Lazy init: first time this enum has ever been used for skipping
+			de.skipper = newLucene41SkipReader(de.docIn.Clone(), LUCENE41_MAX_SKIP_LEVELS,
+				LUCENE41_BLOCK_SIZE, true, de.indexHasOffsets, de.indexHasPayloads)
+		}
+
+		if !de.skipped {
+			if de.skipOffset == -1 {
+				panic("assert fail")
+			}
+			// This is the first time this enum has skipped since reset() was
+			// called; load the skip data:
+			de.skipper.init(de.docTermStartFP+de.skipOffset, de.docTermStartFP,
+				de.posTermStartFP, de.payTermStartFP, de.docFreq)
+			de.skipped = true
+		}
+
+		n, err := de.skipper.SkipTo(target)
+		if err != nil {
+			panic(err)
+		}
+		newDocUpto := n + 1
+
+		if newDocUpto > de.docUpto {
+			// Skipper moved
+			if newDocUpto%LUCENE41_BLOCK_SIZE != 0 {
+				panic(fmt.Sprintf("got %v", newDocUpto))
+			}
+			de.docUpto = newDocUpto
+
+			// Force to read next block
+			de.docBufferUpto = LUCENE41_BLOCK_SIZE
+			de.accum = de.skipper.Doc()
+			de.docIn.Seek(de.skipper.DocPointer())
+			de.posPendingFP = de.skipper.PosPointer()
+			de.payPendingFP = de.skipper.PayPointer()
+			de.posPendingCount = de.skipper.PosBufferUpto()
+			de.lastStartOffset = 0 // new document
+			de.payloadByteUpto = de.skipper.PayloadByteUpto()
+		}
+		de.nextSkipDoc = de.skipper.NextSkipDoc()
+	}
+	if de.docUpto == de.docFreq {
+		de.doc = NO_MORE_DOCS
+		return de.doc, false
+	}
+	if de.docBufferUpto == LUCENE41_BLOCK_SIZE {
+		if err := de.refillDocs(); err != nil {
+			panic(err)
+		}
+	}
+
+	// Now scan:
+	for {
+		de.accum += int(de.docDeltaBuffer[de.docBufferUpto])
+		de.freq = int(de.freqBuffer[de.docBufferUpto])
+		de.posPendingCount += de.freq
+		de.docBufferUpto++
+		de.docUpto++
+
+		if de.accum >= target {
+			break
+		}
+		if de.docUpto == de.docFreq {
+			de.doc = NO_MORE_DOCS
+			return de.doc, false
+		}
+	}
+
+	if de.liveDocs == nil || de.liveDocs.Get(de.accum) {
+		de.position = 0
+		de.lastStartOffset = 0
+		de.doc = de.accum
+		return de.doc, true
+	}
+	return de.NextDoc()
+}
+
+// TODO: in theory we could avoid loading frq block
+// when not needed, ie, use skip data to load how far to
+// seek the pos pointer ... instead of having to load frq
+// blocks only to sum up how many positions to skip
+func (de *everythingEnum) skipPositions() error {
+	// Skip positions now:
+	toSkip := de.posPendingCount - de.freq
+	leftInBlock := LUCENE41_BLOCK_SIZE - de.posBufferUpto
+	if toSkip < leftInBlock {
+		for end := de.posBufferUpto + toSkip; de.posBufferUpto < end; de.posBufferUpto++ {
+			if de.indexHasPayloads {
+				de.payloadByteUpto += int(de.payloadLengthBuffer[de.posBufferUpto])
+			}
+		}
+	} else {
+		toSkip -= leftInBlock
+		for toSkip >= LUCENE41_BLOCK_SIZE {
+			if de.posIn.FilePointer() == de.lastPosBlockFP {
+				panic("assert fail")
+			}
+			if err := de.owner.forUtil.SkipBlock(de.posIn); err != nil {
+				return err
+			}
+
+			if de.indexHasPayloads {
+				// Skip payloadLength block:
+				if err := de.owner.forUtil.SkipBlock(de.payIn); err != nil {
+					return err
+				}
+				// Skip payloadBytes block:
+				numBytes, err := de.payIn.ReadVInt()
+				if err != nil {
+					return err
+				}
+				de.payIn.Seek(de.payIn.FilePointer() + int64(numBytes))
+			}
+
+			if de.indexHasOffsets {
+				if err := de.owner.forUtil.SkipBlock(de.payIn); err != nil {
+					return err
+				}
+				if err := de.owner.forUtil.SkipBlock(de.payIn); err != nil {
+					return err
+				}
+			}
+			toSkip -= LUCENE41_BLOCK_SIZE
+		}
+		if err := de.refillPositions(); err != nil {
+			return err
+		}
+		de.payloadByteUpto = 0
+		for de.posBufferUpto = 0; de.posBufferUpto < toSkip; de.posBufferUpto++ {
+			if de.indexHasPayloads {
+				de.payloadByteUpto += int(de.payloadLengthBuffer[de.posBufferUpto])
+			}
+		}
+	}
+	de.position = 0
+	de.lastStartOffset = 0
+	return nil
+}
+
+func (de *everythingEnum) NextPosition() int {
+	if de.posPendingFP != -1 {
+		de.posIn.Seek(de.posPendingFP)
+		de.posPendingFP = -1
+
+		if de.payPendingFP != -1 {
+			de.payIn.Seek(de.payPendingFP)
+			de.payPendingFP = -1
+		}
+
+		// Force buffer refill:
+		de.posBufferUpto = LUCENE41_BLOCK_SIZE
+	}
+
+	if de.posPendingCount > de.freq {
+		if err := de.skipPositions(); err != nil {
+			panic(err)
+		}
+		de.posPendingCount = de.freq
+	}
+
+	if de.posBufferUpto == LUCENE41_BLOCK_SIZE {
+		if err := de.refillPositions(); err != nil {
+			panic(err)
+		}
+		de.posBufferUpto = 0
+	}
+	de.position += int(de.posDeltaBuffer[de.posBufferUpto])
+
+	if de.indexHasPayloads {
+		de.payloadLength = int(de.payloadLengthBuffer[de.posBufferUpto])
+		de.payload = de.payloadBytes[de.payloadByteUpto : de.payloadByteUpto+de.payloadLength]
+		de.payloadByteUpto += de.payloadLength
+	}
+
+	if de.indexHasOffsets {
+		de.startOffset = de.lastStartOffset + int(de.offsetStartDeltaBuffer[de.posBufferUpto])
+		de.endOffset = de.startOffset + int(de.offsetLengthBuffer[de.posBufferUpto])
+		de.lastStartOffset = de.startOffset
+	}
+
+	de.posBufferUpto++
+	de.posPendingCount--
+	return de.position
+}
+
+func (de *everythingEnum) StartOffset() int {
+	return de.startOffset
+}
+
+func (de *everythingEnum) EndOffset() int {
+	return de.endOffset
+}
+
+func (de *everythingEnum) Payload() []byte {
+	if de.payloadLength == 0 {
+		return nil
+	}
+	return de.payload
 }
 
 type Lucene41StoredFieldsReader struct {
@@ -204,14 +1483,30 @@ type Lucene41StoredFieldsReader struct {
 }
 
 func newLucene41StoredFieldsReader(d store.Directory, si SegmentInfo, fn FieldInfos, ctx store.IOContext) (r StoredFieldsReader, err error) {
-	formatName := "Lucene41StoredFields"
-	compressionMode := codec.COMPRESSION_MODE_FAST
-	// chunkSize := 1 << 14
-	p, err := newCompressingStoredFieldsReader(d, si, "", fn, ctx, formatName, compressionMode)
-	if err == nil {
-		r = &Lucene41StoredFieldsReader{p}
+	mode, err := storedFieldsModeOf(si)
+	if err != nil {
+		return nil, err
 	}
-	return r, nil
+	f := mode.format()
+	p, err := newCompressingStoredFieldsReader(d, si, f.segmentSuffix, fn, ctx, f.formatName, f.compressionMode)
+	if err != nil {
+		return nil, err
+	}
+	return &Lucene41StoredFieldsReader{p}, nil
+}
+
+/*
+Returns a stored fields writer of the given mode, which is recorded
+in the segment's attributes so the reader can pick the matching
+decompressor.
+*/
+func newLucene41StoredFieldsWriter(d store.Directory, si *SegmentInfo, ctx store.IOContext, mode StoredFieldsMode) (w StoredFieldsWriter, err error) {
+	if previous, ok := si.attributes[LUCENE41_SF_MODE_KEY]; ok && previous != mode.String() {
+		panic(fmt.Sprintf("found existing value for %v for segment: %v old=%v, new=%v",
+			LUCENE41_SF_MODE_KEY, si.name, previous, mode))
+	}
+	si.attributes[LUCENE41_SF_MODE_KEY] = mode.String()
+	return mode.format().FieldsWriter(d, si, ctx)
 }
 
 const (
@@ -219,6 +1514,16 @@ const (
 	CODEC_SFX_DAT             = "Data"
 	CODEC_SFX_VERSION_START   = 0
 	CODEC_SFX_VERSION_CURRENT = CODEC_SFX_VERSION_START
+
+	CODEC_SFX_STRING         = 0x00
+	CODEC_SFX_BYTE_ARR       = 0x01
+	CODEC_SFX_NUMERIC_INT    = 0x02
+	CODEC_SFX_NUMERIC_FLOAT  = 0x03
+	CODEC_SFX_NUMERIC_LONG   = 0x04
+	CODEC_SFX_NUMERIC_DOUBLE = 0x05
+
+	CODEC_SFX_TYPE_BITS = 3 // bitsRequired(NUMERIC_DOUBLE)
+	CODEC_SFX_TYPE_MASK = 7 // mask with TYPE_BITS bits set
 )
 
 type CompressingStoredFieldsReader struct {
@@ -314,16 +1619,207 @@ func (r *CompressingStoredFieldsReader) Close() (err error) {
 	return err
 }
 
-func (r *CompressingStoredFieldsReader) visitDocument(n int, visitor StoredFieldVisitor) error {
-	panic("not implemented yet")
+func readStoredField(in *store.ByteArrayDataInput, visitor StoredFieldVisitor, info FieldInfo, bits int) (err error) {
+	switch bits & CODEC_SFX_TYPE_MASK {
+	case CODEC_SFX_BYTE_ARR:
+		length, err := in.ReadVInt()
+		if err != nil {
+			return err
+		}
+		data := make([]byte, length)
+		if err = in.ReadBytes(data); err != nil {
+			return err
+		}
+		return visitor.binaryField(info, data)
+	case CODEC_SFX_STRING:
+		length, err := in.ReadVInt()
+		if err != nil {
+			return err
+		}
+		data := make([]byte, length)
+		if err = in.ReadBytes(data); err != nil {
+			return err
+		}
+		return visitor.stringField(info, string(data))
+	case CODEC_SFX_NUMERIC_INT:
+		n, err := in.ReadInt()
+		if err != nil {
+			return err
+		}
+		return visitor.intField(info, int(n))
+	case CODEC_SFX_NUMERIC_FLOAT:
+		n, err := in.ReadInt()
+		if err != nil {
+			return err
+		}
+		return visitor.floatField(info, math.Float32frombits(uint32(n)))
+	case CODEC_SFX_NUMERIC_LONG:
+		n, err := in.ReadLong()
+		if err != nil {
+			return err
+		}
+		return visitor.longField(info, n)
+	case CODEC_SFX_NUMERIC_DOUBLE:
+		n, err := in.ReadLong()
+		if err != nil {
+			return err
+		}
+		return visitor.doubleField(info, math.Float64frombits(uint64(n)))
+	default:
+		panic(fmt.Sprintf("Unknown type flag: %x", bits))
+	}
+}
+
+func skipStoredField(in *store.ByteArrayDataInput, bits int) (err error) {
+	switch bits & CODEC_SFX_TYPE_MASK {
+	case CODEC_SFX_BYTE_ARR, CODEC_SFX_STRING:
+		length, err := in.ReadVInt()
+		if err != nil {
+			return err
+		}
+		in.SkipBytes(int(length))
+	case CODEC_SFX_NUMERIC_INT, CODEC_SFX_NUMERIC_FLOAT:
+		in.SkipBytes(4)
+	case CODEC_SFX_NUMERIC_LONG, CODEC_SFX_NUMERIC_DOUBLE:
+		in.SkipBytes(8)
+	default:
+		panic(fmt.Sprintf("Unknown type flag: %x", bits))
+	}
+	return nil
+}
+
+func (r *CompressingStoredFieldsReader) visitDocument(docID int, visitor StoredFieldVisitor) error {
+	r.fieldsStream.Seek(r.indexReader.startPointer(docID))
+
+	n, err := r.fieldsStream.ReadVInt()
+	if err != nil {
+		return err
+	}
+	docBase := int(n)
+	if n, err = r.fieldsStream.ReadVInt(); err != nil {
+		return err
+	}
+	chunkDocs := int(n)
+	if docID < docBase || docID >= docBase+chunkDocs || docBase+chunkDocs > r.numDocs {
+		return errors.New(fmt.Sprintf("Corrupted: docBase=%v,chunkDocs=%v,numDocs=%v (resource=%v)",
+			docBase, chunkDocs, r.numDocs, r.fieldsStream))
+	}
+
+	var numStoredFields, offset, length, totalLength int
+	if chunkDocs == 1 {
+		if n, err = r.fieldsStream.ReadVInt(); err != nil {
+			return err
+		}
+		numStoredFields = int(n)
+		if n, err = r.fieldsStream.ReadVInt(); err != nil {
+			return err
+		}
+		length = int(n)
+		totalLength = length
+	} else {
+		bitsPerStoredFields, err := r.fieldsStream.ReadVInt()
+		if err != nil {
+			return err
+		}
+		if bitsPerStoredFields == 0 {
+			if n, err = r.fieldsStream.ReadVInt(); err != nil {
+				return err
+			}
+			numStoredFields = int(n)
+		} else if bitsPerStoredFields > 31 {
+			return errors.New(fmt.Sprintf("bitsPerStoredFields=%v (resource=%v)", bitsPerStoredFields, r.fieldsStream))
+		} else {
+			reader, err := util.NewPackedReaderNoHeader(r.fieldsStream, util.PACKED,
+				int32(r.packedIntsVersion), int32(chunkDocs), uint32(bitsPerStoredFields))
+			if err != nil {
+				return err
+			}
+			numStoredFields = int(reader.Get(int32(docID - docBase)))
+		}
+
+		bitsPerLength, err := r.fieldsStream.ReadVInt()
+		if err != nil {
+			return err
+		}
+		if bitsPerLength == 0 {
+			if n, err = r.fieldsStream.ReadVInt(); err != nil {
+				return err
+			}
+			length = int(n)
+			offset = (docID - docBase) * length
+			totalLength = chunkDocs * length
+		} else if bitsPerLength > 31 {
+			return errors.New(fmt.Sprintf("bitsPerLength=%v (resource=%v)", bitsPerLength, r.fieldsStream))
+		} else {
+			reader, err := util.NewPackedReaderNoHeader(r.fieldsStream, util.PACKED,
+				int32(r.packedIntsVersion), int32(chunkDocs), uint32(bitsPerLength))
+			if err != nil {
+				return err
+			}
+			for i := 0; i < docID-docBase; i++ {
+				offset += int(reader.Get(int32(i)))
+			}
+			length = int(reader.Get(int32(docID - docBase)))
+			totalLength = offset + length
+			for i := docID - docBase + 1; i < chunkDocs; i++ {
+				totalLength += int(reader.Get(int32(i)))
+			}
+		}
+	}
+
+	if (length == 0) != (numStoredFields == 0) {
+		return errors.New(fmt.Sprintf("length=%v, numStoredFields=%v (resource=%v)",
+			length, numStoredFields, r.fieldsStream))
+	}
+	if numStoredFields == 0 {
+		// nothing to do
+		return nil
+	}
+
+	if r.bytes, err = r.decompressor.Decompress(r.fieldsStream, totalLength, offset, length, r.bytes); err != nil {
+		return err
+	}
+
+	documentInput := store.NewByteArrayDataInput(r.bytes)
+	for fieldIDX := 0; fieldIDX < numStoredFields; fieldIDX++ {
+		infoAndBits, err := documentInput.ReadVLong()
+		if err != nil {
+			return err
+		}
+		fieldNumber := int32(uint64(infoAndBits) >> CODEC_SFX_TYPE_BITS)
+		fieldInfo := r.fieldInfos.byNumber[fieldNumber]
+
+		bits := int(infoAndBits & CODEC_SFX_TYPE_MASK)
+		if bits > CODEC_SFX_NUMERIC_DOUBLE {
+			panic(fmt.Sprintf("bits=%x", bits))
+		}
+
+		switch visitor.needsField(fieldInfo) {
+		case SOTRED_FIELD_VISITOR_STATUS_YES:
+			err = readStoredField(documentInput, visitor, fieldInfo, bits)
+		case SOTRED_FIELD_VISITOR_STATUS_NO:
+			err = skipStoredField(documentInput, bits)
+		case SOTRED_FIELD_VISITOR_STATUS_STOP:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (r *CompressingStoredFieldsReader) clone() StoredFieldsReader {
 	r.ensureOpen()
-	// return CompressingStoredFieldsProducer()
-	panic("not implemented yet")
-	return nil
+	return &CompressingStoredFieldsReader{
+		fieldInfos:        r.fieldInfos,
+		fieldsStream:      r.fieldsStream.Clone(),
+		indexReader:       r.indexReader,
+		packedIntsVersion: r.packedIntsVersion,
+		compressionMode:   r.compressionMode,
+		decompressor:      r.compressionMode.NewDecompressor(),
+		numDocs:           r.numDocs,
+	}
 }
 
 type CompressingStoredFieldsIndexReader struct {
@@ -413,3 +1909,61 @@ func newCompressingStoredFieldsIndexReader(fieldsIndexIn store.IndexInput, si Se
 
 	return r, nil
 }
+
+func (r *CompressingStoredFieldsIndexReader) block(docID int) int {
+	lo, hi := 0, len(r.docBases)-1
+	for lo <= hi {
+		mid := int(uint(lo+hi) >> 1)
+		midValue := r.docBases[mid]
+		if midValue == docID {
+			return mid
+		} else if midValue < docID {
+			lo = mid + 1
+		} else {
+			hi = mid - 1
+		}
+	}
+	return hi
+}
+
+func (r *CompressingStoredFieldsIndexReader) relativeDocBase(block, relativeChunk int) int {
+	expected := r.avgChunkDocs[block] * relativeChunk
+	delta := moveLowOrderBitToSign(r.docBasesDeltas[block].Get(int32(relativeChunk)))
+	return expected + int(delta)
+}
+
+func (r *CompressingStoredFieldsIndexReader) relativeStartPointer(block, relativeChunk int) int64 {
+	expected := r.avgChunkSizes[block] * int64(relativeChunk)
+	delta := moveLowOrderBitToSign(r.startPointersDeltas[block].Get(int32(relativeChunk)))
+	return expected + delta
+}
+
+func (r *CompressingStoredFieldsIndexReader) relativeChunk(block, relativeDoc int) int {
+	lo, hi := 0, int(r.docBasesDeltas[block].Size())-1
+	for lo <= hi {
+		mid := int(uint(lo+hi) >> 1)
+		midValue := r.relativeDocBase(block, mid)
+		if midValue == relativeDoc {
+			return mid
+		} else if midValue < relativeDoc {
+			lo = mid + 1
+		} else {
+			hi = mid - 1
+		}
+	}
+	return hi
+}
+
+// Returns the start pointer of the chunk which contains docID.
+func (r *CompressingStoredFieldsIndexReader) startPointer(docID int) int64 {
+	if docID < 0 || docID >= r.maxDoc {
+		panic(fmt.Sprintf("docID out of range [0-%v]: %v", r.maxDoc, docID))
+	}
+	block := r.block(docID)
+	relativeChunk := r.relativeChunk(block, docID-r.docBases[block])
+	return r.startPointers[block] + r.relativeStartPointer(block, relativeChunk)
+}
+
+func moveLowOrderBitToSign(n int64) int64 {
+	return int64(uint64(n)>>1) ^ -(n & 1)
+}
@@ -1,15 +1,62 @@
 package index
 
 import (
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
 	"github.com/balzaczyy/golucene/util"
 	"math"
 )
 
+// ForUtil.java
+
+const (
+	// Special number of bits per value used whenever all values to
+	// encode are equal.
+	LUCENE41_ALL_VALUES_EQUAL = 0
+
+	// Upper limit of the number of bytes that might be required to
+	// store LUCENE41_BLOCK_SIZE encoded values.
+	LUCENE41_MAX_ENCODED_SIZE = LUCENE41_BLOCK_SIZE * 4
+)
+
+/*
+Upper limit of the number of values that might be decoded in a single
+call to ReadBlock(). Although values after LUCENE41_BLOCK_SIZE are
+garbage, it is necessary to allocate value buffers whose size is >=
+LUCENE41_MAX_DATA_SIZE to avoid index out of range panics.
+*/
+var LUCENE41_MAX_DATA_SIZE = computeMaxDataSize()
+
+func computeMaxDataSize() int {
+	maxDataSize := 0
+	for version := int32(util.PACKED_VERSION_START); version <= util.PACKED_VERSION_CURRENT; version++ {
+		for _, format := range []util.PackedFormat{util.PACKED, util.PACKED_SINGLE_BLOCK} {
+			for bpv := uint32(1); bpv <= 32; bpv++ {
+				if !format.IsSupported(bpv) {
+					continue
+				}
+				decoder := util.GetPackedIntsDecoder(format, version, bpv)
+				iterations := int(computeIterations(decoder))
+				if n := iterations * decoder.ByteValueCount(); n > maxDataSize {
+					maxDataSize = n
+				}
+			}
+		}
+	}
+	return maxDataSize
+}
+
+/*
+Encode all values in normal area with fixed bit width, which is
+determined by the max value in this block.
+*/
 type ForUtil struct {
 	encodedSizes []int32
 	encoders     []util.PackedIntsEncoder
 	decoders     []util.PackedIntsDecoder
-	iterations   []int32
+	// specialized decoders of the PACKED format, nil otherwise
+	forDecoders []codec.ForDecoder
+	iterations  []int32
 }
 
 type DataInput interface {
@@ -26,6 +73,7 @@ func NewForUtil(in DataInput) (fu ForUtil, err error) {
 	self.encodedSizes = make([]int32, 33)
 	self.encoders = make([]util.PackedIntsEncoder, 33)
 	self.decoders = make([]util.PackedIntsDecoder, 33)
+	self.forDecoders = make([]codec.ForDecoder, 33)
 	self.iterations = make([]int32, 33)
 
 	for bpv := 1; bpv <= 32; bpv++ {
@@ -41,6 +89,38 @@ func NewForUtil(in DataInput) (fu ForUtil, err error) {
 		self.encodedSizes[bpv] = encodedSize(format, packedIntsVersion, bitsPerValue)
 		self.encoders[bpv] = util.GetPackedIntsEncoder(format, packedIntsVersion, bitsPerValue)
 		self.decoders[bpv] = util.GetPackedIntsDecoder(format, packedIntsVersion, bitsPerValue)
+		if format == util.PACKED {
+			self.forDecoders[bpv] = codec.GetForDecoder(bitsPerValue)
+		}
+		self.iterations[bpv] = computeIterations(self.decoders[bpv])
+	}
+	return self, nil
+}
+
+/*
+Create a new ForUtil instance and save state into out. All bit widths
+are encoded with the PACKED format.
+*/
+func NewForUtilInto(out util.DataOutput) (fu ForUtil, err error) {
+	self := ForUtil{}
+	if err = out.WriteVInt(util.PACKED_VERSION_CURRENT); err != nil {
+		return self, err
+	}
+	self.encodedSizes = make([]int32, 33)
+	self.encoders = make([]util.PackedIntsEncoder, 33)
+	self.decoders = make([]util.PackedIntsDecoder, 33)
+	self.forDecoders = make([]codec.ForDecoder, 33)
+	self.iterations = make([]int32, 33)
+
+	format := util.PackedFormat(util.PACKED)
+	for bpv := 1; bpv <= 32; bpv++ {
+		if err = out.WriteVInt(int32(format)<<5 | int32(bpv-1)); err != nil {
+			return self, err
+		}
+		self.encodedSizes[bpv] = encodedSize(format, util.PACKED_VERSION_CURRENT, uint32(bpv))
+		self.encoders[bpv] = util.GetPackedIntsEncoder(format, util.PACKED_VERSION_CURRENT, uint32(bpv))
+		self.decoders[bpv] = util.GetPackedIntsDecoder(format, util.PACKED_VERSION_CURRENT, uint32(bpv))
+		self.forDecoders[bpv] = codec.GetForDecoder(uint32(bpv))
 		self.iterations[bpv] = computeIterations(self.decoders[bpv])
 	}
 	return self, nil
@@ -55,3 +135,110 @@ func encodedSize(format util.PackedFormat, packedIntsVersion int32, bitsPerValue
 func computeIterations(decoder util.PackedIntsDecoder) int32 {
 	return int32(math.Ceil(float64(LUCENE41_BLOCK_SIZE) / float64(decoder.ByteValueCount())))
 }
+
+/*
+Read the next block of data (For format). encoded is used as a
+scratch buffer of at least LUCENE41_MAX_ENCODED_SIZE bytes, and
+decoded must hold at least LUCENE41_MAX_DATA_SIZE values.
+*/
+func (u ForUtil) ReadBlock(in store.IndexInput, encoded []byte, decoded []int32) error {
+	numBits, err := in.ReadByte()
+	if err != nil {
+		return err
+	}
+	if numBits > 32 {
+		panic("assert fail")
+	}
+
+	if numBits == LUCENE41_ALL_VALUES_EQUAL {
+		value, err := in.ReadVInt()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < LUCENE41_BLOCK_SIZE; i++ {
+			decoded[i] = value
+		}
+		return nil
+	}
+
+	encodedSize := u.encodedSizes[numBits]
+	if err = in.ReadBytes(encoded[:encodedSize]); err != nil {
+		return err
+	}
+
+	decoder := u.decoders[numBits]
+	iters := int(u.iterations[numBits])
+	if iters*decoder.ByteValueCount() < LUCENE41_BLOCK_SIZE {
+		panic("assert fail")
+	}
+
+	if forDecoder := u.forDecoders[numBits]; forDecoder != nil {
+		forDecoder(encoded, decoded, iters)
+	} else {
+		decoder.DecodeByteToInt(encoded, decoded, iters)
+	}
+	return nil
+}
+
+// Write a block of data (For format).
+func (u ForUtil) WriteBlock(data []int32, out store.IndexOutput) error {
+	if isAllEqual(data) {
+		if err := out.WriteByte(LUCENE41_ALL_VALUES_EQUAL); err != nil {
+			return err
+		}
+		return out.WriteVInt(data[0])
+	}
+
+	numBits := bitsRequired(data)
+	if numBits <= 0 || numBits > 32 {
+		panic("assert fail")
+	}
+	if err := out.WriteByte(byte(numBits)); err != nil {
+		return err
+	}
+	w := util.NewPackedWriterNoHeader(out, util.PACKED, LUCENE41_BLOCK_SIZE, numBits)
+	for _, v := range data[:LUCENE41_BLOCK_SIZE] {
+		if err := w.Add(int64(v)); err != nil {
+			return err
+		}
+	}
+	return w.Finish()
+}
+
+func isAllEqual(data []int32) bool {
+	v := data[0]
+	for _, d := range data[1:LUCENE41_BLOCK_SIZE] {
+		if d != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Compute the number of bits required to serialize any of the longs in data.
+func bitsRequired(data []int32) uint32 {
+	or := uint32(0)
+	for _, v := range data[:LUCENE41_BLOCK_SIZE] {
+		// assert v >= 0
+		or |= uint32(v)
+	}
+	return util.BitsRequired(int64(or))
+}
+
+// Skip the next block of data.
+func (u ForUtil) SkipBlock(in store.IndexInput) error {
+	numBits, err := in.ReadByte()
+	if err != nil {
+		return err
+	}
+	if numBits == LUCENE41_ALL_VALUES_EQUAL {
+		_, err = in.ReadVInt()
+		return err
+	}
+	if numBits > 32 {
+		panic("assert fail")
+	}
+	encodedSize := u.encodedSizes[numBits]
+	in.Seek(in.FilePointer() + int64(encodedSize))
+	return nil
+}
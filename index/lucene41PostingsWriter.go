@@ -0,0 +1,473 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// Lucene41PostingsWriter.java
+
+/*
+Concrete class that writes docId (maybe frq,pos,offset,payloads)
+list with postings format.
+
+Postings list for each term will be stored separately.
+*/
+type Lucene41PostingsWriter struct {
+	docOut   store.IndexOutput
+	posOut   store.IndexOutput
+	payOut   store.IndexOutput
+	termsOut store.IndexOutput
+
+	// How current field indexes postings:
+	fieldHasFreqs     bool
+	fieldHasPositions bool
+	fieldHasOffsets   bool
+	fieldHasPayloads  bool
+
+	// Holds starting file pointers for each term:
+	docTermStartFP int64
+	posTermStartFP int64
+	payTermStartFP int64
+
+	docDeltaBuffer []int32
+	freqBuffer     []int32
+	docBufferUpto  int
+
+	posDeltaBuffer         []int32
+	payloadLengthBuffer    []int32
+	offsetStartDeltaBuffer []int32
+	offsetLengthBuffer     []int32
+	posBufferUpto          int
+
+	payloadBytes    []byte
+	payloadByteUpto int
+
+	lastBlockDocID           int
+	lastBlockPosFP           int64
+	lastBlockPayFP           int64
+	lastBlockPosBufferUpto   int
+	lastBlockPayloadByteUpto int
+
+	lastDocID       int
+	lastPosition    int
+	lastStartOffset int
+	docCount        int
+
+	forUtil    ForUtil
+	skipWriter *lucene41SkipWriter
+
+	pendingTerms []lucene41PendingTerm
+	bytesWriter  *store.RAMOutputStream
+}
+
+type lucene41PendingTerm struct {
+	docStartFP         int64
+	posStartFP         int64
+	payStartFP         int64
+	skipOffset         int64
+	lastPosBlockOffset int64
+	singletonDocID     int
+}
+
+// Creates a postings writer for the given segment.
+func newLucene41PostingsWriter(state SegmentWriteState) (w *Lucene41PostingsWriter, err error) {
+	docOut, err := state.directory.CreateOutput(util.SegmentFileName(
+		state.segmentInfo.name, state.segmentSuffix, LUCENE41_DOC_EXTENSION), state.context)
+	if err != nil {
+		return nil, err
+	}
+
+	var posOut, payOut store.IndexOutput
+	success := false
+	defer func() {
+		if !success {
+			util.CloseWhileSuppressingError(docOut, posOut, payOut)
+		}
+	}()
+
+	if err = codec.WriteHeader(docOut, LUCENE41_DOC_CODEC, LUCENE41_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	forUtil, err := NewForUtilInto(docOut)
+	if err != nil {
+		return nil, err
+	}
+
+	w = &Lucene41PostingsWriter{
+		docOut:         docOut,
+		docDeltaBuffer: make([]int32, LUCENE41_MAX_DATA_SIZE),
+		freqBuffer:     make([]int32, LUCENE41_MAX_DATA_SIZE),
+		forUtil:        forUtil,
+		bytesWriter:    store.NewRAMOutputStream(),
+	}
+
+	if state.fieldInfos.hasProx {
+		w.posDeltaBuffer = make([]int32, LUCENE41_MAX_DATA_SIZE)
+		if posOut, err = state.directory.CreateOutput(util.SegmentFileName(
+			state.segmentInfo.name, state.segmentSuffix, LUCENE41_POS_EXTENSION), state.context); err != nil {
+			return nil, err
+		}
+		if err = codec.WriteHeader(posOut, LUCENE41_POS_CODEC, LUCENE41_VERSION_CURRENT); err != nil {
+			return nil, err
+		}
+
+		if state.fieldInfos.hasPayloads {
+			w.payloadBytes = make([]byte, 128)
+			w.payloadLengthBuffer = make([]int32, LUCENE41_MAX_DATA_SIZE)
+		}
+		if state.fieldInfos.hasOffsets {
+			w.offsetStartDeltaBuffer = make([]int32, LUCENE41_MAX_DATA_SIZE)
+			w.offsetLengthBuffer = make([]int32, LUCENE41_MAX_DATA_SIZE)
+		}
+
+		if state.fieldInfos.hasPayloads || state.fieldInfos.hasOffsets {
+			if payOut, err = state.directory.CreateOutput(util.SegmentFileName(
+				state.segmentInfo.name, state.segmentSuffix, LUCENE41_PAY_EXTENSION), state.context); err != nil {
+				return nil, err
+			}
+			if err = codec.WriteHeader(payOut, LUCENE41_PAY_CODEC, LUCENE41_VERSION_CURRENT); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.posOut, w.payOut = posOut, payOut
+
+	// TODO: should we try skipping every 2/4 blocks...?
+	w.skipWriter = newLucene41SkipWriter(LUCENE41_MAX_SKIP_LEVELS,
+		LUCENE41_BLOCK_SIZE, int(state.segmentInfo.docCount), docOut, posOut, payOut)
+
+	success = true
+	return w, nil
+}
+
+func (w *Lucene41PostingsWriter) Start(termsOut store.IndexOutput) error {
+	w.termsOut = termsOut
+	if err := codec.WriteHeader(termsOut, LUCENE41_TERMS_CODEC, LUCENE41_VERSION_CURRENT); err != nil {
+		return err
+	}
+	return termsOut.WriteVInt(LUCENE41_BLOCK_SIZE)
+}
+
+func (w *Lucene41PostingsWriter) SetField(fieldInfo FieldInfo) {
+	w.fieldHasFreqs = fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS
+	w.fieldHasPositions = fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS
+	w.fieldHasOffsets = fieldInfo.indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+	w.fieldHasPayloads = fieldInfo.storePayloads
+	w.skipWriter.setField(w.fieldHasPositions, w.fieldHasOffsets, w.fieldHasPayloads)
+}
+
+func (w *Lucene41PostingsWriter) StartTerm() error {
+	w.docTermStartFP = w.docOut.FilePointer()
+	if w.fieldHasPositions {
+		w.posTermStartFP = w.posOut.FilePointer()
+		if w.fieldHasPayloads || w.fieldHasOffsets {
+			w.payTermStartFP = w.payOut.FilePointer()
+		}
+	}
+	w.lastDocID = 0
+	w.lastBlockDocID = -1
+	w.skipWriter.resetSkip()
+	return nil
+}
+
+func (w *Lucene41PostingsWriter) StartDoc(docId, termDocFreq int) error {
+	// Have collected a block of docs, and get a new doc. Should write
+	// skip data as well as postings list for current block.
+	if w.lastBlockDocID != -1 && w.docBufferUpto == 0 {
+		if err := w.skipWriter.bufferSkip(w.lastBlockDocID, w.docCount, w.lastBlockPosFP,
+			w.lastBlockPayFP, w.lastBlockPosBufferUpto, w.lastBlockPayloadByteUpto); err != nil {
+			return err
+		}
+	}
+
+	docDelta := docId - w.lastDocID
+	if docId < 0 || (w.docCount > 0 && docDelta <= 0) {
+		return errors.New(fmt.Sprintf("docs out of order (%v <= %v) (docOut: %v)", docId, w.lastDocID, w.docOut))
+	}
+
+	w.docDeltaBuffer[w.docBufferUpto] = int32(docDelta)
+	if w.fieldHasFreqs {
+		w.freqBuffer[w.docBufferUpto] = int32(termDocFreq)
+	}
+	w.docBufferUpto++
+	w.docCount++
+
+	if w.docBufferUpto == LUCENE41_BLOCK_SIZE {
+		if err := w.forUtil.WriteBlock(w.docDeltaBuffer, w.docOut); err != nil {
+			return err
+		}
+		if w.fieldHasFreqs {
+			if err := w.forUtil.WriteBlock(w.freqBuffer, w.docOut); err != nil {
+				return err
+			}
+		}
+		// NOTE: don't set docBufferUpto back to 0 here; FinishDoc will
+		// do so (because it needs to see that the block was filled so it
+		// can save skip data)
+	}
+
+	w.lastDocID = docId
+	w.lastPosition = 0
+	w.lastStartOffset = 0
+	return nil
+}
+
+// Add a new position & payload
+func (w *Lucene41PostingsWriter) AddPosition(position int, payload []byte, startOffset, endOffset int) error {
+	w.posDeltaBuffer[w.posBufferUpto] = int32(position - w.lastPosition)
+	if w.fieldHasPayloads {
+		w.payloadLengthBuffer[w.posBufferUpto] = int32(len(payload))
+		if len(payload) > 0 {
+			if w.payloadByteUpto+len(payload) > len(w.payloadBytes) {
+				next := make([]byte, 2*(w.payloadByteUpto+len(payload)))
+				copy(next, w.payloadBytes[:w.payloadByteUpto])
+				w.payloadBytes = next
+			}
+			copy(w.payloadBytes[w.payloadByteUpto:], payload)
+			w.payloadByteUpto += len(payload)
+		}
+	}
+
+	if w.fieldHasOffsets {
+		if startOffset < w.lastStartOffset || endOffset < startOffset {
+			return errors.New(fmt.Sprintf("offsets out of order (startOffset=%v, endOffset=%v, lastStartOffset=%v)",
+				startOffset, endOffset, w.lastStartOffset))
+		}
+		w.offsetStartDeltaBuffer[w.posBufferUpto] = int32(startOffset - w.lastStartOffset)
+		w.offsetLengthBuffer[w.posBufferUpto] = int32(endOffset - startOffset)
+		w.lastStartOffset = startOffset
+	}
+
+	w.posBufferUpto++
+	w.lastPosition = position
+	if w.posBufferUpto == LUCENE41_BLOCK_SIZE {
+		if err := w.forUtil.WriteBlock(w.posDeltaBuffer, w.posOut); err != nil {
+			return err
+		}
+
+		if w.fieldHasPayloads {
+			if err := w.forUtil.WriteBlock(w.payloadLengthBuffer, w.payOut); err != nil {
+				return err
+			}
+			if err := w.payOut.WriteVInt(int32(w.payloadByteUpto)); err != nil {
+				return err
+			}
+			if err := w.payOut.WriteBytes(w.payloadBytes[:w.payloadByteUpto]); err != nil {
+				return err
+			}
+			w.payloadByteUpto = 0
+		}
+		if w.fieldHasOffsets {
+			if err := w.forUtil.WriteBlock(w.offsetStartDeltaBuffer, w.payOut); err != nil {
+				return err
+			}
+			if err := w.forUtil.WriteBlock(w.offsetLengthBuffer, w.payOut); err != nil {
+				return err
+			}
+		}
+		w.posBufferUpto = 0
+	}
+	return nil
+}
+
+func (w *Lucene41PostingsWriter) FinishDoc() error {
+	// Since we don't know df for current term, we had to buffer those
+	// skip data for each block, and when a new doc comes, write them
+	// to skip file.
+	if w.docBufferUpto == LUCENE41_BLOCK_SIZE {
+		w.lastBlockDocID = w.lastDocID
+		if w.posOut != nil {
+			if w.payOut != nil {
+				w.lastBlockPayFP = w.payOut.FilePointer()
+			}
+			w.lastBlockPosFP = w.posOut.FilePointer()
+			w.lastBlockPosBufferUpto = w.posBufferUpto
+			w.lastBlockPayloadByteUpto = w.payloadByteUpto
+		}
+		w.docBufferUpto = 0
+	}
+	return nil
+}
+
+// Called when we are done adding docs to this term
+func (w *Lucene41PostingsWriter) FinishTerm(stats TermStats) error {
+	if stats.DocFreq <= 0 || stats.DocFreq != w.docCount {
+		panic("assert fail")
+	}
+
+	// docFreq == 1, don't write the single docid/freq to a separate
+	// file along with a pointer to it.
+	var singletonDocID int
+	if stats.DocFreq == 1 {
+		// pulse the singleton docid into the term dictionary, freq is
+		// implicitly totalTermFreq
+		singletonDocID = int(w.docDeltaBuffer[0])
+	} else {
+		singletonDocID = -1
+		// vInt encode the remaining doc deltas and freqs:
+		for i := 0; i < w.docBufferUpto; i++ {
+			docDelta, freq := w.docDeltaBuffer[i], w.freqBuffer[i]
+			var err error
+			switch {
+			case !w.fieldHasFreqs:
+				err = w.docOut.WriteVInt(docDelta)
+			case freq == 1:
+				err = w.docOut.WriteVInt((docDelta << 1) | 1)
+			default:
+				if err = w.docOut.WriteVInt(docDelta << 1); err == nil {
+					err = w.docOut.WriteVInt(freq)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	lastPosBlockOffset := int64(-1)
+	if w.fieldHasPositions {
+		// totalTermFreq is just total number of positions(or payloads,
+		// or offsets) associated with current term.
+		if stats.TotalTermFreq == -1 {
+			panic("assert fail")
+		}
+		if stats.TotalTermFreq > LUCENE41_BLOCK_SIZE {
+			// record file offset for last pos in last block
+			lastPosBlockOffset = w.posOut.FilePointer() - w.posTermStartFP
+		}
+		if w.posBufferUpto > 0 {
+			if err := w.writeVIntPositions(); err != nil {
+				return err
+			}
+		}
+	}
+
+	skipOffset := int64(-1)
+	if w.docCount > LUCENE41_BLOCK_SIZE {
+		skipPointer, err := w.skipWriter.writeSkip(w.docOut)
+		if err != nil {
+			return err
+		}
+		skipOffset = skipPointer - w.docTermStartFP
+	}
+
+	payStartFP := int64(-1)
+	if stats.TotalTermFreq >= LUCENE41_BLOCK_SIZE {
+		payStartFP = w.payTermStartFP
+	}
+
+	w.pendingTerms = append(w.pendingTerms, lucene41PendingTerm{w.docTermStartFP,
+		w.posTermStartFP, payStartFP, skipOffset, lastPosBlockOffset, singletonDocID})
+	w.docBufferUpto = 0
+	w.posBufferUpto = 0
+	w.lastDocID = 0
+	w.docCount = 0
+	return nil
+}
+
+// vInt encodes the remaining positions, payloads and offsets, which
+// don't fill a block, into the .pos file.
+func (w *Lucene41PostingsWriter) writeVIntPositions() (err error) {
+	// force first payload and offset lengths to be written
+	lastPayloadLength, lastOffsetLength := int32(-1), int32(-1)
+	payloadBytesReadUpto := 0
+	for i := 0; i < w.posBufferUpto && err == nil; i++ {
+		posDelta := w.posDeltaBuffer[i]
+		if w.fieldHasPayloads {
+			payloadLength := w.payloadLengthBuffer[i]
+			if payloadLength != lastPayloadLength {
+				lastPayloadLength = payloadLength
+				if err = w.posOut.WriteVInt((posDelta << 1) | 1); err == nil {
+					err = w.posOut.WriteVInt(payloadLength)
+				}
+			} else {
+				err = w.posOut.WriteVInt(posDelta << 1)
+			}
+			if err == nil && payloadLength != 0 {
+				end := payloadBytesReadUpto + int(payloadLength)
+				err = w.posOut.WriteBytes(w.payloadBytes[payloadBytesReadUpto:end])
+				payloadBytesReadUpto = end
+			}
+		} else {
+			err = w.posOut.WriteVInt(posDelta)
+		}
+
+		if err == nil && w.fieldHasOffsets {
+			delta, length := w.offsetStartDeltaBuffer[i], w.offsetLengthBuffer[i]
+			if length == lastOffsetLength {
+				err = w.posOut.WriteVInt(delta << 1)
+			} else if err = w.posOut.WriteVInt(delta<<1 | 1); err == nil {
+				err = w.posOut.WriteVInt(length)
+				lastOffsetLength = length
+			}
+		}
+	}
+	if w.fieldHasPayloads {
+		if payloadBytesReadUpto != w.payloadByteUpto {
+			panic("assert fail")
+		}
+		w.payloadByteUpto = 0
+	}
+	return err
+}
+
+func (w *Lucene41PostingsWriter) FlushTermsBlock(start, count int) (err error) {
+	if count == 0 {
+		return w.termsOut.WriteByte(0)
+	}
+
+	if start > len(w.pendingTerms) || count > start {
+		panic("assert fail")
+	}
+
+	limit := len(w.pendingTerms) - start + count
+
+	var lastDocStartFP, lastPosStartFP, lastPayStartFP int64
+	for _, term := range w.pendingTerms[limit-count : limit] {
+		if term.singletonDocID == -1 {
+			err = w.bytesWriter.WriteVLong(term.docStartFP - lastDocStartFP)
+			lastDocStartFP = term.docStartFP
+		} else {
+			err = w.bytesWriter.WriteVInt(int32(term.singletonDocID))
+		}
+
+		if err == nil && w.fieldHasPositions {
+			err = w.bytesWriter.WriteVLong(term.posStartFP - lastPosStartFP)
+			lastPosStartFP = term.posStartFP
+			if err == nil && term.lastPosBlockOffset != -1 {
+				err = w.bytesWriter.WriteVLong(term.lastPosBlockOffset)
+			}
+			if err == nil && (w.fieldHasPayloads || w.fieldHasOffsets) && term.payStartFP != -1 {
+				err = w.bytesWriter.WriteVLong(term.payStartFP - lastPayStartFP)
+				lastPayStartFP = term.payStartFP
+			}
+		}
+
+		if err == nil && term.skipOffset != -1 {
+			err = w.bytesWriter.WriteVLong(term.skipOffset)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = w.termsOut.WriteVInt(int32(w.bytesWriter.FilePointer())); err != nil {
+		return err
+	}
+	if err = w.bytesWriter.WriteTo(w.termsOut); err != nil {
+		return err
+	}
+	w.bytesWriter.Reset()
+
+	// Remove the terms we just wrote:
+	w.pendingTerms = append(w.pendingTerms[:limit-count], w.pendingTerms[limit:]...)
+	return nil
+}
+
+func (w *Lucene41PostingsWriter) Close() error {
+	return util.Close(w.docOut, w.posOut, w.payOut)
+}
@@ -0,0 +1,214 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/store"
+)
+
+// Lucene41SkipReader.java
+
+// Expert: maximum number of skip levels written by Lucene41PostingsWriter.
+const LUCENE41_MAX_SKIP_LEVELS = 10
+
+/*
+Implements the skip list reader for block postings format that
+stores positions and payloads.
+
+Although this skipper uses MultiLevelSkipListReader as an interface,
+its definition of skip position will be a little different.
+
+For example, when skipInterval = blockSize = 3, df = 2*skipInterval =
+6,
+
+	0 1 2 3 4 5
+	d d d d d d    (posting list)
+	    ^     ^    (skip point in MultiLeveSkipWriter)
+	      ^        (skip point in Lucene41SkipWriter)
+
+In this case, MultiLevelSkipListReader will use the last document as
+a skip point, while Lucene41SkipReader should assume no skip point
+will comes.
+
+If we use the interface directly in Lucene41SkipReader, it may
+silly try to read another skip data after the only skip point is
+loaded.
+
+To illustrate this, we can call SkipTo(d[5]), since skip point d[3]
+has smaller docId, and numSkipped+blockSize== df, the
+MultiLevelSkipListReader will assume the skip list isn't exhausted
+yet, and try to load a non-existed skip point
+
+Therefore, we'll trim df before passing it to the interface. see
+trim(int)
+*/
+type lucene41SkipReader struct {
+	*MultiLevelSkipListReader
+
+	blockSize int
+
+	docPointer      []int64
+	posPointer      []int64
+	payPointer      []int64
+	posBufferUpto   []int
+	payloadByteUpto []int
+
+	lastPosPointer      int64
+	lastPayPointer      int64
+	lastPayloadByteUpto int
+	lastDocPointer      int64
+	lastPosBufferUpto   int
+}
+
+func newLucene41SkipReader(skipStream store.IndexInput, maxSkipLevels, blockSize int,
+	hasPos, hasOffsets, hasPayloads bool) *lucene41SkipReader {
+	ans := &lucene41SkipReader{
+		blockSize:  blockSize,
+		docPointer: make([]int64, maxSkipLevels),
+	}
+	ans.MultiLevelSkipListReader = newMultiLevelSkipListReader(ans, skipStream, maxSkipLevels, blockSize, 8)
+	if hasPos {
+		ans.posPointer = make([]int64, maxSkipLevels)
+		ans.posBufferUpto = make([]int, maxSkipLevels)
+		if hasPayloads {
+			ans.payloadByteUpto = make([]int, maxSkipLevels)
+		}
+		if hasOffsets || hasPayloads {
+			ans.payPointer = make([]int64, maxSkipLevels)
+		}
+	}
+	return ans
+}
+
+/*
+Trim original docFreq to tell skipReader read proper number of skip
+points.
+
+Since our definition in Lucene41Skip* is a little different from
+MultiLevelSkip* This trimmed docFreq will prevent skipReader from:
+1. silly reading a non-existed skip point after the last block
+boundary
+2. moving into the vInt block
+*/
+func (r *lucene41SkipReader) trim(df int) int {
+	if df%r.blockSize == 0 {
+		return df - 1
+	}
+	return df
+}
+
+func (r *lucene41SkipReader) init(skipPointer, docBasePointer, posBasePointer, payBasePointer int64, df int) {
+	r.MultiLevelSkipListReader.init(skipPointer, r.trim(df))
+	r.lastDocPointer = docBasePointer
+	r.lastPosPointer = posBasePointer
+	r.lastPayPointer = payBasePointer
+
+	for i := range r.docPointer {
+		r.docPointer[i] = docBasePointer
+	}
+	if r.posPointer != nil {
+		for i := range r.posPointer {
+			r.posPointer[i] = posBasePointer
+		}
+		for i := range r.payPointer {
+			r.payPointer[i] = payBasePointer
+		}
+	} else if posBasePointer != 0 {
+		panic("assert fail")
+	}
+}
+
+/*
+Returns the doc pointer of the doc to which the last call of SkipTo()
+has skipped.
+*/
+func (r *lucene41SkipReader) DocPointer() int64 {
+	return r.lastDocPointer
+}
+
+func (r *lucene41SkipReader) PosPointer() int64 {
+	return r.lastPosPointer
+}
+
+func (r *lucene41SkipReader) PosBufferUpto() int {
+	return r.lastPosBufferUpto
+}
+
+func (r *lucene41SkipReader) PayPointer() int64 {
+	return r.lastPayPointer
+}
+
+func (r *lucene41SkipReader) PayloadByteUpto() int {
+	return r.lastPayloadByteUpto
+}
+
+func (r *lucene41SkipReader) NextSkipDoc() int {
+	return r.skipDoc[0]
+}
+
+func (r *lucene41SkipReader) seekChild(level int) error {
+	if err := r.MultiLevelSkipListReader.seekChild(level); err != nil {
+		return err
+	}
+	r.docPointer[level] = r.lastDocPointer
+	if r.posPointer != nil {
+		r.posPointer[level] = r.lastPosPointer
+		r.posBufferUpto[level] = r.lastPosBufferUpto
+		if r.payloadByteUpto != nil {
+			r.payloadByteUpto[level] = r.lastPayloadByteUpto
+		}
+		if r.payPointer != nil {
+			r.payPointer[level] = r.lastPayPointer
+		}
+	}
+	return nil
+}
+
+func (r *lucene41SkipReader) setLastSkipData(level int) {
+	r.lastDoc = r.skipDoc[level]
+	r.lastChildPointer = r.childPointer[level]
+	r.lastDocPointer = r.docPointer[level]
+	if r.posPointer != nil {
+		r.lastPosPointer = r.posPointer[level]
+		r.lastPosBufferUpto = r.posBufferUpto[level]
+		if r.payPointer != nil {
+			r.lastPayPointer = r.payPointer[level]
+		}
+		if r.payloadByteUpto != nil {
+			r.lastPayloadByteUpto = r.payloadByteUpto[level]
+		}
+	}
+}
+
+func (r *lucene41SkipReader) readSkipData(level int, skipStream store.IndexInput) (delta int, err error) {
+	if delta, err = asInt(skipStream.ReadVInt()); err != nil {
+		return 0, err
+	}
+	n, err := skipStream.ReadVInt()
+	if err != nil {
+		return 0, err
+	}
+	r.docPointer[level] += int64(n)
+
+	if r.posPointer != nil {
+		if n, err = skipStream.ReadVInt(); err != nil {
+			return 0, err
+		}
+		r.posPointer[level] += int64(n)
+		if r.posBufferUpto[level], err = asInt(skipStream.ReadVInt()); err != nil {
+			return 0, err
+		}
+
+		if r.payloadByteUpto != nil {
+			if r.payloadByteUpto[level], err = asInt(skipStream.ReadVInt()); err != nil {
+				return 0, err
+			}
+		}
+
+		if r.payPointer != nil {
+			if n, err = skipStream.ReadVInt(); err != nil {
+				return 0, err
+			}
+			r.payPointer[level] += int64(n)
+		}
+	}
+	return delta, nil
+}
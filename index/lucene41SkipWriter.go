@@ -0,0 +1,155 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/store"
+)
+
+// Lucene41SkipWriter.java
+
+/*
+Write skip lists with multiple levels, and support skip within block
+ints.
+
+Assume that docFreq = 28, skipInterval = blockSize = 12
+
+	|       block#0       | |      block#1        | |vInts|
+	d d d d d d d d d d d d d d d d d d d d d d d d d d d d (posting list)
+	                        ^                       ^       (level 0 skip point)
+
+Note that skipWriter will ignore first document in block#0, since it
+is useless as a skip point. Also, we'll never skip into the vInts
+block, only record skip data at the start its start point(if it
+exist).
+
+For each skip point, we will record:
+1. docID in former position, i.e. for position 12, record docID[11],
+etc.
+2. its related file points(position, payload),
+3. related numbers or uptos(position, payload).
+4. start offset.
+*/
+type lucene41SkipWriter struct {
+	*MultiLevelSkipListWriter
+
+	lastSkipDoc        []int
+	lastSkipDocPointer []int64
+	lastSkipPosPointer []int64
+	lastSkipPayPointer []int64
+
+	docOut store.IndexOutput
+	posOut store.IndexOutput
+	payOut store.IndexOutput
+
+	curDoc             int
+	curDocPointer      int64
+	curPosPointer      int64
+	curPayPointer      int64
+	curPosBufferUpto   int
+	curPayloadByteUpto int
+
+	fieldHasPositions bool
+	fieldHasOffsets   bool
+	fieldHasPayloads  bool
+}
+
+func newLucene41SkipWriter(maxSkipLevels, blockSize, docCount int,
+	docOut, posOut, payOut store.IndexOutput) *lucene41SkipWriter {
+	ans := &lucene41SkipWriter{
+		lastSkipDoc:        make([]int, maxSkipLevels),
+		lastSkipDocPointer: make([]int64, maxSkipLevels),
+		docOut:             docOut,
+		posOut:             posOut,
+		payOut:             payOut,
+	}
+	if posOut != nil {
+		ans.lastSkipPosPointer = make([]int64, maxSkipLevels)
+		if payOut != nil {
+			ans.lastSkipPayPointer = make([]int64, maxSkipLevels)
+		}
+	}
+	ans.MultiLevelSkipListWriter = newMultiLevelSkipListWriter(ans, blockSize, 8, maxSkipLevels, docCount)
+	return ans
+}
+
+func (w *lucene41SkipWriter) setField(fieldHasPositions, fieldHasOffsets, fieldHasPayloads bool) {
+	w.fieldHasPositions = fieldHasPositions
+	w.fieldHasOffsets = fieldHasOffsets
+	w.fieldHasPayloads = fieldHasPayloads
+}
+
+func (w *lucene41SkipWriter) resetSkip() {
+	w.MultiLevelSkipListWriter.resetSkip()
+	for i, _ := range w.lastSkipDoc {
+		w.lastSkipDoc[i] = 0
+	}
+	fp := w.docOut.FilePointer()
+	for i, _ := range w.lastSkipDocPointer {
+		w.lastSkipDocPointer[i] = fp
+	}
+	if w.fieldHasPositions {
+		fp = w.posOut.FilePointer()
+		for i, _ := range w.lastSkipPosPointer {
+			w.lastSkipPosPointer[i] = fp
+		}
+		if w.fieldHasOffsets || w.fieldHasPayloads {
+			fp = w.payOut.FilePointer()
+			for i, _ := range w.lastSkipPayPointer {
+				w.lastSkipPayPointer[i] = fp
+			}
+		}
+	}
+}
+
+/*
+Sets the values for the current skip data: doc is the last doc of
+the previous block, numDocs the number of docs written so far, and
+the remaining arguments where the positions and payloads following
+the block start.
+*/
+func (w *lucene41SkipWriter) bufferSkip(doc, numDocs int, posFP, payFP int64,
+	posBufferUpto, payloadByteUpto int) error {
+	w.curDoc = doc
+	w.curDocPointer = w.docOut.FilePointer()
+	w.curPosPointer = posFP
+	w.curPayPointer = payFP
+	w.curPosBufferUpto = posBufferUpto
+	w.curPayloadByteUpto = payloadByteUpto
+	return w.MultiLevelSkipListWriter.bufferSkip(numDocs)
+}
+
+func (w *lucene41SkipWriter) writeSkipData(level int, skipBuffer store.IndexOutput) error {
+	delta := w.curDoc - w.lastSkipDoc[level]
+	if err := skipBuffer.WriteVInt(int32(delta)); err != nil {
+		return err
+	}
+	w.lastSkipDoc[level] = w.curDoc
+
+	if err := skipBuffer.WriteVInt(int32(w.curDocPointer - w.lastSkipDocPointer[level])); err != nil {
+		return err
+	}
+	w.lastSkipDocPointer[level] = w.curDocPointer
+
+	if w.fieldHasPositions {
+		if err := skipBuffer.WriteVInt(int32(w.curPosPointer - w.lastSkipPosPointer[level])); err != nil {
+			return err
+		}
+		w.lastSkipPosPointer[level] = w.curPosPointer
+		if err := skipBuffer.WriteVInt(int32(w.curPosBufferUpto)); err != nil {
+			return err
+		}
+
+		if w.fieldHasPayloads {
+			if err := skipBuffer.WriteVInt(int32(w.curPayloadByteUpto)); err != nil {
+				return err
+			}
+		}
+
+		if w.fieldHasOffsets || w.fieldHasPayloads {
+			if err := skipBuffer.WriteVInt(int32(w.curPayPointer - w.lastSkipPayPointer[level])); err != nil {
+				return err
+			}
+			w.lastSkipPayPointer[level] = w.curPayPointer
+		}
+	}
+	return nil
+}
@@ -0,0 +1,555 @@
+package index
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBlockDocsEnum(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	termsEnum := r.Context().Leaves()[0].reader.Fields().Terms("content").Iterator(nil)
+	reuse := DOCS_ENUM_EMPTY
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if term == nil {
+			break
+		}
+		var docs []int
+		reuse = termsEnum.DocsByFlags(nil, reuse, DOCS_ENUM_FLAG_FREQS)
+		for doc, more := reuse.NextDoc(); more; doc, more = reuse.NextDoc() {
+			if len(docs) > 0 && doc <= docs[len(docs)-1] {
+				t.Fatalf("Docs of '%v' out of order: %v after %v", string(term), doc, docs)
+			}
+			if reuse.Freq() < 1 {
+				t.Errorf("Unexpected freq %v of '%v' in doc %v", reuse.Freq(), string(term), doc)
+			}
+			docs = append(docs, doc)
+		}
+		if reuse.DocId() != NO_MORE_DOCS {
+			t.Errorf("Exhausted enum should be at NO_MORE_DOCS, but was %v", reuse.DocId())
+		}
+		if len(docs) != termsEnum.DocFreq() {
+			t.Fatalf("Expected %v docs of '%v', but was %v", termsEnum.DocFreq(), string(term), len(docs))
+		}
+		for _, doc := range docs {
+			de := termsEnum.DocsByFlags(nil, DOCS_ENUM_EMPTY, 0)
+			if actual, more := de.Advance(doc); !more || actual != doc {
+				t.Errorf("Advance(%v) of '%v' should return itself, but was %v", doc, string(term), actual)
+			}
+		}
+	}
+}
+
+// Reads the positions of the current doc of e.
+func readPositions(t *testing.T, e DocsAndPositionsEnum) []int {
+	positions := make([]int, e.Freq())
+	for i := range positions {
+		positions[i] = e.NextPosition()
+		if i > 0 && positions[i] <= positions[i-1] {
+			t.Fatalf("Positions of doc %v out of order: %v", e.DocId(), positions[:i+1])
+		}
+	}
+	return positions
+}
+
+func TestBlockDocsAndPositionsEnum(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	termsEnum := r.Context().Leaves()[0].reader.Fields().Terms("content").Iterator(nil)
+	var reuse DocsAndPositionsEnum
+	numPositions := 0
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if term == nil {
+			break
+		}
+		docs := termsEnum.DocsByFlags(nil, DOCS_ENUM_EMPTY, DOCS_ENUM_FLAG_FREQS)
+		reuse = termsEnum.DocsAndPositionsByFlags(nil, reuse, 0)
+		all := make(map[int][]int)
+		for doc, more := reuse.NextDoc(); more; doc, more = reuse.NextDoc() {
+			if expected, _ := docs.NextDoc(); doc != expected || reuse.Freq() != docs.Freq() {
+				t.Fatalf("Expected doc %v freq %v of '%v', but doc %v freq %v",
+					expected, docs.Freq(), string(term), doc, reuse.Freq())
+			}
+			all[doc] = readPositions(t, reuse)
+			numPositions += len(all[doc])
+		}
+		if _, more := docs.NextDoc(); more {
+			t.Fatalf("Missing docs of '%v'", string(term))
+		}
+
+		// positions of skipped docs are skipped over
+		de := termsEnum.DocsAndPositionsByFlags(nil, DocsAndPositionsEnum{}, 0)
+		i := 0
+		for doc, more := de.NextDoc(); more; doc, more = de.NextDoc() {
+			if i++; i%2 == 0 {
+				assertEquals(t, fmt.Sprint(all[doc]), fmt.Sprint(readPositions(t, de)))
+			}
+		}
+		for doc, positions := range all {
+			de = termsEnum.DocsAndPositionsByFlags(nil, de, 0)
+			if actual, more := de.Advance(doc); !more || actual != doc {
+				t.Fatalf("Advance(%v) of '%v' should return itself, but was %v", doc, string(term), actual)
+			}
+			assertEquals(t, fmt.Sprint(positions), fmt.Sprint(readPositions(t, de)))
+		}
+	}
+	if numPositions == 0 {
+		t.Error("Expected positions in the content field")
+	}
+}
+
+// A testTokensField whose tokens carry the given payloads.
+type testPayloadsField struct {
+	*testTokensField
+	payloadAtt analysis.PayloadAttribute
+	payloads   [][]byte
+}
+
+func newTestPayloadsField(name string, ft document.IndexableFieldType, payloads [][]byte,
+	tokens ...testToken) *testPayloadsField {
+	ans := &testPayloadsField{testTokensField: newTestTokensField(name, ft, 1, tokens...), payloads: payloads}
+	ans.payloadAtt = ans.Attributes().Add("PayloadAttribute").(analysis.PayloadAttribute)
+	return ans
+}
+
+func (f *testPayloadsField) TokenStream(analyzer analysis.Analyzer) (analysis.TokenStream, error) {
+	return f, nil
+}
+
+func (f *testPayloadsField) IncrementToken() (bool, error) {
+	ok, err := f.testTokensField.IncrementToken()
+	if ok {
+		f.payloadAtt.SetPayload(f.payloads[f.next-1])
+	}
+	return ok, err
+}
+
+func TestEverythingEnum(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	ft := document.NewFieldTypeFrom(document.TEXT_FIELD_TYPE_NOT_STORED)
+	ft.SetIndexOptions(document.INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS)
+	ft.Freeze()
+
+	// every 4th doc has no payloads; the offsets vary per doc, and
+	// enough docs have "a" to fill blocks and write skip data
+	const numDocs = 300
+	payloadOf := func(doc, token int) []byte {
+		if doc%4 == 0 {
+			return nil
+		}
+		return []byte(fmt.Sprintf("%v:%v", doc, token))
+	}
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, 1000)
+	for i := 0; i < numDocs; i++ {
+		base := i % 5
+		if err := w.AddDocument([]document.IndexableField{newTestPayloadsField("text", ft,
+			[][]byte{payloadOf(i, 0), payloadOf(i, 1), payloadOf(i, 2)},
+			testToken{"a", 1, base, base + 1},
+			testToken{"b", 1, base + 2, base + 3},
+			testToken{"a", 1, base + 4, base + 6})}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, len(r.Leaves()))
+	ar := r.Leaves()[0].Reader().(AtomicReader)
+	assertEquals(t, true, ar.FieldInfos().byName["text"].storePayloads)
+	termsEnum := ar.Terms("text").Iterator(nil)
+	if found, err := termsEnum.SeekExact([]byte("a")); err != nil || !found {
+		t.Fatalf("Term should be found (%v)", err)
+	}
+
+	assertPosition := func(de DocsAndPositionsEnum, doc, token int, offsets bool) {
+		base := doc % 5
+		expected := []struct{ position, start, end int }{{0, base, base + 1}, {2, base + 4, base + 6}}[token/2]
+		assertEquals(t, expected.position, de.NextPosition())
+		if offsets {
+			assertEquals(t, expected.start, de.StartOffset())
+			assertEquals(t, expected.end, de.EndOffset())
+		}
+		assertEquals(t, string(payloadOf(doc, token)), string(de.Payload()))
+	}
+
+	flags := DOCS_POSITIONS_ENUM_FLAG_OFF_SETS | DOCS_POSITIONS_ENUM_FLAG_PAYLOADS
+	de := termsEnum.DocsAndPositionsByFlags(nil, DocsAndPositionsEnum{}, flags)
+	for i := 0; i < numDocs; i++ {
+		if doc, more := de.NextDoc(); !more || doc != i {
+			t.Fatalf("Expected doc %v, but was %v", i, doc)
+		}
+		assertEquals(t, 2, de.Freq())
+		assertPosition(de, i, 0, true)
+		assertPosition(de, i, 2, true)
+	}
+	if doc, more := de.NextDoc(); more {
+		t.Errorf("Unexpected doc %v", doc)
+	}
+
+	// positions of skipped docs, and offsets that are not needed, are
+	// skipped over
+	de = termsEnum.DocsAndPositionsByFlags(nil, de, DOCS_POSITIONS_ENUM_FLAG_PAYLOADS)
+	for doc, more := de.NextDoc(); more; doc, more = de.NextDoc() {
+		if doc%3 == 0 {
+			assertPosition(de, doc, 0, false)
+			assertPosition(de, doc, 2, false)
+		}
+	}
+
+	// skips to the block of the target, and its positions
+	for _, target := range []int{130, 250, 299} {
+		de = termsEnum.DocsAndPositionsByFlags(nil, de, flags)
+		if doc, more := de.Advance(target); !more || doc != target {
+			t.Fatalf("Expected doc %v, but was %v", target, doc)
+		}
+		assertPosition(de, target, 0, true)
+		assertPosition(de, target, 2, true)
+	}
+}
+
+// Writes postings of a single term in Lucene41 .doc format, including
+// the multi-level skip data, to mimic Lucene41PostingsWriter.
+type testDocWriter struct {
+	buf []byte
+	// one buffer per skip level
+	skipBuffers [][]byte
+	lastSkipDoc []int
+	lastSkipFP  []int64
+}
+
+func (w *testDocWriter) writeVLong(buf []byte, n int64) []byte {
+	for n >= 0x80 {
+		buf = append(buf, byte(n&0x7f|0x80))
+		n >>= 7
+	}
+	return append(buf, byte(n))
+}
+
+func (w *testDocWriter) writeBlock(values []int) {
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	equal := true
+	for _, v := range values {
+		equal = equal && v == values[0]
+	}
+	if equal {
+		w.buf = append(w.buf, LUCENE41_ALL_VALUES_EQUAL)
+		w.buf = w.writeVLong(w.buf, int64(values[0]))
+		return
+	}
+	numBits := uint(1)
+	for max>>numBits != 0 {
+		numBits++
+	}
+	w.buf = append(w.buf, byte(numBits))
+	// big-endian bit stream
+	var acc uint64
+	var bits uint
+	for _, v := range values {
+		acc = acc<<numBits | uint64(v)
+		for bits += numBits; bits >= 8; bits -= 8 {
+			w.buf = append(w.buf, byte(acc>>(bits-8)))
+		}
+	}
+	if bits > 0 {
+		w.buf = append(w.buf, byte(acc<<(8-bits)))
+	}
+}
+
+func (w *testDocWriter) bufferSkip(numLevels int, doc int) {
+	var childPointer int64
+	for level := 0; level < numLevels; level++ {
+		fp := int64(len(w.buf))
+		b := w.writeVLong(w.skipBuffers[level], int64(doc-w.lastSkipDoc[level]))
+		b = w.writeVLong(b, fp-w.lastSkipFP[level])
+		w.lastSkipDoc[level], w.lastSkipFP[level] = doc, fp
+		newChildPointer := int64(len(b))
+		if level != 0 {
+			b = w.writeVLong(b, childPointer)
+		}
+		childPointer = newChildPointer
+		w.skipBuffers[level] = b
+	}
+}
+
+// Returns the skip offset, relative to the start of the postings.
+func (w *testDocWriter) writePostings(docs []int) int64 {
+	numLevels := 1 + mathLog(len(docs)/LUCENE41_BLOCK_SIZE, 8)
+	w.skipBuffers = make([][]byte, numLevels)
+	w.lastSkipDoc = make([]int, numLevels)
+	w.lastSkipFP = make([]int64, numLevels)
+
+	deltas := make([]int, 0, LUCENE41_BLOCK_SIZE)
+	freqs := make([]int, LUCENE41_BLOCK_SIZE)
+	for i := range freqs {
+		freqs[i] = 1
+	}
+	lastDoc := 0
+	for i, doc := range docs {
+		if i > 0 && i%LUCENE41_BLOCK_SIZE == 0 {
+			levels := 1
+			for n := i / LUCENE41_BLOCK_SIZE; n%8 == 0 && levels < numLevels; n /= 8 {
+				levels++
+			}
+			w.bufferSkip(levels, lastDoc)
+		}
+		deltas = append(deltas, doc-lastDoc)
+		lastDoc = doc
+		if len(deltas) == LUCENE41_BLOCK_SIZE {
+			w.writeBlock(deltas)
+			w.writeBlock(freqs)
+			deltas = deltas[:0]
+		}
+	}
+	for _, delta := range deltas {
+		w.buf = w.writeVLong(w.buf, int64(delta<<1|1))
+	}
+
+	skipOffset := int64(len(w.buf))
+	for level := numLevels - 1; level > 0; level-- {
+		if len(w.skipBuffers[level]) > 0 {
+			w.buf = w.writeVLong(w.buf, int64(len(w.skipBuffers[level])))
+			w.buf = append(w.buf, w.skipBuffers[level]...)
+		}
+	}
+	w.buf = append(w.buf, w.skipBuffers[0]...)
+	return skipOffset
+}
+
+type testBits []bool
+
+func (b testBits) Get(index int) bool { return b[index] }
+func (b testBits) Length() int        { return len(b) }
+
+func TestBlockDocsEnumAdvance(t *testing.T) {
+	random := rand.New(rand.NewSource(42))
+	var docs []int
+	for doc := random.Intn(3); len(docs) < 3000; doc += 1 + random.Intn(4) {
+		docs = append(docs, doc)
+	}
+	w := &testDocWriter{}
+	skipOffset := w.writePostings(docs)
+
+	dir, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "_0.doc"), w.buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docIn, err := d.OpenInput("_0.doc", store.IO_CONTEXT_READ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer docIn.Close()
+
+	header := []byte{util.PACKED_VERSION_CURRENT}
+	for bpv := 1; bpv <= 32; bpv++ {
+		header = append(header, byte(util.PACKED<<5|(bpv-1)))
+	}
+	forUtil, err := NewForUtil(store.NewByteArrayDataInput(header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Lucene41PostingsReader{docIn: docIn, forUtil: forUtil}
+	fieldInfo := FieldInfo{indexOptions: INDEX_OPT_DOCS_AND_FREQS}
+	termState := newIntBlockTermState()
+	termState.docFreq = len(docs)
+	termState.totalTermFreq = int64(len(docs))
+	termState.singletonDocID = -1
+	termState.skipOffset = skipOffset
+
+	liveDocs := make(testBits, docs[len(docs)-1]+1)
+	for i := range liveDocs {
+		liveDocs[i] = random.Intn(5) != 0
+	}
+
+	for _, live := range []util.Bits{nil, liveDocs} {
+		var expected []int
+		for _, doc := range docs {
+			if live == nil || live.Get(doc) {
+				expected = append(expected, doc)
+			}
+		}
+		// returns the first expected doc >= target
+		ceil := func(target int) (int, bool) {
+			for _, doc := range expected {
+				if doc >= target {
+					return doc, true
+				}
+			}
+			return NO_MORE_DOCS, false
+		}
+
+		de, err := r.Docs(fieldInfo, termState.BlockTermState, live, DOCS_ENUM_EMPTY, DOCS_ENUM_FLAG_FREQS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, doc := range expected {
+			if actual, more := de.NextDoc(); !more || actual != doc || de.Freq() != 1 {
+				t.Fatalf("Expected doc %v at %v, but was %v (freq=%v)", doc, i, actual, de.Freq())
+			}
+		}
+		if _, more := de.NextDoc(); more {
+			t.Fatal("Should be exhausted")
+		}
+
+		for round := 0; round < 50; round++ {
+			if de, err = r.Docs(fieldInfo, termState.BlockTermState, live, de, 0); err != nil {
+				t.Fatal(err)
+			}
+			target := 0
+			for {
+				// mix up short and long jumps to exercise all skip levels
+				if random.Intn(3) == 0 {
+					target += random.Intn(2000)
+				} else {
+					target += random.Intn(20)
+				}
+				expectedDoc, expectedMore := ceil(target)
+				var doc int
+				var more bool
+				if random.Intn(4) == 0 && target <= de.DocId()+1 {
+					doc, more = de.NextDoc()
+				} else {
+					doc, more = de.Advance(target)
+				}
+				if doc != expectedDoc || more != expectedMore {
+					t.Fatalf("Advance(%v) expected %v, but was %v", target, expectedDoc, doc)
+				}
+				if !more {
+					break
+				}
+				target = doc + 1
+			}
+			if !de.DocIdSetIterator.(*blockDocsEnum).skipped {
+				t.Fatal("Skipper should have been used")
+			}
+		}
+	}
+}
+
+// Collects stored string fields, loading only the wanted ones if any.
+type testStoredFieldVisitor struct {
+	wanted map[string]bool
+	fields map[string]string
+	stop   string
+}
+
+func (v *testStoredFieldVisitor) binaryField(fi FieldInfo, value []byte) error {
+	v.fields[fi.name] = string(value)
+	return nil
+}
+
+func (v *testStoredFieldVisitor) stringField(fi FieldInfo, value string) error {
+	v.fields[fi.name] = value
+	return nil
+}
+
+func (v *testStoredFieldVisitor) intField(fi FieldInfo, value int) error        { return nil }
+func (v *testStoredFieldVisitor) longField(fi FieldInfo, value int64) error     { return nil }
+func (v *testStoredFieldVisitor) floatField(fi FieldInfo, value float32) error  { return nil }
+func (v *testStoredFieldVisitor) doubleField(fi FieldInfo, value float64) error { return nil }
+
+func (v *testStoredFieldVisitor) needsField(fi FieldInfo) StoredFieldVisitorStatus {
+	if fi.name == v.stop {
+		return SOTRED_FIELD_VISITOR_STATUS_STOP
+	}
+	if v.wanted == nil || v.wanted[fi.name] {
+		return SOTRED_FIELD_VISITOR_STATUS_YES
+	}
+	return SOTRED_FIELD_VISITOR_STATUS_NO
+}
+
+func TestStoredFieldsDocument(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := make(map[string]bool)
+	for docID := 0; docID < r.MaxDoc(); docID++ {
+		v := &testStoredFieldVisitor{fields: make(map[string]string)}
+		if err = r.Document(docID, v); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"modified", "key", "scope", "title", "description"} {
+			if v.fields[name] == "" {
+				t.Errorf("Doc %v should have stored field %v: %v", docID, name, v.fields)
+			}
+		}
+		if v.fields["scope"] != "belfrysample" || !strings.HasPrefix(v.fields["key"], "belfrysample/") {
+			t.Errorf("Unexpected fields of doc %v: %v", docID, v.fields)
+		}
+		titles[v.fields["title"]] = true
+
+		// skip and stop
+		partial := &testStoredFieldVisitor{
+			wanted: map[string]bool{"key": true, "description": true},
+			fields: make(map[string]string),
+			stop:   "description",
+		}
+		if err = r.Document(docID, partial); err != nil {
+			t.Fatal(err)
+		}
+		if len(partial.fields) != 1 || partial.fields["key"] != v.fields["key"] {
+			t.Errorf("Expected only key %v of doc %v, but was %v", v.fields["key"], docID, partial.fields)
+		}
+	}
+	if len(titles) != r.MaxDoc() {
+		t.Errorf("Expected %v distinct titles, but was %v", r.MaxDoc(), titles)
+	}
+	if !titles["Caring for your fruit bat"] || !titles["Bat sonar"] {
+		t.Errorf("Missing titles: %v", titles)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/balzaczyy/golucene/util"
 	"io"
 	"log"
+	"strconv"
 )
 
 const (
@@ -15,9 +16,13 @@ const (
 	LUCENE42_FI_EXTENSION = "fnm"
 
 	// Codec header
-	LUCENE42_FI_CODEC_NAME     = "Lucene42FieldInfos"
-	LUCENE42_FI_FORMAT_START   = 0
-	LUCENE42_FI_FORMAT_CURRENT = LUCENE42_FI_FORMAT_START
+	LUCENE42_FI_CODEC_NAME   = "Lucene42FieldInfos"
+	LUCENE42_FI_FORMAT_START = 0
+	// Records the doc values generation of each field. Only used when
+	// doc values were updated, so other segments stay readable by
+	// Lucene 4.2.
+	LUCENE42_FI_FORMAT_DV_GEN  = 1
+	LUCENE42_FI_FORMAT_CURRENT = LUCENE42_FI_FORMAT_DV_GEN
 
 	// Field flags
 	LUCENE42_FI_IS_INDEXED                   = 0x1
@@ -30,10 +35,11 @@ const (
 )
 
 var (
-	Lucene42FieldInfosReader = func(dir store.Directory, segment string, context store.IOContext) (fi FieldInfos, err error) {
+	Lucene42FieldInfosReader = func(dir store.Directory, segment, segmentSuffix string,
+		context store.IOContext) (fi FieldInfos, err error) {
 		log.Printf("Reading FieldInfos from %v...", dir)
 		fi = FieldInfos{}
-		fileName := util.SegmentFileName(segment, "", LUCENE42_FI_EXTENSION)
+		fileName := util.SegmentFileName(segment, segmentSuffix, LUCENE42_FI_EXTENSION)
 		log.Printf("Segment: %v", fileName)
 		input, err := dir.OpenInput(fileName, context)
 		if err != nil {
@@ -50,7 +56,7 @@ var (
 			}
 		}()
 
-		_, err = codec.CheckHeader(input,
+		format, err := codec.CheckHeader(input,
 			LUCENE42_FI_CODEC_NAME,
 			LUCENE42_FI_FORMAT_START,
 			LUCENE42_FI_FORMAT_CURRENT)
@@ -115,6 +121,11 @@ var (
 			}
 			infos[i] = NewFieldInfo(name, isIndexed, fieldNumber, storeTermVector,
 				omitNorms, storePayloads, indexOptions, docValuesType, normsType, attributes)
+			if format >= LUCENE42_FI_FORMAT_DV_GEN {
+				if infos[i].dvGen, err = input.ReadLong(); err != nil {
+					return fi, err
+				}
+			}
 		}
 
 		if input.FilePointer() != input.Length() {
@@ -128,6 +139,107 @@ var (
 	}
 )
 
+// Lucene42FieldInfosWriter.java
+
+/*
+Writes FieldInfos in the Lucene42 .fnm format. The doc values
+generations are only written if any field has updated doc values.
+*/
+var Lucene42FieldInfosWriter = func(dir store.Directory, segment, segmentSuffix string,
+	infos FieldInfos, ctx store.IOContext) (err error) {
+	fileName := util.SegmentFileName(segment, segmentSuffix, LUCENE42_FI_EXTENSION)
+	output, err := dir.CreateOutput(fileName, ctx)
+	if err != nil {
+		return err
+	}
+
+	success := false
+	defer func() {
+		if success {
+			err = output.Close()
+		} else {
+			util.CloseWhileSuppressingError(output)
+		}
+	}()
+
+	format := LUCENE42_FI_FORMAT_START
+	for _, fi := range infos.values {
+		if fi.dvGen != -1 {
+			format = LUCENE42_FI_FORMAT_DV_GEN
+		}
+	}
+	if err = codec.WriteHeader(output, LUCENE42_FI_CODEC_NAME, int32(format)); err != nil {
+		return err
+	}
+	if err = output.WriteVInt(int32(len(infos.values))); err != nil {
+		return err
+	}
+	for _, fi := range infos.values {
+		var bits byte
+		if fi.omitNorms {
+			bits |= LUCENE42_FI_OMIT_NORMS
+		}
+		if fi.storePayloads {
+			bits |= LUCENE42_FI_STORE_PAYLOADS
+		}
+		if fi.indexed {
+			bits |= LUCENE42_FI_IS_INDEXED
+			switch fi.indexOptions {
+			case INDEX_OPT_DOCS_ONLY:
+				bits |= LUCENE42_FI_OMIT_TERM_FREQ_AND_POSITIONS
+			case INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS:
+				bits |= LUCENE42_FI_STORE_OFFSETS_IN_POSTINGS
+			case INDEX_OPT_DOCS_AND_FREQS:
+				bits |= LUCENE42_FI_OMIT_POSITIONS
+			}
+		}
+		if fi.storeTermVector {
+			bits |= LUCENE42_FI_STORE_TERMVECTOR
+		}
+		if err = output.WriteString(fi.name); err == nil {
+			err = output.WriteVInt(fi.number)
+		}
+		if err == nil {
+			err = output.WriteByte(bits)
+		}
+		if err == nil {
+			// DV Types are packed in one byte
+			dv, nrm := docValuesByte(fi.docValueType), docValuesByte(fi.normType)
+			if dv&^0x0F != 0 || nrm&^0x0F != 0 {
+				panic("assert fail")
+			}
+			err = output.WriteByte(nrm<<4 | dv)
+		}
+		if err == nil {
+			err = output.WriteStringStringMap(fi.attributes)
+		}
+		if err == nil && format >= LUCENE42_FI_FORMAT_DV_GEN {
+			err = output.WriteLong(fi.dvGen)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	success = true
+	return nil
+}
+
+func docValuesByte(t DocValuesType) byte {
+	switch t {
+	case DocValuesType(0):
+		return 0
+	case DOC_VALUES_TYPE_NUMERIC:
+		return 1
+	case DOC_VALUES_TYPE_BINARY:
+		return 2
+	case DOC_VALUES_TYPE_SORTED:
+		return 3
+	case DOC_VALUES_TYPE_SORTED_SET:
+		return 4
+	}
+	panic("assert fail")
+}
+
 func getDocValuesType(input store.IndexInput, b byte) (t DocValuesType, err error) {
 	switch b {
 	case 0:
@@ -147,13 +259,26 @@ func getDocValuesType(input store.IndexInput, b byte) (t DocValuesType, err erro
 }
 
 type Codec struct {
+	Name                      string
 	ReadSegmentInfo           func(d store.Directory, segment string, ctx store.IOContext) (si SegmentInfo, err error)
-	ReadFieldInfos            func(d store.Directory, segment string, ctx store.IOContext) (fi FieldInfos, err error)
+	ReadFieldInfos            func(d store.Directory, segment, suffix string, ctx store.IOContext) (fi FieldInfos, err error)
 	GetFieldsProducer         func(s SegmentReadState) (r FieldsProducer, err error)
 	GetDocValuesProducer      func(s SegmentReadState) (r DocValuesProducer, err error)
 	GetNormsDocValuesProducer func(s SegmentReadState) (r DocValuesProducer, err error)
 	GetStoredFieldsReader     func(d store.Directory, si SegmentInfo, fn FieldInfos, ctx store.IOContext) (r StoredFieldsReader, err error)
 	GetTermVectorsReader      func(d store.Directory, si SegmentInfo, fn FieldInfos, ctx store.IOContext) (r TermVectorsReader, err error)
+	ReadLiveDocs              func(d store.Directory, info SegmentInfoPerCommit, ctx store.IOContext) (r util.Bits, err error)
+
+	WriteSegmentInfo      func(d store.Directory, si *SegmentInfo, fis FieldInfos, ctx store.IOContext) error
+	WriteFieldInfos       func(d store.Directory, segment, suffix string, infos FieldInfos, ctx store.IOContext) error
+	GetFieldsConsumer     func(s SegmentWriteState) (w FieldsConsumer, err error)
+	GetStoredFieldsWriter func(d store.Directory, si *SegmentInfo, ctx store.IOContext) (w StoredFieldsWriter, err error)
+	GetTermVectorsWriter  func(d store.Directory, si *SegmentInfo, ctx store.IOContext) (w TermVectorsWriter, err error)
+	GetDocValuesConsumer  func(s SegmentWriteState) (w DocValuesConsumer, err error)
+
+	GetNormsDocValuesConsumer func(s SegmentWriteState) (w DocValuesConsumer, err error)
+	NewLiveDocs               func(size int, existing util.Bits) util.MutableBits
+	WriteLiveDocs             func(d store.Directory, info SegmentInfoPerCommit, bits util.MutableBits, newDelCount int, ctx store.IOContext) error
 }
 
 func LoadFieldsProducer(name string, state SegmentReadState) (fp FieldsProducer, err error) {
@@ -195,6 +320,30 @@ func LoadFieldsProducer(name string, state SegmentReadState) (fp FieldsProducer,
 	panic(fmt.Sprintf("Service '%v' not found.", name))
 }
 
+func LoadFieldsConsumer(name string, state SegmentWriteState) (fc FieldsConsumer, err error) {
+	switch name {
+	case "Lucene41":
+		postingsWriter, err := newLucene41PostingsWriter(state)
+		if err != nil {
+			return nil, err
+		}
+		success := false
+		defer func() {
+			if !success {
+				util.CloseWhileSuppressingError(postingsWriter)
+			}
+		}()
+
+		fc, err := newBlockTreeTermsWriter(state, postingsWriter)
+		if err != nil {
+			return nil, err
+		}
+		success = true
+		return fc, nil
+	}
+	panic(fmt.Sprintf("Service '%v' not found.", name))
+}
+
 func LoadDocValuesProducer(name string, state SegmentReadState) (fp DocValuesProducer, err error) {
 	switch name {
 	case "Lucene42":
@@ -204,14 +353,38 @@ func LoadDocValuesProducer(name string, state SegmentReadState) (fp DocValuesPro
 	panic(fmt.Sprintf("Service '%v' not found.", name))
 }
 
+func LoadDocValuesConsumer(name string, state SegmentWriteState) (w DocValuesConsumer, err error) {
+	switch name {
+	case "Lucene42":
+		return newLucene42DocValuesConsumer(state, LUCENE42_DV_DATA_CODEC, LUCENE42_DV_DATA_EXTENSION,
+			LUCENE42_DV_METADATA_CODEC, LUCENE42_DV_METADATA_EXTENSION)
+	}
+	panic(fmt.Sprintf("Service '%v' not found.", name))
+}
+
 const (
 	PER_FIELD_FORMAT_KEY = "PerFieldPostingsFormat.format"
 	PER_FIELD_SUFFIX_KEY = "PerFieldPostingsFormat.suffix"
+
+	PER_FIELD_DV_FORMAT_KEY = "PerFieldDocValuesFormat.format"
+	PER_FIELD_DV_SUFFIX_KEY = "PerFieldDocValuesFormat.suffix"
 )
 
+// Returns the Lucene42 codec, writing stored fields with
+// STORED_FIELDS_BEST_SPEED.
 func NewLucene42Codec() Codec {
-	return Codec{ReadSegmentInfo: Lucene40SegmentInfoReader,
-		ReadFieldInfos: Lucene42FieldInfosReader,
+	return NewLucene42CodecWithMode(STORED_FIELDS_BEST_SPEED)
+}
+
+/*
+Returns the Lucene42 codec, writing stored fields with the given
+mode. Segments are readable whatever the mode they were written
+with.
+*/
+func NewLucene42CodecWithMode(storedFieldsMode StoredFieldsMode) Codec {
+	return Codec{Name: "Lucene42",
+		ReadSegmentInfo: Lucene40SegmentInfoReader,
+		ReadFieldInfos:  Lucene42FieldInfosReader,
 		GetFieldsProducer: func(readState SegmentReadState) (fp FieldsProducer, err error) {
 			return newPerFieldPostingsReader(readState)
 		},
@@ -227,7 +400,85 @@ func NewLucene42Codec() Codec {
 		GetTermVectorsReader: func(d store.Directory, si SegmentInfo, fn FieldInfos, ctx store.IOContext) (r TermVectorsReader, err error) {
 			return newLucene42TermVectorsReader(d, si, fn, ctx)
 		},
+		ReadLiveDocs: Lucene40LiveDocsReader,
+
+		WriteSegmentInfo: Lucene40SegmentInfoWriter,
+		WriteFieldInfos:  Lucene42FieldInfosWriter,
+		GetFieldsConsumer: func(writeState SegmentWriteState) (w FieldsConsumer, err error) {
+			return newPerFieldPostingsWriter(writeState), nil
+		},
+		GetStoredFieldsWriter: func(d store.Directory, si *SegmentInfo, ctx store.IOContext) (w StoredFieldsWriter, err error) {
+			return newLucene41StoredFieldsWriter(d, si, ctx, storedFieldsMode)
+		},
+		GetTermVectorsWriter: func(d store.Directory, si *SegmentInfo, ctx store.IOContext) (w TermVectorsWriter, err error) {
+			return lucene42TermVectorsFormat.VectorsWriter(d, si, ctx)
+		},
+		GetDocValuesConsumer: func(s SegmentWriteState) (w DocValuesConsumer, err error) {
+			return newPerFieldDocValuesWriter(s), nil
+		},
+		GetNormsDocValuesConsumer: func(s SegmentWriteState) (w DocValuesConsumer, err error) {
+			return newLucene42DocValuesConsumer(s, "Lucene41NormsData", "nvd", "Lucene41NormsMetadata", "nvm")
+		},
+		NewLiveDocs:   Lucene40NewLiveDocs,
+		WriteLiveDocs: Lucene40LiveDocsWriter,
+	}
+}
+
+// PerFieldPostingsFormat.java/FieldsWriter
+
+/*
+Writes the postings of each field with the postings format of the
+field, recording the format name and suffix as attributes of the
+FieldInfo so that PerFieldPostingsReader can load it back.
+*/
+type PerFieldPostingsWriter struct {
+	segmentWriteState SegmentWriteState
+	formats           map[string]FieldsConsumer // format name -> consumer
+	suffixes          map[string]int            // format name -> suffix
+}
+
+func newPerFieldPostingsWriter(state SegmentWriteState) *PerFieldPostingsWriter {
+	return &PerFieldPostingsWriter{
+		segmentWriteState: state,
+		formats:           make(map[string]FieldsConsumer),
+		suffixes:          make(map[string]int),
+	}
+}
+
+func (w *PerFieldPostingsWriter) AddField(field FieldInfo) (TermsConsumer, error) {
+	// TODO: per-field postings format selection
+	formatName := "Lucene41"
+
+	consumer, ok := w.formats[formatName]
+	if !ok {
+		suffix := len(w.suffixes)
+		w.suffixes[formatName] = suffix
+
+		segmentWriteState := w.segmentWriteState // clone
+		segmentWriteState.segmentSuffix = formatName + "_" + strconv.Itoa(suffix)
+		var err error
+		if consumer, err = LoadFieldsConsumer(formatName, segmentWriteState); err != nil {
+			return nil, err
+		}
+		w.formats[formatName] = consumer
+	}
+
+	// NOTE: Lucene stores the suffix in the field attributes, so
+	// the reader can find the files of each format
+	if field.attributes == nil {
+		panic("assert fail")
+	}
+	field.attributes[PER_FIELD_FORMAT_KEY] = formatName
+	field.attributes[PER_FIELD_SUFFIX_KEY] = strconv.Itoa(w.suffixes[formatName])
+	return consumer.AddField(field)
+}
+
+func (w *PerFieldPostingsWriter) Close() error {
+	consumers := make([]io.Closer, 0, len(w.formats))
+	for _, consumer := range w.formats {
+		consumers = append(consumers, consumer)
 	}
+	return util.Close(consumers...)
 }
 
 type PerFieldPostingsReader struct {
@@ -310,6 +561,106 @@ func (r *PerFieldPostingsReader) Close() error {
 	return util.Close(items...)
 }
 
+// PerFieldDocValuesFormat.java/FieldsWriter
+
+/*
+Writes the doc values of each field with the doc values format of
+the field, recording the format name and suffix as attributes of the
+FieldInfo so that PerFieldDocValuesReader can load it back.
+*/
+type PerFieldDocValuesWriter struct {
+	segmentWriteState SegmentWriteState
+	formats           map[string]DocValuesConsumer // format name -> consumer
+	suffixes          map[string]int               // format name -> suffix
+}
+
+func newPerFieldDocValuesWriter(state SegmentWriteState) *PerFieldDocValuesWriter {
+	return &PerFieldDocValuesWriter{
+		segmentWriteState: state,
+		formats:           make(map[string]DocValuesConsumer),
+		suffixes:          make(map[string]int),
+	}
+}
+
+func (w *PerFieldDocValuesWriter) AddNumericField(field FieldInfo, values []int64) error {
+	consumer, err := w.instance(field)
+	if err != nil {
+		return err
+	}
+	return consumer.AddNumericField(field, values)
+}
+
+func (w *PerFieldDocValuesWriter) AddBinaryField(field FieldInfo, values [][]byte) error {
+	consumer, err := w.instance(field)
+	if err != nil {
+		return err
+	}
+	return consumer.AddBinaryField(field, values)
+}
+
+func (w *PerFieldDocValuesWriter) AddSortedField(field FieldInfo, values [][]byte, docToOrd []int64) error {
+	consumer, err := w.instance(field)
+	if err != nil {
+		return err
+	}
+	return consumer.AddSortedField(field, values, docToOrd)
+}
+
+func (w *PerFieldDocValuesWriter) AddSortedSetField(field FieldInfo, values [][]byte,
+	docToOrdCount, ords []int64) error {
+	consumer, err := w.instance(field)
+	if err != nil {
+		return err
+	}
+	return consumer.AddSortedSetField(field, values, docToOrdCount, ords)
+}
+
+func (w *PerFieldDocValuesWriter) instance(field FieldInfo) (DocValuesConsumer, error) {
+	// TODO: per-field doc values format selection
+	formatName := "Lucene42"
+
+	consumer, ok := w.formats[formatName]
+	if !ok {
+		suffix := len(w.suffixes)
+		w.suffixes[formatName] = suffix
+
+		segmentWriteState := w.segmentWriteState // clone
+		segmentWriteState.segmentSuffix = fullSegmentSuffix(w.segmentWriteState.segmentSuffix,
+			formatName+"_"+strconv.Itoa(suffix))
+		var err error
+		if consumer, err = LoadDocValuesConsumer(formatName, segmentWriteState); err != nil {
+			return nil, err
+		}
+		w.formats[formatName] = consumer
+	}
+
+	if field.attributes == nil {
+		panic("assert fail")
+	}
+	field.attributes[PER_FIELD_DV_FORMAT_KEY] = formatName
+	field.attributes[PER_FIELD_DV_SUFFIX_KEY] = strconv.Itoa(w.suffixes[formatName])
+	return consumer, nil
+}
+
+func (w *PerFieldDocValuesWriter) Close() error {
+	consumers := make([]io.Closer, 0, len(w.formats))
+	for _, consumer := range w.formats {
+		consumers = append(consumers, consumer)
+	}
+	return util.Close(consumers...)
+}
+
+/*
+Returns the suffix of the files of a doc values format, prefixed by
+the outer suffix, e.g. the generation of updated doc values.
+*/
+func fullSegmentSuffix(outerSegmentSuffix, segmentSuffix string) string {
+	if outerSegmentSuffix == "" {
+		return segmentSuffix
+	}
+	return outerSegmentSuffix + "_" + segmentSuffix
+}
+
 type PerFieldDocValuesReader struct {
 	fields  map[string]DocValuesProducer
 	formats map[string]DocValuesProducer
@@ -337,14 +688,14 @@ func newPerFieldDocValuesReader(state SegmentReadState) (dvp DocValuesProducer,
 	for _, fi := range state.fieldInfos.values {
 		if fi.docValueType != 0 {
 			fieldName := fi.name
-			if formatName, ok := fi.attributes[PER_FIELD_FORMAT_KEY]; ok {
+			if formatName, ok := fi.attributes[PER_FIELD_DV_FORMAT_KEY]; ok {
 				// null formatName means the field is in fieldInfos, but has no docvalues!
-				suffix := fi.attributes[PER_FIELD_SUFFIX_KEY]
+				suffix := fi.attributes[PER_FIELD_DV_SUFFIX_KEY]
 				// assert suffix != nil
-				segmentSuffix := formatName + "_" + suffix
+				segmentSuffix := fullSegmentSuffix(state.segmentSuffix, formatName+"_"+suffix)
 				if _, ok := ans.formats[segmentSuffix]; !ok {
 					newReadState := state // clone
-					newReadState.segmentSuffix = formatName + "_" + suffix
+					newReadState.segmentSuffix = segmentSuffix
 					if p, err := LoadDocValuesProducer(formatName, newReadState); err == nil {
 						ans.formats[segmentSuffix] = p
 					}
@@ -401,40 +752,15 @@ type Lucene42TermVectorsReader struct {
 	*CompressingTermVectorsReader
 }
 
+// Lucene42TermVectorsFormat.java
+var lucene42TermVectorsFormat = NewCompressingTermVectorsFormat(
+	"Lucene42TermVectors", "", codec.COMPRESSION_MODE_FAST, 1<<12)
+
 func newLucene42TermVectorsReader(d store.Directory, si SegmentInfo, fn FieldInfos, ctx store.IOContext) (r TermVectorsReader, err error) {
-	formatName := "Lucene41StoredFields"
-	compressionMode := codec.COMPRESSION_MODE_FAST
-	// chunkSize := 1 << 12
-	p, err := newCompressingTermVectorsReader(d, si, "", fn, ctx, formatName, compressionMode)
+	f := lucene42TermVectorsFormat
+	p, err := newCompressingTermVectorsReader(d, si, f.segmentSuffix, fn, ctx, f.formatName, f.compressionMode)
 	if err == nil {
 		r = &Lucene42TermVectorsReader{p}
 	}
-	return r, nil
-}
-
-type CompressingTermVectorsReader struct {
-	vectorsStream store.IndexInput
-	closed        bool
-}
-
-func newCompressingTermVectorsReader(d store.Directory, si SegmentInfo, segmentSuffix string, fn FieldInfos,
-	ctx store.IOContext, formatName string, compressionMode codec.CompressionMode) (r *CompressingTermVectorsReader, err error) {
-	panic("not implemented yet")
-	return nil, nil
-}
-
-func (r *CompressingTermVectorsReader) Close() (err error) {
-	if !r.closed {
-		err = util.Close(r.vectorsStream)
-		r.closed = true
-	}
-	return err
-}
-
-func (r *CompressingTermVectorsReader) get(doc int) Fields {
-	panic("not implemented yet")
-}
-
-func (r *CompressingTermVectorsReader) clone() TermVectorsReader {
-	panic("not implemented yet")
+	return r, err
 }
@@ -2,6 +2,8 @@ package index
 
 import (
 	"github.com/balzaczyy/golucene/store"
+	"io/ioutil"
+	"os"
 	"testing"
 )
 
@@ -16,7 +18,7 @@ func TestReadFieldInfos(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	fis, err := Lucene42FieldInfosReader(cd, "_0", store.IO_CONTEXT_READONCE)
+	fis, err := Lucene42FieldInfosReader(cd, "_0", "", store.IO_CONTEXT_READONCE)
 	if err != nil {
 		t.Error(err)
 	}
@@ -24,3 +26,77 @@ func TestReadFieldInfos(t *testing.T) {
 		t.Errorf("hasNorms must be true and hasDocValues must be false, but found %v", fis)
 	}
 }
+
+func TestWriteFieldInfos(t *testing.T) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infos := NewFieldInfos([]FieldInfo{
+		NewFieldInfo("id", true, 0, false, true, false, INDEX_OPT_DOCS_ONLY, 0, 0,
+			map[string]string{PER_FIELD_FORMAT_KEY: "Lucene41", PER_FIELD_SUFFIX_KEY: "0"}),
+		NewFieldInfo("body", true, 1, true, false, true,
+			INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS, 0, DOC_VALUES_TYPE_NUMERIC,
+			map[string]string{}),
+		NewFieldInfo("title", true, 2, false, false, false,
+			INDEX_OPT_DOCS_AND_FREQS, DOC_VALUES_TYPE_SORTED, DOC_VALUES_TYPE_NUMERIC,
+			map[string]string{}),
+		NewFieldInfo("tags", false, 3, false, false, false, 0, DOC_VALUES_TYPE_SORTED_SET, 0,
+			map[string]string{PER_FIELD_DV_FORMAT_KEY: "Lucene42", PER_FIELD_DV_SUFFIX_KEY: "0"}),
+		NewFieldInfo("stored", false, 4, false, false, false, 0, 0, 0, map[string]string{}),
+	})
+	if err = Lucene42FieldInfosWriter(d, "_0", "", infos, store.IO_CONTEXT_DEFAULT); err != nil {
+		t.Fatal(err)
+	}
+	fis, err := Lucene42FieldInfosReader(d, "_0", "", store.IO_CONTEXT_READONCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, len(infos.values), len(fis.values))
+	for i, fi := range infos.values {
+		assertEquals(t, fi.String(), fis.values[i].String())
+	}
+	if !fis.hasNorms || !fis.hasDocValues || !fis.hasPayloads || !fis.hasOffsets || !fis.hasVectors {
+		t.Errorf("Unexpected flags of %v", fis)
+	}
+}
+
+func TestNormValues(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ar := r.Leaves()[0].Reader().(AtomicReader)
+	norms := make(map[string]NumericDocValues)
+	for _, field := range []string{"key", "title", "content"} {
+		if norms[field], err = ar.NormValues(field); err != nil {
+			t.Fatal(err)
+		}
+		if norms[field] == nil {
+			t.Fatalf("Field %v should have norms", field)
+		}
+	}
+	for docID := 0; docID < r.MaxDoc(); docID++ {
+		// single term field is encoded as 1.0
+		if n := norms["key"].Get(docID); n != 124 {
+			t.Errorf("Expected norm 124 of key for doc %v, but was %v", docID, n)
+		}
+		// longer fields have smaller norms
+		if title, content := norms["title"].Get(docID), norms["content"].Get(docID); title > 124 || content >= title {
+			t.Errorf("Unexpected norms of doc %v: title=%v, content=%v", docID, title, content)
+		}
+	}
+	if v, err := ar.NormValues("unknown"); v != nil || err != nil {
+		t.Errorf("Unknown field should have no norms, but was %v (%v)", v, err)
+	}
+}
@@ -38,13 +38,19 @@ type Lucene42DocValuesProducer struct {
 	data     store.IndexInput
 
 	numericInstances map[int]NumericDocValues
+	binaryInstances  map[int]BinaryDocValues
+	fstInstances     map[int]*util.FST
 
 	maxDoc int
 }
 
 func newLucene42DocValuesProducer(state SegmentReadState,
 	dataCodec, dataExtension, metaCodec, metaExtension string) (dvp *Lucene42DocValuesProducer, err error) {
-	dvp = &Lucene42DocValuesProducer{}
+	dvp = &Lucene42DocValuesProducer{
+		numericInstances: make(map[int]NumericDocValues),
+		binaryInstances:  make(map[int]BinaryDocValues),
+		fstInstances:     make(map[int]*util.FST),
+	}
 	dvp.maxDoc = int(state.segmentInfo.docCount)
 	metaName := util.SegmentFileName(state.segmentInfo.name, state.segmentSuffix, metaExtension)
 	// read in the entries from the metadata file.
@@ -98,19 +104,17 @@ Line 126
 func (dvp *Lucene42DocValuesProducer) readFields(meta store.IndexInput, infos FieldInfos) (err error) {
 	fieldNumber, err := meta.ReadVInt()
 	for fieldNumber != -1 && err == nil {
-		fieldType, err := meta.ReadByte()
-		if err != nil {
+		var fieldType byte
+		if fieldType, err = meta.ReadByte(); err != nil {
 			break
 		}
 		switch fieldType {
 		case LUCENE42_DV_NUMBER:
 			entry := NumericEntry{}
-			entry.offset, err = meta.ReadLong()
-			if err != nil {
+			if entry.offset, err = meta.ReadLong(); err != nil {
 				return err
 			}
-			entry.format, err = meta.ReadByte()
-			if err != nil {
+			if entry.format, err = meta.ReadByte(); err != nil {
 				return err
 			}
 			switch entry.format {
@@ -122,16 +126,43 @@ func (dvp *Lucene42DocValuesProducer) readFields(meta store.IndexInput, infos Fi
 				return errors.New(fmt.Sprintf("Unknown format: %v, input=%v", entry.format, meta))
 			}
 			if entry.format != LUCENE42_DV_UNCOMPRESSED {
-				n, err := meta.ReadVInt()
-				if err != nil {
+				if entry.packedIntsVersion, err = asInt(meta.ReadVInt()); err != nil {
 					return err
 				}
-				entry.packedIntsVersion = int(n)
 			}
+			dvp.numerics[int(fieldNumber)] = entry
 		case LUCENE42_DV_BYTES:
-			panic("not implemented yet")
+			entry := BinaryEntry{}
+			if entry.offset, err = meta.ReadLong(); err != nil {
+				return err
+			}
+			if entry.numBytes, err = meta.ReadLong(); err != nil {
+				return err
+			}
+			if entry.minLength, err = asInt(meta.ReadVInt()); err != nil {
+				return err
+			}
+			if entry.maxLength, err = asInt(meta.ReadVInt()); err != nil {
+				return err
+			}
+			if entry.minLength != entry.maxLength {
+				if entry.packedIntsVersion, err = asInt(meta.ReadVInt()); err != nil {
+					return err
+				}
+				if entry.blockSize, err = asInt(meta.ReadVInt()); err != nil {
+					return err
+				}
+			}
+			dvp.binaries[int(fieldNumber)] = entry
 		case LUCENE42_DV_FST:
-			panic("not implemented yet")
+			entry := FSTEntry{}
+			if entry.offset, err = meta.ReadLong(); err != nil {
+				return err
+			}
+			if entry.numOrds, err = meta.ReadVLong(); err != nil {
+				return err
+			}
+			dvp.fsts[int(fieldNumber)] = entry
 		default:
 			return errors.New(fmt.Sprintf("invalid entry type: %v, input=%v", fieldType, meta))
 		}
@@ -154,23 +185,261 @@ func (dvp *Lucene42DocValuesProducer) Numeric(field FieldInfo) (v NumericDocValu
 }
 
 func (dvp *Lucene42DocValuesProducer) loadNumeric(field FieldInfo) (v NumericDocValues, err error) {
-	panic("not implemented yet")
-	return nil, nil
+	entry, ok := dvp.numerics[int(field.number)]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("no numeric entry for field: %v", field.name))
+	}
+	dvp.data.Seek(entry.offset)
+	switch entry.format {
+	case LUCENE42_DV_TABLE_COMPRESSED:
+		size, err := dvp.data.ReadVInt()
+		if err != nil {
+			return nil, err
+		}
+		if size > 256 {
+			return nil, errors.New(fmt.Sprintf(
+				"TABLE_COMPRESSED cannot have more than 256 distinct values, input=%v", dvp.data))
+		}
+		decode := make([]int64, size)
+		for i, _ := range decode {
+			if decode[i], err = dvp.data.ReadLong(); err != nil {
+				return nil, err
+			}
+		}
+		formatID, err := dvp.data.ReadVInt()
+		if err != nil {
+			return nil, err
+		}
+		bitsPerValue, err := dvp.data.ReadVInt()
+		if err != nil {
+			return nil, err
+		}
+		ordsReader, err := util.NewPackedReaderNoHeader(dvp.data, util.PackedFormat(formatID),
+			int32(entry.packedIntsVersion), int32(dvp.maxDoc), uint32(bitsPerValue))
+		if err != nil {
+			return nil, err
+		}
+		return NumericDocValuesFunc(func(docID int) int64 {
+			return decode[int(ordsReader.Get(int32(docID)))]
+		}), nil
+	case LUCENE42_DV_DELTA_COMPRESSED:
+		blockSize, err := dvp.data.ReadVInt()
+		if err != nil {
+			return nil, err
+		}
+		reader, err := util.NewBlockPackedReader(dvp.data, int32(entry.packedIntsVersion),
+			int(blockSize), int64(dvp.maxDoc))
+		if err != nil {
+			return nil, err
+		}
+		return NumericDocValuesFunc(func(docID int) int64 {
+			return reader.Get(int64(docID))
+		}), nil
+	case LUCENE42_DV_UNCOMPRESSED:
+		bytes := make([]byte, dvp.maxDoc)
+		if err = dvp.data.ReadBytes(bytes); err != nil {
+			return nil, err
+		}
+		return NumericDocValuesFunc(func(docID int) int64 {
+			return int64(int8(bytes[docID]))
+		}), nil
+	case LUCENE42_DV_GCD_COMPRESSED:
+		min, err := dvp.data.ReadLong()
+		if err != nil {
+			return nil, err
+		}
+		mult, err := dvp.data.ReadLong()
+		if err != nil {
+			return nil, err
+		}
+		quotientBlockSize, err := dvp.data.ReadVInt()
+		if err != nil {
+			return nil, err
+		}
+		quotientReader, err := util.NewBlockPackedReader(dvp.data, int32(entry.packedIntsVersion),
+			int(quotientBlockSize), int64(dvp.maxDoc))
+		if err != nil {
+			return nil, err
+		}
+		return NumericDocValuesFunc(func(docID int) int64 {
+			return min + mult*quotientReader.Get(int64(docID))
+		}), nil
+	default:
+		panic("assert fail")
+	}
 }
 
 func (dvp *Lucene42DocValuesProducer) Binary(field FieldInfo) (v BinaryDocValues, err error) {
-	panic("not implemented yet")
-	return nil, nil
+	dvp.lock.Lock()
+	defer dvp.lock.Unlock()
+
+	if v, ok := dvp.binaryInstances[int(field.number)]; ok {
+		return v, nil
+	}
+	if v, err = dvp.loadBinary(field); err == nil {
+		dvp.binaryInstances[int(field.number)] = v
+	}
+	return v, err
+}
+
+func (dvp *Lucene42DocValuesProducer) loadBinary(field FieldInfo) (v BinaryDocValues, err error) {
+	entry, ok := dvp.binaries[int(field.number)]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("no binary entry for field: %v", field.name))
+	}
+	dvp.data.Seek(entry.offset)
+	bytes := make([]byte, entry.numBytes)
+	if err = dvp.data.ReadBytes(bytes); err != nil {
+		return nil, err
+	}
+	if entry.minLength == entry.maxLength {
+		fixedLength := entry.minLength
+		return BinaryDocValuesFunc(func(docID int) []byte {
+			start := fixedLength * docID
+			return bytes[start : start+fixedLength]
+		}), nil
+	}
+	addresses, err := util.NewMonotonicBlockPackedReader(dvp.data, int32(entry.packedIntsVersion),
+		entry.blockSize, int64(dvp.maxDoc))
+	if err != nil {
+		return nil, err
+	}
+	return BinaryDocValuesFunc(func(docID int) []byte {
+		var startAddress int64
+		if docID > 0 {
+			startAddress = addresses.Get(int64(docID - 1))
+		}
+		endAddress := addresses.Get(int64(docID))
+		return bytes[startAddress:endAddress]
+	}), nil
+}
+
+func (dvp *Lucene42DocValuesProducer) loadFST(field FieldInfo, entry FSTEntry) (fst *util.FST, err error) {
+	dvp.lock.Lock()
+	defer dvp.lock.Unlock()
+
+	if fst, ok := dvp.fstInstances[int(field.number)]; ok {
+		return fst, nil
+	}
+	dvp.data.Seek(entry.offset)
+	if fst, err = util.LoadFST(dvp.data, util.PositiveIntOutputsSingleton(true)); err == nil {
+		dvp.fstInstances[int(field.number)] = fst
+	}
+	return fst, err
 }
 
 func (dvp *Lucene42DocValuesProducer) Sorted(field FieldInfo) (v SortedDocValues, err error) {
-	panic("not implemented yet")
-	return nil, nil
+	entry, ok := dvp.fsts[int(field.number)]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("no FST entry for field: %v", field.name))
+	}
+	fst, err := dvp.loadFST(field, entry)
+	if err != nil {
+		return nil, err
+	}
+	docToOrd, err := dvp.Numeric(field)
+	if err != nil {
+		return nil, err
+	}
+	return &lucene42SortedDocValues{docToOrd, fst, entry.numOrds}, nil
 }
 
 func (dvp *Lucene42DocValuesProducer) SortedSet(field FieldInfo) (v SortedSetDocValues, err error) {
-	panic("not implemented yet")
-	return nil, nil
+	entry, ok := dvp.fsts[int(field.number)]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("no FST entry for field: %v", field.name))
+	}
+	if entry.numOrds == 0 {
+		return EMPTY_SORTED_SET_DOC_VALUES, nil // empty FST!
+	}
+	fst, err := dvp.loadFST(field, entry)
+	if err != nil {
+		return nil, err
+	}
+	docToOrds, err := dvp.Binary(field)
+	if err != nil {
+		return nil, err
+	}
+	return &lucene42SortedSetDocValues{
+		docToOrds: docToOrds,
+		fst:       fst,
+		numOrds:   entry.numOrds,
+		input:     store.NewEmptyByteArrayDataInput(),
+	}, nil
+}
+
+// Looks up the term of specified ord in the FST of a sorted field.
+func lookupFSTOrd(fst *util.FST, ord int64) []byte {
+	output, err := util.GetFSTByOutput(fst, ord)
+	if err != nil {
+		panic(err) // bogus
+	}
+	if output == nil {
+		panic(fmt.Sprintf("ord out of bounds: %v", ord))
+	}
+	result := make([]byte, len(output))
+	for i, v := range output {
+		result[i] = byte(v)
+	}
+	return result
+}
+
+type lucene42SortedDocValues struct {
+	docToOrd NumericDocValues
+	fst      *util.FST
+	numOrds  int64
+}
+
+func (v *lucene42SortedDocValues) Get(docID int) []byte {
+	if ord := v.Ord(docID); ord != -1 {
+		return v.LookupOrd(ord)
+	}
+	return nil
+}
+
+func (v *lucene42SortedDocValues) Ord(docID int) int {
+	return int(v.docToOrd.Get(docID))
+}
+
+func (v *lucene42SortedDocValues) LookupOrd(ord int) []byte {
+	return lookupFSTOrd(v.fst, int64(ord))
+}
+
+func (v *lucene42SortedDocValues) ValueCount() int {
+	return int(v.numOrds)
+}
+
+type lucene42SortedSetDocValues struct {
+	docToOrds  BinaryDocValues
+	fst        *util.FST
+	numOrds    int64
+	input      *store.ByteArrayDataInput
+	currentOrd int64
+}
+
+func (v *lucene42SortedSetDocValues) NextOrd() int64 {
+	if v.input.EOF() {
+		return NO_MORE_ORDS
+	}
+	delta, err := v.input.ReadVLong()
+	if err != nil {
+		panic(err) // bogus
+	}
+	v.currentOrd += delta
+	return v.currentOrd
+}
+
+func (v *lucene42SortedSetDocValues) SetDocument(docID int) {
+	v.input.Reset(v.docToOrds.Get(docID))
+	v.currentOrd = 0
+}
+
+func (v *lucene42SortedSetDocValues) LookupOrd(ord int64) []byte {
+	return lookupFSTOrd(v.fst, ord)
+}
+
+func (v *lucene42SortedSetDocValues) ValueCount() int64 {
+	return v.numOrds
 }
 
 func (dvp *Lucene42DocValuesProducer) Close() error {
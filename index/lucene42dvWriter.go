@@ -0,0 +1,364 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+	"sort"
+)
+
+// Lucene42DocValuesConsumer.java
+
+const (
+	LUCENE42_DV_BLOCK_SIZE = 4096
+
+	// Maximum length for each binary doc values field.
+	LUCENE42_DV_MAX_BINARY_FIELD_LENGTH = (1 << 15) - 2
+)
+
+/*
+Writer for the Lucene42 doc values format, which Lucene42DocValuesProducer
+reads: the metadata of every field goes to the metadata file, which
+points to the values in the data file.
+*/
+type Lucene42DocValuesConsumer struct {
+	data, meta store.IndexOutput
+	maxDoc     int
+}
+
+func newLucene42DocValuesConsumer(state SegmentWriteState,
+	dataCodec, dataExtension, metaCodec, metaExtension string) (w *Lucene42DocValuesConsumer, err error) {
+	w = &Lucene42DocValuesConsumer{maxDoc: int(state.segmentInfo.docCount)}
+	success := false
+	defer func() {
+		if !success {
+			util.CloseWhileSuppressingError(w)
+		}
+	}()
+
+	dataName := util.SegmentFileName(state.segmentInfo.name, state.segmentSuffix, dataExtension)
+	if w.data, err = state.directory.CreateOutput(dataName, state.context); err != nil {
+		return nil, err
+	}
+	if err = codec.WriteHeader(w.data, dataCodec, LUCENE42_DV_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	metaName := util.SegmentFileName(state.segmentInfo.name, state.segmentSuffix, metaExtension)
+	if w.meta, err = state.directory.CreateOutput(metaName, state.context); err != nil {
+		return nil, err
+	}
+	if err = codec.WriteHeader(w.meta, metaCodec, LUCENE42_DV_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	success = true
+	return w, nil
+}
+
+func (w *Lucene42DocValuesConsumer) AddNumericField(field FieldInfo, values []int64) error {
+	return w.addNumericField(field, values, true)
+}
+
+func (w *Lucene42DocValuesConsumer) addNumericField(field FieldInfo, values []int64,
+	optimizeStorage bool) (err error) {
+	if len(values) != w.maxDoc {
+		panic("assert fail")
+	}
+	if err = w.meta.WriteVInt(field.number); err == nil {
+		if err = w.meta.WriteByte(LUCENE42_DV_NUMBER); err == nil {
+			err = w.meta.WriteLong(w.data.FilePointer())
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	minValue, maxValue := int64(math.MaxInt64), int64(math.MinInt64)
+	var gcd int64
+	// TODO: more efficient?
+	var uniqueValues map[int64]bool
+	if optimizeStorage {
+		uniqueValues = make(map[int64]bool)
+		for count, v := range values {
+			if gcd != 1 {
+				if v < math.MinInt64/2 || v > math.MaxInt64/2 {
+					// in that case v - minValue might overflow and make the
+					// GCD computation return wrong results. Since these
+					// extreme values are unlikely, we just discard GCD
+					// computation for them
+					gcd = 1
+				} else if count != 0 { // minValue needs to be set first
+					gcd = greatestCommonDivisor(gcd, v-minValue)
+				}
+			}
+			if v < minValue {
+				minValue = v
+			}
+			if v > maxValue {
+				maxValue = v
+			}
+			if uniqueValues != nil {
+				if uniqueValues[v] = true; len(uniqueValues) > 256 {
+					uniqueValues = nil
+				}
+			}
+		}
+	}
+
+	switch {
+	case uniqueValues != nil:
+		// small number of unique values
+		bitsPerValue := util.BitsRequired(int64(len(uniqueValues) - 1))
+		// PackedInts.fastestFormatAndBits() rounds 7 bits per value up
+		// to a byte with the default acceptable overhead ratio
+		if bitsPerValue >= 7 && minValue >= math.MinInt8 && maxValue <= math.MaxInt8 {
+			if err = w.meta.WriteByte(LUCENE42_DV_UNCOMPRESSED); err != nil {
+				return err
+			}
+			for _, v := range values {
+				if err = w.data.WriteByte(byte(v)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if err = w.meta.WriteByte(LUCENE42_DV_TABLE_COMPRESSED); err != nil {
+			return err
+		}
+		decode := make([]int64, 0, len(uniqueValues))
+		for v, _ := range uniqueValues {
+			decode = append(decode, v)
+		}
+		sort.Sort(int64Slice(decode))
+		encode := make(map[int64]int64)
+		if err = w.data.WriteVInt(int32(len(decode))); err != nil {
+			return err
+		}
+		for i, v := range decode {
+			if err = w.data.WriteLong(v); err != nil {
+				return err
+			}
+			encode[v] = int64(i)
+		}
+
+		if err = w.meta.WriteVInt(util.PACKED_VERSION_CURRENT); err == nil {
+			if err = w.data.WriteVInt(util.PACKED); err == nil {
+				err = w.data.WriteVInt(int32(bitsPerValue))
+			}
+		}
+		if err != nil {
+			return err
+		}
+		writer := util.NewPackedWriterNoHeader(w.data, util.PACKED, int32(w.maxDoc), bitsPerValue)
+		for _, v := range values {
+			if err = writer.Add(encode[v]); err != nil {
+				return err
+			}
+		}
+		return writer.Finish()
+
+	case gcd != 0 && gcd != 1:
+		if err = w.meta.WriteByte(LUCENE42_DV_GCD_COMPRESSED); err == nil {
+			if err = w.meta.WriteVInt(util.PACKED_VERSION_CURRENT); err == nil {
+				if err = w.data.WriteLong(minValue); err == nil {
+					if err = w.data.WriteLong(gcd); err == nil {
+						err = w.data.WriteVInt(LUCENE42_DV_BLOCK_SIZE)
+					}
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+		writer := util.NewBlockPackedWriter(w.data, LUCENE42_DV_BLOCK_SIZE)
+		for _, v := range values {
+			if err = writer.Add((v - minValue) / gcd); err != nil {
+				return err
+			}
+		}
+		return writer.Finish()
+
+	default:
+		if err = w.meta.WriteByte(LUCENE42_DV_DELTA_COMPRESSED); err == nil { // delta-compressed
+			if err = w.meta.WriteVInt(util.PACKED_VERSION_CURRENT); err == nil {
+				err = w.data.WriteVInt(LUCENE42_DV_BLOCK_SIZE)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		writer := util.NewBlockPackedWriter(w.data, LUCENE42_DV_BLOCK_SIZE)
+		for _, v := range values {
+			if err = writer.Add(v); err != nil {
+				return err
+			}
+		}
+		return writer.Finish()
+	}
+}
+
+func (w *Lucene42DocValuesConsumer) Close() (err error) {
+	success := false
+	defer func() {
+		if success {
+			err = util.Close(w.data, w.meta)
+		} else {
+			util.CloseWhileSuppressingError(w.data, w.meta)
+		}
+		w.data, w.meta = nil, nil
+	}()
+	if w.meta != nil {
+		if err = w.meta.WriteVInt(-1); err != nil { // write EOF marker
+			return err
+		}
+	}
+	success = true
+	return nil
+}
+
+func (w *Lucene42DocValuesConsumer) AddBinaryField(field FieldInfo, values [][]byte) (err error) {
+	// write the []byte data
+	if err = w.meta.WriteVInt(field.number); err == nil {
+		err = w.meta.WriteByte(LUCENE42_DV_BYTES)
+	}
+	if err != nil {
+		return err
+	}
+	minLength, maxLength := math.MaxInt32, math.MinInt32
+	startFP := w.data.FilePointer()
+	for _, v := range values {
+		if len(v) > LUCENE42_DV_MAX_BINARY_FIELD_LENGTH {
+			return errors.New(fmt.Sprintf(
+				"DocValuesField \"%v\" is too large, must be <= %v",
+				field.name, LUCENE42_DV_MAX_BINARY_FIELD_LENGTH))
+		}
+		if len(v) < minLength {
+			minLength = len(v)
+		}
+		if len(v) > maxLength {
+			maxLength = len(v)
+		}
+		if err = w.data.WriteBytes(v); err != nil {
+			return err
+		}
+	}
+	if err = w.meta.WriteLong(startFP); err == nil {
+		if err = w.meta.WriteLong(w.data.FilePointer() - startFP); err == nil {
+			if err = w.meta.WriteVInt(int32(minLength)); err == nil {
+				err = w.meta.WriteVInt(int32(maxLength))
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// if minLength == maxLength, its a fixed-length []byte, we are done
+	// (the addresses are implicit) otherwise, we need to record the
+	// length fields...
+	if minLength != maxLength {
+		if err = w.meta.WriteVInt(util.PACKED_VERSION_CURRENT); err == nil {
+			err = w.meta.WriteVInt(LUCENE42_DV_BLOCK_SIZE)
+		}
+		if err != nil {
+			return err
+		}
+
+		writer := util.NewMonotonicBlockPackedWriter(w.data, LUCENE42_DV_BLOCK_SIZE)
+		var addr int64
+		for _, v := range values {
+			addr += int64(len(v))
+			if err = writer.Add(addr); err != nil {
+				return err
+			}
+		}
+		return writer.Finish()
+	}
+	return nil
+}
+
+func (w *Lucene42DocValuesConsumer) writeFST(field FieldInfo, values [][]byte) (err error) {
+	if err = w.meta.WriteVInt(field.number); err == nil {
+		if err = w.meta.WriteByte(LUCENE42_DV_FST); err == nil {
+			err = w.meta.WriteLong(w.data.FilePointer())
+		}
+	}
+	if err != nil {
+		return err
+	}
+	builder := util.NewFSTBuilder(util.INPUT_TYPE_BYTE1, util.PositiveIntOutputsSingleton(true))
+	for ord, v := range values {
+		builder.Add(util.ToIntsRef(v), int64(ord))
+	}
+	if fst := builder.Finish(); fst != nil {
+		if err = fst.Save(w.data); err != nil {
+			return err
+		}
+	}
+	return w.meta.WriteVLong(int64(len(values)))
+}
+
+func (w *Lucene42DocValuesConsumer) AddSortedField(field FieldInfo,
+	values [][]byte, docToOrd []int64) error {
+	// write the ordinals as numerics
+	if err := w.addNumericField(field, docToOrd, false); err != nil {
+		return err
+	}
+	// write the values as FST
+	return w.writeFST(field, values)
+}
+
+/*
+Writes the ordinals of each document as a binary value of vLong
+deltas, which lucene42SortedSetDocValues decodes.
+*/
+func (w *Lucene42DocValuesConsumer) AddSortedSetField(field FieldInfo,
+	values [][]byte, docToOrdCount, ords []int64) error {
+	// write the ordinals as a binary field
+	docToOrds := make([][]byte, len(docToOrdCount))
+	out := store.NewRAMOutputStream()
+	for docID, count := range docToOrdCount {
+		out.Reset()
+		var lastOrd int64
+		for _, ord := range ords[:count] {
+			if err := out.WriteVLong(ord - lastOrd); err != nil {
+				return err
+			}
+			lastOrd = ord
+		}
+		ords = ords[count:]
+		docToOrds[docID] = make([]byte, out.FilePointer())
+		out.WriteToBytes(docToOrds[docID])
+	}
+	if err := w.AddBinaryField(field, docToOrds); err != nil {
+		return err
+	}
+
+	// write the values as FST
+	return w.writeFST(field, values)
+}
+
+// util/MathUtil.java
+
+// Returns the greatest common divisor of a and b.
+func greatestCommonDivisor(a, b int64) int64 {
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+type int64Slice []int64
+
+func (s int64Slice) Len() int           { return len(s) }
+func (s int64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s int64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,381 @@
+package index
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Writes doc values in the Lucene42 format, to mimic
+// Lucene42DocValuesConsumer. Numerics are always written with the
+// format requested by the test.
+type testDVWriter struct {
+	testTVWriter
+	meta, data []byte
+}
+
+func (w *testDVWriter) writeVInt32(buf []byte, n int32) []byte {
+	u := uint32(n)
+	for u >= 0x80 {
+		buf = append(buf, byte(u&0x7f|0x80))
+		u >>= 7
+	}
+	return append(buf, byte(u))
+}
+
+func (w *testDVWriter) writeVLong(buf []byte, n int64) []byte {
+	for n >= 0x80 {
+		buf = append(buf, byte(n&0x7f|0x80))
+		n >>= 7
+	}
+	return append(buf, byte(n))
+}
+
+func (w *testDVWriter) writeInt(buf []byte, n uint32) []byte {
+	return append(buf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+func (w *testDVWriter) writeLong(buf []byte, n int64) []byte {
+	buf = w.writeInt(buf, uint32(uint64(n)>>32))
+	return w.writeInt(buf, uint32(n))
+}
+
+func (w *testDVWriter) writeHeader(buf []byte, codecName string, version uint32) []byte {
+	buf = w.writeInt(buf, uint32(codec.CODEC_MAGIC))
+	buf = w.writeVInt(buf, len(codecName))
+	buf = append(buf, codecName...)
+	return w.writeInt(buf, version)
+}
+
+// Appends values as MonotonicBlockPackedWriter does.
+func (w *testDVWriter) writeMonotonic(buf []byte, values []int64) []byte {
+	for len(values) > 0 {
+		block := values
+		if len(block) > COMPRESSING_TV_BLOCK_SIZE {
+			block = block[:COMPRESSING_TV_BLOCK_SIZE]
+		}
+		values = values[len(block):]
+
+		min := block[0]
+		var avg float32
+		if len(block) > 1 {
+			avg = float32(block[len(block)-1]-min) / float32(len(block)-1)
+		}
+		deltas := make([]int64, len(block))
+		var maxZigZagDelta int64
+		for i, v := range block {
+			delta := v - min - int64(avg*float32(i))
+			deltas[i] = (delta << 1) ^ (delta >> 63)
+			if deltas[i] > maxZigZagDelta {
+				maxZigZagDelta = deltas[i]
+			}
+		}
+		buf = w.writeVLong(buf, min)
+		buf = w.writeInt(buf, math.Float32bits(avg))
+		if maxZigZagDelta == 0 {
+			buf = w.writeVInt(buf, 0)
+		} else {
+			bpv := util.BitsRequired(maxZigZagDelta)
+			buf = w.writeVInt(buf, int(bpv))
+			buf = w.writePacked(buf, deltas, bpv)
+		}
+	}
+	return buf
+}
+
+func (w *testDVWriter) addNumericEntry(number int, format byte, data []byte) {
+	w.meta = w.writeVInt(w.meta, number)
+	w.meta = append(w.meta, LUCENE42_DV_NUMBER)
+	w.meta = w.writeLong(w.meta, int64(len(w.data)))
+	w.meta = append(w.meta, format)
+	if format != LUCENE42_DV_UNCOMPRESSED {
+		w.meta = w.writeVInt(w.meta, util.PACKED_VERSION_CURRENT)
+	}
+	w.data = append(w.data, data...)
+}
+
+func (w *testDVWriter) addDeltaField(number int, values []int64) {
+	data := w.writeVInt(nil, COMPRESSING_TV_BLOCK_SIZE)
+	w.addNumericEntry(number, LUCENE42_DV_DELTA_COMPRESSED, w.writeBlockPacked(data, values))
+}
+
+func (w *testDVWriter) addBinaryField(number int, values [][]byte) {
+	w.meta = w.writeVInt(w.meta, number)
+	w.meta = append(w.meta, LUCENE42_DV_BYTES)
+	w.meta = w.writeLong(w.meta, int64(len(w.data)))
+	minLength, maxLength := len(values[0]), len(values[0])
+	var numBytes int64
+	addresses := make([]int64, len(values))
+	for i, v := range values {
+		w.data = append(w.data, v...)
+		numBytes += int64(len(v))
+		addresses[i] = numBytes
+		if len(v) < minLength {
+			minLength = len(v)
+		}
+		if len(v) > maxLength {
+			maxLength = len(v)
+		}
+	}
+	w.meta = w.writeLong(w.meta, numBytes)
+	w.meta = w.writeVInt(w.meta, minLength)
+	w.meta = w.writeVInt(w.meta, maxLength)
+	if minLength != maxLength {
+		w.meta = w.writeVInt(w.meta, util.PACKED_VERSION_CURRENT)
+		w.meta = w.writeVInt(w.meta, COMPRESSING_TV_BLOCK_SIZE)
+		w.data = w.writeMonotonic(w.data, addresses)
+	}
+}
+
+// Adds an FST which maps "ab", "ac" and "b" to ords 0, 1 and 2.
+func (w *testDVWriter) addFST(number int, numOrds int64) {
+	w.meta = w.writeVInt(w.meta, number)
+	w.meta = append(w.meta, LUCENE42_DV_FST)
+	w.meta = w.writeLong(w.meta, int64(len(w.data)))
+	w.meta = w.writeVLong(w.meta, numOrds)
+
+	final, last, stop, hasOutput := util.FST_BIT_FINAL_ARC, util.FST_BIT_LAST_ARC,
+		util.FST_BIT_STOP_NODE, util.FST_BIT_ARC_HAS_OUTPUT
+	fstBytes := []byte{
+		0,
+		// node 5, reversed: 'b' -> final, 'c' (+1) -> final
+		1, 'c', final | last | stop | hasOutput, 'b', final | stop,
+		// root node 11, reversed: 'a' -> node 5, 'b' (+2) -> final
+		2, 'b', final | last | stop | hasOutput, 5, 'a', 0,
+	}
+	w.data = w.writeHeader(w.data, util.FST_FILE_FORMAT_NAME, util.FST_VERSION_VINT_TARGET)
+	w.data = append(w.data, 0, 0, 0) // not packed, no empty output, BYTE1
+	for _, n := range []int64{11, 2, 4, 2, int64(len(fstBytes))} {
+		w.data = w.writeVLong(w.data, n)
+	}
+	w.data = append(w.data, fstBytes...)
+}
+
+func (w *testDVWriter) finish(dir string) error {
+	w.meta = w.writeVInt32(w.meta, -1)
+	if err := ioutil.WriteFile(filepath.Join(dir, "_0.dvm"), w.meta, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "_0.dvd"), w.data, 0644)
+}
+
+func TestLucene42DocValuesProducer(t *testing.T) {
+	const maxDoc = 150
+	const (
+		delta = iota
+		table
+		gcd
+		uncompressed
+		fixed
+		variable
+		sorted
+		sortedSet
+		emptySet
+	)
+	terms := []string{"ab", "ac", "b"}
+	docOrds := func(docID int) []int64 {
+		return [][]int64{nil, {0}, {0, 2}, {1, 2}}[docID%4]
+	}
+
+	w := &testDVWriter{}
+	w.meta = w.writeHeader(nil, LUCENE42_DV_METADATA_CODEC, LUCENE42_DV_VERSION_CURRENT)
+	w.data = w.writeHeader(nil, LUCENE42_DV_DATA_CODEC, LUCENE42_DV_VERSION_CURRENT)
+
+	values := make([]int64, maxDoc)
+	for i := range values {
+		values[i] = int64(i*i) - 1000
+	}
+	w.addDeltaField(delta, values)
+
+	decode := []int64{-5, 7, 1 << 40}
+	data := w.writeVInt(nil, len(decode))
+	for _, v := range decode {
+		data = w.writeLong(data, v)
+	}
+	data = w.writeVInt(data, util.PACKED)
+	data = w.writeVInt(data, 2)
+	for i := range values {
+		values[i] = int64(i % len(decode))
+	}
+	w.addNumericEntry(table, LUCENE42_DV_TABLE_COMPRESSED, w.writePacked(data, values, 2))
+
+	data = w.writeLong(nil, -30)
+	data = w.writeLong(data, 3)
+	data = w.writeVInt(data, COMPRESSING_TV_BLOCK_SIZE)
+	for i := range values {
+		values[i] = int64(i % 7)
+	}
+	w.addNumericEntry(gcd, LUCENE42_DV_GCD_COMPRESSED, w.writeBlockPacked(data, values))
+
+	data = make([]byte, maxDoc)
+	for i := range data {
+		data[i] = byte(int8(i - 75))
+	}
+	w.addNumericEntry(uncompressed, LUCENE42_DV_UNCOMPRESSED, data)
+
+	binaries := make([][]byte, maxDoc)
+	for i := range binaries {
+		binaries[i] = []byte{byte(i), byte(i >> 8), 'x'}
+	}
+	w.addBinaryField(fixed, binaries)
+	for i := range binaries {
+		binaries[i] = bytes.Repeat([]byte{byte(i)}, i%5)
+	}
+	w.addBinaryField(variable, binaries)
+
+	w.addFST(sorted, int64(len(terms)))
+	for i := range values {
+		values[i] = int64(i % len(terms))
+	}
+	w.addDeltaField(sorted, values)
+
+	w.addFST(sortedSet, int64(len(terms)))
+	for i := range binaries {
+		binaries[i] = nil
+		var prev int64
+		for _, ord := range docOrds(i) {
+			binaries[i] = w.writeVLong(binaries[i], ord-prev)
+			prev = ord
+		}
+	}
+	w.addBinaryField(sortedSet, binaries)
+
+	w.meta = w.writeVInt(w.meta, emptySet)
+	w.meta = append(w.meta, LUCENE42_DV_FST)
+	w.meta = w.writeLong(w.meta, 0)
+	w.meta = w.writeVLong(w.meta, 0)
+
+	dir, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = w.finish(dir); err != nil {
+		t.Fatal(err)
+	}
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fieldInfos := NewFieldInfos([]FieldInfo{
+		NewFieldInfo("delta", false, delta, false, true, false, 0, DOC_VALUES_TYPE_NUMERIC, 0, nil),
+		NewFieldInfo("table", false, table, false, true, false, 0, DOC_VALUES_TYPE_NUMERIC, 0, nil),
+		NewFieldInfo("gcd", false, gcd, false, true, false, 0, DOC_VALUES_TYPE_NUMERIC, 0, nil),
+		NewFieldInfo("uncompressed", false, uncompressed, false, true, false, 0, DOC_VALUES_TYPE_NUMERIC, 0, nil),
+		NewFieldInfo("fixed", false, fixed, false, true, false, 0, DOC_VALUES_TYPE_BINARY, 0, nil),
+		NewFieldInfo("variable", false, variable, false, true, false, 0, DOC_VALUES_TYPE_BINARY, 0, nil),
+		NewFieldInfo("sorted", false, sorted, false, true, false, 0, DOC_VALUES_TYPE_SORTED, 0, nil),
+		NewFieldInfo("sortedSet", false, sortedSet, false, true, false, 0, DOC_VALUES_TYPE_SORTED_SET, 0, nil),
+		NewFieldInfo("emptySet", false, emptySet, false, true, false, 0, DOC_VALUES_TYPE_SORTED_SET, 0, nil),
+	})
+	si := SegmentInfo{name: "_0", docCount: maxDoc}
+	state := newSegmentReadState(d, si, fieldInfos, store.IO_CONTEXT_READ, -1)
+	dvp, err := newLucene42DocValuesProducer(state, LUCENE42_DV_DATA_CODEC, LUCENE42_DV_DATA_EXTENSION,
+		LUCENE42_DV_METADATA_CODEC, LUCENE42_DV_METADATA_EXTENSION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dvp.Close()
+	core := &SegmentCoreReaders{fieldInfos: fieldInfos, dvProducer: dvp}
+
+	numerics := map[string]func(docID int) int64{
+		"delta":        func(docID int) int64 { return int64(docID*docID) - 1000 },
+		"table":        func(docID int) int64 { return decode[docID%len(decode)] },
+		"gcd":          func(docID int) int64 { return -30 + 3*int64(docID%7) },
+		"uncompressed": func(docID int) int64 { return int64(docID - 75) },
+	}
+	for name, expected := range numerics {
+		v, err := core.NumericDocValues(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for docID := 0; docID < maxDoc; docID++ {
+			if n := v.Get(docID); n != expected(docID) {
+				t.Fatalf("Expected %v for doc %v of field %v, but was %v", expected(docID), docID, name, n)
+			}
+		}
+	}
+
+	v, err := core.BinaryDocValues("fixed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for docID := 0; docID < maxDoc; docID++ {
+		if b := v.Get(docID); !bytes.Equal(b, []byte{byte(docID), byte(docID >> 8), 'x'}) {
+			t.Fatalf("Unexpected value for doc %v: %v", docID, b)
+		}
+	}
+	if v, err = core.BinaryDocValues("variable"); err != nil {
+		t.Fatal(err)
+	}
+	for docID := 0; docID < maxDoc; docID++ {
+		if b := v.Get(docID); !bytes.Equal(b, bytes.Repeat([]byte{byte(docID)}, docID%5)) {
+			t.Fatalf("Unexpected value for doc %v: %v", docID, b)
+		}
+	}
+
+	sv, err := core.SortedDocValues("sorted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sv.ValueCount() != len(terms) {
+		t.Errorf("Expected %v values, but was %v", len(terms), sv.ValueCount())
+	}
+	for ord, term := range terms {
+		if b := sv.LookupOrd(ord); string(b) != term {
+			t.Errorf("Expected '%v' for ord %v, but was '%v'", term, ord, string(b))
+		}
+	}
+	for docID := 0; docID < maxDoc; docID++ {
+		if ord := sv.Ord(docID); ord != docID%len(terms) {
+			t.Fatalf("Expected ord %v for doc %v, but was %v", docID%len(terms), docID, ord)
+		}
+		if b := sv.Get(docID); string(b) != terms[docID%len(terms)] {
+			t.Fatalf("Unexpected value for doc %v: %v", docID, string(b))
+		}
+	}
+
+	ssv, err := core.SortedSetDocValues("sortedSet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ssv.ValueCount() != int64(len(terms)) {
+		t.Errorf("Expected %v values, but was %v", len(terms), ssv.ValueCount())
+	}
+	for docID := 0; docID < maxDoc; docID++ {
+		ssv.SetDocument(docID)
+		for _, expected := range docOrds(docID) {
+			if ord := ssv.NextOrd(); ord != expected {
+				t.Fatalf("Expected ord %v for doc %v, but was %v", expected, docID, ord)
+			}
+		}
+		if ord := ssv.NextOrd(); ord != NO_MORE_ORDS {
+			t.Fatalf("Expected no more ords for doc %v, but was %v", docID, ord)
+		}
+	}
+	if b := ssv.LookupOrd(1); string(b) != "ac" {
+		t.Errorf("Expected 'ac' for ord 1, but was '%v'", string(b))
+	}
+
+	if ssv, err = core.SortedSetDocValues("emptySet"); err != nil {
+		t.Fatal(err)
+	}
+	ssv.SetDocument(0)
+	if ssv.ValueCount() != 0 || ssv.NextOrd() != NO_MORE_ORDS {
+		t.Error("Empty set should have no ords")
+	}
+
+	// type mismatch and unknown field
+	if v, err := core.NumericDocValues("fixed"); v != nil || err != nil {
+		t.Errorf("Expected no numeric values for binary field, but was %v (%v)", v, err)
+	}
+	if v, err := core.BinaryDocValues("unknown"); v != nil || err != nil {
+		t.Errorf("Expected no values for unknown field, but was %v (%v)", v, err)
+	}
+}
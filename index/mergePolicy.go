@@ -0,0 +1,249 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// MergeTrigger.java
+
+// Indicates the event that triggered a merge.
+type MergeTrigger int
+
+const (
+	// Merge was triggered by a segment flush.
+	MERGE_TRIGGER_SEGMENT_FLUSH = MergeTrigger(1)
+	// Merge was triggered by a full flush. Full flushes can be caused
+	// by a commit or close.
+	MERGE_TRIGGER_FULL_FLUSH = MergeTrigger(2)
+	// Merge has been triggered explicitly by the user.
+	MERGE_TRIGGER_EXPLICIT = MergeTrigger(3)
+	// Merge was triggered by a successfully finished merge.
+	MERGE_TRIGGER_MERGE_FINISHED = MergeTrigger(4)
+)
+
+func (t MergeTrigger) String() string {
+	switch t {
+	case MERGE_TRIGGER_SEGMENT_FLUSH:
+		return "SEGMENT_FLUSH"
+	case MERGE_TRIGGER_FULL_FLUSH:
+		return "FULL_FLUSH"
+	case MERGE_TRIGGER_EXPLICIT:
+		return "EXPLICIT"
+	case MERGE_TRIGGER_MERGE_FINISHED:
+		return "MERGE_FINISHED"
+	}
+	panic("assert fail")
+}
+
+// MergePolicy.java
+
+/*
+Expert: a MergePolicy determines the sequence of primitive merge
+operations.
+
+Whenever the segments in an index have been altered by IndexWriter,
+either the addition of a newly flushed segment, addition of many
+segments from AddIndexes* calls, or a previous merge that may now
+need to cascade, IndexWriter invokes FindMerges() to give the
+MergePolicy a chance to pick merges that are now required. This
+method returns a MergeSpecification instance describing the set of
+merges that should be done, or nil if no merges are necessary. When
+IndexWriter.ForceMerge() is called, it calls FindForcedMerges() and
+the MergePolicy should then return the necessary merges.
+
+Note that the policy can return more than one merge at a time. In
+this case, if the writer is using a serial merge scheduler, the
+merges will be run sequentially.
+
+The default MergePolicy is LogByteSizeMergePolicy.
+
+NOTE: a MergePolicy instance is bound to the IndexWriter it is set
+to, and must not be shared between IndexWriters.
+*/
+type MergePolicy interface {
+	// Sets the IndexWriter to use by this merge policy. Called once by
+	// the IndexWriter when it is created.
+	SetIndexWriter(writer *IndexWriter)
+	/*
+		Determine what set of merge operations are now necessary on the
+		index. IndexWriter calls this whenever there is a change to the
+		segments. This call is always synchronized on the IndexWriter
+		instance so only one goroutine at a time will call this method.
+	*/
+	FindMerges(trigger MergeTrigger, infos *SegmentInfos) (spec *MergeSpecification, err error)
+	/*
+		Determine what set of merge operations is necessary in order to
+		merge to <= the specified segment count. IndexWriter calls this
+		when its ForceMerge() method is called. This call is always
+		synchronized on the IndexWriter instance so only one goroutine
+		at a time will call this method.
+
+		segmentsToMerge holds the names of the segments to be merged;
+		the value is true if the segment is an original segment (not
+		one that was flushed or merged since ForceMerge was called).
+	*/
+	FindForcedMerges(infos *SegmentInfos, maxSegmentCount int,
+		segmentsToMerge map[string]bool) (spec *MergeSpecification, err error)
+	// Determine what set of merge operations is necessary in order to
+	// expunge all deletes from the index.
+	FindForcedDeletesMerges(infos *SegmentInfos) (spec *MergeSpecification, err error)
+	// Returns true if a new segment (regardless of its origin) should
+	// use the compound file format.
+	UseCompoundFile(infos *SegmentInfos, mergedInfo *SegmentInfoPerCommit) (bool, error)
+}
+
+// MergePolicy.java/OneMerge
+
+/*
+OneMerge provides the information necessary to perform an individual
+primitive merge operation, resulting in a single new segment. The
+merge spec includes the subset of segments to be merged as well as
+whether the new segment should use the compound file format.
+*/
+type OneMerge struct {
+	// Segments to be merged.
+	segments []SegmentInfoPerCommit
+	// The SegmentInfoPerCommit of the new segment, set by IndexWriter
+	// when the merge starts.
+	info *SegmentInfoPerCommit
+	// Total number of documents in the segments to be merged.
+	totalDocCount int
+	// The target segment count of ForceMerge(), or -1 if the merge was
+	// not forced.
+	maxNumSegments int
+	// Expert: wraps the readers of the segments to be merged, e.g. to
+	// sort their documents, or nil to merge the readers as they are.
+	// The merge releases the segment readers, not the wrapped ones.
+	wrapReaders func(readers []AtomicReader) ([]AtomicReader, error)
+}
+
+// Sole constructor.
+func NewOneMerge(segments []SegmentInfoPerCommit) *OneMerge {
+	if len(segments) == 0 {
+		panic("segments must include at least one segment")
+	}
+	// clone the list, as the incoming list may be based off the
+	// original SegmentInfos and may be modified
+	ans := &OneMerge{
+		segments:       append([]SegmentInfoPerCommit(nil), segments...),
+		maxNumSegments: -1,
+	}
+	for _, info := range segments {
+		ans.totalDocCount += int(info.info.docCount)
+	}
+	return ans
+}
+
+// Returns the segments to be merged.
+func (m *OneMerge) Segments() []SegmentInfoPerCommit {
+	return m.segments
+}
+
+// Returns the total number of documents in the segments to be merged,
+// not accounting for deletions.
+func (m *OneMerge) TotalDocCount() int {
+	return m.totalDocCount
+}
+
+// Returns a readable description of the current merge state.
+func (m *OneMerge) SegString() string {
+	var b bytes.Buffer
+	for i, info := range m.segments {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(info.String())
+	}
+	if m.info != nil {
+		fmt.Fprintf(&b, " into %v", m.info.info.name)
+	}
+	if m.maxNumSegments != -1 {
+		fmt.Fprintf(&b, " [maxNumSegments=%v]", m.maxNumSegments)
+	}
+	return b.String()
+}
+
+// MergePolicy.java/MergeSpecification
+
+/*
+A MergeSpecification instance provides the information necessary to
+perform multiple merges. It simply contains a list of OneMerge
+instances.
+*/
+type MergeSpecification struct {
+	// The subset of segments to be included in the primitive merge.
+	merges []*OneMerge
+}
+
+// Adds the provided OneMerge to this specification.
+func (spec *MergeSpecification) add(merge *OneMerge) {
+	spec.merges = append(spec.merges, merge)
+}
+
+// Returns the merges of this specification.
+func (spec *MergeSpecification) Merges() []*OneMerge {
+	return spec.merges
+}
+
+// Returns a description of the merges in this specification.
+func (spec *MergeSpecification) SegString() string {
+	var b bytes.Buffer
+	b.WriteString("MergeSpec:\n")
+	for i, merge := range spec.merges {
+		fmt.Fprintf(&b, "  %v: %v\n", i+1, merge.SegString())
+	}
+	return b.String()
+}
+
+// NoMergePolicy.java
+
+/*
+A MergePolicy which never returns merges to execute (hence its
+name). It is also a singleton and can be accessed through
+NO_MERGE_POLICY_NO_COMPOUND_FILES if you want to indicate the index
+does not use compound files, or through NO_MERGE_POLICY_COMPOUND_FILES
+otherwise. Use it if you want to prevent an IndexWriter from ever
+executing merges, without going through the hassle of tweaking a
+merge policy's settings to achieve that, such as changing its merge
+factor.
+
+This is useful when bulk loading documents: disable merging while
+adding them, then reopen the index with another MergePolicy and call
+IndexWriter.ForceMerge() at the end.
+*/
+type NoMergePolicy struct {
+	useCompoundFile bool
+}
+
+var (
+	// A singleton NoMergePolicy which indicates the index does not use
+	// compound files.
+	NO_MERGE_POLICY_NO_COMPOUND_FILES = &NoMergePolicy{false}
+	// A singleton NoMergePolicy which indicates the index uses
+	// compound files.
+	NO_MERGE_POLICY_COMPOUND_FILES = &NoMergePolicy{true}
+)
+
+func (mp *NoMergePolicy) SetIndexWriter(writer *IndexWriter) {}
+
+func (mp *NoMergePolicy) FindMerges(trigger MergeTrigger, infos *SegmentInfos) (*MergeSpecification, error) {
+	return nil, nil
+}
+
+func (mp *NoMergePolicy) FindForcedMerges(infos *SegmentInfos, maxSegmentCount int,
+	segmentsToMerge map[string]bool) (*MergeSpecification, error) {
+	return nil, nil
+}
+
+func (mp *NoMergePolicy) FindForcedDeletesMerges(infos *SegmentInfos) (*MergeSpecification, error) {
+	return nil, nil
+}
+
+func (mp *NoMergePolicy) UseCompoundFile(infos *SegmentInfos, mergedInfo *SegmentInfoPerCommit) (bool, error) {
+	return mp.useCompoundFile, nil
+}
+
+func (mp *NoMergePolicy) String() string {
+	return "NoMergePolicy"
+}
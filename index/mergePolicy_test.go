@@ -0,0 +1,42 @@
+package index
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNoMergePolicy(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	// bulk load without merging
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetMaxBufferedDocs(2).
+		SetMergePolicy(NO_MERGE_POLICY_NO_COMPOUND_FILES).SetMergeScheduler(NO_MERGE_SCHEDULER))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// more segments than the default merge factor
+	addTestDocs(t, w, 30)
+	if err = w.ForceMerge(1); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertSegmentCount(t, d, 15)
+
+	useCompoundFile, err := NO_MERGE_POLICY_COMPOUND_FILES.UseCompoundFile(nil, nil)
+	if err != nil || !useCompoundFile {
+		t.Error("NO_MERGE_POLICY_COMPOUND_FILES should use compound files")
+	}
+
+	// merge at the end
+	w = openTestIndexWriter(t, d, OPEN_MODE_APPEND, 2)
+	if err = w.ForceMerge(1); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertSegmentCount(t, d, 1)
+}
@@ -0,0 +1,87 @@
+package index
+
+import (
+	"sync"
+)
+
+// MergeScheduler.java
+
+/*
+Expert: IndexWriter uses an instance implementing this interface to
+execute the merges selected by a MergePolicy. The default
+MergeScheduler is SerialMergeScheduler.
+*/
+type MergeScheduler interface {
+	// Run the merges provided by IndexWriter.NextMerge().
+	Merge(writer *IndexWriter) error
+	// Close this MergeScheduler.
+	Close() error
+}
+
+// SerialMergeScheduler.java
+
+/*
+A MergeScheduler that simply does each merge sequentially, using the
+goroutine that triggered the merge.
+*/
+type SerialMergeScheduler struct {
+	sync.Locker
+}
+
+// Sole constructor.
+func NewSerialMergeScheduler() *SerialMergeScheduler {
+	return &SerialMergeScheduler{&sync.Mutex{}}
+}
+
+/*
+Just do the merges in sequence. We do this "synchronized" so that
+even if the application is using multiple goroutines, only one merge
+may run at a time.
+*/
+func (ms *SerialMergeScheduler) Merge(writer *IndexWriter) error {
+	ms.Lock()
+	defer ms.Unlock()
+	for {
+		merge := writer.NextMerge()
+		if merge == nil {
+			return nil
+		}
+		if err := writer.Merge(merge); err != nil {
+			return err
+		}
+	}
+}
+
+func (ms *SerialMergeScheduler) Close() error {
+	return nil
+}
+
+// NoMergeScheduler.java
+
+/*
+A MergeScheduler which never executes any merges. It is also a
+singleton and can be accessed through NO_MERGE_SCHEDULER. Use it if
+you want to prevent an IndexWriter from ever executing merges,
+regardless of the MergePolicy used. Note that you can achieve the
+same thing by using NoMergePolicy, however with NoMergeScheduler you
+also ensure that no unnecessary code of any MergeScheduler
+implementation is ever executed. Hence it is recommended to use both
+if you want to disable merges from ever happening.
+
+NOTE: since the merges are never executed, ForceMerge() must not be
+called on an IndexWriter using this scheduler, or it would wait
+forever. To merge the segments once bulk loading is done, reopen the
+index with another MergeScheduler instead.
+*/
+type NoMergeScheduler struct{}
+
+// The single instance of NoMergeScheduler.
+var NO_MERGE_SCHEDULER = NoMergeScheduler{}
+
+func (ms NoMergeScheduler) Merge(writer *IndexWriter) error {
+	return nil
+}
+
+func (ms NoMergeScheduler) Close() error {
+	return nil
+}
@@ -0,0 +1,83 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/util"
+)
+
+// index/MultiBits.java
+
+/*
+Concatenates multiple Bits together, on every lookup.
+
+NOTE: This is very costly, as every lookup must do a binary search
+to locate the right sub-reader.
+*/
+type MultiBits struct {
+	subs []util.Bits
+	// length is 1+len(subs) (the last entry has the maxDoc):
+	starts       []int
+	defaultValue bool
+}
+
+func newMultiBits(subs []util.Bits, starts []int, defaultValue bool) *MultiBits {
+	// assert len(starts) == 1+len(subs)
+	return &MultiBits{subs, starts, defaultValue}
+}
+
+func (mb *MultiBits) Get(doc int) bool {
+	reader := subIndex(doc, mb.starts)
+	// assert reader != -1
+	bits := mb.subs[reader]
+	if bits == nil {
+		return mb.defaultValue
+	}
+	// assert doc-starts[reader] < bits.Length()
+	return bits.Get(doc - mb.starts[reader])
+}
+
+func (mb *MultiBits) Length() int {
+	return mb.starts[len(mb.starts)-1]
+}
+
+/*
+Returns the sub-Bits matching the provided slice, and true if
+the slice is congruent with one of the sub readers. Otherwise
+returns nil and false.
+
+NOTE: nil with true is a valid result, meaning the matched sub
+reader has no deletions.
+*/
+func (mb *MultiBits) matchingSub(slice ReaderSlice) (util.Bits, bool) {
+	reader := subIndex(slice.start, mb.starts)
+	// assert reader != -1
+	// assert reader < len(subs)
+	if mb.starts[reader] == slice.start && mb.starts[1+reader] == slice.start+slice.length {
+		return mb.subs[reader], true
+	}
+	return nil, false
+}
+
+// index/BitsSlice.java
+
+// Exposes a slice of an existing Bits as a new Bits.
+type BitsSlice struct {
+	parent util.Bits
+	start  int
+	length int
+}
+
+func newBitsSlice(parent util.Bits, slice ReaderSlice) *BitsSlice {
+	// assert length >= 0
+	return &BitsSlice{parent, slice.start, slice.length}
+}
+
+func (bs *BitsSlice) Get(doc int) bool {
+	if doc >= bs.length {
+		panic("assert fail")
+	}
+	return bs.parent.Get(doc + bs.start)
+}
+
+func (bs *BitsSlice) Length() int {
+	return bs.length
+}
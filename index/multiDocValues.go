@@ -0,0 +1,297 @@
+package index
+
+import (
+	"sort"
+)
+
+// MultiDocValues.java
+
+/*
+Returns a NumericDocValues for a reader's norms (potentially merging
+on-the-fly), or nil if no leaf of the reader has norms for the field.
+
+NOTE: this is a slow way to access normalization values. Instead,
+access them per-segment with AtomicReader.NormValues().
+*/
+func GetMultiNormValues(r IndexReader, field string) (NumericDocValues, error) {
+	return multiNumericValues(r, func(leaf AtomicReader) (NumericDocValues, error) {
+		return leaf.NormValues(field)
+	})
+}
+
+/*
+Returns a NumericDocValues for a reader's docvalues (potentially
+merging on-the-fly), or nil if no leaf of the reader has numeric
+docvalues for the field.
+
+NOTE: this is a slow way to access numeric values. Instead, access
+them per-segment with AtomicReader.NumericDocValues().
+*/
+func GetMultiNumericValues(r IndexReader, field string) (NumericDocValues, error) {
+	return multiNumericValues(r, func(leaf AtomicReader) (NumericDocValues, error) {
+		return leaf.NumericDocValues(field)
+	})
+}
+
+func multiNumericValues(r IndexReader,
+	values func(leaf AtomicReader) (NumericDocValues, error)) (NumericDocValues, error) {
+	leaves := r.Leaves()
+	switch len(leaves) {
+	case 0:
+		return nil, nil
+	case 1:
+		return values(leaves[0].Reader().(AtomicReader))
+	}
+	subs := make([]NumericDocValues, len(leaves))
+	anyReal := false
+	for i, ctx := range leaves {
+		v, err := values(ctx.Reader().(AtomicReader))
+		if err != nil {
+			return nil, err
+		}
+		subs[i], anyReal = v, anyReal || v != nil
+	}
+	if !anyReal {
+		return nil, nil
+	}
+	return NumericDocValuesFunc(func(docID int) int64 {
+		i := SubIndex(docID, leaves)
+		if subs[i] == nil {
+			return 0
+		}
+		return subs[i].Get(docID - leaves[i].DocBase)
+	}), nil
+}
+
+/*
+Returns a BinaryDocValues for a reader's docvalues (potentially
+merging on-the-fly), or nil if no leaf of the reader has binary
+docvalues for the field.
+
+NOTE: this is a slow way to access binary values. Instead, access
+them per-segment with AtomicReader.BinaryDocValues().
+*/
+func GetMultiBinaryValues(r IndexReader, field string) (BinaryDocValues, error) {
+	leaves := r.Leaves()
+	switch len(leaves) {
+	case 0:
+		return nil, nil
+	case 1:
+		return leaves[0].Reader().(AtomicReader).BinaryDocValues(field)
+	}
+	subs := make([]BinaryDocValues, len(leaves))
+	anyReal := false
+	for i, ctx := range leaves {
+		v, err := ctx.Reader().(AtomicReader).BinaryDocValues(field)
+		if err != nil {
+			return nil, err
+		}
+		subs[i], anyReal = v, anyReal || v != nil
+	}
+	if !anyReal {
+		return nil, nil
+	}
+	return BinaryDocValuesFunc(func(docID int) []byte {
+		i := SubIndex(docID, leaves)
+		if subs[i] == nil {
+			return nil
+		}
+		return subs[i].Get(docID - leaves[i].DocBase)
+	}), nil
+}
+
+/*
+Returns a SortedDocValues for a reader's docvalues (potentially doing
+extremely slow things), or nil if no leaf of the reader has sorted
+docvalues for the field. The values of all leaves are merged into a
+single sorted set of ordinals when this is called.
+
+NOTE: this is a slow way to access sorted values. Instead, access
+them per-segment with AtomicReader.SortedDocValues().
+*/
+func GetMultiSortedValues(r IndexReader, field string) (SortedDocValues, error) {
+	leaves := r.Leaves()
+	switch len(leaves) {
+	case 0:
+		return nil, nil
+	case 1:
+		return leaves[0].Reader().(AtomicReader).SortedDocValues(field)
+	}
+	subs := make([]SortedDocValues, len(leaves))
+	anyReal := false
+	for i, ctx := range leaves {
+		v, err := ctx.Reader().(AtomicReader).SortedDocValues(field)
+		if err != nil {
+			return nil, err
+		}
+		subs[i], anyReal = v, anyReal || v != nil
+	}
+	if !anyReal {
+		return nil, nil
+	}
+	ordMap := newOrdinalMap(len(subs), func(i int) (int, func(ord int) []byte) {
+		if subs[i] == nil {
+			return 0, nil
+		}
+		return subs[i].ValueCount(), subs[i].LookupOrd
+	})
+	return &multiSortedDocValues{leaves, subs, ordMap}, nil
+}
+
+/*
+Returns a SortedSetDocValues for a reader's docvalues (potentially
+doing extremely slow things), or nil if no leaf of the reader has
+sorted set docvalues for the field. The values of all leaves are
+merged into a single sorted set of ordinals when this is called.
+
+NOTE: this is a slow way to access sorted set values. Instead, access
+them per-segment with AtomicReader.SortedSetDocValues().
+*/
+func GetMultiSortedSetValues(r IndexReader, field string) (SortedSetDocValues, error) {
+	leaves := r.Leaves()
+	switch len(leaves) {
+	case 0:
+		return nil, nil
+	case 1:
+		return leaves[0].Reader().(AtomicReader).SortedSetDocValues(field)
+	}
+	subs := make([]SortedSetDocValues, len(leaves))
+	anyReal := false
+	for i, ctx := range leaves {
+		v, err := ctx.Reader().(AtomicReader).SortedSetDocValues(field)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			v = EMPTY_SORTED_SET_DOC_VALUES
+		} else {
+			anyReal = true
+		}
+		subs[i] = v
+	}
+	if !anyReal {
+		return nil, nil
+	}
+	ordMap := newOrdinalMap(len(subs), func(i int) (int, func(ord int) []byte) {
+		return int(subs[i].ValueCount()), func(ord int) []byte {
+			return subs[i].LookupOrd(int64(ord))
+		}
+	})
+	return &multiSortedSetDocValues{leaves: leaves, values: subs, ordMap: ordMap}, nil
+}
+
+// MultiDocValues.java/OrdinalMap
+
+/*
+Maps the ordinals of the values of each segment to the ordinals of
+the merged, deduplicated and sorted values of all segments.
+*/
+type ordinalMap struct {
+	// segment ord -> global ord, per segment
+	globalOrds [][]int
+	// global ord -> value
+	values [][]byte
+}
+
+// Creates an ordinalMap of numSubs segments, whose value count and
+// lookup function sub returns.
+func newOrdinalMap(numSubs int, sub func(i int) (valueCount int, lookupOrd func(ord int) []byte)) *ordinalMap {
+	ans := &ordinalMap{globalOrds: make([][]int, numSubs)}
+	unique := make(map[string]bool)
+	for i := 0; i < numSubs; i++ {
+		valueCount, lookupOrd := sub(i)
+		for ord := 0; ord < valueCount; ord++ {
+			if v := lookupOrd(ord); !unique[string(v)] {
+				unique[string(v)] = true
+				ans.values = append(ans.values, append([]byte(nil), v...))
+			}
+		}
+	}
+	// byte-wise order, same as the order of Go strings
+	sort.Sort(byteSlices(ans.values))
+	globalOrds := make(map[string]int, len(ans.values))
+	for ord, v := range ans.values {
+		globalOrds[string(v)] = ord
+	}
+	for i := 0; i < numSubs; i++ {
+		valueCount, lookupOrd := sub(i)
+		ans.globalOrds[i] = make([]int, valueCount)
+		for ord := 0; ord < valueCount; ord++ {
+			ans.globalOrds[i][ord] = globalOrds[string(lookupOrd(ord))]
+		}
+	}
+	return ans
+}
+
+type byteSlices [][]byte
+
+func (s byteSlices) Len() int           { return len(s) }
+func (s byteSlices) Less(i, j int) bool { return string(s[i]) < string(s[j]) }
+func (s byteSlices) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// MultiDocValues.java/MultiSortedDocValues
+
+// Implements SortedDocValues over n subs, using an ordinalMap.
+type multiSortedDocValues struct {
+	leaves []AtomicReaderContext
+	values []SortedDocValues // nil for leaves without values
+	ordMap *ordinalMap
+}
+
+func (v *multiSortedDocValues) Get(docID int) []byte {
+	if ord := v.Ord(docID); ord != -1 {
+		return v.LookupOrd(ord)
+	}
+	return nil
+}
+
+func (v *multiSortedDocValues) Ord(docID int) int {
+	i := SubIndex(docID, v.leaves)
+	if v.values[i] == nil {
+		return -1
+	}
+	ord := v.values[i].Ord(docID - v.leaves[i].DocBase)
+	if ord == -1 {
+		return -1
+	}
+	return v.ordMap.globalOrds[i][ord]
+}
+
+func (v *multiSortedDocValues) LookupOrd(ord int) []byte {
+	return v.ordMap.values[ord]
+}
+
+func (v *multiSortedDocValues) ValueCount() int {
+	return len(v.ordMap.values)
+}
+
+// MultiDocValues.java/MultiSortedSetDocValues
+
+// Implements SortedSetDocValues over n subs, using an ordinalMap.
+type multiSortedSetDocValues struct {
+	leaves          []AtomicReaderContext
+	values          []SortedSetDocValues
+	ordMap          *ordinalMap
+	currentSubIndex int
+}
+
+func (v *multiSortedSetDocValues) NextOrd() int64 {
+	ord := v.values[v.currentSubIndex].NextOrd()
+	if ord == NO_MORE_ORDS {
+		return ord
+	}
+	return int64(v.ordMap.globalOrds[v.currentSubIndex][ord])
+}
+
+func (v *multiSortedSetDocValues) SetDocument(docID int) {
+	v.currentSubIndex = SubIndex(docID, v.leaves)
+	v.values[v.currentSubIndex].SetDocument(docID - v.leaves[v.currentSubIndex].DocBase)
+}
+
+func (v *multiSortedSetDocValues) LookupOrd(ord int64) []byte {
+	return v.ordMap.values[ord]
+}
+
+func (v *multiSortedSetDocValues) ValueCount() int64 {
+	return int64(len(v.ordMap.values))
+}
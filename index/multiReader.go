@@ -0,0 +1,63 @@
+package index
+
+// index/MultiReader.java
+
+/*
+A CompositeReader which reads multiple indexes, appending their
+content. It can be used to create a view on several sub-readers (like
+DirectoryReader) and execute searches on it.
+
+For efficiency, in this API documents are often referred to via
+document numbers, non-negative integers which each name a unique
+document in the index. These document numbers are ephemeral -- they
+may change as documents are added to and deleted from an index.
+Clients should thus not rely on a given document having the same
+number between sessions.
+
+NOTE: IndexReader instances are completely thread safe, meaning
+multiple goroutines can call any of its methods, concurrently. If your
+application requires external synchronization, you should not
+synchronize on the IndexReader instance; use your own (non-Lucene)
+objects instead.
+*/
+type MultiReader struct {
+	*BaseCompositeReader
+	closeSubReaders bool
+}
+
+/*
+Construct a MultiReader aggregating the named set of (sub)readers.
+
+If closeSubReaders is true, the subreaders are closed when this
+MultiReader is closed, i.e. their lifecycle is owned by the
+MultiReader. Otherwise the subreaders are shared: they are incRef'ed
+here, and only decRef'ed when this MultiReader is closed.
+*/
+func NewMultiReader(subReaders []IndexReader, closeSubReaders bool) *MultiReader {
+	readers := make([]IndexReader, len(subReaders))
+	copy(readers, subReaders)
+	ans := &MultiReader{closeSubReaders: closeSubReaders}
+	ans.BaseCompositeReader = newBaseCompositeReader(ans, readers)
+	if !closeSubReaders {
+		for _, r := range readers {
+			r.incRef()
+		}
+	}
+	return ans
+}
+
+func (r *MultiReader) doClose() (err error) {
+	for _, sub := range r.getSequentialSubReaders() {
+		// try to close each reader, even if an error is returned
+		var err2 error
+		if r.closeSubReaders {
+			err2 = sub.Close()
+		} else {
+			err2 = sub.decRef()
+		}
+		if err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/store"
+	"testing"
+)
+
+func openTestReaders(t *testing.T, n int) []IndexReader {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subs := make([]IndexReader, n)
+	for i := range subs {
+		if subs[i], err = OpenDirectoryReader(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return subs
+}
+
+func TestMultiReader(t *testing.T) {
+	subs := openTestReaders(t, 2)
+	r := NewMultiReader(subs, true)
+	maxDoc := subs[0].MaxDoc()
+	assertEquals(t, 2*maxDoc, r.MaxDoc())
+	assertEquals(t, 2*maxDoc, r.NumDocs())
+
+	leaves := r.Leaves()
+	if len(leaves) != 2 {
+		t.Fatalf("Expected 2 leaves, but was %v", len(leaves))
+	}
+	assertEquals(t, 0, leaves[0].DocBase)
+	assertEquals(t, maxDoc, leaves[1].DocBase)
+
+	visitor := NewDocumentStoredFieldVisitorOf("title")
+	if err := r.Document(maxDoc+7, visitor); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Bats", visitor.Document().Get("title"))
+
+	if n, err := r.DocFreq(NewTerm("scope", "belfrysample")); err != nil || n != 2*maxDoc {
+		t.Errorf("Expected docFreq %v, but was %v (%v)", 2*maxDoc, n, err)
+	}
+	termsEnum := GetMultiTerms(r, "scope").Iterator(nil)
+	if ok, err := termsEnum.SeekExact([]byte("belfrysample")); !ok || err != nil {
+		t.Fatalf("Term not found: %v", err)
+	}
+	docs := collectDocs(termsEnum.Docs(GetMultiLiveDocs(r), DOCS_ENUM_EMPTY))
+	if len(docs) != 2*maxDoc || docs[maxDoc] != maxDoc {
+		t.Errorf("Unexpected docs %v", docs)
+	}
+
+	// sub readers are owned by the MultiReader
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range subs {
+		assertClosed(t, "owned sub reader", sub)
+	}
+}
+
+func TestMultiReaderSharedSubReaders(t *testing.T) {
+	subs := openTestReaders(t, 2)
+	// the same reader can be added twice
+	r := NewMultiReader([]IndexReader{subs[0], subs[1], subs[0]}, false)
+	assertEquals(t, 3*subs[0].MaxDoc(), r.MaxDoc())
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// shared sub readers are still usable
+	for _, sub := range subs {
+		if sub.(*StandardDirectoryReader).refCount != 1 {
+			t.Errorf("Unexpected ref count %v", sub.(*StandardDirectoryReader).refCount)
+		}
+		if n, err := sub.DocFreq(NewTerm("scope", "belfrysample")); err != nil || n != sub.MaxDoc() {
+			t.Errorf("Expected docFreq %v, but was %v (%v)", sub.MaxDoc(), n, err)
+		}
+		if err := sub.Close(); err != nil {
+			t.Fatal(err)
+		}
+		assertClosed(t, "shared sub reader", sub)
+	}
+}
@@ -0,0 +1,655 @@
+package index
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// index/MultiTermsEnum.java
+
+type termsEnumIndex struct {
+	subIndex  int
+	termsEnum TermsEnum
+}
+
+type termsEnumWithSlice struct {
+	subSlice ReaderSlice
+	terms    TermsEnum
+	current  []byte
+	index    int
+}
+
+func (e *termsEnumWithSlice) reset(terms TermsEnum, term []byte) {
+	e.terms = terms
+	e.current = term
+}
+
+func (e *termsEnumWithSlice) String() string {
+	return fmt.Sprintf("%v:%v", e.subSlice, e.terms)
+}
+
+// Priority queue of sub enums, ordered by current term, then by
+// doc base of the slice.
+type termMergeQueue []*termsEnumWithSlice
+
+func (q termMergeQueue) Len() int { return len(q) }
+func (q termMergeQueue) Less(i, j int) bool {
+	if cmp := bytes.Compare(q[i].current, q[j].current); cmp != 0 {
+		return cmp < 0
+	}
+	return q[i].subSlice.start < q[j].subSlice.start
+}
+func (q termMergeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *termMergeQueue) Push(x interface{}) { *q = append(*q, x.(*termsEnumWithSlice)) }
+func (q *termMergeQueue) Pop() interface{} {
+	n := len(*q)
+	ans := (*q)[n-1]
+	*q = (*q)[:n-1]
+	return ans
+}
+
+/*
+Exposes TermsEnum API, merged from TermsEnum API of sub-segments.
+This does a merge sort, by term text, of the sub-readers.
+*/
+type MultiTermsEnum struct {
+	*TermsEnumImpl
+	queue               termMergeQueue
+	subs                []*termsEnumWithSlice // all of our subs (one per sub-reader)
+	currentSubs         []*termsEnumWithSlice // current subs that have at least one term for this field
+	top                 []*termsEnumWithSlice
+	subDocs             []docsEnumWithSlice
+	subDocsAndPositions []docsAndPositionsEnumWithSlice
+
+	lastSeek      []byte
+	lastSeekExact bool
+
+	numTop  int
+	numSubs int
+	current []byte
+}
+
+/*
+Sole constructor. slices specifies which sub-reader slices have
+terms.
+*/
+func NewMultiTermsEnum(slices []ReaderSlice) *MultiTermsEnum {
+	ans := &MultiTermsEnum{
+		queue:               make(termMergeQueue, 0, len(slices)),
+		top:                 make([]*termsEnumWithSlice, len(slices)),
+		subs:                make([]*termsEnumWithSlice, len(slices)),
+		currentSubs:         make([]*termsEnumWithSlice, len(slices)),
+		subDocs:             make([]docsEnumWithSlice, len(slices)),
+		subDocsAndPositions: make([]docsAndPositionsEnumWithSlice, len(slices)),
+	}
+	ans.TermsEnumImpl = newTermsEnumImpl(ans)
+	for i, slice := range slices {
+		ans.subs[i] = &termsEnumWithSlice{subSlice: slice, index: i}
+		ans.subDocs[i].slice = slice
+		ans.subDocsAndPositions[i].slice = slice
+	}
+	return ans
+}
+
+/*
+The terms array must be newly created TermsEnum, ie Next() has not
+yet been called.
+*/
+func (e *MultiTermsEnum) reset(termsEnumsIndex []termsEnumIndex) (TermsEnum, error) {
+	// assert len(termsEnumsIndex) <= len(top)
+	e.numSubs = 0
+	e.numTop = 0
+	e.queue = e.queue[:0]
+	for _, idx := range termsEnumsIndex {
+		term, err := idx.termsEnum.Next()
+		if err != nil {
+			return nil, err
+		}
+		if term != nil {
+			entry := e.subs[idx.subIndex]
+			entry.reset(idx.termsEnum, term)
+			heap.Push(&e.queue, entry)
+			e.currentSubs[e.numSubs] = entry
+			e.numSubs++
+		} // else field has no terms
+	}
+	if len(e.queue) == 0 {
+		return EMPTY_TERMS_ENUM, nil
+	}
+	return e, nil
+}
+
+// Returns how many sub-reader slices contain the current term.
+func (e *MultiTermsEnum) MatchCount() int {
+	return e.numTop
+}
+
+func (e *MultiTermsEnum) Term() []byte {
+	return e.current
+}
+
+func (e *MultiTermsEnum) SeekExact(term []byte) (ok bool, err error) {
+	e.queue = e.queue[:0]
+	e.numTop = 0
+
+	seekOpt := e.lastSeek != nil && bytes.Compare(e.lastSeek, term) <= 0
+	e.lastSeek = nil
+	e.lastSeekExact = true
+
+	for _, sub := range e.currentSubs[:e.numSubs] {
+		var status bool
+		// LUCENE-2130: if we had just seek'd already, prior to this
+		// seek, and the new seek term is after the previous one, don't
+		// try to re-seek this sub if its current term is already beyond
+		// this new seek term. Doing so is a waste because this sub will
+		// simply seek to the same spot.
+		if seekOpt {
+			if curTerm := sub.current; curTerm != nil {
+				if cmp := bytes.Compare(term, curTerm); cmp == 0 {
+					status = true
+				} else if cmp > 0 {
+					if status, err = sub.terms.SeekExact(term); err != nil {
+						return false, err
+					}
+				}
+			}
+		} else if status, err = sub.terms.SeekExact(term); err != nil {
+			return false, err
+		}
+
+		if status {
+			e.top[e.numTop] = sub
+			e.numTop++
+			sub.current = sub.terms.Term()
+			e.current = sub.current
+			// assert bytes.Equal(term, sub.current)
+		}
+	}
+
+	// if at least one sub had exact match to the requested term then
+	// we found match
+	return e.numTop > 0, nil
+}
+
+func (e *MultiTermsEnum) SeekCeil(term []byte) SeekStatus {
+	e.queue = e.queue[:0]
+	e.numTop = 0
+	e.lastSeekExact = false
+
+	seekOpt := e.lastSeek != nil && bytes.Compare(e.lastSeek, term) <= 0
+	e.lastSeek = append(e.lastSeek[:0], term...)
+
+	for _, sub := range e.currentSubs[:e.numSubs] {
+		var status SeekStatus
+		// LUCENE-2130: if we had just seek'd already, prior to this
+		// seek, and the new seek term is after the previous one, don't
+		// try to re-seek this sub if its current term is already beyond
+		// this new seek term. Doing so is a waste because this sub will
+		// simply seek to the same spot.
+		if seekOpt {
+			if curTerm := sub.current; curTerm != nil {
+				if cmp := bytes.Compare(term, curTerm); cmp == 0 {
+					status = SEEK_STATUS_FOUND
+				} else if cmp < 0 {
+					status = SEEK_STATUS_NOT_FOUND
+				} else {
+					status = sub.terms.SeekCeil(term)
+				}
+			} else {
+				status = SEEK_STATUS_END
+			}
+		} else {
+			status = sub.terms.SeekCeil(term)
+		}
+
+		switch status {
+		case SEEK_STATUS_FOUND:
+			e.top[e.numTop] = sub
+			e.numTop++
+			sub.current = sub.terms.Term()
+			e.current = sub.current
+		case SEEK_STATUS_NOT_FOUND:
+			sub.current = sub.terms.Term()
+			// assert sub.current != nil
+			heap.Push(&e.queue, sub)
+		default:
+			// enum exhausted
+			sub.current = nil
+		}
+	}
+
+	if e.numTop > 0 {
+		// at least one sub had exact match to the requested term
+		return SEEK_STATUS_FOUND
+	} else if len(e.queue) > 0 {
+		// no sub had exact match, but at least one sub found a term
+		// after the requested term -- advance to that next term:
+		e.pullTop()
+		return SEEK_STATUS_NOT_FOUND
+	}
+	return SEEK_STATUS_END
+}
+
+func (e *MultiTermsEnum) SeekExactByPosition(ord int64) error {
+	return ErrUnsupported
+}
+
+func (e *MultiTermsEnum) Ord() (int64, error) {
+	return 0, ErrUnsupported
+}
+
+// extract all subs from the queue that have the same top term
+func (e *MultiTermsEnum) pullTop() {
+	// assert numTop == 0
+	for {
+		e.top[e.numTop] = heap.Pop(&e.queue).(*termsEnumWithSlice)
+		e.numTop++
+		if len(e.queue) == 0 || !bytes.Equal(e.queue[0].current, e.top[0].current) {
+			break
+		}
+	}
+	e.current = e.top[0].current
+}
+
+// call Next() on each top, and put back into queue
+func (e *MultiTermsEnum) pushTop() error {
+	for _, sub := range e.top[:e.numTop] {
+		term, err := sub.terms.Next()
+		if err != nil {
+			return err
+		}
+		if sub.current = term; term != nil {
+			heap.Push(&e.queue, sub)
+		} // else no more fields in this reader
+	}
+	e.numTop = 0
+	return nil
+}
+
+func (e *MultiTermsEnum) Next() (term []byte, err error) {
+	if e.lastSeekExact {
+		// Must SeekCeil at this point, so those subs that didn't have
+		// the term can find the following term.
+		//
+		// NOTE: we could save some CPU by only SeekCeil the subs that
+		// didn't match the last exact seek... but most impls
+		// short-circuit if you SeekCeil to term they are already on.
+		if status := e.SeekCeil(e.current); status != SEEK_STATUS_FOUND {
+			panic("assert fail")
+		}
+		e.lastSeekExact = false
+	}
+	e.lastSeek = nil
+
+	// restore queue
+	if err = e.pushTop(); err != nil {
+		return nil, err
+	}
+
+	// gather equal top fields
+	if len(e.queue) > 0 {
+		e.pullTop()
+	} else {
+		e.current = nil
+	}
+	return e.current, nil
+}
+
+func (e *MultiTermsEnum) DocFreq() int {
+	sum := 0
+	for _, sub := range e.top[:e.numTop] {
+		sum += sub.terms.DocFreq()
+	}
+	return sum
+}
+
+func (e *MultiTermsEnum) TotalTermFreq() int64 {
+	sum := int64(0)
+	for _, sub := range e.top[:e.numTop] {
+		v := sub.terms.TotalTermFreq()
+		if v == -1 {
+			return v
+		}
+		sum += v
+	}
+	return sum
+}
+
+// Returns the live docs to pass to the sub enum of given slice.
+func subLiveDocs(liveDocs util.Bits, slice ReaderSlice) util.Bits {
+	if liveDocs == nil {
+		// no deletions
+		return nil
+	}
+	if multiLiveDocs, ok := liveDocs.(*MultiBits); ok {
+		// optimize for common case: requested skip docs is a congruent
+		// sub-slice of MultiBits: in this case, we just pull the
+		// liveDocs from the sub reader, rather than making the
+		// inefficient Slice(Multi(sub-readers)):
+		if b, ok := multiLiveDocs.matchingSub(slice); ok {
+			return b
+		}
+	}
+	// custom case: requested skip docs is foreign: must slice it on
+	// every access
+	return newBitsSlice(liveDocs, slice)
+}
+
+func (e *MultiTermsEnum) DocsByFlags(liveDocs util.Bits, reuse DocsEnum, flags int) DocsEnum {
+	// Can only reuse if incoming enum is also a MultiDocsEnum
+	docsEnum, ok := reuse.DocIdSetIterator.(*MultiDocsEnum)
+	// ... and was previously created w/ this MultiTermsEnum:
+	if !ok || !docsEnum.canReuse(e) {
+		docsEnum = newMultiDocsEnum(e, len(e.subs))
+	}
+
+	upto := 0
+	for _, entry := range e.top[:e.numTop] {
+		b := subLiveDocs(liveDocs, entry.subSlice)
+		// assert entry.index < len(docsEnum.subDocsEnum)
+		subDocsEnum := entry.terms.DocsByFlags(b, docsEnum.subDocsEnum[entry.index], flags)
+		if subDocsEnum.DocIdSetIterator == nil {
+			panic("One of our subs cannot provide a docsenum")
+		}
+		docsEnum.subDocsEnum[entry.index] = subDocsEnum
+		e.subDocs[upto].docsEnum = subDocsEnum
+		e.subDocs[upto].slice = entry.subSlice
+		upto++
+	}
+
+	if upto == 0 {
+		return DOCS_ENUM_EMPTY
+	}
+	return DocsEnum{docsEnum.reset(e.subDocs, upto)}
+}
+
+func (e *MultiTermsEnum) DocsAndPositionsByFlags(liveDocs util.Bits, reuse DocsAndPositionsEnum, flags int) DocsAndPositionsEnum {
+	// Can only reuse if incoming enum is also a MultiDocsAndPositionsEnum
+	docsAndPositionsEnum, ok := reuse.DocsAndPositionsIterator.(*MultiDocsAndPositionsEnum)
+	// ... and was previously created w/ this MultiTermsEnum:
+	if !ok || !docsAndPositionsEnum.canReuse(e) {
+		docsAndPositionsEnum = newMultiDocsAndPositionsEnum(e, len(e.subs))
+	}
+
+	upto := 0
+	for _, entry := range e.top[:e.numTop] {
+		b := subLiveDocs(liveDocs, entry.subSlice)
+		// assert entry.index < len(docsAndPositionsEnum.subDocsAndPositionsEnum)
+		subPostings := entry.terms.DocsAndPositionsByFlags(b,
+			docsAndPositionsEnum.subDocsAndPositionsEnum[entry.index], flags)
+		if subPostings.DocsAndPositionsIterator == nil {
+			// At least one of our subs does not store offsets or
+			// positions -- we can't correctly produce a
+			// MultiDocsAndPositions enum
+			return DocsAndPositionsEnum{}
+		}
+		docsAndPositionsEnum.subDocsAndPositionsEnum[entry.index] = subPostings
+		e.subDocsAndPositions[upto].docsAndPositionsEnum = subPostings
+		e.subDocsAndPositions[upto].slice = entry.subSlice
+		upto++
+	}
+
+	if upto == 0 {
+		return DocsAndPositionsEnum{}
+	}
+	return DocsAndPositionsEnum{docsAndPositionsEnum.reset(e.subDocsAndPositions, upto)}
+}
+
+func (e *MultiTermsEnum) String() string {
+	return fmt.Sprintf("MultiTermsEnum(%v)", e.subs)
+}
+
+// index/MultiDocsEnum.java
+
+// Holds a DocsEnum along with the corresponding ReaderSlice.
+type docsEnumWithSlice struct {
+	docsEnum DocsEnum
+	slice    ReaderSlice
+}
+
+/*
+Exposes DocsEnum, merged from DocsEnum API of sub-segments. Doc IDs
+of each sub are shifted by the doc base of its slice.
+*/
+type MultiDocsEnum struct {
+	parent      *MultiTermsEnum
+	subDocsEnum []DocsEnum
+	subs        []docsEnumWithSlice
+	numSubs     int
+	upto        int
+	current     DocIdSetIterator
+	currentBase int
+	doc         int
+}
+
+func newMultiDocsEnum(parent *MultiTermsEnum, subReaderCount int) *MultiDocsEnum {
+	return &MultiDocsEnum{
+		parent:      parent,
+		subDocsEnum: make([]DocsEnum, subReaderCount),
+		doc:         -1,
+	}
+}
+
+func (de *MultiDocsEnum) reset(subs []docsEnumWithSlice, numSubs int) *MultiDocsEnum {
+	de.numSubs = numSubs
+	de.subs = append(de.subs[:0], subs[:numSubs]...)
+	de.upto = -1
+	de.doc = -1
+	de.current = nil
+	return de
+}
+
+// Returns true if this instance can be reused by the provided
+// MultiTermsEnum.
+func (de *MultiDocsEnum) canReuse(parent *MultiTermsEnum) bool {
+	return de.parent == parent
+}
+
+// Returns the number of sub-readers currently merged.
+func (de *MultiDocsEnum) NumSubs() int {
+	return de.numSubs
+}
+
+func (de *MultiDocsEnum) Freq() int {
+	return de.current.Freq()
+}
+
+func (de *MultiDocsEnum) DocId() int {
+	return de.doc
+}
+
+func (de *MultiDocsEnum) Cost() int64 {
+	var cost int64
+	for _, sub := range de.subs[:de.numSubs] {
+		cost += sub.docsEnum.Cost()
+	}
+	return cost
+}
+
+func (de *MultiDocsEnum) Advance(target int) (doc int, more bool) {
+	// assert target > doc
+	for {
+		if de.current != nil {
+			if target < de.currentBase {
+				// target was in the previous slice but there was no
+				// matching doc after it
+				doc, more = de.current.NextDoc()
+			} else {
+				doc, more = de.current.Advance(target - de.currentBase)
+			}
+			if more && doc != NO_MORE_DOCS {
+				de.doc = doc + de.currentBase
+				return de.doc, true
+			}
+			de.current = nil
+		} else if de.upto == de.numSubs-1 {
+			de.doc = NO_MORE_DOCS
+			return de.doc, false
+		} else {
+			de.upto++
+			de.current = de.subs[de.upto].docsEnum.DocIdSetIterator
+			de.currentBase = de.subs[de.upto].slice.start
+		}
+	}
+}
+
+func (de *MultiDocsEnum) NextDoc() (doc int, more bool) {
+	for {
+		if de.current == nil {
+			if de.upto == de.numSubs-1 {
+				de.doc = NO_MORE_DOCS
+				return de.doc, false
+			}
+			de.upto++
+			de.current = de.subs[de.upto].docsEnum.DocIdSetIterator
+			de.currentBase = de.subs[de.upto].slice.start
+		}
+
+		if doc, more = de.current.NextDoc(); more && doc != NO_MORE_DOCS {
+			de.doc = de.currentBase + doc
+			return de.doc, true
+		}
+		de.current = nil
+	}
+}
+
+func (de *MultiDocsEnum) String() string {
+	return fmt.Sprintf("MultiDocsEnum(%v)", de.subs[:de.numSubs])
+}
+
+// index/MultiDocsAndPositionsEnum.java
+
+// Holds a DocsAndPositionsEnum along with the corresponding
+// ReaderSlice.
+type docsAndPositionsEnumWithSlice struct {
+	docsAndPositionsEnum DocsAndPositionsEnum
+	slice                ReaderSlice
+}
+
+/*
+Exposes flex API, merged from flex API of sub-segments. Doc IDs of
+each sub are shifted by the doc base of its slice.
+*/
+type MultiDocsAndPositionsEnum struct {
+	parent                  *MultiTermsEnum
+	subDocsAndPositionsEnum []DocsAndPositionsEnum
+	subs                    []docsAndPositionsEnumWithSlice
+	numSubs                 int
+	upto                    int
+	current                 DocsAndPositionsIterator
+	currentBase             int
+	doc                     int
+}
+
+func newMultiDocsAndPositionsEnum(parent *MultiTermsEnum, subReaderCount int) *MultiDocsAndPositionsEnum {
+	return &MultiDocsAndPositionsEnum{
+		parent:                  parent,
+		subDocsAndPositionsEnum: make([]DocsAndPositionsEnum, subReaderCount),
+		doc:                     -1,
+	}
+}
+
+func (de *MultiDocsAndPositionsEnum) reset(subs []docsAndPositionsEnumWithSlice, numSubs int) *MultiDocsAndPositionsEnum {
+	de.numSubs = numSubs
+	de.subs = append(de.subs[:0], subs[:numSubs]...)
+	de.upto = -1
+	de.doc = -1
+	de.current = nil
+	return de
+}
+
+// Returns true if this instance can be reused by the provided
+// MultiTermsEnum.
+func (de *MultiDocsAndPositionsEnum) canReuse(parent *MultiTermsEnum) bool {
+	return de.parent == parent
+}
+
+// Returns the number of sub-readers currently merged.
+func (de *MultiDocsAndPositionsEnum) NumSubs() int {
+	return de.numSubs
+}
+
+func (de *MultiDocsAndPositionsEnum) Freq() int {
+	return de.current.Freq()
+}
+
+func (de *MultiDocsAndPositionsEnum) DocId() int {
+	return de.doc
+}
+
+func (de *MultiDocsAndPositionsEnum) Cost() int64 {
+	var cost int64
+	for _, sub := range de.subs[:de.numSubs] {
+		cost += sub.docsAndPositionsEnum.Cost()
+	}
+	return cost
+}
+
+func (de *MultiDocsAndPositionsEnum) Advance(target int) (doc int, more bool) {
+	// assert target > doc
+	for {
+		if de.current != nil {
+			if target < de.currentBase {
+				// target was in the previous slice but there was no
+				// matching doc after it
+				doc, more = de.current.NextDoc()
+			} else {
+				doc, more = de.current.Advance(target - de.currentBase)
+			}
+			if more && doc != NO_MORE_DOCS {
+				de.doc = doc + de.currentBase
+				return de.doc, true
+			}
+			de.current = nil
+		} else if de.upto == de.numSubs-1 {
+			de.doc = NO_MORE_DOCS
+			return de.doc, false
+		} else {
+			de.upto++
+			de.current = de.subs[de.upto].docsAndPositionsEnum.DocsAndPositionsIterator
+			de.currentBase = de.subs[de.upto].slice.start
+		}
+	}
+}
+
+func (de *MultiDocsAndPositionsEnum) NextDoc() (doc int, more bool) {
+	for {
+		if de.current == nil {
+			if de.upto == de.numSubs-1 {
+				de.doc = NO_MORE_DOCS
+				return de.doc, false
+			}
+			de.upto++
+			de.current = de.subs[de.upto].docsAndPositionsEnum.DocsAndPositionsIterator
+			de.currentBase = de.subs[de.upto].slice.start
+		}
+
+		if doc, more = de.current.NextDoc(); more && doc != NO_MORE_DOCS {
+			de.doc = de.currentBase + doc
+			return de.doc, true
+		}
+		de.current = nil
+	}
+}
+
+func (de *MultiDocsAndPositionsEnum) NextPosition() int {
+	return de.current.NextPosition()
+}
+
+func (de *MultiDocsAndPositionsEnum) StartOffset() int {
+	return de.current.StartOffset()
+}
+
+func (de *MultiDocsAndPositionsEnum) EndOffset() int {
+	return de.current.EndOffset()
+}
+
+func (de *MultiDocsAndPositionsEnum) Payload() []byte {
+	return de.current.Payload()
+}
+
+func (de *MultiDocsAndPositionsEnum) String() string {
+	return fmt.Sprintf("MultiDocsAndPositionsEnum(%v)", de.subs[:de.numSubs])
+}
@@ -0,0 +1,146 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"testing"
+)
+
+func collectDocs(docsEnum DocsEnum) []int {
+	var docs []int
+	for docID, ok := docsEnum.NextDoc(); ok && docID != NO_MORE_DOCS; docID, ok = docsEnum.NextDoc() {
+		docs = append(docs, docID)
+	}
+	return docs
+}
+
+func TestMultiTermsEnum(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	leaf := r.Leaves()[0].Reader().(AtomicReader)
+	maxDoc := leaf.MaxDoc()
+
+	// view the same segment twice, as if the index had two segments
+	fields := NewMultiFields([]Fields{leaf.Fields(), leaf.Fields()},
+		[]ReaderSlice{{0, maxDoc, 0}, {maxDoc, maxDoc, 1}})
+	if fields.Terms("nonexistent") != nil {
+		t.Error("Missing field should have no terms")
+	}
+	single, multi := leaf.Terms("scope"), fields.Terms("scope")
+	if multi.DocCount() != 2*single.DocCount() {
+		t.Errorf("Expected doc count %v, but was %v", 2*single.DocCount(), multi.DocCount())
+	}
+	if multi.SumDocFreq() != 2*single.SumDocFreq() {
+		t.Errorf("Expected sum doc freq %v, but was %v", 2*single.SumDocFreq(), multi.SumDocFreq())
+	}
+	if v := single.SumTotalTermFreq(); v != -1 && multi.SumTotalTermFreq() != 2*v {
+		t.Errorf("Expected sum total term freq %v, but was %v", 2*v, multi.SumTotalTermFreq())
+	}
+
+	// merged terms are the same terms, in the same order, with both
+	// subs contributing
+	singleEnum, multiEnum := single.Iterator(nil), multi.Iterator(nil)
+	count := 0
+	for {
+		expected, err := singleEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		term, err := multiEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(expected) != string(term) {
+			t.Fatalf("Expected term '%s', but was '%s'", expected, term)
+		}
+		if term == nil {
+			break
+		}
+		count++
+		if n := multiEnum.(*MultiTermsEnum).MatchCount(); n != 2 {
+			t.Errorf("Term '%s' should match 2 subs, but was %v", term, n)
+		}
+		if multiEnum.DocFreq() != 2*singleEnum.DocFreq() {
+			t.Errorf("Expected doc freq %v, but was %v", 2*singleEnum.DocFreq(), multiEnum.DocFreq())
+		}
+	}
+	if count == 0 {
+		t.Fatal("Field 'scope' should have terms")
+	}
+
+	// doc IDs of the second sub are shifted by its doc base
+	if ok, err := multiEnum.SeekExact([]byte("belfrysample")); !ok || err != nil {
+		t.Fatalf("Term not found: %v", err)
+	}
+	docs := collectDocs(multiEnum.Docs(nil, DOCS_ENUM_EMPTY))
+	if len(docs) != 2*maxDoc {
+		t.Fatalf("Expected %v docs, but was %v", 2*maxDoc, docs)
+	}
+	for i, docID := range docs {
+		if docID != i {
+			t.Errorf("Expected doc %v, but was %v", i, docID)
+		}
+	}
+
+	// a foreign live docs is sliced per sub; a congruent MultiBits is
+	// handed over to the subs directly
+	deleted := make(testBits, 2*maxDoc)
+	for i := range deleted {
+		deleted[i] = i != 1 && i != maxDoc+2
+	}
+	for _, liveDocs := range []util.Bits{deleted, newMultiBits([]util.Bits{deleted[:maxDoc], deleted[maxDoc:]}, []int{0, maxDoc, 2 * maxDoc}, true)} {
+		docs = collectDocs(multiEnum.Docs(liveDocs, DOCS_ENUM_EMPTY))
+		if len(docs) != 2*maxDoc-2 {
+			t.Errorf("Expected %v docs, but was %v", 2*maxDoc-2, docs)
+		}
+		for _, docID := range docs {
+			if !deleted[docID] {
+				t.Errorf("Deleted doc %v should be skipped", docID)
+			}
+		}
+	}
+
+	docsEnum := multiEnum.Docs(nil, DOCS_ENUM_EMPTY)
+	if docID, ok := docsEnum.Advance(maxDoc + 3); !ok || docID != maxDoc+3 {
+		t.Errorf("Expected to advance to %v, but was %v", maxDoc+3, docID)
+	}
+	if docID, ok := docsEnum.Advance(2 * maxDoc); ok || docID != NO_MORE_DOCS {
+		t.Errorf("Expected no more docs, but was %v", docID)
+	}
+
+	// seeking beyond all terms
+	if status := multiEnum.SeekCeil([]byte("\xff")); status != SEEK_STATUS_END {
+		t.Errorf("Expected END, but was %v", status)
+	}
+	if status := multiEnum.SeekCeil([]byte("a")); status == SEEK_STATUS_END {
+		t.Error("Should position on the first term after 'a'")
+	}
+}
+
+func TestGetMultiFields(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if GetMultiTerms(r, "scope") == nil {
+		t.Error("Field 'scope' should have terms")
+	}
+	if GetMultiTerms(r, "nonexistent") != nil {
+		t.Error("Missing field should have no terms")
+	}
+	if GetMultiLiveDocs(r) != nil {
+		t.Error("Index without deletions should have no live docs")
+	}
+}
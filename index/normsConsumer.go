@@ -0,0 +1,88 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/util"
+)
+
+// NormsConsumer.java
+
+/*
+Computes the norm of each inverted field through the Similarity once
+the field of a document is inverted, and writes the norms through the
+codec's norms consumer when the segment is flushed. Fields which omit
+norms get none.
+*/
+type NormsConsumer struct {
+	codec      Codec
+	similarity Similarity
+	writers    map[string]*NumericDocValuesWriter
+	bytesUsed  util.Counter
+}
+
+func newNormsConsumer(codec Codec, similarity Similarity, bytesUsed util.Counter) *NormsConsumer {
+	return &NormsConsumer{
+		codec:      codec,
+		similarity: similarity,
+		writers:    make(map[string]*NumericDocValuesWriter),
+		bytesUsed:  bytesUsed,
+	}
+}
+
+// Records the norm of a field of the given document, unless the
+// field omits norms.
+func (c *NormsConsumer) finish(docID int, fieldInfo *FieldInfo, state *FieldInvertState) {
+	if !fieldInfo.indexed || fieldInfo.omitNorms {
+		return
+	}
+	writer, ok := c.writers[fieldInfo.name]
+	if !ok {
+		writer = newNumericDocValuesWriter(fieldInfo, c.bytesUsed)
+		c.writers[fieldInfo.name] = writer
+	}
+	writer.addValue(docID, c.similarity.ComputeNorm(state))
+	fieldInfo.normType = DOC_VALUES_TYPE_NUMERIC
+}
+
+/*
+Writes the norms of the fields which have norms in the flushed
+segment. Documents without a field get a norm of 0.
+*/
+func (c *NormsConsumer) flush(state SegmentWriteState) (err error) {
+	defer func() {
+		c.writers = make(map[string]*NumericDocValuesWriter)
+	}()
+	if !state.fieldInfos.hasNorms {
+		return nil
+	}
+	consumer, err := c.codec.GetNormsDocValuesConsumer(state)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = consumer.Close()
+		} else {
+			util.CloseWhileSuppressingError(consumer)
+		}
+	}()
+
+	for _, fi := range state.fieldInfos.values {
+		if !fi.indexed || fi.omitNorms {
+			continue
+		}
+		if writer, ok := c.writers[fi.name]; ok {
+			writer.finish(int(state.segmentInfo.docCount))
+			if err = writer.flush(state, consumer); err != nil {
+				return err
+			}
+		} else if fi.normType != 0 {
+			panic("assert fail")
+		}
+	}
+	return nil
+}
+
+// Discards the buffered norms.
+func (c *NormsConsumer) abort() {
+	c.writers = make(map[string]*NumericDocValuesWriter)
+}
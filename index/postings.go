@@ -1,11 +1,13 @@
 package index
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/balzaczyy/golucene/codec"
 	"github.com/balzaczyy/golucene/store"
 	"github.com/balzaczyy/golucene/util"
+	"github.com/balzaczyy/golucene/util/automaton"
 	"io"
 	"log"
 	"sort"
@@ -352,6 +354,15 @@ func (r *FieldReader) Iterator(reuse TermsEnum) TermsEnum {
 	return newSegmentTermsEnum(r)
 }
 
+func (r *FieldReader) Intersect(compiled *automaton.CompiledAutomaton, startTerm []byte) (TermsEnum, error) {
+	return intersect(r, compiled, startTerm)
+}
+
+// For debugging -- used by CheckIndex too
+func (r *FieldReader) ComputeStats() (*BlockTreeStats, error) {
+	return newSegmentTermsEnum(r).computeBlockStats()
+}
+
 func (r *FieldReader) SumTotalTermFreq() int64 {
 	return r.sumTotalTermFreq
 }
@@ -440,6 +451,110 @@ func newSegmentTermsEnum(r *FieldReader) *SegmentTermsEnum {
 	return ans
 }
 
+// Runs next() through the entire terms dict, computing aggregate
+// statistics.
+func (e *SegmentTermsEnum) computeBlockStats() (stats *BlockTreeStats, err error) {
+	stats = newBlockTreeStats(e.segment, e.fieldInfo.name)
+	if e.index != nil {
+		stats.IndexNodeCount = e.index.NodeCount()
+		stats.IndexArcCount = e.index.ArcCount()
+		stats.IndexNumBytes = e.index.SizeInBytes()
+	}
+
+	e.currentFrame = e.staticFrame
+	var arc *util.Arc
+	if e.index != nil {
+		arc = e.index.FirstArc(e.arcs[0])
+		// Empty string prefix must have an output in the index!
+		if !arc.IsFinal() {
+			panic("assert fail")
+		}
+	}
+
+	if e.currentFrame, err = e.pushFrame(arc, e.rootCode, 0); err != nil {
+		return nil, err
+	}
+	e.currentFrame.fpOrig = e.currentFrame.fp
+	if err = e.currentFrame.loadBlock(); err != nil {
+		return nil, err
+	}
+	e.validIndexPrefix = 0
+
+	stats.startBlock(e.currentFrame, !e.currentFrame.isLastInFloor)
+
+allTerms:
+	for {
+		// Pop finished blocks
+		for e.currentFrame.nextEnt == e.currentFrame.entCount {
+			stats.endBlock(e.currentFrame)
+			if !e.currentFrame.isLastInFloor {
+				if err = e.currentFrame.loadNextFloorBlock(); err != nil {
+					return nil, err
+				}
+				stats.startBlock(e.currentFrame, true)
+			} else {
+				if e.currentFrame.ord == 0 {
+					break allTerms
+				}
+				lastFP := e.currentFrame.fpOrig
+				e.currentFrame = e.stack[e.currentFrame.ord-1]
+				if lastFP != e.currentFrame.lastSubFP {
+					panic("assert fail")
+				}
+			}
+		}
+
+		for {
+			isSubBlock, err := e.currentFrame.next()
+			if err != nil {
+				return nil, err
+			}
+			if !isSubBlock {
+				stats.term(e.term)
+				break
+			}
+			// Push to new block:
+			if e.currentFrame, err = e.pushFrameAt(nil, e.currentFrame.lastSubFP, len(e.term)); err != nil {
+				return nil, err
+			}
+			e.currentFrame.fpOrig = e.currentFrame.fp
+			// This is a "next" frame -- even if it's
+			// floor'd we must pretend it isn't so we don't
+			// try to scan to the right floor frame:
+			e.currentFrame.isFloor = false
+			if err = e.currentFrame.loadBlock(); err != nil {
+				return nil, err
+			}
+			stats.startBlock(e.currentFrame, !e.currentFrame.isLastInFloor)
+		}
+	}
+
+	stats.finish()
+
+	// Put root frame back:
+	e.currentFrame = e.staticFrame
+	if e.index != nil {
+		arc = e.index.FirstArc(e.arcs[0])
+		// Empty string prefix must have an output in the index!
+		if !arc.IsFinal() {
+			panic("assert fail")
+		}
+	} else {
+		arc = nil
+	}
+	if e.currentFrame, err = e.pushFrame(arc, e.rootCode, 0); err != nil {
+		return nil, err
+	}
+	e.currentFrame.rewind()
+	if err = e.currentFrame.loadBlock(); err != nil {
+		return nil, err
+	}
+	e.validIndexPrefix = 0
+	e.term = e.term[:0]
+
+	return stats, nil
+}
+
 func (e *SegmentTermsEnum) initIndexInput() {
 	if e.in == nil {
 		e.in = e.FieldReader.BlockTreeTermsReader.in.Clone()
@@ -585,11 +700,9 @@ func (e *SegmentTermsEnum) SeekExact(target []byte) (ok bool, err error) {
 		// TODO: reverse vLong byte order for better FST
 		// prefix output sharing
 
-		noOutputs := e.fstOutputs.NoOutput()
-
 		// First compare up to valid seek frames:
 		for targetUpto < targetLimit {
-			cmp = int(e.term[targetUpto] - target[targetUpto])
+			cmp = int(e.term[targetUpto]) - int(target[targetUpto])
 			log.Printf("    cycle targetUpto=%v (vs limit=%v) cmp=%v (targetLabel=%c vs termLabel=%c) arc.output=%v output=%v",
 				targetUpto, targetLimit, cmp, target[targetUpto], e.term[targetUpto], arc.Output, output)
 			if cmp != 0 {
@@ -601,9 +714,7 @@ func (e *SegmentTermsEnum) SeekExact(target []byte) (ok bool, err error) {
 				log.Printf("FAIL: arc.label=%c targetLabel=%c", arc.Label, target[targetUpto])
 				panic("assert fail")
 			}
-			if arc.Output != noOutputs {
-				output = e.fstOutputs.Add(output, arc.Output).([]byte)
-			}
+			output = e.fstOutputs.Add(output, arc.Output).([]byte)
 			if arc.IsFinal() {
 				lastFrame = e.stack[1+lastFrame.ord]
 			}
@@ -622,7 +733,7 @@ func (e *SegmentTermsEnum) SeekExact(target []byte) (ok bool, err error) {
 				targetLimit2 = len(e.term)
 			}
 			for targetUpto < targetLimit2 {
-				cmp = int(e.term[targetUpto] - target[targetUpto])
+				cmp = int(e.term[targetUpto]) - int(target[targetUpto])
 				log.Printf("    cycle2 targetUpto=%v (vs limit=%v) cmp=%v (targetLabel=%c vs termLabel=%c)",
 					targetUpto, targetLimit, cmp, target[targetUpto], e.term[targetUpto])
 				if cmp != 0 {
@@ -704,7 +815,7 @@ func (e *SegmentTermsEnum) SeekExact(target []byte) (ok bool, err error) {
 
 			if !e.currentFrame.hasTerms {
 				e.termExists = false
-				e.term = append(e.term, byte(targetLabel))
+				e.term = append(e.term[:targetUpto], byte(targetLabel))
 				log.Printf("  FAST NOT_FOUND term=%v", brToString(e.term))
 				return false, nil
 			}
@@ -725,15 +836,12 @@ func (e *SegmentTermsEnum) SeekExact(target []byte) (ok bool, err error) {
 		} else {
 			// Follow this arc
 			arc = nextArc
-			e.term[targetUpto] = byte(targetLabel)
+			e.term = append(e.term[:targetUpto], byte(targetLabel))
 			// Aggregate output as we go:
 			if arc.Output == nil {
 				panic("assert fail")
 			}
-			noOutputs := e.fstOutputs.NoOutput()
-			if arc.Output != noOutputs {
-				output = e.fstOutputs.Add(output, arc.Output).([]byte)
-			}
+			output = e.fstOutputs.Add(output, arc.Output).([]byte)
 			log.Printf("    index: follow label=%x arc.output=%v arc.nfo=%v",
 				target[targetUpto], arc.Output, arc.NextFinalOutput)
 			targetUpto++
@@ -776,8 +884,242 @@ func (e *SegmentTermsEnum) SeekExact(target []byte) (ok bool, err error) {
 	}
 }
 
-func (e *SegmentTermsEnum) SeekCeil(text []byte) SeekStatus {
-	panic("not implemented yet")
+func (e *SegmentTermsEnum) SeekCeil(target []byte) SeekStatus {
+	status, err := e.seekCeil(target)
+	if err != nil {
+		panic(err)
+	}
+	return status
+}
+
+func (e *SegmentTermsEnum) seekCeil(target []byte) (status SeekStatus, err error) {
+	if e.index == nil {
+		panic("terms index was not loaded")
+	}
+
+	e.eof = false
+	log.Printf("BTTR.seekCeil seg=%v target=%v:%v current=%v (exists?=%v) validIndexPrefix=%v",
+		e.segment, e.fieldInfo.name, brToString(target), brToString(e.term), e.termExists, e.validIndexPrefix)
+	e.printSeekState()
+
+	var arc *util.Arc
+	var targetUpto int
+	var output []byte
+
+	e.targetBeforeCurrentLength = e.currentFrame.ord
+
+	if e.currentFrame != e.staticFrame {
+		// We are already seek'd; find the common
+		// prefix of new seek term vs current term and
+		// re-use the corresponding seek state.  For
+		// example, if app first seeks to foobar, then
+		// seeks to foobaz, we can re-use the seek state
+		// for the first 5 bytes.
+
+		log.Printf("  re-use current seek state validIndexPrefix=%v", e.validIndexPrefix)
+
+		arc = e.arcs[0]
+		if !arc.IsFinal() {
+			panic("assert fail")
+		}
+		output = arc.Output.([]byte)
+		targetUpto = 0
+
+		lastFrame := e.stack[0]
+		if e.validIndexPrefix > len(e.term) {
+			panic("assert fail")
+		}
+
+		targetLimit := len(target)
+		if e.validIndexPrefix < targetLimit {
+			targetLimit = e.validIndexPrefix
+		}
+
+		cmp := 0
+
+		// TOOD: we should write our vLong backwards (MSB
+		// first) to get better sharing from the FST
+
+		// First compare up to valid seek frames:
+		for targetUpto < targetLimit {
+			cmp = int(e.term[targetUpto]) - int(target[targetUpto])
+			log.Printf("    cycle targetUpto=%v (vs limit=%v) cmp=%v (targetLabel=%c vs termLabel=%c) arc.output=%v output=%v",
+				targetUpto, targetLimit, cmp, target[targetUpto], e.term[targetUpto], arc.Output, output)
+			if cmp != 0 {
+				break
+			}
+			arc = e.arcs[1+targetUpto]
+			if arc.Label != int(target[targetUpto]) {
+				log.Printf("FAIL: arc.label=%c targetLabel=%c", arc.Label, target[targetUpto])
+				panic("assert fail")
+			}
+			// TOOD: we could save the outputs in local
+			// byte[][] instead of making new objs ever
+			// seek; but, often the FST doesn't have any
+			// shared bytes (but this could change if we
+			// reverse vLong byte order)
+			output = e.fstOutputs.Add(output, arc.Output).([]byte)
+			if arc.IsFinal() {
+				lastFrame = e.stack[1+lastFrame.ord]
+			}
+			targetUpto++
+		}
+
+		if cmp == 0 {
+			targetUptoMid := targetUpto
+			// Second compare the rest of the term, but
+			// don't save arc/output/frame:
+			targetLimit2 := len(target)
+			if len(e.term) < targetLimit2 {
+				targetLimit2 = len(e.term)
+			}
+			for targetUpto < targetLimit2 {
+				cmp = int(e.term[targetUpto]) - int(target[targetUpto])
+				log.Printf("    cycle2 targetUpto=%v (vs limit=%v) cmp=%v (targetLabel=%c vs termLabel=%c)",
+					targetUpto, targetLimit, cmp, target[targetUpto], e.term[targetUpto])
+				if cmp != 0 {
+					break
+				}
+				targetUpto++
+			}
+
+			if cmp == 0 {
+				cmp = len(e.term) - len(target)
+			}
+			targetUpto = targetUptoMid
+		}
+
+		if cmp < 0 {
+			// Common case: target term is after current
+			// term, ie, app is seeking multiple terms
+			// in sorted order
+			log.Printf("  target is after current (shares prefixLen=%v); clear frame.scanned ord=%v", targetUpto, lastFrame.ord)
+			e.currentFrame = lastFrame
+		} else if cmp > 0 {
+			// Uncommon case: target term
+			// is before current term; this means we can
+			// keep the currentFrame but we must rewind it
+			// (so we scan from the start)
+			e.targetBeforeCurrentLength = 0
+			log.Printf("  target is before current (shares prefixLen=%v); rewind frame ord=%v", targetUpto, lastFrame.ord)
+			e.currentFrame = lastFrame
+			e.currentFrame.rewind()
+		} else {
+			// Target is exactly the same as current term
+			if len(e.term) != len(target) {
+				panic("assert fail")
+			}
+			if e.termExists {
+				log.Println("  target is same as current; return FOUND")
+				return SEEK_STATUS_FOUND, nil
+			} else {
+				log.Println("  target is same as current but term doesn't exist")
+			}
+		}
+	} else {
+		e.targetBeforeCurrentLength = -1
+		arc = e.index.FirstArc(e.arcs[0])
+
+		// Empty string prefix must have an output (block) in the index!
+		if !arc.IsFinal() || arc.Output == nil {
+			panic("assert fail")
+		}
+
+		log.Println("    no seek state; push root frame")
+
+		output = arc.Output.([]byte)
+
+		e.currentFrame = e.staticFrame
+
+		targetUpto = 0
+		e.currentFrame, err = e.pushFrame(arc, e.fstOutputs.Add(output, arc.NextFinalOutput).([]byte), 0)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	log.Printf("  start index loop targetUpto=%v output=%v currentFrame.ord+1=%v targetBeforeCurrentLength=%v",
+		targetUpto, output, e.currentFrame.ord, e.targetBeforeCurrentLength)
+
+	for targetUpto < len(target) {
+		targetLabel := int(target[targetUpto])
+		nextArc, err := e.index.FindTargetArc(targetLabel, arc, e.getArc(1+targetUpto), e.fstReader)
+		if err != nil {
+			return 0, err
+		}
+		if nextArc == nil {
+			// Index is exhausted
+			log.Printf("    index: index exhausted label=%c %x", targetLabel, targetLabel)
+
+			e.validIndexPrefix = e.currentFrame.prefix
+
+			e.currentFrame.scanToFloorFrame(target)
+
+			if err = e.currentFrame.loadBlock(); err != nil {
+				return 0, err
+			}
+
+			return e.scanToCeil(target)
+		} else {
+			// Follow this arc
+			e.term = append(e.term[:targetUpto], byte(targetLabel))
+			arc = nextArc
+			// Aggregate output as we go:
+			if arc.Output == nil {
+				panic("assert fail")
+			}
+			output = e.fstOutputs.Add(output, arc.Output).([]byte)
+
+			log.Printf("    index: follow label=%x arc.output=%v arc.nfo=%v",
+				target[targetUpto], arc.Output, arc.NextFinalOutput)
+			targetUpto++
+
+			if arc.IsFinal() {
+				log.Println("    arc is final!")
+				e.currentFrame, err = e.pushFrame(arc, e.fstOutputs.Add(output, arc.NextFinalOutput).([]byte), targetUpto)
+				if err != nil {
+					return 0, err
+				}
+				log.Printf("    curFrame.ord=%v hasTerms=%v", e.currentFrame.ord, e.currentFrame.hasTerms)
+			}
+		}
+	}
+
+	e.validIndexPrefix = e.currentFrame.prefix
+
+	e.currentFrame.scanToFloorFrame(target)
+
+	if err = e.currentFrame.loadBlock(); err != nil {
+		return 0, err
+	}
+
+	return e.scanToCeil(target)
+}
+
+// Scans the current frame to the target term; if the frame is
+// exhausted, positions to the next term after it.
+func (e *SegmentTermsEnum) scanToCeil(target []byte) (status SeekStatus, err error) {
+	status, err = e.currentFrame.scanToTerm(target, false)
+	if err != nil {
+		return 0, err
+	}
+	if status == SEEK_STATUS_END {
+		e.term = append(e.term[:0], target...)
+		e.termExists = false
+
+		next, err := e.Next()
+		if err != nil {
+			return 0, err
+		}
+		if next != nil {
+			log.Printf("  return NOT_FOUND term=%v", brToString(e.term))
+			return SEEK_STATUS_NOT_FOUND, nil
+		}
+		log.Println("  return END")
+		return SEEK_STATUS_END, nil
+	}
+	log.Printf("  return %v term=%v", status, brToString(e.term))
+	return status, nil
 }
 
 func (e *SegmentTermsEnum) printSeekState() {
@@ -871,46 +1213,223 @@ func (e *SegmentTermsEnum) printSeekState() {
 }
 
 func (e *SegmentTermsEnum) Next() (buf []byte, err error) {
-	panic("not implemented yet")
-}
-
-func (e *SegmentTermsEnum) Term() []byte {
-	if e.eof {
-		panic("assert fail")
-	}
+	if e.in == nil {
+		// Fresh TermsEnum; seek to first term:
+		var arc *util.Arc
+		if e.index != nil {
+			arc = e.index.FirstArc(e.arcs[0])
+			// Empty string prefix must have an output in the index!
+			if !arc.IsFinal() {
+				panic("assert fail")
+			}
+		}
+		if e.currentFrame, err = e.pushFrame(arc, e.rootCode, 0); err != nil {
+			return nil, err
+		}
+		if err = e.currentFrame.loadBlock(); err != nil {
+			return nil, err
+		}
+	}
+
+	e.targetBeforeCurrentLength = e.currentFrame.ord
+
+	if e.eof {
+		panic("assert fail")
+	}
+	log.Printf("BTTR.next seg=%v term=%v termExists?=%v field=%v termBlockOrd=%v validIndexPrefix=%v",
+		e.segment, brToString(e.term), e.termExists, e.fieldInfo.name, e.currentFrame.state.termBlockOrd, e.validIndexPrefix)
+	e.printSeekState()
+
+	if e.currentFrame == e.staticFrame {
+		// If seek was previously called and the term was
+		// cached, or seek(TermState) was called, usually
+		// caller is just going to pull a D/&PEnum or get
+		// docFreq, etc.  But, if they then call next(),
+		// this method catches up all internal state so next()
+		// works properly:
+		log.Printf("  re-seek to pending term=%v", brToString(e.term))
+		ok, err := e.SeekExact(e.term)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			panic("assert fail")
+		}
+	}
+
+	// Pop finished blocks
+	for e.currentFrame.nextEnt == e.currentFrame.entCount {
+		if !e.currentFrame.isLastInFloor {
+			if err = e.currentFrame.loadNextFloorBlock(); err != nil {
+				return nil, err
+			}
+		} else {
+			log.Println("  pop frame")
+			if e.currentFrame.ord == 0 {
+				log.Println("  return null")
+				e.eof = true
+				e.term = e.term[:0]
+				e.validIndexPrefix = 0
+				e.currentFrame.rewind()
+				e.termExists = false
+				return nil, nil
+			}
+			lastFP := e.currentFrame.fpOrig
+			e.currentFrame = e.stack[e.currentFrame.ord-1]
+
+			if e.currentFrame.nextEnt == -1 || e.currentFrame.lastSubFP != lastFP {
+				// We popped into a frame that's not loaded
+				// yet or not scan'd to the right entry
+				e.currentFrame.scanToFloorFrame(e.term)
+				if err = e.currentFrame.loadBlock(); err != nil {
+					return nil, err
+				}
+				if err = e.currentFrame.scanToSubBlock(lastFP); err != nil {
+					return nil, err
+				}
+			}
+
+			// Note that the seek state (last seek) has been
+			// invalidated beyond this depth
+			if e.currentFrame.prefix < e.validIndexPrefix {
+				e.validIndexPrefix = e.currentFrame.prefix
+			}
+			log.Printf("  reset validIndexPrefix=%v", e.validIndexPrefix)
+		}
+	}
+
+	for {
+		isSubBlock, err := e.currentFrame.next()
+		if err != nil {
+			return nil, err
+		}
+		if !isSubBlock {
+			log.Printf("  return term=%v currentFrame.ord=%v", brToString(e.term), e.currentFrame.ord)
+			return e.term, nil
+		}
+		// Push to new block:
+		log.Println("  push frame")
+		if e.currentFrame, err = e.pushFrameAt(nil, e.currentFrame.lastSubFP, len(e.term)); err != nil {
+			return nil, err
+		}
+		// This is a "next" frame -- even if it's
+		// floor'd we must pretend it isn't so we don't
+		// try to scan to the right floor frame:
+		e.currentFrame.isFloor = false
+		if err = e.currentFrame.loadBlock(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (e *SegmentTermsEnum) Term() []byte {
+	if e.eof {
+		panic("assert fail")
+	}
 	return e.term
 }
 
 func (e *SegmentTermsEnum) DocFreq() int {
-	panic("not implemented yet")
+	if e.eof {
+		panic("assert fail")
+	}
+	log.Printf("BTR.docFreq")
+	err := e.currentFrame.decodeMetaData()
+	if err != nil {
+		panic(err)
+	}
+	log.Printf("  return %v", e.currentFrame.state.docFreq)
+	return e.currentFrame.state.docFreq
 }
 
 func (e *SegmentTermsEnum) TotalTermFreq() int64 {
-	panic("not implemented yet")
+	if e.eof {
+		panic("assert fail")
+	}
+	err := e.currentFrame.decodeMetaData()
+	if err != nil {
+		panic(err)
+	}
+	return e.currentFrame.state.totalTermFreq
 }
 
 func (e *SegmentTermsEnum) DocsByFlags(skipDocs util.Bits, reuse DocsEnum, flags int) DocsEnum {
-	panic("not implemented yet")
+	if e.eof {
+		panic("assert fail")
+	}
+	err := e.currentFrame.decodeMetaData()
+	if err != nil {
+		panic(err)
+	}
+	ans, err := e.postingsReader.Docs(e.fieldInfo, e.currentFrame.state, skipDocs, reuse, flags)
+	if err != nil {
+		panic(err)
+	}
+	return ans
 }
 
 func (e *SegmentTermsEnum) DocsAndPositionsByFlags(skipDocs util.Bits, reuse DocsAndPositionsEnum, flags int) DocsAndPositionsEnum {
-	panic("not implemented yet")
+	if e.fieldInfo.indexOptions < INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS {
+		// Positions were not indexed:
+		return DocsAndPositionsEnum{}
+	}
+
+	if e.eof {
+		panic("assert fail")
+	}
+	err := e.currentFrame.decodeMetaData()
+	if err != nil {
+		panic(err)
+	}
+	ans, err := e.postingsReader.DocsAndPositions(e.fieldInfo, e.currentFrame.state, skipDocs, reuse, flags)
+	if err != nil {
+		panic(err)
+	}
+	return ans
 }
 
 func (e *SegmentTermsEnum) SeekExactFromLast(target []byte, otherState TermState) error {
-	panic("not implemented yet")
+	log.Printf("BTTR.seekExact termState seg=%v target=%v state=%v",
+		e.segment, brToString(target), otherState)
+	e.eof = false
+	if !bytes.Equal(target, e.term) || !e.termExists {
+		if _, ok := otherState.(*intBlockTermState); !ok {
+			panic("assert fail")
+		}
+		e.currentFrame = e.staticFrame
+		e.currentFrame.state.Self.CopyFrom(otherState)
+		e.term = append(e.term[:0], target...)
+		e.currentFrame.metaDataUpto = e.currentFrame.getTermBlockOrd()
+		if e.currentFrame.metaDataUpto <= 0 {
+			panic("assert fail")
+		}
+		e.validIndexPrefix = 0
+	} else {
+		log.Printf("  skip seek: already on target state=%v", e.currentFrame.state)
+	}
+	return nil
 }
 
 func (e *SegmentTermsEnum) TermState() TermState {
-	panic("not implemented yet")
+	if e.eof {
+		panic("assert fail")
+	}
+	err := e.currentFrame.decodeMetaData()
+	if err != nil {
+		panic(err)
+	}
+	ts := e.currentFrame.state.Self.Clone()
+	log.Printf("BTTR.termState seg=%v state=%v", e.segment, ts)
+	return ts
 }
 
+// Block-tree terms dict doesn't record term ordinals.
 func (e *SegmentTermsEnum) SeekExactByPosition(ord int64) error {
-	panic("not implemented yet")
+	return ErrUnsupported
 }
 
-func (e *SegmentTermsEnum) Ord() int64 {
-	panic("not supported!")
+func (e *SegmentTermsEnum) Ord() (int64, error) {
+	return 0, ErrUnsupported
 }
 
 type segmentTermsEnumFrame struct {
@@ -1042,7 +1561,7 @@ func (f *segmentTermsEnumFrame) loadBlock() (err error) {
 		panic("assert fail")
 	}
 	f.isLastInFloor = (code & 1) != 0
-	if f.arc != nil && f.isLastInFloor && f.isFloor {
+	if f.arc != nil && !f.isLastInFloor && !f.isFloor {
 		panic("assert fail")
 	}
 
@@ -1058,11 +1577,11 @@ func (f *segmentTermsEnumFrame) loadBlock() (err error) {
 	if len(f.suffixBytes) < numBytes {
 		f.suffixBytes = make([]byte, numBytes)
 	}
-	err = f.in.ReadBytes(f.suffixBytes)
+	err = f.in.ReadBytes(f.suffixBytes[:numBytes])
 	if err != nil {
 		return err
 	}
-	f.suffixesReader.Reset(f.suffixBytes)
+	f.suffixesReader.Reset(f.suffixBytes[:numBytes])
 
 	if f.arc == nil {
 		log.Printf("    loadBlock (next) fp=%v entCount=%v prefixLen=%v isLastInFloor=%v leaf?=%v",
@@ -1075,16 +1594,16 @@ func (f *segmentTermsEnumFrame) loadBlock() (err error) {
 	// stats
 	numBytes, err = asInt(f.in.ReadVInt())
 	if err != nil {
-		return nil
+		return err
 	}
 	if len(f.statBytes) < numBytes {
 		f.statBytes = make([]byte, numBytes)
 	}
-	err = f.in.ReadBytes(f.statBytes)
+	err = f.in.ReadBytes(f.statBytes[:numBytes])
 	if err != nil {
 		return err
 	}
-	f.statsReader.Reset(f.statBytes)
+	f.statsReader.Reset(f.statBytes[:numBytes])
 	f.metaDataUpto = 0
 
 	f.state.termBlockOrd = 0
@@ -1102,6 +1621,121 @@ func (f *segmentTermsEnumFrame) loadBlock() (err error) {
 	return nil
 }
 
+func (f *segmentTermsEnumFrame) loadNextFloorBlock() error {
+	log.Printf("    loadNextFloorBlock fp=%v fpEnd=%v", f.fp, f.fpEnd)
+	if f.arc != nil && !f.isFloor {
+		panic("assert fail")
+	}
+	f.fp = f.fpEnd
+	f.nextEnt = -1
+	return f.loadBlock()
+}
+
+// Decodes next entry; returns true if it's a sub-block
+func (f *segmentTermsEnumFrame) next() (isSubBlock bool, err error) {
+	if f.isLeafBlock {
+		return f.nextLeaf()
+	}
+	return f.nextNonLeaf()
+}
+
+// Decodes next entry; returns true if it's a sub-block
+func (f *segmentTermsEnumFrame) nextLeaf() (isSubBlock bool, err error) {
+	log.Printf("  frame.next ord=%v nextEnt=%v entCount=%v", f.ord, f.nextEnt, f.entCount)
+	if f.nextEnt == -1 || f.nextEnt >= f.entCount {
+		panic("assert fail")
+	}
+	f.nextEnt++
+	if f.suffix, err = asInt(f.suffixesReader.ReadVInt()); err != nil {
+		return false, err
+	}
+	f.startBytePos = f.suffixesReader.Pos
+	f.setTermLength(f.prefix + f.suffix)
+	if err = f.suffixesReader.ReadBytes(f.term[f.prefix:]); err != nil {
+		return false, err
+	}
+	// A normal term
+	f.termExists = true
+	return false, nil
+}
+
+func (f *segmentTermsEnumFrame) nextNonLeaf() (isSubBlock bool, err error) {
+	log.Printf("  frame.next ord=%v nextEnt=%v entCount=%v", f.ord, f.nextEnt, f.entCount)
+	if f.nextEnt == -1 || f.nextEnt >= f.entCount {
+		panic("assert fail")
+	}
+	f.nextEnt++
+	code, err := asInt(f.suffixesReader.ReadVInt())
+	if err != nil {
+		return false, err
+	}
+	f.suffix = int(uint(code) >> 1)
+	f.startBytePos = f.suffixesReader.Pos
+	f.setTermLength(f.prefix + f.suffix)
+	if err = f.suffixesReader.ReadBytes(f.term[f.prefix:]); err != nil {
+		return false, err
+	}
+	if (code & 1) == 0 {
+		// A normal term
+		f.termExists = true
+		f.subCode = 0
+		f.state.termBlockOrd++
+		return false, nil
+	}
+	// A sub-block; make sub-FP absolute:
+	f.termExists = false
+	subCode, err := f.suffixesReader.ReadVLong()
+	if err != nil {
+		return false, err
+	}
+	f.subCode = int(subCode)
+	f.lastSubFP = f.fp - subCode
+	log.Printf("    lastSubFP=%v", f.lastSubFP)
+	return true, nil
+}
+
+func (f *segmentTermsEnumFrame) scanToSubBlock(subFP int64) error {
+	if f.isLeafBlock {
+		panic("assert fail")
+	}
+	log.Printf("  scanToSubBlock fp=%v subFP=%v entCount=%v lastSubFP=%v", f.fp, subFP, f.entCount, f.lastSubFP)
+	if f.lastSubFP == subFP {
+		log.Println("    already positioned")
+		return nil
+	}
+	if subFP >= f.fp {
+		panic("assert fail")
+	}
+	targetSubCode := f.fp - subFP
+	log.Printf("    targetSubCode=%v", targetSubCode)
+	for {
+		if f.nextEnt >= f.entCount {
+			panic("assert fail")
+		}
+		f.nextEnt++
+		code, err := asInt(f.suffixesReader.ReadVInt())
+		if err != nil {
+			return err
+		}
+		f.suffixesReader.SkipBytes(int(uint(code) >> 1))
+		log.Printf("    %v (of %v) ent isSubBlock=%v", f.nextEnt, f.entCount, (code&1) == 1)
+		if (code & 1) != 0 {
+			subCode, err := f.suffixesReader.ReadVLong()
+			if err != nil {
+				return err
+			}
+			log.Printf("      subCode=%v", subCode)
+			if targetSubCode == subCode {
+				log.Println("        match!")
+				f.lastSubFP = subFP
+				return nil
+			}
+		} else {
+			f.state.termBlockOrd++
+		}
+	}
+}
+
 func (f *segmentTermsEnumFrame) rewind() {
 	// Force reload:
 	f.fp = f.fpOrig
@@ -1137,53 +1771,98 @@ func (f *segmentTermsEnumFrame) scanToFloorFrame(target []byte) {
 		panic("assert fail")
 	}
 
-	panic("not implemented yet")
-	// long newFP;
-	//  while (true) {
-	//    final long code = floorDataReader.readVLong();
-	//    newFP = fpOrig + (code >>> 1);
-	//    hasTerms = (code & 1) != 0;
-	//    // if (DEBUG) {
-	//    //   System.out.println("      label=" + toHex(nextFloorLabel) + " fp=" + newFP + " hasTerms?=" + hasTerms + " numFollowFloor=" + numFollowFloorBlocks);
-	//    // }
-
-	//    isLastInFloor = numFollowFloorBlocks == 1;
-	//    numFollowFloorBlocks--;
-
-	//    if (isLastInFloor) {
-	//      nextFloorLabel = 256;
-	//      // if (DEBUG) {
-	//      //   System.out.println("        stop!  last block nextFloorLabel=" + toHex(nextFloorLabel));
-	//      // }
-	//      break;
-	//    } else {
-	//      nextFloorLabel = floorDataReader.readByte() & 0xff;
-	//      if (targetLabel < nextFloorLabel) {
-	//        // if (DEBUG) {
-	//        //   System.out.println("        stop!  nextFloorLabel=" + toHex(nextFloorLabel));
-	//        // }
-	//        break;
-	//      }
-	//    }
-	//  }
-
-	//  if (newFP != fp) {
-	//    // Force re-load of the block:
-	//    // if (DEBUG) {
-	//    //   System.out.println("      force switch to fp=" + newFP + " oldFP=" + fp);
-	//    // }
-	//    nextEnt = -1;
-	//    fp = newFP;
-	//  } else {
-	//    // if (DEBUG) {
-	//    //   System.out.println("      stay on same fp=" + newFP);
-	//    // }
-	//  }
+	var newFP int64
+	for {
+		code, _ := f.floorDataReader.ReadVLong()
+		newFP = f.fpOrig + int64(uint64(code)>>1)
+		f.hasTerms = (code & 1) != 0
+		log.Printf("      label=%x fp=%v hasTerms?=%v numFollowFloor=%v",
+			f.nextFloorLabel, newFP, f.hasTerms, f.numFollowFloorBlocks)
+
+		f.isLastInFloor = f.numFollowFloorBlocks == 1
+		f.numFollowFloorBlocks--
+
+		if f.isLastInFloor {
+			f.nextFloorLabel = 256
+			log.Printf("        stop!  last block nextFloorLabel=%x", f.nextFloorLabel)
+			break
+		} else {
+			b, _ := f.floorDataReader.ReadByte()
+			f.nextFloorLabel = int(b)
+			if targetLabel < f.nextFloorLabel {
+				log.Printf("        stop!  nextFloorLabel=%x", f.nextFloorLabel)
+				break
+			}
+		}
+	}
+
+	if newFP != f.fp {
+		// Force re-load of the block:
+		log.Printf("      force switch to fp=%v oldFP=%v", newFP, f.fp)
+		f.nextEnt = -1
+		f.fp = newFP
+	} else {
+		log.Printf("      stay on same fp=%v", newFP)
+	}
+}
+
+func (f *segmentTermsEnumFrame) decodeMetaData() error {
+	log.Printf("BTTR.decodeMetadata seg=%v mdUpto=%v vs termBlockOrd=%v",
+		f.segment, f.metaDataUpto, f.state.termBlockOrd)
+
+	// lazily catch up on metadata decode:
+	limit := f.getTermBlockOrd()
+	if limit <= 0 {
+		panic("assert fail")
+	}
+
+	// We must set/incr state.termCount because
+	// postings impl can look at this
+	f.state.termBlockOrd = f.metaDataUpto
+
+	// TODO: better API would be "jump straight to term=N"???
+	for f.metaDataUpto < limit {
+		// TODO: we could make "tiers" of metadata, ie,
+		// decode docFreq/totalTF but don't decode postings
+		// metadata; this way caller could get
+		// docFreq/totalTF w/o paying decode cost for
+		// postings
+
+		// TODO: if docFreq were bulk decoded we could
+		// just skipN here:
+		docFreq, err := asInt(f.statsReader.ReadVInt())
+		if err != nil {
+			return err
+		}
+		f.state.docFreq = docFreq
+		log.Printf("    dF=%v", f.state.docFreq)
+		if f.fieldInfo.indexOptions != INDEX_OPT_DOCS_ONLY {
+			n, err := f.statsReader.ReadVLong()
+			if err != nil {
+				return err
+			}
+			f.state.totalTermFreq = int64(f.state.docFreq) + n
+			log.Printf("    totTF=%v", f.state.totalTermFreq)
+		}
+
+		err = f.postingsReader.NextTerm(f.fieldInfo, f.state)
+		if err != nil {
+			return err
+		}
+		f.metaDataUpto++
+		f.state.termBlockOrd++
+	}
+	return nil
 }
 
 // Used only by assert
 func (f *segmentTermsEnumFrame) prefixMatches(target []byte) bool {
-	panic("not implemented yet")
+	for bytePos := 0; bytePos < f.prefix; bytePos++ {
+		if target[bytePos] != f.term[bytePos] {
+			return false
+		}
+	}
+	return true
 }
 
 // NOTE: sets startBytePos/suffix as a side effect
@@ -1246,7 +1925,7 @@ func (f *segmentTermsEnumFrame) scanToTermLeaf(target []byte, exactOnly bool) (s
 			var cmp int
 			var stop bool
 			if targetPos < targetLimit {
-				cmp = int(f.suffixBytes[bytePos] - target[targetPos])
+				cmp = int(f.suffixBytes[bytePos]) - int(target[targetPos])
 				bytePos++
 				targetPos++
 				stop = false
@@ -1271,9 +1950,9 @@ func (f *segmentTermsEnumFrame) scanToTermLeaf(target []byte, exactOnly bool) (s
 				}
 				break
 			} else if cmp > 0 {
-				// // Done!  Current entry is after target --
-				//     // return NOT_FOUND:
-				//     fillTerm();
+				// Done!  Current entry is after target --
+				// return NOT_FOUND:
+				f.fillTerm()
 
 				//     if (!exactOnly && !termExists) {
 				//       // We are on a sub-block, and caller wants
@@ -1334,18 +2013,178 @@ func (f *segmentTermsEnumFrame) scanToTermLeaf(target []byte, exactOnly bool) (s
 // Target's prefix matches this block's prefix; we
 // scan the entries check if the suffix matches.
 func (f *segmentTermsEnumFrame) scanToTermNonLeaf(target []byte, exactOnly bool) (status SeekStatus, err error) {
-	panic("not implemented yet")
+	log.Printf("    scanToTermNonLeaf: block fp=%v prefix=%v nextEnt=%v (of %v) target=%v term=%v",
+		f.fp, f.prefix, f.nextEnt, f.entCount, brToString(target), brToString(f.term))
+	if f.nextEnt == -1 {
+		panic("assert fail")
+	}
+
+	if f.nextEnt == f.entCount {
+		if exactOnly {
+			f.fillTerm()
+			f.termExists = f.subCode == 0
+		}
+		return SEEK_STATUS_END, nil
+	}
+
+	if !f.prefixMatches(target) {
+		panic("assert fail")
+	}
+
+	// Loop over each entry (term or sub-block) in this block:
+nextTerm:
+	for {
+		f.nextEnt++
+
+		code, err := asInt(f.suffixesReader.ReadVInt())
+		if err != nil {
+			return 0, err
+		}
+		f.suffix = int(uint(code) >> 1)
+		log.Printf("      cycle: sub-block?=%v %v (of %v) suffix=%v", (code&1) == 1,
+			f.nextEnt-1, f.entCount, brToString(f.suffixBytes[f.suffixesReader.Pos:f.suffixesReader.Pos+f.suffix]))
+
+		f.termExists = (code & 1) == 0
+		termLen := f.prefix + f.suffix
+		f.startBytePos = f.suffixesReader.Pos
+		f.suffixesReader.SkipBytes(f.suffix)
+		if f.termExists {
+			f.state.termBlockOrd++
+			f.subCode = 0
+		} else {
+			subCode, err := f.suffixesReader.ReadVLong()
+			if err != nil {
+				return 0, err
+			}
+			f.subCode = int(subCode)
+			f.lastSubFP = f.fp - subCode
+		}
+
+		targetLimit := termLen
+		if len(target) < termLen {
+			targetLimit = len(target)
+		}
+		targetPos := f.prefix
+
+		// Loop over bytes in the suffix, comparing to
+		// the target
+		bytePos := f.startBytePos
+		for {
+			var cmp int
+			var stop bool
+			if targetPos < targetLimit {
+				cmp = int(f.suffixBytes[bytePos]) - int(target[targetPos])
+				bytePos++
+				targetPos++
+				stop = false
+			} else {
+				if targetPos != targetLimit {
+					panic("assert fail")
+				}
+				cmp = termLen - len(target)
+				stop = true
+			}
+
+			if cmp < 0 {
+				// Current entry is still before the target;
+				// keep scanning
+
+				if f.nextEnt == f.entCount {
+					if exactOnly {
+						f.fillTerm()
+					}
+					// We are done scanning this block
+					break nextTerm
+				}
+				continue nextTerm
+			} else if cmp > 0 {
+				// Done!  Current entry is after target --
+				// return NOT_FOUND:
+				f.fillTerm()
+
+				if !exactOnly && !f.termExists {
+					// We are on a sub-block, and caller wants
+					// us to position to the next term after
+					// the target, so we must recurse into the
+					// sub-frame(s):
+					if f.currentFrame, err = f.pushFrameAt(nil, f.currentFrame.lastSubFP, termLen); err != nil {
+						return 0, err
+					}
+					if err = f.currentFrame.loadBlock(); err != nil {
+						return 0, err
+					}
+					for {
+						isSubBlock, err := f.currentFrame.next()
+						if err != nil {
+							return 0, err
+						}
+						if !isSubBlock {
+							break
+						}
+						if f.currentFrame, err = f.pushFrameAt(nil, f.currentFrame.lastSubFP, len(f.term)); err != nil {
+							return 0, err
+						}
+						if err = f.currentFrame.loadBlock(); err != nil {
+							return 0, err
+						}
+					}
+				}
+
+				log.Println("        not found")
+				return SEEK_STATUS_NOT_FOUND, nil
+			} else if stop {
+				// Exact match!
+
+				// This cannot be a sub-block because we
+				// would have followed the index to this
+				// sub-block from the start:
+
+				if !f.termExists {
+					panic("assert fail")
+				}
+				f.fillTerm()
+				log.Println("        found!")
+				return SEEK_STATUS_FOUND, nil
+			}
+		}
+	}
+
+	// It is possible (and OK) that terms index pointed us
+	// at this block, but, we scanned the entire block and
+	// did not find the term to position to.  This happens
+	// when the target is after the last term in the block
+	// (but, before the next term in the index).  EG
+	// target could be foozzz, and terms index pointed us
+	// to the foo* block, but the last term in this block
+	// was fooz (and, eg, first term in the next block will
+	// bee fop).
+	log.Println("      block end")
+	if exactOnly {
+		f.fillTerm()
+	}
+
+	// TODO: not consistent that in the
+	// not-exact case we don't next() into the next
+	// frame here
+	return SEEK_STATUS_END, nil
 }
 
 func (f *segmentTermsEnumFrame) fillTerm() {
-	termLength := f.prefix + f.suffix
-	if len(f.term) < termLength {
+	f.setTermLength(f.prefix + f.suffix)
+	copy(f.term[f.prefix:f.prefix+f.suffix], f.suffixBytes[f.startBytePos:])
+}
+
+// Resizes current term, keeping the bytes beyond its current
+// length, since frames share the prefix already held there.
+func (e *SegmentTermsEnum) setTermLength(length int) {
+	if cap(e.term) < length {
 		// TODO over-allocate
-		next := make([]byte, termLength)
-		copy(next, f.term)
-		f.term = next
+		next := make([]byte, length)
+		copy(next, e.term[:cap(e.term)])
+		e.term = next
+	} else {
+		e.term = e.term[:length]
 	}
-	copy(f.term[f.prefix:f.prefix+f.suffix], f.suffixBytes[f.startBytePos:])
 }
 
 // for debugging
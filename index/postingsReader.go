@@ -2,6 +2,7 @@ package index
 
 import (
 	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
 	"io"
 )
 
@@ -25,9 +26,14 @@ type PostingsReaderBase interface {
 	Init(termsIn store.IndexInput) error
 	// Return a newly created empty BlockTermState
 	NewTermState() *BlockTermState
-	// nextTerm(fieldInfo FieldInfo, state BlockTermState)
-	// docs(fieldInfo FieldInfo, state BlockTermState, skipDocs util.Bits, reuse DocsEnum, flags int)
-	// docsAndPositions(fieldInfo FieldInfo, state BlockTermState, skipDocs util.Bits)
+	/* Actually decode metadata for next term */
+	NextTerm(fieldInfo FieldInfo, state *BlockTermState) error
+	/* Must fully consume state, since after this call that
+	TermState may be reused. */
+	Docs(fieldInfo FieldInfo, state *BlockTermState, skipDocs util.Bits, reuse DocsEnum, flags int) (DocsEnum, error)
+	/* Must fully consume state, since after this call that
+	TermState may be reused. */
+	DocsAndPositions(fieldInfo FieldInfo, state *BlockTermState, skipDocs util.Bits, reuse DocsAndPositionsEnum, flags int) (DocsAndPositionsEnum, error)
 	/** Returns approximate RAM bytes used */
 	// RamBytesUsed() int64
 	/** Reads data for all terms in the next block; this
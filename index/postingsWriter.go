@@ -0,0 +1,297 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"io"
+	"sort"
+)
+
+// codecs/FieldsConsumer.java
+
+/*
+Abstract API that consumes terms, doc, freq, prox, offset and
+payloads postings. Concrete implementations of this actually do
+"something" with the postings (write it into the index in a specific
+format).
+
+The lifecycle is:
+
+1. FieldsConsumer is created by the postings format.
+2. For each field, AddField() is called, returning a TermsConsumer
+for the field.
+3. After all fields are added, the consumer is closed.
+*/
+type FieldsConsumer interface {
+	io.Closer
+	// Add a new field
+	AddField(field FieldInfo) (TermsConsumer, error)
+}
+
+/*
+Merges the postings of the given fields, read from the segments being
+merged, field by field in name order.
+*/
+func mergeFields(consumer FieldsConsumer, mergeState *MergeState, fields Fields) error {
+	for _, name := range indexedFieldNames(mergeState.fieldInfos) {
+		terms := fields.Terms(name)
+		if terms == nil {
+			continue
+		}
+		info := mergeState.fieldInfos.byName[name]
+		termsConsumer, err := consumer.AddField(info)
+		if err != nil {
+			return err
+		}
+		if err = mergeTerms(termsConsumer, mergeState, info.indexOptions, terms.Iterator(nil)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the names of the indexed fields, in sorted order.
+func indexedFieldNames(fieldInfos FieldInfos) []string {
+	var names []string
+	for _, fi := range fieldInfos.values {
+		if fi.indexed {
+			names = append(names, fi.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// codecs/TermsConsumer.java
+
+/*
+Abstract API that consumes terms for an individual field.
+
+The lifecycle is:
+
+1. TermsConsumer is returned for each field by FieldsConsumer.AddField().
+2. TermsConsumer returns a PostingsConsumer for each term in
+StartTerm().
+3. When the producer (e.g. IndexWriter) is done adding documents for
+the term, it calls FinishTerm(), passing in the accumulated term
+statistics.
+4. Producer calls Finish() with the accumulated collection
+statistics when it is finished adding terms to the field.
+*/
+type TermsConsumer interface {
+	/*
+		Starts a new term in this field; this may be called with no
+		corresponding call to finish if the term had no docs.
+	*/
+	StartTerm(text []byte) (PostingsConsumer, error)
+	// Finishes the current term; numDocs must be > 0.
+	FinishTerm(text []byte, stats TermStats) error
+	// Called when we are done adding terms to this field.
+	Finish(sumTotalTermFreq, sumDocFreq int64, docCount int) error
+}
+
+/*
+Merges the terms of a field from the segments being merged, which
+termsEnum iterates. Documents are renumbered, and deleted documents
+dropped, through the doc maps of mergeState.
+*/
+func mergeTerms(consumer TermsConsumer, mergeState *MergeState,
+	indexOptions IndexOptions, termsEnum TermsEnum) error {
+	flags := 0
+	switch indexOptions {
+	case INDEX_OPT_DOCS_AND_FREQS:
+		flags = DOCS_ENUM_FLAG_FREQS
+	case INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS:
+		flags = DOCS_POSITIONS_ENUM_FLAG_PAYLOADS
+	case INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS:
+		flags = DOCS_POSITIONS_ENUM_FLAG_PAYLOADS | DOCS_POSITIONS_ENUM_FLAG_OFF_SETS
+	}
+
+	var docsEnumIn DocsEnum
+	var postingsEnumIn DocsAndPositionsEnum
+	docsEnum := newMappingMultiDocsEnum(mergeState)
+	postingsEnum := newMappingMultiDocsAndPositionsEnum(mergeState)
+	visitedDocs := util.NewFixedBitSet(int(mergeState.segmentInfo.docCount))
+	var sumTotalTermFreq, sumDocFreq int64
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			return err
+		}
+		if term == nil {
+			break
+		}
+		// We can pass nil for liveDocs, because the mapping enum will
+		// skip the non-live docs:
+		var postings DocIdSetIterator
+		if indexOptions < INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS {
+			docsEnumIn = termsEnum.DocsByFlags(nil, docsEnumIn, flags)
+			if docsEnumIn.DocIdSetIterator == nil {
+				continue
+			}
+			postings = docsEnum.reset(docsEnumIn.DocIdSetIterator.(*MultiDocsEnum))
+		} else {
+			postingsEnumIn = termsEnum.DocsAndPositionsByFlags(nil, postingsEnumIn, flags)
+			if postingsEnumIn.DocsAndPositionsIterator == nil {
+				continue
+			}
+			postings = postingsEnum.reset(postingsEnumIn.DocsAndPositionsIterator.(*MultiDocsAndPositionsEnum))
+		}
+		postingsConsumer, err := consumer.StartTerm(term)
+		if err != nil {
+			return err
+		}
+		stats, err := mergePostings(postingsConsumer, indexOptions, postings, visitedDocs)
+		if err != nil {
+			return err
+		}
+		if stats.DocFreq > 0 {
+			if err = consumer.FinishTerm(term, stats); err != nil {
+				return err
+			}
+			sumTotalTermFreq += stats.TotalTermFreq
+			sumDocFreq += int64(stats.DocFreq)
+		}
+	}
+
+	docCount := visitedDocs.Cardinality()
+	if indexOptions == INDEX_OPT_DOCS_ONLY {
+		sumTotalTermFreq = -1
+	}
+	return consumer.Finish(sumTotalTermFreq, sumDocFreq, docCount)
+}
+
+// codecs/PostingsConsumer.java
+
+/*
+Abstract API that consumes postings for an individual term.
+
+The lifecycle is:
+
+1. PostingsConsumer is returned for each term by TermsConsumer.StartTerm().
+2. StartDoc() is called for each document where the term occurs,
+specifying id and term frequency for that document.
+3. If positions are enabled for the field, then AddPosition() will be
+called for each term occurrence.
+4. FinishDoc() is called when the producer is done adding positions
+to the document.
+*/
+type PostingsConsumer interface {
+	/*
+		Adds a new doc in this term. freq will be -1 when term
+		frequencies are omitted for the field.
+	*/
+	StartDoc(docId, freq int) error
+	/*
+		Add a new position & payload, and start/end offset. A nil or
+		empty payload means no payload. The payload may be reused by the
+		caller once this returns. startOffset and endOffset will be -1
+		when offsets are not indexed.
+	*/
+	AddPosition(position int, payload []byte, startOffset, endOffset int) error
+	// Called when we are done adding positions & payloads for each doc.
+	FinishDoc() error
+}
+
+/*
+Merges the postings of a term, which postings iterates with the
+merged docIDs, marking the documents it visits in visitedDocs. If
+positions are indexed, postings must be a DocsAndPositionsIterator.
+*/
+func mergePostings(consumer PostingsConsumer, indexOptions IndexOptions,
+	postings DocIdSetIterator, visitedDocs *util.FixedBitSet) (stats TermStats, err error) {
+	positions, _ := postings.(DocsAndPositionsIterator)
+	for {
+		doc, more := postings.NextDoc()
+		if !more || doc == NO_MORE_DOCS {
+			break
+		}
+		visitedDocs.Set(doc)
+		if indexOptions == INDEX_OPT_DOCS_ONLY {
+			if err = consumer.StartDoc(doc, -1); err != nil {
+				return stats, err
+			}
+		} else {
+			freq := postings.Freq()
+			if err = consumer.StartDoc(doc, freq); err != nil {
+				return stats, err
+			}
+			stats.TotalTermFreq += int64(freq)
+			if indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS {
+				if err = mergePositions(consumer, indexOptions, positions, freq); err != nil {
+					return stats, err
+				}
+			}
+		}
+		if err = consumer.FinishDoc(); err != nil {
+			return stats, err
+		}
+		stats.DocFreq++
+	}
+	if indexOptions == INDEX_OPT_DOCS_ONLY {
+		stats.TotalTermFreq = -1
+	}
+	return stats, nil
+}
+
+// Adds the freq positions of the current doc of postings, with their
+// payloads, and their offsets if indexed.
+func mergePositions(consumer PostingsConsumer, indexOptions IndexOptions,
+	postings DocsAndPositionsIterator, freq int) error {
+	for i := 0; i < freq; i++ {
+		position := postings.NextPosition()
+		startOffset, endOffset := -1, -1
+		if indexOptions == INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS {
+			startOffset, endOffset = postings.StartOffset(), postings.EndOffset()
+		}
+		if err := consumer.AddPosition(position, postings.Payload(), startOffset, endOffset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// codecs/TermStats.java
+
+// Holder for per-term statistics.
+type TermStats struct {
+	// How many documents have at least one occurrence of this term.
+	DocFreq int
+	/*
+		Total number of times this term occurs across all documents in
+		the field.
+	*/
+	TotalTermFreq int64
+}
+
+// codecs/PostingsWriterBase.java
+
+/*
+Extension of PostingsConsumer to support pluggable term dictionaries.
+
+This class contains additional hooks to interact with the provided
+term dictionaries such as BlockTreeTermsWriter. If you want to re-use
+an existing implementation and are only interested in customizing the
+format of the postings list, extend this class instead.
+*/
+type PostingsWriterBase interface {
+	PostingsConsumer
+	io.Closer
+	/*
+		Called once after startup, before any terms have been added.
+		Implementations typically write a header to the provided termsOut.
+	*/
+	Start(termsOut store.IndexOutput) error
+	// Start a new term. Note that a matching call to FinishTerm() is done, only if the term has at least one document.
+	StartTerm() error
+	/*
+		Flush count terms starting at start "backwards", as a block.
+		start is a negative offset from the end of the terms stack, ie
+		bigger start means further back in the stack.
+	*/
+	FlushTermsBlock(start, count int) error
+	// Finishes the current term. The provided TermStats contains the term's summary statistics.
+	FinishTerm(stats TermStats) error
+	// Called when the writing switches to another field.
+	SetField(fieldInfo FieldInfo)
+}
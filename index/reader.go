@@ -9,16 +9,100 @@ import (
 	"sync/atomic"
 )
 
+/*
+A custom listener that's invoked when the IndexReader is closed.
+*/
+type ReaderClosedListener interface {
+	OnClose(r IndexReader)
+}
+
 type IndexReader interface {
 	io.Closer
+	incRef()
 	decRef() error
 	ensureOpen()
 	registerParentReader(r IndexReader)
+	markClosedByChild()
+	/*
+		Retrieve term vectors for this document, or nil if term vectors
+		were not indexed. The returned Fields instance acts like a
+		single-document inverted index (the docID will be 0).
+	*/
+	TermVectors(docID int) (fs Fields, err error)
+	/*
+		Expert: visits the fields of a stored document, for custom
+		processing/loading of each field.
+	*/
+	Document(docID int, visitor StoredFieldVisitor) error
 	NumDocs() int
 	MaxDoc() int
+	/*
+		Returns the number of documents containing the term. This method
+		returns 0 if the term or field does not exist.
+	*/
+	DocFreq(term Term) (n int, err error)
+	/*
+		Returns the total number of occurrences of term across all
+		documents (the sum of the freq() for each doc that has this
+		term). This will be -1 if the codec doesn't support this measure.
+	*/
+	TotalTermFreq(term Term) (n int64, err error)
+	/*
+		Returns the sum of DocFreq for all terms in this field, or -1 if
+		this measure isn't stored by the codec.
+	*/
+	SumDocFreq(field string) (n int64, err error)
+	/*
+		Returns the number of documents that have at least one term for
+		this field, or -1 if this measure isn't stored by the codec.
+	*/
+	DocCount(field string) (n int, err error)
+	/*
+		Returns the sum of TotalTermFreq for all terms in this field, or
+		-1 if this measure isn't stored by the codec (or if this field
+		omits term freq and positions).
+	*/
+	SumTotalTermFreq(field string) (n int64, err error)
 	doClose() error
 	Context() IndexReaderContext
 	Leaves() []AtomicReaderContext
+	/*
+		Expert: adds a ReaderClosedListener. The provided listener will
+		be invoked when this reader is closed.
+	*/
+	AddReaderClosedListener(listener ReaderClosedListener)
+	// Expert: remove a previously added ReaderClosedListener.
+	RemoveReaderClosedListener(listener ReaderClosedListener)
+	/*
+		Expert: Returns a key for this IndexReader, so FieldCache,
+		CachingWrapperFilter, etc. can find it in their caches. The key
+		is the same for the readers sharing the same core, e.g. the
+		SegmentReaders of a segment whose deletions changed, so the
+		cached values, which don't depend on the deletions, are shared.
+		By default the reader itself is the key.
+	*/
+	CoreCacheKey() interface{}
+	/*
+		Expert: increments the refCount of this IndexReader instance.
+		Panics if the reader is closed already.
+	*/
+	IncRef()
+	/*
+		Expert: increments the refCount of this IndexReader instance
+		only if the IndexReader has not been closed yet and returns true
+		iff the refCount was successfully incremented, otherwise false.
+		If this method returns false the reader is either already closed
+		or is currently being closed. Either way this reader instance
+		shouldn't be used by an application unless true is returned.
+	*/
+	TryIncRef() bool
+	/*
+		Expert: decreases the refCount of this IndexReader instance. If
+		the refCount drops to 0, then this reader is closed.
+	*/
+	DecRef() error
+	// Expert: returns the current refCount for this reader.
+	RefCount() int
 }
 
 type IndexReaderImpl struct {
@@ -29,6 +113,9 @@ type IndexReaderImpl struct {
 	refCount          int32 // synchronized
 	parentReaders     map[IndexReader]bool
 	parentReadersLock sync.RWMutex
+
+	readerClosedListeners     []ReaderClosedListener
+	readerClosedListenersLock sync.Mutex
 }
 
 func newIndexReader(self IndexReader) *IndexReaderImpl {
@@ -39,6 +126,41 @@ func newIndexReader(self IndexReader) *IndexReaderImpl {
 	}
 }
 
+/*
+Expert: adds a ReaderClosedListener. The provided listener will be
+invoked when this reader is closed.
+*/
+func (r *IndexReaderImpl) AddReaderClosedListener(listener ReaderClosedListener) {
+	r.ensureOpen()
+	r.readerClosedListenersLock.Lock()
+	defer r.readerClosedListenersLock.Unlock()
+	r.readerClosedListeners = append(r.readerClosedListeners, listener)
+}
+
+// Expert: remove a previously added ReaderClosedListener.
+func (r *IndexReaderImpl) RemoveReaderClosedListener(listener ReaderClosedListener) {
+	r.readerClosedListenersLock.Lock()
+	defer r.readerClosedListenersLock.Unlock()
+	for i, v := range r.readerClosedListeners {
+		if v == listener {
+			r.readerClosedListeners = append(r.readerClosedListeners[:i], r.readerClosedListeners[i+1:]...)
+			break
+		}
+	}
+}
+
+/*
+Expert: increments the refCount of this IndexReader instance.
+RefCounts are used to determine when a reader can be closed safely,
+i.e. as soon as there are no more references. Be sure to always call
+a corresponding decRef(), in a finally clause; otherwise the reader
+may never be closed.
+*/
+func (r *IndexReaderImpl) incRef() {
+	r.ensureOpen()
+	atomic.AddInt32(&r.refCount, 1)
+}
+
 func (r *IndexReaderImpl) decRef() error {
 	// only check refcount here (don't call ensureOpen()), so we can
 	// still close the reader if it was made invalid by a child:
@@ -55,7 +177,9 @@ func (r *IndexReaderImpl) decRef() error {
 				atomic.AddInt32(&r.refCount, 1)
 			}
 		}()
-		r.doClose()
+		if err := r.doClose(); err != nil {
+			return err
+		}
 		success = true
 		r.reportCloseToParentReaders()
 		r.notifyReaderClosedListeners()
@@ -66,6 +190,32 @@ func (r *IndexReaderImpl) decRef() error {
 	return nil
 }
 
+func (r *IndexReaderImpl) IncRef() {
+	r.incRef()
+}
+
+func (r *IndexReaderImpl) TryIncRef() bool {
+	for {
+		count := atomic.LoadInt32(&r.refCount)
+		if count <= 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&r.refCount, count, count+1) {
+			return true
+		}
+	}
+}
+
+func (r *IndexReaderImpl) DecRef() error {
+	return r.decRef()
+}
+
+func (r *IndexReaderImpl) RefCount() int {
+	// NOTE: don't ensureOpen, so that callers can see refCount is 0
+	// (reader is closed)
+	return int(atomic.LoadInt32(&r.refCount))
+}
+
 func (r *IndexReaderImpl) ensureOpen() {
 	if atomic.LoadInt32(&r.refCount) <= 0 {
 		panic("this IndexReader is closed")
@@ -85,23 +235,30 @@ func (r *IndexReaderImpl) registerParentReader(reader IndexReader) {
 }
 
 func (r *IndexReaderImpl) notifyReaderClosedListeners() {
-	panic("not implemented yet")
+	r.readerClosedListenersLock.Lock()
+	defer r.readerClosedListenersLock.Unlock()
+	for _, listener := range r.readerClosedListeners {
+		listener.OnClose(r.IndexReader)
+	}
 }
 
 func (r *IndexReaderImpl) reportCloseToParentReaders() {
 	r.parentReadersLock.Lock()
 	defer r.parentReadersLock.Unlock()
 	for parent, _ := range r.parentReaders {
-		p := parent.(*IndexReaderImpl)
-		p.closedByChild = true
-		// cross memory barrier by a fake write:
-		// FIXME do we need it in Go?
-		atomic.AddInt32(&p.refCount, 0)
-		// recurse:
-		p.reportCloseToParentReaders()
+		parent.markClosedByChild()
 	}
 }
 
+func (r *IndexReaderImpl) markClosedByChild() {
+	r.closedByChild = true
+	// cross memory barrier by a fake write:
+	// FIXME do we need it in Go?
+	atomic.AddInt32(&r.refCount, 0)
+	// recurse:
+	r.reportCloseToParentReaders()
+}
+
 func (r *IndexReaderImpl) Close() error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -116,10 +273,16 @@ func (r *IndexReaderImpl) Leaves() []AtomicReaderContext {
 	return r.Context().Leaves()
 }
 
+func (r *IndexReaderImpl) CoreCacheKey() interface{} {
+	// Don't call ensureOpen since FC calls this (to evict) on close
+	return r.IndexReader
+}
+
 type IndexReaderContext interface {
 	Reader() IndexReader
 	Leaves() []AtomicReaderContext
 	Children() []IndexReaderContext
+	parentContext() *CompositeReaderContext
 }
 
 type IndexReaderContextImpl struct {
@@ -137,10 +300,32 @@ func newIndexReaderContext(parent *CompositeReaderContext, ordInParent, docBaseI
 		ordInParent:     ordInParent}
 }
 
+// The parent context, or nil if this is the top-level context.
+func (ctx *IndexReaderContextImpl) parentContext() *CompositeReaderContext {
+	return ctx.parent
+}
+
 type ARFieldsReader interface {
+	// Returns the FieldInfos describing all fields in this reader.
+	FieldInfos() FieldInfos
 	Terms(field string) Terms
 	Fields() Fields
 	LiveDocs() util.Bits
+	// Returns NumericDocValues for this field, or nil if no
+	// NumericDocValues were indexed for this field.
+	NumericDocValues(field string) (v NumericDocValues, err error)
+	// Returns BinaryDocValues for this field, or nil if no
+	// BinaryDocValues were indexed for this field.
+	BinaryDocValues(field string) (v BinaryDocValues, err error)
+	// Returns SortedDocValues for this field, or nil if no
+	// SortedDocValues were indexed for this field.
+	SortedDocValues(field string) (v SortedDocValues, err error)
+	// Returns SortedSetDocValues for this field, or nil if no
+	// SortedSetDocValues were indexed for this field.
+	SortedSetDocValues(field string) (v SortedSetDocValues, err error)
+	// Returns NumericDocValues representing norms for this field, or
+	// nil if no NumericDocValues were indexed.
+	NormValues(field string) (v NumericDocValues, err error)
 }
 
 type AtomicReader interface {
@@ -165,24 +350,70 @@ func (r *AtomicReaderImpl) Context() IndexReaderContext {
 	return r.readerContext
 }
 
+/*
+Returns the number of documents containing the term. This method
+returns 0 if the term or field does not exist. This method does not
+take into account deleted documents that have not yet been merged
+away.
+*/
 func (r *AtomicReaderImpl) DocFreq(term Term) (n int, err error) {
-	panic("not implemented yet")
+	termsEnum, err := r.seekTerm(term)
+	if termsEnum == nil || err != nil {
+		return 0, err
+	}
+	return termsEnum.DocFreq(), nil
 }
 
+/*
+Returns the total number of occurrences of the term. This method
+returns 0 if the term or field does not exist, or -1 if the codec
+does not support the measure. This method does not take into account
+deleted documents that have not yet been merged away.
+*/
 func (r *AtomicReaderImpl) TotalTermFreq(term Term) (n int64, err error) {
-	panic("not implemented yet")
+	termsEnum, err := r.seekTerm(term)
+	if termsEnum == nil || err != nil {
+		return 0, err
+	}
+	return termsEnum.TotalTermFreq(), nil
+}
+
+// Returns a TermsEnum positioned at the term, or nil if the term or
+// field does not exist.
+func (r *AtomicReaderImpl) seekTerm(term Term) (TermsEnum, error) {
+	terms := r.Terms(term.Field)
+	if terms == nil {
+		return nil, nil
+	}
+	termsEnum := terms.Iterator(nil)
+	if ok, err := termsEnum.SeekExact(term.Bytes); !ok || err != nil {
+		return nil, err
+	}
+	return termsEnum, nil
 }
 
 func (r *AtomicReaderImpl) SumDocFreq(field string) (n int64, err error) {
-	panic("not implemented yet")
+	terms := r.Terms(field)
+	if terms == nil {
+		return 0, nil
+	}
+	return terms.SumDocFreq(), nil
 }
 
 func (r *AtomicReaderImpl) DocCount(field string) (n int, err error) {
-	panic("not implemented yet")
+	terms := r.Terms(field)
+	if terms == nil {
+		return 0, nil
+	}
+	return terms.DocCount(), nil
 }
 
 func (r *AtomicReaderImpl) SumTotalTermFreq(field string) (n int64, err error) {
-	panic("not implemented yet")
+	terms := r.Terms(field)
+	if terms == nil {
+		return 0, nil
+	}
+	return terms.SumTotalTermFreq(), nil
 }
 
 func (r *AtomicReaderImpl) Terms(field string) Terms {
@@ -1,23 +1,42 @@
 package index
 
+import (
+	"fmt"
+)
+
+// ReaderUtil.java
+
 /*
- * Returns index of the searcher/reader for document n in the
- * array used to construct this searcher/reader.
- */
-func subIndex(n int, docStarts []int) int {
-	// searcher/reader for doc n:
-	size := len(docStarts)
+Walks up the reader tree and return the given context's top level
+reader context, or in other words the reader tree's root context.
+*/
+func TopLevelContext(ctx IndexReaderContext) IndexReaderContext {
+	for {
+		parent := ctx.parentContext()
+		if parent == nil {
+			return ctx
+		}
+		ctx = parent
+	}
+}
+
+/*
+Returns index of the slot for document n among size slots whose
+ascending doc bases are given by docBase. When several slots share
+the same doc base (e.g. empty sub readers), the last one is returned.
+*/
+func searchDocBases(n, size int, docBase func(i int) int) int {
 	lo := 0        // search starts array
 	hi := size - 1 // for first element less than n, return its index
 	for hi >= lo {
 		mid := int(uint(lo+hi) >> 1)
-		midValue := docStarts[mid]
+		midValue := docBase(mid)
 		if n < midValue {
 			hi = mid - 1
 		} else if n > midValue {
 			lo = mid + 1
 		} else { // found a match
-			for mid+1 < size && docStarts[mid+1] == midValue {
+			for mid+1 < size && docBase(mid+1) == midValue {
 				mid++ // scan to last match
 			}
 			return mid
@@ -25,3 +44,37 @@ func subIndex(n int, docStarts []int) int {
 	}
 	return hi
 }
+
+/*
+Returns index of the searcher/reader for document n in the array
+used to construct this searcher/reader.
+*/
+func subIndex(n int, docStarts []int) int {
+	return searchDocBases(n, len(docStarts), func(i int) int { return docStarts[i] })
+}
+
+/*
+Returns index of the leaf in the slice of leaves containing document
+n, searched by the leaves' DocBase.
+*/
+func SubIndex(n int, leaves []AtomicReaderContext) int {
+	return searchDocBases(n, len(leaves), func(i int) int { return leaves[i].DocBase })
+}
+
+/*
+Returns the leaf containing document n, which is relative to the
+top-level context of leaves, and n translated to the leaf. Panics if
+n is out of range.
+*/
+func LeafForDoc(n int, leaves []AtomicReaderContext) (leaf AtomicReaderContext, localDocID int) {
+	maxDoc := 0
+	if len(leaves) > 0 {
+		last := leaves[len(leaves)-1]
+		maxDoc = last.DocBase + last.Reader().MaxDoc()
+	}
+	if n < 0 || n >= maxDoc {
+		panic(fmt.Sprintf("docID must be [0, %v) (got docID=%v)", maxDoc, n))
+	}
+	leaf = leaves[SubIndex(n, leaves)]
+	return leaf, n - leaf.DocBase
+}
@@ -0,0 +1,101 @@
+package index
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/codec"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+)
+
+// Describes a single file of a segment, as reported by
+// SegmentReader.FileBreakdown().
+type SegmentFile struct {
+	Name      string
+	Extension string
+	// Name of the compound file holding this file, or empty
+	// if the file lives directly in the directory
+	Compound string
+	// Codec name and format version read from the file's codec
+	// header; Codec is empty and Version -1 if the file has no
+	// header (e.g. pre-4.0 formats)
+	Codec   string
+	Version int32
+	Size    int64
+}
+
+func (f SegmentFile) String() string {
+	name := f.Name
+	if f.Compound != "" {
+		name = fmt.Sprintf("%v/%v", f.Compound, f.Name)
+	}
+	if f.Codec == "" {
+		return fmt.Sprintf("%v (%v bytes)", name, f.Size)
+	}
+	return fmt.Sprintf("%v %v v%v (%v bytes)", name, f.Codec, f.Version, f.Size)
+}
+
+/*
+Lists each file of this segment with its extension, codec header
+and size, in file name order. Files packed in the compound file are
+listed after it, with Compound set to its name. Useful to find out
+which part of the index takes up the space.
+*/
+func (r *SegmentReader) FileBreakdown() (files []SegmentFile, err error) {
+	r.ensureOpen()
+	dir := r.si.info.dir
+	names := r.si.Files()
+	sort.Strings(names)
+	for _, name := range names {
+		file, err := describeSegmentFile(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	if cfsDir := r.core.cfsReader; cfsDir != nil {
+		cfsName := util.SegmentFileName(r.si.info.name, "", store.COMPOUND_FILE_EXTENSION)
+		names, err := cfsDir.ListAll()
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			file, err := describeSegmentFile(cfsDir, name)
+			if err != nil {
+				return nil, err
+			}
+			file.Compound = cfsName
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func describeSegmentFile(dir store.Directory, name string) (file SegmentFile, err error) {
+	file = SegmentFile{Name: name, Extension: util.FileExtension(name), Version: -1}
+	in, err := dir.OpenInput(name, store.IO_CONTEXT_READONCE)
+	if err != nil {
+		return file, err
+	}
+	defer in.Close()
+
+	file.Size = in.Length()
+	if file.Size < int64(codec.HeaderLength("")) {
+		return file, nil
+	}
+	magic, err := in.ReadInt()
+	if err != nil {
+		return file, err
+	}
+	if magic == codec.CODEC_MAGIC {
+		if file.Codec, err = in.ReadString(); err != nil {
+			return file, err
+		}
+		if file.Version, err = in.ReadInt(); err != nil {
+			return file, err
+		}
+	}
+	return file, nil
+}
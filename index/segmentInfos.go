@@ -24,15 +24,14 @@ func NewFindSegmentsFile(directory store.Directory,
 	return &FindSegmentsFile{directory, doBody, 10}
 }
 
-// TODO support IndexCommit
-func (fsf *FindSegmentsFile) run() (obj interface{}, err error) {
+func (fsf *FindSegmentsFile) run(commit IndexCommit) (obj interface{}, err error) {
 	log.Print("Finding segments file...")
-	// if commit != nil {
-	// 	if fsf.directory != commit.Directory {
-	// 		return nil, errors.New("the specified commit does not match the specified Directory")
-	// 	}
-	// 	return fsf.doBody(commit.SegmentsFileName)
-	// }
+	if commit != nil {
+		if fsf.directory != commit.Directory() {
+			return nil, errors.New("the specified commit does not match the specified Directory")
+		}
+		return fsf.doBody(commit.SegmentsFileName())
+	}
 
 	lastGen := int64(-1)
 	gen := int64(0)
@@ -88,42 +87,9 @@ func (fsf *FindSegmentsFile) run() (obj interface{}, err error) {
 			// gens.  This way, if either approach is hitting
 			// a stale cache (NFS) we have a better chance of
 			// getting the right generation.
-			genB := int64(-1)
-			genInput, err := fsf.directory.OpenInput(INDEX_FILENAME_SEGMENTS_GEN, store.IO_CONTEXT_READ)
+			genB, err := fsf.readSegmentsGen()
 			if err != nil {
-				// if fsf.infoStream != nil {
-				log.Printf("segments.gen open: %v", err)
-				// }
-			} else {
-				defer genInput.Close()
-				log.Print("Reading segments info...")
-
-				version, err := genInput.ReadInt()
-				if err != nil {
-					return nil, err
-				}
-				log.Printf("Version: %v", version)
-				if version == FORMAT_SEGMENTS_GEN_CURRENT {
-					log.Print("Version is current.")
-					gen0, err := genInput.ReadLong()
-					if err != nil {
-						return nil, err
-					}
-					gen1, err := genInput.ReadLong()
-					if err != nil {
-						return nil, err
-					} else {
-						// if fsf.infoStream != nil {
-						log.Printf("fallback check: %v; %v", gen0, gen1)
-						// }
-						if gen0 == gen1 {
-							// The file is consistent.
-							genB = gen0
-						}
-					}
-				} else {
-					return nil, codec.NewIndexFormatTooNewError(genInput, version, FORMAT_SEGMENTS_GEN_CURRENT, FORMAT_SEGMENTS_GEN_CURRENT)
-				}
+				return nil, err
 			}
 
 			// if fsf.infoStream != nil {
@@ -223,11 +189,61 @@ func (fsf *FindSegmentsFile) run() (obj interface{}, err error) {
 	}
 }
 
+/*
+Reads the generation recorded in segments.gen, or -1 if the file
+doesn't exist, can't be read, or is inconsistent. Only a format error
+is reported, since the file is merely a hint which may be stale or
+partially written.
+*/
+func (fsf *FindSegmentsFile) readSegmentsGen() (gen int64, err error) {
+	genInput, err := fsf.directory.OpenInput(INDEX_FILENAME_SEGMENTS_GEN, store.IO_CONTEXT_READONCE)
+	if err != nil {
+		// if fsf.infoStream != nil {
+		log.Printf("segments.gen open: %v", err)
+		// }
+		return -1, nil
+	}
+	defer genInput.Close()
+	log.Print("Reading segments info...")
+
+	version, err := genInput.ReadInt()
+	if err != nil {
+		log.Printf("segments.gen read: %v", err)
+		return -1, nil
+	}
+	log.Printf("Version: %v", version)
+	if version != FORMAT_SEGMENTS_GEN_CURRENT {
+		// rethrow any format exception
+		return -1, codec.NewIndexFormatTooNewError(genInput, version, FORMAT_SEGMENTS_GEN_CURRENT, FORMAT_SEGMENTS_GEN_CURRENT)
+	}
+	log.Print("Version is current.")
+	gen0, err := genInput.ReadLong()
+	if err == nil {
+		var gen1 int64
+		if gen1, err = genInput.ReadLong(); err == nil {
+			// if fsf.infoStream != nil {
+			log.Printf("fallback check: %v; %v", gen0, gen1)
+			// }
+			if gen0 == gen1 {
+				// The file is consistent.
+				return gen0, nil
+			}
+			return -1, nil
+		}
+	}
+	log.Printf("segments.gen read: %v", err)
+	return -1, nil
+}
+
 const (
 	INDEX_FILENAME_SEGMENTS     = "segments"
 	INDEX_FILENAME_SEGMENTS_GEN = "segments.gen"
 	COMOPUND_FILE_EXTENSION     = "cfs"
 	VERSION_40                  = 0
+	// The file format version for the segments_N codec header, since
+	// 4.6+: adds the generations of the updated doc values. Only
+	// written when a segment has field updates.
+	VERSION_46                  = 1
 	FORMAT_SEGMENTS_GEN_CURRENT = -2
 )
 
@@ -289,12 +305,13 @@ func (si *SegmentInfo) CheckFileNames(files map[string]bool) {
 }
 
 type SegmentInfos struct {
-	counter        int
-	version        int64
-	generation     int64
-	lastGeneration int64
-	userData       map[string]string
-	Segments       []SegmentInfoPerCommit
+	counter           int
+	version           int64
+	generation        int64
+	lastGeneration    int64
+	userData          map[string]string
+	Segments          []SegmentInfoPerCommit
+	pendingSegnOutput *store.ChecksumIndexOutput
 }
 
 func LastCommitGeneration(files []string) int64 {
@@ -317,6 +334,15 @@ func (sis SegmentInfos) SegmentsFileName() string {
 	return util.FileNameFromGeneration(util.SEGMENTS, "", sis.lastGeneration)
 }
 
+// Get the next segments_N filename that will be written.
+func (sis *SegmentInfos) nextSegmentFileName() string {
+	nextGeneration := int64(1)
+	if sis.generation != -1 {
+		nextGeneration = sis.generation + 1
+	}
+	return util.FileNameFromGeneration(INDEX_FILENAME_SEGMENTS, "", nextGeneration)
+}
+
 func GenerationFromSegmentsFileName(fileName string) int64 {
 	switch {
 	case fileName == INDEX_FILENAME_SEGMENTS:
@@ -364,7 +390,7 @@ func (sis *SegmentInfos) Read(directory store.Directory, segmentFileName string)
 	}
 	if format == codec.CODEC_MAGIC {
 		// 4.0+
-		_, err = codec.CheckHeaderNoMagic(input, "segments", VERSION_40, VERSION_40)
+		actualFormat, err := codec.CheckHeaderNoMagic(input, "segments", VERSION_40, VERSION_46)
 		if err != nil {
 			return err
 		}
@@ -413,7 +439,29 @@ func (sis *SegmentInfos) Read(directory store.Directory, segmentFileName string)
 			if delCount < 0 || delCount > int(info.docCount) {
 				return errors.New(fmt.Sprintf("invalid deletion count: %v (resource: %v)", delCount, input))
 			}
-			sis.Segments = append(sis.Segments, NewSegmentInfoPerCommit(info, delCount, delGen))
+			fieldInfosGen := int64(-1)
+			if actualFormat >= VERSION_46 {
+				if fieldInfosGen, err = input.ReadLong(); err != nil {
+					return err
+				}
+			}
+			siPerCommit := NewSegmentInfoPerCommit(info, delCount, delGen, fieldInfosGen)
+			if actualFormat >= VERSION_46 {
+				numGensUpdatesFiles, err := asInt(input.ReadInt())
+				if err != nil {
+					return err
+				}
+				for i := 0; i < numGensUpdatesFiles; i++ {
+					gen, err := input.ReadLong()
+					if err != nil {
+						return err
+					}
+					if siPerCommit.genUpdatesFiles[gen], err = input.ReadStringSet(); err != nil {
+						return err
+					}
+				}
+			}
+			sis.Segments = append(sis.Segments, siPerCommit)
 		}
 		sis.userData, err = input.ReadStringStringMap()
 		if err != nil {
@@ -437,15 +485,269 @@ func (sis *SegmentInfos) Read(directory store.Directory, segmentFileName string)
 	return nil
 }
 
+// Writes a new segments_N file, pending until finishCommit().
+func (sis *SegmentInfos) write(directory store.Directory) (err error) {
+	segmentFileName := sis.nextSegmentFileName()
+
+	// Always advance the generation on write:
+	if sis.generation == -1 {
+		sis.generation = 1
+	} else {
+		sis.generation++
+	}
+
+	main, err := directory.CreateOutput(segmentFileName, store.IO_CONTEXT_DEFAULT)
+	if err != nil {
+		return err
+	}
+	segnOutput := store.NewChecksumIndexOutput(main)
+	success := false
+	defer func() {
+		if !success {
+			util.CloseWhileSuppressingError(segnOutput)
+			// Try not to leave a truncated segments_N file in
+			// the index:
+			directory.DeleteFile(segmentFileName)
+		}
+	}()
+
+	// Indexes without field updates stay in the 4.0 format
+	format := VERSION_40
+	for _, siPerCommit := range sis.Segments {
+		if siPerCommit.HasFieldUpdates() {
+			format = VERSION_46
+		}
+	}
+	if err = codec.WriteHeader(segnOutput, "segments", int32(format)); err != nil {
+		return err
+	}
+	if err = segnOutput.WriteLong(sis.version); err != nil {
+		return err
+	}
+	if err = segnOutput.WriteInt(int32(sis.counter)); err != nil {
+		return err
+	}
+	if err = segnOutput.WriteInt(int32(len(sis.Segments))); err != nil {
+		return err
+	}
+	for _, siPerCommit := range sis.Segments {
+		si := siPerCommit.info
+		if err = segnOutput.WriteString(si.name); err != nil {
+			return err
+		}
+		if err = segnOutput.WriteString(si.codec.Name); err != nil {
+			return err
+		}
+		if err = segnOutput.WriteLong(siPerCommit.delGen); err != nil {
+			return err
+		}
+		if err = segnOutput.WriteInt(int32(siPerCommit.delCount)); err != nil {
+			return err
+		}
+		if format >= VERSION_46 {
+			if err = segnOutput.WriteLong(siPerCommit.fieldInfosGen); err != nil {
+				return err
+			}
+			if err = segnOutput.WriteInt(int32(len(siPerCommit.genUpdatesFiles))); err != nil {
+				return err
+			}
+			for gen, files := range siPerCommit.genUpdatesFiles {
+				if err = segnOutput.WriteLong(gen); err != nil {
+					return err
+				}
+				if err = segnOutput.WriteStringSet(files); err != nil {
+					return err
+				}
+			}
+		}
+		// assert si.dir == directory
+	}
+	if err = segnOutput.WriteStringStringMap(sis.userData); err != nil {
+		return err
+	}
+	sis.pendingSegnOutput = segnOutput
+	success = true
+	return nil
+}
+
+/*
+Call this to start a commit. This writes the new segments file, but
+leaves out the checksum at the end, so that it is not visible to
+readers. Once this is called you must call finishCommit() to complete
+the commit or rollbackCommit() to abort it.
+*/
+func (sis *SegmentInfos) prepareCommit(dir store.Directory) error {
+	if sis.pendingSegnOutput != nil {
+		panic("prepareCommit was already called")
+	}
+	return sis.write(dir)
+}
+
+// Abort a commit started by prepareCommit().
+func (sis *SegmentInfos) rollbackCommit(dir store.Directory) {
+	if sis.pendingSegnOutput != nil {
+		// Suppress so we keep throwing the original error in
+		// our caller
+		util.CloseWhileSuppressingError(sis.pendingSegnOutput)
+		sis.pendingSegnOutput = nil
+
+		// Must carefully compute fileName from "generation"
+		// since lastGeneration isn't incremented:
+		segmentFileName := util.FileNameFromGeneration(INDEX_FILENAME_SEGMENTS, "", sis.generation)
+		// Suppress so we keep throwing the original error in
+		// our caller
+		dir.DeleteFile(segmentFileName)
+	}
+}
+
+func (sis *SegmentInfos) finishCommit(dir store.Directory) (err error) {
+	if sis.pendingSegnOutput == nil {
+		panic("prepareCommit was not called")
+	}
+	success := false
+	defer func() {
+		if !success {
+			// Closes pendingSegnOutput & deletes partial segments_N:
+			sis.rollbackCommit(dir)
+		}
+	}()
+	if err = sis.pendingSegnOutput.FinishCommit(); err != nil {
+		return err
+	}
+	if err = sis.pendingSegnOutput.Close(); err != nil {
+		return err
+	}
+	sis.pendingSegnOutput = nil
+
+	// NOTE: if we crash here, we have left a segments_N
+	// file in the directory in a possibly corrupt state (if
+	// some bytes made it to stable storage and others
+	// didn't). But, the segments_N file includes checksum
+	// at the end, which should catch this case. So when a
+	// reader tries to read it, it will throw a
+	// CorruptIndexException, which should cause the retry
+	// logic in SegmentInfos to kick in and load the last
+	// good (previous) segments_N-1 file.
+	fileName := util.FileNameFromGeneration(INDEX_FILENAME_SEGMENTS, "", sis.generation)
+	if err = dir.Sync([]string{fileName}); err != nil {
+		return err
+	}
+	success = true
+
+	sis.lastGeneration = sis.generation
+	writeSegmentsGen(dir, sis.generation)
+	return nil
+}
+
+// A utility for writing the SEGMENTS_GEN file to a Directory.
+func writeSegmentsGen(dir store.Directory, generation int64) {
+	genOutput, err := dir.CreateOutput(INDEX_FILENAME_SEGMENTS_GEN, store.IO_CONTEXT_READONCE)
+	if err == nil {
+		func() {
+			defer genOutput.Close()
+			if err = genOutput.WriteInt(FORMAT_SEGMENTS_GEN_CURRENT); err == nil {
+				if err = genOutput.WriteLong(generation); err == nil {
+					err = genOutput.WriteLong(generation)
+				}
+			}
+		}()
+		if err == nil {
+			err = dir.Sync([]string{INDEX_FILENAME_SEGMENTS_GEN})
+		}
+	}
+	if err != nil {
+		// It's OK if we fail to write this file since it's
+		// used only as one of the retry fallbacks.
+		log.Printf("segments.gen write: %v", err)
+		dir.DeleteFile(INDEX_FILENAME_SEGMENTS_GEN)
+	}
+}
+
+/*
+Writes & syncs to the Directory dir, taking care to remove the
+segments file on error.
+*/
+func (sis *SegmentInfos) Commit(dir store.Directory) error {
+	// the referenced files must be on stable storage first
+	if err := dir.Sync(sis.Files(dir, false)); err != nil {
+		return err
+	}
+	if err := sis.prepareCommit(dir); err != nil {
+		return err
+	}
+	return sis.finishCommit(dir)
+}
+
+/*
+Call this before committing if changes have been made to the
+segments.
+*/
+func (sis *SegmentInfos) changed() {
+	sis.version++
+}
+
+// Returns a copy of this instance, without any of the segments.
+func (sis *SegmentInfos) cloneWithoutSegments() *SegmentInfos {
+	ans := *sis
+	ans.Segments = nil
+	ans.pendingSegnOutput = nil
+	ans.userData = make(map[string]string)
+	for k, v := range sis.userData {
+		ans.userData[k] = v
+	}
+	return &ans
+}
+
+// Returns a copy of this instance, sharing the segments' SegmentInfo.
+func (sis *SegmentInfos) clone() *SegmentInfos {
+	ans := sis.cloneWithoutSegments()
+	ans.Segments = append([]SegmentInfoPerCommit(nil), sis.Segments...)
+	return ans
+}
+
+// Carries over the generation of a commit written by a clone of this
+// instance.
+func (sis *SegmentInfos) updateGeneration(other *SegmentInfos) {
+	sis.lastGeneration, sis.generation = other.lastGeneration, other.generation
+}
+
 func (sis *SegmentInfos) ReadAll(directory store.Directory) error {
 	sis.generation, sis.lastGeneration = -1, -1
 	_, err := NewFindSegmentsFile(directory, func(segmentFileName string) (obj interface{}, err error) {
 		err = sis.Read(directory, segmentFileName)
 		return nil, err
-	}).run()
+	}).run(nil)
 	return err
 }
 
+/*
+Returns all file names referenced by SegmentInfo instances matching
+the provided Directory (ie files associated with any "external"
+segments are skipped). The returned collection is recomputed on each
+invocation.
+*/
+func (sis *SegmentInfos) Files(dir store.Directory, includeSegmentsFile bool) []string {
+	files := make(map[string]bool)
+	if includeSegmentsFile {
+		if segmentFileName := sis.SegmentsFileName(); segmentFileName != "" {
+			files[segmentFileName] = true
+		}
+	}
+	for _, info := range sis.Segments {
+		// assert info.info.dir == dir
+		if info.info.dir == dir {
+			for _, file := range info.Files() {
+				files[file] = true
+			}
+		}
+	}
+	ans := make([]string, 0, len(files))
+	for file, _ := range files {
+		ans = append(ans, file)
+	}
+	return ans
+}
+
 func (sis *SegmentInfos) Clear() {
 	sis.Segments = make([]SegmentInfoPerCommit, 0)
 }
@@ -2,6 +2,9 @@ package index
 
 import (
 	"github.com/balzaczyy/golucene/store"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -26,3 +29,115 @@ func assertEquals(t *testing.T, a, b interface{}) {
 		t.Errorf("Expected '%v', but '%v'", a, b)
 	}
 }
+
+// Copies the files of the index at src into a new temporary directory.
+func copyTestIndex(t *testing.T, src string) string {
+	dir, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(src, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, f.Name()), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadSegmentsGenFallback(t *testing.T) {
+	dir := copyTestIndex(t, "../search/testdata/win8/belfrysample")
+	defer os.RemoveAll(dir)
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &testDVWriter{}
+	genFile := filepath.Join(dir, INDEX_FILENAME_SEGMENTS_GEN)
+	for _, v := range []struct {
+		desc string
+		data []byte
+	}{
+		{"ahead of the directory listing", w.writeLong(w.writeLong(w.writeInt(nil, uint32(0xFFFFFFFE)), 2), 2)},
+		{"inconsistent", w.writeLong(w.writeLong(w.writeInt(nil, uint32(0xFFFFFFFE)), 3), 1)},
+		{"truncated", w.writeInt(nil, uint32(0xFFFFFFFE))},
+		{"missing", nil},
+	} {
+		if v.data == nil {
+			os.Remove(genFile)
+		} else if err = ioutil.WriteFile(genFile, v.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		sis := &SegmentInfos{}
+		if err = sis.ReadAll(d); err != nil {
+			t.Fatalf("Failed to read segments with %v segments.gen: %v", v.desc, err)
+		}
+		if sis.generation != 1 || len(sis.Segments) != 1 {
+			t.Errorf("Unexpected segments with %v segments.gen: gen=%v, %v", v.desc, sis.generation, sis.Segments)
+		}
+	}
+
+	// unknown format is not tolerated
+	if err = ioutil.WriteFile(genFile, w.writeInt(nil, uint32(0xFFFFFFF0)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = (&SegmentInfos{}).ReadAll(d); err == nil {
+		t.Error("Should fail on unknown segments.gen format")
+	}
+	os.Remove(genFile)
+	os.Remove(filepath.Join(dir, "segments_1"))
+	if err = (&SegmentInfos{}).ReadAll(d); err == nil {
+		t.Error("Should fail without any segments file")
+	}
+}
+
+func TestSegmentInfosCommit(t *testing.T) {
+	dir := copyTestIndex(t, "../search/testdata/win8/belfrysample")
+	defer os.RemoveAll(dir)
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sis := &SegmentInfos{}
+	if err = sis.ReadAll(d); err != nil {
+		t.Fatal(err)
+	}
+	sis.userData = map[string]string{"checkpoint": "42"}
+	sis.changed()
+	if err = sis.Commit(d); err != nil {
+		t.Fatal(err)
+	}
+	if sis.SegmentsFileName() != "segments_2" {
+		t.Errorf("Expected segments_2, but was %v", sis.SegmentsFileName())
+	}
+
+	// the new commit is found by both the listing and segments.gen
+	fsf := NewFindSegmentsFile(d, nil)
+	if gen, err := fsf.readSegmentsGen(); err != nil || gen != 2 {
+		t.Errorf("Expected segments.gen to record gen 2, but was %v (%v)", gen, err)
+	}
+	sis2 := &SegmentInfos{}
+	if err = sis2.ReadAll(d); err != nil {
+		t.Fatal(err)
+	}
+	if sis2.generation != 2 || sis2.version != sis.version || sis2.counter != sis.counter {
+		t.Errorf("Expected gen=2, version=%v, counter=%v, but was gen=%v, version=%v, counter=%v",
+			sis.version, sis.counter, sis2.generation, sis2.version, sis2.counter)
+	}
+	if len(sis2.Segments) != 1 || sis2.Segments[0].String() != sis.Segments[0].String() {
+		t.Errorf("Expected segments %v, but was %v", sis.Segments, sis2.Segments)
+	}
+	if sis2.userData["checkpoint"] != "42" {
+		t.Errorf("Expected user data %v, but was %v", sis.userData, sis2.userData)
+	}
+}
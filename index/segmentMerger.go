@@ -0,0 +1,511 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// SegmentMerger.java
+
+/*
+Combines two or more Segments, represented by AtomicReaders, into a
+single Segment. Deleted documents of the readers are dropped, and the
+remaining documents are renumbered consecutively, in the order of
+the readers.
+
+The field infos are merged first, then the stored fields, postings,
+doc values, norms and term vectors are written through the codec.
+*/
+type SegmentMerger struct {
+	directory    store.Directory
+	codec        Codec
+	context      store.IOContext
+	fieldNumbers *fieldNumbers
+	mergeState   *MergeState
+}
+
+func newSegmentMerger(readers []AtomicReader, segmentInfo *SegmentInfo,
+	dir store.Directory, fieldNumbers *fieldNumbers, context store.IOContext) *SegmentMerger {
+	return &SegmentMerger{
+		directory:    dir,
+		codec:        segmentInfo.codec,
+		context:      context,
+		fieldNumbers: fieldNumbers,
+		mergeState: &MergeState{
+			readers:     readers,
+			segmentInfo: segmentInfo,
+		},
+	}
+}
+
+/*
+Merges the readers into the directory passed to the constructor, and
+returns the MergeState, whose segmentInfo has the number of merged
+documents.
+*/
+func (m *SegmentMerger) merge() (*MergeState, error) {
+	m.mergeState.segmentInfo.docCount = int32(m.setDocMaps())
+	if err := m.mergeFieldInfos(); err != nil {
+		return nil, err
+	}
+	numMerged, err := m.mergeFields()
+	if err != nil {
+		return nil, err
+	}
+	if numMerged != int(m.mergeState.segmentInfo.docCount) {
+		panic("assert fail")
+	}
+
+	segmentWriteState := newSegmentWriteState(m.directory, m.mergeState.segmentInfo,
+		m.mergeState.fieldInfos, m.context)
+	if err = m.mergeTerms(segmentWriteState); err != nil {
+		return nil, err
+	}
+	if m.mergeState.fieldInfos.hasDocValues {
+		if err = m.mergeDocValues(segmentWriteState); err != nil {
+			return nil, err
+		}
+	}
+	if m.mergeState.fieldInfos.hasNorms {
+		if err = m.mergeNorms(segmentWriteState); err != nil {
+			return nil, err
+		}
+	}
+	if m.mergeState.fieldInfos.hasVectors {
+		if numMerged, err = m.mergeVectors(); err != nil {
+			return nil, err
+		}
+		if numMerged != int(m.mergeState.segmentInfo.docCount) {
+			panic("assert fail")
+		}
+	}
+
+	// write the merged infos
+	if err = m.codec.WriteFieldInfos(m.directory, m.mergeState.segmentInfo.name, "",
+		m.mergeState.fieldInfos, m.context); err != nil {
+		return nil, err
+	}
+	return m.mergeState, nil
+}
+
+// Computes the doc map and doc base of each reader, and returns the
+// number of documents of the merged segment.
+func (m *SegmentMerger) setDocMaps() int {
+	numReaders := len(m.mergeState.readers)
+	m.mergeState.docMaps = make([]*docMap, numReaders)
+	m.mergeState.docBase = make([]int, numReaders)
+	docBase := 0
+	for i, reader := range m.mergeState.readers {
+		m.mergeState.docBase[i] = docBase
+		docMap := buildDocMap(reader)
+		m.mergeState.docMaps[i] = docMap
+		docBase += docMap.numDocs()
+	}
+	return docBase
+}
+
+/*
+Merges the field infos of all readers. A field keeps its global
+number, and its settings are unified as IndexWriter does for the
+documents of a single segment.
+*/
+func (m *SegmentMerger) mergeFieldInfos() error {
+	byName := make(map[string]*FieldInfo)
+	var names []string
+	for _, reader := range m.mergeState.readers {
+		for _, fi := range reader.FieldInfos().values {
+			merged, ok := byName[fi.name]
+			if !ok {
+				number := m.fieldNumbers.addOrGet(fi.name, fi.number)
+				info := NewFieldInfo(fi.name, fi.indexed, number, fi.storeTermVector,
+					fi.omitNorms, fi.storePayloads, fi.indexOptions, 0, 0, make(map[string]string))
+				merged = &info
+				byName[fi.name] = merged
+				names = append(names, fi.name)
+			} else {
+				merged.update(fi)
+			}
+			if fi.docValueType != 0 {
+				if merged.docValueType != 0 && merged.docValueType != fi.docValueType {
+					return errors.New(fmt.Sprintf(
+						"cannot change DocValues type from %v to %v for field \"%v\"",
+						merged.docValueType, fi.docValueType, fi.name))
+				}
+				if err := m.fieldNumbers.setDocValuesType(fi.name, fi.docValueType); err != nil {
+					return err
+				}
+				merged.docValueType = fi.docValueType
+			}
+			if !merged.omitNorms && fi.normType != 0 {
+				merged.normType = fi.normType
+			}
+		}
+	}
+	infos := make([]FieldInfo, len(names))
+	for i, name := range names {
+		infos[i] = *byName[name]
+	}
+	m.mergeState.fieldInfos = NewFieldInfos(infos)
+	return nil
+}
+
+// Merges the stored fields, and returns the number of documents
+// merged.
+func (m *SegmentMerger) mergeFields() (n int, err error) {
+	writer, err := m.codec.GetStoredFieldsWriter(m.directory, m.mergeState.segmentInfo, m.context)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err == nil {
+			err = writer.Close()
+		} else {
+			util.CloseWhileSuppressingError(writer)
+		}
+	}()
+	return mergeStoredFields(writer, m.mergeState)
+}
+
+// Merges the term vectors, and returns the number of documents
+// merged.
+func (m *SegmentMerger) mergeVectors() (n int, err error) {
+	writer, err := m.codec.GetTermVectorsWriter(m.directory, m.mergeState.segmentInfo, m.context)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err == nil {
+			err = writer.Close()
+		} else {
+			util.CloseWhileSuppressingError(writer)
+		}
+	}()
+	return mergeTermVectors(writer, m.mergeState)
+}
+
+func (m *SegmentMerger) mergeTerms(segmentWriteState SegmentWriteState) (err error) {
+	var fields []Fields
+	var slices []ReaderSlice
+	docBase := 0
+	for readerIndex, reader := range m.mergeState.readers {
+		f := reader.Fields()
+		maxDoc := reader.MaxDoc()
+		if f != nil {
+			slices = append(slices, ReaderSlice{docBase, maxDoc, readerIndex})
+			fields = append(fields, f)
+		}
+		docBase += maxDoc
+	}
+
+	consumer, err := m.codec.GetFieldsConsumer(segmentWriteState)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = consumer.Close()
+		} else {
+			util.CloseWhileSuppressingError(consumer)
+		}
+	}()
+	return mergeFields(consumer, m.mergeState, NewMultiFields(fields, slices))
+}
+
+func (m *SegmentMerger) mergeDocValues(segmentWriteState SegmentWriteState) (err error) {
+	consumer, err := m.codec.GetDocValuesConsumer(segmentWriteState)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = consumer.Close()
+		} else {
+			util.CloseWhileSuppressingError(consumer)
+		}
+	}()
+	for _, field := range m.mergeState.fieldInfos.values {
+		switch field.docValueType {
+		case DOC_VALUES_TYPE_NUMERIC:
+			err = mergeNumericField(consumer, field, m.mergeState,
+				func(r AtomicReader) (NumericDocValues, error) {
+					return r.NumericDocValues(field.name)
+				})
+		case DOC_VALUES_TYPE_BINARY:
+			err = mergeBinaryField(consumer, field, m.mergeState)
+		case DOC_VALUES_TYPE_SORTED:
+			err = mergeSortedField(consumer, field, m.mergeState)
+		case DOC_VALUES_TYPE_SORTED_SET:
+			err = mergeSortedSetField(consumer, field, m.mergeState)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *SegmentMerger) mergeNorms(segmentWriteState SegmentWriteState) (err error) {
+	consumer, err := m.codec.GetNormsDocValuesConsumer(segmentWriteState)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = consumer.Close()
+		} else {
+			util.CloseWhileSuppressingError(consumer)
+		}
+	}()
+	for _, field := range m.mergeState.fieldInfos.values {
+		if field.normType == 0 {
+			continue
+		}
+		if err = mergeNumericField(consumer, field, m.mergeState,
+			func(r AtomicReader) (NumericDocValues, error) {
+				return r.NormValues(field.name)
+			}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MergeState.java
+
+// Holds common state used during segment merging.
+type MergeState struct {
+	// SegmentInfo of the newly merged segment.
+	segmentInfo *SegmentInfo
+	// FieldInfos of the newly merged segment.
+	fieldInfos FieldInfos
+	// Readers being merged.
+	readers []AtomicReader
+	// Maps docIDs around deletions.
+	docMaps []*docMap
+	// New docID base per reader.
+	docBase []int
+}
+
+// MergeState.java/DocMap
+
+/*
+Remaps docids around deletes during merge: each live document of a
+reader gets the next docID, and deleted documents map to -1.
+*/
+type docMap struct {
+	maxDoc         int
+	docIDs         []int // nil if there are no deletions
+	numDeletedDocs int
+}
+
+func buildDocMap(reader AtomicReader) *docMap {
+	maxDoc := reader.MaxDoc()
+	ans := &docMap{maxDoc: maxDoc}
+	liveDocs := reader.LiveDocs()
+	if liveDocs == nil {
+		return ans
+	}
+	ans.docIDs = make([]int, maxDoc)
+	del := 0
+	for i := 0; i < maxDoc; i++ {
+		if liveDocs.Get(i) {
+			ans.docIDs[i] = i - del
+		} else {
+			ans.docIDs[i] = -1
+			del++
+		}
+	}
+	ans.numDeletedDocs = del
+	if maxDoc-del != reader.NumDocs() {
+		panic("assert fail")
+	}
+	return ans
+}
+
+// Returns the mapped docID corresponding to the provided one, or -1
+// if the document is deleted.
+func (m *docMap) get(docID int) int {
+	if m.docIDs == nil {
+		return docID
+	}
+	return m.docIDs[docID]
+}
+
+// Returns the number of not-deleted documents.
+func (m *docMap) numDocs() int {
+	return m.maxDoc - m.numDeletedDocs
+}
+
+// MappingMultiDocsEnum.java
+
+/*
+Exposes flex API, merged from flex API of sub-segments, remapping
+docIDs (this is used for segment merging).
+*/
+type MappingMultiDocsEnum struct {
+	mergeState  *MergeState
+	subs        []docsEnumWithSlice
+	numSubs     int
+	upto        int
+	current     DocIdSetIterator
+	currentBase int
+	currentMap  *docMap
+	doc         int
+}
+
+func newMappingMultiDocsEnum(mergeState *MergeState) *MappingMultiDocsEnum {
+	return &MappingMultiDocsEnum{mergeState: mergeState, doc: -1}
+}
+
+func (de *MappingMultiDocsEnum) reset(docsEnum *MultiDocsEnum) *MappingMultiDocsEnum {
+	de.numSubs = docsEnum.numSubs
+	de.subs = docsEnum.subs
+	de.upto = -1
+	de.current = nil
+	de.doc = -1
+	return de
+}
+
+func (de *MappingMultiDocsEnum) Freq() int {
+	return de.current.Freq()
+}
+
+func (de *MappingMultiDocsEnum) DocId() int {
+	return de.doc
+}
+
+func (de *MappingMultiDocsEnum) Cost() int64 {
+	var cost int64
+	for _, sub := range de.subs[:de.numSubs] {
+		cost += sub.docsEnum.Cost()
+	}
+	return cost
+}
+
+func (de *MappingMultiDocsEnum) Advance(target int) (int, bool) {
+	panic("not supported")
+}
+
+func (de *MappingMultiDocsEnum) NextDoc() (int, bool) {
+	for {
+		if de.current == nil {
+			if de.upto == de.numSubs-1 {
+				de.doc = NO_MORE_DOCS
+				return de.doc, false
+			}
+			de.upto++
+			reader := de.subs[de.upto].slice.readerIndex
+			de.current = de.subs[de.upto].docsEnum.DocIdSetIterator
+			de.currentBase = de.mergeState.docBase[reader]
+			de.currentMap = de.mergeState.docMaps[reader]
+			if de.currentMap.maxDoc != de.subs[de.upto].slice.length {
+				panic(fmt.Sprintf("readerIndex=%v subs.len=%v len1=%v vs %v",
+					reader, len(de.subs), de.currentMap.maxDoc, de.subs[de.upto].slice.length))
+			}
+		}
+
+		if doc, more := de.current.NextDoc(); more && doc != NO_MORE_DOCS {
+			// compact deletions
+			if doc = de.currentMap.get(doc); doc == -1 {
+				continue
+			}
+			de.doc = de.currentBase + doc
+			return de.doc, true
+		}
+		de.current = nil
+	}
+}
+
+// MappingMultiDocsAndPositionsEnum.java
+
+/*
+Exposes flex API, merged from flex API of sub-segments, remapping
+docIDs (this is used for segment merging).
+*/
+type MappingMultiDocsAndPositionsEnum struct {
+	mergeState  *MergeState
+	subs        []docsAndPositionsEnumWithSlice
+	numSubs     int
+	upto        int
+	current     DocsAndPositionsIterator
+	currentBase int
+	currentMap  *docMap
+	doc         int
+}
+
+func newMappingMultiDocsAndPositionsEnum(mergeState *MergeState) *MappingMultiDocsAndPositionsEnum {
+	return &MappingMultiDocsAndPositionsEnum{mergeState: mergeState, doc: -1}
+}
+
+func (de *MappingMultiDocsAndPositionsEnum) reset(postingsEnum *MultiDocsAndPositionsEnum) *MappingMultiDocsAndPositionsEnum {
+	de.numSubs = postingsEnum.numSubs
+	de.subs = postingsEnum.subs
+	de.upto = -1
+	de.current = nil
+	de.doc = -1
+	return de
+}
+
+func (de *MappingMultiDocsAndPositionsEnum) Freq() int {
+	return de.current.Freq()
+}
+
+func (de *MappingMultiDocsAndPositionsEnum) DocId() int {
+	return de.doc
+}
+
+func (de *MappingMultiDocsAndPositionsEnum) Cost() int64 {
+	var cost int64
+	for _, sub := range de.subs[:de.numSubs] {
+		cost += sub.docsAndPositionsEnum.Cost()
+	}
+	return cost
+}
+
+func (de *MappingMultiDocsAndPositionsEnum) Advance(target int) (int, bool) {
+	panic("not supported")
+}
+
+func (de *MappingMultiDocsAndPositionsEnum) NextDoc() (int, bool) {
+	for {
+		if de.current == nil {
+			if de.upto == de.numSubs-1 {
+				de.doc = NO_MORE_DOCS
+				return de.doc, false
+			}
+			de.upto++
+			reader := de.subs[de.upto].slice.readerIndex
+			de.current = de.subs[de.upto].docsAndPositionsEnum.DocsAndPositionsIterator
+			de.currentBase = de.mergeState.docBase[reader]
+			de.currentMap = de.mergeState.docMaps[reader]
+		}
+
+		if doc, more := de.current.NextDoc(); more && doc != NO_MORE_DOCS {
+			// compact deletions
+			if doc = de.currentMap.get(doc); doc == -1 {
+				continue
+			}
+			de.doc = de.currentBase + doc
+			return de.doc, true
+		}
+		de.current = nil
+	}
+}
+
+func (de *MappingMultiDocsAndPositionsEnum) NextPosition() int {
+	return de.current.NextPosition()
+}
+
+func (de *MappingMultiDocsAndPositionsEnum) StartOffset() int {
+	return de.current.StartOffset()
+}
+
+func (de *MappingMultiDocsAndPositionsEnum) EndOffset() int {
+	return de.current.EndOffset()
+}
+
+func (de *MappingMultiDocsAndPositionsEnum) Payload() []byte {
+	return de.current.Payload()
+}
@@ -0,0 +1,300 @@
+package index
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestSegmentMerger(t *testing.T) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vectorsType := document.NewFieldTypeFrom(testIndexedType)
+	vectorsType.SetStoreTermVectors(true)
+	vectorsType.SetStoreTermVectorPositions(true)
+	vectorsType.SetStoreTermVectorOffsets(true)
+	vectorsType.Freeze()
+
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().
+		SetMaxBufferedDocs(10).SetSimilarity(lengthSimilarity{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const numDocs = 30
+	for i := 0; i < numDocs; i++ {
+		doc := newTestDoc(i)
+		for j := 0; j <= i%4; j++ {
+			doc = append(doc, document.NewField("body", fmt.Sprintf("term%v", j), testIndexedType))
+		}
+		if i%3 != 0 {
+			doc = append(doc, document.NewField("tags", "red", vectorsType))
+		}
+		if i%2 == 0 {
+			doc = append(doc, document.NewNumericDocValuesField("dv", int64(i)))
+		}
+		doc = append(doc,
+			document.NewSortedDocValuesField("sorted", []byte(fmt.Sprintf("s%v", i%4))),
+			document.NewSortedSetDocValuesField("set", []byte(fmt.Sprintf("c%v", i%3))))
+		if err = w.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 3, len(r.Leaves()))
+	var readers []*SegmentReader
+	for _, ctx := range r.Leaves() {
+		readers = append(readers, ctx.Reader().(*SegmentReader))
+	}
+	// delete the even docs of the second segment
+	second := readers[1]
+	liveDocs := make(testBits, second.MaxDoc())
+	for i := range liveDocs {
+		liveDocs[i] = i%2 == 1
+	}
+	readers[1] = newSegmentReaderFromCore(second.si, second.core, liveDocs, second.MaxDoc()/2)
+	defer readers[1].decRef()
+
+	var ids []int // original ids of the merged docs
+	docBase := 0
+	for _, reader := range readers {
+		for i := 0; i < reader.MaxDoc(); i++ {
+			if reader.LiveDocs() == nil || reader.LiveDocs().Get(i) {
+				ids = append(ids, docBase+i)
+			}
+		}
+		docBase += reader.MaxDoc()
+	}
+
+	si := &SegmentInfo{
+		dir:         d,
+		version:     util.LUCENE_MAIN_VERSION,
+		name:        "_merged",
+		docCount:    -1,
+		codec:       readers[0].si.info.codec,
+		diagnostics: make(map[string]string),
+		attributes:  make(map[string]string),
+		Files:       make(map[string]bool),
+	}
+	mergeState, err := newSegmentMerger([]AtomicReader{readers[0], readers[1], readers[2]}, si, d, newFieldNumbers(),
+		store.IO_CONTEXT_DEFAULT).merge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, int32(len(ids)), mergeState.segmentInfo.docCount)
+
+	merged, err := NewSegmentReader(NewSegmentInfoPerCommit(*si, 0, -1, -1), 1, store.IO_CONTEXT_READ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer merged.decRef()
+	assertEquals(t, len(ids), merged.MaxDoc())
+	for _, name := range []string{"id", "parity", "body", "tags", "dv", "sorted", "set"} {
+		if _, ok := merged.FieldInfos().byName[name]; !ok {
+			t.Errorf("Merged segment should have field %v", name)
+		}
+	}
+
+	dv, err := merged.NumericDocValues("dv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted, err := merged.SortedDocValues("sorted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := merged.SortedSetDocValues("set")
+	if err != nil {
+		t.Fatal(err)
+	}
+	norms, err := merged.NormValues("body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dv == nil || sorted == nil || set == nil || norms == nil {
+		t.Fatal("Merged segment should have doc values and norms")
+	}
+	assertEquals(t, 4, sorted.ValueCount())
+	assertEquals(t, int64(3), set.ValueCount())
+
+	for doc, id := range ids {
+		visitor := NewDocumentStoredFieldVisitor()
+		if err = merged.Document(doc, visitor); err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, fmt.Sprintf("%04d", id), visitor.Document().Get("id"))
+		assertEquals(t, fmt.Sprintf("document number %v", id), visitor.Document().Get("body"))
+
+		expected := int64(id)
+		if id%2 == 1 {
+			expected = 0
+		}
+		assertEquals(t, expected, dv.Get(doc))
+		assertEquals(t, fmt.Sprintf("s%v", id%4), string(sorted.Get(doc)))
+		set.SetDocument(doc)
+		assertEquals(t, fmt.Sprintf("c%v", id%3), string(set.LookupOrd(set.NextOrd())))
+		assertEquals(t, int64(NO_MORE_ORDS), set.NextOrd())
+		assertEquals(t, int64(id%4+1), norms.Get(doc))
+
+		fields, err := merged.TermVectors(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id%3 == 0 {
+			if fields != nil && fields.Terms("tags") != nil {
+				t.Errorf("Doc %v should have no term vectors", doc)
+			}
+			continue
+		}
+		if fields == nil || fields.Terms("tags") == nil {
+			t.Fatalf("Doc %v should have term vectors", doc)
+		}
+		termsEnum := fields.Terms("tags").Iterator(nil)
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, "red", string(term))
+		dpEnum := termsEnum.DocsAndPositionsByFlags(nil, DocsAndPositionsEnum{},
+			DOCS_POSITIONS_ENUM_FLAG_OFF_SETS)
+		if d, more := dpEnum.NextDoc(); !more || d != 0 {
+			t.Fatalf("Expected doc 0, but was %v", d)
+		}
+		assertEquals(t, 0, dpEnum.NextPosition())
+		assertEquals(t, 0, dpEnum.StartOffset())
+		assertEquals(t, 3, dpEnum.EndOffset())
+	}
+
+	terms := merged.Fields().Terms("parity")
+	if terms == nil {
+		t.Fatal("Merged segment should have postings of parity")
+	}
+	assertEquals(t, len(ids), terms.DocCount())
+	termsEnum := terms.Iterator(nil)
+	for _, parity := range []int{0, 1} {
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, []string{"even", "odd"}[parity], string(term))
+		var expected []int
+		for doc, id := range ids {
+			if id%2 == parity {
+				expected = append(expected, doc)
+			}
+		}
+		assertEquals(t, len(expected), termsEnum.DocFreq())
+		assertEquals(t, int64(2*len(expected)), termsEnum.TotalTermFreq())
+		docsEnum := termsEnum.DocsByFlags(nil, DOCS_ENUM_EMPTY, DOCS_ENUM_FLAG_FREQS)
+		for _, doc := range expected {
+			d, more := docsEnum.NextDoc()
+			if !more {
+				t.Fatalf("Expected doc %v, but reached the end", doc)
+			}
+			assertEquals(t, doc, d)
+			assertEquals(t, 2, docsEnum.Freq())
+		}
+		if d, more := docsEnum.NextDoc(); more {
+			t.Errorf("Unexpected doc %v", d)
+		}
+	}
+	if term, _ := termsEnum.Next(); term != nil {
+		t.Errorf("Unexpected term %v", string(term))
+	}
+}
+
+// Returns the positions of every term of field in r, by term and doc.
+func allPositions(t *testing.T, r AtomicReader, field string) map[string]map[int][]int {
+	ans := make(map[string]map[int][]int)
+	termsEnum := r.Terms(field).Iterator(nil)
+	var de DocsAndPositionsEnum
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if term == nil {
+			return ans
+		}
+		docs := make(map[int][]int)
+		de = termsEnum.DocsAndPositionsByFlags(nil, de, 0)
+		for doc, more := de.NextDoc(); more; doc, more = de.NextDoc() {
+			docs[doc] = readPositions(t, de)
+		}
+		ans[string(term)] = docs
+	}
+}
+
+func TestSegmentMergerPositions(t *testing.T) {
+	dir := copyTestIndex(t, "../search/testdata/win8/belfrysample")
+	defer os.RemoveAll(dir)
+	d, err := store.OpenFSDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxDoc := r.MaxDoc()
+	expected := allPositions(t, r.Leaves()[0].Reader().(AtomicReader), "content")
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the added segment is merged with the positional segment written
+	// by Lucene
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().
+		SetOpenMode(OPEN_MODE_APPEND).SetAnalyzer(newTestSpaceAnalyzer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.AddDocument([]document.IndexableField{
+		document.NewTextField("extra", "belfry bells belfry", document.STORE_NO),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.ForceMerge(1); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if r, err = OpenDirectoryReader(d); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, len(r.Leaves()))
+	assertEquals(t, maxDoc+1, r.MaxDoc())
+	merged := r.Leaves()[0].Reader().(AtomicReader)
+	assertEquals(t, INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS, merged.FieldInfos().byName["content"].indexOptions)
+	assertEquals(t, fmt.Sprint(expected), fmt.Sprint(allPositions(t, merged, "content")))
+	assertEquals(t, fmt.Sprint(map[string]map[int][]int{
+		"bells":  {maxDoc: {1}},
+		"belfry": {maxDoc: {0, 2}},
+	}), fmt.Sprint(allPositions(t, merged, "extra")))
+}
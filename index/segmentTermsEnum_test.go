@@ -43,3 +43,89 @@ func TestInitTerm(t *testing.T) {
 		t.Error("SeekExact should return true.")
 	}
 }
+
+func TestSeekExactFromLast(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := r.Context().Leaves()[0].reader.Fields().Terms("content")
+	termsEnum := terms.Iterator(nil)
+	target := NewTerm("content", "bat").Bytes
+	ok, err := termsEnum.SeekExact(target)
+	if err != nil || !ok {
+		t.Fatalf("SeekExact should find the term: %v", err)
+	}
+	docFreq, totalTermFreq := termsEnum.DocFreq(), termsEnum.TotalTermFreq()
+	if docFreq <= 0 || totalTermFreq < int64(docFreq) {
+		t.Errorf("Invalid stats: docFreq=%v totalTermFreq=%v", docFreq, totalTermFreq)
+	}
+	state := termsEnum.TermState()
+
+	termsEnum = terms.Iterator(nil)
+	err = termsEnum.SeekExactFromLast(target, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "bat", string(termsEnum.Term()))
+	assertEquals(t, docFreq, termsEnum.DocFreq())
+	assertEquals(t, totalTermFreq, termsEnum.TotalTermFreq())
+}
+
+func TestOrdUnsupported(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	termsEnum := r.Context().Leaves()[0].reader.Fields().Terms("content").Iterator(nil)
+	if _, err = termsEnum.Ord(); err != ErrUnsupported {
+		t.Errorf("Ord() should return ErrUnsupported, but was %v", err)
+	}
+	if err = termsEnum.SeekExactByPosition(0); err != ErrUnsupported {
+		t.Errorf("SeekExactByPosition() should return ErrUnsupported, but was %v", err)
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := r.Context().Leaves()[0].reader.Fields().Terms("content").(*FieldReader)
+	stats, err := terms.ComputeStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(stats)
+	assertEquals(t, "content", stats.Field)
+	assertEquals(t, terms.numTerms, stats.TotalTermCount)
+
+	var totalTermBytes int64
+	termsEnum := terms.Iterator(nil)
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if term == nil {
+			break
+		}
+		totalTermBytes += int64(len(term))
+	}
+	assertEquals(t, totalTermBytes, stats.TotalTermBytes)
+	if stats.TotalBlockCount == 0 || stats.IndexNumBytes == 0 {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+}
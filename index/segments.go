@@ -6,6 +6,7 @@ import (
 	"github.com/balzaczyy/golucene/util"
 	"io"
 	"log"
+	"strconv"
 	"sync/atomic"
 )
 
@@ -14,20 +15,72 @@ type SegmentInfoPerCommit struct {
 	delCount        int
 	delGen          int64
 	nextWriteDelGen int64
+	// Generation number of the FieldInfos (-1 if there are no updates)
+	fieldInfosGen int64
+	// Normally 1 + fieldInfosGen, unless an error was hit on last
+	// attempt to write
+	nextWriteFieldInfosGen int64
+	// Track the per-generation updates files. Never modified in place,
+	// since the map is shared by the copies of this struct.
+	genUpdatesFiles map[int64]map[string]bool
 }
 
-func NewSegmentInfoPerCommit(info SegmentInfo, delCount int, delGen int64) SegmentInfoPerCommit {
+func NewSegmentInfoPerCommit(info SegmentInfo, delCount int, delGen, fieldInfosGen int64) SegmentInfoPerCommit {
 	nextWriteDelGen := int64(1)
 	if delGen != -1 {
 		nextWriteDelGen = delGen + 1
 	}
-	return SegmentInfoPerCommit{info, delCount, delGen, nextWriteDelGen}
+	nextWriteFieldInfosGen := int64(1)
+	if fieldInfosGen != -1 {
+		nextWriteFieldInfosGen = fieldInfosGen + 1
+	}
+	return SegmentInfoPerCommit{info, delCount, delGen, nextWriteDelGen,
+		fieldInfosGen, nextWriteFieldInfosGen, make(map[int64]map[string]bool)}
 }
 
 func (si SegmentInfoPerCommit) HasDeletions() bool {
 	return si.delGen != -1
 }
 
+// Returns true if there are any field updates for the segment in
+// this commit.
+func (si SegmentInfoPerCommit) HasFieldUpdates() bool {
+	return si.fieldInfosGen != -1
+}
+
+// Returns all files in use by this segment.
+func (si SegmentInfoPerCommit) Files() []string {
+	// Start from the wrapped info's files:
+	files := make([]string, 0, len(si.info.Files)+1)
+	for file, _ := range si.info.Files {
+		files = append(files, file)
+	}
+	// Must separately add any live docs files:
+	if si.HasDeletions() {
+		files = append(files, util.FileNameFromGeneration(si.info.name, LUCENE40_DELETES_EXTENSION, si.delGen))
+	}
+	// Must separately add any field updates files:
+	for _, updateFiles := range si.genUpdatesFiles {
+		for file, _ := range updateFiles {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Returns total size in bytes of all files for this segment.
+func (si SegmentInfoPerCommit) SizeInBytes() (int64, error) {
+	var sum int64
+	for _, fileName := range si.Files() {
+		length, err := si.info.dir.FileLength(fileName)
+		if err != nil {
+			return 0, err
+		}
+		sum += length
+	}
+	return sum, nil
+}
+
 func (si SegmentInfoPerCommit) StringOf(dir store.Directory, pendingDelCount int) string {
 	return si.info.StringOf(dir, si.delCount+pendingDelCount)
 }
@@ -37,15 +90,24 @@ func (si SegmentInfoPerCommit) String() string {
 	if si.delGen != -1 {
 		s = fmt.Sprintf("%v:delGen=%v", s, si.delGen)
 	}
+	if si.fieldInfosGen != -1 {
+		s = fmt.Sprintf("%v:fieldInfosGen=%v", s, si.fieldInfosGen)
+	}
 	return s
 }
 
 type SegmentReader struct {
 	*AtomicReaderImpl
 	si       SegmentInfoPerCommit
-	liveDocs *util.Bits
+	liveDocs util.Bits
 	numDocs  int
-	core     SegmentCoreReaders
+	core     *SegmentCoreReaders
+
+	// the FieldInfos of the latest generation if the segment has
+	// field updates, or else the core's
+	fieldInfos FieldInfos
+	// DocValuesProducer of each generation of updated doc values
+	dvProducers map[int64]DocValuesProducer
 }
 
 func NewSegmentReader(si SegmentInfoPerCommit, termInfosIndexDivisor int, context store.IOContext) (r *SegmentReader, err error) {
@@ -60,6 +122,7 @@ func NewSegmentReader(si SegmentInfoPerCommit, termInfosIndexDivisor int, contex
 	if err != nil {
 		return r, err
 	}
+	r.fieldInfos = r.core.fieldInfos
 	success := false
 	defer func() {
 		// With lock-less commits, it's entirely possible (and
@@ -72,12 +135,20 @@ func NewSegmentReader(si SegmentInfoPerCommit, termInfosIndexDivisor int, contex
 			if err != nil {
 				log.Print(err)
 			}
-			r.core.decRef()
+			r.doClose()
 		}
 	}()
 
+	if err = r.initFieldUpdates(); err != nil {
+		return r, err
+	}
+
 	if si.HasDeletions() {
-		panic("not supported yet")
+		// NOTE: the bitvector is stored using the regular directory, not cfs
+		r.liveDocs, err = si.info.codec.ReadLiveDocs(r.Directory(), si, store.IO_CONTEXT_READONCE)
+		if err != nil {
+			return r, err
+		}
 	} else {
 		// assert si.getDelCount() == 0
 		// r.liveDocs = nil
@@ -87,28 +158,126 @@ func NewSegmentReader(si SegmentInfoPerCommit, termInfosIndexDivisor int, contex
 	return r, nil
 }
 
+/*
+Create new SegmentReader sharing core from a previous SegmentReader
+and using the provided in-memory liveDocs. Used by reopening a reader
+whose segment got new deletions.
+*/
+func newSegmentReaderFromCore(si SegmentInfoPerCommit, core *SegmentCoreReaders,
+	liveDocs util.Bits, numDocs int) *SegmentReader {
+	r := &SegmentReader{si: si, core: core, liveDocs: liveDocs, numDocs: numDocs,
+		fieldInfos: core.fieldInfos}
+	r.AtomicReaderImpl = newAtomicReader(r)
+	r.ARFieldsReader = r
+	core.incRef()
+	return r
+}
+
+/*
+Create new SegmentReader sharing core from a previous SegmentReader
+and loading new live docs and doc values from newer generations of
+deletes and field updates.
+*/
+func openSegmentReaderFromCore(si SegmentInfoPerCommit, core *SegmentCoreReaders) (r *SegmentReader, err error) {
+	var liveDocs util.Bits
+	if si.HasDeletions() {
+		// NOTE: the bitvector is stored using the regular directory, not cfs
+		if liveDocs, err = si.info.codec.ReadLiveDocs(si.info.dir, si, store.IO_CONTEXT_READONCE); err != nil {
+			return nil, err
+		}
+	} // else assert si.getDelCount() == 0
+	r = newSegmentReaderFromCore(si, core, liveDocs, int(si.info.docCount)-si.delCount)
+	if err = r.initFieldUpdates(); err != nil {
+		r.doClose()
+		return nil, err
+	}
+	return r, nil
+}
+
+/*
+Reads the FieldInfos of the latest generation, and opens the doc
+values of each generation of updated fields, if the segment has
+field updates.
+*/
+func (r *SegmentReader) initFieldUpdates() error {
+	if !r.si.HasFieldUpdates() {
+		return nil
+	}
+	codec := r.si.info.codec
+	// NOTE: updates files are stored using the regular directory, not cfs
+	dir := r.si.info.dir
+	fieldInfos, err := codec.ReadFieldInfos(dir, r.si.info.name,
+		strconv.FormatInt(r.si.fieldInfosGen, 36), store.IO_CONTEXT_READONCE)
+	if err != nil {
+		return err
+	}
+	r.fieldInfos = fieldInfos
+
+	genInfos := make(map[int64][]FieldInfo)
+	for _, fi := range fieldInfos.values {
+		if fi.dvGen != -1 {
+			genInfos[fi.dvGen] = append(genInfos[fi.dvGen], fi)
+		}
+	}
+	r.dvProducers = make(map[int64]DocValuesProducer)
+	for gen, infos := range genInfos {
+		state := newSegmentReadState(dir, r.si.info, NewFieldInfos(infos),
+			store.IO_CONTEXT_READ, r.core.termsIndexDivisor)
+		state.segmentSuffix = strconv.FormatInt(gen, 36)
+		p, err := codec.GetDocValuesProducer(state)
+		if err != nil {
+			return err
+		}
+		r.dvProducers[gen] = p
+	}
+	return nil
+}
+
 func (r *SegmentReader) LiveDocs() util.Bits {
 	r.ensureOpen()
 	return r.liveDocs
 }
 
 func (r *SegmentReader) doClose() error {
-	r.core.decRef()
-	return nil
+	err := r.core.decRef()
+	for _, p := range r.dvProducers {
+		if e := p.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Expert: adds a CoreClosedListener to this reader's shared core
+func (r *SegmentReader) AddCoreClosedListener(listener CoreClosedListener) {
+	r.ensureOpen()
+	r.core.addCoreClosedListener(listener)
+}
+
+// Expert: removes a CoreClosedListener from this reader's shared core
+func (r *SegmentReader) RemoveCoreClosedListener(listener CoreClosedListener) {
+	r.ensureOpen()
+	r.core.removeCoreClosedListener(listener)
 }
 
 func (r *SegmentReader) FieldInfos() FieldInfos {
 	r.ensureOpen()
-	return r.core.fieldInfos
+	return r.fieldInfos
 }
 
+/*
+Expert: retrieve thread-private StoredFieldsReader. Since there is no
+thread-local in Go, each call returns a new clone which must not be
+shared between goroutines.
+*/
 func (r *SegmentReader) FieldsReader() StoredFieldsReader {
 	r.ensureOpen()
-	panic("not implemented yet")
+	return r.core.fieldsReaderOrig.clone()
 }
 
-func (r *SegmentReader) Document(docId int, visitor StoredFieldVisitor) error {
-	panic("not implemented yet")
+func (r *SegmentReader) Document(docID int, visitor StoredFieldVisitor) error {
+	r.checkBounds(docID)
+	return r.FieldsReader().visitDocument(docID, visitor)
 }
 
 func (r *SegmentReader) Fields() Fields {
@@ -126,12 +295,32 @@ func (r *SegmentReader) MaxDoc() int {
 	return int(r.si.info.docCount)
 }
 
+/*
+Expert: retrieve thread-private TermVectorsReader, or nil if term
+vectors were not indexed. Since there is no thread-local in Go, each
+call returns a new clone which must not be shared between goroutines.
+*/
 func (r *SegmentReader) TermVectorsReader() TermVectorsReader {
-	panic("not implemented yet")
+	r.ensureOpen()
+	if r.core.termVectorsReaderOrig == nil {
+		return nil
+	}
+	return r.core.termVectorsReaderOrig.clone()
 }
 
 func (r *SegmentReader) TermVectors(docID int) (fs Fields, err error) {
-	panic("not implemented yet")
+	termVectorsReader := r.TermVectorsReader()
+	if termVectorsReader == nil {
+		return nil, nil
+	}
+	r.checkBounds(docID)
+	return termVectorsReader.get(docID)
+}
+
+func (r *SegmentReader) checkBounds(docID int) {
+	if docID < 0 || docID >= r.MaxDoc() {
+		panic(fmt.Sprintf("docID must be >= 0 and < maxDoc=%v (got docID=%v)", r.MaxDoc(), docID))
+	}
 }
 
 // SegmentReader.java L179
@@ -170,31 +359,98 @@ func (r *SegmentReader) TermInfosIndexDivisor() int {
 
 func (r *SegmentReader) NumericDocValues(field string) (v NumericDocValues, err error) {
 	r.ensureOpen()
-	panic("not implemented yet")
+	if p, fi := r.updatedDocValues(field, DOC_VALUES_TYPE_NUMERIC); p != nil {
+		return p.Numeric(fi)
+	}
+	return r.core.NumericDocValues(field)
 }
 
 func (r *SegmentReader) BinaryDocValues(field string) (v BinaryDocValues, err error) {
 	r.ensureOpen()
-	panic("not implemented yet")
+	if p, fi := r.updatedDocValues(field, DOC_VALUES_TYPE_BINARY); p != nil {
+		return p.Binary(fi)
+	}
+	return r.core.BinaryDocValues(field)
+}
+
+// Returns the DocValuesProducer of field if its doc values of the
+// specified type were updated, or nil if they come from the core.
+func (r *SegmentReader) updatedDocValues(field string, dvType DocValuesType) (DocValuesProducer, FieldInfo) {
+	fi, ok := r.fieldInfos.byName[field]
+	if !ok || fi.docValueType != dvType || fi.dvGen == -1 {
+		return nil, fi
+	}
+	return r.dvProducers[fi.dvGen], fi
 }
 
 func (r *SegmentReader) SortedDocValues(field string) (v SortedDocValues, err error) {
 	r.ensureOpen()
-	panic("not implemented yet")
+	return r.core.SortedDocValues(field)
 }
 
 func (r *SegmentReader) SortedSetDocValues(field string) (v SortedSetDocValues, err error) {
 	r.ensureOpen()
-	panic("not implemented yet")
+	return r.core.SortedSetDocValues(field)
 }
 
 func (r *SegmentReader) NormValues(field string) (v NumericDocValues, err error) {
 	r.ensureOpen()
-	panic("not implemented yet")
+	return r.core.NormValues(field)
+}
+
+// Returns the FieldInfo of field if it has doc values of specified type.
+func (r *SegmentCoreReaders) docValuesFieldInfo(field string, dvType DocValuesType) (fi FieldInfo, ok bool) {
+	if fi, ok = r.fieldInfos.byName[field]; !ok {
+		return fi, false
+	}
+	if fi.docValueType != dvType {
+		// Field DocValues are different than requested type
+		return fi, false
+	}
+	// assert dvProducer != nil
+	return fi, true
+}
+
+func (r *SegmentCoreReaders) NumericDocValues(field string) (v NumericDocValues, err error) {
+	if fi, ok := r.docValuesFieldInfo(field, DOC_VALUES_TYPE_NUMERIC); ok {
+		return r.dvProducer.Numeric(fi)
+	}
+	return nil, nil
+}
+
+func (r *SegmentCoreReaders) BinaryDocValues(field string) (v BinaryDocValues, err error) {
+	if fi, ok := r.docValuesFieldInfo(field, DOC_VALUES_TYPE_BINARY); ok {
+		return r.dvProducer.Binary(fi)
+	}
+	return nil, nil
+}
+
+func (r *SegmentCoreReaders) SortedDocValues(field string) (v SortedDocValues, err error) {
+	if fi, ok := r.docValuesFieldInfo(field, DOC_VALUES_TYPE_SORTED); ok {
+		return r.dvProducer.Sorted(fi)
+	}
+	return nil, nil
+}
+
+func (r *SegmentCoreReaders) SortedSetDocValues(field string) (v SortedSetDocValues, err error) {
+	if fi, ok := r.docValuesFieldInfo(field, DOC_VALUES_TYPE_SORTED_SET); ok {
+		return r.dvProducer.SortedSet(fi)
+	}
+	return nil, nil
+}
+
+func (r *SegmentCoreReaders) NormValues(field string) (v NumericDocValues, err error) {
+	fi, ok := r.fieldInfos.byName[field]
+	if !ok || fi.normType == 0 {
+		// Field does not exist or does not index norms
+		return nil, nil
+	}
+	// assert normsProducer != nil
+	return r.normsProducer.Numeric(fi)
 }
 
 type CoreClosedListener interface {
-	onClose(r *SegmentReader)
+	OnClose(r *SegmentReader)
 }
 
 type SegmentCoreReaders struct {
@@ -220,13 +476,13 @@ type SegmentCoreReaders struct {
 }
 
 func newSegmentCoreReaders(owner *SegmentReader, dir store.Directory, si SegmentInfoPerCommit,
-	context store.IOContext, termsIndexDivisor int) (self SegmentCoreReaders, err error) {
+	context store.IOContext, termsIndexDivisor int) (self *SegmentCoreReaders, err error) {
 	if termsIndexDivisor == 0 {
 		panic("indexDivisor must be < 0 (don't load terms index) or greater than 0 (got 0)")
 	}
 	log.Printf("Initializing SegmentCoreReaders from directory: %v", dir)
 
-	self = SegmentCoreReaders{refCount: 1}
+	self = &SegmentCoreReaders{refCount: 1}
 
 	log.Print("Initializing listeners...")
 	self.addListener = make(chan CoreClosedListener)
@@ -257,7 +513,7 @@ func newSegmentCoreReaders(owner *SegmentReader, dir store.Directory, si Segment
 				log.Print("Shutting down SegmentCoreReaders...")
 				isRunning = false
 				for _, v := range coreClosedListeners {
-					v.onClose(owner)
+					v.OnClose(owner)
 				}
 			}
 		}
@@ -289,7 +545,7 @@ func newSegmentCoreReaders(owner *SegmentReader, dir store.Directory, si Segment
 	}
 	log.Printf("CFS Directory: %v", cfsDir)
 	log.Print("Reading FieldInfos...")
-	self.fieldInfos, err = codec.ReadFieldInfos(cfsDir, si.info.name, store.IO_CONTEXT_READONCE)
+	self.fieldInfos, err = codec.ReadFieldInfos(cfsDir, si.info.name, "", store.IO_CONTEXT_READONCE)
 	if err != nil {
 		return self, err
 	}
@@ -357,13 +613,47 @@ func newSegmentCoreReaders(owner *SegmentReader, dir store.Directory, si Segment
 	return self, nil
 }
 
-func (r *SegmentCoreReaders) decRef() {
+func (r *SegmentCoreReaders) incRef() {
+	if n := atomic.AddInt32(&r.refCount, 1); n <= 1 {
+		panic("SegmentCoreReaders is already closed")
+	}
+}
+
+func (r *SegmentCoreReaders) decRef() error {
 	if atomic.AddInt32(&r.refCount, -1) == 0 {
-		util.Close( /*self.termVectorsLocal, self.fieldsReaderLocal, docValuesLocal, normsLocal,*/
-			r.fields, r.dvProducer, r.termVectorsReaderOrig, r.fieldsReaderOrig,
-			r.cfsReader, r.normsProducer)
+		closers := make([]io.Closer, 0, 6)
+		/*self.termVectorsLocal, self.fieldsReaderLocal, docValuesLocal, normsLocal,*/
+		if r.fields != nil {
+			closers = append(closers, r.fields)
+		}
+		if r.dvProducer != nil {
+			closers = append(closers, r.dvProducer)
+		}
+		if r.termVectorsReaderOrig != nil {
+			closers = append(closers, r.termVectorsReaderOrig)
+		}
+		if r.fieldsReaderOrig != nil {
+			closers = append(closers, r.fieldsReaderOrig)
+		}
+		if r.cfsReader != nil {
+			closers = append(closers, r.cfsReader)
+		}
+		if r.normsProducer != nil {
+			closers = append(closers, r.normsProducer)
+		}
+		err := util.Close(closers...)
 		r.notifyListener <- r.owner
+		return err
 	}
+	return nil
+}
+
+func (r *SegmentCoreReaders) addCoreClosedListener(listener CoreClosedListener) {
+	r.addListener <- listener
+}
+
+func (r *SegmentCoreReaders) removeCoreClosedListener(listener CoreClosedListener) {
+	r.removeListener <- listener
 }
 
 type SegmentReadState struct {
@@ -380,6 +670,22 @@ func newSegmentReadState(dir store.Directory, info SegmentInfo, fieldInfos Field
 	return SegmentReadState{dir, info, fieldInfos, context, termsIndexDivisor, ""}
 }
 
+// Holder class for common parameters used during write.
+type SegmentWriteState struct {
+	directory   store.Directory
+	segmentInfo *SegmentInfo
+	fieldInfos  FieldInfos
+	context     store.IOContext
+	// Suffix used to generate per-format file names, e.g.
+	// "Lucene41_0".
+	segmentSuffix string
+}
+
+func newSegmentWriteState(dir store.Directory, info *SegmentInfo, fieldInfos FieldInfos,
+	context store.IOContext) SegmentWriteState {
+	return SegmentWriteState{dir, info, fieldInfos, context, ""}
+}
+
 type DocValuesProducer interface {
 	io.Closer
 	Numeric(field FieldInfo) (v NumericDocValues, err error)
@@ -388,25 +694,6 @@ type DocValuesProducer interface {
 	SortedSet(field FieldInfo) (v SortedSetDocValues, err error)
 }
 
-type NumericDocValues interface {
-	value(docID int) int64
-}
-type BinaryDocValues interface {
-	value(docID int, result []byte)
-}
-type SortedDocValues interface {
-	BinaryDocValues
-	ord(docID int) int
-	lookupOrd(ord int, result []byte)
-	valueCount() int
-}
-type SortedSetDocValues interface {
-	nextOrd() int64
-	setDocument(docID int)
-	lookupOrd(ord int64, result []byte)
-	valueCount() int64
-}
-
 type StoredFieldVisitor interface {
 	binaryField(fi FieldInfo, value []byte) error
 	stringField(fi FieldInfo, value string) error
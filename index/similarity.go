@@ -0,0 +1,40 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/util"
+	"math"
+)
+
+// search/similarities/Similarity.java
+
+/*
+The indexing time part of a Similarity, which computes the norm of
+each indexed field. It is embedded by search.Similarity, and declared
+here so that IndexWriterConfig can refer to it.
+*/
+type Similarity interface {
+	/*
+		Computes the normalization value for a field, given the
+		accumulated state of term processing for this field (see
+		FieldInvertState).
+
+		Matches in longer fields are less precise, so implementations of
+		this method usually return smaller values when state.Length() is
+		large, and larger values when state.Length() is small.
+	*/
+	ComputeNorm(state *FieldInvertState) int64
+}
+
+/*
+The Similarity used by IndexWriterConfig by default. It computes the
+same norms as search.DefaultSimilarity: the boost of the field times
+one over the square root of its number of terms, not counting
+overlapping tokens, encoded in a single byte.
+*/
+type defaultSimilarity struct{}
+
+func (s defaultSimilarity) ComputeNorm(state *FieldInvertState) int64 {
+	numTerms := state.Length() - state.NumOverlap()
+	norm := state.Boost() * float32(1/math.Sqrt(float64(numTerms)))
+	return int64(int8(util.FloatToByte315(norm)))
+}
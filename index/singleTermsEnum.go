@@ -0,0 +1,39 @@
+package index
+
+import (
+	"bytes"
+)
+
+// SingleTermsEnum.java
+
+/*
+Subclass of FilteredTermsEnum for enumerating a single term.
+
+For example, this can be used by MultiTermQuerys that need only visit
+one term, but want to preserve MultiTermQuery semantics such as
+rewrite methods.
+*/
+type SingleTermsEnum struct {
+	*FilteredTermsEnumImpl
+	singleRef []byte
+}
+
+/*
+Creates a new SingleTermsEnum.
+
+After calling the constructor the enumeration is already pointing to
+the term, if it exists.
+*/
+func NewSingleTermsEnum(tenum TermsEnum, termText []byte) *SingleTermsEnum {
+	ans := &SingleTermsEnum{singleRef: termText}
+	ans.FilteredTermsEnumImpl = NewFilteredTermsEnum(ans, tenum, true)
+	ans.SetInitialSeekTerm(termText)
+	return ans
+}
+
+func (e *SingleTermsEnum) Accept(term []byte) AcceptStatus {
+	if bytes.Equal(term, e.singleRef) {
+		return ACCEPT_STATUS_YES
+	}
+	return ACCEPT_STATUS_END
+}
@@ -0,0 +1,277 @@
+package index
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/store"
+	"math"
+)
+
+// MultiLevelSkipListReader.java
+
+/*
+Hooks of a multi-level skip list reader, implemented by the concrete
+type which embeds MultiLevelSkipListReader.
+*/
+type MultiLevelSkipListReaderSPI interface {
+	/*
+		Subclasses must implement the actual skip data encoding in this
+		method.
+	*/
+	readSkipData(level int, skipStream store.IndexInput) (delta int, err error)
+	// Seeks the skip entry on the given level
+	seekChild(level int) error
+	// Copies the values of the last read skip entry on this level
+	setLastSkipData(level int)
+}
+
+/*
+This abstract class reads skip lists with multiple levels.
+
+See MultiLevelSkipListWriter for the information about the encoding
+of the multi level skip lists.
+
+Subclasses must implement the abstract method readSkipData(level,
+skipStream) which defines the actual format of the skip data.
+*/
+type MultiLevelSkipListReader struct {
+	spi MultiLevelSkipListReaderSPI
+
+	// the maximum number of skip levels possible for this index
+	maxNumberOfSkipLevels int
+
+	// number of levels in this skip list
+	numberOfSkipLevels int
+
+	docCount    int
+	haveSkipped bool
+
+	// skipStream for each level.
+	skipStream []store.IndexInput
+
+	// The start pointer of each skip level.
+	skipPointer []int64
+
+	// skipInterval of each level.
+	skipInterval []int
+
+	// Number of docs skipped per level.
+	numSkipped []int
+
+	// Doc id of current skip entry per level.
+	skipDoc []int
+
+	// Doc id of last read skip entry with docId <= target.
+	lastDoc int
+
+	// Child pointer of current skip entry per level.
+	childPointer []int64
+
+	// childPointer of last read skip entry with docId <= target.
+	lastChildPointer int64
+
+	skipMultiplier int
+}
+
+// Creates a MultiLevelSkipListReader.
+func newMultiLevelSkipListReader(spi MultiLevelSkipListReaderSPI, skipStream store.IndexInput,
+	maxSkipLevels, skipInterval, skipMultiplier int) *MultiLevelSkipListReader {
+	ans := &MultiLevelSkipListReader{
+		spi:                   spi,
+		maxNumberOfSkipLevels: maxSkipLevels,
+		skipStream:            make([]store.IndexInput, maxSkipLevels),
+		skipPointer:           make([]int64, maxSkipLevels),
+		skipInterval:          make([]int, maxSkipLevels),
+		numSkipped:            make([]int, maxSkipLevels),
+		skipDoc:               make([]int, maxSkipLevels),
+		childPointer:          make([]int64, maxSkipLevels),
+		skipMultiplier:        skipMultiplier,
+	}
+	ans.skipStream[0] = skipStream
+	ans.skipInterval[0] = skipInterval
+	for i := 1; i < maxSkipLevels; i++ {
+		// cache skip intervals
+		ans.skipInterval[i] = ans.skipInterval[i-1] * skipMultiplier
+	}
+	return ans
+}
+
+/*
+Returns the id of the doc to which the last call of SkipTo() has
+skipped.
+*/
+func (r *MultiLevelSkipListReader) Doc() int {
+	return r.lastDoc
+}
+
+/*
+Skips entries to the first beyond the current whose document number
+is greater than or equal to target. Returns the entry's document
+number.
+*/
+func (r *MultiLevelSkipListReader) SkipTo(target int) (n int, err error) {
+	if !r.haveSkipped {
+		// first time, load skip levels
+		if err = r.loadSkipLevels(); err != nil {
+			return 0, err
+		}
+		r.haveSkipped = true
+	}
+
+	// walk up the levels until highest level is found that has a skip
+	// for this target
+	level := 0
+	for level < r.numberOfSkipLevels-1 && target > r.skipDoc[level+1] {
+		level++
+	}
+
+	for level >= 0 {
+		if target > r.skipDoc[level] {
+			ok, err := r.loadNextSkip(level)
+			if err != nil {
+				return 0, err
+			}
+			if !ok {
+				continue
+			}
+		} else {
+			// no more skips on this level, go down one level
+			if level > 0 && r.lastChildPointer > r.skipStream[level-1].FilePointer() {
+				if err = r.spi.seekChild(level - 1); err != nil {
+					return 0, err
+				}
+			}
+			level--
+		}
+	}
+
+	return r.numSkipped[0] - r.skipInterval[0] - 1, nil
+}
+
+func (r *MultiLevelSkipListReader) loadNextSkip(level int) (ok bool, err error) {
+	// we have to skip, the target document is greater than the current
+	// skip list entry
+	r.spi.setLastSkipData(level)
+
+	r.numSkipped[level] += r.skipInterval[level]
+
+	if r.numSkipped[level] > r.docCount {
+		// this skip list is exhausted
+		r.skipDoc[level] = math.MaxInt32
+		if r.numberOfSkipLevels > level {
+			r.numberOfSkipLevels = level
+		}
+		return false, nil
+	}
+
+	// read next skip entry
+	delta, err := r.spi.readSkipData(level, r.skipStream[level])
+	if err != nil {
+		return false, err
+	}
+	r.skipDoc[level] += delta
+
+	if level != 0 {
+		// read the child pointer if we are not on the leaf level
+		n, err := r.skipStream[level].ReadVLong()
+		if err != nil {
+			return false, err
+		}
+		r.childPointer[level] = n + r.skipPointer[level-1]
+	}
+
+	return true, nil
+}
+
+// Seeks the skip entry on the given level
+func (r *MultiLevelSkipListReader) seekChild(level int) (err error) {
+	r.skipStream[level].Seek(r.lastChildPointer)
+	r.numSkipped[level] = r.numSkipped[level+1] - r.skipInterval[level+1]
+	r.skipDoc[level] = r.lastDoc
+	if level > 0 {
+		n, err := r.skipStream[level].ReadVLong()
+		if err != nil {
+			return err
+		}
+		r.childPointer[level] = n + r.skipPointer[level-1]
+	}
+	return nil
+}
+
+func (r *MultiLevelSkipListReader) Close() (err error) {
+	for i := 1; i < len(r.skipStream); i++ {
+		if r.skipStream[i] != nil {
+			if err2 := r.skipStream[i].Close(); err2 != nil && err == nil {
+				err = err2
+			}
+		}
+	}
+	return err
+}
+
+// Initializes the reader, for reuse on a new term.
+func (r *MultiLevelSkipListReader) init(skipPointer int64, df int) {
+	r.skipPointer[0] = skipPointer
+	r.docCount = df
+	if skipPointer < 0 || skipPointer > r.skipStream[0].Length() {
+		panic(fmt.Sprintf("invalid skip pointer: %v, length=%v", skipPointer, r.skipStream[0].Length()))
+	}
+	for i := range r.skipDoc {
+		r.skipDoc[i] = 0
+		r.numSkipped[i] = 0
+		r.childPointer[i] = 0
+	}
+
+	r.haveSkipped = false
+	for i := 1; i < r.numberOfSkipLevels; i++ {
+		r.skipStream[i] = nil
+	}
+}
+
+// Loads the skip levels
+func (r *MultiLevelSkipListReader) loadSkipLevels() error {
+	if r.docCount <= r.skipInterval[0] {
+		r.numberOfSkipLevels = 1
+	} else {
+		r.numberOfSkipLevels = 1 + mathLog(r.docCount/r.skipInterval[0], r.skipMultiplier)
+	}
+
+	if r.numberOfSkipLevels > r.maxNumberOfSkipLevels {
+		r.numberOfSkipLevels = r.maxNumberOfSkipLevels
+	}
+
+	r.skipStream[0].Seek(r.skipPointer[0])
+
+	for i := r.numberOfSkipLevels - 1; i > 0; i-- {
+		// the length of the current level
+		length, err := r.skipStream[0].ReadVLong()
+		if err != nil {
+			return err
+		}
+
+		// the start pointer of the current level
+		r.skipPointer[i] = r.skipStream[0].FilePointer()
+		// TODO buffer the top level as Java's SkipBuffer does
+		// clone this stream, it is already at the start of the current level
+		r.skipStream[i] = r.skipStream[0].Clone()
+
+		// move base stream beyond the current level
+		r.skipStream[0].Seek(r.skipStream[0].FilePointer() + length)
+	}
+
+	// use base stream for the lowest level
+	r.skipPointer[0] = r.skipStream[0].FilePointer()
+	return nil
+}
+
+// Returns x <= 0 ? 0 : floor(log(x) / log(base))
+func mathLog(x, base int) int {
+	if base <= 1 {
+		panic("base must be > 1")
+	}
+	ret := 0
+	for x >= base {
+		x /= base
+		ret++
+	}
+	return ret
+}
@@ -0,0 +1,122 @@
+package index
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// SlowCompositeReaderWrapper.java
+
+/*
+This type forces a composite reader (eg a MultiReader or
+DirectoryReader) to emulate an atomic reader. This requires
+implementing the postings APIs on-the-fly, using the static methods
+in MultiFields and MultiDocValues, by stepping through the sub
+readers to merge fields/terms, appending docs, etc.
+
+NOTE: this type almost always results in a performance hit. If this
+is important to your use case, you'll get better performance by
+gathering the sub readers using IndexReader.Leaves() and then
+operate per-AtomicReader, instead of using this type.
+*/
+type SlowCompositeReaderWrapper struct {
+	*AtomicReaderImpl
+	in         CompositeReader
+	fields     Fields
+	liveDocs   util.Bits
+	fieldInfos FieldInfos
+}
+
+/*
+This method is sugar for getting an AtomicReader from an IndexReader
+of any kind. If the reader is already atomic, it is returned
+unchanged, otherwise wrapped by this type.
+*/
+func WrapSlowCompositeReader(reader IndexReader) AtomicReader {
+	if ar, ok := reader.(AtomicReader); ok {
+		return ar
+	}
+	return newSlowCompositeReaderWrapper(reader.(CompositeReader))
+}
+
+func newSlowCompositeReaderWrapper(reader CompositeReader) *SlowCompositeReaderWrapper {
+	ans := &SlowCompositeReaderWrapper{
+		in:         reader,
+		fields:     GetMultiFields(reader),
+		liveDocs:   GetMultiLiveDocs(reader),
+		fieldInfos: GetMergedFieldInfos(reader),
+	}
+	ans.AtomicReaderImpl = newAtomicReader(ans)
+	ans.ARFieldsReader = ans
+	reader.registerParentReader(ans)
+	return ans
+}
+
+func (r *SlowCompositeReaderWrapper) Fields() Fields {
+	r.ensureOpen()
+	return r.fields
+}
+
+func (r *SlowCompositeReaderWrapper) NumericDocValues(field string) (v NumericDocValues, err error) {
+	r.ensureOpen()
+	return GetMultiNumericValues(r.in, field)
+}
+
+func (r *SlowCompositeReaderWrapper) BinaryDocValues(field string) (v BinaryDocValues, err error) {
+	r.ensureOpen()
+	return GetMultiBinaryValues(r.in, field)
+}
+
+func (r *SlowCompositeReaderWrapper) SortedDocValues(field string) (v SortedDocValues, err error) {
+	r.ensureOpen()
+	return GetMultiSortedValues(r.in, field)
+}
+
+func (r *SlowCompositeReaderWrapper) SortedSetDocValues(field string) (v SortedSetDocValues, err error) {
+	r.ensureOpen()
+	return GetMultiSortedSetValues(r.in, field)
+}
+
+func (r *SlowCompositeReaderWrapper) NormValues(field string) (v NumericDocValues, err error) {
+	r.ensureOpen()
+	return GetMultiNormValues(r.in, field)
+}
+
+func (r *SlowCompositeReaderWrapper) TermVectors(docID int) (fs Fields, err error) {
+	r.ensureOpen()
+	return r.in.TermVectors(docID)
+}
+
+func (r *SlowCompositeReaderWrapper) NumDocs() int {
+	// Don't call ensureOpen() here (it could affect performance)
+	return r.in.NumDocs()
+}
+
+func (r *SlowCompositeReaderWrapper) MaxDoc() int {
+	// Don't call ensureOpen() here (it could affect performance)
+	return r.in.MaxDoc()
+}
+
+func (r *SlowCompositeReaderWrapper) Document(docID int, visitor StoredFieldVisitor) error {
+	r.ensureOpen()
+	return r.in.Document(docID, visitor)
+}
+
+func (r *SlowCompositeReaderWrapper) LiveDocs() util.Bits {
+	r.ensureOpen()
+	return r.liveDocs
+}
+
+func (r *SlowCompositeReaderWrapper) FieldInfos() FieldInfos {
+	r.ensureOpen()
+	return r.fieldInfos
+}
+
+func (r *SlowCompositeReaderWrapper) doClose() error {
+	// TODO: as this is a wrapper, should we really close the delegate?
+	return r.in.Close()
+}
+
+func (r *SlowCompositeReaderWrapper) String() string {
+	return fmt.Sprintf("SlowCompositeReaderWrapper(%v)", r.in)
+}
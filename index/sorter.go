@@ -0,0 +1,150 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+)
+
+// sorter/Sorter.java
+
+/*
+Sorts documents of a given index by returning a permutation on the
+document IDs.
+
+NOTE: A Sorter implementation can be easily written from a
+comparison of two documents, with SortDocs().
+*/
+type Sorter interface {
+	/*
+		Returns a mapping from the old document ID to its new location
+		in the sorted index, or nil if the reader is already sorted.
+		Implementations can use the auxiliary SortDocs() to compute the
+		old-to-new permutation given a comparison of documents.
+
+		NOTE: deleted documents are expected to appear in the mapping as
+		well, they will however be marked as deleted in the sorted view.
+	*/
+	Sort(reader AtomicReader) (*SorterDocMap, error)
+	/*
+		Returns the identifier of this Sorter. This identifier is used
+		to know whether segments have been sorted with the same Sorter.
+	*/
+	ID() string
+}
+
+// sorter/Sorter.java/DocMap
+
+/*
+A permutation of doc IDs. For every document ID between 0 and
+Size(), OldToNew(NewToOld(docID)) returns docID.
+*/
+type SorterDocMap struct {
+	oldToNew []int
+	newToOld []int
+}
+
+// Given a doc ID from the original index, return its ordinal in the
+// sorted index.
+func (m *SorterDocMap) OldToNew(docID int) int {
+	return m.oldToNew[docID]
+}
+
+// Given the ordinal of a doc ID, return its doc ID in the original
+// index.
+func (m *SorterDocMap) NewToOld(docID int) int {
+	return m.newToOld[docID]
+}
+
+// Returns the number of documents in this map. This must be equal to
+// the MaxDoc() of the AtomicReader which is sorted.
+func (m *SorterDocMap) Size() int {
+	return len(m.newToOld)
+}
+
+/*
+Computes the old-to-new permutation of maxDoc documents, where
+less(doc1, doc2) tells whether doc1 sorts before doc2. The sort is
+stable, so equal documents keep their relative order. Returns nil if
+the documents are already sorted.
+*/
+func SortDocs(maxDoc int, less func(doc1, doc2 int) bool) *SorterDocMap {
+	sorted := true
+	for i := 1; i < maxDoc; i++ {
+		if less(i, i-1) {
+			sorted = false
+			break
+		}
+	}
+	if sorted {
+		return nil
+	}
+
+	docs := &docsSorter{make([]int, maxDoc), less}
+	for i := range docs.docs {
+		docs.docs[i] = i
+	}
+	sort.Stable(docs)
+	ans := &SorterDocMap{oldToNew: make([]int, maxDoc), newToOld: docs.docs}
+	for newID, oldID := range docs.docs {
+		ans.oldToNew[oldID] = newID
+	}
+	return ans
+}
+
+type docsSorter struct {
+	docs []int
+	less func(doc1, doc2 int) bool
+}
+
+func (s *docsSorter) Len() int           { return len(s.docs) }
+func (s *docsSorter) Less(i, j int) bool { return s.less(s.docs[i], s.docs[j]) }
+func (s *docsSorter) Swap(i, j int)      { s.docs[i], s.docs[j] = s.docs[j], s.docs[i] }
+
+// sorter/NumericDocValuesSorter.java
+
+/*
+A Sorter which sorts documents according to their NumericDocValues.
+Documents without a value for the field sort as 0.
+*/
+type NumericDocValuesSorter struct {
+	fieldName string
+	ascending bool
+}
+
+// Constructor over the given field name, and ascending sort order if
+// ascending is true, descending otherwise.
+func NewNumericDocValuesSorter(fieldName string, ascending bool) *NumericDocValuesSorter {
+	return &NumericDocValuesSorter{fieldName, ascending}
+}
+
+func (s *NumericDocValuesSorter) Sort(reader AtomicReader) (*SorterDocMap, error) {
+	ndv, err := reader.NumericDocValues(s.fieldName)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]int64, reader.MaxDoc())
+	if ndv != nil {
+		for doc := range values {
+			values[doc] = ndv.Get(doc)
+		}
+	}
+	if s.ascending {
+		return SortDocs(len(values), func(doc1, doc2 int) bool {
+			return values[doc1] < values[doc2]
+		}), nil
+	}
+	return SortDocs(len(values), func(doc1, doc2 int) bool {
+		return values[doc1] > values[doc2]
+	}), nil
+}
+
+func (s *NumericDocValuesSorter) ID() string {
+	if s.ascending {
+		return fmt.Sprintf("DocValues(%v,asc)", s.fieldName)
+	}
+	return fmt.Sprintf("DocValues(%v,desc)", s.fieldName)
+}
+
+func (s *NumericDocValuesSorter) String() string {
+	return s.ID()
+}
@@ -0,0 +1,404 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/util"
+	"github.com/balzaczyy/golucene/util/automaton"
+	"sort"
+)
+
+// sorter/SortingAtomicReader.java
+
+/*
+An AtomicReader which supports sorting documents by a given Sorter.
+You can use this type to sort an index as follows:
+
+	sorted, err := index.WrapSortingAtomicReader(reader, sorter)
+	...
+	err = w.AddIndexesFromReaders(sorted)
+
+The sorted view renumbers the documents in the order of the Sorter.
+Its postings, stored fields, term vectors, doc values, norms and live
+docs are those of the wrapped reader, accessed through the new
+document IDs.
+
+NOTE: the postings of each term are loaded in memory to be sorted, so
+this reader is meant to be consumed once, e.g. by a merge, rather
+than searched.
+*/
+type SortingAtomicReader struct {
+	*FilterAtomicReader
+	docMap *SorterDocMap
+}
+
+/*
+Return a sorted view of reader according to the order defined by
+sorter. If the reader is already sorted, this method might return the
+reader as-is.
+*/
+func WrapSortingAtomicReader(reader AtomicReader, sorter Sorter) (AtomicReader, error) {
+	docMap, err := sorter.Sort(reader)
+	if err != nil {
+		return nil, err
+	}
+	if docMap == nil {
+		// the reader is already sorted
+		return reader, nil
+	}
+	if docMap.Size() != reader.MaxDoc() {
+		return nil, errors.New(fmt.Sprintf(
+			"reader.MaxDoc() should be equal to docMap.Size(), got %v != %v",
+			reader.MaxDoc(), docMap.Size()))
+	}
+	return newSortingAtomicReader(reader, docMap), nil
+}
+
+func newSortingAtomicReader(in AtomicReader, docMap *SorterDocMap) *SortingAtomicReader {
+	ans := &SortingAtomicReader{docMap: docMap}
+	ans.FilterAtomicReader = NewFilterAtomicReader(ans, in)
+	return ans
+}
+
+func (r *SortingAtomicReader) Fields() Fields {
+	fields := r.FilterAtomicReader.Fields()
+	if fields == nil {
+		return nil
+	}
+	return sortingFields{fields, r.docMap}
+}
+
+func (r *SortingAtomicReader) LiveDocs() util.Bits {
+	liveDocs := r.FilterAtomicReader.LiveDocs()
+	if liveDocs == nil {
+		return nil
+	}
+	return &mappedBits{liveDocs, r.docMap.NewToOld}
+}
+
+func (r *SortingAtomicReader) Document(docID int, visitor StoredFieldVisitor) error {
+	return r.FilterAtomicReader.Document(r.docMap.NewToOld(docID), visitor)
+}
+
+func (r *SortingAtomicReader) TermVectors(docID int) (fs Fields, err error) {
+	return r.FilterAtomicReader.TermVectors(r.docMap.NewToOld(docID))
+}
+
+func (r *SortingAtomicReader) NumericDocValues(field string) (v NumericDocValues, err error) {
+	return r.sortedNumericValues(r.FilterAtomicReader.NumericDocValues(field))
+}
+
+func (r *SortingAtomicReader) NormValues(field string) (v NumericDocValues, err error) {
+	return r.sortedNumericValues(r.FilterAtomicReader.NormValues(field))
+}
+
+func (r *SortingAtomicReader) sortedNumericValues(in NumericDocValues, err error) (NumericDocValues, error) {
+	if in == nil || err != nil {
+		return nil, err
+	}
+	return NumericDocValuesFunc(func(docID int) int64 {
+		return in.Get(r.docMap.NewToOld(docID))
+	}), nil
+}
+
+func (r *SortingAtomicReader) BinaryDocValues(field string) (v BinaryDocValues, err error) {
+	in, err := r.FilterAtomicReader.BinaryDocValues(field)
+	if in == nil || err != nil {
+		return nil, err
+	}
+	return BinaryDocValuesFunc(func(docID int) []byte {
+		return in.Get(r.docMap.NewToOld(docID))
+	}), nil
+}
+
+func (r *SortingAtomicReader) SortedDocValues(field string) (v SortedDocValues, err error) {
+	in, err := r.FilterAtomicReader.SortedDocValues(field)
+	if in == nil || err != nil {
+		return nil, err
+	}
+	return &sortingSortedDocValues{in, r.docMap}, nil
+}
+
+func (r *SortingAtomicReader) SortedSetDocValues(field string) (v SortedSetDocValues, err error) {
+	in, err := r.FilterAtomicReader.SortedSetDocValues(field)
+	if in == nil || err != nil {
+		return nil, err
+	}
+	return &sortingSortedSetDocValues{in, r.docMap}, nil
+}
+
+func (r *SortingAtomicReader) String() string {
+	return fmt.Sprintf("SortingAtomicReader(%v)", r.Delegate())
+}
+
+// Bits whose document IDs are translated by docMap before looking up
+// the wrapped Bits.
+type mappedBits struct {
+	in     util.Bits
+	docMap func(docID int) int
+}
+
+func (b *mappedBits) Get(index int) bool {
+	return b.in.Get(b.docMap(index))
+}
+
+func (b *mappedBits) Length() int {
+	return b.in.Length()
+}
+
+type sortingSortedDocValues struct {
+	in     SortedDocValues
+	docMap *SorterDocMap
+}
+
+func (v *sortingSortedDocValues) Get(docID int) []byte {
+	return v.in.Get(v.docMap.NewToOld(docID))
+}
+
+func (v *sortingSortedDocValues) Ord(docID int) int {
+	return v.in.Ord(v.docMap.NewToOld(docID))
+}
+
+func (v *sortingSortedDocValues) LookupOrd(ord int) []byte {
+	return v.in.LookupOrd(ord)
+}
+
+func (v *sortingSortedDocValues) ValueCount() int {
+	return v.in.ValueCount()
+}
+
+type sortingSortedSetDocValues struct {
+	in     SortedSetDocValues
+	docMap *SorterDocMap
+}
+
+func (v *sortingSortedSetDocValues) NextOrd() int64 {
+	return v.in.NextOrd()
+}
+
+func (v *sortingSortedSetDocValues) SetDocument(docID int) {
+	v.in.SetDocument(v.docMap.NewToOld(docID))
+}
+
+func (v *sortingSortedSetDocValues) LookupOrd(ord int64) []byte {
+	return v.in.LookupOrd(ord)
+}
+
+func (v *sortingSortedSetDocValues) ValueCount() int64 {
+	return v.in.ValueCount()
+}
+
+// sorter/SortingAtomicReader.java/SortingFields
+
+type sortingFields struct {
+	Fields
+	docMap *SorterDocMap
+}
+
+func (f sortingFields) Terms(field string) Terms {
+	terms := f.Fields.Terms(field)
+	if terms == nil {
+		return nil
+	}
+	return sortingTerms{terms, f.docMap}
+}
+
+// sorter/SortingAtomicReader.java/SortingTerms
+
+type sortingTerms struct {
+	Terms
+	docMap *SorterDocMap
+}
+
+func (t sortingTerms) Iterator(reuse TermsEnum) TermsEnum {
+	return &sortingTermsEnum{t.Terms.Iterator(nil), t.docMap}
+}
+
+func (t sortingTerms) Intersect(compiled *automaton.CompiledAutomaton, startTerm []byte) (TermsEnum, error) {
+	termsEnum, err := t.Terms.Intersect(compiled, startTerm)
+	if err != nil {
+		return nil, err
+	}
+	return &sortingTermsEnum{termsEnum, t.docMap}, nil
+}
+
+// sorter/SortingAtomicReader.java/SortingTermsEnum
+
+/*
+A TermsEnum whose postings are read entirely from the wrapped one,
+and sorted by the new document IDs.
+*/
+type sortingTermsEnum struct {
+	TermsEnum
+	docMap *SorterDocMap
+}
+
+// The liveDocs of the sorted view, checked with the old document IDs.
+func (e *sortingTermsEnum) newToOld(liveDocs util.Bits) util.Bits {
+	if liveDocs == nil {
+		return nil
+	}
+	return &mappedBits{liveDocs, e.docMap.OldToNew}
+}
+
+func (e *sortingTermsEnum) Docs(liveDocs util.Bits, reuse DocsEnum) DocsEnum {
+	return e.DocsByFlags(liveDocs, reuse, DOCS_ENUM_FLAG_FREQS)
+}
+
+func (e *sortingTermsEnum) DocsByFlags(liveDocs util.Bits, reuse DocsEnum, flags int) DocsEnum {
+	in := e.TermsEnum.DocsByFlags(e.newToOld(liveDocs), DocsEnum{}, flags)
+	if in.DocIdSetIterator == nil {
+		return in
+	}
+	return DocsEnum{newSortingDocsEnum(in, flags&DOCS_ENUM_FLAG_FREQS != 0, e.docMap)}
+}
+
+func (e *sortingTermsEnum) DocsAndPositions(liveDocs util.Bits, reuse DocsAndPositionsEnum) DocsAndPositionsEnum {
+	return e.DocsAndPositionsByFlags(liveDocs, reuse,
+		DOCS_POSITIONS_ENUM_FLAG_OFF_SETS|DOCS_POSITIONS_ENUM_FLAG_PAYLOADS)
+}
+
+func (e *sortingTermsEnum) DocsAndPositionsByFlags(liveDocs util.Bits,
+	reuse DocsAndPositionsEnum, flags int) DocsAndPositionsEnum {
+	in := e.TermsEnum.DocsAndPositionsByFlags(e.newToOld(liveDocs), DocsAndPositionsEnum{}, flags)
+	if in.DocsAndPositionsIterator == nil {
+		return in
+	}
+	return DocsAndPositionsEnum{newSortingDocsAndPositionsEnum(in, e.docMap)}
+}
+
+// sorter/SortingAtomicReader.java/SortingDocsEnum
+
+// A DocsEnum over the postings of a term, sorted by new document ID.
+type sortingDocsEnum struct {
+	docs  []int
+	freqs []int // nil if freqs were not requested
+	upto  int
+	docID int
+}
+
+func newSortingDocsEnum(in DocsEnum, withFreqs bool, docMap *SorterDocMap) *sortingDocsEnum {
+	ans := &sortingDocsEnum{upto: -1, docID: -1}
+	for doc, more := in.NextDoc(); more && doc != NO_MORE_DOCS; doc, more = in.NextDoc() {
+		ans.docs = append(ans.docs, docMap.OldToNew(doc))
+		if withFreqs {
+			ans.freqs = append(ans.freqs, in.Freq())
+		}
+	}
+	sort.Sort(ans)
+	return ans
+}
+
+func (e *sortingDocsEnum) Len() int           { return len(e.docs) }
+func (e *sortingDocsEnum) Less(i, j int) bool { return e.docs[i] < e.docs[j] }
+func (e *sortingDocsEnum) Swap(i, j int) {
+	e.docs[i], e.docs[j] = e.docs[j], e.docs[i]
+	if e.freqs != nil {
+		e.freqs[i], e.freqs[j] = e.freqs[j], e.freqs[i]
+	}
+}
+
+func (e *sortingDocsEnum) DocId() int {
+	return e.docID
+}
+
+func (e *sortingDocsEnum) Freq() int {
+	if e.freqs == nil {
+		return 1
+	}
+	return e.freqs[e.upto]
+}
+
+func (e *sortingDocsEnum) NextDoc() (doc int, more bool) {
+	if e.upto+1 >= len(e.docs) {
+		e.upto, e.docID = len(e.docs), NO_MORE_DOCS
+		return NO_MORE_DOCS, false
+	}
+	e.upto++
+	e.docID = e.docs[e.upto]
+	return e.docID, true
+}
+
+func (e *sortingDocsEnum) Cost() int64 {
+	return int64(len(e.docs))
+}
+
+func (e *sortingDocsEnum) Advance(target int) (doc int, more bool) {
+	// need to support it for checkIndex, but in practice it won't be
+	// called, so don't bother to implement efficiently for now.
+	for doc, more = e.NextDoc(); more && doc < target; doc, more = e.NextDoc() {
+	}
+	return doc, more
+}
+
+// sorter/SortingAtomicReader.java/SortingDocsAndPositionsEnum
+
+// A position of a term in a document, with its offsets and payload.
+type sortingPosition struct {
+	position, startOffset, endOffset int
+	payload                          []byte
+}
+
+/*
+A DocsAndPositionsEnum over the postings of a term, sorted by new
+document ID, along with their positions.
+*/
+type sortingDocsAndPositionsEnum struct {
+	*sortingDocsEnum
+	positions [][]sortingPosition // per doc, in the order of docs
+	posUpto   int
+}
+
+func newSortingDocsAndPositionsEnum(in DocsAndPositionsEnum, docMap *SorterDocMap) *sortingDocsAndPositionsEnum {
+	ans := &sortingDocsAndPositionsEnum{sortingDocsEnum: &sortingDocsEnum{upto: -1, docID: -1}}
+	docs := ans.sortingDocsEnum
+	for doc, more := in.NextDoc(); more && doc != NO_MORE_DOCS; doc, more = in.NextDoc() {
+		freq := in.Freq()
+		positions := make([]sortingPosition, freq)
+		for i := range positions {
+			positions[i].position = in.NextPosition()
+			positions[i].startOffset, positions[i].endOffset = in.StartOffset(), in.EndOffset()
+			if payload := in.Payload(); payload != nil {
+				positions[i].payload = append([]byte(nil), payload...)
+			}
+		}
+		docs.docs = append(docs.docs, docMap.OldToNew(doc))
+		docs.freqs = append(docs.freqs, freq)
+		ans.positions = append(ans.positions, positions)
+	}
+	sort.Sort(ans)
+	return ans
+}
+
+func (e *sortingDocsAndPositionsEnum) Swap(i, j int) {
+	e.sortingDocsEnum.Swap(i, j)
+	e.positions[i], e.positions[j] = e.positions[j], e.positions[i]
+}
+
+func (e *sortingDocsAndPositionsEnum) NextDoc() (doc int, more bool) {
+	e.posUpto = -1
+	return e.sortingDocsEnum.NextDoc()
+}
+
+func (e *sortingDocsAndPositionsEnum) Advance(target int) (doc int, more bool) {
+	for doc, more = e.NextDoc(); more && doc < target; doc, more = e.NextDoc() {
+	}
+	return doc, more
+}
+
+func (e *sortingDocsAndPositionsEnum) NextPosition() int {
+	e.posUpto++
+	return e.positions[e.upto][e.posUpto].position
+}
+
+func (e *sortingDocsAndPositionsEnum) StartOffset() int {
+	return e.positions[e.upto][e.posUpto].startOffset
+}
+
+func (e *sortingDocsAndPositionsEnum) EndOffset() int {
+	return e.positions[e.upto][e.posUpto].endOffset
+}
+
+func (e *sortingDocsAndPositionsEnum) Payload() []byte {
+	return e.positions[e.upto][e.posUpto].payload
+}
@@ -0,0 +1,97 @@
+package index
+
+import (
+	"fmt"
+)
+
+// sorter/SortingMergePolicy.java
+
+/*
+A MergePolicy that reorders documents according to a Sorter before
+merging them. As a consequence, all segments resulting from a merge
+will be sorted while segments resulting from a flush will be in the
+order in which documents have been added.
+
+Sorted segments allow searches sorted by the same criteria to
+terminate early in each segment, once enough hits were collected.
+
+NOTE: never use this policy if you rely on IndexWriter.AddDocuments
+to have sequentially-assigned doc IDs, this policy will scatter doc
+IDs.
+*/
+type SortingMergePolicy struct {
+	in     MergePolicy
+	sorter Sorter
+}
+
+// Create a new MergePolicy that sorts documents with sorter.
+func NewSortingMergePolicy(in MergePolicy, sorter Sorter) *SortingMergePolicy {
+	return &SortingMergePolicy{in, sorter}
+}
+
+func (mp *SortingMergePolicy) SetIndexWriter(writer *IndexWriter) {
+	mp.in.SetIndexWriter(writer)
+}
+
+func (mp *SortingMergePolicy) FindMerges(trigger MergeTrigger, infos *SegmentInfos) (*MergeSpecification, error) {
+	spec, err := mp.in.FindMerges(trigger, infos)
+	return mp.sortedMergeSpecification(spec), err
+}
+
+func (mp *SortingMergePolicy) FindForcedMerges(infos *SegmentInfos, maxSegmentCount int,
+	segmentsToMerge map[string]bool) (*MergeSpecification, error) {
+	spec, err := mp.in.FindForcedMerges(infos, maxSegmentCount, segmentsToMerge)
+	return mp.sortedMergeSpecification(spec), err
+}
+
+func (mp *SortingMergePolicy) FindForcedDeletesMerges(infos *SegmentInfos) (*MergeSpecification, error) {
+	spec, err := mp.in.FindForcedDeletesMerges(infos)
+	return mp.sortedMergeSpecification(spec), err
+}
+
+func (mp *SortingMergePolicy) UseCompoundFile(infos *SegmentInfos, mergedInfo *SegmentInfoPerCommit) (bool, error) {
+	return mp.in.UseCompoundFile(infos, mergedInfo)
+}
+
+// Makes the merges of spec sort the documents of their segments.
+func (mp *SortingMergePolicy) sortedMergeSpecification(spec *MergeSpecification) *MergeSpecification {
+	if spec == nil {
+		return nil
+	}
+	for _, merge := range spec.merges {
+		merge.wrapReaders = mp.sortedReaders
+	}
+	return spec
+}
+
+/*
+Returns a single sorted view of the readers of a merge, or the
+readers themselves if their documents are already sorted.
+*/
+func (mp *SortingMergePolicy) sortedReaders(readers []AtomicReader) ([]AtomicReader, error) {
+	var atomicView AtomicReader
+	if len(readers) == 1 {
+		atomicView = readers[0]
+	} else {
+		subs := make([]IndexReader, len(readers))
+		for i, reader := range readers {
+			subs[i] = reader
+		}
+		// the merge releases the readers, so the view doesn't hold
+		// references on them
+		atomicView = WrapSlowCompositeReader(NewMultiReader(subs, true))
+	}
+	docMap, err := mp.sorter.Sort(atomicView)
+	if err != nil {
+		return nil, err
+	}
+	if docMap == nil {
+		// already sorted, return the original list
+		return readers, nil
+	}
+	return []AtomicReader{newSortingAtomicReader(atomicView, docMap)}, nil
+}
+
+func (mp *SortingMergePolicy) String() string {
+	return fmt.Sprintf("SortingMergePolicy(%v, sorter=%v)", mp.in, mp.sorter.ID())
+}
@@ -0,0 +1,158 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestSortDocs(t *testing.T) {
+	values := []int{3, 1, 2, 1}
+	docMap := SortDocs(len(values), func(doc1, doc2 int) bool {
+		return values[doc1] < values[doc2]
+	})
+	assertEquals(t, 4, docMap.Size())
+	// stable: doc 1 stays before doc 3
+	for newID, oldID := range []int{1, 3, 2, 0} {
+		assertEquals(t, oldID, docMap.NewToOld(newID))
+		assertEquals(t, newID, docMap.OldToNew(oldID))
+	}
+	if docMap = SortDocs(3, func(doc1, doc2 int) bool { return doc1 < doc2 }); docMap != nil {
+		t.Error("Sorted documents should not be mapped")
+	}
+}
+
+// Returns a test document whose "ts" value is a permutation of the
+// document numbers.
+func newSortingTestDoc(i, numDocs int) []document.IndexableField {
+	ts := (i * 7) % numDocs
+	return append(newTestDoc(i),
+		document.NewNumericDocValuesField("ts", int64(ts)),
+		document.NewSortedDocValuesField("tag", []byte(fmt.Sprintf("t%02d", ts))))
+}
+
+// Checks the documents of the reader are sorted by their "ts" value,
+// and their postings, stored fields and doc values agree.
+func assertSortedByTs(t *testing.T, reader AtomicReader, numDocs int) {
+	assertEquals(t, numDocs, reader.MaxDoc())
+	ts, err := reader.NumericDocValues("ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := reader.SortedDocValues("tag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int, numDocs)
+	for doc := 0; doc < numDocs; doc++ {
+		assertEquals(t, int64(doc), ts.Get(doc))
+		assertEquals(t, fmt.Sprintf("t%02d", doc), string(tag.Get(doc)))
+		visitor := NewDocumentStoredFieldVisitor()
+		if err = reader.Document(doc, visitor); err != nil {
+			t.Fatal(err)
+		}
+		if ids[doc], err = strconv.Atoi(visitor.Document().Get("id")); err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, doc, (ids[doc]*7)%numDocs)
+	}
+
+	termsEnum := reader.Terms("parity").Iterator(nil)
+	found, err := termsEnum.SeekExact([]byte("even"))
+	if err != nil || !found {
+		t.Fatalf("Term should be found (%v)", err)
+	}
+	var numEven int
+	docsEnum := termsEnum.DocsByFlags(nil, DOCS_ENUM_EMPTY, DOCS_ENUM_FLAG_FREQS)
+	prev := -1
+	for doc, more := docsEnum.NextDoc(); more; doc, more = docsEnum.NextDoc() {
+		if doc <= prev {
+			t.Fatalf("Postings should be in order: %v after %v", doc, prev)
+		}
+		prev = doc
+		assertEquals(t, 2, docsEnum.Freq())
+		assertEquals(t, 0, ids[doc]%2)
+		numEven++
+	}
+	assertEquals(t, numDocs/2, numEven)
+}
+
+func TestSortingAtomicReader(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	const numDocs = 20
+	w := openTestIndexWriter(t, d, OPEN_MODE_CREATE, numDocs)
+	for i := 0; i < numDocs; i++ {
+		if err := w.AddDocument(newSortingTestDoc(i, numDocs)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, len(r.Leaves()))
+	in := r.Leaves()[0].Reader().(AtomicReader)
+	sorter := NewNumericDocValuesSorter("ts", true)
+	assertEquals(t, "DocValues(ts,asc)", sorter.ID())
+
+	sorted, err := WrapSortingAtomicReader(in, sorter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSortedByTs(t, sorted, numDocs)
+	if sorted2, err := WrapSortingAtomicReader(sorted, sorter); err != nil || sorted2 != sorted {
+		t.Errorf("Sorted reader should be returned as is (%v)", err)
+	}
+}
+
+func TestSortingMergePolicy(t *testing.T) {
+	d, path := openTestDirectory(t)
+	defer os.RemoveAll(path)
+
+	const numDocs = 20
+	sorter := NewNumericDocValuesSorter("ts", true)
+	mp := NewSortingMergePolicy(NewLogDocMergePolicy(), sorter)
+	w, err := NewIndexWriter(d, NewIndexWriterConfig().SetMaxBufferedDocs(6).SetMergePolicy(mp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < numDocs; i++ {
+		if err = w.AddDocument(newSortingTestDoc(i, numDocs)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.ForceMerge(1); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	assertEquals(t, 1, len(r.Leaves()))
+	assertSortedByTs(t, r.Leaves()[0].Reader().(AtomicReader), numDocs)
+
+	var out bytes.Buffer
+	checker := NewCheckIndex(d)
+	checker.SetInfoStream(&out, false)
+	status, err := checker.CheckIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Fatalf("Index should be clean:\n%v", out.String())
+	}
+}
@@ -0,0 +1,331 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// TermVectorsConsumer.java
+
+/*
+Buffers the term vectors of the document being inverted in a
+TermsHash of its own, which is released after each document, and
+writes them through the codec's TermVectorsWriter when the document
+is finished.
+
+The TermVectorsWriter, and so the term vectors files, are only
+created once a document with term vectors is seen. Documents without
+term vectors are then written as documents with no vector fields.
+*/
+type TermVectorsConsumer struct {
+	directory   store.Directory
+	codec       Codec
+	segmentInfo *SegmentInfo
+
+	termsHash *TermsHash
+	fields    map[string]*TermVectorsConsumerPerField
+	perFields []*TermVectorsConsumerPerField // vector fields of the current doc
+
+	writer    TermVectorsWriter
+	lastDocID int
+}
+
+func newTermVectorsConsumer(directory store.Directory, codec Codec,
+	segmentInfo *SegmentInfo, bytesUsed util.Counter) *TermVectorsConsumer {
+	return &TermVectorsConsumer{
+		directory:   directory,
+		codec:       codec,
+		segmentInfo: segmentInfo,
+		termsHash:   newTermsHash(bytesUsed),
+		fields:      make(map[string]*TermVectorsConsumerPerField),
+	}
+}
+
+/*
+Returns the per-field consumer of the given field for the current
+document, which stores positions, offsets and payloads as told.
+*/
+func (c *TermVectorsConsumer) addField(fieldInfo *FieldInfo,
+	positions, offsets, payloads bool) *TermVectorsConsumerPerField {
+	perField, ok := c.fields[fieldInfo.name]
+	if !ok {
+		perField = newTermVectorsConsumerPerField(c, fieldInfo)
+		c.fields[fieldInfo.name] = perField
+	}
+	perField.start(positions, offsets, payloads)
+	c.perFields = append(c.perFields, perField)
+	return perField
+}
+
+func (c *TermVectorsConsumer) initTermVectorsWriter() (err error) {
+	if c.writer == nil {
+		c.writer, err = c.codec.GetTermVectorsWriter(c.directory, c.segmentInfo, store.IO_CONTEXT_DEFAULT)
+		c.lastDocID = 0
+	}
+	return err
+}
+
+// Fills in no-vector documents up to, but not including, docID.
+func (c *TermVectorsConsumer) fill(docID int) error {
+	for c.lastDocID < docID {
+		if err := c.writer.StartDocument(0); err != nil {
+			return err
+		}
+		if err := c.writer.FinishDocument(); err != nil {
+			return err
+		}
+		c.lastDocID++
+	}
+	return nil
+}
+
+// Writes the term vectors of the given document, if it has any.
+func (c *TermVectorsConsumer) finishDocument(docID int) (err error) {
+	if len(c.perFields) == 0 {
+		return nil
+	}
+	if err = c.initTermVectorsWriter(); err != nil {
+		return err
+	}
+	if err = c.fill(docID); err != nil {
+		return err
+	}
+
+	// Append term vectors to the real outputs:
+	if err = c.writer.StartDocument(len(c.perFields)); err != nil {
+		return err
+	}
+	for _, perField := range c.perFields {
+		if err = perField.finishDocument(); err != nil {
+			return err
+		}
+	}
+	if err = c.writer.FinishDocument(); err != nil {
+		return err
+	}
+	if c.lastDocID != docID {
+		panic("assert fail")
+	}
+	c.lastDocID++
+
+	c.reset()
+	return nil
+}
+
+// Releases the term vectors buffered for the current document.
+func (c *TermVectorsConsumer) reset() {
+	for _, perField := range c.perFields {
+		perField.reset()
+	}
+	c.perFields = c.perFields[:0]
+	c.termsHash.reset()
+}
+
+/*
+Finishes the term vectors files of the flushed segment, if any
+document had term vectors.
+*/
+func (c *TermVectorsConsumer) flush(state SegmentWriteState) (err error) {
+	if c.writer == nil {
+		return nil
+	}
+	defer func() {
+		if err == nil {
+			err = c.writer.Close()
+		} else {
+			util.CloseWhileSuppressingError(c.writer)
+		}
+		c.writer = nil
+		c.lastDocID = 0
+	}()
+	numDocs := int(state.segmentInfo.docCount)
+	if err = c.fill(numDocs); err != nil {
+		return err
+	}
+	return c.writer.Finish(state.fieldInfos, numDocs)
+}
+
+// Discards the buffered term vectors, deleting any files written so
+// far.
+func (c *TermVectorsConsumer) abort() {
+	if c.writer != nil {
+		c.writer.Abort()
+		c.writer = nil
+	}
+	c.lastDocID = 0
+	c.reset()
+}
+
+// TermVectorsConsumerPerField.java
+
+/*
+Buffers the term vectors of a single field of the current document.
+Each term has two streams: the positions, as vInt deltas shifted left
+by one whose low bit tells if a payload follows, and the offsets, as
+the vInt delta of the start offset from the previous end offset
+followed by the length.
+*/
+type TermVectorsConsumerPerField struct {
+	*TermsHashPerField
+
+	consumer *TermVectorsConsumer
+	postings *TermVectorsPostingsArray
+
+	doVectorPositions bool
+	doVectorOffsets   bool
+	doVectorPayloads  bool
+	hasPayloads       bool // if enabled, and we actually saw any for this field
+}
+
+func newTermVectorsConsumerPerField(consumer *TermVectorsConsumer,
+	fieldInfo *FieldInfo) *TermVectorsConsumerPerField {
+	ans := &TermVectorsConsumerPerField{consumer: consumer}
+	ans.TermsHashPerField = newTermsHashPerField(consumer.termsHash, fieldInfo, 2, ans)
+	return ans
+}
+
+func (w *TermVectorsConsumerPerField) start(positions, offsets, payloads bool) {
+	w.doVectorPositions = positions
+	w.doVectorOffsets = offsets
+	w.doVectorPayloads = payloads
+	w.hasPayloads = false
+	if w.bytesHash.Size() != 0 {
+		// Only necessary if previous doc hit a non-aborting error
+		// while writing vectors in this field:
+		w.reset()
+	}
+}
+
+/*
+Adds a term occurrence at the given position, between the given
+offsets, with an optional payload.
+*/
+func (w *TermVectorsConsumerPerField) addTerm(term []byte, position,
+	startOffset, endOffset int, payload []byte) {
+	termID, isNew := w.add(term)
+	switch {
+	case termID == -1:
+		// skipped immense term
+	case isNew:
+		w.postings.freqs[termID] = 1
+		w.writeProx(termID, position, 0, startOffset, endOffset, 0, payload)
+	default:
+		w.postings.freqs[termID]++
+		w.writeProx(termID, position, int(w.postings.lastPositions[termID]),
+			startOffset, endOffset, int(w.postings.lastOffsets[termID]), payload)
+	}
+}
+
+func (w *TermVectorsConsumerPerField) writeProx(termID, position, lastPosition,
+	startOffset, endOffset, lastOffset int, payload []byte) {
+	if w.doVectorOffsets {
+		w.writeVInt(1, int32(startOffset-lastOffset))
+		w.writeVInt(1, int32(endOffset-startOffset))
+		w.postings.lastOffsets[termID] = int32(endOffset)
+	}
+
+	if w.doVectorPositions {
+		if w.doVectorPayloads && len(payload) > 0 {
+			w.writeVInt(0, int32((position-lastPosition)<<1|1))
+			w.writeVInt(0, int32(len(payload)))
+			for _, b := range payload {
+				w.writeByte(0, b)
+			}
+			w.hasPayloads = true
+		} else {
+			w.writeVInt(0, int32((position-lastPosition)<<1))
+		}
+		w.postings.lastPositions[termID] = int32(position)
+	}
+}
+
+/*
+Called once per field per document if term vectors are enabled, to
+write the vectors to the TermVectorsWriter.
+*/
+func (w *TermVectorsConsumerPerField) finishDocument() error {
+	tv := w.consumer.writer
+	numPostings := w.bytesHash.Size()
+	if err := tv.StartField(*w.fieldInfo, numPostings,
+		w.doVectorPositions, w.doVectorOffsets, w.hasPayloads); err != nil {
+		return err
+	}
+
+	var posReader, offReader *ByteSliceReader
+	if w.doVectorPositions {
+		posReader = newByteSliceReader()
+	}
+	if w.doVectorOffsets {
+		offReader = newByteSliceReader()
+	}
+
+	termIDs := w.sortPostings()
+	for _, id := range termIDs[:numPostings] {
+		termID := int(id)
+		freq := int(w.postings.freqs[termID])
+
+		// Get BytesRef
+		if err := tv.StartTerm(w.bytesHash.Get(termID), freq); err != nil {
+			return err
+		}
+
+		if w.doVectorPositions || w.doVectorOffsets {
+			var positions, offsets util.DataInput
+			if posReader != nil {
+				w.initReader(posReader, termID, 0)
+				positions = posReader
+			}
+			if offReader != nil {
+				w.initReader(offReader, termID, 1)
+				offsets = offReader
+			}
+			if err := addProx(tv, freq, positions, offsets); err != nil {
+				return err
+			}
+		}
+	}
+	return tv.FinishField()
+}
+
+func (w *TermVectorsConsumerPerField) createPostingsArray(size int) *ParallelPostingsArray {
+	w.postings = newTermVectorsPostingsArray(size)
+	return w.postings.ParallelPostingsArray
+}
+
+func (w *TermVectorsConsumerPerField) growPostingsArray() *ParallelPostingsArray {
+	w.postings.grow()
+	return w.postings.ParallelPostingsArray
+}
+
+func (w *TermVectorsConsumerPerField) clearPostingsArray() {
+	w.postings = nil
+}
+
+func (w *TermVectorsConsumerPerField) bytesPerPosting() int {
+	return PARALLEL_POSTINGS_ARRAY_BYTES_PER_POSTING + 3*util.NUM_BYTES_INT
+}
+
+// TermVectorsConsumerPerField.java/TermVectorsPostingsArray
+
+type TermVectorsPostingsArray struct {
+	*ParallelPostingsArray
+	freqs         []int32 // How many times this term occurred in the current doc
+	lastOffsets   []int32 // Last offset we saw
+	lastPositions []int32 // Last position where this term occurred
+}
+
+func newTermVectorsPostingsArray(size int) *TermVectorsPostingsArray {
+	return &TermVectorsPostingsArray{
+		ParallelPostingsArray: newParallelPostingsArray(size),
+		freqs:                 make([]int32, size),
+		lastOffsets:           make([]int32, size),
+		lastPositions:         make([]int32, size),
+	}
+}
+
+func (arr *TermVectorsPostingsArray) grow() {
+	arr.ParallelPostingsArray.grow()
+	arr.freqs = growInt32s(arr.freqs, arr.size)
+	arr.lastOffsets = growInt32s(arr.lastOffsets, arr.size)
+	arr.lastPositions = growInt32s(arr.lastPositions, arr.size)
+}
@@ -1,8 +1,10 @@
 package index
 
 import (
+	"errors"
 	"fmt"
 	"github.com/balzaczyy/golucene/util"
+	"github.com/balzaczyy/golucene/util/automaton"
 	"log"
 	"sort"
 )
@@ -18,11 +20,37 @@ func NewTerm(fld string, text string) Term {
 
 type Terms interface {
 	Iterator(reuse TermsEnum) TermsEnum
+	/*
+		Returns a TermsEnum that iterates over all terms that are
+		accepted by the provided CompiledAutomaton. If the startTerm is
+		provided then the returned enum will only accept terms > startTerm,
+		but you still must call Next() first to get to the first term.
+		Note that the provided startTerm must be accepted by the
+		automaton.
+
+		NOTE: the returned TermsEnum cannot seek. Returns error if the
+		type of the automaton is not AUTOMATON_TYPE_NORMAL, whose terms
+		can be enumerated more cheaply.
+	*/
+	Intersect(compiled *automaton.CompiledAutomaton, startTerm []byte) (TermsEnum, error)
 	DocCount() int
 	SumTotalTermFreq() int64
 	SumDocFreq() int64
 }
 
+// Terms.java/intersect
+
+// The default implementation of Terms.Intersect(), which filters the
+// terms of the Iterator() of terms with an AutomatonTermsEnum.
+func intersect(terms Terms, compiled *automaton.CompiledAutomaton, startTerm []byte) (TermsEnum, error) {
+	if compiled.Type != automaton.AUTOMATON_TYPE_NORMAL {
+		return nil, errors.New("please use the enumeration of the type of the CompiledAutomaton instead")
+	}
+	ans := NewAutomatonTermsEnum(terms.Iterator(nil), compiled.Automaton)
+	ans.startTerm = startTerm
+	return ans, nil
+}
+
 // TermsEnum.java
 /*
 Iterator to seek, or step through terms to obtain frequency information, or
@@ -37,7 +65,7 @@ succesfully call next() or one of the seek methods.
 type TermsEnum interface {
 	util.BytesRefIterator
 
-	Attributes() util.AttributeSource
+	Attributes() *util.AttributeSource
 	/* Attempts to seek to the exact term, returning
 	true if the term is found. If this returns false, the
 	enum is unpositioned. For some codecs, seekExact may
@@ -53,7 +81,8 @@ type TermsEnum interface {
 	/* Seeks to the specified term by ordinal (position) as
 	previously returned by ord. The target ord
 	may be before or after the current ord, and must be
-	within bounds. */
+	within bounds. This is an optional method: returns
+	ErrUnsupported if the codec doesn't support ordinals. */
 	SeekExactByPosition(ord int64) error
 	/* Expert: Seeks a specific position by TermState previously obtained
 	from termState(). Callers shoudl maintain the TermState to
@@ -77,9 +106,10 @@ type TermsEnum interface {
 	is unpositioned. */
 	Term() []byte
 	/* Returns ordinal position for current term. This is an
-	optional method (the codec may panic). Do not call this
-	when the enum is unpositioned. */
-	Ord() int64
+	optional method: returns ErrUnsupported if the codec
+	doesn't support ordinals. Do not call this when the enum
+	is unpositioned. */
+	Ord() (int64, error)
 	/* Returns the number of documentsw containing the current
 	term. Do not call this when enum is unpositioned. */
 	DocFreq() int
@@ -120,6 +150,10 @@ type TermsEnum interface {
 	TermState() TermState
 }
 
+// Returned by optional TermsEnum methods, e.g. Ord() and
+// SeekExactByPosition(), when the codec doesn't support them.
+var ErrUnsupported = errors.New("operation not supported by this TermsEnum")
+
 type SeekStatus int
 
 const (
@@ -130,14 +164,14 @@ const (
 
 type TermsEnumImpl struct {
 	TermsEnum
-	atts util.AttributeSource
+	atts *util.AttributeSource
 }
 
 func newTermsEnumImpl(self TermsEnum) *TermsEnumImpl {
 	return &TermsEnumImpl{self, util.NewAttributeSource()}
 }
 
-func (e *TermsEnumImpl) Attributes() util.AttributeSource {
+func (e *TermsEnumImpl) Attributes() *util.AttributeSource {
 	return e.atts
 }
 
@@ -206,7 +240,7 @@ func (e *EmptyTermsEnum) TotalTermFreq() int64 {
 	panic("this method should never be called")
 }
 
-func (e *EmptyTermsEnum) Ord() int64 {
+func (e *EmptyTermsEnum) Ord() (int64, error) {
 	panic("this method should never be called")
 }
 
@@ -275,7 +309,7 @@ func NewTermContextFromTerm(ctx IndexReaderContext, t Term) (tc *TermContext, er
 				if ok {
 					termState := termsEnum.TermState()
 					log.Println("    found")
-					perReaderTermState.register(termState, leaf.Ord, termsEnum.DocFreq(), termsEnum.TotalTermFreq())
+					perReaderTermState.Register(termState, leaf.Ord, termsEnum.DocFreq(), termsEnum.TotalTermFreq())
 				}
 			}
 		}
@@ -283,7 +317,12 @@ func NewTermContextFromTerm(ctx IndexReaderContext, t Term) (tc *TermContext, er
 	return perReaderTermState, nil
 }
 
-func (tc TermContext) register(state TermState, ord, docFreq int, totalTermFreq int64) {
+/*
+Registers and associates a TermState with a leaf ordinal. The leaf
+ordinal should be derived from an IndexReaderContext's leaf ord. The
+docFreq and totalTermFreq of the state are added to the totals.
+*/
+func (tc *TermContext) Register(state TermState, ord, docFreq int, totalTermFreq int64) {
 	// assert ord >= 0 && ord < len(states)
 	// assert states[ord] == null : "state for ord: " + ord + " already registered";
 	tc.DocFreq += docFreq
@@ -311,17 +350,58 @@ func NewMultiTerms(subs []Terms, subSlices []ReaderSlice) MultiTerms {
 }
 
 func (mt MultiTerms) Iterator(reuse TermsEnum) TermsEnum {
-	panic("not implemented yet")
+	termsEnums := make([]termsEnumIndex, 0, len(mt.subs))
+	for i, sub := range mt.subs {
+		if termsEnum := sub.Iterator(nil); termsEnum != nil {
+			termsEnums = append(termsEnums, termsEnumIndex{i, termsEnum})
+		}
+	}
+	if len(termsEnums) == 0 {
+		return EMPTY_TERMS_ENUM
+	}
+	ans, err := NewMultiTermsEnum(mt.subSlices).reset(termsEnums)
+	if err != nil {
+		panic(err)
+	}
+	return ans
+}
+
+func (mt MultiTerms) Intersect(compiled *automaton.CompiledAutomaton, startTerm []byte) (TermsEnum, error) {
+	return intersect(mt, compiled, startTerm)
 }
 
 func (mt MultiTerms) DocCount() int {
-	panic("not implemented yet")
+	sum := 0
+	for _, terms := range mt.subs {
+		v := terms.DocCount()
+		if v == -1 {
+			return -1
+		}
+		sum += v
+	}
+	return sum
 }
 
 func (mt MultiTerms) SumTotalTermFreq() int64 {
-	panic("not implemented yet")
+	sum := int64(0)
+	for _, terms := range mt.subs {
+		v := terms.SumTotalTermFreq()
+		if v == -1 {
+			return -1
+		}
+		sum += v
+	}
+	return sum
 }
 
 func (mt MultiTerms) SumDocFreq() int64 {
-	panic("not implemented yet")
+	sum := int64(0)
+	for _, terms := range mt.subs {
+		v := terms.SumDocFreq()
+		if v == -1 {
+			return -1
+		}
+		sum += v
+	}
+	return sum
 }
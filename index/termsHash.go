@@ -0,0 +1,403 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/util"
+	"log"
+)
+
+// ByteSliceReader.java
+
+/*
+IndexInput that knows how to read the byte slices written by Posting
+and PostingVector. We read the bytes in each slice until we hit the
+end of that slice at which point we read the forwarding address of
+the next slice and then jump to it.
+*/
+type ByteSliceReader struct {
+	*util.DataInputImpl
+	pool         *util.ByteBlockPool
+	bufferUpto   int
+	buffer       []byte
+	upto         int
+	limit        int
+	level        int
+	bufferOffset int
+	endIndex     int
+}
+
+func newByteSliceReader() *ByteSliceReader {
+	ans := &ByteSliceReader{}
+	ans.DataInputImpl = &util.DataInputImpl{DataReader: ans}
+	return ans
+}
+
+func (r *ByteSliceReader) init(pool *util.ByteBlockPool, startIndex, endIndex int) {
+	if endIndex-startIndex < 0 || startIndex < 0 || endIndex < 0 {
+		panic("assert fail")
+	}
+	r.pool = pool
+	r.endIndex = endIndex
+
+	r.level = 0
+	r.bufferUpto = startIndex / util.BYTE_BLOCK_SIZE
+	r.bufferOffset = r.bufferUpto * util.BYTE_BLOCK_SIZE
+	r.buffer = pool.Buffers[r.bufferUpto]
+	r.upto = startIndex & util.BYTE_BLOCK_MASK
+
+	firstSize := util.LEVEL_SIZE_ARRAY[0]
+	if startIndex+firstSize >= endIndex {
+		// There is only this one slice to read
+		r.limit = endIndex & util.BYTE_BLOCK_MASK
+	} else {
+		r.limit = r.upto + firstSize - 4
+	}
+}
+
+func (r *ByteSliceReader) eof() bool {
+	if r.upto+r.bufferOffset > r.endIndex {
+		panic("assert fail")
+	}
+	return r.upto+r.bufferOffset == r.endIndex
+}
+
+func (r *ByteSliceReader) ReadByte() (b byte, err error) {
+	if r.eof() {
+		panic("assert fail")
+	}
+	if r.upto == r.limit {
+		r.nextSlice()
+	}
+	b = r.buffer[r.upto]
+	r.upto++
+	return b, nil
+}
+
+func (r *ByteSliceReader) nextSlice() {
+	// Skip to our next slice
+	nextIndex := (int(r.buffer[r.limit]) << 24) + (int(r.buffer[r.limit+1]) << 16) +
+		(int(r.buffer[r.limit+2]) << 8) + int(r.buffer[r.limit+3])
+
+	r.level = util.NEXT_LEVEL_ARRAY[r.level]
+	newSize := util.LEVEL_SIZE_ARRAY[r.level]
+
+	r.bufferUpto = nextIndex / util.BYTE_BLOCK_SIZE
+	r.bufferOffset = r.bufferUpto * util.BYTE_BLOCK_SIZE
+
+	r.buffer = r.pool.Buffers[r.bufferUpto]
+	r.upto = nextIndex & util.BYTE_BLOCK_MASK
+
+	if nextIndex+newSize >= r.endIndex {
+		// We are advancing to the final slice
+		if r.endIndex-nextIndex <= 0 {
+			panic("assert fail")
+		}
+		r.limit = r.endIndex - r.bufferOffset
+	} else {
+		// This is not the final slice (subtract 4 for the forwarding
+		// address at the end of this new slice)
+		r.limit = r.upto + newSize - 4
+	}
+}
+
+func (r *ByteSliceReader) ReadBytes(buf []byte) error {
+	for len(buf) > 0 {
+		numLeft := r.limit - r.upto
+		if numLeft < len(buf) {
+			// Read entire slice
+			copy(buf, r.buffer[r.upto:r.limit])
+			buf = buf[numLeft:]
+			r.nextSlice()
+		} else {
+			// This slice is the last one
+			copy(buf, r.buffer[r.upto:r.upto+len(buf)])
+			r.upto += len(buf)
+			break
+		}
+	}
+	return nil
+}
+
+// ParallelPostingsArray.java
+
+// Bytes used per term by the arrays of ParallelPostingsArray.
+const PARALLEL_POSTINGS_ARRAY_BYTES_PER_POSTING = 3 * util.NUM_BYTES_INT
+
+/*
+Per-term arrays kept in parallel to the term ids of a BytesRefHash:
+where the term's bytes start, where its int stream pointers start,
+and where its first byte slice starts.
+*/
+type ParallelPostingsArray struct {
+	size       int
+	textStarts []int32
+	intStarts  []int32
+	byteStarts []int32
+}
+
+func newParallelPostingsArray(size int) *ParallelPostingsArray {
+	return &ParallelPostingsArray{
+		size:       size,
+		textStarts: make([]int32, size),
+		intStarts:  make([]int32, size),
+		byteStarts: make([]int32, size),
+	}
+}
+
+func (arr *ParallelPostingsArray) grow() {
+	newSize := arr.size + arr.size>>3 + 1
+	arr.textStarts = growInt32s(arr.textStarts, newSize)
+	arr.intStarts = growInt32s(arr.intStarts, newSize)
+	arr.byteStarts = growInt32s(arr.byteStarts, newSize)
+	arr.size = newSize
+}
+
+func growInt32s(array []int32, newSize int) []int32 {
+	ans := make([]int32, newSize)
+	copy(ans, array)
+	return ans
+}
+
+// TermsHash.java
+
+/*
+This class implements the in-memory pools of an inverted index: terms
+are hashed into the term byte pool, and the streams of each term are
+appended as vInts to slices of the byte pool, whose current write
+positions are kept in the int pool.
+
+A TermsHash is owned by a single consumer of a single
+DocumentsWriterPerThread, so no synchronization is needed while
+inverting documents. The memory allocated by the pools and the
+postings arrays is tracked by bytesUsed.
+*/
+type TermsHash struct {
+	intPool      *util.IntBlockPool
+	bytePool     *util.ByteBlockPool
+	termBytePool *util.ByteBlockPool
+	bytesUsed    util.Counter
+}
+
+func newTermsHash(bytesUsed util.Counter) *TermsHash {
+	bytePool := util.NewByteBlockPool(util.NewDirectTrackingByteAllocator(bytesUsed))
+	return &TermsHash{
+		intPool:      util.NewIntBlockPool(util.NewDirectTrackingIntAllocator(bytesUsed)),
+		bytePool:     bytePool,
+		termBytePool: bytePool,
+		bytesUsed:    bytesUsed,
+	}
+}
+
+// Releases the pools; the per-field hashes must be cleared too.
+func (h *TermsHash) reset() {
+	h.intPool.Reset(false, false)
+	h.bytePool.Reset(false, false)
+}
+
+// TermsHashPerField.java
+
+const TERMS_HASH_HASH_INIT_SIZE = 4
+
+/*
+Hashes the terms of a single field, and maintains for each term
+streamCount byte streams in the TermsHash's byte pool.
+*/
+type TermsHashPerField struct {
+	termsHash   *TermsHash
+	fieldInfo   *FieldInfo
+	streamCount int
+
+	intPool      *util.IntBlockPool
+	bytePool     *util.ByteBlockPool
+	termBytePool *util.ByteBlockPool
+
+	bytesHash     *util.BytesRefHash
+	postingsArray *ParallelPostingsArray
+
+	intUptos     []int32
+	intUptoStart int
+}
+
+/*
+Creates the per-field hash, whose postings arrays are allocated by
+the given consumer.
+*/
+func newTermsHashPerField(termsHash *TermsHash, fieldInfo *FieldInfo, streamCount int,
+	consumer postingsArrayConsumer) *TermsHashPerField {
+	ans := &TermsHashPerField{
+		termsHash:    termsHash,
+		fieldInfo:    fieldInfo,
+		streamCount:  streamCount,
+		intPool:      termsHash.intPool,
+		bytePool:     termsHash.bytePool,
+		termBytePool: termsHash.termBytePool,
+	}
+	ans.bytesHash = util.NewBytesRefHash(ans.termBytePool, TERMS_HASH_HASH_INIT_SIZE,
+		&postingsBytesStartArray{ans, consumer})
+	return ans
+}
+
+/*
+Adds the term to the hash. Returns the term id and true if the term
+was not seen yet, or the existing term id and false otherwise. Returns
+-1 if the term is too long to be indexed, in which case it is skipped.
+*/
+func (h *TermsHashPerField) add(term []byte) (termID int, isNew bool) {
+	// We are first in the chain so we must "intern" the term text
+	// into textStart address. Get the text & hash of this term.
+	termID, err := h.bytesHash.Add(term)
+	if err != nil {
+		h.skippingLongTerm(term, err)
+		return -1, false
+	}
+	if termID >= 0 {
+		// New posting
+		h.bytesHash.ByteStart(termID)
+		// Init stream slices
+		if h.streamCount+h.intPool.IntUpto > util.INT_BLOCK_SIZE {
+			h.intPool.NextBuffer()
+		}
+		if util.BYTE_BLOCK_SIZE-h.bytePool.ByteUpto < h.streamCount*util.FIRST_LEVEL_SIZE {
+			h.bytePool.NextBuffer()
+		}
+
+		h.intUptos = h.intPool.Buffer
+		h.intUptoStart = h.intPool.IntUpto
+		h.intPool.IntUpto += h.streamCount
+
+		h.postingsArray.intStarts[termID] = int32(h.intUptoStart + h.intPool.IntOffset)
+
+		for i := 0; i < h.streamCount; i++ {
+			upto := h.bytePool.NewSlice(util.FIRST_LEVEL_SIZE)
+			h.intUptos[h.intUptoStart+i] = int32(upto + h.bytePool.ByteOffset)
+		}
+		h.postingsArray.byteStarts[termID] = h.intUptos[h.intUptoStart]
+		return termID, true
+	}
+
+	termID = (-termID) - 1
+	intStart := int(h.postingsArray.intStarts[termID])
+	h.intUptos = h.intPool.Buffers[intStart>>util.INT_BLOCK_SHIFT]
+	h.intUptoStart = intStart & util.INT_BLOCK_MASK
+	return termID, false
+}
+
+func (h *TermsHashPerField) writeByte(stream int, b byte) {
+	upto := int(h.intUptos[h.intUptoStart+stream])
+	bytes := h.bytePool.Buffers[upto>>util.BYTE_BLOCK_SHIFT]
+	offset := upto & util.BYTE_BLOCK_MASK
+	if bytes[offset] != 0 {
+		// End of slice; allocate a new one
+		offset = h.bytePool.AllocSlice(bytes, offset)
+		bytes = h.bytePool.Buffer
+		h.intUptos[h.intUptoStart+stream] = int32(offset + h.bytePool.ByteOffset)
+	}
+	bytes[offset] = b
+	h.intUptos[h.intUptoStart+stream]++
+}
+
+func (h *TermsHashPerField) writeBytes(stream int, b []byte) {
+	// TODO: optimize
+	for _, v := range b {
+		h.writeByte(stream, v)
+	}
+}
+
+func (h *TermsHashPerField) writeVInt(stream int, i int32) {
+	for (i & ^0x7F) != 0 {
+		h.writeByte(stream, byte((i&0x7f)|0x80))
+		i = int32(uint32(i) >> 7)
+	}
+	h.writeByte(stream, byte(i))
+}
+
+// Positions the reader at the start of the given stream of the term.
+func (h *TermsHashPerField) initReader(reader *ByteSliceReader, termID, stream int) {
+	if stream >= h.streamCount {
+		panic("assert fail")
+	}
+	intStart := int(h.postingsArray.intStarts[termID])
+	ints := h.intPool.Buffers[intStart>>util.INT_BLOCK_SHIFT]
+	upto := intStart & util.INT_BLOCK_MASK
+	reader.init(h.bytePool,
+		int(h.postingsArray.byteStarts[termID])+stream*util.FIRST_LEVEL_SIZE,
+		int(ints[upto+stream]))
+}
+
+// Collapses the hash table and sorts in-place; returns the sorted
+// term ids.
+func (h *TermsHashPerField) sortPostings() []int32 {
+	return h.bytesHash.Sort()
+}
+
+// Clears the hashed terms, so that the hash can be reused once the
+// pools of the TermsHash are reset.
+func (h *TermsHashPerField) reset() {
+	h.bytesHash.Clear(false)
+	h.bytesHash.Reinit()
+}
+
+// Logs that a term longer than the term byte pool can hold is
+// skipped.
+func (h *TermsHashPerField) skippingLongTerm(term []byte, cause error) {
+	prefix := term
+	if len(prefix) > 30 {
+		prefix = prefix[:30]
+	}
+	log.Printf("WARNING: document contains at least one immense term in field=\"%v\" (whose UTF8 encoding is longer than the max length %v), all of which were skipped. Please correct the analyzer to not produce such terms. The prefix of the first immense term is: '%v...', original message: %v",
+		h.fieldInfo.name, util.BYTE_BLOCK_SIZE-2, string(prefix), cause)
+}
+
+/*
+Implemented by the per-field consumers of a TermsHash, which keep
+their per-term data in arrays parallel to the term ids.
+*/
+type postingsArrayConsumer interface {
+	// Allocates the postings arrays with the given size.
+	createPostingsArray(size int) *ParallelPostingsArray
+	// Grows the postings arrays along with the term ids.
+	growPostingsArray() *ParallelPostingsArray
+	// Releases the postings arrays.
+	clearPostingsArray()
+	// Returns the bytes used per term by the postings arrays.
+	bytesPerPosting() int
+}
+
+// TermsHashPerField.java/PostingsBytesStartArray
+
+/*
+Uses the text starts of the postings array as the term starts of the
+BytesRefHash, so the postings arrays grow along with the hash, and
+tracks the memory of the postings arrays.
+*/
+type postingsBytesStartArray struct {
+	perField *TermsHashPerField
+	consumer postingsArrayConsumer
+}
+
+func (a *postingsBytesStartArray) Init() []int32 {
+	if a.perField.postingsArray == nil {
+		a.perField.postingsArray = a.consumer.createPostingsArray(2)
+		a.addBytesUsed(a.perField.postingsArray.size)
+	}
+	return a.perField.postingsArray.textStarts
+}
+
+func (a *postingsBytesStartArray) Grow() []int32 {
+	oldSize := a.perField.postingsArray.size
+	a.perField.postingsArray = a.consumer.growPostingsArray()
+	a.addBytesUsed(a.perField.postingsArray.size - oldSize)
+	return a.perField.postingsArray.textStarts
+}
+
+func (a *postingsBytesStartArray) Clear() []int32 {
+	if a.perField.postingsArray != nil {
+		a.addBytesUsed(-a.perField.postingsArray.size)
+		a.consumer.clearPostingsArray()
+		a.perField.postingsArray = nil
+	}
+	return nil
+}
+
+func (a *postingsBytesStartArray) addBytesUsed(numPostings int) {
+	a.perField.termsHash.bytesUsed.AddAndGet(int64(numPostings * a.consumer.bytesPerPosting()))
+}
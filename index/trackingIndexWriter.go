@@ -0,0 +1,102 @@
+package index
+
+import (
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/store"
+	"sync/atomic"
+)
+
+// index/TrackingIndexWriter.java
+
+/*
+Class that tracks changes to a delegated IndexWriter, used by
+search.ControlledRealTimeReopenThread to ensure specific changes are
+visible. Create this class (passing your IndexWriter), and then pass
+this class to ControlledRealTimeReopenThread. Be sure to make all
+changes via the TrackingIndexWriter, otherwise
+ControlledRealTimeReopenThread won't know about the changes.
+*/
+type TrackingIndexWriter struct {
+	writer      *IndexWriter
+	indexingGen int64 // atomic
+}
+
+// Create a TrackingIndexWriter wrapping the provided IndexWriter.
+func NewTrackingIndexWriter(writer *IndexWriter) *TrackingIndexWriter {
+	return &TrackingIndexWriter{writer: writer, indexingGen: 1}
+}
+
+/*
+Calls IndexWriter.AddDocument() and returns the generation that
+reflects this change.
+*/
+func (w *TrackingIndexWriter) AddDocument(doc []document.IndexableField) (int64, error) {
+	if err := w.writer.AddDocument(doc); err != nil {
+		return 0, err
+	}
+	// Return gen as of when indexing finished:
+	return w.Generation(), nil
+}
+
+/*
+Calls IndexWriter.UpdateNumericDocValue() and returns the generation
+that reflects this change.
+*/
+func (w *TrackingIndexWriter) UpdateNumericDocValue(term Term, field string, value int64) (int64, error) {
+	if err := w.writer.UpdateNumericDocValue(term, field, value); err != nil {
+		return 0, err
+	}
+	// Return gen as of when indexing finished:
+	return w.Generation(), nil
+}
+
+/*
+Calls IndexWriter.UpdateBinaryDocValue() and returns the generation
+that reflects this change.
+*/
+func (w *TrackingIndexWriter) UpdateBinaryDocValue(term Term, field string, value []byte) (int64, error) {
+	if err := w.writer.UpdateBinaryDocValue(term, field, value); err != nil {
+		return 0, err
+	}
+	// Return gen as of when indexing finished:
+	return w.Generation(), nil
+}
+
+/*
+Calls IndexWriter.AddIndexes() and returns the generation that
+reflects this change.
+*/
+func (w *TrackingIndexWriter) AddIndexes(dirs ...store.Directory) (int64, error) {
+	if err := w.writer.AddIndexes(dirs...); err != nil {
+		return 0, err
+	}
+	// Return gen as of when indexing finished:
+	return w.Generation(), nil
+}
+
+/*
+Calls IndexWriter.AddIndexesFromReaders() and returns the generation
+that reflects this change.
+*/
+func (w *TrackingIndexWriter) AddIndexesFromReaders(readers ...IndexReader) (int64, error) {
+	if err := w.writer.AddIndexesFromReaders(readers...); err != nil {
+		return 0, err
+	}
+	// Return gen as of when indexing finished:
+	return w.Generation(), nil
+}
+
+// Return the current generation being indexed.
+func (w *TrackingIndexWriter) Generation() int64 {
+	return atomic.LoadInt64(&w.indexingGen)
+}
+
+// Return the wrapped IndexWriter.
+func (w *TrackingIndexWriter) IndexWriter() *IndexWriter {
+	return w.writer
+}
+
+// Return and increment current gen.
+func (w *TrackingIndexWriter) GetAndIncrementGeneration() int64 {
+	return atomic.AddInt64(&w.indexingGen, 1) - 1
+}
@@ -0,0 +1,62 @@
+package docvalues
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/docvalues/DoubleDocValues.java
+
+/*
+Embeddable implementation of FunctionValues for a ValueSource whose
+values are float64: all the values are derived from DoubleVal() of
+self, which must be implemented.
+*/
+type DoubleDocValues struct {
+	self function.FunctionValues
+	vs   function.ValueSource
+}
+
+func NewDoubleDocValues(self function.FunctionValues, vs function.ValueSource) *DoubleDocValues {
+	return &DoubleDocValues{self, vs}
+}
+
+func (v *DoubleDocValues) FloatVal(doc int) float32 {
+	return float32(v.self.DoubleVal(doc))
+}
+
+func (v *DoubleDocValues) IntVal(doc int) int32 {
+	return int32(v.self.DoubleVal(doc))
+}
+
+func (v *DoubleDocValues) LongVal(doc int) int64 {
+	return int64(v.self.DoubleVal(doc))
+}
+
+func (v *DoubleDocValues) StrVal(doc int) string {
+	return fmt.Sprint(v.self.DoubleVal(doc))
+}
+
+func (v *DoubleDocValues) BoolVal(doc int) bool {
+	return v.self.DoubleVal(doc) != 0
+}
+
+func (v *DoubleDocValues) ObjectVal(doc int) interface{} {
+	if v.self.Exists(doc) {
+		return v.self.DoubleVal(doc)
+	}
+	return nil
+}
+
+func (v *DoubleDocValues) Exists(doc int) bool {
+	return true
+}
+
+func (v *DoubleDocValues) Explain(doc int) *search.Explanation {
+	return search.NewExplanation(v.self.FloatVal(doc), v.self.ToString(doc))
+}
+
+func (v *DoubleDocValues) ToString(doc int) string {
+	return v.vs.Description() + "=" + v.self.StrVal(doc)
+}
@@ -0,0 +1,62 @@
+package docvalues
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/docvalues/FloatDocValues.java
+
+/*
+Embeddable implementation of FunctionValues for a ValueSource whose
+values are float32: all the values are derived from FloatVal() of
+self, which must be implemented.
+*/
+type FloatDocValues struct {
+	self function.FunctionValues
+	vs   function.ValueSource
+}
+
+func NewFloatDocValues(self function.FunctionValues, vs function.ValueSource) *FloatDocValues {
+	return &FloatDocValues{self, vs}
+}
+
+func (v *FloatDocValues) IntVal(doc int) int32 {
+	return int32(v.self.FloatVal(doc))
+}
+
+func (v *FloatDocValues) LongVal(doc int) int64 {
+	return int64(v.self.FloatVal(doc))
+}
+
+func (v *FloatDocValues) DoubleVal(doc int) float64 {
+	return float64(v.self.FloatVal(doc))
+}
+
+func (v *FloatDocValues) StrVal(doc int) string {
+	return fmt.Sprint(v.self.FloatVal(doc))
+}
+
+func (v *FloatDocValues) BoolVal(doc int) bool {
+	return v.self.IntVal(doc) != 0
+}
+
+func (v *FloatDocValues) ObjectVal(doc int) interface{} {
+	if v.self.Exists(doc) {
+		return v.self.FloatVal(doc)
+	}
+	return nil
+}
+
+func (v *FloatDocValues) Exists(doc int) bool {
+	return true
+}
+
+func (v *FloatDocValues) Explain(doc int) *search.Explanation {
+	return search.NewExplanation(v.self.FloatVal(doc), v.self.ToString(doc))
+}
+
+func (v *FloatDocValues) ToString(doc int) string {
+	return v.vs.Description() + "=" + v.self.StrVal(doc)
+}
@@ -0,0 +1,62 @@
+package docvalues
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/docvalues/IntDocValues.java
+
+/*
+Embeddable implementation of FunctionValues for a ValueSource whose
+values are int32: all the values are derived from IntVal() of self,
+which must be implemented.
+*/
+type IntDocValues struct {
+	self function.FunctionValues
+	vs   function.ValueSource
+}
+
+func NewIntDocValues(self function.FunctionValues, vs function.ValueSource) *IntDocValues {
+	return &IntDocValues{self, vs}
+}
+
+func (v *IntDocValues) FloatVal(doc int) float32 {
+	return float32(v.self.IntVal(doc))
+}
+
+func (v *IntDocValues) LongVal(doc int) int64 {
+	return int64(v.self.IntVal(doc))
+}
+
+func (v *IntDocValues) DoubleVal(doc int) float64 {
+	return float64(v.self.IntVal(doc))
+}
+
+func (v *IntDocValues) StrVal(doc int) string {
+	return fmt.Sprint(v.self.IntVal(doc))
+}
+
+func (v *IntDocValues) BoolVal(doc int) bool {
+	return v.self.IntVal(doc) != 0
+}
+
+func (v *IntDocValues) ObjectVal(doc int) interface{} {
+	if v.self.Exists(doc) {
+		return v.self.IntVal(doc)
+	}
+	return nil
+}
+
+func (v *IntDocValues) Exists(doc int) bool {
+	return true
+}
+
+func (v *IntDocValues) Explain(doc int) *search.Explanation {
+	return search.NewExplanation(v.self.FloatVal(doc), v.self.ToString(doc))
+}
+
+func (v *IntDocValues) ToString(doc int) string {
+	return v.vs.Description() + "=" + v.self.StrVal(doc)
+}
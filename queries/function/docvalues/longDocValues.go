@@ -0,0 +1,62 @@
+package docvalues
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/docvalues/LongDocValues.java
+
+/*
+Embeddable implementation of FunctionValues for a ValueSource whose
+values are int64: all the values are derived from LongVal() of self,
+which must be implemented.
+*/
+type LongDocValues struct {
+	self function.FunctionValues
+	vs   function.ValueSource
+}
+
+func NewLongDocValues(self function.FunctionValues, vs function.ValueSource) *LongDocValues {
+	return &LongDocValues{self, vs}
+}
+
+func (v *LongDocValues) FloatVal(doc int) float32 {
+	return float32(v.self.LongVal(doc))
+}
+
+func (v *LongDocValues) IntVal(doc int) int32 {
+	return int32(v.self.LongVal(doc))
+}
+
+func (v *LongDocValues) DoubleVal(doc int) float64 {
+	return float64(v.self.LongVal(doc))
+}
+
+func (v *LongDocValues) StrVal(doc int) string {
+	return fmt.Sprint(v.self.LongVal(doc))
+}
+
+func (v *LongDocValues) BoolVal(doc int) bool {
+	return v.self.LongVal(doc) != 0
+}
+
+func (v *LongDocValues) ObjectVal(doc int) interface{} {
+	if v.self.Exists(doc) {
+		return v.self.LongVal(doc)
+	}
+	return nil
+}
+
+func (v *LongDocValues) Exists(doc int) bool {
+	return true
+}
+
+func (v *LongDocValues) Explain(doc int) *search.Explanation {
+	return search.NewExplanation(v.self.FloatVal(doc), v.self.ToString(doc))
+}
+
+func (v *LongDocValues) ToString(doc int) string {
+	return v.vs.Description() + "=" + v.self.StrVal(doc)
+}
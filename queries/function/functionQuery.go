@@ -0,0 +1,183 @@
+package function
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/search"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+)
+
+// queries/function/FunctionQuery.java
+
+/*
+Returns a score for each document based on a ValueSource, often some
+function of the value of a field.
+
+Note: This API is experimental and may change in non backward-
+compatible ways in the future.
+*/
+type FunctionQuery struct {
+	*search.AbstractQuery
+	source ValueSource
+}
+
+// Creates a FunctionQuery scoring all the documents by source.
+func NewFunctionQuery(source ValueSource) *FunctionQuery {
+	ans := &FunctionQuery{source: source}
+	ans.AbstractQuery = search.NewAbstractQuery(ans)
+	return ans
+}
+
+// Returns the associated ValueSource.
+func (q *FunctionQuery) ValueSource() ValueSource {
+	return q.source
+}
+
+func (q *FunctionQuery) CreateWeight(ss *search.IndexSearcher) (search.Weight, error) {
+	return newFunctionWeight(q, ss)
+}
+
+func (q *FunctionQuery) ToString(field string) string {
+	if boost := q.Boost(); boost != 1 {
+		return fmt.Sprintf("(%v)^%v", q.source.Description(), boost)
+	}
+	return q.source.Description()
+}
+
+// queries/function/FunctionQuery.java/FunctionWeight
+
+type functionWeight struct {
+	query       *FunctionQuery
+	searcher    *search.IndexSearcher
+	queryNorm   float32
+	queryWeight float32
+	context     Context
+}
+
+func newFunctionWeight(query *FunctionQuery, searcher *search.IndexSearcher) (*functionWeight, error) {
+	context := NewContext(searcher)
+	if err := query.source.CreateWeight(context, searcher); err != nil {
+		return nil, err
+	}
+	return &functionWeight{query: query, searcher: searcher, context: context}, nil
+}
+
+func (w *functionWeight) Query() search.Query {
+	return w.query
+}
+
+func (w *functionWeight) ValueForNormalization() float32 {
+	w.queryWeight = w.query.Boost()
+	return w.queryWeight * w.queryWeight
+}
+
+func (w *functionWeight) Normalize(norm float32, topLevelBoost float32) {
+	w.queryNorm = norm * topLevelBoost
+	w.queryWeight *= w.queryNorm
+}
+
+func (w *functionWeight) Scorer(ctx index.AtomicReaderContext,
+	scoreDocsInOrder, topScorer bool, acceptDocs util.Bits) (search.Scorer, error) {
+	return newAllScorer(ctx, acceptDocs, w, w.queryWeight)
+}
+
+func (w *functionWeight) IsScoresDocsOutOfOrder() bool {
+	return false
+}
+
+func (w *functionWeight) Explain(ctx index.AtomicReaderContext, doc int) (*search.Explanation, error) {
+	scorer, err := newAllScorer(ctx, ctx.Reader().(index.AtomicReader).LiveDocs(), w, w.queryWeight)
+	if err != nil {
+		return nil, err
+	}
+	return scorer.explain(doc), nil
+}
+
+func (w *functionWeight) String() string {
+	return fmt.Sprintf("weight(%v)", w.query)
+}
+
+// queries/function/FunctionQuery.java/AllScorer
+
+// Scores all the accepted documents by their function value.
+type allScorer struct {
+	*search.ScorerImpl
+	weight     *functionWeight
+	maxDoc     int
+	qWeight    float32
+	doc        int
+	vals       FunctionValues
+	acceptDocs util.Bits
+}
+
+func newAllScorer(ctx index.AtomicReaderContext, acceptDocs util.Bits,
+	w *functionWeight, qWeight float32) (*allScorer, error) {
+	vals, err := w.query.source.Values(w.context, ctx)
+	if err != nil {
+		return nil, err
+	}
+	ans := &allScorer{
+		weight:     w,
+		maxDoc:     ctx.Reader().MaxDoc(),
+		qWeight:    qWeight,
+		doc:        -1,
+		vals:       vals,
+		acceptDocs: acceptDocs,
+	}
+	ans.ScorerImpl = search.NewScorer(ans, w)
+	return ans, nil
+}
+
+func (s *allScorer) DocId() int {
+	return s.doc
+}
+
+func (s *allScorer) NextDoc() (int, bool) {
+	for {
+		s.doc++
+		if s.doc >= s.maxDoc {
+			s.doc = index.NO_MORE_DOCS
+			return s.doc, false
+		}
+		if s.acceptDocs != nil && !s.acceptDocs.Get(s.doc) {
+			continue
+		}
+		return s.doc, true
+	}
+}
+
+func (s *allScorer) Advance(target int) (int, bool) {
+	// this will work even if target==NO_MORE_DOCS
+	s.doc = target - 1
+	return s.NextDoc()
+}
+
+func (s *allScorer) Score() float32 {
+	score := s.qWeight * s.vals.FloatVal(s.doc)
+	// Current priority queues can't handle NaN and -Inf, so map to
+	// -math.MaxFloat32. This conditional handles both -Inf and NaN
+	// since comparisons with NaN are always false.
+	if score > float32(math.Inf(-1)) {
+		return score
+	}
+	return -math.MaxFloat32
+}
+
+func (s *allScorer) Freq() int {
+	return 1
+}
+
+func (s *allScorer) Cost() int64 {
+	return int64(s.maxDoc)
+}
+
+func (s *allScorer) explain(doc int) *search.Explanation {
+	sc := s.qWeight * s.vals.FloatVal(doc)
+	result := search.NewComplexExplanation(true, sc,
+		fmt.Sprintf("FunctionQuery(%v), product of:", s.weight.query.source.Description()))
+	result.AddDetail(s.vals.Explain(doc))
+	result.AddDetail(search.NewExplanation(s.weight.query.Boost(), "boost"))
+	result.AddDetail(search.NewExplanation(s.weight.queryNorm, "queryNorm"))
+	return result
+}
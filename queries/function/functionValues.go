@@ -0,0 +1,32 @@
+package function
+
+import (
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/FunctionValues.java
+
+/*
+Represents field values as different types. Normally created via a
+ValueSource for a particular field and reader.
+
+The docvalues package holds the embeddable implementations deriving
+all the values from a single one, e.g. FloatDocValues.
+*/
+type FunctionValues interface {
+	FloatVal(doc int) float32
+	IntVal(doc int) int32
+	LongVal(doc int) int64
+	DoubleVal(doc int) float64
+	StrVal(doc int) string
+	BoolVal(doc int) bool
+	/*
+		Native representation of the value, e.g. float32 for a float
+		field, or nil if the document has no value.
+	*/
+	ObjectVal(doc int) interface{}
+	// Returns true if there is a value for this document.
+	Exists(doc int) bool
+	Explain(doc int) *search.Explanation
+	ToString(doc int) string
+}
@@ -0,0 +1,132 @@
+package function
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/ValueSource.java
+
+/*
+Instantiates FunctionValues for a particular reader.
+
+Often used when creating a FunctionQuery.
+*/
+type ValueSource interface {
+	/*
+		Gets the values for this reader and the context that was
+		previously passed to CreateWeight().
+	*/
+	Values(context Context, readerContext index.AtomicReaderContext) (FunctionValues, error)
+	/*
+		Implementations should propagate CreateWeight() to
+		sub-ValueSources which can optionally store weight info in the
+		context. The context object will be passed to Values() where
+		this info can be retrieved.
+	*/
+	CreateWeight(context Context, searcher *search.IndexSearcher) error
+	// Description of field, used in explain().
+	Description() string
+}
+
+/*
+The state shared by the ValueSources of a search, keyed by the
+ValueSources themselves, or by names like "searcher".
+*/
+type Context map[interface{}]interface{}
+
+// Returns a new non-threadsafe context map.
+func NewContext(searcher *search.IndexSearcher) Context {
+	return Context{"searcher": searcher}
+}
+
+// queries/function/ValueSource.java/ValueSourceSortField
+
+/*
+Returns a SortField sorting the hits of searcher by the double
+values of source, in ascending order unless reverse is true.
+*/
+func NewSortField(searcher *search.IndexSearcher, source ValueSource, reverse bool) (*search.SortField, error) {
+	context := NewContext(searcher)
+	if err := source.CreateWeight(context, searcher); err != nil {
+		return nil, err
+	}
+	return search.NewCustomSortField(source.Description(),
+		&valueSourceComparatorSource{source, context}, reverse), nil
+}
+
+// queries/function/ValueSource.java/ValueSourceComparatorSource
+
+type valueSourceComparatorSource struct {
+	source  ValueSource
+	context Context
+}
+
+func (s *valueSourceComparatorSource) NewComparator(field string, numHits, sortPos int,
+	reversed bool) search.FieldComparator {
+	return &valueSourceComparator{
+		source:  s.source,
+		context: s.context,
+		values:  make([]float64, numHits),
+	}
+}
+
+// queries/function/ValueSource.java/ValueSourceComparator
+
+// Implement a FieldComparator that works off of the FunctionValues
+// for a ValueSource instead of the normal Lucene FieldComparator that
+// works off of a FieldCache.
+type valueSourceComparator struct {
+	source  ValueSource
+	context Context
+	values  []float64
+	docVals FunctionValues
+	bottom  float64
+}
+
+func (c *valueSourceComparator) Compare(slot1, slot2 int) int {
+	return compareFloat64(c.values[slot1], c.values[slot2])
+}
+
+func (c *valueSourceComparator) CompareBottom(doc int) int {
+	return compareFloat64(c.bottom, c.docVals.DoubleVal(doc))
+}
+
+func (c *valueSourceComparator) Copy(slot, doc int) {
+	c.values[slot] = c.docVals.DoubleVal(doc)
+}
+
+func (c *valueSourceComparator) SetNextReader(ctx index.AtomicReaderContext) (search.FieldComparator, error) {
+	var err error
+	c.docVals, err = c.source.Values(c.context, ctx)
+	return c, err
+}
+
+func (c *valueSourceComparator) SetBottom(slot int) {
+	c.bottom = c.values[slot]
+}
+
+func (c *valueSourceComparator) SetScorer(scorer search.Scorer) {}
+
+func (c *valueSourceComparator) Value(slot int) interface{} {
+	return c.values[slot]
+}
+
+// Like Java's Double.compare(), where NaN is greater than any other
+// value.
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	case a == b:
+		return 0
+	case a != a: // NaN
+		if b != b {
+			return 0
+		}
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,56 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/queries/function/docvalues"
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/valuesource/ConstValueSource.java
+
+// ConstValueSource returns a constant for all documents
+type ConstValueSource struct {
+	constant float32
+}
+
+func NewConstValueSource(constant float32) *ConstValueSource {
+	return &ConstValueSource{constant}
+}
+
+func (s *ConstValueSource) Description() string {
+	return fmt.Sprintf("const(%v)", s.constant)
+}
+
+func (s *ConstValueSource) CreateWeight(context function.Context, searcher *search.IndexSearcher) error {
+	return nil
+}
+
+func (s *ConstValueSource) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	ans := &constValues{source: s}
+	ans.FloatDocValues = docvalues.NewFloatDocValues(ans, s)
+	return ans, nil
+}
+
+type constValues struct {
+	*docvalues.FloatDocValues
+	source *ConstValueSource
+}
+
+func (v *constValues) FloatVal(doc int) float32 {
+	return v.source.constant
+}
+
+func (v *constValues) DoubleVal(doc int) float64 {
+	return float64(v.source.constant)
+}
+
+func (v *constValues) BoolVal(doc int) bool {
+	return v.source.constant != 0
+}
+
+func (v *constValues) ToString(doc int) string {
+	return v.source.Description()
+}
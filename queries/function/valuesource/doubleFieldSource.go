@@ -0,0 +1,67 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/queries/function/docvalues"
+	"github.com/balzaczyy/golucene/search"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// queries/function/valuesource/DoubleFieldSource.java
+
+/*
+Obtains double field values from the FieldCache and makes those
+values available as other numeric types, casting as needed.
+*/
+type DoubleFieldSource struct {
+	*FieldCacheSource
+	parser search.DoubleParser
+}
+
+func NewDoubleFieldSource(field string) *DoubleFieldSource {
+	return NewDoubleFieldSourceWithParser(field, nil)
+}
+
+/*
+Creates a DoubleFieldSource parsing the terms of field with parser,
+or the default parsers of the FieldCache if nil.
+*/
+func NewDoubleFieldSourceWithParser(field string, parser search.DoubleParser) *DoubleFieldSource {
+	return &DoubleFieldSource{newFieldCacheSource(field), parser}
+}
+
+func (s *DoubleFieldSource) Description() string {
+	return fmt.Sprintf("double(%v)", s.field)
+}
+
+func (s *DoubleFieldSource) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	reader := readerContext.Reader().(index.AtomicReader)
+	arr, err := s.cache.Doubles(reader, s.field, s.parser, true)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := s.cache.DocsWithField(reader, s.field)
+	if err != nil {
+		return nil, err
+	}
+	ans := &doubleFieldValues{arr: arr, valid: valid}
+	ans.DoubleDocValues = docvalues.NewDoubleDocValues(ans, s)
+	return ans, nil
+}
+
+type doubleFieldValues struct {
+	*docvalues.DoubleDocValues
+	arr   search.FieldCacheDoubles
+	valid util.Bits
+}
+
+func (v *doubleFieldValues) DoubleVal(doc int) float64 {
+	return v.arr.Get(doc)
+}
+
+func (v *doubleFieldValues) Exists(doc int) bool {
+	return v.valid.Get(doc)
+}
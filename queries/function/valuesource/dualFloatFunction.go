@@ -0,0 +1,87 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/queries/function/docvalues"
+	"github.com/balzaczyy/golucene/search"
+	"math"
+)
+
+// queries/function/valuesource/DualFloatFunction.java
+
+// Abstract ValueSource implementation which wraps two ValueSources
+// and applies an extendible float function to their values.
+type DualFloatFunction struct {
+	a, b function.ValueSource
+	name string
+	fn   func(doc int, aVals, bVals function.FunctionValues) float32
+}
+
+/*
+Creates a DualFloatFunction named name, whose value for a document
+is fn of the values of a and b.
+*/
+func NewDualFloatFunction(name string, fn func(doc int, aVals, bVals function.FunctionValues) float32,
+	a, b function.ValueSource) *DualFloatFunction {
+	return &DualFloatFunction{a, b, name, fn}
+}
+
+func (f *DualFloatFunction) Description() string {
+	return fmt.Sprintf("%v(%v,%v)", f.name, f.a.Description(), f.b.Description())
+}
+
+func (f *DualFloatFunction) CreateWeight(context function.Context, searcher *search.IndexSearcher) error {
+	if err := f.a.CreateWeight(context, searcher); err != nil {
+		return err
+	}
+	return f.b.CreateWeight(context, searcher)
+}
+
+func (f *DualFloatFunction) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	aVals, err := f.a.Values(context, readerContext)
+	if err != nil {
+		return nil, err
+	}
+	bVals, err := f.b.Values(context, readerContext)
+	if err != nil {
+		return nil, err
+	}
+	ans := &dualFloatValues{f: f, aVals: aVals, bVals: bVals}
+	ans.FloatDocValues = docvalues.NewFloatDocValues(ans, f)
+	return ans, nil
+}
+
+type dualFloatValues struct {
+	*docvalues.FloatDocValues
+	f            *DualFloatFunction
+	aVals, bVals function.FunctionValues
+}
+
+func (v *dualFloatValues) FloatVal(doc int) float32 {
+	return v.f.fn(doc, v.aVals, v.bVals)
+}
+
+func (v *dualFloatValues) ToString(doc int) string {
+	return fmt.Sprintf("%v(%v,%v)", v.f.name, v.aVals.ToString(doc), v.bVals.ToString(doc))
+}
+
+// queries/function/valuesource/DivFloatFunction.java
+
+// Function to divide "a" by "b"
+func NewDivFloatFunction(a, b function.ValueSource) *DualFloatFunction {
+	return NewDualFloatFunction("div", func(doc int, aVals, bVals function.FunctionValues) float32 {
+		return aVals.FloatVal(doc) / bVals.FloatVal(doc)
+	}, a, b)
+}
+
+// queries/function/valuesource/PowFloatFunction.java
+
+// Function to raise the base "a" to the power "b"
+func NewPowFloatFunction(a, b function.ValueSource) *DualFloatFunction {
+	return NewDualFloatFunction("pow", func(doc int, aVals, bVals function.FunctionValues) float32 {
+		return float32(math.Pow(float64(aVals.FloatVal(doc)), float64(bVals.FloatVal(doc))))
+	}, a, b)
+}
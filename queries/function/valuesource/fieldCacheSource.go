@@ -0,0 +1,34 @@
+package valuesource
+
+import (
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/valuesource/FieldCacheSource.java
+
+/*
+Embeddable base of the ValueSources reading the values of a field
+from the FieldCache, which returns the numeric doc values of the
+field if it has any, or else un-inverts its indexed terms.
+*/
+type FieldCacheSource struct {
+	field string
+	cache search.FieldCache
+}
+
+func newFieldCacheSource(field string) *FieldCacheSource {
+	return &FieldCacheSource{field, search.FIELD_CACHE_DEFAULT}
+}
+
+func (s *FieldCacheSource) FieldCache() search.FieldCache {
+	return s.cache
+}
+
+func (s *FieldCacheSource) Field() string {
+	return s.field
+}
+
+func (s *FieldCacheSource) CreateWeight(context function.Context, searcher *search.IndexSearcher) error {
+	return nil
+}
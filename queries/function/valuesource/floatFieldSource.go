@@ -0,0 +1,67 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/queries/function/docvalues"
+	"github.com/balzaczyy/golucene/search"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// queries/function/valuesource/FloatFieldSource.java
+
+/*
+Obtains float field values from the FieldCache and makes those values
+available as other numeric types, casting as needed.
+*/
+type FloatFieldSource struct {
+	*FieldCacheSource
+	parser search.FloatParser
+}
+
+func NewFloatFieldSource(field string) *FloatFieldSource {
+	return NewFloatFieldSourceWithParser(field, nil)
+}
+
+/*
+Creates a FloatFieldSource parsing the terms of field with parser, or
+the default parsers of the FieldCache if nil.
+*/
+func NewFloatFieldSourceWithParser(field string, parser search.FloatParser) *FloatFieldSource {
+	return &FloatFieldSource{newFieldCacheSource(field), parser}
+}
+
+func (s *FloatFieldSource) Description() string {
+	return fmt.Sprintf("float(%v)", s.field)
+}
+
+func (s *FloatFieldSource) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	reader := readerContext.Reader().(index.AtomicReader)
+	arr, err := s.cache.Floats(reader, s.field, s.parser, true)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := s.cache.DocsWithField(reader, s.field)
+	if err != nil {
+		return nil, err
+	}
+	ans := &floatFieldValues{arr: arr, valid: valid}
+	ans.FloatDocValues = docvalues.NewFloatDocValues(ans, s)
+	return ans, nil
+}
+
+type floatFieldValues struct {
+	*docvalues.FloatDocValues
+	arr   search.FieldCacheFloats
+	valid util.Bits
+}
+
+func (v *floatFieldValues) FloatVal(doc int) float32 {
+	return v.arr.Get(doc)
+}
+
+func (v *floatFieldValues) Exists(doc int) bool {
+	return v.valid.Get(doc)
+}
@@ -0,0 +1,107 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/valuesource/IfFunction.java
+
+/*
+Depending on the boolean value of the ifSource function, returns the
+value of the trueSource or falseSource function.
+*/
+type IfFunction struct {
+	ifSource, trueSource, falseSource function.ValueSource
+}
+
+func NewIfFunction(ifSource, trueSource, falseSource function.ValueSource) *IfFunction {
+	return &IfFunction{ifSource, trueSource, falseSource}
+}
+
+func (f *IfFunction) Description() string {
+	return fmt.Sprintf("if(%v,%v,%v)", f.ifSource.Description(),
+		f.trueSource.Description(), f.falseSource.Description())
+}
+
+func (f *IfFunction) CreateWeight(context function.Context, searcher *search.IndexSearcher) error {
+	if err := f.ifSource.CreateWeight(context, searcher); err != nil {
+		return err
+	}
+	if err := f.trueSource.CreateWeight(context, searcher); err != nil {
+		return err
+	}
+	return f.falseSource.CreateWeight(context, searcher)
+}
+
+func (f *IfFunction) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	ifVals, err := f.ifSource.Values(context, readerContext)
+	if err != nil {
+		return nil, err
+	}
+	trueVals, err := f.trueSource.Values(context, readerContext)
+	if err != nil {
+		return nil, err
+	}
+	falseVals, err := f.falseSource.Values(context, readerContext)
+	if err != nil {
+		return nil, err
+	}
+	return &ifValues{ifVals, trueVals, falseVals}, nil
+}
+
+type ifValues struct {
+	ifVals, trueVals, falseVals function.FunctionValues
+}
+
+// Returns the values of the branch taken by doc.
+func (v *ifValues) branch(doc int) function.FunctionValues {
+	if v.ifVals.BoolVal(doc) {
+		return v.trueVals
+	}
+	return v.falseVals
+}
+
+func (v *ifValues) FloatVal(doc int) float32 {
+	return v.branch(doc).FloatVal(doc)
+}
+
+func (v *ifValues) IntVal(doc int) int32 {
+	return v.branch(doc).IntVal(doc)
+}
+
+func (v *ifValues) LongVal(doc int) int64 {
+	return v.branch(doc).LongVal(doc)
+}
+
+func (v *ifValues) DoubleVal(doc int) float64 {
+	return v.branch(doc).DoubleVal(doc)
+}
+
+func (v *ifValues) StrVal(doc int) string {
+	return v.branch(doc).StrVal(doc)
+}
+
+func (v *ifValues) BoolVal(doc int) bool {
+	return v.branch(doc).BoolVal(doc)
+}
+
+func (v *ifValues) ObjectVal(doc int) interface{} {
+	return v.branch(doc).ObjectVal(doc)
+}
+
+func (v *ifValues) Exists(doc int) bool {
+	return true // TODO: flow through to any sub-sources?
+}
+
+func (v *ifValues) Explain(doc int) *search.Explanation {
+	return search.NewExplanation(v.FloatVal(doc), v.ToString(doc))
+}
+
+func (v *ifValues) ToString(doc int) string {
+	return fmt.Sprintf("if(%v,%v,%v)", v.ifVals.ToString(doc),
+		v.trueVals.ToString(doc), v.falseVals.ToString(doc))
+}
@@ -0,0 +1,67 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/queries/function/docvalues"
+	"github.com/balzaczyy/golucene/search"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// queries/function/valuesource/IntFieldSource.java
+
+/*
+Obtains int field values from the FieldCache and makes those values
+available as other numeric types, casting as needed.
+*/
+type IntFieldSource struct {
+	*FieldCacheSource
+	parser search.IntParser
+}
+
+func NewIntFieldSource(field string) *IntFieldSource {
+	return NewIntFieldSourceWithParser(field, nil)
+}
+
+/*
+Creates an IntFieldSource parsing the terms of field with parser, or
+the default parsers of the FieldCache if nil.
+*/
+func NewIntFieldSourceWithParser(field string, parser search.IntParser) *IntFieldSource {
+	return &IntFieldSource{newFieldCacheSource(field), parser}
+}
+
+func (s *IntFieldSource) Description() string {
+	return fmt.Sprintf("int(%v)", s.field)
+}
+
+func (s *IntFieldSource) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	reader := readerContext.Reader().(index.AtomicReader)
+	arr, err := s.cache.Ints(reader, s.field, s.parser, true)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := s.cache.DocsWithField(reader, s.field)
+	if err != nil {
+		return nil, err
+	}
+	ans := &intFieldValues{arr: arr, valid: valid}
+	ans.IntDocValues = docvalues.NewIntDocValues(ans, s)
+	return ans, nil
+}
+
+type intFieldValues struct {
+	*docvalues.IntDocValues
+	arr   search.FieldCacheInts
+	valid util.Bits
+}
+
+func (v *intFieldValues) IntVal(doc int) int32 {
+	return v.arr.Get(doc)
+}
+
+func (v *intFieldValues) Exists(doc int) bool {
+	return v.valid.Get(doc)
+}
@@ -0,0 +1,60 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/queries/function/docvalues"
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/valuesource/LinearFloatFunction.java
+
+/*
+LinearFloatFunction implements a linear function over another
+ValueSource.
+
+Normally Used as an argument to a FunctionQuery
+*/
+type LinearFloatFunction struct {
+	source           function.ValueSource
+	slope, intercept float32
+}
+
+// Creates a function of the values x of source: slope * x + intercept.
+func NewLinearFloatFunction(source function.ValueSource, slope, intercept float32) *LinearFloatFunction {
+	return &LinearFloatFunction{source, slope, intercept}
+}
+
+func (f *LinearFloatFunction) Description() string {
+	return fmt.Sprintf("%v*float(%v)+%v", f.slope, f.source.Description(), f.intercept)
+}
+
+func (f *LinearFloatFunction) CreateWeight(context function.Context, searcher *search.IndexSearcher) error {
+	return f.source.CreateWeight(context, searcher)
+}
+
+func (f *LinearFloatFunction) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	vals, err := f.source.Values(context, readerContext)
+	if err != nil {
+		return nil, err
+	}
+	ans := &linearFloatValues{f: f, vals: vals}
+	ans.FloatDocValues = docvalues.NewFloatDocValues(ans, f)
+	return ans, nil
+}
+
+type linearFloatValues struct {
+	*docvalues.FloatDocValues
+	f    *LinearFloatFunction
+	vals function.FunctionValues
+}
+
+func (v *linearFloatValues) FloatVal(doc int) float32 {
+	return v.vals.FloatVal(doc)*v.f.slope + v.f.intercept
+}
+
+func (v *linearFloatValues) ToString(doc int) string {
+	return fmt.Sprintf("%v*float(%v)+%v", v.f.slope, v.vals.ToString(doc), v.f.intercept)
+}
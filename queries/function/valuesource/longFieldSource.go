@@ -0,0 +1,67 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/queries/function/docvalues"
+	"github.com/balzaczyy/golucene/search"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// queries/function/valuesource/LongFieldSource.java
+
+/*
+Obtains long field values from the FieldCache and makes those values
+available as other numeric types, casting as needed.
+*/
+type LongFieldSource struct {
+	*FieldCacheSource
+	parser search.LongParser
+}
+
+func NewLongFieldSource(field string) *LongFieldSource {
+	return NewLongFieldSourceWithParser(field, nil)
+}
+
+/*
+Creates a LongFieldSource parsing the terms of field with parser, or
+the default parsers of the FieldCache if nil.
+*/
+func NewLongFieldSourceWithParser(field string, parser search.LongParser) *LongFieldSource {
+	return &LongFieldSource{newFieldCacheSource(field), parser}
+}
+
+func (s *LongFieldSource) Description() string {
+	return fmt.Sprintf("long(%v)", s.field)
+}
+
+func (s *LongFieldSource) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	reader := readerContext.Reader().(index.AtomicReader)
+	arr, err := s.cache.Longs(reader, s.field, s.parser, true)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := s.cache.DocsWithField(reader, s.field)
+	if err != nil {
+		return nil, err
+	}
+	ans := &longFieldValues{arr: arr, valid: valid}
+	ans.LongDocValues = docvalues.NewLongDocValues(ans, s)
+	return ans, nil
+}
+
+type longFieldValues struct {
+	*docvalues.LongDocValues
+	arr   search.FieldCacheLongs
+	valid util.Bits
+}
+
+func (v *longFieldValues) LongVal(doc int) int64 {
+	return v.arr.Get(doc)
+}
+
+func (v *longFieldValues) Exists(doc int) bool {
+	return v.valid.Get(doc)
+}
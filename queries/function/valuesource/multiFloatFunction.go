@@ -0,0 +1,154 @@
+package valuesource
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/queries/function/docvalues"
+	"github.com/balzaczyy/golucene/search"
+	"math"
+)
+
+// queries/function/valuesource/MultiFloatFunction.java
+
+/*
+Abstract ValueSource implementation which wraps multiple ValueSources
+and applies an extendible float function to their values.
+*/
+type MultiFloatFunction struct {
+	sources []function.ValueSource
+	name    string
+	fn      func(doc int, valsArr []function.FunctionValues) float32
+}
+
+/*
+Creates a MultiFloatFunction named name, whose value for a document
+is fn of the values of the sources.
+*/
+func NewMultiFloatFunction(name string, fn func(doc int, valsArr []function.FunctionValues) float32,
+	sources ...function.ValueSource) *MultiFloatFunction {
+	return &MultiFloatFunction{sources, name, fn}
+}
+
+func (f *MultiFloatFunction) Description() string {
+	var buf bytes.Buffer
+	buf.WriteString(f.name)
+	buf.WriteRune('(')
+	for i, source := range f.sources {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		buf.WriteString(source.Description())
+	}
+	buf.WriteRune(')')
+	return buf.String()
+}
+
+func (f *MultiFloatFunction) CreateWeight(context function.Context, searcher *search.IndexSearcher) error {
+	for _, source := range f.sources {
+		if err := source.CreateWeight(context, searcher); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *MultiFloatFunction) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	valsArr := make([]function.FunctionValues, len(f.sources))
+	for i, source := range f.sources {
+		var err error
+		if valsArr[i], err = source.Values(context, readerContext); err != nil {
+			return nil, err
+		}
+	}
+	ans := &multiFloatValues{f: f, valsArr: valsArr}
+	ans.FloatDocValues = docvalues.NewFloatDocValues(ans, f)
+	return ans, nil
+}
+
+type multiFloatValues struct {
+	*docvalues.FloatDocValues
+	f       *MultiFloatFunction
+	valsArr []function.FunctionValues
+}
+
+func (v *multiFloatValues) FloatVal(doc int) float32 {
+	return v.f.fn(doc, v.valsArr)
+}
+
+func (v *multiFloatValues) ToString(doc int) string {
+	var buf bytes.Buffer
+	buf.WriteString(v.f.name)
+	buf.WriteRune('(')
+	for i, vals := range v.valsArr {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		buf.WriteString(vals.ToString(doc))
+	}
+	buf.WriteRune(')')
+	return buf.String()
+}
+
+// queries/function/valuesource/SumFloatFunction.java
+
+// Returns the sum of its components.
+func NewSumFloatFunction(sources ...function.ValueSource) *MultiFloatFunction {
+	return NewMultiFloatFunction("sum", func(doc int, valsArr []function.FunctionValues) float32 {
+		var val float32
+		for _, vals := range valsArr {
+			val += vals.FloatVal(doc)
+		}
+		return val
+	}, sources...)
+}
+
+// queries/function/valuesource/ProductFloatFunction.java
+
+// Returns the product of its components.
+func NewProductFloatFunction(sources ...function.ValueSource) *MultiFloatFunction {
+	return NewMultiFloatFunction("product", func(doc int, valsArr []function.FunctionValues) float32 {
+		val := float32(1)
+		for _, vals := range valsArr {
+			val *= vals.FloatVal(doc)
+		}
+		return val
+	}, sources...)
+}
+
+// queries/function/valuesource/MaxFloatFunction.java
+
+// Returns the max of its components, or 0 if there is none.
+func NewMaxFloatFunction(sources ...function.ValueSource) *MultiFloatFunction {
+	return NewMultiFloatFunction("max", func(doc int, valsArr []function.FunctionValues) float32 {
+		if len(valsArr) == 0 {
+			return 0
+		}
+		val := float32(math.Inf(-1))
+		for _, vals := range valsArr {
+			if v := vals.FloatVal(doc); v > val {
+				val = v
+			}
+		}
+		return val
+	}, sources...)
+}
+
+// queries/function/valuesource/MinFloatFunction.java
+
+// Returns the min of its components, or 0 if there is none.
+func NewMinFloatFunction(sources ...function.ValueSource) *MultiFloatFunction {
+	return NewMultiFloatFunction("min", func(doc int, valsArr []function.FunctionValues) float32 {
+		if len(valsArr) == 0 {
+			return 0
+		}
+		val := float32(math.Inf(1))
+		for _, vals := range valsArr {
+			if v := vals.FloatVal(doc); v < val {
+				val = v
+			}
+		}
+		return val
+	}, sources...)
+}
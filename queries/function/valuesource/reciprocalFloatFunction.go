@@ -0,0 +1,65 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/queries/function/docvalues"
+	"github.com/balzaczyy/golucene/search"
+)
+
+// queries/function/valuesource/ReciprocalFloatFunction.java
+
+/*
+ReciprocalFloatFunction implements a reciprocal function
+f(x) = a/(mx+b), based on the float value of a field or function as
+exported by ValueSource.
+
+When a and b are equal, and x>=0, this function has a maximum value
+of 1 that drops as x increases. Increasing the value of a and b
+together results in a movement of the entire function to a flatter
+part of the curve. These properties make this an ideal function for
+boosting more recent documents.
+*/
+type ReciprocalFloatFunction struct {
+	source  function.ValueSource
+	m, a, b float32
+}
+
+// f(source) = a/(m*float(source)+b)
+func NewReciprocalFloatFunction(source function.ValueSource, m, a, b float32) *ReciprocalFloatFunction {
+	return &ReciprocalFloatFunction{source, m, a, b}
+}
+
+func (f *ReciprocalFloatFunction) Description() string {
+	return fmt.Sprintf("%v/(%v*float(%v)+%v)", f.a, f.m, f.source.Description(), f.b)
+}
+
+func (f *ReciprocalFloatFunction) CreateWeight(context function.Context, searcher *search.IndexSearcher) error {
+	return f.source.CreateWeight(context, searcher)
+}
+
+func (f *ReciprocalFloatFunction) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	vals, err := f.source.Values(context, readerContext)
+	if err != nil {
+		return nil, err
+	}
+	ans := &reciprocalFloatValues{f: f, vals: vals}
+	ans.FloatDocValues = docvalues.NewFloatDocValues(ans, f)
+	return ans, nil
+}
+
+type reciprocalFloatValues struct {
+	*docvalues.FloatDocValues
+	f    *ReciprocalFloatFunction
+	vals function.FunctionValues
+}
+
+func (v *reciprocalFloatValues) FloatVal(doc int) float32 {
+	return v.f.a / (v.f.m*v.vals.FloatVal(doc) + v.f.b)
+}
+
+func (v *reciprocalFloatValues) ToString(doc int) string {
+	return fmt.Sprintf("%v/(%v*float(%v)+%v)", v.f.a, v.f.m, v.vals.ToString(doc), v.f.b)
+}
@@ -0,0 +1,129 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/queries/function/docvalues"
+	"github.com/balzaczyy/golucene/search"
+	"math"
+)
+
+// queries/function/valuesource/ScaleFloatFunction.java
+
+/*
+Scales values to be between min and max.
+
+This implementation currently traverses all of the source values to
+obtain their min and max.
+
+This implementation currently cannot distinguish when documents have
+been deleted or documents that have no value, and 0.0 values will be
+used for these cases. This means that if values are normally all
+greater than 0.0, one can still end up with 0.0 as the min value to
+map from.
+*/
+type ScaleFloatFunction struct {
+	source   function.ValueSource
+	min, max float32
+}
+
+func NewScaleFloatFunction(source function.ValueSource, min, max float32) *ScaleFloatFunction {
+	return &ScaleFloatFunction{source, min, max}
+}
+
+func (f *ScaleFloatFunction) Description() string {
+	return fmt.Sprintf("scale(%v,%v,%v)", f.source.Description(), f.min, f.max)
+}
+
+// queries/function/valuesource/ScaleFloatFunction.java/ScaleInfo
+
+// The min and max of the source values, shared by the segments of a
+// search through the context.
+type scaleInfo struct {
+	minVal, maxVal float32
+}
+
+func (f *ScaleFloatFunction) createScaleInfo(context function.Context,
+	readerContext index.AtomicReaderContext) (*scaleInfo, error) {
+	leaves := index.TopLevelContext(&readerContext).Leaves()
+
+	minVal := float32(math.Inf(1))
+	maxVal := float32(math.Inf(-1))
+
+	for _, leaf := range leaves {
+		maxDoc := leaf.Reader().MaxDoc()
+		vals, err := f.source.Values(context, leaf)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < maxDoc; i++ {
+			val := vals.FloatVal(i)
+			if math.Float32bits(val)&(0xff<<23) == 0xff<<23 {
+				// if the exponent in the float is all ones, then this is
+				// +Inf, -Inf or NaN which don't make sense to factor into
+				// the scale function
+				continue
+			}
+			if val < minVal {
+				minVal = val
+			}
+			if val > maxVal {
+				maxVal = val
+			}
+		}
+	}
+
+	if math.IsInf(float64(minVal), 1) {
+		// must have been an empty index
+		minVal, maxVal = 0, 0
+	}
+
+	info := &scaleInfo{minVal, maxVal}
+	context[f] = info
+	return info, nil
+}
+
+func (f *ScaleFloatFunction) Values(context function.Context,
+	readerContext index.AtomicReaderContext) (function.FunctionValues, error) {
+	info, _ := context[f].(*scaleInfo)
+	if info == nil {
+		var err error
+		if info, err = f.createScaleInfo(context, readerContext); err != nil {
+			return nil, err
+		}
+	}
+
+	var scale float32
+	if info.maxVal-info.minVal != 0 {
+		scale = (f.max - f.min) / (info.maxVal - info.minVal)
+	}
+	vals, err := f.source.Values(context, readerContext)
+	if err != nil {
+		return nil, err
+	}
+	ans := &scaleFloatValues{f: f, info: info, scale: scale, vals: vals}
+	ans.FloatDocValues = docvalues.NewFloatDocValues(ans, f)
+	return ans, nil
+}
+
+func (f *ScaleFloatFunction) CreateWeight(context function.Context, searcher *search.IndexSearcher) error {
+	return f.source.CreateWeight(context, searcher)
+}
+
+type scaleFloatValues struct {
+	*docvalues.FloatDocValues
+	f     *ScaleFloatFunction
+	info  *scaleInfo
+	scale float32
+	vals  function.FunctionValues
+}
+
+func (v *scaleFloatValues) FloatVal(doc int) float32 {
+	return (v.vals.FloatVal(doc)-v.info.minVal)*v.scale + v.f.min
+}
+
+func (v *scaleFloatValues) ToString(doc int) string {
+	return fmt.Sprintf("scale(%v,toMin=%v,toMax=%v,fromMin=%v,fromMax=%v)",
+		v.vals.ToString(doc), v.f.min, v.f.max, v.info.minVal, v.info.maxVal)
+}
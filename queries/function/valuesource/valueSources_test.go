@@ -0,0 +1,258 @@
+package valuesource
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis/core"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/queries/function"
+	"github.com/balzaczyy/golucene/search"
+	"github.com/balzaczyy/golucene/store"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+/*
+Indexes 5 documents with indexed int and long fields "int" and "long",
+and float and double doc values fields "float" and "double", except
+for the last one, committing a segment
+every 2 documents, and opens a searcher over them.
+*/
+func newTestSearcher(t *testing.T) (*search.IndexSearcher, func()) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := index.NewIndexWriter(d, index.NewIndexWriterConfig().
+		SetOpenMode(index.OPEN_MODE_CREATE).SetAnalyzer(core.NewWhitespaceAnalyzer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ints := []int{3, 1, 4, 2}
+	floats := []float32{0.5, 2, 1, 1.5}
+	for i := 0; i < 5; i++ {
+		doc := []document.IndexableField{
+			document.NewTextField("body", "a", document.STORE_NO),
+		}
+		if i < len(ints) {
+			doc = append(doc, document.NewIntField("int", ints[i], document.STORE_NO),
+				document.NewLongField("long", int64(ints[i]), document.STORE_NO),
+				document.NewFloatDocValuesField("float", floats[i]),
+				document.NewDoubleDocValuesField("double", float64(floats[i])))
+		}
+		if err = w.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+		if (i+1)%2 == 0 {
+			if err = w.Commit(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Context().Leaves()) < 2 {
+		t.Fatalf("Expected several segments, but %v", len(r.Context().Leaves()))
+	}
+	return search.NewIndexSearcher(r), func() {
+		r.Close()
+		os.RemoveAll(path)
+	}
+}
+
+// Returns the values of source for all the documents of the searcher.
+func floatVals(t *testing.T, ss *search.IndexSearcher, source function.ValueSource) []float32 {
+	context := function.NewContext(ss)
+	if err := source.CreateWeight(context, ss); err != nil {
+		t.Fatal(err)
+	}
+	var ans []float32
+	for _, leaf := range ss.TopReaderContext().Leaves() {
+		vals, err := source.Values(context, leaf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for doc := 0; doc < leaf.Reader().MaxDoc(); doc++ {
+			ans = append(ans, vals.FloatVal(doc))
+		}
+	}
+	return ans
+}
+
+func assertFloatVals(t *testing.T, ss *search.IndexSearcher, source function.ValueSource, expected ...float32) {
+	actual := floatVals(t, ss, source)
+	if len(actual) != len(expected) {
+		t.Errorf("Expected %v values of %v, but %v", expected, source.Description(), actual)
+		return
+	}
+	for i, v := range expected {
+		if math.Abs(float64(actual[i]-v)) > 1e-6 {
+			t.Errorf("Expected %v values of %v, but %v", expected, source.Description(), actual)
+			return
+		}
+	}
+}
+
+// Asserts the order of the hits, and their scores if given.
+func assertHits(t *testing.T, docs search.TopDocs, expected []int, scores ...float32) {
+	if len(docs.ScoreDocs) != len(expected) {
+		t.Errorf("Expected hits %v, but %v", expected, docs.ScoreDocs)
+		return
+	}
+	for i, doc := range expected {
+		if docs.ScoreDocs[i].Doc != doc || i < len(scores) && docs.ScoreDocs[i].Score != scores[i] {
+			t.Errorf("Expected hits %v scored %v, but %v", expected, scores, docs.ScoreDocs)
+			return
+		}
+	}
+}
+
+func TestFunctionQuery(t *testing.T) {
+	ss, cleanup := newTestSearcher(t)
+	defer cleanup()
+
+	q := function.NewFunctionQuery(NewIntFieldSource("int"))
+	docs, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, docs, []int{2, 0, 3, 1, 4}, 4, 3, 2, 1, 0)
+	if s := q.String(); s != "int(int)" {
+		t.Errorf("Expected int(int), but %v", s)
+	}
+
+	explanation, err := ss.Explain(q, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if explanation.Value() != 4 || !explanation.IsMatch() ||
+		explanation.Description() != "FunctionQuery(int(int)), product of:" ||
+		explanation.Details()[0].Description() != "int(int)=4" {
+		t.Errorf("Unexpected explanation %v", explanation)
+	}
+
+	// the values are read from the doc values of the field, too
+	q = function.NewFunctionQuery(NewFloatFieldSource("float"))
+	q.SetBoost(2)
+	if s := q.String(); s != "(float(float))^2" {
+		t.Errorf("Expected (float(float))^2, but %v", s)
+	}
+	if docs, err = ss.SearchTop(q, 3); err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, docs, []int{1, 3, 2}, 2, 1.5, 1)
+
+	// NaN and -Inf scores are mapped to -math.MaxFloat32
+	q = function.NewFunctionQuery(NewDivFloatFunction(NewConstValueSource(-1), NewConstValueSource(0)))
+	if docs, err = ss.SearchTop(q, 1); err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, docs, []int{0}, -math.MaxFloat32)
+}
+
+func TestFunctionSort(t *testing.T) {
+	ss, cleanup := newTestSearcher(t)
+	defer cleanup()
+
+	source := NewSumFloatFunction(NewIntFieldSource("int"), NewFloatFieldSource("float"))
+	for _, reverse := range []bool{false, true} {
+		sortField, err := function.NewSortField(ss, source, reverse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs, err := ss.SearchSorted(search.NewMatchAllDocsQuery(), nil, 10, search.NewSort(sortField))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []int{4, 1, 0, 3, 2}
+		if reverse {
+			expected = []int{2, 0, 3, 1, 4}
+		}
+		assertHits(t, docs.TopDocs, expected)
+		if v := docs.FieldDocs[0].Fields[0]; !reverse && v != float64(0) || reverse && v != float64(5) {
+			t.Errorf("Unexpected sort value %v", v)
+		}
+	}
+}
+
+func TestValueSources(t *testing.T) {
+	ss, cleanup := newTestSearcher(t)
+	defer cleanup()
+
+	ints := NewIntFieldSource("int")
+	floats := NewFloatFieldSource("float")
+	assertFloatVals(t, ss, NewConstValueSource(1.5), 1.5, 1.5, 1.5, 1.5, 1.5)
+	assertFloatVals(t, ss, ints, 3, 1, 4, 2, 0)
+	assertFloatVals(t, ss, NewLongFieldSource("long"), 3, 1, 4, 2, 0)
+	assertFloatVals(t, ss, NewDoubleFieldSource("double"), 0.5, 2, 1, 1.5, 0)
+	assertFloatVals(t, ss, floats, 0.5, 2, 1, 1.5, 0)
+
+	assertFloatVals(t, ss, NewSumFloatFunction(ints, floats), 3.5, 3, 5, 3.5, 0)
+	assertFloatVals(t, ss, NewProductFloatFunction(ints, floats), 1.5, 2, 4, 3, 0)
+	assertFloatVals(t, ss, NewMaxFloatFunction(ints, floats), 3, 2, 4, 2, 0)
+	assertFloatVals(t, ss, NewMinFloatFunction(ints, floats), 0.5, 1, 1, 1.5, 0)
+	assertFloatVals(t, ss, NewMaxFloatFunction(), 0, 0, 0, 0, 0)
+	assertFloatVals(t, ss, NewDivFloatFunction(floats, ints), 0.5/3, 2, 0.25, 0.75, float32(math.NaN()))
+	assertFloatVals(t, ss, NewPowFloatFunction(ints, NewConstValueSource(2)), 9, 1, 16, 4, 0)
+	assertFloatVals(t, ss, NewLinearFloatFunction(ints, 2, 1), 7, 3, 9, 5, 1)
+	assertFloatVals(t, ss, NewReciprocalFloatFunction(ints, 1, 4, 4), 4.0/7, 0.8, 0.5, 4.0/6, 1)
+
+	// the documents whose int is 1 take the false branch
+	assertFloatVals(t, ss, NewIfFunction(NewLinearFloatFunction(ints, 1, -1), NewConstValueSource(10), floats),
+		10, 2, 10, 10, 10)
+
+	// the range is that of all the segments, including the missing 0
+	assertFloatVals(t, ss, NewScaleFloatFunction(ints, 0, 1), 0.75, 0.25, 1, 0.5, 0)
+	assertFloatVals(t, ss, NewScaleFloatFunction(NewConstValueSource(1), 0, 1), 0, 0, 0, 0, 0)
+}
+
+func TestFunctionValues(t *testing.T) {
+	ss, cleanup := newTestSearcher(t)
+	defer cleanup()
+
+	ints := NewIntFieldSource("int")
+	leaves := ss.TopReaderContext().Leaves()
+	context := function.NewContext(ss)
+	first, err := ints.Values(context, leaves[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Exists(0) || first.ObjectVal(0) != int32(3) || first.StrVal(0) != "3" ||
+		first.DoubleVal(0) != 3 || !first.BoolVal(0) || first.ToString(0) != "int(int)=3" {
+		t.Errorf("Unexpected values of doc 0: %v", first.ToString(0))
+	}
+	last, err := ints.Values(context, leaves[len(leaves)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Exists(0) || last.ObjectVal(0) != nil || last.BoolVal(0) {
+		t.Errorf("Expected no value for doc 4, but %v", last.ToString(0))
+	}
+
+	source := NewScaleFloatFunction(NewSumFloatFunction(ints, NewConstValueSource(1)), 0, 10)
+	if d := source.Description(); d != "scale(sum(int(int),const(1)),0,10)" {
+		t.Errorf("Unexpected description %v", d)
+	}
+	vals, err := source.Values(context, leaves[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := vals.ToString(1); !strings.HasPrefix(s, "scale(sum(int(int)=1,const(1)),") {
+		t.Errorf("Unexpected value string %v", s)
+	}
+	if s := fmt.Sprint(vals.FloatVal(1)); s != "2.5" {
+		t.Errorf("Expected 2.5, but %v", s)
+	}
+}
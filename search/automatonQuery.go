@@ -0,0 +1,89 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util/automaton"
+)
+
+// search/AutomatonQuery.java
+
+/*
+A Query that will match terms against a finite-state machine.
+
+This query will match documents that contain terms accepted by a
+given finite-state machine. The automaton can be constructed with the
+automaton API directly, or more conveniently, with the utility
+queries built on top of it: PrefixQuery, WildcardQuery and
+RegexpQuery.
+
+When the query is executed, it will create an equivalent
+CompiledAutomaton, and walk the terms of the segments by the
+cheapest enumeration of its type: seeking to the single term, or to
+the prefix, or intersecting the term dictionary with the automaton
+otherwise.
+*/
+type AutomatonQuery struct {
+	*MultiTermQuery
+	// the automaton to match index terms against
+	automaton *automaton.Automaton
+	compiled  *automaton.CompiledAutomaton
+	// term containing the field, and possibly some pattern structure
+	term index.Term
+}
+
+/*
+Create a new AutomatonQuery from an Automaton. The term contains the
+field to match; its text is the pattern the automaton was built from,
+if any. The automaton must be deterministic, as built by the automaton
+package.
+*/
+func NewAutomatonQuery(term index.Term, a *automaton.Automaton) *AutomatonQuery {
+	ans := &AutomatonQuery{}
+	ans.init(ans, term, a)
+	return ans
+}
+
+// Creates the AutomatonQuery embedded by the queries built on top of
+// it, which are self.
+func newAutomatonQuery(self multiTermQuery, term index.Term, a *automaton.Automaton) *AutomatonQuery {
+	ans := &AutomatonQuery{}
+	ans.init(self, term, a)
+	return ans
+}
+
+func (q *AutomatonQuery) init(self multiTermQuery, term index.Term, a *automaton.Automaton) {
+	q.MultiTermQuery = NewMultiTermQuery(self, term.Field)
+	q.automaton = a
+	q.compiled = automaton.NewCompiledAutomaton(a)
+	q.term = term
+}
+
+func (q *AutomatonQuery) TermsEnum(terms index.Terms) (index.TermsEnum, error) {
+	switch q.compiled.Type {
+	case automaton.AUTOMATON_TYPE_NONE:
+		return index.EMPTY_TERMS_ENUM, nil
+	case automaton.AUTOMATON_TYPE_ALL:
+		return terms.Iterator(nil), nil
+	case automaton.AUTOMATON_TYPE_SINGLE:
+		return index.NewSingleTermsEnum(terms.Iterator(nil), q.compiled.Term), nil
+	case automaton.AUTOMATON_TYPE_PREFIX:
+		// TODO: this is very likely faster than .intersect, but we
+		// should test and maybe cutover
+		return NewPrefixTermsEnum(terms.Iterator(nil), q.compiled.Term), nil
+	default:
+		return terms.Intersect(q.compiled, nil)
+	}
+}
+
+// Returns the automaton the terms are matched against.
+func (q *AutomatonQuery) Automaton() *automaton.Automaton {
+	return q.automaton
+}
+
+func (q *AutomatonQuery) ToString(field string) string {
+	s := "AutomatonQuery {\n" + q.automaton.String() + "}" + boostString(q.boost)
+	if q.term.Field != field {
+		s = q.term.Field + ":" + s
+	}
+	return s
+}
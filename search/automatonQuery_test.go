@@ -0,0 +1,108 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util/automaton"
+	"testing"
+)
+
+var automatonQueryBodies = []string{
+	"apple banana", "apricot", "application apt", "banana band", "cherry", "bandana",
+}
+
+func newTestRegexpQuery(t *testing.T, text string) *RegexpQuery {
+	q, err := NewRegexpQuery(index.NewTerm("body", text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func TestAutomatonQueries(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, automatonQueryBodies...)
+	defer cleanup()
+
+	for _, v := range []struct {
+		q        Query
+		expected string
+	}{
+		{NewPrefixQuery(index.NewTerm("body", "ap")), "[0 1 2]"},
+		{NewPrefixQuery(index.NewTerm("body", "appl")), "[0 2]"},
+		{NewPrefixQuery(index.NewTerm("body", "band")), "[3 5]"},
+		{NewPrefixQuery(index.NewTerm("body", "")), "[0 1 2 3 4 5]"},
+		{NewPrefixQuery(index.NewTerm("body", "zebra")), "[]"},
+		{NewPrefixQuery(index.NewTerm("nosuchfield", "a")), "[]"},
+		{NewWildcardQuery(index.NewTerm("body", "ap*")), "[0 1 2]"},
+		{NewWildcardQuery(index.NewTerm("body", "ba?d")), "[3]"},
+		{NewWildcardQuery(index.NewTerm("body", "*an*")), "[0 3 5]"},
+		{NewWildcardQuery(index.NewTerm("body", "*y")), "[4]"},
+		{NewWildcardQuery(index.NewTerm("body", "cherry")), "[4]"},
+		{NewWildcardQuery(index.NewTerm("body", "cherr\\?")), "[]"},
+		{newTestRegexpQuery(t, "ap.*t"), "[1 2]"},
+		{newTestRegexpQuery(t, "ban(ana|d)"), "[0 3]"},
+		{newTestRegexpQuery(t, "[a-c]p+.*"), "[0 1 2]"},
+		{newTestRegexpQuery(t, "b.*&~(banana)"), "[3 5]"},
+		{newTestRegexpQuery(t, "#"), "[]"},
+		{NewAutomatonQuery(index.NewTerm("body", ""), automaton.Union(
+			automaton.MakeString("apt"), automaton.MakeString("cherry"))), "[2 4]"},
+	} {
+		assertEquals(t, v.expected, matchingDocs(t, ss, v.q))
+	}
+}
+
+func TestAutomatonQueryRewrite(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, automatonQueryBodies...)
+	defer cleanup()
+
+	q := NewPrefixQuery(index.NewTerm("body", "ap"))
+	q.SetRewriteMethod(SCORING_BOOLEAN_QUERY_REWRITE)
+	q.SetBoost(2)
+	rewritten, err := q.Rewrite(ss.IndexReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sorted terms, boosted by the query
+	assertEquals(t, "body:apple^2 body:application^2 body:apricot^2 body:apt^2", rewritten.ToString(""))
+
+	defer SetMaxClauseCount(MaxClauseCount())
+	SetMaxClauseCount(3)
+	_, err = q.Rewrite(ss.IndexReader())
+	assertEquals(t, ErrTooManyClauses, err)
+}
+
+func TestMultiTermQueryWrapperFilter(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, automatonQueryBodies...)
+	defer cleanup()
+
+	defer SetMaxClauseCount(MaxClauseCount())
+	SetMaxClauseCount(1)
+	q := newBodyTermQuery("banana")
+	f := NewMultiTermQueryWrapperFilter(NewPrefixQuery(index.NewTerm("body", "ap")).MultiTermQuery)
+	docs, err := ss.Search(q, f, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, docs, 0)
+
+	f = NewMultiTermQueryWrapperFilter(NewWildcardQuery(index.NewTerm("body", "z*")).MultiTermQuery)
+	if docs, err = ss.Search(q, f, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, docs)
+	assertEquals(t, "body:z*", f.String())
+}
+
+func TestAutomatonQueryToString(t *testing.T) {
+	q := NewPrefixQuery(index.NewTerm("body", "ap"))
+	assertEquals(t, "ap*", q.ToString("body"))
+	q.SetBoost(2)
+	assertEquals(t, "body:ap*^2", q.ToString("other"))
+
+	assertEquals(t, "body:a?c*", NewWildcardQuery(index.NewTerm("body", "a?c*")).ToString(""))
+	assertEquals(t, "/ab+/", newTestRegexpQuery(t, "ab+").ToString("body"))
+
+	_, err := NewRegexpQuery(index.NewTerm("body", "(ab"))
+	if err == nil {
+		t.Error("Expected error of invalid regular expression")
+	}
+}
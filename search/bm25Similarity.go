@@ -0,0 +1,309 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+)
+
+// search/similarities/BM25Similarity.java
+
+/*
+BM25 Similarity. Introduced in Stephen E. Robertson, Steve Walker,
+Susan Jones, Micheline Hancock-Beaulieu, and Mike Gatford. Okapi at
+TREC-3. In Proceedings of the Third Text REtrieval Conference (TREC
+1994). Gaithersburg, USA, November 1994.
+
+The score of a document is the sum over the query terms of
+
+	idf * boost * freq * (k1 + 1) / (freq + k1 * (1 - b + b * dl / avgdl))
+
+where dl is the length of the field, as decoded from its norm, and
+avgdl the average length of the field in the collection. Coordination
+and query normalization are disabled.
+
+It is selected with IndexSearcher.SetSimilarity(). Its norms are the
+ones of DefaultSimilarity, so an index built with the default
+IndexWriterConfig can be searched with it.
+*/
+type BM25Similarity struct {
+	k1 float32
+	b  float32
+	// if true, tokens with a position increment of zero are not
+	// counted in the field length
+	discountOverlaps bool
+}
+
+// BM25 with the default parameters: k1 = 1.2, b = 0.75.
+func NewBM25Similarity() *BM25Similarity {
+	return NewBM25SimilarityWithParams(1.2, 0.75)
+}
+
+/*
+BM25 with the supplied parameter values. k1 controls non-linear term
+frequency normalization (saturation), and b controls to what degree
+document length normalizes tf values.
+*/
+func NewBM25SimilarityWithParams(k1, b float32) *BM25Similarity {
+	return &BM25Similarity{k1, b, true}
+}
+
+// Returns the k1 parameter.
+func (s *BM25Similarity) K1() float32 {
+	return s.k1
+}
+
+// Returns the b parameter.
+func (s *BM25Similarity) B() float32 {
+	return s.b
+}
+
+/*
+Sets whether overlap tokens (Tokens with 0 position increment) are
+ignored when computing norm. By default this is true, meaning overlap
+tokens do not count when computing norms.
+*/
+func (s *BM25Similarity) SetDiscountOverlaps(v bool) {
+	s.discountOverlaps = v
+}
+
+// Returns true if overlap tokens are discounted from the document's
+// length.
+func (s *BM25Similarity) DiscountOverlaps() bool {
+	return s.discountOverlaps
+}
+
+// Implemented as log(1 + (numDocs - docFreq + 0.5)/(docFreq + 0.5)).
+func (s *BM25Similarity) idf(docFreq, numDocs int64) float32 {
+	return float32(math.Log(1 + (float64(numDocs-docFreq)+0.5)/(float64(docFreq)+0.5)))
+}
+
+// Implemented as 1 / (distance + 1).
+func (s *BM25Similarity) sloppyFreq(distance int) float32 {
+	return 1.0 / float32(distance+1)
+}
+
+// The default implementation computes the average as
+// sumTotalTermFreq / maxDoc, or returns 1 if the index does not store
+// sumTotalTermFreq.
+func (s *BM25Similarity) avgFieldLength(collectionStats CollectionStatistics) float32 {
+	sumTotalTermFreq := collectionStats.SumTotalTermFreq()
+	if sumTotalTermFreq <= 0 {
+		return 1 // field does not exist, or stat is unsupported
+	}
+	return float32(float64(sumTotalTermFreq) / float64(collectionStats.MaxDoc()))
+}
+
+// The default implementation encodes boost / sqrt(length) with
+// util.FloatToByte315().
+func (s *BM25Similarity) encodeNormValue(boost float32, fieldLength int) byte {
+	return util.FloatToByte315(boost / float32(math.Sqrt(float64(fieldLength))))
+}
+
+// Cache of decoded bytes, as the squared inverses of the encoded
+// 1/sqrt(length), i.e. the lengths. Shared with SimilarityBase.
+var lengthNormTable = func() (table [256]float32) {
+	for i := range table {
+		f := util.Byte315ToFloat(byte(i))
+		table[i] = 1.0 / (f * f)
+	}
+	return
+}()
+
+// The default implementation returns 1 / f^2, where f is
+// util.Byte315ToFloat(b).
+func (s *BM25Similarity) decodeNormValue(b byte) float32 {
+	return lengthNormTable[b]
+}
+
+func (s *BM25Similarity) ComputeNorm(state *index.FieldInvertState) int64 {
+	numTerms := state.Length()
+	if s.discountOverlaps {
+		numTerms -= state.NumOverlap()
+	}
+	return int64(int8(s.encodeNormValue(state.Boost(), numTerms)))
+}
+
+// Coordination is disabled, i.e. returns 1.
+func (s *BM25Similarity) Coord(overlap, maxOverlap int) float32 {
+	return 1
+}
+
+// Query normalization is disabled, i.e. returns 1.
+func (s *BM25Similarity) QueryNorm(valueForNormalization float32) float32 {
+	return 1
+}
+
+/*
+Computes a score factor for a simple term, or for a phrase, which is
+the sum of the idfs of its terms, and returns an explanation for it.
+*/
+func (s *BM25Similarity) idfExplain(collectionStats CollectionStatistics, termStats ...TermStatistics) *Explanation {
+	max := collectionStats.MaxDoc()
+	if len(termStats) == 1 {
+		df := termStats[0].DocFreq
+		return NewExplanation(s.idf(df, max), fmt.Sprintf("idf(docFreq=%v, maxDocs=%v)", df, max))
+	}
+	exp := NewExplanation(0, "idf(), sum of:")
+	var idf float32
+	for _, stat := range termStats {
+		df := stat.DocFreq
+		termIdf := s.idf(df, max)
+		exp.AddDetail(NewExplanation(termIdf, fmt.Sprintf("idf(docFreq=%v, maxDocs=%v)", df, max)))
+		idf += termIdf
+	}
+	exp.SetValue(idf)
+	return exp
+}
+
+func (s *BM25Similarity) ComputeWeight(queryBoost float32,
+	collectionStats CollectionStatistics, termStats ...TermStatistics) SimWeight {
+	idf := s.idfExplain(collectionStats, termStats...)
+	avgdl := s.avgFieldLength(collectionStats)
+
+	// compute freq-independent part of bm25 equation across all norm values
+	var cache [256]float32
+	for i := range cache {
+		cache[i] = s.k1 * ((1 - s.b) + s.b*s.decodeNormValue(byte(i))/avgdl)
+	}
+	return &bm25Stats{field: collectionStats.Field(), idf: idf,
+		queryBoost: queryBoost, avgdl: avgdl, cache: cache}
+}
+
+func (s *BM25Similarity) ExactSimScorer(w SimWeight, ctx index.AtomicReaderContext) (ExactSimScorer, error) {
+	stats := w.(*bm25Stats)
+	norms, err := ctx.Reader().(index.AtomicReader).NormValues(stats.field)
+	if err != nil {
+		return nil, err
+	}
+	return &exactBM25DocScorer{s, stats, stats.weight * (s.k1 + 1), norms}, nil
+}
+
+func (s *BM25Similarity) SloppySimScorer(w SimWeight, ctx index.AtomicReaderContext) (SloppySimScorer, error) {
+	stats := w.(*bm25Stats)
+	norms, err := ctx.Reader().(index.AtomicReader).NormValues(stats.field)
+	if err != nil {
+		return nil, err
+	}
+	return &sloppyBM25DocScorer{s, stats, stats.weight * (s.k1 + 1), norms}, nil
+}
+
+func (s *BM25Similarity) String() string {
+	return fmt.Sprintf("BM25(k1=%v,b=%v)", s.k1, s.b)
+}
+
+// Explains the score of the document, whose term frequency is freq.
+func (s *BM25Similarity) explainScore(doc int, freq *Explanation,
+	stats *bm25Stats, norms index.NumericDocValues) *Explanation {
+	result := NewExplanation(0, fmt.Sprintf("score(doc=%v,freq=%v), product of:", doc, freq))
+
+	boostExpl := NewExplanation(stats.queryBoost*stats.topLevelBoost, "boost")
+	if boostExpl.Value() != 1 {
+		result.AddDetail(boostExpl)
+	}
+	result.AddDetail(stats.idf)
+
+	f := freq.Value()
+	tfNormExpl := NewExplanation(0, "tfNorm, computed from:")
+	tfNormExpl.AddDetail(freq)
+	tfNormExpl.AddDetail(NewExplanation(s.k1, "parameter k1"))
+	if norms == nil {
+		tfNormExpl.AddDetail(NewExplanation(0, "parameter b (norms omitted for field)"))
+		tfNormExpl.SetValue((f * (s.k1 + 1)) / (f + s.k1))
+	} else {
+		doclen := s.decodeNormValue(byte(norms.Get(doc)))
+		tfNormExpl.AddDetail(NewExplanation(s.b, "parameter b"))
+		tfNormExpl.AddDetail(NewExplanation(stats.avgdl, "avgFieldLength"))
+		tfNormExpl.AddDetail(NewExplanation(doclen, "fieldLength"))
+		tfNormExpl.SetValue((f * (s.k1 + 1)) / (f + s.k1*(1-s.b+s.b*doclen/stats.avgdl)))
+	}
+	result.AddDetail(tfNormExpl)
+	result.SetValue(boostExpl.Value() * stats.idf.Value() * tfNormExpl.Value())
+	return result
+}
+
+// search/similarities/BM25Similarity.java/ExactBM25DocScorer
+
+type exactBM25DocScorer struct {
+	sim         *BM25Similarity
+	stats       *bm25Stats
+	weightValue float32 // boost * idf * (k1 + 1)
+	norms       index.NumericDocValues
+}
+
+func (s *exactBM25DocScorer) Score(doc, freq int) float32 {
+	// if there are no norms, we act as if b=0
+	norm := s.sim.k1
+	if s.norms != nil {
+		norm = s.stats.cache[byte(s.norms.Get(doc))]
+	}
+	return s.weightValue * float32(freq) / (float32(freq) + norm)
+}
+
+func (s *exactBM25DocScorer) Explain(doc int, freq *Explanation) *Explanation {
+	return s.sim.explainScore(doc, freq, s.stats, s.norms)
+}
+
+// search/similarities/BM25Similarity.java/SloppyBM25DocScorer
+
+type sloppyBM25DocScorer struct {
+	sim         *BM25Similarity
+	stats       *bm25Stats
+	weightValue float32 // boost * idf * (k1 + 1)
+	norms       index.NumericDocValues
+}
+
+func (s *sloppyBM25DocScorer) Score(doc int, freq float32) float32 {
+	// if there are no norms, we act as if b=0
+	norm := s.sim.k1
+	if s.norms != nil {
+		norm = s.stats.cache[byte(s.norms.Get(doc))]
+	}
+	return s.weightValue * freq / (freq + norm)
+}
+
+func (s *sloppyBM25DocScorer) ComputeSlopFactor(distance int) float32 {
+	return s.sim.sloppyFreq(distance)
+}
+
+func (s *sloppyBM25DocScorer) ComputePayloadFactor(doc, start, end int, payload []byte) float32 {
+	return 1
+}
+
+func (s *sloppyBM25DocScorer) Explain(doc int, freq *Explanation) *Explanation {
+	return s.sim.explainScore(doc, freq, s.stats, s.norms)
+}
+
+// search/similarities/BM25Similarity.java/BM25Stats
+
+// Collection statistics for the BM25 model.
+type bm25Stats struct {
+	field string
+	// BM25's idf
+	idf *Explanation
+	// The average document length.
+	avgdl float32
+	// query's inner boost
+	queryBoost float32
+	// query's outer boost (only for explain)
+	topLevelBoost float32
+	// weight (idf * boost)
+	weight float32
+	// precomputed norm[256] with k1 * ((1 - b) + b * dl / avgdl)
+	cache [256]float32
+}
+
+func (s *bm25Stats) ValueForNormalization() float32 {
+	// we return a TF-IDF like normalization to be nice, but we don't
+	// actually normalize ourselves.
+	queryWeight := s.idf.Value() * s.queryBoost
+	return queryWeight * queryWeight
+}
+
+func (s *bm25Stats) Normalize(queryNorm, topLevelBoost float32) {
+	// we don't normalize with queryNorm at all, we just capture the
+	// top-level boost
+	s.topLevelBoost = topLevelBoost
+	s.weight = s.idf.Value() * s.queryBoost * topLevelBoost
+}
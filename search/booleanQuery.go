@@ -0,0 +1,472 @@
+package search
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/BooleanClause.java
+
+// Specifies how clauses are to occur in matching documents.
+type Occur int
+
+const (
+	// Use this operator for clauses that must appear in the matching
+	// documents.
+	OCCUR_MUST = Occur(1)
+	/*
+		Use this operator for clauses that should appear in the matching
+		documents. For a BooleanQuery with no MUST clauses one or more
+		SHOULD clauses must match a document for the BooleanQuery to
+		match.
+	*/
+	OCCUR_SHOULD = Occur(2)
+	/*
+		Use this operator for clauses that must not appear in the
+		matching documents. Note that it is not possible to search
+		for queries that only consist of a MUST_NOT clause.
+	*/
+	OCCUR_MUST_NOT = Occur(3)
+)
+
+func (o Occur) String() string {
+	switch o {
+	case OCCUR_MUST:
+		return "+"
+	case OCCUR_MUST_NOT:
+		return "-"
+	default:
+		return ""
+	}
+}
+
+// A clause in a BooleanQuery.
+type BooleanClause struct {
+	// The query whose matching documents are combined by the boolean
+	// query.
+	query Query
+	occur Occur
+}
+
+// Constructs a BooleanClause.
+func NewBooleanClause(query Query, occur Occur) *BooleanClause {
+	return &BooleanClause{query, occur}
+}
+
+func (c *BooleanClause) Occur() Occur {
+	return c.occur
+}
+
+func (c *BooleanClause) Query() Query {
+	return c.query
+}
+
+func (c *BooleanClause) IsProhibited() bool {
+	return c.occur == OCCUR_MUST_NOT
+}
+
+func (c *BooleanClause) IsRequired() bool {
+	return c.occur == OCCUR_MUST
+}
+
+func (c *BooleanClause) String() string {
+	return c.occur.String() + c.query.ToString("")
+}
+
+// search/BooleanQuery.java
+
+var maxClauseCount = 1024
+
+/*
+Returned by BooleanQuery.Add() if a query would contain more than
+MaxClauseCount() clauses.
+*/
+var ErrTooManyClauses = errors.New("too many boolean clauses")
+
+/*
+Return the maximum number of clauses permitted, 1024 by default.
+Attempts to add more than the permitted number of clauses cause
+ErrTooManyClauses to be returned.
+*/
+func MaxClauseCount() int {
+	return maxClauseCount
+}
+
+// Set the maximum number of clauses permitted per BooleanQuery.
+// Default value is 1024.
+func SetMaxClauseCount(n int) {
+	if n < 1 {
+		panic("maxClauseCount must be >= 1")
+	}
+	maxClauseCount = n
+}
+
+/*
+A Query that matches documents matching boolean combinations of other
+queries, e.g. TermQuerys, PhraseQuerys or other BooleanQuerys.
+*/
+type BooleanQuery struct {
+	*AbstractQuery
+	clauses          []*BooleanClause
+	disableCoord     bool
+	minNrShouldMatch int
+}
+
+// Constructs an empty boolean query.
+func NewBooleanQuery() *BooleanQuery {
+	return NewBooleanQueryDisableCoord(false)
+}
+
+/*
+Constructs an empty boolean query.
+
+Similarity.Coord() may be disabled in scoring, as appropriate. For
+example, this score factor does not make sense for most automatically
+generated queries, like WildcardQuery and FuzzyQuery.
+*/
+func NewBooleanQueryDisableCoord(disableCoord bool) *BooleanQuery {
+	ans := &BooleanQuery{disableCoord: disableCoord}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+// Returns true iff Similarity.Coord() is disabled in scoring for
+// this query instance.
+func (q *BooleanQuery) IsCoordDisabled() bool {
+	return q.disableCoord
+}
+
+/*
+Specifies a minimum number of the optional BooleanClauses which must
+be satisfied.
+
+By default no optional clauses are necessary for a match (unless
+there are no required clauses). If this method is used, then the
+specified number of clauses is required.
+
+Use of this method is totally independent of specifying that any
+specific clauses are required (or prohibited). This number will only
+be compared against the number of matching optional clauses.
+*/
+func (q *BooleanQuery) SetMinimumNumberShouldMatch(min int) {
+	q.minNrShouldMatch = min
+}
+
+// Gets the minimum number of the optional BooleanClauses which must
+// be satisfied.
+func (q *BooleanQuery) MinimumNumberShouldMatch() int {
+	return q.minNrShouldMatch
+}
+
+/*
+Adds a clause to a boolean query. Returns ErrTooManyClauses if the
+new number of clauses exceeds the maximum clause number.
+*/
+func (q *BooleanQuery) Add(query Query, occur Occur) error {
+	return q.AddClause(NewBooleanClause(query, occur))
+}
+
+/*
+Adds a clause to a boolean query. Returns ErrTooManyClauses if the
+new number of clauses exceeds the maximum clause number.
+*/
+func (q *BooleanQuery) AddClause(clause *BooleanClause) error {
+	if len(q.clauses) >= maxClauseCount {
+		return ErrTooManyClauses
+	}
+	q.clauses = append(q.clauses, clause)
+	return nil
+}
+
+// Returns the list of clauses in this query.
+func (q *BooleanQuery) Clauses() []*BooleanClause {
+	return q.clauses
+}
+
+func (q *BooleanQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	return newBooleanWeight(q, ss, q.disableCoord)
+}
+
+func (q *BooleanQuery) Rewrite(r index.IndexReader) (Query, error) {
+	if q.minNrShouldMatch == 0 && len(q.clauses) == 1 { // optimize 1-clause queries
+		if c := q.clauses[0]; !c.IsProhibited() { // just return clause
+			query, err := c.query.Rewrite(r) // rewrite first
+			if err != nil {
+				return nil, err
+			}
+			if q.boost == 1.0 {
+				return query, nil
+			}
+			if query != c.query { // incorporate boost
+				query.SetBoost(q.boost * query.Boost())
+				return query, nil
+			}
+			// the clause is shared with the caller, whose boost can't be
+			// changed, so keep wrapping it
+		}
+	}
+
+	var clone *BooleanQuery // recursively rewrite
+	for i, c := range q.clauses {
+		query, err := c.query.Rewrite(r)
+		if err != nil {
+			return nil, err
+		}
+		if query != c.query { // clause rewrote: must clone
+			if clone == nil {
+				clone = q.clone()
+			}
+			clone.clauses[i] = NewBooleanClause(query, c.occur)
+		}
+	}
+	if clone != nil {
+		return clone, nil // some clauses rewrote
+	}
+	return q, nil // no clauses rewrote
+}
+
+// Returns a copy of this query, with its own list of clauses.
+func (q *BooleanQuery) clone() *BooleanQuery {
+	ans := NewBooleanQueryDisableCoord(q.disableCoord)
+	ans.clauses = append([]*BooleanClause(nil), q.clauses...)
+	ans.minNrShouldMatch = q.minNrShouldMatch
+	ans.boost = q.boost
+	return ans
+}
+
+func (q *BooleanQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	needParens := q.boost != 1.0 || q.minNrShouldMatch > 0
+	if needParens {
+		buf.WriteString("(")
+	}
+
+	for i, c := range q.clauses {
+		buf.WriteString(c.occur.String())
+		if subQuery, ok := c.query.(*BooleanQuery); ok { // wrap sub-bools in parens
+			fmt.Fprintf(&buf, "(%v)", subQuery.ToString(field))
+		} else {
+			buf.WriteString(c.query.ToString(field))
+		}
+		if i != len(q.clauses)-1 {
+			buf.WriteString(" ")
+		}
+	}
+
+	if needParens {
+		buf.WriteString(")")
+	}
+
+	if q.minNrShouldMatch > 0 {
+		fmt.Fprintf(&buf, "~%v", q.minNrShouldMatch)
+	}
+
+	buf.WriteString(boostString(q.boost))
+	return buf.String()
+}
+
+// search/BooleanQuery.java/BooleanWeight
+
+/*
+Expert: the Weight for BooleanQuery, used to normalize, score and
+explain these queries.
+*/
+type BooleanWeight struct {
+	query *BooleanQuery
+	// The Similarity implementation.
+	similarity   Similarity
+	weights      []Weight
+	maxCoord     int // num optional + num required
+	disableCoord bool
+}
+
+func newBooleanWeight(q *BooleanQuery, ss *IndexSearcher, disableCoord bool) (*BooleanWeight, error) {
+	ans := &BooleanWeight{
+		query:        q,
+		similarity:   ss.Similarity(),
+		weights:      make([]Weight, 0, len(q.clauses)),
+		disableCoord: disableCoord,
+	}
+	for _, c := range q.clauses {
+		w, err := c.query.CreateWeight(ss)
+		if err != nil {
+			return nil, err
+		}
+		ans.weights = append(ans.weights, w)
+		if !c.IsProhibited() {
+			ans.maxCoord++
+		}
+	}
+	return ans, nil
+}
+
+func (w *BooleanWeight) Query() Query {
+	return w.query
+}
+
+func (w *BooleanWeight) ValueForNormalization() float32 {
+	var sum float32
+	for i, c := range w.query.clauses {
+		// call sumOfSquaredWeights for all clauses in case of side effects
+		s := w.weights[i].ValueForNormalization() // sum sub weights
+		if !c.IsProhibited() {
+			// only add to sum for non-prohibited clauses
+			sum += s
+		}
+	}
+	return sum * w.query.boost * w.query.boost // boost each sub-weight
+}
+
+// Returns the coord factor of a document matching overlap of the
+// maxOverlap clauses.
+func (w *BooleanWeight) Coord(overlap, maxOverlap int) float32 {
+	// LUCENE-4300: in most cases of maxOverlap=1, BQ rewrites itself
+	// away, so coord() is not applied. But when BQ cannot optimize
+	// itself away for a single clause (minNrShouldMatch,
+	// prohibited clauses, etc), its important not to apply coord(1,1)
+	// for consistency, it might not be 1.0F
+	if maxOverlap == 1 {
+		return 1
+	}
+	return w.similarity.Coord(overlap, maxOverlap)
+}
+
+func (w *BooleanWeight) Normalize(norm float32, topLevelBoost float32) {
+	topLevelBoost *= w.query.boost // incorporate boost
+	for _, subWeight := range w.weights {
+		// normalize all clauses, (even if prohibited in case of side
+		// affects)
+		subWeight.Normalize(norm, topLevelBoost)
+	}
+}
+
+func (w *BooleanWeight) Explain(ctx index.AtomicReaderContext, doc int) (*Explanation, error) {
+	minShouldMatch := w.query.minNrShouldMatch
+	sumExpl := NewExplanation(0, "sum of:")
+	coord := 0
+	var sum float32
+	fail := false
+	shouldMatchCount := 0
+	for i, c := range w.query.clauses {
+		scorer, err := w.weights[i].Scorer(ctx, true, true, ctx.Reader().(index.AtomicReader).LiveDocs())
+		if err != nil {
+			return nil, err
+		}
+		if scorer == nil {
+			if c.IsRequired() {
+				fail = true
+				sumExpl.AddDetail(NewExplanation(0, fmt.Sprintf("no match on required clause (%v)", c.Query())))
+			}
+			continue
+		}
+		e, err := w.weights[i].Explain(ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+		if e.IsMatch() {
+			if !c.IsProhibited() {
+				sumExpl.AddDetail(e)
+				sum += e.Value()
+				coord++
+			} else {
+				r := NewExplanation(0, fmt.Sprintf("match on prohibited clause (%v)", c.Query()))
+				r.AddDetail(e)
+				sumExpl.AddDetail(r)
+				fail = true
+			}
+			if c.Occur() == OCCUR_SHOULD {
+				shouldMatchCount++
+			}
+		} else if c.IsRequired() {
+			r := NewExplanation(0, fmt.Sprintf("no match on required clause (%v)", c.Query()))
+			r.AddDetail(e)
+			sumExpl.AddDetail(r)
+			fail = true
+		}
+	}
+	if fail {
+		sumExpl.SetMatch(false)
+		sumExpl.SetValue(0)
+		sumExpl.SetDescription("Failure to meet condition(s) of required/prohibited clause(s)")
+		return sumExpl, nil
+	} else if shouldMatchCount < minShouldMatch {
+		sumExpl.SetMatch(false)
+		sumExpl.SetValue(0)
+		sumExpl.SetDescription(fmt.Sprintf("Failure to match minimum number of optional clauses: %v", minShouldMatch))
+		return sumExpl, nil
+	}
+
+	sumExpl.SetMatch(coord > 0)
+	sumExpl.SetValue(sum)
+
+	coordFactor := float32(1)
+	if !w.disableCoord {
+		coordFactor = w.Coord(coord, w.maxCoord)
+	}
+	if coordFactor == 1 {
+		return sumExpl, nil // eliminate wrapper
+	}
+	result := NewComplexExplanation(sumExpl.IsMatch(), sum*coordFactor, "product of:")
+	result.AddDetail(sumExpl)
+	result.AddDetail(NewExplanation(coordFactor, fmt.Sprintf("coord(%v/%v)", coord, w.maxCoord)))
+	return result, nil
+}
+
+func (w *BooleanWeight) Scorer(ctx index.AtomicReaderContext,
+	scoreDocsInOrder, topScorer bool, acceptDocs util.Bits) (Scorer, error) {
+	var required, prohibited, optional []Scorer
+	for i, c := range w.query.clauses {
+		subScorer, err := w.weights[i].Scorer(ctx, true, false, acceptDocs)
+		if err != nil {
+			return nil, err
+		}
+		if subScorer == nil {
+			if c.IsRequired() {
+				return nil, nil
+			}
+		} else if c.IsRequired() {
+			required = append(required, subScorer)
+		} else if c.IsProhibited() {
+			prohibited = append(prohibited, subScorer)
+		} else {
+			optional = append(optional, subScorer)
+		}
+	}
+
+	if len(required) == 0 && len(optional) == 0 {
+		// no required and optional clauses.
+		return nil, nil
+	} else if len(optional) < w.query.minNrShouldMatch {
+		// either >1 req scorer, or there are 0 req scorers and at least 1
+		// optional scorer. Therefore if there are not enough optional
+		// scorers no documents will be matched by the query
+		return nil, nil
+	}
+
+	// Check if we can return a BooleanScorer
+	if !scoreDocsInOrder && topScorer && len(required) == 0 {
+		return newBooleanScorer(w, w.disableCoord, w.query.minNrShouldMatch,
+			optional, prohibited, w.maxCoord), nil
+	}
+
+	// Return a BooleanScorer2
+	return newBooleanScorer2(w, w.disableCoord, w.query.minNrShouldMatch,
+		required, prohibited, optional, w.maxCoord), nil
+}
+
+func (w *BooleanWeight) IsScoresDocsOutOfOrder() bool {
+	for _, c := range w.query.clauses {
+		if c.IsRequired() {
+			return false // BS2 (in-order) will be used by Scorer()
+		}
+	}
+	// Scorer() will return an out-of-order scorer if requested.
+	return true
+}
+
+func (w *BooleanWeight) String() string {
+	return fmt.Sprintf("weight(%v)", w.query)
+}
@@ -0,0 +1,291 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"math"
+	"sort"
+	"testing"
+)
+
+func newBodyTermQuery(text string) *TermQuery {
+	return NewTermQuery(index.NewTerm("body", text))
+}
+
+// Returns the ids of the documents matching q, in increasing order.
+func matchingDocs(t *testing.T, ss *IndexSearcher, q Query) string {
+	docs, err := ss.SearchTop(q, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int, len(docs.ScoreDocs))
+	for i, hit := range docs.ScoreDocs {
+		ids[i] = hit.Doc
+	}
+	sort.Ints(ids)
+	return fmt.Sprint(ids)
+}
+
+func newTestBooleanQuery(clauses ...interface{}) *BooleanQuery {
+	q := NewBooleanQuery()
+	for i := 0; i < len(clauses); i += 2 {
+		q.Add(clauses[i].(Query), clauses[i+1].(Occur))
+	}
+	return q
+}
+
+func TestBooleanQuery(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+	defer cleanup()
+
+	a, b, c, d := newBodyTermQuery("a"), newBodyTermQuery("b"), newBodyTermQuery("c"), newBodyTermQuery("d")
+	for _, v := range []struct {
+		q        *BooleanQuery
+		expected string
+	}{
+		{newTestBooleanQuery(a, OCCUR_MUST, b, OCCUR_MUST), "[0 1]"},
+		{newTestBooleanQuery(a, OCCUR_MUST, b, OCCUR_MUST, d, OCCUR_MUST), "[]"},
+		{newTestBooleanQuery(a, OCCUR_SHOULD, c, OCCUR_SHOULD), "[0 1 2 3 4]"},
+		{newTestBooleanQuery(a, OCCUR_MUST, c, OCCUR_MUST_NOT), "[1 3]"},
+		{newTestBooleanQuery(a, OCCUR_SHOULD, c, OCCUR_SHOULD, b, OCCUR_MUST_NOT), "[3 4]"},
+		{newTestBooleanQuery(b, OCCUR_MUST, c, OCCUR_MUST_NOT, a, OCCUR_MUST_NOT), "[]"},
+		{newTestBooleanQuery(d, OCCUR_MUST, a, OCCUR_SHOULD), "[4]"},
+		{newTestBooleanQuery(b, OCCUR_MUST, newBodyTermQuery("nosuchterm"), OCCUR_SHOULD), "[0 1 2]"},
+		{newTestBooleanQuery(newBodyTermQuery("nosuchterm"), OCCUR_MUST, a, OCCUR_SHOULD), "[]"},
+		{newTestBooleanQuery(newTestBooleanQuery(a, OCCUR_SHOULD, d, OCCUR_SHOULD), OCCUR_MUST,
+			b, OCCUR_MUST_NOT), "[3 4]"},
+	} {
+		assertEquals(t, v.expected, matchingDocs(t, ss, v.q))
+	}
+}
+
+func TestBooleanQueryMinimumNumberShouldMatch(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+	defer cleanup()
+
+	a, b, c, d := newBodyTermQuery("a"), newBodyTermQuery("b"), newBodyTermQuery("c"), newBodyTermQuery("d")
+	for _, v := range []struct {
+		q        *BooleanQuery
+		min      int
+		expected string
+	}{
+		{newTestBooleanQuery(a, OCCUR_SHOULD, b, OCCUR_SHOULD, c, OCCUR_SHOULD), 1, "[0 1 2 3 4]"},
+		{newTestBooleanQuery(a, OCCUR_SHOULD, b, OCCUR_SHOULD, c, OCCUR_SHOULD), 2, "[0 1 2]"},
+		{newTestBooleanQuery(a, OCCUR_SHOULD, b, OCCUR_SHOULD, c, OCCUR_SHOULD), 3, "[0]"},
+		{newTestBooleanQuery(a, OCCUR_SHOULD, b, OCCUR_SHOULD, c, OCCUR_SHOULD), 4, "[]"},
+		{newTestBooleanQuery(c, OCCUR_MUST, a, OCCUR_SHOULD, b, OCCUR_SHOULD, d, OCCUR_SHOULD), 1, "[0 2 4]"},
+		{newTestBooleanQuery(c, OCCUR_MUST, a, OCCUR_SHOULD, b, OCCUR_SHOULD, d, OCCUR_SHOULD), 2, "[0]"},
+		{newTestBooleanQuery(c, OCCUR_MUST, a, OCCUR_SHOULD, b, OCCUR_SHOULD), 2, "[0]"},
+		{newTestBooleanQuery(a, OCCUR_SHOULD, b, OCCUR_SHOULD, c, OCCUR_MUST_NOT), 2, "[1]"},
+	} {
+		v.q.SetMinimumNumberShouldMatch(v.min)
+		assertEquals(t, v.expected, matchingDocs(t, ss, v.q))
+	}
+}
+
+func TestBooleanQueryCoord(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b", "a", "b")
+	defer cleanup()
+
+	score := func(disableCoord bool, doc int) float32 {
+		q := NewBooleanQueryDisableCoord(disableCoord)
+		q.Add(newBodyTermQuery("a"), OCCUR_SHOULD)
+		q.Add(newBodyTermQuery("b"), OCCUR_SHOULD)
+		docs, err := ss.SearchTop(q, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, 3, docs.TotalHits)
+		for _, hit := range docs.ScoreDocs {
+			if hit.Doc == doc {
+				return hit.Score
+			}
+		}
+		t.Fatalf("Doc %v not found in %v", doc, docs.ScoreDocs)
+		return 0
+	}
+	// both clauses match the first doc, only one the others
+	assertEquals(t, score(true, 0), score(false, 0))
+	assertEquals(t, score(true, 1)/2, score(false, 1))
+	assertEquals(t, score(true, 2)/2, score(false, 2))
+}
+
+func TestBooleanQueryRewrite(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b")
+	defer cleanup()
+
+	a := newBodyTermQuery("a")
+	q, err := ss.Rewrite(newTestBooleanQuery(a, OCCUR_MUST))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, Query(a), q)
+
+	// prohibited and boosted single clauses can't be rewritten to the
+	// clause's query itself
+	bq := newTestBooleanQuery(a, OCCUR_MUST_NOT)
+	if q, err = ss.Rewrite(bq); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, Query(bq), q)
+	bq = newTestBooleanQuery(a, OCCUR_SHOULD)
+	bq.SetBoost(2)
+	if q, err = ss.Rewrite(bq); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, Query(bq), q)
+	assertEquals(t, float32(1), a.Boost())
+
+	// nested single clauses are rewritten, and get the boost
+	bq = newTestBooleanQuery(newTestBooleanQuery(a, OCCUR_SHOULD), OCCUR_SHOULD, newBodyTermQuery("b"), OCCUR_SHOULD)
+	if q, err = ss.Rewrite(bq); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "body:a body:b", q.ToString(""))
+	assertEquals(t, Query(a), q.(*BooleanQuery).Clauses()[0].Query())
+	assertEquals(t, 1, len(bq.Clauses()[0].Query().(*BooleanQuery).Clauses()))
+}
+
+func TestBooleanQueryToString(t *testing.T) {
+	q := newTestBooleanQuery(newBodyTermQuery("a"), OCCUR_MUST, newBodyTermQuery("b"), OCCUR_MUST_NOT,
+		newTestBooleanQuery(newBodyTermQuery("c"), OCCUR_SHOULD, newBodyTermQuery("d"), OCCUR_SHOULD), OCCUR_SHOULD)
+	assertEquals(t, "+body:a -body:b (body:c body:d)", q.String())
+	assertEquals(t, "+a -b (c d)", q.ToString("body"))
+	q.SetMinimumNumberShouldMatch(1)
+	assertEquals(t, "(+a -b (c d))~1", q.ToString("body"))
+	q.SetBoost(2)
+	assertEquals(t, "(+a -b (c d))~1^2", q.ToString("body"))
+}
+
+func TestBooleanQueryMaxClauseCount(t *testing.T) {
+	defer SetMaxClauseCount(MaxClauseCount())
+	SetMaxClauseCount(2)
+	q := NewBooleanQuery()
+	assertEquals(t, nil, q.Add(newBodyTermQuery("a"), OCCUR_SHOULD))
+	assertEquals(t, nil, q.Add(newBodyTermQuery("b"), OCCUR_SHOULD))
+	assertEquals(t, ErrTooManyClauses, q.Add(newBodyTermQuery("c"), OCCUR_SHOULD))
+	assertEquals(t, 2, len(q.Clauses()))
+}
+
+// A recordingCollector which accepts the docs out of order.
+type outOfOrderRecordingCollector struct {
+	recordingCollector
+}
+
+func (c *outOfOrderRecordingCollector) AcceptsDocsOutOfOrder() bool {
+	return true
+}
+
+// Returns the scores of the docs collected by c, by document.
+func recordedScores(c *recordingCollector) map[int]float32 {
+	ans := make(map[int]float32)
+	for i, doc := range c.docs {
+		ans[doc] = c.scores[i]
+	}
+	return ans
+}
+
+func TestBooleanScorer(t *testing.T) {
+	// spans a few windows of the bucket table
+	bodies := make([]string, 3*BUCKET_TABLE_SIZE+100)
+	for i := range bodies {
+		bodies[i] = fmt.Sprintf("%v %v %v", i%2, i%3, i%5)
+	}
+	ss, cleanup := newTestSearcher(t, bodies...)
+	defer cleanup()
+
+	zero, one, two := newBodyTermQuery("0"), newBodyTermQuery("1"), newBodyTermQuery("2")
+	for _, v := range []struct {
+		q   *BooleanQuery
+		min int
+	}{
+		{newTestBooleanQuery(one, OCCUR_SHOULD, two, OCCUR_SHOULD), 0},
+		{newTestBooleanQuery(zero, OCCUR_SHOULD, one, OCCUR_SHOULD, two, OCCUR_SHOULD), 2},
+		{newTestBooleanQuery(one, OCCUR_SHOULD, two, OCCUR_SHOULD, zero, OCCUR_MUST_NOT), 0},
+	} {
+		v.q.SetMinimumNumberShouldMatch(v.min)
+		w, err := ss.CreateNormalizedWeight(v.q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, true, w.IsScoresDocsOutOfOrder())
+		ctx := ss.TopReaderContext().Leaves()[0]
+		scorer, err := w.Scorer(ctx, false, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, ok := scorer.(*BooleanScorer)
+		assertEquals(t, true, ok)
+
+		inOrder, outOfOrder := new(recordingCollector), new(outOfOrderRecordingCollector)
+		if err := ss.SearchCollector(v.q, inOrder); err != nil {
+			t.Fatal(err)
+		}
+		if err := ss.SearchCollector(v.q, outOfOrder); err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, len(inOrder.docs), len(outOfOrder.docs))
+		if len(inOrder.docs) == 0 {
+			t.Errorf("Expected hits for %v", v.q)
+		}
+		// the buckets sum the scores in double precision
+		expected := recordedScores(inOrder)
+		for doc, score := range recordedScores(&outOfOrder.recordingCollector) {
+			if math.Abs(float64(score-expected[doc])) > 1e-6 {
+				t.Errorf("%v in %v: expected score %v, but %v", v.q, doc, expected[doc], score)
+			}
+		}
+	}
+
+	// required clauses are scored in order
+	q := newTestBooleanQuery(one, OCCUR_MUST, two, OCCUR_SHOULD)
+	w, err := ss.CreateNormalizedWeight(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, false, w.IsScoresDocsOutOfOrder())
+}
+
+// Returns the in-order scorer of q, in the first leaf.
+func newLeafScorer(t *testing.T, ss *IndexSearcher, q Query) Scorer {
+	w, err := ss.CreateNormalizedWeight(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scorer, err := w.Scorer(ss.TopReaderContext().Leaves()[0], true, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scorer
+}
+
+func TestScorerCost(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b", "a", "a c", "a b c", "a")
+	defer cleanup()
+
+	a, b, c := newBodyTermQuery("a"), newBodyTermQuery("b"), newBodyTermQuery("c")
+	for _, v := range []struct {
+		q    Query
+		cost int64
+	}{
+		{a, 5},
+		{newTestBooleanQuery(a, OCCUR_MUST, b, OCCUR_MUST), 2},
+		{newTestBooleanQuery(a, OCCUR_MUST, b, OCCUR_MUST, c, OCCUR_MUST), 2},
+		{newTestBooleanQuery(b, OCCUR_SHOULD, c, OCCUR_SHOULD), 4},
+		{newTestBooleanQuery(a, OCCUR_MUST, c, OCCUR_MUST_NOT), 5},
+		{newTestBooleanQuery(b, OCCUR_MUST, a, OCCUR_SHOULD), 2},
+		{NewMatchAllDocsQuery(), 5},
+	} {
+		assertEquals(t, v.cost, newLeafScorer(t, ss, v.q).Cost())
+	}
+
+	// the rarest term leads the conjunction
+	scorerA, scorerB := newLeafScorer(t, ss, a), newLeafScorer(t, ss, b)
+	conjunction := newConjunctionScorer(nil, []Scorer{scorerA, scorerB}, 1)
+	assertEquals(t, scorerB, conjunction.scorers[0])
+	var docs []int
+	for doc, more := conjunction.NextDoc(); more; doc, more = conjunction.NextDoc() {
+		docs = append(docs, doc)
+	}
+	assertEquals(t, "[0 3]", fmt.Sprint(docs))
+}
@@ -0,0 +1,318 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"math"
+)
+
+// search/BooleanScorer.java
+
+/*
+Description from Doug Cutting (excerpted from LUCENE-1483):
+
+BooleanScorer uses an array to score windows of 2K docs. So it scores
+docs 0-2K first, then docs 2K-4K, etc. For each window it iterates
+through all query terms and accumulates a score in table[doc%2K]. It
+also stores in the table a bitmask representing which terms
+contributed to the score. Non-zero scores are chained in a linked
+list. At the end of scoring each window it then iterates through the
+linked list and, if the bitmask matches the boolean constraints,
+collects a hit. For boolean queries with lots of frequent terms this
+can be much faster, since it does not need to update a priority queue
+for each posting, instead performing constant-time operations per
+posting. The only downside is that it results in hits being delivered
+out-of-order within the window, which means it cannot be nested
+within other scorers. But it works well as a top-level scorer.
+
+The new BooleanScorer2 implementation instead works by merging
+priority queues of postings, albeit with some clever tricks. For
+example, a pure conjunction (all terms required) does not require a
+priority queue. Instead it sorts the posting streams at the start,
+then repeatedly skips the first to the last. If the first ever equals
+the last, then there's a hit. When some terms are required and some
+terms are optional, the conjunction can be evaluated first, then the
+optional terms can all skip to the match and be added to the score.
+Thus the conjunction can reduce the number of priority queue updates
+for the optional terms.
+
+BooleanWeight selects this scorer when the collector accepts the
+documents out of order, it is the top-level scorer, and none of the
+clauses is required.
+*/
+type BooleanScorer struct {
+	*ScorerImpl
+	scorers          *subScorer
+	bucketTable      *bucketTable
+	coordFactors     []float32
+	minNrShouldMatch int
+	end              int
+	current          *bucket
+}
+
+// Any time a prohibited clause matches we set bit 0:
+const PROHIBITED_MASK = 1
+
+func newBooleanScorer(w *BooleanWeight, disableCoord bool, minNrShouldMatch int,
+	optional, prohibited []Scorer, maxCoord int) *BooleanScorer {
+	ans := &BooleanScorer{
+		bucketTable:      newBucketTable(),
+		minNrShouldMatch: minNrShouldMatch,
+	}
+	ans.ScorerImpl = NewScorer(ans, w)
+
+	for _, scorer := range optional {
+		if _, more := scorer.NextDoc(); more {
+			ans.scorers = &subScorer{scorer, false, ans.bucketTable.newCollector(0), ans.scorers}
+		}
+	}
+	for _, scorer := range prohibited {
+		if _, more := scorer.NextDoc(); more {
+			ans.scorers = &subScorer{scorer, true, ans.bucketTable.newCollector(PROHIBITED_MASK), ans.scorers}
+		}
+	}
+
+	ans.coordFactors = make([]float32, len(optional)+1)
+	for i := range ans.coordFactors {
+		if disableCoord {
+			ans.coordFactors[i] = 1
+		} else {
+			ans.coordFactors[i] = w.Coord(i, maxCoord)
+		}
+	}
+	return ans
+}
+
+func (s *BooleanScorer) ScoreAndCollect(c LeafCollector) error {
+	_, err := s.ScoreAndCollectUpTo(c, math.MaxInt32, -1)
+	return err
+}
+
+// firstDocID is ignored since NextDoc() initializes 'current'
+func (s *BooleanScorer) ScoreAndCollectUpTo(c LeafCollector, max, firstDocID int) (bool, error) {
+	// Make sure it's only BooleanScorer that calls us:
+	// assert firstDocID == -1
+	bs := newBucketScorer(s.weight)
+
+	// The internal loop will set the score and doc before calling
+	// Collect().
+	c.SetScorer(bs)
+	for {
+		s.bucketTable.first = nil
+
+		for s.current != nil { // more queued
+			// check prohibited & required
+			if s.current.bits&PROHIBITED_MASK == 0 {
+				// NOTE: Lucene always passes max = math.MaxInt32 today,
+				// because we never embed a BooleanScorer inside another
+				// (even though that should work)... but in theory an
+				// outside app could pass a different max so we must check
+				// it:
+				if s.current.doc >= max {
+					tmp := s.current
+					s.current = s.current.next
+					tmp.next = s.bucketTable.first
+					s.bucketTable.first = tmp
+					continue
+				}
+
+				if s.current.coord >= s.minNrShouldMatch {
+					bs.score = s.current.score * float64(s.coordFactors[s.current.coord])
+					bs.doc = s.current.doc
+					bs.freq = s.current.coord
+					if err := c.Collect(s.current.doc); err != nil {
+						return false, err
+					}
+				}
+			}
+
+			s.current = s.current.next // pop the queue
+		}
+
+		if s.bucketTable.first != nil {
+			s.current = s.bucketTable.first
+			s.bucketTable.first = s.current.next
+			return true, nil
+		}
+
+		// refill the queue
+		more := false
+		s.end += BUCKET_TABLE_SIZE
+		for sub := s.scorers; sub != nil; sub = sub.next {
+			if subScorerDocID := sub.scorer.DocId(); subScorerDocID != index.NO_MORE_DOCS {
+				subMore, err := sub.scorer.ScoreAndCollectUpTo(sub.collector, s.end, subScorerDocID)
+				if err != nil {
+					return false, err
+				}
+				more = more || subMore
+			}
+		}
+		s.current = s.bucketTable.first
+
+		if s.current == nil && !more {
+			return false, nil
+		}
+	}
+}
+
+func (s *BooleanScorer) DocId() int {
+	panic("not supported")
+}
+
+func (s *BooleanScorer) NextDoc() (int, bool) {
+	panic("not supported")
+}
+
+func (s *BooleanScorer) Advance(target int) (int, bool) {
+	panic("not supported")
+}
+
+func (s *BooleanScorer) Freq() int {
+	panic("not supported")
+}
+
+func (s *BooleanScorer) Score() float32 {
+	panic("not supported")
+}
+
+func (s *BooleanScorer) Cost() int64 {
+	return math.MaxInt32
+}
+
+func (s *BooleanScorer) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("boolean(")
+	for sub := s.scorers; sub != nil; sub = sub.next {
+		fmt.Fprintf(&buf, "%v ", sub.scorer)
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// search/BooleanScorer.java/BooleanScorerCollector
+
+// Accumulates the scores of the documents of a sub scorer in the
+// buckets of the table.
+type booleanScorerCollector struct {
+	bucketTable *bucketTable
+	mask        int
+	scorer      Scorer
+}
+
+func (c *booleanScorerCollector) Collect(doc int) error {
+	table := c.bucketTable
+	b := table.buckets[doc&BUCKET_TABLE_MASK]
+
+	if b.doc != doc { // invalid bucket
+		b.doc = doc                         // set doc
+		b.score = float64(c.scorer.Score()) // initialize score
+		b.bits = c.mask                     // initialize mask
+		b.coord = 1                         // initialize coord
+		b.next = table.first                // push onto valid list
+		table.first = b
+	} else { // valid bucket
+		b.score += float64(c.scorer.Score()) // increment score
+		b.bits |= c.mask                     // add bits in mask
+		b.coord++                            // increment coord
+	}
+	return nil
+}
+
+func (c *booleanScorerCollector) SetScorer(s Scorer) {
+	c.scorer = s
+}
+
+func (c *booleanScorerCollector) AcceptsDocsOutOfOrder() bool {
+	return true
+}
+
+// search/BooleanScorer.java/BucketScorer
+
+/*
+An internal Scorer which is used in ScoreAndCollectUpTo() for
+setting the current score. This is required since Collector exposes
+a SetScorer() method and implementations that need the score will
+call Scorer.Score(). Therefore the only methods that are implemented
+are Score() and DocId().
+*/
+type bucketScorer struct {
+	*ScorerImpl
+	score float64
+	doc   int
+	freq  int
+}
+
+func newBucketScorer(w Weight) *bucketScorer {
+	ans := &bucketScorer{doc: index.NO_MORE_DOCS}
+	ans.ScorerImpl = NewScorer(ans, w)
+	return ans
+}
+
+func (s *bucketScorer) Advance(target int) (int, bool) {
+	return index.NO_MORE_DOCS, false
+}
+
+func (s *bucketScorer) DocId() int {
+	return s.doc
+}
+
+func (s *bucketScorer) Freq() int {
+	return s.freq
+}
+
+func (s *bucketScorer) NextDoc() (int, bool) {
+	return index.NO_MORE_DOCS, false
+}
+
+func (s *bucketScorer) Score() float32 {
+	return float32(s.score)
+}
+
+func (s *bucketScorer) Cost() int64 {
+	return 1
+}
+
+// search/BooleanScorer.java/Bucket
+
+type bucket struct {
+	doc   int     // tells if bucket is valid
+	score float64 // incremental score
+	bits  int     // used for bool constraints
+	coord int     // count of terms in score
+	next  *bucket // next valid bucket
+}
+
+// search/BooleanScorer.java/BucketTable
+
+const (
+	BUCKET_TABLE_SIZE = 1 << 11
+	BUCKET_TABLE_MASK = BUCKET_TABLE_SIZE - 1
+)
+
+// A simple hash table of document scores within a range.
+type bucketTable struct {
+	buckets []*bucket
+	first   *bucket // head of valid list
+}
+
+func newBucketTable() *bucketTable {
+	ans := &bucketTable{buckets: make([]*bucket, BUCKET_TABLE_SIZE)}
+	for i := range ans.buckets {
+		ans.buckets[i] = &bucket{doc: -1}
+	}
+	return ans
+}
+
+func (t *bucketTable) newCollector(mask int) LeafCollector {
+	return &booleanScorerCollector{bucketTable: t, mask: mask}
+}
+
+// search/BooleanScorer.java/SubScorer
+
+type subScorer struct {
+	scorer     Scorer
+	prohibited bool
+	collector  LeafCollector
+	next       *subScorer
+}
@@ -0,0 +1,282 @@
+package search
+
+import (
+	"fmt"
+)
+
+// search/BooleanScorer2.java
+
+/*
+See the description in BooleanScorer, comparing BooleanScorer &
+BooleanScorer2.
+
+An alternative to BooleanScorer that also allows a minimum number of
+optional scorers that should match. Implements Advance(), and has no
+limitations on the numbers of added scorers. Uses ConjunctionScorer,
+DisjunctionScorer, ReqOptScorer and ReqExclScorer.
+
+The scorer is selected per segment from the sub-scorers of the
+clauses matching in it: a single scorer, a conjunction of the
+required ones, a disjunction of the optional ones, or a combination
+of them, less the documents of the prohibited ones.
+*/
+type BooleanScorer2 struct {
+	*ScorerImpl
+	requiredScorers   []Scorer
+	optionalScorers   []Scorer
+	prohibitedScorers []Scorer
+	coordinator       *coordinator
+	// The scorer to which all scoring will be delegated, except for
+	// computing and using the coordination factor.
+	countingSumScorer Scorer
+	// The number of optionalScorers that need to match (if there are
+	// any)
+	minNrShouldMatch int
+	doc              int
+}
+
+/*
+Creates a Scorer with the given similarity and lists of required,
+prohibited and optional scorers. In no required scorers are added, at
+least one of the optional scorers will have to match during the
+search.
+
+minNrShouldMatch is the minimum number of optional added scorers that
+should match during the search. In case no required scorers are
+added, at least one of the optional scorers will have to match during
+the search.
+*/
+func newBooleanScorer2(w *BooleanWeight, disableCoord bool, minNrShouldMatch int,
+	required, prohibited, optional []Scorer, maxCoord int) *BooleanScorer2 {
+	if minNrShouldMatch < 0 {
+		panic("Minimum number of optional scorers should not be negative")
+	}
+	ans := &BooleanScorer2{
+		requiredScorers:   required,
+		optionalScorers:   optional,
+		prohibitedScorers: prohibited,
+		minNrShouldMatch:  minNrShouldMatch,
+		doc:               -1,
+	}
+	ans.ScorerImpl = NewScorer(ans, w)
+	ans.coordinator = newCoordinator(w, maxCoord, disableCoord)
+	ans.countingSumScorer = ans.makeCountingSumScorer()
+	return ans
+}
+
+// search/BooleanScorer2.java/Coordinator
+
+// Counts the clauses matching the current document, and holds the
+// coord factor for each number of them.
+type coordinator struct {
+	coordFactors []float32
+	nrMatchers   int // to be increased by score() of match counting scorers.
+}
+
+func newCoordinator(w *BooleanWeight, maxCoord int, disableCoord bool) *coordinator {
+	ans := &coordinator{coordFactors: make([]float32, len(w.query.clauses)+1)}
+	for i := range ans.coordFactors {
+		if disableCoord {
+			ans.coordFactors[i] = 1
+		} else {
+			ans.coordFactors[i] = w.Coord(i, maxCoord)
+		}
+	}
+	return ans
+}
+
+/*
+A Scorer which adds the number of matching clauses of its scorer to
+the coordinator, once for each document it scores. It counts one
+match for a single clause, all the clauses of a conjunction, or the
+matching clauses of a disjunction.
+*/
+type countingScorer struct {
+	*ScorerImpl
+	scorer      Scorer
+	coordinator *coordinator
+	// the number of clauses matching the current doc of scorer
+	nrMatchers    func() int
+	lastScoredDoc int
+	lastDocScore  float32
+}
+
+func newCountingScorer(scorer Scorer, coordinator *coordinator, nrMatchers func() int) *countingScorer {
+	ans := &countingScorer{
+		scorer:        scorer,
+		coordinator:   coordinator,
+		nrMatchers:    nrMatchers,
+		lastScoredDoc: -1,
+	}
+	ans.ScorerImpl = NewScorer(ans, scorer.Weight())
+	return ans
+}
+
+func (s *countingScorer) Score() float32 {
+	// Save the score of lastScoredDoc, so that we don't compute it more than
+	// once in score().
+	if doc := s.scorer.DocId(); doc >= s.lastScoredDoc {
+		if doc > s.lastScoredDoc {
+			s.lastDocScore = s.scorer.Score()
+			s.lastScoredDoc = doc
+		}
+		s.coordinator.nrMatchers += s.nrMatchers()
+	}
+	return s.lastDocScore
+}
+
+func (s *countingScorer) DocId() int {
+	return s.scorer.DocId()
+}
+
+func (s *countingScorer) Freq() int {
+	return s.scorer.Freq()
+}
+
+func (s *countingScorer) NextDoc() (int, bool) {
+	return s.scorer.NextDoc()
+}
+
+func (s *countingScorer) Advance(target int) (int, bool) {
+	return s.scorer.Advance(target)
+}
+
+func (s *countingScorer) Cost() int64 {
+	return s.scorer.Cost()
+}
+
+// Counts a single matching clause.
+func (s *BooleanScorer2) singleMatchScorer(scorer Scorer) Scorer {
+	return newCountingScorer(scorer, s.coordinator, func() int { return 1 })
+}
+
+// Counts the matching clauses of a disjunction.
+func (s *BooleanScorer2) countingDisjunctionSumScorer(scorers []Scorer, minNrShouldMatch int) Scorer {
+	// each scorer from the list counted as a single matcher
+	disjunction := newDisjunctionSumScorer(s.weight, scorers, minNrShouldMatch)
+	return newCountingScorer(disjunction, s.coordinator, func() int { return disjunction.nrMatchers })
+}
+
+// Counts all the clauses of a conjunction.
+func (s *BooleanScorer2) countingConjunctionSumScorer(requiredScorers []Scorer) Scorer {
+	// each scorer from the list counted as a single matcher
+	requiredNrMatchers := len(requiredScorers)
+	conjunction := newConjunctionScorer(s.weight, requiredScorers, 1)
+	return newCountingScorer(conjunction, s.coordinator, func() int { return requiredNrMatchers })
+}
+
+func (s *BooleanScorer2) dualConjunctionSumScorer(req1, req2 Scorer) Scorer { // non counting.
+	return newConjunctionScorer(s.weight, []Scorer{req1, req2}, 1)
+	// All scorers match, so defaultSimilarity always has 1 as
+	// the coordination factor.
+	// Therefore the sum of the scores of two scorers
+	// is used as score.
+}
+
+/*
+Returns the scorer to be used for match counting and score summing.
+Uses requiredScorers, optionalScorers and prohibitedScorers.
+*/
+func (s *BooleanScorer2) makeCountingSumScorer() Scorer { // each scorer counted as a single matcher
+	if len(s.requiredScorers) == 0 {
+		return s.makeCountingSumScorerNoReq()
+	}
+	return s.makeCountingSumScorerSomeReq()
+}
+
+func (s *BooleanScorer2) makeCountingSumScorerNoReq() Scorer { // No required scorers
+	// minNrShouldMatch optional scorers are required, but at least 1
+	nrOptRequired := s.minNrShouldMatch
+	if nrOptRequired < 1 {
+		nrOptRequired = 1
+	}
+	var requiredCountingSumScorer Scorer
+	if len(s.optionalScorers) > nrOptRequired {
+		requiredCountingSumScorer = s.countingDisjunctionSumScorer(s.optionalScorers, nrOptRequired)
+	} else if len(s.optionalScorers) == 1 {
+		requiredCountingSumScorer = s.singleMatchScorer(s.optionalScorers[0])
+	} else {
+		requiredCountingSumScorer = s.countingConjunctionSumScorer(s.optionalScorers)
+	}
+	return s.addProhibitedScorers(requiredCountingSumScorer)
+}
+
+func (s *BooleanScorer2) makeCountingSumScorerSomeReq() Scorer { // At least one required scorer.
+	if len(s.optionalScorers) == s.minNrShouldMatch { // all optional scorers also required.
+		allReq := make([]Scorer, 0, len(s.requiredScorers)+len(s.optionalScorers))
+		allReq = append(allReq, s.requiredScorers...)
+		allReq = append(allReq, s.optionalScorers...)
+		return s.addProhibitedScorers(s.countingConjunctionSumScorer(allReq))
+	}
+	// optionalScorers.size() > minNrShouldMatch, and at least one required scorer
+	var requiredCountingSumScorer Scorer
+	if len(s.requiredScorers) == 1 {
+		requiredCountingSumScorer = s.singleMatchScorer(s.requiredScorers[0])
+	} else {
+		requiredCountingSumScorer = s.countingConjunctionSumScorer(s.requiredScorers)
+	}
+	if s.minNrShouldMatch > 0 { // use a required disjunction scorer over the optional scorers
+		return s.addProhibitedScorers(s.dualConjunctionSumScorer( // non counting
+			requiredCountingSumScorer,
+			s.countingDisjunctionSumScorer(s.optionalScorers, s.minNrShouldMatch)))
+	}
+	// minNrShouldMatch == 0
+	var optionalScorer Scorer
+	if len(s.optionalScorers) == 1 {
+		optionalScorer = s.singleMatchScorer(s.optionalScorers[0])
+	} else {
+		// require 1 in combined, optional scorer.
+		optionalScorer = s.countingDisjunctionSumScorer(s.optionalScorers, 1)
+	}
+	return newReqOptSumScorer(s.addProhibitedScorers(requiredCountingSumScorer), optionalScorer)
+}
+
+/*
+Returns the scorer to be used for match counting and score summing.
+Uses the given required scorer and the prohibitedScorers.
+*/
+func (s *BooleanScorer2) addProhibitedScorers(requiredCountingSumScorer Scorer) Scorer {
+	switch len(s.prohibitedScorers) {
+	case 0:
+		return requiredCountingSumScorer // no prohibited
+	case 1:
+		return newReqExclScorer(requiredCountingSumScorer, s.prohibitedScorers[0])
+	default:
+		return newReqExclScorer(requiredCountingSumScorer,
+			newDisjunctionSumScorer(s.weight, s.prohibitedScorers, 1))
+	}
+}
+
+func (s *BooleanScorer2) DocId() int {
+	return s.doc
+}
+
+func (s *BooleanScorer2) NextDoc() (int, bool) {
+	var more bool
+	s.doc, more = s.countingSumScorer.NextDoc()
+	return s.doc, more
+}
+
+func (s *BooleanScorer2) Score() float32 {
+	s.coordinator.nrMatchers = 0
+	sum := s.countingSumScorer.Score()
+	return sum * s.coordinator.coordFactors[s.coordinator.nrMatchers]
+}
+
+func (s *BooleanScorer2) Freq() int {
+	return s.countingSumScorer.Freq()
+}
+
+func (s *BooleanScorer2) Cost() int64 {
+	return s.countingSumScorer.Cost()
+}
+
+func (s *BooleanScorer2) Advance(target int) (int, bool) {
+	var more bool
+	s.doc, more = s.countingSumScorer.Advance(target)
+	return s.doc, more
+}
+
+func (s *BooleanScorer2) String() string {
+	return fmt.Sprintf("scorer(%v)", s.weight)
+}
@@ -0,0 +1,253 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"sync"
+)
+
+// search/CachingWrapperFilter.java
+
+/*
+Wraps another Filter's result and caches it. The purpose is to allow
+filters to simply filter, and then wrap with this class to add
+caching.
+
+The DocIdSets are cached per segment, by the core cache key of its
+reader, so they are shared by the readers of a segment whose
+deletions changed; the acceptDocs are applied to the cached set for
+each search. A DocIdSet which isn't a bit set is compressed into a
+WAH8DocIdSet first, so the cached sets of large indexes don't each
+cost maxDoc/8 bytes. The entries of a segment are
+evicted when its core is closed.
+*/
+type CachingWrapperFilter struct {
+	filter Filter
+	lock   sync.Mutex
+	cache  map[interface{}]DocIdSet
+	// the keys whose readers have a listener purging the cache
+	purged map[interface{}]bool
+	// for testing
+	hitCount, missCount int
+}
+
+// Wraps another filter's result and caches it.
+func NewCachingWrapperFilter(filter Filter) *CachingWrapperFilter {
+	return &CachingWrapperFilter{
+		filter: filter,
+		cache:  make(map[interface{}]DocIdSet),
+		purged: make(map[interface{}]bool),
+	}
+}
+
+// The cached DocIdSet of a segment whose filter matches no document.
+var emptyDocIdSet = DocIdSet(NewFixedBitSetDocIdSet(util.NewFixedBitSet(0)))
+
+/*
+Provide the DocIdSet to be cached, using the DocIdSet provided by the
+wrapped Filter. This implementation returns the given DocIdSet, if
+it's a bit set, which is the case of MultiTermQueryWrapperFilter,
+otherwise it copies the documents of its iterator into a compressed
+WAH8DocIdSet.
+*/
+func docIdSetToCache(docIdSet DocIdSet, reader index.AtomicReader) (DocIdSet, error) {
+	if docIdSet == nil {
+		// this is better than returning nil, as the nonnull result can
+		// be cached
+		return emptyDocIdSet, nil
+	}
+	if _, ok := docIdSet.(*FixedBitSetDocIdSet); ok {
+		return docIdSet, nil
+	}
+	it, err := docIdSet.Iterator()
+	if err != nil {
+		return nil, err
+	}
+	// nil is allowed to be returned by Iterator(), in this case we
+	// wrap with the empty set, which is cacheable.
+	if it == nil {
+		return emptyDocIdSet, nil
+	}
+	return NewWAH8DocIdSetBuilder().AddIterator(it).Build(), nil
+}
+
+func (f *CachingWrapperFilter) DocIdSet(ctx index.AtomicReaderContext, acceptDocs util.Bits) (DocIdSet, error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	key := reader.CoreCacheKey()
+
+	f.lock.Lock()
+	docIdSet, ok := f.cache[key]
+	if ok {
+		f.hitCount++
+	} else {
+		f.missCount++
+	}
+	f.lock.Unlock()
+
+	if !ok {
+		// the deletions are applied to the cached set on every search
+		uncached, err := f.filter.DocIdSet(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		if docIdSet, err = docIdSetToCache(uncached, reader); err != nil {
+			return nil, err
+		}
+		f.lock.Lock()
+		f.cache[key] = docIdSet
+		register := !f.purged[key]
+		f.purged[key] = true
+		f.lock.Unlock()
+		if register {
+			f.initReader(reader)
+		}
+	}
+
+	if docIdSet == emptyDocIdSet {
+		return nil, nil
+	}
+	return wrapBitsFilteredDocIdSet(docIdSet, acceptDocs), nil
+}
+
+// Registers the listener which purges the entry of the reader, when
+// its core is closed.
+func (f *CachingWrapperFilter) initReader(reader index.AtomicReader) {
+	if sr, ok := reader.(*index.SegmentReader); ok {
+		sr.AddCoreClosedListener((*purgeCoreListener)(f))
+		return
+	}
+	// we have a slow reader of some sort, try to register a purge
+	// event to its key
+	if key, ok := reader.CoreCacheKey().(index.IndexReader); ok {
+		key.AddReaderClosedListener((*purgeReaderListener)(f))
+		return
+	}
+	// last chance
+	reader.AddReaderClosedListener((*purgeReaderListener)(f))
+}
+
+// Removes the entry of the given core cache key.
+func (f *CachingWrapperFilter) purge(key interface{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.cache, key)
+	delete(f.purged, key)
+}
+
+type purgeCoreListener CachingWrapperFilter
+
+func (l *purgeCoreListener) OnClose(r *index.SegmentReader) {
+	(*CachingWrapperFilter)(l).purge(r.CoreCacheKey())
+}
+
+type purgeReaderListener CachingWrapperFilter
+
+func (l *purgeReaderListener) OnClose(r index.IndexReader) {
+	(*CachingWrapperFilter)(l).purge(r.CoreCacheKey())
+}
+
+// Returns the number of the cached DocIdSets.
+func (f *CachingWrapperFilter) size() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.cache)
+}
+
+func (f *CachingWrapperFilter) String() string {
+	return fmt.Sprintf("CachingWrapperFilter(%v)", f.filter)
+}
+
+// search/BitsFilteredDocIdSet.java
+
+/*
+This implementation supplies a filtered DocIdSet, that excludes all
+docids which are not in a Bits instance. This is especially useful
+in Filter to apply the acceptDocs passed to DocIdSet() before
+returning the final DocIdSet.
+*/
+type bitsFilteredDocIdSet struct {
+	innerSet   DocIdSet
+	acceptDocs util.Bits
+}
+
+/*
+Convenience wrapper method: If acceptDocs == nil it returns the
+original set without wrapping.
+*/
+func wrapBitsFilteredDocIdSet(set DocIdSet, acceptDocs util.Bits) DocIdSet {
+	if set == nil || acceptDocs == nil {
+		return set
+	}
+	return &bitsFilteredDocIdSet{set, acceptDocs}
+}
+
+func (s *bitsFilteredDocIdSet) Iterator() (index.DocIdSetIterator, error) {
+	it, err := s.innerSet.Iterator()
+	if it == nil || err != nil {
+		return nil, err
+	}
+	return &bitsFilteredDocIdSetIterator{it, s.acceptDocs, -1}, nil
+}
+
+func (s *bitsFilteredDocIdSet) Bits() util.Bits {
+	bits := s.innerSet.Bits()
+	if bits == nil {
+		return nil
+	}
+	return &filteredBits{bits, s.acceptDocs}
+}
+
+// The Bits of both the inner set and the acceptDocs.
+type filteredBits struct {
+	bits, acceptDocs util.Bits
+}
+
+func (b *filteredBits) Get(docid int) bool {
+	return b.bits.Get(docid) && b.acceptDocs.Get(docid)
+}
+
+func (b *filteredBits) Length() int {
+	return b.bits.Length()
+}
+
+// search/FilteredDocIdSetIterator.java
+
+// An iterator of the documents of an inner iterator, which are in
+// the acceptDocs.
+type bitsFilteredDocIdSetIterator struct {
+	innerIter  index.DocIdSetIterator
+	acceptDocs util.Bits
+	doc        int
+}
+
+func (it *bitsFilteredDocIdSetIterator) DocId() int {
+	return it.doc
+}
+
+func (it *bitsFilteredDocIdSetIterator) Freq() int {
+	return it.innerIter.Freq()
+}
+
+func (it *bitsFilteredDocIdSetIterator) NextDoc() (int, bool) {
+	doc, more := it.innerIter.NextDoc()
+	return it.match(doc, more)
+}
+
+func (it *bitsFilteredDocIdSetIterator) Cost() int64 {
+	return it.innerIter.Cost()
+}
+
+func (it *bitsFilteredDocIdSetIterator) Advance(target int) (int, bool) {
+	doc, more := it.innerIter.Advance(target)
+	return it.match(doc, more)
+}
+
+// Skips the documents which are not accepted, from doc on.
+func (it *bitsFilteredDocIdSetIterator) match(doc int, more bool) (int, bool) {
+	for more && !it.acceptDocs.Get(doc) {
+		doc, more = it.innerIter.NextDoc()
+	}
+	it.doc = doc
+	return doc, more
+}
@@ -0,0 +1,113 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"testing"
+	"time"
+)
+
+// Returns the documents of the set, or nil for a nil set.
+func docIdSetDocs(t *testing.T, set DocIdSet) []int {
+	if set == nil {
+		return nil
+	}
+	it, err := set.Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docs []int
+	for doc, more := it.NextDoc(); more; doc, more = it.NextDoc() {
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func TestCachingWrapperFilter(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+	defer cleanup()
+
+	f := NewCachingWrapperFilter(NewQueryWrapperFilter(newBodyTermQuery("a")))
+	for i := 0; i < 3; i++ {
+		docs, err := ss.Search(NewMatchAllDocsQuery(), f, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, 3, docs.TotalHits)
+	}
+	leaves := len(ss.IndexReader().Context().Leaves())
+	assertEquals(t, leaves, f.missCount)
+	assertEquals(t, 2*leaves, f.hitCount)
+	assertEquals(t, leaves, f.size())
+	assertEquals(t, "CachingWrapperFilter(QueryWrapperFilter(body:a))", fmt.Sprint(f))
+}
+
+func TestCachingWrapperFilterAcceptDocs(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+	defer cleanup()
+
+	ctx := ss.IndexReader().Context().Leaves()[0]
+	f := NewCachingWrapperFilter(NewQueryWrapperFilter(newBodyTermQuery("a")))
+	set, err := f.DocIdSet(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[0 1 3]", fmt.Sprint(docIdSetDocs(t, set)))
+
+	// the cached set is filtered by the acceptDocs of each call
+	acceptDocs := util.NewFixedBitSet(5)
+	acceptDocs.Set(1)
+	acceptDocs.Set(2)
+	acceptDocs.Set(3)
+	set, err = f.DocIdSet(ctx, acceptDocs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[1 3]", fmt.Sprint(docIdSetDocs(t, set)))
+	// the set is cached compressed, without random access
+	if _, ok := f.cache[ctx.Reader().(index.AtomicReader).CoreCacheKey()].(*WAH8DocIdSet); !ok {
+		t.Errorf("Expected a cached WAH8DocIdSet, but %v", f.cache)
+	}
+	if set.Bits() != nil {
+		t.Error("Expected no random access to the cached set")
+	}
+	assertEquals(t, 1, f.missCount)
+	assertEquals(t, 1, f.hitCount)
+
+	// a filter matching no document is cached, too
+	f = NewCachingWrapperFilter(NewQueryWrapperFilter(newBodyTermQuery("nosuchterm")))
+	for i := 0; i < 2; i++ {
+		set, err = f.DocIdSet(ctx, acceptDocs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, 0, len(docIdSetDocs(t, set)))
+	}
+	assertEquals(t, 1, f.missCount)
+	assertEquals(t, 1, f.hitCount)
+}
+
+func TestCachingWrapperFilterPurge(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+
+	f := NewCachingWrapperFilter(NewMultiTermQueryWrapperFilter(
+		NewPrefixQuery(index.NewTerm("body", "a")).MultiTermQuery))
+	docs, err := ss.Search(NewMatchAllDocsQuery(), f, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, docs.TotalHits)
+	if f.size() == 0 {
+		t.Fatal("Expected cached DocIdSets")
+	}
+
+	// the entries are purged when the segment cores are closed
+	cleanup()
+	for i := 0; f.size() > 0; i++ {
+		if i == 100 {
+			t.Fatalf("Expected purged cache, but %v entries", f.size())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -2,108 +2,407 @@ package search
 
 import (
 	"container/heap"
+	"errors"
+	"fmt"
 	"github.com/balzaczyy/golucene/index"
 	"math"
 )
 
+// search/ScoreDoc.java
+
+// Holds one hit in TopDocs.
 type ScoreDoc struct {
-	score float64
-	doc   int
+	// The score of this document for the query.
+	Score float32
+	// A hit document's number.
+	Doc int
+	// Only set by MergeTopDocs(), or -1.
+	ShardIndex int
 }
 
-type PriorityQueue struct {
-	items []interface{}
-	less  func(i, j int) bool
+// Constructs a ScoreDoc, which is not merged from a shard.
+func NewScoreDoc(doc int, score float32) *ScoreDoc {
+	return &ScoreDoc{score, doc, -1}
 }
 
-func (pq PriorityQueue) Len() int            { return len(pq.items) }
-func (pq PriorityQueue) Less(i, j int) bool  { return pq.less(i, j) }
-func (pq PriorityQueue) Swap(i, j int)       { pq.items[i], pq.items[j] = pq.items[j], pq.items[i] }
-func (pq *PriorityQueue) Push(x interface{}) { pq.items = append(pq.items, x) }
-func (pq *PriorityQueue) Pop() interface{} {
-	n := pq.Len()
-	ans := pq.items[n-1]
-	pq.items = pq.items[0 : n-1]
-	return ans
+func (d *ScoreDoc) String() string {
+	return fmt.Sprintf("doc=%v score=%v shardIndex=%v", d.Doc, d.Score, d.ShardIndex)
 }
 
+// search/TopDocs.java
+
+// Represents hits returned by IndexSearcher.Search().
 type TopDocs struct {
-	totalHits int
-	scoreDocs []ScoreDoc
-	maxScore  float64
+	// The total number of hits for the query.
+	TotalHits int
+	// The top hits for the query.
+	ScoreDocs []*ScoreDoc
+	// Stores the maximum score value encountered, needed for
+	// normalizing, or NaN if scores are not tracked.
+	MaxScore float32
 }
 
-type Collector interface {
+// search/TopDocs.java/ShardRef
+
+// Refers to one hit in the shard hits being merged.
+type shardRef struct {
+	// Which shard (index into shardHits[]):
+	shardIndex int
+	// Which hit within the shard:
+	hitIndex int
+}
+
+// search/TopDocs.java/ScoreMergeSortQueue
+
+// Orders the current hits of the shards by score descending, and
+// then by shard and hit index, which breaks ties by docID.
+type scoreMergeSortQueue struct {
+	refs      []*shardRef
+	shardHits [][]*ScoreDoc
+}
+
+func (q *scoreMergeSortQueue) Len() int { return len(q.refs) }
+
+func (q *scoreMergeSortQueue) Less(i, j int) bool {
+	first, second := q.refs[i], q.refs[j]
+	firstScore := q.shardHits[first.shardIndex][first.hitIndex].Score
+	secondScore := q.shardHits[second.shardIndex][second.hitIndex].Score
+	if firstScore != secondScore {
+		return firstScore > secondScore
+	}
+	// Tie break: earlier shard wins
+	if first.shardIndex != second.shardIndex {
+		return first.shardIndex < second.shardIndex
+	}
+	// Tie break in same shard: resolve however the shard had resolved
+	// it:
+	return first.hitIndex < second.hitIndex
+}
+
+func (q *scoreMergeSortQueue) Swap(i, j int) { q.refs[i], q.refs[j] = q.refs[j], q.refs[i] }
+
+func (q *scoreMergeSortQueue) Push(x interface{}) { q.refs = append(q.refs, x.(*shardRef)) }
+
+func (q *scoreMergeSortQueue) Pop() interface{} {
+	n := len(q.refs)
+	ans := q.refs[n-1]
+	q.refs = q.refs[:n-1]
+	return ans
+}
+
+/*
+Returns a new TopDocs, containing topN results across the provided
+TopDocs, sorting by score. Each TopDocs instance must be sorted by
+score, e.g. returned by a TopScoreDocCollector of a shard, i.e. a
+sub-index searched by its own IndexSearcher.
+
+The ShardIndex of each returned ScoreDoc is set to the index of its
+TopDocs in shardHits. The TotalHits of the result is the sum of those
+of the shards.
+*/
+func MergeTopDocs(topN int, shardHits []TopDocs) TopDocs {
+	queue := &scoreMergeSortQueue{shardHits: make([][]*ScoreDoc, len(shardHits))}
+	totalHitCount := 0
+	availHitCount := 0
+	maxScore := float32(math.SmallestNonzeroFloat32)
+	for shardIdx, shard := range shardHits {
+		queue.shardHits[shardIdx] = shard.ScoreDocs
+		// totalHits can be non-zero even if no hits were collected,
+		// when searchAfter was used:
+		totalHitCount += shard.TotalHits
+		if len(shard.ScoreDocs) > 0 {
+			availHitCount += len(shard.ScoreDocs)
+			heap.Push(queue, &shardRef{shardIdx, 0})
+			// like Java's Math.max(), NaN wins
+			if shard.MaxScore > maxScore || math.IsNaN(float64(shard.MaxScore)) {
+				maxScore = shard.MaxScore
+			}
+		}
+	}
+	if availHitCount == 0 {
+		maxScore = float32(math.NaN())
+	}
+
+	if topN > availHitCount {
+		topN = availHitCount
+	}
+	hits := make([]*ScoreDoc, topN)
+	for hitUpto := range hits {
+		ref := heap.Pop(queue).(*shardRef)
+		hit := shardHits[ref.shardIndex].ScoreDocs[ref.hitIndex]
+		hit.ShardIndex = ref.shardIndex
+		hits[hitUpto] = hit
+		if ref.hitIndex++; ref.hitIndex < len(shardHits[ref.shardIndex].ScoreDocs) {
+			heap.Push(queue, ref)
+		}
+	}
+	return TopDocs{totalHitCount, hits, maxScore}
+}
+
+// search/Collector.java
+
+/*
+Expert: the part of a Collector which collects the hits of a single
+leaf, i.e. segment, of the index. Scorers only drive this part of a
+Collector, within the leaf IndexSearcher set by SetNextReader().
+*/
+type LeafCollector interface {
+	/*
+		Called before successive calls to Collect(). Implementations that
+		need the score of the current document (passed-in to Collect()),
+		should save the passed-in Scorer and call Scorer.Score() when
+		needed.
+	*/
 	SetScorer(s Scorer)
-	Collect(doc int)
-	SetNextReader(ctx index.AtomicReaderContext)
+	/*
+		Called once for every document matching a query, with the
+		unbased document number. A non-nil error stops the search, and
+		is returned by IndexSearcher.
+
+		Note: The collection of the current segment can be terminated by
+		returning ErrCollectionTerminated, in which case the search
+		continues with the next segment.
+	*/
+	Collect(doc int) error
+	/*
+		Return true if this collector does not require the matching
+		docIDs to be delivered in int sort order (smallest to largest) to
+		Collect().
+
+		Most Lucene Query implementations will visit matching docIDs in
+		order. However, some queries (currently limited to certain cases
+		of BooleanQuery) can achieve faster searching if the Collector
+		allows them to deliver the docIDs out of order.
+
+		Many collectors don't mind getting docIDs out of order, so it's
+		important to return true here.
+	*/
 	AcceptsDocsOutOfOrder() bool
 }
 
+/*
+Expert: Collectors are primarily meant to be used to gather raw
+results from a search, and implement sorting or custom result
+filtering, collation, etc.
+
+IndexSearcher drives a Collector through the segments of its reader:
+for each of them it calls SetNextReader(), then SetScorer() with the
+Scorer matching the segment's documents, then Collect() for each of
+them. So a custom aggregation only implements this lifecycle, and
+plugs into IndexSearcher.SearchCollector(), which does the traversal.
+
+Collector decouples the score from the collected doc: the score
+computation is skipped entirely if it's not needed. Collectors that
+do need the score should implement SetScorer(), to hold onto the
+passed Scorer instance, and call Scorer.Score() within Collect() to
+compute the current hit's score.
+
+Lucene's core collectors are derived from Collector:
+
+  - TopDocsCollector is an abstract base class that assumes you will
+    retrieve the top N docs, according to some criteria, after
+    collection is done.
+  - TopScoreDocCollector is a concrete subclass TopDocsCollector and
+    sorts according to score + docID. This is used internally by the
+    IndexSearcher search methods that do not take an explicit Sort.
+  - TopFieldCollector sorts according to a specified Sort object
+    (sort by field).
+  - TotalHitCountCollector simply counts the number of hits.
+  - MultiCollector forwards the hits to several collectors.
+  - PositiveScoresOnlyCollector wraps any other Collector and prevents
+    collection of hits whose score is <= 0.0.
+  - TimeLimitingCollector wraps any other Collector and aborts the
+    search if it's taken too much time.
+*/
+type Collector interface {
+	LeafCollector
+	/*
+		Called before collecting from each AtomicReaderContext. All doc
+		ids in Collect() will correspond to ctx.Reader(). Add
+		ctx.DocBase to the current reader's internal document id to
+		re-base ids in Collect().
+
+		Returning ErrCollectionTerminated skips the leaf.
+	*/
+	SetNextReader(ctx index.AtomicReaderContext) error
+}
+
+// search/CollectionTerminatedException.java
+
+/*
+Returned by Collector.Collect() to terminate the collection of the
+current leaf. IndexSearcher then moves on to the next leaf, without
+returning an error.
+*/
+var ErrCollectionTerminated = errors.New("collection of the current leaf terminated")
+
+// search/HitQueue.java
+
+/*
+The priority queue of TopScoreDocCollector, whose least element, the
+top, is the hit with the lowest score, or the highest doc id among
+those with the lowest score.
+
+It is pre-populated with sentinel objects, whose score is -Inf, which
+are all less than any actual hit, so a collector only has to compare
+a hit with the top and replace it, instead of checking the size of
+the queue.
+*/
+type hitQueue []*ScoreDoc
+
+func newHitQueue(size int) *hitQueue {
+	pq := make(hitQueue, size)
+	for i := range pq {
+		// Always set the doc Id to MaxInt32 so that it won't be
+		// favored by lessThan. This generally should not happen since
+		// if score is not NEG_INF, TopScoreDocCollector will always
+		// add the object to the queue.
+		pq[i] = NewScoreDoc(math.MaxInt32, float32(math.Inf(-1)))
+	}
+	return &pq
+}
+
+func (pq hitQueue) Len() int { return len(pq) }
+
+func (pq hitQueue) Less(i, j int) bool {
+	if pq[i].Score == pq[j].Score {
+		return pq[i].Doc > pq[j].Doc
+	}
+	return pq[i].Score < pq[j].Score
+}
+
+func (pq hitQueue) Swap(i, j int) { pq[i], pq[j] = pq[j], pq[i] }
+
+func (pq *hitQueue) Push(x interface{}) { *pq = append(*pq, x.(*ScoreDoc)) }
+
+func (pq *hitQueue) Pop() interface{} {
+	n := len(*pq)
+	ans := (*pq)[n-1]
+	*pq = (*pq)[:n-1]
+	return ans
+}
+
+// search/TopDocsCollector.java
+
+/*
+The hooks of TopDocsCollector, which the concrete collectors which
+embed it may override.
+*/
+type topDocsCollector interface {
+	/*
+		Populates the results array with the ScoreDoc instances. This can
+		be overridden in case a different ScoreDoc type should be
+		returned.
+	*/
+	populateResults(results []*ScoreDoc, howMany int)
+	/*
+		Returns a TopDocs instance containing the given results. If
+		results is nil it means there are no results to return, either
+		because there were 0 calls to Collect() or because the arguments
+		to TopDocsRange() were invalid.
+	*/
+	newTopDocs(results []*ScoreDoc, start int) TopDocs
+	// The number of valid PQ entries
+	topDocsSize() int
+}
+
+/*
+A base class for all collectors that return a TopDocs output. This
+collector allows easy extension by providing a single constructor
+which accepts a priority queue of ScoreDocs, whose Pop() returns the
+least relevant hit. Extending classes can override the hooks of
+topDocsCollector in order to provide their own implementation.
+*/
 type TopDocsCollector struct {
-	Collector
-	self      interface{}
-	pq        *PriorityQueue // PriorityQueue
-	TotalHits int
-	// populateResults func(results []ScoreDoc, howMany int)
-	// newTopDocs      func(results []ScoreDoc, start int) TopDocs
-	// topDocsSize     func() int
-	// TopDocs         func() TopDocs
+	self topDocsCollector
+	/*
+		The priority queue which holds the top documents. Note that
+		different implementations of PriorityQueue give different
+		meaning to 'top documents'. HitQueue for example aggregates the
+		top scoring documents, while other PQ implementations may hold
+		documents sorted by other criteria.
+	*/
+	pq heap.Interface
+	// The total number of documents that the collector encountered.
+	totalHits int
 }
 
-func newTopDocsCollector(self interface{}, pq *PriorityQueue) *TopDocsCollector {
+func newTopDocsCollector(self topDocsCollector, pq heap.Interface) *TopDocsCollector {
 	return &TopDocsCollector{self: self, pq: pq}
 }
 
-func (c *TopDocsCollector) populateResults(results []ScoreDoc, howMany int) {
+func (c *TopDocsCollector) populateResults(results []*ScoreDoc, howMany int) {
 	for i := howMany - 1; i >= 0; i-- {
-		results[i] = *(heap.Pop(c.pq).(*ScoreDoc))
+		results[i] = heap.Pop(c.pq).(*ScoreDoc)
 	}
 }
 
-func (c *TopDocsCollector) newTopDocs(results []ScoreDoc, start int) TopDocs {
+func (c *TopDocsCollector) newTopDocs(results []*ScoreDoc, start int) TopDocs {
 	if results == nil {
-		return TopDocs{0, []ScoreDoc{}, math.NaN()}
+		return TopDocs{0, nil, float32(math.NaN())}
 	}
-	return TopDocs{c.TotalHits, results, math.NaN()}
+	return TopDocs{c.totalHits, results, float32(math.NaN())}
 }
 
+// The total number of documents that matched this query.
+func (c *TopDocsCollector) TotalHits() int {
+	return c.totalHits
+}
+
+// The number of valid PQ entries
 func (c *TopDocsCollector) topDocsSize() int {
 	// In case pq was populated with sentinel values, there might be less
 	// results than pq.size(). Therefore return all results until either
 	// pq.size() or totalHits.
-	if n := c.pq.Len(); c.TotalHits >= n {
+	if n := c.pq.Len(); c.totalHits >= n {
 		return n
 	}
-	return c.TotalHits
+	return c.totalHits
 }
 
+// Returns the top docs that were collected by this collector.
 func (c *TopDocsCollector) TopDocs() TopDocs {
 	// In case pq was populated with sentinel values, there might be less
 	// results than pq.size(). Therefore return all results until either
 	// pq.size() or totalHits.
-	return c.TopDocsRange(0, c.topDocsSize())
+	return c.TopDocsRange(0, c.self.topDocsSize())
 }
 
+/*
+Returns the documents in the range [start .. start+howMany) that were
+collected by this collector. Note that if start >= pq.size(), an
+empty TopDocs is returned, and if pq.size() - start < howMany, then
+only the available documents in [start .. pq.size()) are returned.
+
+This method is useful to call in case pagination of search results is
+allowed by the search application, as well as it attempts to optimize
+the memory used by allocating only as much as requested by howMany.
+
+NOTE: you cannot call this method more than once for each search
+execution. If you need to call it more than once, passing each time a
+different range, you should call TopDocs() and work with the returned
+TopDocs object, which will contain all the results this search
+execution collected.
+*/
 func (c *TopDocsCollector) TopDocsRange(start, howMany int) TopDocs {
 	// In case pq was populated with sentinel values, there might be less
 	// results than pq.size(). Therefore return all results until either
 	// pq.size() or totalHits.
-	size := c.topDocsSize()
+	size := c.self.topDocsSize()
 
 	// Don't bother to throw an exception, just return an empty TopDocs in case
 	// the parameters are invalid or out of range.
 	// TODO: shouldn't we throw IAE if apps give bad params here so they dont
 	// have sneaky silent bugs?
 	if start < 0 || start >= size || howMany <= 0 {
-		return c.newTopDocs(nil, start)
+		return c.self.newTopDocs(nil, start)
 	}
 
 	// We know that start < pqsize, so just fix howMany.
 	if size-start < howMany {
 		howMany = size - start
 	}
-	results := make([]ScoreDoc, howMany)
+	results := make([]*ScoreDoc, howMany)
 
 	// pq's pop() returns the 'least' element in the queue, therefore need
 	// to discard the first ones, until we reach the requested range.
@@ -115,109 +414,166 @@ func (c *TopDocsCollector) TopDocsRange(start, howMany int) TopDocs {
 	}
 
 	// Get the requested results from pq.
-	c.populateResults(results, howMany)
+	c.self.populateResults(results, howMany)
 
-	return c.newTopDocs(results, start)
+	return c.self.newTopDocs(results, start)
 }
 
+// search/TopScoreDocCollector.java
+
+/*
+A Collector implementation that collects the top-scoring hits,
+returning them as a TopDocs. This is used by IndexSearcher to
+implement TopDocs-based search. Hits are sorted by score descending
+and then (when the scores are tied) docID ascending. When you create
+an instance of this collector you should know in advance whether
+documents are going to be collected in doc Id order or not.
+
+NOTE: The values -Inf and NaN are not valid scores. This collector
+will not properly collect hits with such scores.
+*/
 type TopScoreDocCollector struct {
 	*TopDocsCollector
 	pqTop   *ScoreDoc
 	docBase int
 	scorer  Scorer
+	inOrder bool
+	// the last hit of the previous page, or nil
+	after *ScoreDoc
+	// after.Doc, relative to the current segment
+	afterDoc int
+	// the hits after the previous page
+	collectedHits int
 }
 
-func newTocScoreDocCollector(numHits int) *TopScoreDocCollector {
-	docs := make([]interface{}, numHits)
-	for i, _ := range docs {
-		docs[i] = ScoreDoc{-math.MaxFloat32, math.MaxInt32}
-	}
-	pq := &PriorityQueue{items: docs}
-	pq.less = func(i, j int) bool {
-		hitA := pq.items[i].(*ScoreDoc)
-		hitB := pq.items[j].(*ScoreDoc)
-		if hitA.score == hitB.score {
-			return hitA.doc > hitB.doc
-		}
-		return hitA.score < hitB.score
+/*
+Creates a new TopScoreDocCollector given the number of hits to
+collect and whether documents are scored in order by the input Scorer
+to SetScorer().
+
+NOTE: The instances returned by this method pre-allocate a full array
+of length numHits, and fill the array with sentinel objects.
+*/
+func NewTopScoreDocCollector(numHits int, docsScoredInOrder bool) (*TopScoreDocCollector, error) {
+	return NewTopScoreDocCollectorAfter(numHits, nil, docsScoredInOrder)
+}
+
+/*
+Creates a new TopScoreDocCollector given the number of hits to
+collect, the bottom of the previous page, and whether documents are
+scored in order by the input Scorer to SetScorer().
+
+Only the hits which rank after the hit after are collected, so the
+next page of hits is returned, and after may be nil for the first
+page.
+
+NOTE: The instances returned by this method pre-allocate a full array
+of length numHits, and fill the array with sentinel objects.
+*/
+func NewTopScoreDocCollectorAfter(numHits int, after *ScoreDoc, docsScoredInOrder bool) (*TopScoreDocCollector, error) {
+	if numHits <= 0 {
+		return nil, errors.New("numHits must be > 0; please use TotalHitCountCollector if you just need the total hit count")
 	}
+	pq := newHitQueue(numHits)
 	heap.Init(pq)
+	ans := &TopScoreDocCollector{pqTop: (*pq)[0], inOrder: docsScoredInOrder, after: after}
+	if after != nil {
+		ans.afterDoc = after.Doc
+	}
+	ans.TopDocsCollector = newTopDocsCollector(ans, pq)
+	return ans, nil
+}
 
-	pqTop := heap.Pop(pq).(*ScoreDoc)
-	heap.Push(pq, pqTop)
-	c := &TopScoreDocCollector{pqTop: pqTop}
-	c.TopDocsCollector = newTopDocsCollector(c, pq)
-	return c
+func (c *TopScoreDocCollector) topDocsSize() int {
+	if c.after == nil {
+		return c.TopDocsCollector.topDocsSize()
+	}
+	if n := c.pq.Len(); c.collectedHits >= n {
+		return n
+	}
+	return c.collectedHits
 }
 
-func (c *TopScoreDocCollector) newTopDocs(results []ScoreDoc, start int) TopDocs {
+func (c *TopScoreDocCollector) newTopDocs(results []*ScoreDoc, start int) TopDocs {
+	if c.after != nil {
+		// the hits of a page don't know the max score
+		if results == nil {
+			return TopDocs{c.totalHits, []*ScoreDoc{}, float32(math.NaN())}
+		}
+		return TopDocs{c.totalHits, results, float32(math.NaN())}
+	}
 	if results == nil {
-		return TopDocs{0, []ScoreDoc{}, math.NaN()}
+		return TopDocs{0, nil, float32(math.NaN())}
 	}
 
 	// We need to compute maxScore in order to set it in TopDocs. If start == 0,
 	// it means the largest element is already in results, use its score as
 	// maxScore. Otherwise pop everything else, until the largest element is
 	// extracted and use its score as maxScore.
-	maxScore := math.NaN()
+	var maxScore float32
 	if start == 0 {
-		maxScore = results[0].score
+		maxScore = results[0].Score
 	} else {
-		pq := c.TopDocsCollector.pq
-		for i := pq.Len(); i > 1; i-- {
-			heap.Pop(pq)
+		for i := c.pq.Len(); i > 1; i-- {
+			heap.Pop(c.pq)
 		}
-		maxScore = heap.Pop(pq).(*ScoreDoc).score
+		maxScore = heap.Pop(c.pq).(*ScoreDoc).Score
 	}
 
-	return TopDocs{c.TopDocsCollector.TotalHits, results, maxScore}
+	return TopDocs{c.totalHits, results, maxScore}
 }
 
-func (c *TopScoreDocCollector) SetNextReader(ctx index.AtomicReaderContext) {
+func (c *TopScoreDocCollector) SetNextReader(ctx index.AtomicReaderContext) error {
 	c.docBase = ctx.DocBase
-}
-
-func NewTopScoreDocCollector(numHits int, after ScoreDoc, docsScoredInOrder bool) *TopDocsCollector {
-	if numHits < 0 {
-		panic("numHits must be > 0; please use TotalHitCountCollector if you just need the total hit count")
+	if c.after != nil {
+		c.afterDoc = c.after.Doc - ctx.DocBase
 	}
-
-	if docsScoredInOrder {
-		return NewInOrderTopScoreDocCollector(numHits).TopScoreDocCollector.TopDocsCollector.Collector.(*TopDocsCollector)
-		// TODO support paging
-	} else {
-		panic("not supported yet")
-	}
-}
-
-type InOrderTopScoreDocCollector struct {
-	*TopScoreDocCollector
+	return nil
 }
 
-func NewInOrderTopScoreDocCollector(numHits int) *InOrderTopScoreDocCollector {
-	return &InOrderTopScoreDocCollector{newTocScoreDocCollector(numHits)}
+func (c *TopScoreDocCollector) SetScorer(scorer Scorer) {
+	c.scorer = scorer
 }
 
-func (c *InOrderTopScoreDocCollector) Collect(doc int) {
+func (c *TopScoreDocCollector) Collect(doc int) error {
 	score := c.scorer.Score()
 
 	// This collector cannot handle these scores:
 	// assert score != -math.MaxFloat64
 	// assert !math.IsNaN(score)
 
-	c.TotalHits++
-	if score <= c.pqTop.score {
-		// Since docs are returned in-order (i.e., increasing doc Id), a document
-		// with equal score to pqTop.score cannot compete since HitQueue favors
-		// documents with lower doc Ids. Therefore reject those docs too.
-		return
+	c.totalHits++
+	if c.after != nil {
+		if score > c.after.Score || score == c.after.Score && doc <= c.afterDoc {
+			// hit was collected on a previous page
+			return nil
+		}
+	}
+	if c.inOrder {
+		if score <= c.pqTop.Score {
+			// Since docs are returned in-order (i.e., increasing doc Id), a document
+			// with equal score to pqTop.score cannot compete since HitQueue favors
+			// documents with lower doc Ids. Therefore reject those docs too.
+			return nil
+		}
+	} else {
+		if score < c.pqTop.Score {
+			// Doesn't compete w/ bottom entry in queue
+			return nil
+		}
+		if score == c.pqTop.Score && doc+c.docBase > c.pqTop.Doc {
+			// Break tie in score by doc ID:
+			return nil
+		}
 	}
-	c.pqTop.doc = doc + c.docBase
-	c.pqTop.score = score
-	heap.Pop(c.pq)
-	heap.Push(c.pq, c.pqTop)
+	c.collectedHits++
+	c.pqTop.Doc = doc + c.docBase
+	c.pqTop.Score = score
+	heap.Fix(c.pq, 0)
+	c.pqTop = (*c.pq.(*hitQueue))[0]
+	return nil
 }
 
-func (c *InOrderTopScoreDocCollector) AcceptsDocsOutOfOrder() bool {
-	return false
+func (c *TopScoreDocCollector) AcceptsDocsOutOfOrder() bool {
+	return !c.inOrder
 }
@@ -0,0 +1,126 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// A Scorer which only scores, as set by the test.
+type fixedScorer struct {
+	Scorer
+	score float32
+}
+
+func (s *fixedScorer) Score() float32 { return s.score }
+
+// Collects the docs of the scores, in the given order, and returns the
+// top hits as "doc:score".
+func collectTopScoreDocs(t *testing.T, c *TopScoreDocCollector, scores []float32, order []int) string {
+	scorer := new(fixedScorer)
+	c.SetScorer(scorer)
+	for _, doc := range order {
+		scorer.score = scores[doc]
+		if err := c.Collect(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	docs := c.TopDocs()
+	ans := make([]string, len(docs.ScoreDocs))
+	for i, hit := range docs.ScoreDocs {
+		ans[i] = fmt.Sprintf("%v:%v", hit.Doc, hit.Score)
+	}
+	return fmt.Sprint(docs.TotalHits, ans)
+}
+
+func TestTopScoreDocCollector(t *testing.T) {
+	scores := []float32{1, 3, 2, 3, 1, 2, 3}
+	inOrder := []int{0, 1, 2, 3, 4, 5, 6}
+	outOfOrder := []int{6, 4, 5, 3, 0, 2, 1}
+	for _, v := range []struct {
+		numHits int
+		after   *ScoreDoc
+		hits    string
+	}{
+		{3, nil, "7 [1:3 3:3 6:3]"},
+		{4, nil, "7 [1:3 3:3 6:3 2:2]"},
+		{10, nil, "7 [1:3 3:3 6:3 2:2 5:2 0:1 4:1]"},
+		{3, NewScoreDoc(3, 3), "7 [6:3 2:2 5:2]"},
+		{10, NewScoreDoc(5, 2), "7 [0:1 4:1]"},
+		{10, NewScoreDoc(4, 1), "7 []"},
+	} {
+		for _, order := range [][]int{inOrder, outOfOrder} {
+			docsInOrder := &order[0] == &inOrder[0]
+			c, err := NewTopScoreDocCollectorAfter(v.numHits, v.after, docsInOrder)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEquals(t, !docsInOrder, c.AcceptsDocsOutOfOrder())
+			assertEquals(t, v.hits, collectTopScoreDocs(t, c, scores, order))
+		}
+	}
+
+	if _, err := NewTopScoreDocCollector(0, true); err == nil {
+		t.Error("Expected error for no hit to collect")
+	}
+}
+
+func TestSearchAfter(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a", "a a", "a b", "a a a", "b a", "a", "c", "a b a")
+	defer cleanup()
+
+	q := newBodyTermQuery("a")
+	all, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 7, len(all.ScoreDocs))
+
+	var after *ScoreDoc
+	for i := 0; i < len(all.ScoreDocs); i += 3 {
+		page, err := ss.SearchAfter(after, q, nil, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, 7, page.TotalHits)
+		if after != nil { // a page doesn't know the max score
+			assertEquals(t, true, math.IsNaN(float64(page.MaxScore)))
+		}
+		for j, hit := range page.ScoreDocs {
+			assertEquals(t, all.ScoreDocs[i+j].Doc, hit.Doc)
+			assertEquals(t, all.ScoreDocs[i+j].Score, hit.Score)
+		}
+		after = page.ScoreDocs[len(page.ScoreDocs)-1]
+	}
+	last, err := ss.SearchAfter(after, q, nil, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, len(last.ScoreDocs))
+
+	if _, err = ss.SearchAfter(NewScoreDoc(8, 1), q, nil, 3); err == nil {
+		t.Error("Expected error for after.Doc beyond maxDoc")
+	}
+}
+
+func TestMergeTopDocs(t *testing.T) {
+	shards := []TopDocs{
+		{3, []*ScoreDoc{NewScoreDoc(2, 4), NewScoreDoc(0, 2), NewScoreDoc(1, 1)}, 4},
+		{0, nil, float32(math.NaN())},
+		{5, []*ScoreDoc{NewScoreDoc(3, 5), NewScoreDoc(0, 2), NewScoreDoc(4, 2)}, 5},
+	}
+	merged := MergeTopDocs(5, shards)
+	assertEquals(t, 8, merged.TotalHits)
+	assertEquals(t, float32(5), merged.MaxScore)
+	var hits []string
+	for _, hit := range merged.ScoreDocs {
+		hits = append(hits, fmt.Sprintf("%v/%v:%v", hit.ShardIndex, hit.Doc, hit.Score))
+	}
+	// ties are broken by shard, then by the rank in the shard
+	assertEquals(t, "[2/3:5 0/2:4 0/0:2 2/0:2 2/4:2]", fmt.Sprint(hits))
+
+	assertEquals(t, 6, len(MergeTopDocs(10, shards).ScoreDocs))
+	empty := MergeTopDocs(10, shards[1:2])
+	assertEquals(t, 0, len(empty.ScoreDocs))
+	assertEquals(t, true, math.IsNaN(float64(empty.MaxScore)))
+}
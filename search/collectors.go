@@ -0,0 +1,211 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+)
+
+// search/TotalHitCountCollector.java
+
+// Just counts the total number of hits.
+type TotalHitCountCollector struct {
+	totalHits int
+}
+
+func NewTotalHitCountCollector() *TotalHitCountCollector {
+	return new(TotalHitCountCollector)
+}
+
+// Returns how many hits matched the search.
+func (c *TotalHitCountCollector) TotalHits() int {
+	return c.totalHits
+}
+
+func (c *TotalHitCountCollector) SetScorer(s Scorer) {}
+
+func (c *TotalHitCountCollector) Collect(doc int) error {
+	c.totalHits++
+	return nil
+}
+
+func (c *TotalHitCountCollector) SetNextReader(ctx index.AtomicReaderContext) error {
+	return nil
+}
+
+func (c *TotalHitCountCollector) AcceptsDocsOutOfOrder() bool {
+	return true
+}
+
+// search/MultiCollector.java
+
+/*
+A Collector which allows running a search with several Collectors. It
+offers a static WrapCollectors() method which accepts a list of
+collectors and wraps them with MultiCollector, while filtering out
+the nil ones.
+*/
+type MultiCollector struct {
+	collectors []Collector
+}
+
+/*
+Wraps a list of Collectors with a MultiCollector. This method works
+as follows:
+
+  - Filters out the nil collectors, so they are not used during
+    search time.
+  - If the input contains 1 non-nil collector, it is returned.
+  - Otherwise the method returns a MultiCollector which wraps the
+    non-nil ones.
+
+It panics if either 0 collectors were input, or all collectors are
+nil.
+*/
+func WrapCollectors(collectors ...Collector) Collector {
+	var nonNil []Collector
+	for _, c := range collectors {
+		if c != nil {
+			nonNil = append(nonNil, c)
+		}
+	}
+	switch len(nonNil) {
+	case 0:
+		panic("At least 1 collector must not be nil")
+	case 1:
+		return nonNil[0]
+	}
+	return &MultiCollector{nonNil}
+}
+
+// Returns the wrapped collectors.
+func (c *MultiCollector) Collectors() []Collector {
+	return c.collectors
+}
+
+// Accepts the docs out of order only if all the wrapped collectors
+// do.
+func (c *MultiCollector) AcceptsDocsOutOfOrder() bool {
+	for _, sub := range c.collectors {
+		if !sub.AcceptsDocsOutOfOrder() {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *MultiCollector) Collect(doc int) error {
+	for _, sub := range c.collectors {
+		if err := sub.Collect(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *MultiCollector) SetNextReader(ctx index.AtomicReaderContext) error {
+	for _, sub := range c.collectors {
+		if err := sub.SetNextReader(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *MultiCollector) SetScorer(s Scorer) {
+	for _, sub := range c.collectors {
+		sub.SetScorer(s)
+	}
+}
+
+// search/PositiveScoresOnlyCollector.java
+
+/*
+A Collector implementation which wraps another Collector and makes
+sure only documents with scores > 0 are collected.
+*/
+type PositiveScoresOnlyCollector struct {
+	c      Collector
+	scorer Scorer
+}
+
+func NewPositiveScoresOnlyCollector(c Collector) *PositiveScoresOnlyCollector {
+	return &PositiveScoresOnlyCollector{c: c}
+}
+
+func (c *PositiveScoresOnlyCollector) Collect(doc int) error {
+	if c.scorer.Score() > 0 {
+		return c.c.Collect(doc)
+	}
+	return nil
+}
+
+func (c *PositiveScoresOnlyCollector) SetNextReader(ctx index.AtomicReaderContext) error {
+	return c.c.SetNextReader(ctx)
+}
+
+func (c *PositiveScoresOnlyCollector) SetScorer(s Scorer) {
+	// Set a ScoreCachingWrappingScorer in case the wrapped Collector
+	// will call Score() also.
+	c.scorer = NewScoreCachingWrappingScorer(s)
+	c.c.SetScorer(c.scorer)
+}
+
+func (c *PositiveScoresOnlyCollector) AcceptsDocsOutOfOrder() bool {
+	return c.c.AcceptsDocsOutOfOrder()
+}
+
+// search/ScoreCachingWrappingScorer.java
+
+/*
+A Scorer which wraps another scorer and caches the score of the
+current document. Successive calls to Score() will return the same
+result and will not invoke the wrapped Scorer's Score() method,
+unless the current document has changed.
+
+This class might be useful due to the changes done to the Collector
+interface, in which the score is not computed for a document by
+default, only if the collector requests it. Some collectors may need
+to use the score in several places, however all they have in hand is
+a Scorer object, and might end up computing the score of a document
+more than once.
+*/
+type ScoreCachingWrappingScorer struct {
+	*ScorerImpl
+	scorer   Scorer
+	curDoc   int
+	curScore float32
+}
+
+// Creates a new instance by wrapping the given scorer.
+func NewScoreCachingWrappingScorer(scorer Scorer) *ScoreCachingWrappingScorer {
+	ans := &ScoreCachingWrappingScorer{scorer: scorer, curDoc: -1}
+	ans.ScorerImpl = NewScorer(ans, scorer.Weight())
+	return ans
+}
+
+func (s *ScoreCachingWrappingScorer) Score() float32 {
+	if doc := s.scorer.DocId(); doc != s.curDoc {
+		s.curScore = s.scorer.Score()
+		s.curDoc = doc
+	}
+	return s.curScore
+}
+
+func (s *ScoreCachingWrappingScorer) DocId() int {
+	return s.scorer.DocId()
+}
+
+func (s *ScoreCachingWrappingScorer) Freq() int {
+	return s.scorer.Freq()
+}
+
+func (s *ScoreCachingWrappingScorer) NextDoc() (int, bool) {
+	return s.scorer.NextDoc()
+}
+
+func (s *ScoreCachingWrappingScorer) Advance(target int) (int, bool) {
+	return s.scorer.Advance(target)
+}
+
+func (s *ScoreCachingWrappingScorer) Cost() int64 {
+	return s.scorer.Cost()
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"testing"
+)
+
+func TestTotalHitCountCollector(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a")
+	defer cleanup()
+
+	c := NewTotalHitCountCollector()
+	if err := ss.SearchCollector(newBodyTermQuery("a"), c); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, c.TotalHits())
+}
+
+func TestMultiCollector(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a")
+	defer cleanup()
+
+	counter := NewTotalHitCountCollector()
+	assertEquals(t, Collector(counter), WrapCollectors(nil, counter, nil))
+
+	recorder := new(recordingCollector)
+	c := WrapCollectors(counter, nil, recorder)
+	mc, ok := c.(*MultiCollector)
+	assertEquals(t, true, ok)
+	assertEquals(t, 2, len(mc.Collectors()))
+	// the recorder needs the docs in order
+	assertEquals(t, false, c.AcceptsDocsOutOfOrder())
+
+	if err := ss.SearchCollector(newBodyTermQuery("b"), c); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, counter.TotalHits())
+	assertEquals(t, "[0 1 2]", fmt.Sprint(recorder.docs))
+}
+
+func TestWrapNoCollectors(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for nil collectors")
+		}
+	}()
+	WrapCollectors(nil, nil)
+}
+
+// Counts the calls to Score(), for the current doc.
+type scoreCountingScorer struct {
+	Scorer
+	doc    int
+	scores []float32
+	calls  int
+}
+
+func (s *scoreCountingScorer) DocId() int { return s.doc }
+
+func (s *scoreCountingScorer) Score() float32 {
+	s.calls++
+	return s.scores[s.doc]
+}
+
+func (s *scoreCountingScorer) Weight() Weight { return nil }
+
+func TestPositiveScoresOnlyCollector(t *testing.T) {
+	scorer := &scoreCountingScorer{scores: []float32{2, 0, -1, 3, 0.5}}
+	recorder := new(recordingCollector)
+	c := NewPositiveScoresOnlyCollector(recorder)
+	c.SetScorer(scorer)
+	if err := c.SetNextReader(index.AtomicReaderContext{}); err != nil {
+		t.Fatal(err)
+	}
+	for doc := range scorer.scores {
+		scorer.doc = doc
+		if err := c.Collect(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertEquals(t, "[0 3 4]", fmt.Sprint(recorder.docs))
+	assertEquals(t, "[2 3 0.5]", fmt.Sprint(recorder.scores))
+	// the recorder reuses the cached scores
+	assertEquals(t, 5, scorer.calls)
+}
+
+func TestScoreCachingWrappingScorer(t *testing.T) {
+	scorer := &scoreCountingScorer{scores: []float32{1, 2}}
+	s := NewScoreCachingWrappingScorer(scorer)
+	assertEquals(t, float32(1), s.Score())
+	assertEquals(t, float32(1), s.Score())
+	assertEquals(t, 1, scorer.calls)
+	scorer.doc = 1
+	assertEquals(t, float32(2), s.Score())
+	assertEquals(t, float32(2), s.Score())
+	assertEquals(t, 2, scorer.calls)
+}
@@ -0,0 +1,117 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"sort"
+)
+
+// search/ConjunctionScorer.java
+
+/*
+Scorer for conjunctions, sets of queries, all of which are required.
+
+The scorers are sorted by their cost, so the cheapest one leads the
+matching, and the others leap-frog to its documents by Advance(),
+which uses the skip data of the postings.
+*/
+type ConjunctionScorer struct {
+	*ScorerImpl
+	lastDoc int
+	// the scorers by increasing cost
+	scorers []Scorer
+	// the docs of the scorers, which lead the matching
+	docs  []int
+	coord float32
+}
+
+func newConjunctionScorer(w Weight, scorers []Scorer, coord float32) *ConjunctionScorer {
+	ans := &ConjunctionScorer{
+		lastDoc: -1,
+		scorers: append([]Scorer(nil), scorers...),
+		docs:    make([]int, len(scorers)),
+		coord:   coord,
+	}
+	for i := range ans.docs {
+		ans.docs[i] = -1
+	}
+	// Sort the scorers the first time to allow the least frequent one
+	// to lead the matching.
+	sort.Stable(scorersByCost(ans.scorers))
+	ans.ScorerImpl = NewScorer(ans, w)
+	return ans
+}
+
+// Advances the scorers, other than the lead one, to doc. As soon as
+// one of them is beyond it, the lead one advances to its doc, and so
+// on, until all of them are on the same doc.
+func (s *ConjunctionScorer) doNext(doc int) int {
+	for {
+		// doc may already be NO_MORE_DOCS here, but we don't check
+		// that explicitly, as the others then advance to
+		// NO_MORE_DOCS too, which is the match returned.
+		advanceHead := false
+		for i := 1; i < len(s.scorers); i++ {
+			// invariant: s.docs[i] <= doc at this point.
+
+			// s.docs[i] may already be equal to doc if we advanced the
+			// head on the previous iteration and it exactly matched.
+			if s.docs[i] < doc {
+				s.docs[i], _ = s.scorers[i].Advance(doc)
+				if s.docs[i] > doc {
+					// scorer beyond the current doc - break and advance
+					// lead to the new highest doc.
+					doc = s.docs[i]
+					advanceHead = true
+					break
+				}
+			}
+		}
+		if !advanceHead {
+			// success - all scorers are on the same doc
+			return doc
+		}
+		// advance head for next iteration
+		doc, _ = s.scorers[0].Advance(doc)
+		s.docs[0] = doc
+	}
+}
+
+func (s *ConjunctionScorer) Advance(target int) (int, bool) {
+	s.docs[0], _ = s.scorers[0].Advance(target)
+	s.lastDoc = s.doNext(s.docs[0])
+	return s.lastDoc, s.lastDoc != index.NO_MORE_DOCS
+}
+
+func (s *ConjunctionScorer) DocId() int {
+	return s.lastDoc
+}
+
+func (s *ConjunctionScorer) NextDoc() (int, bool) {
+	s.docs[0], _ = s.scorers[0].NextDoc()
+	s.lastDoc = s.doNext(s.docs[0])
+	return s.lastDoc, s.lastDoc != index.NO_MORE_DOCS
+}
+
+func (s *ConjunctionScorer) Score() float32 {
+	var sum float32
+	for _, scorer := range s.scorers {
+		sum += scorer.Score()
+	}
+	return sum * s.coord
+}
+
+func (s *ConjunctionScorer) Freq() int {
+	return len(s.scorers)
+}
+
+// A match needs all the scorers, so the lead one bounds the cost.
+func (s *ConjunctionScorer) Cost() int64 {
+	return s.scorers[0].Cost()
+}
+
+// Scorers sorted by increasing cost.
+type scorersByCost []Scorer
+
+func (a scorersByCost) Len() int           { return len(a) }
+func (a scorersByCost) Less(i, j int) bool { return a[i].Cost() < a[j].Cost() }
+func (a scorersByCost) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
@@ -0,0 +1,145 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+)
+
+// search/ConstantScoreAutoRewrite.java
+
+// Defaults derived from rough tests with a 20.0 million doc Wikipedia
+// index. With more than 350 terms in the query, the filter method is
+// fastest:
+const CONSTANT_SCORE_AUTO_DEFAULT_TERM_COUNT_CUTOFF = 350
+
+// If the query will hit more than 1 in 1000 of the docs in the index
+// (0.1%), the filter method is fastest:
+const CONSTANT_SCORE_AUTO_DEFAULT_DOC_COUNT_PERCENT = 0.1
+
+/*
+A rewrite method that tries to pick the best constant-score rewrite
+method based on term and document counts from the query. If both the
+number of terms and documents is small enough, then
+CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE is used. Otherwise,
+CONSTANT_SCORE_FILTER_REWRITE is used.
+*/
+type ConstantScoreAutoRewrite struct {
+	termCountCutoff int
+	docCountPercent float64
+	readOnly        bool
+}
+
+func NewConstantScoreAutoRewrite() *ConstantScoreAutoRewrite {
+	return &ConstantScoreAutoRewrite{
+		termCountCutoff: CONSTANT_SCORE_AUTO_DEFAULT_TERM_COUNT_CUTOFF,
+		docCountPercent: CONSTANT_SCORE_AUTO_DEFAULT_DOC_COUNT_PERCENT,
+	}
+}
+
+/*
+Read-only default instance of ConstantScoreAutoRewrite, with
+CONSTANT_SCORE_AUTO_DEFAULT_TERM_COUNT_CUTOFF and
+CONSTANT_SCORE_AUTO_DEFAULT_DOC_COUNT_PERCENT. Use
+NewConstantScoreAutoRewrite() for other cutoffs.
+*/
+var CONSTANT_SCORE_AUTO_REWRITE_DEFAULT = RewriteMethod(&ConstantScoreAutoRewrite{
+	termCountCutoff: CONSTANT_SCORE_AUTO_DEFAULT_TERM_COUNT_CUTOFF,
+	docCountPercent: CONSTANT_SCORE_AUTO_DEFAULT_DOC_COUNT_PERCENT,
+	readOnly:        true,
+})
+
+/*
+If the number of terms in this query is equal to or larger than this
+setting then CONSTANT_SCORE_FILTER_REWRITE is used.
+*/
+func (rw *ConstantScoreAutoRewrite) SetTermCountCutoff(count int) {
+	if rw.readOnly {
+		panic("Please create a private instance")
+	}
+	rw.termCountCutoff = count
+}
+
+func (rw *ConstantScoreAutoRewrite) TermCountCutoff() int {
+	return rw.termCountCutoff
+}
+
+/*
+If the number of documents to be visited in the postings exceeds this
+specified percentage of the MaxDoc() for the index, then
+CONSTANT_SCORE_FILTER_REWRITE is used.
+*/
+func (rw *ConstantScoreAutoRewrite) SetDocCountPercent(percent float64) {
+	if rw.readOnly {
+		panic("Please create a private instance")
+	}
+	rw.docCountPercent = percent
+}
+
+func (rw *ConstantScoreAutoRewrite) DocCountPercent() float64 {
+	return rw.docCountPercent
+}
+
+func (rw *ConstantScoreAutoRewrite) Rewrite(r index.IndexReader, q *MultiTermQuery) (Query, error) {
+	// Get the enum and start visiting terms. If we exhaust the enum
+	// before hitting either of the cutoffs, we use
+	// ConstantBooleanQueryRewrite; else, ConstantFilterRewrite:
+	docCountCutoff := int(rw.docCountPercent / 100 * float64(r.MaxDoc()))
+	termCountLimit := rw.termCountCutoff
+	if n := MaxClauseCount(); n < termCountLimit {
+		termCountLimit = n
+	}
+	col := &cutOffTermCollector{
+		scoringTermCollector: newScoringTermCollector(),
+		docCountCutoff:       docCountCutoff,
+		termCountLimit:       termCountLimit,
+	}
+	if err := collectTerms(r, q, col); err != nil {
+		return nil, err
+	}
+	if col.hasCutOff {
+		return CONSTANT_SCORE_FILTER_REWRITE.Rewrite(r, q)
+	}
+	bq, err := col.booleanQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	if len(bq.Clauses()) == 0 {
+		return bq, nil
+	}
+	// strip scores
+	result := NewConstantScoreQuery(bq)
+	result.SetBoost(q.boost)
+	return result, nil
+}
+
+func (rw *ConstantScoreAutoRewrite) String() string {
+	if rw.readOnly {
+		return "CONSTANT_SCORE_AUTO_REWRITE_DEFAULT"
+	}
+	return fmt.Sprintf("ConstantScoreAutoRewrite(termCountCutoff=%v, docCountPercent=%v)",
+		rw.termCountCutoff, rw.docCountPercent)
+}
+
+/*
+Collects the terms until either the number of the terms, or of the
+documents to visit, reaches its limit.
+*/
+type cutOffTermCollector struct {
+	*scoringTermCollector
+	docCountCutoff, termCountLimit int
+	docVisitCount                  int
+	hasCutOff                      bool
+}
+
+func (c *cutOffTermCollector) collect(term []byte) (bool, error) {
+	size := len(c.terms)
+	if _, ok := c.ords[string(term)]; !ok {
+		size++
+	}
+	c.docVisitCount += c.termsEnum.DocFreq()
+	if size >= c.termCountLimit || c.docVisitCount >= c.docCountCutoff {
+		c.hasCutOff = true
+		return false, nil
+	}
+	return c.scoringTermCollector.collect(term)
+}
@@ -0,0 +1,251 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/ConstantScoreQuery.java
+
+/*
+A query that wraps another query or a filter and simply returns a
+constant score equal to the query boost for every document that
+matches the filter or query. For queries it therefore simply strips
+of all scores and returns a constant one.
+*/
+type ConstantScoreQuery struct {
+	*AbstractQuery
+	filter Filter
+	query  Query
+}
+
+/*
+Strips off scores from the passed in Query. The hits will get a
+constant score dependent on the boost factor of this query.
+*/
+func NewConstantScoreQuery(query Query) *ConstantScoreQuery {
+	if query == nil {
+		panic("Query may not be null")
+	}
+	ans := &ConstantScoreQuery{query: query}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+/*
+Wraps a Filter as a Query. The hits will get a constant score
+dependent on the boost factor of this query. If you simply want to
+filter, use FilteredQuery instead.
+*/
+func NewConstantScoreQueryWithFilter(filter Filter) *ConstantScoreQuery {
+	if filter == nil {
+		panic("Filter may not be null")
+	}
+	ans := &ConstantScoreQuery{filter: filter}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+// Returns the encapsulated filter, returns nil if a query is wrapped.
+func (q *ConstantScoreQuery) Filter() Filter {
+	return q.filter
+}
+
+// Returns the encapsulated query, returns nil if a filter is wrapped.
+func (q *ConstantScoreQuery) Query() Query {
+	return q.query
+}
+
+func (q *ConstantScoreQuery) Rewrite(r index.IndexReader) (Query, error) {
+	if q.query != nil {
+		rewritten, err := q.query.Rewrite(r)
+		if err != nil {
+			return nil, err
+		}
+		if rewritten != q.query {
+			ans := NewConstantScoreQuery(rewritten)
+			ans.SetBoost(q.boost)
+			return ans, nil
+		}
+	}
+	return q, nil
+}
+
+func (q *ConstantScoreQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	ans := &constantWeight{query: q}
+	if q.query != nil {
+		var err error
+		if ans.innerWeight, err = q.query.CreateWeight(ss); err != nil {
+			return nil, err
+		}
+	}
+	return ans, nil
+}
+
+func (q *ConstantScoreQuery) ToString(field string) string {
+	inner := fmt.Sprint(q.filter)
+	if q.query != nil {
+		inner = q.query.ToString(field)
+	}
+	return fmt.Sprintf("ConstantScore(%v)%v", inner, boostString(q.boost))
+}
+
+// search/ConstantScoreQuery.java/ConstantWeight
+
+type constantWeight struct {
+	query       *ConstantScoreQuery
+	innerWeight Weight
+	queryNorm   float32
+	queryWeight float32
+}
+
+func (w *constantWeight) Query() Query {
+	return w.query
+}
+
+func (w *constantWeight) ValueForNormalization() float32 {
+	// we calculate sumOfSquaredWeights of the inner weight, but ignore
+	// it (just to initialize everything)
+	if w.innerWeight != nil {
+		w.innerWeight.ValueForNormalization()
+	}
+	w.queryWeight = w.query.boost
+	return w.queryWeight * w.queryWeight
+}
+
+func (w *constantWeight) Normalize(norm float32, topLevelBoost float32) {
+	w.queryNorm = norm * topLevelBoost
+	w.queryWeight *= w.queryNorm
+	// we normalize the inner weight, but ignore it (just to initialize
+	// everything)
+	if w.innerWeight != nil {
+		w.innerWeight.Normalize(norm, topLevelBoost)
+	}
+}
+
+func (w *constantWeight) Scorer(ctx index.AtomicReaderContext,
+	scoreDocsInOrder, topScorer bool, acceptDocs util.Bits) (Scorer, error) {
+	var disi index.DocIdSetIterator
+	if w.query.filter != nil {
+		dis, err := w.query.filter.DocIdSet(ctx, acceptDocs)
+		if dis == nil || err != nil {
+			// this means the filter does not accept any documents.
+			return nil, err
+		}
+		if disi, err = dis.Iterator(); err != nil {
+			return nil, err
+		}
+	} else {
+		scorer, err := w.innerWeight.Scorer(ctx, scoreDocsInOrder, topScorer, acceptDocs)
+		if err != nil {
+			return nil, err
+		}
+		if scorer != nil {
+			disi = scorer
+		}
+	}
+	if disi == nil {
+		return nil, nil
+	}
+	return newConstantScorer(disi, w, w.queryWeight), nil
+}
+
+func (w *constantWeight) Explain(ctx index.AtomicReaderContext, doc int) (*Explanation, error) {
+	cs, err := w.Scorer(ctx, true, false, ctx.Reader().(index.AtomicReader).LiveDocs())
+	if err != nil {
+		return nil, err
+	}
+	exists := false
+	if cs != nil {
+		newDoc, _ := cs.Advance(doc)
+		exists = newDoc == doc
+	}
+
+	if !exists {
+		return NewComplexExplanation(false, 0, fmt.Sprintf("%v doesn't match id %v", w.query, doc)), nil
+	}
+	result := NewComplexExplanation(true, w.queryWeight, fmt.Sprintf("%v, product of:", w.query))
+	result.AddDetail(NewExplanation(w.query.boost, "boost"))
+	result.AddDetail(NewExplanation(w.queryNorm, "queryNorm"))
+	return result, nil
+}
+
+func (w *constantWeight) IsScoresDocsOutOfOrder() bool {
+	if w.innerWeight != nil {
+		return w.innerWeight.IsScoresDocsOutOfOrder()
+	}
+	return false
+}
+
+// search/ConstantScoreQuery.java/ConstantScorer
+
+// A Scorer which gives the documents of an iterator a constant score.
+type constantScorer struct {
+	*ScorerImpl
+	docIdSetIterator index.DocIdSetIterator
+	theScore         float32
+}
+
+func newConstantScorer(docIdSetIterator index.DocIdSetIterator, w Weight, theScore float32) *constantScorer {
+	ans := &constantScorer{docIdSetIterator: docIdSetIterator, theScore: theScore}
+	ans.ScorerImpl = NewScorer(ans, w)
+	return ans
+}
+
+func (s *constantScorer) NextDoc() (int, bool) {
+	return s.docIdSetIterator.NextDoc()
+}
+
+func (s *constantScorer) DocId() int {
+	return s.docIdSetIterator.DocId()
+}
+
+func (s *constantScorer) Score() float32 {
+	return s.theScore
+}
+
+func (s *constantScorer) Freq() int {
+	return 1
+}
+
+func (s *constantScorer) Advance(target int) (int, bool) {
+	return s.docIdSetIterator.Advance(target)
+}
+
+func (s *constantScorer) Cost() int64 {
+	return s.docIdSetIterator.Cost()
+}
+
+// this optimization allows out of order scoring as top scorer!
+func (s *constantScorer) ScoreAndCollect(c LeafCollector) error {
+	if inner, ok := s.docIdSetIterator.(Scorer); ok {
+		return inner.ScoreAndCollect(s.wrapCollector(c))
+	}
+	return s.ScorerImpl.ScoreAndCollect(c)
+}
+
+// this optimization allows out of order scoring as top scorer, too
+func (s *constantScorer) ScoreAndCollectUpTo(c LeafCollector, max, firstDocID int) (bool, error) {
+	if inner, ok := s.docIdSetIterator.(Scorer); ok {
+		return inner.ScoreAndCollectUpTo(s.wrapCollector(c), max, firstDocID)
+	}
+	return s.ScorerImpl.ScoreAndCollectUpTo(c, max, firstDocID)
+}
+
+// Wraps the collector, so it scores the documents of the inner scorer
+// by this scorer.
+func (s *constantScorer) wrapCollector(c LeafCollector) LeafCollector {
+	return &constantScoreCollector{c, s}
+}
+
+type constantScoreCollector struct {
+	LeafCollector
+	scorer *constantScorer
+}
+
+func (c *constantScoreCollector) SetScorer(s Scorer) {
+	// we must wrap again here, but using the scorer passed in as
+	// parameter:
+	c.LeafCollector.SetScorer(newConstantScorer(s, c.scorer.weight, c.scorer.theScore))
+}
@@ -0,0 +1,90 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"testing"
+)
+
+// Returns the scores of the hits of q, by document.
+func hitScores(t *testing.T, ss *IndexSearcher, q Query) map[int]float32 {
+	docs, err := ss.SearchTop(q, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ans := make(map[int]float32)
+	for _, hit := range docs.ScoreDocs {
+		ans[hit.Doc] = hit.Score
+	}
+	return ans
+}
+
+func TestConstantScoreQuery(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+	defer cleanup()
+
+	for _, q := range []*ConstantScoreQuery{
+		NewConstantScoreQuery(newBodyTermQuery("a")),
+		NewConstantScoreQuery(NewPrefixQuery(index.NewTerm("body", "a"))),
+		NewConstantScoreQueryWithFilter(NewQueryWrapperFilter(newBodyTermQuery("a"))),
+		NewConstantScoreQueryWithFilter(NewMultiTermQueryWrapperFilter(
+			NewPrefixQuery(index.NewTerm("body", "a")).MultiTermQuery)),
+	} {
+		// a single clause is normalized to 1, whatever the boost
+		q.SetBoost(3)
+		assertEquals(t, "map[0:1 1:1 3:1]", fmt.Sprint(hitScores(t, ss, q)))
+
+		// the constant score is the boost of the clause
+		bq := newTestBooleanQuery(q, OCCUR_MUST, newBodyTermQuery("c"), OCCUR_SHOULD)
+		scores := hitScores(t, ss, bq)
+		assertEquals(t, 3, len(scores))
+		assertEquals(t, scores[1], scores[3])
+		if scores[0] <= scores[1] {
+			t.Errorf("Expected higher score of the optional clause, but %v", scores)
+		}
+	}
+
+	// no matching documents
+	q := NewConstantScoreQueryWithFilter(NewQueryWrapperFilter(newBodyTermQuery("nosuchterm")))
+	assertEquals(t, "map[]", fmt.Sprint(hitScores(t, ss, q)))
+}
+
+func TestConstantScoreQueryCollector(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+	defer cleanup()
+
+	q := NewConstantScoreQuery(newTestBooleanQuery(
+		newBodyTermQuery("a"), OCCUR_SHOULD, newBodyTermQuery("d"), OCCUR_SHOULD))
+	q.SetBoost(2)
+	c := new(recordingCollector)
+	if err := ss.SearchCollector(q, c); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[0 1 3 4]", fmt.Sprint(c.docs))
+	assertEquals(t, "[1 1 1 1]", fmt.Sprint(c.scores))
+}
+
+func TestConstantScoreQueryRewrite(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+	defer cleanup()
+
+	pq := NewPrefixQuery(index.NewTerm("body", "a"))
+	pq.SetRewriteMethod(SCORING_BOOLEAN_QUERY_REWRITE)
+	q := NewConstantScoreQuery(pq)
+	q.SetBoost(2)
+	rewritten, err := q.Rewrite(ss.IndexReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ConstantScore(body:a)^2", rewritten.ToString(""))
+
+	q = NewConstantScoreQuery(newBodyTermQuery("a"))
+	rewritten, err = q.Rewrite(ss.IndexReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, q, rewritten)
+	assertEquals(t, "ConstantScore(a)", q.ToString("body"))
+	assertEquals(t, "ConstantScore(QueryWrapperFilter(body:a))",
+		NewConstantScoreQueryWithFilter(NewQueryWrapperFilter(newBodyTermQuery("a"))).ToString(""))
+}
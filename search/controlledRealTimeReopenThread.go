@@ -0,0 +1,260 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"math"
+	"sync"
+	"time"
+)
+
+// search/ControlledRealTimeReopenThread.java
+
+/*
+Utility goroutine to periodically reopen the references of a
+ReferenceManager, e.g. a SearcherManager, with different target
+staleness. It starts running when created.
+
+If there are waiting goroutines, i.e. ones that called
+WaitForGeneration() for a generation which isn't visible yet, the
+reopen happens targetMinStale after the previous one; otherwise it
+happens targetMaxStale after. This way searches which need to see
+their own changes get them quickly, while the cost of reopening is
+paid rarely otherwise.
+
+All changes to the index must be done through the TrackingIndexWriter
+which returns the generation of each change, for this goroutine to
+know when they're visible.
+*/
+type ControlledRealTimeReopenThread struct {
+	manager        *ReferenceManager
+	writer         *index.TrackingIndexWriter
+	targetMaxStale time.Duration
+	targetMinStale time.Duration
+
+	lock sync.Mutex // guards the fields below
+	// the highest generation waited for
+	waitingGen int64
+	// the generation visible by the searches
+	searchingGen int64
+	// the generation as of when the running refresh started
+	refreshStartGen int64
+	// closed, and replaced, when searchingGen changes
+	refreshed chan bool
+	finish    bool
+	// the error which stopped the reopens, if any
+	err error
+
+	// wakes the goroutine up, when waitingGen changes or on Close()
+	wake chan bool
+	// closed when the goroutine exits
+	done chan bool
+}
+
+/*
+Create ControlledRealTimeReopenThread, to periodically reopen the
+references of the ReferenceManager.
+
+targetMaxStale is the maximum time until a new reference must be
+opened; this sets the upper bound on how slowly reopens may occur,
+when no caller is waiting for a specific generation to become
+visible.
+
+targetMinStale is the minimum time until a new reference must be
+opened; this sets the lower bound on how quickly reopens may occur,
+when a caller is waiting for a specific generation to become visible.
+*/
+func NewControlledRealTimeReopenThread(writer *index.TrackingIndexWriter, manager *ReferenceManager,
+	targetMaxStale, targetMinStale time.Duration) *ControlledRealTimeReopenThread {
+	if targetMaxStale < targetMinStale {
+		panic(fmt.Sprintf("targetMaxStale (=%v) < targetMinStale (=%v)", targetMaxStale, targetMinStale))
+	}
+	ans := &ControlledRealTimeReopenThread{
+		manager:        manager,
+		writer:         writer,
+		targetMaxStale: targetMaxStale,
+		targetMinStale: targetMinStale,
+		refreshed:      make(chan bool),
+		wake:           make(chan bool, 1),
+		done:           make(chan bool),
+	}
+	manager.AddListener((*handleRefresh)(ans))
+	go ans.run()
+	return ans
+}
+
+// search/ControlledRealTimeReopenThread.java/HandleRefresh
+
+// Tracks the generation of the refreshes, including those not
+// triggered by the goroutine.
+type handleRefresh ControlledRealTimeReopenThread
+
+func (h *handleRefresh) BeforeRefresh() error {
+	t := (*ControlledRealTimeReopenThread)(h)
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	// Save the gen as of when we started the reopen; AfterRefresh()
+	// copies this to searchingGen once the reopen completes:
+	t.refreshStartGen = t.writer.GetAndIncrementGeneration()
+	return nil
+}
+
+func (h *handleRefresh) AfterRefresh(didRefresh bool) error {
+	t := (*ControlledRealTimeReopenThread)(h)
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.setSearchingGen(t.refreshStartGen)
+	return nil
+}
+
+// Sets the visible generation, and wakes the waiting goroutines up.
+// The lock must be held.
+func (t *ControlledRealTimeReopenThread) setSearchingGen(gen int64) {
+	t.searchingGen = gen
+	close(t.refreshed)
+	t.refreshed = make(chan bool)
+}
+
+// Wakes the goroutine up, if it's not being woken up already.
+func (t *ControlledRealTimeReopenThread) wakeUp() {
+	select {
+	case t.wake <- true:
+	default:
+	}
+}
+
+/*
+Stops the reopens, and waits for the goroutine to exit. The goroutines
+waiting for a generation are released. Returns the error which
+stopped the reopens, if any.
+*/
+func (t *ControlledRealTimeReopenThread) Close() error {
+	t.lock.Lock()
+	if t.finish {
+		t.lock.Unlock()
+		<-t.done
+		return t.err
+	}
+	t.finish = true
+	t.lock.Unlock()
+
+	t.wakeUp()
+	<-t.done
+	t.manager.RemoveListener((*handleRefresh)(t))
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	// Max it out so any waiting search goroutines will return:
+	t.setSearchingGen(math.MaxInt64)
+	return t.err
+}
+
+/*
+Waits for the target generation to become visible in the searcher,
+up to maxWait, or forever if maxWait is negative. Returns true if the
+generation became visible, false if it timed out, or if the reopens
+were stopped by an error, which is returned then.
+
+Panics if targetGen is greater than the current generation of the
+TrackingIndexWriter, as it was never returned by it.
+*/
+func (t *ControlledRealTimeReopenThread) WaitForGeneration(targetGen int64, maxWait time.Duration) (bool, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if curGen := t.writer.Generation(); targetGen > curGen {
+		panic(fmt.Sprintf("targetGen=%v was never returned by the ReferenceManager instance (current gen=%v)",
+			targetGen, curGen))
+	}
+	if targetGen <= t.searchingGen {
+		return true, nil
+	}
+
+	// Notify the reopen goroutine that the waitingGen has changed, so
+	// it may wake up and realize it should not sleep for much or any
+	// longer before reopening:
+	if targetGen > t.waitingGen {
+		t.waitingGen = targetGen
+	}
+	t.wakeUp()
+
+	var timeout <-chan time.Time
+	if maxWait >= 0 {
+		timer := time.NewTimer(maxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for targetGen > t.searchingGen {
+		if t.err != nil {
+			return false, t.err
+		}
+		refreshed := t.refreshed
+		t.lock.Unlock()
+		select {
+		case <-refreshed:
+			t.lock.Lock()
+		case <-timeout:
+			t.lock.Lock()
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Returns the generation visible by the searches.
+func (t *ControlledRealTimeReopenThread) SearchingGen() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.searchingGen
+}
+
+func (t *ControlledRealTimeReopenThread) run() {
+	defer close(t.done)
+	lastReopenStart := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		// Loop until we've waited long enough before the next reopen:
+		for {
+			t.lock.Lock()
+			finish := t.finish
+			// True if we have someone waiting for reopened searcher:
+			hasWaiting := t.waitingGen > t.searchingGen
+			t.lock.Unlock()
+			if finish {
+				return
+			}
+
+			stale := t.targetMaxStale
+			if hasWaiting {
+				stale = t.targetMinStale
+			}
+			sleep := lastReopenStart.Add(stale).Sub(time.Now())
+			if sleep <= 0 {
+				break
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(sleep)
+			select {
+			case <-t.wake:
+			case <-timer.C:
+			}
+		}
+
+		lastReopenStart = time.Now()
+		// the refresh listener saves the generation as of when the
+		// reopen starts, and makes it visible once it completes
+		if err := t.manager.MaybeRefreshBlocking(); err != nil {
+			t.lock.Lock()
+			t.err = err
+			// wake the waiting goroutines up, to return the error
+			t.setSearchingGen(t.searchingGen)
+			t.lock.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Adds a document with the given body, returning its generation.
+func addTrackedTestDoc(t *testing.T, w *index.TrackingIndexWriter, body string) int64 {
+	gen, err := w.AddDocument([]document.IndexableField{
+		document.NewTextField("body", body, document.STORE_NO),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gen
+}
+
+func TestControlledRealTimeReopenThread(t *testing.T) {
+	w, cleanup := newTestWriter(t)
+	defer cleanup()
+	tw := index.NewTrackingIndexWriter(w)
+	mgr, err := NewSearcherManagerFromWriter(w, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Close()
+	// without waiting searches, the reopens wouldn't happen during the
+	// test
+	reopen := NewControlledRealTimeReopenThread(tw, mgr.ReferenceManager, time.Hour, 10*time.Millisecond)
+	defer reopen.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gen := addTrackedTestDoc(t, tw, "a")
+			start := time.Now()
+			ok, err := reopen.WaitForGeneration(gen, -1)
+			if err != nil {
+				t.Error(err)
+			}
+			assertEquals(t, true, ok)
+			if elapsed := time.Since(start); elapsed > time.Minute {
+				t.Errorf("Expected a reopen close to targetMinStale, but waited %v", elapsed)
+			}
+			s, err := mgr.Acquire()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer mgr.Release(s)
+			// at least this goroutine's document is visible
+			docs, err := s.SearchTop(newBodyTermQuery("a"), 10)
+			if err != nil {
+				t.Error(err)
+			}
+			if docs.TotalHits < 1 {
+				t.Errorf("Expected the document of generation %v to be visible", gen)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s, err := mgr.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 4, countHits(t, s, "a"))
+	mgr.Release(s)
+	if gen := reopen.SearchingGen(); gen >= tw.Generation() || gen < 1 {
+		t.Errorf("Expected searching gen below %v, but %v", tw.Generation(), gen)
+	}
+
+	// the generation must have been returned by the writer
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic waiting for a future generation")
+			}
+		}()
+		reopen.WaitForGeneration(tw.Generation()+1, 0)
+	}()
+}
+
+func TestControlledRealTimeReopenThreadTimeout(t *testing.T) {
+	w, cleanup := newTestWriter(t)
+	defer cleanup()
+	tw := index.NewTrackingIndexWriter(w)
+	mgr, err := NewSearcherManagerFromWriter(w, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Close()
+	reopen := NewControlledRealTimeReopenThread(tw, mgr.ReferenceManager, time.Hour, time.Hour)
+
+	gen := addTrackedTestDoc(t, tw, "a")
+	ok, err := reopen.WaitForGeneration(gen, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, false, ok)
+
+	// the waiting goroutines are released on Close()
+	done := make(chan bool)
+	go func() {
+		ok, err := reopen.WaitForGeneration(gen, -1)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- ok
+	}()
+	if err = reopen.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, true, <-done)
+	if err = reopen.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a refresh by the application makes the generations visible, too
+	reopen = NewControlledRealTimeReopenThread(tw, mgr.ReferenceManager, time.Hour, time.Hour)
+	defer reopen.Close()
+	gen = addTrackedTestDoc(t, tw, "a")
+	if err = mgr.MaybeRefreshBlocking(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err = reopen.WaitForGeneration(gen, 0); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, true, ok)
+}
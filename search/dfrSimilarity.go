@@ -0,0 +1,581 @@
+package search
+
+import (
+	"fmt"
+	"math"
+)
+
+// search/similarities/DFRSimilarity.java
+
+/*
+Implements the divergence from randomness (DFR) framework introduced
+in Gianni Amati and Cornelis Joost Van Rijsbergen. 2002. Probabilistic
+models of information retrieval based on measuring the divergence
+from randomness. ACM Trans. Inf. Syst. 20, 4 (October 2002), 357-389.
+
+The DFR scoring formula is composed of three separate components: the
+basic model, the aftereffect and an additional normalization
+component, represented by the interfaces BasicModel, AfterEffect and
+Normalization, respectively. The names of these interfaces were
+chosen to be as close to the original DFR framework as possible:
+
+BasicModel: Basic model of information content:
+
+  - BasicModelBE: Limiting form of Bose-Einstein
+  - BasicModelG: Geometric approximation of Bose-Einstein
+  - BasicModelP: Poisson approximation of the Binomial
+  - BasicModelD: Divergence approximation of the Binomial
+  - BasicModelIn: Inverse document frequency
+  - BasicModelIne: Inverse expected document frequency [mixture of
+    Poisson and IDF]
+  - BasicModelIF: Inverse term frequency [approximation of I(ne)]
+
+AfterEffect: First normalization of information gain:
+
+  - AfterEffectL: Laplace's law of succession
+  - AfterEffectB: Ratio of two Bernoulli processes
+  - NoAfterEffect: no first normalization
+
+Normalization: Second (length) normalization:
+
+  - NormalizationH1: Uniform distribution of term frequency
+  - NormalizationH2: term frequency density inversely related to
+    length
+  - NormalizationH3: term frequency normalization provided by
+    Dirichlet prior
+  - NormalizationZ: term frequency normalization provided by a Zipfian
+    relation
+  - NoNormalization: no second normalization
+
+Note that qtf, the multiplicity of term-occurrence in the query, is
+not handled by this implementation.
+*/
+type DFRSimilarity struct {
+	*SimilarityBase
+	// The basic model for information content.
+	basicModel BasicModel
+	// The first normalization of the information content.
+	afterEffect AfterEffect
+	// The term frequency normalization.
+	normalization Normalization
+}
+
+/*
+Creates DFRSimilarity from the three components.
+
+Note that none of the parameters may be nil: it panics otherwise. For
+no first or second normalization, use NoAfterEffect or
+NoNormalization instead.
+*/
+func NewDFRSimilarity(basicModel BasicModel, afterEffect AfterEffect,
+	normalization Normalization) *DFRSimilarity {
+	if basicModel == nil || afterEffect == nil || normalization == nil {
+		panic("nil parameters not allowed.")
+	}
+	ans := &DFRSimilarity{basicModel: basicModel, afterEffect: afterEffect, normalization: normalization}
+	ans.SimilarityBase = NewSimilarityBase(ans)
+	return ans
+}
+
+func (s *DFRSimilarity) Score(stats *BasicStats, freq, docLen float32) float32 {
+	tfn := s.normalization.Tfn(stats, freq, docLen)
+	return stats.TotalBoost() * s.basicModel.Score(stats, tfn) * s.afterEffect.Score(stats, tfn)
+}
+
+func (s *DFRSimilarity) Explain(expl *Explanation, stats *BasicStats, doc int, freq, docLen float32) {
+	if stats.TotalBoost() != 1 {
+		expl.AddDetail(NewExplanation(stats.TotalBoost(), "boost"))
+	}
+	normExpl := explainNormalization(s.normalization, stats, freq, docLen)
+	tfn := normExpl.Value()
+	expl.AddDetail(normExpl)
+	expl.AddDetail(explainBasicModel(s.basicModel, stats, tfn))
+	expl.AddDetail(explainAfterEffect(s.afterEffect, stats, tfn))
+}
+
+func (s *DFRSimilarity) String() string {
+	return fmt.Sprintf("DFR %v%v%v", s.basicModel, s.afterEffect, s.normalization)
+}
+
+// Returns the basic model of information content
+func (s *DFRSimilarity) BasicModel() BasicModel {
+	return s.basicModel
+}
+
+// Returns the first normalization
+func (s *DFRSimilarity) AfterEffect() AfterEffect {
+	return s.afterEffect
+}
+
+// Returns the second normalization
+func (s *DFRSimilarity) Normalization() Normalization {
+	return s.normalization
+}
+
+// search/similarities/BasicModel.java
+
+/*
+This class acts as the base class for the specific basic model
+implementations in the DFR framework. Basic models compute the
+informative content Inf1 = -log2Prob1.
+*/
+type BasicModel interface {
+	// Returns the informative content score.
+	Score(stats *BasicStats, tfn float32) float32
+	/*
+		Subclasses must override this method to return the code of the
+		basic model formula. Refer to the original paper for the list.
+	*/
+	String() string
+}
+
+// Returns an explanation for the score of the basic model.
+func explainBasicModel(m BasicModel, stats *BasicStats, tfn float32) *Explanation {
+	result := NewExplanation(m.Score(stats, tfn), simpleName(m)+", computed from: ")
+	result.AddDetail(NewExplanation(tfn, "tfn"))
+	if _, ok := m.(BasicModelIn); ok {
+		result.AddDetail(NewExplanation(float32(stats.DocFreq()), "docFreq"))
+		result.AddDetail(NewExplanation(float32(stats.NumberOfDocuments()), "numberOfDocuments"))
+		return result
+	}
+	result.AddDetail(NewExplanation(float32(stats.NumberOfDocuments()), "numberOfDocuments"))
+	result.AddDetail(NewExplanation(float32(stats.TotalTermFreq()), "totalTermFreq"))
+	return result
+}
+
+// search/similarities/BasicModelBE.java
+
+/*
+Limiting form of the Bose-Einstein model. The formula used in Lucene
+differs slightly from the one in the original paper: F is increased
+by tfn+1 and N is increased by F.
+*/
+type BasicModelBE struct{}
+
+func NewBasicModelBE() BasicModelBE {
+	return BasicModelBE{}
+}
+
+func (m BasicModelBE) Score(stats *BasicStats, tfn float32) float32 {
+	F := float64(stats.TotalTermFreq()) + 1 + float64(tfn)
+	// approximation only holds true when F << N, so we use N += F
+	N := F + float64(stats.NumberOfDocuments())
+	return float32(-log2((N-1)*math.E) +
+		m.f(N+F-1, N+F-float64(tfn)-2) - m.f(F, F-float64(tfn)))
+}
+
+// The f helper function defined for Be.
+func (m BasicModelBE) f(n, k float64) float64 {
+	return (k+0.5)*log2(n/k) + (n-k)*log2(n)
+}
+
+func (m BasicModelBE) String() string {
+	return "Be"
+}
+
+// search/similarities/BasicModelD.java
+
+/*
+Implements the approximation of the binomial model with the
+divergence for DFR. The formula used in Lucene differs slightly from
+the one in the original paper: to avoid underflow for small values of
+N and F, N is increased by 1 and F is always increased by tfn+1.
+
+WARNING: for terms that do not meet the expected random distribution
+(e.g. stopwords), this model may give poor performance, such as
+abnormally high scores for low tf values.
+*/
+type BasicModelD struct{}
+
+func NewBasicModelD() BasicModelD {
+	return BasicModelD{}
+}
+
+func (m BasicModelD) Score(stats *BasicStats, tfn float32) float32 {
+	// we have to ensure phi is always < 1 for tiny TTF values, otherwise nphi can go negative,
+	// resulting in NaN. cleanest way is to unconditionally always add tfn to totalTermFreq
+	// to create a 'normalized' F.
+	F := float64(stats.TotalTermFreq()) + 1 + float64(tfn)
+	phi := float64(tfn) / F
+	nphi := 1 - phi
+	p := 1.0 / float64(stats.NumberOfDocuments()+1)
+	D := phi*log2(phi/p) + nphi*log2(nphi/(1-p))
+	return float32(D*F + 0.5*log2(1+2*math.Pi*float64(tfn)*nphi))
+}
+
+func (m BasicModelD) String() string {
+	return "D"
+}
+
+// search/similarities/BasicModelG.java
+
+/*
+Geometric as limiting form of the Bose-Einstein model. The formula
+used in Lucene differs slightly from the one in the original paper: F
+is increased by 1 and N is increased by F.
+*/
+type BasicModelG struct{}
+
+func NewBasicModelG() BasicModelG {
+	return BasicModelG{}
+}
+
+func (m BasicModelG) Score(stats *BasicStats, tfn float32) float32 {
+	// just like in BE, approximation only holds true when F << N, so we use lambda = F / (N + F)
+	F := float64(stats.TotalTermFreq()) + 1
+	N := float64(stats.NumberOfDocuments())
+	lambda := F / (N + F)
+	// -log(1 / (lambda + 1)) -> log(lambda + 1)
+	return float32(log2(lambda+1) + float64(tfn)*log2((1+lambda)/lambda))
+}
+
+func (m BasicModelG) String() string {
+	return "G"
+}
+
+// search/similarities/BasicModelIF.java
+
+// An approximation of the I(ne) model.
+type BasicModelIF struct{}
+
+func NewBasicModelIF() BasicModelIF {
+	return BasicModelIF{}
+}
+
+func (m BasicModelIF) Score(stats *BasicStats, tfn float32) float32 {
+	N := float64(stats.NumberOfDocuments())
+	F := float64(stats.TotalTermFreq())
+	return tfn * float32(log2(1+(N+1)/(F+0.5)))
+}
+
+func (m BasicModelIF) String() string {
+	return "I(F)"
+}
+
+// search/similarities/BasicModelIn.java
+
+// The basic tf-idf model of randomness.
+type BasicModelIn struct{}
+
+func NewBasicModelIn() BasicModelIn {
+	return BasicModelIn{}
+}
+
+func (m BasicModelIn) Score(stats *BasicStats, tfn float32) float32 {
+	N := float64(stats.NumberOfDocuments())
+	n := float64(stats.DocFreq())
+	return tfn * float32(log2((N+1)/(n+0.5)))
+}
+
+func (m BasicModelIn) String() string {
+	return "I(n)"
+}
+
+// search/similarities/BasicModelIne.java
+
+/*
+Tf-idf model of randomness, based on a mixture of Poisson and inverse
+document frequency.
+*/
+type BasicModelIne struct{}
+
+func NewBasicModelIne() BasicModelIne {
+	return BasicModelIne{}
+}
+
+func (m BasicModelIne) Score(stats *BasicStats, tfn float32) float32 {
+	N := float64(stats.NumberOfDocuments())
+	F := float64(stats.TotalTermFreq())
+	ne := N * (1 - math.Pow((N-1)/N, F))
+	return tfn * float32(log2((N+1)/(ne+0.5)))
+}
+
+func (m BasicModelIne) String() string {
+	return "I(ne)"
+}
+
+// search/similarities/BasicModelP.java
+
+/*
+Implements the Poisson approximation for the binomial model for DFR.
+
+WARNING: for terms that do not meet the expected random distribution
+(e.g. stopwords), this model may give poor performance, such as
+abnormally high scores for low tf values.
+*/
+type BasicModelP struct{}
+
+func NewBasicModelP() BasicModelP {
+	return BasicModelP{}
+}
+
+func (m BasicModelP) Score(stats *BasicStats, tfn float32) float32 {
+	lambda := float64(stats.TotalTermFreq()+1) / float64(stats.NumberOfDocuments()+1)
+	t := float64(tfn)
+	return float32(t*log2(t/lambda) +
+		(lambda+1/(12*t)-t)*math.Log2E +
+		0.5*log2(2*math.Pi*t))
+}
+
+func (m BasicModelP) String() string {
+	return "P"
+}
+
+// search/similarities/AfterEffect.java
+
+/*
+This class acts as the base class for the implementations of the
+first normalization of the informative content in the DFR framework.
+This component is also called the after effect and is defined by the
+formula Inf2 = 1 - Prob2, where Prob2 measures the information gain.
+*/
+type AfterEffect interface {
+	// Returns the aftereffect score.
+	Score(stats *BasicStats, tfn float32) float32
+	// Subclasses must override this method to return the code of the
+	// after effect formula. Refer to the original paper for the list.
+	String() string
+}
+
+// Returns an explanation for the score of the after effect.
+func explainAfterEffect(e AfterEffect, stats *BasicStats, tfn float32) *Explanation {
+	switch e.(type) {
+	case NoAfterEffect:
+		return NewExplanation(1, "no aftereffect")
+	case AfterEffectB:
+		result := NewExplanation(e.Score(stats, tfn), "B, product of:")
+		result.AddDetail(NewExplanation(float32(stats.TotalTermFreq()+1), "F"))
+		result.AddDetail(NewExplanation(float32(stats.DocFreq()+1), "n"))
+		result.AddDetail(NewExplanation(tfn, "tfn"))
+		return result
+	}
+	result := NewExplanation(e.Score(stats, tfn), simpleName(e)+", computed from: ")
+	result.AddDetail(NewExplanation(tfn, "tfn"))
+	return result
+}
+
+// search/similarities/AfterEffect.java/NoAfterEffect
+
+// Implementation used when there is no aftereffect.
+type NoAfterEffect struct{}
+
+func NewNoAfterEffect() NoAfterEffect {
+	return NoAfterEffect{}
+}
+
+func (e NoAfterEffect) Score(stats *BasicStats, tfn float32) float32 {
+	return 1
+}
+
+func (e NoAfterEffect) String() string {
+	return ""
+}
+
+// search/similarities/AfterEffectB.java
+
+// Model of the information gain based on the ratio of two Bernoulli
+// processes.
+type AfterEffectB struct{}
+
+func NewAfterEffectB() AfterEffectB {
+	return AfterEffectB{}
+}
+
+func (e AfterEffectB) Score(stats *BasicStats, tfn float32) float32 {
+	F := stats.TotalTermFreq() + 1
+	n := stats.DocFreq() + 1
+	return float32(F+1) / (float32(n) * (tfn + 1))
+}
+
+func (e AfterEffectB) String() string {
+	return "B"
+}
+
+// search/similarities/AfterEffectL.java
+
+// Model of the information gain based on Laplace's law of succession.
+type AfterEffectL struct{}
+
+func NewAfterEffectL() AfterEffectL {
+	return AfterEffectL{}
+}
+
+func (e AfterEffectL) Score(stats *BasicStats, tfn float32) float32 {
+	return 1 / (tfn + 1)
+}
+
+func (e AfterEffectL) String() string {
+	return "L"
+}
+
+// search/similarities/Normalization.java
+
+/*
+This class acts as the base class for the implementations of the term
+frequency normalization methods in the DFR framework.
+*/
+type Normalization interface {
+	// Returns the normalized term frequency.
+	Tfn(stats *BasicStats, tf, length float32) float32
+	/*
+		Subclasses must override this method to return the code of the
+		normalization formula. Refer to the original paper for the list.
+	*/
+	String() string
+}
+
+// Returns an explanation for the normalized term frequency.
+func explainNormalization(n Normalization, stats *BasicStats, tf, length float32) *Explanation {
+	if _, ok := n.(NoNormalization); ok {
+		return NewExplanation(1, "no normalization")
+	}
+	result := NewExplanation(n.Tfn(stats, tf, length), simpleName(n)+", computed from: ")
+	result.AddDetail(NewExplanation(tf, "tf"))
+	result.AddDetail(NewExplanation(stats.AvgFieldLength(), "avgFieldLength"))
+	result.AddDetail(NewExplanation(length, "len"))
+	return result
+}
+
+// search/similarities/Normalization.java/NoNormalization
+
+// Implementation used when there is no normalization.
+type NoNormalization struct{}
+
+func NewNoNormalization() NoNormalization {
+	return NoNormalization{}
+}
+
+func (n NoNormalization) Tfn(stats *BasicStats, tf, length float32) float32 {
+	return tf
+}
+
+func (n NoNormalization) String() string {
+	return ""
+}
+
+// search/similarities/NormalizationH1.java
+
+/*
+Normalization model that assumes a uniform distribution of the term
+frequency.
+
+While this model is parameterless in the original article,
+information-based models (see IBSimilarity) introduced a
+multiplying factor. The default value for the c parameter is 1.
+*/
+type NormalizationH1 struct {
+	c float32
+}
+
+/*
+Creates NormalizationH1 with the supplied parameter c, the hyper-
+parameter that controls the term frequency normalization with respect
+to the document length.
+*/
+func NewNormalizationH1(c float32) *NormalizationH1 {
+	return &NormalizationH1{c}
+}
+
+func (n *NormalizationH1) Tfn(stats *BasicStats, tf, length float32) float32 {
+	return tf * n.c * stats.AvgFieldLength() / length
+}
+
+func (n *NormalizationH1) String() string {
+	return "1"
+}
+
+// Returns the c parameter.
+func (n *NormalizationH1) C() float32 {
+	return n.c
+}
+
+// search/similarities/NormalizationH2.java
+
+/*
+Normalization model in which the term frequency is inversely related
+to the length.
+
+While this model is parameterless in the original article, the thesis
+introduces the parameterized variant. The default value for the c
+parameter is 1.
+*/
+type NormalizationH2 struct {
+	c float32
+}
+
+/*
+Creates NormalizationH2 with the supplied parameter c, the hyper-
+parameter that controls the term frequency normalization with respect
+to the document length.
+*/
+func NewNormalizationH2(c float32) *NormalizationH2 {
+	return &NormalizationH2{c}
+}
+
+func (n *NormalizationH2) Tfn(stats *BasicStats, tf, length float32) float32 {
+	return tf * float32(log2(1+float64(n.c*stats.AvgFieldLength()/length)))
+}
+
+func (n *NormalizationH2) String() string {
+	return "2"
+}
+
+// Returns the c parameter.
+func (n *NormalizationH2) C() float32 {
+	return n.c
+}
+
+// search/similarities/NormalizationH3.java
+
+// Dirichlet Priors normalization. The default value for the mu
+// parameter is 800.
+type NormalizationH3 struct {
+	mu float32
+}
+
+// Creates NormalizationH3 with the supplied parameter mu, the smoothing
+// parameter.
+func NewNormalizationH3(mu float32) *NormalizationH3 {
+	return &NormalizationH3{mu}
+}
+
+func (n *NormalizationH3) Tfn(stats *BasicStats, tf, length float32) float32 {
+	return (tf + n.mu*((float32(stats.TotalTermFreq())+1)/(float32(stats.NumberOfFieldTokens())+1))) /
+		(length + n.mu) * n.mu
+}
+
+func (n *NormalizationH3) String() string {
+	return fmt.Sprintf("3(%v)", n.mu)
+}
+
+// Returns the parameter mu.
+func (n *NormalizationH3) Mu() float32 {
+	return n.mu
+}
+
+// search/similarities/NormalizationZ.java
+
+// Pareto-Zipf Normalization. The default value for the z parameter is
+// 0.30.
+type NormalizationZ struct {
+	z float32
+}
+
+// Creates NormalizationZ with the supplied parameter z, which
+// represents A/(A+1) where A measures the specificity of the language.
+func NewNormalizationZ(z float32) *NormalizationZ {
+	return &NormalizationZ{z}
+}
+
+func (n *NormalizationZ) Tfn(stats *BasicStats, tf, length float32) float32 {
+	return tf * float32(math.Pow(float64(stats.AvgFieldLength()/length), float64(n.z)))
+}
+
+func (n *NormalizationZ) String() string {
+	return fmt.Sprintf("Z(%v)", n.z)
+}
+
+// Returns the parameter z.
+func (n *NormalizationZ) Z() float32 {
+	return n.z
+}
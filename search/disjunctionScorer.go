@@ -0,0 +1,244 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"math"
+)
+
+// search/DisjunctionScorer.java
+
+/*
+Base class for Scorers that score disjunctions. Currently this just
+provides helper methods to manage the heap of the sub-scorers, ordered
+by their current doc, and their cost.
+*/
+type disjunctionScorer struct {
+	*ScorerImpl
+	subScorers []Scorer
+	numScorers int
+	// the summed cost of the sub-scorers, which leave the heap once
+	// exhausted
+	cost int64
+}
+
+func newDisjunctionScorer(self Scorer, w Weight, subScorers []Scorer) *disjunctionScorer {
+	ans := &disjunctionScorer{
+		ScorerImpl: NewScorer(self, w),
+		subScorers: subScorers,
+		numScorers: len(subScorers),
+	}
+	for _, scorer := range subScorers {
+		ans.cost += scorer.Cost()
+	}
+	ans.heapify()
+	return ans
+}
+
+// A disjunction may match the documents of any sub-scorer.
+func (s *disjunctionScorer) Cost() int64 {
+	return s.cost
+}
+
+/*
+Organize subScorers into a min heap with scorers generating the
+earliest document on top.
+*/
+func (s *disjunctionScorer) heapify() {
+	for i := (s.numScorers >> 1) - 1; i >= 0; i-- {
+		s.heapAdjust(i)
+	}
+}
+
+/*
+The subtree of subScorers at root is a min heap except possibly for
+its root element. Bubble the root down as required to make the
+subtree a heap.
+*/
+func (s *disjunctionScorer) heapAdjust(root int) {
+	scorer := s.subScorers[root]
+	doc := scorer.DocId()
+	i := root
+	for i <= (s.numScorers>>1)-1 {
+		lchild := (i << 1) + 1
+		lscorer := s.subScorers[lchild]
+		ldoc := lscorer.DocId()
+		rdoc, rchild := index.NO_MORE_DOCS, (i<<1)+2
+		var rscorer Scorer
+		if rchild < s.numScorers {
+			rscorer = s.subScorers[rchild]
+			rdoc = rscorer.DocId()
+		}
+		if ldoc < doc {
+			if rdoc < ldoc {
+				s.subScorers[i] = rscorer
+				s.subScorers[rchild] = scorer
+				i = rchild
+			} else {
+				s.subScorers[i] = lscorer
+				s.subScorers[lchild] = scorer
+				i = lchild
+			}
+		} else if rdoc < doc {
+			s.subScorers[i] = rscorer
+			s.subScorers[rchild] = scorer
+			i = rchild
+		} else {
+			return
+		}
+	}
+}
+
+/*
+Remove the root Scorer from subScorers and re-establish it as a heap
+*/
+func (s *disjunctionScorer) heapRemoveRoot() {
+	if s.numScorers == 1 {
+		s.subScorers[0] = nil
+		s.numScorers = 0
+	} else {
+		s.subScorers[0] = s.subScorers[s.numScorers-1]
+		s.subScorers[s.numScorers-1] = nil
+		s.numScorers--
+		s.heapAdjust(0)
+	}
+}
+
+// search/DisjunctionSumScorer.java
+
+/*
+A Scorer for OR like queries, counterpart of ConjunctionScorer. This
+Scorer implements Advance(), and it uses NextDoc() on the given
+scorers.
+*/
+type DisjunctionSumScorer struct {
+	*disjunctionScorer
+	// The minimum number of scorers that should match.
+	minimumNrMatchers int
+	// The document number of the current match.
+	doc int
+	// The number of subscorers that provide the current match.
+	nrMatchers int
+	score      float32
+}
+
+/*
+Construct a DisjunctionSumScorer.
+
+subScorers is a list of at least two subscorers, whose docs are all
+still unpositioned. minimumNrMatchers is the positive minimum number
+of subscorers that should match to match this query. When
+minimumNrMatchers is bigger than the number of subScorers, no matches
+will be produced. When minimumNrMatchers equals the number of
+subScorers, it is more efficient to use ConjunctionScorer.
+*/
+func newDisjunctionSumScorer(w Weight, subScorers []Scorer, minimumNrMatchers int) *DisjunctionSumScorer {
+	if minimumNrMatchers <= 0 {
+		panic("Minimum nr of matchers must be positive")
+	}
+	if len(subScorers) <= 1 {
+		panic("There must be at least 2 subScorers")
+	}
+	ans := &DisjunctionSumScorer{
+		minimumNrMatchers: minimumNrMatchers,
+		doc:               -1,
+	}
+	ans.disjunctionScorer = newDisjunctionScorer(ans, w, subScorers)
+	return ans
+}
+
+func (s *DisjunctionSumScorer) NextDoc() (int, bool) {
+	// assert s.doc != NO_MORE_DOCS
+	for {
+		if s.subScorers[0].DocId() == s.doc {
+			if _, more := s.subScorers[0].NextDoc(); more {
+				s.heapAdjust(0)
+			} else {
+				s.heapRemoveRoot()
+				if s.numScorers < s.minimumNrMatchers {
+					s.doc = index.NO_MORE_DOCS
+					return s.doc, false
+				}
+			}
+			// advance all the scorers on the current doc first
+			continue
+		}
+		s.afterNext()
+		if s.nrMatchers >= s.minimumNrMatchers {
+			break
+		}
+	}
+	return s.doc, s.doc != index.NO_MORE_DOCS
+}
+
+func (s *DisjunctionSumScorer) afterNext() {
+	sub := s.subScorers[0]
+	s.doc = sub.DocId()
+	if s.doc == index.NO_MORE_DOCS {
+		s.nrMatchers = math.MaxInt32 // stop looping
+	} else {
+		s.score = sub.Score()
+		s.nrMatchers = 1
+		s.countMatches(1)
+		s.countMatches(2)
+	}
+}
+
+// TODO: this currently scores, but so did the previous impl
+// TODO: remove recursion.
+// TODO: if we separate scoring, out of here, modify this
+// and afterNext() to terminate when nrMatchers == minimumNrMatchers
+// then also change freq() to just always compute it from scratch
+func (s *DisjunctionSumScorer) countMatches(root int) {
+	if root < s.numScorers && s.subScorers[root].DocId() == s.doc {
+		s.nrMatchers++
+		s.score += s.subScorers[root].Score()
+		s.countMatches((root << 1) + 1)
+		s.countMatches((root << 1) + 2)
+	}
+}
+
+/*
+Returns the score of the current document matching the query.
+Initially invalid, until NextDoc() is called the first time.
+*/
+func (s *DisjunctionSumScorer) Score() float32 {
+	return s.score
+}
+
+func (s *DisjunctionSumScorer) DocId() int {
+	return s.doc
+}
+
+func (s *DisjunctionSumScorer) Freq() int {
+	return s.nrMatchers
+}
+
+/*
+Advances to the first match beyond the current whose document number
+is greater than or equal to a given target. The implementation uses
+the Advance() method on the subscorers.
+*/
+func (s *DisjunctionSumScorer) Advance(target int) (int, bool) {
+	if s.numScorers == 0 {
+		s.doc = index.NO_MORE_DOCS
+		return s.doc, false
+	}
+	for s.subScorers[0].DocId() < target {
+		if _, more := s.subScorers[0].Advance(target); more {
+			s.heapAdjust(0)
+		} else {
+			s.heapRemoveRoot()
+			if s.numScorers == 0 {
+				s.doc = index.NO_MORE_DOCS
+				return s.doc, false
+			}
+		}
+	}
+
+	s.afterNext()
+
+	if s.nrMatchers >= s.minimumNrMatchers {
+		return s.doc, s.doc != index.NO_MORE_DOCS
+	}
+	return s.NextDoc()
+}
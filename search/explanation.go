@@ -0,0 +1,129 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// search/Explanation.java
+
+/*
+Expert: Describes the score computation for document and query.
+
+The explanation is a tree of the values, and their descriptions,
+which are combined into the score. It is a match if its value is
+positive, unless it's a complex explanation, whose match is set
+explicitly, see NewComplexExplanation().
+*/
+type Explanation struct {
+	value       float32        // the value of this node
+	description string         // what it represents
+	details     []*Explanation // sub-explanations
+	// the match of a complex explanation, nil for a plain one
+	match *bool
+}
+
+func NewExplanation(value float32, description string) *Explanation {
+	return &Explanation{value: value, description: description}
+}
+
+/*
+Creates an explanation whose match is explicitly set, rather than
+derived from its value, e.g. for a document which matches the query
+but has a score of zero.
+*/
+func NewComplexExplanation(match bool, value float32, description string) *Explanation {
+	ans := NewExplanation(value, description)
+	ans.SetMatch(match)
+	return ans
+}
+
+/*
+Indicates whether or not this Explanation models a good match.
+
+By default, an Explanation represents a "match" if the value is
+positive. A complex explanation returns the match it was given.
+*/
+func (e *Explanation) IsMatch() bool {
+	if e.match != nil {
+		return *e.match
+	}
+	return e.value > 0
+}
+
+// Sets the match of the explanation, which turns it into a complex
+// one.
+func (e *Explanation) SetMatch(match bool) {
+	e.match = &match
+}
+
+// The value assigned to this explanation node.
+func (e *Explanation) Value() float32 {
+	return e.value
+}
+
+// Sets the value assigned to this explanation node.
+func (e *Explanation) SetValue(value float32) {
+	e.value = value
+}
+
+// A description of this explanation node.
+func (e *Explanation) Description() string {
+	return e.description
+}
+
+// Sets the description of this explanation node.
+func (e *Explanation) SetDescription(description string) {
+	e.description = description
+}
+
+// A short one line summary which should contain all high level
+// information about this Explanation, without the "Details".
+func (e *Explanation) Summary() string {
+	if e.match == nil {
+		return fmt.Sprintf("%v = %v", e.value, e.description)
+	}
+	if *e.match {
+		return fmt.Sprintf("%v = (MATCH) %v", e.value, e.description)
+	}
+	return fmt.Sprintf("%v = (NON-MATCH) %v", e.value, e.description)
+}
+
+// The sub-nodes of this explanation node.
+func (e *Explanation) Details() []*Explanation {
+	return e.details
+}
+
+// Adds a sub-node to this explanation node.
+func (e *Explanation) AddDetail(detail *Explanation) {
+	e.details = append(e.details, detail)
+}
+
+// Render an explanation as text, indented by the depth of the nodes.
+func (e *Explanation) String() string {
+	var buf bytes.Buffer
+	e.writeTo(&buf, 0)
+	return buf.String()
+}
+
+func (e *Explanation) writeTo(buf *bytes.Buffer, depth int) {
+	for i := 0; i < depth; i++ {
+		buf.WriteString("  ")
+	}
+	buf.WriteString(e.Summary())
+	buf.WriteString("\n")
+	for _, detail := range e.details {
+		detail.writeTo(buf, depth+1)
+	}
+}
+
+// Returns the name of the type of v, without its package, like the
+// simple names of Java classes in the descriptions of explanations.
+func simpleName(v interface{}) string {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
@@ -0,0 +1,125 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"math"
+	"testing"
+)
+
+/*
+Checks the explanation of every document against q: the hits are
+matches, whose values are their scores, and the other documents are
+not matches.
+*/
+func checkExplanations(t *testing.T, ss *IndexSearcher, q Query) {
+	docs, err := ss.SearchTop(q, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := make(map[int]float32)
+	for _, hit := range docs.ScoreDocs {
+		scores[hit.Doc] = hit.Score
+	}
+	for doc := 0; doc < ss.IndexReader().MaxDoc(); doc++ {
+		expl, err := ss.Explain(q, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		score, ok := scores[doc]
+		if ok != expl.IsMatch() {
+			t.Errorf("%v in %v: expected match %v, but explanation:\n%v", q, doc, ok, expl)
+		} else if ok && math.Abs(float64(score-expl.Value())) > 1e-5*math.Max(1, float64(score)) {
+			t.Errorf("%v in %v: expected score %v, but explanation:\n%v", q, doc, score, expl)
+		}
+	}
+}
+
+func TestExplanation(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+	defer cleanup()
+
+	expl, err := ss.Explain(newBodyTermQuery("d"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, `1.1976817 = (MATCH) weight(body:d in 4) [DefaultSimilarity], result of:
+  1.1976817 = (MATCH) fieldWeight in 4, product of:
+    1 = tf(freq=1), with freq of:
+      1 = termFreq=1
+    1.9162908 = idf(docFreq=1, maxDocs=5)
+    0.625 = fieldNorm(doc=4)
+`, expl.String())
+	if expl, err = ss.Explain(newBodyTermQuery("d"), 0); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "0 = (NON-MATCH) no matching term\n", expl.String())
+
+	a, b, c, d := newBodyTermQuery("a"), newBodyTermQuery("b"), newBodyTermQuery("c"), newBodyTermQuery("d")
+	boosted := newBodyTermQuery("b")
+	boosted.SetBoost(3)
+	minShouldMatch := newTestBooleanQuery(a, OCCUR_SHOULD, b, OCCUR_SHOULD, c, OCCUR_SHOULD)
+	minShouldMatch.SetMinimumNumberShouldMatch(2)
+	for _, sim := range []Similarity{
+		NewDefaultSimilarity(),
+		NewBM25Similarity(),
+		NewDFRSimilarity(NewBasicModelIn(), NewAfterEffectB(), NewNormalizationH2(1)),
+		NewIBSimilarity(NewDistributionLL(), NewLambdaDF(), NewNormalizationH2(1)),
+		NewLMJelinekMercerSimilarity(nil, 0.7),
+	} {
+		ss.SetSimilarity(sim)
+		for _, q := range []Query{
+			a,
+			newTestBooleanQuery(a, OCCUR_SHOULD, boosted, OCCUR_SHOULD, d, OCCUR_SHOULD),
+			newTestBooleanQuery(a, OCCUR_MUST, c, OCCUR_MUST_NOT),
+			newTestBooleanQuery(b, OCCUR_MUST, newTestBooleanQuery(c, OCCUR_SHOULD, d, OCCUR_SHOULD), OCCUR_SHOULD),
+			minShouldMatch,
+			NewMatchAllDocsQuery(),
+			NewConstantScoreQuery(c),
+			NewConstantScoreQueryWithFilter(NewQueryWrapperFilter(b)),
+			NewFilteredQuery(a, NewQueryWrapperFilter(b)),
+		} {
+			checkExplanations(t, ss, q)
+		}
+	}
+}
+
+func TestExplanationPositions(t *testing.T) {
+	ss, cleanup := newBelfrySearcher(t)
+	defer cleanup()
+
+	bat := index.NewTerm("content", "bat")
+	clauses := []SpanQuery{newContentSpanTermQuery("fruit"), newContentSpanTermQuery("bat")}
+	for _, sim := range []Similarity{
+		NewDefaultSimilarity(),
+		NewBM25Similarity(),
+		NewLMDirichletSimilarity(),
+	} {
+		ss.SetSimilarity(sim)
+		for _, q := range []Query{
+			newContentPhraseQuery(0, "fruit", "bat"),
+			newContentPhraseQuery(2, "fruit|new|your", "bat"),
+			newContentSpanNearQuery(1, false, "fruit", "bat"),
+			NewPayloadTermQuery(bat, MaxPayloadFunction{}),
+			NewPayloadTermQueryWithSpanScore(bat, AveragePayloadFunction{}, false),
+			NewPayloadNearQuery(clauses, 0, true),
+		} {
+			checkExplanations(t, ss, q)
+		}
+	}
+}
+
+func TestExplanationOutOfRange(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b", "b c")
+	defer cleanup()
+	for _, doc := range []int{-1, 2} {
+		if _, err := ss.Explain(newBodyTermQuery("a"), doc); err == nil {
+			t.Errorf("Expected error for doc %v", doc)
+		}
+	}
+
+	empty, cleanup2 := newTestSearcher(t)
+	defer cleanup2()
+	if _, err := empty.Explain(newBodyTermQuery("a"), 0); err == nil {
+		t.Error("Expected error for an empty index")
+	}
+}
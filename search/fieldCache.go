@@ -0,0 +1,809 @@
+package search
+
+import (
+	"errors"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// search/FieldCache.java
+
+/*
+Expert: Maintains caches of term values, for sorting or grouping by
+the values of fields which have no doc values.
+
+The values of a field are un-inverted from its indexed terms: each
+document gets the value of its term, which should be single. The
+values are cached per segment, by the core cache key of its reader,
+so they are shared by the readers of a segment whose deletions
+changed. The entries of a segment are evicted when its core is
+closed.
+
+If the field has doc values, they are returned instead, and no value
+is cached.
+*/
+type FieldCache interface {
+	/*
+		Checks the internal cache for an appropriate entry, and if none
+		is found, reads the terms in field and returns a bit set at the
+		size of reader.MaxDoc(), with turned on bits for each docid that
+		does have a value for this field. All the documents have a value
+		if the field has doc values.
+	*/
+	DocsWithField(reader index.AtomicReader, field string) (util.Bits, error)
+	/*
+		Returns an Ints over the values found in documents in the given
+		field. If the field has numeric doc values, they are returned.
+		Otherwise the terms are parsed by parser, and the values are
+		cached. If parser is nil, the terms are first parsed by
+		DEFAULT_INT_PARSER, then NUMERIC_UTILS_INT_PARSER if they are
+		not decimal numbers. If setDocsWithField is true, the documents
+		with a value are also cached, for DocsWithField().
+	*/
+	Ints(reader index.AtomicReader, field string, parser IntParser, setDocsWithField bool) (FieldCacheInts, error)
+	// Same as Ints(), for int64 values.
+	Longs(reader index.AtomicReader, field string, parser LongParser, setDocsWithField bool) (FieldCacheLongs, error)
+	// Same as Ints(), for float32 values.
+	Floats(reader index.AtomicReader, field string, parser FloatParser, setDocsWithField bool) (FieldCacheFloats, error)
+	// Same as Ints(), for float64 values.
+	Doubles(reader index.AtomicReader, field string, parser DoubleParser, setDocsWithField bool) (FieldCacheDoubles, error)
+	/*
+		Checks the internal cache for an appropriate entry, and if none
+		is found, reads the term values in field and returns a
+		SortedDocValues instance, providing methods to retrieve sort
+		ordinals and terms for a document. A document without a term
+		has ord -1, and one with several terms gets the ord of its last
+		term. The SortedDocValues of the field are returned if any.
+	*/
+	TermsIndex(reader index.AtomicReader, field string) (index.SortedDocValues, error)
+	/*
+		Checks the internal cache for an appropriate entry, and if none
+		is found, un-inverts the terms of the multi-valued field and
+		returns a SortedSetDocValues, whose ords of a document are
+		those of its terms. The SortedSetDocValues of the field are
+		returned if any. A new instance is returned by each call, as
+		the iteration has a state.
+	*/
+	DocTermOrds(reader index.AtomicReader, field string) (index.SortedSetDocValues, error)
+	/*
+		Expert: drops all cache entries associated with this reader core
+		cache key.
+
+		NOTE: this function will not free the entries being used by
+		other goroutines.
+	*/
+	PurgeByCacheKey(coreCacheKey interface{})
+	/*
+		EXPERT: Instructs the FieldCache to forcibly expunge all entries
+		from the underlying caches. This is intended only to be used for
+		test methods as a way to ensure a known base state of the Cache.
+		It should not be relied on for "Cache maintenance" in general
+		application code, as the entries of a reader are purged when it
+		is closed.
+	*/
+	PurgeAllCaches()
+}
+
+// Expert: The cache used internally by sorting and
+// FieldCacheTermsFilter.
+var FIELD_CACHE_DEFAULT = FieldCache(newFieldCacheImpl())
+
+// search/FieldCache.java/Ints
+
+// Field values as 32-bit signed integers
+type FieldCacheInts interface {
+	// Return an integer representation of this field's value.
+	Get(docID int) int32
+}
+
+// search/FieldCache.java/Longs
+
+// Field values as 64-bit signed long integers
+type FieldCacheLongs interface {
+	// Return a long representation of this field's value.
+	Get(docID int) int64
+}
+
+// search/FieldCache.java/Floats
+
+// Field values as 32-bit floats
+type FieldCacheFloats interface {
+	// Return a float representation of this field's value.
+	Get(docID int) float32
+}
+
+// search/FieldCache.java/Doubles
+
+// Field values as 64-bit doubles
+type FieldCacheDoubles interface {
+	// Return a double representation of this field's value.
+	Get(docID int) float64
+}
+
+// search/FieldCache.java/StopFillCacheException
+
+/*
+Hack: When a parser returns this error, FieldCache stops enumerating
+terms and returns the current FieldCache array. This is used by the
+NumericUtils parsers, which stop at the first term of a lower
+precision.
+*/
+var ErrStopFillCache = errors.New("stop filling the field cache")
+
+// search/FieldCache.java/IntParser
+
+/*
+Interface to parse ints from document fields. A parser is part of
+the cache key, so it must be comparable.
+*/
+type IntParser interface {
+	// Return an integer representation of this field's value.
+	ParseInt(term []byte) (int32, error)
+}
+
+// search/FieldCache.java/LongParser
+
+/*
+Interface to parse longs from document fields. A parser is part of
+the cache key, so it must be comparable.
+*/
+type LongParser interface {
+	// Return a long representation of this field's value.
+	ParseLong(term []byte) (int64, error)
+}
+
+// search/FieldCache.java/FloatParser
+
+/*
+Interface to parse floats from document fields. A parser is part of
+the cache key, so it must be comparable.
+*/
+type FloatParser interface {
+	// Return a float representation of this field's value.
+	ParseFloat(term []byte) (float32, error)
+}
+
+// search/FieldCache.java/DoubleParser
+
+/*
+Interface to parse doubles from document fields. A parser is part of
+the cache key, so it must be comparable.
+*/
+type DoubleParser interface {
+	// Return a double representation of this field's value.
+	ParseDouble(term []byte) (float64, error)
+}
+
+// The default parser for int values, which are parsed as decimal
+// numbers.
+var DEFAULT_INT_PARSER = IntParser(defaultIntParser{})
+
+type defaultIntParser struct{}
+
+func (p defaultIntParser) ParseInt(term []byte) (int32, error) {
+	v, err := strconv.ParseInt(string(term), 10, 32)
+	return int32(v), err
+}
+
+func (p defaultIntParser) String() string {
+	return "FieldCache.DEFAULT_INT_PARSER"
+}
+
+// The default parser for long values, which are parsed as decimal
+// numbers.
+var DEFAULT_LONG_PARSER = LongParser(defaultLongParser{})
+
+type defaultLongParser struct{}
+
+func (p defaultLongParser) ParseLong(term []byte) (int64, error) {
+	return strconv.ParseInt(string(term), 10, 64)
+}
+
+func (p defaultLongParser) String() string {
+	return "FieldCache.DEFAULT_LONG_PARSER"
+}
+
+// The default parser for float values, which are parsed as decimal
+// numbers.
+var DEFAULT_FLOAT_PARSER = FloatParser(defaultFloatParser{})
+
+type defaultFloatParser struct{}
+
+func (p defaultFloatParser) ParseFloat(term []byte) (float32, error) {
+	v, err := strconv.ParseFloat(string(term), 32)
+	return float32(v), err
+}
+
+func (p defaultFloatParser) String() string {
+	return "FieldCache.DEFAULT_FLOAT_PARSER"
+}
+
+// The default parser for double values, which are parsed as decimal
+// numbers.
+var DEFAULT_DOUBLE_PARSER = DoubleParser(defaultDoubleParser{})
+
+type defaultDoubleParser struct{}
+
+func (p defaultDoubleParser) ParseDouble(term []byte) (float64, error) {
+	return strconv.ParseFloat(string(term), 64)
+}
+
+func (p defaultDoubleParser) String() string {
+	return "FieldCache.DEFAULT_DOUBLE_PARSER"
+}
+
+/*
+A parser instance for int values encoded by util.IntToPrefixCoded(),
+e.g. when indexed via IntField/NumericTokenStream.
+*/
+var NUMERIC_UTILS_INT_PARSER = IntParser(numericUtilsIntParser{})
+
+type numericUtilsIntParser struct{}
+
+func (p numericUtilsIntParser) ParseInt(term []byte) (int32, error) {
+	if shift, err := util.PrefixCodedIntShift(term); err != nil {
+		return 0, err
+	} else if shift > 0 {
+		return 0, ErrStopFillCache
+	}
+	return util.PrefixCodedToInt(term)
+}
+
+func (p numericUtilsIntParser) String() string {
+	return "FieldCache.NUMERIC_UTILS_INT_PARSER"
+}
+
+/*
+A parser instance for long values encoded by
+util.LongToPrefixCoded(), e.g. when indexed via
+LongField/NumericTokenStream.
+*/
+var NUMERIC_UTILS_LONG_PARSER = LongParser(numericUtilsLongParser{})
+
+type numericUtilsLongParser struct{}
+
+func (p numericUtilsLongParser) ParseLong(term []byte) (int64, error) {
+	if shift, err := util.PrefixCodedLongShift(term); err != nil {
+		return 0, err
+	} else if shift > 0 {
+		return 0, ErrStopFillCache
+	}
+	return util.PrefixCodedToLong(term)
+}
+
+func (p numericUtilsLongParser) String() string {
+	return "FieldCache.NUMERIC_UTILS_LONG_PARSER"
+}
+
+/*
+A parser instance for float values encoded with util.NumericUtils,
+e.g. when indexed via FloatField/NumericTokenStream.
+*/
+var NUMERIC_UTILS_FLOAT_PARSER = FloatParser(numericUtilsFloatParser{})
+
+type numericUtilsFloatParser struct{}
+
+func (p numericUtilsFloatParser) ParseFloat(term []byte) (float32, error) {
+	v, err := NUMERIC_UTILS_INT_PARSER.ParseInt(term)
+	return util.SortableIntToFloat(v), err
+}
+
+func (p numericUtilsFloatParser) String() string {
+	return "FieldCache.NUMERIC_UTILS_FLOAT_PARSER"
+}
+
+/*
+A parser instance for double values encoded with util.NumericUtils,
+e.g. when indexed via DoubleField/NumericTokenStream.
+*/
+var NUMERIC_UTILS_DOUBLE_PARSER = DoubleParser(numericUtilsDoubleParser{})
+
+type numericUtilsDoubleParser struct{}
+
+func (p numericUtilsDoubleParser) ParseDouble(term []byte) (float64, error) {
+	v, err := NUMERIC_UTILS_LONG_PARSER.ParseLong(term)
+	return util.SortableLongToDouble(v), err
+}
+
+func (p numericUtilsDoubleParser) String() string {
+	return "FieldCache.NUMERIC_UTILS_DOUBLE_PARSER"
+}
+
+// Returns true if the error of a default parser means the terms are
+// not decimal numbers, and should be parsed as prefix coded ones.
+func isNumberFormatError(err error) bool {
+	_, ok := err.(*strconv.NumError)
+	return ok
+}
+
+// search/FieldCacheImpl.java
+
+// The kinds of the values of a cache entry.
+const (
+	fieldCacheDocsWithField = iota
+	fieldCacheInts
+	fieldCacheLongs
+	fieldCacheFloats
+	fieldCacheDoubles
+	fieldCacheTermsIndex
+	fieldCacheDocTermOrds
+)
+
+// The key of a cache entry of a segment.
+type fieldCacheKey struct {
+	field  string
+	kind   int
+	parser interface{}
+}
+
+/*
+The default FieldCache implementation. The values are created outside
+of the lock, so two goroutines may un-invert the same field at the
+same time; the last one wins.
+*/
+type fieldCacheImpl struct {
+	lock sync.Mutex
+	// the entries of each reader core
+	caches map[interface{}]map[fieldCacheKey]interface{}
+}
+
+func newFieldCacheImpl() *fieldCacheImpl {
+	return &fieldCacheImpl{caches: make(map[interface{}]map[fieldCacheKey]interface{})}
+}
+
+func (c *fieldCacheImpl) PurgeAllCaches() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.caches = make(map[interface{}]map[fieldCacheKey]interface{})
+}
+
+func (c *fieldCacheImpl) PurgeByCacheKey(coreCacheKey interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.caches, coreCacheKey)
+}
+
+// Returns the number of the cached entries, of all the cores.
+func (c *fieldCacheImpl) size() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	n := 0
+	for _, entries := range c.caches {
+		n += len(entries)
+	}
+	return n
+}
+
+// Returns the cached value of key, or else caches the one created by
+// create().
+func (c *fieldCacheImpl) get(reader index.AtomicReader, key fieldCacheKey,
+	create func() (interface{}, error)) (interface{}, error) {
+
+	coreKey := reader.CoreCacheKey()
+	c.lock.Lock()
+	value, ok := c.caches[coreKey][key]
+	c.lock.Unlock()
+	if ok {
+		return value, nil
+	}
+	value, err := create()
+	if err != nil {
+		return nil, err
+	}
+	c.put(reader, key, value)
+	return value, nil
+}
+
+func (c *fieldCacheImpl) put(reader index.AtomicReader, key fieldCacheKey, value interface{}) {
+	coreKey := reader.CoreCacheKey()
+	c.lock.Lock()
+	entries, ok := c.caches[coreKey]
+	if !ok {
+		entries = make(map[fieldCacheKey]interface{})
+		c.caches[coreKey] = entries
+	}
+	entries[key] = value
+	c.lock.Unlock()
+	if !ok {
+		// first time this reader is using FieldCache
+		c.initReader(reader)
+	}
+}
+
+// Registers the listener which purges the entries of the reader, when
+// its core is closed.
+func (c *fieldCacheImpl) initReader(reader index.AtomicReader) {
+	if sr, ok := reader.(*index.SegmentReader); ok {
+		sr.AddCoreClosedListener((*fieldCachePurgeCoreListener)(c))
+		return
+	}
+	// we have a slow reader of some sort, try to register a purge
+	// event rather than relying on gc
+	if key, ok := reader.CoreCacheKey().(index.IndexReader); ok {
+		key.AddReaderClosedListener((*fieldCachePurgeReaderListener)(c))
+		return
+	}
+	// last chance
+	reader.AddReaderClosedListener((*fieldCachePurgeReaderListener)(c))
+}
+
+type fieldCachePurgeCoreListener fieldCacheImpl
+
+func (l *fieldCachePurgeCoreListener) OnClose(r *index.SegmentReader) {
+	(*fieldCacheImpl)(l).PurgeByCacheKey(r.CoreCacheKey())
+}
+
+type fieldCachePurgeReaderListener fieldCacheImpl
+
+func (l *fieldCachePurgeReaderListener) OnClose(r index.IndexReader) {
+	(*fieldCacheImpl)(l).PurgeByCacheKey(r.CoreCacheKey())
+}
+
+/*
+Un-inverts the indexed terms of field: calls setTerm() with each term
+in order, then setDoc() with each of its documents. Returns the
+documents with a term.
+*/
+func uninvert(reader index.AtomicReader, field string,
+	setTerm func(term []byte) error, setDoc func(doc int)) (util.Bits, error) {
+
+	maxDoc := reader.MaxDoc()
+	terms := reader.Terms(field)
+	if terms == nil {
+		return util.MatchNoBits(maxDoc), nil
+	}
+	docsWithField := NewFixedBitSetDocIdSet(util.NewFixedBitSet(maxDoc))
+	numSet := 0
+	termsEnum := terms.Iterator(nil)
+	docs := index.DOCS_ENUM_EMPTY
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			return nil, err
+		}
+		if term == nil {
+			break
+		}
+		if err = setTerm(term); err == ErrStopFillCache {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		docs = termsEnum.DocsByFlags(nil, docs, 0)
+		for doc, more := docs.NextDoc(); more; doc, more = docs.NextDoc() {
+			setDoc(doc)
+			if !docsWithField.Get(doc) {
+				docsWithField.Set(doc)
+				numSet++
+			}
+		}
+	}
+	if numSet == maxDoc {
+		// the cardinality of the bits is maxDoc, so all documents have
+		// a value
+		return util.MatchAllBits(maxDoc), nil
+	}
+	return docsWithField, nil
+}
+
+// Returns true if the field of the reader has doc values, so all
+// documents have a value.
+func hasDocValues(reader index.AtomicReader, field string) (bool, error) {
+	if dv, err := reader.NumericDocValues(field); dv != nil || err != nil {
+		return dv != nil, err
+	}
+	if dv, err := reader.BinaryDocValues(field); dv != nil || err != nil {
+		return dv != nil, err
+	}
+	dv, err := reader.SortedDocValues(field)
+	return dv != nil, err
+}
+
+func (c *fieldCacheImpl) DocsWithField(reader index.AtomicReader, field string) (util.Bits, error) {
+	if ok, err := hasDocValues(reader, field); ok || err != nil {
+		return util.MatchAllBits(reader.MaxDoc()), err
+	}
+	value, err := c.get(reader, fieldCacheKey{field, fieldCacheDocsWithField, nil}, func() (interface{}, error) {
+		return uninvert(reader, field, func(term []byte) error { return nil }, func(doc int) {})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(util.Bits), nil
+}
+
+/*
+Un-inverts field with the given parse function, and caches the
+documents with a value if setDocsWithField is true.
+*/
+func (c *fieldCacheImpl) uninvertValues(reader index.AtomicReader, field string, setDocsWithField bool,
+	setTerm func(term []byte) error, setDoc func(doc int)) error {
+
+	docsWithField, err := uninvert(reader, field, setTerm, setDoc)
+	if err != nil {
+		return err
+	}
+	if setDocsWithField {
+		c.put(reader, fieldCacheKey{field, fieldCacheDocsWithField, nil}, docsWithField)
+	}
+	return nil
+}
+
+type intsFromArray []int32
+
+func (a intsFromArray) Get(docID int) int32 { return a[docID] }
+
+type intsFromDocValues struct{ index.NumericDocValues }
+
+func (v intsFromDocValues) Get(docID int) int32 { return int32(v.NumericDocValues.Get(docID)) }
+
+func (c *fieldCacheImpl) Ints(reader index.AtomicReader, field string, parser IntParser, setDocsWithField bool) (FieldCacheInts, error) {
+	if dv, err := reader.NumericDocValues(field); dv != nil || err != nil {
+		return intsFromDocValues{dv}, err
+	}
+	value, err := c.get(reader, fieldCacheKey{field, fieldCacheInts, parser}, func() (interface{}, error) {
+		values := make(intsFromArray, reader.MaxDoc())
+		uninvertWith := func(parser IntParser) error {
+			var currentValue int32
+			return c.uninvertValues(reader, field, setDocsWithField, func(term []byte) (err error) {
+				currentValue, err = parser.ParseInt(term)
+				return err
+			}, func(doc int) {
+				values[doc] = currentValue
+			})
+		}
+		if parser != nil {
+			return values, uninvertWith(parser)
+		}
+		err := uninvertWith(DEFAULT_INT_PARSER)
+		if isNumberFormatError(err) {
+			// the terms are not decimal numbers
+			for i := range values {
+				values[i] = 0
+			}
+			err = uninvertWith(NUMERIC_UTILS_INT_PARSER)
+		}
+		return values, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(intsFromArray), nil
+}
+
+type longsFromArray []int64
+
+func (a longsFromArray) Get(docID int) int64 { return a[docID] }
+
+func (c *fieldCacheImpl) Longs(reader index.AtomicReader, field string, parser LongParser, setDocsWithField bool) (FieldCacheLongs, error) {
+	if dv, err := reader.NumericDocValues(field); dv != nil || err != nil {
+		return dv, err
+	}
+	value, err := c.get(reader, fieldCacheKey{field, fieldCacheLongs, parser}, func() (interface{}, error) {
+		values := make(longsFromArray, reader.MaxDoc())
+		uninvertWith := func(parser LongParser) error {
+			var currentValue int64
+			return c.uninvertValues(reader, field, setDocsWithField, func(term []byte) (err error) {
+				currentValue, err = parser.ParseLong(term)
+				return err
+			}, func(doc int) {
+				values[doc] = currentValue
+			})
+		}
+		if parser != nil {
+			return values, uninvertWith(parser)
+		}
+		err := uninvertWith(DEFAULT_LONG_PARSER)
+		if isNumberFormatError(err) {
+			// the terms are not decimal numbers
+			for i := range values {
+				values[i] = 0
+			}
+			err = uninvertWith(NUMERIC_UTILS_LONG_PARSER)
+		}
+		return values, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(longsFromArray), nil
+}
+
+type floatsFromArray []float32
+
+func (a floatsFromArray) Get(docID int) float32 { return a[docID] }
+
+// The doc values of floats hold their bits.
+type floatsFromDocValues struct{ index.NumericDocValues }
+
+func (v floatsFromDocValues) Get(docID int) float32 {
+	return math.Float32frombits(uint32(v.NumericDocValues.Get(docID)))
+}
+
+func (c *fieldCacheImpl) Floats(reader index.AtomicReader, field string, parser FloatParser, setDocsWithField bool) (FieldCacheFloats, error) {
+	if dv, err := reader.NumericDocValues(field); dv != nil || err != nil {
+		return floatsFromDocValues{dv}, err
+	}
+	value, err := c.get(reader, fieldCacheKey{field, fieldCacheFloats, parser}, func() (interface{}, error) {
+		values := make(floatsFromArray, reader.MaxDoc())
+		uninvertWith := func(parser FloatParser) error {
+			var currentValue float32
+			return c.uninvertValues(reader, field, setDocsWithField, func(term []byte) (err error) {
+				currentValue, err = parser.ParseFloat(term)
+				return err
+			}, func(doc int) {
+				values[doc] = currentValue
+			})
+		}
+		if parser != nil {
+			return values, uninvertWith(parser)
+		}
+		err := uninvertWith(DEFAULT_FLOAT_PARSER)
+		if isNumberFormatError(err) {
+			// the terms are not decimal numbers
+			for i := range values {
+				values[i] = 0
+			}
+			err = uninvertWith(NUMERIC_UTILS_FLOAT_PARSER)
+		}
+		return values, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(floatsFromArray), nil
+}
+
+type doublesFromArray []float64
+
+func (a doublesFromArray) Get(docID int) float64 { return a[docID] }
+
+// The doc values of doubles hold their bits.
+type doublesFromDocValues struct{ index.NumericDocValues }
+
+func (v doublesFromDocValues) Get(docID int) float64 {
+	return math.Float64frombits(uint64(v.NumericDocValues.Get(docID)))
+}
+
+func (c *fieldCacheImpl) Doubles(reader index.AtomicReader, field string, parser DoubleParser, setDocsWithField bool) (FieldCacheDoubles, error) {
+	if dv, err := reader.NumericDocValues(field); dv != nil || err != nil {
+		return doublesFromDocValues{dv}, err
+	}
+	value, err := c.get(reader, fieldCacheKey{field, fieldCacheDoubles, parser}, func() (interface{}, error) {
+		values := make(doublesFromArray, reader.MaxDoc())
+		uninvertWith := func(parser DoubleParser) error {
+			var currentValue float64
+			return c.uninvertValues(reader, field, setDocsWithField, func(term []byte) (err error) {
+				currentValue, err = parser.ParseDouble(term)
+				return err
+			}, func(doc int) {
+				values[doc] = currentValue
+			})
+		}
+		if parser != nil {
+			return values, uninvertWith(parser)
+		}
+		err := uninvertWith(DEFAULT_DOUBLE_PARSER)
+		if isNumberFormatError(err) {
+			// the terms are not decimal numbers
+			for i := range values {
+				values[i] = 0
+			}
+			err = uninvertWith(NUMERIC_UTILS_DOUBLE_PARSER)
+		}
+		return values, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(doublesFromArray), nil
+}
+
+func (c *fieldCacheImpl) TermsIndex(reader index.AtomicReader, field string) (index.SortedDocValues, error) {
+	if dv, err := reader.SortedDocValues(field); dv != nil || err != nil {
+		return dv, err
+	}
+	value, err := c.get(reader, fieldCacheKey{field, fieldCacheTermsIndex, nil}, func() (interface{}, error) {
+		docToOrd := make([]int, reader.MaxDoc())
+		for i := range docToOrd {
+			docToOrd[i] = -1
+		}
+		ans := &uninvertedTermsIndex{docToOrd: docToOrd}
+		_, err := uninvert(reader, field, func(term []byte) error {
+			ans.terms = append(ans.terms, append([]byte(nil), term...))
+			return nil
+		}, func(doc int) {
+			docToOrd[doc] = len(ans.terms) - 1
+		})
+		return ans, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*uninvertedTermsIndex), nil
+}
+
+// The SortedDocValues of the indexed terms of a field.
+type uninvertedTermsIndex struct {
+	terms    [][]byte
+	docToOrd []int
+}
+
+func (v *uninvertedTermsIndex) Get(docID int) []byte {
+	if ord := v.docToOrd[docID]; ord >= 0 {
+		return v.terms[ord]
+	}
+	return nil
+}
+
+func (v *uninvertedTermsIndex) Ord(docID int) int {
+	return v.docToOrd[docID]
+}
+
+func (v *uninvertedTermsIndex) LookupOrd(ord int) []byte {
+	return v.terms[ord]
+}
+
+func (v *uninvertedTermsIndex) ValueCount() int {
+	return len(v.terms)
+}
+
+// search/DocTermOrds.java
+
+// The un-inverted terms of a multi-valued field.
+type docTermOrds struct {
+	terms [][]byte
+	// the ords of the terms of each doc, in order
+	docToOrds [][]int32
+}
+
+func (c *fieldCacheImpl) DocTermOrds(reader index.AtomicReader, field string) (index.SortedSetDocValues, error) {
+	if dv, err := reader.SortedSetDocValues(field); dv != nil || err != nil {
+		return dv, err
+	}
+	value, err := c.get(reader, fieldCacheKey{field, fieldCacheDocTermOrds, nil}, func() (interface{}, error) {
+		ans := &docTermOrds{docToOrds: make([][]int32, reader.MaxDoc())}
+		_, err := uninvert(reader, field, func(term []byte) error {
+			ans.terms = append(ans.terms, append([]byte(nil), term...))
+			return nil
+		}, func(doc int) {
+			ans.docToOrds[doc] = append(ans.docToOrds[doc], int32(len(ans.terms)-1))
+		})
+		return ans, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &docTermOrdsIterator{ords: value.(*docTermOrds)}, nil
+}
+
+// The SortedSetDocValues of docTermOrds.
+type docTermOrdsIterator struct {
+	ords *docTermOrds
+	// the remaining ords of the current document
+	current []int32
+}
+
+func (it *docTermOrdsIterator) NextOrd() int64 {
+	if len(it.current) == 0 {
+		return index.NO_MORE_ORDS
+	}
+	ord := it.current[0]
+	it.current = it.current[1:]
+	return int64(ord)
+}
+
+func (it *docTermOrdsIterator) SetDocument(docID int) {
+	it.current = it.ords.docToOrds[docID]
+}
+
+func (it *docTermOrdsIterator) LookupOrd(ord int64) []byte {
+	return it.ords.terms[ord]
+}
+
+func (it *docTermOrdsIterator) ValueCount() int64 {
+	return int64(len(it.ords.terms))
+}
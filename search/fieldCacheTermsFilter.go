@@ -0,0 +1,175 @@
+package search
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+)
+
+// search/FieldCacheTermsFilter.java
+
+/*
+A Filter that only accepts documents whose single term value in the
+specified field is contained in the provided set of allowed terms.
+
+This is the same functionality as TermsFilter, except this filter
+requires that the field contains only a single term for all
+documents. Because of drastically different implementations, they
+also have different performance characteristics, as described below.
+
+The terms index of the field, i.e. the ord of the term of each
+document, is read from the SortedDocValues of the field, or else
+un-inverted from its postings by the FieldCache. With each search,
+this filter translates the specified set of terms into a private bit
+set keyed by term number per segment. Then, during matching, the term number for
+each docID is retrieved from the terms index and then checked for
+inclusion using the bit set. As docIDs are simply scanned linearly,
+an index with a great many small documents may find this linear scan
+too costly.
+
+In contrast, TermsFilter builds up a bit set, keyed by docID, every
+time it's created, by enumerating through all matching docs using
+DocsEnum to seek and scan through each term's docID list. While there
+is no linear scan of all docIDs, this approach requires a number of
+"disk seeks" in proportion to the number of terms. If you are
+matching only a very small number of terms, and those terms in turn
+match a very small number of documents, TermsFilter may perform
+faster.
+
+Which filter is best is very application dependent.
+*/
+type FieldCacheTermsFilter struct {
+	field string
+	terms [][]byte
+}
+
+func NewFieldCacheTermsFilter(field string, terms ...[]byte) *FieldCacheTermsFilter {
+	return &FieldCacheTermsFilter{field, terms}
+}
+
+// Same as NewFieldCacheTermsFilter, with the terms as strings.
+func NewFieldCacheTermsFilterOfStrings(field string, terms ...string) *FieldCacheTermsFilter {
+	bytes := make([][]byte, len(terms))
+	for i, term := range terms {
+		bytes[i] = []byte(term)
+	}
+	return &FieldCacheTermsFilter{field, bytes}
+}
+
+func (f *FieldCacheTermsFilter) DocIdSet(ctx index.AtomicReaderContext, acceptDocs util.Bits) (DocIdSet, error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	fcsi, err := FIELD_CACHE_DEFAULT.TermsIndex(reader, f.field)
+	if err != nil {
+		return nil, err
+	}
+	bits := NewFixedBitSetDocIdSet(util.NewFixedBitSet(fcsi.ValueCount()))
+	matched := false
+	for _, term := range f.terms {
+		if ord := lookupTerm(fcsi, term); ord >= 0 {
+			bits.Set(ord)
+			matched = true
+		}
+	}
+	if !matched {
+		return nil, nil
+	}
+	return &fieldCacheDocIdSet{reader.MaxDoc(), acceptDocs, func(doc int) bool {
+		ord := fcsi.Ord(doc)
+		// ord -1 means the document has no value, which never matches
+		return ord >= 0 && bits.Get(ord)
+	}}, nil
+}
+
+func (f *FieldCacheTermsFilter) String() string {
+	var buf bytes.Buffer
+	for _, term := range f.terms {
+		if buf.Len() > 0 {
+			buf.WriteString(" ")
+		}
+		buf.WriteString(f.field)
+		buf.WriteString(":")
+		buf.Write(term)
+	}
+	return buf.String()
+}
+
+/*
+If key exists, returns its ordinal, else returns -insertionPoint-1,
+like sort.Search.
+*/
+func lookupTerm(dv index.SortedDocValues, key []byte) int {
+	n := dv.ValueCount()
+	ord := sort.Search(n, func(i int) bool {
+		return bytes.Compare(dv.LookupOrd(i), key) >= 0
+	})
+	if ord < n && bytes.Equal(dv.LookupOrd(ord), key) {
+		return ord
+	}
+	return -ord - 1
+}
+
+// search/FieldCacheDocIdSet.java
+
+/*
+Base class for DocIdSet to be used with the field cache. The
+implementation of its iterator is very stupid and slow if the
+implementation of the matchDoc() method is not optimized, as
+iterators simply increment the document id until matchDoc(int)
+returns true. Because of this matchDoc(int) must be as fast as
+possible and in no case do any I/O.
+*/
+type fieldCacheDocIdSet struct {
+	maxDoc     int
+	acceptDocs util.Bits
+	// this method checks, if a doc is a hit
+	matchDoc func(doc int) bool
+}
+
+func (s *fieldCacheDocIdSet) Bits() util.Bits {
+	return s
+}
+
+func (s *fieldCacheDocIdSet) Get(doc int) bool {
+	return s.matchDoc(doc) && (s.acceptDocs == nil || s.acceptDocs.Get(doc))
+}
+
+func (s *fieldCacheDocIdSet) Length() int {
+	return s.maxDoc
+}
+
+func (s *fieldCacheDocIdSet) Iterator() (index.DocIdSetIterator, error) {
+	return &fieldCacheDocIdSetIterator{s, -1}, nil
+}
+
+// Scans the documents, one by one.
+type fieldCacheDocIdSetIterator struct {
+	owner *fieldCacheDocIdSet
+	doc   int
+}
+
+func (it *fieldCacheDocIdSetIterator) DocId() int {
+	return it.doc
+}
+
+func (it *fieldCacheDocIdSetIterator) Freq() int {
+	return 1
+}
+
+func (it *fieldCacheDocIdSetIterator) NextDoc() (int, bool) {
+	return it.Advance(it.doc + 1)
+}
+
+func (it *fieldCacheDocIdSetIterator) Cost() int64 {
+	return int64(it.owner.maxDoc)
+}
+
+func (it *fieldCacheDocIdSetIterator) Advance(target int) (int, bool) {
+	for it.doc = target; it.doc < it.owner.maxDoc; it.doc++ {
+		if it.owner.Get(it.doc) {
+			return it.doc, true
+		}
+	}
+	it.doc = index.NO_MORE_DOCS
+	return it.doc, false
+}
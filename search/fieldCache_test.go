@@ -0,0 +1,212 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"testing"
+	"time"
+)
+
+// Opens a searcher over documents with numeric fields, indexed as
+// trie terms or decimal text, and doc values. The third document has
+// none of them.
+func newFieldCacheTestSearcher(t *testing.T) (*IndexSearcher, func()) {
+	ints := []int{3, -7, 0, 1 << 20}
+	longs := []int64{1 << 40, -5, 0, 2}
+	floats := []float32{1.5, -0.5, 0, 3}
+	doubles := []float64{-2.25, 1e100, 0, 4}
+	texts := []string{"12", "-4", "", "7"}
+	docs := make([][]document.IndexableField, len(ints))
+	for i := range docs {
+		docs[i] = []document.IndexableField{
+			document.NewTextField("body", "x", document.STORE_NO),
+			document.NewNumericDocValuesField("dv", int64(10*i)),
+		}
+		if i == 2 {
+			continue
+		}
+		docs[i] = append(docs[i],
+			document.NewIntField("int", ints[i], document.STORE_NO),
+			document.NewLongField("long", longs[i], document.STORE_NO),
+			document.NewFloatField("float", floats[i], document.STORE_NO),
+			document.NewDoubleField("double", doubles[i], document.STORE_NO),
+			document.NewStringField("text", texts[i], document.STORE_NO))
+	}
+	return newTestSearcherOfDocs(t, docs...)
+}
+
+// Returns the values of the docs, by get().
+func fieldCacheValues(maxDoc int, get func(doc int) interface{}) string {
+	values := make([]interface{}, maxDoc)
+	for doc := range values {
+		values[doc] = get(doc)
+	}
+	return fmt.Sprint(values)
+}
+
+func TestFieldCache(t *testing.T) {
+	ss, cleanup := newFieldCacheTestSearcher(t)
+	defer cleanup()
+
+	reader := ss.TopReaderContext().Leaves()[0].Reader().(index.AtomicReader)
+	maxDoc := reader.MaxDoc()
+	cache := newFieldCacheImpl()
+
+	ints, err := cache.Ints(reader, "int", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[3 -7 0 1048576]", fieldCacheValues(maxDoc, func(doc int) interface{} { return ints.Get(doc) }))
+	longs, err := cache.Longs(reader, "long", NUMERIC_UTILS_LONG_PARSER, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[1099511627776 -5 0 2]", fieldCacheValues(maxDoc, func(doc int) interface{} { return longs.Get(doc) }))
+	floats, err := cache.Floats(reader, "float", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[1.5 -0.5 0 3]", fieldCacheValues(maxDoc, func(doc int) interface{} { return floats.Get(doc) }))
+	doubles, err := cache.Doubles(reader, "double", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[-2.25 1e+100 0 4]", fieldCacheValues(maxDoc, func(doc int) interface{} { return doubles.Get(doc) }))
+	assertEquals(t, 5, cache.size())
+
+	// decimal text
+	if ints, err = cache.Ints(reader, "text", nil, false); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[12 -4 0 7]", fieldCacheValues(maxDoc, func(doc int) interface{} { return ints.Get(doc) }))
+	if _, err = cache.Ints(reader, "text", NUMERIC_UTILS_INT_PARSER, false); err == nil {
+		t.Error("Expected error parsing text as prefix coded ints")
+	}
+	assertEquals(t, 6, cache.size())
+
+	// cached entries
+	if _, err = cache.Ints(reader, "int", nil, true); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 6, cache.size())
+
+	bits, err := cache.DocsWithField(reader, "int")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[true true false true]", fieldCacheValues(maxDoc, func(doc int) interface{} { return bits.Get(doc) }))
+	if bits, err = cache.DocsWithField(reader, "body"); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, util.MatchAllBits(maxDoc), bits)
+	if bits, err = cache.DocsWithField(reader, "nosuchfield"); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, util.MatchNoBits(maxDoc), bits)
+	assertEquals(t, 8, cache.size())
+
+	// doc values are not cached
+	if bits, err = cache.DocsWithField(reader, "dv"); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, util.MatchAllBits(maxDoc), bits)
+	if longs, err = cache.Longs(reader, "dv", nil, true); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[0 10 20 30]", fieldCacheValues(maxDoc, func(doc int) interface{} { return longs.Get(doc) }))
+	assertEquals(t, 8, cache.size())
+
+	cache.PurgeAllCaches()
+	assertEquals(t, 0, cache.size())
+}
+
+func TestFieldCacheTermsIndex(t *testing.T) {
+	ss, cleanup := newFieldCacheTestSearcher(t)
+	defer cleanup()
+
+	reader := ss.TopReaderContext().Leaves()[0].Reader().(index.AtomicReader)
+	cache := newFieldCacheImpl()
+	termsIndex, err := cache.TermsIndex(reader, "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, termsIndex.ValueCount())
+	assertEquals(t, "[1 0 -1 2]", fieldCacheValues(reader.MaxDoc(), func(doc int) interface{} {
+		return termsIndex.Ord(doc)
+	}))
+	assertEquals(t, "7", string(termsIndex.LookupOrd(2)))
+	assertEquals(t, 1, lookupTerm(termsIndex, []byte("12")))
+	assertEquals(t, -2, lookupTerm(termsIndex, []byte("0")))
+	assertEquals(t, 1, cache.size())
+}
+
+func TestFieldCacheDocTermOrds(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "b a", "e", "c c a", "d")
+	defer cleanup()
+
+	reader := ss.TopReaderContext().Leaves()[0].Reader().(index.AtomicReader)
+	cache := newFieldCacheImpl()
+	ords, err := cache.DocTermOrds(reader, "body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, int64(5), ords.ValueCount())
+	assertEquals(t, "[[0 1] [4] [0 2] [3]]", fieldCacheValues(reader.MaxDoc(), func(doc int) interface{} {
+		var docOrds []int64
+		ords.SetDocument(doc)
+		for ord := ords.NextOrd(); ord != index.NO_MORE_ORDS; ord = ords.NextOrd() {
+			docOrds = append(docOrds, ord)
+		}
+		return docOrds
+	}))
+	assertEquals(t, "c", string(ords.LookupOrd(2)))
+	assertEquals(t, 1, cache.size())
+}
+
+func TestFieldCachePurge(t *testing.T) {
+	ss, cleanup := newFieldCacheTestSearcher(t)
+
+	reader := ss.TopReaderContext().Leaves()[0].Reader().(index.AtomicReader)
+	cache := newFieldCacheImpl()
+	if _, err := cache.Ints(reader, "int", nil, true); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 2, cache.size())
+	cache.PurgeByCacheKey(reader.CoreCacheKey())
+	assertEquals(t, 0, cache.size())
+	if _, err := cache.TermsIndex(reader, "text"); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, cache.size())
+
+	// the entries are purged when the segment cores are closed
+	cleanup()
+	for i := 0; cache.size() > 0; i++ {
+		if i == 100 {
+			t.Fatalf("Expected purged cache, but %v entries", cache.size())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSearchSortedFieldCache(t *testing.T) {
+	ss, cleanup := newFieldCacheTestSearcher(t)
+	defer cleanup()
+
+	q := NewMatchAllDocsQuery()
+	for _, v := range []struct {
+		field string
+		typ   SortFieldType
+		hits  string
+	}{
+		{"int", SORT_FIELD_TYPE_INT, "[1 2 0 3] [-7 0 3 1048576]"},
+		{"long", SORT_FIELD_TYPE_LONG, "[1 2 3 0] [-5 0 2 1099511627776]"},
+		{"float", SORT_FIELD_TYPE_FLOAT, "[1 2 0 3] [-0.5 0 1.5 3]"},
+		{"double", SORT_FIELD_TYPE_DOUBLE, "[0 2 3 1] [-2.25 0 4 1e+100]"},
+		{"text", SORT_FIELD_TYPE_LONG, "[1 2 3 0] [-4 0 7 12]"},
+	} {
+		assertEquals(t, v.hits, sortedHits(t, ss, q, 10, NewSort(NewSortField(v.field, v.typ, false))))
+	}
+}
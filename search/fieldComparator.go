@@ -0,0 +1,712 @@
+package search
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+)
+
+// search/FieldComparator.java
+
+/*
+Expert: a FieldComparator compares hits so as to determine their
+sort order when collecting the top results with TopFieldCollector.
+The concrete public FieldComparator classes here correspond to the
+SortField types.
+
+This API is designed to achieve high performance sorting, by exposing
+a tight interaction with FieldValueHitQueue as it visits hits.
+Whenever a hit is competitive, it's enrolled into a virtual slot,
+which is an int ranging from 0 to numHits-1. The FieldComparator is
+made aware of segment transitions during searching in case any
+internal state it's tracking needs to be recomputed during these
+transitions.
+
+A comparator must define these functions:
+
+  - Compare() Compare a hit at 'slot a' with hit 'slot b'.
+  - SetBottom() This method is called by FieldValueHitQueue to notify
+    the FieldComparator of the current weakest ("bottom") slot. Note
+    that this slot may not hold the weakest value according to your
+    comparator, in cases where your comparator is not the primary one
+    (ie, is only used to break ties from the comparators before it).
+  - CompareBottom() Compare a new hit (docID) against the "weakest"
+    (bottom) entry in the queue.
+  - Copy() Installs a new hit into the priority queue. The
+    FieldValueHitQueue calls this method when a new hit is
+    competitive.
+  - SetNextReader() Invoked when the search is switching to the next
+    segment. You may need to update internal state of the comparator,
+    for example retrieving new values from the doc values.
+  - Value() Return the sort value stored in the specified slot. This
+    is only called at the end of the search, in order to populate
+    FieldDoc.Fields when returning the top results.
+*/
+type FieldComparator interface {
+	/*
+		Compare hit at slot1 with hit at slot2. Returns any N < 0 if
+		slot2's value is sorted after slot1, any N > 0 if the slot2's
+		value is sorted before slot1 and 0 if they are equal.
+	*/
+	Compare(slot1, slot2 int) int
+	/*
+		Set the bottom slot, ie the "weakest" (sorted last) entry in the
+		queue. When CompareBottom() is called, you should compare against
+		this slot. This will always be called before CompareBottom().
+	*/
+	SetBottom(slot int)
+	/*
+		Compare the bottom of the queue with doc. This will only invoked
+		after SetBottom() has been called. This should return the same
+		result as Compare(bottomSlot, otherSlot) as if doc were copied
+		into otherSlot.
+
+		For a search that hits many results, this method will be the
+		hotspot (invoked by far the most frequently).
+	*/
+	CompareBottom(doc int) int
+	/*
+		This method is called when a new hit is competitive. You should
+		copy any state associated with this document that will be
+		required for future comparisons, into the specified slot.
+	*/
+	Copy(slot, doc int)
+	/*
+		Set a new AtomicReaderContext. All subsequent docIDs are relative
+		to the current reader (you must add docBase if you need to map it
+		to a top-level docID).
+
+		Returns the comparator to use for this segment; most comparators
+		can just return themselves.
+	*/
+	SetNextReader(ctx index.AtomicReaderContext) (FieldComparator, error)
+	/*
+		Sets the Scorer to use in case a document's score is needed. The
+		default implementations ignore it.
+	*/
+	SetScorer(scorer Scorer)
+	// Return the actual value in the slot.
+	Value(slot int) interface{}
+}
+
+// search/FieldComparatorSource.java
+
+/*
+Provides a FieldComparator for custom field sorting, see
+NewCustomSortField().
+*/
+type FieldComparatorSource interface {
+	/*
+		Creates a comparator for the field in the given index, for the
+		top numHits hits, at position sortPos in the Sort.
+	*/
+	NewComparator(field string, numHits, sortPos int, reversed bool) FieldComparator
+}
+
+// search/FieldComparator.java/NumericComparator
+
+/*
+Base of the comparators of the numeric values of a field, read from
+its NumericDocValues, or else the FieldCache, which tracks which
+documents have no value when the SortField has a missing value.
+*/
+type numericComparator struct {
+	field           string
+	hasMissingValue bool
+	// nil if all the documents have a value
+	docsWithField util.Bits
+}
+
+/*
+Returns the NumericDocValues of the field of the segment, which have
+a value for all the documents, or nil if the field has none, in which
+case the values must be read from the FieldCache.
+*/
+func (c *numericComparator) docValues(reader index.AtomicReader) (index.NumericDocValues, error) {
+	c.docsWithField = nil
+	return reader.NumericDocValues(c.field)
+}
+
+// Reads the documents with a value of the segment, after its values
+// were read from the FieldCache.
+func (c *numericComparator) setDocsWithField(reader index.AtomicReader) (err error) {
+	if c.hasMissingValue {
+		if c.docsWithField, err = FIELD_CACHE_DEFAULT.DocsWithField(reader, c.field); err != nil {
+			return err
+		}
+		if _, ok := c.docsWithField.(util.MatchAllBits); ok {
+			c.docsWithField = nil
+		}
+	}
+	return nil
+}
+
+// Returns true if doc has a value, or there is no missing value.
+func (c *numericComparator) hasValue(doc int) bool {
+	return c.docsWithField == nil || c.docsWithField.Get(doc)
+}
+
+// search/FieldComparator.java/IntComparator
+
+// Parses field's values as int32 (using NumericDocValues or
+// FieldCache.Ints()) and sorts by ascending value.
+type intComparator struct {
+	numericComparator
+	currentValues FieldCacheInts
+	values        []int32
+	missingValue  int32
+	bottom        int32
+}
+
+func newIntComparator(numHits int, field string, hasMissingValue bool, missingValue int32) *intComparator {
+	return &intComparator{
+		numericComparator: numericComparator{field: field, hasMissingValue: hasMissingValue},
+		values:            make([]int32, numHits),
+		missingValue:      missingValue,
+	}
+}
+
+func (c *intComparator) docValue(doc int) int32 {
+	if c.hasValue(doc) {
+		return c.currentValues.Get(doc)
+	}
+	return c.missingValue
+}
+
+func (c *intComparator) Compare(slot1, slot2 int) int {
+	return compareInt64(int64(c.values[slot1]), int64(c.values[slot2]))
+}
+
+func (c *intComparator) CompareBottom(doc int) int {
+	return compareInt64(int64(c.bottom), int64(c.docValue(doc)))
+}
+
+func (c *intComparator) Copy(slot, doc int) {
+	c.values[slot] = c.docValue(doc)
+}
+
+func (c *intComparator) SetNextReader(ctx index.AtomicReaderContext) (FieldComparator, error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	if dv, err := c.docValues(reader); dv != nil || err != nil {
+		c.currentValues = intsFromDocValues{dv}
+		return c, err
+	}
+	// NOTE: must do this before calling setDocsWithField(), so the
+	// documents with a value are cached along with the values
+	values, err := FIELD_CACHE_DEFAULT.Ints(reader, c.field, nil, c.hasMissingValue)
+	if err != nil {
+		return nil, err
+	}
+	c.currentValues = values
+	return c, c.setDocsWithField(reader)
+}
+
+func (c *intComparator) SetBottom(slot int) {
+	c.bottom = c.values[slot]
+}
+
+func (c *intComparator) SetScorer(scorer Scorer) {}
+
+func (c *intComparator) Value(slot int) interface{} {
+	return c.values[slot]
+}
+
+// search/FieldComparator.java/FloatComparator
+
+/*
+Parses field's values as float32 (using NumericDocValues, which hold
+the bits of the floats, or FieldCache.Floats()) and sorts by
+ascending value.
+*/
+type floatComparator struct {
+	numericComparator
+	currentValues FieldCacheFloats
+	values        []float32
+	missingValue  float32
+	bottom        float32
+}
+
+func newFloatComparator(numHits int, field string, hasMissingValue bool, missingValue float32) *floatComparator {
+	return &floatComparator{
+		numericComparator: numericComparator{field: field, hasMissingValue: hasMissingValue},
+		values:            make([]float32, numHits),
+		missingValue:      missingValue,
+	}
+}
+
+func (c *floatComparator) docValue(doc int) float32 {
+	if c.hasValue(doc) {
+		return c.currentValues.Get(doc)
+	}
+	return c.missingValue
+}
+
+func (c *floatComparator) Compare(slot1, slot2 int) int {
+	return compareFloat32(c.values[slot1], c.values[slot2])
+}
+
+func (c *floatComparator) CompareBottom(doc int) int {
+	return compareFloat32(c.bottom, c.docValue(doc))
+}
+
+func (c *floatComparator) Copy(slot, doc int) {
+	c.values[slot] = c.docValue(doc)
+}
+
+func (c *floatComparator) SetNextReader(ctx index.AtomicReaderContext) (FieldComparator, error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	if dv, err := c.docValues(reader); dv != nil || err != nil {
+		c.currentValues = floatsFromDocValues{dv}
+		return c, err
+	}
+	// NOTE: must do this before calling setDocsWithField(), so the
+	// documents with a value are cached along with the values
+	values, err := FIELD_CACHE_DEFAULT.Floats(reader, c.field, nil, c.hasMissingValue)
+	if err != nil {
+		return nil, err
+	}
+	c.currentValues = values
+	return c, c.setDocsWithField(reader)
+}
+
+func (c *floatComparator) SetBottom(slot int) {
+	c.bottom = c.values[slot]
+}
+
+func (c *floatComparator) SetScorer(scorer Scorer) {}
+
+func (c *floatComparator) Value(slot int) interface{} {
+	return c.values[slot]
+}
+
+// search/FieldComparator.java/LongComparator
+
+// Parses field's values as int64 (using NumericDocValues or
+// FieldCache.Longs()) and sorts by ascending value.
+type longComparator struct {
+	numericComparator
+	currentValues FieldCacheLongs
+	values        []int64
+	missingValue  int64
+	bottom        int64
+}
+
+func newLongComparator(numHits int, field string, hasMissingValue bool, missingValue int64) *longComparator {
+	return &longComparator{
+		numericComparator: numericComparator{field: field, hasMissingValue: hasMissingValue},
+		values:            make([]int64, numHits),
+		missingValue:      missingValue,
+	}
+}
+
+func (c *longComparator) docValue(doc int) int64 {
+	if c.hasValue(doc) {
+		return c.currentValues.Get(doc)
+	}
+	return c.missingValue
+}
+
+func (c *longComparator) Compare(slot1, slot2 int) int {
+	return compareInt64(c.values[slot1], c.values[slot2])
+}
+
+func (c *longComparator) CompareBottom(doc int) int {
+	return compareInt64(c.bottom, c.docValue(doc))
+}
+
+func (c *longComparator) Copy(slot, doc int) {
+	c.values[slot] = c.docValue(doc)
+}
+
+func (c *longComparator) SetNextReader(ctx index.AtomicReaderContext) (FieldComparator, error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	if dv, err := c.docValues(reader); dv != nil || err != nil {
+		c.currentValues = dv
+		return c, err
+	}
+	// NOTE: must do this before calling setDocsWithField(), so the
+	// documents with a value are cached along with the values
+	values, err := FIELD_CACHE_DEFAULT.Longs(reader, c.field, nil, c.hasMissingValue)
+	if err != nil {
+		return nil, err
+	}
+	c.currentValues = values
+	return c, c.setDocsWithField(reader)
+}
+
+func (c *longComparator) SetBottom(slot int) {
+	c.bottom = c.values[slot]
+}
+
+func (c *longComparator) SetScorer(scorer Scorer) {}
+
+func (c *longComparator) Value(slot int) interface{} {
+	return c.values[slot]
+}
+
+// search/FieldComparator.java/DoubleComparator
+
+/*
+Parses field's values as float64 (using NumericDocValues, which hold
+the bits of the doubles, or FieldCache.Doubles()) and sorts by
+ascending value.
+*/
+type doubleComparator struct {
+	numericComparator
+	currentValues FieldCacheDoubles
+	values        []float64
+	missingValue  float64
+	bottom        float64
+}
+
+func newDoubleComparator(numHits int, field string, hasMissingValue bool, missingValue float64) *doubleComparator {
+	return &doubleComparator{
+		numericComparator: numericComparator{field: field, hasMissingValue: hasMissingValue},
+		values:            make([]float64, numHits),
+		missingValue:      missingValue,
+	}
+}
+
+func (c *doubleComparator) docValue(doc int) float64 {
+	if c.hasValue(doc) {
+		return c.currentValues.Get(doc)
+	}
+	return c.missingValue
+}
+
+func (c *doubleComparator) Compare(slot1, slot2 int) int {
+	return compareFloat64(c.values[slot1], c.values[slot2])
+}
+
+func (c *doubleComparator) CompareBottom(doc int) int {
+	return compareFloat64(c.bottom, c.docValue(doc))
+}
+
+func (c *doubleComparator) Copy(slot, doc int) {
+	c.values[slot] = c.docValue(doc)
+}
+
+func (c *doubleComparator) SetNextReader(ctx index.AtomicReaderContext) (FieldComparator, error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	if dv, err := c.docValues(reader); dv != nil || err != nil {
+		c.currentValues = doublesFromDocValues{dv}
+		return c, err
+	}
+	// NOTE: must do this before calling setDocsWithField(), so the
+	// documents with a value are cached along with the values
+	values, err := FIELD_CACHE_DEFAULT.Doubles(reader, c.field, nil, c.hasMissingValue)
+	if err != nil {
+		return nil, err
+	}
+	c.currentValues = values
+	return c, c.setDocsWithField(reader)
+}
+
+func (c *doubleComparator) SetBottom(slot int) {
+	c.bottom = c.values[slot]
+}
+
+func (c *doubleComparator) SetScorer(scorer Scorer) {}
+
+func (c *doubleComparator) Value(slot int) interface{} {
+	return c.values[slot]
+}
+
+func compareInt64(a, b int64) int {
+	// TODO: there are sneaky non-branch ways to compute -1/1/0 sign
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// search/FieldComparator.java/RelevanceComparator
+
+/*
+Sorts by descending relevance. NOTE: if you are sorting only by
+descending relevance and then secondarily by ascending docID,
+performance is faster using TopScoreDocCollector directly (which
+IndexSearcher.Search() uses when no Sort is specified).
+*/
+type relevanceComparator struct {
+	scores []float32
+	bottom float32
+	scorer Scorer
+}
+
+func newRelevanceComparator(numHits int) *relevanceComparator {
+	return &relevanceComparator{scores: make([]float32, numHits)}
+}
+
+func (c *relevanceComparator) Compare(slot1, slot2 int) int {
+	return compareFloat32(c.scores[slot2], c.scores[slot1])
+}
+
+func (c *relevanceComparator) CompareBottom(doc int) int {
+	return compareFloat32(c.scorer.Score(), c.bottom)
+}
+
+func (c *relevanceComparator) Copy(slot, doc int) {
+	c.scores[slot] = c.scorer.Score()
+}
+
+func (c *relevanceComparator) SetNextReader(ctx index.AtomicReaderContext) (FieldComparator, error) {
+	return c, nil
+}
+
+func (c *relevanceComparator) SetBottom(slot int) {
+	c.bottom = c.scores[slot]
+}
+
+func (c *relevanceComparator) SetScorer(scorer Scorer) {
+	c.scorer = scorer
+}
+
+func (c *relevanceComparator) Value(slot int) interface{} {
+	return c.scores[slot]
+}
+
+// Like Java's Float.compare(), where NaN is greater than any other
+// value.
+func compareFloat32(a, b float32) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	case a == b:
+		return 0
+	case a != a: // NaN
+		if b != b {
+			return 0
+		}
+		return 1
+	}
+	return -1
+}
+
+// Like Java's Double.compare(), where NaN is greater than any other
+// value.
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	case a == b:
+		return 0
+	case a != a: // NaN
+		if b != b {
+			return 0
+		}
+		return 1
+	}
+	return -1
+}
+
+// search/FieldComparator.java/DocComparator
+
+// Sorts by ascending docID
+type docComparator struct {
+	docIDs  []int
+	docBase int
+	bottom  int
+}
+
+func newDocComparator(numHits int) *docComparator {
+	return &docComparator{docIDs: make([]int, numHits)}
+}
+
+func (c *docComparator) Compare(slot1, slot2 int) int {
+	// No overflow risk because docIDs are non-negative
+	return c.docIDs[slot1] - c.docIDs[slot2]
+}
+
+func (c *docComparator) CompareBottom(doc int) int {
+	// No overflow risk because docIDs are non-negative
+	return c.bottom - (c.docBase + doc)
+}
+
+func (c *docComparator) Copy(slot, doc int) {
+	c.docIDs[slot] = c.docBase + doc
+}
+
+func (c *docComparator) SetNextReader(ctx index.AtomicReaderContext) (FieldComparator, error) {
+	// TODO: can we "map" our docIDs to the current reader? saves
+	// having to then subtract on every compare call
+	c.docBase = ctx.DocBase
+	return c, nil
+}
+
+func (c *docComparator) SetBottom(slot int) {
+	c.bottom = c.docIDs[slot]
+}
+
+func (c *docComparator) SetScorer(scorer Scorer) {}
+
+func (c *docComparator) Value(slot int) interface{} {
+	return c.docIDs[slot]
+}
+
+// search/FieldComparator.java/TermOrdValComparator
+
+/*
+Sorts by field's natural Term sort order, using ordinals. This is
+functionally equivalent to a comparison of the []byte values, but it
+first resolves the string to their relative ordinal positions (using
+the SortedDocValues of the field, or else FieldCache.TermsIndex()),
+and does most comparisons using the ordinals. For medium to large
+results, this comparator will be much faster than comparing the
+values. For very small result sets it may be slower.
+
+Documents without a value are sorted first, or last if missingLast.
+*/
+type termOrdValComparator struct {
+	ords      []int
+	values    [][]byte
+	readerGen []int
+
+	currentReaderGen int
+	termsIndex       index.SortedDocValues
+	field            string
+
+	bottomSlot       int
+	bottomOrd        int
+	bottomSameReader bool
+	bottomValue      []byte
+
+	// -1 if missing values are sorted first, else 1
+	missingSortCmp int
+	// the ord of the documents without a value
+	missingOrd int
+	// returns the terms index of the field of a segment
+	sortedDocValues func(reader index.AtomicReader, field string) (index.SortedDocValues, error)
+}
+
+func newTermOrdValComparator(numHits int, field string, missingLast bool) *termOrdValComparator {
+	ans := &termOrdValComparator{
+		ords:             make([]int, numHits),
+		values:           make([][]byte, numHits),
+		readerGen:        make([]int, numHits),
+		currentReaderGen: -1,
+		field:            field,
+		bottomSlot:       -1,
+		missingSortCmp:   -1,
+		missingOrd:       -1,
+		sortedDocValues:  termsIndex,
+	}
+	if missingLast {
+		ans.missingSortCmp = 1
+		ans.missingOrd = math.MaxInt32
+	}
+	return ans
+}
+
+/*
+Returns the SortedDocValues of the field of the segment if any, or
+else its terms index from the FieldCache.
+*/
+func termsIndex(reader index.AtomicReader, field string) (index.SortedDocValues, error) {
+	if dv, err := reader.SortedDocValues(field); dv != nil || err != nil {
+		return dv, err
+	}
+	return FIELD_CACHE_DEFAULT.TermsIndex(reader, field)
+}
+
+func (c *termOrdValComparator) Compare(slot1, slot2 int) int {
+	if c.readerGen[slot1] == c.readerGen[slot2] {
+		return compareInt64(int64(c.ords[slot1]), int64(c.ords[slot2]))
+	}
+	val1, val2 := c.values[slot1], c.values[slot2]
+	if val1 == nil {
+		if val2 == nil {
+			return 0
+		}
+		return c.missingSortCmp
+	} else if val2 == nil {
+		return -c.missingSortCmp
+	}
+	return bytes.Compare(val1, val2)
+}
+
+// Returns the ord of doc in the current segment.
+func (c *termOrdValComparator) ord(doc int) int {
+	if ord := c.termsIndex.Ord(doc); ord != -1 {
+		return ord
+	}
+	return c.missingOrd
+}
+
+func (c *termOrdValComparator) CompareBottom(doc int) int {
+	docOrd := c.ord(doc)
+	if c.bottomSameReader {
+		// ord is precisely comparable, even in the equal case
+		return compareInt64(int64(c.bottomOrd), int64(docOrd))
+	} else if c.bottomOrd >= docOrd {
+		// the equals case always means bottom is > doc (because we set
+		// bottomOrd to the lower bound in SetBottom())
+		return 1
+	}
+	return -1
+}
+
+func (c *termOrdValComparator) Copy(slot, doc int) {
+	ord := c.ord(doc)
+	c.ords[slot] = ord
+	if ord == c.missingOrd {
+		c.values[slot] = nil
+	} else {
+		c.values[slot] = append(c.values[slot][:0], c.termsIndex.LookupOrd(ord)...)
+	}
+	c.readerGen[slot] = c.currentReaderGen
+}
+
+func (c *termOrdValComparator) SetNextReader(ctx index.AtomicReaderContext) (FieldComparator, error) {
+	termsIndex, err := c.sortedDocValues(ctx.Reader().(index.AtomicReader), c.field)
+	if err != nil {
+		return nil, err
+	}
+	c.termsIndex = termsIndex
+	c.currentReaderGen++
+	if c.bottomSlot != -1 {
+		c.SetBottom(c.bottomSlot)
+	}
+	return c, nil
+}
+
+func (c *termOrdValComparator) SetBottom(slot int) {
+	c.bottomSlot = slot
+	c.bottomValue = c.values[slot]
+	if c.currentReaderGen == c.readerGen[slot] {
+		c.bottomOrd = c.ords[slot]
+		c.bottomSameReader = true
+		return
+	}
+	if c.bottomValue == nil {
+		// missing value has the same ord in every reader
+		c.ords[slot] = c.missingOrd
+		c.bottomOrd = c.missingOrd
+		c.bottomSameReader = true
+		c.readerGen[slot] = c.currentReaderGen
+		return
+	}
+	if index := lookupTerm(c.termsIndex, c.bottomValue); index < 0 {
+		c.bottomOrd = -index - 2
+		c.bottomSameReader = false
+	} else {
+		c.bottomOrd = index
+		// exact value match
+		c.bottomSameReader = true
+		c.readerGen[slot] = c.currentReaderGen
+		c.ords[slot] = c.bottomOrd
+	}
+}
+
+func (c *termOrdValComparator) SetScorer(scorer Scorer) {}
+
+func (c *termOrdValComparator) Value(slot int) interface{} {
+	return c.values[slot]
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/Filter.java
+
+/*
+Abstract base class for restricting which documents may be returned
+during searching.
+*/
+type Filter interface {
+	/*
+		Creates a DocIdSet enumerating the documents that should be
+		permitted in search results. NOTE: nil can be returned if no
+		documents are accepted by this Filter.
+
+		Note: This method will be called once per segment in the index
+		during searching. The returned DocIdSet must refer to document
+		IDs for that segment, not for the top-level reader.
+
+		acceptDocs are the Bits that represent the allowable docs to
+		match (typically deleted docs but possibly filtering other
+		documents), which the returned DocIdSet must honor.
+	*/
+	DocIdSet(ctx index.AtomicReaderContext, acceptDocs util.Bits) (DocIdSet, error)
+}
+
+// search/DocIdSet.java
+
+/*
+A DocIdSet contains a set of doc ids. Implementing classes must only
+implement Iterator() to provide access to the set.
+*/
+type DocIdSet interface {
+	/*
+		Provides a DocIdSetIterator to access the set. This
+		implementation can return nil if there are no docs that match.
+	*/
+	Iterator() (index.DocIdSetIterator, error)
+	/*
+		Optionally provides a Bits interface for random access to
+		matching documents, or nil, if this DocIdSet does not support
+		random access.
+	*/
+	Bits() util.Bits
+}
@@ -0,0 +1,216 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/FilteredQuery.java
+
+/*
+A query that applies a filter to the results of another query.
+
+Note: the bits are retrieved from the filter each time this query is
+used in a search - use a CachingWrapperFilter to avoid regenerating
+bits every time.
+*/
+type FilteredQuery struct {
+	*AbstractQuery
+	query  Query
+	filter Filter
+}
+
+/*
+Constructs a new query which applies a filter to the results of the
+original query. Filter.DocIdSet() will be called every time this
+query is used in a search.
+*/
+func NewFilteredQuery(query Query, filter Filter) *FilteredQuery {
+	if query == nil || filter == nil {
+		panic("Query and filter cannot be null.")
+	}
+	ans := &FilteredQuery{query: query, filter: filter}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+// Returns this FilteredQuery's (unfiltered) Query
+func (q *FilteredQuery) Query() Query {
+	return q.query
+}
+
+// Returns this FilteredQuery's filter
+func (q *FilteredQuery) Filter() Filter {
+	return q.filter
+}
+
+// Rewrites the query. If the wrapped is an instance of
+// MatchAllDocsQuery it returns a ConstantScoreQuery. Otherwise it
+// returns a new FilteredQuery wrapping the rewritten query.
+func (q *FilteredQuery) Rewrite(r index.IndexReader) (Query, error) {
+	queryRewritten, err := q.query.Rewrite(r)
+	if err != nil {
+		return nil, err
+	}
+	if queryRewritten != q.query {
+		// rewrite to a new FilteredQuery wrapping the rewritten query
+		rewritten := NewFilteredQuery(queryRewritten, q.filter)
+		rewritten.SetBoost(q.boost)
+		return rewritten, nil
+	}
+	// nothing to rewrite, we are done!
+	return q, nil
+}
+
+// Returns a Weight that applies the filter to the enclosed query's
+// Weight. This is accomplished by overriding the Scorer returned by
+// the Weight.
+func (q *FilteredQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	w, err := q.query.CreateWeight(ss)
+	if err != nil {
+		return nil, err
+	}
+	return &filteredWeight{q, w}, nil
+}
+
+func (q *FilteredQuery) ToString(field string) string {
+	return fmt.Sprintf("filtered(%v)->%v%v", q.query.ToString(field), q.filter, boostString(q.boost))
+}
+
+// search/FilteredQuery.java/Weight
+
+type filteredWeight struct {
+	query  *FilteredQuery
+	weight Weight
+}
+
+func (w *filteredWeight) Query() Query {
+	return w.query
+}
+
+func (w *filteredWeight) ValueForNormalization() float32 {
+	return w.weight.ValueForNormalization() * w.query.boost * w.query.boost // boost sub-weight
+}
+
+func (w *filteredWeight) Normalize(norm float32, topLevelBoost float32) {
+	w.weight.Normalize(norm, topLevelBoost*w.query.boost) // incorporate boost
+}
+
+func (w *filteredWeight) IsScoresDocsOutOfOrder() bool {
+	// the filtered scorer always leap-frogs in order
+	return false
+}
+
+func (w *filteredWeight) Explain(ctx index.AtomicReaderContext, doc int) (*Explanation, error) {
+	inner, err := w.weight.Explain(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	f := w.query.filter
+	docIdSet, err := f.DocIdSet(ctx, ctx.Reader().(index.AtomicReader).LiveDocs())
+	if err != nil {
+		return nil, err
+	}
+	if docIdSet != nil {
+		docIdSetIterator, err := docIdSet.Iterator()
+		if err != nil {
+			return nil, err
+		}
+		if docIdSetIterator != nil {
+			if next, _ := docIdSetIterator.Advance(doc); next == doc {
+				return inner, nil
+			}
+		}
+	}
+	result := NewExplanation(0, fmt.Sprintf("failure to match filter: %v", f))
+	result.AddDetail(inner)
+	return result, nil
+}
+
+// return a filtering scorer
+func (w *filteredWeight) Scorer(ctx index.AtomicReaderContext,
+	scoreDocsInOrder, topScorer bool, acceptDocs util.Bits) (Scorer, error) {
+	filterDocIdSet, err := w.query.filter.DocIdSet(ctx, acceptDocs)
+	if filterDocIdSet == nil || err != nil {
+		// this means the filter does not accept any documents.
+		return nil, err
+	}
+	filterIter, err := filterDocIdSet.Iterator()
+	if filterIter == nil || err != nil {
+		// this means the filter does not accept any documents.
+		return nil, err
+	}
+	// we pass nil as acceptDocs, as our filter has already respected acceptDocs, no need to do twice
+	scorer, err := w.weight.Scorer(ctx, true, false, nil)
+	if scorer == nil || err != nil {
+		return nil, err
+	}
+	return newLeapFrogScorer(w, scorer, filterIter), nil
+}
+
+// search/FilteredQuery.java/LeapFrogScorer
+
+/*
+A Scorer which advances the query's scorer and the filter's iterator
+in turns, each to the document of the other, until both are on the
+same document, which is a match.
+*/
+type leapFrogScorer struct {
+	*ScorerImpl
+	scorer     Scorer
+	filterIter index.DocIdSetIterator
+	doc        int
+}
+
+func newLeapFrogScorer(w Weight, scorer Scorer, filterIter index.DocIdSetIterator) *leapFrogScorer {
+	ans := &leapFrogScorer{scorer: scorer, filterIter: filterIter, doc: -1}
+	ans.ScorerImpl = NewScorer(ans, w)
+	return ans
+}
+
+func (s *leapFrogScorer) advanceToNextCommonDoc(primaryDoc int) (int, bool) {
+	secondaryDoc := s.filterIter.DocId()
+	for {
+		if primaryDoc < secondaryDoc {
+			primaryDoc, _ = s.scorer.Advance(secondaryDoc)
+		} else if primaryDoc == secondaryDoc {
+			s.doc = primaryDoc
+			return s.doc, s.doc != index.NO_MORE_DOCS
+		} else {
+			secondaryDoc, _ = s.filterIter.Advance(primaryDoc)
+		}
+	}
+}
+
+func (s *leapFrogScorer) NextDoc() (int, bool) {
+	doc, _ := s.scorer.NextDoc()
+	return s.advanceToNextCommonDoc(doc)
+}
+
+func (s *leapFrogScorer) Advance(target int) (int, bool) {
+	if target > s.doc {
+		doc, _ := s.scorer.Advance(target)
+		return s.advanceToNextCommonDoc(doc)
+	}
+	return s.NextDoc()
+}
+
+func (s *leapFrogScorer) DocId() int {
+	return s.doc
+}
+
+func (s *leapFrogScorer) Cost() int64 {
+	if cost := s.filterIter.Cost(); cost < s.scorer.Cost() {
+		return cost
+	}
+	return s.scorer.Cost()
+}
+
+func (s *leapFrogScorer) Freq() int {
+	return s.scorer.Freq()
+}
+
+func (s *leapFrogScorer) Score() float32 {
+	return s.scorer.Score()
+}
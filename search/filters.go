@@ -1,4 +0,0 @@
-package search
-
-type Filter interface {
-}
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// util/FixedBitSet.java
+
+/*
+A DocIdSet of the documents of a segment, with a bit per document in
+a FixedBitSet, which supports random access too.
+*/
+type FixedBitSetDocIdSet struct {
+	*util.FixedBitSet
+}
+
+func NewFixedBitSetDocIdSet(bits *util.FixedBitSet) *FixedBitSetDocIdSet {
+	return &FixedBitSetDocIdSet{bits}
+}
+
+func (s *FixedBitSetDocIdSet) Iterator() (index.DocIdSetIterator, error) {
+	return &fixedBitSetIterator{s.FixedBitSet, -1}, nil
+}
+
+func (s *FixedBitSetDocIdSet) Bits() util.Bits {
+	return s.FixedBitSet
+}
+
+// util/FixedBitSet.java/FixedBitSetIterator
+
+// Iterates the set bits of a FixedBitSet, in increasing order.
+type fixedBitSetIterator struct {
+	bits *util.FixedBitSet
+	doc  int
+}
+
+func (it *fixedBitSetIterator) DocId() int {
+	return it.doc
+}
+
+func (it *fixedBitSetIterator) Freq() int {
+	return 1
+}
+
+func (it *fixedBitSetIterator) NextDoc() (int, bool) {
+	return it.Advance(it.doc + 1)
+}
+
+func (it *fixedBitSetIterator) Cost() int64 {
+	return int64(it.bits.Length())
+}
+
+func (it *fixedBitSetIterator) Advance(target int) (int, bool) {
+	if it.doc = it.bits.NextSetBit(target); it.doc == -1 {
+		it.doc = index.NO_MORE_DOCS
+	}
+	return it.doc, it.doc != index.NO_MORE_DOCS
+}
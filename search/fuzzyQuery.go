@@ -0,0 +1,149 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util/automaton"
+	"unicode/utf8"
+)
+
+// search/FuzzyQuery.java
+
+const (
+	FUZZY_DEFAULT_MAX_EDITS      = automaton.LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE
+	FUZZY_DEFAULT_PREFIX_LENGTH  = 0
+	FUZZY_DEFAULT_MAX_EXPANSIONS = 50
+	FUZZY_DEFAULT_TRANSPOSITIONS = true
+)
+
+/*
+Implements the fuzzy search query. The similarity measurement is
+based on the Damerau-Levenshtein (optimal string alignment)
+algorithm, though you can explicitly choose classic Levenshtein by
+passing false to the transpositions parameter.
+
+This query uses MultiTermQuery's TopTermsBlendedFreqScoringRewrite
+as default. So terms will be collected and scored according to their
+edit distance. Only the top terms are used for building the
+BooleanQuery. It is not recommended to change the rewrite mode for
+fuzzy queries.
+
+At most, this query will match terms up to
+automaton.LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE edits. Higher
+distances (especially with transpositions enabled), are generally not
+useful and will match a significant amount of your term dictionary.
+If you really want this, consider using an n-gram indexing technique
+(such as the SpellChecker in the suggest module) instead.
+
+NOTE: terms of length 1 or 2 will sometimes not match because of how
+the scaled distance between two terms is computed. For a term to
+match, the edit distance between the terms must be less than the
+minimum length term (either the input term, or the candidate term).
+For example, FuzzyQuery on term "abcd" with maxEdits=2 will not match
+an indexed term "ab", and FuzzyQuery on term "a" with maxEdits=2 will
+not match an indexed term "abc".
+*/
+type FuzzyQuery struct {
+	*MultiTermQuery
+	maxEdits       int
+	maxExpansions  int
+	transpositions bool
+	prefixLength   int
+	term           index.Term
+}
+
+/*
+Create a new FuzzyQuery that will match terms with an edit distance
+of at most maxEdits to term. If a prefixLength > 0 is specified, a
+common prefix of that length is also required.
+
+maxEdits must be between 0 and
+automaton.LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE, prefixLength and
+maxExpansions, the maximum number of terms to match, must not be
+negative, otherwise an error is returned. If transpositions is true,
+transpositions are treated as a primitive edit operation, otherwise
+they are two edits.
+*/
+func NewFuzzyQueryWithArgs(term index.Term, maxEdits, prefixLength, maxExpansions int,
+	transpositions bool) (*FuzzyQuery, error) {
+	if maxEdits < 0 || maxEdits > automaton.LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE {
+		return nil, errors.New(fmt.Sprintf("maxEdits must be between 0 and %v",
+			automaton.LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE))
+	}
+	if prefixLength < 0 {
+		return nil, errors.New("prefixLength cannot be negative.")
+	}
+	if maxExpansions < 0 {
+		return nil, errors.New("maxExpansions cannot be negative.")
+	}
+	ans := &FuzzyQuery{
+		maxEdits:       maxEdits,
+		maxExpansions:  maxExpansions,
+		transpositions: transpositions,
+		prefixLength:   prefixLength,
+		term:           term,
+	}
+	ans.MultiTermQuery = NewMultiTermQuery(ans, term.Field)
+	ans.SetRewriteMethod(NewTopTermsBlendedFreqScoringRewrite(maxExpansions))
+	return ans, nil
+}
+
+// Calls NewFuzzyQueryWithArgs(term, maxEdits, prefixLength,
+// FUZZY_DEFAULT_MAX_EXPANSIONS, FUZZY_DEFAULT_TRANSPOSITIONS).
+func NewFuzzyQueryWithPrefix(term index.Term, maxEdits, prefixLength int) (*FuzzyQuery, error) {
+	return NewFuzzyQueryWithArgs(term, maxEdits, prefixLength,
+		FUZZY_DEFAULT_MAX_EXPANSIONS, FUZZY_DEFAULT_TRANSPOSITIONS)
+}
+
+// Calls NewFuzzyQueryWithArgs(term, FUZZY_DEFAULT_MAX_EDITS,
+// FUZZY_DEFAULT_PREFIX_LENGTH, FUZZY_DEFAULT_MAX_EXPANSIONS,
+// FUZZY_DEFAULT_TRANSPOSITIONS), which never fails.
+func NewFuzzyQuery(term index.Term) *FuzzyQuery {
+	ans, err := NewFuzzyQueryWithArgs(term, FUZZY_DEFAULT_MAX_EDITS,
+		FUZZY_DEFAULT_PREFIX_LENGTH, FUZZY_DEFAULT_MAX_EXPANSIONS, FUZZY_DEFAULT_TRANSPOSITIONS)
+	if err != nil {
+		panic(err) // should not happen
+	}
+	return ans
+}
+
+// Returns the maximum number of edit distances allowed for this
+// query to match.
+func (q *FuzzyQuery) MaxEdits() int {
+	return q.maxEdits
+}
+
+// Returns the non-fuzzy prefix length. This is the number of
+// characters at the start of a term that must be identical (not
+// fuzzy) to the query term if the query is to match that term.
+func (q *FuzzyQuery) PrefixLength() int {
+	return q.prefixLength
+}
+
+// Returns true if transpositions should be treated as a primitive
+// edit operation. If this is false, comparisons will implement the
+// classic Levenshtein algorithm.
+func (q *FuzzyQuery) Transpositions() bool {
+	return q.transpositions
+}
+
+// Returns the pattern term.
+func (q *FuzzyQuery) Term() index.Term {
+	return q.term
+}
+
+func (q *FuzzyQuery) TermsEnum(terms index.Terms) (index.TermsEnum, error) {
+	if q.maxEdits == 0 || q.prefixLength >= utf8.RuneCount(q.term.Bytes) { // can only match if it's exact
+		return index.NewSingleTermsEnum(terms.Iterator(nil), q.term.Bytes), nil
+	}
+	return NewFuzzyTermsEnum(terms, q.term, q.maxEdits, q.prefixLength, q.transpositions)
+}
+
+func (q *FuzzyQuery) ToString(field string) string {
+	s := fmt.Sprintf("%v~%v%v", string(q.term.Bytes), q.maxEdits, boostString(q.boost))
+	if q.term.Field != field {
+		s = q.term.Field + ":" + s
+	}
+	return s
+}
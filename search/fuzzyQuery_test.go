@@ -0,0 +1,88 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"testing"
+)
+
+func newTestFuzzyQuery(t *testing.T, text string, maxEdits, prefixLength, maxExpansions int,
+	transpositions bool) *FuzzyQuery {
+	q, err := NewFuzzyQueryWithArgs(index.NewTerm("body", text), maxEdits, prefixLength, maxExpansions, transpositions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func TestFuzzyQuery(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "brown", "crown", "frown", "brwon", "clown", "down town", "blue")
+	defer cleanup()
+
+	for _, v := range []struct {
+		q        Query
+		expected string
+	}{
+		{newTestFuzzyQuery(t, "brown", 0, 0, 50, true), "[0]"},
+		{newTestFuzzyQuery(t, "brown", 1, 0, 50, true), "[0 1 2 3]"},
+		{newTestFuzzyQuery(t, "brown", 1, 0, 50, false), "[0 1 2]"},
+		{newTestFuzzyQuery(t, "brown", 2, 0, 50, false), "[0 1 2 3 4 5]"},
+		{newTestFuzzyQuery(t, "brown", 1, 1, 50, true), "[0 3]"},
+		{newTestFuzzyQuery(t, "brown", 2, 5, 50, true), "[0]"},
+		// the exact term, and the least of the terms of 1 edit
+		{newTestFuzzyQuery(t, "brown", 1, 0, 2, true), "[0 3]"},
+		{NewFuzzyQuery(index.NewTerm("body", "towm")), "[5]"},
+		{NewFuzzyQuery(index.NewTerm("nosuchfield", "brown")), "[]"},
+	} {
+		assertEquals(t, v.expected, matchingDocs(t, ss, v.q))
+	}
+
+	// the exact match ranks highest, as the frequencies are blended
+	docs, err := ss.SearchTop(newTestFuzzyQuery(t, "crown", 1, 0, 50, true), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, docs.ScoreDocs[0].Doc)
+	assertEquals(t, 4, docs.TotalHits)
+	for _, hit := range docs.ScoreDocs[2:] {
+		assertEquals(t, docs.ScoreDocs[1].Score, hit.Score)
+	}
+}
+
+func TestFuzzyQueryRewrite(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "brown", "crown", "crown", "brwon")
+	defer cleanup()
+
+	q := newTestFuzzyQuery(t, "brown", 1, 0, 50, true)
+	rewritten, err := q.Rewrite(ss.IndexReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "body:brown body:brwon^0.8 body:crown^0.8", rewritten.ToString(""))
+	for _, c := range rewritten.(*BooleanQuery).Clauses() {
+		// the doc freq of the most frequent term
+		assertEquals(t, 2, c.Query().(*TermQuery).docFreq)
+	}
+
+	// bounded by the max clause count
+	defer SetMaxClauseCount(MaxClauseCount())
+	SetMaxClauseCount(1)
+	if rewritten, err = q.Rewrite(ss.IndexReader()); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "body:brown", rewritten.ToString(""))
+}
+
+func TestFuzzyQueryArgs(t *testing.T) {
+	term := index.NewTerm("body", "brown")
+	for _, v := range [][3]int{{-1, 0, 50}, {3, 0, 50}, {1, -1, 50}, {1, 0, -1}} {
+		if _, err := NewFuzzyQueryWithArgs(term, v[0], v[1], v[2], true); err == nil {
+			t.Errorf("Expected error of args %v", v)
+		}
+	}
+
+	q := NewFuzzyQuery(term)
+	assertEquals(t, "body:brown~2", q.ToString(""))
+	q.SetBoost(2)
+	assertEquals(t, "brown~2^2", q.ToString("body"))
+	assertEquals(t, "TopTermsBlendedFreqScoringRewrite(50)", q.RewriteMethod().(*TopTermsRewrite).String())
+}
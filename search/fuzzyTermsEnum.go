@@ -0,0 +1,99 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util/automaton"
+	"unicode/utf8"
+)
+
+// FuzzyTermsEnum.java
+
+/*
+Subclass of TermsEnum for enumerating all terms that are similar to
+the specified filter term.
+
+Term enumerations are always ordered by Comparator(). Each term in
+the enumeration is greater than all that precede it.
+
+The terms are found by intersecting the Levenshtein automaton of the
+maximum edit distance with the term dictionary, so only the matching
+parts of the dictionary are visited. The exact edit distance of each
+term is then computed using the automata of smaller distances, and
+scaled to a rough similarity, see Boost().
+*/
+type FuzzyTermsEnum struct {
+	*index.FilteredTermsEnumImpl
+
+	// matchers[i] accepts all terms within edit distance i
+	matchers []*automaton.Automaton
+
+	termLength int
+	boost      float32
+}
+
+/*
+Constructor for enumeration of all terms from specified terms which
+are within maxEdits of the specified term. The first prefixLength
+characters must match exactly; transpositions are counted as a single
+edit if transpositions is true.
+
+Returns error if maxEdits is negative or exceeds
+automaton.LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE.
+*/
+func NewFuzzyTermsEnum(terms index.Terms, term index.Term, maxEdits, prefixLength int,
+	transpositions bool) (*FuzzyTermsEnum, error) {
+	if maxEdits < 0 || maxEdits > automaton.LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE {
+		return nil, errors.New(fmt.Sprintf("maxEdits must be between 0 and %v, but was %v",
+			automaton.LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE, maxEdits))
+	}
+	text := string(term.Bytes)
+	la := automaton.NewLevenshteinAutomata(text, prefixLength, transpositions)
+	matchers := make([]*automaton.Automaton, maxEdits+1)
+	for i := range matchers {
+		a, err := la.ToAutomaton(i)
+		if err != nil {
+			return nil, err
+		}
+		matchers[i] = a
+	}
+	ans := &FuzzyTermsEnum{matchers: matchers, termLength: utf8.RuneCountInString(text)}
+	tenum := index.NewAutomatonTermsEnum(terms.Iterator(nil), matchers[maxEdits])
+	ans.FilteredTermsEnumImpl = index.NewFilteredTermsEnum(ans, tenum, false)
+	return ans, nil
+}
+
+// Computes the exact edit distance of the term, which is already
+// accepted by the automaton of the maximum edit distance.
+func (e *FuzzyTermsEnum) Accept(term []byte) index.AcceptStatus {
+	ed := len(e.matchers) - 1
+	for ed > 0 && e.matchers[ed-1].RunBytes(term) {
+		ed--
+	}
+	if ed == 0 {
+		// exact match
+		e.boost = 1
+		return index.ACCEPT_STATUS_YES
+	}
+	length := utf8.RuneCount(term)
+	if e.termLength < length {
+		length = e.termLength
+	}
+	// scale to a rough similarity
+	similarity := 1 - float32(ed)/float32(length)
+	if similarity <= 0 {
+		return index.ACCEPT_STATUS_NO
+	}
+	e.boost = similarity
+	return index.ACCEPT_STATUS_YES
+}
+
+/*
+Returns the similarity of the current term to the filter term, in
+(0, 1], 1 being an exact match. Do not call this when the enum is
+unpositioned.
+*/
+func (e *FuzzyTermsEnum) Boost() float32 {
+	return e.boost
+}
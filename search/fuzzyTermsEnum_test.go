@@ -0,0 +1,82 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util/automaton"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFuzzyTermsEnum(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := r.Context().Leaves()[0].Reader().(index.AtomicReader).Terms("content")
+
+	var all []string
+	termsEnum := terms.Iterator(nil)
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if term == nil {
+			break
+		}
+		all = append(all, string(term))
+	}
+
+	for _, target := range []string{"bat", "belfry", "tower", "zzz", ""} {
+		for maxEdits := 0; maxEdits <= automaton.LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE; maxEdits++ {
+			a, err := automaton.NewLevenshteinAutomata(target, 1, true).ToAutomaton(maxEdits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// a term of edit distance maxEdits is dropped when it's as
+			// short as maxEdits, since its similarity is 0
+			var expected []string
+			for _, term := range all {
+				if a.Run(term) && (term == target || utf8.RuneCountInString(term) > maxEdits && utf8.RuneCountInString(target) > maxEdits) {
+					expected = append(expected, term)
+				}
+			}
+
+			fte, err := NewFuzzyTermsEnum(terms, index.NewTerm("content", target), maxEdits, 1, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual []string
+			for {
+				term, err := fte.Next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if term == nil {
+					break
+				}
+				actual = append(actual, string(term))
+				if boost := fte.Boost(); boost <= 0 || boost > 1 || (boost == 1) != (string(term) == target) {
+					t.Errorf("Unexpected boost %v of '%v' for '%v'", boost, string(term), target)
+				}
+			}
+			if len(expected) != len(actual) {
+				t.Fatalf("Expected %v for '%v'~%v, but was %v", expected, target, maxEdits, actual)
+			}
+			for i, v := range expected {
+				assertEquals(t, v, actual[i])
+			}
+		}
+	}
+}
+
+func TestFuzzyTermsEnumMaxEdits(t *testing.T) {
+	if _, err := NewFuzzyTermsEnum(nil, index.NewTerm("content", "bat"), 3, 0, true); err == nil {
+		t.Error("Should fail for maxEdits 3")
+	}
+}
@@ -0,0 +1,242 @@
+package search
+
+import (
+	"fmt"
+	"math"
+)
+
+// search/similarities/IBSimilarity.java
+
+/*
+Provides a framework for the family of information-based models, as
+described in Stéphane Clinchant and Eric Gaussier. 2010.
+Information-based models for ad hoc IR. In Proceeding of the 33rd
+international ACM SIGIR conference on Research and development in
+information retrieval (SIGIR '10). ACM, New York, NY, USA, 234-241.
+
+The retrieval function is of the form
+
+	RSV(q, d) = sum(-x^q_w log Prob(X_w >= t^d_w | lambda_w))
+
+where x^q_w is the query boost, X_w is a random variable that counts
+the occurrences of word w, t^d_w is the normalized term frequency and
+lambda_w is a parameter.
+
+The framework described in the paper has many similarities to the DFR
+framework (see DFRSimilarity). It is possible that the two Similarities
+will be merged at one point.
+
+To make this framework more flexible, the three components of the
+model are represented by the interfaces Distribution, Lambda and
+Normalization:
+
+Distribution: Probabilistic distribution used to model term occurrence
+
+  - DistributionLL: Log-logistic
+  - DistributionSPL: Smoothed power-law
+
+Lambda: lambda_w parameter of the probability distribution
+
+  - LambdaDF: N_w/N or average number of documents where w occurs
+  - LambdaTTF: F_w/N or average number of occurrences of w in the
+    collection
+
+Normalization: Term frequency normalization, any of the ones of
+DFRSimilarity.
+*/
+type IBSimilarity struct {
+	*SimilarityBase
+	// The probabilistic distribution used to model term occurrence.
+	distribution Distribution
+	// The lambda (lambda_w) parameter.
+	lambda Lambda
+	// The term frequency normalization.
+	normalization Normalization
+}
+
+/*
+Creates IBSimilarity from the three components.
+
+Note that none of the parameters may be nil: it panics otherwise. For
+no term frequency normalization, use NoNormalization.
+*/
+func NewIBSimilarity(distribution Distribution, lambda Lambda,
+	normalization Normalization) *IBSimilarity {
+	if distribution == nil || lambda == nil || normalization == nil {
+		panic("nil parameters not allowed.")
+	}
+	ans := &IBSimilarity{distribution: distribution, lambda: lambda, normalization: normalization}
+	ans.SimilarityBase = NewSimilarityBase(ans)
+	return ans
+}
+
+func (s *IBSimilarity) Score(stats *BasicStats, freq, docLen float32) float32 {
+	return stats.TotalBoost() * s.distribution.Score(stats,
+		s.normalization.Tfn(stats, freq, docLen), s.lambda.Lambda(stats))
+}
+
+func (s *IBSimilarity) Explain(expl *Explanation, stats *BasicStats, doc int, freq, docLen float32) {
+	if stats.TotalBoost() != 1 {
+		expl.AddDetail(NewExplanation(stats.TotalBoost(), "boost"))
+	}
+	normExpl := explainNormalization(s.normalization, stats, freq, docLen)
+	lambdaExpl := explainLambda(s.lambda, stats)
+	expl.AddDetail(normExpl)
+	expl.AddDetail(lambdaExpl)
+	expl.AddDetail(NewExplanation(s.distribution.Score(stats, normExpl.Value(), lambdaExpl.Value()),
+		simpleName(s.distribution)))
+}
+
+/*
+The name of IB methods follow the pattern IB <distribution>
+<lambda><normalization>. The name of the distribution is the same as
+in the original paper; for the names of lambda parameters, refer to
+Lambda.
+*/
+func (s *IBSimilarity) String() string {
+	return fmt.Sprintf("IB %v-%v%v", s.distribution, s.lambda, s.normalization)
+}
+
+// Returns the distribution
+func (s *IBSimilarity) Distribution() Distribution {
+	return s.distribution
+}
+
+// Returns the distribution's lambda parameter
+func (s *IBSimilarity) Lambda() Lambda {
+	return s.lambda
+}
+
+// Returns the term frequency normalization
+func (s *IBSimilarity) Normalization() Normalization {
+	return s.normalization
+}
+
+// search/similarities/Distribution.java
+
+/*
+The probabilistic distribution used to model term occurrence in
+information-based models.
+*/
+type Distribution interface {
+	// Computes the score.
+	Score(stats *BasicStats, tfn, lambda float32) float32
+	/*
+		Subclasses must override this method to return the name of the
+		distribution.
+	*/
+	String() string
+}
+
+// search/similarities/DistributionLL.java
+
+/*
+Log-logistic distribution.
+
+Unlike for DFR, the natural logarithm is used, as it is faster to
+compute and the original paper does not express any preference to a
+specific base.
+*/
+type DistributionLL struct{}
+
+func NewDistributionLL() DistributionLL {
+	return DistributionLL{}
+}
+
+func (d DistributionLL) Score(stats *BasicStats, tfn, lambda float32) float32 {
+	return float32(-math.Log(float64(lambda / (tfn + lambda))))
+}
+
+func (d DistributionLL) String() string {
+	return "LL"
+}
+
+// search/similarities/DistributionSPL.java
+
+/*
+The smoothed power-law (SPL) distribution for the information-based
+framework that is described in the original paper.
+
+Unlike for DFR, the natural logarithm is used, as it is faster to
+compute and the original paper does not express any preference to a
+specific base.
+*/
+type DistributionSPL struct{}
+
+func NewDistributionSPL() DistributionSPL {
+	return DistributionSPL{}
+}
+
+func (d DistributionSPL) Score(stats *BasicStats, tfn, lambda float32) float32 {
+	if lambda == 1 {
+		lambda = 0.99
+	}
+	l := float64(lambda)
+	return float32(-math.Log((math.Pow(l, float64(tfn/(tfn+1))) - l) / (1 - l)))
+}
+
+func (d DistributionSPL) String() string {
+	return "SPL"
+}
+
+// search/similarities/Lambda.java
+
+// The lambda (lambda_w) parameter in information-based models.
+type Lambda interface {
+	// Computes the lambda parameter.
+	Lambda(stats *BasicStats) float32
+	/*
+		Subclasses must override this method to return the code of the
+		lambda formula. Since the original paper is not very clear on
+		this matter, and also uses the DFR naming scheme incorrectly, the
+		codes here were chosen arbitrarily.
+	*/
+	String() string
+}
+
+// Returns an explanation for the lambda parameter.
+func explainLambda(l Lambda, stats *BasicStats) *Explanation {
+	result := NewExplanation(l.Lambda(stats), "lambda, computed from: ")
+	switch l.(type) {
+	case LambdaDF:
+		result.AddDetail(NewExplanation(float32(stats.DocFreq()), "docFreq"))
+	case LambdaTTF:
+		result.AddDetail(NewExplanation(float32(stats.TotalTermFreq()), "totalTermFreq"))
+	}
+	result.AddDetail(NewExplanation(float32(stats.NumberOfDocuments()), "numberOfDocuments"))
+	return result
+}
+
+// search/similarities/LambdaDF.java
+
+// Computes lambda as (docFreq+1) / (numberOfDocuments+1).
+type LambdaDF struct{}
+
+func NewLambdaDF() LambdaDF {
+	return LambdaDF{}
+}
+
+func (l LambdaDF) Lambda(stats *BasicStats) float32 {
+	return float32(stats.DocFreq()+1) / float32(stats.NumberOfDocuments()+1)
+}
+
+func (l LambdaDF) String() string {
+	return "D"
+}
+
+// search/similarities/LambdaTTF.java
+
+// Computes lambda as (totalTermFreq+1) / (numberOfDocuments+1).
+type LambdaTTF struct{}
+
+func NewLambdaTTF() LambdaTTF {
+	return LambdaTTF{}
+}
+
+func (l LambdaTTF) Lambda(stats *BasicStats) float32 {
+	return float32(stats.TotalTermFreq()+1) / float32(stats.NumberOfDocuments()+1)
+}
+
+func (l LambdaTTF) String() string {
+	return "L"
+}
@@ -0,0 +1,137 @@
+package search
+
+import (
+	"sync"
+)
+
+// search/LiveFieldValues.java
+
+/*
+Tracks live field values across NRT reader reopens. This holds a map
+for all updated ids since the last reader reopen. Once the NRT reader
+is reopened, it prunes the map. This means you must reopen your NRT
+reader periodically otherwise the RAM consumption of this class will
+grow unbounded!
+
+NOTE: you must ensure the same id is never updated at the same time
+by two goroutines, because in this case you cannot in general know
+which thread "won".
+*/
+type LiveFieldValues struct {
+	mgr *SearcherManager
+	/*
+		Called when the id/value was already flushed & opened in an NRT
+		IndexSearcher, to go look up the value (eg, via doc values,
+		field cache, stored fields, etc.).
+	*/
+	lookupFromSearcher func(s *IndexSearcher, id string) (interface{}, error)
+
+	lock    sync.RWMutex // guards the maps
+	current map[string]interface{}
+	old     map[string]interface{}
+}
+
+/*
+Creates a LiveFieldValues over the searchers of mgr, looking the
+values up with lookupFromSearcher once they are visible in the
+current searcher.
+*/
+func NewLiveFieldValues(mgr *SearcherManager,
+	lookupFromSearcher func(s *IndexSearcher, id string) (interface{}, error)) *LiveFieldValues {
+	ans := &LiveFieldValues{
+		mgr:                mgr,
+		lookupFromSearcher: lookupFromSearcher,
+		current:            make(map[string]interface{}),
+		old:                make(map[string]interface{}),
+	}
+	mgr.AddListener(ans)
+	return ans
+}
+
+// Stops tracking the reopens of the SearcherManager.
+func (v *LiveFieldValues) Close() error {
+	v.mgr.RemoveListener(v)
+	return nil
+}
+
+func (v *LiveFieldValues) BeforeRefresh() error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.old = v.current
+	// Start sending all updates after this point to the new map.
+	// While reopen is running, any lookup will first try this new
+	// map, then fallback to old, then to the current searcher:
+	v.current = make(map[string]interface{})
+	return nil
+}
+
+func (v *LiveFieldValues) AfterRefresh(didRefresh bool) error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	// Now drop all the old values because they are now visible via
+	// the searcher that was just opened; if didRefresh is false, it's
+	// possible old has some entries in it, which is fine: it means
+	// they were actually already included in the previously opened
+	// reader. So we can safely clear old here:
+	v.old = make(map[string]interface{})
+	return nil
+}
+
+/*
+Call this after you've successfully added a document to the index,
+to record what value you just set the field to.
+*/
+func (v *LiveFieldValues) Add(id string, value interface{}) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.current[id] = value
+}
+
+/*
+Call this after you've successfully deleted a document from the
+index, so Get() doesn't find it anymore, even before the reopen.
+*/
+func (v *LiveFieldValues) Delete(id string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.current[id] = deletedFieldValue{}
+}
+
+// Marks the ids deleted since the last reopen in the maps.
+type deletedFieldValue struct{}
+
+// Returns the [approximate] number of id/value pairs buffered in RAM.
+func (v *LiveFieldValues) Size() int {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	return len(v.current) + len(v.old)
+}
+
+// Returns the current value for this id, or nil if the id isn't in
+// the index.
+func (v *LiveFieldValues) Get(id string) (interface{}, error) {
+	// First try to get the "live" value:
+	v.lock.RLock()
+	value, ok := v.current[id]
+	if !ok {
+		value, ok = v.old[id]
+	}
+	v.lock.RUnlock()
+	if ok {
+		if _, deleted := value.(deletedFieldValue); deleted {
+			// Deleted but the deletion is not yet reflected in the reader:
+			return nil, nil
+		}
+		return value, nil
+	}
+
+	// It either does not exist in the index, or, it was already
+	// flushed & NRT reader was opened on the segment, so fallback to
+	// current searcher:
+	s, err := v.mgr.Acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer v.mgr.Release(s)
+	return v.lookupFromSearcher(s, id)
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"testing"
+)
+
+func TestLiveFieldValues(t *testing.T) {
+	w, cleanup := newTestWriter(t)
+	defer cleanup()
+	mgr, err := NewSearcherManagerFromWriter(w, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Close()
+
+	lookups := 0
+	values := NewLiveFieldValues(mgr, func(s *IndexSearcher, id string) (interface{}, error) {
+		lookups++
+		docs, err := s.SearchTop(NewTermQuery(index.NewTerm("id", id)), 1)
+		if err != nil || docs.TotalHits == 0 {
+			return nil, err
+		}
+		doc, err := s.Doc(docs.ScoreDocs[0].Doc)
+		if err != nil {
+			return nil, err
+		}
+		return doc.Get("value"), nil
+	})
+	defer values.Close()
+
+	add := func(id, value string) {
+		if err := w.AddDocument([]document.IndexableField{
+			document.NewStringField("id", id, document.STORE_NO),
+			document.NewStringField("value", value, document.STORE_YES),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		values.Add(id, value)
+	}
+	get := func(id string) interface{} {
+		value, err := values.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	// the values are visible before the reopen
+	add("1", "a")
+	add("2", "b")
+	assertEquals(t, "a", get("1"))
+	assertEquals(t, "b", get("2"))
+	assertEquals(t, nil, get("3"))
+	assertEquals(t, 1, lookups)
+	assertEquals(t, 2, values.Size())
+
+	// and looked up in the searcher after it
+	if err = mgr.MaybeRefreshBlocking(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, values.Size())
+	assertEquals(t, "a", get("1"))
+	assertEquals(t, "b", get("2"))
+	assertEquals(t, 3, lookups)
+
+	add("3", "c")
+	assertEquals(t, "c", get("3"))
+	assertEquals(t, 3, lookups)
+
+	// a deleted id is not found, although the searcher still has it
+	values.Delete("1")
+	assertEquals(t, nil, get("1"))
+	assertEquals(t, 3, lookups)
+	values.Delete("3")
+	assertEquals(t, nil, get("3"))
+	add("3", "d")
+	assertEquals(t, "d", get("3"))
+	assertEquals(t, 3, lookups)
+	assertEquals(t, 2, values.Size())
+
+	// the values are not tracked anymore once closed
+	values.Close()
+	if err = mgr.MaybeRefreshBlocking(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 2, values.Size())
+}
@@ -0,0 +1,217 @@
+package search
+
+import (
+	"fmt"
+	"math"
+)
+
+// search/similarities/LMSimilarity.java
+
+/*
+The hook of LMSimilarity, implemented by the language models which
+embed it.
+*/
+type lmSimilarity interface {
+	similarityBase
+	/*
+		Returns the name of the LM method. The values of the parameters
+		should be included as well.
+
+		Used in String().
+	*/
+	Name() string
+}
+
+/*
+Abstract superclass for language modeling Similarities. The following
+inner types are introduced:
+
+  - CollectionModel, an interface for the collection model, which
+    computes the probability of the term in the collection, used by
+    the models to smooth the probabilities of the documents.
+  - DefaultCollectionModel, an implementation of the former, that
+    computes the term probability as the number of occurrences of the
+    term in the collection, divided by the total number of tokens.
+*/
+type LMSimilarity struct {
+	*SimilarityBase
+	self lmSimilarity
+	// The collection model.
+	collectionModel CollectionModel
+}
+
+// Creates a new instance with the specified collection language model,
+// or DefaultCollectionModel if nil.
+func NewLMSimilarity(self lmSimilarity, collectionModel CollectionModel) *LMSimilarity {
+	if collectionModel == nil {
+		collectionModel = NewDefaultCollectionModel()
+	}
+	return &LMSimilarity{NewSimilarityBase(self), self, collectionModel}
+}
+
+// Returns the probability of the term of stats in the collection.
+func (s *LMSimilarity) collectionProbability(stats *BasicStats) float32 {
+	return s.collectionModel.ComputeProbability(stats)
+}
+
+func (s *LMSimilarity) Explain(expl *Explanation, stats *BasicStats, doc int, freq, docLen float32) {
+	expl.AddDetail(NewExplanation(s.collectionProbability(stats), "collection probability"))
+}
+
+/*
+Returns the name of the LM method. If a custom collection model
+strategy is used, its name is included as well.
+*/
+func (s *LMSimilarity) String() string {
+	if coll := s.collectionModel.Name(); coll != "" {
+		return fmt.Sprintf("LM %v - %v", s.self.Name(), coll)
+	}
+	return fmt.Sprintf("LM %v", s.self.Name())
+}
+
+// search/similarities/LMSimilarity.java/CollectionModel
+
+// A strategy for computing the collection language model.
+type CollectionModel interface {
+	/*
+		Computes the probability p(w|C) according to the language model
+		strategy for the current term.
+	*/
+	ComputeProbability(stats *BasicStats) float32
+	// The name of the collection model strategy, or empty.
+	Name() string
+}
+
+// search/similarities/LMSimilarity.java/DefaultCollectionModel
+
+/*
+Models p(w|C) as the number of occurrences of the term in the
+collection, divided by the total number of tokens + 1.
+*/
+type DefaultCollectionModel struct{}
+
+func NewDefaultCollectionModel() DefaultCollectionModel {
+	return DefaultCollectionModel{}
+}
+
+func (m DefaultCollectionModel) ComputeProbability(stats *BasicStats) float32 {
+	return (float32(stats.TotalTermFreq()) + 1) / (float32(stats.NumberOfFieldTokens()) + 1)
+}
+
+func (m DefaultCollectionModel) Name() string {
+	return ""
+}
+
+// search/similarities/LMDirichletSimilarity.java
+
+/*
+Bayesian smoothing using Dirichlet priors. From Chengxiang Zhai and
+John Lafferty. 2001. A study of smoothing methods for language models
+applied to Ad Hoc information retrieval. In Proceedings of the 24th
+annual international ACM SIGIR conference on Research and development
+in information retrieval (SIGIR '01). ACM, New York, NY, USA, 334-342.
+
+The formula as defined the paper assigns a negative score to
+documents that contain the term, but with fewer occurrences than
+predicted by the collection language model. The Lucene implementation
+returns 0 for such documents.
+*/
+type LMDirichletSimilarity struct {
+	*LMSimilarity
+	// The μ parameter.
+	mu float32
+}
+
+// Instantiates the similarity with the default μ value of 2000.
+func NewLMDirichletSimilarity() *LMDirichletSimilarity {
+	return NewLMDirichletSimilarityWithParams(nil, 2000)
+}
+
+// Instantiates the similarity with the provided μ parameter, and
+// collection model, or DefaultCollectionModel if nil.
+func NewLMDirichletSimilarityWithParams(collectionModel CollectionModel, mu float32) *LMDirichletSimilarity {
+	ans := &LMDirichletSimilarity{mu: mu}
+	ans.LMSimilarity = NewLMSimilarity(ans, collectionModel)
+	return ans
+}
+
+func (s *LMDirichletSimilarity) Score(stats *BasicStats, freq, docLen float32) float32 {
+	score := stats.TotalBoost() * float32(
+		math.Log(1+float64(freq/(s.mu*s.collectionProbability(stats))))+
+			math.Log(float64(s.mu/(docLen+s.mu))))
+	if score > 0 {
+		return score
+	}
+	return 0
+}
+
+func (s *LMDirichletSimilarity) Explain(expl *Explanation, stats *BasicStats, doc int, freq, docLen float32) {
+	if stats.TotalBoost() != 1 {
+		expl.AddDetail(NewExplanation(stats.TotalBoost(), "boost"))
+	}
+	expl.AddDetail(NewExplanation(s.mu, "mu"))
+	expl.AddDetail(NewExplanation(float32(math.Log(1+float64(freq/(s.mu*s.collectionProbability(stats))))),
+		"term weight"))
+	expl.AddDetail(NewExplanation(float32(math.Log(float64(s.mu/(docLen+s.mu)))), "document norm"))
+	s.LMSimilarity.Explain(expl, stats, doc, freq, docLen)
+}
+
+// Returns the μ parameter.
+func (s *LMDirichletSimilarity) Mu() float32 {
+	return s.mu
+}
+
+func (s *LMDirichletSimilarity) Name() string {
+	return fmt.Sprintf("Dirichlet(%f)", s.mu)
+}
+
+// search/similarities/LMJelinekMercerSimilarity.java
+
+/*
+Language model based on the Jelinek-Mercer smoothing method. From
+Chengxiang Zhai and John Lafferty. 2001. A study of smoothing methods
+for language models applied to Ad Hoc information retrieval. In
+Proceedings of the 24th annual international ACM SIGIR conference on
+Research and development in information retrieval (SIGIR '01). ACM,
+New York, NY, USA, 334-342.
+
+The model has a single parameter, λ. According to said paper, the
+optimal value depends on both the collection and the query. The
+optimal value is around 0.1 for title queries and 0.7 for long
+queries.
+*/
+type LMJelinekMercerSimilarity struct {
+	*LMSimilarity
+	// The λ parameter.
+	lambda float32
+}
+
+// Instantiates with the specified λ parameter, and collection model,
+// or DefaultCollectionModel if nil.
+func NewLMJelinekMercerSimilarity(collectionModel CollectionModel, lambda float32) *LMJelinekMercerSimilarity {
+	ans := &LMJelinekMercerSimilarity{lambda: lambda}
+	ans.LMSimilarity = NewLMSimilarity(ans, collectionModel)
+	return ans
+}
+
+func (s *LMJelinekMercerSimilarity) Score(stats *BasicStats, freq, docLen float32) float32 {
+	return stats.TotalBoost() * float32(math.Log(1+
+		float64(((1-s.lambda)*freq/docLen)/(s.lambda*s.collectionProbability(stats)))))
+}
+
+func (s *LMJelinekMercerSimilarity) Explain(expl *Explanation, stats *BasicStats, doc int, freq, docLen float32) {
+	if stats.TotalBoost() != 1 {
+		expl.AddDetail(NewExplanation(stats.TotalBoost(), "boost"))
+	}
+	expl.AddDetail(NewExplanation(s.lambda, "lambda"))
+	s.LMSimilarity.Explain(expl, stats, doc, freq, docLen)
+}
+
+// Returns the λ parameter.
+func (s *LMJelinekMercerSimilarity) Lambda() float32 {
+	return s.lambda
+}
+
+func (s *LMJelinekMercerSimilarity) Name() string {
+	return fmt.Sprintf("Jelinek-Mercer(%f)", s.lambda)
+}
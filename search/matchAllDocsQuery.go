@@ -0,0 +1,130 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/MatchAllDocsQuery.java
+
+/*
+A query that matches all documents, by iterating over the live
+documents of each segment, without visiting any term.
+*/
+type MatchAllDocsQuery struct {
+	*AbstractQuery
+}
+
+func NewMatchAllDocsQuery() *MatchAllDocsQuery {
+	ans := new(MatchAllDocsQuery)
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+func (q *MatchAllDocsQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	return &matchAllDocsWeight{query: q}, nil
+}
+
+func (q *MatchAllDocsQuery) ToString(field string) string {
+	return "*:*" + boostString(q.boost)
+}
+
+// search/MatchAllDocsQuery.java/MatchAllDocsWeight
+
+type matchAllDocsWeight struct {
+	query       *MatchAllDocsQuery
+	queryWeight float32
+	queryNorm   float32
+}
+
+func (w *matchAllDocsWeight) Query() Query {
+	return w.query
+}
+
+func (w *matchAllDocsWeight) ValueForNormalization() float32 {
+	w.queryWeight = w.query.boost
+	return w.queryWeight * w.queryWeight
+}
+
+func (w *matchAllDocsWeight) Normalize(queryNorm float32, topLevelBoost float32) {
+	w.queryNorm = queryNorm * topLevelBoost
+	w.queryWeight *= w.queryNorm
+}
+
+func (w *matchAllDocsWeight) Scorer(ctx index.AtomicReaderContext,
+	scoreDocsInOrder, topScorer bool, acceptDocs util.Bits) (Scorer, error) {
+	return newMatchAllScorer(ctx.Reader(), acceptDocs, w, w.queryWeight), nil
+}
+
+func (w *matchAllDocsWeight) IsScoresDocsOutOfOrder() bool {
+	return false
+}
+
+func (w *matchAllDocsWeight) Explain(ctx index.AtomicReaderContext, doc int) (*Explanation, error) {
+	// explain query weight
+	queryExpl := NewComplexExplanation(true, w.queryWeight, "MatchAllDocsQuery, product of:")
+	if w.query.boost != 1 {
+		queryExpl.AddDetail(NewExplanation(w.query.boost, "boost"))
+	}
+	queryExpl.AddDetail(NewExplanation(w.queryNorm, "queryNorm"))
+	return queryExpl, nil
+}
+
+func (w *matchAllDocsWeight) String() string {
+	return fmt.Sprintf("weight(%v)", w.query)
+}
+
+// search/MatchAllDocsQuery.java/MatchAllScorer
+
+// A Scorer of all the accepted documents, by a constant score.
+type matchAllScorer struct {
+	*ScorerImpl
+	score      float32
+	doc        int
+	maxDoc     int
+	acceptDocs util.Bits
+}
+
+func newMatchAllScorer(reader index.IndexReader, acceptDocs util.Bits, w Weight, score float32) *matchAllScorer {
+	ans := &matchAllScorer{
+		score:      score,
+		doc:        -1,
+		maxDoc:     reader.MaxDoc(),
+		acceptDocs: acceptDocs,
+	}
+	ans.ScorerImpl = NewScorer(ans, w)
+	return ans
+}
+
+func (s *matchAllScorer) DocId() int {
+	return s.doc
+}
+
+func (s *matchAllScorer) NextDoc() (int, bool) {
+	s.doc++
+	for s.acceptDocs != nil && s.doc < s.maxDoc && !s.acceptDocs.Get(s.doc) {
+		s.doc++
+	}
+	if s.doc >= s.maxDoc {
+		s.doc = index.NO_MORE_DOCS
+	}
+	return s.doc, s.doc != index.NO_MORE_DOCS
+}
+
+func (s *matchAllScorer) Score() float32 {
+	return s.score
+}
+
+func (s *matchAllScorer) Freq() int {
+	return 1
+}
+
+func (s *matchAllScorer) Cost() int64 {
+	return int64(s.maxDoc)
+}
+
+func (s *matchAllScorer) Advance(target int) (int, bool) {
+	s.doc = target - 1
+	return s.NextDoc()
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/util"
+	"testing"
+)
+
+func TestMatchAllDocsQuery(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+	defer cleanup()
+
+	q := NewMatchAllDocsQuery()
+	q.SetBoost(2)
+	assertEquals(t, "map[0:1 1:1 2:1 3:1 4:1]", fmt.Sprint(hitScores(t, ss, q)))
+
+	// filter-only search
+	docs, err := ss.Search(q, NewQueryWrapperFilter(newBodyTermQuery("c")), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, docs, 0, 2, 4)
+
+	bq := newTestBooleanQuery(q, OCCUR_MUST, newBodyTermQuery("b"), OCCUR_MUST_NOT)
+	assertEquals(t, "[3 4]", matchingDocs(t, ss, bq))
+
+	assertEquals(t, "*:*^2", q.ToString("body"))
+}
+
+func TestMatchAllDocsQueryAcceptDocs(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a", "c d")
+	defer cleanup()
+
+	w, err := ss.CreateNormalizedWeight(NewMatchAllDocsQuery())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := ss.TopReaderContext().Leaves()[0]
+	acceptDocs := util.NewFixedBitSet(ctx.Reader().MaxDoc())
+	acceptDocs.Set(1)
+	acceptDocs.Set(3)
+	s, err := w.Scorer(ctx, true, false, acceptDocs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docs []int
+	for doc, more := s.NextDoc(); more; doc, more = s.NextDoc() {
+		docs = append(docs, doc)
+	}
+	assertEquals(t, "[1 3]", fmt.Sprint(docs))
+
+	s, err = w.Scorer(ctx, true, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, more := s.Advance(3)
+	assertEquals(t, 3, doc)
+	assertEquals(t, true, more)
+	_, more = s.Advance(5)
+	assertEquals(t, false, more)
+}
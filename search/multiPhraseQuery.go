@@ -0,0 +1,458 @@
+package search
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+)
+
+// search/MultiPhraseQuery.java
+
+/*
+MultiPhraseQuery is a generalized version of PhraseQuery, with an
+added method AddTerms(). To use this class, to search for the phrase
+"Microsoft app*" first use Add() on the term "Microsoft", then find
+all terms that have "app" as prefix using IndexReader.Terms(), and use
+AddTerms() to add them to the query. It also matches the alternatives
+which synonym or stemming filters stack on the same position.
+*/
+type MultiPhraseQuery struct {
+	*AbstractQuery
+	field      string
+	termArrays [][]index.Term
+	positions  []int
+	slop       int
+}
+
+func NewMultiPhraseQuery() *MultiPhraseQuery {
+	ans := new(MultiPhraseQuery)
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+/*
+Sets the phrase slop for this query, the number of other words
+permitted between words in the query phrase. If zero, then this is an
+exact phrase search.
+*/
+func (q *MultiPhraseQuery) SetSlop(s int) {
+	q.slop = s
+}
+
+// Returns the phrase slop for this query.
+func (q *MultiPhraseQuery) Slop() int {
+	return q.slop
+}
+
+// Add a single term at the next position in the phrase.
+func (q *MultiPhraseQuery) Add(term index.Term) error {
+	return q.AddTerms(term)
+}
+
+/*
+Add multiple terms at the next position in the phrase. Any of the
+terms may match.
+*/
+func (q *MultiPhraseQuery) AddTerms(terms ...index.Term) error {
+	position := 0
+	if n := len(q.positions); n > 0 {
+		position = q.positions[n-1] + 1
+	}
+	return q.AddTermsAt(terms, position)
+}
+
+/*
+Allows to specify the relative position of terms within the phrase.
+Returns an error if no term is given, or if the terms are not all in
+the field of the phrase.
+*/
+func (q *MultiPhraseQuery) AddTermsAt(terms []index.Term, position int) error {
+	if len(terms) == 0 {
+		return errors.New("At least one term is required at a position of a phrase")
+	}
+	field := q.field
+	if len(q.termArrays) == 0 {
+		field = terms[0].Field
+	}
+	for _, term := range terms {
+		if term.Field != field {
+			return errors.New(fmt.Sprintf(
+				"All phrase terms must be in the same field (%v): %v", field, term))
+		}
+	}
+	q.field = field
+	q.termArrays = append(q.termArrays, terms)
+	q.positions = append(q.positions, position)
+	return nil
+}
+
+// Returns the terms at each position of the phrase, which must not
+// be modified.
+func (q *MultiPhraseQuery) TermArrays() [][]index.Term {
+	return q.termArrays
+}
+
+// Returns the relative positions of terms in this phrase.
+func (q *MultiPhraseQuery) Positions() []int {
+	return q.positions
+}
+
+func (q *MultiPhraseQuery) Rewrite(r index.IndexReader) (Query, error) {
+	switch len(q.termArrays) {
+	case 0:
+		bq := NewBooleanQuery()
+		bq.SetBoost(q.boost)
+		return bq, nil
+	case 1: // optimize one-term case
+		boq := NewBooleanQueryDisableCoord(true)
+		for _, term := range q.termArrays[0] {
+			if err := boq.Add(NewTermQuery(term), OCCUR_SHOULD); err != nil {
+				return nil, err
+			}
+		}
+		boq.SetBoost(q.boost)
+		return boq, nil
+	default:
+		return q, nil
+	}
+}
+
+func (q *MultiPhraseQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	return newMultiPhraseWeight(q, ss)
+}
+
+// Prints a user-readable version of this query, e.g.
+// `body:"a (b c) ? d"~2`, where "?" marks a skipped position.
+func (q *MultiPhraseQuery) ToString(f string) string {
+	var buf bytes.Buffer
+	if q.field != f {
+		buf.WriteString(q.field)
+		buf.WriteString(":")
+	}
+	buf.WriteString(`"`)
+	lastPos := -1
+	for k, terms := range q.termArrays {
+		position := q.positions[k]
+		if k > 0 {
+			buf.WriteString(" ")
+			for j := 1; j < position-lastPos; j++ {
+				buf.WriteString("? ")
+			}
+		}
+		if len(terms) > 1 {
+			buf.WriteString("(")
+			for j, term := range terms {
+				if j > 0 {
+					buf.WriteString(" ")
+				}
+				buf.Write(term.Bytes)
+			}
+			buf.WriteString(")")
+		} else {
+			buf.Write(terms[0].Bytes)
+		}
+		lastPos = position
+	}
+	buf.WriteString(`"`)
+	if q.slop != 0 {
+		fmt.Fprintf(&buf, "~%v", q.slop)
+	}
+	buf.WriteString(boostString(q.boost))
+	return buf.String()
+}
+
+// search/MultiPhraseQuery.java/MultiPhraseWeight
+
+type multiPhraseWeight struct {
+	query      *MultiPhraseQuery
+	similarity Similarity
+	stats      SimWeight
+	// keyed by the bytes of the terms, all in the field of the query
+	termContexts map[string]*index.TermContext
+}
+
+func newMultiPhraseWeight(q *MultiPhraseQuery, ss *IndexSearcher) (*multiPhraseWeight, error) {
+	ans := &multiPhraseWeight{
+		query:        q,
+		similarity:   ss.Similarity(),
+		termContexts: make(map[string]*index.TermContext),
+	}
+	ctx := ss.TopReaderContext()
+
+	// compute idf
+	var allTermStats []TermStatistics
+	for _, terms := range q.termArrays {
+		for _, term := range terms {
+			termContext, ok := ans.termContexts[string(term.Bytes)]
+			if !ok {
+				var err error
+				if termContext, err = index.NewTermContextFromTerm(ctx, term); err != nil {
+					return nil, err
+				}
+				ans.termContexts[string(term.Bytes)] = termContext
+			}
+			allTermStats = append(allTermStats, ss.TermStatistics(term, termContext))
+		}
+	}
+	ans.stats = ans.similarity.ComputeWeight(q.boost,
+		ss.CollectionStatistics(q.field), allTermStats...)
+	return ans, nil
+}
+
+func (w *multiPhraseWeight) Query() Query {
+	return w.query
+}
+
+func (w *multiPhraseWeight) ValueForNormalization() float32 {
+	return w.stats.ValueForNormalization()
+}
+
+func (w *multiPhraseWeight) Normalize(norm float32, topLevelBoost float32) {
+	w.stats.Normalize(norm, topLevelBoost)
+}
+
+func (w *multiPhraseWeight) IsScoresDocsOutOfOrder() bool {
+	return false
+}
+
+func (w *multiPhraseWeight) Scorer(ctx index.AtomicReaderContext,
+	scoreDocsInOrder, topScorer bool, acceptDocs util.Bits) (Scorer, error) {
+	q := w.query
+	if len(q.termArrays) == 0 {
+		return nil, nil
+	}
+	fieldTerms := ctx.Reader().(index.AtomicReader).Terms(q.field)
+	if fieldTerms == nil {
+		return nil, nil
+	}
+	termsEnum := fieldTerms.Iterator(nil)
+
+	postingsFreqs := make([]*postingsAndFreq, len(q.termArrays))
+	for pos, terms := range q.termArrays {
+		var postings index.DocsAndPositionsEnum
+		docFreq := 0
+		for _, term := range terms {
+			termState := w.termContexts[string(term.Bytes)].State(ctx.Ord)
+			if termState == nil { // term is not present in that reader
+				continue
+			}
+			if err := termsEnum.SeekExactFromLast(term.Bytes, *termState); err != nil {
+				return nil, err
+			}
+			// coarse -- this overcounts since a given doc can have more
+			// than one term
+			docFreq += termsEnum.DocFreq()
+			if len(terms) == 1 {
+				postings = termsEnum.DocsAndPositionsByFlags(acceptDocs, index.DocsAndPositionsEnum{}, 0)
+				if postings.DocsAndPositionsIterator == nil {
+					// term does exist, but has no positions
+					return nil, errNoPositions(term)
+				}
+			}
+		}
+		if docFreq == 0 {
+			// none of the terms at this position is present, so the
+			// phrase can't match
+			return nil, nil
+		}
+		if len(terms) > 1 {
+			var err error
+			if postings, err = newUnionDocsAndPositionsEnum(acceptDocs, ctx,
+				terms, w.termContexts, termsEnum); err != nil {
+				return nil, err
+			}
+		}
+		postingsFreqs[pos] = &postingsAndFreq{postings, docFreq, q.positions[pos], terms}
+	}
+
+	if q.slop == 0 {
+		// sort by increasing docFreq order
+		sort.Stable(postingsAndFreqs(postingsFreqs))
+		docScorer, err := w.similarity.ExactSimScorer(w.stats, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return newExactPhraseScorer(w, postingsFreqs, docScorer), nil
+	}
+	docScorer, err := w.similarity.SloppySimScorer(w.stats, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newSloppyPhraseScorer(w, postingsFreqs, q.slop, docScorer), nil
+}
+
+func (w *multiPhraseWeight) Explain(ctx index.AtomicReaderContext, doc int) (*Explanation, error) {
+	scorer, err := w.Scorer(ctx, true, false, ctx.Reader().(index.AtomicReader).LiveDocs())
+	if err != nil {
+		return nil, err
+	}
+	if scorer != nil {
+		if newDoc, _ := scorer.Advance(doc); newDoc == doc {
+			var scoreExplanation *Explanation
+			if w.query.slop == 0 {
+				freq := float32(scorer.Freq())
+				docScorer, err := w.similarity.ExactSimScorer(w.stats, ctx)
+				if err != nil {
+					return nil, err
+				}
+				scoreExplanation = docScorer.Explain(doc, NewExplanation(freq, fmt.Sprintf("phraseFreq=%v", freq)))
+			} else {
+				freq := scorer.(*SloppyPhraseScorer).sloppyFreq()
+				docScorer, err := w.similarity.SloppySimScorer(w.stats, ctx)
+				if err != nil {
+					return nil, err
+				}
+				scoreExplanation = docScorer.Explain(doc, NewExplanation(freq, fmt.Sprintf("phraseFreq=%v", freq)))
+			}
+			result := NewComplexExplanation(true, scoreExplanation.Value(), fmt.Sprintf(
+				"weight(%v in %v) [%v], result of:", w.query, doc, simpleName(w.similarity)))
+			result.AddDetail(scoreExplanation)
+			return result, nil
+		}
+	}
+	return NewComplexExplanation(false, 0, "no matching term"), nil
+}
+
+func errNoPositions(term index.Term) error {
+	return errors.New(fmt.Sprintf(
+		`field "%v" was indexed without position data; cannot run MultiPhraseQuery (term=%v)`,
+		term.Field, string(term.Bytes)))
+}
+
+func (w *multiPhraseWeight) String() string {
+	return fmt.Sprintf("weight(%v)", w.query)
+}
+
+// search/MultiPhraseQuery.java/UnionDocsAndPositionsEnum
+
+/*
+Takes the logical union of multiple DocsAndPositionsEnum iterators:
+the documents of any of them, with the positions of all of them in
+each document, in increasing order.
+*/
+type unionDocsAndPositionsEnum struct {
+	queue   *docsQueue
+	posList []int
+	posUpto int
+	doc     int
+	cost    int64
+}
+
+func newUnionDocsAndPositionsEnum(liveDocs util.Bits, ctx index.AtomicReaderContext,
+	terms []index.Term, termContexts map[string]*index.TermContext,
+	termsEnum index.TermsEnum) (index.DocsAndPositionsEnum, error) {
+	queue := make(docsQueue, 0, len(terms))
+	var cost int64
+	for _, term := range terms {
+		termState := termContexts[string(term.Bytes)].State(ctx.Ord)
+		if termState == nil { // term doesn't exist in reader
+			continue
+		}
+		if err := termsEnum.SeekExactFromLast(term.Bytes, *termState); err != nil {
+			return index.DocsAndPositionsEnum{}, err
+		}
+		postings := termsEnum.DocsAndPositionsByFlags(liveDocs, index.DocsAndPositionsEnum{}, 0)
+		if postings.DocsAndPositionsIterator == nil {
+			// term does exist, but has no positions
+			return index.DocsAndPositionsEnum{}, errNoPositions(term)
+		}
+		cost += postings.Cost()
+		if _, more := postings.NextDoc(); more {
+			queue = append(queue, postings)
+		}
+	}
+	heap.Init(&queue)
+	return index.DocsAndPositionsEnum{
+		DocsAndPositionsIterator: &unionDocsAndPositionsEnum{queue: &queue, doc: -1, cost: cost}}, nil
+}
+
+func (e *unionDocsAndPositionsEnum) NextDoc() (int, bool) {
+	if e.queue.Len() == 0 {
+		e.doc = index.NO_MORE_DOCS
+		return e.doc, false
+	}
+
+	// TODO: move this init into positions(): if the search doesn't
+	// need the positions for this doc then don't waste CPU merging
+	// them:
+	e.posList = e.posList[:0]
+	e.posUpto = 0
+	e.doc = (*e.queue)[0].DocId()
+
+	// merge sort all positions together
+	for e.queue.Len() > 0 && (*e.queue)[0].DocId() == e.doc {
+		postings := (*e.queue)[0]
+		for i, freq := 0, postings.Freq(); i < freq; i++ {
+			e.posList = append(e.posList, postings.NextPosition())
+		}
+		if _, more := postings.NextDoc(); more {
+			heap.Fix(e.queue, 0)
+		} else {
+			heap.Pop(e.queue)
+		}
+	}
+	sort.Ints(e.posList)
+	return e.doc, true
+}
+
+func (e *unionDocsAndPositionsEnum) NextPosition() int {
+	ans := e.posList[e.posUpto]
+	e.posUpto++
+	return ans
+}
+
+func (e *unionDocsAndPositionsEnum) StartOffset() int {
+	return -1
+}
+
+func (e *unionDocsAndPositionsEnum) EndOffset() int {
+	return -1
+}
+
+func (e *unionDocsAndPositionsEnum) Payload() []byte {
+	return nil
+}
+
+func (e *unionDocsAndPositionsEnum) Advance(target int) (int, bool) {
+	for e.queue.Len() > 0 && target > (*e.queue)[0].DocId() {
+		postings := heap.Pop(e.queue).(index.DocsAndPositionsEnum)
+		if _, more := postings.Advance(target); more {
+			heap.Push(e.queue, postings)
+		}
+	}
+	return e.NextDoc()
+}
+
+func (e *unionDocsAndPositionsEnum) Freq() int {
+	return len(e.posList)
+}
+
+func (e *unionDocsAndPositionsEnum) DocId() int {
+	return e.doc
+}
+
+func (e *unionDocsAndPositionsEnum) Cost() int64 {
+	return e.cost
+}
+
+// search/MultiPhraseQuery.java/DocsQueue
+
+// The postings of a union, ordered by their current doc.
+type docsQueue []index.DocsAndPositionsEnum
+
+func (pq docsQueue) Len() int           { return len(pq) }
+func (pq docsQueue) Less(i, j int) bool { return pq[i].DocId() < pq[j].DocId() }
+func (pq docsQueue) Swap(i, j int)      { pq[i], pq[j] = pq[j], pq[i] }
+
+func (pq *docsQueue) Push(x interface{}) { *pq = append(*pq, x.(index.DocsAndPositionsEnum)) }
+
+func (pq *docsQueue) Pop() interface{} {
+	n := len(*pq)
+	ans := (*pq)[n-1]
+	*pq = (*pq)[:n-1]
+	return ans
+}
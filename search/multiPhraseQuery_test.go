@@ -0,0 +1,199 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis/analysistest"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/store"
+	"testing"
+)
+
+// Opens a searcher over the belfry sample, whose "content" field is
+// indexed with positions.
+func newBelfrySearcher(t *testing.T) (*IndexSearcher, func()) {
+	d, err := store.OpenFSDirectory("testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewIndexSearcher(r), func() { r.Close() }
+}
+
+// Builds a MultiPhraseQuery on the "content" field, with the given
+// alternatives at each position, separated by "|".
+func newContentPhraseQuery(slop int, positions ...string) *MultiPhraseQuery {
+	q := NewMultiPhraseQuery()
+	for _, alternatives := range positions {
+		var terms []index.Term
+		for _, text := range splitAlternatives(alternatives) {
+			terms = append(terms, index.NewTerm("content", text))
+		}
+		q.AddTerms(terms...)
+	}
+	q.SetSlop(slop)
+	return q
+}
+
+func splitAlternatives(s string) []string {
+	var ans []string
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i == len(s) || s[i] == '|' {
+			ans = append(ans, s[start:i])
+			start = i + 1
+		}
+	}
+	return ans
+}
+
+// Returns the phrase frequencies of the documents matching q, by doc.
+func phraseFreqs(t *testing.T, ss *IndexSearcher, q Query) string {
+	w, err := ss.CreateNormalizedWeight(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	freqs := make(map[int]int)
+	for _, ctx := range ss.TopReaderContext().Leaves() {
+		scorer, err := w.Scorer(ctx, true, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if scorer == nil {
+			continue
+		}
+		for doc, more := scorer.NextDoc(); more; doc, more = scorer.NextDoc() {
+			if score := scorer.Score(); score <= 0 {
+				t.Errorf("Expected positive score for doc %v, but %v", doc, score)
+			}
+			freqs[ctx.DocBase+doc] = scorer.Freq()
+		}
+	}
+	return fmt.Sprint(freqs)
+}
+
+func TestMultiPhraseQuery(t *testing.T) {
+	ss, cleanup := newBelfrySearcher(t)
+	defer cleanup()
+
+	for _, v := range []struct {
+		q        *MultiPhraseQuery
+		expected string
+	}{
+		{newContentPhraseQuery(0, "fruit", "bat"), "map[0:2 1:2 2:1]"},
+		{newContentPhraseQuery(0, "fruit|new|your", "bat"), "map[0:7 1:8 2:8 6:1 7:2]"},
+		{newContentPhraseQuery(0, "your", "fruit|new|cute", "bat"), "map[0:1 1:1 2:1 7:1]"},
+		{newContentPhraseQuery(0, "bat", "guano|drop"), "map[4:6]"},
+		{newContentPhraseQuery(0, "bat", "nosuchterm"), "map[]"},
+		{newContentPhraseQuery(0, "bat", "nosuchterm|guano"), "map[4:4]"},
+		{newContentPhraseQuery(0, "wave", "sound"), "map[3:1]"},
+		// reversed terms need a slop of 2
+		{newContentPhraseQuery(1, "wave", "sound"), "map[3:1]"},
+		{newContentPhraseQuery(2, "wave", "sound"), "map[3:2 7:1]"},
+	} {
+		assertEquals(t, v.expected, phraseFreqs(t, ss, v.q))
+	}
+
+	// a gap in the positions matches any term
+	q := newContentPhraseQuery(0, "your")
+	q.AddTermsAt([]index.Term{index.NewTerm("content", "bat")}, 2)
+	assertEquals(t, "map[0:1 1:1 2:1 7:1]", phraseFreqs(t, ss, q))
+}
+
+func TestMultiPhraseQuerySloppyScore(t *testing.T) {
+	ss, cleanup := newBelfrySearcher(t)
+	defer cleanup()
+
+	// closer matches score higher: doc 7 has "sound wave", but not
+	// "wave sound"
+	score := func(q Query) float32 {
+		docs, err := ss.SearchTop(q, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, hit := range docs.ScoreDocs {
+			if hit.Doc == 7 {
+				return hit.Score
+			}
+		}
+		t.Fatalf("Expected doc 7 to match %v", q)
+		return 0
+	}
+	exact := score(newContentPhraseQuery(0, "sound", "wave"))
+	if sloppy := score(newContentPhraseQuery(2, "wave", "sound")); sloppy >= exact {
+		t.Errorf("Expected sloppy match to score less than %v, but %v", exact, sloppy)
+	}
+}
+
+func TestMultiPhraseQueryOnTokenGraph(t *testing.T) {
+	// "wi fi network", with the synonym "wifi" spanning "wi fi"
+	graph := []analysistest.Token{
+		analysistest.NewToken("wi", 1, 1, 0, 2),
+		analysistest.NewToken("wifi", 0, 2, 0, 5),
+		analysistest.NewToken("fi", 1, 1, 3, 5),
+		analysistest.NewToken("network", 1, 1, 6, 13),
+	}
+	ss, cleanup := newTestSearcherOfDocs(t,
+		[]document.IndexableField{document.NewTextFieldFromTokenStream("body",
+			analysistest.NewCannedTokenStream(13, graph...))},
+		[]document.IndexableField{document.NewTextField("body", "wifi network", document.STORE_NO)})
+	defer cleanup()
+
+	bodyQuery := func(slop int, texts ...string) *MultiPhraseQuery {
+		q := NewMultiPhraseQuery()
+		for _, text := range texts {
+			q.Add(index.NewTerm("body", text))
+		}
+		q.SetSlop(slop)
+		return q
+	}
+	// the position lengths are not indexed: "wifi" is followed by "fi"
+	assertEquals(t, "map[0:1]", phraseFreqs(t, ss, bodyQuery(0, "wi", "fi", "network")))
+	assertEquals(t, "map[0:1]", phraseFreqs(t, ss, bodyQuery(0, "wifi", "fi", "network")))
+	assertEquals(t, "map[1:1]", phraseFreqs(t, ss, bodyQuery(0, "wifi", "network")))
+	assertEquals(t, "map[0:1 1:1]", phraseFreqs(t, ss, bodyQuery(1, "wifi", "network")))
+}
+
+func TestMultiPhraseQueryRewrite(t *testing.T) {
+	ss, cleanup := newBelfrySearcher(t)
+	defer cleanup()
+
+	q, err := ss.Rewrite(NewMultiPhraseQuery())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, len(q.(*BooleanQuery).Clauses()))
+
+	mpq := newContentPhraseQuery(0, "fruit|new")
+	mpq.SetBoost(2)
+	if q, err = ss.Rewrite(mpq); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "(content:fruit content:new)^2", q.ToString(""))
+	assertEquals(t, true, q.(*BooleanQuery).IsCoordDisabled())
+
+	mpq = newContentPhraseQuery(0, "fruit", "bat")
+	if q, err = ss.Rewrite(mpq); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, Query(mpq), q)
+}
+
+func TestMultiPhraseQueryToString(t *testing.T) {
+	q := newContentPhraseQuery(0, "a", "b|c")
+	q.AddTermsAt([]index.Term{index.NewTerm("content", "d")}, 3)
+	assertEquals(t, `content:"a (b c) ? d"`, q.String())
+	q.SetSlop(2)
+	q.SetBoost(3)
+	assertEquals(t, `"a (b c) ? d"~2^3`, q.ToString("content"))
+
+	err := q.Add(index.NewTerm("body", "e"))
+	if err == nil {
+		t.Error("Expected error for term of another field")
+	}
+	assertEquals(t, 3, len(q.TermArrays()))
+	assertEquals(t, "[0 1 3]", fmt.Sprint(q.Positions()))
+}
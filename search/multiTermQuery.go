@@ -0,0 +1,316 @@
+package search
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/index"
+	"sort"
+)
+
+// search/MultiTermQuery.java
+
+/*
+The hook of MultiTermQuery, implemented by the concrete queries which
+embed it.
+*/
+type multiTermQuery interface {
+	Query
+	/*
+		Constructs an enumeration that expands the pattern term. This
+		method should only be called if the field exists (i.e. terms is
+		not nil).
+	*/
+	TermsEnum(terms index.Terms) (index.TermsEnum, error)
+}
+
+/*
+An abstract Query that matches documents containing a subset of terms
+provided by a FilteredTermsEnum enumeration.
+
+This query cannot be used directly; you must use one of its
+implementations, which provide their enumeration of the terms by
+TermsEnum(), e.g. WildcardQuery or PrefixQuery.
+
+The query is rewritten by its RewriteMethod, which enumerates the
+matching terms:
+
+  - CONSTANT_SCORE_FILTER_REWRITE first creates a private Filter, by
+    visiting each term in sequence and marking all docs for that
+    term. Matching documents are assigned a constant score equal to
+    the query's boost. This method never returns ErrTooManyClauses.
+
+  - SCORING_BOOLEAN_QUERY_REWRITE rewrites to a BooleanQuery of the
+    TermQuerys of the terms, which keep their scores. It returns
+    ErrTooManyClauses if there are more terms than MaxClauseCount().
+
+  - CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE is like the scoring boolean
+    rewrite, but the documents get a constant score equal to the
+    query's boost.
+
+  - CONSTANT_SCORE_AUTO_REWRITE_DEFAULT, the default, chooses between
+    the filter and the boolean rewrites by the number of terms and
+    documents, see ConstantScoreAutoRewrite.
+
+  - The TopTermsRewrite methods only keep the most competitive terms,
+    so the number of clauses is bounded, see
+    NewTopTermsScoringBooleanQueryRewrite.
+*/
+type MultiTermQuery struct {
+	*AbstractQuery
+	self          multiTermQuery
+	field         string
+	rewriteMethod RewriteMethod
+}
+
+// Constructs a query matching terms of the given field, whose
+// enumeration is provided by self.
+func NewMultiTermQuery(self multiTermQuery, field string) *MultiTermQuery {
+	return &MultiTermQuery{NewAbstractQuery(self), self, field, CONSTANT_SCORE_AUTO_REWRITE_DEFAULT}
+}
+
+// Returns the field name for this query
+func (q *MultiTermQuery) Field() string {
+	return q.field
+}
+
+func (q *MultiTermQuery) Rewrite(r index.IndexReader) (Query, error) {
+	return q.rewriteMethod.Rewrite(r, q)
+}
+
+func (q *MultiTermQuery) RewriteMethod() RewriteMethod {
+	return q.rewriteMethod
+}
+
+// Sets the rewrite method to be used when executing the query. You
+// can use one of the predefined rewrite methods, or create your own.
+func (q *MultiTermQuery) SetRewriteMethod(method RewriteMethod) {
+	q.rewriteMethod = method
+}
+
+// search/MultiTermQuery.java/RewriteMethod
+
+// Abstract class that defines how the query is rewritten.
+type RewriteMethod interface {
+	Rewrite(r index.IndexReader, q *MultiTermQuery) (Query, error)
+}
+
+/*
+A rewrite method that first creates a private Filter, by visiting
+each term in sequence and marking all docs for that term. Matching
+documents are assigned a constant score equal to the query's boost.
+
+This method is faster than the BooleanQuery rewrite methods when the
+number of matched terms or matched documents is non-trivial. Also, it
+will never return ErrTooManyClauses.
+*/
+var CONSTANT_SCORE_FILTER_REWRITE = RewriteMethod(constantScoreFilterRewrite{})
+
+type constantScoreFilterRewrite struct{}
+
+func (rw constantScoreFilterRewrite) Rewrite(r index.IndexReader, q *MultiTermQuery) (Query, error) {
+	result := NewConstantScoreQueryWithFilter(NewMultiTermQueryWrapperFilter(q))
+	result.SetBoost(q.boost)
+	return result, nil
+}
+
+func (rw constantScoreFilterRewrite) String() string {
+	return "CONSTANT_SCORE_FILTER_REWRITE"
+}
+
+// search/TermCollectingRewrite.java
+
+// The collector of the terms of a MultiTermQuery, leaf by leaf.
+type termCollector interface {
+	setReaderContext(topReaderContext index.IndexReaderContext, ctx index.AtomicReaderContext)
+	// the next enumeration the terms are collected from
+	setNextEnum(termsEnum index.TermsEnum)
+	// return false to stop collecting
+	collect(term []byte) (bool, error)
+}
+
+/*
+A TermsEnum which boosts its terms by their similarity, like
+FuzzyTermsEnum, whose boost is given to the queries of the terms.
+*/
+type boostedTermsEnum interface {
+	index.TermsEnum
+	Boost() float32
+}
+
+// Collects the terms matching q, in the leaves of r.
+func collectTerms(r index.IndexReader, q *MultiTermQuery, collector termCollector) error {
+	topReaderContext := r.Context()
+	for _, ctx := range topReaderContext.Leaves() {
+		terms := ctx.Reader().(index.AtomicReader).Terms(q.field)
+		if terms == nil {
+			// field does not exist
+			continue
+		}
+		termsEnum, err := q.self.TermsEnum(terms)
+		if err != nil {
+			return err
+		}
+		if termsEnum == index.EMPTY_TERMS_ENUM {
+			continue
+		}
+		collector.setReaderContext(topReaderContext, ctx)
+		collector.setNextEnum(termsEnum)
+		for {
+			term, err := termsEnum.Next()
+			if err != nil {
+				return err
+			}
+			if term == nil {
+				break
+			}
+			ok, err := collector.collect(term)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				// interrupt whole term collection, so also don't iterate
+				// other subReaders
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// search/ScoringRewrite.java
+
+/*
+A rewrite method that first translates each term into a SHOULD clause
+of a BooleanQuery, and keeps the scores as computed by the query.
+Returns ErrTooManyClauses if the number of terms exceeds
+MaxClauseCount().
+*/
+var SCORING_BOOLEAN_QUERY_REWRITE = RewriteMethod(scoringBooleanQueryRewrite{})
+
+type scoringBooleanQueryRewrite struct{}
+
+func (rw scoringBooleanQueryRewrite) Rewrite(r index.IndexReader, q *MultiTermQuery) (Query, error) {
+	col := newScoringTermCollector()
+	if err := collectTerms(r, q, col); err != nil {
+		return nil, err
+	}
+	return col.booleanQuery(q)
+}
+
+func (rw scoringBooleanQueryRewrite) String() string {
+	return "SCORING_BOOLEAN_QUERY_REWRITE"
+}
+
+/*
+Like SCORING_BOOLEAN_QUERY_REWRITE except scores are not computed.
+Instead, each matching document receives a constant score equal to
+the query's boost.
+
+NOTE: This rewrite method will return ErrTooManyClauses if the number
+of terms exceeds MaxClauseCount().
+*/
+var CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE = RewriteMethod(constantScoreBooleanQueryRewrite{})
+
+type constantScoreBooleanQueryRewrite struct{}
+
+func (rw constantScoreBooleanQueryRewrite) Rewrite(r index.IndexReader, q *MultiTermQuery) (Query, error) {
+	col := newScoringTermCollector()
+	if err := collectTerms(r, q, col); err != nil {
+		return nil, err
+	}
+	bq, err := col.booleanQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	if len(bq.Clauses()) == 0 {
+		return bq, nil
+	}
+	// strip the scores off
+	result := NewConstantScoreQuery(bq)
+	result.SetBoost(q.boost)
+	return result, nil
+}
+
+func (rw constantScoreBooleanQueryRewrite) String() string {
+	return "CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE"
+}
+
+/*
+Collects the distinct terms, with their states in all the leaves,
+and their boosts, which are sorted by term.
+*/
+type scoringTermCollector struct {
+	topReaderContext index.IndexReaderContext
+	readerContext    index.AtomicReaderContext
+	termsEnum        index.TermsEnum
+	boosted          boostedTermsEnum
+
+	ords       map[string]int
+	terms      [][]byte
+	boosts     []float32
+	termStates []*index.TermContext
+}
+
+func newScoringTermCollector() *scoringTermCollector {
+	return &scoringTermCollector{ords: make(map[string]int)}
+}
+
+// Builds the BooleanQuery of the TermQuerys of the collected terms,
+// boosted by the boosts of the terms.
+func (c *scoringTermCollector) booleanQuery(q *MultiTermQuery) (*BooleanQuery, error) {
+	result := NewBooleanQueryDisableCoord(true)
+	sort.Sort(c)
+	for i, term := range c.terms {
+		tq := NewTermQueryWithStates(index.Term{Field: q.field, Bytes: term}, c.termStates[i])
+		tq.SetBoost(q.boost * c.boosts[i])
+		if err := result.Add(tq, OCCUR_SHOULD); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (c *scoringTermCollector) setReaderContext(topReaderContext index.IndexReaderContext, ctx index.AtomicReaderContext) {
+	c.topReaderContext, c.readerContext = topReaderContext, ctx
+}
+
+func (c *scoringTermCollector) setNextEnum(termsEnum index.TermsEnum) {
+	c.termsEnum = termsEnum
+	c.boosted, _ = termsEnum.(boostedTermsEnum)
+}
+
+func (c *scoringTermCollector) collect(term []byte) (bool, error) {
+	state := c.termsEnum.TermState()
+	if ord, ok := c.ords[string(term)]; ok {
+		// duplicate term: update docFreq
+		c.termStates[ord].Register(state, c.readerContext.Ord,
+			c.termsEnum.DocFreq(), c.termsEnum.TotalTermFreq())
+		return true, nil
+	}
+	// new entry: we populate the entry initially
+	if len(c.terms) >= MaxClauseCount() {
+		return false, ErrTooManyClauses
+	}
+	boost := float32(1)
+	if c.boosted != nil {
+		boost = c.boosted.Boost()
+	}
+	termState := index.NewTermContext(c.topReaderContext)
+	termState.Register(state, c.readerContext.Ord, c.termsEnum.DocFreq(), c.termsEnum.TotalTermFreq())
+	c.ords[string(term)] = len(c.terms)
+	c.terms = append(c.terms, append([]byte(nil), term...))
+	c.boosts = append(c.boosts, boost)
+	c.termStates = append(c.termStates, termState)
+	return true, nil
+}
+
+func (c *scoringTermCollector) Len() int { return len(c.terms) }
+
+func (c *scoringTermCollector) Less(i, j int) bool {
+	return bytes.Compare(c.terms[i], c.terms[j]) < 0
+}
+
+func (c *scoringTermCollector) Swap(i, j int) {
+	c.terms[i], c.terms[j] = c.terms[j], c.terms[i]
+	c.boosts[i], c.boosts[j] = c.boosts[j], c.boosts[i]
+	c.termStates[i], c.termStates[j] = c.termStates[j], c.termStates[i]
+}
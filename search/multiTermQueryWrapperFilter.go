@@ -0,0 +1,81 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/MultiTermQueryWrapperFilter.java
+
+/*
+A wrapper for MultiTermQuery, that exposes its functionality as a
+Filter.
+
+MultiTermQueryWrapperFilter is not designed to be used by itself.
+Normally you subclass it to provide a Filter counterpart for a
+MultiTermQuery subclass.
+
+This class also provides the functionality behind the
+CONSTANT_SCORE_FILTER_REWRITE rewrite method: as the matching terms
+are not turned into clauses, it never returns ErrTooManyClauses.
+*/
+type MultiTermQueryWrapperFilter struct {
+	query *MultiTermQuery
+}
+
+// Wrap a MultiTermQuery as a Filter.
+func NewMultiTermQueryWrapperFilter(query *MultiTermQuery) *MultiTermQueryWrapperFilter {
+	return &MultiTermQueryWrapperFilter{query}
+}
+
+// Returns the field name for this query
+func (f *MultiTermQueryWrapperFilter) Field() string {
+	return f.query.field
+}
+
+/*
+Returns a DocIdSet with documents that should be permitted in search
+results, or nil if no term of the segment matches.
+*/
+func (f *MultiTermQueryWrapperFilter) DocIdSet(ctx index.AtomicReaderContext, acceptDocs util.Bits) (DocIdSet, error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	terms := reader.Terms(f.query.field)
+	if terms == nil {
+		// field does not exist
+		return nil, nil
+	}
+	termsEnum, err := f.query.self.TermsEnum(terms)
+	if err != nil {
+		return nil, err
+	}
+	var bitSet *FixedBitSetDocIdSet
+	docsEnum := index.DOCS_ENUM_EMPTY
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			return nil, err
+		}
+		if term == nil {
+			break
+		}
+		if bitSet == nil {
+			// fill into a bitset, lazily, as there may be no term at all
+			bitSet = NewFixedBitSetDocIdSet(util.NewFixedBitSet(reader.MaxDoc()))
+		}
+		// enumerate all docs of the term, but not the freqs
+		docsEnum = termsEnum.DocsByFlags(acceptDocs, docsEnum, 0)
+		for doc, more := docsEnum.NextDoc(); more; doc, more = docsEnum.NextDoc() {
+			bitSet.Set(doc)
+		}
+	}
+	if bitSet == nil {
+		return nil, nil
+	}
+	return bitSet, nil
+}
+
+func (f *MultiTermQueryWrapperFilter) String() string {
+	// query.ToString should be ok for the filter, too, if the query
+	// boost is 1.0f
+	return f.query.self.ToString("")
+}
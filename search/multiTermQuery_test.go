@@ -0,0 +1,110 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"testing"
+)
+
+// Rewrites the query "body:ap*^2" by the given method.
+func rewriteTestPrefixQuery(t *testing.T, ss *IndexSearcher, method RewriteMethod) (Query, error) {
+	q := NewPrefixQuery(index.NewTerm("body", "ap"))
+	q.SetRewriteMethod(method)
+	q.SetBoost(2)
+	return q.Rewrite(ss.IndexReader())
+}
+
+func TestMultiTermQueryRewriteMethods(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, automatonQueryBodies...)
+	defer cleanup()
+
+	private := NewConstantScoreAutoRewrite()
+	private.SetDocCountPercent(100)
+	for _, v := range []struct {
+		method   RewriteMethod
+		expected string
+		docs     string
+	}{
+		{CONSTANT_SCORE_FILTER_REWRITE, "ConstantScore(body:ap*^2)^2", "[0 1 2]"},
+		{SCORING_BOOLEAN_QUERY_REWRITE, "body:apple^2 body:application^2 body:apricot^2 body:apt^2", "[0 1 2]"},
+		{CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE,
+			"ConstantScore(body:apple^2 body:application^2 body:apricot^2 body:apt^2)^2", "[0 1 2]"},
+		// the default doc count cutoff is 0 in a tiny index
+		{CONSTANT_SCORE_AUTO_REWRITE_DEFAULT, "ConstantScore(body:ap*^2)^2", "[0 1 2]"},
+		{private, "ConstantScore(body:apple^2 body:application^2 body:apricot^2 body:apt^2)^2", "[0 1 2]"},
+		// the least terms are kept for the same boost
+		{NewTopTermsScoringBooleanQueryRewrite(2), "body:apple^2 body:application^2", "[0 2]"},
+		{NewTopTermsBoostOnlyBooleanQueryRewrite(2),
+			"ConstantScore(body:apple)^2 ConstantScore(body:application)^2", "[0 2]"},
+	} {
+		rewritten, err := rewriteTestPrefixQuery(t, ss, v.method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, v.expected, rewritten.ToString(""))
+		assertEquals(t, v.docs, matchingDocs(t, ss, rewritten))
+	}
+
+	// the scores of the terms are stripped off
+	rewritten, err := rewriteTestPrefixQuery(t, ss, CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := hitScores(t, ss, rewritten)
+	assertEquals(t, scores[0], scores[1])
+	assertEquals(t, scores[0], scores[2])
+
+	// no matching term
+	q := NewPrefixQuery(index.NewTerm("body", "zebra"))
+	q.SetRewriteMethod(private)
+	rewritten, err = q.Rewrite(ss.IndexReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "", rewritten.ToString(""))
+}
+
+func TestMultiTermQueryRewriteTermCount(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, automatonQueryBodies...)
+	defer cleanup()
+
+	defer SetMaxClauseCount(MaxClauseCount())
+	SetMaxClauseCount(3)
+
+	// the boolean rewrites overflow the boolean max clause count
+	for _, method := range []RewriteMethod{SCORING_BOOLEAN_QUERY_REWRITE, CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE} {
+		_, err := rewriteTestPrefixQuery(t, ss, method)
+		assertEquals(t, ErrTooManyClauses, err)
+	}
+
+	// while the others bound the number of terms
+	private := NewConstantScoreAutoRewrite()
+	private.SetDocCountPercent(100)
+	rewritten, err := rewriteTestPrefixQuery(t, ss, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ConstantScore(body:ap*^2)^2", rewritten.ToString(""))
+
+	private.SetTermCountCutoff(2)
+	SetMaxClauseCount(1024)
+	if rewritten, err = rewriteTestPrefixQuery(t, ss, private); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ConstantScore(body:ap*^2)^2", rewritten.ToString(""))
+	assertEquals(t, "ConstantScoreAutoRewrite(termCountCutoff=2, docCountPercent=100)", fmt.Sprint(private))
+
+	SetMaxClauseCount(3)
+	if rewritten, err = rewriteTestPrefixQuery(t, ss, NewTopTermsScoringBooleanQueryRewrite(10)); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "body:apple^2 body:application^2 body:apricot^2", rewritten.ToString(""))
+}
+
+func TestConstantScoreAutoRewriteDefault(t *testing.T) {
+	defer func() {
+		assertEquals(t, "Please create a private instance", recover())
+	}()
+	CONSTANT_SCORE_AUTO_REWRITE_DEFAULT.(*ConstantScoreAutoRewrite).SetTermCountCutoff(10)
+	t.Error("Expected panic of the read-only default")
+}
@@ -0,0 +1,586 @@
+package search
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// search/spans/NearSpansOrdered.java
+
+/*
+A Spans that is formed from the ordered subspans of a SpanNearQuery
+where the subspans do not overlap and have a maximum slop between
+them.
+
+The formed spans only contains minimum slop matches.
+The matching slop is computed from the distance(s) between the
+non overlapping matching Spans.
+Successive matches are always formed from the successive Spans of
+the SpanNearQuery.
+
+The formed spans may contain overlaps when the slop is at least 1.
+For example, when querying using
+
+	t1 t2 t3
+
+with slop at least 1, the fragment:
+
+	t1 t2 t1 t3 t2 t3
+
+matches twice:
+
+	t1 t2 .. t3
+	      t1 .. t2 t3
+*/
+type nearSpansOrdered struct {
+	allowedSlop int
+	firstTime   bool
+	more        bool
+	// The spans in the same order as the SpanNearQuery
+	subSpans []Spans
+	// Indicates that all subSpans have same doc()
+	inSameDoc                      bool
+	matchDoc, matchStart, matchEnd int
+	matchPayload                   [][]byte
+	subSpansByDoc                  []Spans
+	query                          *SpanNearQuery
+	collectPayloads                bool
+}
+
+func newNearSpansOrdered(query *SpanNearQuery, subSpans []Spans, collectPayloads bool) *nearSpansOrdered {
+	if len(subSpans) < 2 {
+		panic(fmt.Sprintf("Less than 2 clauses: %v", query))
+	}
+	ans := &nearSpansOrdered{
+		allowedSlop:     query.slop,
+		firstTime:       true,
+		subSpans:        subSpans,
+		matchDoc:        -1,
+		matchStart:      -1,
+		matchEnd:        -1,
+		subSpansByDoc:   make([]Spans, len(subSpans)),
+		query:           query,
+		collectPayloads: collectPayloads,
+	}
+	copy(ans.subSpansByDoc, subSpans)
+	return ans
+}
+
+func (s *nearSpansOrdered) Doc() int   { return s.matchDoc }
+func (s *nearSpansOrdered) Start() int { return s.matchStart }
+func (s *nearSpansOrdered) End() int   { return s.matchEnd }
+
+func (s *nearSpansOrdered) Payload() [][]byte {
+	return s.matchPayload
+}
+
+func (s *nearSpansOrdered) IsPayloadAvailable() bool {
+	return len(s.matchPayload) != 0
+}
+
+// A match needs all the sub spans, so the cheapest bounds it.
+func (s *nearSpansOrdered) Cost() int64 {
+	minCost := int64(math.MaxInt64)
+	for _, spans := range s.subSpans {
+		if cost := spans.Cost(); cost < minCost {
+			minCost = cost
+		}
+	}
+	return minCost
+}
+
+func (s *nearSpansOrdered) Next() bool {
+	if s.firstTime {
+		s.firstTime = false
+		for _, spans := range s.subSpans {
+			if !spans.Next() {
+				s.more = false
+				return false
+			}
+		}
+		s.more = true
+	}
+	if s.collectPayloads {
+		s.matchPayload = nil
+	}
+	return s.advanceAfterOrdered()
+}
+
+func (s *nearSpansOrdered) SkipTo(target int) bool {
+	if s.firstTime {
+		s.firstTime = false
+		for _, spans := range s.subSpans {
+			if !spans.SkipTo(target) {
+				s.more = false
+				return false
+			}
+		}
+		s.more = true
+	} else if s.more && s.subSpans[0].Doc() < target {
+		if s.subSpans[0].SkipTo(target) {
+			s.inSameDoc = false
+		} else {
+			s.more = false
+			return false
+		}
+	}
+	if s.collectPayloads {
+		s.matchPayload = nil
+	}
+	return s.advanceAfterOrdered()
+}
+
+/*
+Advances the subSpans to just after an ordered match with a minimum
+slop that is smaller than the slop allowed by the SpanNearQuery.
+Returns true iff there is such a match.
+*/
+func (s *nearSpansOrdered) advanceAfterOrdered() bool {
+	for s.more && (s.inSameDoc || s.toSameDoc()) {
+		if s.stretchToOrder() && s.shrinkToAfterShortestMatch() {
+			return true
+		}
+	}
+	return false // no more matches
+}
+
+// Advance the subSpans to the same document
+func (s *nearSpansOrdered) toSameDoc() bool {
+	sort.Stable(spansByDoc(s.subSpansByDoc))
+	firstIndex := 0
+	maxDoc := s.subSpansByDoc[len(s.subSpansByDoc)-1].Doc()
+	for s.subSpansByDoc[firstIndex].Doc() != maxDoc {
+		if !s.subSpansByDoc[firstIndex].SkipTo(maxDoc) {
+			s.more = false
+			s.inSameDoc = false
+			return false
+		}
+		maxDoc = s.subSpansByDoc[firstIndex].Doc()
+		if firstIndex++; firstIndex == len(s.subSpansByDoc) {
+			firstIndex = 0
+		}
+	}
+	s.inSameDoc = true
+	return true
+}
+
+/*
+Check whether two Spans in the same document are ordered. Returns
+true iff spans1 starts before spans2 or the spans start at the same
+position, and spans1 ends before spans2.
+*/
+func docSpansOrdered(spans1, spans2 Spans) bool {
+	// do not call docSpansOrderedByPositions() to avoid invoking
+	// End():
+	if start1, start2 := spans1.Start(), spans2.Start(); start1 != start2 {
+		return start1 < start2
+	}
+	return spans1.End() < spans2.End()
+}
+
+// Like docSpansOrdered(), but use the spans starts and ends as
+// parameters.
+func docSpansOrderedByPositions(start1, end1, start2, end2 int) bool {
+	if start1 == start2 {
+		return end1 < end2
+	}
+	return start1 < start2
+}
+
+/*
+Order the subSpans within the same document by advancing all later
+spans after the previous one.
+*/
+func (s *nearSpansOrdered) stretchToOrder() bool {
+	s.matchDoc = s.subSpans[0].Doc()
+	for i := 1; s.inSameDoc && i < len(s.subSpans); i++ {
+		for !docSpansOrdered(s.subSpans[i-1], s.subSpans[i]) {
+			if !s.subSpans[i].Next() {
+				s.inSameDoc = false
+				s.more = false
+				break
+			} else if s.matchDoc != s.subSpans[i].Doc() {
+				s.inSameDoc = false
+				break
+			}
+		}
+	}
+	return s.inSameDoc
+}
+
+/*
+The subSpans are ordered in the same doc, so there is a possible
+match. Compute the slop while making the match as short as possible
+by advancing all subSpans except the last one in reverse order.
+*/
+func (s *nearSpansOrdered) shrinkToAfterShortestMatch() bool {
+	last := s.subSpans[len(s.subSpans)-1]
+	s.matchStart = last.Start()
+	s.matchEnd = last.End()
+	var possibleMatchPayloads [][]byte
+	if last.IsPayloadAvailable() {
+		possibleMatchPayloads = append(possibleMatchPayloads, last.Payload()...)
+	}
+
+	matchSlop := 0
+	lastStart, lastEnd := s.matchStart, s.matchEnd
+	for i := len(s.subSpans) - 2; i >= 0; i-- {
+		prevSpans := s.subSpans[i]
+		var possiblePayload [][]byte
+		if s.collectPayloads && prevSpans.IsPayloadAvailable() {
+			possiblePayload = prevSpans.Payload()
+		}
+
+		prevStart, prevEnd := prevSpans.Start(), prevSpans.End()
+		for { // advance prevSpans until after (lastStart, lastEnd)
+			if !prevSpans.Next() {
+				s.inSameDoc = false
+				s.more = false
+				break // check remaining subSpans for final match
+			} else if s.matchDoc != prevSpans.Doc() {
+				s.inSameDoc = false // the last subSpans is not advanced here
+				break               // check remaining subSpans for last match in this document
+			} else {
+				ppStart, ppEnd := prevSpans.Start(), prevSpans.End() // cannot avoid invoking End()
+				if !docSpansOrderedByPositions(ppStart, ppEnd, lastStart, lastEnd) {
+					break // check remaining subSpans
+				}
+				// prevSpans still before (lastStart, lastEnd)
+				prevStart, prevEnd = ppStart, ppEnd
+				if s.collectPayloads && prevSpans.IsPayloadAvailable() {
+					possiblePayload = prevSpans.Payload()
+				}
+			}
+		}
+
+		if s.collectPayloads && possiblePayload != nil {
+			possibleMatchPayloads = append(possibleMatchPayloads, possiblePayload...)
+		}
+
+		if s.matchStart > prevEnd { // only non overlapping spans add to slop
+			matchSlop += s.matchStart - prevEnd
+		}
+
+		// Do not break on (matchSlop > allowedSlop) here to make sure
+		// that subSpans[0] is advanced after the match, if any.
+		s.matchStart = prevStart
+		lastStart, lastEnd = prevStart, prevEnd
+	}
+
+	match := matchSlop <= s.allowedSlop
+	if s.collectPayloads && match && len(possibleMatchPayloads) > 0 {
+		s.matchPayload = possibleMatchPayloads
+	}
+	return match // ordered and allowed slop
+}
+
+func (s *nearSpansOrdered) String() string {
+	return fmt.Sprintf("nearSpansOrdered(%v)@%v", s.query, nearSpansPosition(s, s.firstTime, s.more))
+}
+
+// Returns the current position of the near spans, for debugging.
+func nearSpansPosition(s Spans, firstTime, more bool) string {
+	if firstTime {
+		return "START"
+	}
+	if more {
+		return fmt.Sprintf("%v:%v-%v", s.Doc(), s.Start(), s.End())
+	}
+	return "END"
+}
+
+// Sorts the spans by their current documents.
+type spansByDoc []Spans
+
+func (s spansByDoc) Len() int           { return len(s) }
+func (s spansByDoc) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s spansByDoc) Less(i, j int) bool { return s[i].Doc() < s[j].Doc() }
+
+// search/spans/NearSpansUnordered.java
+
+/*
+Similar to nearSpansOrdered, but for the unordered case.
+*/
+type nearSpansUnordered struct {
+	query *SpanNearQuery
+
+	ordered     []*spansCell // spans in query order
+	slop        int          // from query
+	first       *spansCell   // linked list of spans
+	last        *spansCell   // sorted by doc only
+	totalLength int          // sum of current lengths
+	queue       *cellQueue   // sorted queue of spans
+	max         *spansCell   // max element in queue
+	more        bool         // true iff not done
+	firstTime   bool         // true before first Next()
+}
+
+func newNearSpansUnordered(query *SpanNearQuery, subSpans []Spans) *nearSpansUnordered {
+	ans := &nearSpansUnordered{
+		query:     query,
+		slop:      query.slop,
+		more:      true,
+		firstTime: true,
+	}
+	queue := make(cellQueue, 0, len(subSpans))
+	ans.queue = &queue
+	for i, spans := range subSpans {
+		ans.ordered = append(ans.ordered, &spansCell{owner: ans, spans: spans, length: -1, index: i})
+	}
+	return ans
+}
+
+func (s *nearSpansUnordered) Next() bool {
+	if s.firstTime {
+		s.initList(true)
+		s.listToQueue() // initialize queue
+		s.firstTime = false
+	} else if s.more {
+		if s.min().Next() { // trigger further scanning
+			heap.Fix(s.queue, 0) // maintain queue
+		} else {
+			s.more = false
+		}
+	}
+
+	for s.more {
+		queueStale := false
+		if s.min().Doc() != s.max.Doc() { // maintain list
+			s.queueToList()
+			queueStale = true
+		}
+
+		// skip to doc w/ all clauses
+		for s.more && s.first.Doc() < s.last.Doc() {
+			s.more = s.first.SkipTo(s.last.Doc()) // skip first upto last
+			s.firstToLast()                       // and move it to the end
+			queueStale = true
+		}
+
+		if !s.more {
+			return false
+		}
+
+		// found doc w/ all clauses
+		if queueStale { // maintain the queue
+			s.listToQueue()
+		}
+
+		if s.atMatch() {
+			return true
+		}
+
+		s.more = s.min().Next()
+		if s.more {
+			heap.Fix(s.queue, 0) // maintain queue
+		}
+	}
+	return false // no more matches
+}
+
+func (s *nearSpansUnordered) SkipTo(target int) bool {
+	if s.firstTime { // initialize
+		s.initList(false)
+		for cell := s.first; s.more && cell != nil; cell = cell.next {
+			s.more = cell.SkipTo(target) // skip all
+		}
+		if s.more {
+			s.listToQueue()
+		}
+		s.firstTime = false
+	} else { // normal case
+		for s.more && s.min().Doc() < target { // skip as needed
+			if s.min().SkipTo(target) {
+				heap.Fix(s.queue, 0)
+			} else {
+				s.more = false
+			}
+		}
+	}
+	return s.more && (s.atMatch() || s.Next())
+}
+
+// Returns the spans of the clauses, in query order.
+func (s *nearSpansUnordered) subSpans() []Spans {
+	ans := make([]Spans, len(s.ordered))
+	for i, cell := range s.ordered {
+		ans[i] = cell.spans
+	}
+	return ans
+}
+
+func (s *nearSpansUnordered) min() *spansCell {
+	return (*s.queue)[0]
+}
+
+func (s *nearSpansUnordered) Doc() int   { return s.min().Doc() }
+func (s *nearSpansUnordered) Start() int { return s.min().Start() }
+func (s *nearSpansUnordered) End() int   { return s.max.End() }
+
+func (s *nearSpansUnordered) Cost() int64 {
+	minCost := int64(math.MaxInt64)
+	for _, cell := range s.ordered {
+		if cost := cell.Cost(); cost < minCost {
+			minCost = cost
+		}
+	}
+	return minCost
+}
+
+// WARNING: The payloads are not ordered by position.
+func (s *nearSpansUnordered) Payload() [][]byte {
+	var matchPayload [][]byte
+	for cell := s.first; cell != nil; cell = cell.next {
+		if cell.IsPayloadAvailable() {
+			matchPayload = append(matchPayload, cell.Payload()...)
+		}
+	}
+	return matchPayload
+}
+
+func (s *nearSpansUnordered) IsPayloadAvailable() bool {
+	for pointer := s.min(); pointer != nil; pointer = pointer.next {
+		if pointer.IsPayloadAvailable() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *nearSpansUnordered) String() string {
+	return fmt.Sprintf("nearSpansUnordered(%v)@%v", s.query, nearSpansPosition(s, s.firstTime, s.more))
+}
+
+func (s *nearSpansUnordered) initList(next bool) {
+	for i := 0; s.more && i < len(s.ordered); i++ {
+		cell := s.ordered[i]
+		if next {
+			s.more = cell.Next() // move to first entry
+		}
+		if s.more {
+			s.addToList(cell) // add to list
+		}
+	}
+}
+
+func (s *nearSpansUnordered) addToList(cell *spansCell) {
+	if s.last != nil { // add next to end of list
+		s.last.next = cell
+	} else {
+		s.first = cell
+	}
+	s.last = cell
+	cell.next = nil
+}
+
+func (s *nearSpansUnordered) firstToLast() {
+	s.last.next = s.first // move first to end of list
+	s.last = s.first
+	s.first = s.first.next
+	s.last.next = nil
+}
+
+func (s *nearSpansUnordered) queueToList() {
+	s.last, s.first = nil, nil
+	for s.queue.Len() > 0 {
+		s.addToList(heap.Pop(s.queue).(*spansCell))
+	}
+}
+
+func (s *nearSpansUnordered) listToQueue() {
+	*s.queue = (*s.queue)[:0] // rebuild queue
+	for cell := s.first; cell != nil; cell = cell.next {
+		heap.Push(s.queue, cell) // add to queue from list
+	}
+}
+
+func (s *nearSpansUnordered) atMatch() bool {
+	return s.min().Doc() == s.max.Doc() &&
+		s.max.End()-s.min().Start()-s.totalLength <= s.slop
+}
+
+// search/spans/NearSpansUnordered.java/SpansCell
+
+/*
+Wraps a Spans, and can be used to form a linked list. Maintains the
+total length and the max cell of its nearSpansUnordered.
+*/
+type spansCell struct {
+	owner  *nearSpansUnordered
+	spans  Spans
+	next   *spansCell
+	length int
+	index  int
+}
+
+func (c *spansCell) Next() bool {
+	return c.adjust(c.spans.Next())
+}
+
+func (c *spansCell) SkipTo(target int) bool {
+	return c.adjust(c.spans.SkipTo(target))
+}
+
+func (c *spansCell) adjust(condition bool) bool {
+	s := c.owner
+	if c.length != -1 {
+		s.totalLength -= c.length // subtract old length
+	}
+	if condition {
+		c.length = c.End() - c.Start()
+		s.totalLength += c.length // add new length
+		if s.max == nil || c.Doc() > s.max.Doc() ||
+			c.Doc() == s.max.Doc() && c.End() > s.max.End() {
+			s.max = c
+		}
+	}
+	s.more = condition
+	return condition
+}
+
+func (c *spansCell) Doc() int   { return c.spans.Doc() }
+func (c *spansCell) Start() int { return c.spans.Start() }
+func (c *spansCell) End() int   { return c.spans.End() }
+
+func (c *spansCell) Cost() int64 {
+	return c.spans.Cost()
+}
+
+func (c *spansCell) Payload() [][]byte {
+	return c.spans.Payload()
+}
+
+func (c *spansCell) IsPayloadAvailable() bool {
+	return c.spans.IsPayloadAvailable()
+}
+
+func (c *spansCell) String() string {
+	return fmt.Sprintf("%v#%v", c.spans, c.index)
+}
+
+// search/spans/NearSpansUnordered.java/CellQueue
+
+type cellQueue []*spansCell
+
+func (pq cellQueue) Len() int { return len(pq) }
+
+func (pq cellQueue) Less(i, j int) bool {
+	spans1, spans2 := pq[i], pq[j]
+	if spans1.Doc() == spans2.Doc() {
+		return docSpansOrdered(spans1, spans2)
+	}
+	return spans1.Doc() < spans2.Doc()
+}
+
+func (pq cellQueue) Swap(i, j int) { pq[i], pq[j] = pq[j], pq[i] }
+
+func (pq *cellQueue) Push(x interface{}) { *pq = append(*pq, x.(*spansCell)) }
+
+func (pq *cellQueue) Pop() interface{} {
+	n := len(*pq)
+	ans := (*pq)[n-1]
+	*pq = (*pq)[:n-1]
+	return ans
+}
@@ -0,0 +1,403 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+)
+
+// search/NumericRangeQuery.java
+
+/*
+A Query that matches numeric values within a specified range. To use
+this, you must first index the numeric values using IntField,
+FloatField, LongField or DoubleField (expert: NumericTokenStream). If
+your terms are instead textual, you should use TermRangeQuery.
+
+You create a new NumericRangeQuery with the constructor of the
+numeric type, e.g.:
+
+	min, max := int64(1), int64(10)
+	q := NewLongRangeQuery("price", &min, &max, true, true)
+
+matches all documents whose long valued "price" field is between 1
+and 10, inclusive. A nil bound is open.
+
+The precision step of the query must be the one the field was
+indexed with; the constructors without precisionStep use
+util.NUMERIC_PRECISION_STEP_DEFAULT (4), as the fields do.
+
+The numeric values are indexed as trie terms of several precisions:
+each value is indexed at its full precision, and at lower precisions
+obtained by stripping precisionStep bits at a time (see
+util.NumericUtils). A range is split into sub-ranges, where the inner
+parts of the range are matched by a few terms of lower precision, and
+only the margins of the range by terms of higher precision, so the
+number of terms enumerated is small, no matter how many distinct
+values the range contains: for precisionStep 4 and 64 bit values,
+there are at most 15*2*16 = 480 terms (15 terms per precision for
+each of the two margins, and 16 precisions), instead of one term per
+distinct value.
+
+This query uses the CONSTANT_SCORE_AUTO_REWRITE_DEFAULT rewrite method by
+default, like the other MultiTermQuerys.
+*/
+type NumericRangeQuery struct {
+	*MultiTermQuery
+	precisionStep              int
+	dataType                   document.NumericType
+	min, max                   interface{}
+	minInclusive, maxInclusive bool
+	// the prefix coded lower and upper bounds of the sub-ranges, in
+	// pairs; nil if the range is empty
+	rangeBounds [][]byte
+}
+
+var (
+	numericLongNegativeInfinity = util.DoubleToSortableLong(math.Inf(-1))
+	numericLongPositiveInfinity = util.DoubleToSortableLong(math.Inf(1))
+	numericIntNegativeInfinity  = util.FloatToSortableInt(float32(math.Inf(-1)))
+	numericIntPositiveInfinity  = util.FloatToSortableInt(float32(math.Inf(1)))
+)
+
+func newNumericRangeQuery(field string, precisionStep int, dataType document.NumericType,
+	min, max interface{}, minInclusive, maxInclusive bool) *NumericRangeQuery {
+	if precisionStep < 1 {
+		panic("precisionStep must be >=1")
+	}
+	ans := &NumericRangeQuery{
+		precisionStep: precisionStep,
+		dataType:      dataType,
+		min:           min,
+		max:           max,
+		minInclusive:  minInclusive,
+		maxInclusive:  maxInclusive,
+	}
+	ans.MultiTermQuery = NewMultiTermQuery(ans, field)
+	ans.rangeBounds = ans.splitRange()
+	return ans
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries a long range
+using the given precisionStep. You can have half-open ranges (which
+are in fact </≤ or >/≥ queries) by setting the min or max value to
+nil. By setting inclusive to false, it will match all documents
+excluding the bounds, with inclusive on, the boundaries are hits,
+too. It panics if precisionStep is less than 1.
+*/
+func NewLongRangeQueryWithStep(field string, precisionStep int, min, max *int64,
+	minInclusive, maxInclusive bool) *NumericRangeQuery {
+	var minValue, maxValue interface{}
+	if min != nil {
+		minValue = *min
+	}
+	if max != nil {
+		maxValue = *max
+	}
+	return newNumericRangeQuery(field, precisionStep, document.NUMERIC_TYPE_LONG,
+		minValue, maxValue, minInclusive, maxInclusive)
+}
+
+// Calls NewLongRangeQueryWithStep() with the default precision step
+// util.NUMERIC_PRECISION_STEP_DEFAULT.
+func NewLongRangeQuery(field string, min, max *int64, minInclusive, maxInclusive bool) *NumericRangeQuery {
+	return NewLongRangeQueryWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT,
+		min, max, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries an int range
+using the given precisionStep. The values are truncated to 32 bits,
+as the ones of IntField. See NewLongRangeQueryWithStep() for the
+bounds.
+*/
+func NewIntRangeQueryWithStep(field string, precisionStep int, min, max *int,
+	minInclusive, maxInclusive bool) *NumericRangeQuery {
+	var minValue, maxValue interface{}
+	if min != nil {
+		minValue = int32(*min)
+	}
+	if max != nil {
+		maxValue = int32(*max)
+	}
+	return newNumericRangeQuery(field, precisionStep, document.NUMERIC_TYPE_INT,
+		minValue, maxValue, minInclusive, maxInclusive)
+}
+
+// Calls NewIntRangeQueryWithStep() with the default precision step
+// util.NUMERIC_PRECISION_STEP_DEFAULT.
+func NewIntRangeQuery(field string, min, max *int, minInclusive, maxInclusive bool) *NumericRangeQuery {
+	return NewIntRangeQueryWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT,
+		min, max, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries a double range
+using the given precisionStep. See NewLongRangeQueryWithStep() for
+the bounds. math.NaN() will never match a half-open range, to hit
+NaN use a query with min == max == math.NaN(). By setting inclusive
+to false, it will match all documents excluding the bounds, with
+inclusive on, the boundaries are hits, too.
+*/
+func NewDoubleRangeQueryWithStep(field string, precisionStep int, min, max *float64,
+	minInclusive, maxInclusive bool) *NumericRangeQuery {
+	var minValue, maxValue interface{}
+	if min != nil {
+		minValue = *min
+	}
+	if max != nil {
+		maxValue = *max
+	}
+	return newNumericRangeQuery(field, precisionStep, document.NUMERIC_TYPE_DOUBLE,
+		minValue, maxValue, minInclusive, maxInclusive)
+}
+
+// Calls NewDoubleRangeQueryWithStep() with the default precision step
+// util.NUMERIC_PRECISION_STEP_DEFAULT.
+func NewDoubleRangeQuery(field string, min, max *float64, minInclusive, maxInclusive bool) *NumericRangeQuery {
+	return NewDoubleRangeQueryWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT,
+		min, max, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries a float range
+using the given precisionStep. See NewDoubleRangeQueryWithStep() for
+the bounds.
+*/
+func NewFloatRangeQueryWithStep(field string, precisionStep int, min, max *float32,
+	minInclusive, maxInclusive bool) *NumericRangeQuery {
+	var minValue, maxValue interface{}
+	if min != nil {
+		minValue = *min
+	}
+	if max != nil {
+		maxValue = *max
+	}
+	return newNumericRangeQuery(field, precisionStep, document.NUMERIC_TYPE_FLOAT,
+		minValue, maxValue, minInclusive, maxInclusive)
+}
+
+// Calls NewFloatRangeQueryWithStep() with the default precision step
+// util.NUMERIC_PRECISION_STEP_DEFAULT.
+func NewFloatRangeQuery(field string, min, max *float32, minInclusive, maxInclusive bool) *NumericRangeQuery {
+	return NewFloatRangeQueryWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT,
+		min, max, minInclusive, maxInclusive)
+}
+
+/*
+Splits the range into the prefix coded bounds of its sub-ranges.
+Returns nil if the range is empty, i.e. its lower bound is greater
+than its upper bound.
+*/
+func (q *NumericRangeQuery) splitRange() [][]byte {
+	var rangeBounds [][]byte
+	addRange := func(minPrefixCoded, maxPrefixCoded []byte) {
+		rangeBounds = append(rangeBounds, minPrefixCoded, maxPrefixCoded)
+	}
+	switch q.dataType {
+	case document.NUMERIC_TYPE_LONG, document.NUMERIC_TYPE_DOUBLE:
+		var minBound, maxBound int64
+		if q.dataType == document.NUMERIC_TYPE_LONG {
+			minBound, maxBound = math.MinInt64, math.MaxInt64
+			if q.min != nil {
+				minBound = q.min.(int64)
+			}
+			if q.max != nil {
+				maxBound = q.max.(int64)
+			}
+		} else {
+			minBound, maxBound = numericLongNegativeInfinity, numericLongPositiveInfinity
+			if q.min != nil {
+				minBound = util.DoubleToSortableLong(q.min.(float64))
+			}
+			if q.max != nil {
+				maxBound = util.DoubleToSortableLong(q.max.(float64))
+			}
+		}
+		if !q.minInclusive && q.min != nil {
+			if minBound == math.MaxInt64 {
+				return nil
+			}
+			minBound++
+		}
+		if !q.maxInclusive && q.max != nil {
+			if maxBound == math.MinInt64 {
+				return nil
+			}
+			maxBound--
+		}
+		util.SplitLongRange(addRange, q.precisionStep, minBound, maxBound)
+
+	case document.NUMERIC_TYPE_INT, document.NUMERIC_TYPE_FLOAT:
+		var minBound, maxBound int32
+		if q.dataType == document.NUMERIC_TYPE_INT {
+			minBound, maxBound = math.MinInt32, math.MaxInt32
+			if q.min != nil {
+				minBound = q.min.(int32)
+			}
+			if q.max != nil {
+				maxBound = q.max.(int32)
+			}
+		} else {
+			minBound, maxBound = numericIntNegativeInfinity, numericIntPositiveInfinity
+			if q.min != nil {
+				minBound = util.FloatToSortableInt(q.min.(float32))
+			}
+			if q.max != nil {
+				maxBound = util.FloatToSortableInt(q.max.(float32))
+			}
+		}
+		if !q.minInclusive && q.min != nil {
+			if minBound == math.MaxInt32 {
+				return nil
+			}
+			minBound++
+		}
+		if !q.maxInclusive && q.max != nil {
+			if maxBound == math.MinInt32 {
+				return nil
+			}
+			maxBound--
+		}
+		util.SplitIntRange(addRange, q.precisionStep, minBound, maxBound)
+
+	default:
+		panic(fmt.Sprintf("Invalid NumericType %v", q.dataType))
+	}
+	return rangeBounds
+}
+
+func (q *NumericRangeQuery) TermsEnum(terms index.Terms) (index.TermsEnum, error) {
+	// an empty split means min > max
+	if len(q.rangeBounds) == 0 {
+		return index.EMPTY_TERMS_ENUM, nil
+	}
+	return newNumericRangeTermsEnum(terms.Iterator(nil), q.rangeBounds), nil
+}
+
+// Returns true if the lower endpoint is inclusive
+func (q *NumericRangeQuery) IncludesMin() bool {
+	return q.minInclusive
+}
+
+// Returns true if the upper endpoint is inclusive
+func (q *NumericRangeQuery) IncludesMax() bool {
+	return q.maxInclusive
+}
+
+// Returns the lower value of this range query, nil if it's open. It's
+// an int32, int64, float32 or float64 by the numeric type.
+func (q *NumericRangeQuery) Min() interface{} {
+	return q.min
+}
+
+// Returns the upper value of this range query, nil if it's open. It's
+// an int32, int64, float32 or float64 by the numeric type.
+func (q *NumericRangeQuery) Max() interface{} {
+	return q.max
+}
+
+// Returns the precision step.
+func (q *NumericRangeQuery) PrecisionStep() int {
+	return q.precisionStep
+}
+
+func (q *NumericRangeQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	if q.Field() != field {
+		buf.WriteString(q.Field())
+		buf.WriteString(":")
+	}
+	if q.minInclusive {
+		buf.WriteString("[")
+	} else {
+		buf.WriteString("{")
+	}
+	if q.min == nil {
+		buf.WriteString("*")
+	} else {
+		fmt.Fprintf(&buf, "%v", q.min)
+	}
+	buf.WriteString(" TO ")
+	if q.max == nil {
+		buf.WriteString("*")
+	} else {
+		fmt.Fprintf(&buf, "%v", q.max)
+	}
+	if q.maxInclusive {
+		buf.WriteString("]")
+	} else {
+		buf.WriteString("}")
+	}
+	buf.WriteString(boostString(q.boost))
+	return buf.String()
+}
+
+// search/NumericRangeQuery.java/NumericRangeTermsEnum
+
+/*
+Subclass of FilteredTermsEnum for enumerating all terms that match
+the sub-ranges for trie range queries.
+
+The sub-ranges are split from the highest precision to the lowest,
+and the shift is encoded in the first byte of the terms, so their
+bounds are in term order, and the enumeration only seeks forward, to
+the lower bound of the next sub-range.
+*/
+type numericRangeTermsEnum struct {
+	*index.FilteredTermsEnumImpl
+	currentLowerBound, currentUpperBound []byte
+	rangeBounds                          [][]byte
+}
+
+func newNumericRangeTermsEnum(tenum index.TermsEnum, rangeBounds [][]byte) *numericRangeTermsEnum {
+	ans := &numericRangeTermsEnum{rangeBounds: rangeBounds}
+	ans.FilteredTermsEnumImpl = index.NewFilteredTermsEnum(ans, tenum, true)
+	return ans
+}
+
+func (e *numericRangeTermsEnum) nextRange() {
+	e.currentLowerBound, e.currentUpperBound = e.rangeBounds[0], e.rangeBounds[1]
+	e.rangeBounds = e.rangeBounds[2:]
+}
+
+func (e *numericRangeTermsEnum) NextSeekTerm(term []byte) ([]byte, error) {
+	for len(e.rangeBounds) >= 2 {
+		e.nextRange()
+		// if the new upper bound is before the term parameter, the
+		// sub-range is never a hit
+		if term != nil && bytes.Compare(term, e.currentUpperBound) > 0 {
+			continue
+		}
+		// never seek backwards, so use current term if lower bound is
+		// smaller
+		if term != nil && bytes.Compare(term, e.currentLowerBound) > 0 {
+			return term, nil
+		}
+		return e.currentLowerBound, nil
+	}
+	// no more sub-range enums available
+	e.currentLowerBound, e.currentUpperBound = nil, nil
+	return nil, nil
+}
+
+func (e *numericRangeTermsEnum) Accept(term []byte) index.AcceptStatus {
+	for e.currentUpperBound == nil || bytes.Compare(term, e.currentUpperBound) > 0 {
+		if len(e.rangeBounds) == 0 {
+			return index.ACCEPT_STATUS_END
+		}
+		// peek next sub-range, only seek if the current term is smaller
+		// than next lower bound
+		if bytes.Compare(term, e.rangeBounds[0]) < 0 {
+			return index.ACCEPT_STATUS_NO_AND_SEEK
+		}
+		// step forward to next range without seeking, as next lower
+		// range bound is less or equal current term
+		e.nextRange()
+	}
+	return index.ACCEPT_STATUS_YES
+}
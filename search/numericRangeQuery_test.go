@@ -0,0 +1,141 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const numericTestDocs = 100
+
+// The value of the numeric fields of the i-th test document.
+func numericTestValue(i int) int64 {
+	return int64(i*23 - 1000)
+}
+
+func newNumericTestSearcher(t *testing.T) (*IndexSearcher, func()) {
+	step2 := document.NewFieldTypeFrom(document.LONG_FIELD_TYPE_NOT_STORED)
+	step2.SetNumericPrecisionStep(2)
+	step2.Freeze()
+	docs := make([][]document.IndexableField, numericTestDocs)
+	for i := range docs {
+		v := numericTestValue(i)
+		docs[i] = []document.IndexableField{
+			document.NewIntField("int", int(v), document.STORE_NO),
+			document.NewLongField("long", v<<20, document.STORE_NO),
+			document.NewLongFieldWithType("long2", v, step2),
+			document.NewFloatField("float", float32(v)/4, document.STORE_NO),
+			document.NewDoubleField("double", float64(v)/4, document.STORE_NO),
+		}
+	}
+	return newTestSearcherOfDocs(t, docs...)
+}
+
+func totalHits(t *testing.T, ss *IndexSearcher, q Query) int {
+	docs, err := ss.SearchTop(q, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return docs.TotalHits
+}
+
+// Returns the number of test documents in the range, and the bounds
+// of the range, nil if open.
+func numericTestRange(min, max int64, minInclusive, maxInclusive, openMin, openMax bool) (int, *int64, *int64) {
+	count := 0
+	for i := 0; i < numericTestDocs; i++ {
+		v := numericTestValue(i)
+		if (openMin || v > min || minInclusive && v == min) && (openMax || v < max || maxInclusive && v == max) {
+			count++
+		}
+	}
+	var minBound, maxBound *int64
+	if !openMin {
+		minBound = &min
+	}
+	if !openMax {
+		maxBound = &max
+	}
+	return count, minBound, maxBound
+}
+
+func TestNumericRangeQuery(t *testing.T) {
+	ss, cleanup := newNumericTestSearcher(t)
+	defer cleanup()
+
+	rnd := rand.New(rand.NewSource(42))
+	for i := 0; i < 10; i++ {
+		min, max := rnd.Int63n(2400)-1200, rnd.Int63n(2400)-1200
+		minInclusive, maxInclusive := rnd.Intn(2) == 0, rnd.Intn(2) == 0
+		openMin, openMax := rnd.Intn(10) == 0, rnd.Intn(10) == 0
+		expected, minBound, maxBound := numericTestRange(min, max, minInclusive, maxInclusive, openMin, openMax)
+
+		var intMin, intMax *int
+		var longMin, longMax *int64
+		var floatMin, floatMax *float32
+		var doubleMin, doubleMax *float64
+		if minBound != nil {
+			intMin, longMin = new(int), new(int64)
+			floatMin, doubleMin = new(float32), new(float64)
+			*intMin, *longMin = int(min), min<<20
+			*floatMin, *doubleMin = float32(min)/4, float64(min)/4
+		}
+		if maxBound != nil {
+			intMax, longMax = new(int), new(int64)
+			floatMax, doubleMax = new(float32), new(float64)
+			*intMax, *longMax = int(max), max<<20
+			*floatMax, *doubleMax = float32(max)/4, float64(max)/4
+		}
+		for _, q := range []*NumericRangeQuery{
+			NewIntRangeQuery("int", intMin, intMax, minInclusive, maxInclusive),
+			NewLongRangeQuery("long", longMin, longMax, minInclusive, maxInclusive),
+			NewLongRangeQueryWithStep("long2", 2, minBound, maxBound, minInclusive, maxInclusive),
+			NewFloatRangeQuery("float", floatMin, floatMax, minInclusive, maxInclusive),
+			NewDoubleRangeQuery("double", doubleMin, doubleMax, minInclusive, maxInclusive),
+		} {
+			if actual := totalHits(t, ss, q); actual != expected {
+				t.Errorf("Expected %v hits of %v, but %v", expected, q, actual)
+			}
+		}
+	}
+}
+
+func TestNumericRangeQueryTerms(t *testing.T) {
+	ss, cleanup := newNumericTestSearcher(t)
+	defer cleanup()
+
+	// the whole range is matched by a few terms of lower precision
+	min, max := int64(-1000), int64(1277)
+	q := NewLongRangeQueryWithStep("long2", 2, &min, &max, true, true)
+	q.SetRewriteMethod(SCORING_BOOLEAN_QUERY_REWRITE)
+	rewritten, err := q.Rewrite(ss.IndexReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, numericTestDocs, totalHits(t, ss, rewritten))
+	if n := len(rewritten.(*BooleanQuery).Clauses()); n >= numericTestDocs/4 {
+		t.Errorf("Expected a few terms, but %v", n)
+	}
+
+	// empty ranges
+	max = min - 1
+	assertEquals(t, 0, totalHits(t, ss, NewLongRangeQueryWithStep("long2", 2, &min, &max, true, true)))
+	assertEquals(t, 0, totalHits(t, ss, NewLongRangeQueryWithStep("long2", 2, &min, &min, true, false)))
+	maxInt := math.MaxInt32
+	assertEquals(t, 0, totalHits(t, ss, NewIntRangeQuery("int", &maxInt, nil, false, true)))
+}
+
+func TestNumericRangeQueryToString(t *testing.T) {
+	min, max := 1.5, 4.0
+	q := NewDoubleRangeQuery("price", &min, &max, true, false)
+	assertEquals(t, "[1.5 TO 4}", q.ToString("price"))
+	assertEquals(t, "price:[1.5 TO 4}", fmt.Sprint(q))
+	lmin := int64(3)
+	q = NewLongRangeQuery("time", &lmin, nil, false, true)
+	q.SetBoost(2)
+	assertEquals(t, "time:{3 TO *]^2", q.ToString(""))
+	assertEquals(t, int64(3), q.Min())
+	assertEquals(t, nil, q.Max())
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"math"
+)
+
+// search/payloads/PayloadFunction.java
+
+/*
+An abstract class that defines a way for Payload*Query instances to
+transform the cumulative effects of payload scores for a document.
+
+This class and its derivations are experimental and subject to
+change.
+*/
+type PayloadFunction interface {
+	/*
+		Calculate the score up to this point for this doc and field.
+
+		start and end are the positions of the match, numPayloadsSeen
+		the number of payloads seen so far, currentScore the current
+		score so far, and currentPayloadScore the score of the current
+		payload. Returns the new current score.
+	*/
+	CurrentScore(docId int, field string, start, end, numPayloadsSeen int,
+		currentScore, currentPayloadScore float32) float32
+	/*
+		Calculate the final score for all the payloads seen so far for
+		this doc/field.
+	*/
+	DocScore(docId int, field string, numPayloadsSeen int, payloadScore float32) float32
+}
+
+// Explains the final score of the payloads of the document.
+func explainPayloadFunction(f PayloadFunction, docId int, field string,
+	numPayloadsSeen int, payloadScore float32) *Explanation {
+	return NewExplanation(f.DocScore(docId, field, numPayloadsSeen, payloadScore),
+		simpleName(f)+".docScore()")
+}
+
+// search/payloads/AveragePayloadFunction.java
+
+/*
+Calculate the final score as the average score of all payloads seen.
+
+Is thread safe and completely reusable.
+*/
+type AveragePayloadFunction struct{}
+
+func (f AveragePayloadFunction) CurrentScore(docId int, field string, start, end, numPayloadsSeen int,
+	currentScore, currentPayloadScore float32) float32 {
+	return currentPayloadScore + currentScore
+}
+
+func (f AveragePayloadFunction) DocScore(docId int, field string, numPayloadsSeen int, payloadScore float32) float32 {
+	if numPayloadsSeen > 0 {
+		return payloadScore / float32(numPayloadsSeen)
+	}
+	return 1
+}
+
+// search/payloads/MaxPayloadFunction.java
+
+/*
+Returns the maximum payload score seen, else 1 if there are no
+payloads on the doc.
+
+Is thread safe and completely reusable.
+*/
+type MaxPayloadFunction struct{}
+
+func (f MaxPayloadFunction) CurrentScore(docId int, field string, start, end, numPayloadsSeen int,
+	currentScore, currentPayloadScore float32) float32 {
+	if numPayloadsSeen == 0 {
+		return currentPayloadScore
+	}
+	return float32(math.Max(float64(currentPayloadScore), float64(currentScore)))
+}
+
+func (f MaxPayloadFunction) DocScore(docId int, field string, numPayloadsSeen int, payloadScore float32) float32 {
+	if numPayloadsSeen > 0 {
+		return payloadScore
+	}
+	return 1
+}
+
+// search/payloads/MinPayloadFunction.java
+
+// Calculates the minimum payload seen.
+type MinPayloadFunction struct{}
+
+func (f MinPayloadFunction) CurrentScore(docId int, field string, start, end, numPayloadsSeen int,
+	currentScore, currentPayloadScore float32) float32 {
+	if numPayloadsSeen == 0 {
+		return currentPayloadScore
+	}
+	return float32(math.Min(float64(currentPayloadScore), float64(currentScore)))
+}
+
+func (f MinPayloadFunction) DocScore(docId int, field string, numPayloadsSeen int, payloadScore float32) float32 {
+	if numPayloadsSeen > 0 {
+		return payloadScore
+	}
+	return 1
+}
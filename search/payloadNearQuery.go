@@ -0,0 +1,201 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/payloads/PayloadNearQuery.java
+
+/*
+This class is very similar to SpanNearQuery except that it factors in
+the value of the payloads located at each of the positions where the
+TermSpans occurs.
+
+NOTE: In order to take advantage of this with the default scoring
+implementation (DefaultSimilarity), you must override
+DefaultSimilarity.ScorePayload(), which returns 1 by default, and
+wrap the overriding type with NewTFIDFSimilarity().
+
+Payload scores are aggregated using a pluggable PayloadFunction.
+*/
+type PayloadNearQuery struct {
+	*SpanNearQuery
+	function PayloadFunction
+}
+
+// Construct a PayloadNearQuery which averages the scores of the
+// payloads.
+func NewPayloadNearQuery(clauses []SpanQuery, slop int, inOrder bool) *PayloadNearQuery {
+	return NewPayloadNearQueryWithFunction(clauses, slop, inOrder, AveragePayloadFunction{})
+}
+
+// Construct a PayloadNearQuery which aggregates the scores of the
+// payloads by function.
+func NewPayloadNearQueryWithFunction(clauses []SpanQuery, slop int, inOrder bool,
+	function PayloadFunction) *PayloadNearQuery {
+	ans := &PayloadNearQuery{function: function}
+	ans.SpanNearQuery = NewSpanNearQuery(clauses, slop, inOrder)
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+func (q *PayloadNearQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	w, err := NewSpanWeight(q, ss)
+	if err != nil {
+		return nil, err
+	}
+	return &payloadNearSpanWeight{w, q}, nil
+}
+
+func (q *PayloadNearQuery) Rewrite(r index.IndexReader) (Query, error) {
+	clauses, err := rewriteSpanQueries(r, q.clauses)
+	if clauses == nil || err != nil {
+		return q, err // no clauses rewrote
+	}
+	clone := NewPayloadNearQueryWithFunction(clauses, q.slop, q.inOrder, q.function)
+	clone.SetBoost(q.boost)
+	return clone, nil
+}
+
+func (q *PayloadNearQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	buf.WriteString("payloadNear([")
+	for i, clause := range q.clauses {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(clause.ToString(field))
+	}
+	fmt.Fprintf(&buf, "], %v, %v)", q.slop, q.inOrder)
+	buf.WriteString(boostString(q.boost))
+	return buf.String()
+}
+
+// search/payloads/PayloadNearQuery.java/PayloadNearSpanWeight
+
+type payloadNearSpanWeight struct {
+	*SpanWeight
+	query *PayloadNearQuery
+}
+
+func (w *payloadNearSpanWeight) Scorer(ctx index.AtomicReaderContext,
+	scoreDocsInOrder, topScorer bool, acceptDocs util.Bits) (Scorer, error) {
+	if w.stats == nil {
+		return nil, nil
+	}
+	spans, err := w.query.Spans(ctx, acceptDocs, w.termContexts)
+	if err != nil {
+		return nil, err
+	}
+	docScorer, err := w.similarity.SloppySimScorer(w.stats, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newPayloadNearSpanScorer(w.query, spans, w, docScorer), nil
+}
+
+func (w *payloadNearSpanWeight) Explain(ctx index.AtomicReaderContext, doc int) (*Explanation, error) {
+	scorer, err := w.Scorer(ctx, true, false, ctx.Reader().(index.AtomicReader).LiveDocs())
+	if err != nil {
+		return nil, err
+	}
+	if scorer != nil {
+		if newDoc, _ := scorer.Advance(doc); newDoc == doc {
+			s := scorer.(*payloadNearSpanScorer)
+			expl, err := w.explainSpanScore(ctx, doc, s.SloppyFreq())
+			if err != nil {
+				return nil, err
+			}
+			// now the payloads part
+			payloadExpl := explainPayloadFunction(w.query.function, doc,
+				w.query.field, s.payloadsSeen, s.payloadScore)
+			// combined
+			result := NewComplexExplanation(true, expl.Value()*payloadExpl.Value(), "PayloadNearQuery, product of:")
+			result.AddDetail(expl)
+			result.AddDetail(payloadExpl)
+			return result, nil
+		}
+	}
+	return NewComplexExplanation(false, 0, "no matching term"), nil
+}
+
+// search/payloads/PayloadNearQuery.java/PayloadNearSpanScorer
+
+/*
+Scores the spans of the near query, and the payloads collected by the
+near spans, at any level of nesting.
+*/
+type payloadNearSpanScorer struct {
+	*SpanScorer
+	query        *PayloadNearQuery
+	payloadScore float32
+	payloadsSeen int
+}
+
+func newPayloadNearSpanScorer(query *PayloadNearQuery, spans Spans,
+	w Weight, docScorer SloppySimScorer) *payloadNearSpanScorer {
+	ans := &payloadNearSpanScorer{SpanScorer: &SpanScorer{}, query: query}
+	ans.init(ans, ans, spans, w, docScorer)
+	return ans
+}
+
+// Get the payloads associated with all underlying subspans
+func (s *payloadNearSpanScorer) collectPayloads(subSpans []Spans) {
+	for _, spans := range subSpans {
+		switch spans := spans.(type) {
+		case *nearSpansOrdered:
+			if spans.IsPayloadAvailable() {
+				s.processPayloads(spans.Payload(), spans.Start(), spans.End())
+			}
+			s.collectPayloads(spans.subSpans)
+		case *nearSpansUnordered:
+			if spans.IsPayloadAvailable() {
+				s.processPayloads(spans.Payload(), spans.Start(), spans.End())
+			}
+			s.collectPayloads(spans.subSpans())
+		}
+	}
+}
+
+/*
+By default, uses the PayloadFunction to score the payloads, but can
+be overridden to do other things.
+
+payloads are the payloads of the match from start to end.
+*/
+func (s *payloadNearSpanScorer) processPayloads(payloads [][]byte, start, end int) {
+	for _, payload := range payloads {
+		s.payloadScore = s.query.function.CurrentScore(s.doc, s.query.field, start, end,
+			s.payloadsSeen, s.payloadScore, s.docScorer.ComputePayloadFactor(
+				s.doc, s.spans.Start(), s.spans.End(), payload))
+		s.payloadsSeen++
+	}
+}
+
+func (s *payloadNearSpanScorer) setFreqCurrentDoc() bool {
+	if !s.more {
+		return false
+	}
+	s.doc = s.spans.Doc()
+	s.freq, s.numMatches = 0, 0
+	s.payloadScore, s.payloadsSeen = 0, 0
+	for {
+		matchLength := s.spans.End() - s.spans.Start()
+		s.freq += s.docScorer.ComputeSlopFactor(matchLength)
+		s.numMatches++
+		s.collectPayloads([]Spans{s.spans})
+		if s.more = s.spans.Next(); !s.more || s.doc != s.spans.Doc() {
+			break
+		}
+	}
+	return true
+}
+
+// Returns the span score multiplied by the score of the payloads.
+func (s *payloadNearSpanScorer) Score() float32 {
+	return s.SpanScorer.Score() * s.query.function.DocScore(
+		s.doc, s.query.field, s.payloadsSeen, s.payloadScore)
+}
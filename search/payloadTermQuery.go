@@ -0,0 +1,179 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/payloads/PayloadTermQuery.java
+
+/*
+This class is very similar to SpanTermQuery except that it factors in
+the value of the payload located at each of the positions where the
+term occurs.
+
+NOTE: In order to take advantage of this with the default scoring
+implementation (DefaultSimilarity), you must override
+DefaultSimilarity.ScorePayload(), which returns 1 by default, and
+wrap the overriding type with NewTFIDFSimilarity().
+
+Payload scores are aggregated using a pluggable PayloadFunction.
+*/
+type PayloadTermQuery struct {
+	*SpanTermQuery
+	function         PayloadFunction
+	includeSpanScore bool
+}
+
+/*
+Construct a PayloadTermQuery whose score is the span score of the
+term multiplied by the score of its payloads, as aggregated by
+function.
+*/
+func NewPayloadTermQuery(term index.Term, function PayloadFunction) *PayloadTermQuery {
+	return NewPayloadTermQueryWithSpanScore(term, function, true)
+}
+
+/*
+Same as NewPayloadTermQuery, but if includeSpanScore is false, the
+score is the score of the payloads only, ignoring the span score.
+*/
+func NewPayloadTermQueryWithSpanScore(term index.Term, function PayloadFunction,
+	includeSpanScore bool) *PayloadTermQuery {
+	ans := &PayloadTermQuery{function: function, includeSpanScore: includeSpanScore}
+	ans.SpanTermQuery = &SpanTermQuery{NewAbstractQuery(ans), term}
+	return ans
+}
+
+func (q *PayloadTermQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	w, err := NewSpanWeight(q, ss)
+	if err != nil {
+		return nil, err
+	}
+	return &payloadTermWeight{w, q}, nil
+}
+
+// search/payloads/PayloadTermQuery.java/PayloadTermWeight
+
+type payloadTermWeight struct {
+	*SpanWeight
+	query *PayloadTermQuery
+}
+
+func (w *payloadTermWeight) Scorer(ctx index.AtomicReaderContext,
+	scoreDocsInOrder, topScorer bool, acceptDocs util.Bits) (Scorer, error) {
+	if w.stats == nil {
+		return nil, nil
+	}
+	spans, err := w.query.Spans(ctx, acceptDocs, w.termContexts)
+	if err != nil {
+		return nil, err
+	}
+	docScorer, err := w.similarity.SloppySimScorer(w.stats, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newPayloadTermSpanScorer(w.query, spans, w, docScorer), nil
+}
+
+func (w *payloadTermWeight) Explain(ctx index.AtomicReaderContext, doc int) (*Explanation, error) {
+	scorer, err := w.Scorer(ctx, true, false, ctx.Reader().(index.AtomicReader).LiveDocs())
+	if err != nil {
+		return nil, err
+	}
+	if scorer != nil {
+		if newDoc, _ := scorer.Advance(doc); newDoc == doc {
+			s := scorer.(*payloadTermSpanScorer)
+			expl, err := w.explainSpanScore(ctx, doc, s.SloppyFreq())
+			if err != nil {
+				return nil, err
+			}
+			// now the payloads part
+			payloadExpl := explainPayloadFunction(w.query.function, doc,
+				w.query.term.Field, s.payloadsSeen, s.payloadScore)
+			// combined
+			var result *Explanation
+			if w.query.includeSpanScore {
+				result = NewComplexExplanation(true, expl.Value()*payloadExpl.Value(), "btq, product of:")
+				result.AddDetail(expl)
+			} else {
+				result = NewComplexExplanation(true, payloadExpl.Value(), "btq(includeSpanScore=false), result of:")
+			}
+			result.AddDetail(payloadExpl)
+			return result, nil
+		}
+	}
+	return NewComplexExplanation(false, 0, "no matching term"), nil
+}
+
+// search/payloads/PayloadTermQuery.java/PayloadTermWeight/PayloadTermSpanScorer
+
+/*
+Scores the span of the term, and the payloads of the term at each of
+its positions.
+*/
+type payloadTermSpanScorer struct {
+	*SpanScorer
+	query        *PayloadTermQuery
+	payloadScore float32
+	payloadsSeen int
+}
+
+func newPayloadTermSpanScorer(query *PayloadTermQuery, spans Spans,
+	w Weight, docScorer SloppySimScorer) *payloadTermSpanScorer {
+	ans := &payloadTermSpanScorer{SpanScorer: &SpanScorer{}, query: query}
+	ans.init(ans, ans, spans, w, docScorer)
+	return ans
+}
+
+func (s *payloadTermSpanScorer) setFreqCurrentDoc() bool {
+	if !s.more {
+		return false
+	}
+	s.doc = s.spans.Doc()
+	s.freq, s.numMatches = 0, 0
+	s.payloadScore, s.payloadsSeen = 0, 0
+	for s.more && s.doc == s.spans.Doc() {
+		matchLength := s.spans.End() - s.spans.Start()
+		s.freq += s.docScorer.ComputeSlopFactor(matchLength)
+		s.numMatches++
+		s.processPayload()
+		s.more = s.spans.Next() // this moves positions to the next match in this document
+	}
+	return s.more || s.freq != 0
+}
+
+// Adds the score of the payload at the current position, if any.
+func (s *payloadTermSpanScorer) processPayload() {
+	if !s.spans.IsPayloadAvailable() {
+		return // zero out the payload?
+	}
+	factor := float32(1)
+	if payload := s.spans.Payload(); len(payload) > 0 {
+		factor = s.docScorer.ComputePayloadFactor(s.doc, s.spans.Start(), s.spans.End(), payload[0])
+	}
+	s.payloadScore = s.query.function.CurrentScore(s.doc, s.query.term.Field,
+		s.spans.Start(), s.spans.End(), s.payloadsSeen, s.payloadScore, factor)
+	s.payloadsSeen++
+}
+
+/*
+Returns the span score multiplied by the payload score, or the payload
+score only if the span score is not included.
+*/
+func (s *payloadTermSpanScorer) Score() float32 {
+	if s.query.includeSpanScore {
+		return s.spanScore() * s.docPayloadScore()
+	}
+	return s.docPayloadScore()
+}
+
+// Returns the SpanScorer score only.
+func (s *payloadTermSpanScorer) spanScore() float32 {
+	return s.SpanScorer.Score()
+}
+
+// The score for the payload, as aggregated by the PayloadFunction.
+func (s *payloadTermSpanScorer) docPayloadScore() float32 {
+	return s.query.function.DocScore(s.doc, s.query.term.Field, s.payloadsSeen, s.payloadScore)
+}
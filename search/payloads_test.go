@@ -0,0 +1,217 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis/analysistest"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"testing"
+)
+
+func TestPayloadFunctions(t *testing.T) {
+	for _, v := range []struct {
+		function PayloadFunction
+		score    float32
+	}{
+		{AveragePayloadFunction{}, 2.5},
+		{MaxPayloadFunction{}, 4},
+		{MinPayloadFunction{}, 1},
+	} {
+		var score float32
+		for i, payloadScore := range []float32{3, 1, 4, 2} {
+			score = v.function.CurrentScore(0, "f", i, i+1, i, score, payloadScore)
+		}
+		assertEquals(t, v.score, v.function.DocScore(0, "f", 4, score))
+		// no payload seen
+		assertEquals(t, float32(1), v.function.DocScore(0, "f", 0, 0))
+	}
+}
+
+// Spans of single positions, with their payloads, if not "".
+type fakePayloadSpans struct {
+	docs, positions []int
+	payloads        []string
+	i               int
+	readPayload     bool
+}
+
+func (s *fakePayloadSpans) Next() bool {
+	s.i++
+	s.readPayload = false
+	return s.i < len(s.docs)
+}
+
+func (s *fakePayloadSpans) SkipTo(target int) bool {
+	for s.Next() {
+		if s.Doc() >= target {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *fakePayloadSpans) Doc() int {
+	if s.i >= len(s.docs) {
+		return index.NO_MORE_DOCS
+	}
+	return s.docs[s.i]
+}
+
+func (s *fakePayloadSpans) Start() int { return s.positions[s.i] }
+func (s *fakePayloadSpans) End() int   { return s.positions[s.i] + 1 }
+
+func (s *fakePayloadSpans) Payload() [][]byte {
+	s.readPayload = true
+	return [][]byte{[]byte(s.payloads[s.i])}
+}
+
+func (s *fakePayloadSpans) IsPayloadAvailable() bool {
+	return !s.readPayload && s.payloads[s.i] != ""
+}
+
+func (s *fakePayloadSpans) Cost() int64 {
+	return int64(len(s.docs))
+}
+
+// Scores 1 per document, and the single digit of a payload as its
+// factor.
+type digitPayloadSimScorer struct{}
+
+func (s digitPayloadSimScorer) Score(doc int, freq float32) float32 { return 1 }
+func (s digitPayloadSimScorer) ComputeSlopFactor(distance int) float32 {
+	return 1
+}
+func (s digitPayloadSimScorer) ComputePayloadFactor(doc, start, end int, payload []byte) float32 {
+	return float32(payload[0] - '0')
+}
+func (s digitPayloadSimScorer) Explain(doc int, freq *Explanation) *Explanation { return freq }
+
+func TestPayloadNearSpanScorer(t *testing.T) {
+	for _, v := range []struct {
+		function PayloadFunction
+		scores   map[int]float32
+	}{
+		{AveragePayloadFunction{}, map[int]float32{0: 2.75, 1: 4, 2: 1}},
+		{MaxPayloadFunction{}, map[int]float32{0: 5, 1: 4, 2: 1}},
+		{MinPayloadFunction{}, map[int]float32{0: 1, 1: 4, 2: 1}},
+	} {
+		q := NewPayloadNearQueryWithFunction([]SpanQuery{
+			newContentSpanTermQuery("a"), newContentSpanTermQuery("b")}, 0, true, v.function)
+		spans := newNearSpansOrdered(q.SpanNearQuery, []Spans{
+			&fakePayloadSpans{docs: []int{0, 0, 1, 2}, positions: []int{1, 5, 0, 3},
+				payloads: []string{"2", "3", "4", ""}, i: -1},
+			&fakePayloadSpans{docs: []int{0, 0, 1, 2}, positions: []int{2, 6, 1, 4},
+				payloads: []string{"5", "1", "", ""}, i: -1},
+		}, true)
+		scorer := newPayloadNearSpanScorer(q, spans, nil, digitPayloadSimScorer{})
+		scores := make(map[int]float32)
+		for doc, more := scorer.NextDoc(); more; doc, more = scorer.NextDoc() {
+			scores[doc] = scorer.Score()
+		}
+		assertEquals(t, fmt.Sprint(v.scores), fmt.Sprint(scores))
+	}
+}
+
+func TestPayloadQueriesWithoutPayloads(t *testing.T) {
+	ss, cleanup := newBelfrySearcher(t)
+	defer cleanup()
+
+	// the payload score is 1 when there is no payload
+	bat := index.NewTerm("content", "bat")
+	assertEquals(t, fmt.Sprint(hitScores(t, ss, NewSpanTermQuery(bat))),
+		fmt.Sprint(hitScores(t, ss, NewPayloadTermQuery(bat, MaxPayloadFunction{}))))
+	assertEquals(t, "map[0:1 1:1 2:1 3:1 4:1 5:1 6:1 7:1]",
+		fmt.Sprint(hitScores(t, ss, NewPayloadTermQueryWithSpanScore(bat, MaxPayloadFunction{}, false))))
+
+	clauses := []SpanQuery{newContentSpanTermQuery("fruit"), newContentSpanTermQuery("bat")}
+	assertEquals(t, fmt.Sprint(hitScores(t, ss, NewSpanNearQuery(clauses, 0, true))),
+		fmt.Sprint(hitScores(t, ss, NewPayloadNearQuery(clauses, 0, true))))
+	assertEquals(t, "map[0:2 1:2 2:1]", phraseFreqs(t, ss, NewPayloadNearQuery(clauses, 0, true)))
+
+	q := NewPayloadNearQuery(clauses, 1, false)
+	q.SetBoost(2)
+	assertEquals(t, "payloadNear([fruit, bat], 1, false)^2", q.ToString("content"))
+	assertEquals(t, "content:bat", NewPayloadTermQuery(bat, AveragePayloadFunction{}).ToString(""))
+}
+
+// Scores the single digit of a payload as its factor.
+type digitPayloadSimilarity struct {
+	*DefaultSimilarity
+}
+
+func (s *digitPayloadSimilarity) ScorePayload(doc, start, end int, payload []byte) float32 {
+	return float32(payload[0] - '0')
+}
+
+// Indexes documents whose "body" tokens are given as "term" or
+// "term|payload".
+func newPayloadsSearcher(t *testing.T, bodies ...[]string) (*IndexSearcher, func()) {
+	docs := make([][]document.IndexableField, len(bodies))
+	for i, body := range bodies {
+		tokens := make([]analysistest.Token, len(body))
+		for j, text := range body {
+			parts := splitAlternatives(text)
+			tokens[j] = analysistest.NewToken(parts[0], 1, 1, 2*j, 2*j+1)
+			if len(parts) > 1 {
+				tokens[j].Payload = []byte(parts[1])
+			}
+		}
+		docs[i] = []document.IndexableField{document.NewTextFieldFromTokenStream("body",
+			analysistest.NewCannedTokenStream(2*len(body), tokens...))}
+	}
+	ss, cleanup := newTestSearcherOfDocs(t, docs...)
+	ss.SetSimilarity(NewTFIDFSimilarity(&digitPayloadSimilarity{NewDefaultSimilarity()}))
+	return ss, cleanup
+}
+
+func TestPayloadQueriesWithPayloads(t *testing.T) {
+	ss, cleanup := newPayloadsSearcher(t,
+		[]string{"a|2", "b|5", "a|3"},
+		[]string{"a|4", "b"},
+		[]string{"b|3", "a|1"},
+		[]string{"a", "b"})
+	defer cleanup()
+
+	for _, v := range []struct {
+		text     string
+		function PayloadFunction
+		expected string
+	}{
+		{"a", MaxPayloadFunction{}, "map[0:3 1:4 2:1 3:1]"},
+		{"a", MinPayloadFunction{}, "map[0:2 1:4 2:1 3:1]"},
+		{"a", AveragePayloadFunction{}, "map[0:2.5 1:4 2:1 3:1]"},
+		// docs without payloads score 1
+		{"b", MaxPayloadFunction{}, "map[0:5 1:1 2:3 3:1]"},
+	} {
+		q := NewPayloadTermQueryWithSpanScore(index.NewTerm("body", v.text), v.function, false)
+		assertEquals(t, v.expected, fmt.Sprint(hitScores(t, ss, q)))
+	}
+
+	// the payload score multiplies the span score
+	factors := func(q, spanQuery Query) string {
+		scores, spanScores := hitScores(t, ss, q), hitScores(t, ss, spanQuery)
+		ans := make(map[int]string)
+		for doc, score := range scores {
+			ans[doc] = fmt.Sprintf("%.2f", score/spanScores[doc])
+		}
+		return fmt.Sprint(ans)
+	}
+	a := NewSpanTermQuery(index.NewTerm("body", "a"))
+	assertEquals(t, "map[0:2.50 1:4.00 2:1.00 3:1.00]", factors(
+		NewPayloadTermQuery(index.NewTerm("body", "a"), AveragePayloadFunction{}), a))
+
+	clauses := []SpanQuery{a, NewSpanTermQuery(index.NewTerm("body", "b"))}
+	for _, v := range []struct {
+		function PayloadFunction
+		expected string
+	}{
+		{MaxPayloadFunction{}, "map[0:5.00 1:4.00 3:1.00]"},
+		{MinPayloadFunction{}, "map[0:2.00 1:4.00 3:1.00]"},
+	} {
+		assertEquals(t, v.expected, factors(NewPayloadNearQueryWithFunction(clauses, 0, true, v.function),
+			NewSpanNearQuery(clauses, 0, true)))
+	}
+	assertEquals(t, "map[0:5.00 1:4.00 2:3.00 3:1.00]", factors(
+		NewPayloadNearQueryWithFunction(clauses, 0, false, MaxPayloadFunction{}),
+		NewSpanNearQuery(clauses, 0, false)))
+}
@@ -0,0 +1,372 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"math/bits"
+	"sort"
+)
+
+// util/PForDeltaDocIdSet.java
+
+/*
+DocIdSet implementation based on pfor-delta encoding. This
+implementation is inspired from LinkedIn's Kamikaze
+(http://data.linkedin.com/opensource/kamikaze) and Daniel Lemire's
+JavaFastPFOR (https://github.com/lemire/JavaFastPFOR).
+
+On the contrary to the original PFOR paper, exceptions are encoded
+with FOR instead of Simple16.
+
+The docs are encoded by blocks of PFOR_BLOCK_SIZE deltas, only the
+last block may be smaller. Each block starts with a token byte:
+
+  - if its highest bit is set, the block is unary-encoded: a VInt
+    gives the number of bytes of a bitmap of the docs, relative to
+    the last doc of the previous block.
+  - otherwise its lowest 6 bits are the number of bits per value
+    (bpv) of the deltas, minus one, followed by a byte for the number
+    of exceptions, the deltas packed on bpv bits, and the exceptions,
+    i.e. the deltas which don't fit in bpv bits, as their index in
+    the block and a VInt of their high bits.
+
+The encoding which takes the fewer bytes is chosen for each block.
+Every PFOR_DEFAULT_INDEX_INTERVAL blocks, the last doc of the previous
+block and the position of the block are indexed, so Advance() can
+jump over the blocks before its target.
+*/
+type PForDeltaDocIdSet struct {
+	data        []byte
+	cardinality int
+	// the docs before, and the positions of, the indexed blocks
+	indexDocs, indexPositions []int
+	indexInterval             int
+}
+
+const (
+	PFOR_BLOCK_SIZE = 128
+	// The default number of blocks between two indexed ones.
+	PFOR_DEFAULT_INDEX_INTERVAL = 2
+)
+
+// The empty set.
+var pforEmpty = &PForDeltaDocIdSet{indexInterval: PFOR_DEFAULT_INDEX_INTERVAL}
+
+func (s *PForDeltaDocIdSet) Iterator() (index.DocIdSetIterator, error) {
+	return &pforIterator{set: s, doc: -1, prevDoc: -1}, nil
+}
+
+// This set doesn't support random access.
+func (s *PForDeltaDocIdSet) Bits() util.Bits {
+	return nil
+}
+
+// Returns the number of documents in this set.
+func (s *PForDeltaDocIdSet) Cardinality() int {
+	return s.cardinality
+}
+
+// Returns the approximate number of bytes of the encoded set.
+func (s *PForDeltaDocIdSet) SizeInBytes() int64 {
+	return int64(len(s.data) + 8*(len(s.indexDocs)+len(s.indexPositions)))
+}
+
+func (s *PForDeltaDocIdSet) String() string {
+	return fmt.Sprintf("PForDeltaDocIdSet(cardinality=%v, bytes=%v)", s.cardinality, s.SizeInBytes())
+}
+
+// util/PForDeltaDocIdSet.java/Builder
+
+/*
+A builder for PForDeltaDocIdSets, to which the docs are added in
+increasing order.
+*/
+type PForDeltaDocIdSetBuilder struct {
+	out           []byte
+	indexInterval int
+	numBlocks     int
+	// the deltas of the pending block
+	deltas      []int
+	prevDoc     int // the last doc of the previous block
+	lastDocID   int
+	cardinality int
+
+	indexDocs, indexPositions []int
+}
+
+func NewPForDeltaDocIdSetBuilder() *PForDeltaDocIdSetBuilder {
+	return &PForDeltaDocIdSetBuilder{
+		indexInterval: PFOR_DEFAULT_INDEX_INTERVAL,
+		deltas:        make([]int, 0, PFOR_BLOCK_SIZE),
+		prevDoc:       -1,
+		lastDocID:     -1,
+	}
+}
+
+/*
+Sets the number of blocks between two indexed ones. Lower values make
+Advance() faster, but the set larger.
+*/
+func (b *PForDeltaDocIdSetBuilder) SetIndexInterval(indexInterval int) *PForDeltaDocIdSetBuilder {
+	if indexInterval < 1 {
+		panic("indexInterval must be >= 1")
+	}
+	b.indexInterval = indexInterval
+	return b
+}
+
+// Adds a document to the set. The documents must be added in
+// increasing order.
+func (b *PForDeltaDocIdSetBuilder) Add(docID int) *PForDeltaDocIdSetBuilder {
+	if docID <= b.lastDocID {
+		panic(fmt.Sprintf("docs must be added in increasing order, got %v after %v", docID, b.lastDocID))
+	}
+	b.deltas = append(b.deltas, docID-b.lastDocID-1)
+	b.lastDocID = docID
+	b.cardinality++
+	if len(b.deltas) == PFOR_BLOCK_SIZE {
+		b.encodeBlock()
+	}
+	return b
+}
+
+// Adds the documents of the iterator, which must be beyond the ones
+// added already.
+func (b *PForDeltaDocIdSetBuilder) AddIterator(it index.DocIdSetIterator) *PForDeltaDocIdSetBuilder {
+	for doc, more := it.NextDoc(); more; doc, more = it.NextDoc() {
+		b.Add(doc)
+	}
+	return b
+}
+
+// Encodes the pending deltas as a block, with the smaller of the
+// unary and PFOR encodings.
+func (b *PForDeltaDocIdSetBuilder) encodeBlock() {
+	if b.numBlocks%b.indexInterval == 0 {
+		b.indexDocs = append(b.indexDocs, b.prevDoc)
+		b.indexPositions = append(b.indexPositions, len(b.out))
+	}
+	b.numBlocks++
+
+	bpv, pforSize := pforBestBitsPerValue(b.deltas)
+	unaryBytes := (b.lastDocID - b.prevDoc + 7) >> 3
+	if unarySize := 1 + vIntSize(unaryBytes) + unaryBytes; unarySize <= pforSize {
+		b.out = append(b.out, 0x80)
+		b.out = appendVInt(b.out, unaryBytes)
+		bitmap := make([]byte, unaryBytes)
+		for doc, i := b.prevDoc, 0; i < len(b.deltas); i++ {
+			doc += b.deltas[i] + 1
+			bit := doc - b.prevDoc - 1
+			bitmap[bit>>3] |= 1 << uint(bit&7)
+		}
+		b.out = append(b.out, bitmap...)
+	} else {
+		var exceptions []int
+		for i, delta := range b.deltas {
+			if delta>>uint(bpv) != 0 {
+				exceptions = append(exceptions, i)
+			}
+		}
+		b.out = append(b.out, byte(bpv-1), byte(len(exceptions)))
+		b.out = packBits(b.out, b.deltas, bpv)
+		for _, i := range exceptions {
+			b.out = append(b.out, byte(i))
+			b.out = appendVInt(b.out, b.deltas[i]>>uint(bpv))
+		}
+	}
+
+	b.prevDoc = b.lastDocID
+	b.deltas = b.deltas[:0]
+}
+
+/*
+Returns the number of bits per value, at least 1, which makes the
+PFOR encoding of the given values the smallest, and the number of
+bytes of this encoding.
+*/
+func pforBestBitsPerValue(values []int) (int, int) {
+	bestBpv, bestSize := 0, -1
+	for bpv := 1; bpv <= 32; bpv++ {
+		size := 2 + (len(values)*bpv+7)>>3
+		for _, v := range values {
+			if high := v >> uint(bpv); high != 0 {
+				size += 1 + vIntSize(high)
+			}
+		}
+		if bestSize < 0 || size < bestSize {
+			bestBpv, bestSize = bpv, size
+		}
+	}
+	return bestBpv, bestSize
+}
+
+// Builds the set of the added documents. The builder must not be
+// used anymore.
+func (b *PForDeltaDocIdSetBuilder) Build() *PForDeltaDocIdSet {
+	if len(b.deltas) > 0 {
+		b.encodeBlock()
+	}
+	if b.cardinality == 0 {
+		return pforEmpty
+	}
+	return &PForDeltaDocIdSet{
+		data:           b.out,
+		cardinality:    b.cardinality,
+		indexDocs:      b.indexDocs,
+		indexPositions: b.indexPositions,
+		indexInterval:  b.indexInterval,
+	}
+}
+
+// util/PForDeltaDocIdSet.java/Iterator
+
+type pforIterator struct {
+	set      *PForDeltaDocIdSet
+	pos      int
+	blockNum int   // the number of the next block to decode
+	docs     []int // the docs of the decoded block
+	i        int   // the index of the next doc in docs
+	prevDoc  int   // the last doc of the decoded block
+	deltas   []int
+	doc      int
+}
+
+// Decodes the block at pos, returning false if there is none.
+func (it *pforIterator) decodeBlock() bool {
+	data := it.set.data
+	if it.pos >= len(data) {
+		return false
+	}
+	n := it.set.cardinality - it.blockNum*PFOR_BLOCK_SIZE
+	if n > PFOR_BLOCK_SIZE {
+		n = PFOR_BLOCK_SIZE
+	}
+	it.blockNum++
+	it.docs, it.i = it.docs[:0], 0
+
+	token := data[it.pos]
+	it.pos++
+	if token&0x80 != 0 {
+		var numBytes int
+		numBytes, it.pos = readVInt(data, it.pos)
+		for i, word := range data[it.pos : it.pos+numBytes] {
+			for ; word != 0; word &= word - 1 {
+				it.docs = append(it.docs, it.prevDoc+1+i<<3+bits.TrailingZeros8(word))
+			}
+		}
+		it.pos += numBytes
+	} else {
+		bpv, numExceptions := int(token&0x3F)+1, int(data[it.pos])
+		it.pos++
+		it.deltas, it.pos = unpackBits(it.deltas[:0], data, it.pos, n, bpv)
+		for j := 0; j < numExceptions; j++ {
+			i := int(data[it.pos])
+			var high int
+			high, it.pos = readVInt(data, it.pos+1)
+			it.deltas[i] |= high << uint(bpv)
+		}
+		doc := it.prevDoc
+		for _, delta := range it.deltas {
+			doc += delta + 1
+			it.docs = append(it.docs, doc)
+		}
+	}
+	it.prevDoc = it.docs[len(it.docs)-1]
+	return true
+}
+
+func (it *pforIterator) DocId() int {
+	return it.doc
+}
+
+func (it *pforIterator) Freq() int {
+	return 1
+}
+
+func (it *pforIterator) NextDoc() (int, bool) {
+	if it.i == len(it.docs) && (it.doc == index.NO_MORE_DOCS || !it.decodeBlock()) {
+		it.doc = index.NO_MORE_DOCS
+		return it.doc, false
+	}
+	it.doc = it.docs[it.i]
+	it.i++
+	return it.doc, true
+}
+
+func (it *pforIterator) Advance(target int) (int, bool) {
+	if it.doc == index.NO_MORE_DOCS {
+		return it.doc, false
+	}
+	if it.i == len(it.docs) || it.prevDoc < target {
+		// jump to the last indexed block before the target, if it is
+		// ahead
+		docs := it.set.indexDocs
+		if k := sort.SearchInts(docs, target) - 1; k >= 0 && k*it.set.indexInterval > it.blockNum {
+			it.pos = it.set.indexPositions[k]
+			it.blockNum = k * it.set.indexInterval
+			it.prevDoc = docs[k]
+			it.docs, it.i = it.docs[:0], 0
+		}
+		// skip the blocks before the target
+		for it.prevDoc < target {
+			if !it.decodeBlock() {
+				it.doc = index.NO_MORE_DOCS
+				return it.doc, false
+			}
+		}
+	}
+	for doc, more := it.NextDoc(); more; doc, more = it.NextDoc() {
+		if doc >= target {
+			return doc, true
+		}
+	}
+	return it.doc, false
+}
+
+func (it *pforIterator) Cost() int64 {
+	return int64(it.set.cardinality)
+}
+
+// Appends the values packed on bpv bits, lowest bits first.
+func packBits(out []byte, values []int, bpv int) []byte {
+	var pending uint64
+	var pendingBits uint
+	for _, v := range values {
+		pending |= uint64(v) & (1<<uint(bpv) - 1) << pendingBits
+		for pendingBits += uint(bpv); pendingBits >= 8; pendingBits -= 8 {
+			out = append(out, byte(pending))
+			pending >>= 8
+		}
+	}
+	if pendingBits > 0 {
+		out = append(out, byte(pending))
+	}
+	return out
+}
+
+// Appends n values packed on bpv bits at pos, returning the values
+// and the position beyond them.
+func unpackBits(values []int, data []byte, pos, n, bpv int) ([]int, int) {
+	var pending uint64
+	var pendingBits uint
+	for i := 0; i < n; i++ {
+		for ; pendingBits < uint(bpv); pendingBits += 8 {
+			pending |= uint64(data[pos]) << pendingBits
+			pos++
+		}
+		values = append(values, int(pending&(1<<uint(bpv)-1)))
+		pending >>= uint(bpv)
+		pendingBits -= uint(bpv)
+	}
+	return values, pos
+}
+
+// Returns the number of bytes of i as a VInt.
+func vIntSize(i int) int {
+	size := 1
+	for ; i >= 0x80; i >>= 7 {
+		size++
+	}
+	return size
+}
@@ -0,0 +1,33 @@
+package search
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPForDeltaDocIdSet(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	for _, docs := range randomDocSets(rnd, 50000) {
+		for _, indexInterval := range []int{1, 3, PFOR_DEFAULT_INDEX_INTERVAL} {
+			b := NewPForDeltaDocIdSetBuilder().SetIndexInterval(indexInterval)
+			for _, doc := range docs {
+				b.Add(doc)
+			}
+			set := b.Build()
+			assertEquals(t, len(docs), set.Cardinality())
+			assertDocIdSetDocs(t, rnd, docs, set)
+		}
+	}
+}
+
+func TestPForDeltaDocIdSetCompression(t *testing.T) {
+	maxDoc := 1 << 20
+	b := NewPForDeltaDocIdSetBuilder()
+	for doc := 0; doc < maxDoc; doc += 1000 {
+		b.Add(doc)
+	}
+	// the deltas fit in 10 bits
+	if set := b.Build(); set.SizeInBytes() > int64(set.Cardinality()*10/8*11/10+100) {
+		t.Errorf("Expected %v to take about 10 bits per doc", set)
+	}
+}
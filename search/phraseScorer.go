@@ -0,0 +1,347 @@
+package search
+
+import (
+	"container/heap"
+	"github.com/balzaczyy/golucene/index"
+	"math"
+)
+
+// search/PhrasePositions.java
+
+/*
+Position of a term in a document that takes into account the term
+offset within the phrase.
+*/
+type phrasePositions struct {
+	doc      int // current doc
+	position int // position in doc
+	count    int // remaining pos in this doc
+	offset   int // position in phrase
+	ord      int // unique across all phrasePositions instances
+	postings index.DocsAndPositionsEnum
+	next     *phrasePositions // used to make lists
+	repeats  bool             // there's other pp for same term (e.g. query="1st word 2nd word"~1)
+	terms    []index.Term     // for repetitions initialization
+}
+
+func newPhrasePositions(postings index.DocsAndPositionsEnum, offset, ord int, terms []index.Term) *phrasePositions {
+	return &phrasePositions{
+		doc:      -1,
+		offset:   offset,
+		ord:      ord,
+		postings: postings,
+		terms:    terms,
+	}
+}
+
+// increments to next doc
+func (pp *phrasePositions) nextDoc() bool {
+	pp.doc, _ = pp.postings.NextDoc()
+	return pp.doc != index.NO_MORE_DOCS
+}
+
+func (pp *phrasePositions) skipTo(target int) bool {
+	pp.doc, _ = pp.postings.Advance(target)
+	return pp.doc != index.NO_MORE_DOCS
+}
+
+func (pp *phrasePositions) firstPosition() {
+	pp.count = pp.postings.Freq() // read first pos
+	pp.nextPosition()
+}
+
+/*
+Go to next location of this term current document, and set position
+as location - offset, so that a matching exact phrase is easily
+identified when all phrasePositions have exactly the same position.
+*/
+func (pp *phrasePositions) nextPosition() bool {
+	if pp.count > 0 { // read subsequent pos's
+		pp.count--
+		pp.position = pp.postings.NextPosition() - pp.offset
+		return true
+	}
+	return false
+}
+
+// search/PhraseQueue.java
+
+type phraseQueue []*phrasePositions
+
+func (pq phraseQueue) Len() int { return len(pq) }
+
+func (pq phraseQueue) Less(i, j int) bool {
+	pp1, pp2 := pq[i], pq[j]
+	if pp1.doc == pp2.doc {
+		if pp1.position == pp2.position {
+			// same doc and pp.position, so decide by actual term
+			// positions. rely on: pp.position == tp.position - offset.
+			if pp1.offset == pp2.offset {
+				return pp1.ord < pp2.ord
+			}
+			return pp1.offset < pp2.offset
+		}
+		return pp1.position < pp2.position
+	}
+	return pp1.doc < pp2.doc
+}
+
+func (pq phraseQueue) Swap(i, j int) { pq[i], pq[j] = pq[j], pq[i] }
+
+func (pq *phraseQueue) Push(x interface{}) { *pq = append(*pq, x.(*phrasePositions)) }
+
+func (pq *phraseQueue) Pop() interface{} {
+	n := len(*pq)
+	ans := (*pq)[n-1]
+	*pq = (*pq)[:n-1]
+	return ans
+}
+
+// search/PhraseQuery.java/PostingsAndFreq
+
+// The postings of the terms at a position of a phrase.
+type postingsAndFreq struct {
+	postings index.DocsAndPositionsEnum
+	docFreq  int
+	position int
+	terms    []index.Term
+}
+
+// Sorts by increasing docFreq, then position, so the rarest terms
+// lead the matching.
+type postingsAndFreqs []*postingsAndFreq
+
+func (s postingsAndFreqs) Len() int      { return len(s) }
+func (s postingsAndFreqs) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s postingsAndFreqs) Less(i, j int) bool {
+	if s[i].docFreq != s[j].docFreq {
+		return s[i].docFreq < s[j].docFreq
+	}
+	return s[i].position < s[j].position
+}
+
+// search/PhraseScorer.java
+
+// The hook of PhraseScorer, which the concrete phrase scorers which
+// embed it implement.
+type phraseScorerSPI interface {
+	/*
+		For a document containing all the phrase query terms, compute
+		the frequency of the phrase in that document, and the number of
+		matches of the phrase. A non zero frequency means a match.
+	*/
+	phraseFreq() (freq float32, matches int)
+}
+
+/*
+Scoring functionality for phrase queries. A document is considered
+matching if it contains the phrase-query terms at "valid" positions.
+What "valid positions" are depends on the type of the phrase query:
+for an exact phrase query terms are required to appear in adjacent
+locations, while for a sloppy phrase query some distance between the
+terms is allowed. The phrase scorers which embed PhraseScorer define
+this via their phraseFreq() hook.
+*/
+type PhraseScorer struct {
+	*ScorerImpl
+	spi       phraseScorerSPI
+	firstTime bool
+	more      bool
+	pq        *phraseQueue
+	first     *phrasePositions
+	last      *phrasePositions
+	freq      float32 // phrase frequency in current doc as computed by phraseFreq().
+	matches   int     // number of matches in current doc
+}
+
+/*
+Converts the postings to a list of phrase positions. Note that
+phrase-position differs from term-position in that its position
+reflects the phrase offset: pp.pos = tp.pos - offset. This allows to
+easily identify a matching (exact) phrase when all phrasePositions
+have exactly the same position.
+*/
+func newPhraseScorer(self Scorer, spi phraseScorerSPI, w Weight, postings []*postingsAndFreq) *PhraseScorer {
+	ans := &PhraseScorer{
+		ScorerImpl: NewScorer(self, w),
+		spi:        spi,
+		firstTime:  true,
+		more:       true,
+	}
+	for i, p := range postings {
+		pp := newPhrasePositions(p.postings, p.position, i, p.terms)
+		if ans.last != nil { // add next to end of list
+			ans.last.next = pp
+		} else {
+			ans.first = pp
+		}
+		ans.last = pp
+	}
+	pq := make(phraseQueue, 0, len(postings))
+	ans.pq = &pq
+	return ans
+}
+
+func (s *PhraseScorer) DocId() int {
+	return s.first.doc
+}
+
+// A match needs all the terms, so the rarest one bounds the cost.
+func (s *PhraseScorer) Cost() int64 {
+	minCost := int64(math.MaxInt64)
+	for pp := s.first; pp != nil; pp = pp.next {
+		if cost := pp.postings.Cost(); cost < minCost {
+			minCost = cost
+		}
+	}
+	return minCost
+}
+
+func (s *PhraseScorer) NextDoc() (int, bool) {
+	if s.firstTime {
+		s.init()
+		s.firstTime = false
+	} else if s.more {
+		s.more = s.last.nextDoc() // trigger further scanning
+	}
+	if !s.doNext() {
+		s.first.doc = index.NO_MORE_DOCS
+	}
+	return s.first.doc, s.first.doc != index.NO_MORE_DOCS
+}
+
+// next without initial increment
+func (s *PhraseScorer) doNext() bool {
+	for s.more {
+		for s.more && s.first.doc < s.last.doc { // find doc w/ all the terms
+			s.more = s.first.skipTo(s.last.doc) // skip first upto last
+			s.firstToLast()                     // and move it to the end
+		}
+		if s.more {
+			// found a doc with all of the terms
+			s.freq, s.matches = s.spi.phraseFreq() // check for phrase
+			if s.freq != 0 {
+				return true // found a match
+			}
+			s.more = s.last.nextDoc() // trigger further scanning
+		}
+	}
+	return false // no more matches
+}
+
+func (s *PhraseScorer) Advance(target int) (int, bool) {
+	s.firstTime = false
+	for pp := s.first; s.more && pp != nil; pp = pp.next {
+		s.more = pp.skipTo(target)
+	}
+	if s.more {
+		s.sort() // re-sort
+	}
+	if !s.doNext() {
+		s.first.doc = index.NO_MORE_DOCS
+	}
+	return s.first.doc, s.first.doc != index.NO_MORE_DOCS
+}
+
+// Returns the number of matches of the phrase in the current
+// document.
+func (s *PhraseScorer) Freq() int {
+	return s.matches
+}
+
+// Returns the phrase frequency of the current document, as computed
+// by phraseFreq().
+func (s *PhraseScorer) sloppyFreq() float32 {
+	return s.freq
+}
+
+func (s *PhraseScorer) init() {
+	for pp := s.first; s.more && pp != nil; pp = pp.next {
+		s.more = pp.nextDoc()
+	}
+	if s.more {
+		s.sort()
+	}
+}
+
+func (s *PhraseScorer) sort() {
+	*s.pq = (*s.pq)[:0]
+	for pp := s.first; pp != nil; pp = pp.next {
+		heap.Push(s.pq, pp)
+	}
+	s.pqToList()
+}
+
+// Rebuilds the list of phrasePositions from the queue, in order.
+func (s *PhraseScorer) pqToList() {
+	s.first, s.last = nil, nil
+	for s.pq.Len() > 0 {
+		pp := heap.Pop(s.pq).(*phrasePositions)
+		if s.last != nil { // add next to end of list
+			s.last.next = pp
+		} else {
+			s.first = pp
+		}
+		s.last = pp
+		pp.next = nil
+	}
+}
+
+// Moves the first phrasePositions of the list to its end.
+func (s *PhraseScorer) firstToLast() {
+	s.last.next = s.first // move first to end of list
+	s.last = s.first
+	s.first = s.first.next
+	s.last.next = nil
+}
+
+// search/ExactPhraseScorer.java
+
+// Scorer for phrase queries whose terms must appear in adjacent
+// locations.
+type ExactPhraseScorer struct {
+	*PhraseScorer
+	docScorer ExactSimScorer
+}
+
+func newExactPhraseScorer(w Weight, postings []*postingsAndFreq, docScorer ExactSimScorer) *ExactPhraseScorer {
+	ans := &ExactPhraseScorer{docScorer: docScorer}
+	ans.PhraseScorer = newPhraseScorer(ans, ans, w, postings)
+	return ans
+}
+
+func (s *ExactPhraseScorer) phraseFreq() (float32, int) {
+	// sort list with pq
+	*s.pq = (*s.pq)[:0]
+	for pp := s.first; pp != nil; pp = pp.next {
+		pp.firstPosition()
+		heap.Push(s.pq, pp) // build pq from list
+	}
+	s.pqToList() // rebuild list from pq
+
+	// for counting how many times the exact phrase is found in current
+	// document, just count how many times all phrasePositions have
+	// exactly the same position.
+	freq := 0
+	for {
+		for s.first.position < s.last.position { // find position w/ all terms
+			for { // scan forward in first
+				if !s.first.nextPosition() {
+					return float32(freq), freq
+				}
+				if s.first.position >= s.last.position {
+					break
+				}
+			}
+			s.firstToLast()
+		}
+		freq++ // all equal: a match
+		if !s.last.nextPosition() {
+			return float32(freq), freq
+		}
+	}
+}
+
+func (s *ExactPhraseScorer) Score() float32 {
+	return s.docScorer.Score(s.first.doc, s.matches)
+}
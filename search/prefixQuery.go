@@ -0,0 +1,71 @@
+package search
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util/automaton"
+)
+
+// search/PrefixQuery.java
+
+/*
+A Query that matches documents containing terms with a specified
+prefix. A PrefixQuery is built by QueryParser for input like app*.
+
+This query uses the CONSTANT_SCORE_AUTO_REWRITE_DEFAULT rewrite method by
+default.
+*/
+type PrefixQuery struct {
+	*AutomatonQuery
+	prefix index.Term
+}
+
+// Constructs a query for terms starting with prefix.
+func NewPrefixQuery(prefix index.Term) *PrefixQuery {
+	ans := &PrefixQuery{prefix: prefix}
+	ans.AutomatonQuery = newAutomatonQuery(ans, prefix, automaton.Concatenate(
+		automaton.MakeString(string(prefix.Bytes)), automaton.MakeAnyString()))
+	return ans
+}
+
+// Returns the prefix of this query.
+func (q *PrefixQuery) Prefix() index.Term {
+	return q.prefix
+}
+
+// Prints a user-readable version of this query.
+func (q *PrefixQuery) ToString(field string) string {
+	s := string(q.prefix.Bytes) + "*" + boostString(q.boost)
+	if q.Field() != field {
+		s = q.Field() + ":" + s
+	}
+	return s
+}
+
+// search/PrefixTermsEnum.java
+
+/*
+Subclass of FilteredTermsEnum for enumerating all terms that match
+the specified prefix filter term.
+
+Term enumerations are always ordered by Comparator(). Each term in
+the enumeration is greater than all that precede it.
+*/
+type PrefixTermsEnum struct {
+	*index.FilteredTermsEnumImpl
+	prefixRef []byte
+}
+
+func NewPrefixTermsEnum(tenum index.TermsEnum, prefixText []byte) *PrefixTermsEnum {
+	ans := &PrefixTermsEnum{prefixRef: prefixText}
+	ans.FilteredTermsEnumImpl = index.NewFilteredTermsEnum(ans, tenum, true)
+	ans.SetInitialSeekTerm(prefixText)
+	return ans
+}
+
+func (e *PrefixTermsEnum) Accept(term []byte) index.AcceptStatus {
+	if bytes.HasPrefix(term, e.prefixRef) {
+		return index.ACCEPT_STATUS_YES
+	}
+	return index.ACCEPT_STATUS_END
+}
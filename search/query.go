@@ -1,17 +1,70 @@
 package search
 
 import (
+	"errors"
 	"fmt"
 	"github.com/balzaczyy/golucene/index"
 )
 
+// search/Query.java
+
+/*
+The abstract base class for queries.
+
+Instantiable subclasses are:
+
+  - TermQuery
+  - BooleanQuery
+  - FilteredQuery
+  - MultiPhraseQuery
+  - PrefixQuery
+  - WildcardQuery
+  - RegexpQuery
+  - AutomatonQuery
+  - FuzzyQuery
+  - TermRangeQuery
+  - NumericRangeQuery
+  - ConstantScoreQuery
+  - MatchAllDocsQuery
+
+A query is first rewritten by the IndexSearcher into primitive
+queries, which then create the Weight used to score the documents of
+each segment.
+*/
 type Query interface {
-	CreateWeight(ss IndexSearcher) (w Weight, err error)
-	Rewrite(r index.IndexReader) Query
+	/*
+		Sets the boost for this query clause to b. Documents matching
+		this clause will (in addition to the normal weightings) have
+		their score multiplied by b.
+	*/
+	SetBoost(b float32)
+	// Gets the boost for this clause. Documents matching this clause
+	// will (in addition to the normal weightings) have their score
+	// multiplied by b. The boost is 1.0 by default.
+	Boost() float32
+	/*
+		Prints a query to a string, with field assumed to be the default
+		field and omitted.
+	*/
+	ToString(field string) string
+	/*
+		Expert: Constructs an appropriate Weight implementation for this
+		query. Only implemented by primitive queries, which re-write to
+		themselves.
+	*/
+	CreateWeight(ss *IndexSearcher) (Weight, error)
+	/*
+		Expert: called to re-write queries into primitive queries. For
+		example, a PrefixQuery will be rewritten into a BooleanQuery that
+		consists of TermQuerys.
+	*/
+	Rewrite(r index.IndexReader) (Query, error)
 }
 
+// Embeddable implementation of Query, which holds the boost, and
+// re-writes to itself.
 type AbstractQuery struct {
-	Query
+	self  Query
 	boost float32
 }
 
@@ -19,10 +72,32 @@ func NewAbstractQuery(self Query) *AbstractQuery {
 	return &AbstractQuery{self, 1.0}
 }
 
-func (q *AbstractQuery) CreateWeight(ss IndexSearcher) (w Weight, err error) {
-	panic(fmt.Sprintf("Query %v does not implement createWeight", q))
+func (q *AbstractQuery) SetBoost(b float32) {
+	q.boost = b
+}
+
+func (q *AbstractQuery) Boost() float32 {
+	return q.boost
+}
+
+func (q *AbstractQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	return nil, errors.New(fmt.Sprintf("Query %v does not implement createWeight", q.self))
+}
+
+func (q *AbstractQuery) Rewrite(r index.IndexReader) (Query, error) {
+	return q.self, nil
+}
+
+// Prints a query to a string.
+func (q *AbstractQuery) String() string {
+	return q.self.ToString("")
 }
 
-func (q *AbstractQuery) Rewrite(r index.IndexReader) Query {
-	return q.Query
+// Returns the "^boost" suffix of the string form of a query, or ""
+// if boost is the default 1.0.
+func boostString(boost float32) string {
+	if boost == 1.0 {
+		return ""
+	}
+	return fmt.Sprintf("^%v", boost)
 }
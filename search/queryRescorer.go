@@ -0,0 +1,174 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"math"
+	"sort"
+)
+
+// search/QueryRescorer.java
+
+/*
+A Rescorer that uses a provided Query to assign scores to the
+first-pass hits.
+*/
+type QueryRescorer struct {
+	query Query
+	/*
+		Implement this to return the combined score from the first and
+		second pass scores. Note that if the second pass query failed to
+		match a document, secondPassMatches is false and the
+		secondPassScore is undefined; you should likely just return the
+		firstPassScore in this case.
+	*/
+	combine func(firstPassScore float32, secondPassMatches bool, secondPassScore float32) float32
+}
+
+/*
+Sole constructor, passing the 2nd pass query to assign scores to the
+1st pass hits, and the function to combine both scores.
+*/
+func NewQueryRescorer(query Query,
+	combine func(firstPassScore float32, secondPassMatches bool, secondPassScore float32) float32) *QueryRescorer {
+	return &QueryRescorer{query, combine}
+}
+
+func (r *QueryRescorer) Rescore(searcher *IndexSearcher, firstPassTopDocs TopDocs, topN int) (TopDocs, error) {
+	// copy the hits, so that the first pass ones are left untouched
+	hits := make([]*ScoreDoc, len(firstPassTopDocs.ScoreDocs))
+	for i, hit := range firstPassTopDocs.ScoreDocs {
+		copied := *hit
+		hits[i] = &copied
+	}
+	sort.Sort(scoreDocsByDoc(hits))
+
+	leaves := searcher.leafContexts
+	weight, err := searcher.CreateNormalizedWeight(r.query)
+	if err != nil {
+		return TopDocs{}, err
+	}
+
+	// Now merge sort docIDs from hits, with reader's leaves:
+	readerUpto := -1
+	endDoc := 0
+	docBase := 0
+	var scorer Scorer
+	for _, hit := range hits {
+		docID := hit.Doc
+		var readerContext *index.AtomicReaderContext
+		for docID >= endDoc {
+			readerUpto++
+			readerContext = &leaves[readerUpto]
+			endDoc = readerContext.DocBase + readerContext.Reader().MaxDoc()
+		}
+
+		if readerContext != nil {
+			// We advanced to another segment:
+			docBase = readerContext.DocBase
+			if scorer, err = weight.Scorer(*readerContext, true, false,
+				readerContext.Reader().(index.AtomicReader).LiveDocs()); err != nil {
+				return TopDocs{}, err
+			}
+		}
+
+		if scorer != nil {
+			targetDoc := docID - docBase
+			actualDoc := scorer.DocId()
+			if actualDoc < targetDoc {
+				actualDoc, _ = scorer.Advance(targetDoc)
+			}
+
+			if actualDoc == targetDoc {
+				// Query did match this doc:
+				hit.Score = r.combine(hit.Score, true, scorer.Score())
+			} else {
+				// Query did not match this doc:
+				// assert actualDoc > targetDoc
+				hit.Score = r.combine(hit.Score, false, 0)
+			}
+		} else {
+			// Query did not match this doc:
+			hit.Score = r.combine(hit.Score, false, 0)
+		}
+	}
+
+	// TODO: we should do a partial sort (of only topN) instead, but
+	// typically the number of hits is smallish:
+	sort.Sort(scoreDocsByScore(hits))
+
+	if topN < len(hits) {
+		hits = hits[:topN]
+	}
+
+	maxScore := float32(math.NaN())
+	if len(hits) > 0 {
+		maxScore = hits[0].Score
+	}
+	return TopDocs{firstPassTopDocs.TotalHits, hits, maxScore}, nil
+}
+
+func (r *QueryRescorer) Explain(searcher *IndexSearcher, firstPassExplanation *Explanation,
+	docID int) (*Explanation, error) {
+	secondPassExplanation, err := searcher.Explain(r.query, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	secondPassMatches := secondPassExplanation.IsMatch()
+	var score float32
+	if secondPassMatches {
+		score = r.combine(firstPassExplanation.Value(), true, secondPassExplanation.Value())
+	} else {
+		score = r.combine(firstPassExplanation.Value(), false, 0)
+	}
+
+	result := NewExplanation(score, "combined first and second pass score using QueryRescorer")
+
+	first := NewExplanation(firstPassExplanation.Value(), "first pass score")
+	first.AddDetail(firstPassExplanation)
+	result.AddDetail(first)
+
+	var second *Explanation
+	if secondPassMatches {
+		second = NewExplanation(secondPassExplanation.Value(), "second pass score")
+	} else {
+		second = NewExplanation(0, "no second pass score")
+	}
+	second.AddDetail(secondPassExplanation)
+	result.AddDetail(second)
+
+	return result, nil
+}
+
+/*
+Sugar API, calling QueryRescorer.Rescore() using a simple linear
+combination of firstPassScore + weight * secondPassScore.
+*/
+func RescoreWithQuery(searcher *IndexSearcher, topDocs TopDocs, query Query,
+	weight float64, topN int) (TopDocs, error) {
+	return NewQueryRescorer(query, func(firstPassScore float32, secondPassMatches bool, secondPassScore float32) float32 {
+		score := firstPassScore
+		if secondPassMatches {
+			score += float32(weight * float64(secondPassScore))
+		}
+		return score
+	}).Rescore(searcher, topDocs, topN)
+}
+
+type scoreDocsByDoc []*ScoreDoc
+
+func (s scoreDocsByDoc) Len() int           { return len(s) }
+func (s scoreDocsByDoc) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s scoreDocsByDoc) Less(i, j int) bool { return s[i].Doc < s[j].Doc }
+
+// Sorts by score descending, then docID ascending.
+type scoreDocsByScore []*ScoreDoc
+
+func (s scoreDocsByScore) Len() int      { return len(s) }
+func (s scoreDocsByScore) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s scoreDocsByScore) Less(i, j int) bool {
+	if s[i].Score != s[j].Score {
+		return s[i].Score > s[j].Score
+	}
+	return s[i].Doc < s[j].Doc
+}
@@ -0,0 +1,74 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestQueryRescorer(t *testing.T) {
+	r, cleanup := newMultiSegmentTestReader(t, 2, "a x", "a b", "a x", "a b", "a x", "x b")
+	defer cleanup()
+	if len(r.Context().Leaves()) < 2 {
+		t.Fatalf("Expected several segments, but %v", len(r.Context().Leaves()))
+	}
+	ss := NewIndexSearcher(r)
+
+	first := newBodyTermQuery("a")
+	firstPass, err := ss.SearchTop(first, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, firstPass, 0, 1, 2, 3, 4)
+	firstScore := firstPass.ScoreDocs[0].Score
+
+	// the documents also matching the second pass query move ahead
+	second := newBodyTermQuery("b")
+	rescored, err := RescoreWithQuery(ss, firstPass, second, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 5, rescored.TotalHits)
+	assertEquals(t, 3, len(rescored.ScoreDocs))
+	assertEquals(t, 1, rescored.ScoreDocs[0].Doc)
+	assertEquals(t, 3, rescored.ScoreDocs[1].Doc)
+	assertEquals(t, 0, rescored.ScoreDocs[2].Doc)
+	assertEquals(t, rescored.ScoreDocs[0].Score, rescored.MaxScore)
+	if rescored.ScoreDocs[0].Score <= firstScore {
+		t.Errorf("Expected rescored score above %v, but %v", firstScore, rescored.ScoreDocs[0].Score)
+	}
+	assertEquals(t, firstScore, rescored.ScoreDocs[2].Score)
+	// the first pass hits are left untouched
+	for _, hit := range firstPass.ScoreDocs {
+		assertEquals(t, firstScore, hit.Score)
+	}
+
+	// the combination of the scores is up to the rescorer
+	rescorer := NewQueryRescorer(second, func(firstPassScore float32, secondPassMatches bool, secondPassScore float32) float32 {
+		if secondPassMatches {
+			return -secondPassScore
+		}
+		return firstPassScore
+	})
+	rescored, err = rescorer.Rescore(ss, firstPass, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, rescored, 0, 2, 4, 1, 3)
+
+	explain := func(doc int) *Explanation {
+		firstPassExplanation, err := ss.Explain(first, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		explanation, err := rescorer.Explain(ss, firstPassExplanation, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return explanation
+	}
+	explanation := explain(1)
+	assertEquals(t, rescored.ScoreDocs[3].Score, explanation.Value())
+	assertEquals(t, "second pass score", explanation.Details()[1].Description())
+	explanation = explain(2)
+	assertEquals(t, firstScore, explanation.Value())
+	assertEquals(t, "no second pass score", explanation.Details()[1].Description())
+}
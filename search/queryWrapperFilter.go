@@ -0,0 +1,70 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/QueryWrapperFilter.java
+
+/*
+Constrains search results to only match those which also match a
+provided query.
+
+This could be used, for example, with a NumericRangeQuery on a
+suitably formatted date field to implement date filtering. One could
+re-use a single CachingWrapperFilter(QueryWrapperFilter) that matches,
+e.g., only documents modified within the last week. This would only
+need to be reconstructed once per day.
+*/
+type QueryWrapperFilter struct {
+	query Query
+}
+
+// Constructs a filter which only matches documents matching query.
+func NewQueryWrapperFilter(query Query) *QueryWrapperFilter {
+	if query == nil {
+		panic("Query may not be null")
+	}
+	return &QueryWrapperFilter{query}
+}
+
+// returns the inner Query
+func (f *QueryWrapperFilter) Query() Query {
+	return f.query
+}
+
+func (f *QueryWrapperFilter) DocIdSet(ctx index.AtomicReaderContext, acceptDocs util.Bits) (DocIdSet, error) {
+	// get a private context that is used to rewrite, createWeight and score eventually
+	reader := ctx.Reader()
+	privateContext := reader.Context().Leaves()[0]
+	weight, err := NewIndexSearcher(reader).CreateNormalizedWeight(f.query)
+	if err != nil {
+		return nil, err
+	}
+	return &scorerDocIdSet{func() (index.DocIdSetIterator, error) {
+		s, err := weight.Scorer(privateContext, true, false, acceptDocs)
+		if s == nil || err != nil {
+			return nil, err
+		}
+		return s, nil
+	}}, nil
+}
+
+func (f *QueryWrapperFilter) String() string {
+	return fmt.Sprintf("QueryWrapperFilter(%v)", f.query)
+}
+
+// A DocIdSet whose iterator is the Scorer of a query.
+type scorerDocIdSet struct {
+	scorer func() (index.DocIdSetIterator, error)
+}
+
+func (s *scorerDocIdSet) Iterator() (index.DocIdSetIterator, error) {
+	return s.scorer()
+}
+
+func (s *scorerDocIdSet) Bits() util.Bits {
+	return nil
+}
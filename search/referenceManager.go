@@ -0,0 +1,269 @@
+package search
+
+import (
+	"errors"
+	"sync"
+)
+
+// search/ReferenceManager.java
+
+// The hooks of the managed references, implemented by the concrete
+// managers.
+type referenceManagerSPI interface {
+	// Decrement reference counting on the given reference.
+	decRef(reference interface{}) error
+	/*
+		Refresh the given reference if needed. Returns nil if no refresh
+		was needed, otherwise a new refreshed reference.
+	*/
+	refreshIfNeeded(referenceToRefresh interface{}) (interface{}, error)
+	/*
+		Try to increment reference counting on the given reference.
+		Return true if the operation was successful.
+	*/
+	tryIncRef(reference interface{}) bool
+}
+
+/*
+Utility class to safely share instances of a certain type across
+multiple goroutines, while periodically refreshing them. This class
+ensures each reference is closed only once all goroutines have
+finished using it. It is recommended to consult the documentation of
+ReferenceManager implementations for their MaybeRefresh() semantics.
+*/
+type ReferenceManager struct {
+	spi     referenceManagerSPI
+	lock    sync.Mutex // guards current
+	current interface{}
+	// a single refresh runs at a time; holds a value while it runs
+	refreshLock chan bool
+
+	listenersLock sync.Mutex
+	listeners     []RefreshListener
+}
+
+func newReferenceManager(spi referenceManagerSPI, current interface{}) *ReferenceManager {
+	return &ReferenceManager{
+		spi:         spi,
+		current:     current,
+		refreshLock: make(chan bool, 1),
+	}
+}
+
+func (m *ReferenceManager) ensureOpen() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.current == nil {
+		return errors.New("this ReferenceManager is closed")
+	}
+	return nil
+}
+
+func (m *ReferenceManager) swapReference(newReference interface{}) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.current == nil {
+		return errors.New("this ReferenceManager is closed")
+	}
+	oldReference := m.current
+	m.current = newReference
+	return m.spi.decRef(oldReference)
+}
+
+/*
+Obtain the current reference. You must match every call to acquire
+with one call to release(); it's best to do so in a defer statement.
+The reference must not be used after release() was called.
+*/
+func (m *ReferenceManager) acquire() (interface{}, error) {
+	for {
+		m.lock.Lock()
+		ref := m.current
+		m.lock.Unlock()
+		if ref == nil {
+			return nil, errors.New("this ReferenceManager is closed")
+		}
+		if m.spi.tryIncRef(ref) {
+			return ref, nil
+		}
+	}
+}
+
+/*
+Closes this ReferenceManager to prevent future acquiring. A reference
+manager should be closed if the reference to the managed resource
+should be disposed or the application using the ReferenceManager is
+shutting down. The managed resource might not be released immediately,
+if the ReferenceManager user is holding on to a previously acquired
+reference. The resource will be released once when the last reference
+is released.
+
+Calling Close() again has no effect.
+*/
+func (m *ReferenceManager) Close() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.current == nil {
+		return nil
+	}
+	oldReference := m.current
+	m.current = nil
+	return m.spi.decRef(oldReference)
+}
+
+func (m *ReferenceManager) doMaybeRefresh() (err error) {
+	// the caller, MaybeRefresh() or MaybeRefreshBlocking(), holds the
+	// refresh lock already
+	reference, err := m.acquire()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err2 := m.release(reference); err2 != nil && err == nil {
+			err = err2
+		}
+	}()
+	if err = m.notifyRefreshListenersBefore(); err != nil {
+		return err
+	}
+	refreshed := false
+	defer func() {
+		if err2 := m.notifyRefreshListenersRefreshed(refreshed); err2 != nil && err == nil {
+			err = err2
+		}
+	}()
+	newReference, err := m.spi.refreshIfNeeded(reference)
+	if err != nil || newReference == nil {
+		return err
+	}
+	// assert newReference != reference: "refreshIfNeeded should return nil if refresh wasn't needed"
+	if err = m.swapReference(newReference); err != nil {
+		m.release(newReference)
+		return err
+	}
+	refreshed = true
+	return nil
+}
+
+/*
+You must call this (or MaybeRefreshBlocking()), periodically, if you
+want that acquire() will return refreshed instances.
+
+Goroutine-safe: if a refresh is already in progress on another
+goroutine, then this method returns immediately with false, as soon
+as the lock is not available. Otherwise, it returns true after it
+refreshed, if a refresh was needed.
+
+Note that the refreshed reference is released by the last caller
+which releases it, not necessarily by this method.
+*/
+func (m *ReferenceManager) MaybeRefresh() (bool, error) {
+	if err := m.ensureOpen(); err != nil {
+		return false, err
+	}
+	// Ensure only 1 goroutine does refresh at once; other goroutines
+	// just return immediately:
+	select {
+	case m.refreshLock <- true:
+		defer func() { <-m.refreshLock }()
+		return true, m.doMaybeRefresh()
+	default:
+		return false, nil
+	}
+}
+
+/*
+You must call this (or MaybeRefresh()), periodically, if you want
+that acquire() will return refreshed instances.
+
+Goroutine-safe: if a refresh is already in progress on another
+goroutine, then this method blocks until the refresh completes, and
+then refreshes again, if needed.
+*/
+func (m *ReferenceManager) MaybeRefreshBlocking() error {
+	if err := m.ensureOpen(); err != nil {
+		return err
+	}
+	// Ensure only 1 goroutine does refresh at once
+	m.refreshLock <- true
+	defer func() { <-m.refreshLock }()
+	return m.doMaybeRefresh()
+}
+
+/*
+Release the reference previously obtained via acquire().
+
+NOTE: it's safe to call this after Close().
+*/
+func (m *ReferenceManager) release(reference interface{}) error {
+	// assert reference != nil
+	return m.spi.decRef(reference)
+}
+
+func (m *ReferenceManager) notifyRefreshListenersBefore() error {
+	for _, listener := range m.refreshListeners() {
+		if err := listener.BeforeRefresh(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *ReferenceManager) notifyRefreshListenersRefreshed(didRefresh bool) error {
+	for _, listener := range m.refreshListeners() {
+		if err := listener.AfterRefresh(didRefresh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns a copy of the listeners, which may change while they are
+// notified.
+func (m *ReferenceManager) refreshListeners() []RefreshListener {
+	m.listenersLock.Lock()
+	defer m.listenersLock.Unlock()
+	return append([]RefreshListener(nil), m.listeners...)
+}
+
+// Adds a listener, to be notified when a reference is refreshed/swapped.
+func (m *ReferenceManager) AddListener(listener RefreshListener) {
+	if listener == nil {
+		panic("Listener cannot be nil")
+	}
+	m.listenersLock.Lock()
+	defer m.listenersLock.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// Remove a listener added with AddListener().
+func (m *ReferenceManager) RemoveListener(listener RefreshListener) {
+	if listener == nil {
+		panic("Listener cannot be nil")
+	}
+	m.listenersLock.Lock()
+	defer m.listenersLock.Unlock()
+	for i, v := range m.listeners {
+		if v == listener {
+			m.listeners = append(m.listeners[:i], m.listeners[i+1:]...)
+			break
+		}
+	}
+}
+
+// search/ReferenceManager.java/RefreshListener
+
+/*
+Use to receive notification when a refresh has finished. See
+ReferenceManager.AddListener().
+*/
+type RefreshListener interface {
+	// Called right before a refresh attempt starts.
+	BeforeRefresh() error
+	/*
+		Called after the attempted refresh; if the refresh did open a new
+		reference then didRefresh will be true and Acquire() is
+		guaranteed to return the new reference.
+	*/
+	AfterRefresh(didRefresh bool) error
+}
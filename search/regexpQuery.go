@@ -0,0 +1,61 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util/automaton"
+)
+
+// search/RegexpQuery.java
+
+/*
+A fast regular expression query based on the automaton package.
+
+Comparisons are fast, and the term dictionary is intersected with
+the automaton, instead of testing each term against the expression.
+
+The supported syntax is documented in the automaton.RegExp type.
+Note this might be different than other regular expression
+implementations. For some alternatives with different syntax, look
+under the sandbox.
+
+Note this query can be slow, as it needs to iterate over many terms.
+In order to prevent extremely slow RegexpQueries, a Regexp term
+should not start with the expression .*
+*/
+type RegexpQuery struct {
+	*AutomatonQuery
+}
+
+/*
+Constructs a query for terms matching term, with all optional
+regular expression syntax enabled. Returns error if term is not a
+valid regular expression.
+*/
+func NewRegexpQuery(term index.Term) (*RegexpQuery, error) {
+	return NewRegexpQueryWithFlags(term, automaton.REGEXP_ALL)
+}
+
+/*
+Constructs a query for terms matching term, with the optional
+regular expression syntax of flags enabled, e.g.
+automaton.REGEXP_INTERSECTION. Returns error if term is not a valid
+regular expression.
+*/
+func NewRegexpQueryWithFlags(term index.Term, flags int) (*RegexpQuery, error) {
+	re, err := automaton.NewRegExpWithFlags(string(term.Bytes), flags)
+	if err != nil {
+		return nil, err
+	}
+	ans := new(RegexpQuery)
+	ans.AutomatonQuery = newAutomatonQuery(ans, term, re.ToAutomaton())
+	return ans, nil
+}
+
+// Prints a user-readable version of this query.
+func (q *RegexpQuery) ToString(field string) string {
+	s := "/" + string(q.term.Bytes) + "/" + boostString(q.boost)
+	if q.term.Field != field {
+		s = q.term.Field + ":" + s
+	}
+	return s
+}
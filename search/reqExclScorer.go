@@ -0,0 +1,121 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+)
+
+// search/ReqExclScorer.java
+
+/*
+A Scorer for queries with a required subscorer and an excluding
+(prohibited) sub DocIdSetIterator.
+
+This Scorer implements Advance(), and it uses Advance() on the given
+scorers.
+*/
+type ReqExclScorer struct {
+	*ScorerImpl
+	reqScorer Scorer
+	exclDisi  index.DocIdSetIterator
+	doc       int
+	// the cost of reqScorer, which is dropped once exhausted
+	cost int64
+}
+
+// Construct a ReqExclScorer, with reqScorer the scorer that must
+// match, except where exclDisi matches.
+func newReqExclScorer(reqScorer Scorer, exclDisi index.DocIdSetIterator) *ReqExclScorer {
+	ans := &ReqExclScorer{reqScorer: reqScorer, exclDisi: exclDisi, doc: -1, cost: reqScorer.Cost()}
+	ans.ScorerImpl = NewScorer(ans, reqScorer.Weight())
+	return ans
+}
+
+func (s *ReqExclScorer) NextDoc() (int, bool) {
+	if s.reqScorer == nil {
+		return s.doc, false
+	}
+	var more bool
+	if s.doc, more = s.reqScorer.NextDoc(); !more {
+		s.reqScorer = nil // exhausted, nothing left
+		return s.doc, false
+	}
+	if s.exclDisi == nil {
+		return s.doc, true
+	}
+	s.doc = s.toNonExcluded()
+	return s.doc, s.doc != index.NO_MORE_DOCS
+}
+
+/*
+Advance to non excluded doc.
+
+On entry:
+
+  - reqScorer != nil,
+  - exclDisi != nil,
+  - reqScorer was advanced once via NextDoc() or Advance() and
+    reqScorer.DocId() may still be excluded.
+
+Advances reqScorer a non excluded required doc, if any. Returns the
+doc, or NO_MORE_DOCS if there is none.
+*/
+func (s *ReqExclScorer) toNonExcluded() int {
+	exclDoc := s.exclDisi.DocId()
+	reqDoc := s.reqScorer.DocId() // may be excluded
+	for more := true; more; reqDoc, more = s.reqScorer.NextDoc() {
+		if reqDoc < exclDoc {
+			return reqDoc // reqScorer advanced to before exclScorer, ie. not excluded
+		} else if reqDoc > exclDoc {
+			var exclMore bool
+			if exclDoc, exclMore = s.exclDisi.Advance(reqDoc); !exclMore {
+				s.exclDisi = nil // exhausted, no more exclusions
+				return reqDoc
+			}
+			if exclDoc > reqDoc {
+				return reqDoc // not excluded
+			}
+		}
+	}
+	s.reqScorer = nil // exhausted, nothing left
+	return index.NO_MORE_DOCS
+}
+
+func (s *ReqExclScorer) DocId() int {
+	return s.doc
+}
+
+// The exclusions may only reduce the matches of the required scorer.
+func (s *ReqExclScorer) Cost() int64 {
+	return s.cost
+}
+
+/*
+Returns the score of the current document matching the query.
+Initially invalid, until NextDoc() is called the first time.
+*/
+func (s *ReqExclScorer) Score() float32 {
+	return s.reqScorer.Score() // reqScorer may be nil when next() or skipTo() already return false
+}
+
+func (s *ReqExclScorer) Freq() int {
+	return s.reqScorer.Freq()
+}
+
+func (s *ReqExclScorer) Advance(target int) (int, bool) {
+	if s.reqScorer == nil {
+		s.doc = index.NO_MORE_DOCS
+		return s.doc, false
+	}
+	if s.exclDisi == nil {
+		var more bool
+		s.doc, more = s.reqScorer.Advance(target)
+		return s.doc, more
+	}
+	if _, more := s.reqScorer.Advance(target); !more {
+		s.reqScorer = nil
+		s.doc = index.NO_MORE_DOCS
+		return s.doc, false
+	}
+	s.doc = s.toNonExcluded()
+	return s.doc, s.doc != index.NO_MORE_DOCS
+}
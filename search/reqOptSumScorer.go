@@ -0,0 +1,82 @@
+package search
+
+// search/ReqOptSumScorer.java
+
+/*
+A Scorer for queries with a required part and an optional part.
+Delays Advance() on the optional part until a Score() is needed.
+
+This Scorer implements Advance().
+*/
+type ReqOptSumScorer struct {
+	*ScorerImpl
+	// The scorers passed from the constructor. These are set to nil as
+	// soon as their NextDoc() or Advance() returns false.
+	reqScorer Scorer
+	optScorer Scorer
+}
+
+// Construct a ReqOptScorer, with reqScorer the required scorer, which
+// must match, and optScorer the optional scorer, which is used for
+// scoring only.
+func newReqOptSumScorer(reqScorer, optScorer Scorer) *ReqOptSumScorer {
+	ans := &ReqOptSumScorer{reqScorer: reqScorer, optScorer: optScorer}
+	ans.ScorerImpl = NewScorer(ans, reqScorer.Weight())
+	return ans
+}
+
+func (s *ReqOptSumScorer) NextDoc() (int, bool) {
+	return s.reqScorer.NextDoc()
+}
+
+func (s *ReqOptSumScorer) Advance(target int) (int, bool) {
+	return s.reqScorer.Advance(target)
+}
+
+func (s *ReqOptSumScorer) DocId() int {
+	return s.reqScorer.DocId()
+}
+
+// Only the required scorer drives the matching.
+func (s *ReqOptSumScorer) Cost() int64 {
+	return s.reqScorer.Cost()
+}
+
+/*
+Returns the score of the current document matching the query.
+Initially invalid, until NextDoc() is called the first time. It is
+the score of the required scorer, eventually increased by the score
+of the optional scorer when it also matches the current document.
+*/
+func (s *ReqOptSumScorer) Score() float32 {
+	// TODO: sum into a double and cast to float if we ever send
+	// required clauses to BS1
+	curDoc := s.reqScorer.DocId()
+	reqScore := s.reqScorer.Score()
+	if s.optScorer == nil {
+		return reqScore
+	}
+
+	optScorerDoc := s.optScorer.DocId()
+	if optScorerDoc < curDoc {
+		var more bool
+		if optScorerDoc, more = s.optScorer.Advance(curDoc); !more {
+			s.optScorer = nil
+			return reqScore
+		}
+	}
+
+	if optScorerDoc == curDoc {
+		return reqScore + s.optScorer.Score()
+	}
+	return reqScore
+}
+
+func (s *ReqOptSumScorer) Freq() int {
+	// we might have deferred advance()
+	s.Score()
+	if s.optScorer != nil && s.optScorer.DocId() == s.reqScorer.DocId() {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,25 @@
+package search
+
+// search/Rescorer.java
+
+/*
+Re-scores the topN results (TopDocs) from an original query. See
+QueryRescorer for an actual implementation. Typically, you run a
+low-cost first-pass query across the entire index, collecting the top
+few hundred hits perhaps, and then use this class to mix in a more
+costly second pass scoring.
+
+See QueryRescorer.Rescore() for a simple static method to call to
+rescore using a 2nd pass Query.
+*/
+type Rescorer interface {
+	/*
+		Rescore an initial first-pass TopDocs, returning a new TopDocs
+		of the topN hits.
+	*/
+	Rescore(searcher *IndexSearcher, firstPassTopDocs TopDocs, topN int) (TopDocs, error)
+	/*
+		Explains how the score for the specified document was computed.
+	*/
+	Explain(searcher *IndexSearcher, firstPassExplanation *Explanation, docID int) (*Explanation, error)
+}
@@ -0,0 +1,81 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+)
+
+// search/Scorer.java
+
+/*
+Expert: Common scoring functionality for different types of queries.
+
+A Scorer iterates over documents matching a query in increasing
+order of doc Id.
+
+Document scores are computed using a given Similarity
+implementation.
+
+NOTE: The values -Inf and NaN are not valid scores. Certain
+collectors (eg TopScoreDocCollector) will not properly collect hits
+with these scores.
+*/
+type Scorer interface {
+	index.DocIdSetIterator
+	/*
+		Returns the score of the current document matching the query.
+		Initially invalid, until NextDoc() or Advance() is called the
+		first time, or when called from within Collector.Collect().
+	*/
+	Score() float32
+	// Returns the Weight of the query of this scorer, or nil.
+	Weight() Weight
+	// Scores and collects all matching documents.
+	ScoreAndCollect(c LeafCollector) error
+	/*
+		Expert: Collects matching documents in a range. Hook for
+		optimization. Note, firstDocID is added to ensure that NextDoc()
+		was called before this method.
+
+		Returns true if more matching documents may remain.
+	*/
+	ScoreAndCollectUpTo(c LeafCollector, max, firstDocID int) (bool, error)
+}
+
+// Embeddable implementation of the collecting methods of Scorer, by
+// iterating over the documents of self.
+type ScorerImpl struct {
+	self   Scorer
+	weight Weight
+}
+
+// Constructs a Scorer, whose iteration and scoring methods are
+// implemented by self, for the given weight, which may be nil.
+func NewScorer(self Scorer, weight Weight) *ScorerImpl {
+	return &ScorerImpl{self, weight}
+}
+
+func (s *ScorerImpl) Weight() Weight {
+	return s.weight
+}
+
+func (s *ScorerImpl) ScoreAndCollect(c LeafCollector) error {
+	c.SetScorer(s.self)
+	for doc, more := s.self.NextDoc(); more; doc, more = s.self.NextDoc() {
+		if err := c.Collect(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ScorerImpl) ScoreAndCollectUpTo(c LeafCollector, max, firstDocID int) (bool, error) {
+	c.SetScorer(s.self)
+	doc := firstDocID
+	for doc < max {
+		if err := c.Collect(doc); err != nil {
+			return false, err
+		}
+		doc, _ = s.self.NextDoc()
+	}
+	return doc != index.NO_MORE_DOCS, nil
+}
@@ -1,50 +1,336 @@
 package search
 
 import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
 	"github.com/balzaczyy/golucene/index"
-	"log"
 	"math"
 )
 
-// IndexSearcher
+// search/IndexSearcher.java
+
+/*
+Implements search over a single IndexReader.
+
+Applications usually need only call the inherited SearchTop() or
+Search() methods. For performance reasons, if your index is unchanging,
+you should share a single IndexSearcher instance across multiple
+searches instead of creating a new one per-search. If your index has
+changed and you wish to see the changes reflected in searching, you
+should use index.OpenDirectoryReaderIfChanged() to obtain a new
+reader and then create a new IndexSearcher from that. Also, for
+low-latency turnaround it's best to use a near-real-time reader. Once
+you have a new IndexReader, it's relatively cheap to create a new
+IndexSearcher from it.
+
+The search is driven segment by segment: the query is rewritten,
+turned into a normalized Weight, and the Scorer of each leaf of the
+reader feeds its matching documents to a Collector. If the searcher
+is given an Executor, the top hits searches run each leaf on its own
+goroutine, and merge the top hits of the leaves, which lowers the
+latency of a query over an index of many segments.
+*/
 type IndexSearcher struct {
-	reader        index.IndexReader
+	reader index.IndexReader
+	// NOTE: these members might change in incompatible ways
+	// in the next release
 	readerContext index.IndexReaderContext
 	leafContexts  []index.AtomicReaderContext
-	similarity    Similarity
+	// The Similarity implementation used by this searcher.
+	similarity Similarity
+	// runs the searches of the leaves, or nil to search them in turn
+	executor *Executor
 }
 
-func NewIndexSearcher(r index.IndexReader) IndexSearcher {
-	log.Print("Initializing IndexSearcher from IndexReader: ", r)
+// Creates a searcher searching the provided index.
+func NewIndexSearcher(r index.IndexReader) *IndexSearcher {
 	return NewIndexSearcherFromContext(r.Context())
 }
 
-func NewIndexSearcherFromContext(context index.IndexReaderContext) IndexSearcher {
-	//assert context.isTopLevel: "IndexSearcher's ReaderContext must be topLevel for reader" + context.reader();
-	defaultSimilarity := NewDefaultSimilarity()
-	return IndexSearcher{context.Reader(), context, context.Leaves(), defaultSimilarity}
+/*
+Runs searches of separate segments using the provided Executor.
+IndexSearcher doesn't close the Executor, which may be shared by many
+searchers.
+*/
+func NewIndexSearcherWithExecutor(r index.IndexReader, executor *Executor) *IndexSearcher {
+	return NewIndexSearcherFromContextWithExecutor(r.Context(), executor)
+}
+
+/*
+Creates a searcher searching the provided top-level
+IndexReaderContext.
+*/
+func NewIndexSearcherFromContext(context index.IndexReaderContext) *IndexSearcher {
+	return NewIndexSearcherFromContextWithExecutor(context, nil)
+}
+
+/*
+Creates a searcher searching the provided top-level
+IndexReaderContext, running the searches of separate segments using
+the provided Executor, if non-nil.
+*/
+func NewIndexSearcherFromContextWithExecutor(context index.IndexReaderContext, executor *Executor) *IndexSearcher {
+	// assert context.isTopLevel: "IndexSearcher's ReaderContext must be topLevel for reader" + context.reader();
+	return &IndexSearcher{
+		reader:        context.Reader(),
+		readerContext: context,
+		leafContexts:  context.Leaves(),
+		similarity:    NewDefaultSimilarity(),
+		executor:      executor,
+	}
+}
+
+// Return the IndexReader this searches.
+func (ss *IndexSearcher) IndexReader() index.IndexReader {
+	return ss.reader
+}
+
+/*
+Returns this searcher's top-level IndexReaderContext.
+*/
+func (ss *IndexSearcher) TopReaderContext() index.IndexReaderContext {
+	return ss.readerContext
+}
+
+// Expert: Set the Similarity implementation used by this
+// IndexSearcher.
+func (ss *IndexSearcher) SetSimilarity(similarity Similarity) {
+	ss.similarity = similarity
+}
+
+// Returns the Similarity used by this searcher, DefaultSimilarity
+// unless set otherwise.
+func (ss *IndexSearcher) Similarity() Similarity {
+	return ss.similarity
+}
+
+// Sugar for ss.IndexReader().Document(docID, visitor)
+func (ss *IndexSearcher) Document(docID int, visitor index.StoredFieldVisitor) error {
+	return ss.reader.Document(docID, visitor)
+}
+
+// Returns the stored fields of the document docID.
+func (ss *IndexSearcher) Doc(docID int) (doc *document.Document, err error) {
+	visitor := index.NewDocumentStoredFieldVisitor()
+	if err = ss.reader.Document(docID, visitor); err != nil {
+		return nil, err
+	}
+	return visitor.Document(), nil
+}
+
+// Returns only the given stored fields of the document docID.
+func (ss *IndexSearcher) DocOf(docID int, fieldsToLoad ...string) (doc *document.Document, err error) {
+	visitor := index.NewDocumentStoredFieldVisitorOf(fieldsToLoad...)
+	if err = ss.reader.Document(docID, visitor); err != nil {
+		return nil, err
+	}
+	return visitor.Document(), nil
 }
 
-func (ss IndexSearcher) SearchTop(q Query, n int) (topDocs TopDocs, err error) {
+// Finds the top n hits for query.
+func (ss *IndexSearcher) SearchTop(q Query, n int) (topDocs TopDocs, err error) {
 	return ss.Search(q, nil, n)
 }
 
-func (ss IndexSearcher) Search(q Query, f Filter, n int) (topDocs TopDocs, err error) {
-	w, err := ss.createNormalizedWeight(wrapFilter(q, f))
+// Finds the top n hits for query, applying filter if non-nil.
+func (ss *IndexSearcher) Search(q Query, f Filter, n int) (topDocs TopDocs, err error) {
+	w, err := ss.CreateNormalizedWeight(wrapFilter(q, f))
+	if err != nil {
+		return TopDocs{}, err
+	}
+	return ss.searchTop(ss.leafContexts, w, nil, n)
+}
+
+/*
+Finds the top n hits for query, applying filter if non-nil, where all
+results are after a previous result (after).
+
+By passing the bottom result from a previous page as after, this
+method can be used for efficient 'deep-paging' across potentially
+large result sets.
+
+Returns an error if after.Doc exceeds the number of documents.
+*/
+func (ss *IndexSearcher) SearchAfter(after *ScoreDoc, q Query, f Filter, n int) (topDocs TopDocs, err error) {
+	if after != nil && after.Doc >= ss.reader.MaxDoc() {
+		return TopDocs{}, errors.New(fmt.Sprintf(
+			"after.doc exceeds the number of documents in that reader: after.doc=%v limit=%v",
+			after.Doc, ss.reader.MaxDoc()))
+	}
+	w, err := ss.CreateNormalizedWeight(wrapFilter(q, f))
+	if err != nil {
+		return TopDocs{}, err
+	}
+	return ss.searchTop(ss.leafContexts, w, after, n)
+}
+
+/*
+Search implementation with arbitrary sorting. Finds the top n hits
+for query, applying filter if non-nil, and sorting the hits by the
+criteria in sort.
+
+NOTE: this does not compute scores by default; use
+SearchSortedWithScores() to enable scoring.
+*/
+func (ss *IndexSearcher) SearchSorted(q Query, f Filter, n int, sort *Sort) (TopFieldDocs, error) {
+	return ss.SearchSortedWithScores(q, f, n, sort, false, false)
+}
+
+/*
+Search implementation with arbitrary sorting, plus control over
+whether hit scores and max score should be computed. Finds the top n
+hits for query, applying filter if non-nil, and sorting the hits by
+the criteria in sort. If doDocScores is true then the score of each
+hit will be computed and returned. If doMaxScore is true then the
+maximum score over all collected hits will be computed.
+*/
+func (ss *IndexSearcher) SearchSortedWithScores(q Query, f Filter, n int, sort *Sort,
+	doDocScores, doMaxScore bool) (TopFieldDocs, error) {
+	w, err := ss.CreateNormalizedWeight(wrapFilter(q, f))
+	if err != nil {
+		return TopFieldDocs{}, err
+	}
+	return ss.searchSorted(ss.leafContexts, w, n, sort, true, doDocScores, doMaxScore)
+}
+
+/*
+Lower-level search API.
+
+Collector.Collect() is called for every matching document.
+*/
+func (ss *IndexSearcher) SearchCollector(q Query, c Collector) error {
+	return ss.SearchFilteredCollector(q, nil, c)
+}
+
+/*
+Lower-level search API.
+
+Collector.Collect() is called for every matching document. Collector-
+based access to remote indexes is discouraged.
+
+Applications should only use this if they need all of the matching
+documents. The high-level search API (Search()) is usually more
+efficient, as it skips non-high-scoring hits.
+
+f, if non-nil, is used to filter the results.
+*/
+func (ss *IndexSearcher) SearchFilteredCollector(q Query, f Filter, c Collector) error {
+	w, err := ss.CreateNormalizedWeight(wrapFilter(q, f))
+	if err != nil {
+		return err
+	}
+	return ss.search(ss.leafContexts, w, c)
+}
+
+/*
+Returns an Explanation that describes how doc scored against query.
+
+This is intended to be used in developing Similarity implementations,
+and, for good performance, should not be displayed with every hit.
+Computing an explanation is as expensive as executing the query over
+the entire index.
+*/
+func (ss *IndexSearcher) Explain(q Query, doc int) (*Explanation, error) {
+	w, err := ss.CreateNormalizedWeight(q)
+	if err != nil {
+		return nil, err
+	}
+	return ss.ExplainWeight(w, doc)
+}
+
+/*
+Expert: low-level implementation method. Returns an Explanation that
+describes how doc scored against weight.
+
+Applications should call Explain(). Returns an error if doc is not in
+[0, MaxDoc()) of the searched reader.
+*/
+func (ss *IndexSearcher) ExplainWeight(w Weight, doc int) (*Explanation, error) {
+	if maxDoc := ss.reader.MaxDoc(); doc < 0 || doc >= maxDoc {
+		return nil, errors.New(fmt.Sprintf("docID must be [0, %v) (got docID=%v)", maxDoc, doc))
+	}
+	ctx, deBasedDoc := index.LeafForDoc(doc, ss.leafContexts)
+	return w.Explain(ctx, deBasedDoc)
+}
+
+// Expert: Low-level search implementation. Finds the top n hits for
+// query, in the given leaves, after the hit after if non-nil.
+func (ss *IndexSearcher) searchTop(leaves []index.AtomicReaderContext, w Weight,
+	after *ScoreDoc, nDocs int) (TopDocs, error) {
+	limit := ss.reader.MaxDoc()
+	if limit == 0 {
+		limit = 1
+	}
+	if nDocs > limit {
+		nDocs = limit
+	}
+	if ss.executor != nil && len(leaves) > 1 {
+		return ss.searchTopParallel(leaves, w, after, nDocs)
+	}
+	// single thread
+	collector, err := NewTopScoreDocCollectorAfter(nDocs, after, !w.IsScoresDocsOutOfOrder())
 	if err != nil {
 		return TopDocs{}, err
 	}
-	return ss.searchWSI(w, ScoreDoc{}, n), nil
+	if err = ss.search(leaves, w, collector); err != nil {
+		return TopDocs{}, err
+	}
+	return collector.TopDocs(), nil
 }
 
-func (ss IndexSearcher) searchWSI(w Weight, after ScoreDoc, nDocs int) TopDocs {
-	// TODO support concurrent search
-	return ss.searchLWSI(ss.leafContexts, w, after, nDocs)
+/*
+Searches each leaf on its own goroutine of the executor, collecting
+its top nDocs hits, and merges them. Weights and their leaf Scorers
+must then support being used by concurrent goroutines, which is the
+case of the Weights of this package.
+*/
+func (ss *IndexSearcher) searchTopParallel(leaves []index.AtomicReaderContext, w Weight,
+	after *ScoreDoc, nDocs int) (TopDocs, error) {
+	shardHits := make([]TopDocs, len(leaves))
+	results := make([]<-chan error, len(leaves))
+	for i, ctx := range leaves {
+		i, leaf := i, []index.AtomicReaderContext{ctx}
+		results[i] = ss.executor.Execute(func() error {
+			collector, err := NewTopScoreDocCollectorAfter(nDocs, after, !w.IsScoresDocsOutOfOrder())
+			if err != nil {
+				return err
+			}
+			if err = ss.search(leaf, w, collector); err != nil {
+				return err
+			}
+			shardHits[i] = collector.TopDocs()
+			return nil
+		})
+	}
+	// wait for all the leaves, even after a failure, so that no task
+	// outlives the search
+	var err error
+	for _, result := range results {
+		if err2 := <-result; err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	if err != nil {
+		return TopDocs{}, err
+	}
+	// ties are broken by leaf, then by docID within the leaf, like
+	// the sequential search does
+	topDocs := MergeTopDocs(nDocs, shardHits)
+	for _, hit := range topDocs.ScoreDocs {
+		// the hits are of this searcher, not of a shard
+		hit.ShardIndex = -1
+	}
+	return topDocs, nil
 }
 
-func (ss IndexSearcher) searchLWSI(leaves []index.AtomicReaderContext,
-	w Weight, after ScoreDoc, nDocs int) TopDocs {
-	// TODO support concurrent search
+/*
+Just like searchTop(), but you choose whether or not the fields in
+the returned FieldDocs should be set by specifying fillFields.
+*/
+func (ss *IndexSearcher) searchSorted(leaves []index.AtomicReaderContext, w Weight, nDocs int,
+	sort *Sort, fillFields, doDocScores, doMaxScore bool) (TopFieldDocs, error) {
+	// single thread
 	limit := ss.reader.MaxDoc()
 	if limit == 0 {
 		limit = 1
@@ -52,69 +338,158 @@ func (ss IndexSearcher) searchLWSI(leaves []index.AtomicReaderContext,
 	if nDocs > limit {
 		nDocs = limit
 	}
-	collector := NewTopScoreDocCollector(nDocs, after, !w.IsScoresDocsOutOfOrder())
-	ss.searchLWC(leaves, w, collector)
-	return collector.TopDocs()
+	collector, err := NewTopFieldCollector(sort, nDocs, fillFields, doDocScores,
+		doMaxScore, !w.IsScoresDocsOutOfOrder())
+	if err != nil {
+		return TopFieldDocs{}, err
+	}
+	if err = ss.search(leaves, w, collector); err != nil {
+		return TopFieldDocs{}, err
+	}
+	return collector.TopFieldDocs(), nil
 }
 
-func (ss IndexSearcher) searchLWC(leaves []index.AtomicReaderContext, w Weight, c Collector) {
+/*
+Lower-level search API.
+
+Collector.Collect() is called for every document.
+
+NOTE: this method executes the searches on all given leaves
+exclusively. To search across all the searchers leaves use
+ss.leafContexts.
+*/
+func (ss *IndexSearcher) search(leaves []index.AtomicReaderContext, w Weight, c Collector) error {
 	// TODO: should we make this
 	// threaded...?  the Collector could be sync'd?
 	// always use single thread:
 	for _, ctx := range leaves { // search each subreader
-		log.Print(ctx)
-		c.SetNextReader(ctx)
-		// GOTO: CollectionTerminatedException
-		if scorer, ok := w.Scorer(ctx, !c.AcceptsDocsOutOfOrder(), true,
-			ctx.Reader().(index.AtomicReader).LiveDocs()); ok {
-			scorer.ScoreAndCollect(c)
-			// GOTO: CollectionTerminatedException
+		if err := c.SetNextReader(ctx); err != nil {
+			if err == ErrCollectionTerminated {
+				// there is no doc of interest in this reader context
+				// continue with the following leaf
+				continue
+			}
+			return err
+		}
+		scorer, err := w.Scorer(ctx, !c.AcceptsDocsOutOfOrder(), true,
+			ctx.Reader().(index.AtomicReader).LiveDocs())
+		if err != nil {
+			return err
+		}
+		if scorer != nil {
+			if err = scorer.ScoreAndCollect(c); err != nil && err != ErrCollectionTerminated {
+				return err
+			}
+			// collection was terminated prematurely
+			// continue with the following leaf
 		}
 	}
+	return nil
 }
 
-func (ss IndexSearcher) TopReaderContext() index.IndexReaderContext {
-	return ss.readerContext
+/*
+A bounded pool of goroutines, which runs the searches of the leaves
+of the IndexSearchers sharing it, so that no more than its number of
+workers leaf searches run at once.
+*/
+type Executor struct {
+	workers chan bool
+}
+
+// Creates an Executor running up to the given number of tasks at once.
+func NewExecutor(workers int) *Executor {
+	if workers < 1 {
+		panic(fmt.Sprintf("workers must be >= 1, got %v", workers))
+	}
+	return &Executor{make(chan bool, workers)}
+}
+
+/*
+Runs task on its own goroutine, once a worker is free. It returns
+without waiting for the task to start; the returned channel receives
+the error of the task once it completes, or an error describing the
+panic of the task, if any.
+*/
+func (e *Executor) Execute(task func() error) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		e.workers <- true
+		defer func() { <-e.workers }()
+		result <- runRecovered(task)
+	}()
+	return result
+}
+
+// Runs task, reporting its panic, if any, as an error.
+func runRecovered(task func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprintf("search task panicked: %v", r))
+		}
+	}()
+	return task()
 }
 
+// Wraps q with a FilteredQuery if f is non-nil.
 func wrapFilter(q Query, f Filter) Query {
 	if f == nil {
 		return q
 	}
-	panic("FilteredQuery not supported yet")
+	return NewFilteredQuery(q, f)
 }
 
-func (ss IndexSearcher) createNormalizedWeight(q Query) (w Weight, err error) {
-	q = rewrite(q, ss.reader)
-	log.Printf("After rewrite: %v", q)
-	w, err = q.CreateWeight(ss)
-	if err != nil {
+/*
+Expert: called to re-write queries into primitive queries, until the
+rewritten query is stable.
+*/
+func (ss *IndexSearcher) Rewrite(q Query) (Query, error) {
+	after, err := q.Rewrite(ss.reader)
+	for err == nil && after != q {
+		q = after
+		after, err = q.Rewrite(ss.reader)
+	}
+	return q, err
+}
+
+/*
+Creates a normalized weight for a top-level Query. The query is
+rewritten by this method and Query.CreateWeight() called, afterwards
+the Weight is normalized. The returned Weight can then directly be
+used to get a Scorer.
+*/
+func (ss *IndexSearcher) CreateNormalizedWeight(q Query) (w Weight, err error) {
+	if q, err = ss.Rewrite(q); err != nil {
+		return nil, err
+	}
+	if w, err = q.CreateWeight(ss); err != nil {
 		return nil, err
 	}
 	v := w.ValueForNormalization()
-	norm := ss.similarity.queryNorm(v)
-	if math.IsInf(norm, 1) || math.IsNaN(norm) {
+	norm := ss.similarity.QueryNorm(v)
+	if math.IsInf(float64(norm), 0) || math.IsNaN(float64(norm)) {
 		norm = 1.0
 	}
 	w.Normalize(norm, 1.0)
 	return w, nil
 }
 
-func rewrite(q Query, r index.IndexReader) Query {
-	log.Printf("Rewriting '%v'...", q)
-	after := q.Rewrite(r)
-	for after != q {
-		q = after
-		after = q.Rewrite(r)
-	}
-	return q
-}
+/*
+Returns TermStatistics for a term.
 
-func (ss IndexSearcher) TermStatistics(term index.Term, context index.TermContext) TermStatistics {
+This can be overridden for example, to return a term's statistics
+across a distributed collection.
+*/
+func (ss *IndexSearcher) TermStatistics(term index.Term, context *index.TermContext) TermStatistics {
 	return NewTermStatistics(term.Bytes, int64(context.DocFreq), context.TotalTermFreq)
 }
 
-func (ss IndexSearcher) CollectionStatistics(field string) CollectionStatistics {
+/*
+Returns CollectionStatistics for a field.
+
+This can be overridden for example, to return a field's statistics
+across a distributed collection.
+*/
+func (ss *IndexSearcher) CollectionStatistics(field string) CollectionStatistics {
 	terms := index.GetMultiTerms(ss.reader, field)
 	if terms == nil {
 		return NewCollectionStatistics(field, int64(ss.reader.MaxDoc()), 0, 0, 0)
@@ -122,9 +497,20 @@ func (ss IndexSearcher) CollectionStatistics(field string) CollectionStatistics
 	return NewCollectionStatistics(field, int64(ss.reader.MaxDoc()), int64(terms.DocCount()), terms.SumTotalTermFreq(), terms.SumDocFreq())
 }
 
+func (ss *IndexSearcher) String() string {
+	return fmt.Sprintf("IndexSearcher(%v)", ss.reader)
+}
+
+// search/TermStatistics.java
+
+// Contains statistics for a specific term
 type TermStatistics struct {
-	Term                   []byte
-	DocFreq, TotalTermFreq int64
+	// the term text
+	Term []byte
+	// the number of documents this term occurs in
+	DocFreq int64
+	// the total number of occurrences of this term, or -1
+	TotalTermFreq int64
 }
 
 func NewTermStatistics(term []byte, docFreq, totalTermFreq int64) TermStatistics {
@@ -133,6 +519,9 @@ func NewTermStatistics(term []byte, docFreq, totalTermFreq int64) TermStatistics
 	return TermStatistics{term, docFreq, totalTermFreq}
 }
 
+// search/CollectionStatistics.java
+
+// Contains statistics for a collection (field)
 type CollectionStatistics struct {
 	field                                          string
 	maxDoc, docCount, sumTotalTermFreq, sumDocFreq int64
@@ -146,40 +535,19 @@ func NewCollectionStatistics(field string, maxDoc, docCount, sumTotalTermFreq, s
 	return CollectionStatistics{field, maxDoc, docCount, sumTotalTermFreq, sumDocFreq}
 }
 
-type Similarity interface {
-	queryNorm(valueForNormalization float32) float64
-	computeWeight(queryBoost float32, collectionStats CollectionStatistics, termStats ...TermStatistics) SimWeight
-	exactSimScorer(w SimWeight, ctx index.AtomicReaderContext) ExactSimScorer
-}
-
-type ExactSimScorer interface {
-	Score(doc, freq int) float64
-}
-
-type SimWeight interface {
-	ValueForNormalization() float32
-	Normalize(norm float64, topLevelBoost float32) float32
-}
-
-type TFIDFSimilarity struct {
-}
-
-func (ts *TFIDFSimilarity) computeWeight(queryBoost float32, collectionStats CollectionStatistics, termStats ...TermStatistics) SimWeight {
-	panic("not implemented yet")
-}
+// returns the field name
+func (s CollectionStatistics) Field() string { return s.field }
 
-func (ts *TFIDFSimilarity) exactSimScorer(w SimWeight, ctx index.AtomicReaderContext) ExactSimScorer {
-	panic("not implemented yet")
-}
+// returns the total number of documents, regardless of whether they
+// all contain values for this field.
+func (s CollectionStatistics) MaxDoc() int64 { return s.maxDoc }
 
-type DefaultSimilarity struct {
-	*TFIDFSimilarity
-}
+// returns the total number of documents that have at least one term
+// for this field.
+func (s CollectionStatistics) DocCount() int64 { return s.docCount }
 
-func (ds *DefaultSimilarity) queryNorm(sumOfSquaredWeights float32) float64 {
-	return 1.0 / math.Sqrt(float64(sumOfSquaredWeights))
-}
+// returns the total number of tokens for this field
+func (s CollectionStatistics) SumTotalTermFreq() int64 { return s.sumTotalTermFreq }
 
-func NewDefaultSimilarity() Similarity {
-	return &DefaultSimilarity{&TFIDFSimilarity{}}
-}
+// returns the total number of postings for this field
+func (s CollectionStatistics) SumDocFreq() int64 { return s.sumDocFreq }
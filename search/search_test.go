@@ -1,9 +1,15 @@
 package search
 
 import (
-	// "fmt"
+	"fmt"
+	"github.com/balzaczyy/golucene/analysis/core"
+	"github.com/balzaczyy/golucene/document"
 	"github.com/balzaczyy/golucene/index"
 	"github.com/balzaczyy/golucene/store"
+	"github.com/balzaczyy/golucene/util"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -54,3 +60,370 @@ func assertEquals(t *testing.T, a, b interface{}) {
 // 	ss.IncludeIndex("testdata/usingworldtimepro")
 // 	assertEquals(t, 17, ss.search("time"))
 // }
+
+func TestDoc(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ss := NewIndexSearcher(r)
+	doc, err := ss.Doc(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Bats", doc.Get("title"))
+	assertEquals(t, "belfrysample/bats.dita", doc.Get("key"))
+	if doc, err = ss.DocOf(7, "key"); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, len(doc.Fields()))
+	assertEquals(t, "belfrysample/bats.dita", doc.Get("key"))
+}
+
+func TestSearchTop(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/win8/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ss := NewIndexSearcher(r)
+	docs, err := ss.SearchTop(NewTermQuery(index.NewTerm("content", "bat")), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 8, docs.TotalHits)
+	assertEquals(t, 8, len(docs.ScoreDocs))
+	assertEquals(t, docs.ScoreDocs[0].Score, docs.MaxScore)
+	for i := 1; i < len(docs.ScoreDocs); i++ {
+		if prev, hit := docs.ScoreDocs[i-1], docs.ScoreDocs[i]; prev.Score < hit.Score ||
+			prev.Score == hit.Score && prev.Doc > hit.Doc {
+			t.Errorf("Hits out of order: %v before %v", prev, hit)
+		}
+	}
+
+	docs, err = ss.SearchTop(NewTermQuery(index.NewTerm("content", "nosuchterm")), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, docs.TotalHits)
+	assertEquals(t, 0, len(docs.ScoreDocs))
+}
+
+// Indexes the given bodies, one document per body, whose "id" is
+// its number, and opens a searcher over them.
+func newTestSearcher(t *testing.T, bodies ...string) (*IndexSearcher, func()) {
+	docs := make([][]document.IndexableField, len(bodies))
+	for i, body := range bodies {
+		docs[i] = []document.IndexableField{
+			document.NewStringField("id", string('0'+byte(i)), document.STORE_YES),
+			document.NewTextField("body", body, document.STORE_NO),
+		}
+	}
+	return newTestSearcherOfDocs(t, docs...)
+}
+
+// Indexes the given documents, and opens a searcher over them.
+func newTestSearcherOfDocs(t *testing.T, docs ...[]document.IndexableField) (*IndexSearcher, func()) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := index.NewIndexWriter(d, index.NewIndexWriterConfig().
+		SetOpenMode(index.OPEN_MODE_CREATE).SetAnalyzer(core.NewWhitespaceAnalyzer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range docs {
+		if err = w.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewIndexSearcher(r), func() {
+		r.Close()
+		os.RemoveAll(path)
+	}
+}
+
+func assertHits(t *testing.T, docs TopDocs, expected ...int) {
+	if docs.TotalHits != len(expected) || len(docs.ScoreDocs) != len(expected) {
+		t.Errorf("Expected hits %v, but %v", expected, docs.ScoreDocs)
+		return
+	}
+	for i, doc := range expected {
+		if docs.ScoreDocs[i].Doc != doc {
+			t.Errorf("Expected hits %v, but %v", expected, docs.ScoreDocs)
+			return
+		}
+	}
+}
+
+func TestSearchScoreOrder(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a")
+	defer cleanup()
+
+	q := NewTermQuery(index.NewTerm("body", "a"))
+	docs, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the shortest field first, then the most frequent term
+	assertHits(t, docs, 3, 1, 0)
+
+	// n is a limit on the returned hits, not on the total hits
+	if docs, err = ss.SearchTop(q, 2); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, docs.TotalHits)
+	assertEquals(t, 2, len(docs.ScoreDocs))
+	assertEquals(t, 3, docs.ScoreDocs[0].Doc)
+
+	doc, err := ss.Doc(docs.ScoreDocs[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "3", doc.Get("id"))
+}
+
+func TestSearchFilter(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a")
+	defer cleanup()
+
+	q := NewTermQuery(index.NewTerm("body", "a"))
+	f := NewQueryWrapperFilter(NewTermQuery(index.NewTerm("body", "c")))
+	docs, err := ss.Search(q, f, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, docs, 0)
+
+	f = NewQueryWrapperFilter(NewTermQuery(index.NewTerm("body", "b")))
+	if docs, err = ss.Search(q, f, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, docs, 1, 0)
+
+	f = NewQueryWrapperFilter(NewTermQuery(index.NewTerm("body", "nosuchterm")))
+	if docs, err = ss.Search(q, f, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, docs)
+}
+
+// Indexes the given bodies, committing a segment every segmentSize
+// documents, and opens a reader over them.
+func newMultiSegmentTestReader(t *testing.T, segmentSize int, bodies ...string) (index.IndexReader, func()) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := index.NewIndexWriter(d, index.NewIndexWriterConfig().
+		SetOpenMode(index.OPEN_MODE_CREATE).SetAnalyzer(core.NewWhitespaceAnalyzer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, body := range bodies {
+		if err = w.AddDocument([]document.IndexableField{
+			document.NewTextField("body", body, document.STORE_NO),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if (i+1)%segmentSize == 0 {
+			if err = w.Commit(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, func() {
+		r.Close()
+		os.RemoveAll(path)
+	}
+}
+
+func TestSearchExecutor(t *testing.T) {
+	bodies := make([]string, 200)
+	for i := range bodies {
+		bodies[i] = fmt.Sprintf("a%v b%v c%v", i%2, i%3, i%5)
+		if i%7 == 0 {
+			bodies[i] += " a0 b0"
+		}
+	}
+	r, cleanup := newMultiSegmentTestReader(t, 17, bodies...)
+	defer cleanup()
+	if len(r.Context().Leaves()) < 2 {
+		t.Fatalf("Expected several segments, but %v", len(r.Context().Leaves()))
+	}
+
+	ss := NewIndexSearcher(r)
+	parallel := NewIndexSearcherWithExecutor(r, NewExecutor(3))
+	q := newTestBooleanQuery(newBodyTermQuery("a0"), OCCUR_SHOULD, newBodyTermQuery("b0"), OCCUR_SHOULD,
+		newBodyTermQuery("c1"), OCCUR_SHOULD)
+	for _, n := range []int{1, 10, 200} {
+		expected, err := ss.SearchTop(q, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := parallel.SearchTop(q, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, fmt.Sprint(expected), fmt.Sprint(actual))
+
+		// deep paging
+		after := expected.ScoreDocs[len(expected.ScoreDocs)-1]
+		if expected, err = ss.SearchAfter(after, q, nil, n); err != nil {
+			t.Fatal(err)
+		}
+		if actual, err = parallel.SearchAfter(after, q, nil, n); err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, fmt.Sprint(expected), fmt.Sprint(actual))
+	}
+
+	// no hits
+	docs, err := parallel.SearchTop(newBodyTermQuery("nosuchterm"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, docs)
+
+	// the panic of a leaf search is returned once all the leaves are
+	// done
+	if _, err = parallel.Search(q, panickingFilter(1), 10); err == nil ||
+		!strings.Contains(err.Error(), "no DocIdSet for leaf 1") {
+		t.Errorf("Expected the panic of leaf 1, but %v", err)
+	}
+	if _, err = parallel.SearchTop(q, 10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A Filter which panics on the leaf of the given ord.
+type panickingFilter int
+
+func (f panickingFilter) DocIdSet(ctx index.AtomicReaderContext, acceptDocs util.Bits) (DocIdSet, error) {
+	if ctx.Ord == int(f) {
+		panic(fmt.Sprintf("no DocIdSet for leaf %v", ctx.Ord))
+	}
+	return NewQueryWrapperFilter(NewMatchAllDocsQuery()).DocIdSet(ctx, acceptDocs)
+}
+
+// A Collector which panics on the first collected document.
+type panickingCollector struct {
+	recordingCollector
+}
+
+func (c *panickingCollector) Collect(doc int) error {
+	panic("cannot collect")
+}
+
+func TestExecutorPanic(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a")
+	defer cleanup()
+
+	executor := NewExecutor(1)
+	q := newBodyTermQuery("b")
+	var results []<-chan error
+	for i := 0; i < 3; i++ {
+		var c Collector = new(recordingCollector)
+		if i == 1 {
+			c = new(panickingCollector)
+		}
+		results = append(results, executor.Execute(func() error {
+			return ss.SearchCollector(q, c)
+		}))
+	}
+	// the worker is released by the panicking task, for the next ones
+	for i, result := range results {
+		err := <-result
+		if i == 1 {
+			if err == nil || err.Error() != "search task panicked: cannot collect" {
+				t.Errorf("Expected the panic of the collector, but %v", err)
+			}
+		} else if err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+	}
+}
+
+// A Collector which records the collected documents and their
+// scores.
+type recordingCollector struct {
+	scorer  Scorer
+	docBase int
+	docs    []int
+	scores  []float32
+}
+
+func (c *recordingCollector) SetScorer(s Scorer) {
+	c.scorer = s
+}
+
+func (c *recordingCollector) Collect(doc int) error {
+	c.docs = append(c.docs, c.docBase+doc)
+	c.scores = append(c.scores, c.scorer.Score())
+	return nil
+}
+
+func (c *recordingCollector) SetNextReader(ctx index.AtomicReaderContext) error {
+	c.docBase = ctx.DocBase
+	return nil
+}
+
+func (c *recordingCollector) AcceptsDocsOutOfOrder() bool {
+	return false
+}
+
+func TestSearchCollector(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b c", "a a b", "b c", "a")
+	defer cleanup()
+
+	q := NewTermQuery(index.NewTerm("body", "b"))
+	c := new(recordingCollector)
+	if err := ss.SearchCollector(q, c); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[0 1 2]", fmt.Sprint(c.docs))
+	for _, score := range c.scores {
+		if score <= 0 {
+			t.Errorf("Expected positive scores, but %v", c.scores)
+		}
+	}
+
+	c = new(recordingCollector)
+	f := NewQueryWrapperFilter(NewTermQuery(index.NewTerm("body", "c")))
+	if err := ss.SearchFilteredCollector(q, f, c); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "[0 2]", fmt.Sprint(c.docs))
+}
@@ -0,0 +1,92 @@
+package search
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSearcherLifetimeManager(t *testing.T) {
+	w, cleanup := newTestWriter(t)
+	defer cleanup()
+	addTestDocs(t, w, "a b", "a c")
+
+	searchers, err := NewSearcherManagerFromWriter(w, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer searchers.Close()
+	mgr := NewSearcherLifetimeManager()
+	defer mgr.Close()
+
+	// records the current searcher, and returns its token
+	record := func() (*IndexSearcher, int64) {
+		s, err := searchers.Acquire()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer searchers.Release(s)
+		token, err := mgr.Record(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s, token
+	}
+	s1, token1 := record()
+	_, token := record()
+	assertEquals(t, token1, token)
+	if _, err = mgr.Record(NewIndexSearcher(s1.IndexReader())); err == nil {
+		t.Error("Expected error recording another searcher of the same reader")
+	}
+
+	addTestDocs(t, w, "a d")
+	if err = searchers.MaybeRefreshBlocking(); err != nil {
+		t.Fatal(err)
+	}
+	s2, token2 := record()
+	if token2 == token1 {
+		t.Fatal("Expected a new token after the refresh")
+	}
+
+	// the follow-on request of the first search sees the same
+	// point-in-time searcher
+	s, err := mgr.Acquire(token1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, s1, s)
+	assertEquals(t, 2, countHits(t, s, "a"))
+	if err = mgr.Release(s); err != nil {
+		t.Fatal(err)
+	}
+
+	// the current searcher is never pruned
+	if err = mgr.Prune(NewPruneByAge(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ = mgr.Acquire(token1); s == nil {
+		t.Fatal("Expected the searcher to be kept")
+	}
+	mgr.Release(s)
+	time.Sleep(10 * time.Millisecond)
+	if err = mgr.Prune(NewPruneByAge(time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ = mgr.Acquire(token1); s != nil {
+		t.Error("Expected the old searcher to be pruned")
+	}
+	assertEquals(t, 0, s1.IndexReader().RefCount())
+	if s, _ = mgr.Acquire(token2); s != s2 {
+		t.Fatal("Expected the current searcher to be kept")
+	}
+	mgr.Release(s)
+
+	if err = mgr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = mgr.Acquire(token2); err == nil {
+		t.Error("Expected error acquiring a searcher after Close()")
+	}
+	if _, err = mgr.Record(s2); err == nil {
+		t.Error("Expected error recording a searcher after Close()")
+	}
+}
@@ -0,0 +1,198 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/store"
+)
+
+// search/SearcherFactory.java
+
+/*
+Factory class used by SearcherManager to create new IndexSearchers.
+The implementation can be used to:
+
+  - Wrap the IndexSearcher with custom settings, e.g. a Similarity
+    or an Executor.
+  - Warm the IndexSearcher, with queries or sorts which load caches,
+    before it's used by searches.
+
+NewSearcher() must return a searcher of exactly the given reader.
+*/
+type SearcherFactory interface {
+	// Returns a new IndexSearcher over the given reader.
+	NewSearcher(reader index.IndexReader) (*IndexSearcher, error)
+}
+
+// Adapts a function to a SearcherFactory.
+type SearcherFactoryFunc func(reader index.IndexReader) (*IndexSearcher, error)
+
+func (f SearcherFactoryFunc) NewSearcher(reader index.IndexReader) (*IndexSearcher, error) {
+	return f(reader)
+}
+
+// The SearcherFactory used if none is given, which returns plain
+// IndexSearchers.
+var defaultSearcherFactory = SearcherFactoryFunc(func(reader index.IndexReader) (*IndexSearcher, error) {
+	return NewIndexSearcher(reader), nil
+})
+
+// search/SearcherManager.java
+
+/*
+Utility class to safely share IndexSearcher instances across multiple
+goroutines, while periodically reopening. This class ensures each
+searcher is closed only once all goroutines have finished using it.
+
+Use Acquire() to obtain the current searcher, and Release() to
+release it, like this:
+
+	s, err := mgr.Acquire()
+	if err != nil {
+		return err
+	}
+	defer mgr.Release(s)
+	// Do searching, doc retrieval, etc. with s
+
+In addition you should periodically call MaybeRefresh(). While it's
+possible to call this just before running each query, this is
+discouraged since it penalizes the unlucky queries that do the
+reopen. It's better to use a separate background goroutine, that
+periodically calls MaybeRefresh(). Finally, be sure to call Close()
+once you are done.
+*/
+type SearcherManager struct {
+	*ReferenceManager
+	searcherFactory SearcherFactory
+}
+
+/*
+Creates and returns a new SearcherManager from the given IndexWriter.
+
+applyAllDeletes: if true, all buffered deletes will be applied (made
+visible) in the IndexSearcher / DirectoryReader. If false, the
+deletes may or may not be applied, but remain buffered (in
+IndexWriter) so that they will be applied in the future. Applying
+deletes can be costly, so if your app can tolerate deleted documents
+being returned you might gain some performance by passing false.
+
+searcherFactory: an optional SearcherFactory, nil for the default.
+*/
+func NewSearcherManagerFromWriter(writer *index.IndexWriter, applyAllDeletes bool,
+	searcherFactory SearcherFactory) (*SearcherManager, error) {
+	reader, err := index.OpenDirectoryReaderFromWriter(writer, applyAllDeletes)
+	if err != nil {
+		return nil, err
+	}
+	return newSearcherManager(reader, searcherFactory)
+}
+
+/*
+Creates and returns a new SearcherManager from the given Directory.
+
+searcherFactory: an optional SearcherFactory, nil for the default.
+*/
+func NewSearcherManager(dir store.Directory, searcherFactory SearcherFactory) (*SearcherManager, error) {
+	reader, err := index.OpenDirectoryReader(dir)
+	if err != nil {
+		return nil, err
+	}
+	return newSearcherManager(reader, searcherFactory)
+}
+
+func newSearcherManager(reader index.IndexReader, searcherFactory SearcherFactory) (*SearcherManager, error) {
+	if searcherFactory == nil {
+		searcherFactory = defaultSearcherFactory
+	}
+	searcher, err := GetSearcher(searcherFactory, reader)
+	if err != nil {
+		return nil, err
+	}
+	ans := &SearcherManager{searcherFactory: searcherFactory}
+	ans.ReferenceManager = newReferenceManager(ans, searcher)
+	return ans, nil
+}
+
+func (m *SearcherManager) decRef(reference interface{}) error {
+	return reference.(*IndexSearcher).IndexReader().DecRef()
+}
+
+func (m *SearcherManager) refreshIfNeeded(referenceToRefresh interface{}) (interface{}, error) {
+	r := referenceToRefresh.(*IndexSearcher).IndexReader()
+	// assert r is DirectoryReader: "searcher's IndexReader should be a DirectoryReader"
+	newReader, err := index.OpenIfChanged(r.(index.DirectoryReader))
+	if err != nil || newReader == nil {
+		return nil, err
+	}
+	searcher, err := GetSearcher(m.searcherFactory, newReader)
+	if err != nil {
+		return nil, err
+	}
+	return searcher, nil
+}
+
+func (m *SearcherManager) tryIncRef(reference interface{}) bool {
+	return reference.(*IndexSearcher).IndexReader().TryIncRef()
+}
+
+/*
+Obtain the current IndexSearcher. You must match every call to
+Acquire() with one call to Release(); it's best to do so in a defer
+statement. The searcher must not be used after Release() was called.
+*/
+func (m *SearcherManager) Acquire() (*IndexSearcher, error) {
+	searcher, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	return searcher.(*IndexSearcher), nil
+}
+
+/*
+Release the searcher previously obtained via Acquire().
+
+NOTE: it's safe to call this after Close().
+*/
+func (m *SearcherManager) Release(searcher *IndexSearcher) error {
+	return m.release(searcher)
+}
+
+/*
+Returns true if no changes have occurred since this searcher, i.e.
+its reader, was opened.
+*/
+func (m *SearcherManager) IsSearcherCurrent() (bool, error) {
+	searcher, err := m.Acquire()
+	if err != nil {
+		return false, err
+	}
+	defer m.Release(searcher)
+	r := searcher.IndexReader()
+	// assert r is DirectoryReader: "searcher's IndexReader should be a DirectoryReader"
+	return r.(index.DirectoryReader).IsCurrent(), nil
+}
+
+/*
+Expert: creates a searcher from the provided IndexReader using the
+provided SearcherFactory. NOTE: this decRefs incoming reader on
+error.
+*/
+func GetSearcher(searcherFactory SearcherFactory, reader index.IndexReader) (searcher *IndexSearcher, err error) {
+	success := false
+	defer func() {
+		if !success {
+			reader.DecRef()
+		}
+	}()
+	if searcher, err = searcherFactory.NewSearcher(reader); err != nil {
+		return nil, err
+	}
+	if searcher.IndexReader() != reader {
+		return nil, errors.New(fmt.Sprintf(
+			"SearcherFactory must wrap exactly the provided reader (got %v but expected %v)",
+			searcher.IndexReader(), reader))
+	}
+	success = true
+	return searcher, nil
+}
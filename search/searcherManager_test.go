@@ -0,0 +1,194 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/analysis/core"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/store"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// Opens a writer over a new index in a temporary directory.
+func newTestWriter(t *testing.T) (*index.IndexWriter, func()) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := store.OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := index.NewIndexWriter(d, index.NewIndexWriterConfig().
+		SetOpenMode(index.OPEN_MODE_CREATE).SetAnalyzer(core.NewWhitespaceAnalyzer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w, func() {
+		w.Close()
+		os.RemoveAll(path)
+	}
+}
+
+func addTestDocs(t *testing.T, w *index.IndexWriter, bodies ...string) {
+	for _, body := range bodies {
+		if err := w.AddDocument([]document.IndexableField{
+			document.NewTextField("body", body, document.STORE_NO),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Returns the number of hits of the term in the body field.
+func countHits(t *testing.T, ss *IndexSearcher, term string) int {
+	docs, err := ss.SearchTop(newBodyTermQuery(term), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return docs.TotalHits
+}
+
+func TestSearcherManager(t *testing.T) {
+	w, cleanup := newTestWriter(t)
+	defer cleanup()
+	addTestDocs(t, w, "a b", "a c")
+	if err := w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	warmed := 0
+	mgr, err := NewSearcherManager(w.Directory(), SearcherFactoryFunc(func(r index.IndexReader) (*IndexSearcher, error) {
+		warmed++
+		return NewIndexSearcher(r), nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1, err := mgr.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 2, countHits(t, s1, "a"))
+	current, err := mgr.IsSearcherCurrent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, true, current)
+
+	addTestDocs(t, w, "a d")
+	if err = w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if current, err = mgr.IsSearcherCurrent(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, false, current)
+	refreshed, err := mgr.MaybeRefresh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, true, refreshed)
+	assertEquals(t, 2, warmed)
+
+	s2, err := mgr.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s2 == s1 {
+		t.Fatal("Expected a new searcher after the refresh")
+	}
+	assertEquals(t, 3, countHits(t, s2, "a"))
+	// the old searcher is still usable until it is released
+	assertEquals(t, 2, countHits(t, s1, "a"))
+	if err = mgr.Release(s1); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, s1.IndexReader().RefCount())
+
+	// nothing changed
+	if err = mgr.MaybeRefreshBlocking(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 2, warmed)
+	s3, err := mgr.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, s2, s3)
+	mgr.Release(s3)
+
+	// the searcher in use is released by its last user
+	if err = mgr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = mgr.Acquire(); err == nil {
+		t.Error("Expected error acquiring a searcher after Close()")
+	}
+	if _, err = mgr.MaybeRefresh(); err == nil {
+		t.Error("Expected error refreshing after Close()")
+	}
+	assertEquals(t, 1, s2.IndexReader().RefCount())
+	if err = mgr.Release(s2); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, s2.IndexReader().RefCount())
+	if err = mgr.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearcherManagerNRT(t *testing.T) {
+	w, cleanup := newTestWriter(t)
+	defer cleanup()
+	addTestDocs(t, w, "a b")
+
+	mgr, err := NewSearcherManagerFromWriter(w, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Close()
+	s, err := mgr.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, countHits(t, s, "a"))
+	mgr.Release(s)
+
+	// the uncommitted documents are visible after a refresh
+	addTestDocs(t, w, "a c")
+	if _, err = mgr.MaybeRefresh(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = mgr.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 2, countHits(t, s, "a"))
+	mgr.Release(s)
+}
+
+func TestSearcherManagerFactory(t *testing.T) {
+	w, cleanup := newTestWriter(t)
+	defer cleanup()
+	addTestDocs(t, w, "a b")
+	if err := w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the factory must wrap the given reader
+	var given, other index.IndexReader
+	_, err := NewSearcherManager(w.Directory(), SearcherFactoryFunc(func(r index.IndexReader) (_ *IndexSearcher, err error) {
+		given = r
+		if other, err = index.OpenDirectoryReader(w.Directory()); err != nil {
+			t.Fatal(err)
+		}
+		return NewIndexSearcher(other), nil
+	}))
+	defer other.Close()
+	if err == nil {
+		t.Fatal("Expected error from a factory wrapping another reader")
+	}
+	// the reader is released on error
+	assertEquals(t, 0, given.RefCount())
+}
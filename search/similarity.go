@@ -0,0 +1,247 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+)
+
+// search/similarities/Similarity.java
+
+/*
+Similarity defines the components of Lucene scoring.
+
+Expert: Scoring API.
+
+This is a low-level API, you should only extend this API if you want
+to implement an information retrieval model. If you are instead
+looking for a convenient way to alter Lucene's scoring, consider
+tweaking the default implementation DefaultSimilarity.
+
+At indexing time, ComputeNorm() is called for each indexed field, to
+compute the normalization value stored in the index, see
+index.Similarity. It should match the Similarity of the IndexSearcher.
+
+At query-time, queries use the IndexSearcher.Similarity() to assign
+a SimWeight to each term, by ComputeWeight(), which is normalized by
+its query, and then score the documents of each segment with the
+ExactSimScorer() of the SimWeight.
+*/
+type Similarity interface {
+	index.Similarity
+	/*
+		Hook to integrate coordinate-level matching. By default this is
+		disabled (returns 1), as with most modern models this will only
+		skew performance, but some implementations such as
+		DefaultSimilarity override this.
+
+		overlap is the number of query terms matched in the document,
+		and maxOverlap the total number of terms in the query.
+	*/
+	Coord(overlap, maxOverlap int) float32
+	/*
+		Computes the normalization value for a query given the sum of the
+		normalized weights SimWeight.ValueForNormalization() of each of
+		the query terms. This value is passed back to the weight
+		(SimWeight.Normalize()) of each query term, to make scores from
+		different queries comparable.
+	*/
+	QueryNorm(valueForNormalization float32) float32
+	/*
+		Compute any collection-level weight (e.g. IDF, average document
+		length, etc) needed for scoring a query.
+
+		queryBoost is the query-time boost, collectionStats the
+		collection-level statistics, such as number of documents, and
+		termStats the term-level statistics, such as the document
+		frequency of a term across the whole collection.
+	*/
+	ComputeWeight(queryBoost float32, collectionStats CollectionStatistics,
+		termStats ...TermStatistics) SimWeight
+	/*
+		Creates a new ExactSimScorer to score matching documents from a
+		segment of the inverted index.
+	*/
+	ExactSimScorer(w SimWeight, ctx index.AtomicReaderContext) (ExactSimScorer, error)
+	/*
+		Creates a new SloppySimScorer to score matching documents from a
+		segment of the inverted index.
+	*/
+	SloppySimScorer(w SimWeight, ctx index.AtomicReaderContext) (SloppySimScorer, error)
+}
+
+// search/similarities/Similarity.java/ExactSimScorer
+
+/*
+API for scoring exact queries such as TermQuery and exact
+PhraseQuery.
+
+Frequencies are integers (the term or phrase frequency within the
+document).
+*/
+type ExactSimScorer interface {
+	// Score a single document, with freq the term frequency in the
+	// document.
+	Score(doc, freq int) float32
+	// Explain the score for a single document, whose frequency is
+	// explained by freq.
+	Explain(doc int, freq *Explanation) *Explanation
+}
+
+// search/similarities/Similarity.java/SloppySimScorer
+
+/*
+API for scoring "sloppy" queries such as sloppy PhraseQuery and
+MultiPhraseQuery.
+
+Frequencies are floating-point values: an approximate within-document
+frequency adjusted for "sloppiness" by ComputeSlopFactor().
+*/
+type SloppySimScorer interface {
+	// Score a single document, with freq the sloppy term frequency in
+	// the document.
+	Score(doc int, freq float32) float32
+	// Computes the amount of a sloppy phrase match, based on an edit
+	// distance.
+	ComputeSlopFactor(distance int) float32
+	// Calculate a scoring factor based on the data in the payload, of
+	// the match from start to end.
+	ComputePayloadFactor(doc, start, end int, payload []byte) float32
+	// Explain the score for a single document, whose sloppy frequency
+	// is explained by freq.
+	Explain(doc int, freq *Explanation) *Explanation
+}
+
+// search/similarities/Similarity.java/SimWeight
+
+// Stores the weight for a query across the indexed collection. This
+// abstract implementation is empty; descendants of Similarity should
+// subclass SimWeight and define the statistics they require.
+type SimWeight interface {
+	/*
+		The value for normalization of contained query clauses (e.g. sum
+		of squared weights).
+
+		NOTE: a Similarity implementation might not use any query
+		normalization at all, its not required. However, if it wants to
+		participate in query normalization, it can return a value here.
+	*/
+	ValueForNormalization() float32
+	/*
+		Assigns the query normalization factor and boost from parent
+		queries to this.
+
+		NOTE: a Similarity implementation might not use this normalized
+		value at all, its not required. However, its usually a good idea
+		to at least incorporate the topLevelBoost (e.g. from an outer
+		BooleanQuery) into its score.
+	*/
+	Normalize(queryNorm, topLevelBoost float32)
+}
+
+// search/similarities/DefaultSimilarity.java
+
+/*
+Expert: Default scoring implementation, the hooks of the classic
+vector space model of TFIDFSimilarity:
+
+  - tf(freq) = sqrt(freq)
+  - idf(docFreq, numDocs) = 1 + log(numDocs/(docFreq+1))
+  - lengthNorm = boost * 1/sqrt(numTerms), encoded in a single byte
+  - coord(overlap, maxOverlap) = overlap/maxOverlap
+  - queryNorm = 1/sqrt(sumOfSquaredWeights)
+
+To customize one of them, embed DefaultSimilarity in a type which
+overrides the hook, and wrap it with NewTFIDFSimilarity(), so the
+model calls the overriding hook.
+*/
+type DefaultSimilarity struct {
+	*TFIDFSimilarity
+	// if true, tokens with a position increment of zero are not
+	// counted in the field length
+	discountOverlaps bool
+}
+
+// Creates a DefaultSimilarity, which discounts overlapping tokens.
+func NewDefaultSimilarity() *DefaultSimilarity {
+	ans := &DefaultSimilarity{discountOverlaps: true}
+	ans.TFIDFSimilarity = NewTFIDFSimilarity(ans)
+	return ans
+}
+
+// Implemented as overlap / maxOverlap.
+func (ds *DefaultSimilarity) Coord(overlap, maxOverlap int) float32 {
+	return float32(overlap) / float32(maxOverlap)
+}
+
+// Implemented as 1/sqrt(sumOfSquaredWeights).
+func (ds *DefaultSimilarity) QueryNorm(sumOfSquaredWeights float32) float32 {
+	return float32(1.0 / math.Sqrt(float64(sumOfSquaredWeights)))
+}
+
+// Encodes a normalization factor for storage in an index, with
+// util.FloatToByte315().
+func (ds *DefaultSimilarity) EncodeNormValue(f float32) byte {
+	return util.FloatToByte315(f)
+}
+
+// Decodes a normalization factor stored in an index.
+func (ds *DefaultSimilarity) DecodeNormValue(b byte) float32 {
+	return util.Byte315ToFloat(b)
+}
+
+/*
+Implemented as state.Boost()*(1/sqrt(numTerms)), where numTerms is
+state.Length() if discountOverlaps is false, else it's
+state.Length() - state.NumOverlap().
+*/
+func (ds *DefaultSimilarity) LengthNorm(state *index.FieldInvertState) float32 {
+	numTerms := state.Length()
+	if ds.discountOverlaps {
+		numTerms -= state.NumOverlap()
+	}
+	return state.Boost() * float32(1/math.Sqrt(float64(numTerms)))
+}
+
+// Implemented as sqrt(freq).
+func (ds *DefaultSimilarity) Tf(freq float32) float32 {
+	return float32(math.Sqrt(float64(freq)))
+}
+
+// Implemented as 1 / (distance + 1).
+func (ds *DefaultSimilarity) SloppyFreq(distance int) float32 {
+	return 1.0 / float32(distance+1)
+}
+
+// The default implementation returns 1, i.e. payloads don't affect
+// the score by default.
+func (ds *DefaultSimilarity) ScorePayload(doc, start, end int, payload []byte) float32 {
+	return 1
+}
+
+// Implemented as log(numDocs/(docFreq+1)) + 1.
+func (ds *DefaultSimilarity) Idf(docFreq, numDocs int64) float32 {
+	return float32(math.Log(float64(numDocs)/float64(docFreq+1)) + 1.0)
+}
+
+/*
+Determines whether overlap tokens (Tokens with 0 position increment)
+are ignored when computing norm. By default this is true, meaning
+overlap tokens do not count when computing norms.
+
+NOTE: the norms are computed at indexing time, so the
+IndexWriterConfig must use the same setting.
+*/
+func (ds *DefaultSimilarity) SetDiscountOverlaps(v bool) {
+	ds.discountOverlaps = v
+}
+
+// Returns true if overlap tokens are discounted from the document's
+// length.
+func (ds *DefaultSimilarity) DiscountOverlaps() bool {
+	return ds.discountOverlaps
+}
+
+func (ds *DefaultSimilarity) String() string {
+	return "DefaultSimilarity"
+}
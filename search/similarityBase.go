@@ -0,0 +1,368 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+)
+
+// search/similarities/SimilarityBase.java
+
+/*
+The hook of SimilarityBase, implemented by the retrieval models which
+embed it.
+*/
+type similarityBase interface {
+	/*
+		Scores the document doc. Subclasses must apply their scoring
+		formula here.
+
+		stats are the corpus level statistics, freq the term frequency
+		and docLen the document length.
+	*/
+	Score(stats *BasicStats, freq, docLen float32) float32
+	// Subclasses must override this method to return the name of the
+	// Similarity and preferably the values of parameters (if any) as
+	// well.
+	String() string
+}
+
+/*
+The optional hook of SimilarityBase, implemented by the models which
+explain their scores in detail.
+*/
+type similarityBaseExplainer interface {
+	/*
+		Subclasses should implement this method to explain the score.
+		expl already contains the score, the name of the class and the
+		doc id, as well as the term frequency and its explanation;
+		subclasses add their own details.
+
+		stats are the corpus level statistics, doc the document id, freq
+		the term frequency and docLen the document length.
+	*/
+	Explain(expl *Explanation, stats *BasicStats, doc int, freq, docLen float32)
+}
+
+/*
+A subclass of Similarity that provides a simplified API for its
+descendants. Subclasses are only required to implement the Score()
+and String() methods.
+
+The documents are scored by the model, given the BasicStats of the
+term, its frequency in the document and the length of the document's
+field. The queries of several terms, like PhraseQuery, are scored as
+the sum of the scores of their terms, almost as if they were boolean
+queries. Coordination and query normalization are disabled.
+
+The length of the field is stored as its norm, which is the one of
+DefaultSimilarity, so an index built with the default
+IndexWriterConfig can be searched with any of the models.
+*/
+type SimilarityBase struct {
+	self similarityBase
+	// if true, tokens with a position increment of zero are not
+	// counted in the field length
+	discountOverlaps bool
+}
+
+// Creates a SimilarityBase scoring the documents by self.
+func NewSimilarityBase(self similarityBase) *SimilarityBase {
+	return &SimilarityBase{self, true}
+}
+
+/*
+Determines whether overlap tokens (Tokens with 0 position increment)
+are ignored when computing norm. By default this is true, meaning
+overlap tokens do not count when computing norms.
+*/
+func (s *SimilarityBase) SetDiscountOverlaps(v bool) {
+	s.discountOverlaps = v
+}
+
+// Returns true if overlap tokens are discounted from the document's
+// length.
+func (s *SimilarityBase) DiscountOverlaps() bool {
+	return s.discountOverlaps
+}
+
+// Coordination is disabled, i.e. returns 1.
+func (s *SimilarityBase) Coord(overlap, maxOverlap int) float32 {
+	return 1
+}
+
+// Query normalization is disabled, i.e. returns 1.
+func (s *SimilarityBase) QueryNorm(valueForNormalization float32) float32 {
+	return 1
+}
+
+// Fills all member fields defined in BasicStats in stats.
+func (s *SimilarityBase) fillBasicStats(stats *BasicStats,
+	collectionStats CollectionStatistics, termStats TermStatistics) {
+	// assert collectionStats.SumTotalTermFreq() == -1 || collectionStats.SumTotalTermFreq() >= termStats.TotalTermFreq; // #positions(field) must be >= #positions(term)
+	numberOfDocuments := collectionStats.MaxDoc()
+
+	docFreq := termStats.DocFreq
+	totalTermFreq := termStats.TotalTermFreq
+	// codec does not supply totalTermFreq: substitute docFreq
+	if totalTermFreq == -1 {
+		totalTermFreq = docFreq
+	}
+
+	numberOfFieldTokens := docFreq
+	avgFieldLength := float32(1)
+	if sumTotalTermFreq := collectionStats.SumTotalTermFreq(); sumTotalTermFreq > 0 {
+		numberOfFieldTokens = sumTotalTermFreq
+		avgFieldLength = float32(numberOfFieldTokens) / float32(numberOfDocuments)
+	} // else field does not exist, or stat is unsupported by codec
+
+	stats.numberOfDocuments = numberOfDocuments
+	stats.numberOfFieldTokens = numberOfFieldTokens
+	stats.avgFieldLength = avgFieldLength
+	stats.docFreq = docFreq
+	stats.totalTermFreq = totalTermFreq
+}
+
+func (s *SimilarityBase) ComputeWeight(queryBoost float32,
+	collectionStats CollectionStatistics, termStats ...TermStatistics) SimWeight {
+	stats := make(multiBasicStats, len(termStats))
+	for i, ts := range termStats {
+		stats[i] = NewBasicStats(collectionStats.Field(), queryBoost)
+		s.fillBasicStats(stats[i], collectionStats, ts)
+	}
+	if len(stats) == 1 {
+		return stats[0]
+	}
+	return stats
+}
+
+// Returns the stats of each term of the weight, which are several for
+// a multi term query, e.g. phrase.
+func subStats(w SimWeight) multiBasicStats {
+	if stats, ok := w.(*BasicStats); ok {
+		return multiBasicStats{stats}
+	}
+	return w.(multiBasicStats)
+}
+
+func (s *SimilarityBase) ExactSimScorer(w SimWeight, ctx index.AtomicReaderContext) (ExactSimScorer, error) {
+	stats := subStats(w)
+	norms, err := ctx.Reader().(index.AtomicReader).NormValues(stats[0].field)
+	if err != nil {
+		return nil, err
+	}
+	return &basicExactDocScorer{s, stats, norms}, nil
+}
+
+func (s *SimilarityBase) SloppySimScorer(w SimWeight, ctx index.AtomicReaderContext) (SloppySimScorer, error) {
+	stats := subStats(w)
+	norms, err := ctx.Reader().(index.AtomicReader).NormValues(stats[0].field)
+	if err != nil {
+		return nil, err
+	}
+	return &basicSloppyDocScorer{s, stats, norms}, nil
+}
+
+// Encodes the document length in the same way as DefaultSimilarity.
+func (s *SimilarityBase) ComputeNorm(state *index.FieldInvertState) int64 {
+	numTerms := state.Length()
+	if s.discountOverlaps {
+		numTerms -= state.NumOverlap()
+	}
+	return int64(int8(s.encodeNormValue(state.Boost(), numTerms)))
+}
+
+// Encodes the length to a byte via util.FloatToByte315().
+func (s *SimilarityBase) encodeNormValue(boost float32, length int) byte {
+	return util.FloatToByte315(boost / float32(math.Sqrt(float64(length))))
+}
+
+// Decodes a normalization factor (document length) stored in an index.
+func (s *SimilarityBase) decodeNormValue(norm byte) float32 {
+	return lengthNormTable[norm]
+}
+
+// Sums the scores of the terms of the document, whose field is decoded
+// from its norm, or is 1 without norms.
+func (s *SimilarityBase) score(stats multiBasicStats, norms index.NumericDocValues,
+	doc int, freq float32) float32 {
+	docLen := float32(1)
+	if norms != nil {
+		docLen = s.decodeNormValue(byte(norms.Get(doc)))
+	}
+	var sum float32
+	for _, st := range stats {
+		sum += s.self.Score(st, freq, docLen)
+	}
+	return sum
+}
+
+/*
+Explains the score. The implementation here provides a basic
+explanation in the format score(name-of-similarity, doc=doc-id,
+freq=term-frequency), computed from:, and attaches the score
+(computed via the Score() method) and the explanation for the term
+frequency. Models implementing the Explain() hook add their own
+details.
+*/
+func (s *SimilarityBase) explain(stats *BasicStats, doc int, freq *Explanation, docLen float32) *Explanation {
+	result := NewExplanation(s.self.Score(stats, freq.Value(), docLen),
+		fmt.Sprintf("score(%v, doc=%v, freq=%v), computed from:", simpleName(s.self), doc, freq.Value()))
+	result.AddDetail(freq)
+	if explainer, ok := s.self.(similarityBaseExplainer); ok {
+		explainer.Explain(result, stats, doc, freq.Value(), docLen)
+	}
+	return result
+}
+
+// Explains the score of the document, as the sum of the scores of the
+// terms, if several.
+func (s *SimilarityBase) explainScore(stats multiBasicStats, norms index.NumericDocValues,
+	doc int, freq *Explanation) *Explanation {
+	docLen := float32(1)
+	if norms != nil {
+		docLen = s.decodeNormValue(byte(norms.Get(doc)))
+	}
+	if len(stats) == 1 {
+		return s.explain(stats[0], doc, freq, docLen)
+	}
+	// a multi term query (e.g. phrase). return the summation, scoring
+	// almost as if it were boolean query
+	result := NewExplanation(s.score(stats, norms, doc, freq.Value()), "sum of:")
+	for _, st := range stats {
+		result.AddDetail(s.explain(st, doc, freq, docLen))
+	}
+	return result
+}
+
+// Returns the base two logarithm of x.
+func log2(x float64) float64 {
+	// Put this to a 'util' class if we need more of these.
+	return math.Log(x) / math.Ln2
+}
+
+// search/similarities/SimilarityBase.java/BasicExactDocScorer
+
+type basicExactDocScorer struct {
+	sim   *SimilarityBase
+	stats multiBasicStats
+	norms index.NumericDocValues
+}
+
+func (s *basicExactDocScorer) Score(doc, freq int) float32 {
+	// We have to supply something in case norms are omitted
+	return s.sim.score(s.stats, s.norms, doc, float32(freq))
+}
+
+func (s *basicExactDocScorer) Explain(doc int, freq *Explanation) *Explanation {
+	return s.sim.explainScore(s.stats, s.norms, doc, freq)
+}
+
+// search/similarities/SimilarityBase.java/BasicSloppyDocScorer
+
+type basicSloppyDocScorer struct {
+	sim   *SimilarityBase
+	stats multiBasicStats
+	norms index.NumericDocValues
+}
+
+func (s *basicSloppyDocScorer) Score(doc int, freq float32) float32 {
+	// We have to supply something in case norms are omitted
+	return s.sim.score(s.stats, s.norms, doc, freq)
+}
+
+func (s *basicSloppyDocScorer) ComputeSlopFactor(distance int) float32 {
+	return 1.0 / float32(distance+1)
+}
+
+func (s *basicSloppyDocScorer) ComputePayloadFactor(doc, start, end int, payload []byte) float32 {
+	return 1
+}
+
+func (s *basicSloppyDocScorer) Explain(doc int, freq *Explanation) *Explanation {
+	return s.sim.explainScore(s.stats, s.norms, doc, freq)
+}
+
+// search/similarities/BasicStats.java
+
+// Stores all statistics commonly used ranking methods.
+type BasicStats struct {
+	field string
+	// The number of documents.
+	numberOfDocuments int64
+	// The total number of tokens in the field.
+	numberOfFieldTokens int64
+	// The average field length.
+	avgFieldLength float32
+	// The document frequency.
+	docFreq int64
+	// The total number of occurrences of this term across all documents.
+	totalTermFreq int64
+
+	// Query's inner boost.
+	queryBoost float32
+	// Any outer query's boost.
+	topLevelBoost float32
+	// For most Similarities, the immediate and the top level query
+	// boosts are not handled differently. Hence, this field is just the
+	// product of the other two.
+	totalBoost float32
+}
+
+// Constructor. Sets the query boost.
+func NewBasicStats(field string, queryBoost float32) *BasicStats {
+	return &BasicStats{field: field, queryBoost: queryBoost, totalBoost: queryBoost}
+}
+
+// Returns the number of documents.
+func (s *BasicStats) NumberOfDocuments() int64 { return s.numberOfDocuments }
+
+// Returns the total number of tokens in the field.
+func (s *BasicStats) NumberOfFieldTokens() int64 { return s.numberOfFieldTokens }
+
+// Returns the average field length.
+func (s *BasicStats) AvgFieldLength() float32 { return s.avgFieldLength }
+
+// Returns the document frequency.
+func (s *BasicStats) DocFreq() int64 { return s.docFreq }
+
+// Returns the total number of occurrences of this term across all
+// documents.
+func (s *BasicStats) TotalTermFreq() int64 { return s.totalTermFreq }
+
+// Returns the total boost.
+func (s *BasicStats) TotalBoost() float32 { return s.totalBoost }
+
+// The square of the raw normalization value, the query boost.
+func (s *BasicStats) ValueForNormalization() float32 {
+	return s.queryBoost * s.queryBoost
+}
+
+/*
+No normalization is done. topLevelBoost is saved in the object,
+however.
+*/
+func (s *BasicStats) Normalize(queryNorm, topLevelBoost float32) {
+	s.topLevelBoost = topLevelBoost
+	s.totalBoost = s.queryBoost * topLevelBoost
+}
+
+// search/similarities/MultiSimilarity.java/MultiStats
+
+// The stats of the terms of a multi term query, e.g. phrase.
+type multiBasicStats []*BasicStats
+
+func (s multiBasicStats) ValueForNormalization() float32 {
+	var sum float32
+	for _, stat := range s {
+		sum += stat.ValueForNormalization()
+	}
+	return sum
+}
+
+func (s multiBasicStats) Normalize(queryNorm, topLevelBoost float32) {
+	for _, stat := range s {
+		stat.Normalize(queryNorm, topLevelBoost)
+	}
+}
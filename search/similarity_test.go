@@ -0,0 +1,107 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDefaultSimilarity(t *testing.T) {
+	sim := NewDefaultSimilarity()
+	assertEquals(t, float32(2), sim.Tf(4))
+	assertEquals(t, float32(1), sim.Idf(9, 10))
+	assertEquals(t, float32(0.5), sim.QueryNorm(4))
+	assertEquals(t, float32(0.75), sim.Coord(3, 4))
+	assertEquals(t, float32(0.25), sim.SloppyFreq(3))
+	assertEquals(t, float32(0.5), sim.DecodeNormValue(sim.EncodeNormValue(0.5)))
+	assertEquals(t, true, sim.DiscountOverlaps())
+	assertEquals(t, "DefaultSimilarity", fmt.Sprint(sim))
+}
+
+// Ignores the term frequencies.
+type constantTfSimilarity struct {
+	*DefaultSimilarity
+}
+
+func (s *constantTfSimilarity) Tf(freq float32) float32 {
+	return 1
+}
+
+// Returns the docs and the scores of the top hits of q.
+func scoredHits(t *testing.T, ss *IndexSearcher, q Query) string {
+	docs, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits := make([]string, len(docs.ScoreDocs))
+	for i, hit := range docs.ScoreDocs {
+		hits[i] = fmt.Sprintf("%v:%v", hit.Doc, hit.Score)
+	}
+	return fmt.Sprint(hits)
+}
+
+func TestTFIDFSimilarity(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b b", "a a a", "b c")
+	defer cleanup()
+
+	// tf * norm, as the query weight and idf cancel out
+	q := newBodyTermQuery("a")
+	assertEquals(t, "[1:0.8660254 0:0.5]", scoredHits(t, ss, q))
+
+	ss.SetSimilarity(NewTFIDFSimilarity(&constantTfSimilarity{NewDefaultSimilarity()}))
+	assertEquals(t, "[0:0.5 1:0.5]", scoredHits(t, ss, q))
+}
+
+func TestBM25Similarity(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b b", "a a a", "b c", "a")
+	defer cleanup()
+
+	sim := NewBM25Similarity()
+	assertEquals(t, "BM25(k1=1.2,b=0.75)", fmt.Sprint(sim))
+	assertEquals(t, float32(1), sim.Coord(1, 2))
+	ss.SetSimilarity(sim)
+	// idf = log(1 + 1.5/3.5), avgdl = 9/4, the norms decode to 1 and 4
+	assertEquals(t, "[1:0.4804193 3:0.46157935 0:0.27058098]", scoredHits(t, ss, newBodyTermQuery("a")))
+
+	// with b = 0, the field lengths are ignored
+	ss.SetSimilarity(NewBM25SimilarityWithParams(1.2, 0))
+	assertEquals(t, "[1:0.56048924 0:0.35667494 3:0.35667494]", scoredHits(t, ss, newBodyTermQuery("a")))
+}
+
+func TestSimilarityBase(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b b", "a a a", "b c", "a")
+	defer cleanup()
+
+	// p(a|C) = 6/10, the norms decode to lengths 4, 4, 4 and 1
+	q := newBodyTermQuery("a")
+	for _, v := range []struct {
+		sim  Similarity
+		name string
+		hits string
+	}{
+		{NewDFRSimilarity(NewBasicModelIn(), NewAfterEffectB(), NewNormalizationH2(1)), "DFR I(n)B2", "[1:0.59332854 3:0.5670377 0:0.3527039]"},
+		{NewDFRSimilarity(NewBasicModelBE(), NewAfterEffectL(), NewNormalizationH1(1)), "DFR BeL1", "[3:1.0961978 1:1.0527723 0:0.9057048]"},
+		{NewDFRSimilarity(NewBasicModelG(), NewNoAfterEffect(), NewNoNormalization()), "DFR G", "[1:4.9231844 0:2.0931094 3:2.0931094]"},
+		{NewDFRSimilarity(NewBasicModelP(), NewAfterEffectL(), NewNormalizationH3(800)), "DFR PL3(800)", "[1:7.2035956 3:7.2030034 0:7.197618]"},
+		{NewDFRSimilarity(NewBasicModelD(), NewAfterEffectL(), NewNormalizationZ(0.3)), "DFR DLZ(0.3)", "[0:0.78890616 3:0.6524081 1:0.6025219]"},
+		{NewDFRSimilarity(NewBasicModelIF(), NewAfterEffectB(), NewNormalizationH2(1)), "DFR I(F)B2", "[1:1.0756639 3:1.0280005 0:0.63942796]"},
+		{NewDFRSimilarity(NewBasicModelIne(), NewAfterEffectB(), NewNormalizationH2(1)), "DFR I(ne)B2", "[1:0.5693664 3:0.5441373 0:0.3384596]"},
+		{NewIBSimilarity(NewDistributionLL(), NewLambdaDF(), NewNormalizationH2(1)), "IB LL-D2", "[1:1.2280196 3:1.1396102 0:0.590461]"},
+		{NewIBSimilarity(NewDistributionSPL(), NewLambdaTTF(), NewNormalizationH2(1)), "IB SPL-L2", "[1:1.0166965 3:0.9372063 0:0.46221164]"},
+		{NewLMDirichletSimilarity(), "LM Dirichlet(2000.000000)", "[1:0.0004989048 3:0.00033313606 0:0]"},
+		{NewLMJelinekMercerSimilarity(nil, 0.7), "LM Jelinek-Mercer(0.700000)", "[3:0.5389965 1:0.4289956 0:0.16430305]"},
+	} {
+		assertEquals(t, v.name, fmt.Sprint(v.sim))
+		ss.SetSimilarity(v.sim)
+		assertEquals(t, v.hits, scoredHits(t, ss, q))
+	}
+}
+
+func TestSimilarityBasePhrase(t *testing.T) {
+	ss, cleanup := newBelfrySearcher(t)
+	defer cleanup()
+
+	// the phrase is scored as the sum of its terms
+	ss.SetSimilarity(NewDFRSimilarity(NewBasicModelIn(), NewAfterEffectL(), NewNormalizationH2(1)))
+	assertEquals(t, "map[0:2 1:2 2:1]", phraseFreqs(t, ss, newContentPhraseQuery(0, "fruit", "bat")))
+	assertEquals(t, "map[0:8 1:8 2:9 6:1 7:2]", phraseFreqs(t, ss, newContentPhraseQuery(1, "fruit|new|your", "bat")))
+}
@@ -0,0 +1,213 @@
+package search
+
+import (
+	"bytes"
+	"container/heap"
+	"github.com/balzaczyy/golucene/index"
+)
+
+// search/SloppyPhraseScorer.java
+
+/*
+Scorer for phrase queries whose terms may appear within a distance,
+the slop, of their positions in the phrase. Each match contributes to
+the phrase frequency by the slop factor of its distance, so closer
+matches score higher.
+*/
+type SloppyPhraseScorer struct {
+	*PhraseScorer
+	docScorer SloppySimScorer
+	slop      int
+	// the phrasePositions sharing a term with another one, or nil
+	repeats []*phrasePositions
+	tmpPos  []*phrasePositions // for flipping repeating pps.
+}
+
+func newSloppyPhraseScorer(w Weight, postings []*postingsAndFreq, slop int,
+	docScorer SloppySimScorer) *SloppyPhraseScorer {
+	ans := &SloppyPhraseScorer{docScorer: docScorer, slop: slop}
+	ans.PhraseScorer = newPhraseScorer(ans, ans, w, postings)
+	// phrase positions sharing a term, e.g. query="1st word 2nd
+	// word"~1, would match at the same term position, so they are
+	// kept apart when matching.
+	for pp := ans.first; pp != nil; pp = pp.next {
+		for pp2 := pp.next; pp2 != nil; pp2 = pp2.next {
+			if shareTerm(pp.terms, pp2.terms) {
+				if !pp.repeats {
+					pp.repeats = true
+					ans.repeats = append(ans.repeats, pp)
+				}
+				if !pp2.repeats {
+					pp2.repeats = true
+					ans.repeats = append(ans.repeats, pp2)
+				}
+			}
+		}
+	}
+	if ans.repeats != nil {
+		ans.tmpPos = make([]*phrasePositions, 0, len(postings))
+	}
+	return ans
+}
+
+// Returns true if the two sets of terms have a term in common.
+func shareTerm(terms1, terms2 []index.Term) bool {
+	for _, t1 := range terms1 {
+		for _, t2 := range terms2 {
+			if t1.Field == t2.Field && bytes.Equal(t1.Bytes, t2.Bytes) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+Score a candidate doc for all slop-valid position-combinations
+(matches) encountered while traversing/hopping the phrasePositions.
+
+The score contribution of a match depends on the distance:
+  - highest score for distance=0 (exact match).
+  - score gets lower as distance gets higher.
+
+Example: for query "a b"~2, a document "x a b a y" can be scored
+twice: once for "a b" (distance=0), and once for "b a" (distance=2).
+
+Possibly not all valid combinations are encountered, because for
+efficiency we always propagate the least phrasePositions. This allows
+to base on a queue and move forward faster. As result, for example,
+document "a b c b a" would score differently for queries "a b c"~4
+and "c b a"~4, although they really are equivalent. Similarly, for
+doc "a b c b a f g", query "c b"~2 would get same score as "g f"~2,
+although "c b"~2 could be matched twice. We may want to fix this in
+the future (currently not, for performance reasons).
+*/
+func (s *SloppyPhraseScorer) phraseFreq() (float32, int) {
+	end, ok := s.initPhrasePositions()
+	var freq float32
+	matches := 0
+	for done := !ok; !done; {
+		pp := heap.Pop(s.pq).(*phrasePositions)
+		start := pp.position
+		next := (*s.pq)[0].position
+		tpsDiffer := true
+		for pos := start; pos <= next || !tpsDiffer; pos = pp.position {
+			if pos <= next && tpsDiffer {
+				start = pos // advance pp to min window
+			}
+			if !pp.nextPosition() {
+				done = true // ran out of a term -- done
+				break
+			}
+			var pp2 *phrasePositions
+			if pp.repeats {
+				pp2 = s.termPositionsDiffer(pp)
+			}
+			tpsDiffer = pp2 == nil
+			if pp2 != nil && pp2 != pp {
+				pp = s.flip(pp, pp2) // flip pp to pp2
+			}
+		}
+		if matchLength := end - start; matchLength <= s.slop {
+			freq += s.docScorer.ComputeSlopFactor(matchLength) // score match
+			matches++
+		}
+		if pp.position > end {
+			end = pp.position
+		}
+		heap.Push(s.pq, pp) // restore pq
+	}
+	return freq, matches
+}
+
+// flip pp2 and pp in the queue: pop until finding pp2, insert back
+// all but pp2, insert pp back. assumes: pp!=pp2, pp2 in pq, pp not in
+// pq. called only when there are repeating pps.
+func (s *SloppyPhraseScorer) flip(pp, pp2 *phrasePositions) *phrasePositions {
+	s.tmpPos = s.tmpPos[:0]
+	for {
+		pp3 := heap.Pop(s.pq).(*phrasePositions)
+		if pp3 == pp2 {
+			break
+		}
+		s.tmpPos = append(s.tmpPos, pp3)
+	}
+	// insert back all but pp2
+	for _, pp3 := range s.tmpPos {
+		heap.Push(s.pq, pp3)
+	}
+	// insert pp back
+	heap.Push(s.pq, pp)
+	return pp2
+}
+
+/*
+Init phrasePositions in place. There is a one time initialization for
+this scorer:
+  - Put in repeats the pps which share a term with another one.
+
+Positions are checked for each doc:
+  - Advance some repeating pps so that no two pps are on the same
+    term position.
+  - Compute the max phrase position of the pps.
+  - Build the queue from the list of pps.
+
+Returns the max phrase position, and false if the pps ran out of
+positions, i.e. there is no match.
+*/
+func (s *SloppyPhraseScorer) initPhrasePositions() (int, bool) {
+	end := 0
+	// position the pp's
+	for pp := s.first; pp != nil; pp = pp.next {
+		pp.firstPosition()
+	}
+	// with repeats must advance some repeating pp's so they all start
+	// with differing tp's
+	for _, pp := range s.repeats {
+		for pp2 := s.termPositionsDiffer(pp); pp2 != nil; pp2 = s.termPositionsDiffer(pp) {
+			// out of pps that do not differ, advance the pp with higher
+			// offset
+			if !pp2.nextPosition() {
+				return -1, false // ran out of a term -- done
+			}
+		}
+	}
+	// build queue from list
+	*s.pq = (*s.pq)[:0]
+	for pp := s.first; pp != nil; pp = pp.next {
+		if pp.position > end {
+			end = pp.position
+		}
+		heap.Push(s.pq, pp)
+	}
+	return end, true
+}
+
+/*
+We disallow two pp's to have the same term position, as otherwise the
+matching would match a repeated term twice, e.g. in query "a b a"~2
+the first and the last "a" both matching the single "a" in a
+document. Returns nil if pp differs from all repeating pps, or else
+the one with the higher offset of pp and the first repeating pp it
+does not differ from.
+*/
+func (s *SloppyPhraseScorer) termPositionsDiffer(pp *phrasePositions) *phrasePositions {
+	tpPos := pp.position + pp.offset
+	for _, pp2 := range s.repeats {
+		if pp2 == pp {
+			continue
+		}
+		if tpPos2 := pp2.position + pp2.offset; tpPos2 == tpPos {
+			// do not differ: return the one with higher offset.
+			if pp.offset > pp2.offset {
+				return pp
+			}
+			return pp2
+		}
+	}
+	return nil
+}
+
+func (s *SloppyPhraseScorer) Score() float32 {
+	return s.docScorer.Score(s.first.doc, s.freq)
+}
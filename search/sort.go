@@ -0,0 +1,327 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// search/Sort.java
+
+/*
+Encapsulates sort criteria for returned hits.
+
+The fields used to determine sort order must be carefully chosen.
+Documents must contain a single term in such a field, and the value
+of the term should indicate the document's relative position in a
+given sort order. The field must be indexed, but should not be
+tokenized, and does not need to be stored (unless you happen to want
+it back with the rest of your document data).
+
+Sorting on a numeric field reads the NumericDocValues of the field,
+while sorting on a string field reads its SortedDocValues, and on a
+multi-valued one its SortedSetDocValues. Without doc values, the
+indexed terms are un-inverted by the FieldCache.
+
+A Sort can be reused across searches; the comparators of its fields
+are created per search.
+*/
+type Sort struct {
+	// internal representation of the sort criteria
+	fields []*SortField
+}
+
+/*
+Represents sorting by computed relevance. Using this sort criteria
+returns the same results as calling IndexSearcher.Search() without a
+sort criteria, only with slightly more overhead.
+*/
+var SORT_RELEVANCE = NewSort()
+
+// Represents sorting by index order.
+var SORT_INDEXORDER = NewSort(SORT_FIELD_DOC)
+
+/*
+Sets the sort to the given criteria in succession: the first
+SortField is checked first, but if it produces a tie, then the
+second SortField is used to break the tie, etc. Finally, if there is
+still a tie after all SortFields are checked, the internal Lucene
+docid is used to break it.
+
+Without any field, sorts by computed relevance.
+*/
+func NewSort(fields ...*SortField) *Sort {
+	if len(fields) == 0 {
+		fields = []*SortField{SORT_FIELD_SCORE}
+	}
+	return &Sort{fields}
+}
+
+// Representation of the sort criteria.
+func (s *Sort) SortFields() []*SortField {
+	return s.fields
+}
+
+// Returns true if the relevance score is needed to sort documents.
+func (s *Sort) NeedsScores() bool {
+	for _, field := range s.fields {
+		if field.NeedsScores() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Sort) String() string {
+	var buf bytes.Buffer
+	for i, field := range s.fields {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(field.String())
+	}
+	return buf.String()
+}
+
+// search/SortField.java/Type
+
+// Specifies the type of the terms to be sorted, or special types such
+// as relevance or document index order.
+type SortFieldType int
+
+const (
+	// Sort by document score (relevance). Sort values are float32 and
+	// higher values are at the front.
+	SORT_FIELD_TYPE_SCORE = SortFieldType(iota)
+	// Sort by document number (index order). Sort values are int and
+	// lower values are at the front.
+	SORT_FIELD_TYPE_DOC
+	// Sort using term values as Strings. Sort values are []byte and
+	// lower values are at the front.
+	SORT_FIELD_TYPE_STRING
+	// Sort using term values as encoded Integers. Sort values are
+	// int32 and lower values are at the front.
+	SORT_FIELD_TYPE_INT
+	// Sort using term values as encoded Floats. Sort values are float32
+	// and lower values are at the front.
+	SORT_FIELD_TYPE_FLOAT
+	// Sort using term values as encoded Longs. Sort values are int64
+	// and lower values are at the front.
+	SORT_FIELD_TYPE_LONG
+	// Sort using term values as encoded Doubles. Sort values are
+	// float64 and lower values are at the front.
+	SORT_FIELD_TYPE_DOUBLE
+	// Sort using a custom FieldComparator. Sort values are any
+	// comparable and sorting is done according to natural order.
+	SORT_FIELD_TYPE_CUSTOM
+	// Sort using a term, selected among the values of a multi-valued
+	// field, see NewSortedSetSortField(). Sort values are []byte and
+	// lower values are at the front.
+	SORT_FIELD_TYPE_SORTED_SET
+)
+
+// search/SortField.java
+
+/*
+Stores information about how to sort documents by terms in an
+individual field. Fields must be indexed in order to sort by them.
+*/
+type SortField struct {
+	field   string
+	typ     SortFieldType
+	reverse bool // defaults to natural order
+	// used for CUSTOM sort
+	comparatorSource FieldComparatorSource
+	// used for SORTED_SET sort
+	selector SortedSetSelector
+	// the value of the documents without any value, or nil
+	missingValue interface{}
+}
+
+// Represents sorting by document score (relevance).
+var SORT_FIELD_SCORE = NewSortField("", SORT_FIELD_TYPE_SCORE, false)
+
+// Represents sorting by document number (index order).
+var SORT_FIELD_DOC = NewSortField("", SORT_FIELD_TYPE_DOC, false)
+
+/*
+Creates a sort, possibly in reverse, by terms in the given field with
+the type of term values explicitly given. field may be "" if typ is
+SCORE or DOC.
+*/
+func NewSortField(field string, typ SortFieldType, reverse bool) *SortField {
+	if field == "" && typ != SORT_FIELD_TYPE_SCORE && typ != SORT_FIELD_TYPE_DOC {
+		panic("field can only be empty when type is SCORE or DOC")
+	}
+	if typ == SORT_FIELD_TYPE_CUSTOM {
+		panic("use NewCustomSortField() for a CUSTOM sort")
+	} else if typ == SORT_FIELD_TYPE_SORTED_SET {
+		panic("use NewSortedSetSortField() for a SORTED_SET sort")
+	}
+	return &SortField{field: field, typ: typ, reverse: reverse}
+}
+
+/*
+Creates a sort, possibly in reverse, with a custom comparison
+function.
+*/
+func NewCustomSortField(field string, comparator FieldComparatorSource, reverse bool) *SortField {
+	if field == "" {
+		panic("field can only be empty when type is SCORE or DOC")
+	}
+	return &SortField{
+		field:            field,
+		typ:              SORT_FIELD_TYPE_CUSTOM,
+		reverse:          reverse,
+		comparatorSource: comparator,
+	}
+}
+
+/*
+Pass this to SetMissingValue() to have missing string values sort
+first.
+*/
+var SORT_FIELD_STRING_FIRST = &stringMissingValue{"SortField.STRING_FIRST"}
+
+/*
+Pass this to SetMissingValue() to have missing string values sort
+last.
+*/
+var SORT_FIELD_STRING_LAST = &stringMissingValue{"SortField.STRING_LAST"}
+
+type stringMissingValue struct{ name string }
+
+func (v *stringMissingValue) String() string { return v.name }
+
+/*
+Sets the value of the documents which have no value in the field, to
+sort them with. By default they sort as 0, or first for STRING. The
+value must be of the sort values' type, e.g. float32 for FLOAT, or
+SORT_FIELD_STRING_FIRST or SORT_FIELD_STRING_LAST for STRING and
+SORTED_SET.
+*/
+func (f *SortField) SetMissingValue(missingValue interface{}) {
+	var ok bool
+	switch f.typ {
+	case SORT_FIELD_TYPE_STRING, SORT_FIELD_TYPE_SORTED_SET:
+		ok = missingValue == SORT_FIELD_STRING_FIRST || missingValue == SORT_FIELD_STRING_LAST
+	case SORT_FIELD_TYPE_INT:
+		_, ok = missingValue.(int32)
+	case SORT_FIELD_TYPE_FLOAT:
+		_, ok = missingValue.(float32)
+	case SORT_FIELD_TYPE_LONG:
+		_, ok = missingValue.(int64)
+	case SORT_FIELD_TYPE_DOUBLE:
+		_, ok = missingValue.(float64)
+	default:
+		panic(fmt.Sprintf("Missing value only works for numeric, STRING or SORTED_SET types, not %v", f.typ))
+	}
+	if !ok {
+		panic(fmt.Sprintf("Illegal missing value %v (%T) for type %v", missingValue, missingValue, f.typ))
+	}
+	f.missingValue = missingValue
+}
+
+// Returns the value of the documents which have no value, or nil for
+// the default.
+func (f *SortField) MissingValue() interface{} {
+	return f.missingValue
+}
+
+// Returns the name of the field. Could return "" if the sort is by
+// SCORE or DOC.
+func (f *SortField) Field() string {
+	return f.field
+}
+
+// Returns the type of contents in the field.
+func (f *SortField) Type() SortFieldType {
+	return f.typ
+}
+
+// Returns whether the sort should be reversed.
+func (f *SortField) Reverse() bool {
+	return f.reverse
+}
+
+// Returns the FieldComparatorSource used for custom sorting, or nil.
+func (f *SortField) ComparatorSource() FieldComparatorSource {
+	return f.comparatorSource
+}
+
+// Whether the relevance score is needed to sort documents.
+func (f *SortField) NeedsScores() bool {
+	return f.typ == SORT_FIELD_TYPE_SCORE
+}
+
+func (f *SortField) String() string {
+	var s string
+	switch f.typ {
+	case SORT_FIELD_TYPE_SCORE:
+		s = "<score>"
+	case SORT_FIELD_TYPE_DOC:
+		s = "<doc>"
+	case SORT_FIELD_TYPE_STRING:
+		s = fmt.Sprintf(`<string: "%v">`, f.field)
+	case SORT_FIELD_TYPE_INT:
+		s = fmt.Sprintf(`<int: "%v">`, f.field)
+	case SORT_FIELD_TYPE_FLOAT:
+		s = fmt.Sprintf(`<float: "%v">`, f.field)
+	case SORT_FIELD_TYPE_LONG:
+		s = fmt.Sprintf(`<long: "%v">`, f.field)
+	case SORT_FIELD_TYPE_DOUBLE:
+		s = fmt.Sprintf(`<double: "%v">`, f.field)
+	case SORT_FIELD_TYPE_CUSTOM:
+		s = fmt.Sprintf(`<custom: "%v": %v>`, f.field, f.comparatorSource)
+	case SORT_FIELD_TYPE_SORTED_SET:
+		s = fmt.Sprintf(`<sortedset: "%v">`, f.field)
+	default:
+		s = fmt.Sprintf(`<???: "%v">`, f.field)
+	}
+	if f.reverse {
+		s += "!"
+	}
+	if f.missingValue != nil {
+		s += fmt.Sprintf(" missingValue=%v", f.missingValue)
+	}
+	if f.typ == SORT_FIELD_TYPE_SORTED_SET {
+		s += fmt.Sprintf(" selector=%v", f.selector)
+	}
+	return s
+}
+
+/*
+Returns the FieldComparator to use for sorting.
+
+numHits is the number of top hits the queue will store, and sortPos
+the position of this SortField within Sort. The comparator is
+primary if sortPos==0, secondary if sortPos==1, etc. Some comparators
+can optimize themselves when they are the primary sort.
+*/
+func (f *SortField) Comparator(numHits, sortPos int) FieldComparator {
+	switch f.typ {
+	case SORT_FIELD_TYPE_SCORE:
+		return newRelevanceComparator(numHits)
+	case SORT_FIELD_TYPE_DOC:
+		return newDocComparator(numHits)
+	case SORT_FIELD_TYPE_INT:
+		missingValue, _ := f.missingValue.(int32)
+		return newIntComparator(numHits, f.field, f.missingValue != nil, missingValue)
+	case SORT_FIELD_TYPE_FLOAT:
+		missingValue, _ := f.missingValue.(float32)
+		return newFloatComparator(numHits, f.field, f.missingValue != nil, missingValue)
+	case SORT_FIELD_TYPE_LONG:
+		missingValue, _ := f.missingValue.(int64)
+		return newLongComparator(numHits, f.field, f.missingValue != nil, missingValue)
+	case SORT_FIELD_TYPE_DOUBLE:
+		missingValue, _ := f.missingValue.(float64)
+		return newDoubleComparator(numHits, f.field, f.missingValue != nil, missingValue)
+	case SORT_FIELD_TYPE_CUSTOM:
+		return f.comparatorSource.NewComparator(f.field, numHits, sortPos, f.reverse)
+	case SORT_FIELD_TYPE_STRING:
+		return newTermOrdValComparator(numHits, f.field, f.missingValue == SORT_FIELD_STRING_LAST)
+	case SORT_FIELD_TYPE_SORTED_SET:
+		return newSortedSetComparator(numHits, f.field, f.missingValue == SORT_FIELD_STRING_LAST, f.selector)
+	default:
+		panic(fmt.Sprintf("Illegal sort type: %v", f.typ))
+	}
+}
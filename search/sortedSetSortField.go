@@ -0,0 +1,133 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+)
+
+// search/SortedSetSortField.java/Selector
+
+// Selects a value from the document's set to use as the sort value.
+type SortedSetSelector int
+
+const (
+	// Selects the minimum value in the set
+	SORTED_SET_SELECTOR_MIN = SortedSetSelector(iota)
+	// Selects the maximum value in the set
+	SORTED_SET_SELECTOR_MAX
+)
+
+func (s SortedSetSelector) String() string {
+	switch s {
+	case SORTED_SET_SELECTOR_MIN:
+		return "MIN"
+	case SORTED_SET_SELECTOR_MAX:
+		return "MAX"
+	}
+	return "???"
+}
+
+// search/SortedSetSortField.java
+
+/*
+Creates a SortField for a multi-valued field, by selecting a value
+from the set of values of each document. This is typically very fast
+with SortedSetDocValues, though the terms of an indexed field are
+also un-inverted by FieldCache.DocTermOrds().
+
+By default, the minimum value in the set is selected as the sort
+value, but this can be customized with the selector, e.g. to sort the
+documents by their maximum value. Documents without any value sort
+first, unless SORT_FIELD_STRING_LAST is set as the missing value.
+*/
+func NewSortedSetSortField(field string, reverse bool, selector SortedSetSelector) *SortField {
+	if field == "" {
+		panic("field can only be empty when type is SCORE or DOC")
+	}
+	if selector != SORTED_SET_SELECTOR_MIN && selector != SORTED_SET_SELECTOR_MAX {
+		panic("invalid selector")
+	}
+	return &SortField{
+		field:    field,
+		typ:      SORT_FIELD_TYPE_SORTED_SET,
+		reverse:  reverse,
+		selector: selector,
+	}
+}
+
+// Returns the selector in use for this sort
+func (f *SortField) Selector() SortedSetSelector {
+	return f.selector
+}
+
+// Returns a termOrdValComparator over the values selected among the
+// sets of values of the documents.
+func newSortedSetComparator(numHits int, field string, missingLast bool,
+	selector SortedSetSelector) *termOrdValComparator {
+
+	ans := newTermOrdValComparator(numHits, field, missingLast)
+	ans.sortedDocValues = func(reader index.AtomicReader, field string) (index.SortedDocValues, error) {
+		sortedSet, err := FIELD_CACHE_DEFAULT.DocTermOrds(reader, field)
+		if err != nil {
+			return nil, err
+		}
+		if selector == SORTED_SET_SELECTOR_MAX {
+			return &maxSortedSetValue{sortedSet}, nil
+		}
+		return &minSortedSetValue{sortedSet}, nil
+	}
+	return ans
+}
+
+// Wraps a SortedSetDocValues and returns the first ordinal (min)
+type minSortedSetValue struct {
+	in index.SortedSetDocValues
+}
+
+func (v *minSortedSetValue) Ord(docID int) int {
+	v.in.SetDocument(docID)
+	return int(v.in.NextOrd())
+}
+
+func (v *minSortedSetValue) Get(docID int) []byte {
+	if ord := v.Ord(docID); ord != -1 {
+		return v.LookupOrd(ord)
+	}
+	return nil
+}
+
+func (v *minSortedSetValue) LookupOrd(ord int) []byte {
+	return v.in.LookupOrd(int64(ord))
+}
+
+func (v *minSortedSetValue) ValueCount() int {
+	return int(v.in.ValueCount())
+}
+
+// Wraps a SortedSetDocValues and returns the last ordinal (max)
+type maxSortedSetValue struct {
+	in index.SortedSetDocValues
+}
+
+func (v *maxSortedSetValue) Ord(docID int) int {
+	v.in.SetDocument(docID)
+	maxOrd := int64(index.NO_MORE_ORDS)
+	for ord := v.in.NextOrd(); ord != index.NO_MORE_ORDS; ord = v.in.NextOrd() {
+		maxOrd = ord
+	}
+	return int(maxOrd)
+}
+
+func (v *maxSortedSetValue) Get(docID int) []byte {
+	if ord := v.Ord(docID); ord != -1 {
+		return v.LookupOrd(ord)
+	}
+	return nil
+}
+
+func (v *maxSortedSetValue) LookupOrd(ord int) []byte {
+	return v.in.LookupOrd(int64(ord))
+}
+
+func (v *maxSortedSetValue) ValueCount() int {
+	return int(v.in.ValueCount())
+}
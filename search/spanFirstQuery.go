@@ -0,0 +1,189 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/spans/SpanPositionCheckQuery.java/AcceptStatus
+
+// Return value of SpanPositionCheckQuery's hook AcceptPosition().
+type SpanAcceptStatus int
+
+const (
+	// Indicates the match should be accepted
+	SPAN_ACCEPT_STATUS_YES = SpanAcceptStatus(1)
+	// Indicates the match should be rejected
+	SPAN_ACCEPT_STATUS_NO = SpanAcceptStatus(2)
+	/*
+		Indicates the match should be rejected, and the enumeration
+		should advance to the next document.
+	*/
+	SPAN_ACCEPT_STATUS_NO_AND_ADVANCE = SpanAcceptStatus(3)
+)
+
+// search/spans/SpanPositionCheckQuery.java
+
+/*
+The hook of SpanPositionCheckQuery, implemented by the concrete
+queries which embed it.
+*/
+type spanPositionCheckQuery interface {
+	SpanQuery
+	/*
+		Implementing classes are required to return whether the current
+		position is a match for the passed in "match" SpanQuery.
+
+		This is only called if the underlying Next() for the match
+		returned true.
+	*/
+	AcceptPosition(spans Spans) SpanAcceptStatus
+}
+
+/*
+Base class for filtering a SpanQuery based on the position of a
+match, e.g. SpanFirstQuery.
+*/
+type SpanPositionCheckQuery struct {
+	*AbstractQuery
+	self  spanPositionCheckQuery
+	match SpanQuery
+}
+
+func NewSpanPositionCheckQuery(self spanPositionCheckQuery, match SpanQuery) *SpanPositionCheckQuery {
+	return &SpanPositionCheckQuery{NewAbstractQuery(self), self, match}
+}
+
+// Returns the SpanQuery whose matches are filtered.
+func (q *SpanPositionCheckQuery) Match() SpanQuery {
+	return q.match
+}
+
+func (q *SpanPositionCheckQuery) Field() string {
+	return q.match.Field()
+}
+
+func (q *SpanPositionCheckQuery) ExtractTerms(terms map[string]index.Term) {
+	q.match.ExtractTerms(terms)
+}
+
+func (q *SpanPositionCheckQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	return NewSpanWeight(q.self, ss)
+}
+
+func (q *SpanPositionCheckQuery) Spans(ctx index.AtomicReaderContext, acceptDocs util.Bits,
+	termContexts map[string]*index.TermContext) (Spans, error) {
+	spans, err := q.match.Spans(ctx, acceptDocs, termContexts)
+	if err != nil {
+		return nil, err
+	}
+	return &positionCheckSpans{q.self, spans}, nil
+}
+
+// The spans of the match, whose positions are accepted by query.
+type positionCheckSpans struct {
+	query spanPositionCheckQuery
+	spans Spans
+}
+
+func (s *positionCheckSpans) Next() bool {
+	return s.spans.Next() && s.doNext()
+}
+
+func (s *positionCheckSpans) SkipTo(target int) bool {
+	return s.spans.SkipTo(target) && s.doNext()
+}
+
+// Moves the spans until a position is accepted.
+func (s *positionCheckSpans) doNext() bool {
+	for {
+		switch s.query.AcceptPosition(s.spans) {
+		case SPAN_ACCEPT_STATUS_YES:
+			return true
+		case SPAN_ACCEPT_STATUS_NO:
+			if !s.spans.Next() {
+				return false
+			}
+		case SPAN_ACCEPT_STATUS_NO_AND_ADVANCE:
+			if !s.spans.SkipTo(s.spans.Doc() + 1) {
+				return false
+			}
+		}
+	}
+}
+
+func (s *positionCheckSpans) Doc() int   { return s.spans.Doc() }
+func (s *positionCheckSpans) Start() int { return s.spans.Start() }
+func (s *positionCheckSpans) End() int   { return s.spans.End() }
+
+func (s *positionCheckSpans) Cost() int64 {
+	return s.spans.Cost()
+}
+
+func (s *positionCheckSpans) Payload() [][]byte {
+	if s.spans.IsPayloadAvailable() {
+		return s.spans.Payload()
+	}
+	return nil
+}
+
+func (s *positionCheckSpans) IsPayloadAvailable() bool {
+	return s.spans.IsPayloadAvailable()
+}
+
+func (s *positionCheckSpans) String() string {
+	return fmt.Sprintf("spans(%v)", s.query)
+}
+
+// search/spans/SpanFirstQuery.java
+
+/*
+Matches spans near the beginning of a field.
+
+This class is a simple extension of SpanPositionRangeQuery in that it
+assumes the start to be zero and only checks the end boundary.
+*/
+type SpanFirstQuery struct {
+	*SpanPositionCheckQuery
+	end int
+}
+
+/*
+Construct a SpanFirstQuery matching spans in match whose end position
+is less than or equal to end.
+*/
+func NewSpanFirstQuery(match SpanQuery, end int) *SpanFirstQuery {
+	ans := &SpanFirstQuery{end: end}
+	ans.SpanPositionCheckQuery = NewSpanPositionCheckQuery(ans, match)
+	return ans
+}
+
+// Return the maximum end position permitted in a match.
+func (q *SpanFirstQuery) End() int {
+	return q.end
+}
+
+func (q *SpanFirstQuery) AcceptPosition(spans Spans) SpanAcceptStatus {
+	if spans.Start() >= q.end {
+		return SPAN_ACCEPT_STATUS_NO_AND_ADVANCE
+	}
+	if spans.End() <= q.end {
+		return SPAN_ACCEPT_STATUS_YES
+	}
+	return SPAN_ACCEPT_STATUS_NO
+}
+
+func (q *SpanFirstQuery) Rewrite(r index.IndexReader) (Query, error) {
+	match, err := q.match.Rewrite(r)
+	if match == q.match || err != nil {
+		return q, err // no clause rewrote
+	}
+	clone := NewSpanFirstQuery(match.(SpanQuery), q.end)
+	clone.SetBoost(q.boost)
+	return clone, nil
+}
+
+func (q *SpanFirstQuery) ToString(field string) string {
+	return fmt.Sprintf("spanFirst(%v, %v)%v", q.match.ToString(field), q.end, boostString(q.boost))
+}
@@ -0,0 +1,135 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/spans/SpanNearQuery.java
+
+/*
+Matches spans which are near one another. One can specify slop, the
+maximum number of intervening unmatched positions, as well as whether
+matches are required to be in-order.
+*/
+type SpanNearQuery struct {
+	*AbstractQuery
+	clauses         []SpanQuery
+	slop            int
+	inOrder         bool
+	field           string
+	collectPayloads bool
+}
+
+/*
+Construct a SpanNearQuery. Matches spans matching a span from each
+clause, with up to slop total unmatched positions between them. When
+inOrder is true, the spans from each clause must be ordered as in
+clauses.
+
+The clauses must all be of the same field.
+*/
+func NewSpanNearQuery(clauses []SpanQuery, slop int, inOrder bool) *SpanNearQuery {
+	return NewSpanNearQueryWithPayloads(clauses, slop, inOrder, true)
+}
+
+/*
+Same as NewSpanNearQuery, and collects the payloads of the matches of
+the ordered query if collectPayloads is true.
+*/
+func NewSpanNearQueryWithPayloads(clauses []SpanQuery, slop int, inOrder, collectPayloads bool) *SpanNearQuery {
+	ans := &SpanNearQuery{
+		clauses:         make([]SpanQuery, 0, len(clauses)),
+		slop:            slop,
+		inOrder:         inOrder,
+		collectPayloads: collectPayloads,
+	}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	for i, clause := range clauses {
+		if i == 0 { // check field
+			ans.field = clause.Field()
+		} else if clause.Field() != "" && clause.Field() != ans.field {
+			panic("Clauses must have same field.")
+		}
+		ans.clauses = append(ans.clauses, clause)
+	}
+	return ans
+}
+
+// Return the clauses whose spans are matched.
+func (q *SpanNearQuery) Clauses() []SpanQuery {
+	return q.clauses
+}
+
+// Return the maximum number of intervening unmatched positions
+// permitted.
+func (q *SpanNearQuery) Slop() int {
+	return q.slop
+}
+
+// Return true if matches are required to be in-order.
+func (q *SpanNearQuery) IsInOrder() bool {
+	return q.inOrder
+}
+
+func (q *SpanNearQuery) Field() string {
+	return q.field
+}
+
+func (q *SpanNearQuery) ExtractTerms(terms map[string]index.Term) {
+	for _, clause := range q.clauses {
+		clause.ExtractTerms(terms)
+	}
+}
+
+func (q *SpanNearQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	return NewSpanWeight(q, ss)
+}
+
+func (q *SpanNearQuery) Rewrite(r index.IndexReader) (Query, error) {
+	clauses, err := rewriteSpanQueries(r, q.clauses)
+	if clauses == nil || err != nil {
+		return q, err // no clauses rewrote
+	}
+	clone := NewSpanNearQueryWithPayloads(clauses, q.slop, q.inOrder, q.collectPayloads)
+	clone.SetBoost(q.boost)
+	return clone, nil
+}
+
+func (q *SpanNearQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	buf.WriteString("spanNear([")
+	for i, clause := range q.clauses {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(clause.ToString(field))
+	}
+	fmt.Fprintf(&buf, "], %v, %v)", q.slop, q.inOrder)
+	buf.WriteString(boostString(q.boost))
+	return buf.String()
+}
+
+func (q *SpanNearQuery) Spans(ctx index.AtomicReaderContext, acceptDocs util.Bits,
+	termContexts map[string]*index.TermContext) (Spans, error) {
+	switch len(q.clauses) {
+	case 0: // optimize 0-clause case
+		return NewSpanOrQuery().Spans(ctx, acceptDocs, termContexts)
+	case 1: // optimize 1-clause case
+		return q.clauses[0].Spans(ctx, acceptDocs, termContexts)
+	}
+	subSpans := make([]Spans, len(q.clauses))
+	for i, clause := range q.clauses {
+		spans, err := clause.Spans(ctx, acceptDocs, termContexts)
+		if err != nil {
+			return nil, err
+		}
+		subSpans[i] = spans
+	}
+	if q.inOrder {
+		return newNearSpansOrdered(q, subSpans, q.collectPayloads), nil
+	}
+	return newNearSpansUnordered(q, subSpans), nil
+}
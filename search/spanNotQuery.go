@@ -0,0 +1,166 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/spans/SpanNotQuery.java
+
+// Removes matches which overlap with another SpanQuery.
+type SpanNotQuery struct {
+	*AbstractQuery
+	include SpanQuery
+	exclude SpanQuery
+}
+
+/*
+Construct a SpanNotQuery matching spans from include which have no
+overlap with spans from exclude.
+*/
+func NewSpanNotQuery(include, exclude SpanQuery) *SpanNotQuery {
+	if include.Field() != "" && exclude.Field() != "" && include.Field() != exclude.Field() {
+		panic("Clauses must have same field.")
+	}
+	ans := &SpanNotQuery{include: include, exclude: exclude}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+// Return the SpanQuery whose matches are filtered.
+func (q *SpanNotQuery) Include() SpanQuery {
+	return q.include
+}
+
+// Return the SpanQuery whose matches must not overlap those
+// returned.
+func (q *SpanNotQuery) Exclude() SpanQuery {
+	return q.exclude
+}
+
+func (q *SpanNotQuery) Field() string {
+	return q.include.Field()
+}
+
+func (q *SpanNotQuery) ExtractTerms(terms map[string]index.Term) {
+	q.include.ExtractTerms(terms)
+}
+
+func (q *SpanNotQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	return NewSpanWeight(q, ss)
+}
+
+func (q *SpanNotQuery) Rewrite(r index.IndexReader) (Query, error) {
+	clauses, err := rewriteSpanQueries(r, []SpanQuery{q.include, q.exclude})
+	if clauses == nil || err != nil {
+		return q, err // no clauses rewrote
+	}
+	clone := NewSpanNotQuery(clauses[0], clauses[1])
+	clone.SetBoost(q.boost)
+	return clone, nil
+}
+
+func (q *SpanNotQuery) ToString(field string) string {
+	return fmt.Sprintf("spanNot(%v, %v)%v",
+		q.include.ToString(field), q.exclude.ToString(field), boostString(q.boost))
+}
+
+func (q *SpanNotQuery) Spans(ctx index.AtomicReaderContext, acceptDocs util.Bits,
+	termContexts map[string]*index.TermContext) (Spans, error) {
+	includeSpans, err := q.include.Spans(ctx, acceptDocs, termContexts)
+	if err != nil {
+		return nil, err
+	}
+	excludeSpans, err := q.exclude.Spans(ctx, acceptDocs, termContexts)
+	if err != nil {
+		return nil, err
+	}
+	return &notSpans{
+		query:        q,
+		includeSpans: includeSpans,
+		moreInclude:  true,
+		excludeSpans: excludeSpans,
+		moreExclude:  excludeSpans.Next(),
+	}, nil
+}
+
+// The spans of include, which don't overlap the spans of exclude.
+type notSpans struct {
+	query        *SpanNotQuery
+	includeSpans Spans
+	moreInclude  bool
+	excludeSpans Spans
+	moreExclude  bool
+}
+
+func (s *notSpans) Next() bool {
+	if s.moreInclude { // move to next include
+		s.moreInclude = s.includeSpans.Next()
+	}
+	for s.moreInclude && s.moreExclude {
+		if s.includeSpans.Doc() > s.excludeSpans.Doc() { // skip exclude
+			s.moreExclude = s.excludeSpans.SkipTo(s.includeSpans.Doc())
+		}
+		s.skipExcludeBefore()
+		if s.noOverlap() {
+			break // we found a match
+		}
+		s.moreInclude = s.includeSpans.Next() // intersected: keep scanning
+	}
+	return s.moreInclude
+}
+
+func (s *notSpans) SkipTo(target int) bool {
+	if s.moreInclude { // skip include
+		s.moreInclude = s.includeSpans.SkipTo(target)
+	}
+	if !s.moreInclude {
+		return false
+	}
+	if s.moreExclude && s.includeSpans.Doc() > s.excludeSpans.Doc() {
+		s.moreExclude = s.excludeSpans.SkipTo(s.includeSpans.Doc())
+	}
+	s.skipExcludeBefore()
+	if s.noOverlap() {
+		return true // we found a match
+	}
+	return s.Next() // scan to next match
+}
+
+// Moves exclude while it's before include, in the same document.
+func (s *notSpans) skipExcludeBefore() {
+	for s.moreExclude && s.includeSpans.Doc() == s.excludeSpans.Doc() &&
+		s.excludeSpans.End() <= s.includeSpans.Start() {
+		s.moreExclude = s.excludeSpans.Next()
+	}
+}
+
+// Returns true if exclude doesn't overlap include.
+func (s *notSpans) noOverlap() bool {
+	return !s.moreExclude || s.includeSpans.Doc() != s.excludeSpans.Doc() ||
+		s.includeSpans.End() <= s.excludeSpans.Start()
+}
+
+func (s *notSpans) Doc() int   { return s.includeSpans.Doc() }
+func (s *notSpans) Start() int { return s.includeSpans.Start() }
+func (s *notSpans) End() int   { return s.includeSpans.End() }
+
+func (s *notSpans) Cost() int64 {
+	return s.includeSpans.Cost()
+}
+
+func (s *notSpans) Payload() [][]byte {
+	if s.includeSpans.IsPayloadAvailable() {
+		return s.includeSpans.Payload()
+	}
+	return nil
+}
+
+func (s *notSpans) IsPayloadAvailable() bool {
+	return s.includeSpans.IsPayloadAvailable()
+}
+
+func (s *notSpans) String() string {
+	return fmt.Sprintf("spans(%v)", s.query)
+}
@@ -0,0 +1,222 @@
+package search
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/spans/SpanOrQuery.java
+
+// Matches the union of its clauses.
+type SpanOrQuery struct {
+	*AbstractQuery
+	clauses []SpanQuery
+	field   string
+}
+
+// Construct a SpanOrQuery merging the provided clauses, which must
+// all be of the same field.
+func NewSpanOrQuery(clauses ...SpanQuery) *SpanOrQuery {
+	ans := &SpanOrQuery{clauses: make([]SpanQuery, 0, len(clauses))}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	for _, clause := range clauses {
+		ans.AddClause(clause)
+	}
+	return ans
+}
+
+// Adds a clause to this query, which must be of the same field as
+// the other clauses.
+func (q *SpanOrQuery) AddClause(clause SpanQuery) {
+	if q.field == "" {
+		q.field = clause.Field()
+	} else if clause.Field() != "" && clause.Field() != q.field {
+		panic("Clauses must have same field.")
+	}
+	q.clauses = append(q.clauses, clause)
+}
+
+// Return the clauses whose spans are matched.
+func (q *SpanOrQuery) Clauses() []SpanQuery {
+	return q.clauses
+}
+
+func (q *SpanOrQuery) Field() string {
+	return q.field
+}
+
+func (q *SpanOrQuery) ExtractTerms(terms map[string]index.Term) {
+	for _, clause := range q.clauses {
+		clause.ExtractTerms(terms)
+	}
+}
+
+func (q *SpanOrQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	return NewSpanWeight(q, ss)
+}
+
+func (q *SpanOrQuery) Rewrite(r index.IndexReader) (Query, error) {
+	clauses, err := rewriteSpanQueries(r, q.clauses)
+	if clauses == nil || err != nil {
+		return q, err // no clauses rewrote
+	}
+	clone := NewSpanOrQuery(clauses...)
+	clone.SetBoost(q.boost)
+	return clone, nil
+}
+
+func (q *SpanOrQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	buf.WriteString("spanOr([")
+	for i, clause := range q.clauses {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(clause.ToString(field))
+	}
+	buf.WriteString("])")
+	buf.WriteString(boostString(q.boost))
+	return buf.String()
+}
+
+func (q *SpanOrQuery) Spans(ctx index.AtomicReaderContext, acceptDocs util.Bits,
+	termContexts map[string]*index.TermContext) (Spans, error) {
+	if len(q.clauses) == 1 { // optimize 1-clause case
+		return q.clauses[0].Spans(ctx, acceptDocs, termContexts)
+	}
+	subSpans := make([]Spans, len(q.clauses))
+	for i, clause := range q.clauses {
+		spans, err := clause.Spans(ctx, acceptDocs, termContexts)
+		if err != nil {
+			return nil, err
+		}
+		subSpans[i] = spans
+	}
+	return &orSpans{query: q, subSpans: subSpans}, nil
+}
+
+// The spans of all the clauses, merged by a priority queue.
+type orSpans struct {
+	query    *SpanOrQuery
+	subSpans []Spans
+	queue    *spanQueue // nil before the first move
+}
+
+// Moves the sub-spans to their first match, by next if target is
+// -1, otherwise by skipping to target.
+func (s *orSpans) initSpanQueue(target int) bool {
+	queue := make(spanQueue, 0, len(s.subSpans))
+	for _, spans := range s.subSpans {
+		if target == -1 && spans.Next() || target != -1 && spans.SkipTo(target) {
+			queue = append(queue, spans)
+		}
+	}
+	heap.Init(&queue)
+	s.queue = &queue
+	return queue.Len() != 0
+}
+
+func (s *orSpans) top() Spans {
+	return (*s.queue)[0]
+}
+
+func (s *orSpans) Next() bool {
+	if s.queue == nil {
+		return s.initSpanQueue(-1)
+	}
+	if s.queue.Len() == 0 { // all done
+		return false
+	}
+	if s.top().Next() { // move to next
+		heap.Fix(s.queue, 0)
+		return true
+	}
+	heap.Pop(s.queue) // exhausted a clause
+	return s.queue.Len() != 0
+}
+
+func (s *orSpans) SkipTo(target int) bool {
+	if s.queue == nil {
+		return s.initSpanQueue(target)
+	}
+	skipCalled := false
+	for s.queue.Len() != 0 && s.top().Doc() < target {
+		if s.top().SkipTo(target) {
+			heap.Fix(s.queue, 0)
+		} else {
+			heap.Pop(s.queue)
+		}
+		skipCalled = true
+	}
+	if skipCalled {
+		return s.queue.Len() != 0
+	}
+	return s.Next()
+}
+
+func (s *orSpans) Doc() int   { return s.top().Doc() }
+func (s *orSpans) Start() int { return s.top().Start() }
+func (s *orSpans) End() int   { return s.top().End() }
+
+func (s *orSpans) Cost() int64 {
+	var cost int64
+	for _, spans := range s.subSpans {
+		cost += spans.Cost()
+	}
+	return cost
+}
+
+func (s *orSpans) Payload() [][]byte {
+	if s.queue != nil && s.queue.Len() != 0 && s.top().IsPayloadAvailable() {
+		return s.top().Payload()
+	}
+	return nil
+}
+
+func (s *orSpans) IsPayloadAvailable() bool {
+	return s.queue != nil && s.queue.Len() != 0 && s.top().IsPayloadAvailable()
+}
+
+func (s *orSpans) String() string {
+	at := "START"
+	if s.queue != nil {
+		if s.queue.Len() > 0 {
+			at = fmt.Sprintf("%v:%v-%v", s.Doc(), s.Start(), s.End())
+		} else {
+			at = "END"
+		}
+	}
+	return fmt.Sprintf("spans(%v)@%v", s.query, at)
+}
+
+// search/spans/SpanOrQuery.java/SpanQueue
+
+// Orders the spans by document, then by start and end positions.
+type spanQueue []Spans
+
+func (pq spanQueue) Len() int { return len(pq) }
+
+func (pq spanQueue) Less(i, j int) bool {
+	spans1, spans2 := pq[i], pq[j]
+	if spans1.Doc() == spans2.Doc() {
+		if spans1.Start() == spans2.Start() {
+			return spans1.End() < spans2.End()
+		}
+		return spans1.Start() < spans2.Start()
+	}
+	return spans1.Doc() < spans2.Doc()
+}
+
+func (pq spanQueue) Swap(i, j int) { pq[i], pq[j] = pq[j], pq[i] }
+
+func (pq *spanQueue) Push(x interface{}) { *pq = append(*pq, x.(Spans)) }
+
+func (pq *spanQueue) Pop() interface{} {
+	n := len(*pq)
+	ans := (*pq)[n-1]
+	*pq = (*pq)[:n-1]
+	return ans
+}
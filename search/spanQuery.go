@@ -0,0 +1,279 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+)
+
+// search/spans/SpanQuery.java
+
+/*
+Base interface for span-based queries, which match the spans, i.e.
+the position ranges, of the documents, as given by their Spans,
+instead of the documents only. Span queries can be nested, so
+proximity and ordering constraints are put on the matches of other
+span queries.
+*/
+type SpanQuery interface {
+	Query
+	/*
+		Expert: Returns the matches for this query in a segment.
+
+		termContexts are the states of the terms of this query, in the
+		segments, keyed by the bytes of the terms.
+	*/
+	Spans(ctx index.AtomicReaderContext, acceptDocs util.Bits,
+		termContexts map[string]*index.TermContext) (Spans, error)
+	/*
+		Returns the name of the field matched by this query, or "" if
+		the query has no clause.
+
+		Note that this may return "" if the query matches no terms.
+	*/
+	Field() string
+	/*
+		Expert: adds all terms occurring in this query to the terms,
+		keyed by their bytes.
+	*/
+	ExtractTerms(terms map[string]index.Term)
+}
+
+// Rewrites the span query clauses, and returns the rewritten
+// clauses, or nil if no clause rewrote.
+func rewriteSpanQueries(r index.IndexReader, clauses []SpanQuery) ([]SpanQuery, error) {
+	var ans []SpanQuery
+	for i, c := range clauses {
+		query, err := c.Rewrite(r)
+		if err != nil {
+			return nil, err
+		}
+		if query != c { // clause rewrote: must clone
+			if ans == nil {
+				ans = make([]SpanQuery, len(clauses))
+				copy(ans, clauses)
+			}
+			ans[i] = query.(SpanQuery)
+		}
+	}
+	return ans, nil
+}
+
+// search/spans/SpanWeight.java
+
+// Expert-only. Public for use by other weight implementations.
+type SpanWeight struct {
+	similarity   Similarity
+	termContexts map[string]*index.TermContext
+	query        SpanQuery
+	// nil if the query has no field
+	stats SimWeight
+}
+
+func NewSpanWeight(query SpanQuery, ss *IndexSearcher) (*SpanWeight, error) {
+	ans := &SpanWeight{
+		similarity:   ss.Similarity(),
+		termContexts: make(map[string]*index.TermContext),
+		query:        query,
+	}
+	terms := make(map[string]index.Term)
+	query.ExtractTerms(terms)
+	keys := make([]string, 0, len(terms))
+	for key := range terms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	ctx := ss.TopReaderContext()
+	termStats := make([]TermStatistics, len(keys))
+	for i, key := range keys {
+		state, err := index.NewTermContextFromTerm(ctx, terms[key])
+		if err != nil {
+			return nil, err
+		}
+		termStats[i] = ss.TermStatistics(terms[key], state)
+		ans.termContexts[key] = state
+	}
+	if field := query.Field(); field != "" {
+		ans.stats = ans.similarity.ComputeWeight(query.Boost(),
+			ss.CollectionStatistics(field), termStats...)
+	}
+	return ans, nil
+}
+
+func (w *SpanWeight) Query() Query {
+	return w.query
+}
+
+func (w *SpanWeight) ValueForNormalization() float32 {
+	if w.stats == nil {
+		return 1
+	}
+	return w.stats.ValueForNormalization()
+}
+
+func (w *SpanWeight) Normalize(norm float32, topLevelBoost float32) {
+	if w.stats != nil {
+		w.stats.Normalize(norm, topLevelBoost)
+	}
+}
+
+func (w *SpanWeight) IsScoresDocsOutOfOrder() bool {
+	return false
+}
+
+func (w *SpanWeight) Scorer(ctx index.AtomicReaderContext,
+	scoreDocsInOrder, topScorer bool, acceptDocs util.Bits) (Scorer, error) {
+	if w.stats == nil {
+		return nil, nil
+	}
+	spans, err := w.query.Spans(ctx, acceptDocs, w.termContexts)
+	if err != nil {
+		return nil, err
+	}
+	docScorer, err := w.similarity.SloppySimScorer(w.stats, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewSpanScorer(spans, w, docScorer), nil
+}
+
+func (w *SpanWeight) Explain(ctx index.AtomicReaderContext, doc int) (*Explanation, error) {
+	scorer, err := w.Scorer(ctx, true, false, ctx.Reader().(index.AtomicReader).LiveDocs())
+	if err != nil {
+		return nil, err
+	}
+	if scorer != nil {
+		if newDoc, _ := scorer.Advance(doc); newDoc == doc {
+			result, err := w.explainSpanScore(ctx, doc, scorer.(interface {
+				SloppyFreq() float32
+			}).SloppyFreq())
+			if err != nil {
+				return nil, err
+			}
+			result.SetMatch(true)
+			return result, nil
+		}
+	}
+	return NewComplexExplanation(false, 0, "no matching term"), nil
+}
+
+// Explains the span score of the document, whose sloppy frequency is
+// freq.
+func (w *SpanWeight) explainSpanScore(ctx index.AtomicReaderContext, doc int, freq float32) (*Explanation, error) {
+	docScorer, err := w.similarity.SloppySimScorer(w.stats, ctx)
+	if err != nil {
+		return nil, err
+	}
+	scoreExplanation := docScorer.Explain(doc, NewExplanation(freq, fmt.Sprintf("phraseFreq=%v", freq)))
+	result := NewExplanation(scoreExplanation.Value(), fmt.Sprintf(
+		"weight(%v in %v) [%v], result of:", w.query, doc, simpleName(w.similarity)))
+	result.AddDetail(scoreExplanation)
+	return result, nil
+}
+
+func (w *SpanWeight) String() string {
+	return fmt.Sprintf("weight(%v)", w.query)
+}
+
+// search/spans/SpanScorer.java
+
+// The hook of SpanScorer, implemented by the scorers which embed it.
+type spanScorerSPI interface {
+	/*
+		Computes the frequency of the spans of the current document, and
+		moves the spans to the next document. Returns false if there is
+		no more document.
+	*/
+	setFreqCurrentDoc() bool
+}
+
+/*
+Public for extension only. Scores the documents of the spans, where
+each span contributes to the frequency by the slop factor of its
+length, so shorter spans score higher.
+*/
+type SpanScorer struct {
+	*ScorerImpl
+	spi        spanScorerSPI
+	spans      Spans
+	more       bool
+	doc        int
+	freq       float32
+	numMatches int
+	docScorer  SloppySimScorer
+}
+
+func NewSpanScorer(spans Spans, w Weight, docScorer SloppySimScorer) *SpanScorer {
+	ans := &SpanScorer{}
+	ans.init(ans, ans, spans, w, docScorer)
+	return ans
+}
+
+// Initializes the SpanScorer embedded in self, whose frequencies are
+// computed by spi.
+func (s *SpanScorer) init(self Scorer, spi spanScorerSPI, spans Spans, w Weight, docScorer SloppySimScorer) {
+	s.ScorerImpl = NewScorer(self, w)
+	s.spi = spi
+	s.spans, s.doc, s.docScorer = spans, -1, docScorer
+	s.more = spans.Next()
+}
+
+func (s *SpanScorer) NextDoc() (int, bool) {
+	if !s.spi.setFreqCurrentDoc() {
+		s.doc = index.NO_MORE_DOCS
+	}
+	return s.doc, s.doc != index.NO_MORE_DOCS
+}
+
+func (s *SpanScorer) Advance(target int) (int, bool) {
+	if !s.more {
+		s.doc = index.NO_MORE_DOCS
+		return s.doc, false
+	}
+	if s.spans.Doc() < target { // setFreqCurrentDoc() leaves spans.Doc() ahead
+		s.more = s.spans.SkipTo(target)
+	}
+	return s.NextDoc()
+}
+
+// Sums the slop factors of the spans of the current document, and
+// moves the spans to the next document.
+func (s *SpanScorer) setFreqCurrentDoc() bool {
+	if !s.more {
+		return false
+	}
+	s.doc = s.spans.Doc()
+	s.freq, s.numMatches = 0, 0
+	for {
+		matchLength := s.spans.End() - s.spans.Start()
+		s.freq += s.docScorer.ComputeSlopFactor(matchLength)
+		s.numMatches++
+		if s.more = s.spans.Next(); !s.more || s.doc != s.spans.Doc() {
+			break
+		}
+	}
+	return true
+}
+
+func (s *SpanScorer) DocId() int {
+	return s.doc
+}
+
+func (s *SpanScorer) Cost() int64 {
+	return s.spans.Cost()
+}
+
+func (s *SpanScorer) Score() float32 {
+	return s.docScorer.Score(s.doc, s.freq)
+}
+
+// Returns the number of matches of the current document.
+func (s *SpanScorer) Freq() int {
+	return s.numMatches
+}
+
+// Returns the intermediate "sloppy freq" adjusted for edit distance.
+func (s *SpanScorer) SloppyFreq() float32 {
+	return s.freq
+}
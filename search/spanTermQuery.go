@@ -0,0 +1,88 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/spans/SpanTermQuery.java
+
+// Matches spans containing a term.
+type SpanTermQuery struct {
+	*AbstractQuery
+	term index.Term
+}
+
+// Construct a SpanTermQuery matching the named term's spans.
+func NewSpanTermQuery(term index.Term) *SpanTermQuery {
+	ans := &SpanTermQuery{term: term}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+// Return the term whose spans are matched.
+func (q *SpanTermQuery) Term() index.Term {
+	return q.term
+}
+
+func (q *SpanTermQuery) Field() string {
+	return q.term.Field
+}
+
+func (q *SpanTermQuery) ExtractTerms(terms map[string]index.Term) {
+	terms[string(q.term.Bytes)] = q.term
+}
+
+func (q *SpanTermQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	return NewSpanWeight(q, ss)
+}
+
+func (q *SpanTermQuery) ToString(field string) string {
+	s := string(q.term.Bytes)
+	if q.term.Field != field {
+		s = q.term.Field + ":" + s
+	}
+	return s + boostString(q.boost)
+}
+
+func (q *SpanTermQuery) Spans(ctx index.AtomicReaderContext, acceptDocs util.Bits,
+	termContexts map[string]*index.TermContext) (Spans, error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	var state *index.TermState
+	if termContext, ok := termContexts[string(q.term.Bytes)]; ok {
+		state = termContext.State(ctx.Ord)
+	} else {
+		// this happens with span-not query, as it doesn't include the
+		// NOT side in ExtractTerms(), so we seek to the term now in
+		// this segment
+		if terms := reader.Terms(q.term.Field); terms != nil {
+			termsEnum := terms.Iterator(nil)
+			ok, err := termsEnum.SeekExact(q.term.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				termState := termsEnum.TermState()
+				state = &termState
+			}
+		}
+	}
+	if state == nil { // term is not present in that reader
+		return EMPTY_TERM_SPANS, nil
+	}
+
+	termsEnum := reader.Terms(q.term.Field).Iterator(nil)
+	if err := termsEnum.SeekExactFromLast(q.term.Bytes, *state); err != nil {
+		return nil, err
+	}
+	postings := termsEnum.DocsAndPositionsByFlags(acceptDocs,
+		index.DocsAndPositionsEnum{}, index.DOCS_POSITIONS_ENUM_FLAG_PAYLOADS)
+	if postings.DocsAndPositionsIterator == nil {
+		return nil, errors.New(fmt.Sprintf(
+			`field "%v" was indexed without position data; cannot run SpanTermQuery (term=%v)`,
+			q.term.Field, string(q.term.Bytes)))
+	}
+	return NewTermSpans(postings.DocsAndPositionsIterator, q.term), nil
+}
@@ -0,0 +1,179 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+)
+
+// search/spans/Spans.java
+
+/*
+Expert: an enumeration of span matches. Used to implement span
+searching. Each span represents a range of term positions within a
+document. Matches are enumerated in order, by increasing document
+number, within that by increasing start position and finally by
+increasing end position.
+*/
+type Spans interface {
+	// Move to the next match, returning true iff any such exists.
+	Next() bool
+	/*
+		Skips to the first match beyond the current, whose document
+		number is greater than or equal to target.
+
+		The behavior of this method is undefined when called with
+		target <= current, or after the iterator has exhausted. Both
+		cases may result in unpredicted behavior.
+
+		Returns true iff there is such a match.
+
+		Most implementations are considerably more efficient than that.
+	*/
+	SkipTo(target int) bool
+	// Returns the document number of the current match. Initially
+	// invalid.
+	Doc() int
+	// Returns the start position of the current match. Initially
+	// invalid.
+	Start() int
+	// Returns the end position of the current match. Initially
+	// invalid.
+	End() int
+	/*
+		Returns the payload data for the current span. This is invalid
+		until Next() is called for the first time. This method must not
+		be called more than once after each call of Next(). However,
+		most payloads are loaded lazily, so if the payload data for the
+		current position is not needed, this method may not be called
+		at all for performance reasons. An ordered SpanQuery does not
+		lazy load, so if you have payloads in your index and you do not
+		want ordered SpanNearQuerys to collect payloads, you can disable
+		collection with a constructor option.
+
+		Note that the return type is a collection, thus the ordering
+		should not be relied upon.
+	*/
+	Payload() [][]byte
+	/*
+		Checks if a payload can be loaded at this position.
+
+		Payloads can only be loaded once per call to Next().
+
+		Returns true if there is a payload available at this position
+		that can be loaded.
+	*/
+	IsPayloadAvailable() bool
+	/*
+		Returns the estimated cost of these spans, usually an upper
+		bound of the number of documents they match. See
+		DocIdSetIterator.Cost().
+	*/
+	Cost() int64
+}
+
+// search/spans/TermSpans.java
+
+/*
+Expert: Public for extension only. The positions of a term in the
+documents, each of which is a span of length 1.
+*/
+type TermSpans struct {
+	postings    index.DocsAndPositionsIterator
+	term        index.Term
+	doc         int
+	freq        int
+	count       int
+	position    int
+	readPayload bool
+}
+
+func NewTermSpans(postings index.DocsAndPositionsIterator, term index.Term) *TermSpans {
+	return &TermSpans{postings: postings, term: term, doc: -1}
+}
+
+func (s *TermSpans) Next() bool {
+	if s.count == s.freq {
+		if s.postings == nil {
+			return false
+		}
+		var more bool
+		if s.doc, more = s.postings.NextDoc(); !more {
+			return false
+		}
+		s.freq = s.postings.Freq()
+		s.count = 0
+	}
+	s.position = s.postings.NextPosition()
+	s.count++
+	s.readPayload = false
+	return true
+}
+
+func (s *TermSpans) SkipTo(target int) bool {
+	var more bool
+	if s.doc, more = s.postings.Advance(target); !more {
+		return false
+	}
+	s.freq = s.postings.Freq()
+	s.count = 0
+	s.position = s.postings.NextPosition()
+	s.count++
+	s.readPayload = false
+	return true
+}
+
+func (s *TermSpans) Cost() int64 {
+	return s.postings.Cost()
+}
+
+func (s *TermSpans) Doc() int {
+	return s.doc
+}
+
+func (s *TermSpans) Start() int {
+	return s.position
+}
+
+func (s *TermSpans) End() int {
+	return s.position + 1
+}
+
+func (s *TermSpans) Payload() [][]byte {
+	payload := s.postings.Payload()
+	s.readPayload = true
+	if payload == nil {
+		return nil
+	}
+	return [][]byte{append([]byte(nil), payload...)}
+}
+
+func (s *TermSpans) IsPayloadAvailable() bool {
+	return !s.readPayload && s.postings.Payload() != nil
+}
+
+func (s *TermSpans) String() string {
+	var at string
+	switch s.doc {
+	case -1:
+		at = "START"
+	case index.NO_MORE_DOCS:
+		at = "END"
+	default:
+		at = fmt.Sprintf("%v-%v", s.doc, s.position)
+	}
+	return fmt.Sprintf("spans(%v:%v)@%v", s.term.Field, string(s.term.Bytes), at)
+}
+
+// The spans of a term which doesn't occur in a segment.
+var EMPTY_TERM_SPANS = Spans(emptyTermSpans{})
+
+type emptyTermSpans struct{}
+
+func (s emptyTermSpans) Next() bool               { return false }
+func (s emptyTermSpans) SkipTo(target int) bool   { return false }
+func (s emptyTermSpans) Doc() int                 { return index.NO_MORE_DOCS }
+func (s emptyTermSpans) Cost() int64              { return 0 }
+func (s emptyTermSpans) Start() int               { return -1 }
+func (s emptyTermSpans) End() int                 { return -1 }
+func (s emptyTermSpans) Payload() [][]byte        { return nil }
+func (s emptyTermSpans) IsPayloadAvailable() bool { return false }
@@ -0,0 +1,123 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"strings"
+	"testing"
+)
+
+func newContentSpanTermQuery(text string) *SpanTermQuery {
+	return NewSpanTermQuery(index.NewTerm("content", text))
+}
+
+func newContentSpanNearQuery(slop int, inOrder bool, texts ...string) *SpanNearQuery {
+	clauses := make([]SpanQuery, len(texts))
+	for i, text := range texts {
+		clauses[i] = newContentSpanTermQuery(text)
+	}
+	return NewSpanNearQuery(clauses, slop, inOrder)
+}
+
+// Returns the spans of q, as "doc:start-end".
+func spansOf(t *testing.T, ss *IndexSearcher, q SpanQuery) string {
+	w, err := NewSpanWeight(q, ss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ans []string
+	for _, ctx := range ss.TopReaderContext().Leaves() {
+		spans, err := q.Spans(ctx, nil, w.termContexts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for spans.Next() {
+			ans = append(ans, fmt.Sprintf("%v:%v-%v", ctx.DocBase+spans.Doc(), spans.Start(), spans.End()))
+		}
+	}
+	return strings.Join(ans, " ")
+}
+
+func TestSpanQueries(t *testing.T) {
+	ss, cleanup := newBelfrySearcher(t)
+	defer cleanup()
+
+	for _, v := range []struct {
+		q     SpanQuery
+		spans string
+	}{
+		{newContentSpanTermQuery("wave"), "3:51-52 3:54-55 7:70-71"},
+		{newContentSpanTermQuery("nosuchterm"), ""},
+		{NewSpanTermQuery(index.NewTerm("nosuchfield", "wave")), ""},
+		{newContentSpanNearQuery(0, true, "fruit", "bat"), "0:37-39 0:53-55 1:34-36 1:66-68 2:229-231"},
+		{newContentSpanNearQuery(0, true, "sound", "wave"), "3:50-52 7:69-71"},
+		{newContentSpanNearQuery(0, true, "wave", "sound"), "3:54-56"},
+		{newContentSpanNearQuery(0, false, "wave", "sound"), "3:50-52 3:54-56 7:69-71"},
+		{newContentSpanNearQuery(0, true, "your", "new", "bat"), "2:41-44 7:39-42"},
+		{newContentSpanNearQuery(1, true, "your", "new", "bat"), "0:46-50 2:41-44 7:39-42"},
+		{newContentSpanNearQuery(0, false, "bat", "new", "your"), "2:41-44 7:39-42"},
+		{newContentSpanNearQuery(0, true, "wave"), "3:51-52 3:54-55 7:70-71"},
+		{NewSpanOrQuery(newContentSpanTermQuery("wave"), newContentSpanTermQuery("sound")),
+			"3:50-51 3:51-52 3:54-55 3:55-56 7:69-70 7:70-71"},
+		{NewSpanOrQuery(newContentSpanTermQuery("nosuchterm"), newContentSpanNearQuery(0, true, "wave", "sound")),
+			"3:54-56"},
+		{NewSpanNotQuery(
+			NewSpanOrQuery(newContentSpanTermQuery("wave"), newContentSpanTermQuery("sound")),
+			newContentSpanNearQuery(0, true, "sound", "wave")), "3:54-55 3:55-56"},
+		{NewSpanNotQuery(newContentSpanTermQuery("wave"), newContentSpanTermQuery("nosuchterm")),
+			"3:51-52 3:54-55 7:70-71"},
+		{NewSpanFirstQuery(newContentSpanTermQuery("bat"), 36),
+			"1:35-36 2:32-33 3:32-33 4:34-35 5:34-35 6:30-31 7:27-28 7:34-35"},
+		{NewSpanFirstQuery(newContentSpanNearQuery(0, true, "fruit", "bat"), 40), "0:37-39 1:34-36"},
+	} {
+		assertEquals(t, v.spans, spansOf(t, ss, v.q))
+	}
+}
+
+func TestSpanQueryScorer(t *testing.T) {
+	ss, cleanup := newBelfrySearcher(t)
+	defer cleanup()
+
+	// the same matches as the phrase queries
+	assertEquals(t, "map[0:2 1:2 2:1]", phraseFreqs(t, ss, newContentSpanNearQuery(0, true, "fruit", "bat")))
+	assertEquals(t, "map[3:2 7:1]", phraseFreqs(t, ss, newContentSpanNearQuery(0, false, "sound", "wave")))
+	assertEquals(t, "map[]", phraseFreqs(t, ss, newContentSpanNearQuery(0, true, "nosuchterm", "wave")))
+	assertEquals(t, "[1 2 3 4 5 6 7]", matchingDocs(t, ss, NewSpanFirstQuery(newContentSpanTermQuery("bat"), 36)))
+
+	// the scorer skips to the documents of the other clauses
+	q := newTestBooleanQuery(newContentSpanTermQuery("wave"), OCCUR_MUST,
+		NewTermQuery(index.NewTerm("content", "your")), OCCUR_MUST)
+	assertEquals(t, "[7]", matchingDocs(t, ss, q))
+}
+
+func TestSpanQueryWithoutPositions(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a b")
+	defer cleanup()
+
+	// ids are indexed as docs only
+	if _, err := ss.SearchTop(NewSpanTermQuery(index.NewTerm("id", "0")), 10); err == nil {
+		t.Error("Expected error for field indexed without positions")
+	}
+}
+
+func TestSpanQueryToString(t *testing.T) {
+	q := NewSpanFirstQuery(NewSpanNotQuery(
+		newContentSpanNearQuery(1, true, "a", "b"),
+		NewSpanOrQuery(newContentSpanTermQuery("c"), newContentSpanTermQuery("d"))), 3)
+	q.SetBoost(2)
+	assertEquals(t, "spanFirst(spanNot(spanNear([a, b], 1, true), spanOr([c, d])), 3)^2", q.ToString("content"))
+	assertEquals(t, "spanNear([content:a, content:b], 0, false)",
+		newContentSpanNearQuery(0, false, "a", "b").ToString(""))
+	assertEquals(t, "content", q.Field())
+
+	terms := make(map[string]index.Term)
+	q.ExtractTerms(terms)
+	assertEquals(t, 2, len(terms))
+	assertEquals(t, "content", terms["b"].Field)
+
+	defer func() {
+		assertEquals(t, "Clauses must have same field.", recover())
+	}()
+	NewSpanOrQuery(newContentSpanTermQuery("a"), NewSpanTermQuery(index.NewTerm("title", "a")))
+	t.Error("Expected panic of clauses of different fields")
+}
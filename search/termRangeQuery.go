@@ -0,0 +1,177 @@
+package search
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/index"
+)
+
+// search/TermRangeQuery.java
+
+/*
+A Query that matches documents within a range of terms.
+
+This query matches the documents looking for terms that fall into the
+supplied range according to the byte order of the terms. It is not
+intended for numerical ranges; use NumericRangeQuery instead.
+
+This query uses the CONSTANT_SCORE_AUTO_REWRITE_DEFAULT rewrite method by
+default.
+*/
+type TermRangeQuery struct {
+	*MultiTermQuery
+	lowerTerm    []byte
+	upperTerm    []byte
+	includeLower bool
+	includeUpper bool
+}
+
+/*
+Constructs a query selecting all terms greater/equal than lowerTerm
+but less/equal than upperTerm.
+
+If an endpoint is nil, it is said to be "open". Either or both
+endpoints may be open. Open endpoints may not be exclusive (you can't
+select all but the first or last term without explicitly specifying
+the term to exclude.)
+
+includeLower and includeUpper tell if the lowerTerm and upperTerm
+are included in the range.
+*/
+func NewTermRangeQuery(field string, lowerTerm, upperTerm []byte, includeLower, includeUpper bool) *TermRangeQuery {
+	ans := &TermRangeQuery{
+		lowerTerm:    lowerTerm,
+		upperTerm:    upperTerm,
+		includeLower: includeLower,
+		includeUpper: includeUpper,
+	}
+	ans.MultiTermQuery = NewMultiTermQuery(ans, field)
+	return ans
+}
+
+// Returns the lower value of this range query
+func (q *TermRangeQuery) LowerTerm() []byte {
+	return q.lowerTerm
+}
+
+// Returns the upper value of this range query
+func (q *TermRangeQuery) UpperTerm() []byte {
+	return q.upperTerm
+}
+
+// Returns true if the lower endpoint is inclusive
+func (q *TermRangeQuery) IncludesLower() bool {
+	return q.includeLower
+}
+
+// Returns true if the upper endpoint is inclusive
+func (q *TermRangeQuery) IncludesUpper() bool {
+	return q.includeUpper
+}
+
+func (q *TermRangeQuery) TermsEnum(terms index.Terms) (index.TermsEnum, error) {
+	if q.lowerTerm != nil && q.upperTerm != nil && bytes.Compare(q.lowerTerm, q.upperTerm) > 0 {
+		return index.EMPTY_TERMS_ENUM, nil
+	}
+	tenum := terms.Iterator(nil)
+	if (q.lowerTerm == nil || q.includeLower && len(q.lowerTerm) == 0) && q.upperTerm == nil {
+		return tenum, nil
+	}
+	return NewTermRangeTermsEnum(tenum, q.lowerTerm, q.upperTerm, q.includeLower, q.includeUpper), nil
+}
+
+// Prints a user-readable version of this query.
+func (q *TermRangeQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	if q.Field() != field {
+		buf.WriteString(q.Field())
+		buf.WriteString(":")
+	}
+	if q.includeLower {
+		buf.WriteString("[")
+	} else {
+		buf.WriteString("{")
+	}
+	buf.WriteString(rangeTermString(q.lowerTerm))
+	buf.WriteString(" TO ")
+	buf.WriteString(rangeTermString(q.upperTerm))
+	if q.includeUpper {
+		buf.WriteString("]")
+	} else {
+		buf.WriteString("}")
+	}
+	buf.WriteString(boostString(q.boost))
+	return buf.String()
+}
+
+// Returns the string form of a range endpoint, "*" if it's open.
+func rangeTermString(term []byte) string {
+	switch {
+	case term == nil:
+		return "*"
+	case string(term) == "*":
+		return "\\*"
+	}
+	return string(term)
+}
+
+// search/TermRangeTermsEnum.java
+
+/*
+Subclass of FilteredTermsEnum for enumerating all terms that match
+the specified range parameters. The enumeration seeks to the lower
+term first, and ends at the first term beyond the upper term.
+
+Term enumerations are always ordered by Comparator(). Each term in
+the enumeration is greater than all that precede it.
+*/
+type TermRangeTermsEnum struct {
+	*index.FilteredTermsEnumImpl
+	includeLower  bool
+	includeUpper  bool
+	lowerBytesRef []byte
+	upperBytesRef []byte
+}
+
+/*
+Enumerates all terms greater/equal than lowerTerm but less/equal
+than upperTerm.
+
+If an endpoint is nil, it is said to be "open". Either or both
+endpoints may be open. Open endpoints may not be exclusive (you can't
+select all but the first or last term without explicitly specifying
+the term to exclude.)
+*/
+func NewTermRangeTermsEnum(tenum index.TermsEnum, lowerTerm, upperTerm []byte,
+	includeLower, includeUpper bool) *TermRangeTermsEnum {
+	ans := &TermRangeTermsEnum{
+		includeLower:  includeLower,
+		includeUpper:  includeUpper,
+		lowerBytesRef: lowerTerm,
+		upperBytesRef: upperTerm,
+	}
+	// do a little bit of normalization: open ended range queries should
+	// always be inclusive.
+	if lowerTerm == nil {
+		ans.lowerBytesRef = []byte{}
+		ans.includeLower = true
+	}
+	if upperTerm == nil {
+		ans.includeUpper = true
+	}
+	ans.FilteredTermsEnumImpl = index.NewFilteredTermsEnum(ans, tenum, true)
+	ans.SetInitialSeekTerm(ans.lowerBytesRef)
+	return ans
+}
+
+func (e *TermRangeTermsEnum) Accept(term []byte) index.AcceptStatus {
+	if !e.includeLower && bytes.Equal(term, e.lowerBytesRef) {
+		return index.ACCEPT_STATUS_NO
+	}
+	// Use this field's default sort ordering
+	if e.upperBytesRef != nil {
+		if cmp := bytes.Compare(e.upperBytesRef, term); cmp < 0 || !e.includeUpper && cmp == 0 {
+			return index.ACCEPT_STATUS_END
+		}
+	}
+	return index.ACCEPT_STATUS_YES
+}
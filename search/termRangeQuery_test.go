@@ -0,0 +1,52 @@
+package search
+
+import (
+	"testing"
+)
+
+func newBodyTermRangeQuery(lower, upper string, includeLower, includeUpper bool) *TermRangeQuery {
+	var lowerTerm, upperTerm []byte
+	if lower != "*" {
+		lowerTerm = []byte(lower)
+	}
+	if upper != "*" {
+		upperTerm = []byte(upper)
+	}
+	return NewTermRangeQuery("body", lowerTerm, upperTerm, includeLower, includeUpper)
+}
+
+func TestTermRangeQuery(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "apple", "banana", "cherry", "date", "elder fig")
+	defer cleanup()
+
+	for _, v := range []struct {
+		q        Query
+		expected string
+	}{
+		{newBodyTermRangeQuery("banana", "date", true, true), "[1 2 3]"},
+		{newBodyTermRangeQuery("banana", "date", false, true), "[2 3]"},
+		{newBodyTermRangeQuery("banana", "date", true, false), "[1 2]"},
+		{newBodyTermRangeQuery("banana", "date", false, false), "[2]"},
+		{newBodyTermRangeQuery("b", "d", true, true), "[1 2]"},
+		{newBodyTermRangeQuery("*", "cherry", true, false), "[0 1]"},
+		{newBodyTermRangeQuery("date", "*", false, true), "[4]"},
+		{newBodyTermRangeQuery("*", "*", true, true), "[0 1 2 3 4]"},
+		{newBodyTermRangeQuery("", "*", false, true), "[0 1 2 3 4]"},
+		{newBodyTermRangeQuery("date", "banana", true, true), "[]"},
+		{newBodyTermRangeQuery("date", "date", true, true), "[3]"},
+		{newBodyTermRangeQuery("date", "date", false, true), "[]"},
+		{newBodyTermRangeQuery("zebra", "*", true, true), "[]"},
+	} {
+		assertEquals(t, v.expected, matchingDocs(t, ss, v.q))
+	}
+}
+
+func TestTermRangeQueryToString(t *testing.T) {
+	q := newBodyTermRangeQuery("a", "c", true, false)
+	assertEquals(t, "[a TO c}", q.ToString("body"))
+	q = newBodyTermRangeQuery("*", "c", false, true)
+	q.SetBoost(2)
+	assertEquals(t, "body:{* TO c]^2", q.ToString(""))
+	q = NewTermRangeQuery("body", []byte("*"), nil, true, true)
+	assertEquals(t, "[\\* TO *]", q.ToString("body"))
+}
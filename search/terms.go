@@ -6,6 +6,12 @@ import (
 	"github.com/balzaczyy/golucene/util"
 )
 
+// search/TermQuery.java
+
+/*
+A Query that matches documents containing a term. This may be
+combined with other terms with a BooleanQuery.
+*/
 type TermQuery struct {
 	*AbstractQuery
 	term               index.Term
@@ -13,25 +19,43 @@ type TermQuery struct {
 	perReaderTermState *index.TermContext
 }
 
+// Constructs a query for the term t.
 func NewTermQuery(t index.Term) *TermQuery {
 	return NewTermQueryWithDocFreq(t, -1)
 }
 
+// Expert: constructs a TermQuery that will use the provided docFreq
+// instead of looking up the docFreq against the searcher.
 func NewTermQueryWithDocFreq(t index.Term, docFreq int) *TermQuery {
-	ans := &TermQuery{}
+	ans := &TermQuery{term: t, docFreq: docFreq}
 	ans.AbstractQuery = NewAbstractQuery(ans)
-	ans.term = t
-	ans.docFreq = docFreq
 	return ans
 }
 
-func (q *TermQuery) CreateWeight(ss IndexSearcher) (w Weight, err error) {
+/*
+Expert: constructs a TermQuery that will use the provided docFreq
+instead of looking up the docFreq against the searcher, and the
+states of the term in the segments of the searcher's reader, which
+avoids seeking the term again.
+*/
+func NewTermQueryWithStates(t index.Term, states *index.TermContext) *TermQuery {
+	ans := NewTermQueryWithDocFreq(t, states.DocFreq)
+	ans.perReaderTermState = states
+	return ans
+}
+
+// Returns the term of this query.
+func (q *TermQuery) Term() index.Term {
+	return q.term
+}
+
+func (q *TermQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
 	ctx := ss.TopReaderContext()
 	var termState *index.TermContext
 	if q.perReaderTermState == nil || q.perReaderTermState.TopReaderContext != ctx {
 		// make TermQuery single-pass if we don't have a PRTS or if the context differs!
-		termState, err = index.NewTermContextFromTerm(ctx, q.term)
-		if err != nil {
+		var err error
+		if termState, err = index.NewTermContextFromTerm(ctx, q.term); err != nil {
 			return nil, err
 		}
 	} else {
@@ -44,82 +68,123 @@ func (q *TermQuery) CreateWeight(ss IndexSearcher) (w Weight, err error) {
 		termState.DocFreq = q.docFreq
 	}
 
-	return NewTermWeight(q, ss, *termState), nil
+	return newTermWeight(q, ss, termState), nil
 }
 
-func (q *TermQuery) String() string {
-	boost := ""
-	if q.boost != 1.0 {
-		boost = fmt.Sprintf("^%v", q.boost)
+func (q *TermQuery) ToString(field string) string {
+	s := string(q.term.Bytes)
+	if q.term.Field != field {
+		s = q.term.Field + ":" + s
 	}
-	return fmt.Sprintf("%v:%v%v", q.term.Field, string(q.term.Bytes), boost)
+	return s + boostString(q.boost)
 }
 
+// search/TermQuery.java/TermWeight
+
 type TermWeight struct {
 	query      *TermQuery
 	similarity Similarity
 	stats      SimWeight
-	termStates index.TermContext
+	termStates *index.TermContext
 }
 
-func NewTermWeight(q *TermQuery, ss IndexSearcher, termStates index.TermContext) TermWeight {
-	sim := ss.similarity
-	return TermWeight{q, sim, sim.computeWeight(
-		q.AbstractQuery.boost,
+func newTermWeight(q *TermQuery, ss *IndexSearcher, termStates *index.TermContext) *TermWeight {
+	sim := ss.Similarity()
+	return &TermWeight{q, sim, sim.ComputeWeight(
+		q.boost,
 		ss.CollectionStatistics(q.term.Field),
 		ss.TermStatistics(q.term, termStates)), termStates}
 }
 
-func (tw TermWeight) ValueForNormalization() float32 {
-	return tw.stats.ValueForNormalization()
+func (w *TermWeight) Query() Query {
+	return w.query
 }
 
-func (tw TermWeight) Normalize(norm float64, topLevelBoost float32) float32 {
-	return tw.stats.Normalize(norm, topLevelBoost)
+func (w *TermWeight) ValueForNormalization() float32 {
+	return w.stats.ValueForNormalization()
 }
 
-func (tw TermWeight) IsScoresDocsOutOfOrder() bool {
+func (w *TermWeight) Normalize(norm float32, topLevelBoost float32) {
+	w.stats.Normalize(norm, topLevelBoost)
+}
+
+func (w *TermWeight) IsScoresDocsOutOfOrder() bool {
 	return false
 }
 
-func (tw TermWeight) Scorer(context index.AtomicReaderContext,
-	inOrder bool, topScorer bool, acceptDocs util.Bits) (sc Scorer, ok bool) {
-	// assert termStates.topReaderContext == ReaderUtil.getTopLevelContext(context) : "The top-reader used to create Weight (" + termStates.topReaderContext + ") is not the same as the current reader's top-reader (" + ReaderUtil.getTopLevelContext(context);
-	termsEnum, ok := tw.termsEnum(context)
-	if !ok {
-		return Scorer{}, false
+func (w *TermWeight) Scorer(ctx index.AtomicReaderContext,
+	scoreDocsInOrder, topScorer bool, acceptDocs util.Bits) (Scorer, error) {
+	termsEnum, err := w.termsEnum(ctx)
+	if termsEnum == nil || err != nil {
+		return nil, err
+	}
+	docs := termsEnum.DocsByFlags(acceptDocs, index.DOCS_ENUM_EMPTY, index.DOCS_ENUM_FLAG_FREQS)
+	docScorer, err := w.similarity.ExactSimScorer(w.stats, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newTermScorer(w, docs, docScorer), nil
+}
+
+func (w *TermWeight) Explain(ctx index.AtomicReaderContext, doc int) (*Explanation, error) {
+	scorer, err := w.Scorer(ctx, true, false, ctx.Reader().(index.AtomicReader).LiveDocs())
+	if err != nil {
+		return nil, err
 	}
-	docs := termsEnum.Docs(acceptDocs, index.DOCS_ENUM_EMPTY)
-	// assert docs != null;
-	return *(newTermScorer(tw, docs, tw.similarity.exactSimScorer(tw.stats, context)).Scorer), true
+	if scorer != nil {
+		if newDoc, _ := scorer.Advance(doc); newDoc == doc {
+			freq := float32(scorer.Freq())
+			docScorer, err := w.similarity.ExactSimScorer(w.stats, ctx)
+			if err != nil {
+				return nil, err
+			}
+			scoreExplanation := docScorer.Explain(doc, NewExplanation(freq, fmt.Sprintf("termFreq=%v", freq)))
+			result := NewComplexExplanation(true, scoreExplanation.Value(), fmt.Sprintf(
+				"weight(%v in %v) [%v], result of:", w.query, doc, simpleName(w.similarity)))
+			result.AddDetail(scoreExplanation)
+			return result, nil
+		}
+	}
+	return NewComplexExplanation(false, 0, "no matching term"), nil
 }
 
-func (tw TermWeight) termsEnum(ctx index.AtomicReaderContext) (te index.TermsEnum, ok bool) {
-	state := tw.termStates.State(ctx.Ord)
+// Returns a TermsEnum positioned at this weight's term, or nil if
+// the term does not exist in the given context.
+func (w *TermWeight) termsEnum(ctx index.AtomicReaderContext) (index.TermsEnum, error) {
+	state := w.termStates.State(ctx.Ord)
 	if state == nil { // term is not present in that reader
-		// assert termNotInReader(ctx.Reader(), tw.Term)
-		// : "no termstate found but term exists in reader term=" + term;
-		return index.EMPTY_TERMS_ENUM, false
+		return nil, nil
 	}
-	te = ctx.Reader().(index.AtomicReader).Terms(tw.query.term.Field).Iterator(index.EMPTY_TERMS_ENUM)
-	te.SeekExactFromLast(tw.query.term.Bytes, *state)
-	return te, true
+	te := ctx.Reader().(index.AtomicReader).Terms(w.query.term.Field).Iterator(nil)
+	if err := te.SeekExactFromLast(w.query.term.Bytes, *state); err != nil {
+		return nil, err
+	}
+	return te, nil
 }
 
+func (w *TermWeight) String() string {
+	return fmt.Sprintf("weight(%v)", w.query)
+}
+
+// search/TermScorer.java
+
+// Expert: A Scorer for documents matching a Term.
 type TermScorer struct {
-	*Scorer
+	*ScorerImpl
+	index.DocsEnum
 	docScorer ExactSimScorer
-	docsEnum  index.DocsEnum
-}
-
-func newTermScorer(w Weight, td index.DocsEnum, docScorer ExactSimScorer) TermScorer {
-	ans := &TermScorer{}
-	scorer := newScorer(ans, w, func() float64 {
-		// assert docID() != NO_MORE_DOCS
-		return ans.docScorer.Score(ans.docsEnum.DocId(), ans.docsEnum.Freq())
-	})
-	ans.Scorer = &scorer
-	ans.docScorer = docScorer
-	ans.docsEnum = td
-	return *ans
+}
+
+func newTermScorer(w Weight, td index.DocsEnum, docScorer ExactSimScorer) *TermScorer {
+	ans := &TermScorer{DocsEnum: td, docScorer: docScorer}
+	ans.ScorerImpl = NewScorer(ans, w)
+	return ans
+}
+
+func (s *TermScorer) Score() float32 {
+	return s.docScorer.Score(s.DocId(), s.Freq())
+}
+
+func (s *TermScorer) String() string {
+	return fmt.Sprintf("scorer(%v)", s.weight)
 }
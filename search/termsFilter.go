@@ -0,0 +1,138 @@
+package search
+
+import (
+	"bytes"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"sort"
+)
+
+// queries/TermsFilter.java
+
+/*
+Constructs a filter for docs matching any of the terms added to this
+class. Unlike a RangeFilter this can be used for filtering on
+multiple terms that are not necessarily in a sequence. An example
+might be a collection of primary keys from a database query result or
+perhaps a choice of "category" labels picked by the end user. As a
+filter, this is much faster than the equivalent query (a BooleanQuery
+with many "should" TermQuerys)
+
+The terms are sorted, so each segment's terms dictionary is sought
+forwards, field by field.
+*/
+type TermsFilter struct {
+	// the sorted and distinct terms, grouped by field
+	fields []string
+	terms  [][][]byte
+}
+
+/*
+Creates a new TermsFilter from the given list of terms. The list can
+contain duplicate terms and multiple fields.
+*/
+func NewTermsFilter(terms ...index.Term) *TermsFilter {
+	if len(terms) == 0 {
+		panic("You must specify a non-empty terms list")
+	}
+	sorted := make([]index.Term, len(terms))
+	copy(sorted, terms)
+	sort.Sort(termsByFieldAndBytes(sorted))
+	ans := new(TermsFilter)
+	for i, term := range sorted {
+		if i > 0 && term.Field == sorted[i-1].Field &&
+			bytes.Equal(term.Bytes, sorted[i-1].Bytes) {
+			continue // deduplicate
+		}
+		if n := len(ans.fields); n == 0 || ans.fields[n-1] != term.Field {
+			ans.fields = append(ans.fields, term.Field)
+			ans.terms = append(ans.terms, nil)
+		}
+		n := len(ans.terms) - 1
+		ans.terms[n] = append(ans.terms[n], term.Bytes)
+	}
+	return ans
+}
+
+/*
+Creates a new TermsFilter from the given list of terms of a single
+field. The list can contain duplicate terms.
+*/
+func NewTermsFilterOfField(field string, terms ...[]byte) *TermsFilter {
+	list := make([]index.Term, len(terms))
+	for i, term := range terms {
+		list[i] = index.Term{Field: field, Bytes: term}
+	}
+	return NewTermsFilter(list...)
+}
+
+func (f *TermsFilter) DocIdSet(ctx index.AtomicReaderContext, acceptDocs util.Bits) (DocIdSet, error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	fields := reader.Fields()
+	if fields == nil {
+		// reader has no fields
+		return nil, nil
+	}
+	var result *FixedBitSetDocIdSet
+	var termsEnum index.TermsEnum
+	docs := index.DOCS_ENUM_EMPTY
+	for i, field := range f.fields {
+		terms := fields.Terms(field)
+		if terms == nil {
+			continue
+		}
+		termsEnum = terms.Iterator(termsEnum)
+		for _, term := range f.terms[i] {
+			ok, err := termsEnum.SeekExact(term)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			docs = termsEnum.DocsByFlags(acceptDocs, docs, 0)
+			if result == nil {
+				if doc, more := docs.NextDoc(); more {
+					// lazy init, but don't do it in the hot loop since we
+					// could end up with a lot of empty sets
+					result = NewFixedBitSetDocIdSet(util.NewFixedBitSet(reader.MaxDoc()))
+					result.Set(doc)
+				}
+			}
+			for doc, more := docs.NextDoc(); more; doc, more = docs.NextDoc() {
+				result.Set(doc)
+			}
+		}
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return result, nil
+}
+
+func (f *TermsFilter) String() string {
+	var buf bytes.Buffer
+	for i, field := range f.fields {
+		for _, term := range f.terms[i] {
+			if buf.Len() > 0 {
+				buf.WriteString(" ")
+			}
+			buf.WriteString(field)
+			buf.WriteString(":")
+			buf.Write(term)
+		}
+	}
+	return buf.String()
+}
+
+// Sorts the terms by field, then by bytes.
+type termsByFieldAndBytes []index.Term
+
+func (s termsByFieldAndBytes) Len() int      { return len(s) }
+func (s termsByFieldAndBytes) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s termsByFieldAndBytes) Less(i, j int) bool {
+	if s[i].Field != s[j].Field {
+		return s[i].Field < s[j].Field
+	}
+	return bytes.Compare(s[i].Bytes, s[j].Bytes) < 0
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"testing"
+)
+
+// Returns the documents matching a MatchAllDocsQuery filtered by f.
+func filteredDocs(t *testing.T, ss *IndexSearcher, f Filter) string {
+	c := new(recordingCollector)
+	if err := ss.SearchFilteredCollector(NewMatchAllDocsQuery(), f, c); err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprint(c.docs)
+}
+
+func newTermsFilterTestSearcher(t *testing.T) (*IndexSearcher, func()) {
+	var docs [][]document.IndexableField
+	for i, body := range []string{"a b c", "a a b", "b c", "a", "c d"} {
+		docs = append(docs, []document.IndexableField{
+			document.NewStringField("id", fmt.Sprintf("id%v", i), document.STORE_YES),
+			document.NewTextField("body", body, document.STORE_NO),
+			document.NewStringField("group", fmt.Sprintf("g%v", i%3), document.STORE_NO),
+		})
+	}
+	return newTestSearcherOfDocs(t, docs...)
+}
+
+func TestTermsFilter(t *testing.T) {
+	ss, cleanup := newTermsFilterTestSearcher(t)
+	defer cleanup()
+
+	f := NewTermsFilterOfField("id", []byte("id3"), []byte("id0"), []byte("nosuchid"), []byte("id3"))
+	assertEquals(t, "id:id0 id:id3 id:nosuchid", f.String())
+	assertEquals(t, "[0 3]", filteredDocs(t, ss, f))
+
+	// terms of several fields
+	f = NewTermsFilter(index.NewTerm("body", "d"), index.NewTerm("id", "id1"),
+		index.NewTerm("nosuchfield", "a"), index.NewTerm("body", "d"))
+	assertEquals(t, "body:d id:id1 nosuchfield:a", f.String())
+	assertEquals(t, "[1 4]", filteredDocs(t, ss, f))
+
+	f = NewTermsFilter(index.NewTerm("body", "nosuchterm"))
+	assertEquals(t, "[]", filteredDocs(t, ss, f))
+}
+
+func TestFieldCacheTermsFilter(t *testing.T) {
+	ss, cleanup := newTermsFilterTestSearcher(t)
+	defer cleanup()
+
+	f := NewFieldCacheTermsFilterOfStrings("group", "g2", "g0", "nosuchgroup")
+	assertEquals(t, "group:g2 group:g0 group:nosuchgroup", f.String())
+	assertEquals(t, "[0 2 3]", filteredDocs(t, ss, f))
+
+	f = NewFieldCacheTermsFilter("id", []byte("id4"), []byte("id1"))
+	assertEquals(t, "[1 4]", filteredDocs(t, ss, f))
+
+	f = NewFieldCacheTermsFilterOfStrings("group", "nosuchgroup")
+	assertEquals(t, "[]", filteredDocs(t, ss, f))
+	f = NewFieldCacheTermsFilterOfStrings("nosuchfield", "g0")
+	assertEquals(t, "[]", filteredDocs(t, ss, f))
+}
+
+func TestFieldCacheTermsFilterDocValues(t *testing.T) {
+	var docs [][]document.IndexableField
+	for i := 0; i < 5; i++ {
+		docs = append(docs, []document.IndexableField{
+			document.NewSortedDocValuesField("group", []byte(fmt.Sprintf("g%v", i%3))),
+		})
+	}
+	ss, cleanup := newTestSearcherOfDocs(t, docs...)
+	defer cleanup()
+
+	f := NewFieldCacheTermsFilterOfStrings("group", "g1", "nosuchgroup")
+	assertEquals(t, "[1 4]", filteredDocs(t, ss, f))
+}
@@ -0,0 +1,313 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+)
+
+// search/similarities/TFIDFSimilarity.java
+
+/*
+The hooks of TFIDFSimilarity, which define the factors of the classic
+vector space model, see DefaultSimilarity.
+*/
+type tfidfSimilaritySPI interface {
+	/*
+		Computes a score factor based on the fraction of all query terms
+		that a document contains. This value is multiplied into scores.
+		The presence of a large portion of the query terms indicates a
+		better match with the query, so implementations of this method
+		usually return larger values when the ratio between these
+		parameters is large and smaller values when the ratio between
+		them is small.
+	*/
+	Coord(overlap, maxOverlap int) float32
+	/*
+		Computes the normalization value for a query given the sum of
+		the squared weights of each of the query terms. This value is
+		multiplied into the weight of each query term. While the classic
+		query normalization factor is computed as
+		1/sqrt(sumOfSquaredWeights), other implementations might
+		completely ignore sumOfSquaredWeights (ie return 1).
+
+		This does not affect ranking, but the default implementation
+		does make scores from different queries more comparable than
+		they would be by eliminating the magnitude of the Query vector
+		as a factor in the score.
+	*/
+	QueryNorm(sumOfSquaredWeights float32) float32
+	/*
+		Computes a score factor based on a term or phrase's frequency in
+		a document. This value is multiplied by the Idf() factor for each
+		term in the query and these products are then summed to form the
+		initial score for a document.
+
+		Terms and phrases repeated in a document indicate the topic of
+		the document, so implementations of this method usually return
+		larger values when freq is large, and smaller values when freq
+		is small.
+	*/
+	Tf(freq float32) float32
+	/*
+		Computes a score factor based on a term's document frequency (the
+		number of documents which contain the term). This value is
+		multiplied by the Tf() factor for each term in the query and
+		these products are then summed to form the initial score for a
+		document.
+
+		Terms that occur in fewer documents are better indicators of
+		topic, so implementations of this method usually return larger
+		values for rare terms, and smaller values for common terms.
+	*/
+	Idf(docFreq, numDocs int64) float32
+	/*
+		Compute an index-time normalization value for this field
+		instance.
+
+		This value will be stored in a single byte lossy representation
+		by EncodeNormValue().
+	*/
+	LengthNorm(state *index.FieldInvertState) float32
+	// Encodes a normalization factor for storage in an index.
+	EncodeNormValue(f float32) byte
+	// Decodes a normalization factor stored in an index.
+	DecodeNormValue(b byte) float32
+	/*
+		Computes the amount of a sloppy phrase match, based on an edit
+		distance. This value is summed for each sloppy phrase match in a
+		document to form the frequency to be used in scoring instead of
+		the exact term count.
+
+		A phrase match with a small edit distance to a document passage
+		more closely matches the document, so implementations of this
+		method usually return larger values when the edit distance is
+		small and smaller values when it is large.
+	*/
+	SloppyFreq(distance int) float32
+	// Calculate a scoring factor based on the data in the payload.
+	ScorePayload(doc, start, end int, payload []byte) float32
+}
+
+/*
+Implementation of Similarity with the Vector Space Model.
+
+Expert: Scoring API.
+
+TFIDFSimilarity defines the components of Lucene scoring. Overriding
+computation of these components is a convenient way to alter Lucene
+scoring.
+
+The score of a document d for a query q is:
+
+	score(q,d) = coord(q,d) * queryNorm(q) *
+		sum(t in q) ( tf(t in d) * idf(t)^2 * t.Boost() * norm(t,d) )
+
+where the factors are given by the hooks of the model, implemented
+by DefaultSimilarity:
+
+  - tf(t in d) is Tf() of the number of times term t appears in the
+    currently scored document d.
+  - idf(t) is Idf() of the number of documents in which the term t
+    appears. It is squared, as it is in both the query weight and the
+    document score.
+  - coord(q,d) is Coord() of the number of query terms found in the
+    document, and is computed by BooleanQuery.
+  - queryNorm(q) is QueryNorm() of the sum of the squared weights of
+    the query terms, computed by the query Weight objects.
+  - t.Boost() is the search time boost of term t in the query q, as
+    specified in the query text, or set by application calls to
+    SetBoost().
+  - norm(t,d) is LengthNorm() of the field at indexing time, which
+    encapsulates its index-time boost and its length. It is encoded
+    by EncodeNormValue() in a single byte, so it loses precision.
+*/
+type TFIDFSimilarity struct {
+	spi tfidfSimilaritySPI
+}
+
+// Creates the similarity of the vector space model, whose factors are
+// given by spi.
+func NewTFIDFSimilarity(spi tfidfSimilaritySPI) *TFIDFSimilarity {
+	return &TFIDFSimilarity{spi}
+}
+
+func (s *TFIDFSimilarity) Coord(overlap, maxOverlap int) float32 {
+	return s.spi.Coord(overlap, maxOverlap)
+}
+
+func (s *TFIDFSimilarity) QueryNorm(sumOfSquaredWeights float32) float32 {
+	return s.spi.QueryNorm(sumOfSquaredWeights)
+}
+
+func (s *TFIDFSimilarity) ComputeNorm(state *index.FieldInvertState) int64 {
+	return int64(int8(s.spi.EncodeNormValue(s.spi.LengthNorm(state))))
+}
+
+/*
+Computes a score factor for a simple term, or for a phrase, which is
+the sum of the idfs of its terms, and returns an explanation for it.
+*/
+func (s *TFIDFSimilarity) idfExplain(collectionStats CollectionStatistics, termStats ...TermStatistics) *Explanation {
+	max := collectionStats.MaxDoc()
+	if len(termStats) == 1 {
+		df := termStats[0].DocFreq
+		return NewExplanation(s.spi.Idf(df, max), fmt.Sprintf("idf(docFreq=%v, maxDocs=%v)", df, max))
+	}
+	exp := NewExplanation(0, "idf(), sum of:")
+	var idf float32
+	for _, stat := range termStats {
+		df := stat.DocFreq
+		termIdf := s.spi.Idf(df, max)
+		exp.AddDetail(NewExplanation(termIdf, fmt.Sprintf("idf(docFreq=%v, maxDocs=%v)", df, max)))
+		idf += termIdf
+	}
+	exp.SetValue(idf)
+	return exp
+}
+
+func (s *TFIDFSimilarity) ComputeWeight(queryBoost float32,
+	collectionStats CollectionStatistics, termStats ...TermStatistics) SimWeight {
+	return newIDFStats(collectionStats.Field(), s.idfExplain(collectionStats, termStats...), queryBoost)
+}
+
+func (s *TFIDFSimilarity) ExactSimScorer(w SimWeight, ctx index.AtomicReaderContext) (ExactSimScorer, error) {
+	stats := w.(*idfStats)
+	norms, err := ctx.Reader().(index.AtomicReader).NormValues(stats.field)
+	if err != nil {
+		return nil, err
+	}
+	return &exactTFIDFDocScorer{s, stats, norms}, nil
+}
+
+func (s *TFIDFSimilarity) SloppySimScorer(w SimWeight, ctx index.AtomicReaderContext) (SloppySimScorer, error) {
+	stats := w.(*idfStats)
+	norms, err := ctx.Reader().(index.AtomicReader).NormValues(stats.field)
+	if err != nil {
+		return nil, err
+	}
+	return &sloppyTFIDFDocScorer{s, stats, norms}, nil
+}
+
+// Explains the score of the document, whose term frequency is freq.
+func (s *TFIDFSimilarity) explainScore(doc int, freq *Explanation,
+	stats *idfStats, norms index.NumericDocValues) *Explanation {
+	result := NewExplanation(0, fmt.Sprintf("score(doc=%v,freq=%v), product of:", doc, freq))
+
+	// explain query weight
+	queryExpl := NewExplanation(0, "queryWeight, product of:")
+	boostExpl := NewExplanation(stats.queryBoost, "boost")
+	if stats.queryBoost != 1 {
+		queryExpl.AddDetail(boostExpl)
+	}
+	queryExpl.AddDetail(stats.idf)
+	queryNormExpl := NewExplanation(stats.queryNorm, "queryNorm")
+	queryExpl.AddDetail(queryNormExpl)
+	queryExpl.SetValue(boostExpl.Value() * stats.idf.Value() * queryNormExpl.Value())
+	result.AddDetail(queryExpl)
+
+	// explain field weight
+	fieldExpl := NewExplanation(0, fmt.Sprintf("fieldWeight in %v, product of:", doc))
+	tfExplanation := NewExplanation(s.spi.Tf(freq.Value()),
+		fmt.Sprintf("tf(freq=%v), with freq of:", freq.Value()))
+	tfExplanation.AddDetail(freq)
+	fieldExpl.AddDetail(tfExplanation)
+	fieldExpl.AddDetail(stats.idf)
+	fieldNorm := float32(1)
+	if norms != nil {
+		fieldNorm = s.spi.DecodeNormValue(byte(norms.Get(doc)))
+	}
+	fieldExpl.AddDetail(NewExplanation(fieldNorm, fmt.Sprintf("fieldNorm(doc=%v)", doc)))
+	fieldExpl.SetMatch(tfExplanation.IsMatch())
+	fieldExpl.SetValue(tfExplanation.Value() * stats.idf.Value() * fieldNorm)
+	result.AddDetail(fieldExpl)
+	result.SetMatch(fieldExpl.IsMatch())
+
+	// combine them
+	result.SetValue(queryExpl.Value() * fieldExpl.Value())
+	if queryExpl.Value() == 1 {
+		return fieldExpl
+	}
+	return result
+}
+
+// search/similarities/TFIDFSimilarity.java/ExactTFIDFDocScorer
+
+type exactTFIDFDocScorer struct {
+	sim   *TFIDFSimilarity
+	stats *idfStats
+	norms index.NumericDocValues
+}
+
+func (s *exactTFIDFDocScorer) Score(doc, freq int) float32 {
+	raw := s.sim.spi.Tf(float32(freq)) * s.stats.value // compute tf(f)*weight
+	if s.norms == nil {
+		return raw
+	}
+	return raw * s.sim.spi.DecodeNormValue(byte(s.norms.Get(doc))) // normalize for field
+}
+
+func (s *exactTFIDFDocScorer) Explain(doc int, freq *Explanation) *Explanation {
+	return s.sim.explainScore(doc, freq, s.stats, s.norms)
+}
+
+// search/similarities/TFIDFSimilarity.java/SloppyTFIDFDocScorer
+
+type sloppyTFIDFDocScorer struct {
+	sim   *TFIDFSimilarity
+	stats *idfStats
+	norms index.NumericDocValues
+}
+
+func (s *sloppyTFIDFDocScorer) Score(doc int, freq float32) float32 {
+	raw := s.sim.spi.Tf(freq) * s.stats.value // compute tf(f)*weight
+	if s.norms == nil {
+		return raw
+	}
+	return raw * s.sim.spi.DecodeNormValue(byte(s.norms.Get(doc))) // normalize for field
+}
+
+func (s *sloppyTFIDFDocScorer) ComputeSlopFactor(distance int) float32 {
+	return s.sim.spi.SloppyFreq(distance)
+}
+
+func (s *sloppyTFIDFDocScorer) ComputePayloadFactor(doc, start, end int, payload []byte) float32 {
+	return s.sim.spi.ScorePayload(doc, start, end, payload)
+}
+
+func (s *sloppyTFIDFDocScorer) Explain(doc int, freq *Explanation) *Explanation {
+	return s.sim.explainScore(doc, freq, s.stats, s.norms)
+}
+
+// search/similarities/TFIDFSimilarity.java/IDFStats
+
+// Collection statistics for the TF-IDF model. The only statistic of
+// interest to this model is idf.
+type idfStats struct {
+	field string
+	// The idf and its explanation
+	idf         *Explanation
+	queryNorm   float32
+	queryWeight float32
+	queryBoost  float32
+	value       float32
+}
+
+func newIDFStats(field string, idf *Explanation, queryBoost float32) *idfStats {
+	return &idfStats{
+		field:       field,
+		idf:         idf,
+		queryBoost:  queryBoost,
+		queryWeight: idf.Value() * queryBoost, // compute query weight
+	}
+}
+
+func (s *idfStats) ValueForNormalization() float32 {
+	// TODO: (sorta LUCENE-1907) make non-static class and expose this squaring via a nice method to subclasses?
+	return s.queryWeight * s.queryWeight // sum of squared weights
+}
+
+func (s *idfStats) Normalize(queryNorm, topLevelBoost float32) {
+	s.queryNorm = queryNorm * topLevelBoost
+	s.queryWeight *= s.queryNorm            // normalize query weight
+	s.value = s.queryWeight * s.idf.Value() // idf for document
+}
@@ -0,0 +1,267 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// search/TimeLimitingCollector.java/TimeExceededException
+
+/*
+Returned by TimeLimitingCollector.Collect() when the time allowed
+has elapsed. The documents collected so far by the wrapped collector
+are the partial results of the search.
+*/
+type TimeExceededError struct {
+	// Time allowed, in ticks of the clock.
+	TimeAllowed int64
+	// Time elapsed, in ticks of the clock.
+	TimeElapsed int64
+	// Last doc (absolute doc id) that was collected when the search
+	// time exceeded.
+	LastDocCollected int
+}
+
+func (e *TimeExceededError) Error() string {
+	return fmt.Sprintf("Elapsed time: %v. Exceeded allowed search time: %v ms.",
+		e.TimeElapsed, e.TimeAllowed)
+}
+
+// search/TimeLimitingCollector.java
+
+/*
+The TimeLimitingCollector is used to timeout search requests that
+take longer than the maximum allowed search time limit. After this
+time is exceeded, the search goroutine is stopped by returning a
+TimeExceededError, and the wrapped collector holds the partial
+results.
+*/
+type TimeLimitingCollector struct {
+	t0           int64
+	timeout      int64
+	collector    Collector
+	clock        util.Counter
+	ticksAllowed int64
+	greedy       bool
+	docBase      int
+}
+
+/*
+Create a TimeLimitingCollector wrapper over another Collector with a
+specified timeout.
+
+clock is the timer clock, e.g. GlobalTimerCounter(), and ticksAllowed
+the max time allowed for collecting hits after which
+TimeExceededError is returned.
+*/
+func NewTimeLimitingCollector(collector Collector, clock util.Counter, ticksAllowed int64) *TimeLimitingCollector {
+	return &TimeLimitingCollector{
+		t0:           math.MinInt64,
+		timeout:      math.MinInt64,
+		collector:    collector,
+		clock:        clock,
+		ticksAllowed: ticksAllowed,
+	}
+}
+
+/*
+Sets the baseline for this collector. By default the collectors
+baseline is initialized once the first reader is passed to the
+collector. To include operations executed in prior to the actual
+document collection set the baseline through this method in your
+prelude.
+
+Example usage:
+
+	clock := search.GlobalTimerCounter()
+	baseline := clock.Get()
+	// ... prepare search
+	collector := search.NewTimeLimitingCollector(c, clock, numTicks)
+	collector.SetBaselineAt(baseline)
+	ss.SearchCollector(q, collector)
+*/
+func (c *TimeLimitingCollector) SetBaselineAt(clockTime int64) {
+	c.t0 = clockTime
+	c.timeout = c.t0 + c.ticksAllowed
+}
+
+// Syntactic sugar for SetBaselineAt() using clock.Get().
+func (c *TimeLimitingCollector) SetBaseline() {
+	c.SetBaselineAt(c.clock.Get())
+}
+
+/*
+Checks if this time limited collector is greedy in collecting the
+last hit. A non greedy collector, upon a timeout, would return a
+TimeExceededError without allowing the wrapped collector to collect
+the current doc. A greedy one would first allow the wrapped hit
+collector to collect the current doc and only then return the
+TimeExceededError.
+*/
+func (c *TimeLimitingCollector) IsGreedy() bool {
+	return c.greedy
+}
+
+// Sets whether this time limited collector is greedy.
+func (c *TimeLimitingCollector) SetGreedy(greedy bool) {
+	c.greedy = greedy
+}
+
+/*
+Calls Collect() on the decorated Collector unless the allowed time
+has passed, in which case it returns a TimeExceededError.
+*/
+func (c *TimeLimitingCollector) Collect(doc int) error {
+	if now := c.clock.Get(); c.timeout < now {
+		if c.greedy {
+			if err := c.collector.Collect(doc); err != nil {
+				return err
+			}
+		}
+		return &TimeExceededError{c.timeout - c.t0, now - c.t0, c.docBase + doc}
+	}
+	return c.collector.Collect(doc)
+}
+
+func (c *TimeLimitingCollector) SetNextReader(ctx index.AtomicReaderContext) error {
+	if err := c.collector.SetNextReader(ctx); err != nil {
+		return err
+	}
+	c.docBase = ctx.DocBase
+	if c.t0 == math.MinInt64 {
+		c.SetBaseline()
+	}
+	return nil
+}
+
+func (c *TimeLimitingCollector) SetScorer(scorer Scorer) {
+	c.collector.SetScorer(scorer)
+}
+
+func (c *TimeLimitingCollector) AcceptsDocsOutOfOrder() bool {
+	return c.collector.AcceptsDocsOutOfOrder()
+}
+
+/*
+This is so the same timer can be used with a multi-phase search
+process such as grouping. We don't want to create a new
+TimeLimitingCollector for each phase because that would reset the
+timer for each phase. Once time is up subsequent phases need to
+timeout quickly.
+*/
+func (c *TimeLimitingCollector) SetCollector(collector Collector) {
+	c.collector = collector
+}
+
+var globalTimerThread struct {
+	sync.Once
+	*TimerThread
+}
+
+/*
+Returns the global TimerThread's Counter, whose ticks are
+milliseconds.
+
+Invoking this creates and starts the global TimerThread, if not
+started yet.
+*/
+func GlobalTimerCounter() util.Counter {
+	return GlobalTimerThread().counter
+}
+
+/*
+Returns the global TimerThread.
+
+Invoking this creates and starts the global TimerThread, if not
+started yet.
+*/
+func GlobalTimerThread() *TimerThread {
+	globalTimerThread.Do(func() {
+		globalTimerThread.TimerThread = NewTimerThread(TIMER_THREAD_DEFAULT_RESOLUTION, util.NewCounter(true))
+		globalTimerThread.Start()
+	})
+	return globalTimerThread.TimerThread
+}
+
+// search/TimeLimitingCollector.java/TimerThread
+
+// The default timer resolution, in milliseconds.
+const TIMER_THREAD_DEFAULT_RESOLUTION = 20
+
+/*
+Timer goroutine, which increments its counter by its resolution, each
+resolution milliseconds.
+
+This is a much cheaper way to know the elapsed time than calling
+time.Now() for each collected document. Its precision is the
+resolution.
+*/
+type TimerThread struct {
+	stop       int32 // atomic
+	resolution int64 // atomic
+	counter    util.Counter
+}
+
+/*
+Creates a timer, whose counter is incremented each resolution
+milliseconds, which must be a thread safe Counter. The timer is not
+started.
+*/
+func NewTimerThread(resolution int64, counter util.Counter) *TimerThread {
+	return &TimerThread{resolution: resolution, counter: counter}
+}
+
+// Starts the timer in its own goroutine.
+func (t *TimerThread) Start() {
+	go t.run()
+}
+
+func (t *TimerThread) run() {
+	for atomic.LoadInt32(&t.stop) == 0 {
+		resolution := atomic.LoadInt64(&t.resolution)
+		t.counter.AddAndGet(resolution)
+		time.Sleep(time.Duration(resolution) * time.Millisecond)
+	}
+}
+
+// Get the timer value in milliseconds.
+func (t *TimerThread) Milliseconds() int64 {
+	return t.counter.Get()
+}
+
+// Stops the timer.
+func (t *TimerThread) StopTimer() {
+	atomic.StoreInt32(&t.stop, 1)
+}
+
+// Return the timer resolution.
+func (t *TimerThread) Resolution() int64 {
+	return atomic.LoadInt64(&t.resolution)
+}
+
+/*
+Set the timer resolution. The default timer resolution is 20
+milliseconds. This means that a search required to take no longer
+than 800 milliseconds may be stopped after 780 to 820 milliseconds.
+
+Note that:
+
+  - Finer (smaller) resolution is more accurate but less efficient.
+  - Setting resolution to less than 5 milliseconds will be silently
+    modified to 5 milliseconds.
+  - Setting resolution smaller than current resolution might take
+    effect only after current resolution. (Assume current resolution
+    of 20 milliseconds is modified to 5 milliseconds, then it can take
+    up to 20 milliseconds for the change to have effect.
+*/
+func (t *TimerThread) SetResolution(resolution int64) {
+	if resolution < 5 {
+		resolution = 5 // 5 milliseconds is about the minimum reasonable time for a time.Sleep() call.
+	}
+	atomic.StoreInt64(&t.resolution, resolution)
+}
@@ -0,0 +1,81 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/util"
+	"testing"
+	"time"
+)
+
+// A collector which advances the clock by one tick per collected doc.
+type tickingCollector struct {
+	*TopScoreDocCollector
+	clock util.Counter
+}
+
+func (c *tickingCollector) Collect(doc int) error {
+	c.clock.AddAndGet(1)
+	return c.TopScoreDocCollector.Collect(doc)
+}
+
+func TestTimeLimitingCollector(t *testing.T) {
+	ss, cleanup := newTestSearcher(t, "a", "a", "a", "a", "a")
+	defer cleanup()
+
+	for _, greedy := range []bool{false, true} {
+		clock := util.NewCounter(false)
+		top, err := NewTopScoreDocCollector(10, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := NewTimeLimitingCollector(&tickingCollector{top, clock}, clock, 2)
+		c.SetGreedy(greedy)
+		assertEquals(t, greedy, c.IsGreedy())
+
+		err = ss.SearchCollector(newBodyTermQuery("a"), c)
+		timeExceeded, ok := err.(*TimeExceededError)
+		if !ok {
+			t.Fatalf("Expected TimeExceededError, but %v", err)
+		}
+		assertEquals(t, int64(2), timeExceeded.TimeAllowed)
+		assertEquals(t, int64(3), timeExceeded.TimeElapsed)
+		assertEquals(t, 3, timeExceeded.LastDocCollected)
+		// the partial results
+		if greedy {
+			assertEquals(t, 4, top.TotalHits())
+		} else {
+			assertEquals(t, 3, top.TotalHits())
+		}
+	}
+
+	// enough time
+	clock := util.NewCounter(false)
+	top, err := NewTopScoreDocCollector(10, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewTimeLimitingCollector(&tickingCollector{top, clock}, clock, 10)
+	if err = ss.SearchCollector(newBodyTermQuery("a"), c); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 5, top.TotalHits())
+
+	// the baseline may be set before searching
+	c = NewTimeLimitingCollector(top, clock, 10)
+	c.SetBaselineAt(clock.Get() - 20)
+	if _, ok := ss.SearchCollector(newBodyTermQuery("a"), c).(*TimeExceededError); !ok {
+		t.Error("Expected TimeExceededError")
+	}
+}
+
+func TestTimerThread(t *testing.T) {
+	timer := NewTimerThread(1, util.NewCounter(true))
+	timer.SetResolution(1)
+	assertEquals(t, int64(5), timer.Resolution())
+	timer.Start()
+	defer timer.StopTimer()
+
+	for start := timer.Milliseconds(); timer.Milliseconds() < start+10; {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assertEquals(t, GlobalTimerCounter(), GlobalTimerThread().counter)
+}
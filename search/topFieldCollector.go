@@ -0,0 +1,351 @@
+package search
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"math"
+)
+
+// search/FieldDoc.java
+
+/*
+Expert: A ScoreDoc which also contains information about how to sort
+the referenced document. In addition to the document number and
+score, this object contains an array of values for the document from
+the field(s) used to sort. For example, if the sort criteria was to
+sort by fields "a", "b" then "c", the fields object array will have
+three elements, corresponding respectively to the term values for the
+document in fields "a", "b" and "c". The class of each element in the
+array will be either int32, int64, float32 or []byte depending on the
+type of values in the terms of each field.
+*/
+type FieldDoc struct {
+	*ScoreDoc
+	/*
+		Expert: The values which are used to sort the referenced
+		document. The order of these will match the original sort
+		criteria given by a Sort object. Each Object will have been
+		returned from the Value() method corresponding FieldComparator
+		used to sort this field, or nil if the fields were not filled.
+	*/
+	Fields []interface{}
+}
+
+func (d *FieldDoc) String() string {
+	return fmt.Sprintf("%v fields=%v", d.ScoreDoc, d.Fields)
+}
+
+// search/TopFieldDocs.java
+
+/*
+Represents hits returned by IndexSearcher.SearchSorted(). Its
+ScoreDocs are those of its FieldDocs, in the same order.
+*/
+type TopFieldDocs struct {
+	TopDocs
+	// The fields which were used to sort results by.
+	Fields []*SortField
+	// The top hits, with the values they are sorted by.
+	FieldDocs []*FieldDoc
+}
+
+// search/FieldValueHitQueue.java/Entry
+
+// An entry of fieldValueHitQueue, whose sort values are in the slot
+// of the comparators.
+type fieldValueHitQueueEntry struct {
+	*ScoreDoc
+	slot int
+}
+
+// search/FieldValueHitQueue.java
+
+/*
+Expert: A hit queue for sorting by hits by terms in more than one
+field, using the FieldComparators of the fields.
+
+Its least element, the top, is the entry which sorts last, and entries
+which sort the same are sorted by ascending doc id.
+*/
+type fieldValueHitQueue struct {
+	entries []*fieldValueHitQueueEntry
+	// Stores the sort criteria being used.
+	fields      []*SortField
+	comparators []FieldComparator
+	reverseMul  []int
+}
+
+func newFieldValueHitQueue(fields []*SortField, size int) (*fieldValueHitQueue, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("Sort must contain at least one field")
+	}
+	ans := &fieldValueHitQueue{
+		entries:     make([]*fieldValueHitQueueEntry, 0, size),
+		fields:      fields,
+		comparators: make([]FieldComparator, len(fields)),
+		reverseMul:  make([]int, len(fields)),
+	}
+	for i, field := range fields {
+		// the primary comparator is the first one, for sortPos==0
+		ans.comparators[i] = field.Comparator(size, i)
+		ans.reverseMul[i] = 1
+		if field.reverse {
+			ans.reverseMul[i] = -1
+		}
+	}
+	return ans, nil
+}
+
+func (pq *fieldValueHitQueue) Len() int { return len(pq.entries) }
+
+func (pq *fieldValueHitQueue) Less(i, j int) bool {
+	hitA, hitB := pq.entries[i], pq.entries[j]
+	for k, comparator := range pq.comparators {
+		if c := pq.reverseMul[k] * comparator.Compare(hitA.slot, hitB.slot); c != 0 {
+			// Short circuit
+			return c > 0
+		}
+	}
+	// avoid random sort order that could lead to duplicates
+	return hitA.Doc > hitB.Doc
+}
+
+func (pq *fieldValueHitQueue) Swap(i, j int) {
+	pq.entries[i], pq.entries[j] = pq.entries[j], pq.entries[i]
+}
+
+func (pq *fieldValueHitQueue) Push(x interface{}) {
+	pq.entries = append(pq.entries, x.(*fieldValueHitQueueEntry))
+}
+
+func (pq *fieldValueHitQueue) Pop() interface{} {
+	n := len(pq.entries)
+	ans := pq.entries[n-1]
+	pq.entries = pq.entries[:n-1]
+	return ans
+}
+
+/*
+Given a queue Entry, creates a corresponding FieldDoc that contains
+the values used to sort the given document. These values are not the
+raw values out of the index, but the internal representation of them.
+This is so the given search hit can be collated by a MultiSearcher
+with other search hits.
+*/
+func (pq *fieldValueHitQueue) fillFields(entry *fieldValueHitQueueEntry) *FieldDoc {
+	fields := make([]interface{}, len(pq.comparators))
+	for i, comparator := range pq.comparators {
+		fields[i] = comparator.Value(entry.slot)
+	}
+	return &FieldDoc{entry.ScoreDoc, fields}
+}
+
+// search/TopFieldCollector.java
+
+/*
+A Collector that sorts by SortField using FieldComparators.
+
+See NewTopFieldCollector() for the options.
+*/
+type TopFieldCollector struct {
+	*TopDocsCollector
+	queue       *fieldValueHitQueue
+	comparators []FieldComparator
+	reverseMul  []int
+	numHits     int
+	bottom      *fieldValueHitQueueEntry
+	queueFull   bool
+	docBase     int
+	scorer      Scorer
+
+	fillFields bool
+	// Stores the maximum score value encountered, needed for
+	// normalizing. If document scores are not tracked, this value is
+	// initialized to NaN.
+	maxScore       float32
+	trackDocScores bool
+	trackMaxScore  bool
+	inOrder        bool
+	// the FieldDocs of the last results
+	fieldDocs []*FieldDoc
+}
+
+/*
+Creates a new TopFieldCollector from the given arguments.
+
+NOTE: The instances returned by this method pre-allocate a full array
+of length numHits.
+
+  - sort: the sort criteria (SortFields).
+  - numHits: the number of results to collect.
+  - fillFields: specifies whether the actual field values should be
+    returned on the results (FieldDoc).
+  - trackDocScores: specifies whether document scores should be
+    tracked and set on the results. Note that if set to false, then
+    the results' scores will be set to NaN. Setting this to true
+    affects performance, as it incurs the score computation on each
+    competitive result. Therefore if document scores are not required
+    by the application, it is recommended to set it to false.
+  - trackMaxScore: specifies whether the query's maxScore should be
+    tracked and set on the resulting TopDocs. Note that if set to
+    false, MaxScore returns NaN. Setting this to true affects
+    performance as it incurs the score computation on each result.
+    Also, setting this true automatically sets trackDocScores to true
+    as well.
+  - docsScoredInOrder: specifies whether documents are scored in doc
+    Id order or not by the given Scorer in SetScorer().
+
+Returns an error if the sort criteria contains no field, or numHits
+is not positive.
+*/
+func NewTopFieldCollector(sort *Sort, numHits int, fillFields, trackDocScores,
+	trackMaxScore, docsScoredInOrder bool) (*TopFieldCollector, error) {
+	if numHits <= 0 {
+		return nil, errors.New("numHits must be > 0; please use TotalHitCountCollector if you just need the total hit count")
+	}
+	queue, err := newFieldValueHitQueue(sort.fields, numHits)
+	if err != nil {
+		return nil, err
+	}
+	ans := &TopFieldCollector{
+		queue:          queue,
+		comparators:    queue.comparators,
+		reverseMul:     queue.reverseMul,
+		numHits:        numHits,
+		fillFields:     fillFields,
+		maxScore:       float32(math.NaN()),
+		trackDocScores: trackDocScores || trackMaxScore,
+		trackMaxScore:  trackMaxScore,
+		inOrder:        docsScoredInOrder,
+	}
+	if trackMaxScore {
+		ans.maxScore = float32(math.Inf(-1))
+	}
+	ans.TopDocsCollector = newTopDocsCollector(ans, queue)
+	return ans, nil
+}
+
+func (c *TopFieldCollector) SetNextReader(ctx index.AtomicReaderContext) error {
+	c.docBase = ctx.DocBase
+	for i, comparator := range c.comparators {
+		comparator, err := comparator.SetNextReader(ctx)
+		if err != nil {
+			return err
+		}
+		c.comparators[i] = comparator
+	}
+	return nil
+}
+
+func (c *TopFieldCollector) SetScorer(scorer Scorer) {
+	c.scorer = scorer
+	for _, comparator := range c.comparators {
+		comparator.SetScorer(scorer)
+	}
+}
+
+func (c *TopFieldCollector) Collect(doc int) error {
+	score := float32(math.NaN())
+	if c.trackDocScores {
+		score = c.scorer.Score()
+		if c.trackMaxScore && score > c.maxScore {
+			c.maxScore = score
+		}
+	}
+	c.totalHits++
+	if c.queueFull {
+		// Fastmatch: return if this hit is not competitive
+		for i, comparator := range c.comparators {
+			cmp := c.reverseMul[i] * comparator.CompareBottom(doc)
+			if cmp < 0 {
+				// Definitely not competitive.
+				return nil
+			} else if cmp > 0 {
+				// Definitely competitive.
+				break
+			} else if i == len(c.comparators)-1 {
+				// Here cmp=0. If we're at the last comparator, this doc is
+				// not competitive if docs are visited in doc Id order,
+				// which means this doc cannot compete with any other
+				// document in the queue, or else if it's after the bottom
+				// doc.
+				if c.inOrder || doc+c.docBase > c.bottom.Doc {
+					return nil
+				}
+			}
+		}
+
+		// This hit is competitive - replace bottom element in queue &
+		// adjustTop
+		for _, comparator := range c.comparators {
+			comparator.Copy(c.bottom.slot, doc)
+		}
+		c.bottom.Doc = c.docBase + doc
+		c.bottom.Score = score
+		heap.Fix(c.queue, 0)
+		c.bottom = c.queue.entries[0]
+		for _, comparator := range c.comparators {
+			comparator.SetBottom(c.bottom.slot)
+		}
+	} else {
+		// Startup transient: queue hasn't gathered numHits yet
+		slot := c.totalHits - 1
+		for _, comparator := range c.comparators {
+			comparator.Copy(slot, doc)
+		}
+		heap.Push(c.queue, &fieldValueHitQueueEntry{NewScoreDoc(c.docBase+doc, score), slot})
+		c.bottom = c.queue.entries[0]
+		if c.queueFull = c.totalHits == c.numHits; c.queueFull {
+			for _, comparator := range c.comparators {
+				comparator.SetBottom(c.bottom.slot)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *TopFieldCollector) AcceptsDocsOutOfOrder() bool {
+	return !c.inOrder
+}
+
+func (c *TopFieldCollector) populateResults(results []*ScoreDoc, howMany int) {
+	c.fieldDocs = make([]*FieldDoc, howMany)
+	for i := howMany - 1; i >= 0; i-- {
+		entry := heap.Pop(c.queue).(*fieldValueHitQueueEntry)
+		if c.fillFields {
+			c.fieldDocs[i] = c.queue.fillFields(entry)
+		} else {
+			c.fieldDocs[i] = &FieldDoc{entry.ScoreDoc, nil}
+		}
+		results[i] = entry.ScoreDoc
+	}
+}
+
+func (c *TopFieldCollector) newTopDocs(results []*ScoreDoc, start int) TopDocs {
+	if results == nil {
+		c.fieldDocs = []*FieldDoc{}
+		// Set maxScore to NaN, in case this is a maxScore tracking
+		// collector.
+		return TopDocs{c.totalHits, []*ScoreDoc{}, float32(math.NaN())}
+	}
+	return TopDocs{c.totalHits, results, c.maxScore}
+}
+
+// Returns the top sorted docs that were collected by this collector.
+func (c *TopFieldCollector) TopFieldDocs() TopFieldDocs {
+	return c.topFieldDocs(c.TopDocs())
+}
+
+/*
+Returns the sorted documents in the range [start .. start+howMany)
+that were collected by this collector, like TopDocsRange().
+*/
+func (c *TopFieldCollector) TopFieldDocsRange(start, howMany int) TopFieldDocs {
+	return c.topFieldDocs(c.TopDocsRange(start, howMany))
+}
+
+func (c *TopFieldCollector) topFieldDocs(docs TopDocs) TopFieldDocs {
+	return TopFieldDocs{docs, c.queue.fields, c.fieldDocs}
+}
@@ -0,0 +1,345 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/document"
+	"github.com/balzaczyy/golucene/index"
+	"math"
+	"testing"
+)
+
+// Opens a searcher over two segments of documents, whose "name" is
+// missing if "", and whose "num" is a numeric doc value, also indexed
+// as "inum" along with the names.
+func newSortTestSearcher(t *testing.T) (*IndexSearcher, func()) {
+	names := []string{"c", "a", "", "e", "b", "a", "d", ""}
+	nums := []int64{5, -3, 2, 5, 0, 7, -3, 1}
+	var subs []index.IndexReader
+	var cleanups []func()
+	for seg := 0; seg < 2; seg++ {
+		var docs [][]document.IndexableField
+		for i := seg * 4; i < seg*4+4; i++ {
+			doc := []document.IndexableField{
+				document.NewTextField("body", "x"+string(" x x x"[:2*(i%4)]), document.STORE_NO),
+				document.NewNumericDocValuesField("num", nums[i]),
+			}
+			if names[i] != "" {
+				doc = append(doc, document.NewStringField("name", names[i], document.STORE_NO),
+					document.NewIntField("inum", int(nums[i]), document.STORE_NO))
+			}
+			docs = append(docs, doc)
+		}
+		ss, cleanup := newTestSearcherOfDocs(t, docs...)
+		subs = append(subs, ss.IndexReader())
+		cleanups = append(cleanups, cleanup)
+	}
+	return NewIndexSearcher(index.NewMultiReader(subs, false)), func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}
+}
+
+// Returns the docs of the hits, and the values they are sorted by.
+func sortedHits(t *testing.T, ss *IndexSearcher, q Query, n int, sort *Sort) string {
+	docs, err := ss.SearchSorted(q, nil, n, sort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, len(docs.ScoreDocs), len(docs.FieldDocs))
+	var ids, values []string
+	for i, hit := range docs.FieldDocs {
+		assertEquals(t, docs.ScoreDocs[i], hit.ScoreDoc)
+		ids = append(ids, fmt.Sprint(hit.Doc))
+		for _, v := range hit.Fields {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+	return fmt.Sprint(ids, values)
+}
+
+func TestSearchSorted(t *testing.T) {
+	ss, cleanup := newSortTestSearcher(t)
+	defer cleanup()
+
+	q := NewMatchAllDocsQuery()
+	for _, v := range []struct {
+		sort *Sort
+		n    int
+		hits string
+	}{
+		{NewSort(NewSortField("name", SORT_FIELD_TYPE_STRING, false)), 10,
+			"[2 7 1 5 4 0 6 3] [  a a b c d e]"},
+		{NewSort(NewSortField("name", SORT_FIELD_TYPE_STRING, false)), 3,
+			"[2 7 1] [  a]"},
+		{NewSort(NewSortField("name", SORT_FIELD_TYPE_STRING, true)), 10,
+			"[3 6 0 4 1 5 2 7] [e d c b a a  ]"},
+		{NewSort(NewSortField("name", SORT_FIELD_TYPE_STRING, true)), 5,
+			"[3 6 0 4 1] [e d c b a]"},
+		{NewSort(NewSortField("num", SORT_FIELD_TYPE_LONG, false)), 10,
+			"[1 6 4 7 2 0 3 5] [-3 -3 0 1 2 5 5 7]"},
+		{NewSort(NewSortField("num", SORT_FIELD_TYPE_INT, false)), 4,
+			"[1 6 4 7] [-3 -3 0 1]"},
+		{NewSort(NewSortField("num", SORT_FIELD_TYPE_INT, true),
+			NewSortField("name", SORT_FIELD_TYPE_STRING, true)), 10,
+			"[5 3 0 2 7 4 6 1] [7 a 5 e 5 c 2  1  0 b -3 d -3 a]"},
+		{NewSort(NewSortField("nosuchfield", SORT_FIELD_TYPE_LONG, false)), 3,
+			"[0 1 2] [0 0 0]"},
+		{SORT_INDEXORDER, 3, "[0 1 2] [0 1 2]"},
+		{NewSort(SORT_FIELD_SCORE, SORT_FIELD_DOC), 3, "[0 1 2] [1 0 1 1 1 2]"},
+	} {
+		assertEquals(t, v.hits, sortedHits(t, ss, q, v.n, v.sort))
+	}
+}
+
+func TestSearchSortedScores(t *testing.T) {
+	ss, cleanup := newSortTestSearcher(t)
+	defer cleanup()
+
+	q := newBodyTermQuery("x")
+	top, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same hits as by relevance
+	docs, err := ss.SearchSorted(q, nil, 10, SORT_RELEVANCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 8, docs.TotalHits)
+	for i, hit := range top.ScoreDocs {
+		assertEquals(t, hit.Doc, docs.ScoreDocs[i].Doc)
+		assertEquals(t, hit.Score, docs.FieldDocs[i].Fields[0])
+		// scores are not tracked by default
+		assertEquals(t, true, math.IsNaN(float64(docs.ScoreDocs[i].Score)))
+	}
+	assertEquals(t, true, math.IsNaN(float64(docs.MaxScore)))
+
+	byName := NewSort(NewSortField("name", SORT_FIELD_TYPE_STRING, false))
+	docs, err = ss.SearchSortedWithScores(q, nil, 10, byName, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, top.MaxScore, docs.MaxScore)
+	assertEquals(t, fmt.Sprint(hitScores(t, ss, q)), fmt.Sprint(func() map[int]float32 {
+		scores := make(map[int]float32)
+		for _, hit := range docs.ScoreDocs {
+			scores[hit.Doc] = hit.Score
+		}
+		return scores
+	}()))
+}
+
+func TestTopFieldCollector(t *testing.T) {
+	scores := []float32{1, 3, 2, 3, 1, 2, 3}
+	for _, order := range [][]int{{0, 1, 2, 3, 4, 5, 6}, {6, 4, 5, 3, 0, 2, 1}} {
+		inOrder := order[0] == 0
+		c, err := NewTopFieldCollector(SORT_RELEVANCE, 4, false, true, true, inOrder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, !inOrder, c.AcceptsDocsOutOfOrder())
+		scorer := new(fixedScorer)
+		c.SetScorer(scorer)
+		for _, doc := range order {
+			scorer.score = scores[doc]
+			if err := c.Collect(doc); err != nil {
+				t.Fatal(err)
+			}
+		}
+		docs := c.TopFieldDocs()
+		assertEquals(t, 7, docs.TotalHits)
+		assertEquals(t, float32(3), docs.MaxScore)
+		assertEquals(t, "[doc=1 score=3 shardIndex=-1 doc=3 score=3 shardIndex=-1 doc=6 score=3 shardIndex=-1 doc=2 score=2 shardIndex=-1]",
+			fmt.Sprint(docs.ScoreDocs))
+		assertEquals(t, 0, len(c.TopFieldDocs().ScoreDocs))
+	}
+
+	if _, err := NewTopFieldCollector(NewSort(), 0, true, false, false, true); err == nil {
+		t.Error("Expected error for no hit to collect")
+	}
+	assertEquals(t, `<string: "name">!,<long: "num">,<score>,<doc>`, NewSort(
+		NewSortField("name", SORT_FIELD_TYPE_STRING, true), NewSortField("num", SORT_FIELD_TYPE_LONG, false),
+		SORT_FIELD_SCORE, SORT_FIELD_DOC).String())
+}
+
+func TestSearchSortedMissingValues(t *testing.T) {
+	ss, cleanup := newSortTestSearcher(t)
+	defer cleanup()
+
+	newSortField := func(field string, typ SortFieldType, reverse bool, missingValue interface{}) *SortField {
+		ans := NewSortField(field, typ, reverse)
+		ans.SetMissingValue(missingValue)
+		return ans
+	}
+	q := NewMatchAllDocsQuery()
+	for _, v := range []struct {
+		sort *Sort
+		n    int
+		hits string
+	}{
+		{NewSort(newSortField("name", SORT_FIELD_TYPE_STRING, false, SORT_FIELD_STRING_LAST)), 10,
+			"[1 5 4 0 6 3 2 7] [a a b c d e  ]"},
+		{NewSort(newSortField("name", SORT_FIELD_TYPE_STRING, false, SORT_FIELD_STRING_LAST)), 3,
+			"[1 5 4] [a a b]"},
+		{NewSort(newSortField("name", SORT_FIELD_TYPE_STRING, true, SORT_FIELD_STRING_LAST)), 10,
+			"[2 7 3 6 0 4 1 5] [  e d c b a a]"},
+		{NewSort(newSortField("name", SORT_FIELD_TYPE_STRING, false, SORT_FIELD_STRING_FIRST)), 3,
+			"[2 7 1] [  a]"},
+		{NewSort(newSortField("nosuchfield", SORT_FIELD_TYPE_LONG, false, int64(42))), 3,
+			"[0 1 2] [42 42 42]"},
+		{NewSort(newSortField("inum", SORT_FIELD_TYPE_INT, false, int32(-1))), 4,
+			"[1 6 2 7] [-3 -3 -1 -1]"},
+		// doc values always have a value
+		{NewSort(newSortField("num", SORT_FIELD_TYPE_LONG, false, int64(-100))), 3,
+			"[1 6 4] [-3 -3 0]"},
+	} {
+		assertEquals(t, v.hits, sortedHits(t, ss, q, v.n, v.sort))
+	}
+
+	f := NewSortField("num", SORT_FIELD_TYPE_FLOAT, true)
+	f.SetMissingValue(float32(1.5))
+	assertEquals(t, `<float: "num">! missingValue=1.5`, f.String())
+	assertEquals(t, float32(1.5), f.MissingValue())
+	for _, v := range []struct {
+		typ          SortFieldType
+		missingValue interface{}
+	}{
+		{SORT_FIELD_TYPE_INT, 1},
+		{SORT_FIELD_TYPE_DOUBLE, float32(1)},
+		{SORT_FIELD_TYPE_STRING, "a"},
+		{SORT_FIELD_TYPE_SCORE, float32(1)},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic for missing value %v of type %v", v.missingValue, v.typ)
+				}
+			}()
+			NewSortField("num", v.typ, false).SetMissingValue(v.missingValue)
+		}()
+	}
+}
+
+func TestSearchSortedFloats(t *testing.T) {
+	floats := []float32{2.5, -1.25, 0.5, 3}
+	doubles := []float64{1e10, -0.5, 2, 1e-3}
+	docs := make([][]document.IndexableField, len(floats))
+	for i := range docs {
+		docs[i] = []document.IndexableField{
+			document.NewFloatDocValuesField("float", floats[i]),
+			document.NewDoubleDocValuesField("double", doubles[i]),
+		}
+	}
+	ss, cleanup := newTestSearcherOfDocs(t, docs...)
+	defer cleanup()
+
+	q := NewMatchAllDocsQuery()
+	assertEquals(t, "[1 2 0 3] [-1.25 0.5 2.5 3]",
+		sortedHits(t, ss, q, 10, NewSort(NewSortField("float", SORT_FIELD_TYPE_FLOAT, false))))
+	assertEquals(t, "[0 2 3 1] [1e+10 2 0.001 -0.5]",
+		sortedHits(t, ss, q, 10, NewSort(NewSortField("double", SORT_FIELD_TYPE_DOUBLE, true))))
+	assertEquals(t, "[1 3] [-0.5 -1.25 0.001 3]",
+		sortedHits(t, ss, q, 2, NewSort(NewSortField("double", SORT_FIELD_TYPE_DOUBLE, false),
+			NewSortField("float", SORT_FIELD_TYPE_FLOAT, false))))
+}
+
+// Sorts by the modulo of the doc ids.
+type modComparatorSource int
+
+func (s modComparatorSource) NewComparator(field string, numHits, sortPos int, reversed bool) FieldComparator {
+	return &modComparator{newDocComparator(numHits), int(s)}
+}
+
+func (s modComparatorSource) String() string {
+	return fmt.Sprintf("mod %v", int(s))
+}
+
+type modComparator struct {
+	*docComparator
+	mod int
+}
+
+func (c *modComparator) Compare(slot1, slot2 int) int {
+	return c.docIDs[slot1]%c.mod - c.docIDs[slot2]%c.mod
+}
+
+func (c *modComparator) CompareBottom(doc int) int {
+	return c.bottom%c.mod - (c.docBase+doc)%c.mod
+}
+
+func (c *modComparator) SetNextReader(ctx index.AtomicReaderContext) (FieldComparator, error) {
+	c.docBase = ctx.DocBase
+	return c, nil
+}
+
+func (c *modComparator) Value(slot int) interface{} {
+	return c.docIDs[slot] % c.mod
+}
+
+func TestSearchSortedCustom(t *testing.T) {
+	ss, cleanup := newSortTestSearcher(t)
+	defer cleanup()
+
+	byMod := NewCustomSortField("id", modComparatorSource(3), false)
+	assertEquals(t, `<custom: "id": mod 3>`, byMod.String())
+	assertEquals(t, modComparatorSource(3), byMod.ComparatorSource())
+	q := NewMatchAllDocsQuery()
+	assertEquals(t, "[0 3 6 1 4 7 2 5] [0 0 0 1 1 1 2 2]", sortedHits(t, ss, q, 10, NewSort(byMod)))
+	assertEquals(t, "[0 3 6 1] [0 0 0 1]", sortedHits(t, ss, q, 4, NewSort(byMod)))
+	assertEquals(t, "[2 5 1 4] [2 2 1 1]", sortedHits(t, ss, q, 4,
+		NewSort(NewCustomSortField("id", modComparatorSource(3), true))))
+}
+
+func TestSearchSortedSets(t *testing.T) {
+	sets := [][]string{{"m", "c"}, {"a", "z"}, {}, {"d"}, {"b", "x"}, {"e", "f"}}
+	var subs []index.IndexReader
+	for seg := 0; seg < 2; seg++ {
+		var docs [][]document.IndexableField
+		for _, set := range sets[seg*3 : seg*3+3] {
+			doc := []document.IndexableField{document.NewTextField("body", "x", document.STORE_NO)}
+			for _, v := range set {
+				doc = append(doc, document.NewStringField("set", v, document.STORE_NO),
+					document.NewSortedSetDocValuesField("dvset", []byte(v)))
+			}
+			docs = append(docs, doc)
+		}
+		ss, cleanup := newTestSearcherOfDocs(t, docs...)
+		defer cleanup()
+		subs = append(subs, ss.IndexReader())
+	}
+	ss := NewIndexSearcher(index.NewMultiReader(subs, false))
+
+	q := NewMatchAllDocsQuery()
+	for _, field := range []string{"set", "dvset"} {
+		reverseMin := NewSortedSetSortField(field, true, SORTED_SET_SELECTOR_MIN)
+		reverseMin.SetMissingValue(SORT_FIELD_STRING_FIRST)
+		maxLast := NewSortedSetSortField(field, false, SORTED_SET_SELECTOR_MAX)
+		maxLast.SetMissingValue(SORT_FIELD_STRING_LAST)
+		for _, v := range []struct {
+			sort *Sort
+			n    int
+			hits string
+		}{
+			{NewSort(NewSortedSetSortField(field, false, SORTED_SET_SELECTOR_MIN)), 10,
+				"[2 1 4 0 3 5] [ a b c d e]"},
+			{NewSort(NewSortedSetSortField(field, false, SORTED_SET_SELECTOR_MAX)), 10,
+				"[2 3 5 0 4 1] [ d f m x z]"},
+			{NewSort(NewSortedSetSortField(field, false, SORTED_SET_SELECTOR_MAX)), 3,
+				"[2 3 5] [ d f]"},
+			{NewSort(reverseMin), 10, "[5 3 0 4 1 2] [e d c b a ]"},
+			{NewSort(maxLast), 4, "[3 5 0 4] [d f m x]"},
+		} {
+			assertEquals(t, v.hits, sortedHits(t, ss, q, v.n, v.sort))
+		}
+	}
+	assertEquals(t, `<sortedset: "set">! missingValue=SortField.STRING_LAST selector=MAX`, func() string {
+		f := NewSortedSetSortField("set", true, SORTED_SET_SELECTOR_MAX)
+		f.SetMissingValue(SORT_FIELD_STRING_LAST)
+		return f.String()
+	}())
+}
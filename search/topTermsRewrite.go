@@ -0,0 +1,243 @@
+package search
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"sort"
+)
+
+// search/TopTermsRewrite.java
+
+/*
+Base rewrite method for collecting only the top terms via a priority
+queue, i.e. the terms of the highest boosts of the TermsEnum, like
+the similarity of FuzzyTermsEnum, so there are at most size of them.
+The collected terms are then built into a query, sorted by term.
+*/
+type TopTermsRewrite struct {
+	name string
+	size int
+	// builds the query of the top terms of q, which are sorted by term
+	build func(q *MultiTermQuery, scoreTerms []*scoreTerm) (Query, error)
+}
+
+// Returns the maximum number of top scoring terms that will be
+// used.
+func (rw *TopTermsRewrite) Size() int {
+	return rw.size
+}
+
+func (rw *TopTermsRewrite) Rewrite(r index.IndexReader, q *MultiTermQuery) (Query, error) {
+	maxSize := rw.size
+	if n := MaxClauseCount(); n < maxSize {
+		maxSize = n
+	}
+	col := &topTermsCollector{maxSize: maxSize, visited: make(map[string]*scoreTerm)}
+	if err := collectTerms(r, q, col); err != nil {
+		return nil, err
+	}
+	scoreTerms := []*scoreTerm(col.pq)
+	sort.Sort(scoreTermsByTerm(scoreTerms))
+	return rw.build(q, scoreTerms)
+}
+
+func (rw *TopTermsRewrite) String() string {
+	return fmt.Sprintf("%v(%v)", rw.name, rw.size)
+}
+
+// A collected term, with its boost and its states in the segments.
+type scoreTerm struct {
+	bytes     []byte
+	boost     float32
+	termState *index.TermContext
+}
+
+// The least competitive term is on the top, i.e. the one of the
+// lowest boost, and of the greatest term for the same boost.
+type scoreTermQueue []*scoreTerm
+
+func (pq scoreTermQueue) Len() int { return len(pq) }
+
+func (pq scoreTermQueue) Less(i, j int) bool {
+	if pq[i].boost == pq[j].boost {
+		return bytes.Compare(pq[i].bytes, pq[j].bytes) > 0
+	}
+	return pq[i].boost < pq[j].boost
+}
+
+func (pq scoreTermQueue) Swap(i, j int) { pq[i], pq[j] = pq[j], pq[i] }
+
+func (pq *scoreTermQueue) Push(x interface{}) { *pq = append(*pq, x.(*scoreTerm)) }
+
+func (pq *scoreTermQueue) Pop() interface{} {
+	n := len(*pq)
+	ans := (*pq)[n-1]
+	*pq = (*pq)[:n-1]
+	return ans
+}
+
+type scoreTermsByTerm []*scoreTerm
+
+func (s scoreTermsByTerm) Len() int           { return len(s) }
+func (s scoreTermsByTerm) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s scoreTermsByTerm) Less(i, j int) bool { return bytes.Compare(s[i].bytes, s[j].bytes) < 0 }
+
+// Collects the maxSize most competitive terms.
+type topTermsCollector struct {
+	maxSize          int
+	topReaderContext index.IndexReaderContext
+	readerContext    index.AtomicReaderContext
+	termsEnum        index.TermsEnum
+	boosted          boostedTermsEnum
+
+	visited map[string]*scoreTerm
+	pq      scoreTermQueue
+}
+
+func (c *topTermsCollector) setReaderContext(topReaderContext index.IndexReaderContext, ctx index.AtomicReaderContext) {
+	c.topReaderContext, c.readerContext = topReaderContext, ctx
+}
+
+func (c *topTermsCollector) setNextEnum(termsEnum index.TermsEnum) {
+	c.termsEnum = termsEnum
+	c.boosted, _ = termsEnum.(boostedTermsEnum)
+}
+
+func (c *topTermsCollector) collect(term []byte) (bool, error) {
+	boost := float32(1)
+	if c.boosted != nil {
+		boost = c.boosted.Boost()
+	}
+	// ignore uncompetitive hits
+	if len(c.pq) == c.maxSize {
+		if t := c.pq[0]; boost < t.boost || boost == t.boost && bytes.Compare(term, t.bytes) > 0 {
+			return true, nil
+		}
+	}
+	state := c.termsEnum.TermState()
+	if t, ok := c.visited[string(term)]; ok {
+		// if the term is already in the PQ, only update docFreq of term
+		// in PQ
+		t.termState.Register(state, c.readerContext.Ord, c.termsEnum.DocFreq(), c.termsEnum.TotalTermFreq())
+		return true, nil
+	}
+	st := &scoreTerm{
+		bytes:     append([]byte(nil), term...),
+		boost:     boost,
+		termState: index.NewTermContext(c.topReaderContext),
+	}
+	st.termState.Register(state, c.readerContext.Ord, c.termsEnum.DocFreq(), c.termsEnum.TotalTermFreq())
+	c.visited[string(st.bytes)] = st
+	heap.Push(&c.pq, st)
+	// possibly drop entries from queue
+	if len(c.pq) > c.maxSize {
+		st = heap.Pop(&c.pq).(*scoreTerm)
+		delete(c.visited, string(st.bytes))
+	}
+	return true, nil
+}
+
+// search/TopTermsBlendedFreqScoringRewrite.java
+
+/*
+A rewrite method that first translates each term into SHOULD clause
+in a BooleanQuery, but adjusts the frequencies used for scoring to be
+blended across the terms, otherwise the rarest term typically ranks
+highest (often not useful eg in the set of expanded terms in a
+FuzzyQuery).
+
+This rewrite method only uses the top scoring terms so it will not
+overflow the boolean max clause count, i.e. at most size terms, or
+MaxClauseCount() if less.
+*/
+func NewTopTermsBlendedFreqScoringRewrite(size int) *TopTermsRewrite {
+	return &TopTermsRewrite{"TopTermsBlendedFreqScoringRewrite", size, buildBlendedFreqQuery}
+}
+
+/*
+Builds a BooleanQuery of the terms, whose doc freq is the maximum of
+all the terms, and total term freq the sum of them, so they are as
+frequent, and rank by the boost of the terms.
+*/
+func buildBlendedFreqQuery(q *MultiTermQuery, scoreTerms []*scoreTerm) (Query, error) {
+	df, ttf := 0, int64(0)
+	for _, st := range scoreTerms {
+		if st.termState.DocFreq > df {
+			df = st.termState.DocFreq
+		}
+		if st.termState.TotalTermFreq == -1 {
+			ttf = -1
+		} else if ttf != -1 {
+			ttf += st.termState.TotalTermFreq
+		}
+	}
+	result := NewBooleanQueryDisableCoord(true)
+	for _, st := range scoreTerms {
+		st.termState.DocFreq, st.termState.TotalTermFreq = df, ttf
+		tq := NewTermQueryWithStates(index.Term{Field: q.field, Bytes: st.bytes}, st.termState)
+		tq.SetBoost(q.boost * st.boost)
+		if err := result.Add(tq, OCCUR_SHOULD); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// search/MultiTermQuery.java/TopTermsScoringBooleanQueryRewrite
+
+/*
+A rewrite method that first translates each term into SHOULD clause
+in a BooleanQuery, and keeps the scores as computed by the query.
+
+This rewrite method only uses the top scoring terms so it will not
+overflow the boolean max clause count, i.e. at most size terms, or
+MaxClauseCount() if less.
+*/
+func NewTopTermsScoringBooleanQueryRewrite(size int) *TopTermsRewrite {
+	return &TopTermsRewrite{"TopTermsScoringBooleanQueryRewrite", size, buildScoringQuery}
+}
+
+// Builds a BooleanQuery of the TermQuerys of the terms, boosted by
+// the boosts of the terms.
+func buildScoringQuery(q *MultiTermQuery, scoreTerms []*scoreTerm) (Query, error) {
+	result := NewBooleanQueryDisableCoord(true)
+	for _, st := range scoreTerms {
+		tq := NewTermQueryWithStates(index.Term{Field: q.field, Bytes: st.bytes}, st.termState)
+		tq.SetBoost(q.boost * st.boost)
+		if err := result.Add(tq, OCCUR_SHOULD); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// search/MultiTermQuery.java/TopTermsBoostOnlyBooleanQueryRewrite
+
+/*
+A rewrite method that first translates each term into SHOULD clause
+in a BooleanQuery, but the scores are only computed as the boost.
+
+This rewrite method only uses the top scoring terms so it will not
+overflow the boolean max clause count, i.e. at most size terms, or
+MaxClauseCount() if less.
+*/
+func NewTopTermsBoostOnlyBooleanQueryRewrite(size int) *TopTermsRewrite {
+	return &TopTermsRewrite{"TopTermsBoostOnlyBooleanQueryRewrite", size, buildBoostOnlyQuery}
+}
+
+// Builds a BooleanQuery of the ConstantScoreQuerys of the TermQuerys
+// of the terms, whose scores are the boosts of the terms.
+func buildBoostOnlyQuery(q *MultiTermQuery, scoreTerms []*scoreTerm) (Query, error) {
+	result := NewBooleanQueryDisableCoord(true)
+	for _, st := range scoreTerms {
+		csq := NewConstantScoreQuery(NewTermQueryWithStates(
+			index.Term{Field: q.field, Bytes: st.bytes}, st.termState))
+		csq.SetBoost(q.boost * st.boost)
+		if err := result.Add(csq, OCCUR_SHOULD); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
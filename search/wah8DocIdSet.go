@@ -0,0 +1,392 @@
+package search
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+	"math/bits"
+	"sort"
+)
+
+// util/WAH8DocIdSet.java
+
+/*
+DocIdSet implementation based on word-aligned hybrid encoding on
+words of 8 bits. This implementation doesn't support random access
+but has a fast DocIdSetIterator which can advance in logarithmic time
+thanks to an index.
+
+The compression scheme is simplistic and should work well with sparse
+and very dense doc id sets while being only slightly larger than a
+FixedBitSet for incompressible sets.
+
+The encoded set is a sequence of sequences of words, each word being
+a byte of 8 docs. A sequence starts with a header, followed by its
+dirty words:
+
+  - the header is a byte whose highest bit tells if the clean words
+    of the sequence are 0xFF (1) or 0x00 (0), whose next 3 bits are
+    the number of clean words, and whose lowest 4 bits are the number
+    of dirty words. If a number doesn't fit, its bits are all set and
+    the remainder follows as a VInt, first for the clean words then
+    for the dirty words.
+  - the clean words, 0x00 or 0xFF, are implied by the header.
+  - the dirty words, any other value, follow the header.
+
+Every WAH8_DEFAULT_INDEX_INTERVAL sequences, the position and the
+first word of the sequence are indexed, so Advance() can jump over
+the sequences before its target.
+*/
+type WAH8DocIdSet struct {
+	data        []byte
+	cardinality int
+	// the first word numbers, and the positions, of the indexed
+	// sequences
+	indexWordNums, indexPositions []int
+}
+
+// The default number of sequences between two indexed ones.
+const WAH8_DEFAULT_INDEX_INTERVAL = 24
+
+// The empty set.
+var wah8Empty = &WAH8DocIdSet{}
+
+func (s *WAH8DocIdSet) Iterator() (index.DocIdSetIterator, error) {
+	return &wah8Iterator{set: s, wordNum: -1, doc: -1}, nil
+}
+
+// This set doesn't support random access.
+func (s *WAH8DocIdSet) Bits() util.Bits {
+	return nil
+}
+
+// Returns the number of documents in this set.
+func (s *WAH8DocIdSet) Cardinality() int {
+	return s.cardinality
+}
+
+// Returns the approximate number of bytes of the encoded set.
+func (s *WAH8DocIdSet) SizeInBytes() int64 {
+	return int64(len(s.data) + 8*(len(s.indexWordNums)+len(s.indexPositions)))
+}
+
+func (s *WAH8DocIdSet) String() string {
+	return fmt.Sprintf("WAH8DocIdSet(cardinality=%v, bytes=%v)", s.cardinality, s.SizeInBytes())
+}
+
+// util/WAH8DocIdSet.java/Builder
+
+/*
+A builder for WAH8DocIdSets, to which the docs are added in
+increasing order.
+*/
+type WAH8DocIdSetBuilder struct {
+	out           []byte
+	indexInterval int
+	numSequences  int
+	// the pending sequence
+	cleanByte    byte
+	clean        int
+	dirtyWords   []byte
+	seqStartWord int
+	lastWordNum  int
+	// the word being built
+	wordNum     int
+	word        byte
+	lastDocID   int
+	cardinality int
+
+	indexWordNums, indexPositions []int
+}
+
+func NewWAH8DocIdSetBuilder() *WAH8DocIdSetBuilder {
+	return &WAH8DocIdSetBuilder{
+		indexInterval: WAH8_DEFAULT_INDEX_INTERVAL,
+		lastWordNum:   -1,
+		wordNum:       -1,
+		lastDocID:     -1,
+	}
+}
+
+/*
+Sets the number of sequences between two indexed ones. Lower values
+make Advance() faster, but the set larger.
+*/
+func (b *WAH8DocIdSetBuilder) SetIndexInterval(indexInterval int) *WAH8DocIdSetBuilder {
+	if indexInterval < 1 {
+		panic("indexInterval must be >= 1")
+	}
+	b.indexInterval = indexInterval
+	return b
+}
+
+// Adds a document to the set. The documents must be added in
+// increasing order.
+func (b *WAH8DocIdSetBuilder) Add(docID int) *WAH8DocIdSetBuilder {
+	if docID <= b.lastDocID {
+		panic(fmt.Sprintf("docs must be added in increasing order, got %v after %v", docID, b.lastDocID))
+	}
+	if wordNum := docID >> 3; wordNum != b.wordNum {
+		if b.word != 0 {
+			b.addWord(b.wordNum, b.word)
+		}
+		b.wordNum, b.word = wordNum, 0
+	}
+	b.word |= 1 << uint(docID&7)
+	b.lastDocID = docID
+	return b
+}
+
+// Adds the documents of the iterator, which must be beyond the ones
+// added already.
+func (b *WAH8DocIdSetBuilder) AddIterator(it index.DocIdSetIterator) *WAH8DocIdSetBuilder {
+	for doc, more := it.NextDoc(); more; doc, more = it.NextDoc() {
+		b.Add(doc)
+	}
+	return b
+}
+
+// Adds a non-zero word, beyond the previous one.
+func (b *WAH8DocIdSetBuilder) addWord(wordNum int, word byte) {
+	// assert wordNum > b.lastWordNum && word != 0
+	if gap := wordNum - b.lastWordNum - 1; gap == 1 && len(b.dirtyWords) > 0 {
+		// a single clean word costs less as a dirty one
+		b.dirtyWords = append(b.dirtyWords, 0)
+	} else if gap > 0 {
+		b.writeSequence()
+		b.cleanByte, b.clean = 0, gap
+	}
+	switch {
+	case word != 0xFF:
+		b.dirtyWords = append(b.dirtyWords, word)
+	case len(b.dirtyWords) == 0 && (b.clean == 0 || b.cleanByte == 0xFF):
+		b.cleanByte = 0xFF
+		b.clean++
+	case len(b.dirtyWords) > 0 && b.dirtyWords[len(b.dirtyWords)-1] == 0xFF:
+		// two full words in a row start a clean sequence
+		b.dirtyWords = b.dirtyWords[:len(b.dirtyWords)-1]
+		b.writeSequence()
+		b.cleanByte, b.clean = 0xFF, 2
+	default:
+		b.dirtyWords = append(b.dirtyWords, word)
+	}
+	b.lastWordNum = wordNum
+	b.cardinality += bits.OnesCount8(word)
+}
+
+// Writes the pending sequence, if any.
+func (b *WAH8DocIdSetBuilder) writeSequence() {
+	if b.clean == 0 && len(b.dirtyWords) == 0 {
+		return
+	}
+	if b.numSequences%b.indexInterval == 0 {
+		b.indexWordNums = append(b.indexWordNums, b.seqStartWord)
+		b.indexPositions = append(b.indexPositions, len(b.out))
+	}
+	b.numSequences++
+
+	var token byte
+	if b.cleanByte == 0xFF {
+		token = 0x80
+	}
+	token |= byte(minInt(b.clean, 7)) << 4
+	token |= byte(minInt(len(b.dirtyWords), 15))
+	b.out = append(b.out, token)
+	if b.clean >= 7 {
+		b.out = appendVInt(b.out, b.clean-7)
+	}
+	if len(b.dirtyWords) >= 15 {
+		b.out = appendVInt(b.out, len(b.dirtyWords)-15)
+	}
+	b.out = append(b.out, b.dirtyWords...)
+
+	b.seqStartWord += b.clean + len(b.dirtyWords)
+	b.cleanByte, b.clean, b.dirtyWords = 0, 0, b.dirtyWords[:0]
+}
+
+// Builds the set of the added documents. The builder must not be
+// used anymore.
+func (b *WAH8DocIdSetBuilder) Build() *WAH8DocIdSet {
+	if b.word != 0 {
+		b.addWord(b.wordNum, b.word)
+		b.word = 0
+	}
+	b.writeSequence()
+	if b.cardinality == 0 {
+		return wah8Empty
+	}
+	return &WAH8DocIdSet{
+		data:           b.out,
+		cardinality:    b.cardinality,
+		indexWordNums:  b.indexWordNums,
+		indexPositions: b.indexPositions,
+	}
+}
+
+// util/WAH8DocIdSet.java/Iterator
+
+type wah8Iterator struct {
+	set *WAH8DocIdSet
+	pos int
+	// the remaining words of the current sequence
+	cleanByte byte
+	clean     int
+	dirty     int
+	wordNum   int
+	word      byte // the bits of the current word beyond doc
+	doc       int
+	exhausted bool
+}
+
+// Reads the header of the sequence at pos.
+func (it *wah8Iterator) readSequence() {
+	token := it.set.data[it.pos]
+	it.pos++
+	it.cleanByte = 0
+	if token&0x80 != 0 {
+		it.cleanByte = 0xFF
+	}
+	it.clean = int(token>>4) & 0x07
+	it.dirty = int(token & 0x0F)
+	if it.clean == 7 {
+		var n int
+		n, it.pos = readVInt(it.set.data, it.pos)
+		it.clean += n
+	}
+	if it.dirty == 15 {
+		var n int
+		n, it.pos = readVInt(it.set.data, it.pos)
+		it.dirty += n
+	}
+}
+
+// Moves to the next non-zero word, returning false if there is none.
+func (it *wah8Iterator) nextWord() bool {
+	for {
+		switch {
+		case it.clean > 0:
+			if it.cleanByte == 0xFF {
+				it.clean--
+				it.wordNum++
+				it.word = 0xFF
+				return true
+			}
+			it.wordNum += it.clean
+			it.clean = 0
+		case it.dirty > 0:
+			it.dirty--
+			it.wordNum++
+			it.word = it.set.data[it.pos]
+			it.pos++
+			if it.word != 0 {
+				return true
+			}
+		case it.pos < len(it.set.data):
+			it.readSequence()
+		default:
+			return false
+		}
+	}
+}
+
+func (it *wah8Iterator) DocId() int {
+	return it.doc
+}
+
+func (it *wah8Iterator) Freq() int {
+	return 1
+}
+
+func (it *wah8Iterator) NextDoc() (int, bool) {
+	if it.word == 0 && (it.exhausted || !it.nextWord()) {
+		it.exhausted = true
+		it.doc = index.NO_MORE_DOCS
+		return it.doc, false
+	}
+	it.doc = it.wordNum<<3 + bits.TrailingZeros8(it.word)
+	it.word &= it.word - 1
+	return it.doc, true
+}
+
+// Skips the words before targetWordNum, so the next word read is the
+// first one from targetWordNum on.
+func (it *wah8Iterator) skipWords(targetWordNum int) {
+	it.word = 0
+	// jump to the last indexed sequence before the target, if it is
+	// ahead
+	wordNums := it.set.indexWordNums
+	if i := sort.SearchInts(wordNums, targetWordNum+1) - 1; i >= 0 && wordNums[i] > it.wordNum+1 {
+		it.pos = it.set.indexPositions[i]
+		it.wordNum = wordNums[i] - 1
+		it.clean, it.dirty = 0, 0
+	}
+	for need := targetWordNum - it.wordNum - 1; need > 0; {
+		switch {
+		case it.clean > 0:
+			n := minInt(it.clean, need)
+			it.clean -= n
+			it.wordNum += n
+			need -= n
+		case it.dirty > 0:
+			n := minInt(it.dirty, need)
+			it.dirty -= n
+			it.pos += n
+			it.wordNum += n
+			need -= n
+		case it.pos < len(it.set.data):
+			it.readSequence()
+		default:
+			return
+		}
+	}
+}
+
+func (it *wah8Iterator) Advance(target int) (int, bool) {
+	if it.exhausted {
+		return it.doc, false
+	}
+	if targetWordNum := target >> 3; targetWordNum > it.wordNum {
+		it.skipWords(targetWordNum)
+	}
+	for doc, more := it.NextDoc(); more; doc, more = it.NextDoc() {
+		if doc >= target {
+			return doc, true
+		}
+	}
+	return it.doc, false
+}
+
+func (it *wah8Iterator) Cost() int64 {
+	return int64(it.set.cardinality)
+}
+
+// Appends i as a VInt: 7 bits per byte, the highest bit telling if
+// more bytes follow.
+func appendVInt(out []byte, i int) []byte {
+	for i >= 0x80 {
+		out = append(out, byte(i&0x7F|0x80))
+		i >>= 7
+	}
+	return append(out, byte(i))
+}
+
+// Reads the VInt at pos, returning it and the position beyond it.
+func readVInt(data []byte, pos int) (int, int) {
+	i, shift := 0, uint(0)
+	for {
+		b := data[pos]
+		pos++
+		i |= int(b&0x7F) << shift
+		if b < 0x80 {
+			return i, pos
+		}
+		shift += 7
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"math/rand"
+	"testing"
+)
+
+// Returns sets of docs below maxDoc, from empty to full, including
+// sparse, dense and clustered ones.
+func randomDocSets(rnd *rand.Rand, maxDoc int) [][]int {
+	var sets [][]int
+	for _, density := range []float64{0, 0.0005, 0.01, 0.1, 0.5, 0.9, 0.999, 1} {
+		var docs []int
+		for doc := 0; doc < maxDoc; doc++ {
+			if density == 1 || rnd.Float64() < density {
+				docs = append(docs, doc)
+			}
+		}
+		sets = append(sets, docs)
+	}
+	// runs of docs, separated by gaps
+	var docs []int
+	for doc := rnd.Intn(100); doc < maxDoc; doc += rnd.Intn(1000) {
+		for end := doc + rnd.Intn(500); doc < end && doc < maxDoc; doc++ {
+			docs = append(docs, doc)
+		}
+	}
+	sets = append(sets, append(docs, maxDoc+1<<20))
+	return sets
+}
+
+// Checks the set has the given docs, by NextDoc() and by random
+// Advance() and NextDoc() calls.
+func assertDocIdSetDocs(t *testing.T, rnd *rand.Rand, docs []int, set DocIdSet) {
+	actual := docIdSetDocs(t, set)
+	if len(actual) != len(docs) {
+		t.Fatalf("Expected %v docs, but %v", len(docs), len(actual))
+	}
+	for i, doc := range docs {
+		if actual[i] != doc {
+			t.Fatalf("Expected doc %v at %v, but %v", doc, i, actual[i])
+		}
+	}
+
+	it, err := set.Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, int64(len(docs)), it.Cost())
+	i := 0 // the index of the next expected doc
+	for {
+		var doc int
+		var more bool
+		if rnd.Intn(2) == 0 {
+			doc, more = it.NextDoc()
+		} else {
+			target := it.DocId() + 1 + rnd.Intn(1<<uint(rnd.Intn(16)))
+			doc, more = it.Advance(target)
+			for i < len(docs) && docs[i] < target {
+				i++
+			}
+		}
+		if i == len(docs) {
+			if more || doc != index.NO_MORE_DOCS {
+				t.Fatalf("Expected no more docs, but %v", doc)
+			}
+			return
+		}
+		if !more || doc != docs[i] {
+			t.Fatalf("Expected doc %v, but %v", docs[i], doc)
+		}
+		i++
+	}
+}
+
+func TestWAH8DocIdSet(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	for _, docs := range randomDocSets(rnd, 50000) {
+		for _, indexInterval := range []int{1, 4, WAH8_DEFAULT_INDEX_INTERVAL} {
+			b := NewWAH8DocIdSetBuilder().SetIndexInterval(indexInterval)
+			for _, doc := range docs {
+				b.Add(doc)
+			}
+			set := b.Build()
+			assertEquals(t, len(docs), set.Cardinality())
+			assertDocIdSetDocs(t, rnd, docs, set)
+		}
+	}
+}
+
+func TestWAH8DocIdSetCompression(t *testing.T) {
+	maxDoc := 1 << 20
+	full := NewWAH8DocIdSetBuilder()
+	sparse := NewWAH8DocIdSetBuilder()
+	for doc := 0; doc < maxDoc; doc++ {
+		full.Add(doc)
+		if doc%1000 == 0 {
+			sparse.Add(doc)
+		}
+	}
+	for _, set := range []*WAH8DocIdSet{full.Build(), sparse.Build()} {
+		if set.SizeInBytes() > int64(maxDoc/8/20) {
+			t.Errorf("Expected %v to be much smaller than a bit set", set)
+		}
+	}
+}
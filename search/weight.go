@@ -0,0 +1,78 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util"
+)
+
+// search/Weight.java
+
+/*
+Expert: Calculate query weights and build query scorers.
+
+The purpose of Weight is to ensure searching does not modify a
+Query, so that a Query instance can be reused. IndexSearcher
+dependent state of the query should reside in the Weight, and
+AtomicReader dependent state should reside in the Scorer.
+
+Since Weight creates Scorer instances for a given
+AtomicReaderContext (Scorer()) callers must maintain the relationship
+between the searcher's top-level IndexReaderContext and the context
+used to create a Scorer.
+
+A Weight is used in the following way:
+
+ 1. A Weight is constructed by a top-level query, given an
+    IndexSearcher (Query.CreateWeight()).
+ 2. The ValueForNormalization() method is called on the Weight to
+    compute the query normalization factor Similarity.QueryNorm() of
+    the query clauses contained in the query.
+ 3. The query normalization factor is passed to Normalize(). At this
+    point the weighting is complete.
+ 4. A Scorer is constructed by Scorer().
+
+How the documents are scored is described by Explain().
+*/
+type Weight interface {
+	// The query that this concerns.
+	Query() Query
+	// The value for normalization of contained query clauses (e.g. sum
+	// of squared weights).
+	ValueForNormalization() float32
+	// Assigns the query normalization factor and boost from parent
+	// queries to this.
+	Normalize(norm float32, topLevelBoost float32)
+	/*
+		Returns a Scorer which scores documents in/out-of order according
+		to scoreDocsInOrder, or nil if no documents of the segment can
+		match.
+
+		NOTE: even if scoreDocsInOrder is false, it is recommended to
+		check whether the returned Scorer indeed scores documents out of
+		order (i.e., call IsScoresDocsOutOfOrder()), as some Scorer
+		implementations will always return documents in-order.
+
+		NOTE: nil can be returned if no documents will be scored by this
+		query.
+
+		topScorer is true if the Scorer will be used as the top-level
+		scorer, i.e. only its ScoreAndCollect() will be called, and not
+		its iteration methods. acceptDocs are the Bits that represent
+		the allowable docs to match (typically deleted docs but possibly
+		filtering other documents).
+	*/
+	Scorer(ctx index.AtomicReaderContext, scoreDocsInOrder, topScorer bool,
+		acceptDocs util.Bits) (Scorer, error)
+	/*
+		Returns true iff this implementation scores docs only out of
+		order. This method is used in conjunction with Collector's
+		AcceptsDocsOutOfOrder() and Scorer() to create a matching Scorer
+		instance for a given Collector, or vice versa.
+	*/
+	IsScoresDocsOutOfOrder() bool
+	/*
+		An explanation of the score computation for the named document.
+		doc is relative to the leaf of ctx.
+	*/
+	Explain(ctx index.AtomicReaderContext, doc int) (*Explanation, error)
+}
@@ -0,0 +1,81 @@
+package search
+
+import (
+	"github.com/balzaczyy/golucene/index"
+	"github.com/balzaczyy/golucene/util/automaton"
+	"unicode/utf8"
+)
+
+// search/WildcardQuery.java
+
+const (
+	WILDCARD_STRING = '*'  // String equality with support for wildcards
+	WILDCARD_CHAR   = '?'  // Char equality with support for wildcards
+	WILDCARD_ESCAPE = '\\' // Escape character
+)
+
+/*
+Implements the wildcard search query. Supported wildcards are *,
+which matches any character sequence (including the empty one), and
+?, which matches any single character. '\' is the escape character.
+
+Note this query can be slow, as it needs to iterate over many terms.
+In order to prevent extremely slow WildcardQueries, a Wildcard term
+should not start with the wildcard *
+
+This query uses the CONSTANT_SCORE_AUTO_REWRITE_DEFAULT rewrite method by
+default.
+*/
+type WildcardQuery struct {
+	*AutomatonQuery
+}
+
+// Constructs a query for terms matching term.
+func NewWildcardQuery(term index.Term) *WildcardQuery {
+	ans := new(WildcardQuery)
+	ans.AutomatonQuery = newAutomatonQuery(ans, term, WildcardQueryToAutomaton(term))
+	return ans
+}
+
+// Convert Lucene wildcard syntax into an automaton.
+func WildcardQueryToAutomaton(wildcardquery index.Term) *automaton.Automaton {
+	var automata []*automaton.Automaton
+	wildcardText := string(wildcardquery.Bytes)
+	for i := 0; i < len(wildcardText); {
+		c, length := utf8.DecodeRuneInString(wildcardText[i:])
+		switch c {
+		case WILDCARD_STRING:
+			automata = append(automata, automaton.MakeAnyString())
+		case WILDCARD_CHAR:
+			automata = append(automata, automaton.MakeAnyChar())
+		case WILDCARD_ESCAPE:
+			// add the next codepoint instead, if it exists
+			if i+length < len(wildcardText) {
+				nextChar, nextLength := utf8.DecodeRuneInString(wildcardText[i+length:])
+				automata = append(automata, automaton.MakeChar(nextChar))
+				length += nextLength
+				break
+			}
+			// else fallthru, lenient parsing with a trailing \
+			fallthrough
+		default:
+			automata = append(automata, automaton.MakeChar(c))
+		}
+		i += length
+	}
+	return automaton.Concatenate(automata...)
+}
+
+// Returns the pattern term.
+func (q *WildcardQuery) Term() index.Term {
+	return q.term
+}
+
+// Prints a user-readable version of this query.
+func (q *WildcardQuery) ToString(field string) string {
+	s := string(q.term.Bytes) + boostString(q.boost)
+	if q.Field() != field {
+		s = q.Field() + ":" + s
+	}
+	return s
+}
@@ -131,6 +131,32 @@ func (d *CompoundFileDirectory) FileExists(name string) bool {
 	return ok
 }
 
+// Not implemented
+func (d *CompoundFileDirectory) DeleteFile(name string) error {
+	panic("not supported yet")
+}
+
+// Returns the length of a file in the directory.
+func (d *CompoundFileDirectory) FileLength(name string) (int64, error) {
+	d.ensureOpen()
+	// if d.writer != nil {
+	// 	return d.writer.FileLength(name)
+	// }
+	entry, ok := d.entries[util.StripSegmentName(name)]
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("%v does not exist", name))
+	}
+	return entry.length, nil
+}
+
+func (d *CompoundFileDirectory) CreateOutput(name string, ctx IOContext) (out IndexOutput, err error) {
+	panic("not implemented yet")
+}
+
+func (d *CompoundFileDirectory) Sync(names []string) error {
+	panic("not supported yet")
+}
+
 const (
 	CODEC_MAGIC_BYTE1 = byte(uint32(codec.CODEC_MAGIC) >> 24 & 0xFF)
 	CODEC_MAGIC_BYTE2 = byte(uint32(codec.CODEC_MAGIC) >> 16 & 0xFF)
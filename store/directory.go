@@ -115,10 +115,10 @@ type Directory interface {
 	// Files related methods
 	ListAll() (paths []string, err error)
 	FileExists(name string) bool
-	// DeleteFile(name string) error
-	// FileLength(name string) int64
-	// CreateOutput(name string, ctx, IOContext) (out IndexOutput, err error)
-	// Sync(names []string) error
+	DeleteFile(name string) error
+	FileLength(name string) (int64, error)
+	CreateOutput(name string, ctx IOContext) (out IndexOutput, err error)
+	Sync(names []string) error
 	OpenInput(name string, context IOContext) (in IndexInput, err error)
 	// Locks related methods
 	makeLock(name string) Lock
@@ -126,14 +126,48 @@ type Directory interface {
 	setLockFactory(lockFactory LockFactory) error
 	getLockFactory() LockFactory
 	getLockID() string
-	// Utilities
-	// Copy(to Directory, src, dest string, ctx IOContext) error
+	// Utilities: see CopyFile()
 	// Experimental methods
 	createSlicer(name string, ctx IOContext) (slicer IndexInputSlicer, err error)
 	// Private methods
 	ensureOpen()
 }
 
+/*
+Copies the file src of Directory from to Directory to under the new
+file name dest.
+
+NOTE: this method does not check whether dest exist and will
+overwrite it if it does.
+*/
+/*
+Copies the file src in Directory from to the file dest in Directory
+to. The destination file is removed if the copy fails.
+*/
+func CopyFile(from, to Directory, src, dest string, ctx IOContext) (err error) {
+	os, err := to.CreateOutput(dest, ctx)
+	if err != nil {
+		return err
+	}
+	success := false
+	defer func() {
+		if !success {
+			os.Close()
+			to.DeleteFile(dest)
+		}
+	}()
+	is, err := from.OpenInput(src, ctx)
+	if err != nil {
+		return err
+	}
+	defer is.Close()
+	if err = os.CopyBytes(is, is.Length()); err != nil {
+		return err
+	}
+	success = true
+	return os.Close()
+}
+
 type DirectoryImpl struct {
 	Directory
 	isOpen      bool
@@ -5,20 +5,26 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
 	"strconv"
+	"sync"
 )
 
 type FSDirectory struct {
 	*DirectoryImpl
-	path      string
-	chunkSize int
+	sync.Locker
+	path       string
+	staleFiles map[string]bool // files written, but not yet sync'ed
+	chunkSize  int
 }
 
 // TODO support lock factory
 func newFSDirectory(self Directory, path string) (d *FSDirectory, err error) {
 	d = &FSDirectory{}
 	d.DirectoryImpl = newDirectoryImpl(self)
+	d.Locker = &sync.Mutex{}
 	d.path = path
+	d.staleFiles = make(map[string]bool)
 	d.chunkSize = math.MaxInt32
 
 	if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
@@ -79,8 +85,91 @@ func (d *FSDirectory) ListAll() (paths []string, err error) {
 
 func (d *FSDirectory) FileExists(name string) bool {
 	d.ensureOpen()
-	_, err := os.Stat(name)
-	return err != nil
+	_, err := os.Stat(filepath.Join(d.path, name))
+	return err == nil
+}
+
+// Closes the store to future operations.
+func (d *FSDirectory) Close() error {
+	d.isOpen = false
+	return nil
+}
+
+// Returns the length in bytes of a file in the directory.
+func (d *FSDirectory) FileLength(name string) (int64, error) {
+	d.ensureOpen()
+	fi, err := os.Stat(filepath.Join(d.path, name))
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// Removes an existing file in the directory.
+func (d *FSDirectory) DeleteFile(name string) error {
+	d.ensureOpen()
+	if err := os.Remove(filepath.Join(d.path, name)); err != nil {
+		return errors.New(fmt.Sprintf("Cannot delete %v: %v", name, err))
+	}
+	d.Lock()
+	defer d.Unlock()
+	delete(d.staleFiles, name)
+	return nil
+}
+
+// Creates an IndexOutput for the file with the given name.
+func (d *FSDirectory) CreateOutput(name string, ctx IOContext) (out IndexOutput, err error) {
+	d.ensureOpen()
+	if err = d.ensureCanWrite(name); err != nil {
+		return nil, err
+	}
+	return newFSIndexOutput(d, name)
+}
+
+func (d *FSDirectory) ensureCanWrite(name string) error {
+	if err := os.MkdirAll(d.path, 0755); err != nil {
+		return errors.New(fmt.Sprintf("Cannot create directory: %v", d.path))
+	}
+	if err := os.Remove(filepath.Join(d.path, name)); err != nil && !os.IsNotExist(err) {
+		return errors.New(fmt.Sprintf("Cannot overwrite: %v", name))
+	}
+	return nil
+}
+
+func (d *FSDirectory) onIndexOutputClosed(name string) {
+	d.Lock()
+	defer d.Unlock()
+	d.staleFiles[name] = true
+}
+
+/*
+Ensures that any writes to these files are moved to stable storage.
+Lucene uses this to properly commit changes to the index, to prevent
+a machine/OS crash from corrupting the index.
+*/
+func (d *FSDirectory) Sync(names []string) error {
+	d.ensureOpen()
+	d.Lock()
+	defer d.Unlock()
+	for _, name := range names {
+		if !d.staleFiles[name] {
+			continue
+		}
+		if err := fsync(filepath.Join(d.path, name)); err != nil {
+			return err
+		}
+		delete(d.staleFiles, name)
+	}
+	return nil
+}
+
+func fsync(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
 }
 
 func (d *FSDirectory) getLockID() string {
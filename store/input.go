@@ -73,7 +73,9 @@ func newBufferedIndexInputBySize(desc string, bufferSize int) *BufferedIndexInpu
 
 func (in *BufferedIndexInput) ReadByte() (b byte, err error) {
 	if in.bufferPosition >= in.bufferLength {
-		in.refill()
+		if err = in.refill(); err != nil {
+			return 0, err
+		}
 	}
 	in.bufferPosition++
 	return in.buffer[in.bufferPosition-1], nil
@@ -437,6 +439,10 @@ func (in *ByteArrayDataInput) Length() int {
 	return in.limit
 }
 
+func (in *ByteArrayDataInput) EOF() bool {
+	return in.Pos == in.limit
+}
+
 func (in *ByteArrayDataInput) SkipBytes(count int) {
 	in.Pos += count
 }
@@ -455,7 +461,7 @@ func (in *ByteArrayDataInput) ReadInt() (n int32, err error) {
 func (in *ByteArrayDataInput) ReadLong() (n int64, err error) {
 	i1, _ := in.ReadInt()
 	i2, _ := in.ReadInt()
-	return (int64(i1) << 32) | int64(i2), nil
+	return (int64(i1) << 32) | int64(uint32(i2)), nil
 }
 
 func (in *ByteArrayDataInput) ReadVInt() (n int32, err error) {
@@ -0,0 +1,242 @@
+package store
+
+import (
+	"fmt"
+	"github.com/balzaczyy/golucene/util"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// store/IndexOutput.java
+
+/*
+Abstract base class for output to a file in a Directory. A random-
+access output stream. Used for all Lucene index output operations.
+*/
+type IndexOutput interface {
+	io.Closer
+	util.DataOutput
+	// Forces any buffered output to be written.
+	Flush() error
+	// Returns the current position in this file, where the next write
+	// will occur.
+	FilePointer() int64
+	// The number of bytes in the file.
+	Length() (int64, error)
+}
+
+// store/BufferedIndexOutput.java
+
+const OUTPUT_BUFFER_SIZE = 16384
+
+type flushBufferer interface {
+	// Expert: implements buffer write. Writes bytes at the current
+	// position in the output.
+	flushBuffer(buf []byte) error
+}
+
+// Base implementation class for buffered IndexOutput.
+type BufferedIndexOutput struct {
+	*util.DataOutputImpl
+	flushBufferer
+	buffer         []byte
+	bufferStart    int64 // position in file of buffer
+	bufferPosition int   // position in buffer
+}
+
+func newBufferedIndexOutput(bufferSize int, f flushBufferer) *BufferedIndexOutput {
+	checkBufferSize(bufferSize)
+	ans := &BufferedIndexOutput{flushBufferer: f, buffer: make([]byte, bufferSize)}
+	ans.DataOutputImpl = util.NewDataOutput(ans)
+	return ans
+}
+
+func (out *BufferedIndexOutput) WriteByte(b byte) error {
+	if out.bufferPosition >= len(out.buffer) {
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+	out.buffer[out.bufferPosition] = b
+	out.bufferPosition++
+	return nil
+}
+
+func (out *BufferedIndexOutput) WriteBytes(buf []byte) error {
+	bytesLeft := len(out.buffer) - out.bufferPosition
+	// is there enough space in the buffer?
+	if bytesLeft >= len(buf) {
+		// we add the data to the end of the buffer
+		copy(out.buffer[out.bufferPosition:], buf)
+		out.bufferPosition += len(buf)
+		// if the buffer is full, flush it
+		if len(out.buffer)-out.bufferPosition == 0 {
+			return out.Flush()
+		}
+		return nil
+	}
+	// is data larger then buffer?
+	if len(buf) > len(out.buffer) {
+		// we flush the buffer
+		if out.bufferPosition > 0 {
+			if err := out.Flush(); err != nil {
+				return err
+			}
+		}
+		// and write data at once
+		if err := out.flushBuffer(buf); err != nil {
+			return err
+		}
+		out.bufferStart += int64(len(buf))
+		return nil
+	}
+	// we fill/flush the buffer (until the input is written)
+	for len(buf) > 0 {
+		n := copy(out.buffer[out.bufferPosition:], buf)
+		out.bufferPosition += n
+		buf = buf[n:]
+		if out.bufferPosition == len(out.buffer) {
+			if err := out.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (out *BufferedIndexOutput) Flush() error {
+	if err := out.flushBuffer(out.buffer[:out.bufferPosition]); err != nil {
+		return err
+	}
+	out.bufferStart += int64(out.bufferPosition)
+	out.bufferPosition = 0
+	return nil
+}
+
+func (out *BufferedIndexOutput) Close() error {
+	return out.Flush()
+}
+
+func (out *BufferedIndexOutput) FilePointer() int64 {
+	return out.bufferStart + int64(out.bufferPosition)
+}
+
+// FSDirectory.java/FSIndexOutput
+
+type FSIndexOutput struct {
+	*BufferedIndexOutput
+	parent *FSDirectory
+	name   string
+	file   *os.File
+	isOpen bool // remember if the file is open, so that we don't try to close it more than once
+}
+
+func newFSIndexOutput(parent *FSDirectory, name string) (*FSIndexOutput, error) {
+	f, err := os.OpenFile(filepath.Join(parent.path, name),
+		os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, err
+	}
+	ans := &FSIndexOutput{parent: parent, name: name, file: f, isOpen: true}
+	ans.BufferedIndexOutput = newBufferedIndexOutput(OUTPUT_BUFFER_SIZE, ans)
+	return ans, nil
+}
+
+func (out *FSIndexOutput) flushBuffer(buf []byte) error {
+	// assert isOpen
+	for len(buf) > 0 {
+		toWrite := len(buf)
+		if out.parent.chunkSize < toWrite {
+			toWrite = out.parent.chunkSize
+		}
+		n, err := out.file.Write(buf[:toWrite])
+		if err != nil {
+			return err
+		}
+		buf = buf[n:]
+	}
+	return nil
+}
+
+func (out *FSIndexOutput) Close() error {
+	if !out.isOpen {
+		return nil
+	}
+	out.parent.onIndexOutputClosed(out.name)
+	// only close the file if it has not been closed yet
+	out.isOpen = false
+	err := out.BufferedIndexOutput.Close()
+	if err2 := out.file.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+func (out *FSIndexOutput) Length() (int64, error) {
+	fi, err := out.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func (out *FSIndexOutput) String() string {
+	return fmt.Sprintf("FSIndexOutput(path='%v')", filepath.Join(out.parent.path, out.name))
+}
+
+// store/ChecksumIndexOutput.java
+
+// Writes bytes through to a primary IndexOutput, computing checksum.
+type ChecksumIndexOutput struct {
+	*util.DataOutputImpl
+	main   IndexOutput
+	digest hash.Hash32
+}
+
+func NewChecksumIndexOutput(main IndexOutput) *ChecksumIndexOutput {
+	ans := &ChecksumIndexOutput{main: main, digest: crc32.NewIEEE()}
+	ans.DataOutputImpl = util.NewDataOutput(ans)
+	return ans
+}
+
+func (out *ChecksumIndexOutput) WriteByte(b byte) error {
+	out.digest.Write([]byte{b})
+	return out.main.WriteByte(b)
+}
+
+func (out *ChecksumIndexOutput) WriteBytes(buf []byte) error {
+	out.digest.Write(buf)
+	return out.main.WriteBytes(buf)
+}
+
+func (out *ChecksumIndexOutput) Checksum() int64 {
+	return int64(out.digest.Sum32())
+}
+
+func (out *ChecksumIndexOutput) Flush() error {
+	return out.main.Flush()
+}
+
+func (out *ChecksumIndexOutput) Close() error {
+	return out.main.Close()
+}
+
+func (out *ChecksumIndexOutput) FilePointer() int64 {
+	return out.main.FilePointer()
+}
+
+// Writes the checksum computed so far, as the last long of the file.
+func (out *ChecksumIndexOutput) FinishCommit() error {
+	return out.main.WriteLong(out.Checksum())
+}
+
+func (out *ChecksumIndexOutput) Length() (int64, error) {
+	return out.main.Length()
+}
+
+func (out *ChecksumIndexOutput) String() string {
+	return fmt.Sprintf("ChecksumIndexOutput(%v)", out.main)
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFSIndexOutput(t *testing.T) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	d, err := OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	main, err := d.CreateOutput("test.bin", IO_CONTEXT_DEFAULT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := NewChecksumIndexOutput(main)
+	big := make([]byte, OUTPUT_BUFFER_SIZE+10) // bypasses the buffer
+	for i := range big {
+		big[i] = byten(int64(i))
+	}
+	for _, write := range []func() error{
+		func() error { return out.WriteByte(7) },
+		func() error { return out.WriteInt(-2) },
+		func() error { return out.WriteVInt(1 << 20) },
+		func() error { return out.WriteLong(1<<40 + 3) },
+		func() error { return out.WriteVLong(1 << 40) },
+		func() error { return out.WriteString("golucene") },
+		func() error { return out.WriteStringStringMap(map[string]string{"k": "v"}) },
+		func() error { return out.WriteBytes(big) },
+	} {
+		if err = write(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checksum := out.Checksum()
+	fp := out.FilePointer()
+	if err = out.FinishCommit(); err != nil {
+		t.Fatal(err)
+	}
+	if err = out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.Sync([]string{"test.bin"}); err != nil {
+		t.Fatal(err)
+	}
+	if length, err := d.FileLength("test.bin"); err != nil || length != fp+8 {
+		t.Errorf("Expected length %v, but was %v (%v)", fp+8, length, err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(path, "test.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, int64(crc32.ChecksumIEEE(data[:fp])), checksum)
+
+	in, err := d.OpenInput("test.bin", IO_CONTEXT_READONCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	b, _ := in.ReadByte()
+	assertEquals(t, b, byte(7))
+	i, _ := in.ReadInt()
+	assertEquals(t, i, int32(-2))
+	vi, _ := in.ReadVInt()
+	assertEquals(t, vi, int32(1<<20))
+	l, _ := in.ReadLong()
+	assertEquals(t, l, int64(1<<40+3))
+	vl, _ := in.ReadVLong()
+	assertEquals(t, vl, int64(1<<40))
+	s, _ := in.ReadString()
+	assertEquals(t, s, "golucene")
+	m, _ := in.ReadStringStringMap()
+	assertEquals(t, m["k"], "v")
+	buf := make([]byte, len(big))
+	if err = in.ReadBytes(buf); err != nil {
+		t.Fatal(err)
+	}
+	for i := range buf {
+		if buf[i] != big[i] {
+			t.Fatalf("Byte %v: expected %v, but was %v", i, big[i], buf[i])
+		}
+	}
+	l, _ = in.ReadLong()
+	assertEquals(t, l, checksum)
+
+	if err = d.DeleteFile("test.bin"); err != nil {
+		t.Fatal(err)
+	}
+	if d.FileExists("test.bin") {
+		t.Error("File should be deleted")
+	}
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"github.com/balzaczyy/golucene/util"
+)
+
+// store/RAMOutputStream.java
+
+/*
+A memory-resident IndexOutput implementation, used to buffer index
+data which is later copied to a real file, e.g. a block of terms
+whose length must be known before it is written.
+*/
+type RAMOutputStream struct {
+	*util.DataOutputImpl
+	buf []byte
+}
+
+// Construct an empty output buffer.
+func NewRAMOutputStream() *RAMOutputStream {
+	ans := &RAMOutputStream{}
+	ans.DataOutputImpl = util.NewDataOutput(ans)
+	return ans
+}
+
+func (out *RAMOutputStream) WriteByte(b byte) error {
+	out.buf = append(out.buf, b)
+	return nil
+}
+
+func (out *RAMOutputStream) WriteBytes(buf []byte) error {
+	out.buf = append(out.buf, buf...)
+	return nil
+}
+
+// Copy the current contents of this buffer to the named output.
+func (out *RAMOutputStream) WriteTo(to util.DataOutput) error {
+	return to.WriteBytes(out.buf)
+}
+
+// Copy the current contents of this buffer to the output byte slice.
+func (out *RAMOutputStream) WriteToBytes(bytes []byte) {
+	copy(bytes, out.buf)
+}
+
+// Resets this to an empty file.
+func (out *RAMOutputStream) Reset() {
+	out.buf = out.buf[:0]
+}
+
+func (out *RAMOutputStream) Flush() error {
+	return nil
+}
+
+func (out *RAMOutputStream) Close() error {
+	return nil
+}
+
+func (out *RAMOutputStream) FilePointer() int64 {
+	return int64(len(out.buf))
+}
+
+func (out *RAMOutputStream) Length() (int64, error) {
+	return int64(len(out.buf)), nil
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// store/RateLimitedDirectoryWrapper.java
+
+/*
+A Directory wrapper that allows IndexOutput rate limiting using IO
+context specific rate limiters (RateLimiter).
+
+Typically used to cap the write rate of merges, so that large merges
+don't starve the IO of searches on the same disk:
+
+	dir := store.NewRateLimitedDirectoryWrapper(fsDir)
+	dir.SetMaxWriteMBPerSec(20, store.IO_CONTEXT_TYPE_MERGE)
+	w, err := index.NewIndexWriter(dir, conf)
+*/
+type RateLimitedDirectoryWrapper struct {
+	Directory
+	sync.Locker
+	contextRateLimiters map[IOContextType]RateLimiter
+}
+
+func NewRateLimitedDirectoryWrapper(wrapped Directory) *RateLimitedDirectoryWrapper {
+	return &RateLimitedDirectoryWrapper{
+		Directory:           wrapped,
+		Locker:              &sync.Mutex{},
+		contextRateLimiters: make(map[IOContextType]RateLimiter),
+	}
+}
+
+func (d *RateLimitedDirectoryWrapper) CreateOutput(name string, ctx IOContext) (IndexOutput, error) {
+	output, err := d.Directory.CreateOutput(name, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if limiter := d.rateLimiter(ctx.context); limiter != nil {
+		return newRateLimitedIndexOutput(limiter, output), nil
+	}
+	return output, nil
+}
+
+func (d *RateLimitedDirectoryWrapper) rateLimiter(context IOContextType) RateLimiter {
+	d.Lock()
+	defer d.Unlock()
+	return d.contextRateLimiters[context]
+}
+
+/*
+Sets the maximum (approx) MB/sec allowed by all write IO performed by
+IndexOutput created with the given IOContextType. Pass 0 to have no
+limit.
+
+NOTE: For already created IndexOutput instances there is no
+guarantee this new rate will apply to them; it will only be
+guaranteed to apply for new created IndexOutput instances.
+
+NOTE: this is an optional operation and might not be respected by
+all Directory implementations. Currently only buffered (FSDirectory)
+Directory implementations use rate-limiting.
+*/
+func (d *RateLimitedDirectoryWrapper) SetMaxWriteMBPerSec(mbPerSec float64, context IOContextType) {
+	d.Lock()
+	defer d.Unlock()
+	limiter, ok := d.contextRateLimiters[context]
+	switch {
+	case mbPerSec <= 0:
+		if ok {
+			limiter.SetMbPerSec(math.MaxFloat64)
+			delete(d.contextRateLimiters, context)
+		}
+	case ok:
+		limiter.SetMbPerSec(mbPerSec)
+	default:
+		d.contextRateLimiters[context] = NewSimpleRateLimiter(mbPerSec)
+	}
+}
+
+/*
+Sets the rate limiter to be used to limit (approx) MB/sec allowed by
+all IO performed with the given context. Pass nil to have no limit.
+
+Passing an instance of rate limiter compared to setting it using
+SetMaxWriteMBPerSec() allows to use the same limiter instance across
+several directories globally limiting IO across them.
+*/
+func (d *RateLimitedDirectoryWrapper) SetRateLimiter(mergeWriteRateLimiter RateLimiter, context IOContextType) {
+	d.Lock()
+	defer d.Unlock()
+	if mergeWriteRateLimiter == nil {
+		delete(d.contextRateLimiters, context)
+	} else {
+		d.contextRateLimiters[context] = mergeWriteRateLimiter
+	}
+}
+
+// See SetMaxWriteMBPerSec(). Returns 0 if there is no limit.
+func (d *RateLimitedDirectoryWrapper) MaxWriteMBPerSec(context IOContextType) float64 {
+	if limiter := d.rateLimiter(context); limiter != nil {
+		return limiter.MbPerSec()
+	}
+	return 0
+}
+
+func (d *RateLimitedDirectoryWrapper) String() string {
+	return fmt.Sprintf("RateLimitedDirectoryWrapper(%v)", d.Directory)
+}
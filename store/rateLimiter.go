@@ -0,0 +1,154 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// store/RateLimiter.java
+
+/*
+Abstract base class to rate limit IO. Typically implementations are
+shared across multiple IndexInputs or IndexOutputs (for example those
+involved all merging). Those IndexInputs and IndexOutputs would call
+Pause() whenever they want to read bytes or write bytes.
+*/
+type RateLimiter interface {
+	/*
+		Sets an updated mb per second rate limit. The new rate is used
+		from the next call to Pause() on.
+	*/
+	SetMbPerSec(mbPerSec float64)
+	// The current mb per second rate limit.
+	MbPerSec() float64
+	/*
+		Pauses, if necessary, to keep the instantaneous IO rate at or
+		below the target.
+
+		Note: the implementation is thread-safe. Returns the pause time
+		in nano seconds.
+	*/
+	Pause(bytes int64) int64
+}
+
+// store/RateLimiter.java/SimpleRateLimiter
+
+/*
+Simple class to rate limit IO. The rate limit is shared by all the
+goroutines calling Pause(), so the limit applies to their combined
+IO.
+*/
+type SimpleRateLimiter struct {
+	sync.Locker
+	mbPerSec  float64
+	nsPerByte float64
+	lastNS    int64
+}
+
+// mbPerSec is the MB/sec max IO rate
+func NewSimpleRateLimiter(mbPerSec float64) *SimpleRateLimiter {
+	ans := &SimpleRateLimiter{Locker: &sync.Mutex{}}
+	ans.SetMbPerSec(mbPerSec)
+	return ans
+}
+
+func (rl *SimpleRateLimiter) SetMbPerSec(mbPerSec float64) {
+	rl.Lock()
+	defer rl.Unlock()
+	rl.mbPerSec = mbPerSec
+	rl.nsPerByte = 1000000000 / (1024 * 1024 * mbPerSec)
+}
+
+func (rl *SimpleRateLimiter) MbPerSec() float64 {
+	rl.Lock()
+	defer rl.Unlock()
+	return rl.mbPerSec
+}
+
+/*
+Pauses, if necessary, to keep the instantaneous IO rate at or below
+the target. NOTE: multiple goroutines may safely use this, however
+the implementation is not perfectly thread safe but likely in
+practice this is harmless (just means in some rare cases the rate
+might exceed the target). It's best to call this with a biggish
+count, not one byte at a time.
+*/
+func (rl *SimpleRateLimiter) Pause(bytes int64) int64 {
+	if bytes == 1 {
+		return 0
+	}
+	// TODO: this is purely instantaneous rate; maybe we should also
+	// offer decayed recent history one?
+	rl.Lock()
+	delta := float64(bytes) * rl.nsPerByte
+	if delta > math.MaxInt64/2 {
+		delta = math.MaxInt64 / 2
+	}
+	targetNS := rl.lastNS + int64(delta)
+	startNS := time.Now().UnixNano()
+	rl.lastNS = targetNS
+	if rl.lastNS < startNS {
+		rl.lastNS = startNS
+	}
+	rl.Unlock()
+
+	curNS := startNS
+	// While loop because time.Sleep doesn't always sleep enough:
+	for pauseNS := targetNS - curNS; pauseNS > 0; pauseNS = targetNS - curNS {
+		time.Sleep(time.Duration(pauseNS))
+		curNS = time.Now().UnixNano()
+	}
+	return curNS - startNS
+}
+
+func (rl *SimpleRateLimiter) String() string {
+	return fmt.Sprintf("SimpleRateLimiter(mbPerSec=%v)", rl.MbPerSec())
+}
+
+// store/RateLimitedIndexOutput.java
+
+/*
+A rate limiting IndexOutput, which pauses on the RateLimiter before
+the buffered bytes are written to the delegate.
+*/
+type RateLimitedIndexOutput struct {
+	*BufferedIndexOutput
+	delegate    IndexOutput
+	rateLimiter RateLimiter
+}
+
+func newRateLimitedIndexOutput(rateLimiter RateLimiter, delegate IndexOutput) *RateLimitedIndexOutput {
+	ans := &RateLimitedIndexOutput{delegate: delegate, rateLimiter: rateLimiter}
+	ans.BufferedIndexOutput = newBufferedIndexOutput(OUTPUT_BUFFER_SIZE, ans)
+	return ans
+}
+
+func (out *RateLimitedIndexOutput) flushBuffer(buf []byte) error {
+	out.rateLimiter.Pause(int64(len(buf)))
+	return out.delegate.WriteBytes(buf)
+}
+
+func (out *RateLimitedIndexOutput) Flush() error {
+	if err := out.BufferedIndexOutput.Flush(); err != nil {
+		return err
+	}
+	return out.delegate.Flush()
+}
+
+func (out *RateLimitedIndexOutput) Close() error {
+	err := out.BufferedIndexOutput.Close()
+	if err2 := out.delegate.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+func (out *RateLimitedIndexOutput) Length() (int64, error) {
+	return out.delegate.Length()
+}
+
+func (out *RateLimitedIndexOutput) String() string {
+	return fmt.Sprintf("RateLimitedIndexOutput(%v)", out.delegate)
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSimpleRateLimiterPause(t *testing.T) {
+	limiter := NewSimpleRateLimiter(10)
+	start := time.Now()
+	var paused int64
+	// the first call has no previous IO to pay for
+	for i := 0; i < 5; i++ {
+		paused += limiter.Pause(256 * 1024) // 1MB paid, ~100ms
+	}
+	elapsed := time.Since(start)
+	if elapsed < 70*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected about 100ms, but took %v", elapsed)
+	}
+	if paused <= 0 || time.Duration(paused) > elapsed {
+		t.Errorf("Unexpected pause time %v (elapsed %v)", time.Duration(paused), elapsed)
+	}
+	assertEquals(t, int64(0), limiter.Pause(1))
+}
+
+func TestRateLimitedDirectoryWrapper(t *testing.T) {
+	path, err := ioutil.TempDir("", "golucene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	fsDir, err := OpenFSDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewRateLimitedDirectoryWrapper(fsDir)
+	assertEquals(t, 0.0, d.MaxWriteMBPerSec(IO_CONTEXT_TYPE_MERGE))
+	d.SetMaxWriteMBPerSec(5, IO_CONTEXT_TYPE_MERGE)
+	assertEquals(t, 5.0, d.MaxWriteMBPerSec(IO_CONTEXT_TYPE_MERGE))
+
+	out, err := d.CreateOutput("flush.bin", IO_CONTEXT_DEFAULT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := out.(*RateLimitedIndexOutput); ok {
+		t.Error("Only merge outputs should be rate limited")
+	}
+	if err = out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = d.CreateOutput("merge.bin", NewIOContextFromType(IO_CONTEXT_TYPE_MERGE))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := out.(*RateLimitedIndexOutput); !ok {
+		t.Errorf("Merge output should be rate limited, but was %v", out)
+	}
+	data := make([]byte, 512*1024) // ~100ms at 5MB/s
+	for i := range data {
+		data[i] = byte(i)
+	}
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err = out.WriteBytes(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("Write should be throttled, but took %v", elapsed)
+	}
+	if length, err := d.FileLength("merge.bin"); err != nil || length != int64(2*len(data)) {
+		t.Errorf("Expected length %v, but was %v (%v)", 2*len(data), length, err)
+	}
+
+	// disabled again
+	d.SetMaxWriteMBPerSec(0, IO_CONTEXT_TYPE_MERGE)
+	assertEquals(t, 0.0, d.MaxWriteMBPerSec(IO_CONTEXT_TYPE_MERGE))
+	out, err = d.CreateOutput("merge2.bin", NewIOContextFromType(IO_CONTEXT_TYPE_MERGE))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := out.(*RateLimitedIndexOutput); ok {
+		t.Error("Rate limiting should be disabled")
+	}
+	if err = out.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
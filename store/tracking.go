@@ -0,0 +1,55 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+)
+
+// store/TrackingDirectoryWrapper.java
+
+/*
+A delegating Directory that records which files were written to and
+deleted.
+*/
+type TrackingDirectoryWrapper struct {
+	Directory
+	sync.Locker
+	createdFileNames map[string]bool
+}
+
+func NewTrackingDirectoryWrapper(other Directory) *TrackingDirectoryWrapper {
+	return &TrackingDirectoryWrapper{
+		Directory:        other,
+		Locker:           &sync.Mutex{},
+		createdFileNames: make(map[string]bool),
+	}
+}
+
+func (d *TrackingDirectoryWrapper) DeleteFile(name string) error {
+	d.Lock()
+	delete(d.createdFileNames, name)
+	d.Unlock()
+	return d.Directory.DeleteFile(name)
+}
+
+func (d *TrackingDirectoryWrapper) CreateOutput(name string, ctx IOContext) (IndexOutput, error) {
+	d.Lock()
+	d.createdFileNames[name] = true
+	d.Unlock()
+	return d.Directory.CreateOutput(name, ctx)
+}
+
+// Returns the names of all files created through this wrapper.
+func (d *TrackingDirectoryWrapper) CreatedFiles() map[string]bool {
+	d.Lock()
+	defer d.Unlock()
+	ans := make(map[string]bool)
+	for name, _ := range d.createdFileNames {
+		ans[name] = true
+	}
+	return ans
+}
+
+func (d *TrackingDirectoryWrapper) String() string {
+	return fmt.Sprintf("TrackingDirectoryWrapper(%v)", d.Directory)
+}
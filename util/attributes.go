@@ -1,8 +1,258 @@
 package util
 
+import (
+	"fmt"
+	"reflect"
+)
+
+// util/Attribute.java
+
+/*
+Base interface for attributes.
+
+Attribute interfaces are identified by their names, e.g.
+"CharTermAttribute", by which they are added to and retrieved from an
+AttributeSource.
+*/
+type Attribute interface{}
+
+// util/AttributeImpl.java
+
+// Base interface for the implementations of Attributes, which are
+// added to an AttributeSource.
+type AttributeImpl interface {
+	Attribute
+	// Returns the names of the Attribute interfaces this
+	// implementation provides.
+	Interfaces() []string
+	// Clears the values in this AttributeImpl and resets it to its
+	// default value.
+	Clear()
+	/*
+		Copies the values from this Attribute into the passed-in target
+		attribute. The target implementation must support all the
+		Attributes this implementation supports.
+	*/
+	CopyTo(target AttributeImpl)
+	// Returns a copy of this AttributeImpl, with the same values.
+	Clone() AttributeImpl
+}
+
+// util/AttributeSource.java/AttributeFactory
+
+// An AttributeFactory creates instances of AttributeImpls.
+type AttributeFactory interface {
+	// Returns an AttributeImpl for the supplied Attribute interface
+	// name, or nil if the name is unknown.
+	Create(name string) AttributeImpl
+}
+
+// Implementations of the Attribute interfaces, registered by name.
+var attributeImplCreators = make(map[string]func() AttributeImpl)
+
+/*
+Registers the function creating the default implementation of the
+named Attribute interface, which is used by DEFAULT_ATTRIBUTE_FACTORY.
+It is intended to be called from the init() function of the package
+defining the Attribute, and panics if the name is already registered.
+*/
+func RegisterAttributeImpl(name string, creator func() AttributeImpl) {
+	if _, ok := attributeImplCreators[name]; ok {
+		panic(fmt.Sprintf("Attribute %v is already registered", name))
+	}
+	attributeImplCreators[name] = creator
+}
+
+// This is the default factory that creates AttributeImpls using the
+// implementations registered with RegisterAttributeImpl().
+var DEFAULT_ATTRIBUTE_FACTORY AttributeFactory = defaultAttributeFactory{}
+
+type defaultAttributeFactory struct{}
+
+func (f defaultAttributeFactory) Create(name string) AttributeImpl {
+	if creator, ok := attributeImplCreators[name]; ok {
+		return creator()
+	}
+	return nil
+}
+
+// util/AttributeSource.java
+
+/*
+An AttributeSource contains a list of different AttributeImpls, and
+methods to add and get them. There can only be a single instance of
+an attribute in the same AttributeSource instance. This is ensured
+by passing in the name of the actual Attribute interface to Add(),
+which then checks if an instance of that type is already present. If
+yes, it returns the instance, otherwise it creates a new instance and
+returns it.
+*/
 type AttributeSource struct {
+	factory AttributeFactory
+	// the implementation of each Attribute interface
+	attributes map[string]AttributeImpl
+	// the implementations, by type, in the order they were added
+	attributeImpls map[reflect.Type]AttributeImpl
+	impls          []AttributeImpl
+}
+
+// An AttributeSource using the default attribute factory
+// DEFAULT_ATTRIBUTE_FACTORY.
+func NewAttributeSource() *AttributeSource {
+	return NewAttributeSourceWith(DEFAULT_ATTRIBUTE_FACTORY)
+}
+
+// An AttributeSource using the supplied AttributeFactory for creating
+// new Attribute instances.
+func NewAttributeSourceWith(factory AttributeFactory) *AttributeSource {
+	return &AttributeSource{
+		factory:        factory,
+		attributes:     make(map[string]AttributeImpl),
+		attributeImpls: make(map[reflect.Type]AttributeImpl),
+	}
+}
+
+// Returns the used AttributeFactory.
+func (as *AttributeSource) Factory() AttributeFactory {
+	return as.factory
+}
+
+/*
+Expert: Adds a custom AttributeImpl instance with one or more
+Attribute interfaces. This method will only add the interfaces
+which are not already present in this AttributeSource.
+*/
+func (as *AttributeSource) AddImpl(att AttributeImpl) {
+	clazz := reflect.TypeOf(att)
+	if _, ok := as.attributeImpls[clazz]; ok {
+		return
+	}
+	for _, name := range att.Interfaces() {
+		if _, ok := as.attributes[name]; !ok {
+			as.attributes[name] = att
+		}
+	}
+	as.attributeImpls[clazz] = att
+	as.impls = append(as.impls, att)
+}
+
+/*
+The caller must pass in the name of an Attribute interface. This
+method first checks if an instance of that interface is already in
+this AttributeSource and returns it. Otherwise a new instance is
+created by the AttributeFactory, added to this AttributeSource and
+returned. It panics if the factory cannot create the Attribute.
+*/
+func (as *AttributeSource) Add(name string) Attribute {
+	if att, ok := as.attributes[name]; ok {
+		return att
+	}
+	att := as.factory.Create(name)
+	if att == nil {
+		panic(fmt.Sprintf("Could not find implementation for %v", name))
+	}
+	as.AddImpl(att)
+	return att
+}
+
+// Returns true, iff this AttributeSource has any attributes.
+func (as *AttributeSource) HasAttributes() bool {
+	return len(as.attributes) > 0
+}
+
+// Returns true, iff this AttributeSource contains the named Attribute
+// interface.
+func (as *AttributeSource) Has(name string) bool {
+	_, ok := as.attributes[name]
+	return ok
 }
 
-func NewAttributeSource() AttributeSource {
-	return AttributeSource{}
+// Returns the instance of the named Attribute interface contained in
+// this AttributeSource, or nil if it is not contained.
+func (as *AttributeSource) Get(name string) Attribute {
+	if att, ok := as.attributes[name]; ok {
+		return att
+	}
+	return nil
+}
+
+/*
+Resets all Attributes in this AttributeSource by calling Clear() on
+each AttributeImpl implementation.
+*/
+func (as *AttributeSource) Clear() {
+	for _, att := range as.impls {
+		att.Clear()
+	}
+}
+
+/*
+Captures the state of all Attributes. The return value can be passed
+to RestoreState() to restore the state of this or another
+AttributeSource.
+*/
+func (as *AttributeSource) CaptureState() *AttributeState {
+	state := &AttributeState{make([]AttributeImpl, len(as.impls))}
+	for i, att := range as.impls {
+		state.impls[i] = att.Clone()
+	}
+	return state
+}
+
+/*
+Restores this state by copying the values of all attribute
+implementations that this state contains into the attributes
+implementations of the target stream. The target stream must contain
+a corresponding instance for each argument contained in this state,
+otherwise it panics.
+
+Note that this method does not affect attributes of the target
+stream that are not contained in this state. In other words, if for
+example the target stream contains an OffsetAttribute, but this state
+doesn't, then the value of the OffsetAttribute remains unchanged. It
+might be desirable to reset its value to the default, in which case
+the caller should first call Clear() on the target stream.
+*/
+func (as *AttributeSource) RestoreState(state *AttributeState) {
+	if state == nil {
+		return
+	}
+	for _, att := range state.impls {
+		target, ok := as.attributeImpls[reflect.TypeOf(att)]
+		if !ok {
+			panic(fmt.Sprintf(
+				"State contains AttributeImpl of type %T that is not in this AttributeSource", att))
+		}
+		att.CopyTo(target)
+	}
+}
+
+/*
+Copies the contents of this AttributeSource to the given target
+AttributeSource. The given instance has to provide all Attributes
+this instance contains. The actual attribute implementations must be
+identical in both AttributeSource instances; ideally both
+AttributeSource instances should use the same AttributeFactory.
+*/
+func (as *AttributeSource) CopyTo(target *AttributeSource) {
+	for _, att := range as.impls {
+		targetImpl, ok := target.attributeImpls[reflect.TypeOf(att)]
+		if !ok {
+			panic(fmt.Sprintf(
+				"This AttributeSource contains AttributeImpl of type %T that is not in the target", att))
+		}
+		att.CopyTo(targetImpl)
+	}
+}
+
+func (as *AttributeSource) String() string {
+	return fmt.Sprintf("AttributeSource%v", as.impls)
+}
+
+// util/AttributeSource.java/State
+
+// The state of the attributes of an AttributeSource, captured by
+// CaptureState().
+type AttributeState struct {
+	impls []AttributeImpl
 }
@@ -0,0 +1,141 @@
+package util
+
+import (
+	"testing"
+)
+
+type testFlagsAttribute interface {
+	Flags() int
+	SetFlags(flags int)
+}
+
+type testFlagsAttributeImpl struct {
+	flags int
+}
+
+func (a *testFlagsAttributeImpl) Flags() int           { return a.flags }
+func (a *testFlagsAttributeImpl) SetFlags(flags int)   { a.flags = flags }
+func (a *testFlagsAttributeImpl) Interfaces() []string { return []string{"TestFlagsAttribute"} }
+func (a *testFlagsAttributeImpl) Clear()               { a.flags = 0 }
+
+func (a *testFlagsAttributeImpl) CopyTo(target AttributeImpl) {
+	target.(testFlagsAttribute).SetFlags(a.flags)
+}
+
+func (a *testFlagsAttributeImpl) Clone() AttributeImpl {
+	return &testFlagsAttributeImpl{a.flags}
+}
+
+type testNameAttribute interface {
+	Name() string
+	SetName(name string)
+}
+
+// Implements both test attributes.
+type testNameFlagsAttributeImpl struct {
+	testFlagsAttributeImpl
+	name string
+}
+
+func (a *testNameFlagsAttributeImpl) Name() string        { return a.name }
+func (a *testNameFlagsAttributeImpl) SetName(name string) { a.name = name }
+
+func (a *testNameFlagsAttributeImpl) Interfaces() []string {
+	return []string{"TestNameAttribute", "TestFlagsAttribute"}
+}
+
+func (a *testNameFlagsAttributeImpl) Clear() {
+	a.name, a.flags = "", 0
+}
+
+func (a *testNameFlagsAttributeImpl) CopyTo(target AttributeImpl) {
+	target.(testNameAttribute).SetName(a.name)
+	target.(testFlagsAttribute).SetFlags(a.flags)
+}
+
+func (a *testNameFlagsAttributeImpl) Clone() AttributeImpl {
+	clone := *a
+	return &clone
+}
+
+type testAttributeFactory struct{}
+
+func (f testAttributeFactory) Create(name string) AttributeImpl {
+	switch name {
+	case "TestFlagsAttribute":
+		return new(testFlagsAttributeImpl)
+	case "TestNameAttribute":
+		return new(testNameFlagsAttributeImpl)
+	}
+	return nil
+}
+
+func TestAttributeSource(t *testing.T) {
+	src := NewAttributeSourceWith(testAttributeFactory{})
+	if src.HasAttributes() || src.Has("TestFlagsAttribute") || src.Get("TestFlagsAttribute") != nil {
+		t.Error("Source should have no attributes")
+	}
+	flags := src.Add("TestFlagsAttribute").(testFlagsAttribute)
+	if src.Add("TestFlagsAttribute") != flags || src.Get("TestFlagsAttribute") != flags {
+		t.Error("Attribute should be added once")
+	}
+	// only the missing interfaces of an implementation are added
+	name := src.Add("TestNameAttribute").(testNameAttribute)
+	if src.Get("TestFlagsAttribute") != flags {
+		t.Error("Existing attribute should not be replaced")
+	}
+
+	flags.SetFlags(3)
+	name.SetName("a")
+	state := src.CaptureState()
+	flags.SetFlags(5)
+	name.SetName("b")
+	src.RestoreState(state)
+	if flags.Flags() != 3 || name.Name() != "a" {
+		t.Errorf("Unexpected restored state %v, %v", flags.Flags(), name.Name())
+	}
+	src.Clear()
+	if flags.Flags() != 0 || name.Name() != "" {
+		t.Errorf("Attributes should be cleared, but were %v, %v", flags.Flags(), name.Name())
+	}
+	// states are copies, and can be restored many times
+	src.RestoreState(state)
+	src.RestoreState(state)
+	if flags.Flags() != 3 || name.Name() != "a" {
+		t.Errorf("Unexpected restored state %v, %v", flags.Flags(), name.Name())
+	}
+
+	target := NewAttributeSourceWith(testAttributeFactory{})
+	target.Add("TestFlagsAttribute")
+	target.Add("TestNameAttribute")
+	src.CopyTo(target)
+	assertAttributes(t, target, 3, "a")
+	target.Clear()
+	target.RestoreState(state)
+	assertAttributes(t, target, 3, "a")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Should not restore a state with missing attributes")
+		}
+	}()
+	NewAttributeSourceWith(testAttributeFactory{}).RestoreState(state)
+}
+
+func assertAttributes(t *testing.T, src *AttributeSource, flags int, name string) {
+	if v := src.Get("TestFlagsAttribute").(testFlagsAttribute).Flags(); v != flags {
+		t.Errorf("Expected flags %v, but was %v", flags, v)
+	}
+	if v := src.Get("TestNameAttribute").(testNameAttribute).Name(); v != name {
+		t.Errorf("Expected name %v, but was %v", name, v)
+	}
+}
+
+func TestAttributeSourceUnknownAttribute(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Should not add an unknown attribute")
+		}
+	}()
+	NewAttributeSource().Add("UnknownAttribute")
+}
@@ -0,0 +1,175 @@
+package automaton
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Transition.java
+
+/*
+Transition of a State, labeled with the inclusive interval [Min, Max]
+of code points.
+*/
+type Transition struct {
+	Min, Max rune
+	To       *State
+}
+
+func (t *Transition) String() string {
+	if t.Min == t.Max {
+		return fmt.Sprintf("%q -> %v", t.Min, t.To.number)
+	}
+	return fmt.Sprintf("%q-%q -> %v", t.Min, t.Max, t.To.number)
+}
+
+// State.java
+
+// State of an Automaton.
+type State struct {
+	accept      bool
+	transitions []*Transition
+	number      int
+}
+
+func (s *State) IsAccept() bool {
+	return s.accept
+}
+
+// Returns the outgoing transitions, sorted by Min. Since automata
+// here are deterministic, the intervals never overlap.
+func (s *State) Transitions() []*Transition {
+	return s.transitions
+}
+
+// Returns the state number, as assigned by the Automaton.
+func (s *State) Number() int {
+	return s.number
+}
+
+// Performs lookup in transitions, returning the destination state or
+// nil if there is no transition on the given code point.
+func (s *State) Step(c rune) *State {
+	for _, t := range s.transitions {
+		if c < t.Min {
+			break
+		}
+		if c <= t.Max {
+			return t.To
+		}
+	}
+	return nil
+}
+
+// Automaton.java
+
+/*
+Finite-state automaton over Unicode code points. Only deterministic
+automata (DFA) are supported, as built by LevenshteinAutomata, by the
+Make*() functions of BasicAutomata, by the operations of
+BasicOperations, e.g. Concatenate() or Union(), and by RegExp.
+*/
+type Automaton struct {
+	initial *State
+	states  []*State
+}
+
+func newAutomaton(initial *State, states []*State) *Automaton {
+	for i, s := range states {
+		s.number = i
+	}
+	return &Automaton{initial, states}
+}
+
+func (a *Automaton) InitialState() *State {
+	return a.initial
+}
+
+// Returns the states reachable from the initial state, indexed by
+// state number.
+func (a *Automaton) NumberedStates() []*State {
+	return a.states
+}
+
+// Returns true if the given string is accepted by the automaton.
+func (a *Automaton) Run(s string) bool {
+	return a.RunBytes([]byte(s))
+}
+
+// Returns true if the given UTF-8 encoded bytes are accepted by the
+// automaton.
+func (a *Automaton) RunBytes(text []byte) bool {
+	p := a.initial
+	for len(text) > 0 {
+		c, size := utf8.DecodeRune(text)
+		if p = p.Step(c); p == nil {
+			return false
+		}
+		text = text[size:]
+	}
+	return p.accept
+}
+
+/*
+Removes transitions to dead states, i.e. states from which no accept
+state can be reached, and renumbers the remaining live states.
+*/
+func (a *Automaton) RemoveDeadTransitions() {
+	live := make(map[*State]bool)
+	for _, s := range a.states {
+		if s.accept {
+			live[s] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, s := range a.states {
+			if live[s] {
+				continue
+			}
+			for _, t := range s.transitions {
+				if live[t.To] {
+					live[s] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var states []*State
+	for _, s := range a.states {
+		if !live[s] && s != a.initial {
+			continue
+		}
+		transitions := s.transitions[:0]
+		for _, t := range s.transitions {
+			if live[t.To] {
+				transitions = append(transitions, t)
+			}
+		}
+		s.transitions = transitions
+		states = append(states, s)
+	}
+	for i, s := range states {
+		s.number = i
+	}
+	a.states = states
+}
+
+func (a *Automaton) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "initial state: %v\n", a.initial.number)
+	for _, s := range a.states {
+		fmt.Fprintf(&buf, "state %v", s.number)
+		if s.accept {
+			buf.WriteString(" [accept]")
+		}
+		buf.WriteString(":\n")
+		for _, t := range s.transitions {
+			fmt.Fprintf(&buf, "  %v\n", t)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,65 @@
+package automaton
+
+import (
+	"unicode"
+)
+
+// BasicAutomata.java
+
+// Returns a new (deterministic) automaton with the empty language.
+func MakeEmpty() *Automaton {
+	s := &State{}
+	return newAutomaton(s, []*State{s})
+}
+
+// Returns a new (deterministic) automaton that accepts only the empty
+// string.
+func MakeEmptyString() *Automaton {
+	s := &State{accept: true}
+	return newAutomaton(s, []*State{s})
+}
+
+// Returns a new (deterministic) automaton that accepts all strings.
+func MakeAnyString() *Automaton {
+	s := &State{accept: true}
+	s.transitions = []*Transition{{0, unicode.MaxRune, s}}
+	return newAutomaton(s, []*State{s})
+}
+
+// Returns a new (deterministic) automaton that accepts any single
+// code point.
+func MakeAnyChar() *Automaton {
+	return MakeCharRange(0, unicode.MaxRune)
+}
+
+// Returns a new (deterministic) automaton that accepts a single code
+// point of the given value.
+func MakeChar(c rune) *Automaton {
+	return MakeCharRange(c, c)
+}
+
+// Returns a new (deterministic) automaton that accepts a single code
+// point whose value is in the given interval (including both end
+// points).
+func MakeCharRange(min, max rune) *Automaton {
+	if min > max {
+		return MakeEmpty()
+	}
+	s1, s2 := &State{}, &State{accept: true}
+	s1.transitions = []*Transition{{min, max, s2}}
+	return newAutomaton(s1, []*State{s1, s2})
+}
+
+// Returns a new (deterministic) automaton that accepts the single
+// given string.
+func MakeString(s string) *Automaton {
+	initial := &State{}
+	states := []*State{initial}
+	for _, c := range s {
+		next := &State{}
+		states[len(states)-1].transitions = []*Transition{{c, c, next}}
+		states = append(states, next)
+	}
+	states[len(states)-1].accept = true
+	return newAutomaton(initial, states)
+}
@@ -0,0 +1,359 @@
+package automaton
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// BasicOperations.java
+
+/*
+The operations build nondeterministic automata out of copies of their
+arguments, joined by epsilon transitions, and determinize the result,
+so the arguments are never modified and all automata stay
+deterministic.
+*/
+
+// Returns a copy of the states of a, and the copy of its initial
+// state.
+func (a *Automaton) clone() (*State, []*State) {
+	m := make(map[*State]*State, len(a.states))
+	states := make([]*State, len(a.states))
+	for i, s := range a.states {
+		states[i] = &State{accept: s.accept}
+		m[s] = states[i]
+	}
+	for _, s := range a.states {
+		p := m[s]
+		p.transitions = make([]*Transition, len(s.transitions))
+		for i, t := range s.transitions {
+			p.transitions[i] = &Transition{t.Min, t.Max, m[t.To]}
+		}
+	}
+	return m[a.initial], states
+}
+
+// Returns the accept states of the given states.
+func acceptStates(states []*State) []*State {
+	var ans []*State
+	for _, s := range states {
+		if s.accept {
+			ans = append(ans, s)
+		}
+	}
+	return ans
+}
+
+/*
+Adds an epsilon transition from s to to: s gets the transitions of to,
+and accepts if to does. Later changes of to are not reflected in s.
+*/
+func (s *State) addEpsilon(to *State) {
+	if to.accept {
+		s.accept = true
+	}
+	for _, t := range to.transitions {
+		s.transitions = append(s.transitions, &Transition{t.Min, t.Max, t.To})
+	}
+}
+
+/*
+Returns an automaton that accepts the concatenation of the languages
+of the given automata, in order. Returns an automaton accepting only
+the empty string if none is given.
+*/
+func Concatenate(as ...*Automaton) *Automaton {
+	if len(as) == 0 {
+		return MakeEmptyString()
+	}
+	initial, states := as[0].clone()
+	ac := acceptStates(states)
+	for _, a := range as[1:] {
+		aa, aaStates := a.clone()
+		ns := acceptStates(aaStates)
+		for _, s := range ac {
+			s.accept = false
+			s.addEpsilon(aa)
+			if s.accept {
+				ns = append(ns, s)
+			}
+		}
+		ac = ns
+	}
+	return determinize(initial)
+}
+
+// Returns an automaton that accepts the union of the languages of the
+// given automata.
+func Union(as ...*Automaton) *Automaton {
+	s := &State{}
+	for _, a := range as {
+		initial, _ := a.clone()
+		s.addEpsilon(initial)
+	}
+	return determinize(s)
+}
+
+// Returns an automaton that accepts the union of the empty string and
+// the language of the given automaton.
+func Optional(a *Automaton) *Automaton {
+	return Union(MakeEmptyString(), a)
+}
+
+// Returns an automaton that accepts the Kleene star (zero or more
+// concatenated repetitions) of the language of the given automaton.
+func Repeat(a *Automaton) *Automaton {
+	initial, states := a.clone()
+	s := &State{accept: true}
+	s.addEpsilon(initial)
+	for _, p := range acceptStates(states) {
+		p.addEpsilon(s)
+	}
+	return determinize(s)
+}
+
+// Returns an automaton that accepts min or more concatenated
+// repetitions of the language of the given automaton.
+func RepeatMin(a *Automaton, min int) *Automaton {
+	as := make([]*Automaton, 0, min+1)
+	for i := 0; i < min; i++ {
+		as = append(as, a)
+	}
+	return Concatenate(append(as, Repeat(a))...)
+}
+
+/*
+Returns an automaton that accepts between min and max (including
+both) concatenated repetitions of the language of the given
+automaton. Returns an automaton with the empty language if min > max.
+*/
+func RepeatMinMax(a *Automaton, min, max int) *Automaton {
+	if min > max {
+		return MakeEmpty()
+	}
+	as := make([]*Automaton, 0, max)
+	for i := 0; i < min; i++ {
+		as = append(as, a)
+	}
+	if max > min {
+		optional := Optional(a)
+		for i := min; i < max; i++ {
+			as = append(as, optional)
+		}
+	}
+	return Concatenate(as...)
+}
+
+// Returns a (deterministic) automaton that accepts the complement of
+// the language of the given automaton.
+func Complement(a *Automaton) *Automaton {
+	initial, states := a.clone()
+	// make it total, with a reject state for the missing transitions
+	sink := &State{}
+	sink.transitions = []*Transition{{0, unicode.MaxRune, sink}}
+	for _, s := range states {
+		var transitions []*Transition
+		from := rune(0)
+		for _, t := range s.transitions {
+			if from < t.Min {
+				transitions = append(transitions, &Transition{from, t.Min - 1, sink})
+			}
+			transitions = append(transitions, t)
+			from = t.Max + 1
+		}
+		if from <= unicode.MaxRune {
+			transitions = append(transitions, &Transition{from, unicode.MaxRune, sink})
+		}
+		s.transitions = transitions
+		s.accept = !s.accept
+	}
+	sink.accept = true
+	ans := newAutomaton(initial, append(states, sink))
+	ans.RemoveDeadTransitions()
+	return ans
+}
+
+// Returns a (deterministic) automaton that accepts the intersection
+// of the languages of the given automata.
+func Intersection(a1, a2 *Automaton) *Automaton {
+	type pair struct{ s1, s2 *State }
+	pairs := make(map[pair]*State)
+	var queue []pair
+	var states []*State
+	state := func(p pair) *State {
+		if s, ok := pairs[p]; ok {
+			return s
+		}
+		s := &State{accept: p.s1.accept && p.s2.accept}
+		pairs[p] = s
+		queue = append(queue, p)
+		states = append(states, s)
+		return s
+	}
+	initial := state(pair{a1.initial, a2.initial})
+	for i := 0; i < len(queue); i++ {
+		p := queue[i]
+		s := pairs[p]
+		for _, t1 := range p.s1.transitions {
+			for _, t2 := range p.s2.transitions {
+				if t2.Min > t1.Max || t2.Max < t1.Min {
+					continue
+				}
+				min, max := t1.Min, t1.Max
+				if t2.Min > min {
+					min = t2.Min
+				}
+				if t2.Max < max {
+					max = t2.Max
+				}
+				s.transitions = append(s.transitions, &Transition{min, max, state(pair{t1.To, t2.To})})
+			}
+		}
+		sort.Sort(transitionsByMin(s.transitions))
+	}
+	ans := newAutomaton(initial, states)
+	ans.RemoveDeadTransitions()
+	return ans
+}
+
+// Returns a (deterministic) automaton that accepts the strings of the
+// language of a1 which are not in the language of a2.
+func Minus(a1, a2 *Automaton) *Automaton {
+	return Intersection(a1, Complement(a2))
+}
+
+type transitionsByMin []*Transition
+
+func (ts transitionsByMin) Len() int           { return len(ts) }
+func (ts transitionsByMin) Swap(i, j int)      { ts[i], ts[j] = ts[j], ts[i] }
+func (ts transitionsByMin) Less(i, j int) bool { return ts[i].Min < ts[j].Min }
+
+type statesByNumber []*State
+
+func (ss statesByNumber) Len() int           { return len(ss) }
+func (ss statesByNumber) Swap(i, j int)      { ss[i], ss[j] = ss[j], ss[i] }
+func (ss statesByNumber) Less(i, j int) bool { return ss[i].number < ss[j].number }
+
+type runeSet []rune
+
+/*
+Determinizes the (nondeterministic) automaton of the given initial
+state by subset construction, and removes its dead transitions. The
+states of the nondeterministic automaton are renumbered.
+*/
+func determinize(initial *State) *Automaton {
+	// number the states, which identify the sets of states
+	initial.number = 0
+	seen := map[*State]bool{initial: true}
+	for stack := []*State{initial}; len(stack) > 0; {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, t := range s.transitions {
+			if !seen[t.To] {
+				t.To.number = len(seen)
+				seen[t.To] = true
+				stack = append(stack, t.To)
+			}
+		}
+	}
+
+	sets := make(map[string]*State)
+	var queue []struct {
+		state *State
+		set   []*State
+	}
+	state := func(set []*State) *State {
+		sort.Sort(statesByNumber(set))
+		set = uniqueStates(set)
+		key := fmt.Sprint(stateNumbers(set))
+		if s, ok := sets[key]; ok {
+			return s
+		}
+		s := &State{}
+		for _, p := range set {
+			if p.accept {
+				s.accept = true
+				break
+			}
+		}
+		sets[key] = s
+		queue = append(queue, struct {
+			state *State
+			set   []*State
+		}{s, set})
+		return s
+	}
+	dinitial := state([]*State{initial})
+	for i := 0; i < len(queue); i++ {
+		s, set := queue[i].state, queue[i].set
+		// the points where the set of transitions changes
+		var points runeSet
+		for _, p := range set {
+			for _, t := range p.transitions {
+				points = append(points, t.Min, t.Max+1)
+			}
+		}
+		sort.Sort(points)
+		for j := 0; j+1 < len(points); j++ {
+			min, max := points[j], points[j+1]-1
+			if min > max {
+				continue
+			}
+			var to []*State
+			for _, p := range set {
+				for _, t := range p.transitions {
+					if t.Min <= min && min <= t.Max {
+						to = append(to, t.To)
+					}
+				}
+			}
+			if len(to) > 0 {
+				s.addTransition(min, max, state(to))
+			}
+		}
+	}
+	states := make([]*State, len(queue))
+	for i, v := range queue {
+		states[i] = v.state
+	}
+	ans := newAutomaton(dinitial, states)
+	ans.RemoveDeadTransitions()
+	return ans
+}
+
+func (rs runeSet) Len() int           { return len(rs) }
+func (rs runeSet) Swap(i, j int)      { rs[i], rs[j] = rs[j], rs[i] }
+func (rs runeSet) Less(i, j int) bool { return rs[i] < rs[j] }
+
+// Removes the duplicates of the sorted states.
+func uniqueStates(set []*State) []*State {
+	ans := set[:0]
+	for i, s := range set {
+		if i == 0 || s != set[i-1] {
+			ans = append(ans, s)
+		}
+	}
+	return ans
+}
+
+func stateNumbers(set []*State) []int {
+	ans := make([]int, len(set))
+	for i, s := range set {
+		ans[i] = s.number
+	}
+	return ans
+}
+
+// Adds a transition after the existing ones, which are all below min,
+// merging it with the last one if they are adjacent with the same
+// destination.
+func (s *State) addTransition(min, max rune, to *State) {
+	if n := len(s.transitions); n > 0 {
+		if last := s.transitions[n-1]; last.To == to && last.Max+1 == min {
+			last.Max = max
+			return
+		}
+	}
+	s.transitions = append(s.transitions, &Transition{min, max, to})
+}
@@ -0,0 +1,110 @@
+package automaton
+
+import (
+	"unicode"
+)
+
+// CompiledAutomaton.java
+
+// The kind of the language of a CompiledAutomaton, which tells how to
+// enumerate the terms it accepts.
+type AutomatonType int
+
+const (
+	// Automaton that accepts no strings.
+	AUTOMATON_TYPE_NONE = AutomatonType(1)
+	// Automaton that accepts all possible strings.
+	AUTOMATON_TYPE_ALL = AutomatonType(2)
+	// Automaton that accepts only a single fixed string.
+	AUTOMATON_TYPE_SINGLE = AutomatonType(3)
+	// Automaton that matches all strings with a constant prefix.
+	AUTOMATON_TYPE_PREFIX = AutomatonType(4)
+	// Catch-all for any other automata.
+	AUTOMATON_TYPE_NORMAL = AutomatonType(5)
+)
+
+/*
+Immutable class holding compiled details for a given Automaton. The
+Automaton is deterministic, and must not have dead states.
+
+The cheaper enumerations of the special types, e.g. seeking to the
+single term, or to the prefix, should be used instead of intersecting
+the terms with the automaton, which is only needed for the NORMAL
+type.
+*/
+type CompiledAutomaton struct {
+	Type AutomatonType
+	// For AUTOMATON_TYPE_SINGLE this is the singleton term, and for
+	// AUTOMATON_TYPE_PREFIX this is the prefix term; nil otherwise.
+	Term []byte
+	// The automaton, which matching terms are run against.
+	Automaton *Automaton
+}
+
+func NewCompiledAutomaton(a *Automaton) *CompiledAutomaton {
+	ans := &CompiledAutomaton{Automaton: a}
+	// walk the common prefix, i.e. the states with a single transition
+	// on a single code point, which are not accept states
+	var prefix []rune
+	p := a.initial
+	seen := make(map[*State]bool)
+	for !p.accept && len(p.transitions) == 1 && !seen[p] {
+		t := p.transitions[0]
+		if t.Min != t.Max {
+			break
+		}
+		seen[p] = true
+		prefix = append(prefix, t.Min)
+		p = t.To
+	}
+	switch {
+	case !p.accept && len(p.transitions) == 0:
+		// as there are no dead states, this can only be the initial
+		// state of the empty language
+		ans.Type = AUTOMATON_TYPE_NONE
+	case p.accept && len(p.transitions) == 0:
+		ans.Type = AUTOMATON_TYPE_SINGLE
+		ans.Term = []byte(string(prefix))
+	case acceptsAnyString(p):
+		if len(prefix) == 0 {
+			ans.Type = AUTOMATON_TYPE_ALL
+		} else {
+			ans.Type = AUTOMATON_TYPE_PREFIX
+			ans.Term = []byte(string(prefix))
+		}
+	default:
+		ans.Type = AUTOMATON_TYPE_NORMAL
+	}
+	return ans
+}
+
+/*
+Returns true if all strings are accepted from p. As there are no dead
+states, this is the case if all the states reachable from p accept,
+and have transitions for all code points.
+*/
+func acceptsAnyString(p *State) bool {
+	seen := map[*State]bool{p: true}
+	for stack := []*State{p}; len(stack) > 0; {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !s.accept {
+			return false
+		}
+		from := rune(0)
+		for _, t := range s.transitions {
+			if t.Min != from {
+				return false
+			}
+			from = t.Max + 1
+			if !seen[t.To] {
+				seen[t.To] = true
+				stack = append(stack, t.To)
+			}
+		}
+		if from <= unicode.MaxRune {
+			return false
+		}
+	}
+	return true
+}
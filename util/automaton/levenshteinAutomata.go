@@ -0,0 +1,217 @@
+package automaton
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// LevenshteinAutomata.java
+
+// Maximum edit distance this class can generate an automaton for.
+const LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE = 2
+
+/*
+Class to construct DFAs that match a word within some edit distance.
+
+Implements the algorithm described in: Schulz and Mihov: Fast String
+Correction with Levenshtein Automata. Instead of the precomputed
+parametric descriptions of the Java version, the DFA is built by
+subset construction over the positions (offset, edits) of the
+nondeterministic automaton, with the alphabet partitioned into the
+characters of the word and one class for all other characters. This
+is cheap for the supported distances.
+
+The first prefixLength characters of the word must match exactly.
+*/
+type LevenshteinAutomata struct {
+	word           []rune
+	prefixLength   int
+	transpositions bool
+	alphabet       []rune // sorted distinct characters of the word
+}
+
+/*
+Create a new LevenshteinAutomata for some input string. Optionally
+count transpositions as a primitive edit (Damerau distance), and
+require the first prefixLength characters to match exactly.
+*/
+func NewLevenshteinAutomata(input string, prefixLength int, withTranspositions bool) *LevenshteinAutomata {
+	word := []rune(input)
+	if prefixLength > len(word) {
+		prefixLength = len(word)
+	}
+	seen := make(map[rune]bool)
+	var alphabet []rune
+	for _, c := range word {
+		if !seen[c] {
+			seen[c] = true
+			alphabet = append(alphabet, c)
+		}
+	}
+	sort.Sort(runeSlice(alphabet))
+	return &LevenshteinAutomata{word, prefixLength, withTranspositions, alphabet}
+}
+
+/*
+Compute a DFA that accepts all strings within an edit distance of n.
+Returns error if n is negative or exceeds
+LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE.
+*/
+func (la *LevenshteinAutomata) ToAutomaton(n int) (*Automaton, error) {
+	if n < 0 || n > LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE {
+		return nil, errors.New(fmt.Sprintf("edit distance must be within [0, %v], but was %v",
+			LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE, n))
+	}
+
+	b := &levenshteinBuilder{la, n, make(map[string]*State), nil}
+	initial := b.state(b.closure([]position{{0, 0, false}}))
+	for i := 0; i < len(b.queue); i++ {
+		s, positions := b.queue[i].state, b.queue[i].positions
+		// characters below, between and above the word's characters
+		// share the transitions of the "other" class
+		other := b.step(positions, -1)
+		from := rune(0)
+		for _, c := range la.alphabet {
+			if from < c {
+				b.addTransition(s, from, c-1, other)
+			}
+			b.addTransition(s, c, c, b.step(positions, c))
+			from = c + 1
+		}
+		if from <= unicode.MaxRune {
+			b.addTransition(s, from, unicode.MaxRune, other)
+		}
+	}
+	states := make([]*State, len(b.queue))
+	for i, v := range b.queue {
+		states[i] = v.state
+	}
+	a := newAutomaton(initial, states)
+	a.RemoveDeadTransitions()
+	return a, nil
+}
+
+/*
+A position of the nondeterministic automaton: offset characters of
+the word are consumed with edits used. A transposed position is
+waiting for word[offset], having just read word[offset+1].
+*/
+type position struct {
+	offset, edits int
+	transposed    bool
+}
+
+type positionSlice []position
+
+func (p positionSlice) Len() int      { return len(p) }
+func (p positionSlice) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p positionSlice) Less(i, j int) bool {
+	if p[i].offset != p[j].offset {
+		return p[i].offset < p[j].offset
+	}
+	if p[i].edits != p[j].edits {
+		return p[i].edits < p[j].edits
+	}
+	return !p[i].transposed && p[j].transposed
+}
+
+type runeSlice []rune
+
+func (p runeSlice) Len() int           { return len(p) }
+func (p runeSlice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p runeSlice) Less(i, j int) bool { return p[i] < p[j] }
+
+type levenshteinBuilder struct {
+	*LevenshteinAutomata
+	n      int
+	states map[string]*State
+	queue  []struct {
+		state     *State
+		positions []position
+	}
+}
+
+// Returns the DFA state of the given set of positions, creating and
+// enqueuing it if it's new.
+func (b *levenshteinBuilder) state(positions []position) *State {
+	sort.Sort(positionSlice(positions))
+	key := fmt.Sprint(positions)
+	if s, ok := b.states[key]; ok {
+		return s
+	}
+	s := &State{}
+	for _, p := range positions {
+		if !p.transposed && p.offset == len(b.word) {
+			s.accept = true
+			break
+		}
+	}
+	b.states[key] = s
+	b.queue = append(b.queue, struct {
+		state     *State
+		positions []position
+	}{s, positions})
+	return s
+}
+
+func (b *levenshteinBuilder) canEdit(p position) bool {
+	return p.edits < b.n && p.offset >= b.prefixLength
+}
+
+// Adds the positions reachable by deleting characters of the word.
+func (b *levenshteinBuilder) closure(positions []position) []position {
+	seen := make(map[position]bool)
+	var ans []position
+	for len(positions) > 0 {
+		p := positions[len(positions)-1]
+		positions = positions[:len(positions)-1]
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		ans = append(ans, p)
+		if !p.transposed && p.offset < len(b.word) && b.canEdit(p) {
+			positions = append(positions, position{p.offset + 1, p.edits + 1, false})
+		}
+	}
+	return ans
+}
+
+// Returns the positions after reading c; c == -1 stands for any
+// character not in the word.
+func (b *levenshteinBuilder) step(positions []position, c rune) []position {
+	var next []position
+	for _, p := range positions {
+		if p.transposed {
+			if b.word[p.offset] == c {
+				next = append(next, position{p.offset + 2, p.edits, false})
+			}
+			continue
+		}
+		if p.offset < len(b.word) && b.word[p.offset] == c {
+			next = append(next, position{p.offset + 1, p.edits, false})
+		}
+		if b.canEdit(p) {
+			// insertion
+			next = append(next, position{p.offset, p.edits + 1, false})
+			if p.offset < len(b.word) {
+				// substitution
+				next = append(next, position{p.offset + 1, p.edits + 1, false})
+			}
+			if b.transpositions && p.offset+1 < len(b.word) &&
+				b.word[p.offset+1] == c && b.word[p.offset] != c {
+				next = append(next, position{p.offset, p.edits + 1, true})
+			}
+		}
+	}
+	return b.closure(next)
+}
+
+func (b *levenshteinBuilder) addTransition(s *State, min, max rune, positions []position) {
+	if len(positions) == 0 {
+		return
+	}
+	s.addTransition(min, max, b.state(positions))
+}
@@ -0,0 +1,102 @@
+package automaton
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Optimal string alignment distance, i.e. Levenshtein distance with
+// transpositions of adjacent characters when transpositions is true.
+func editDistance(a, b []rune, transpositions bool) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if transpositions && i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func minInt(values ...int) int {
+	ans := values[0]
+	for _, v := range values[1:] {
+		if v < ans {
+			ans = v
+		}
+	}
+	return ans
+}
+
+func randomString(r *rand.Rand, alphabet []rune, maxLength int) string {
+	s := make([]rune, r.Intn(maxLength+1))
+	for i := range s {
+		s[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return string(s)
+}
+
+func TestLevenshteinAutomata(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	alphabet := []rune("abcé世")
+	for _, transpositions := range []bool{false, true} {
+		for i := 0; i < 50; i++ {
+			word := randomString(r, alphabet, 6)
+			prefixLength := r.Intn(3)
+			la := NewLevenshteinAutomata(word, prefixLength, transpositions)
+			for n := 0; n <= LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE; n++ {
+				a, err := la.ToAutomaton(n)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for j := 0; j < 200; j++ {
+					s := randomString(r, append(alphabet, 'z'), 8)
+					prefix := []rune(word)[:la.prefixLength]
+					if j%2 == 0 {
+						s = string(prefix) + s
+					}
+					expected := len(s) >= len(string(prefix)) && s[:len(string(prefix))] == string(prefix) &&
+						editDistance([]rune(word)[la.prefixLength:], []rune(s)[la.prefixLength:], transpositions) <= n
+					if actual := a.Run(s); actual != expected {
+						t.Fatalf("word=%q prefix=%v n=%v transpositions=%v: expected %v for %q, but was %v",
+							word, prefixLength, n, transpositions, expected, s, actual)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestLevenshteinAutomataMaxDistance(t *testing.T) {
+	la := NewLevenshteinAutomata("bat", 0, true)
+	if _, err := la.ToAutomaton(LEVENSHTEIN_MAXIMUM_SUPPORTED_DISTANCE + 1); err == nil {
+		t.Error("Should fail for unsupported distance")
+	}
+	a, err := la.ToAutomaton(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"bat", "bt", "abt", "bats", "cat", "at"} {
+		if !a.Run(s) {
+			t.Errorf("Should accept '%v'", s)
+		}
+	}
+	for _, s := range []string{"", "tab", "cats", "b"} {
+		if a.Run(s) {
+			t.Errorf("Should not accept '%v'", s)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package automaton
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// RegExp.java
+
+// Syntax flags of RegExp.
+const (
+	// Enables intersection (&).
+	REGEXP_INTERSECTION = 0x0001
+	// Enables complement (~).
+	REGEXP_COMPLEMENT = 0x0002
+	// Enables empty language (#).
+	REGEXP_EMPTY = 0x0004
+	// Enables anystring (@).
+	REGEXP_ANYSTRING = 0x0008
+	// Enables all optional regexp syntax.
+	REGEXP_ALL = 0xffff
+	// Enables no optional regexp syntax.
+	REGEXP_NONE = 0x0000
+)
+
+/*
+Regular Expression extension to Automaton.
+
+Regular expressions are built from the following abstract syntax:
+
+	regexp       ::= unionexp
+	unionexp     ::= interexp | unionexp          (union)
+	              |  interexp
+	interexp     ::= concatexp & interexp         (intersection)       [OPTIONAL]
+	              |  concatexp
+	concatexp    ::= repeatexp concatexp          (concatenation)
+	              |  repeatexp
+	repeatexp    ::= repeatexp ?                  (zero or one occurrence)
+	              |  repeatexp *                  (zero or more occurrences)
+	              |  repeatexp +                  (one or more occurrences)
+	              |  repeatexp {n}                (n occurrences)
+	              |  repeatexp {n,}               (n or more occurrences)
+	              |  repeatexp {n,m}              (n to m occurrences, including both)
+	              |  complexp
+	complexp     ::= ~ complexp                   (complement)         [OPTIONAL]
+	              |  charclassexp
+	charclassexp ::= [ charclasses ]              (character class)
+	              |  [^ charclasses ]             (negated character class)
+	              |  simpleexp
+	charclasses  ::= charclass charclasses
+	              |  charclass
+	charclass    ::= charexp - charexp            (character range, including end-points)
+	              |  charexp
+	simpleexp    ::= charexp
+	              |  .                            (any single character)
+	              |  #                            (the empty language) [OPTIONAL]
+	              |  @                            (any string)         [OPTIONAL]
+	              |  " <Unicode string without double-quotes> "  (a string)
+	              |  ( )                          (the empty string)
+	              |  ( unionexp )                 (precedence override)
+	charexp      ::= <Unicode character>          (a single non-reserved character)
+	              |  \ <Unicode character>        (a single character)
+
+The productions marked [OPTIONAL] are only allowed if specified by the
+syntax flags passed to the RegExp constructor. The reserved
+characters used in the (enabled) syntax must be escaped with
+backslash (\) or double-quotes ("..."). (In contrast to other regexp
+syntaxes, this is required also in character classes.) Be aware that
+dash (-) has a special meaning in charclass expressions. An
+identifier is a string not containing right angle bracket (>) or dash
+(-). Numerical intervals (<n-m>) and named automata (<identifier>) of
+the Java version are not supported.
+*/
+type RegExp struct {
+	original  string
+	flags     int
+	b         []rune
+	pos       int
+	automaton *Automaton
+}
+
+// Constructs a RegExp from a string, with all optional syntax
+// enabled. Returns error if a syntax error occurs.
+func NewRegExp(s string) (*RegExp, error) {
+	return NewRegExpWithFlags(s, REGEXP_ALL)
+}
+
+/*
+Constructs a RegExp from a string, with the optional syntax of the
+given flags enabled, e.g. REGEXP_INTERSECTION | REGEXP_EMPTY. Returns
+error if a syntax error occurs.
+*/
+func NewRegExpWithFlags(s string, flags int) (*RegExp, error) {
+	r := &RegExp{original: s, flags: flags, b: []rune(s)}
+	if len(r.b) == 0 {
+		r.automaton = MakeEmptyString()
+	} else {
+		a, err := r.parseUnionExp()
+		if err != nil {
+			return nil, err
+		}
+		if r.pos < len(r.b) {
+			return nil, r.errorf("end-of-string expected at position %v", r.pos)
+		}
+		r.automaton = a
+	}
+	r.b = nil
+	return r, nil
+}
+
+// Returns the original string of this regular expression.
+func (r *RegExp) String() string {
+	return r.original
+}
+
+// Returns the (deterministic) automaton accepting the language of
+// this regular expression, which must not be modified.
+func (r *RegExp) ToAutomaton() *Automaton {
+	return r.automaton
+}
+
+func (r *RegExp) errorf(format string, args ...interface{}) error {
+	return errors.New(fmt.Sprintf("invalid regexp %q: %v", r.original, fmt.Sprintf(format, args...)))
+}
+
+func (r *RegExp) check(flag int) bool {
+	return r.flags&flag != 0
+}
+
+func (r *RegExp) more() bool {
+	return r.pos < len(r.b)
+}
+
+func (r *RegExp) peek(s string) bool {
+	if !r.more() {
+		return false
+	}
+	for _, c := range s {
+		if r.b[r.pos] == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RegExp) match(c rune) bool {
+	if r.more() && r.b[r.pos] == c {
+		r.pos++
+		return true
+	}
+	return false
+}
+
+func (r *RegExp) next() (rune, error) {
+	if !r.more() {
+		return 0, r.errorf("unexpected end-of-string")
+	}
+	r.pos++
+	return r.b[r.pos-1], nil
+}
+
+func (r *RegExp) parseUnionExp() (*Automaton, error) {
+	e, err := r.parseInterExp()
+	if err != nil {
+		return nil, err
+	}
+	if r.match('|') {
+		e2, err := r.parseUnionExp()
+		if err != nil {
+			return nil, err
+		}
+		e = Union(e, e2)
+	}
+	return e, nil
+}
+
+func (r *RegExp) parseInterExp() (*Automaton, error) {
+	e, err := r.parseConcatExp()
+	if err != nil {
+		return nil, err
+	}
+	if r.check(REGEXP_INTERSECTION) && r.match('&') {
+		e2, err := r.parseInterExp()
+		if err != nil {
+			return nil, err
+		}
+		e = Intersection(e, e2)
+	}
+	return e, nil
+}
+
+func (r *RegExp) parseConcatExp() (*Automaton, error) {
+	e, err := r.parseRepeatExp()
+	if err != nil {
+		return nil, err
+	}
+	if r.more() && !r.peek(")|") && (!r.check(REGEXP_INTERSECTION) || !r.peek("&")) {
+		e2, err := r.parseConcatExp()
+		if err != nil {
+			return nil, err
+		}
+		e = Concatenate(e, e2)
+	}
+	return e, nil
+}
+
+func (r *RegExp) parseRepeatExp() (*Automaton, error) {
+	e, err := r.parseComplExp()
+	if err != nil {
+		return nil, err
+	}
+	for r.peek("?*+{") {
+		switch {
+		case r.match('?'):
+			e = Optional(e)
+		case r.match('*'):
+			e = Repeat(e)
+		case r.match('+'):
+			e = RepeatMin(e, 1)
+		case r.match('{'):
+			n, ok := r.parseInt()
+			if !ok {
+				return nil, r.errorf("integer expected at position %v", r.pos)
+			}
+			m := n
+			if r.match(',') {
+				if m, ok = r.parseInt(); !ok {
+					m = -1
+				}
+			}
+			if !r.match('}') {
+				return nil, r.errorf("expected '}' at position %v", r.pos)
+			}
+			if m == -1 {
+				e = RepeatMin(e, n)
+			} else {
+				e = RepeatMinMax(e, n, m)
+			}
+		}
+	}
+	return e, nil
+}
+
+// Parses the digits at the current position, if any.
+func (r *RegExp) parseInt() (int, bool) {
+	start := r.pos
+	for r.peek("0123456789") {
+		r.pos++
+	}
+	if start == r.pos {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(r.b[start:r.pos]))
+	return n, err == nil
+}
+
+func (r *RegExp) parseComplExp() (*Automaton, error) {
+	if r.check(REGEXP_COMPLEMENT) && r.match('~') {
+		e, err := r.parseComplExp()
+		if err != nil {
+			return nil, err
+		}
+		return Complement(e), nil
+	}
+	return r.parseCharClassExp()
+}
+
+func (r *RegExp) parseCharClassExp() (*Automaton, error) {
+	if !r.match('[') {
+		return r.parseSimpleExp()
+	}
+	negate := r.match('^')
+	e, err := r.parseCharClasses()
+	if err != nil {
+		return nil, err
+	}
+	if negate {
+		e = Minus(MakeAnyChar(), e)
+	}
+	if !r.match(']') {
+		return nil, r.errorf("expected ']' at position %v", r.pos)
+	}
+	return e, nil
+}
+
+func (r *RegExp) parseCharClasses() (*Automaton, error) {
+	var es []*Automaton
+	for {
+		e, err := r.parseCharClass()
+		if err != nil {
+			return nil, err
+		}
+		es = append(es, e)
+		if !r.more() || r.peek("]") {
+			return Union(es...), nil
+		}
+	}
+}
+
+func (r *RegExp) parseCharClass() (*Automaton, error) {
+	c, err := r.parseCharExp()
+	if err != nil {
+		return nil, err
+	}
+	if r.match('-') {
+		to, err := r.parseCharExp()
+		if err != nil {
+			return nil, err
+		}
+		return MakeCharRange(c, to), nil
+	}
+	return MakeChar(c), nil
+}
+
+func (r *RegExp) parseSimpleExp() (*Automaton, error) {
+	switch {
+	case r.match('.'):
+		return MakeAnyChar(), nil
+	case r.check(REGEXP_EMPTY) && r.match('#'):
+		return MakeEmpty(), nil
+	case r.check(REGEXP_ANYSTRING) && r.match('@'):
+		return MakeAnyString(), nil
+	case r.match('"'):
+		start := r.pos
+		for r.more() && !r.peek(`"`) {
+			r.pos++
+		}
+		if !r.match('"') {
+			return nil, r.errorf(`expected '"' at position %v`, r.pos)
+		}
+		return MakeString(string(r.b[start : r.pos-1])), nil
+	case r.match('('):
+		if r.match(')') {
+			return MakeEmptyString(), nil
+		}
+		e, err := r.parseUnionExp()
+		if err != nil {
+			return nil, err
+		}
+		if !r.match(')') {
+			return nil, r.errorf("expected ')' at position %v", r.pos)
+		}
+		return e, nil
+	}
+	c, err := r.parseCharExp()
+	if err != nil {
+		return nil, err
+	}
+	return MakeChar(c), nil
+}
+
+func (r *RegExp) parseCharExp() (rune, error) {
+	r.match('\\')
+	return r.next()
+}
@@ -0,0 +1,91 @@
+package automaton
+
+import (
+	"testing"
+)
+
+func TestRegExp(t *testing.T) {
+	for _, v := range []struct {
+		regexp   string
+		accepted []string
+		rejected []string
+	}{
+		{"abc", []string{"abc"}, []string{"", "ab", "abcd", "abd"}},
+		{"ab*c", []string{"ac", "abc", "abbbc"}, []string{"", "ab", "abcc", "bc"}},
+		{"a|bc|()", []string{"a", "bc", ""}, []string{"b", "abc"}},
+		{"[a-c]+d?", []string{"a", "cab", "bd"}, []string{"", "d", "ad d", "ed"}},
+		{"[^a]b", []string{"bb", "中b"}, []string{"ab", "b", "bbb"}},
+		{"(ab){2,3}", []string{"abab", "ababab"}, []string{"ab", "abababab", ""}},
+		{"a{2,}", []string{"aa", "aaaaa"}, []string{"", "a"}},
+		{"a{0}", []string{""}, []string{"a"}},
+		{"a.c", []string{"abc", "a.c", "a中c"}, []string{"ac", "abbc"}},
+		{`"a.b"\*`, []string{"a.b*"}, []string{"axb*", "a.b"}},
+		{"()", []string{""}, []string{"a"}},
+		{"@x", []string{"x", "abcx", "xx"}, []string{"", "xa"}},
+		{"#|a", []string{"a"}, []string{""}},
+		{"[ab]{0,3}&~(a*)", []string{"b", "ab", "aab"}, []string{"", "a", "aaa", "abab"}},
+	} {
+		r, err := NewRegExp(v.regexp)
+		if err != nil {
+			t.Errorf("%v: %v", v.regexp, err)
+			continue
+		}
+		a := r.ToAutomaton()
+		for _, s := range v.accepted {
+			if !a.Run(s) {
+				t.Errorf("Expected %v to accept %q", v.regexp, s)
+			}
+		}
+		for _, s := range v.rejected {
+			if a.Run(s) {
+				t.Errorf("Expected %v to reject %q", v.regexp, s)
+			}
+		}
+	}
+}
+
+func TestRegExpFlags(t *testing.T) {
+	r, err := NewRegExpWithFlags("a&b~c#@", REGEXP_NONE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.ToAutomaton().Run("a&b~c#@") {
+		t.Error("Expected the optional syntax to be taken literally")
+	}
+}
+
+func TestRegExpSyntaxError(t *testing.T) {
+	for _, s := range []string{"(a", "a)", "[ab", "a{", "a{2", `"ab`, `a\`, "a|(", "[]"} {
+		if _, err := NewRegExp(s); err == nil {
+			t.Errorf("Expected syntax error for %q", s)
+		}
+	}
+}
+
+func TestCompiledAutomaton(t *testing.T) {
+	for _, v := range []struct {
+		regexp string
+		typ    AutomatonType
+		term   string
+	}{
+		{"#", AUTOMATON_TYPE_NONE, ""},
+		{"a&b", AUTOMATON_TYPE_NONE, ""},
+		{".*", AUTOMATON_TYPE_ALL, ""},
+		{"@", AUTOMATON_TYPE_ALL, ""},
+		{"abc", AUTOMATON_TYPE_SINGLE, "abc"},
+		{"()", AUTOMATON_TYPE_SINGLE, ""},
+		{"ab.*", AUTOMATON_TYPE_PREFIX, "ab"},
+		{"ab(c|@)", AUTOMATON_TYPE_PREFIX, "ab"},
+		{"ab.*c", AUTOMATON_TYPE_NORMAL, ""},
+		{"ab|ac", AUTOMATON_TYPE_NORMAL, ""},
+	} {
+		r, err := NewRegExp(v.regexp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := NewCompiledAutomaton(r.ToAutomaton())
+		if c.Type != v.typ || string(c.Term) != v.term {
+			t.Errorf("%v: expected type %v with term %q, but %v with %q", v.regexp, v.typ, v.term, c.Type, c.Term)
+		}
+	}
+}
@@ -1,4 +1,51 @@
 package util
 
+/*
+Interface for Bitset-like structures.
+*/
 type Bits interface {
+	/*
+		Returns the value of the bit with the specified index.
+		index should be non-negative and < Length(). The result of
+		passing negative or out of bounds values is undefined by this
+		interface, just don't do it!
+	*/
+	Get(index int) bool
+	// Returns the number of bits in this set
+	Length() int
+}
+
+// util/MutableBits.java
+
+// Extension of Bits for live documents.
+type MutableBits interface {
+	Bits
+	// Sets the bit specified by index to false.
+	Clear(index int)
+}
+
+// util/Bits.java/MatchAllBits
+
+// Bits impl of the specified length with all bits set.
+type MatchAllBits int
+
+func (b MatchAllBits) Get(index int) bool {
+	return true
+}
+
+func (b MatchAllBits) Length() int {
+	return int(b)
+}
+
+// util/Bits.java/MatchNoBits
+
+// Bits impl of the specified length with no bits set.
+type MatchNoBits int
+
+func (b MatchNoBits) Get(index int) bool {
+	return false
+}
+
+func (b MatchNoBits) Length() int {
+	return int(b)
 }
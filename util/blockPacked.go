@@ -0,0 +1,434 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// AbstractBlockPackedWriter.java
+
+const (
+	BLOCK_PACKED_MIN_BLOCK_SIZE     = 64
+	BLOCK_PACKED_MAX_BLOCK_SIZE     = 1 << (30 - 3)
+	BLOCK_PACKED_MIN_VALUE_EQUALS_0 = 1 << 0
+	BLOCK_PACKED_BPV_SHIFT          = 1
+)
+
+func checkBlockSize(blockSize int) {
+	if blockSize < BLOCK_PACKED_MIN_BLOCK_SIZE || blockSize > BLOCK_PACKED_MAX_BLOCK_SIZE {
+		panic(fmt.Sprintf("blockSize must be >= %v and <= %v, got %v",
+			BLOCK_PACKED_MIN_BLOCK_SIZE, BLOCK_PACKED_MAX_BLOCK_SIZE, blockSize))
+	}
+	if blockSize&(blockSize-1) != 0 {
+		panic(fmt.Sprintf("blockSize must be a power of two, got %v", blockSize))
+	}
+}
+
+func zigZagDecode(n int64) int64 {
+	return int64(uint64(n)>>1) ^ -(n & 1)
+}
+
+// BlockPackedReaderIterator.java
+
+// same as DataInput.ReadVLong but supports negative values
+func readBlockPackedVLong(in DataInput) (n int64, err error) {
+	var b byte
+	for shift := uint(0); shift < 56; shift += 7 {
+		if b, err = in.ReadByte(); err != nil {
+			return 0, err
+		}
+		n |= int64(b&0x7F) << shift
+		if b&0x80 == 0 {
+			return n, nil
+		}
+	}
+	if b, err = in.ReadByte(); err != nil {
+		return 0, err
+	}
+	return n | int64(b)<<56, nil
+}
+
+/*
+Reader for sequences of longs written with BlockPackedWriter.
+*/
+type BlockPackedReaderIterator struct {
+	in                DataInput
+	packedIntsVersion int32
+	valueCount        int64
+	blockSize         int
+	values            []int64
+	blocks            []byte
+	off               int
+	ord               int64
+}
+
+/*
+Creates a reader of valueCount values written with blockSize values
+per block.
+*/
+func NewBlockPackedReaderIterator(in DataInput, packedIntsVersion int32, blockSize int,
+	valueCount int64) *BlockPackedReaderIterator {
+	checkBlockSize(blockSize)
+	ans := &BlockPackedReaderIterator{
+		packedIntsVersion: packedIntsVersion,
+		blockSize:         blockSize,
+		values:            make([]int64, blockSize),
+	}
+	ans.Reset(in, valueCount)
+	return ans
+}
+
+/*
+Reset the current reader to wrap a stream of valueCount values
+contained in in. The block size remains unchanged.
+*/
+func (it *BlockPackedReaderIterator) Reset(in DataInput, valueCount int64) {
+	it.in = in
+	if valueCount < 0 {
+		panic("assert fail")
+	}
+	it.valueCount = valueCount
+	it.off = it.blockSize
+	it.ord = 0
+}
+
+// Skip exactly count values.
+func (it *BlockPackedReaderIterator) Skip(count int64) error {
+	if count < 0 {
+		panic("assert fail")
+	}
+	if it.ord+count > it.valueCount || it.ord+count < 0 {
+		return io.EOF
+	}
+
+	// 1. skip buffered values
+	skipBuffer := int64(it.blockSize - it.off)
+	if count < skipBuffer {
+		skipBuffer = count
+	}
+	it.off += int(skipBuffer)
+	it.ord += skipBuffer
+	count -= skipBuffer
+	if count == 0 {
+		return nil
+	}
+
+	// 2. skip as many blocks as necessary
+	if it.off != it.blockSize {
+		panic("assert fail")
+	}
+	for count >= int64(it.blockSize) {
+		token, err := it.in.ReadByte()
+		if err != nil {
+			return err
+		}
+		bitsPerValue := uint32(token >> BLOCK_PACKED_BPV_SHIFT)
+		if bitsPerValue > 64 {
+			return errors.New("Corrupted")
+		}
+		if token&BLOCK_PACKED_MIN_VALUE_EQUALS_0 == 0 {
+			if _, err = readBlockPackedVLong(it.in); err != nil {
+				return err
+			}
+		}
+		blockBytes := PackedFormat(PACKED).ByteCount(it.packedIntsVersion, int32(it.blockSize), bitsPerValue)
+		if err = it.skipBytes(blockBytes); err != nil {
+			return err
+		}
+		it.ord += int64(it.blockSize)
+		count -= int64(it.blockSize)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	// 3. skip last values
+	if count >= int64(it.blockSize) {
+		panic("assert fail")
+	}
+	if err := it.refill(); err != nil {
+		return err
+	}
+	it.ord += count
+	it.off += int(count)
+	return nil
+}
+
+func (it *BlockPackedReaderIterator) skipBytes(count int64) error {
+	if cap(it.blocks) == 0 {
+		it.blocks = make([]byte, it.blockSize)
+	}
+	for count > 0 {
+		n := int64(cap(it.blocks))
+		if count < n {
+			n = count
+		}
+		if err := it.in.ReadBytes(it.blocks[:n]); err != nil {
+			return err
+		}
+		count -= n
+	}
+	return nil
+}
+
+// Read the next value.
+func (it *BlockPackedReaderIterator) Next() (n int64, err error) {
+	if it.ord == it.valueCount {
+		return 0, io.EOF
+	}
+	if it.off == it.blockSize {
+		if err = it.refill(); err != nil {
+			return 0, err
+		}
+	}
+	n = it.values[it.off]
+	it.off++
+	it.ord++
+	return n, nil
+}
+
+/*
+Read between 1 and count values. The returned slice is only valid
+until the next call.
+*/
+func (it *BlockPackedReaderIterator) NextN(count int) (values []int64, err error) {
+	if count <= 0 {
+		panic("assert fail")
+	}
+	if it.ord == it.valueCount {
+		return nil, io.EOF
+	}
+	if it.off == it.blockSize {
+		if err = it.refill(); err != nil {
+			return nil, err
+		}
+	}
+
+	if n := it.blockSize - it.off; count > n {
+		count = n
+	}
+	if n := it.valueCount - it.ord; int64(count) > n {
+		count = int(n)
+	}
+	values = it.values[it.off : it.off+count]
+	it.off += count
+	it.ord += int64(count)
+	return values, nil
+}
+
+func (it *BlockPackedReaderIterator) refill() error {
+	token, err := it.in.ReadByte()
+	if err != nil {
+		return err
+	}
+	minEquals0 := (token & BLOCK_PACKED_MIN_VALUE_EQUALS_0) != 0
+	bitsPerValue := uint32(token >> BLOCK_PACKED_BPV_SHIFT)
+	if bitsPerValue > 64 {
+		return errors.New("Corrupted")
+	}
+	var minValue int64
+	if !minEquals0 {
+		n, err := readBlockPackedVLong(it.in)
+		if err != nil {
+			return err
+		}
+		minValue = zigZagDecode(1 + n)
+	}
+	if !minEquals0 && minValue == 0 {
+		panic("assert fail")
+	}
+
+	if bitsPerValue == 0 {
+		for i := range it.values {
+			it.values[i] = minValue
+		}
+	} else {
+		decoder := GetPackedIntsDecoder(PACKED, it.packedIntsVersion, bitsPerValue)
+		iterations := it.blockSize / decoder.ByteValueCount()
+		blocksSize := iterations * decoder.ByteBlockCount()
+		if len(it.blocks) < blocksSize {
+			it.blocks = make([]byte, blocksSize)
+		}
+
+		valueCount := it.valueCount - it.ord
+		if valueCount > int64(it.blockSize) {
+			valueCount = int64(it.blockSize)
+		}
+		blocksCount := PackedFormat(PACKED).ByteCount(it.packedIntsVersion, int32(valueCount), bitsPerValue)
+		if err = it.in.ReadBytes(it.blocks[:blocksCount]); err != nil {
+			return err
+		}
+
+		decoder.DecodeByteToLong(it.blocks, it.values, iterations)
+
+		if minValue != 0 {
+			for i := int64(0); i < valueCount; i++ {
+				it.values[i] += minValue
+			}
+		}
+	}
+	it.off = 0
+	return nil
+}
+
+// Return the offset of the next value to read.
+func (it *BlockPackedReaderIterator) Ord() int64 {
+	return it.ord
+}
+
+// BlockPackedReader.java
+
+/*
+Provides random access to a stream written with BlockPackedWriter.
+*/
+type BlockPackedReader struct {
+	blockShift, blockMask uint
+	valueCount            int64
+	minValues             []int64
+	subReaders            []PackedIntsReader
+}
+
+// Sole constructor.
+func NewBlockPackedReader(in DataInput, packedIntsVersion int32, blockSize int,
+	valueCount int64) (r *BlockPackedReader, err error) {
+	checkBlockSize(blockSize)
+	r = &BlockPackedReader{
+		valueCount: valueCount,
+		blockShift: uint(BitsRequired(int64(blockSize)) - 1),
+		blockMask:  uint(blockSize - 1),
+	}
+	numBlocks := numBlocks(valueCount, blockSize)
+	r.subReaders = make([]PackedIntsReader, numBlocks)
+	for i := range r.subReaders {
+		token, err := in.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		bitsPerValue := uint32(token >> BLOCK_PACKED_BPV_SHIFT)
+		if bitsPerValue > 64 {
+			return nil, errors.New("Corrupted")
+		}
+		if token&BLOCK_PACKED_MIN_VALUE_EQUALS_0 == 0 {
+			if r.minValues == nil {
+				r.minValues = make([]int64, numBlocks)
+			}
+			n, err := readBlockPackedVLong(in)
+			if err != nil {
+				return nil, err
+			}
+			r.minValues[i] = zigZagDecode(1 + n)
+		}
+		if bitsPerValue == 0 {
+			r.subReaders[i] = newNullReader(int32(blockSize))
+		} else {
+			size := valueCount - int64(i)*int64(blockSize)
+			if size > int64(blockSize) {
+				size = int64(blockSize)
+			}
+			r.subReaders[i], err = NewPackedReaderNoHeader(in, PACKED, packedIntsVersion, int32(size), bitsPerValue)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+func numBlocks(size int64, blockSize int) int {
+	n := int(size / int64(blockSize))
+	if size%int64(blockSize) != 0 {
+		n++
+	}
+	if int64(n)*int64(blockSize) < size {
+		panic("size is too large for this block size")
+	}
+	return n
+}
+
+func (r *BlockPackedReader) Get(index int64) int64 {
+	if index < 0 || index >= r.valueCount {
+		panic("assert fail")
+	}
+	block := int(uint64(index) >> r.blockShift)
+	idx := int32(uint64(index) & uint64(r.blockMask))
+	var min int64
+	if r.minValues != nil {
+		min = r.minValues[block]
+	}
+	return min + r.subReaders[block].Get(idx)
+}
+
+// MonotonicBlockPackedReader.java
+
+/*
+Provides random access to a stream written with
+MonotonicBlockPackedWriter.
+*/
+type MonotonicBlockPackedReader struct {
+	blockShift, blockMask uint
+	valueCount            int64
+	minValues             []int64
+	averages              []float32
+	subReaders            []PackedIntsReader
+}
+
+// Sole constructor.
+func NewMonotonicBlockPackedReader(in DataInput, packedIntsVersion int32, blockSize int,
+	valueCount int64) (r *MonotonicBlockPackedReader, err error) {
+	checkBlockSize(blockSize)
+	numBlocks := numBlocks(valueCount, blockSize)
+	r = &MonotonicBlockPackedReader{
+		valueCount: valueCount,
+		blockShift: uint(BitsRequired(int64(blockSize)) - 1),
+		blockMask:  uint(blockSize - 1),
+		minValues:  make([]int64, numBlocks),
+		averages:   make([]float32, numBlocks),
+		subReaders: make([]PackedIntsReader, numBlocks),
+	}
+	for i := 0; i < numBlocks; i++ {
+		if r.minValues[i], err = in.ReadVLong(); err != nil {
+			return nil, err
+		}
+		n, err := in.ReadInt()
+		if err != nil {
+			return nil, err
+		}
+		r.averages[i] = math.Float32frombits(uint32(n))
+		bitsPerValue, err := asUint32(in.ReadVInt())
+		if err != nil {
+			return nil, err
+		}
+		if bitsPerValue > 64 {
+			return nil, errors.New("Corrupted")
+		}
+		if bitsPerValue == 0 {
+			r.subReaders[i] = newNullReader(int32(blockSize))
+		} else {
+			size := valueCount - int64(i)*int64(blockSize)
+			if size > int64(blockSize) {
+				size = int64(blockSize)
+			}
+			r.subReaders[i], err = NewPackedReaderNoHeader(in, PACKED, packedIntsVersion, int32(size), bitsPerValue)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+func (r *MonotonicBlockPackedReader) Get(index int64) int64 {
+	if index < 0 || index >= r.valueCount {
+		panic("assert fail")
+	}
+	block := int(uint64(index) >> r.blockShift)
+	idx := int32(uint64(index) & uint64(r.blockMask))
+	return r.minValues[block] + int64(float32(idx)*r.averages[block]) + zigZagDecode(r.subReaders[block].Get(idx))
+}
+
+// Returns the number of values.
+func (r *MonotonicBlockPackedReader) Size() int64 {
+	return r.valueCount
+}
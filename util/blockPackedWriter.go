@@ -0,0 +1,258 @@
+package util
+
+import (
+	"math"
+)
+
+func zigZagEncode(n int64) int64 {
+	return (n >> 63) ^ (n << 1)
+}
+
+// same as DataOutput.WriteVLong but accepts negative values
+func writeBlockPackedVLong(out DataOutput, i int64) error {
+	for k := 0; (i & ^0x7F) != 0 && k < 8; k++ {
+		if err := out.WriteByte(byte((i & 0x7F) | 0x80)); err != nil {
+			return err
+		}
+		i = int64(uint64(i) >> 7)
+	}
+	return out.WriteByte(byte(i))
+}
+
+// AbstractBlockPackedWriter.java
+
+type abstractBlockPackedWriter struct {
+	out      DataOutput
+	values   []int64
+	off      int
+	ord      int64
+	finished bool
+	flush    func() error // encodes the buffered values
+}
+
+func newAbstractBlockPackedWriter(out DataOutput, blockSize int) *abstractBlockPackedWriter {
+	checkBlockSize(blockSize)
+	return &abstractBlockPackedWriter{out: out, values: make([]int64, blockSize)}
+}
+
+// Reset this writer to wrap out. The block size remains unchanged.
+func (w *abstractBlockPackedWriter) Reset(out DataOutput) {
+	if out == nil {
+		panic("assert fail")
+	}
+	w.out = out
+	w.off = 0
+	w.ord = 0
+	w.finished = false
+}
+
+func (w *abstractBlockPackedWriter) checkNotFinished() {
+	if w.finished {
+		panic("Already finished")
+	}
+}
+
+// Append a new long.
+func (w *abstractBlockPackedWriter) Add(l int64) error {
+	w.checkNotFinished()
+	if w.off == len(w.values) {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	w.values[w.off] = l
+	w.off++
+	w.ord++
+	return nil
+}
+
+/*
+Flush all buffered data to disk. This instance is not usable anymore
+after this method has been called until Reset() has been called.
+*/
+func (w *abstractBlockPackedWriter) Finish() error {
+	w.checkNotFinished()
+	if w.off > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	w.finished = true
+	return nil
+}
+
+// Return the number of values which have been added.
+func (w *abstractBlockPackedWriter) Ord() int64 {
+	return w.ord
+}
+
+func (w *abstractBlockPackedWriter) writeValues(bitsRequired uint32) error {
+	writer := NewPackedWriterNoHeader(w.out, PACKED, int32(w.off), bitsRequired)
+	for _, v := range w.values[:w.off] {
+		if err := writer.Add(v); err != nil {
+			return err
+		}
+	}
+	return writer.Finish()
+}
+
+// BlockPackedWriter.java
+
+/*
+A writer for large sequences of longs.
+
+The sequence is divided into fixed-size blocks and for each block,
+the difference between each value and the minimum value of the block
+is encoded using as few bits as possible. Memory usage of this class
+is proportional to the block size. Each block has an overhead between
+1 and 10 bytes to store the minimum value and the number of bits per
+value of the block.
+*/
+type BlockPackedWriter struct {
+	*abstractBlockPackedWriter
+}
+
+func NewBlockPackedWriter(out DataOutput, blockSize int) *BlockPackedWriter {
+	w := &BlockPackedWriter{newAbstractBlockPackedWriter(out, blockSize)}
+	w.abstractBlockPackedWriter.flush = w.flush
+	return w
+}
+
+func (w *BlockPackedWriter) flush() error {
+	if w.off <= 0 {
+		panic("assert fail")
+	}
+	min, max := int64(math.MaxInt64), int64(math.MinInt64)
+	for _, v := range w.values[:w.off] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	delta := max - min
+	var bitsRequired uint32
+	switch {
+	case delta < 0:
+		bitsRequired = 64
+	case delta == 0:
+		bitsRequired = 0
+	default:
+		bitsRequired = BitsRequired(delta)
+	}
+	if bitsRequired == 64 {
+		// no need to delta-encode
+		min = 0
+	} else if min > 0 {
+		// make min as small as possible so that writeVLong requires
+		// fewer bytes
+		if m := max - int64(uint64(1)<<bitsRequired-1); m > 0 {
+			min = m
+		} else {
+			min = 0
+		}
+	}
+
+	token := byte(bitsRequired << BLOCK_PACKED_BPV_SHIFT)
+	if min == 0 {
+		token |= BLOCK_PACKED_MIN_VALUE_EQUALS_0
+	}
+	if err := w.out.WriteByte(token); err != nil {
+		return err
+	}
+
+	if min != 0 {
+		if err := writeBlockPackedVLong(w.out, zigZagEncode(min)-1); err != nil {
+			return err
+		}
+	}
+
+	if bitsRequired > 0 {
+		if min != 0 {
+			for i := range w.values[:w.off] {
+				w.values[i] -= min
+			}
+		}
+		if err := w.writeValues(bitsRequired); err != nil {
+			return err
+		}
+	}
+
+	w.off = 0
+	return nil
+}
+
+// MonotonicBlockPackedWriter.java
+
+/*
+A writer for large monotonically increasing sequences of positive
+longs.
+
+The sequence is divided into fixed-size blocks and for each block,
+values are modeled after a linear function f: x -> A * x + B. The
+block encodes deltas from the expected values computed from this
+function using as few bits as possible. Each block has an overhead
+between 6 and 14 bytes.
+*/
+type MonotonicBlockPackedWriter struct {
+	*abstractBlockPackedWriter
+}
+
+func NewMonotonicBlockPackedWriter(out DataOutput, blockSize int) *MonotonicBlockPackedWriter {
+	w := &MonotonicBlockPackedWriter{newAbstractBlockPackedWriter(out, blockSize)}
+	w.abstractBlockPackedWriter.flush = w.flush
+	return w
+}
+
+func (w *MonotonicBlockPackedWriter) Add(l int64) error {
+	if l < 0 {
+		panic("assert fail")
+	}
+	return w.abstractBlockPackedWriter.Add(l)
+}
+
+func (w *MonotonicBlockPackedWriter) flush() error {
+	if w.off <= 0 {
+		panic("assert fail")
+	}
+
+	// TODO: perform a true linear regression?
+	min := w.values[0]
+	var avg float32
+	if w.off > 1 {
+		avg = float32(w.values[w.off-1]-min) / float32(w.off-1)
+	}
+
+	var maxZigZagDelta int64
+	for i := range w.values[:w.off] {
+		w.values[i] = zigZagEncode(w.values[i] - min - int64(avg*float32(i)))
+		if w.values[i] > maxZigZagDelta {
+			maxZigZagDelta = w.values[i]
+		}
+	}
+
+	if err := w.out.WriteVLong(min); err != nil {
+		return err
+	}
+	if err := w.out.WriteInt(int32(math.Float32bits(avg))); err != nil {
+		return err
+	}
+	if maxZigZagDelta == 0 {
+		if err := w.out.WriteVInt(0); err != nil {
+			return err
+		}
+	} else {
+		bitsRequired := BitsRequired(maxZigZagDelta)
+		if err := w.out.WriteVInt(int32(bitsRequired)); err != nil {
+			return err
+		}
+		if err := w.writeValues(bitsRequired); err != nil {
+			return err
+		}
+	}
+
+	w.off = 0
+	return nil
+}
@@ -0,0 +1,271 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+type testDataInput struct {
+	*bytes.Reader
+	DataInput
+}
+
+func (in *testDataInput) ReadByte() (byte, error) {
+	return in.Reader.ReadByte()
+}
+
+func (in *testDataInput) ReadBytes(buf []byte) error {
+	if n, _ := in.Read(buf); n != len(buf) {
+		return errors.New("EOF")
+	}
+	return nil
+}
+
+// Encodes values as BlockPackedWriter does.
+func writeBlockPacked(values []int64, blockSize int) []byte {
+	var buf []byte
+	for len(values) > 0 {
+		block := values
+		if len(block) > blockSize {
+			block = block[:blockSize]
+		}
+		values = values[len(block):]
+
+		min, max := block[0], block[0]
+		for _, v := range block {
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		bpv := uint32(0)
+		if max != min {
+			bpv = BitsRequired(max - min)
+		}
+		token := byte(bpv << BLOCK_PACKED_BPV_SHIFT)
+		if min == 0 {
+			token |= BLOCK_PACKED_MIN_VALUE_EQUALS_0
+		}
+		buf = append(buf, token)
+		if min != 0 {
+			// zig-zag encoded, minus one since it can't be 0
+			n := uint64((min<<1)^(min>>63)) - 1
+			for n >= 0x80 {
+				buf = append(buf, byte(n&0x7F|0x80))
+				n >>= 7
+			}
+			buf = append(buf, byte(n))
+		}
+		if bpv > 0 {
+			packed := make([]byte, PackedFormat(PACKED).ByteCount(PACKED_VERSION_CURRENT, int32(len(block)), bpv))
+			for i, v := range block {
+				v -= min
+				for b := uint32(0); b < bpv; b++ {
+					if v&(int64(1)<<(bpv-1-b)) != 0 {
+						bit := uint32(i)*bpv + b
+						packed[bit/8] |= 1 << (7 - bit%8)
+					}
+				}
+			}
+			buf = append(buf, packed...)
+		}
+	}
+	return buf
+}
+
+func TestBlockPackedReaderIterator(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	const blockSize = 64
+	for _, valueCount := range []int{1, 63, 64, 65, 500} {
+		values := make([]int64, valueCount)
+		for i := range values {
+			switch i / blockSize % 4 {
+			case 0:
+				values[i] = r.Int63n(1000)
+			case 1:
+				values[i] = 42 // all equal
+			case 2:
+				values[i] = r.Int63n(1<<40) - 1<<39 // negative min
+			default:
+				values[i] = r.Int63n(3)
+			}
+		}
+		data := writeBlockPacked(values, blockSize)
+
+		it := NewBlockPackedReaderIterator(&testDataInput{Reader: bytes.NewReader(data)},
+			PACKED_VERSION_CURRENT, blockSize, int64(valueCount))
+		for i, v := range values {
+			n, err := it.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != v {
+				t.Fatalf("Expected %v at %v, but was %v", v, i, n)
+			}
+		}
+		if _, err := it.Next(); err != io.EOF {
+			t.Errorf("Should be exhausted, but was %v", err)
+		}
+
+		// skips and bulk reads
+		for skip := 0; skip < valueCount; skip += 1 + r.Intn(150) {
+			it.Reset(&testDataInput{Reader: bytes.NewReader(data)}, int64(valueCount))
+			if err := it.Skip(int64(skip)); err != nil {
+				t.Fatal(err)
+			}
+			for i := skip; i < valueCount; {
+				next, err := it.NextN(1 + r.Intn(100))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, n := range next {
+					if n != values[i] {
+						t.Fatalf("Expected %v at %v after skipping %v, but was %v", values[i], i, skip, n)
+					}
+					i++
+				}
+				if it.Ord() != int64(i) {
+					t.Fatalf("Expected ord %v, but was %v", i, it.Ord())
+				}
+			}
+		}
+	}
+}
+
+func TestBlockPackedReader(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	const blockSize = 64
+	for _, valueCount := range []int{1, 64, 130} {
+		values := make([]int64, valueCount)
+		for i := range values {
+			if i/blockSize%2 == 0 {
+				values[i] = r.Int63n(1<<20) - 1<<19
+			} else {
+				values[i] = 7 // all equal
+			}
+		}
+		data := writeBlockPacked(values, blockSize)
+
+		reader, err := NewBlockPackedReader(&DataInputImpl{&testDataInput{Reader: bytes.NewReader(data)}},
+			PACKED_VERSION_CURRENT, blockSize, int64(valueCount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, i := range r.Perm(valueCount) {
+			if n := reader.Get(int64(i)); n != values[i] {
+				t.Fatalf("Expected %v at %v, but was %v", values[i], i, n)
+			}
+		}
+	}
+}
+
+func TestBlockPackedWriter(t *testing.T) {
+	r := rand.New(rand.NewSource(13))
+	const blockSize = 64
+	for _, valueCount := range []int{1, 64, 65, 300} {
+		values := make([]int64, valueCount)
+		for i := range values {
+			switch i / blockSize % 5 {
+			case 0:
+				values[i] = r.Int63n(1000)
+			case 1:
+				values[i] = 42 // all equal
+			case 2:
+				values[i] = r.Int63n(1<<40) - 1<<39 // negative min
+			case 3:
+				values[i] = 1<<50 + r.Int63n(5) // large min
+			default:
+				values[i] = r.Int63() - r.Int63() // needs 64 bits
+			}
+		}
+
+		w := new(testDataWriter)
+		writer := NewBlockPackedWriter(NewDataOutput(w), blockSize)
+		for _, v := range values {
+			if err := writer.Add(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := writer.Finish(); err != nil {
+			t.Fatal(err)
+		}
+		if writer.Ord() != int64(valueCount) {
+			t.Errorf("Expected ord %v, but was %v", valueCount, writer.Ord())
+		}
+
+		it := NewBlockPackedReaderIterator(&testDataInput{Reader: bytes.NewReader(w.Bytes())},
+			PACKED_VERSION_CURRENT, blockSize, int64(valueCount))
+		for i, v := range values {
+			n, err := it.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != v {
+				t.Fatalf("Expected %v at %v, but was %v", v, i, n)
+			}
+		}
+		if _, err := it.Next(); err != io.EOF {
+			t.Errorf("Should be exhausted, but was %v", err)
+		}
+	}
+
+	// the writer is reusable once reset
+	w := new(testDataWriter)
+	writer := NewBlockPackedWriter(NewDataOutput(w), blockSize)
+	writer.Add(5)
+	writer.Finish()
+	writer.Reset(NewDataOutput(w))
+	writer.Add(0)
+	if err := writer.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	// 1 equal value with min 5 (zig-zag 10, minus 1), then min 0
+	if !bytes.Equal(w.Bytes(), []byte{0, 9, BLOCK_PACKED_MIN_VALUE_EQUALS_0}) {
+		t.Errorf("Unexpected bytes %v", w.Bytes())
+	}
+}
+
+func TestMonotonicBlockPackedWriter(t *testing.T) {
+	r := rand.New(rand.NewSource(17))
+	const blockSize = 64
+	for _, valueCount := range []int{1, 64, 65, 300} {
+		values := make([]int64, valueCount)
+		for i := 1; i < valueCount; i++ {
+			switch i / blockSize % 3 {
+			case 0:
+				values[i] = values[i-1] + r.Int63n(100)
+			case 1:
+				values[i] = values[i-1] // constant
+			default:
+				values[i] = values[i-1] + 7 // linear
+			}
+		}
+
+		w := new(testDataWriter)
+		writer := NewMonotonicBlockPackedWriter(NewDataOutput(w), blockSize)
+		for _, v := range values {
+			if err := writer.Add(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := writer.Finish(); err != nil {
+			t.Fatal(err)
+		}
+
+		reader, err := NewMonotonicBlockPackedReader(&DataInputImpl{&testDataInput{Reader: bytes.NewReader(w.Bytes())}},
+			PACKED_VERSION_CURRENT, blockSize, int64(valueCount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range values {
+			if n := reader.Get(int64(i)); n != v {
+				t.Fatalf("Expected %v at %v, but was %v", v, i, n)
+			}
+		}
+	}
+}
@@ -53,6 +53,75 @@ func (p *BulkOperationPacked) ByteValueCount() int {
 	return p.byteValueCount
 }
 
+func (p *BulkOperationPacked) ByteBlockCount() int {
+	return p.byteBlockCount
+}
+
+func (p *BulkOperationPacked) DecodeByteToInt(blocks []byte, values []int32, iterations int) {
+	if p.bitsPerValue > 32 {
+		panic(fmt.Sprintf("Cannot decode %v-bits values into an []int32", p.bitsPerValue))
+	}
+	bitsPerValue := int(p.bitsPerValue)
+	nextValue := 0
+	bitsLeft := bitsPerValue
+	valuesOffset := 0
+	for _, b := range blocks[:iterations*p.byteBlockCount] {
+		bytes := int(b)
+		if bitsLeft > 8 {
+			// just buffer
+			bitsLeft -= 8
+			nextValue |= bytes << uint(bitsLeft)
+		} else {
+			// flush
+			bits := 8 - bitsLeft
+			values[valuesOffset] = int32(nextValue | (bytes >> uint(bits)))
+			valuesOffset++
+			for bits >= bitsPerValue {
+				bits -= bitsPerValue
+				values[valuesOffset] = int32((bytes >> uint(bits)) & p.intMask)
+				valuesOffset++
+			}
+			// then buffer
+			bitsLeft = bitsPerValue - bits
+			nextValue = (bytes & ((1 << uint(bits)) - 1)) << uint(bitsLeft)
+		}
+	}
+	if bitsLeft != bitsPerValue {
+		panic("assert fail")
+	}
+}
+
+func (p *BulkOperationPacked) DecodeByteToLong(blocks []byte, values []int64, iterations int) {
+	bitsPerValue := uint(p.bitsPerValue)
+	var nextValue int64
+	bitsLeft := bitsPerValue
+	valuesOffset := 0
+	for _, b := range blocks[:iterations*p.byteBlockCount] {
+		bytes := int64(b)
+		if bitsLeft > 8 {
+			// just buffer
+			bitsLeft -= 8
+			nextValue |= bytes << bitsLeft
+		} else {
+			// flush
+			bits := 8 - bitsLeft
+			values[valuesOffset] = nextValue | (bytes >> bits)
+			valuesOffset++
+			for bits >= bitsPerValue {
+				bits -= bitsPerValue
+				values[valuesOffset] = (bytes >> bits) & p.mask
+				valuesOffset++
+			}
+			// then buffer
+			bitsLeft = bitsPerValue - bits
+			nextValue = (bytes & ((1 << bits) - 1)) << bitsLeft
+		}
+	}
+	if bitsLeft != bitsPerValue {
+		panic("assert fail")
+	}
+}
+
 func newBulkOperationPacked1() *BulkOperation {
 	log.Print("Initializng BulkOperationPacked1...")
 	ans := newBulkOperationPacked(1)
@@ -75,7 +144,7 @@ func newBulkOperationPacked4() *BulkOperation {
 }
 
 func newBulkOperationPacked5() *BulkOperation {
-	ans := newBulkOperationPacked(4)
+	ans := newBulkOperationPacked(5)
 	return ans
 }
 
@@ -196,6 +265,46 @@ func (p *BulkOperationPackedSingleBlock) ByteValueCount() int {
 	return p.valueCount
 }
 
+func (p *BulkOperationPackedSingleBlock) ByteBlockCount() int {
+	return BLOCK_COUNT * 8
+}
+
+func (p *BulkOperationPackedSingleBlock) DecodeByteToInt(blocks []byte, values []int32, iterations int) {
+	if p.bitsPerValue > 32 {
+		panic(fmt.Sprintf("Cannot decode %v-bits values into an []int32", p.bitsPerValue))
+	}
+	valuesOffset := 0
+	for i := 0; i < iterations; i++ {
+		block := readLong(blocks[8*i:])
+		values[valuesOffset] = int32(block & p.mask)
+		valuesOffset++
+		for j := 1; j < p.valueCount; j++ {
+			block = int64(uint64(block) >> p.bitsPerValue)
+			values[valuesOffset] = int32(block & p.mask)
+			valuesOffset++
+		}
+	}
+}
+
+func (p *BulkOperationPackedSingleBlock) DecodeByteToLong(blocks []byte, values []int64, iterations int) {
+	valuesOffset := 0
+	for i := 0; i < iterations; i++ {
+		block := readLong(blocks[8*i:])
+		values[valuesOffset] = block & p.mask
+		valuesOffset++
+		for j := 1; j < p.valueCount; j++ {
+			block = int64(uint64(block) >> p.bitsPerValue)
+			values[valuesOffset] = block & p.mask
+			valuesOffset++
+		}
+	}
+}
+
+func readLong(blocks []byte) int64 {
+	return int64(blocks[0])<<56 | int64(blocks[1])<<48 | int64(blocks[2])<<40 | int64(blocks[3])<<32 |
+		int64(blocks[4])<<24 | int64(blocks[5])<<16 | int64(blocks[6])<<8 | int64(blocks[7])
+}
+
 var (
 	packedBulkOps = []*BulkOperation{
 		newBulkOperationPacked1(),
@@ -0,0 +1,380 @@
+package util
+
+// ByteBlockPool.java
+
+const (
+	BYTE_BLOCK_SHIFT = 15
+	BYTE_BLOCK_SIZE  = 1 << BYTE_BLOCK_SHIFT
+	BYTE_BLOCK_MASK  = BYTE_BLOCK_SIZE - 1
+)
+
+// Abstract class for allocating and freeing byte blocks.
+type ByteAllocator interface {
+	RecycleByteBlocks(blocks [][]byte)
+	ByteBlock() []byte
+}
+
+// A simple ByteAllocator that never recycles.
+type DirectByteAllocator struct{}
+
+func (a *DirectByteAllocator) RecycleByteBlocks(blocks [][]byte) {}
+
+func (a *DirectByteAllocator) ByteBlock() []byte {
+	return make([]byte, BYTE_BLOCK_SIZE)
+}
+
+/*
+A simple ByteAllocator that never recycles, but tracks how many bytes
+are allocated through it.
+*/
+type DirectTrackingByteAllocator struct {
+	bytesUsed Counter
+}
+
+func NewDirectTrackingByteAllocator(bytesUsed Counter) *DirectTrackingByteAllocator {
+	return &DirectTrackingByteAllocator{bytesUsed}
+}
+
+func (a *DirectTrackingByteAllocator) RecycleByteBlocks(blocks [][]byte) {
+	a.bytesUsed.AddAndGet(-int64(len(blocks) * BYTE_BLOCK_SIZE))
+}
+
+func (a *DirectTrackingByteAllocator) ByteBlock() []byte {
+	a.bytesUsed.AddAndGet(BYTE_BLOCK_SIZE)
+	return make([]byte, BYTE_BLOCK_SIZE)
+}
+
+/*
+Class that Posting and PostingVector use to write byte streams into
+shared fixed-size []byte arrays. The idea is to allocate slices of
+increasing lengths. For example, the first slice is 5 bytes, the next
+slice is 14, etc. We start by writing our bytes into the first 5
+bytes. When we hit the end of the slice, we allocate the next slice
+and then write the address of the new slice into the last 4 bytes of
+the previous slice (the "forwarding address").
+
+Each slice is filled with 0's initially, and we mark the end with a
+non-zero byte. This way the methods that are writing into the slice
+don't need to record its length and instead allocate a new slice once
+they hit a non-zero byte.
+*/
+type ByteBlockPool struct {
+	// array of buffers currently used in the pool. Buffers are
+	// allocated if needed don't modify this outside of this class.
+	Buffers [][]byte
+	// index into the buffers array pointing to the current buffer
+	// used as the head
+	bufferUpto int
+	// Where we are in head buffer
+	ByteUpto int
+	// Current head buffer
+	Buffer []byte
+	// Current head offset
+	ByteOffset int
+
+	allocator ByteAllocator
+}
+
+func NewByteBlockPool(allocator ByteAllocator) *ByteBlockPool {
+	return &ByteBlockPool{
+		bufferUpto: -1,
+		ByteUpto:   BYTE_BLOCK_SIZE,
+		ByteOffset: -BYTE_BLOCK_SIZE,
+		allocator:  allocator,
+	}
+}
+
+/*
+Expert: Resets the pool to its initial state reusing the first
+buffer. Calling NextBuffer() is not needed after reset.
+
+If zeroFillBuffers is true, the buffers are filled with 0. This
+should be set to true if this pool is used with slices. If
+reuseFirst is true, the first buffer will be reused and calling
+NextBuffer() is not needed after reset iff the block pool was used
+before ie. NextBuffer() was called before.
+*/
+func (pool *ByteBlockPool) Reset(zeroFillBuffers, reuseFirst bool) {
+	if pool.bufferUpto == -1 {
+		return
+	}
+	// We allocated at least one buffer
+	if zeroFillBuffers {
+		for i := 0; i < pool.bufferUpto; i++ {
+			// Fully zero fill buffers that we fully used
+			zeroFill(pool.Buffers[i])
+		}
+		// Partial zero fill the final buffer
+		zeroFill(pool.Buffers[pool.bufferUpto][:pool.ByteUpto])
+	}
+
+	if pool.bufferUpto > 0 || !reuseFirst {
+		offset := 0
+		if reuseFirst {
+			offset = 1
+		}
+		// Recycle all but the first buffer
+		pool.allocator.RecycleByteBlocks(pool.Buffers[offset : pool.bufferUpto+1])
+		for i := offset; i <= pool.bufferUpto; i++ {
+			pool.Buffers[i] = nil
+		}
+	}
+	if reuseFirst {
+		// Re-use the first buffer
+		pool.bufferUpto = 0
+		pool.ByteUpto = 0
+		pool.ByteOffset = 0
+		pool.Buffer = pool.Buffers[0]
+	} else {
+		pool.bufferUpto = -1
+		pool.ByteUpto = BYTE_BLOCK_SIZE
+		pool.ByteOffset = -BYTE_BLOCK_SIZE
+		pool.Buffer = nil
+	}
+}
+
+func zeroFill(buf []byte) {
+	for i, _ := range buf {
+		buf[i] = 0
+	}
+}
+
+/*
+Advances the pool to its next buffer. This method should be called
+once after the constructor to initialize the pool. In contrast to the
+constructor a Reset() call will advance the pool to its first buffer
+immediately.
+*/
+func (pool *ByteBlockPool) NextBuffer() {
+	pool.bufferUpto++
+	if pool.bufferUpto == len(pool.Buffers) {
+		pool.Buffers = append(pool.Buffers, nil)
+	}
+	pool.Buffer = pool.allocator.ByteBlock()
+	pool.Buffers[pool.bufferUpto] = pool.Buffer
+	pool.ByteUpto = 0
+	pool.ByteOffset += BYTE_BLOCK_SIZE
+}
+
+// Allocates a new slice with the given size.
+func (pool *ByteBlockPool) NewSlice(size int) int {
+	if pool.ByteUpto > BYTE_BLOCK_SIZE-size {
+		pool.NextBuffer()
+	}
+	upto := pool.ByteUpto
+	pool.ByteUpto += size
+	pool.Buffer[pool.ByteUpto-1] = 16
+	return upto
+}
+
+// Size of each slice. These arrays should be at most 16 elements
+// (index is encoded with 4 bits). First array is just a compact way
+// to encode X+1 with a max. Second array is the length of each slice,
+// ie first slice is 5 bytes, next slice is 14 bytes, etc.
+var (
+	NEXT_LEVEL_ARRAY = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 9}
+	LEVEL_SIZE_ARRAY = []int{5, 14, 20, 30, 40, 40, 80, 80, 120, 200}
+)
+
+// The first level size for new slices
+const FIRST_LEVEL_SIZE = 5
+
+/*
+Creates a new byte slice with the given starting size and returns the
+slices offset in the pool.
+*/
+func (pool *ByteBlockPool) AllocSlice(slice []byte, upto int) int {
+	level := slice[upto] & 15
+	newLevel := NEXT_LEVEL_ARRAY[level]
+	newSize := LEVEL_SIZE_ARRAY[newLevel]
+
+	// Maybe allocate another block
+	if pool.ByteUpto > BYTE_BLOCK_SIZE-newSize {
+		pool.NextBuffer()
+	}
+
+	newUpto := pool.ByteUpto
+	offset := newUpto + pool.ByteOffset
+	pool.ByteUpto += newSize
+
+	// Copy forward the past 3 bytes (which we are about to overwrite
+	// with the forwarding address):
+	copy(pool.Buffer[newUpto:], slice[upto-3:upto])
+
+	// Write forwarding address at end of last slice:
+	slice[upto-3] = byte(offset >> 24)
+	slice[upto-2] = byte(offset >> 16)
+	slice[upto-1] = byte(offset >> 8)
+	slice[upto] = byte(offset)
+
+	// Write new level:
+	pool.Buffer[pool.ByteUpto-1] = byte(16 | newLevel)
+
+	return newUpto + 3
+}
+
+/*
+Returns the term starting at textStart. The term is stored as its
+length, encoded in one or two bytes, followed by its bytes. The
+returned slice shares the pool's buffer.
+*/
+func (pool *ByteBlockPool) Term(textStart int) []byte {
+	bytes := pool.Buffers[textStart>>BYTE_BLOCK_SHIFT]
+	pos := textStart & BYTE_BLOCK_MASK
+	if (bytes[pos] & 0x80) == 0 {
+		// length is 1 byte
+		length := int(bytes[pos])
+		return bytes[pos+1 : pos+1+length]
+	}
+	// length is 2 bytes
+	length := int(bytes[pos]&0x7f) + (int(bytes[pos+1]) << 7)
+	return bytes[pos+2 : pos+2+length]
+}
+
+// Appends the bytes to the pool, starting a new buffer if needed.
+// The bytes must not be longer than one block.
+func (pool *ByteBlockPool) Append(bytes []byte) {
+	length := len(bytes)
+	if length > BYTE_BLOCK_SIZE {
+		panic("assert fail")
+	}
+	if pool.ByteUpto+length > BYTE_BLOCK_SIZE {
+		pool.NextBuffer()
+	}
+	copy(pool.Buffer[pool.ByteUpto:], bytes)
+	pool.ByteUpto += length
+}
+
+// IntBlockPool.java
+
+const (
+	INT_BLOCK_SHIFT = 13
+	INT_BLOCK_SIZE  = 1 << INT_BLOCK_SHIFT
+	INT_BLOCK_MASK  = INT_BLOCK_SIZE - 1
+)
+
+// Abstract class for allocating and freeing int blocks.
+type IntAllocator interface {
+	RecycleIntBlocks(blocks [][]int32)
+	IntBlock() []int32
+}
+
+// A simple IntAllocator that never recycles.
+type DirectIntAllocator struct{}
+
+func (a *DirectIntAllocator) RecycleIntBlocks(blocks [][]int32) {}
+
+func (a *DirectIntAllocator) IntBlock() []int32 {
+	return make([]int32, INT_BLOCK_SIZE)
+}
+
+/*
+A simple IntAllocator that never recycles, but tracks how many bytes
+are allocated through it.
+*/
+type DirectTrackingIntAllocator struct {
+	bytesUsed Counter
+}
+
+func NewDirectTrackingIntAllocator(bytesUsed Counter) *DirectTrackingIntAllocator {
+	return &DirectTrackingIntAllocator{bytesUsed}
+}
+
+func (a *DirectTrackingIntAllocator) RecycleIntBlocks(blocks [][]int32) {
+	a.bytesUsed.AddAndGet(-int64(len(blocks) * INT_BLOCK_SIZE * NUM_BYTES_INT))
+}
+
+func (a *DirectTrackingIntAllocator) IntBlock() []int32 {
+	a.bytesUsed.AddAndGet(INT_BLOCK_SIZE * NUM_BYTES_INT)
+	return make([]int32, INT_BLOCK_SIZE)
+}
+
+/*
+A pool for int blocks similar to ByteBlockPool.
+*/
+type IntBlockPool struct {
+	// array of buffers currently used in the pool.
+	Buffers [][]int32
+	// index into the buffers array pointing to the current buffer
+	// used as the head
+	bufferUpto int
+	// Pointer to the current position in head buffer
+	IntUpto int
+	// Current head buffer
+	Buffer []int32
+	// Current head offset
+	IntOffset int
+
+	allocator IntAllocator
+}
+
+func NewIntBlockPool(allocator IntAllocator) *IntBlockPool {
+	return &IntBlockPool{
+		bufferUpto: -1,
+		IntUpto:    INT_BLOCK_SIZE,
+		IntOffset:  -INT_BLOCK_SIZE,
+		allocator:  allocator,
+	}
+}
+
+/*
+Expert: Resets the pool to its initial state reusing the first
+buffer, which is zero filled if zeroFillBuffers is true.
+*/
+func (pool *IntBlockPool) Reset(zeroFillBuffers, reuseFirst bool) {
+	if pool.bufferUpto == -1 {
+		return
+	}
+	// We allocated at least one buffer
+	if zeroFillBuffers {
+		for i := 0; i < pool.bufferUpto; i++ {
+			// Fully zero fill buffers that we fully used
+			for j, _ := range pool.Buffers[i] {
+				pool.Buffers[i][j] = 0
+			}
+		}
+		// Partial zero fill the final buffer
+		for j := 0; j < pool.IntUpto; j++ {
+			pool.Buffers[pool.bufferUpto][j] = 0
+		}
+	}
+
+	start := 0
+	if reuseFirst {
+		start = 1
+	}
+	if pool.bufferUpto >= start {
+		// Recycle all but the first buffer
+		pool.allocator.RecycleIntBlocks(pool.Buffers[start : pool.bufferUpto+1])
+	}
+	for i := start; i <= pool.bufferUpto; i++ {
+		pool.Buffers[i] = nil
+	}
+	if reuseFirst {
+		// Re-use the first buffer
+		pool.bufferUpto = 0
+		pool.IntUpto = 0
+		pool.IntOffset = 0
+		pool.Buffer = pool.Buffers[0]
+	} else {
+		pool.bufferUpto = -1
+		pool.IntUpto = INT_BLOCK_SIZE
+		pool.IntOffset = -INT_BLOCK_SIZE
+		pool.Buffer = nil
+	}
+}
+
+/*
+Advances the pool to its next buffer. This method should be called
+once after the constructor to initialize the pool.
+*/
+func (pool *IntBlockPool) NextBuffer() {
+	pool.bufferUpto++
+	if pool.bufferUpto == len(pool.Buffers) {
+		pool.Buffers = append(pool.Buffers, nil)
+	}
+	pool.Buffer = pool.allocator.IntBlock()
+	pool.Buffers[pool.bufferUpto] = pool.Buffer
+	pool.IntUpto = 0
+	pool.IntOffset += INT_BLOCK_SIZE
+}
@@ -5,7 +5,7 @@ import (
 )
 
 type BytesStore struct {
-	*DataOutput
+	*DataOutputImpl
 	blocks    [][]byte
 	blockSize uint32
 	blockBits uint32
@@ -16,42 +16,44 @@ type BytesStore struct {
 
 func newBytesStore() *BytesStore {
 	self := &BytesStore{}
-	self.DataOutput = &DataOutput{
-		WriteByte: func(b byte) error {
-			if self.nextWrite == self.blockSize {
-				self.current = make([]byte, self.blockSize)
-				self.blocks = append(self.blocks, self.current)
-				self.nextWrite = 0
-			}
-			self.current[self.nextWrite] = b
-			self.nextWrite++
-			return nil
-		},
-		WriteBytes: func(buf []byte) error {
-			var offset uint32 = 0
-			length := uint32(len(buf))
-			for length > 0 {
-				chunk := self.blockSize - self.nextWrite
-				if length <= chunk {
-					copy(self.current[self.nextWrite:], buf[offset:offset+length])
-					self.nextWrite += length
-					break
-				} else {
-					if chunk > 0 {
-						copy(self.current[self.nextWrite:], buf[offset:offset+chunk])
-						offset += chunk
-						length -= chunk
-					}
-					self.current = make([]byte, self.blockSize)
-					self.blocks = append(self.blocks, self.current)
-					self.nextWrite = 0
-				}
-			}
-			return nil
-		}}
+	self.DataOutputImpl = NewDataOutput(self)
 	return self
 }
 
+func (s *BytesStore) WriteByte(b byte) error {
+	if s.nextWrite == s.blockSize {
+		s.current = make([]byte, s.blockSize)
+		s.blocks = append(s.blocks, s.current)
+		s.nextWrite = 0
+	}
+	s.current[s.nextWrite] = b
+	s.nextWrite++
+	return nil
+}
+
+func (s *BytesStore) WriteBytes(buf []byte) error {
+	var offset uint32 = 0
+	length := uint32(len(buf))
+	for length > 0 {
+		chunk := s.blockSize - s.nextWrite
+		if length <= chunk {
+			copy(s.current[s.nextWrite:], buf[offset:offset+length])
+			s.nextWrite += length
+			break
+		} else {
+			if chunk > 0 {
+				copy(s.current[s.nextWrite:], buf[offset:offset+chunk])
+				offset += chunk
+				length -= chunk
+			}
+			s.current = make([]byte, s.blockSize)
+			s.blocks = append(s.blocks, s.current)
+			s.nextWrite = 0
+		}
+	}
+	return nil
+}
+
 func newBytesStoreFromBits(blockBits uint32) *BytesStore {
 	blockSize := uint32(1) << blockBits
 	self := newBytesStore()
@@ -92,6 +94,57 @@ func newBytesStoreFromInput(in DataInput, numBytes int64, maxBlockSize uint32) (
 	return self, nil
 }
 
+/*
+Reverse from srcPos, inclusive, to destPos, inclusive.
+*/
+func (s *BytesStore) reverse(srcPos, destPos int64) {
+	if srcPos >= destPos {
+		panic("assert fail")
+	}
+	srcBlockIndex := int(srcPos >> s.blockBits)
+	src := int(uint32(srcPos) & s.blockMask)
+	srcBlock := s.blocks[srcBlockIndex]
+
+	destBlockIndex := int(destPos >> s.blockBits)
+	dest := int(uint32(destPos) & s.blockMask)
+	destBlock := s.blocks[destBlockIndex]
+
+	limit := int(destPos-srcPos+1) / 2
+	for i := 0; i < limit; i++ {
+		srcBlock[src], destBlock[dest] = destBlock[dest], srcBlock[src]
+		src++
+		if src == int(s.blockSize) {
+			srcBlockIndex++
+			srcBlock = s.blocks[srcBlockIndex]
+			src = 0
+		}
+
+		dest--
+		if dest == -1 {
+			destBlockIndex--
+			destBlock = s.blocks[destBlockIndex]
+			dest = int(s.blockSize - 1)
+		}
+	}
+}
+
+func (s *BytesStore) getPosition() int64 {
+	return int64(len(s.blocks)-1)*int64(s.blockSize) + int64(s.nextWrite)
+}
+
+// Writes all of our bytes to the target DataOutput.
+func (s *BytesStore) writeTo(out DataOutput) error {
+	for i, block := range s.blocks {
+		if i == len(s.blocks)-1 {
+			block = block[:s.nextWrite]
+		}
+		if err := out.WriteBytes(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *BytesStore) String() string {
 	return fmt.Sprintf("%v-bits x%v bytes store", s.blockBits, len(s.blocks))
 }
@@ -213,7 +266,7 @@ func (r *BytesStoreReverseReader) setPosition(pos int64) {
 	// setPosition(0), the next byte you read is
 	// bytes[0] ... but I would expect bytes[-1] (ie,
 	// EOF)...?
-	bufferIndex := int32(pos >> r.owner.blockSize)
+	bufferIndex := int32(pos >> r.owner.blockBits)
 	r.nextBuffer = bufferIndex - 1
 	r.current = r.owner.blocks[bufferIndex]
 	r.nextRead = int32(uint32(pos) & r.owner.blockMask)
@@ -232,7 +285,7 @@ func (bs *BytesStore) reverseReaderAllowSingle(allowSingle bool) BytesReader {
 	if len(bs.blocks) > 0 {
 		current = bs.blocks[0]
 	}
-	ans := &BytesStoreReverseReader{current: current, nextBuffer: -1, nextRead: 0}
+	ans := &BytesStoreReverseReader{owner: bs, current: current, nextBuffer: -1, nextRead: 0}
 	ans.DataInputImpl = &DataInputImpl{ans}
 	return ans
 }
@@ -0,0 +1,393 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// BytesRefHash.java
+
+const BYTES_REF_HASH_DEFAULT_CAPACITY = 16
+
+/*
+Manages allocation of the per-term addresses.
+*/
+type BytesStartArray interface {
+	// Initializes the BytesStartArray. This call will allocate memory.
+	Init() []int32
+	// Grows the BytesStartArray.
+	Grow() []int32
+	// Clears the BytesStartArray and returns the cleared instance.
+	Clear() []int32
+}
+
+// A simple BytesStartArray that tracks nothing but the starts.
+type DirectBytesStartArray struct {
+	initSize   int
+	bytesStart []int32
+}
+
+func NewDirectBytesStartArray(initSize int) *DirectBytesStartArray {
+	return &DirectBytesStartArray{initSize: initSize}
+}
+
+func (a *DirectBytesStartArray) Init() []int32 {
+	a.bytesStart = make([]int32, a.initSize)
+	return a.bytesStart
+}
+
+func (a *DirectBytesStartArray) Grow() []int32 {
+	a.bytesStart = growInt32s(a.bytesStart, len(a.bytesStart)+1)
+	return a.bytesStart
+}
+
+func (a *DirectBytesStartArray) Clear() []int32 {
+	a.bytesStart = nil
+	return nil
+}
+
+// Returns an array of at least minSize, growing by 1/8th to amortize
+// the cost of repeated growth.
+func growInt32s(array []int32, minSize int) []int32 {
+	if len(array) >= minSize {
+		return array
+	}
+	newSize := minSize + minSize>>3
+	if newSize < 4 {
+		newSize = 4
+	}
+	ans := make([]int32, newSize)
+	copy(ans, array)
+	return ans
+}
+
+/*
+BytesRefHash is a special purpose hash-map like data-structure
+optimized for byte slices. BytesRefHash maintains mappings of byte
+arrays to ids (map[[]byte]int) storing the hashed bytes efficiently
+in continuous storage. The mapping to the id is encapsulated inside
+BytesRefHash and is guaranteed to be increased for each added byte
+slice starting with 0.
+
+Note: The maximum capacity byte slice is BYTE_BLOCK_SIZE-2.
+*/
+type BytesRefHash struct {
+	pool       *ByteBlockPool
+	bytesStart []int32
+
+	hashSize     int
+	hashHalfSize int
+	hashMask     int
+	count        int
+	lastCount    int
+	ids          []int32
+
+	bytesStartArray BytesStartArray
+}
+
+func NewBytesRefHash(pool *ByteBlockPool, capacity int, bytesStartArray BytesStartArray) *BytesRefHash {
+	ids := make([]int32, capacity)
+	for i, _ := range ids {
+		ids[i] = -1
+	}
+	return &BytesRefHash{
+		pool:            pool,
+		bytesStart:      bytesStartArray.Init(),
+		hashSize:        capacity,
+		hashHalfSize:    capacity >> 1,
+		hashMask:        capacity - 1,
+		lastCount:       -1,
+		ids:             ids,
+		bytesStartArray: bytesStartArray,
+	}
+}
+
+// Returns the number of byte slices contained in this hash.
+func (h *BytesRefHash) Size() int {
+	return h.count
+}
+
+/*
+Returns the byte slice for the given id. The returned slice shares
+the underlying pool, and must not be modified.
+*/
+func (h *BytesRefHash) Get(id int) []byte {
+	if h.bytesStart == nil {
+		panic("bytesStart is nil - not initialized")
+	}
+	return h.pool.Term(int(h.bytesStart[id]))
+}
+
+/*
+Returns the ids compacted into the front of the ids array, sorted by
+the byte slice they map to, in byte-wise order.
+
+Note: This is a destructive operation. Clear() must be called in
+order to reuse this BytesRefHash instance.
+*/
+func (h *BytesRefHash) Sort() []int32 {
+	ids := h.compact()
+	sort.Sort(&bytesRefHashSorter{h, ids[:h.count]})
+	return ids
+}
+
+type bytesRefHashSorter struct {
+	hash *BytesRefHash
+	ids  []int32
+}
+
+func (s *bytesRefHashSorter) Len() int {
+	return len(s.ids)
+}
+
+func (s *bytesRefHashSorter) Less(i, j int) bool {
+	return bytes.Compare(s.hash.Get(int(s.ids[i])), s.hash.Get(int(s.ids[j]))) < 0
+}
+
+func (s *bytesRefHashSorter) Swap(i, j int) {
+	s.ids[i], s.ids[j] = s.ids[j], s.ids[i]
+}
+
+// Returns the ids array compacted into the front; the remaining
+// entries are -1.
+func (h *BytesRefHash) compact() []int32 {
+	if h.bytesStart == nil {
+		panic("bytesStart is nil - not initialized")
+	}
+	upto := 0
+	for i := 0; i < h.hashSize; i++ {
+		if h.ids[i] != -1 {
+			if upto < i {
+				h.ids[upto] = h.ids[i]
+				h.ids[i] = -1
+			}
+			upto++
+		}
+	}
+	if upto != h.count {
+		panic("assert fail")
+	}
+	h.lastCount = h.count
+	return h.ids
+}
+
+func (h *BytesRefHash) shrink(targetSize int) bool {
+	// Cannot use util.Shrink because we require power of 2:
+	newSize := h.hashSize
+	for newSize >= 8 && newSize/4 > targetSize {
+		newSize /= 2
+	}
+	if newSize != h.hashSize {
+		h.hashSize = newSize
+		h.ids = make([]int32, h.hashSize)
+		for i, _ := range h.ids {
+			h.ids[i] = -1
+		}
+		h.hashHalfSize = newSize / 2
+		h.hashMask = newSize - 1
+		return true
+	}
+	return false
+}
+
+/*
+Clears the BytesRefHash. If resetPool is true, the underlying
+ByteBlockPool is reset as well.
+*/
+func (h *BytesRefHash) Clear(resetPool bool) {
+	h.lastCount = h.count
+	h.count = 0
+	if resetPool {
+		h.pool.Reset(false, false) // we don't need to 0-fill the buffers
+	}
+	h.bytesStart = h.bytesStartArray.Clear()
+	if h.lastCount != -1 && h.shrink(h.lastCount) {
+		// shrink clears the hash entries
+		return
+	}
+	for i, _ := range h.ids {
+		h.ids[i] = -1
+	}
+}
+
+// Closes the BytesRefHash and releases all internally used memory
+func (h *BytesRefHash) Close() {
+	h.Clear(true)
+	h.ids = nil
+}
+
+/*
+Adds a new byte slice. Returns the id the slice is stored with, or
+-(id+1) if the slice was already present in the hash. Returns an
+error if the slice is longer than BYTE_BLOCK_SIZE-2.
+*/
+func (h *BytesRefHash) Add(term []byte) (int, error) {
+	if h.bytesStart == nil {
+		panic("bytesStart is nil - not initialized")
+	}
+	length := len(term)
+	// final position
+	hashPos := h.findHash(term)
+	e := h.ids[hashPos]
+
+	if e == -1 {
+		// new entry
+		len2 := 2 + length
+		if len2+h.pool.ByteUpto > BYTE_BLOCK_SIZE {
+			if len2 > BYTE_BLOCK_SIZE {
+				return 0, errors.New(fmt.Sprintf(
+					"bytes can be at most %v in length; got %v", BYTE_BLOCK_SIZE-2, length))
+			}
+			h.pool.NextBuffer()
+		}
+		buffer := h.pool.Buffer
+		bufferUpto := h.pool.ByteUpto
+		if h.count >= len(h.bytesStart) {
+			h.bytesStart = h.bytesStartArray.Grow()
+			if h.count >= len(h.bytesStart) {
+				panic(fmt.Sprintf("count: %v len: %v", h.count, len(h.bytesStart)))
+			}
+		}
+		e = int32(h.count)
+		h.count++
+
+		h.bytesStart[e] = int32(bufferUpto + h.pool.ByteOffset)
+
+		// We first encode the length, followed by the bytes. Length is
+		// encoded as vInt, but will consume 1 or 2 bytes at most (we
+		// reject too-long terms, above).
+		if length < 128 {
+			// 1 byte to store length
+			buffer[bufferUpto] = byte(length)
+			h.pool.ByteUpto += length + 1
+			copy(buffer[bufferUpto+1:], term)
+		} else {
+			// 2 byte to store length
+			buffer[bufferUpto] = byte(0x80 | (length & 0x7f))
+			buffer[bufferUpto+1] = byte((length >> 7) & 0xff)
+			h.pool.ByteUpto += length + 2
+			copy(buffer[bufferUpto+2:], term)
+		}
+		if h.ids[hashPos] != -1 {
+			panic("assert fail")
+		}
+		h.ids[hashPos] = e
+
+		if h.count == h.hashHalfSize {
+			h.rehash(2*h.hashSize, true)
+		}
+		return int(e), nil
+	}
+	return -(int(e) + 1), nil
+}
+
+/*
+Returns the id of the given byte slice, or -1 if there is no mapping
+for the given slice.
+*/
+func (h *BytesRefHash) Find(term []byte) int {
+	return int(h.ids[h.findHash(term)])
+}
+
+func (h *BytesRefHash) findHash(term []byte) int {
+	if h.bytesStart == nil {
+		panic("bytesStart is nil - not initialized")
+	}
+	code := bytesHashCode(term)
+	hashPos := code & h.hashMask
+	if e := h.ids[hashPos]; e != -1 && !bytes.Equal(h.Get(int(e)), term) {
+		// Conflict: keep searching different locations in the hash
+		// table.
+		inc := ((code >> 8) + code) | 1
+		for {
+			code += inc
+			hashPos = code & h.hashMask
+			e = h.ids[hashPos]
+			if e == -1 || bytes.Equal(h.Get(int(e)), term) {
+				break
+			}
+		}
+	}
+	return hashPos
+}
+
+/*
+Called when hash is too small (> 50% occupied) or too large (< 20%
+occupied).
+*/
+func (h *BytesRefHash) rehash(newSize int, hashOnData bool) {
+	newMask := newSize - 1
+	newHash := make([]int32, newSize)
+	for i, _ := range newHash {
+		newHash[i] = -1
+	}
+	for i := 0; i < h.hashSize; i++ {
+		e0 := h.ids[i]
+		if e0 == -1 {
+			continue
+		}
+		var code int
+		if hashOnData {
+			code = bytesHashCode(h.Get(int(e0)))
+		} else {
+			code = int(h.bytesStart[e0])
+		}
+
+		hashPos := code & newMask
+		if newHash[hashPos] != -1 {
+			inc := ((code >> 8) + code) | 1
+			for {
+				code += inc
+				hashPos = code & newMask
+				if newHash[hashPos] == -1 {
+					break
+				}
+			}
+		}
+		newHash[hashPos] = e0
+	}
+
+	h.hashMask = newMask
+	h.ids = newHash
+	h.hashSize = newSize
+	h.hashHalfSize = newSize / 2
+}
+
+/*
+Reinitializes the BytesRefHash after a previous Clear() call. If
+Clear() has not been called previously this method has no effect.
+*/
+func (h *BytesRefHash) Reinit() {
+	if h.bytesStart == nil {
+		h.bytesStart = h.bytesStartArray.Init()
+	}
+	if h.ids == nil {
+		h.ids = make([]int32, h.hashSize)
+		for i, _ := range h.ids {
+			h.ids[i] = -1
+		}
+	}
+}
+
+/*
+Returns the bytesStart offset into the internally used ByteBlockPool
+for the given id.
+*/
+func (h *BytesRefHash) ByteStart(id int) int {
+	if h.bytesStart == nil {
+		panic("bytesStart is nil - not initialized")
+	}
+	return int(h.bytesStart[id])
+}
+
+// Same as Java's BytesRef.hashCode(), truncated to a non-negative
+// int so it can be masked.
+func bytesHashCode(bytes []byte) int {
+	var h int32 = 0
+	for _, b := range bytes {
+		h = 31*h + int32(int8(b))
+	}
+	return int(uint32(h))
+}
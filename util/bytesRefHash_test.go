@@ -0,0 +1,128 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func newTestBytesRefHash() *BytesRefHash {
+	pool := NewByteBlockPool(&DirectByteAllocator{})
+	pool.NextBuffer()
+	return NewBytesRefHash(pool, BYTES_REF_HASH_DEFAULT_CAPACITY, NewDirectBytesStartArray(BYTES_REF_HASH_DEFAULT_CAPACITY))
+}
+
+func TestBytesRefHashAdd(t *testing.T) {
+	hash := newTestBytesRefHash()
+	terms := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		term := fmt.Sprintf("term%v", i%1500)
+		if i%7 == 0 {
+			// longer than 128 bytes, with a 2 byte length
+			term = string(bytes.Repeat([]byte(term), 30))
+		}
+		id, err := hash.Add([]byte(term))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, ok := terms[term]; ok {
+			if id != -(expected + 1) {
+				t.Errorf("Expected %v for existing term, but was %v", -(expected + 1), id)
+			}
+			continue
+		}
+		if id != len(terms) {
+			t.Errorf("Expected id %v, but was %v", len(terms), id)
+		}
+		terms[term] = id
+	}
+	if hash.Size() != len(terms) {
+		t.Errorf("Expected size %v, but was %v", len(terms), hash.Size())
+	}
+	for term, id := range terms {
+		if s := string(hash.Get(id)); s != term {
+			t.Errorf("Expected %v, but was %v", term, s)
+		}
+		if n := hash.Find([]byte(term)); n != id {
+			t.Errorf("Expected to find %v, but was %v", id, n)
+		}
+	}
+	if n := hash.Find([]byte("missing")); n != -1 {
+		t.Errorf("Expected -1, but was %v", n)
+	}
+
+	sorted := make([]string, 0, len(terms))
+	for term, _ := range terms {
+		sorted = append(sorted, term)
+	}
+	sort.Strings(sorted)
+	ids := hash.Sort()
+	for i, term := range sorted {
+		if s := string(hash.Get(int(ids[i]))); s != term {
+			t.Fatalf("Expected %v at %v, but was %v", term, i, s)
+		}
+	}
+
+	hash.Clear(true)
+	hash.Reinit()
+	if hash.Size() != 0 {
+		t.Errorf("Expected empty hash, but was %v", hash.Size())
+	}
+	hash.pool.NextBuffer()
+	if id, err := hash.Add([]byte("term1")); err != nil || id != 0 {
+		t.Errorf("Expected id 0, but was %v (%v)", id, err)
+	}
+}
+
+func TestBytesRefHashLargeTerm(t *testing.T) {
+	hash := newTestBytesRefHash()
+	if _, err := hash.Add(make([]byte, BYTE_BLOCK_SIZE-1)); err == nil {
+		t.Error("Should reject a term longer than a block")
+	}
+	if _, err := hash.Add(make([]byte, BYTE_BLOCK_SIZE-2)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestByteBlockPoolSlices(t *testing.T) {
+	pool := NewByteBlockPool(&DirectByteAllocator{})
+	pool.NextBuffer()
+
+	// interleave two streams of slices, following the forwarding
+	// addresses when a slice is full
+	const numBytes = 100000
+	starts := []int{pool.NewSlice(FIRST_LEVEL_SIZE), pool.NewSlice(FIRST_LEVEL_SIZE)}
+	uptos := []int{starts[0], starts[1]}
+	for i := 0; i < numBytes; i++ {
+		for s, _ := range uptos {
+			buf := pool.Buffers[uptos[s]>>BYTE_BLOCK_SHIFT]
+			offset := uptos[s] & BYTE_BLOCK_MASK
+			if buf[offset] != 0 {
+				offset = pool.AllocSlice(buf, offset)
+				buf = pool.Buffer
+				uptos[s] = offset + pool.ByteOffset
+			}
+			buf[offset] = byte(i + s)
+			uptos[s]++
+		}
+	}
+
+	for s, start := range starts {
+		pos, level, limit := start, 0, start+LEVEL_SIZE_ARRAY[0]-4
+		for i := 0; i < numBytes; i++ {
+			if pos == limit {
+				buf := pool.Buffers[pos>>BYTE_BLOCK_SHIFT]
+				offset := pos & BYTE_BLOCK_MASK
+				pos = (int(buf[offset]) << 24) | (int(buf[offset+1]) << 16) |
+					(int(buf[offset+2]) << 8) | int(buf[offset+3])
+				level = NEXT_LEVEL_ARRAY[level]
+				limit = pos + LEVEL_SIZE_ARRAY[level] - 4
+			}
+			if b := pool.Buffers[pos>>BYTE_BLOCK_SHIFT][pos&BYTE_BLOCK_MASK]; b != byte(i+s) {
+				t.Fatalf("Stream %v: expected %v at %v, but was %v", s, byte(i+s), i, b)
+			}
+			pos++
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package util
+
+// util/Constants.java
+
+const (
+	/*
+		This is the internal Lucene version, recorded into each segment.
+		NOTE: we track per-segment version as a string with the "X.Y"
+		format, e.g. "4.2", so that is what this constant holds.
+	*/
+	LUCENE_MAIN_VERSION = "4.2"
+
+	// This is the Lucene version for display purposes.
+	LUCENE_VERSION = "4.2-SNAPSHOT"
+)
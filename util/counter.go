@@ -0,0 +1,53 @@
+package util
+
+import (
+	"sync/atomic"
+)
+
+// Counter.java
+
+// Simple counter.
+type Counter interface {
+	// Adds the given delta to the counter's value, and returns the
+	// new value.
+	AddAndGet(delta int64) int64
+	// Returns the counter's current value.
+	Get() int64
+}
+
+/*
+Returns a new counter. If threadSafe is true, the counter may be
+shared by goroutines; otherwise it must be confined to one goroutine
+at a time.
+*/
+func NewCounter(threadSafe bool) Counter {
+	if threadSafe {
+		return &atomicCounter{}
+	}
+	return &serialCounter{}
+}
+
+type serialCounter struct {
+	count int64
+}
+
+func (c *serialCounter) AddAndGet(delta int64) int64 {
+	c.count += delta
+	return c.count
+}
+
+func (c *serialCounter) Get() int64 {
+	return c.count
+}
+
+type atomicCounter struct {
+	count int64
+}
+
+func (c *atomicCounter) AddAndGet(delta int64) int64 {
+	return atomic.AddInt64(&c.count, delta)
+}
+
+func (c *atomicCounter) Get() int64 {
+	return atomic.LoadInt64(&c.count)
+}
@@ -81,3 +81,12 @@ func StripExtension(filename string) string {
 	}
 	return filename
 }
+
+// Returns the extension (anything after the first '.'),
+// or empty string if there is no '.' in the file name.
+func FileExtension(filename string) string {
+	if idx := strings.Index(filename, "."); idx != -1 {
+		return filename[idx+1:]
+	}
+	return ""
+}
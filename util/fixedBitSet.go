@@ -0,0 +1,220 @@
+package util
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// util/FixedBitSet.java
+
+/*
+BitSet of fixed length (numBits), backed by accessible Words(), used
+for the sets of documents of a segment, e.g. by caching filters and
+merging. Unlike OpenBitSet, this bit set does not auto-expand, cannot
+handle long index, and does not have fastXX/XX variants (just X).
+*/
+type FixedBitSet struct {
+	words   []uint64
+	numBits int
+}
+
+// Returns the number of 64 bit words it would take to hold numBits.
+func Bits2words(numBits int) int {
+	numLong := numBits >> 6
+	if numBits&63 != 0 {
+		numLong++
+	}
+	return numLong
+}
+
+func NewFixedBitSet(numBits int) *FixedBitSet {
+	return &FixedBitSet{make([]uint64, Bits2words(numBits)), numBits}
+}
+
+/*
+Creates a bit set of numBits, backed by the given words, which must
+hold them, i.e. have at least Bits2words(numBits) entries.
+*/
+func NewFixedBitSetOf(words []uint64, numBits int) *FixedBitSet {
+	if len(words) < Bits2words(numBits) {
+		panic(fmt.Sprintf("the given words do not hold %v bits", numBits))
+	}
+	return &FixedBitSet{words, numBits}
+}
+
+// Returns a copy of this bit set, which does not share its words.
+func (b *FixedBitSet) Clone() *FixedBitSet {
+	return &FixedBitSet{append([]uint64(nil), b.words...), b.numBits}
+}
+
+func (b *FixedBitSet) Length() int {
+	return b.numBits
+}
+
+// Expert: returns the words of this bit set.
+func (b *FixedBitSet) Words() []uint64 {
+	return b.words
+}
+
+/*
+Returns number of set bits. NOTE: this visits every word in the bit
+set, so if you use this you should consider caching the result.
+*/
+func (b *FixedBitSet) Cardinality() int {
+	count := 0
+	for _, word := range b.words {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+func (b *FixedBitSet) Get(index int) bool {
+	// assert index >= 0 && index < numBits
+	return b.words[index>>6]&(1<<uint(index&63)) != 0
+}
+
+func (b *FixedBitSet) Set(index int) {
+	// assert index >= 0 && index < numBits
+	b.words[index>>6] |= 1 << uint(index&63)
+}
+
+// Sets the bit at index, and returns its previous value.
+func (b *FixedBitSet) GetAndSet(index int) bool {
+	wordNum, mask := index>>6, uint64(1)<<uint(index&63)
+	val := b.words[wordNum]&mask != 0
+	b.words[wordNum] |= mask
+	return val
+}
+
+func (b *FixedBitSet) Clear(index int) {
+	// assert index >= 0 && index < numBits
+	b.words[index>>6] &^= 1 << uint(index&63)
+}
+
+// Clears the bit at index, and returns its previous value.
+func (b *FixedBitSet) GetAndClear(index int) bool {
+	wordNum, mask := index>>6, uint64(1)<<uint(index&63)
+	val := b.words[wordNum]&mask != 0
+	b.words[wordNum] &^= mask
+	return val
+}
+
+/*
+Returns the index of the first set bit starting at the index
+specified. -1 is returned if there are no more set bits.
+*/
+func (b *FixedBitSet) NextSetBit(index int) int {
+	// assert index >= 0
+	if index >= b.numBits {
+		return -1
+	}
+	i := index >> 6
+	if word := b.words[i] >> uint(index&63); word != 0 {
+		return index + bits.TrailingZeros64(word)
+	}
+	for i++; i < len(b.words); i++ {
+		if b.words[i] != 0 {
+			return i<<6 + bits.TrailingZeros64(b.words[i])
+		}
+	}
+	return -1
+}
+
+/*
+Returns the index of the last set bit before or on the index
+specified. -1 is returned if there are no more set bits.
+*/
+func (b *FixedBitSet) PrevSetBit(index int) int {
+	// assert index >= 0 && index < numBits
+	i := index >> 6
+	// skip all the bits to the left of index
+	if word := b.words[i] << uint(63-index&63); word != 0 {
+		return index - bits.LeadingZeros64(word)
+	}
+	for i--; i >= 0; i-- {
+		if b.words[i] != 0 {
+			return i<<6 + 63 - bits.LeadingZeros64(b.words[i])
+		}
+	}
+	return -1
+}
+
+// Does in-place OR of the bits provided by other.
+func (b *FixedBitSet) Or(other *FixedBitSet) {
+	// assert other.numBits <= numBits
+	for i, word := range other.words {
+		b.words[i] |= word
+	}
+}
+
+// Does in-place AND of the bits provided by other.
+func (b *FixedBitSet) And(other *FixedBitSet) {
+	n := len(other.words)
+	if n > len(b.words) {
+		n = len(b.words)
+	}
+	for i := 0; i < n; i++ {
+		b.words[i] &= other.words[i]
+	}
+	for i := n; i < len(b.words); i++ {
+		b.words[i] = 0
+	}
+}
+
+// Does in-place AND NOT of the bits provided by other.
+func (b *FixedBitSet) AndNot(other *FixedBitSet) {
+	n := len(other.words)
+	if n > len(b.words) {
+		n = len(b.words)
+	}
+	for i := 0; i < n; i++ {
+		b.words[i] &^= other.words[i]
+	}
+}
+
+// Returns true if the sets have any elements in common.
+func (b *FixedBitSet) Intersects(other *FixedBitSet) bool {
+	n := len(other.words)
+	if n > len(b.words) {
+		n = len(b.words)
+	}
+	for i := 0; i < n; i++ {
+		if b.words[i]&other.words[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Sets a range of bits, from startIndex (inclusive) to endIndex
+// (exclusive).
+func (b *FixedBitSet) SetRange(startIndex, endIndex int) {
+	// assert startIndex >= 0 && startIndex < numBits
+	// assert endIndex >= 0 && endIndex <= numBits
+	for i := startIndex; i < endIndex; i++ {
+		b.Set(i)
+	}
+}
+
+// Clears a range of bits, from startIndex (inclusive) to endIndex
+// (exclusive).
+func (b *FixedBitSet) ClearRange(startIndex, endIndex int) {
+	// assert startIndex >= 0 && startIndex < numBits
+	// assert endIndex >= 0 && endIndex <= numBits
+	for i := startIndex; i < endIndex; i++ {
+		b.Clear(i)
+	}
+}
+
+// Returns true if both sets have the same bits set.
+func (b *FixedBitSet) Equals(other *FixedBitSet) bool {
+	if b.numBits != other.numBits {
+		return false
+	}
+	for i, n := 0, Bits2words(b.numBits); i < n; i++ {
+		if b.words[i] != other.words[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Returns the set bits of b, by NextSetBit().
+func setBits(b *FixedBitSet) string {
+	var ans []int
+	for i := b.NextSetBit(0); i != -1; i = b.NextSetBit(i + 1) {
+		ans = append(ans, i)
+		if i+1 == b.Length() {
+			break
+		}
+	}
+	return fmt.Sprint(ans)
+}
+
+func newTestFixedBitSet(numBits int, indexes ...int) *FixedBitSet {
+	ans := NewFixedBitSet(numBits)
+	for _, i := range indexes {
+		ans.Set(i)
+	}
+	return ans
+}
+
+func TestFixedBitSet(t *testing.T) {
+	b := newTestFixedBitSet(200, 0, 3, 63, 64, 130, 199)
+	if s := setBits(b); s != "[0 3 63 64 130 199]" {
+		t.Errorf("Unexpected set bits %v", s)
+	}
+	if n := b.Cardinality(); n != 6 {
+		t.Errorf("Expected cardinality 6, but was %v", n)
+	}
+	if !b.Get(63) || b.Get(62) {
+		t.Error("Unexpected Get()")
+	}
+	for _, v := range []struct{ index, next, prev int }{
+		{0, 0, 0},
+		{1, 3, 0},
+		{4, 63, 3},
+		{65, 130, 64},
+		{131, 199, 130},
+		{198, 199, 130},
+	} {
+		if next := b.NextSetBit(v.index); next != v.next {
+			t.Errorf("Expected next set bit %v from %v, but was %v", v.next, v.index, next)
+		}
+		if prev := b.PrevSetBit(v.index); prev != v.prev {
+			t.Errorf("Expected previous set bit %v from %v, but was %v", v.prev, v.index, prev)
+		}
+	}
+	if next := b.NextSetBit(200); next != -1 {
+		t.Errorf("Expected no set bit beyond the length, but was %v", next)
+	}
+
+	if b.GetAndSet(5) || !b.GetAndSet(5) || !b.GetAndClear(5) || b.GetAndClear(5) {
+		t.Error("Unexpected previous values")
+	}
+	b.Clear(0)
+	if prev := b.PrevSetBit(2); prev != -1 {
+		t.Errorf("Expected no previous set bit, but was %v", prev)
+	}
+	b.SetRange(60, 66)
+	b.ClearRange(62, 64)
+	if s := setBits(b); s != "[3 60 61 64 65 130 199]" {
+		t.Errorf("Unexpected set bits %v", s)
+	}
+}
+
+func TestFixedBitSetOperations(t *testing.T) {
+	a := newTestFixedBitSet(130, 1, 64, 100, 129)
+	b := newTestFixedBitSet(130, 1, 65, 100)
+	for _, v := range []struct {
+		name     string
+		op       func(a, b *FixedBitSet)
+		expected string
+	}{
+		{"or", (*FixedBitSet).Or, "[1 64 65 100 129]"},
+		{"and", (*FixedBitSet).And, "[1 100]"},
+		{"andNot", (*FixedBitSet).AndNot, "[64 129]"},
+	} {
+		c := a.Clone()
+		v.op(c, b)
+		if s := setBits(c); s != v.expected {
+			t.Errorf("Expected %v for %v, but was %v", v.expected, v.name, s)
+		}
+	}
+	// the clones do not share the words
+	if s := setBits(a); s != "[1 64 100 129]" {
+		t.Errorf("Unexpected set bits %v", s)
+	}
+	if !a.Intersects(b) || a.Intersects(newTestFixedBitSet(130, 0, 65)) {
+		t.Error("Unexpected Intersects()")
+	}
+	if !a.Equals(newTestFixedBitSet(130, 1, 64, 100, 129)) || a.Equals(b) {
+		t.Error("Unexpected Equals()")
+	}
+
+	// a shorter set clears the remaining bits by And()
+	a.And(newTestFixedBitSet(64, 1))
+	if s := setBits(a); s != "[1]" {
+		t.Errorf("Unexpected set bits %v", s)
+	}
+}
@@ -240,6 +240,110 @@ func loadFST3(in DataInput, outputs Outputs, maxBlockBits uint32) (fst *FST, err
 	return fst, err
 }
 
+/*
+Returns an FST which only accepts the empty string, mapped to
+emptyOutput. This is what Builder produces when no input other than
+the empty string has been added, e.g. the terms index of a field
+whose terms all fit in one block.
+*/
+func NewEmptyStringFST(inputType InputType, outputs Outputs, emptyOutput interface{}) *FST {
+	fst := &FST{
+		inputType:   inputType,
+		outputs:     outputs,
+		NO_OUTPUT:   outputs.NoOutput(),
+		bytes:       newBytesStoreFromBits(15),
+		version:     FST_VERSION_VINT_TARGET,
+		emptyOutput: emptyOutput,
+		// If there are no nodes, ie, the FST only accepts the empty
+		// string, then startNode is 0
+		startNode: 0,
+	}
+	// pad: ensure no node gets address 0 which is reserved to mean
+	// the stop state w/ no arcs
+	fst.bytes.WriteByte(0)
+	fst.cacheRootArcs()
+	return fst
+}
+
+// Save the FST to out, in the format LoadFST() reads.
+func (t *FST) Save(out DataOutput) (err error) {
+	if t.startNode == -1 {
+		panic("call finish first")
+	}
+	if t.nodeAddress != nil {
+		panic("cannot save an FST pre-packed FST; it must first be packed")
+	}
+	if t.packed {
+		panic("not supported yet")
+	}
+	if err = codec.WriteHeader(out, FST_FILE_FORMAT_NAME, FST_VERSION_VINT_TARGET); err != nil {
+		return err
+	}
+	if err = out.WriteByte(0); err != nil { // not packed
+		return err
+	}
+	// TODO: really we should encode this as an arc, arriving
+	// to the root node, instead of special casing here:
+	if t.emptyOutput != nil {
+		// Accepts empty string
+		if err = out.WriteByte(1); err != nil {
+			return err
+		}
+
+		// Serialize empty-string output:
+		ros := newBytesStoreFromBits(10)
+		if err = t.outputs.WriteFinalOutput(t.emptyOutput, ros); err != nil {
+			return err
+		}
+		emptyOutputBytes := make([]byte, 0, ros.getPosition())
+		for _, block := range ros.blocks {
+			emptyOutputBytes = append(emptyOutputBytes, block...)
+		}
+		emptyOutputBytes = emptyOutputBytes[:ros.getPosition()]
+		// reverse
+		for i, j := 0, len(emptyOutputBytes)-1; i < j; i, j = i+1, j-1 {
+			emptyOutputBytes[i], emptyOutputBytes[j] = emptyOutputBytes[j], emptyOutputBytes[i]
+		}
+		if err = out.WriteVInt(int32(len(emptyOutputBytes))); err != nil {
+			return err
+		}
+		if err = out.WriteBytes(emptyOutputBytes); err != nil {
+			return err
+		}
+	} else if err = out.WriteByte(0); err != nil {
+		return err
+	}
+
+	var inputType byte
+	switch t.inputType {
+	case INPUT_TYPE_BYTE1:
+		inputType = 0
+	case INPUT_TYPE_BYTE2:
+		inputType = 1
+	default:
+		inputType = 2
+	}
+	if err = out.WriteByte(inputType); err != nil {
+		return err
+	}
+	if err = out.WriteVLong(t.startNode); err != nil {
+		return err
+	}
+	if err = out.WriteVLong(t.nodeCount); err != nil {
+		return err
+	}
+	if err = out.WriteVLong(t.arcCount); err != nil {
+		return err
+	}
+	if err = out.WriteVLong(t.arcWithOutputCount); err != nil {
+		return err
+	}
+	if err = out.WriteVLong(t.bytes.getPosition()); err != nil {
+		return err
+	}
+	return t.bytes.writeTo(out)
+}
+
 func (t *FST) getNodeAddress(node int64) int64 {
 	if t.nodeAddress != nil { // Deref
 		return t.nodeAddress.Get(int32(node))
@@ -271,8 +375,14 @@ func (t *FST) cacheRootArcs() {
 	}
 }
 
+// Used only by assert
 func (t *FST) assertRootArcs() bool {
-	panic("not implemented yet")
+	for i, root := range t.cachedRootArcs {
+		if root != nil && root.Label != i {
+			panic("assert fail")
+		}
+	}
+	return true
 }
 
 func (t *FST) readLabel(in DataInput) (v int, err error) {
@@ -291,6 +401,21 @@ func (t *FST) readLabel(in DataInput) (v int, err error) {
 	return v, err
 }
 
+func (t *FST) NodeCount() int64 {
+	// 1+ in order to count the -1 implicit final node
+	return 1 + t.nodeCount
+}
+
+func (t *FST) ArcCount() int64 {
+	return t.arcCount
+}
+
+// Returns bytes used to represent the FST
+func (t *FST) SizeInBytes() int64 {
+	// TODO add nodeRefToAddress for packed FST
+	return t.bytes.getPosition()
+}
+
 func targetHasArcs(arc *Arc) bool {
 	return arc.target > 0
 }
@@ -520,7 +645,7 @@ func (t *FST) FindTargetArc(labelToMatch int, follow *Arc, arc *Arc, in BytesRea
 			}
 		}
 		arc.posArcsStart = in.getPosition()
-		for low, high := 0, arc.numArcs-1; low < high; {
+		for low, high := 0, arc.numArcs-1; low <= high; {
 			log.Println("    cycle")
 			mid := int(uint(low+high) / 2)
 			in.setPosition(arc.posArcsStart)
@@ -544,27 +669,29 @@ func (t *FST) FindTargetArc(labelToMatch int, follow *Arc, arc *Arc, in BytesRea
 		return nil, nil
 	}
 
-	panic("not implemented yet")
-
 	// Linear scan
-	// readFirstRealTargetArc(follow.target, arc, in);
-
-	// while(true) {
-	//   //System.out.println("  non-bs cycle");
-	//   // TODO: we should fix this code to not have to create
-	//   // object for the output of every arc we scan... only
-	//   // for the matching arc, if found
-	//   if (arc.label == labelToMatch) {
-	//     //System.out.println("    found!");
-	//     return arc;
-	//   } else if (arc.label > labelToMatch) {
-	//     return null;
-	//   } else if (arc.isLast()) {
-	//     return null;
-	//   } else {
-	//     readNextRealArc(arc, in);
-	//   }
-	// }
+	if _, err = t.readFirstRealTargetArc(follow.target, arc, in); err != nil {
+		return nil, err
+	}
+
+	for {
+		log.Println("  non-bs cycle")
+		// TODO: we should fix this code to not have to create
+		// object for the output of every arc we scan... only
+		// for the matching arc, if found
+		if arc.Label == labelToMatch {
+			log.Println("    found!")
+			return arc, nil
+		} else if arc.Label > labelToMatch {
+			return nil, nil
+		} else if arc.isLast() {
+			return nil, nil
+		} else {
+			if _, err = t.readNextRealArc(arc, in); err != nil {
+				return nil, err
+			}
+		}
+	}
 }
 
 func (t *FST) seekToNextNode(in BytesReader) error {
@@ -623,7 +750,19 @@ type BytesReader interface {
 }
 
 type Outputs interface {
+	// Eg common("foobar", "food") -> "foo"
+	Common(output1, output2 interface{}) interface{}
+	// Eg subtract("foobar", "foo") -> "bar"
+	Subtract(output, inc interface{}) interface{}
+	// Eg add("foo", "bar") -> "foobar"
 	Add(prefix interface{}, output interface{}) interface{}
+	// Encode an output value into a DataOutput.
+	Write(output interface{}, out DataOutput) error
+	/*
+		Encode an final node output value into a DataOutput. By default
+		this just calls Write().
+	*/
+	WriteFinalOutput(output interface{}, out DataOutput) error
 	Read(in DataInput) (e interface{}, err error)
 	ReadFinalOutput(in DataInput) (e interface{}, err error)
 	NoOutput() interface{}
@@ -633,6 +772,10 @@ type abstractOutputs struct {
 	Outputs
 }
 
+func (out *abstractOutputs) WriteFinalOutput(output interface{}, o DataOutput) error {
+	return out.Outputs.Write(output, o)
+}
+
 func (out *abstractOutputs) ReadFinalOutput(in DataInput) (e interface{}, err error) {
 	log.Printf("Reading final output from %v...", in)
 	return out.Outputs.Read(in)
@@ -653,6 +796,14 @@ func ByteSequenceOutputsSingleton() *ByteSequenceOutputs {
 	return oneByteSequenceOutputs
 }
 
+func (out *ByteSequenceOutputs) Write(output interface{}, o DataOutput) error {
+	prefix := output.([]byte)
+	if err := o.WriteVInt(int32(len(prefix))); err != nil {
+		return err
+	}
+	return o.WriteBytes(prefix)
+}
+
 func (out *ByteSequenceOutputs) Read(in DataInput) (e interface{}, err error) {
 	log.Printf("Reading from %v...", in)
 	if length, err := in.ReadVInt(); err == nil {
@@ -670,6 +821,54 @@ func (out *ByteSequenceOutputs) Read(in DataInput) (e interface{}, err error) {
 	return e, err
 }
 
+func (out *ByteSequenceOutputs) Common(_output1, _output2 interface{}) interface{} {
+	output1, output2 := _output1.([]byte), _output2.([]byte)
+	pos := 0
+	for pos < len(output1) && pos < len(output2) && output1[pos] == output2[pos] {
+		pos++
+	}
+	if pos == 0 {
+		// no common prefix
+		return noOutputs
+	} else if pos == len(output1) {
+		// output1 is a prefix of output2
+		return output1
+	} else if pos == len(output2) {
+		// output2 is a prefix of output1
+		return output2
+	}
+	return output1[:pos]
+}
+
+func (out *ByteSequenceOutputs) Subtract(_output, _inc interface{}) interface{} {
+	output, inc := _output.([]byte), _inc.([]byte)
+	if len(inc) == 0 {
+		// no prefix removed
+		return output
+	} else if len(inc) == len(output) {
+		// entire output removed
+		return noOutputs
+	}
+	if len(inc) > len(output) {
+		panic(fmt.Sprintf("inc.length=%v vs output.length=%v", len(inc), len(output)))
+	}
+	return output[len(inc):]
+}
+
+func (out *ByteSequenceOutputs) Add(_prefix interface{}, _output interface{}) interface{} {
+	prefix, output := _prefix.([]byte), _output.([]byte)
+	if len(prefix) == 0 {
+		return output
+	} else if len(output) == 0 {
+		return prefix
+	}
+	// assert len(prefix) > 0 && len(output) > 0
+	result := make([]byte, len(prefix)+len(output))
+	copy(result, prefix)
+	copy(result[len(prefix):], output)
+	return result
+}
+
 func (out *ByteSequenceOutputs) NoOutput() interface{} {
 	return noOutputs
 }
@@ -678,6 +877,87 @@ func (out *ByteSequenceOutputs) String() string {
 	return "ByteSequenceOutputs"
 }
 
+// util/fst/PositiveIntOutputs.java
+
+/*
+An FST Outputs implementation where each output is a non-negative
+int64 value.
+*/
+type PositiveIntOutputs struct {
+	*abstractOutputs
+	doShare bool
+}
+
+var noOutputPositiveInt = int64(0)
+var singletonShare, singletonNoShare *PositiveIntOutputs
+
+func newPositiveIntOutputs(doShare bool) *PositiveIntOutputs {
+	ans := &PositiveIntOutputs{doShare: doShare}
+	ans.abstractOutputs = &abstractOutputs{ans}
+	return ans
+}
+
+func PositiveIntOutputsSingleton(doShare bool) *PositiveIntOutputs {
+	if doShare {
+		if singletonShare == nil {
+			singletonShare = newPositiveIntOutputs(true)
+		}
+		return singletonShare
+	}
+	if singletonNoShare == nil {
+		singletonNoShare = newPositiveIntOutputs(false)
+	}
+	return singletonNoShare
+}
+
+func (out *PositiveIntOutputs) Write(output interface{}, o DataOutput) error {
+	return o.WriteVLong(output.(int64))
+}
+
+func (out *PositiveIntOutputs) Read(in DataInput) (e interface{}, err error) {
+	v, err := in.ReadVLong()
+	if err != nil {
+		return nil, err
+	}
+	if v == 0 {
+		return noOutputPositiveInt, nil
+	}
+	return v, nil
+}
+
+func (out *PositiveIntOutputs) Common(output1, output2 interface{}) interface{} {
+	n1, n2 := output1.(int64), output2.(int64)
+	if out.doShare {
+		if n1 < n2 {
+			return n1
+		}
+		return n2
+	} else if n1 == n2 {
+		return n1
+	}
+	return noOutputPositiveInt
+}
+
+func (out *PositiveIntOutputs) Subtract(output, inc interface{}) interface{} {
+	n1, n2 := output.(int64), inc.(int64)
+	if n2 > n1 {
+		panic("assert fail")
+	}
+	return n1 - n2
+}
+
+func (out *PositiveIntOutputs) Add(prefix interface{}, output interface{}) interface{} {
+	return prefix.(int64) + output.(int64)
+}
+
+func (out *PositiveIntOutputs) NoOutput() interface{} {
+	return noOutputPositiveInt
+}
+
+func (out *PositiveIntOutputs) String() string {
+	return fmt.Sprintf("PositiveIntOutputs(doShare=%v)", out.doShare)
+}
+
 // util/fst/Util.java
 
 /** Looks up the output for this input, or null if the
@@ -695,7 +975,7 @@ func GetFSTOutput(fst *FST, input []byte) (output interface{}, err error) {
 	for _, v := range input {
 		ret, err := fst.FindTargetArc(int(v), arc, arc, fstReader)
 		if ret == nil || err != nil {
-			return ret, err
+			return nil, err
 		}
 		output = fst.outputs.Add(output, arc.Output)
 	}
@@ -706,3 +986,123 @@ func GetFSTOutput(fst *FST, input []byte) (output interface{}, err error) {
 		return nil, nil
 	}
 }
+
+/*
+Reverse lookup (lookup by output instead of by input), in the special
+case when your FSTs outputs are strictly ascending. This locates the
+input/output pair where the output is equal to the target, and will
+return nil if that output does not exist.
+
+NOTE: this only works with PositiveIntOutputs, only works
+when the outputs are ascending in order with the inputs and
+only works when you shared the outputs (pass doShare=true
+to PositiveIntOutputsSingleton()). For example, simple ordinals
+(0, 1, 2, ...), or file offsets (when appending to a file) fit
+this.
+*/
+func GetFSTByOutput(fst *FST, targetOutput int64) (result []int, err error) {
+	in := fst.BytesReader()
+	arc := fst.FirstArc(&Arc{})
+	scratchArc := &Arc{}
+	output := arc.Output.(int64)
+	result = make([]int, 0)
+
+	for {
+		if arc.IsFinal() {
+			finalOutput := output + arc.NextFinalOutput.(int64)
+			if finalOutput == targetOutput {
+				return result, nil
+			} else if finalOutput > targetOutput {
+				return nil, nil
+			}
+		}
+
+		if !targetHasArcs(arc) {
+			return nil, nil
+		}
+		if _, err = fst.readFirstRealTargetArc(arc.target, arc, in); err != nil {
+			return nil, err
+		}
+
+		if arc.bytesPerArc != 0 {
+			low, high, mid := 0, arc.numArcs-1, 0
+			exact := false
+			for low <= high {
+				mid = int(uint(low+high) >> 1)
+				in.setPosition(arc.posArcsStart)
+				in.skipBytes(arc.bytesPerArc * mid)
+				flags, err := in.ReadByte()
+				if err != nil {
+					return nil, err
+				}
+				if _, err = fst.readLabel(in); err != nil {
+					return nil, err
+				}
+				minArcOutput := output
+				if hasFlag(flags, FST_BIT_ARC_HAS_OUTPUT) {
+					arcOutput, err := fst.outputs.Read(in)
+					if err != nil {
+						return nil, err
+					}
+					minArcOutput += arcOutput.(int64)
+				}
+				if minArcOutput == targetOutput {
+					exact = true
+					break
+				} else if minArcOutput < targetOutput {
+					low = mid + 1
+				} else {
+					high = mid - 1
+				}
+			}
+
+			if high == -1 {
+				return nil, nil
+			} else if exact {
+				arc.arcIdx = mid - 1
+			} else {
+				arc.arcIdx = low - 2
+			}
+
+			if _, err = fst.readNextRealArc(arc, in); err != nil {
+				return nil, err
+			}
+			result = append(result, arc.Label)
+			output += arc.Output.(int64)
+		} else {
+			var prevArc *Arc
+			for {
+				// This is the min output we'd hit if we follow this arc:
+				minArcOutput := output + arc.Output.(int64)
+
+				if minArcOutput == targetOutput {
+					// Recurse on this arc:
+					output = minArcOutput
+					result = append(result, arc.Label)
+					break
+				} else if minArcOutput > targetOutput {
+					if prevArc == nil {
+						// Output doesn't exist
+						return nil, nil
+					}
+					// Recurse on previous arc:
+					arc.copyFrom(prevArc)
+					result = append(result, arc.Label)
+					output += arc.Output.(int64)
+					break
+				} else if arc.isLast() {
+					// Recurse on this arc:
+					output = minArcOutput
+					result = append(result, arc.Label)
+					break
+				} else {
+					// Read next arc in this node:
+					prevArc = scratchArc.copyFrom(arc)
+					if _, err = fst.readNextRealArc(arc, in); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+}
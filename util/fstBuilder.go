@@ -0,0 +1,468 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// util/fst/Builder.java
+
+/*
+Builds a minimal FST (maps an IntsRef term to an arbitrary output)
+from pre-sorted terms with outputs. The FST becomes an FSA if you use
+NoOutputs. The FST is written on-the-fly into a compact serialized
+format byte array, which can be saved to / loaded from a Directory or
+used directly for traversal. The FST is always finite (no cycles).
+
+NOTE: The algorithm is described at
+http://citeseerx.ist.psu.edu/viewdoc/summary?doi=10.1.1.24.3698
+
+Unlike the original, this port always shares suffixes and never
+writes nodes as fixed arrays of arcs, or arcs targeting the next
+node; all of these are optional in the format LoadFST() reads.
+*/
+type FSTBuilder struct {
+	dedupHash map[string]int64
+	fst       *FST
+	noOutput  interface{}
+
+	lastInput []int
+
+	// current "frontier"
+	frontier []*builderUnCompiledNode
+}
+
+// Instantiates an FST builder with suffix sharing enabled.
+func NewFSTBuilder(inputType InputType, outputs Outputs) *FSTBuilder {
+	fst := &FST{
+		inputType: inputType,
+		outputs:   outputs,
+		NO_OUTPUT: outputs.NoOutput(),
+		bytes:     newBytesStoreFromBits(15),
+		version:   FST_VERSION_VINT_TARGET,
+		startNode: -1,
+	}
+	// pad: ensure no node gets address 0 which is reserved to mean
+	// the stop state w/ no arcs
+	fst.bytes.WriteByte(0)
+
+	b := &FSTBuilder{
+		dedupHash: make(map[string]int64),
+		fst:       fst,
+		noOutput:  outputs.NoOutput(),
+		frontier:  make([]*builderUnCompiledNode, 10),
+	}
+	for i, _ := range b.frontier {
+		b.frontier[i] = newBuilderUnCompiledNode(b, i)
+	}
+	return b
+}
+
+func (b *FSTBuilder) isNoOutput(output interface{}) bool {
+	if v, ok := output.([]byte); ok {
+		return len(v) == 0
+	}
+	return output == b.noOutput
+}
+
+func (b *FSTBuilder) compileNode(nodeIn *builderUnCompiledNode) *builderCompiledNode {
+	var node int64
+	if nodeIn.numArcs == 0 {
+		node = b.fst.addNode(nodeIn)
+	} else {
+		key := nodeIn.signature()
+		var ok bool
+		if node, ok = b.dedupHash[key]; !ok {
+			node = b.fst.addNode(nodeIn)
+			b.dedupHash[key] = node
+		}
+	}
+	if node == -2 {
+		panic("assert fail")
+	}
+
+	nodeIn.clear()
+	return &builderCompiledNode{node}
+}
+
+func (b *FSTBuilder) freezeTail(prefixLenPlus1 int) {
+	downTo := prefixLenPlus1
+	if downTo < 1 {
+		downTo = 1
+	}
+	for idx := len(b.lastInput); idx >= downTo; idx-- {
+		node := b.frontier[idx]
+		parent := b.frontier[idx-1]
+
+		nextFinalOutput := node.output
+		// We "fake" the node as being final if it has no outgoing
+		// arcs; in theory we could leave it as non-final (the FST
+		// can represent this), but FSTEnum, Util, etc., have trouble
+		// w/ non-final dead-end states:
+		isFinal := node.isFinal || node.numArcs == 0
+
+		// this node makes it and we now compile it. first, compile
+		// any targets that were previously undecided:
+		parent.replaceLast(b.lastInput[idx-1], b.compileNode(node),
+			nextFinalOutput, isFinal)
+	}
+}
+
+/*
+Add the next input/output pair. The provided input must be sorted
+after the previous one according to IntsRef.compareTo; adding the
+same input twice is not supported. The input may be empty, in which
+case it must be the first one added.
+*/
+func (b *FSTBuilder) Add(input []int, output interface{}) {
+	// De-dup NO_OUTPUT since it must be a singleton:
+	if b.isNoOutput(output) {
+		output = b.noOutput
+	}
+
+	if b.lastInput != nil && compareInts(b.lastInput, input) >= 0 {
+		panic(fmt.Sprintf("inputs are added out of order lastInput=%v vs input=%v",
+			b.lastInput, input))
+	}
+
+	if len(input) == 0 {
+		// empty input: only allowed as first input. we have to
+		// special case this because the packed FST format cannot
+		// represent the empty input since 'finalness' is stored on
+		// the incoming arc, not on the node
+		b.frontier[0].isFinal = true
+		b.fst.emptyOutput = output
+		b.lastInput = []int{}
+		return
+	}
+
+	// compare shared prefix length
+	pos1, pos2 := 0, 0
+	pos1Stop := len(b.lastInput)
+	if len(input) < pos1Stop {
+		pos1Stop = len(input)
+	}
+	for pos1 < pos1Stop && b.lastInput[pos1] == input[pos2] {
+		pos1++
+		pos2++
+	}
+	prefixLenPlus1 := pos1 + 1
+
+	if len(b.frontier) < len(input)+1 {
+		for i := len(b.frontier); i < len(input)+1; i++ {
+			b.frontier = append(b.frontier, newBuilderUnCompiledNode(b, i))
+		}
+	}
+
+	// minimize/compile states from previous input's orphan'd suffix
+	b.freezeTail(prefixLenPlus1)
+
+	// init tail states for current input
+	for idx := prefixLenPlus1; idx <= len(input); idx++ {
+		b.frontier[idx-1].addArc(input[idx-1], b.frontier[idx])
+	}
+
+	lastNode := b.frontier[len(input)]
+	lastNode.isFinal = true
+	lastNode.output = b.noOutput
+
+	// push conflicting outputs forward, only as far as needed
+	for idx := 1; idx < prefixLenPlus1; idx++ {
+		node := b.frontier[idx]
+		parentNode := b.frontier[idx-1]
+
+		lastOutput := parentNode.lastOutput(input[idx-1])
+
+		var commonOutputPrefix, wordSuffix interface{}
+		if !b.isNoOutput(lastOutput) {
+			commonOutputPrefix = b.fst.outputs.Common(output, lastOutput)
+			wordSuffix = b.fst.outputs.Subtract(lastOutput, commonOutputPrefix)
+			parentNode.setLastOutput(input[idx-1], commonOutputPrefix)
+			node.prependOutput(wordSuffix)
+		} else {
+			commonOutputPrefix = b.noOutput
+		}
+
+		output = b.fst.outputs.Subtract(output, commonOutputPrefix)
+		if b.isNoOutput(output) {
+			output = b.noOutput
+		}
+	}
+
+	// this is the last input of its prefix, so it just takes the
+	// remaining output:
+	b.frontier[prefixLenPlus1-1].setLastOutput(input[prefixLenPlus1-1], output)
+
+	// save last input
+	b.lastInput = append(b.lastInput[:0], input...)
+}
+
+/*
+Returns final FST. NOTE: this will return nil if nothing is accepted
+by the FST.
+*/
+func (b *FSTBuilder) Finish() *FST {
+	root := b.frontier[0]
+
+	// minimize nodes in the last word's suffix
+	b.freezeTail(0)
+	if root.numArcs == 0 && b.fst.emptyOutput == nil {
+		return nil
+	}
+
+	b.fst.finish(b.compileNode(root).node)
+	return b.fst
+}
+
+func compareInts(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+// Converts the bytes of a term to the labels of an INPUT_TYPE_BYTE1
+// FST.
+func ToIntsRef(term []byte) []int {
+	ans := make([]int, len(term))
+	for i, v := range term {
+		ans[i] = int(v)
+	}
+	return ans
+}
+
+// util/fst/Builder.java/Arc
+
+// Expert: holds a pending (seen but not yet serialized) arc.
+type builderArc struct {
+	label           int // really an "unsigned" byte
+	target          builderNode
+	isFinal         bool
+	output          interface{}
+	nextFinalOutput interface{}
+}
+
+type builderNode interface {
+	isCompiled() bool
+}
+
+// util/fst/Builder.java/CompiledNode
+
+type builderCompiledNode struct {
+	node int64
+}
+
+func (n *builderCompiledNode) isCompiled() bool {
+	return true
+}
+
+// util/fst/Builder.java/UnCompiledNode
+
+// Expert: holds a pending (seen but not yet serialized) Node.
+type builderUnCompiledNode struct {
+	owner   *FSTBuilder
+	numArcs int
+	arcs    []*builderArc
+	// TODO: instead of recording isFinal/output on the node, maybe we
+	// should use -1 arc to mean "end" (like we do when reading the
+	// FST). Would simplify much code here...
+	output  interface{}
+	isFinal bool
+
+	// This node's depth, starting from the automaton root.
+	depth int
+}
+
+func newBuilderUnCompiledNode(owner *FSTBuilder, depth int) *builderUnCompiledNode {
+	return &builderUnCompiledNode{
+		owner:  owner,
+		arcs:   []*builderArc{new(builderArc)},
+		output: owner.noOutput,
+		depth:  depth,
+	}
+}
+
+func (n *builderUnCompiledNode) isCompiled() bool {
+	return false
+}
+
+func (n *builderUnCompiledNode) clear() {
+	n.numArcs = 0
+	n.isFinal = false
+	n.output = n.owner.noOutput
+	// We don't clear the depth here because it never changes for
+	// nodes on the frontier (even when reused).
+}
+
+func (n *builderUnCompiledNode) lastOutput(labelToMatch int) interface{} {
+	if n.numArcs <= 0 || n.arcs[n.numArcs-1].label != labelToMatch {
+		panic("assert fail")
+	}
+	return n.arcs[n.numArcs-1].output
+}
+
+func (n *builderUnCompiledNode) addArc(label int, target builderNode) {
+	if label < 0 || (n.numArcs > 0 && label <= n.arcs[n.numArcs-1].label) {
+		panic(fmt.Sprintf("arc[-1].label=%v new label=%v numArcs=%v",
+			n.arcs[n.numArcs-1].label, label, n.numArcs))
+	}
+	if n.numArcs == len(n.arcs) {
+		n.arcs = append(n.arcs, new(builderArc))
+	}
+	arc := n.arcs[n.numArcs]
+	n.numArcs++
+	arc.label = label
+	arc.target = target
+	arc.output = n.owner.noOutput
+	arc.nextFinalOutput = n.owner.noOutput
+	arc.isFinal = false
+}
+
+func (n *builderUnCompiledNode) replaceLast(labelToMatch int, target builderNode,
+	nextFinalOutput interface{}, isFinal bool) {
+	if n.numArcs <= 0 {
+		panic("assert fail")
+	}
+	arc := n.arcs[n.numArcs-1]
+	if arc.label != labelToMatch {
+		panic(fmt.Sprintf("arc.label=%v vs %v", arc.label, labelToMatch))
+	}
+	arc.target = target
+	arc.nextFinalOutput = nextFinalOutput
+	arc.isFinal = isFinal
+}
+
+func (n *builderUnCompiledNode) setLastOutput(labelToMatch int, newOutput interface{}) {
+	if n.numArcs <= 0 {
+		panic("assert fail")
+	}
+	arc := n.arcs[n.numArcs-1]
+	if arc.label != labelToMatch {
+		panic(fmt.Sprintf("arc.label=%v vs %v", arc.label, labelToMatch))
+	}
+	arc.output = newOutput
+}
+
+// pushes an output prefix forward onto all arcs
+func (n *builderUnCompiledNode) prependOutput(outputPrefix interface{}) {
+	outputs := n.owner.fst.outputs
+	for _, arc := range n.arcs[:n.numArcs] {
+		arc.output = outputs.Add(outputPrefix, arc.output)
+	}
+
+	if n.isFinal {
+		n.output = outputs.Add(outputPrefix, n.output)
+	}
+}
+
+/*
+Returns a key which is equal for two nodes iff they would be
+serialized the same way, so that a node already frozen into the FST
+can be shared as a suffix. All arcs' targets must be compiled.
+*/
+func (n *builderUnCompiledNode) signature() string {
+	var b bytes.Buffer
+	for _, arc := range n.arcs[:n.numArcs] {
+		fmt.Fprintf(&b, "%v:%v:%v:%v:%v;", arc.label, arc.target.(*builderCompiledNode).node,
+			arc.isFinal, arc.output, arc.nextFinalOutput)
+	}
+	return b.String()
+}
+
+// FST.java
+
+/*
+Serializes a compiled node into the byte store and returns its
+address, which is the position of its last byte as nodes are read in
+reverse.
+*/
+func (t *FST) addNode(nodeIn *builderUnCompiledNode) int64 {
+	if nodeIn.numArcs == 0 {
+		if nodeIn.isFinal {
+			return FST_FINAL_END_NODE
+		}
+		return FST_NON_FINAL_END_NODE
+	}
+
+	startAddress := t.bytes.getPosition()
+	lastArc := nodeIn.numArcs - 1
+	for arcIdx, arc := range nodeIn.arcs[:nodeIn.numArcs] {
+		target := arc.target.(*builderCompiledNode)
+		var flags byte
+		if arcIdx == lastArc {
+			flags |= FST_BIT_LAST_ARC
+		}
+
+		hasOutput := !nodeIn.owner.isNoOutput(arc.output)
+		hasFinalOutput := !nodeIn.owner.isNoOutput(arc.nextFinalOutput)
+		if arc.isFinal {
+			flags |= FST_BIT_FINAL_ARC
+			if hasFinalOutput {
+				flags |= FST_BIT_ARC_HAS_FINAL_OUTPUT
+			}
+		} else if hasFinalOutput {
+			panic("assert fail")
+		}
+
+		targetHasArcs := target.node > 0
+		if !targetHasArcs {
+			flags |= FST_BIT_STOP_NODE
+		}
+		if hasOutput {
+			flags |= FST_BIT_ARC_HAS_OUTPUT
+		}
+
+		t.bytes.WriteByte(flags)
+		t.writeLabel(t.bytes, arc.label)
+
+		if hasOutput {
+			t.outputs.Write(arc.output, t.bytes)
+			t.arcWithOutputCount++
+		}
+		if hasFinalOutput {
+			t.outputs.WriteFinalOutput(arc.nextFinalOutput, t.bytes)
+		}
+		if targetHasArcs {
+			t.bytes.WriteVLong(target.node)
+		}
+	}
+
+	thisNodeAddress := t.bytes.getPosition() - 1
+	if thisNodeAddress > startAddress {
+		t.bytes.reverse(startAddress, thisNodeAddress)
+	}
+
+	t.nodeCount++
+	t.arcCount += int64(nodeIn.numArcs)
+	return thisNodeAddress
+}
+
+func (t *FST) writeLabel(out DataOutput, v int) {
+	switch t.inputType {
+	case INPUT_TYPE_BYTE1:
+		if v < 0 || v > 255 {
+			panic(fmt.Sprintf("v=%v", v))
+		}
+		out.WriteByte(byte(v))
+	case INPUT_TYPE_BYTE2:
+		if v < 0 || v > 65535 {
+			panic(fmt.Sprintf("v=%v", v))
+		}
+		out.WriteShort(int16(v))
+	default:
+		out.WriteVInt(int32(v))
+	}
+}
+
+func (t *FST) finish(startNode int64) {
+	if t.startNode != -1 {
+		panic("already finished")
+	}
+	if startNode == FST_FINAL_END_NODE && t.emptyOutput != nil {
+		startNode = 0
+	}
+	t.startNode = startNode
+	t.cacheRootArcs()
+}
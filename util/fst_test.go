@@ -0,0 +1,75 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestFSTBuilder(t *testing.T) {
+	terms := []string{"", "a", "ab", "abc", "abd", "b", "bcd", "cat", "dog", "dogs", "hat", "z\xff"}
+	for i := 0; i < 2000; i++ {
+		terms = append(terms, fmt.Sprintf("term%05d", i*7))
+	}
+	sort.Strings(terms)
+
+	builder := NewFSTBuilder(INPUT_TYPE_BYTE1, PositiveIntOutputsSingleton(true))
+	for ord, term := range terms {
+		builder.Add(ToIntsRef([]byte(term)), int64(ord))
+	}
+	fst := builder.Finish()
+	if fst == nil {
+		t.Fatal("FST should not be empty")
+	}
+
+	// both the built and the loaded FST map terms to ords and back
+	w := new(testDataWriter)
+	if err := fst.Save(NewDataOutput(w)); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFST(&DataInputImpl{&testDataInput{Reader: bytes.NewReader(w.Bytes())}},
+		PositiveIntOutputsSingleton(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []*FST{fst, loaded} {
+		for ord, term := range terms {
+			output, err := GetFSTOutput(f, []byte(term))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != int64(ord) {
+				t.Fatalf("Expected %v for '%v', but was %v", ord, term, output)
+			}
+			input, err := GetFSTByOutput(f, int64(ord))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := string(toBytes(input)); s != term {
+				t.Fatalf("Expected '%v' for %v, but was '%v'", term, ord, s)
+			}
+		}
+		for _, term := range []string{"abcd", "c", "do", "term00001", "zz"} {
+			if output, err := GetFSTOutput(f, []byte(term)); output != nil || err != nil {
+				t.Errorf("'%v' should not be accepted, but was %v (%v)", term, output, err)
+			}
+		}
+	}
+	if loaded.NodeCount() != fst.NodeCount() || loaded.ArcCount() != fst.ArcCount() {
+		t.Errorf("Expected %v nodes and %v arcs, but was %v and %v",
+			fst.NodeCount(), fst.ArcCount(), loaded.NodeCount(), loaded.ArcCount())
+	}
+
+	if NewFSTBuilder(INPUT_TYPE_BYTE1, PositiveIntOutputsSingleton(true)).Finish() != nil {
+		t.Error("FST accepting nothing should be nil")
+	}
+}
+
+func toBytes(labels []int) []byte {
+	ans := make([]byte, len(labels))
+	for i, v := range labels {
+		ans[i] = byte(v)
+	}
+	return ans
+}
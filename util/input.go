@@ -48,8 +48,9 @@ func (in *DataInputImpl) ReadBytesBuffered(buf []byte, useBuffer bool) error {
 }
 
 func (in *DataInputImpl) ReadShort() (n int16, err error) {
-	if b1, err := in.ReadByte(); err == nil {
-		if b2, err := in.ReadByte(); err == nil {
+	var b1, b2 byte
+	if b1, err = in.ReadByte(); err == nil {
+		if b2, err = in.ReadByte(); err == nil {
 			return (int16(b1) << 8) | int16(b2), nil
 		}
 	}
@@ -57,10 +58,11 @@ func (in *DataInputImpl) ReadShort() (n int16, err error) {
 }
 
 func (in *DataInputImpl) ReadInt() (n int32, err error) {
-	if b1, err := in.ReadByte(); err == nil {
-		if b2, err := in.ReadByte(); err == nil {
-			if b3, err := in.ReadByte(); err == nil {
-				if b4, err := in.ReadByte(); err == nil {
+	var b1, b2, b3, b4 byte
+	if b1, err = in.ReadByte(); err == nil {
+		if b2, err = in.ReadByte(); err == nil {
+			if b3, err = in.ReadByte(); err == nil {
+				if b4, err = in.ReadByte(); err == nil {
 					return (int32(b1) << 24) | (int32(b2) << 16) | (int32(b3) << 8) | int32(b4), nil
 				}
 			}
@@ -70,7 +72,8 @@ func (in *DataInputImpl) ReadInt() (n int32, err error) {
 }
 
 func (in *DataInputImpl) ReadVInt() (n int32, err error) {
-	if b, err := in.ReadByte(); err == nil {
+	var b byte
+	if b, err = in.ReadByte(); err == nil {
 		n = int32(b) & 0x7F
 		if b < 128 {
 			return n, nil
@@ -114,11 +117,12 @@ func (in *DataInputImpl) ReadLong() (n int64, err error) {
 	if err != nil {
 		return 0, err
 	}
-	return (int64(d1) << 32) | int64(d2), nil
+	return (int64(d1) << 32) | int64(uint32(d2)), nil
 }
 
 func (in *DataInputImpl) ReadVLong() (n int64, err error) {
-	if b, err := in.ReadByte(); err == nil {
+	var b byte
+	if b, err = in.ReadByte(); err == nil {
 		n = int64(b & 0x7F)
 		if b < 128 {
 			return n, nil
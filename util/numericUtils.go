@@ -0,0 +1,315 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// util/NumericUtils.java
+
+/*
+This is a helper for encoding numeric values into sortable byte
+sequences, the so called "prefix coded" terms, for indexing them as
+trie terms: each value is indexed at its full precision, and at lower
+precisions obtained by stripping the lowest precisionStep bits of the
+previous one. A range query can then be rewritten to a small number
+of terms of different precisions, see SplitLongRange().
+
+To encode, the value is shifted right by the given shift, and the
+remaining bits are split into 7 bit units, each written to one byte.
+The first byte records the type and the shift of the value, so terms
+of a lower precision sort before the full precision terms, and values
+of different types don't collide. Before encoding, the sign bit of
+the value is flipped, so the byte-wise order of the terms is the
+numeric order of the values.
+
+Floating point values are first converted to sortable integers with
+FloatToSortableInt() and DoubleToSortableLong().
+
+The encoding is the same as Lucene's, so the terms are compatible
+with indexes written by Java Lucene 4.x.
+*/
+
+const (
+	// The default precision step used by IntField, FloatField,
+	// LongField, DoubleField, NumericTokenStream and the numeric range
+	// queries.
+	NUMERIC_PRECISION_STEP_DEFAULT = 4
+
+	// Longs are stored at lower precision by shifting off lower bits.
+	// The shift is stored as SHIFT_START_LONG+shift in the first byte.
+	NUMERIC_SHIFT_START_LONG = 0x20
+	// The maximum term length (used for []byte buffer size) for
+	// encoding long values.
+	NUMERIC_BUF_SIZE_LONG = 63/7 + 2
+
+	// Integers are stored at lower precision by shifting off lower
+	// bits. The shift is stored as SHIFT_START_INT+shift in the first
+	// byte.
+	NUMERIC_SHIFT_START_INT = 0x60
+	// The maximum term length (used for []byte buffer size) for
+	// encoding int values.
+	NUMERIC_BUF_SIZE_INT = 31/7 + 2
+)
+
+/*
+Returns prefix coded bits after reducing the precision by shift bits.
+This method is used by NumericTokenStream. It panics if shift is
+not between 0 and 63.
+*/
+func LongToPrefixCoded(val int64, shift int) []byte {
+	if shift&^0x3f != 0 { // ensure shift is 0..63
+		panic("Illegal shift value, must be 0..63")
+	}
+	nChars := (((63 - shift) * 37) >> 8) + 1 // i/7 is the same as (i*37)>>8 for i in 0..63
+	ans := make([]byte, nChars+1)
+	ans[0] = byte(NUMERIC_SHIFT_START_LONG + shift)
+	sortableBits := uint64(val) ^ 0x8000000000000000
+	sortableBits >>= uint(shift)
+	for ; nChars > 0; nChars-- {
+		// Store 7 bits per byte for compatibility with UTF-8 encoding
+		// of terms
+		ans[nChars] = byte(sortableBits & 0x7f)
+		sortableBits >>= 7
+	}
+	return ans
+}
+
+/*
+Returns prefix coded bits after reducing the precision by shift bits.
+This method is used by NumericTokenStream. It panics if shift is
+not between 0 and 31.
+*/
+func IntToPrefixCoded(val int32, shift int) []byte {
+	if shift&^0x1f != 0 { // ensure shift is 0..31
+		panic("Illegal shift value, must be 0..31")
+	}
+	nChars := (((31 - shift) * 37) >> 8) + 1 // i/7 is the same as (i*37)>>8 for i in 0..63
+	ans := make([]byte, nChars+1)
+	ans[0] = byte(NUMERIC_SHIFT_START_INT + shift)
+	sortableBits := uint32(val) ^ 0x80000000
+	sortableBits >>= uint(shift)
+	for ; nChars > 0; nChars-- {
+		// Store 7 bits per byte for compatibility with UTF-8 encoding
+		// of terms
+		ans[nChars] = byte(sortableBits & 0x7f)
+		sortableBits >>= 7
+	}
+	return ans
+}
+
+/*
+Returns the shift value from a prefix encoded long. It returns an
+error if the supplied bytes are not correctly prefix encoded.
+*/
+func PrefixCodedLongShift(val []byte) (int, error) {
+	if len(val) == 0 {
+		return 0, errors.New("Empty prefixCoded bytes")
+	}
+	shift := int(val[0]) - NUMERIC_SHIFT_START_LONG
+	if shift > 63 || shift < 0 {
+		return 0, errors.New(fmt.Sprintf(
+			"Invalid shift value (%v) in prefixCoded bytes (is encoded value really an INT?)", shift))
+	}
+	return shift, nil
+}
+
+/*
+Returns the shift value from a prefix encoded int. It returns an
+error if the supplied bytes are not correctly prefix encoded.
+*/
+func PrefixCodedIntShift(val []byte) (int, error) {
+	if len(val) == 0 {
+		return 0, errors.New("Empty prefixCoded bytes")
+	}
+	shift := int(val[0]) - NUMERIC_SHIFT_START_INT
+	if shift > 31 || shift < 0 {
+		return 0, errors.New(fmt.Sprintf(
+			"Invalid shift value (%v) in prefixCoded bytes (is encoded value really a LONG?)", shift))
+	}
+	return shift, nil
+}
+
+/*
+Returns a long from prefixCoded bytes. Rightmost bits will be zero
+for lower precision codes. This method can be used to decode a term's
+value. It returns an error if the supplied bytes are not correctly
+prefix encoded.
+*/
+func PrefixCodedToLong(val []byte) (int64, error) {
+	shift, err := PrefixCodedLongShift(val)
+	if err != nil {
+		return 0, err
+	}
+	var sortableBits uint64
+	for i, b := range val[1:] {
+		if b&0x80 != 0 {
+			return 0, errors.New(fmt.Sprintf(
+				"Invalid prefixCoded numerical value representation (byte %x at position %v is invalid)",
+				b, i+1))
+		}
+		sortableBits = sortableBits<<7 | uint64(b)
+	}
+	return int64((sortableBits << uint(shift)) ^ 0x8000000000000000), nil
+}
+
+/*
+Returns an int from prefixCoded bytes. Rightmost bits will be zero
+for lower precision codes. This method can be used to decode a term's
+value. It returns an error if the supplied bytes are not correctly
+prefix encoded.
+*/
+func PrefixCodedToInt(val []byte) (int32, error) {
+	shift, err := PrefixCodedIntShift(val)
+	if err != nil {
+		return 0, err
+	}
+	var sortableBits uint32
+	for i, b := range val[1:] {
+		if b&0x80 != 0 {
+			return 0, errors.New(fmt.Sprintf(
+				"Invalid prefixCoded numerical value representation (byte %x at position %v is invalid)",
+				b, i+1))
+		}
+		sortableBits = sortableBits<<7 | uint32(b)
+	}
+	return int32((sortableBits << uint(shift)) ^ 0x80000000), nil
+}
+
+/*
+Converts a float64 value to a sortable signed int64. The value is
+converted by getting their IEEE 754 floating-point "double format"
+bit layout and then some bits are swapped, to be able to compare the
+result as int64. By this the precision is not reduced, but the value
+can easily used as an int64. The sort order (including NaN) is
+defined by math.Float64bits(); NaN is greater than positive infinity.
+*/
+func DoubleToSortableLong(val float64) int64 {
+	f := int64(math.Float64bits(val))
+	if f < 0 {
+		f ^= 0x7fffffffffffffff
+	}
+	return f
+}
+
+// Converts a sortable int64 back to a float64.
+func SortableLongToDouble(val int64) float64 {
+	if val < 0 {
+		val ^= 0x7fffffffffffffff
+	}
+	return math.Float64frombits(uint64(val))
+}
+
+/*
+Converts a float32 value to a sortable signed int32. The value is
+converted by getting their IEEE 754 floating-point "float format" bit
+layout and then some bits are swapped, to be able to compare the
+result as int32. By this the precision is not reduced, but the value
+can easily used as an int32. The sort order (including NaN) is
+defined by math.Float32bits(); NaN is greater than positive infinity.
+*/
+func FloatToSortableInt(val float32) int32 {
+	f := int32(math.Float32bits(val))
+	if f < 0 {
+		f ^= 0x7fffffff
+	}
+	return f
+}
+
+// Converts a sortable int32 back to a float32.
+func SortableIntToFloat(val int32) float32 {
+	if val < 0 {
+		val ^= 0x7fffffff
+	}
+	return math.Float32frombits(uint32(val))
+}
+
+/*
+Splits a long range recursively. You may implement a builder that
+adds clauses to a BooleanQuery for each call to its addRange()
+method, which receives the prefix coded lower and upper bounds of
+each sub-range, both inclusive.
+
+This method is used by NumericRangeQuery. It panics if precisionStep
+is less than 1.
+*/
+func SplitLongRange(addRange func(minPrefixCoded, maxPrefixCoded []byte),
+	precisionStep int, minBound, maxBound int64) {
+	splitRange(func(min, max int64, shift int) {
+		addRange(LongToPrefixCoded(min, shift), LongToPrefixCoded(max, shift))
+	}, 64, precisionStep, minBound, maxBound)
+}
+
+/*
+Splits an int range recursively. You may implement a builder that
+adds clauses to a BooleanQuery for each call to its addRange()
+method, which receives the prefix coded lower and upper bounds of
+each sub-range, both inclusive.
+
+This method is used by NumericRangeQuery. It panics if precisionStep
+is less than 1.
+*/
+func SplitIntRange(addRange func(minPrefixCoded, maxPrefixCoded []byte),
+	precisionStep int, minBound, maxBound int32) {
+	splitRange(func(min, max int64, shift int) {
+		addRange(IntToPrefixCoded(int32(min), shift), IntToPrefixCoded(int32(max), shift))
+	}, 32, precisionStep, int64(minBound), int64(maxBound))
+}
+
+// This helper does the splitting for both 32 and 64 bit.
+func splitRange(addRange func(min, max int64, shift int), valSize, precisionStep int,
+	minBound, maxBound int64) {
+	if precisionStep < 1 {
+		panic("precisionStep must be >=1")
+	}
+	if minBound > maxBound {
+		return
+	}
+	for shift := 0; ; shift += precisionStep {
+		// calculate new bounds for inner precision
+		diff := int64(1) << uint(shift+precisionStep)
+		mask := ((int64(1) << uint(precisionStep)) - 1) << uint(shift)
+		hasLower := minBound&mask != 0
+		hasUpper := maxBound&mask != mask
+		nextMinBound, nextMaxBound := minBound, maxBound
+		if hasLower {
+			nextMinBound += diff
+		}
+		if hasUpper {
+			nextMaxBound -= diff
+		}
+		nextMinBound &^= mask
+		nextMaxBound &^= mask
+		lowerWrapped := nextMinBound < minBound
+		upperWrapped := nextMaxBound > maxBound
+
+		if shift+precisionStep >= valSize || nextMinBound > nextMaxBound ||
+			lowerWrapped || upperWrapped {
+			// We are in the lowest precision or the next precision is
+			// not available.
+			addShiftedRange(addRange, minBound, maxBound, shift)
+			// exit the split recursion loop
+			return
+		}
+
+		if hasLower {
+			addShiftedRange(addRange, minBound, minBound|mask, shift)
+		}
+		if hasUpper {
+			addShiftedRange(addRange, maxBound&^mask, maxBound, shift)
+		}
+
+		// recurse to next precision
+		minBound, maxBound = nextMinBound, nextMaxBound
+	}
+}
+
+func addShiftedRange(addRange func(min, max int64, shift int), minBound, maxBound int64, shift int) {
+	// for the max bound set all lower bits (that were shifted away):
+	// this is important for testing or other usages of the splitted
+	// range (e.g. to reconstruct the full range). The prefixEncoding
+	// will remove the bits anyway, so they do not hurt!
+	maxBound |= (int64(1) << uint(shift)) - 1
+	addRange(minBound, maxBound, shift)
+}
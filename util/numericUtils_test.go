@@ -0,0 +1,130 @@
+package util
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestLongPrefixCoded(t *testing.T) {
+	values := []int64{math.MinInt64, -1 << 40, -1, 0, 1, 1 << 40, math.MaxInt64}
+	for shift := 0; shift < 64; shift++ {
+		var prev []byte
+		for _, v := range values {
+			encoded := LongToPrefixCoded(v, shift)
+			if prev != nil && bytes.Compare(prev, encoded) > 0 {
+				t.Errorf("Encoded values should sort as %v at shift %v", v, shift)
+			}
+			prev = encoded
+			if s, err := PrefixCodedLongShift(encoded); err != nil || s != shift {
+				t.Errorf("Expected shift %v, but was %v (%v)", shift, s, err)
+			}
+			decoded, err := PrefixCodedToLong(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := v >> uint(shift) << uint(shift); decoded != expected {
+				t.Errorf("Expected %v at shift %v, but was %v", expected, shift, decoded)
+			}
+		}
+	}
+	if _, err := PrefixCodedToLong(IntToPrefixCoded(1, 0)); err == nil {
+		t.Error("Should not decode an int as a long")
+	}
+}
+
+func TestIntPrefixCoded(t *testing.T) {
+	values := []int32{math.MinInt32, -1 << 20, -1, 0, 1, 1 << 20, math.MaxInt32}
+	for shift := 0; shift < 32; shift++ {
+		var prev []byte
+		for _, v := range values {
+			encoded := IntToPrefixCoded(v, shift)
+			if prev != nil && bytes.Compare(prev, encoded) > 0 {
+				t.Errorf("Encoded values should sort as %v at shift %v", v, shift)
+			}
+			prev = encoded
+			decoded, err := PrefixCodedToInt(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := v >> uint(shift) << uint(shift); decoded != expected {
+				t.Errorf("Expected %v at shift %v, but was %v", expected, shift, decoded)
+			}
+		}
+	}
+	if _, err := PrefixCodedToInt(LongToPrefixCoded(1, 0)); err == nil {
+		t.Error("Should not decode a long as an int")
+	}
+}
+
+func TestSortableDouble(t *testing.T) {
+	values := []float64{math.Inf(-1), -2.5, -math.SmallestNonzeroFloat64, 0,
+		math.SmallestNonzeroFloat64, 1, math.MaxFloat64, math.Inf(1), math.NaN()}
+	for i, v := range values {
+		sortable := DoubleToSortableLong(v)
+		if i > 0 && sortable <= DoubleToSortableLong(values[i-1]) {
+			t.Errorf("Sortable value of %v should be larger than of %v", v, values[i-1])
+		}
+		if back := SortableLongToDouble(sortable); back != v && !math.IsNaN(v) {
+			t.Errorf("Expected %v, but was %v", v, back)
+		}
+	}
+	floats := []float32{float32(math.Inf(-1)), -2.5, 0, 1, math.MaxFloat32, float32(math.Inf(1))}
+	for i, v := range floats {
+		sortable := FloatToSortableInt(v)
+		if i > 0 && sortable <= FloatToSortableInt(floats[i-1]) {
+			t.Errorf("Sortable value of %v should be larger than of %v", v, floats[i-1])
+		}
+		if back := SortableIntToFloat(sortable); back != v {
+			t.Errorf("Expected %v, but was %v", v, back)
+		}
+	}
+}
+
+func TestSplitRange(t *testing.T) {
+	for _, v := range []struct {
+		precisionStep int
+		lower, upper  int64
+	}{
+		{1, -7, 13}, {2, -1000, 1000}, {4, 0, 0xffff}, {4, 3, 5}, {8, -1 << 40, 1 << 40},
+		{4, math.MinInt64, math.MaxInt64}, {64, -5, 5}, {4, 5, 3},
+	} {
+		// the ranges must be adjacent, and cover exactly [lower, upper]
+		next, done := v.lower, false
+		SplitLongRange(func(minPrefixCoded, maxPrefixCoded []byte) {
+			shift, _ := PrefixCodedLongShift(minPrefixCoded)
+			min, _ := PrefixCodedToLong(minPrefixCoded)
+			max, _ := PrefixCodedToLong(maxPrefixCoded)
+			max |= (int64(1) << uint(shift)) - 1
+			if done || min > max {
+				t.Errorf("Unexpected range [%v,%v] for %v", min, max, v)
+			}
+			// ranges are reported from both ends to the middle
+			switch {
+			case min == next:
+				next = max + 1
+			case max == v.upper:
+				v.upper = min - 1
+			default:
+				t.Errorf("Range [%v,%v] is not adjacent for %v", min, max, v)
+			}
+			done = next-1 == v.upper
+		}, v.precisionStep, v.lower, v.upper)
+		if v.lower <= v.upper && !done {
+			t.Errorf("Ranges should cover %v", v)
+		}
+	}
+
+	var count int
+	SplitIntRange(func(minPrefixCoded, maxPrefixCoded []byte) {
+		min, _ := PrefixCodedToInt(minPrefixCoded)
+		max, _ := PrefixCodedToInt(maxPrefixCoded)
+		if min > max {
+			t.Errorf("Unexpected range [%v,%v]", min, max)
+		}
+		count++
+	}, 4, math.MinInt32, math.MaxInt32)
+	if count != 1 {
+		t.Errorf("Full range should be a single range, but was %v", count)
+	}
+}
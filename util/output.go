@@ -2,16 +2,106 @@ package util
 
 import ()
 
-type DataOutput struct {
-	WriteByte  func(b byte) error
-	WriteBytes func(buf []byte) error
+type DataOutput interface {
+	WriteByte(b byte) error
+	WriteBytes(buf []byte) error
+	WriteShort(i int16) error
+	WriteInt(i int32) error
+	WriteVInt(i int32) error
+	WriteLong(i int64) error
+	WriteVLong(i int64) error
+	WriteString(s string) error
+	WriteStringStringMap(m map[string]string) error
+	WriteStringSet(m map[string]bool) error
+	CopyBytes(input DataInput, numBytes int64) error
+}
+
+type DataWriter interface {
+	/* Writes a single byte. */
+	WriteByte(b byte) error
+	/* Writes an array of bytes. */
+	WriteBytes(buf []byte) error
+}
 
+type DataOutputImpl struct {
+	DataWriter
 	copyBuffer []byte
 }
 
+func NewDataOutput(w DataWriter) *DataOutputImpl {
+	return &DataOutputImpl{DataWriter: w}
+}
+
+func (out *DataOutputImpl) WriteShort(i int16) error {
+	return out.WriteBytes([]byte{byte(i >> 8), byte(i)})
+}
+
+func (out *DataOutputImpl) WriteInt(i int32) error {
+	return out.WriteBytes([]byte{byte(i >> 24), byte(i >> 16), byte(i >> 8), byte(i)})
+}
+
+func (out *DataOutputImpl) WriteVInt(i int32) error {
+	return out.writeVarBytes(uint64(uint32(i)))
+}
+
+func (out *DataOutputImpl) WriteLong(i int64) error {
+	if err := out.WriteInt(int32(i >> 32)); err != nil {
+		return err
+	}
+	return out.WriteInt(int32(i))
+}
+
+func (out *DataOutputImpl) WriteVLong(i int64) error {
+	// assert i >= 0
+	return out.writeVarBytes(uint64(i))
+}
+
+func (out *DataOutputImpl) writeVarBytes(n uint64) error {
+	buf := make([]byte, 0, 10)
+	for n&^0x7F != 0 {
+		buf = append(buf, byte(n&0x7F)|0x80)
+		n >>= 7
+	}
+	return out.WriteBytes(append(buf, byte(n)))
+}
+
+func (out *DataOutputImpl) WriteString(s string) error {
+	if err := out.WriteVInt(int32(len(s))); err != nil {
+		return err
+	}
+	return out.WriteBytes([]byte(s))
+}
+
+func (out *DataOutputImpl) WriteStringStringMap(m map[string]string) error {
+	if err := out.WriteInt(int32(len(m))); err != nil {
+		return err
+	}
+	for k, v := range m {
+		if err := out.WriteString(k); err != nil {
+			return err
+		}
+		if err := out.WriteString(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (out *DataOutputImpl) WriteStringSet(m map[string]bool) error {
+	if err := out.WriteInt(int32(len(m))); err != nil {
+		return err
+	}
+	for k, _ := range m {
+		if err := out.WriteString(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 const DATA_OUTPUT_COPY_BUFFER_SIZE = 16384
 
-func (out *DataOutput) CopyBytes(input DataInput, numBytes int64) error {
+func (out *DataOutputImpl) CopyBytes(input DataInput, numBytes int64) error {
 	// assert numBytes >= 0
 	left := numBytes
 	if out.copyBuffer == nil {
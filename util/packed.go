@@ -22,6 +22,21 @@ func CheckVersion(version int32) {
 	}
 }
 
+/*
+Returns how many bits are required to hold values up to and including
+maxValue.
+*/
+func BitsRequired(maxValue int64) uint32 {
+	if maxValue < 0 {
+		panic(fmt.Sprintf("maxValue must be non-negative (got: %v)", maxValue))
+	}
+	bits := uint32(1)
+	for maxValue>>bits != 0 {
+		bits++
+	}
+	return bits
+}
+
 type PackedFormat int
 
 const (
@@ -29,6 +44,17 @@ const (
 	PACKED_SINGLE_BLOCK = 1
 )
 
+// Tests whether the provided number of bits per value is supported by
+// the format.
+func (f PackedFormat) IsSupported(bitsPerValue uint32) bool {
+	switch int(f) {
+	case PACKED_SINGLE_BLOCK:
+		return bitsPerValue >= 1 && bitsPerValue <= 32 &&
+			packedSingleBlockBulkOps[bitsPerValue-1] != nil
+	}
+	return bitsPerValue >= 1 && bitsPerValue <= 64
+}
+
 func (f PackedFormat) ByteCount(packedIntsVersion, valueCount int32, bitsPerValue uint32) int64 {
 	switch int(f) {
 	case PACKED:
@@ -60,8 +86,30 @@ func (f PackedFormat) longCount(packedIntsVersion, valueCount int32, bitsPerValu
 type PackedIntsEncoder interface {
 }
 
+/*
+A decoder for packed integers.
+*/
 type PackedIntsDecoder interface {
+	/*
+		The number of values that can be stored in ByteBlockCount() byte
+		blocks.
+	*/
 	ByteValueCount() int
+	/*
+		The minimum number of byte blocks to encode in a single
+		iteration, when using byte encoding.
+	*/
+	ByteBlockCount() int
+	/*
+		Read iterations * ByteBlockCount() blocks from blocks, decode
+		them and write iterations * ByteValueCount() values into values.
+	*/
+	DecodeByteToInt(blocks []byte, values []int32, iterations int)
+	/*
+		Read iterations * ByteBlockCount() blocks from blocks, decode
+		them and write iterations * ByteValueCount() values into values.
+	*/
+	DecodeByteToLong(blocks []byte, values []int64, iterations int)
 }
 
 func GetPackedIntsEncoder(format PackedFormat, version int32, bitsPerValue uint32) PackedIntsEncoder {
@@ -150,6 +198,23 @@ func (p PackedIntsReaderImpl) Size() int32 {
 type PackedIntsMutableImpl struct {
 }
 
+// A PackedIntsReader which has all its values equal to 0 (bitsPerValue = 0).
+type NullReader struct {
+	valueCount int32
+}
+
+func newNullReader(valueCount int32) *NullReader {
+	return &NullReader{valueCount}
+}
+
+func (r *NullReader) Get(index int32) int64 {
+	return 0
+}
+
+func (r *NullReader) Size() int32 {
+	return r.valueCount
+}
+
 type Direct8 struct {
 	PackedIntsReaderImpl
 	values []byte
@@ -210,7 +275,7 @@ func newDirect16FromInput(version int32, in DataInput, valueCount int32) (r Pack
 }
 
 func (d *Direct16) Get(index int32) int64 {
-	return int64(d.values[index])
+	return int64(uint16(d.values[index]))
 }
 
 type Direct32 struct {
@@ -244,7 +309,7 @@ func newDirect32FromInput(version int32, in DataInput, valueCount int32) (r Pack
 }
 
 func (d *Direct32) Get(index int32) int64 {
-	return int64(d.values[index])
+	return int64(uint32(d.values[index]))
 }
 
 type Direct64 struct {
@@ -0,0 +1,101 @@
+package util
+
+import (
+	"fmt"
+)
+
+// PackedWriter.java
+
+/*
+Packs high order byte first, to match IndexOutput.WriteInt/Long/Short
+byte order. Values are written as one continuous bit stream, which is
+what the PACKED format of the current (byte aligned) version expects
+when it is read back with NewPackedReaderNoHeader().
+*/
+type PackedWriter struct {
+	out          DataOutput
+	format       PackedFormat
+	valueCount   int32
+	bitsPerValue uint32
+	written      int32
+	finished     bool
+
+	// pending bits of the current byte
+	current     byte
+	currentBits uint32
+}
+
+/*
+Expert: create a writer which does not write any header. The
+metadata (format, valueCount and bitsPerValue) must be stored
+elsewhere so that the values can be decoded by
+NewPackedReaderNoHeader().
+*/
+func NewPackedWriterNoHeader(out DataOutput, format PackedFormat, valueCount int32, bitsPerValue uint32) *PackedWriter {
+	if format != PACKED {
+		panic("not implemented yet")
+	}
+	if !format.IsSupported(bitsPerValue) {
+		panic(fmt.Sprintf("unsupported bitsPerValue: %v", bitsPerValue))
+	}
+	return &PackedWriter{out: out, format: format, valueCount: valueCount, bitsPerValue: bitsPerValue}
+}
+
+// Add a value to the stream.
+func (w *PackedWriter) Add(v int64) error {
+	if w.bitsPerValue < 64 && uint64(v)>>w.bitsPerValue != 0 {
+		panic(fmt.Sprintf("value %v does not fit in %v bits", v, w.bitsPerValue))
+	}
+	if w.finished {
+		panic("assert fail")
+	}
+	if w.valueCount != -1 && w.written >= w.valueCount {
+		panic("Writing past end of stream")
+	}
+	for remaining := w.bitsPerValue; remaining > 0; {
+		n := 8 - w.currentBits
+		if remaining < n {
+			n = remaining
+		}
+		remaining -= n
+		w.current |= byte((uint64(v)>>remaining)&(1<<n-1)) << (8 - w.currentBits - n)
+		if w.currentBits += n; w.currentBits == 8 {
+			if err := w.out.WriteByte(w.current); err != nil {
+				return err
+			}
+			w.current, w.currentBits = 0, 0
+		}
+	}
+	w.written++
+	return nil
+}
+
+/*
+Perform end-of-stream operations: pad the stream with zeros up to
+valueCount and flush the last partial byte.
+*/
+func (w *PackedWriter) Finish() error {
+	if w.finished {
+		panic("assert fail")
+	}
+	if w.valueCount != -1 {
+		for w.written < w.valueCount {
+			if err := w.Add(0); err != nil {
+				return err
+			}
+		}
+	}
+	if w.currentBits > 0 {
+		if err := w.out.WriteByte(w.current); err != nil {
+			return err
+		}
+		w.current, w.currentBits = 0, 0
+	}
+	w.finished = true
+	return nil
+}
+
+// Returns the number of values which have been added.
+func (w *PackedWriter) Ord() int32 {
+	return w.written - 1
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"math/rand"
@@ -31,3 +32,136 @@ func TestByteCount(t *testing.T) {
 		}
 	}
 }
+
+func TestDecodeByteToInt(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for j := 0; j <= 1; j++ {
+		format := PackedFormat(j)
+		for bpv := uint32(1); bpv <= 32; bpv++ {
+			if !format.IsSupported(bpv) {
+				continue
+			}
+			decoder := GetPackedIntsDecoder(format, PACKED_VERSION_CURRENT, bpv)
+			iterations := 3
+			values := make([]int64, iterations*decoder.ByteValueCount())
+			for i := range values {
+				values[i] = r.Int63n(int64(1) << bpv)
+			}
+
+			// encode
+			blocks := make([]byte, iterations*decoder.ByteBlockCount())
+			if format == PACKED {
+				// values are written as a big-endian bit stream
+				for i, v := range values {
+					for b := uint32(0); b < bpv; b++ {
+						if v&(int64(1)<<(bpv-1-b)) != 0 {
+							bit := uint32(i)*bpv + b
+							blocks[bit/8] |= 1 << (7 - bit%8)
+						}
+					}
+				}
+			} else {
+				// each big-endian long holds the values from the lowest bits
+				valueCount := 64 / int(bpv)
+				for i, v := range values {
+					block, k := i/valueCount, uint32(i%valueCount)*bpv
+					for b := uint32(0); b < bpv; b++ {
+						if v&(int64(1)<<b) != 0 {
+							bit := k + b
+							blocks[8*block+7-int(bit/8)] |= 1 << (bit % 8)
+						}
+					}
+				}
+			}
+
+			decoded := make([]int32, len(values))
+			decoder.DecodeByteToInt(blocks, decoded, iterations)
+			for i, v := range values {
+				if decoded[i] != int32(v) {
+					t.Fatalf("format=%v bpv=%v: expected %v at %v, but was %v", format, bpv, v, i, decoded[i])
+				}
+			}
+
+			decodedLongs := make([]int64, len(values))
+			decoder.DecodeByteToLong(blocks, decodedLongs, iterations)
+			for i, v := range values {
+				if decodedLongs[i] != v {
+					t.Fatalf("format=%v bpv=%v: expected %v at %v, but was %v", format, bpv, v, i, decodedLongs[i])
+				}
+			}
+		}
+	}
+}
+
+type testDataWriter struct {
+	bytes.Buffer
+}
+
+func (w *testDataWriter) WriteBytes(buf []byte) error {
+	_, err := w.Write(buf)
+	return err
+}
+
+func TestPackedWriter(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for bpv := uint32(1); bpv <= 32; bpv++ {
+		decoder := GetPackedIntsDecoder(PACKED, PACKED_VERSION_CURRENT, bpv)
+		iterations := 3
+		values := make([]int64, iterations*decoder.ByteValueCount())
+		for i := range values {
+			values[i] = r.Int63n(int64(1) << bpv)
+		}
+
+		w := new(testDataWriter)
+		writer := NewPackedWriterNoHeader(NewDataOutput(w), PACKED, int32(len(values)), bpv)
+		for _, v := range values {
+			if err := writer.Add(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := writer.Finish(); err != nil {
+			t.Fatal(err)
+		}
+		if n := PackedFormat(PACKED).ByteCount(PACKED_VERSION_CURRENT, int32(len(values)), bpv); int64(w.Len()) != n {
+			t.Fatalf("bpv=%v: expected %v bytes, but was %v", bpv, n, w.Len())
+		}
+
+		decoded := make([]int64, len(values))
+		decoder.DecodeByteToLong(w.Bytes(), decoded, iterations)
+		for i, v := range values {
+			if decoded[i] != v {
+				t.Fatalf("bpv=%v: expected %v at %v, but was %v", bpv, v, i, decoded[i])
+			}
+		}
+	}
+
+	// the last partial byte is padded with zeros
+	w := new(testDataWriter)
+	writer := NewPackedWriterNoHeader(NewDataOutput(w), PACKED, 3, 3)
+	writer.Add(7)
+	if err := writer.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(w.Bytes(), []byte{0xE0, 0}) {
+		t.Errorf("expected [224 0], but was %v", w.Bytes())
+	}
+}
+
+// Decodes a 128-value block with the generic PACKED decoder, compare
+// with codec.BenchmarkForDecoder.
+func BenchmarkDecodeByteToInt(b *testing.B) {
+	for _, bpv := range []uint32{1, 3, 7, 8, 12, 17, 24, 32} {
+		b.Run(fmt.Sprintf("bpv=%v", bpv), func(b *testing.B) {
+			decoder := GetPackedIntsDecoder(PACKED, PACKED_VERSION_CURRENT, bpv)
+			iterations := 128 / decoder.ByteValueCount()
+			encoded := make([]byte, iterations*decoder.ByteBlockCount())
+			rand.New(rand.NewSource(1)).Read(encoded)
+			decoded := make([]int32, 128)
+			b.SetBytes(int64(128 * bpv / 8))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				decoder.DecodeByteToInt(encoded, decoded, iterations)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package util
+
+// RamUsageEstimator.java
+
+/*
+Sizes of primitive types and slice headers, used to estimate the
+memory buffered by the indexing chain. Like in Lucene, the estimates
+assume a 64-bit platform.
+*/
+const (
+	NUM_BYTES_INT  = 4
+	NUM_BYTES_LONG = 8
+	// pointer to the backing array, length and capacity
+	NUM_BYTES_SLICE_HEADER = 24
+)
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"math"
+)
+
+// util/SmallFloat.java
+
+/*
+Converts a 32 bit float to an 8 bit float, with 3 mantissa bits and a
+zero-exponent of 15. Values are truncated (rounded down) to the
+nearest 8 bit value. Values between zero and the smallest
+representable value are rounded up.
+
+This is the encoding used for the norm values of DefaultSimilarity.
+*/
+func FloatToByte315(f float32) byte {
+	bits := int32(math.Float32bits(f))
+	smallfloat := bits >> (24 - 3)
+	if smallfloat <= (63-15)<<3 {
+		if bits <= 0 {
+			return 0
+		}
+		return 1
+	}
+	if smallfloat >= (63-15)<<3+0x100 {
+		return 0xFF
+	}
+	return byte(smallfloat - (63-15)<<3)
+}
+
+// Converts an 8 bit float, as produced by FloatToByte315(), to a 32
+// bit float.
+func Byte315ToFloat(b byte) float32 {
+	if b == 0 {
+		return 0
+	}
+	bits := uint32(b) << (24 - 3)
+	bits += (63 - 15) << 24
+	return math.Float32frombits(bits)
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestSmallFloat315(t *testing.T) {
+	for _, v := range []struct {
+		f float32
+		b byte
+	}{
+		{0, 0},
+		{-1, 0},
+		{1e-20, 1}, // smallest value is rounded up
+		{1, 124},
+		{0.5, 120},
+		{1e20, 0xFF}, // largest value
+	} {
+		if b := FloatToByte315(v.f); b != v.b {
+			t.Errorf("Expected %v for %v, but was %v", v.b, v.f, b)
+		}
+	}
+	for i := 1; i < 256; i++ {
+		f := Byte315ToFloat(byte(i))
+		if b := FloatToByte315(f); b != byte(i) {
+			t.Errorf("Expected %v to round trip, but was %v (%v)", i, b, f)
+		}
+		if f <= Byte315ToFloat(byte(i-1)) {
+			t.Errorf("Decoded values must increase, but %v decoded to %v", i, f)
+		}
+	}
+}